@@ -0,0 +1,87 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"gopkg.in/yaml.v3"
+)
+
+// runConfigCommand handles the "config" subcommands:
+//
+//	server config print [-redacted=true] [-config configs/app.yml] [-format yaml|json]
+//	server config validate [-config configs/app.yml]
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "print" && args[0] != "validate") {
+		fmt.Fprintf(os.Stderr, "usage: %s config print [-redacted=true] [-config file] [-format yaml|json]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s config validate [-config file]\n", os.Args[0])
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", "", "base configuration file (default configs/app.yml)")
+	var redacted *bool
+	var format *string
+	if args[0] == "print" {
+		redacted = fs.Bool("redacted", true, "mask passwords, secrets, tokens and DSNs")
+		format = fs.String("format", "yaml", "output format: yaml or json")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	manager := config.NewManager()
+	if err := manager.Load(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 1
+	}
+
+	if args[0] == "validate" {
+		if err := manager.Validate(); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			return 1
+		}
+		if err := module.Configure(manager.GetConfig().Modules); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			return 1
+		}
+		fmt.Println("configuration is valid")
+		return 0
+	}
+
+	if err := printConfig(os.Stdout, manager, *redacted, *format); err != nil {
+		fmt.Fprintf(os.Stderr, "config: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printConfig writes the effective configuration with its sources
+func printConfig(w io.Writer, manager config.Manager, redacted bool, format string) error {
+	settings := manager.Settings(redacted)
+
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(settings)
+	case "yaml":
+		fmt.Fprintln(w, "# Effective configuration, sources in increasing order of precedence:")
+		for _, source := range manager.Sources() {
+			fmt.Fprintf(w, "#   - %s\n", source)
+		}
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(settings); err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("unsupported format: %s", format)
+	}
+}
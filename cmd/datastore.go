@@ -0,0 +1,47 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/factory"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// openDatastore connects to the configured datastore for a subcommand, with
+// the modules and ID strategy configured as the server does on startup. With
+// migrate, the schema is brought up to date when database.auto_migrate is set
+// and verified otherwise.
+func openDatastore(cfg *config.Config, migrate bool) (datastore.DatastoreInterface, error) {
+	if err := module.Configure(cfg.Modules); err != nil {
+		return nil, err
+	}
+	clk := clock.New()
+	ids, err := idgen.New(cfg.Database.IDStrategy, cfg.Database.NodeID, clk)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID strategy: %w", err)
+	}
+	idgen.Use(ids)
+
+	store, err := factory.NewSimpleFactory(clk).CreateDatastore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if !migrate {
+		return store, nil
+	}
+
+	if cfg.Database.AutoMigrate {
+		err = store.Migrate()
+	} else if checker, ok := store.(datastore.SchemaChecker); ok {
+		err = checker.CheckSchema()
+	}
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+	return store, nil
+}
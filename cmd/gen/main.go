@@ -0,0 +1,232 @@
+// Command gen scaffolds new resources across all layers of the server.
+//
+// Usage:
+//
+//	go run ./cmd/gen resource <Name> [-fields "title:string:required,price:float64"] [-force] [-dry-run]
+//	go run ./cmd/gen types [-dry-run]
+//
+// The generated model, DTOs, assembler, service, handler and API registration
+// are wired for dependency injection. The service persists through the generic
+// datastore repository, so no driver specific code is generated. Registration
+// points in existing files are located through "gen:" marker comments.
+//
+// The types command regenerates the registry of the DTO structs served by the
+// schema API; resource runs it after writing the DTOs.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// output maps a template to the file it renders, relative to the repository root
+type output struct {
+	template string
+	path     string
+}
+
+// marker describes a snippet inserted before a marker comment in an existing file
+type marker struct {
+	path    string
+	marker  string
+	snippet string
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "types" {
+		fs := flag.NewFlagSet("types", flag.ExitOnError)
+		dryRun := fs.Bool("dry-run", false, "print the file that would be written without writing it")
+		root := fs.String("root", ".", "repository root")
+		if err := fs.Parse(os.Args[2:]); err != nil {
+			os.Exit(2)
+		}
+		if err := writeTypes(*root, *dryRun); err != nil {
+			fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if len(os.Args) < 3 || os.Args[1] != "resource" {
+		usage()
+		os.Exit(2)
+	}
+
+	name := os.Args[2]
+	fs := flag.NewFlagSet("resource", flag.ExitOnError)
+	fieldsFlag := fs.String("fields", "name:string:required,description:string", "comma separated field definitions name:type[:required]")
+	force := fs.Bool("force", false, "overwrite existing files")
+	dryRun := fs.Bool("dry-run", false, "print the files that would be written without writing them")
+	root := fs.String("root", ".", "repository root")
+	if err := fs.Parse(os.Args[3:]); err != nil {
+		os.Exit(2)
+	}
+
+	if err := run(*root, name, *fieldsFlag, *force, *dryRun); err != nil {
+		fmt.Fprintf(os.Stderr, "gen: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: gen resource <Name> [-fields "title:string:required,price:float64"] [-force] [-dry-run] [-root .]
+       gen types [-dry-run] [-root .]
+
+Supported field types: string, text, int, int64, uint, float64, bool, time`)
+}
+
+// run renders all templates for the resource and patches the registration points
+func run(root, name, fieldsSpec string, force, dryRun bool) error {
+	module, err := readModulePath(filepath.Join(root, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	fields, err := parseFields(fieldsSpec)
+	if err != nil {
+		return err
+	}
+
+	res, err := newResource(module, name, fields)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("").Funcs(template.FuncMap{
+		"lower": strings.ToLower,
+	}).ParseFS(templateFS, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse templates: %w", err)
+	}
+
+	outputs := []output{
+		{"model.go.tmpl", "pkg/domain/model/" + res.Snake + ".go"},
+		{"model_test.go.tmpl", "pkg/domain/model/" + res.Snake + "_test.go"},
+		{"dto.go.tmpl", "pkg/api/dto/v1/" + res.Snake + ".go"},
+		{"assembler.go.tmpl", "pkg/api/assembler/v1/" + res.Snake + ".go"},
+		{"service.go.tmpl", "pkg/domain/service/" + res.Snake + ".go"},
+		{"handler.go.tmpl", "pkg/api/handler/" + res.Snake + ".go"},
+		{"api.go.tmpl", "pkg/api/" + res.Snake + ".go"},
+	}
+
+	for _, out := range outputs {
+		path := filepath.Join(root, out.path)
+		if _, err := os.Stat(path); err == nil && !force {
+			return fmt.Errorf("%s already exists (use -force to overwrite)", out.path)
+		}
+
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, out.template, res); err != nil {
+			return fmt.Errorf("failed to render %s: %w", out.path, err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w\n%s", out.path, err, buf.String())
+		}
+
+		if dryRun {
+			fmt.Printf("would write %s\n", out.path)
+			continue
+		}
+		if err := os.WriteFile(path, src, 0o644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", out.path)
+	}
+
+	for _, m := range res.markers() {
+		if dryRun {
+			fmt.Printf("would update %s (%s)\n", m.path, m.marker)
+			continue
+		}
+		if err := insertBeforeMarker(filepath.Join(root, m.path), m.marker, m.snippet); err != nil {
+			return err
+		}
+		fmt.Printf("updated %s\n", m.path)
+	}
+
+	return writeTypes(root, dryRun)
+}
+
+// markers returns the registration snippets for the resource
+func (r resource) markers() []marker {
+	return []marker{
+		{
+			path:    "pkg/domain/service/interface.go",
+			marker:  "// gen:service-beans",
+			snippet: fmt.Sprintf("\t\tNew%sServiceForDI(),\n", r.Name),
+		},
+		{
+			path:    "pkg/infrastructure/datastore/postgresql/postgresql.go",
+			marker:  "// gen:migrate-models",
+			snippet: fmt.Sprintf("\t\t&model.%s{},\n", r.Name),
+		},
+		{
+			path:    "pkg/infrastructure/datastore/opengauss/opengauss.go",
+			marker:  "// gen:migrate-models",
+			snippet: fmt.Sprintf("\t\t&model.%s{},\n", r.Name),
+		},
+		{
+			path:    "pkg/infrastructure/datastore/mongodb/mongodb.go",
+			marker:  "// gen:migrate-models",
+			snippet: fmt.Sprintf("\t\t&model.%s{},\n", r.Name),
+		},
+	}
+}
+
+// insertBeforeMarker inserts the snippet on the line before the marker comment and gofmts the file
+func insertBeforeMarker(path, markerText, snippet string) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.SplitAfter(string(src), "\n")
+	var out strings.Builder
+	found := false
+	for _, line := range lines {
+		if !found && strings.Contains(line, markerText) {
+			out.WriteString(snippet)
+			found = true
+		}
+		out.WriteString(line)
+	}
+	if !found {
+		return fmt.Errorf("marker %q not found in %s", markerText, path)
+	}
+
+	formatted, err := format.Source([]byte(out.String()))
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", path, err)
+	}
+	return os.WriteFile(path, formatted, 0o644)
+}
+
+// readModulePath returns the module path declared in go.mod
+func readModulePath(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open go.mod (run from the repository root or pass -root): %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.TrimSpace(strings.TrimPrefix(line, "module ")), nil
+		}
+	}
+	return "", fmt.Errorf("module path not found in %s", path)
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// resource holds the naming variants and fields used by the templates
+type resource struct {
+	Module      string
+	Name        string // BlogPost
+	Var         string // blogPost
+	Plural      string // BlogPosts
+	LowerPlural string // blogPosts
+	Snake       string // blog_post
+	Table       string // blog_posts
+	Route       string // blog-posts
+	Short       string // bp
+	Fields      []field
+}
+
+// field describes a resource field
+type field struct {
+	Name     string // Title
+	JSON     string // title
+	GoType   string // string
+	GormTag  string
+	Binding  string // create binding tag
+	Update   string // update binding tag
+	Example  string
+	Required bool
+	IsString bool
+	IsTime   bool
+}
+
+var namePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+
+// newResource builds the template data for a resource name such as BlogPost
+func newResource(module, name string, fields []field) (resource, error) {
+	if !namePattern.MatchString(name) {
+		return resource{}, fmt.Errorf("resource name must be UpperCamelCase, got %q", name)
+	}
+
+	snake := toSnake(name)
+	plural := pluralize(name)
+
+	parts := strings.Split(snake, "_")
+	var short strings.Builder
+	for _, part := range parts {
+		short.WriteByte(part[0])
+	}
+
+	return resource{
+		Module:      module,
+		Name:        name,
+		Var:         lowerFirst(name),
+		Plural:      plural,
+		LowerPlural: lowerFirst(plural),
+		Snake:       snake,
+		Table:       toSnake(plural),
+		Route:       strings.ReplaceAll(toSnake(plural), "_", "-"),
+		Short:       short.String(),
+		Fields:      fields,
+	}, nil
+}
+
+// HasTime reports whether any field is a time.Time
+func (r resource) HasTime() bool {
+	for _, f := range r.Fields {
+		if f.IsTime {
+			return true
+		}
+	}
+	return false
+}
+
+// RequiredStrings returns the required string fields, validated in the domain model
+func (r resource) RequiredStrings() []field {
+	var result []field
+	for _, f := range r.Fields {
+		if f.Required && f.IsString {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// parseFields parses name:type[:required] definitions
+func parseFields(spec string) ([]field, error) {
+	var fields []field
+	seen := make(map[string]bool)
+
+	for _, def := range strings.Split(spec, ",") {
+		def = strings.TrimSpace(def)
+		if def == "" {
+			continue
+		}
+
+		parts := strings.Split(def, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid field definition %q, expected name:type[:required]", def)
+		}
+		json := toSnake(parts[0])
+		if json == "" || json == "id" || json == "created_at" || json == "updated_at" {
+			return nil, fmt.Errorf("invalid or reserved field name %q", parts[0])
+		}
+		if seen[json] {
+			return nil, fmt.Errorf("duplicate field %q", parts[0])
+		}
+		seen[json] = true
+
+		required := len(parts) == 3 && parts[2] == "required"
+		if len(parts) == 3 && !required {
+			return nil, fmt.Errorf("invalid field modifier %q in %q", parts[2], def)
+		}
+
+		f, err := newField(json, parts[1], required)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, f)
+	}
+
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required")
+	}
+	return fields, nil
+}
+
+// newField maps a field type to Go, GORM and validation details
+func newField(json, typ string, required bool) (field, error) {
+	f := field{
+		Name:     toCamel(json),
+		JSON:     json,
+		Required: required,
+	}
+
+	var binding []string
+	switch typ {
+	case "string":
+		f.GoType, f.GormTag, f.Example, f.IsString = "string", "type:varchar(255)", "示例", true
+		binding = append(binding, "max=255")
+	case "text":
+		f.GoType, f.GormTag, f.Example, f.IsString = "string", "type:text", "示例文本", true
+		binding = append(binding, "max=10000")
+	case "int", "int64", "uint":
+		f.GoType, f.Example = typ, "1"
+	case "float64":
+		f.GoType, f.Example = "float64", "1.5"
+	case "bool":
+		f.GoType, f.Example = "bool", "true"
+	case "time":
+		f.GoType, f.Example, f.IsTime = "time.Time", "2024-01-01T12:00:00Z", true
+	default:
+		return field{}, fmt.Errorf("unsupported field type %q", typ)
+	}
+
+	if required && f.IsString {
+		f.GormTag += ";not null"
+	}
+
+	update := append([]string{"omitempty"}, binding...)
+	if required && typ != "bool" {
+		binding = append([]string{"required"}, binding...)
+		if f.IsString {
+			binding = append(binding[:1], append([]string{"min=1"}, binding[1:]...)...)
+		}
+	} else {
+		binding = append([]string{"omitempty"}, binding...)
+	}
+
+	f.Binding = strings.Join(binding, ",")
+	f.Update = strings.Join(update, ",")
+	return f, nil
+}
+
+// toSnake converts CamelCase or kebab-case to snake_case
+func toSnake(s string) string {
+	var b strings.Builder
+	runes := []rune(strings.ReplaceAll(s, "-", "_"))
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && runes[i-1] != '_' && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// toCamel converts snake_case to UpperCamelCase, keeping common initialisms upper case
+func toCamel(s string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(s, "_") {
+		if part == "" {
+			continue
+		}
+		switch part {
+		case "id", "url", "ip", "api", "uuid":
+			b.WriteString(strings.ToUpper(part))
+		default:
+			b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	return b.String()
+}
+
+// lowerFirst lower-cases the first rune
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	runes := []rune(s)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes)
+}
+
+// pluralize applies simple English pluralization rules
+func pluralize(s string) string {
+	lower := strings.ToLower(s)
+	switch {
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return s + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return s[:len(s)-1] + "ies"
+	default:
+		return s + "s"
+	}
+}
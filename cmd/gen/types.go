@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// dtoDir is the package whose structs are registered for the schema API
+const dtoDir = "pkg/api/dto/v1"
+
+// typesFile is the generated registry of the DTO structs, relative to dtoDir
+const typesFile = "types.go"
+
+// writeTypes lists the exported structs of the DTO package in its generated
+// registry, which serves their JSON schemas and example fixtures
+func writeTypes(root string, dryRun bool) error {
+	dir := filepath.Join(root, dtoDir)
+	names, err := structNames(dir)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by \"go run ./cmd/gen types\"; DO NOT EDIT.\n\n")
+	buf.WriteString("package v1\n\n")
+	buf.WriteString("// Types are the DTO structs by name, whose JSON schemas and example fixtures\n")
+	buf.WriteString("// are served under /api/v1/_schema\n")
+	buf.WriteString("var Types = map[string]interface{}{\n")
+	for _, name := range names {
+		fmt.Fprintf(&buf, "\t%q: %s{},\n", name, name)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("failed to format %s: %w", typesFile, err)
+	}
+	path := filepath.Join(dtoDir, typesFile)
+	if dryRun {
+		fmt.Printf("would write %s\n", path)
+		return nil
+	}
+	if err := os.WriteFile(filepath.Join(dir, typesFile), src, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %s (%d types)\n", path, len(names))
+	return nil
+}
+
+// structNames returns the sorted names of the exported, non-generic structs
+// declared in the Go files of dir, except the generated registry. Structs with a
+// MarshalText method are encoded as strings and have no schema of their own.
+func structNames(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var names []string
+	text := make(map[string]bool)
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == typesFile {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", name, err)
+		}
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Name.Name == "MarshalText" && fn.Recv != nil {
+				text[receiverName(fn.Recv.List[0].Type)] = true
+			}
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if _, ok := ts.Type.(*ast.StructType); ok && ts.Name.IsExported() && ts.TypeParams == nil {
+					names = append(names, ts.Name.Name)
+				}
+			}
+		}
+	}
+	names = slices.DeleteFunc(names, func(name string) bool { return text[name] })
+	sort.Strings(names)
+	return names, nil
+}
+
+// receiverName returns the type name of a method receiver such as T or *T
+func receiverName(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
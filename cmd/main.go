@@ -11,12 +11,37 @@ import (
 
 	"github.com/make-bin/server-tpl/pkg/server"
 	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/listener"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
 func main() {
-	// Initialize configuration
-	cfg := config.New()
+	// Subcommands
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "docs" {
+		os.Exit(runDocsCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		os.Exit(runSeedCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		os.Exit(runSnapshotCommand(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tenants" {
+		os.Exit(runTenantsCommand(os.Args[2:]))
+	}
+
+	// Initialize and validate configuration
+	manager := config.NewManager()
+	if err := manager.Load(""); err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if err := manager.Validate(); err != nil {
+		log.Fatalf("%v", err)
+	}
+	cfg := manager.GetConfig()
 
 	// Initialize logger
 	logger.Init(cfg.Log.Level)
@@ -24,6 +49,9 @@ func main() {
 	// Create server instance
 	srv := server.New(cfg)
 
+	// Watch the configuration files and the remote backend for changes
+	manager.WatchConfig(applyConfigChange)
+
 	// Start server in a goroutine
 	go func() {
 		if err := srv.Start(); err != nil && err != http.ErrServerClosed {
@@ -31,10 +59,28 @@ func main() {
 		}
 	}()
 
-	// Wait for interrupt signal to gracefully shutdown the server
+	// Wait for interrupt signal to gracefully shutdown the server. The upgrade
+	// signal hands the sockets over to a new process, then this one drains.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
+	upgrade := make(chan os.Signal, 1)
+	if cfg.Server.Socket.Upgrade && listener.UpgradeSignal != nil {
+		signal.Notify(upgrade, listener.UpgradeSignal)
+	}
+wait:
+	for {
+		select {
+		case <-quit:
+			break wait
+		case <-upgrade:
+			logger.Info("Upgrading server in place...")
+			if err := srv.Upgrade(context.Background()); err != nil {
+				logger.Error("Upgrade failed, the server keeps serving: %v", err)
+				continue
+			}
+			break wait
+		}
+	}
 
 	logger.Info("Shutting down server...")
 
@@ -42,10 +88,21 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	// Shutdown server
+	// Stop watching the configuration, then shutdown server
+	manager.Close()
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
 	logger.Info("Server exited")
 }
+
+// applyConfigChange applies a configuration change to the running server. The
+// log level takes effect at once; the other settings were read by the server
+// on startup and take effect on restart.
+func applyConfigChange(changed *config.Config) {
+	if err := logger.SetLevel(changed.Log.Level); err != nil {
+		logger.Warn("Ignoring log level change: %v", err)
+	}
+	logger.Info("Configuration changed; settings other than log.level take effect on restart")
+}
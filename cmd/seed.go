@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/factory"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/seed"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// seedTimeout bounds a seed run
+const seedTimeout = 5 * time.Minute
+
+// runSeedCommand handles the "seed" subcommand:
+//
+//	server seed [-config configs/app.yml] [-force] [-list] [seeder...]
+//
+// It runs the named seeders and the seeders they depend on, or all of them,
+// against the configured datastore. Production is never seeded, and
+// environments other than development and test only with -force.
+func runSeedCommand(args []string) int {
+	fs := flag.NewFlagSet("seed", flag.ContinueOnError)
+	configPath := fs.String("config", "", "base configuration file (default configs/app.yml)")
+	force := fs.Bool("force", false, "seed environments other than development and test, except production")
+	list := fs.Bool("list", false, "list the seeders in the order they run and exit")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: %s seed [-config file] [-force] [-list] [seeder...]\n", os.Args[0])
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *list {
+		seeders, err := seed.Seeders()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+			return 1
+		}
+		for _, s := range seeders {
+			fmt.Printf("%-24s %s\n", s.Name, s.Model.TableName())
+		}
+		return 0
+	}
+
+	manager := config.NewManager()
+	if err := manager.Load(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		return 1
+	}
+	if err := manager.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		return 1
+	}
+	cfg := manager.GetConfig()
+	logger.Init(cfg.Log.Level)
+
+	if err := seed.CheckEnvironment(cfg.App.Env, *force); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		return 1
+	}
+	if err := runSeeders(cfg, fs.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		return 1
+	}
+	fmt.Println("seeding completed")
+	return 0
+}
+
+// runSeeders connects to the datastore, brings its schema up to date as the
+// server does on startup and runs the seeders
+func runSeeders(cfg *config.Config, names []string) error {
+	if cfg.Database.Type == string(factory.Memory) {
+		return fmt.Errorf("the memory datastore does not outlive the command, seed a persistent datastore")
+	}
+	store, err := openDatastore(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), seedTimeout)
+	defer cancel()
+	return seed.Run(ctx, store, names...)
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/snapshot"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"gorm.io/gorm"
+)
+
+// snapshotTimeout bounds a snapshot dump or restore
+const snapshotTimeout = time.Hour
+
+// snapshotKeyEnv names the environment variable holding the pseudonym key of
+// dumps; the same key yields the same pseudonyms across dumps
+const snapshotKeyEnv = "SNAPSHOT_KEY"
+
+// runSnapshotCommand handles the "snapshot" subcommands:
+//
+//	server snapshot create [-config configs/app.yml] [-output file] [-tables a,b]
+//	server snapshot restore [-config configs/app.yml] [-force] [-replace] [-tables a,b] file
+//	server snapshot list
+//
+// create dumps tables of the configured SQL datastore with personal data
+// anonymized, restore loads such a dump. Production is never restored into,
+// and environments other than development and test only with -force.
+// Snapshots cover the primary database, tables routed to the datastores
+// under database.datastores are left out.
+func runSnapshotCommand(args []string) int {
+	if len(args) == 0 || (args[0] != "create" && args[0] != "restore" && args[0] != "list") {
+		fmt.Fprintf(os.Stderr, "usage: %s snapshot create [-config file] [-output file] [-tables a,b]\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s snapshot restore [-config file] [-force] [-replace] [-tables a,b] file\n", os.Args[0])
+		fmt.Fprintf(os.Stderr, "       %s snapshot list\n", os.Args[0])
+		return 2
+	}
+
+	if args[0] == "list" {
+		for _, t := range snapshot.Tables() {
+			fmt.Printf("%-28s %s\n", t.Name(), describeRules(t))
+		}
+		return 0
+	}
+
+	fs := flag.NewFlagSet("snapshot "+args[0], flag.ContinueOnError)
+	configPath := fs.String("config", "", "base configuration file (default configs/app.yml)")
+	tables := fs.String("tables", "", "comma separated tables, all by default")
+	var output *string
+	var force, replace *bool
+	if args[0] == "create" {
+		output = fs.String("output", "", "archive file (default snapshot-<env>-<time>.tar.gz)")
+	} else {
+		force = fs.Bool("force", false, "restore into environments other than development and test, except production")
+		replace = fs.Bool("replace", false, "delete the rows of the restored tables first")
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	if args[0] == "restore" && fs.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "snapshot: restore takes one archive file\n")
+		return 2
+	}
+
+	manager := config.NewManager()
+	if err := manager.Load(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		return 1
+	}
+	if err := manager.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		return 1
+	}
+	cfg := manager.GetConfig()
+	logger.Init(cfg.Log.Level)
+
+	exclude, err := routedTables(cfg, splitList(*tables))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		return 1
+	}
+	if args[0] == "create" {
+		err = createSnapshot(cfg, *output, splitList(*tables), exclude)
+	} else {
+		if err = checkRestoreEnvironment(cfg.App.Env, *force); err == nil {
+			err = restoreSnapshot(cfg, fs.Arg(0), splitList(*tables), exclude, *replace)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "snapshot: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// createSnapshot dumps the tables of the configured datastore to an archive file.
+// The schema is neither migrated nor verified, the source is only read.
+func createSnapshot(cfg *config.Config, output string, tables, exclude []string) error {
+	if output == "" {
+		output = fmt.Sprintf("snapshot-%s-%s.tar.gz", cfg.App.Env, time.Now().UTC().Format("20060102T150405Z"))
+	}
+	store, db, err := openSnapshotDatastore(cfg, false)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	file, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	manifest, err := snapshot.Dump(ctx, db, file, snapshot.DumpOptions{
+		Tables:      tables,
+		Exclude:     exclude,
+		Key:         []byte(os.Getenv(snapshotKeyEnv)),
+		Environment: cfg.App.Env,
+	})
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return err
+	}
+
+	for _, table := range manifest.Tables {
+		fmt.Printf("%-28s %d rows\n", table.Name, table.Rows)
+	}
+	fmt.Printf("snapshot written to %s\n", output)
+	return nil
+}
+
+// restoreSnapshot loads an archive file into the configured datastore, after
+// bringing its schema up to date as the server does on startup
+func restoreSnapshot(cfg *config.Config, input string, tables, exclude []string, replace bool) error {
+	file, err := os.Open(input)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	store, db, err := openSnapshotDatastore(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+	manifest, err := snapshot.Restore(ctx, db, file, snapshot.RestoreOptions{Tables: tables, Exclude: exclude, Replace: replace})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("snapshot of %s taken at %s restored\n", manifest.Environment, manifest.CreatedAt.Format(time.RFC3339))
+	return nil
+}
+
+// openSnapshotDatastore opens the configured datastore, whose primary database
+// must be backed by GORM
+func openSnapshotDatastore(cfg *config.Config, migrate bool) (datastore.DatastoreInterface, *gorm.DB, error) {
+	store, err := openDatastore(cfg, migrate)
+	if err != nil {
+		return nil, nil, err
+	}
+	primary := store
+	if router, ok := store.(*datastore.Router); ok {
+		primary = router.Primary()
+	}
+	provider, ok := primary.(datastore.GormProvider)
+	if !ok {
+		store.Close()
+		return nil, nil, fmt.Errorf("snapshots need a postgresql or opengauss datastore, not %s", cfg.Database.Type)
+	}
+	return store, provider.DB(), nil
+}
+
+// routedTables returns the tables routed to named datastores, which snapshots
+// leave out. Selecting one of them is an error.
+func routedTables(cfg *config.Config, selected []string) ([]string, error) {
+	routes := datastore.Routes(cfg.Database.Routes)
+	for _, table := range selected {
+		if name := routes.Datastore(table); name != datastore.PrimaryDatastore {
+			return nil, fmt.Errorf("table %s is held by datastore %s, snapshots cover the primary database", table, name)
+		}
+	}
+	var routed []string
+	for table, name := range routes {
+		if name != datastore.PrimaryDatastore {
+			routed = append(routed, table)
+		}
+	}
+	sort.Strings(routed)
+	return routed, nil
+}
+
+// checkRestoreEnvironment refuses to restore into production, and into other
+// environments than development and test unless force is set
+func checkRestoreEnvironment(env string, force bool) error {
+	switch strings.ToLower(env) {
+	case "development", "test":
+		return nil
+	case "production":
+		return fmt.Errorf("refusing to restore a snapshot into the production environment")
+	}
+	if !force {
+		return fmt.Errorf("refusing to restore a snapshot into the %s environment without -force", env)
+	}
+	return nil
+}
+
+// describeRules returns the anonymized columns of a table as column:rule
+func describeRules(t snapshot.Table) string {
+	rules := make([]string, 0, len(t.Columns))
+	for column, rule := range t.Columns {
+		rules = append(rules, column+":"+string(rule))
+	}
+	sort.Strings(rules)
+	if len(t.Filters) > 0 {
+		rules = append(rules, fmt.Sprintf("(rows where %v)", t.Filters))
+	}
+	return strings.Join(rules, " ")
+}
+
+// splitList splits a comma separated list, ignoring empty items
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
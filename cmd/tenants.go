@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// tenantsTimeout bounds the migration of the tenant schemas
+const tenantsTimeout = time.Hour
+
+// tenantsPageSize is the number of organizations read at once
+const tenantsPageSize = 500
+
+// runTenantsCommand handles the "tenants" subcommand:
+//
+//	server tenants migrate [-config configs/app.yml] [org-id...]
+//
+// With a schema per tenant (database.tenancy.mode schema), it creates the
+// missing schemas of the organizations given, or of all organizations, and
+// brings the tenant tables of their schemas up to date. The shared tables are
+// migrated first when database.auto_migrate is set, as the server does on
+// startup.
+func runTenantsCommand(args []string) int {
+	if len(args) == 0 || args[0] != "migrate" {
+		fmt.Fprintf(os.Stderr, "usage: %s tenants migrate [-config file] [org-id...]\n", os.Args[0])
+		return 2
+	}
+
+	fs := flag.NewFlagSet("tenants migrate", flag.ContinueOnError)
+	configPath := fs.String("config", "", "base configuration file (default configs/app.yml)")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+	ids := make([]uint, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseUint(arg, 10, 0)
+		if err != nil || id == 0 {
+			fmt.Fprintf(os.Stderr, "tenants: invalid organization ID %q\n", arg)
+			return 2
+		}
+		ids = append(ids, uint(id))
+	}
+
+	manager := config.NewManager()
+	if err := manager.Load(*configPath); err != nil {
+		fmt.Fprintf(os.Stderr, "tenants: %v\n", err)
+		return 1
+	}
+	if err := manager.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "tenants: %v\n", err)
+		return 1
+	}
+	cfg := manager.GetConfig()
+	logger.Init(cfg.Log.Level)
+
+	if cfg.Database.Tenancy.Mode != "schema" {
+		fmt.Fprintf(os.Stderr, "tenants: database.tenancy.mode is %s, organizations have no schema of their own\n", cfg.Database.Tenancy.Mode)
+		return 1
+	}
+	if err := migrateTenants(cfg, ids); err != nil {
+		fmt.Fprintf(os.Stderr, "tenants: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// migrateTenants provisions the schemas of the organizations ids, of every
+// organization when ids is empty
+func migrateTenants(cfg *config.Config, ids []uint) error {
+	store, err := openDatastore(cfg, true)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), tenantsTimeout)
+	defer cancel()
+	if len(ids) == 0 {
+		if ids, err = organizationIDs(ctx, store); err != nil {
+			return err
+		}
+	}
+	for _, id := range ids {
+		if err := datastore.ProvisionTenant(ctx, store, id); err != nil {
+			return fmt.Errorf("organization %d: %w", id, err)
+		}
+		fmt.Printf("organization %d migrated\n", id)
+	}
+	fmt.Printf("%d tenant schema(s) migrated\n", len(ids))
+	return nil
+}
+
+// organizationIDs returns the IDs of every organization in ascending order
+func organizationIDs(ctx context.Context, store datastore.DatastoreInterface) ([]uint, error) {
+	repo, err := datastore.NewRepository[*model.Organization](store)
+	if err != nil {
+		return nil, err
+	}
+	var ids []uint
+	for page := 1; ; page++ {
+		orgs, err := repo.List(ctx, datastore.ListOptions{Page: page, Size: tenantsPageSize, SortBy: "id"})
+		if err != nil {
+			return nil, err
+		}
+		for _, org := range orgs {
+			ids = append(ids, org.ID)
+		}
+		if len(orgs) < tenantsPageSize {
+			return ids, nil
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/retention"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+)
+
+// adminAPI 管理面板API结构，汇总运行数据供内部面板使用，无需抓取Prometheus
+type adminAPI struct {
+	Config        *config.Config               `inject:"config"`
+	PProf         *pprof.PProfManager          `inject:"pprof"`
+	Datastore     datastore.DatastoreInterface `inject:"datastore"`
+	Cache         datastore.Cache              `inject:"cache"`
+	ErrorReporter errorreport.Reporter         `inject:"error_reporter"`
+	Analytics     analytics.Sink               `inject:"analytics"`
+	Retention     *retention.Manager           `inject:"retention"`
+	handler       *handler.AdminHandler
+	profiles      *handler.ProfileHandler
+	analytics     *handler.AnalyticsHandler
+	retention     *handler.RetentionHandler
+}
+
+// init 注册API接口和管理模块，管理模块没有模型
+func init() {
+	RegisterAPIInterface(newAdminAPI())
+	module.Register(module.Module{
+		Name:        module.Admin,
+		Description: "Admin routes: dashboard, container, quotas and network ACL",
+	})
+}
+
+// newAdminAPI 创建依赖注入版本的管理面板API
+func newAdminAPI() APIInterface {
+	return &adminAPI{}
+}
+
+// Module 所属的管理模块，模块禁用时不注册路由
+func (a *adminAPI) Module() string {
+	return module.Admin
+}
+
+// InitAPIServiceRoute 初始化管理面板API路由（仅管理员），未启用时不注册路由
+func (a *adminAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.Config == nil || !a.Config.Monitor.Admin.Enabled || a.PProf == nil {
+		return
+	}
+	recentErrors, _ := a.ErrorReporter.(handler.RecentErrors)
+	a.handler = handler.NewAdminHandler(a.PProf, a.Datastore, a.Cache, recentErrors, a.Config)
+	a.profiles = handler.NewProfileHandler(a.PProf, a.Config.Monitor.Admin.MaxProfileDuration)
+
+	adminGroup := rg.Group("/admin", middleware.RequireRole("admin"))
+	adminGroup.GET("/dashboard", a.handler.GetDashboard)
+	adminGroup.GET("/errors", a.handler.ListRecentErrors)
+	adminGroup.GET("/config", a.handler.GetConfig)
+	adminGroup.GET("/profiles", a.profiles.DownloadProfileBundle)
+	adminGroup.GET("/profiles/:name", a.profiles.DownloadProfile)
+	if a.Config.Monitor.History.Enabled {
+		adminGroup.GET("/datastore/history", a.handler.GetDatastoreHistory)
+	}
+	if a.Config.Monitor.Analytics.Enabled && a.Analytics != nil {
+		a.analytics = handler.NewAnalyticsHandler(a.Analytics)
+		adminGroup.GET("/analytics", a.analytics.ListAnalyticsEntries)
+	}
+	if a.Retention != nil {
+		a.retention = handler.NewRetentionHandler(a.Retention)
+		adminGroup.GET("/retention", a.retention.PreviewRetention)
+	}
+}
@@ -2,23 +2,29 @@ package api
 
 import (
 	"regexp"
+	"time"
 	"unicode"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
 	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
 	"github.com/make-bin/server-tpl/pkg/domain/service"
 )
 
 // ApplicationAPI 应用API结构
 type ApplicationAPI struct {
-	handler *handler.ApplicationHandler
+	applicationService service.ApplicationServiceInterface
+	handler            *handler.ApplicationHandler
 }
 
 // application 支持依赖注入的应用API结构
 type application struct {
-	ApplicationService service.ApplicationServiceInterface `inject:""`
-	handler            *handler.ApplicationHandler
+	ApplicationService         service.ApplicationServiceInterface         `inject:""`
+	ApplicationVariableService service.ApplicationVariableServiceInterface `inject:""`
+	OperationService           service.OperationServiceInterface           `inject:""`
+	handler                    *handler.ApplicationHandler
 }
 
 // init 注册API接口和验证器
@@ -50,10 +56,11 @@ func newApplication() APIInterface {
 	return &application{}
 }
 
-// NewApplicationAPI 创建应用API实例
-func NewApplicationAPI(applicationService service.ApplicationServiceInterface) *ApplicationAPI {
+// NewApplicationAPI 创建应用API实例，variableService 和 operationService 可以为nil
+func NewApplicationAPI(applicationService service.ApplicationServiceInterface, variableService service.ApplicationVariableServiceInterface, operationService service.OperationServiceInterface) *ApplicationAPI {
 	return &ApplicationAPI{
-		handler: handler.NewApplicationHandler(applicationService),
+		applicationService: applicationService,
+		handler:            handler.NewApplicationHandler(applicationService, variableService, operationService),
 	}
 }
 
@@ -63,47 +70,114 @@ func NewApplicationAPI(applicationService service.ApplicationServiceInterface) *
 // @description 应用管理相关接口
 // @BasePath /api/v1
 func (a *ApplicationAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
-	applicationGroup := rg.Group("/applications")
+	applicationGroup := rg.Group("/applications", applicationPublicIDs(a.applicationService))
 	{
 		// 应用CRUD操作
 		applicationGroup.POST("", a.handler.CreateApplication)
 		applicationGroup.GET("", a.handler.ListApplications)
 		applicationGroup.GET("/:id", a.handler.GetApplication)
 		applicationGroup.PUT("/:id", a.handler.UpdateApplication)
+		applicationGroup.PATCH("/:id", a.handler.PatchApplication)
 		applicationGroup.DELETE("/:id", a.handler.DeleteApplication)
 
+		// 标签管理
+		applicationGroup.POST("/:id/tags", a.handler.AddApplicationTags)
+		applicationGroup.DELETE("/:id/tags/*tag", a.handler.RemoveApplicationTag) // 标签可含 "/"
+
+		// 修订记录和回滚
+		applicationGroup.GET("/:id/revisions", a.handler.ListApplicationRevisions)
+		applicationGroup.POST("/:id/rollback/:revision", a.handler.RollbackApplication)
+
 		// 统计和批量操作
 		applicationGroup.GET("/stats", a.handler.GetApplicationStats)
 		applicationGroup.POST("/batch-delete", a.handler.BatchDeleteApplications)
 
+		// 导入导出
+		applicationGroup.GET("/export", a.handler.ExportApplications)
+		applicationGroup.POST("/import", a.handler.ImportApplications)
+
 		// 健康检查
 		applicationGroup.GET("/health", a.handler.HealthCheck)
 	}
 }
 
+// RoutePolicies 声明应用API的路由策略：健康检查公开、不限流、不计配额且过载时不丢弃，
+// 导入导出使用严格限流，导出在过载时优先丢弃，统计的并发请求合并执行并缓存30秒，
+// 创建和导入应用需要接受服务条款；启用组织时其余路由都需要当前组织的成员身份
+func (a *application) RoutePolicies() map[string]middleware.RoutePolicy {
+	member := model.OrganizationRoleMember
+	scoped := middleware.RoutePolicy{OrgRole: member}
+	return map[string]middleware.RoutePolicy{
+		"GET /applications/health":                  {Public: true, RateLimit: middleware.RateLimitNone, Quota: middleware.QuotaNone, Priority: middleware.PriorityCritical},
+		"GET /applications/export":                  {RateLimit: "strict", Priority: middleware.PriorityLow, OrgRole: member},
+		"POST /applications/import":                 {RateLimit: "strict", Consent: []string{model.PolicyTerms}, OrgRole: member},
+		"POST /applications":                        {Consent: []string{model.PolicyTerms}, OrgRole: member},
+		"GET /applications/stats":                   {Coalesce: true, CacheTTL: 30 * time.Second, CacheTags: []string{"applications"}, OrgRole: member},
+		"GET /applications":                         scoped,
+		"GET /applications/:id":                     scoped,
+		"PUT /applications/:id":                     scoped,
+		"PATCH /applications/:id":                   scoped,
+		"DELETE /applications/:id":                  scoped,
+		"POST /applications/:id/tags":               scoped,
+		"DELETE /applications/:id/tags/*tag":        scoped,
+		"GET /applications/:id/revisions":           scoped,
+		"POST /applications/:id/rollback/:revision": scoped,
+		"POST /applications/batch-delete":           scoped,
+	}
+}
+
+// CacheInvalidations 删除应用后缓存的应用统计失效
+func (a *application) CacheInvalidations() map[string][]string {
+	return map[string][]string{
+		service.EventTypeApplicationDeleted: {"applications"},
+	}
+}
+
 // InitAPIServiceRoute 依赖注入版本的路由初始化
 func (a *application) InitAPIServiceRoute(rg *gin.RouterGroup) {
 	// 创建handler（注入后才能使用）
 	if a.ApplicationService != nil {
-		a.handler = handler.NewApplicationHandler(a.ApplicationService)
+		a.handler = handler.NewApplicationHandler(a.ApplicationService, a.ApplicationVariableService, a.OperationService)
 	}
 
 	applicationGroup := rg.Group("/applications")
 	{
 		if a.handler != nil {
+			applicationGroup.Use(applicationPublicIDs(a.ApplicationService))
+
 			// 应用CRUD操作
 			applicationGroup.POST("", a.handler.CreateApplication)
 			applicationGroup.GET("", a.handler.ListApplications)
 			applicationGroup.GET("/:id", a.handler.GetApplication)
 			applicationGroup.PUT("/:id", a.handler.UpdateApplication)
+			applicationGroup.PATCH("/:id", a.handler.PatchApplication)
 			applicationGroup.DELETE("/:id", a.handler.DeleteApplication)
 
+			// 标签管理
+			applicationGroup.POST("/:id/tags", a.handler.AddApplicationTags)
+			applicationGroup.DELETE("/:id/tags/*tag", a.handler.RemoveApplicationTag) // 标签可含 "/"
+
+			// 修订记录和回滚
+			applicationGroup.GET("/:id/revisions", a.handler.ListApplicationRevisions)
+			applicationGroup.POST("/:id/rollback/:revision", a.handler.RollbackApplication)
+
 			// 统计和批量操作
 			applicationGroup.GET("/stats", a.handler.GetApplicationStats)
 			applicationGroup.POST("/batch-delete", a.handler.BatchDeleteApplications)
 
+			// 导入导出
+			applicationGroup.GET("/export", a.handler.ExportApplications)
+			applicationGroup.POST("/import", a.handler.ImportApplications)
+
 			// 健康检查
 			applicationGroup.GET("/health", a.handler.HealthCheck)
 		}
 	}
 }
+
+// applicationPublicIDs 将应用路由 :id 参数中的公开ID解析为应用ID
+func applicationPublicIDs(applicationService service.ApplicationServiceInterface) gin.HandlerFunc {
+	return middleware.PublicIDMiddleware("id", func(c *gin.Context, publicID string) (uint, error) {
+		return applicationService.ResolveApplicationID(c.Request.Context(), publicID)
+	}, model.ErrApplicationNotFound, "app_not_found")
+}
@@ -0,0 +1,62 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// applicationBackup 支持依赖注入的应用备份API结构
+type applicationBackup struct {
+	ApplicationService       service.ApplicationServiceInterface       `inject:""`
+	ApplicationBackupService service.ApplicationBackupServiceInterface `inject:""`
+	handler                  *handler.ApplicationBackupHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newApplicationBackup())
+}
+
+// newApplicationBackup 创建依赖注入版本的应用备份API
+func newApplicationBackup() APIInterface {
+	return &applicationBackup{}
+}
+
+// RoutePolicies 启用组织时备份路由需要当前组织的成员身份
+func (a *applicationBackup) RoutePolicies() map[string]middleware.RoutePolicy {
+	if a.ApplicationBackupService == nil {
+		return nil
+	}
+	scoped := middleware.RoutePolicy{OrgRole: model.OrganizationRoleMember}
+	return map[string]middleware.RoutePolicy{
+		"POST /applications/:id/backups":                scoped,
+		"GET /applications/:id/backups":                 scoped,
+		"GET /applications/backups/:backup_id":          scoped,
+		"POST /applications/backups/:backup_id/restore": scoped,
+	}
+}
+
+// InitAPIServiceRoute 初始化应用备份API路由
+func (a *applicationBackup) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.ApplicationBackupService == nil {
+		return
+	}
+	a.handler = handler.NewApplicationBackupHandler(a.ApplicationBackupService)
+
+	applicationGroup := rg.Group("/applications")
+	if a.ApplicationService != nil {
+		applicationGroup.Use(applicationPublicIDs(a.ApplicationService))
+	}
+	{
+		// 创建和列出应用的备份
+		applicationGroup.POST("/:id/backups", a.handler.CreateBackup)
+		applicationGroup.GET("/:id/backups", a.handler.ListBackups)
+
+		// 按备份ID查询状态和恢复，应用删除后仍可使用
+		applicationGroup.GET("/backups/:backup_id", a.handler.GetBackup)
+		applicationGroup.POST("/backups/:backup_id/restore", a.handler.RestoreBackup)
+	}
+}
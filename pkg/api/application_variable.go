@@ -0,0 +1,68 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// applicationVariable 支持依赖注入的应用变量API结构
+type applicationVariable struct {
+	ApplicationService         service.ApplicationServiceInterface         `inject:""`
+	ApplicationVariableService service.ApplicationVariableServiceInterface `inject:""`
+	handler                    *handler.ApplicationVariableHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newApplicationVariable())
+}
+
+// newApplicationVariable 创建依赖注入版本的应用变量API
+func newApplicationVariable() APIInterface {
+	return &applicationVariable{}
+}
+
+// RoutePolicies 启用组织时变量路由需要当前组织的成员身份
+func (a *applicationVariable) RoutePolicies() map[string]middleware.RoutePolicy {
+	if a.ApplicationVariableService == nil {
+		return nil
+	}
+	scoped := middleware.RoutePolicy{OrgRole: model.OrganizationRoleMember}
+	return map[string]middleware.RoutePolicy{
+		"GET /applications/:id/variables":         scoped,
+		"POST /applications/:id/variables":        scoped,
+		"GET /applications/:id/variables/:key":    scoped,
+		"PUT /applications/:id/variables/:key":    scoped,
+		"DELETE /applications/:id/variables/:key": scoped,
+		"POST /applications/:id/variables/import": scoped,
+		"GET /applications/:id/variables/export":  scoped,
+	}
+}
+
+// InitAPIServiceRoute 初始化应用变量API路由
+func (a *applicationVariable) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.ApplicationVariableService == nil {
+		return
+	}
+	a.handler = handler.NewApplicationVariableHandler(a.ApplicationVariableService)
+
+	variableGroup := rg.Group("/applications/:id/variables")
+	if a.ApplicationService != nil {
+		variableGroup.Use(applicationPublicIDs(a.ApplicationService))
+	}
+	{
+		// 变量CRUD操作
+		variableGroup.GET("", a.handler.ListVariables)
+		variableGroup.POST("", a.handler.CreateVariable)
+		variableGroup.GET("/:key", a.handler.GetVariable)
+		variableGroup.PUT("/:key", a.handler.UpdateVariable)
+		variableGroup.DELETE("/:key", a.handler.DeleteVariable)
+
+		// 批量导入导出
+		variableGroup.POST("/import", a.handler.ImportVariables)
+		variableGroup.GET("/export", a.handler.ExportVariables)
+	}
+}
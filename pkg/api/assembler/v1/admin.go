@@ -0,0 +1,239 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/monitor"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/retention"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// AdminAssembler handles conversion of operational statistics to DTOs
+type AdminAssembler struct{}
+
+// NewAdminAssembler creates a new AdminAssembler instance
+func NewAdminAssembler() *AdminAssembler {
+	return &AdminAssembler{}
+}
+
+// ToRuntimeResponse converts runtime statistics to RuntimeStatsResponse DTO
+func (a *AdminAssembler) ToRuntimeResponse(stats *pprof.RuntimeStats) dto.RuntimeStatsResponse {
+	resp := dto.RuntimeStatsResponse{
+		Goroutines:    stats.NumGoroutine,
+		CPUs:          stats.NumCPU,
+		HeapAlloc:     stats.HeapAlloc,
+		HeapInuse:     stats.HeapInuse,
+		HeapObjects:   stats.HeapObjects,
+		StackInuse:    stats.StackInuse,
+		Sys:           stats.MemSys,
+		TotalAlloc:    stats.MemTotalAlloc,
+		NumGC:         stats.NumGC,
+		GCPauseTotal:  time.Duration(stats.PauseTotalNs).String(),
+		GCCPUFraction: stats.GCCPUFraction,
+		NextGC:        stats.NextGC,
+	}
+	if stats.LastGC > 0 {
+		lastGC := timestamp.New(time.Unix(0, int64(stats.LastGC)))
+		resp.LastGC = &lastGC
+	}
+	return resp
+}
+
+// ToDatastoreResponse converts datastore statistics to DatastoreStatsResponse DTO
+func (a *AdminAssembler) ToDatastoreResponse(stats *datastore.DatastoreStats) *dto.DatastoreStatsResponse {
+	if stats == nil {
+		return nil
+	}
+
+	resp := &dto.DatastoreStatsResponse{
+		Driver: stats.Driver,
+		Tables: stats.Tables,
+	}
+	if conn := stats.Connections; conn != nil {
+		resp.Connections = &dto.ConnectionStatsResponse{
+			MaxOpen:           conn.MaxOpen,
+			Open:              conn.Open,
+			InUse:             conn.InUse,
+			Idle:              conn.Idle,
+			WaitCount:         conn.WaitCount,
+			WaitDuration:      conn.WaitDuration.String(),
+			MaxIdleClosed:     conn.MaxIdleClosed,
+			MaxLifetimeClosed: conn.MaxLifetimeClosed,
+		}
+	}
+	if len(stats.Datastores) > 0 {
+		resp.Datastores = make(map[string]*dto.DatastoreStatsResponse, len(stats.Datastores))
+		for name, named := range stats.Datastores {
+			resp.Datastores[name] = a.ToDatastoreResponse(named)
+		}
+	}
+	return resp
+}
+
+// ToCacheResponse converts cache statistics to CacheStatsResponse DTO
+func (a *AdminAssembler) ToCacheResponse(stats datastore.CacheStats) *dto.CacheStatsResponse {
+	return &dto.CacheStatsResponse{
+		Hits:    stats.Hits,
+		Misses:  stats.Misses,
+		HitRate: stats.HitRate(),
+		Items:   stats.Items,
+	}
+}
+
+// ToRecentErrorsResponse converts recent error events to RecentErrorsResponse DTO
+func (a *AdminAssembler) ToRecentErrorsResponse(events []errorreport.Event, total uint64) dto.RecentErrorsResponse {
+	resp := dto.RecentErrorsResponse{
+		Total:  total,
+		Events: make([]dto.ErrorEventResponse, len(events)),
+	}
+
+	for i, event := range events {
+		resp.Events[i] = dto.ErrorEventResponse{
+			Level:      string(event.Level),
+			Message:    event.Message,
+			Panic:      event.Panic,
+			RequestID:  event.RequestID,
+			UserID:     event.UserID,
+			Method:     event.Method,
+			Route:      event.Route,
+			StatusCode: event.StatusCode,
+			StackTrace: event.StackTrace,
+			Timestamp:  timestamp.New(event.Timestamp),
+		}
+	}
+
+	return resp
+}
+
+// ToAnalyticsEntriesResponse converts analytics entries to AnalyticsEntriesResponse DTO
+func (a *AdminAssembler) ToAnalyticsEntriesResponse(entries []analytics.Entry) dto.AnalyticsEntriesResponse {
+	resp := dto.AnalyticsEntriesResponse{
+		Entries: make([]dto.AnalyticsEntryResponse, len(entries)),
+	}
+
+	for i, entry := range entries {
+		item := dto.AnalyticsEntryResponse{
+			Kind:           string(entry.Kind),
+			Timestamp:      timestamp.New(entry.Timestamp),
+			RequestID:      entry.RequestID,
+			UserID:         entry.UserID,
+			ImpersonatorID: entry.ImpersonatorID,
+			Action:         entry.Action,
+			Method:         entry.Method,
+			Path:           entry.Path,
+			Route:          entry.Route,
+			Status:         entry.Status,
+			ClientIP:       entry.ClientIP,
+			UserAgent:      entry.UserAgent,
+		}
+		if entry.Duration > 0 {
+			item.Duration = entry.Duration.String()
+		}
+		if entry.Payload != "" && json.Valid([]byte(entry.Payload)) {
+			item.Payload = json.RawMessage(entry.Payload)
+		}
+		resp.Entries[i] = item
+	}
+
+	return resp
+}
+
+// ToDatastoreHistoryResponse converts datastore history series to DatastoreHistoryResponse DTO
+func (a *AdminAssembler) ToDatastoreHistoryResponse(resolution string, series []monitor.HistorySeries) dto.DatastoreHistoryResponse {
+	resp := dto.DatastoreHistoryResponse{
+		Resolution: resolution,
+		Series:     make([]dto.DatastoreSeriesResponse, len(series)),
+	}
+
+	for i, s := range series {
+		points := make([]dto.DatastorePointResponse, len(s.Points))
+		for j, p := range s.Points {
+			points[j] = dto.DatastorePointResponse{
+				Timestamp: timestamp.New(p.Timestamp),
+				Count:     p.Count,
+				AverageMs: float64(p.AverageTime) / float64(time.Millisecond),
+				MaxMs:     float64(p.MaxTime) / float64(time.Millisecond),
+				Errors:    p.Errors,
+			}
+		}
+		resp.Series[i] = dto.DatastoreSeriesResponse{
+			Operation: s.Operation,
+			Table:     s.Table,
+			Points:    points,
+		}
+	}
+
+	return resp
+}
+
+// ToRetentionPreviewResponse converts retention reports to RetentionPreviewResponse DTO
+func (a *AdminAssembler) ToRetentionPreviewResponse(enabled bool, interval time.Duration, reports []retention.Report) dto.RetentionPreviewResponse {
+	resp := dto.RetentionPreviewResponse{
+		Enabled:  enabled,
+		Interval: interval.String(),
+		Policies: make([]dto.RetentionPolicyResponse, len(reports)),
+	}
+	for i, report := range reports {
+		item := dto.RetentionPolicyResponse{
+			Policy:    report.Policy,
+			Retention: report.Retention.String(),
+			Cutoff:    timestamp.New(report.Cutoff),
+			Expired:   report.Expired,
+		}
+		if report.Err != nil {
+			item.Error = report.Err.Error()
+		}
+		resp.Policies[i] = item
+	}
+	return resp
+}
+
+// ToNetworkACLRule converts NetworkACLRuleRequest DTO to a network ACL rule
+func (a *AdminAssembler) ToNetworkACLRule(req *dto.NetworkACLRuleRequest) config.NetworkACLRule {
+	return config.NetworkACLRule{
+		Paths:          req.Paths,
+		Allow:          req.Allow,
+		Deny:           req.Deny,
+		AllowCountries: req.AllowCountries,
+		DenyCountries:  req.DenyCountries,
+	}
+}
+
+// ToNetworkACLResponse converts the network ACL rules in use to NetworkACLResponse DTO
+func (a *AdminAssembler) ToNetworkACLResponse(geoIP bool, global config.NetworkACLRule, groups map[string]config.NetworkACLRule) dto.NetworkACLResponse {
+	resp := dto.NetworkACLResponse{
+		GeoIP:  geoIP,
+		Global: a.toNetworkACLRuleDTO(global),
+		Groups: make(map[string]dto.NetworkACLRuleRequest, len(groups)),
+	}
+	for name, group := range groups {
+		resp.Groups[name] = a.toNetworkACLRuleDTO(group)
+	}
+	return resp
+}
+
+// toNetworkACLRuleDTO converts a network ACL rule to NetworkACLRuleRequest DTO, with empty lists instead of null
+func (a *AdminAssembler) toNetworkACLRuleDTO(rule config.NetworkACLRule) dto.NetworkACLRuleRequest {
+	return dto.NetworkACLRuleRequest{
+		Paths:          rule.Paths,
+		Allow:          nonNilStrings(rule.Allow),
+		Deny:           nonNilStrings(rule.Deny),
+		AllowCountries: nonNilStrings(rule.AllowCountries),
+		DenyCountries:  nonNilStrings(rule.DenyCountries),
+	}
+}
+
+// nonNilStrings returns values as a non-nil slice so responses always contain an array
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
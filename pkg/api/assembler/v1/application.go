@@ -3,6 +3,7 @@ package v1
 import (
 	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
 	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
 )
 
 // ApplicationAssembler handles conversion between domain models and DTOs
@@ -13,20 +14,72 @@ func NewApplicationAssembler() *ApplicationAssembler {
 	return &ApplicationAssembler{}
 }
 
-// ToModel converts ApplicationRequest DTO to domain model
-func (a *ApplicationAssembler) ToModel(req *dto.ApplicationRequest) *model.Application {
+// ToModel converts CreateApplicationRequest DTO to domain model
+func (a *ApplicationAssembler) ToModel(req *dto.CreateApplicationRequest) *model.Application {
 	return &model.Application{
 		Name:        req.Name,
 		Description: req.Description,
+		Tags:        req.Tags,
+	}
+}
+
+// ToUpdatePatch converts UpdateApplicationRequest DTO to a patch. Empty name,
+// empty description and omitted tags leave the application unchanged.
+func (a *ApplicationAssembler) ToUpdatePatch(req *dto.UpdateApplicationRequest) *model.ApplicationPatch {
+	patch := &model.ApplicationPatch{}
+	if req.Name != "" {
+		patch.Name = &req.Name
+	}
+	if req.Description != "" {
+		patch.Description = &req.Description
+	}
+	if req.Tags != nil {
+		tags := model.StringList(req.Tags)
+		patch.Tags = &tags
+	}
+	return patch
+}
+
+// ToPatch converts PatchApplicationRequest DTO to a patch. Fields absent from
+// the request are nil and leave the application unchanged.
+func (a *ApplicationAssembler) ToPatch(req *dto.PatchApplicationRequest) *model.ApplicationPatch {
+	patch := &model.ApplicationPatch{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	if req.Tags != nil {
+		tags := model.StringList(*req.Tags)
+		patch.Tags = &tags
+	}
+	return patch
+}
+
+// ToQuery converts ListApplicationsRequest DTO to a query selecting a page of
+// applications with the parsed tag selector
+func (a *ApplicationAssembler) ToQuery(req *dto.ListApplicationsRequest, selector model.TagSelector) model.ApplicationQuery {
+	page := req.Pagination()
+	return model.ApplicationQuery{
+		Page:      page.Page,
+		Size:      page.Size,
+		IDs:       req.IDs,
+		Tags:      selector,
+		CreatedAt: toTimeRange(req.CreatedAt),
+		UpdatedAt: toTimeRange(req.UpdatedAt),
+		Sort:      toSortOrders(req.Sort),
 	}
 }
 
 // ToResponse converts domain model to ApplicationResponse DTO
 func (a *ApplicationAssembler) ToResponse(app *model.Application) *dto.ApplicationResponse {
 	return &dto.ApplicationResponse{
-		ID:          app.ID,
+		ID:          idgen.Expose(app.ID),
+		PublicID:    app.PublicID,
+		OrgID:       idgen.Expose(app.OrgID),
+		OwnerID:     app.OwnerID,
 		Name:        app.Name,
 		Description: app.Description,
+		Status:      app.Status(),
+		Tags:        tagsOrEmpty(app.Tags),
 		CreatedAt:   app.CreatedAt,
 		UpdatedAt:   app.UpdatedAt,
 	}
@@ -46,3 +99,42 @@ func (a *ApplicationAssembler) ToResponseList(apps []*model.Application, total i
 		PageSize:     pageSize,
 	}
 }
+
+// ToRevisionResponse converts an application revision to ApplicationRevisionResponse DTO
+func (a *ApplicationAssembler) ToRevisionResponse(revision *model.ApplicationRevision) *dto.ApplicationRevisionResponse {
+	changes := make([]dto.FieldChangeResponse, len(revision.Changes))
+	for i, change := range revision.Changes {
+		changes[i] = dto.FieldChangeResponse{Field: change.Field, Old: change.Old, New: change.New}
+	}
+
+	return &dto.ApplicationRevisionResponse{
+		Revision:     revision.Revision,
+		Action:       revision.Action,
+		Actor:        revision.Actor,
+		RestoredFrom: revision.RestoredFrom,
+		Changes:      changes,
+		Snapshot: dto.ApplicationSnapshotResponse{
+			Name:        revision.Snapshot.Name,
+			Description: revision.Snapshot.Description,
+			Tags:        tagsOrEmpty(revision.Snapshot.Tags),
+		},
+		CreatedAt: revision.CreatedAt,
+	}
+}
+
+// ToRevisionResponseList converts application revisions to ApplicationRevisionResponse DTOs
+func (a *ApplicationAssembler) ToRevisionResponseList(revisions []*model.ApplicationRevision) []dto.ApplicationRevisionResponse {
+	responses := make([]dto.ApplicationRevisionResponse, len(revisions))
+	for i, revision := range revisions {
+		responses[i] = *a.ToRevisionResponse(revision)
+	}
+	return responses
+}
+
+// tagsOrEmpty returns tags as a non-nil slice so responses always contain an array
+func tagsOrEmpty(tags model.StringList) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
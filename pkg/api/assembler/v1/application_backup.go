@@ -0,0 +1,55 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// ApplicationBackupAssembler handles conversion between application backup models and DTOs
+type ApplicationBackupAssembler struct{}
+
+// NewApplicationBackupAssembler creates a new ApplicationBackupAssembler instance
+func NewApplicationBackupAssembler() *ApplicationBackupAssembler {
+	return &ApplicationBackupAssembler{}
+}
+
+// ToModel converts ApplicationBackupRequest DTO to domain model
+func (a *ApplicationBackupAssembler) ToModel(appID uint, req *dto.ApplicationBackupRequest) *model.ApplicationBackup {
+	return &model.ApplicationBackup{
+		AppID:       appID,
+		Name:        req.Name,
+		Description: req.Description,
+		IncludeData: req.IncludeData,
+		Compress:    req.Compress,
+	}
+}
+
+// ToResponse converts domain model to ApplicationBackupResponse DTO
+func (a *ApplicationBackupAssembler) ToResponse(backup *model.ApplicationBackup) *dto.ApplicationBackupResponse {
+	return &dto.ApplicationBackupResponse{
+		ID:          backup.BackupID,
+		OperationID: backup.OperationID,
+		AppID:       idgen.Expose(backup.AppID),
+		Name:        backup.Name,
+		Description: backup.Description,
+		IncludeData: backup.IncludeData,
+		Compress:    backup.Compress,
+		FilePath:    backup.StorageKey,
+		FileSize:    backup.Size,
+		Status:      backup.Status,
+		Error:       backup.Error,
+		CreatedAt:   backup.CreatedAt,
+		CompletedAt: timestamp.Ptr(backup.CompletedAt),
+	}
+}
+
+// ToResponseList converts slice of domain models to ApplicationBackupResponse DTOs
+func (a *ApplicationBackupAssembler) ToResponseList(backups []*model.ApplicationBackup) []dto.ApplicationBackupResponse {
+	responses := make([]dto.ApplicationBackupResponse, len(backups))
+	for i, backup := range backups {
+		responses[i] = *a.ToResponse(backup)
+	}
+	return responses
+}
@@ -0,0 +1,149 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// Application export and import formats
+const (
+	ApplicationFormatCSV  = "csv"
+	ApplicationFormatXLSX = "xlsx"
+)
+
+// ApplicationColumns lists the columns of application exports in their default order.
+// Imports read name, description and tags and ignore the other columns.
+var ApplicationColumns = []string{"id", "name", "description", "tags", "created_at", "updated_at"}
+
+// ParseColumns parses a comma separated list of export columns; an empty list selects all columns
+func (a *ApplicationAssembler) ParseColumns(spec string) ([]string, error) {
+	if strings.TrimSpace(spec) == "" {
+		return ApplicationColumns, nil
+	}
+
+	var columns []string
+	seen := make(map[string]bool)
+	for _, column := range strings.Split(spec, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if !isApplicationColumn(column) {
+			return nil, fmt.Errorf("unknown column %q, expected one of %s", column, strings.Join(ApplicationColumns, ","))
+		}
+		if !seen[column] {
+			seen[column] = true
+			columns = append(columns, column)
+		}
+	}
+	return columns, nil
+}
+
+// ToRecord converts an application to the values of the given columns
+func (a *ApplicationAssembler) ToRecord(app *model.Application, columns []string) []string {
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		switch column {
+		case "id":
+			record[i] = strconv.FormatUint(uint64(app.ID), 10)
+		case "name":
+			record[i] = app.Name
+		case "description":
+			record[i] = app.Description
+		case "tags":
+			record[i] = strings.Join(app.Tags, ",")
+		case "created_at":
+			record[i] = app.CreatedAt.UTC().Format(time.RFC3339)
+		case "updated_at":
+			record[i] = app.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+	}
+	return record
+}
+
+// FromRecords converts imported rows to domain models. The first row holds the
+// column names and must include name; blank rows are skipped. rows holds the
+// one-based row number of each application for error reporting.
+func (a *ApplicationAssembler) FromRecords(records [][]string) (apps []*model.Application, rows []int, err error) {
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("the file is empty")
+	}
+
+	index := make(map[string]int)
+	for i, column := range records[0] {
+		column = strings.ToLower(strings.TrimSpace(column))
+		if _, exists := index[column]; !exists {
+			index[column] = i
+		}
+	}
+	if _, ok := index["name"]; !ok {
+		return nil, nil, fmt.Errorf("the header row must include a name column")
+	}
+
+	cell := func(record []string, column string) string {
+		i, ok := index[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return unescapeFormula(strings.TrimSpace(record[i]))
+	}
+
+	for i, record := range records[1:] {
+		if isBlankRecord(record) {
+			continue
+		}
+
+		var tags model.StringList
+		for _, tag := range strings.Split(cell(record, "tags"), ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+
+		apps = append(apps, &model.Application{
+			Name:        cell(record, "name"),
+			Description: cell(record, "description"),
+			Tags:        tags,
+		})
+		rows = append(rows, i+2)
+	}
+	return apps, rows, nil
+}
+
+// EscapeFormula prefixes values that spreadsheet applications would evaluate
+// as a formula with a single quote, so that exported CSV files are safe to open
+func EscapeFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeFormula reverts EscapeFormula
+func unescapeFormula(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune("=+-@", rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// isApplicationColumn reports whether column is an export column
+func isApplicationColumn(column string) bool {
+	for _, c := range ApplicationColumns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// isBlankRecord reports whether every value of record is blank
+func isBlankRecord(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/dotenv"
+)
+
+// Variable import and export formats
+const (
+	VariableFormatDotenv = "dotenv"
+	VariableFormatJSON   = "json"
+)
+
+// ApplicationVariableAssembler handles conversion between application variable models and DTOs
+type ApplicationVariableAssembler struct{}
+
+// NewApplicationVariableAssembler creates a new ApplicationVariableAssembler instance
+func NewApplicationVariableAssembler() *ApplicationVariableAssembler {
+	return &ApplicationVariableAssembler{}
+}
+
+// ToModel converts CreateApplicationVariableRequest DTO to domain model
+func (a *ApplicationVariableAssembler) ToModel(appID uint, req *dto.CreateApplicationVariableRequest) *model.ApplicationVariable {
+	return &model.ApplicationVariable{
+		AppID:  appID,
+		Key:    req.Key,
+		Value:  req.Value,
+		Secret: req.Secret,
+	}
+}
+
+// ApplyUpdate applies the fields present in UpdateApplicationVariableRequest to the model
+func (a *ApplicationVariableAssembler) ApplyUpdate(variable *model.ApplicationVariable, req *dto.UpdateApplicationVariableRequest) {
+	if req.Value != nil {
+		variable.Value = *req.Value
+	}
+	if req.Secret != nil {
+		variable.Secret = *req.Secret
+	}
+}
+
+// ToResponse converts domain model to ApplicationVariableResponse DTO, masking secret values
+func (a *ApplicationVariableAssembler) ToResponse(variable *model.ApplicationVariable) *dto.ApplicationVariableResponse {
+	value := variable.Value
+	if variable.Secret {
+		value = config.RedactedValue
+	}
+
+	return &dto.ApplicationVariableResponse{
+		Key:       variable.Key,
+		Value:     value,
+		Secret:    variable.Secret,
+		CreatedAt: variable.CreatedAt,
+		UpdatedAt: variable.UpdatedAt,
+	}
+}
+
+// ToResponseList converts slice of domain models to ApplicationVariableResponse DTOs
+func (a *ApplicationVariableAssembler) ToResponseList(variables []*model.ApplicationVariable) []dto.ApplicationVariableResponse {
+	responses := make([]dto.ApplicationVariableResponse, len(variables))
+	for i, variable := range variables {
+		responses[i] = *a.ToResponse(variable)
+	}
+	return responses
+}
+
+// ToPublicValues returns the values of the non-secret variables keyed by variable name
+func (a *ApplicationVariableAssembler) ToPublicValues(variables []*model.ApplicationVariable) map[string]string {
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		if !variable.Secret {
+			values[variable.Key] = variable.Value
+		}
+	}
+	return values
+}
+
+// FromImport parses the content of an import request into domain models
+func (a *ApplicationVariableAssembler) FromImport(appID uint, req *dto.ImportApplicationVariablesRequest) ([]*model.ApplicationVariable, error) {
+	var entries []dotenv.Entry
+	switch req.Format {
+	case VariableFormatDotenv:
+		var err error
+		if entries, err = dotenv.Parse([]byte(req.Content)); err != nil {
+			return nil, fmt.Errorf("invalid dotenv content: %w", err)
+		}
+	case VariableFormatJSON:
+		var values map[string]string
+		if err := json.Unmarshal([]byte(req.Content), &values); err != nil {
+			return nil, fmt.Errorf("invalid JSON content, expected an object of string values: %w", err)
+		}
+		for key, value := range values {
+			entries = append(entries, dotenv.Entry{Key: key, Value: value})
+		}
+	default:
+		return nil, fmt.Errorf("unsupported format %q", req.Format)
+	}
+
+	secrets := make(map[string]bool, len(req.Secrets))
+	for _, key := range req.Secrets {
+		secrets[key] = true
+	}
+
+	// Later lines override earlier ones, as when the file is sourced
+	index := make(map[string]int, len(entries))
+	variables := make([]*model.ApplicationVariable, 0, len(entries))
+	for _, entry := range entries {
+		variable := &model.ApplicationVariable{
+			AppID:  appID,
+			Key:    entry.Key,
+			Value:  entry.Value,
+			Secret: secrets[entry.Key],
+		}
+		if i, exists := index[entry.Key]; exists {
+			variables[i] = variable
+			continue
+		}
+		index[entry.Key] = len(variables)
+		variables = append(variables, variable)
+	}
+	return variables, nil
+}
+
+// ToExport renders variables in the given format. Secret variables are only
+// included when includeSecrets is set.
+func (a *ApplicationVariableAssembler) ToExport(variables []*model.ApplicationVariable, format string, includeSecrets bool) ([]byte, error) {
+	switch format {
+	case VariableFormatDotenv, "":
+		entries := make([]dotenv.Entry, 0, len(variables))
+		for _, variable := range variables {
+			if includeSecrets || !variable.Secret {
+				entries = append(entries, dotenv.Entry{Key: variable.Key, Value: variable.Value})
+			}
+		}
+		return dotenv.Marshal(entries), nil
+	case VariableFormatJSON:
+		values := make(map[string]string, len(variables))
+		for _, variable := range variables {
+			if includeSecrets || !variable.Secret {
+				values[variable.Key] = variable.Value
+			}
+		}
+		return json.MarshalIndent(values, "", "  ")
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"time"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/utils/bench"
+)
+
+// BenchAssembler handles conversion of benchmark results to DTOs
+type BenchAssembler struct{}
+
+// NewBenchAssembler creates a new BenchAssembler instance
+func NewBenchAssembler() *BenchAssembler {
+	return &BenchAssembler{}
+}
+
+// ToBenchResponse converts a benchmark result to BenchResponse DTO
+func (a *BenchAssembler) ToBenchResponse(target string, result *bench.Result) *dto.BenchResponse {
+	resp := &dto.BenchResponse{
+		Target:      target,
+		Concurrency: result.Concurrency,
+		Operations:  result.Operations,
+		Errors:      result.Errors,
+		FirstError:  result.FirstError,
+		ElapsedMs:   milliseconds(result.Elapsed),
+		Throughput:  result.Throughput,
+		Latency: dto.LatencyResponse{
+			MinMs:  milliseconds(result.Min),
+			MeanMs: milliseconds(result.Mean),
+			P50Ms:  milliseconds(result.P50),
+			P90Ms:  milliseconds(result.P90),
+			P99Ms:  milliseconds(result.P99),
+			MaxMs:  milliseconds(result.Max),
+		},
+		Histogram: make([]dto.HistogramBucketResponse, 0, len(result.Histogram)),
+	}
+	for _, b := range result.Histogram {
+		le := "+Inf"
+		if b.UpperBound > 0 {
+			le = b.UpperBound.String()
+		}
+		resp.Histogram = append(resp.Histogram, dto.HistogramBucketResponse{LE: le, Count: b.Count})
+	}
+	return resp
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
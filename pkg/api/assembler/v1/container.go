@@ -0,0 +1,57 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/utils/container"
+)
+
+// ContainerAssembler handles conversion of container diagnostics to DTOs
+type ContainerAssembler struct{}
+
+// NewContainerAssembler creates a new ContainerAssembler instance
+func NewContainerAssembler() *ContainerAssembler {
+	return &ContainerAssembler{}
+}
+
+// ToResponse converts bean information to ContainerResponse DTO
+func (a *ContainerAssembler) ToResponse(beans []container.BeanInfo) *dto.ContainerResponse {
+	resp := &dto.ContainerResponse{
+		Healthy: true,
+		Total:   len(beans),
+		Beans:   make([]dto.BeanResponse, len(beans)),
+	}
+
+	for i, bean := range beans {
+		deps := make([]dto.BeanDependencyResponse, len(bean.Dependencies))
+		for j, dep := range bean.Dependencies {
+			deps[j] = dto.BeanDependencyResponse{
+				Field:     dep.Field,
+				Tag:       dep.Tag,
+				Type:      dep.Type,
+				Bean:      dep.Bean,
+				Group:     dep.Group,
+				Satisfied: dep.Satisfied,
+				Error:     dep.Error,
+			}
+		}
+
+		resp.Beans[i] = dto.BeanResponse{
+			Name:         bean.Name,
+			Type:         bean.Type,
+			Qualifiers:   bean.Qualifiers,
+			Lifecycle:    bean.Lifecycle,
+			Dependencies: deps,
+			Starter:      bean.Starter,
+			Stopper:      bean.Stopper,
+			Started:      bean.Started,
+			Healthy:      bean.Healthy,
+			HealthError:  bean.HealthError,
+		}
+		if !bean.Healthy {
+			resp.Healthy = false
+			resp.Unhealthy++
+		}
+	}
+
+	return resp
+}
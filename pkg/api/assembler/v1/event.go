@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"encoding/json"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// EventAssembler handles conversion between domain events and DTOs
+type EventAssembler struct{}
+
+// NewEventAssembler creates a new EventAssembler instance
+func NewEventAssembler() *EventAssembler {
+	return &EventAssembler{}
+}
+
+// ToResponse converts a logged domain event to EventResponse DTO. The event
+// ID is the cursor of clients and is kept when internal IDs are hidden.
+func (a *EventAssembler) ToResponse(e *model.DomainEvent) *dto.EventResponse {
+	return &dto.EventResponse{
+		ID:           idgen.ID(e.ID),
+		Type:         e.EventType,
+		ResourceType: e.ResourceType,
+		OrgID:        idgen.Expose(e.OrgID),
+		Payload:      json.RawMessage(e.Payload),
+		OccurredAt:   timestamp.New(e.OccurredAt),
+	}
+}
+
+// ToListResponse converts the events after cursor to EventListResponse DTO
+func (a *EventAssembler) ToListResponse(events []*model.DomainEvent, cursor uint) *dto.EventListResponse {
+	resp := &dto.EventListResponse{Events: make([]dto.EventResponse, len(events)), NextCursor: idgen.ID(cursor)}
+	for i, e := range events {
+		resp.Events[i] = *a.ToResponse(e)
+		resp.NextCursor = idgen.ID(e.ID)
+	}
+	return resp
+}
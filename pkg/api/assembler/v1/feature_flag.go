@@ -0,0 +1,88 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// FeatureFlagAssembler handles conversion between feature flag models and DTOs
+type FeatureFlagAssembler struct{}
+
+// NewFeatureFlagAssembler creates a new FeatureFlagAssembler instance
+func NewFeatureFlagAssembler() *FeatureFlagAssembler {
+	return &FeatureFlagAssembler{}
+}
+
+// ToModel converts CreateFeatureFlagRequest DTO to domain model
+func (a *FeatureFlagAssembler) ToModel(req *dto.CreateFeatureFlagRequest) *model.FeatureFlag {
+	rollout := 100
+	if req.RolloutPercentage != nil {
+		rollout = *req.RolloutPercentage
+	}
+
+	return &model.FeatureFlag{
+		Key:               req.Key,
+		Description:       req.Description,
+		Enabled:           req.Enabled,
+		RolloutPercentage: rollout,
+		TargetUsers:       req.TargetUsers,
+		TargetTenants:     req.TargetTenants,
+	}
+}
+
+// ApplyUpdate applies the fields present in UpdateFeatureFlagRequest to the model
+func (a *FeatureFlagAssembler) ApplyUpdate(flag *model.FeatureFlag, req *dto.UpdateFeatureFlagRequest) {
+	if req.Description != nil {
+		flag.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		flag.Enabled = *req.Enabled
+	}
+	if req.RolloutPercentage != nil {
+		flag.RolloutPercentage = *req.RolloutPercentage
+	}
+	if req.TargetUsers != nil {
+		flag.TargetUsers = req.TargetUsers
+	}
+	if req.TargetTenants != nil {
+		flag.TargetTenants = req.TargetTenants
+	}
+}
+
+// ToResponse converts domain model to FeatureFlagResponse DTO
+func (a *FeatureFlagAssembler) ToResponse(flag *model.FeatureFlag) *dto.FeatureFlagResponse {
+	source := "datastore"
+	if flag.ID == 0 {
+		source = "config"
+	}
+
+	targetUsers := []string(flag.TargetUsers)
+	if targetUsers == nil {
+		targetUsers = []string{}
+	}
+	targetTenants := []string(flag.TargetTenants)
+	if targetTenants == nil {
+		targetTenants = []string{}
+	}
+
+	return &dto.FeatureFlagResponse{
+		Key:               flag.Key,
+		Description:       flag.Description,
+		Enabled:           flag.Enabled,
+		RolloutPercentage: flag.RolloutPercentage,
+		TargetUsers:       targetUsers,
+		TargetTenants:     targetTenants,
+		Source:            source,
+		CreatedAt:         flag.CreatedAt,
+		UpdatedAt:         flag.UpdatedAt,
+	}
+}
+
+// ToResponseList converts slice of domain models to FeatureFlagResponse DTOs
+func (a *FeatureFlagAssembler) ToResponseList(flags []*model.FeatureFlag) []dto.FeatureFlagResponse {
+	responses := make([]dto.FeatureFlagResponse, len(flags))
+	for i, flag := range flags {
+		responses[i] = *a.ToResponse(flag)
+	}
+	return responses
+}
@@ -0,0 +1,124 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// FileAssembler handles conversion between file models and DTOs
+type FileAssembler struct{}
+
+// NewFileAssembler creates a new FileAssembler instance
+func NewFileAssembler() *FileAssembler {
+	return &FileAssembler{}
+}
+
+// ToModel converts FileUploadRequest DTO to domain model
+func (a *FileAssembler) ToModel(req *dto.FileUploadRequest) *model.File {
+	return &model.File{
+		Name:        req.Name,
+		ContentType: req.ContentType,
+		Size:        req.Size,
+	}
+}
+
+// ToResponse converts domain model to FileResponse DTO
+func (a *FileAssembler) ToResponse(file *model.File) *dto.FileResponse {
+	return &dto.FileResponse{
+		ID:             idgen.Expose(file.ID),
+		PublicID:       file.PublicID,
+		Name:           file.Name,
+		ContentType:    file.ContentType,
+		Size:           file.Size,
+		Status:         file.Status,
+		OwnerID:        file.OwnerID,
+		OrgID:          idgen.Expose(file.OrgID),
+		CreatedAt:      file.CreatedAt,
+		UploadedAt:     timestamp.Ptr(file.UploadedAt),
+		OperationID:    file.OperationID,
+		ChecksumSHA256: file.ChecksumSHA256,
+		ScanStatus:     file.ScanStatus,
+		ScanSignature:  file.ScanSignature,
+	}
+}
+
+// ToResponseList converts domain models to FileResponse DTOs
+func (a *FileAssembler) ToResponseList(files []*model.File) []dto.FileResponse {
+	responses := make([]dto.FileResponse, len(files))
+	for i, file := range files {
+		responses[i] = *a.ToResponse(file)
+	}
+	return responses
+}
+
+// ToURLResponse converts a presigned URL to PresignedURLResponse DTO
+func (a *FileAssembler) ToURLResponse(url *service.PresignedURL) *dto.PresignedURLResponse {
+	return &dto.PresignedURLResponse{
+		URL:       url.URL,
+		Method:    url.Method,
+		Headers:   url.Headers,
+		ExpiresAt: timestamp.New(url.ExpiresAt),
+	}
+}
+
+// ToVariantResponseList converts the variants of a file with their download
+// URLs by variant name to FileVariantResponse DTOs, leaving out variants without URL
+func (a *FileAssembler) ToVariantResponseList(variants model.FileVariants, urls map[string]*service.PresignedURL) []dto.FileVariantResponse {
+	var responses []dto.FileVariantResponse
+	for _, variant := range variants {
+		url, ok := urls[variant.Name]
+		if !ok {
+			continue
+		}
+		responses = append(responses, dto.FileVariantResponse{
+			Name:        variant.Name,
+			ContentType: variant.ContentType,
+			Width:       variant.Width,
+			Height:      variant.Height,
+			Size:        variant.Size,
+			URL:         url.URL,
+			ExpiresAt:   timestamp.New(url.ExpiresAt),
+		})
+	}
+	return responses
+}
+
+// ToUploadResponse converts a file, the URL its content is uploaded to, nil
+// once uploaded, and the URLs of its variants to FileUploadResponse DTO
+func (a *FileAssembler) ToUploadResponse(file *model.File, upload *service.PresignedURL, variants map[string]*service.PresignedURL) *dto.FileUploadResponse {
+	resp := &dto.FileUploadResponse{
+		File:     *a.ToResponse(file),
+		Variants: a.ToVariantResponseList(file.Variants, variants),
+	}
+	if upload != nil {
+		resp.Upload = a.ToURLResponse(upload)
+	}
+	return resp
+}
+
+// ToDownloadResponse converts a file, its download URL and the URLs of its
+// variants to FileDownloadResponse DTO
+func (a *FileAssembler) ToDownloadResponse(file *model.File, download *service.PresignedURL, variants map[string]*service.PresignedURL) *dto.FileDownloadResponse {
+	return &dto.FileDownloadResponse{
+		File:     *a.ToResponse(file),
+		Download: *a.ToURLResponse(download),
+		Variants: a.ToVariantResponseList(file.Variants, variants),
+	}
+}
+
+// ToUploadSessionResponse converts an upload session, its file and the maximum
+// chunk size to UploadSessionResponse DTO
+func (a *FileAssembler) ToUploadSessionResponse(file *model.File, session *model.UploadSession, maxChunkSize int64) *dto.UploadSessionResponse {
+	return &dto.UploadSessionResponse{
+		ID:           idgen.Expose(session.ID),
+		PublicID:     session.PublicID,
+		File:         *a.ToResponse(file),
+		Offset:       session.Offset,
+		Size:         file.Size,
+		MaxChunkSize: maxChunkSize,
+		ExpiresAt:    timestamp.New(session.ExpiresAt),
+	}
+}
@@ -0,0 +1,58 @@
+package v1
+
+import (
+	"time"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// InvitationAssembler handles conversion between invitation models and DTOs
+type InvitationAssembler struct{}
+
+// NewInvitationAssembler creates a new InvitationAssembler instance
+func NewInvitationAssembler() *InvitationAssembler {
+	return &InvitationAssembler{}
+}
+
+// ToModel converts CreateInvitationRequest DTO to domain model
+func (a *InvitationAssembler) ToModel(req *dto.CreateInvitationRequest) *model.Invitation {
+	return &model.Invitation{
+		Email:       req.Email,
+		Role:        req.Role,
+		Permissions: req.Permissions,
+		OrgID:       uint(req.OrgID),
+		OrgRole:     req.OrgRole,
+	}
+}
+
+// ToResponse converts domain model to InvitationResponse DTO with its status at now
+func (a *InvitationAssembler) ToResponse(invitation *model.Invitation, now time.Time) *dto.InvitationResponse {
+	return &dto.InvitationResponse{
+		ID:          idgen.Expose(invitation.ID),
+		PublicID:    invitation.PublicID,
+		Email:       invitation.Email,
+		Role:        invitation.Role,
+		Permissions: invitation.Permissions,
+		OrgID:       idgen.Expose(invitation.OrgID),
+		OrgRole:     invitation.OrgRole,
+		InvitedBy:   invitation.InvitedBy,
+		Status:      invitation.StatusAt(now),
+		ExpiresAt:   timestamp.New(invitation.ExpiresAt),
+		AcceptedBy:  invitation.AcceptedBy,
+		AcceptedAt:  timestamp.Ptr(invitation.AcceptedAt),
+		RevokedAt:   timestamp.Ptr(invitation.RevokedAt),
+		CreatedAt:   invitation.CreatedAt,
+	}
+}
+
+// ToResponseList converts domain models to InvitationResponse DTOs
+func (a *InvitationAssembler) ToResponseList(invitations []*model.Invitation, now time.Time) []dto.InvitationResponse {
+	responses := make([]dto.InvitationResponse, len(invitations))
+	for i, invitation := range invitations {
+		responses[i] = *a.ToResponse(invitation, now)
+	}
+	return responses
+}
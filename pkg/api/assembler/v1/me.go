@@ -0,0 +1,61 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// MeAssembler handles conversion of the current principal to DTOs
+type MeAssembler struct{}
+
+// NewMeAssembler creates a new MeAssembler instance
+func NewMeAssembler() *MeAssembler {
+	return &MeAssembler{}
+}
+
+// ToResponse converts the current principal to MeResponse DTO
+func (a *MeAssembler) ToResponse(user principal.Principal) *dto.MeResponse {
+	return &dto.MeResponse{
+		UserID:         user.UserID,
+		Username:       user.Username,
+		Role:           user.Role,
+		Permissions:    nonNilStrings(user.Permissions),
+		SessionID:      user.SessionID,
+		Impersonated:   user.Impersonated(),
+		ImpersonatorID: user.ImpersonatorID,
+	}
+}
+
+// ToPermissionsResponse converts the current principal to MePermissionsResponse DTO
+func (a *MeAssembler) ToPermissionsResponse(user principal.Principal) *dto.MePermissionsResponse {
+	return &dto.MePermissionsResponse{
+		Role:        user.Role,
+		Permissions: nonNilStrings(user.Permissions),
+	}
+}
+
+// ToPreferencesResponse converts the effective preferences of the current user
+// and their channel preferences to MePreferencesResponse DTO
+func (a *MeAssembler) ToPreferencesResponse(prefs *model.UserPreferences, notifications []dto.NotificationPreferenceResponse) *dto.MePreferencesResponse {
+	return &dto.MePreferencesResponse{
+		Language:             prefs.Language,
+		TimeZone:             prefs.TimeZone,
+		NotificationChannels: nonNilStrings(prefs.NotificationChannels),
+		Notifications:        notifications,
+	}
+}
+
+// ToPreferencesPatch converts UpdateMyPreferencesRequest DTO to a patch. Fields
+// absent from the request are nil and leave the preferences unchanged.
+func (a *MeAssembler) ToPreferencesPatch(req *dto.UpdateMyPreferencesRequest) *model.UserPreferencesPatch {
+	patch := &model.UserPreferencesPatch{
+		Language: req.Language,
+		TimeZone: req.TimeZone,
+	}
+	if req.NotificationChannels != nil {
+		channels := model.StringList(*req.NotificationChannels)
+		patch.NotificationChannels = &channels
+	}
+	return patch
+}
@@ -0,0 +1,64 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// NotificationAssembler handles conversion between notification models and DTOs
+type NotificationAssembler struct{}
+
+// NewNotificationAssembler creates a new NotificationAssembler instance
+func NewNotificationAssembler() *NotificationAssembler {
+	return &NotificationAssembler{}
+}
+
+// ToModel converts SetNotificationPreferenceRequest DTO to domain model; preferences are enabled unless disabled explicitly
+func (a *NotificationAssembler) ToModel(userID, channel string, req *dto.SetNotificationPreferenceRequest) *model.NotificationPreference {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &model.NotificationPreference{
+		UserID:   userID,
+		Channel:  channel,
+		Address:  req.Address,
+		Enabled:  enabled,
+		Language: req.Language,
+	}
+}
+
+// ToResponse converts domain model to NotificationPreferenceResponse DTO
+func (a *NotificationAssembler) ToResponse(pref *model.NotificationPreference) *dto.NotificationPreferenceResponse {
+	return &dto.NotificationPreferenceResponse{
+		Channel:   pref.Channel,
+		Address:   pref.Address,
+		Enabled:   pref.Enabled,
+		Language:  pref.Language,
+		CreatedAt: pref.CreatedAt,
+		UpdatedAt: pref.UpdatedAt,
+	}
+}
+
+// ToResponseList converts slice of domain models to NotificationPreferenceResponse DTOs
+func (a *NotificationAssembler) ToResponseList(prefs []*model.NotificationPreference) []dto.NotificationPreferenceResponse {
+	responses := make([]dto.NotificationPreferenceResponse, len(prefs))
+	for i, pref := range prefs {
+		responses[i] = *a.ToResponse(pref)
+	}
+	return responses
+}
+
+// ToDeliveryResponseList converts notification deliveries to NotificationDeliveryResponse DTOs
+func (a *NotificationAssembler) ToDeliveryResponseList(deliveries []service.NotificationDelivery) []dto.NotificationDeliveryResponse {
+	responses := make([]dto.NotificationDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		responses[i] = dto.NotificationDeliveryResponse{
+			Channel: delivery.Channel,
+			Status:  delivery.Status,
+			Error:   delivery.Error,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"encoding/json"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// OperationAssembler handles conversion between operation models and DTOs
+type OperationAssembler struct{}
+
+// NewOperationAssembler creates a new OperationAssembler instance
+func NewOperationAssembler() *OperationAssembler {
+	return &OperationAssembler{}
+}
+
+// ToResponse converts domain model to OperationResponse DTO
+func (a *OperationAssembler) ToResponse(op *model.Operation) *dto.OperationResponse {
+	return &dto.OperationResponse{
+		ID:          op.OperationID,
+		Type:        op.Type,
+		Status:      op.Status,
+		Progress:    op.Progress,
+		Message:     op.Message,
+		Result:      json.RawMessage(op.Result),
+		Error:       op.Error,
+		Actor:       op.Actor,
+		CreatedAt:   op.CreatedAt,
+		UpdatedAt:   op.UpdatedAt,
+		CompletedAt: timestamp.Ptr(op.CompletedAt),
+	}
+}
+
+// ToResponseList converts slice of domain models to OperationResponse DTOs
+func (a *OperationAssembler) ToResponseList(ops []*model.Operation) []dto.OperationResponse {
+	responses := make([]dto.OperationResponse, len(ops))
+	for i, op := range ops {
+		responses[i] = *a.ToResponse(op)
+	}
+	return responses
+}
+
+// ToSnapshotEvent converts the stored state of an operation to an event
+// without a sequence number, for operations whose events are no longer kept
+func (a *OperationAssembler) ToSnapshotEvent(op *model.Operation) *dto.OperationEventResponse {
+	message := op.Message
+	if op.Status == model.OperationStatusFailed {
+		message = op.Error
+	}
+	return &dto.OperationEventResponse{
+		OperationID: op.OperationID,
+		Type:        op.Type,
+		Status:      op.Status,
+		Progress:    op.Progress,
+		Message:     message,
+		Timestamp:   op.UpdatedAt,
+	}
+}
+
+// ToEventResponse converts an operation event to OperationEventResponse DTO
+func (a *OperationAssembler) ToEventResponse(e *model.OperationEvent) *dto.OperationEventResponse {
+	return &dto.OperationEventResponse{
+		OperationID: e.OperationID,
+		Sequence:    e.Sequence,
+		Type:        e.Type,
+		Status:      e.Status,
+		Progress:    e.Progress,
+		Message:     e.Message,
+		Timestamp:   timestamp.New(e.Timestamp),
+	}
+}
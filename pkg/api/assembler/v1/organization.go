@@ -0,0 +1,85 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+)
+
+// OrganizationAssembler handles conversion between organization models and DTOs
+type OrganizationAssembler struct{}
+
+// NewOrganizationAssembler creates a new OrganizationAssembler instance
+func NewOrganizationAssembler() *OrganizationAssembler {
+	return &OrganizationAssembler{}
+}
+
+// ToModel converts CreateOrganizationRequest DTO to domain model
+func (a *OrganizationAssembler) ToModel(req *dto.CreateOrganizationRequest) *model.Organization {
+	return &model.Organization{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+// ToUpdateModel converts UpdateOrganizationRequest DTO to domain model
+func (a *OrganizationAssembler) ToUpdateModel(id uint, req *dto.UpdateOrganizationRequest) *model.Organization {
+	org := &model.Organization{
+		Name:        req.Name,
+		Description: req.Description,
+	}
+	org.ID = id
+	return org
+}
+
+// ToResponse converts domain model to OrganizationResponse DTO with the role of the current user
+func (a *OrganizationAssembler) ToResponse(org *model.Organization, role string) *dto.OrganizationResponse {
+	return &dto.OrganizationResponse{
+		ID:          idgen.Expose(org.ID),
+		PublicID:    org.PublicID,
+		Name:        org.Name,
+		Description: org.Description,
+		Role:        role,
+		CreatedAt:   org.CreatedAt,
+		UpdatedAt:   org.UpdatedAt,
+	}
+}
+
+// ToResponseList converts the organizations of a user and the memberships
+// returned with them to OrganizationResponse DTOs
+func (a *OrganizationAssembler) ToResponseList(orgs []*model.Organization, memberships []*model.OrganizationMember) []dto.OrganizationResponse {
+	responses := make([]dto.OrganizationResponse, len(orgs))
+	for i, org := range orgs {
+		responses[i] = *a.ToResponse(org, memberships[i].Role)
+	}
+	return responses
+}
+
+// ToMemberModel converts AddOrganizationMemberRequest DTO to domain model
+func (a *OrganizationAssembler) ToMemberModel(orgID uint, req *dto.AddOrganizationMemberRequest) *model.OrganizationMember {
+	return &model.OrganizationMember{
+		OrgID:  orgID,
+		UserID: req.UserID,
+		Role:   req.Role,
+	}
+}
+
+// ToMemberResponse converts domain model to OrganizationMemberResponse DTO
+func (a *OrganizationAssembler) ToMemberResponse(member *model.OrganizationMember) *dto.OrganizationMemberResponse {
+	return &dto.OrganizationMemberResponse{
+		OrgID:     idgen.Expose(member.OrgID),
+		UserID:    member.UserID,
+		Role:      member.Role,
+		CreatedAt: member.CreatedAt,
+		UpdatedAt: member.UpdatedAt,
+	}
+}
+
+// ToMemberResponseList converts domain models to OrganizationMemberResponse DTOs
+func (a *OrganizationAssembler) ToMemberResponseList(members []*model.OrganizationMember) []dto.OrganizationMemberResponse {
+	responses := make([]dto.OrganizationMemberResponse, len(members))
+	for i, member := range members {
+		responses[i] = *a.ToMemberResponse(member)
+	}
+	return responses
+}
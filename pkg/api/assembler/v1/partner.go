@@ -0,0 +1,67 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// PartnerAssembler handles conversion between partner models and DTOs
+type PartnerAssembler struct{}
+
+// NewPartnerAssembler creates a new PartnerAssembler instance
+func NewPartnerAssembler() *PartnerAssembler {
+	return &PartnerAssembler{}
+}
+
+// ToModel converts CreatePartnerRequest DTO to domain model; partners are enabled unless stated otherwise
+func (a *PartnerAssembler) ToModel(req *dto.CreatePartnerRequest) *model.Partner {
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &model.Partner{
+		Name:        req.Name,
+		Description: req.Description,
+		Enabled:     enabled,
+	}
+}
+
+// ToUpdateModel converts UpdatePartnerRequest DTO to domain model
+func (a *PartnerAssembler) ToUpdateModel(name string, req *dto.UpdatePartnerRequest) *model.Partner {
+	return &model.Partner{
+		Name:        name,
+		Description: req.Description,
+		Enabled:     *req.Enabled,
+	}
+}
+
+// ToResponse converts domain model to PartnerResponse DTO
+func (a *PartnerAssembler) ToResponse(partner *model.Partner) *dto.PartnerResponse {
+	return &dto.PartnerResponse{
+		Name:                    partner.Name,
+		Description:             partner.Description,
+		Enabled:                 partner.Enabled,
+		SecretRotatedAt:         timestamp.Ptr(partner.SecretRotatedAt),
+		PreviousSecretExpiresAt: timestamp.Ptr(partner.PreviousSecretExpiresAt),
+		CreatedAt:               partner.CreatedAt,
+		UpdatedAt:               partner.UpdatedAt,
+	}
+}
+
+// ToSecretResponse converts domain model and its new plain text secret to PartnerSecretResponse DTO
+func (a *PartnerAssembler) ToSecretResponse(partner *model.Partner, secret string) *dto.PartnerSecretResponse {
+	return &dto.PartnerSecretResponse{
+		PartnerResponse: *a.ToResponse(partner),
+		Secret:          secret,
+	}
+}
+
+// ToResponseList converts slice of domain models to PartnerResponse DTOs
+func (a *PartnerAssembler) ToResponseList(partners []*model.Partner) []dto.PartnerResponse {
+	responses := make([]dto.PartnerResponse, len(partners))
+	for i, partner := range partners {
+		responses[i] = *a.ToResponse(partner)
+	}
+	return responses
+}
@@ -0,0 +1,82 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// PolicyAssembler handles conversion between policy models and DTOs
+type PolicyAssembler struct{}
+
+// NewPolicyAssembler creates a new PolicyAssembler instance
+func NewPolicyAssembler() *PolicyAssembler {
+	return &PolicyAssembler{}
+}
+
+// ToModel converts PublishPolicyRequest DTO to domain model
+func (a *PolicyAssembler) ToModel(name string, req *dto.PublishPolicyRequest) *model.PolicyDocument {
+	return &model.PolicyDocument{
+		Name:     name,
+		Version:  req.Version,
+		Language: req.Language,
+		Title:    req.Title,
+		Content:  req.Content,
+		Summary:  req.Summary,
+	}
+}
+
+// ToConsentModel converts AcceptPolicyRequest DTO to domain model, truncating the user agent to the column size
+func (a *PolicyAssembler) ToConsentModel(userID, name, lang, ip, userAgent string, req *dto.AcceptPolicyRequest) *model.PolicyConsent {
+	if len(userAgent) > model.MaxPolicyUserAgentLength {
+		userAgent = userAgent[:model.MaxPolicyUserAgentLength]
+	}
+	return &model.PolicyConsent{
+		UserID:    userID,
+		Policy:    name,
+		Version:   req.Version,
+		Language:  lang,
+		IPAddress: ip,
+		UserAgent: userAgent,
+	}
+}
+
+// ToResponse converts domain model to PolicyResponse DTO
+func (a *PolicyAssembler) ToResponse(doc *model.PolicyDocument) *dto.PolicyResponse {
+	return &dto.PolicyResponse{
+		Name:        doc.Name,
+		Version:     doc.Version,
+		Language:    doc.Language,
+		Title:       doc.Title,
+		Content:     doc.Content,
+		Summary:     doc.Summary,
+		PublishedAt: doc.CreatedAt,
+	}
+}
+
+// ToResponseList converts slice of domain models to PolicyResponse DTOs
+func (a *PolicyAssembler) ToResponseList(docs []*model.PolicyDocument) []dto.PolicyResponse {
+	responses := make([]dto.PolicyResponse, len(docs))
+	for i, doc := range docs {
+		responses[i] = *a.ToResponse(doc)
+	}
+	return responses
+}
+
+// ToConsentResponse converts domain model to PolicyConsentResponse DTO
+func (a *PolicyAssembler) ToConsentResponse(consent *model.PolicyConsent) *dto.PolicyConsentResponse {
+	return &dto.PolicyConsentResponse{
+		Policy:     consent.Policy,
+		Version:    consent.Version,
+		Language:   consent.Language,
+		AcceptedAt: consent.CreatedAt,
+	}
+}
+
+// ToConsentResponseList converts slice of domain models to PolicyConsentResponse DTOs
+func (a *PolicyAssembler) ToConsentResponseList(consents []*model.PolicyConsent) []dto.PolicyConsentResponse {
+	responses := make([]dto.PolicyConsentResponse, len(consents))
+	for i, consent := range consents {
+		responses[i] = *a.ToConsentResponse(consent)
+	}
+	return responses
+}
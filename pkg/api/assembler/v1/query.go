@@ -0,0 +1,23 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// toTimeRange converts a TimeRange query parameter to the domain time range
+func toTimeRange(r dto.TimeRange) model.TimeRange {
+	return model.TimeRange{From: r.From, To: r.To}
+}
+
+// toSortOrders converts a sort expression to domain sort orders
+func toSortOrders(e dto.SortExpression) []model.SortOrder {
+	if len(e) == 0 {
+		return nil
+	}
+	orders := make([]model.SortOrder, len(e))
+	for i, f := range e {
+		orders[i] = model.SortOrder{Field: f.Field, Desc: f.Desc}
+	}
+	return orders
+}
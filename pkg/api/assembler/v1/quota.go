@@ -0,0 +1,38 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// QuotaAssembler handles conversion of quota usage to DTOs
+type QuotaAssembler struct{}
+
+// NewQuotaAssembler creates a new QuotaAssembler instance
+func NewQuotaAssembler() *QuotaAssembler {
+	return &QuotaAssembler{}
+}
+
+// ToUsageResponse converts the usage of a principal to QuotaUsageResponse DTO
+func (a *QuotaAssembler) ToUsageResponse(principal string, usages []quota.Usage) *dto.QuotaUsageResponse {
+	resp := &dto.QuotaUsageResponse{
+		Principal: principal,
+		Classes:   make([]dto.QuotaClassUsageResponse, len(usages)),
+	}
+
+	for i, usage := range usages {
+		windows := make([]dto.QuotaWindowResponse, len(usage.Windows))
+		for j, window := range usage.Windows {
+			windows[j] = dto.QuotaWindowResponse{
+				Period:    window.Period,
+				Limit:     window.Limit,
+				Used:      window.Used,
+				Remaining: window.Remaining(),
+				ResetAt:   timestamp.New(window.Reset),
+			}
+		}
+		resp.Classes[i] = dto.QuotaClassUsageResponse{Class: usage.Class, Windows: windows}
+	}
+	return resp
+}
@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"time"
+
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// SessionAssembler handles conversion between session models and DTOs
+type SessionAssembler struct{}
+
+// NewSessionAssembler creates a new SessionAssembler instance
+func NewSessionAssembler() *SessionAssembler {
+	return &SessionAssembler{}
+}
+
+// ToResponse converts domain model to SessionResponse DTO; current is the session of the request
+func (a *SessionAssembler) ToResponse(session *model.Session, current uint) *dto.SessionResponse {
+	return &dto.SessionResponse{
+		ID:         idgen.Expose(session.ID),
+		PublicID:   session.PublicID,
+		UserAgent:  session.UserAgent,
+		IP:         session.IP,
+		Current:    session.ID == current,
+		CreatedAt:  session.CreatedAt,
+		LastSeenAt: timestamp.New(session.LastSeenAt),
+		ExpiresAt:  timestamp.New(session.ExpiresAt),
+	}
+}
+
+// ToResponseList converts slice of domain models to SessionResponse DTOs
+func (a *SessionAssembler) ToResponseList(sessions []*model.Session, current uint) []dto.SessionResponse {
+	responses := make([]dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		responses[i] = *a.ToResponse(session, current)
+	}
+	return responses
+}
+
+// ToTokensResponse converts a session and its new tokens to SessionTokensResponse DTO
+func (a *SessionAssembler) ToTokensResponse(session *model.Session, accessToken string, accessExpiresAt time.Time, refreshToken string) *dto.SessionTokensResponse {
+	return &dto.SessionTokensResponse{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  timestamp.New(accessExpiresAt),
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: timestamp.New(session.ExpiresAt),
+		SessionID:             idgen.Expose(session.ID),
+		SessionPublicID:       session.PublicID,
+	}
+}
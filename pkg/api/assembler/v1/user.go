@@ -0,0 +1,40 @@
+package v1
+
+import (
+	dto "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+)
+
+// UserAssembler handles conversion between user models and DTOs
+type UserAssembler struct{}
+
+// NewUserAssembler creates a new UserAssembler instance
+func NewUserAssembler() *UserAssembler {
+	return &UserAssembler{}
+}
+
+// ToRegistration converts RegisterRequest DTO to a registration
+func (a *UserAssembler) ToRegistration(req *dto.RegisterRequest) *service.Registration {
+	return &service.Registration{
+		Email:           req.Email,
+		Username:        req.Username,
+		Password:        req.Password,
+		InvitationToken: req.InvitationToken,
+	}
+}
+
+// ToResponse converts domain model to UserResponse DTO
+func (a *UserAssembler) ToResponse(user *model.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID:            user.UserID(),
+		Email:         user.Email,
+		Username:      user.Username,
+		Role:          user.Role,
+		Permissions:   user.Permissions,
+		InvitationID:  idgen.Expose(user.InvitationID),
+		EmailVerified: user.Verified(),
+		CreatedAt:     user.CreatedAt,
+	}
+}
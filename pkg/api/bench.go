@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// benchAPI 开发环境的基准测试接口，经过与业务接口相同的中间件链，
+// 用于验证基础设施容量；未启用或生产环境时不注册路由
+type benchAPI struct {
+	Config    *config.Config               `inject:"config"`
+	Datastore datastore.DatastoreInterface `inject:"datastore"`
+	Cache     datastore.Cache              `inject:"cache"`
+	handler   *handler.BenchHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newBenchAPI())
+}
+
+// newBenchAPI 创建依赖注入版本的基准测试接口
+func newBenchAPI() APIInterface {
+	return &benchAPI{}
+}
+
+// InitAPIServiceRoute 初始化基准测试路由
+func (a *benchAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.Config == nil || !a.Config.Server.Bench.Enabled || a.Config.IsProduction() || a.Datastore == nil {
+		return
+	}
+	a.handler = handler.NewBenchHandler(a.Datastore, a.Cache, a.Config.Server.Bench)
+
+	benchGroup := rg.Group("/_bench")
+	benchGroup.POST("/datastore", a.handler.BenchDatastore)
+	benchGroup.POST("/json", a.handler.BenchJSON)
+	if a.Cache != nil {
+		benchGroup.POST("/cache", a.handler.BenchCache)
+	}
+}
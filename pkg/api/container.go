@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/container"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// containerAdmin 依赖注入容器诊断API结构
+type containerAdmin struct {
+	Inspector container.Inspector `inject:"container"`
+	handler   *handler.ContainerHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newContainerAdmin())
+}
+
+// newContainerAdmin 创建依赖注入版本的容器诊断API
+func newContainerAdmin() APIInterface {
+	return &containerAdmin{}
+}
+
+// Module 所属的管理模块，模块禁用时不注册路由
+func (a *containerAdmin) Module() string {
+	return module.Admin
+}
+
+// InitAPIServiceRoute 初始化容器诊断API路由（仅管理员）
+func (a *containerAdmin) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.Inspector == nil {
+		return
+	}
+	a.handler = handler.NewContainerHandler(a.Inspector)
+
+	adminGroup := rg.Group("/admin", middleware.RequireRole("admin"))
+	adminGroup.GET("/container", a.handler.DescribeContainer)
+}
@@ -0,0 +1,17 @@
+package api
+
+import _ "embed"
+
+// 由处理器的swag注释生成API文档，并重新生成 /_schema 发布的DTO类型列表，修改注释或文档类型后需重新生成
+//go:generate go run ../../cmd/gen types -root ../..
+//go:generate go run github.com/swaggo/swag/cmd/swag@v1.16.4 init --generalInfo router/router.go --dir ./,../utils/idgen,../utils/module --output ./docs --outputTypes json
+
+// swaggerSpec 生成的API文档
+//
+//go:embed docs/swagger.json
+var swaggerSpec []byte
+
+// SwaggerSpec 返回生成的API文档（Swagger 2.0，JSON格式）
+func SwaggerSpec() []byte {
+	return swaggerSpec
+}
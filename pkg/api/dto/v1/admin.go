@@ -0,0 +1,470 @@
+package v1
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// RecentErrorsRequest 最近错误查询参数
+// @Description 最近错误事件查询参数
+type RecentErrorsRequest struct {
+	// @Description 返回的事件数量，默认50
+	// @Example 50
+	Limit int `json:"limit" form:"limit" binding:"omitempty,min=1,max=1000" example:"50"`
+}
+
+// ProfileRequest 按需性能分析参数
+// @Description 性能分析的采集时长和类型
+type ProfileRequest struct {
+	// @Description CPU分析和执行跟踪的采集秒数，默认5
+	// @Example 10
+	Seconds int `json:"seconds" form:"seconds" binding:"omitempty,min=1" example:"10"`
+
+	// @Description 打包的分析类型，逗号分隔，默认 heap,goroutine,cpu
+	// @Example "cpu,heap,goroutine"
+	Profiles string `json:"profiles" form:"profiles" binding:"omitempty,max=100" example:"cpu,heap,goroutine"`
+}
+
+// DashboardResponse 管理面板响应
+// @Description 运行时、数据存储、缓存和最近错误的汇总数据
+type DashboardResponse struct {
+	// @Description 运行时统计
+	Runtime RuntimeStatsResponse `json:"runtime"`
+
+	// @Description 数据存储统计，数据存储不支持统计时为空
+	Datastore *DatastoreStatsResponse `json:"datastore,omitempty"`
+
+	// @Description 缓存统计，缓存不支持统计时为空
+	Cache *CacheStatsResponse `json:"cache,omitempty"`
+
+	// @Description 最近的错误事件
+	Errors RecentErrorsResponse `json:"errors"`
+
+	// @Description 数据采集时间
+	// @Example "2024-01-01T00:00:00Z"
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+}
+
+// RuntimeStatsResponse 运行时统计
+// @Description Go运行时的协程、内存和GC统计
+type RuntimeStatsResponse struct {
+	// @Description 协程数量
+	// @Example 24
+	Goroutines int `json:"goroutines" example:"24"`
+
+	// @Description CPU核数
+	// @Example 8
+	CPUs int `json:"cpus" example:"8"`
+
+	// @Description 已分配的堆内存字节数
+	// @Example 4194304
+	HeapAlloc uint64 `json:"heap_alloc" example:"4194304"`
+
+	// @Description 使用中的堆内存字节数
+	// @Example 6291456
+	HeapInuse uint64 `json:"heap_inuse" example:"6291456"`
+
+	// @Description 堆对象数量
+	// @Example 20480
+	HeapObjects uint64 `json:"heap_objects" example:"20480"`
+
+	// @Description 使用中的栈内存字节数
+	// @Example 1048576
+	StackInuse uint64 `json:"stack_inuse" example:"1048576"`
+
+	// @Description 从系统获取的内存字节数
+	// @Example 16777216
+	Sys uint64 `json:"sys" example:"16777216"`
+
+	// @Description 累计分配的内存字节数
+	// @Example 67108864
+	TotalAlloc uint64 `json:"total_alloc" example:"67108864"`
+
+	// @Description GC次数
+	// @Example 12
+	NumGC uint32 `json:"num_gc" example:"12"`
+
+	// @Description GC累计暂停时间
+	// @Example "1.2ms"
+	GCPauseTotal string `json:"gc_pause_total" example:"1.2ms"`
+
+	// @Description GC占用的CPU比例
+	// @Example 0.0001
+	GCCPUFraction float64 `json:"gc_cpu_fraction" example:"0.0001"`
+
+	// @Description 上次GC时间，尚未GC时为空
+	LastGC *timestamp.Time `json:"last_gc,omitempty"`
+
+	// @Description 下次GC的堆大小目标
+	// @Example 8388608
+	NextGC uint64 `json:"next_gc" example:"8388608"`
+}
+
+// DatastoreStatsResponse 数据存储统计
+// @Description 数据存储驱动、表行数和连接池统计
+type DatastoreStatsResponse struct {
+	// @Description 数据存储驱动
+	// @Example "postgresql"
+	Driver string `json:"driver" example:"postgresql"`
+
+	// @Description 各表行数，只有内存存储提供
+	Tables map[string]int `json:"tables,omitempty"`
+
+	// @Description 连接池统计，只有SQL数据库提供
+	Connections *ConnectionStatsResponse `json:"connections,omitempty"`
+
+	// @Description 按名称的其他数据存储统计，只在配置了 database.datastores 时提供
+	Datastores map[string]*DatastoreStatsResponse `json:"datastores,omitempty"`
+}
+
+// ConnectionStatsResponse 连接池统计
+// @Description SQL数据库连接池的使用情况
+type ConnectionStatsResponse struct {
+	// @Description 最大打开连接数，0表示不限制
+	// @Example 100
+	MaxOpen int `json:"max_open" example:"100"`
+
+	// @Description 打开的连接数
+	// @Example 5
+	Open int `json:"open" example:"5"`
+
+	// @Description 使用中的连接数
+	// @Example 2
+	InUse int `json:"in_use" example:"2"`
+
+	// @Description 空闲连接数
+	// @Example 3
+	Idle int `json:"idle" example:"3"`
+
+	// @Description 等待连接的累计次数
+	// @Example 0
+	WaitCount int64 `json:"wait_count" example:"0"`
+
+	// @Description 等待连接的累计时间
+	// @Example "0s"
+	WaitDuration string `json:"wait_duration" example:"0s"`
+
+	// @Description 因超过最大空闲数关闭的连接数
+	// @Example 0
+	MaxIdleClosed int64 `json:"max_idle_closed" example:"0"`
+
+	// @Description 因超过最大存活时间关闭的连接数
+	// @Example 0
+	MaxLifetimeClosed int64 `json:"max_lifetime_closed" example:"0"`
+}
+
+// CacheStatsResponse 缓存统计
+// @Description 缓存命中情况和条目数量
+type CacheStatsResponse struct {
+	// @Description 命中次数
+	// @Example 900
+	Hits uint64 `json:"hits" example:"900"`
+
+	// @Description 未命中次数
+	// @Example 100
+	Misses uint64 `json:"misses" example:"100"`
+
+	// @Description 命中率，0-1
+	// @Example 0.9
+	HitRate float64 `json:"hit_rate" example:"0.9"`
+
+	// @Description 缓存条目数
+	// @Example 42
+	Items int `json:"items" example:"42"`
+}
+
+// RecentErrorsResponse 最近错误响应
+// @Description 内存中保留的最近错误事件，按时间倒序
+type RecentErrorsResponse struct {
+	// @Description 启动以来上报的错误总数
+	// @Example 3
+	Total uint64 `json:"total" example:"3"`
+
+	// @Description 错误事件列表
+	Events []ErrorEventResponse `json:"events"`
+}
+
+// ErrorEventResponse 错误事件
+// @Description 单个错误事件及请求信息
+type ErrorEventResponse struct {
+	// @Description 级别：error 或 fatal
+	// @Example "error"
+	Level string `json:"level" example:"error"`
+
+	// @Description 错误消息
+	// @Example "failed to connect to database"
+	Message string `json:"message" example:"failed to connect to database"`
+
+	// @Description 是否由panic产生
+	// @Example false
+	Panic bool `json:"panic" example:"false"`
+
+	// @Description 请求ID
+	// @Example "req_123456789"
+	RequestID string `json:"request_id,omitempty" example:"req_123456789"`
+
+	// @Description 用户ID
+	// @Example "1001"
+	UserID string `json:"user_id,omitempty" example:"1001"`
+
+	// @Description 请求方法
+	// @Example "GET"
+	Method string `json:"method,omitempty" example:"GET"`
+
+	// @Description 路由
+	// @Example "/api/v1/applications/:id"
+	Route string `json:"route,omitempty" example:"/api/v1/applications/:id"`
+
+	// @Description HTTP状态码
+	// @Example 500
+	StatusCode int `json:"status_code,omitempty" example:"500"`
+
+	// @Description 调用栈，只有panic时提供
+	StackTrace string `json:"stack_trace,omitempty"`
+
+	// @Description 发生时间
+	// @Example "2024-01-01T00:00:00Z"
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+}
+
+// AnalyticsQueryRequest 分析数据查询参数
+// @Description 访问日志和审计事件的查询条件
+type AnalyticsQueryRequest struct {
+	// @Description 数据类型：access（访问日志）或 audit（审计事件），为空时查询全部
+	// @Example "access"
+	Kind string `json:"kind" form:"kind" binding:"omitempty,oneof=access audit" example:"access"`
+
+	// @Description 用户ID，为空时不限制
+	// @Example "1001"
+	UserID string `json:"user_id" form:"user_id" binding:"omitempty,max=100" example:"1001"`
+
+	// @Description 代为操作的管理员用户ID，为空时不限制
+	// @Example "1"
+	ImpersonatorID string `json:"impersonator_id" form:"impersonator_id" binding:"omitempty,max=100" example:"1"`
+
+	// @Description 起始时间（RFC3339），为空时不限制
+	// @Example "2024-01-01T00:00:00Z"
+	Since *time.Time `json:"since" form:"since" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+
+	// @Description 返回的记录数量，默认100
+	// @Example 100
+	Limit int `json:"limit" form:"limit" binding:"omitempty,min=1,max=1000" example:"100"`
+}
+
+// AnalyticsEntriesResponse 分析数据响应
+// @Description 最近的访问日志和审计事件，按时间倒序
+type AnalyticsEntriesResponse struct {
+	// @Description 记录列表
+	Entries []AnalyticsEntryResponse `json:"entries"`
+}
+
+// AnalyticsEntryResponse 分析数据记录
+// @Description 单条访问日志或审计事件
+type AnalyticsEntryResponse struct {
+	// @Description 数据类型：access 或 audit
+	// @Example "access"
+	Kind string `json:"kind" example:"access"`
+
+	// @Description 发生时间
+	// @Example "2024-01-01T00:00:00Z"
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+
+	// @Description 请求ID
+	// @Example "req_123456789"
+	RequestID string `json:"request_id,omitempty" example:"req_123456789"`
+
+	// @Description 用户ID
+	// @Example "1001"
+	UserID string `json:"user_id,omitempty" example:"1001"`
+
+	// @Description 以模拟令牌代为操作的管理员用户ID
+	// @Example "1"
+	ImpersonatorID string `json:"impersonator_id,omitempty" example:"1"`
+
+	// @Description 审计事件类型
+	// @Example "application.created"
+	Action string `json:"action,omitempty" example:"application.created"`
+
+	// @Description 请求方法
+	// @Example "GET"
+	Method string `json:"method,omitempty" example:"GET"`
+
+	// @Description 请求路径
+	// @Example "/api/v1/applications/1"
+	Path string `json:"path,omitempty" example:"/api/v1/applications/1"`
+
+	// @Description 路由
+	// @Example "/api/v1/applications/:id"
+	Route string `json:"route,omitempty" example:"/api/v1/applications/:id"`
+
+	// @Description HTTP状态码
+	// @Example 200
+	Status int `json:"status,omitempty" example:"200"`
+
+	// @Description 请求耗时
+	// @Example "12.5ms"
+	Duration string `json:"duration,omitempty" example:"12.5ms"`
+
+	// @Description 客户端IP
+	// @Example "192.168.1.10"
+	ClientIP string `json:"client_ip,omitempty" example:"192.168.1.10"`
+
+	// @Description 客户端User-Agent
+	// @Example "curl/8.0.1"
+	UserAgent string `json:"user_agent,omitempty" example:"curl/8.0.1"`
+
+	// @Description 审计事件内容（JSON）
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+}
+
+// DatastoreHistoryRequest 数据存储统计历史查询参数
+// @Description 按操作和表查询数据存储耗时历史
+type DatastoreHistoryRequest struct {
+	// @Description 操作：create、query、update、delete、row、raw，为空时查询全部
+	// @Example "query"
+	Operation string `json:"operation" form:"operation" binding:"omitempty,max=50" example:"query"`
+
+	// @Description 表名，为空时查询全部
+	// @Example "applications"
+	Table string `json:"table" form:"table" binding:"omitempty,max=255" example:"applications"`
+
+	// @Description 起始时间（RFC3339），默认为原始样本的保留时长之前
+	// @Example "2024-01-01T00:00:00Z"
+	Since *time.Time `json:"since" form:"since" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-01T00:00:00Z"`
+
+	// @Description 结束时间（RFC3339），为空时不限制
+	// @Example "2024-01-02T00:00:00Z"
+	Until *time.Time `json:"until" form:"until" time_format:"2006-01-02T15:04:05Z07:00" example:"2024-01-02T00:00:00Z"`
+
+	// @Description 精度：raw（采样间隔）或 1h（按小时汇总），为空时起始时间早于原始样本保留时长则按小时汇总
+	// @Example "raw"
+	Resolution string `json:"resolution" form:"resolution" binding:"omitempty,oneof=raw 1h" example:"raw"`
+}
+
+// DatastoreHistoryResponse 数据存储统计历史响应
+// @Description 各操作的耗时历史，适合绘制图表
+type DatastoreHistoryResponse struct {
+	// @Description 精度：raw 或 1h
+	// @Example "raw"
+	Resolution string `json:"resolution" example:"raw"`
+
+	// @Description 按操作和表排序的序列
+	Series []DatastoreSeriesResponse `json:"series"`
+}
+
+// DatastoreSeriesResponse 数据存储统计序列
+// @Description 单个操作在单个表上的耗时历史
+type DatastoreSeriesResponse struct {
+	// @Description 操作
+	// @Example "query"
+	Operation string `json:"operation" example:"query"`
+
+	// @Description 表名
+	// @Example "applications"
+	Table string `json:"table" example:"applications"`
+
+	// @Description 按时间排序的数据点
+	Points []DatastorePointResponse `json:"points"`
+}
+
+// DatastorePointResponse 数据存储统计数据点
+// @Description 一个时间段内的执行次数、耗时和错误数，多个实例的数据已合并
+type DatastorePointResponse struct {
+	// @Description 时间段的开始时间
+	// @Example "2024-01-01T00:00:00Z"
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+
+	// @Description 执行次数
+	// @Example 120
+	Count int64 `json:"count" example:"120"`
+
+	// @Description 平均耗时（毫秒）
+	// @Example 1.5
+	AverageMs float64 `json:"avg_ms" example:"1.5"`
+
+	// @Description 最大耗时（毫秒）
+	// @Example 12.3
+	MaxMs float64 `json:"max_ms" example:"12.3"`
+
+	// @Description 错误数
+	// @Example 0
+	Errors int64 `json:"errors" example:"0"`
+}
+
+// RetentionPreviewResponse 数据保留预览
+// @Description 各保留策略当前过期、下次运行将删除的记录数，不删除任何数据
+type RetentionPreviewResponse struct {
+	// @Description 是否按计划定期删除
+	// @Example true
+	Enabled bool `json:"enabled" example:"true"`
+
+	// @Description 两次运行的间隔
+	// @Example "1h0m0s"
+	Interval string `json:"interval" example:"1h0m0s"`
+
+	// @Description 有对应记录类型的保留策略，按名称排列
+	Policies []RetentionPolicyResponse `json:"policies"`
+}
+
+// RetentionPolicyResponse 保留策略的过期记录
+// @Description 早于截止时间的记录数
+type RetentionPolicyResponse struct {
+	// @Description 策略名称：audit_logs、applications 或 operations
+	// @Example "operations"
+	Policy string `json:"policy" example:"operations"`
+
+	// @Description 保留时长
+	// @Example "168h0m0s"
+	Retention string `json:"retention" example:"168h0m0s"`
+
+	// @Description 截止时间，早于该时间的记录已过期
+	// @Example "2024-01-01T00:00:00Z"
+	Cutoff timestamp.Time `json:"cutoff" example:"2024-01-01T00:00:00Z"`
+
+	// @Description 过期的记录数
+	// @Example 42
+	Expired int64 `json:"expired" example:"42"`
+
+	// @Description 统计失败的原因
+	Error string `json:"error,omitempty"`
+}
+
+// NetworkACLRuleRequest 网络访问控制规则
+// @Description 按客户端IP和国家允许或拒绝请求；拒绝优先，设置任一允许项后其余客户端被拒绝
+type NetworkACLRuleRequest struct {
+	// @Description 分组的路径前缀，全局规则忽略
+	// @Example ["/api/v1/admin"]
+	Paths []string `json:"paths" binding:"omitempty,max=100,dive,startswith=/" example:"/api/v1/admin"`
+
+	// @Description 允许的IP或CIDR
+	// @Example ["10.0.0.0/8"]
+	Allow []string `json:"allow" binding:"omitempty,max=1000,dive,ip|cidr" example:"10.0.0.0/8"`
+
+	// @Description 拒绝的IP或CIDR
+	// @Example ["203.0.113.7"]
+	Deny []string `json:"deny" binding:"omitempty,max=1000,dive,ip|cidr" example:"203.0.113.7"`
+
+	// @Description 允许的国家，ISO 3166-1 alpha-2代码，需配置GeoIP数据库
+	// @Example ["DE"]
+	AllowCountries []string `json:"allow_countries" binding:"omitempty,max=300,dive,len=2" example:"DE"`
+
+	// @Description 拒绝的国家，ISO 3166-1 alpha-2代码，需配置GeoIP数据库
+	// @Example ["KP"]
+	DenyCountries []string `json:"deny_countries" binding:"omitempty,max=300,dive,len=2" example:"KP"`
+}
+
+// NetworkACLResponse 网络访问控制规则
+// @Description 当前生效的全局规则和分组规则
+type NetworkACLResponse struct {
+	// @Description 是否可以使用国家规则
+	// @Example true
+	GeoIP bool `json:"geoip" example:"true"`
+
+	// @Description 全局规则，对所有请求生效
+	Global NetworkACLRuleRequest `json:"global"`
+
+	// @Description 分组规则，按名称，对其路径前缀下的请求生效
+	Groups map[string]NetworkACLRuleRequest `json:"groups"`
+}
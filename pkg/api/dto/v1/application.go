@@ -1,17 +1,37 @@
 package v1
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// 应用DTO的 lookup 标签引用的数据存储查询
+const (
+	// LookupApplication 按ID查询应用
+	LookupApplication = "application"
+	// LookupApplicationName 查询当前组织已使用的应用名称
+	LookupApplicationName = "application_name"
+)
 
 // CreateApplicationRequest 创建应用请求
 // @Description 创建应用的请求参数
 type CreateApplicationRequest struct {
 	// @Description 应用名称，1-100个字符
 	// @Example "示例应用"
-	Name string `json:"name" binding:"required,min=1,max=100,app_name" example:"示例应用"`
+	Name string `json:"name" binding:"required,min=1,max=100,app_name" sanitize:"trim,collapse,strip_control" lookup:"unique=application_name" example:"示例应用"`
 
 	// @Description 应用描述，最多500个字符
 	// @Example "这是一个示例应用"
-	Description string `json:"description" binding:"omitempty,max=500" example:"这是一个示例应用"`
+	Description string `json:"description" binding:"omitempty,max=500" sanitize:"trim,strip_control" example:"这是一个示例应用"`
+
+	// @Description 应用标签，标签为普通标签或key:value形式，最多50个
+	// @Example ["env:prod", "team:core"]
+	Tags []string `json:"tags" binding:"omitempty,max=50,dive,required,max=100" sanitize:"trim,strip_control" example:"env:prod,team:core"`
 }
 
 // UpdateApplicationRequest 更新应用请求
@@ -19,30 +39,144 @@ type CreateApplicationRequest struct {
 type UpdateApplicationRequest struct {
 	// @Description 应用名称，1-100个字符
 	// @Example "更新后的应用名称"
-	Name string `json:"name" binding:"omitempty,min=1,max=100,app_name" example:"更新后的应用名称"`
+	Name string `json:"name" binding:"omitempty,min=1,max=100,app_name" sanitize:"trim,collapse,strip_control" example:"更新后的应用名称"`
 
 	// @Description 应用描述，最多500个字符
 	// @Example "更新后的应用描述"
-	Description string `json:"description" binding:"omitempty,max=500" example:"更新后的应用描述"`
+	Description string `json:"description" binding:"omitempty,max=500" sanitize:"trim,strip_control" example:"更新后的应用描述"`
+
+	// @Description 应用标签，提供时替换全部标签，省略时保持不变
+	// @Example ["env:prod", "team:core"]
+	Tags []string `json:"tags" binding:"omitempty,max=50,dive,required,max=100" sanitize:"trim,strip_control" example:"env:prod,team:core"`
+}
+
+// PatchApplicationRequest 部分更新应用请求
+// @Description 按JSON Merge Patch (RFC 7396) 部分更新应用：省略的字段保持不变，null清空描述或标签
+type PatchApplicationRequest struct {
+	// @Description 应用名称，1-100个字符，不能为null
+	// @Example "更新后的应用名称"
+	Name *string `json:"name" binding:"omitnil,min=1,max=100,app_name" sanitize:"trim,collapse,strip_control" example:"更新后的应用名称"`
+
+	// @Description 应用描述，最多500个字符，空字符串或null清空描述
+	// @Example ""
+	Description *string `json:"description" binding:"omitnil,max=500" sanitize:"trim,strip_control" example:""`
+
+	// @Description 应用标签，提供时替换全部标签，空数组或null清空标签
+	// @Example ["env:prod", "team:core"]
+	Tags *[]string `json:"tags" binding:"omitnil,max=50,dive,required,max=100" sanitize:"trim,strip_control" example:"env:prod,team:core"`
+}
+
+// UnmarshalJSON 按合并补丁语义解析请求，字段为null时设为空值，未知字段返回错误
+func (r *PatchApplicationRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	var typeErr *json.UnmarshalTypeError
+	if err := json.Unmarshal(data, &fields); err != nil && !errors.As(err, &typeErr) {
+		return err
+	}
+	if fields == nil {
+		return errors.New("merge patch must be a JSON object")
+	}
+
+	*r = PatchApplicationRequest{}
+	for key, value := range fields {
+		null := bytes.Equal(bytes.TrimSpace(value), []byte("null"))
+		var err error
+		switch key {
+		case "name":
+			if null {
+				return errors.New("name cannot be removed")
+			}
+			err = json.Unmarshal(value, &r.Name)
+		case "description":
+			r.Description = new(string)
+			if !null {
+				err = json.Unmarshal(value, r.Description)
+			}
+		case "tags":
+			tags := []string{}
+			if !null {
+				err = json.Unmarshal(value, &tags)
+			}
+			r.Tags = &tags
+		default:
+			return fmt.Errorf("unknown field %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ApplicationTagsRequest 应用标签请求
+// @Description 添加应用标签的请求参数
+type ApplicationTagsRequest struct {
+	// @Description 标签列表，标签为普通标签或key:value形式
+	// @Example ["env:prod", "team:core"]
+	Tags []string `json:"tags" binding:"required,min=1,max=50,dive,required,max=100" example:"env:prod,team:core"`
 }
 
 // ListApplicationsRequest 应用列表请求
 // @Description 获取应用列表的请求参数
 type ListApplicationsRequest struct {
-	PageRequest
-	SearchRequest
+	// @Description 页码，从1开始
+	// @Example 1
+	Page int `json:"page" form:"page" binding:"omitempty,min=1" example:"1"`
+
+	// @Description 每页数量，1-100
+	// @Example 10
+	Size int `json:"size" form:"size" binding:"omitempty,min=1,max=100" example:"10"`
+
+	// @Description 应用ID，逗号分隔，最多100个
+	// @Example "1,2,3"
+	IDs IDList `json:"ids,omitempty" form:"ids" binding:"omitempty,max=100" swaggertype:"string" example:"1,2,3"`
 
-	// @Description 应用状态过滤
+	// @Description 应用状态，逗号分隔且匹配任意一个
 	// @Example "active"
-	Status string `json:"status" form:"status" binding:"omitempty,oneof=active inactive deleted" example:"active"`
+	Status EnumSet `json:"status,omitempty" form:"status" binding:"omitempty,dive,oneof=active inactive deleted" swaggertype:"string" example:"active"`
+
+	// @Description 标签选择器，逗号分隔且全部匹配；key:value匹配该标签，key匹配该键的任意值
+	// @Example "env:prod,team:core"
+	Tags string `json:"tags" form:"tags" binding:"omitempty,max=500" sanitize:"trim,strip_control" example:"env:prod,team:core"`
+
+	// @Description 创建时间范围，格式为 from,to
+	// @Example "2024-01-01,2024-01-31"
+	CreatedAt TimeRange `json:"created_at,omitempty" form:"created_at" swaggertype:"string" example:"2024-01-01,2024-01-31"`
+
+	// @Description 更新时间范围，格式为 from,to
+	// @Example "2024-01-01T00:00:00Z,"
+	UpdatedAt TimeRange `json:"updated_at,omitempty" form:"updated_at" swaggertype:"string" example:"2024-01-01T00:00:00Z,"`
+
+	// @Description 排序表达式，逗号分隔的 字段:asc|desc，最多3个字段
+	// @Example "name:asc,created_at:desc"
+	Sort SortExpression `json:"sort,omitempty" form:"sort" binding:"omitempty,max=3,sortable=id name created_at updated_at" swaggertype:"string" example:"name:asc,created_at:desc"`
+}
+
+// Pagination 返回设置了默认值的分页参数
+func (r *ListApplicationsRequest) Pagination() PageRequest {
+	page := PageRequest{Page: r.Page, Size: r.Size}
+	page.Validate()
+	return page
 }
 
 // ApplicationResponse 应用响应
 // @Description 应用详细信息
 type ApplicationResponse struct {
-	// @Description 应用ID
+	// @Description 应用ID，隐藏内部ID时省略
+	// @Example 1
+	ID idgen.ID `json:"id,omitempty" example:"1"`
+
+	// @Description 公开ID，可代替应用ID在路径中使用
+	// @Example "3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"
+	PublicID string `json:"public_id" example:"3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"`
+
+	// @Description 所属组织ID，未启用组织或隐藏内部ID时省略
 	// @Example 1
-	ID uint `json:"id" example:"1"`
+	OrgID idgen.ID `json:"org_id,omitempty" example:"1"`
+
+	// @Description 所有者用户ID，由创建者成为所有者；系统创建的应用为空
+	// @Example "1001"
+	OwnerID string `json:"owner_id,omitempty" example:"1001"`
 
 	// @Description 应用名称
 	// @Example "示例应用"
@@ -52,17 +186,25 @@ type ApplicationResponse struct {
 	// @Example "这是一个示例应用"
 	Description string `json:"description" example:"这是一个示例应用"`
 
-	// @Description 应用状态
+	// @Description 应用状态：active，已软删除时为deleted
 	// @Example "active"
-	Status string `json:"status" example:"active"`
+	Status string `json:"status" example:"active" enums:"active,deleted"`
+
+	// @Description 应用标签
+	// @Example ["env:prod", "team:core"]
+	Tags []string `json:"tags" example:"env:prod,team:core"`
+
+	// @Description 非敏感的应用变量，仅在应用详情中返回
+	// @Example {"LOG_LEVEL": "info"}
+	Variables map[string]string `json:"variables,omitempty"`
 
 	// @Description 创建时间
 	// @Example "2024-01-01T12:00:00Z"
-	CreatedAt time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	CreatedAt timestamp.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
 
 	// @Description 更新时间
 	// @Example "2024-01-01T12:00:00Z"
-	UpdatedAt time.Time `json:"updated_at" example:"2024-01-01T12:00:00Z"`
+	UpdatedAt timestamp.Time `json:"updated_at" example:"2024-01-01T12:00:00Z"`
 }
 
 // ApplicationListResponse 应用列表响应（向后兼容）
@@ -112,16 +254,55 @@ type ApplicationStatsResponse struct {
 	MonthNewApps int64 `json:"month_new_apps" example:"25"`
 }
 
+// ExportApplicationsRequest 应用导出请求
+// @Description 导出应用的请求参数，过滤条件与应用列表相同
+type ExportApplicationsRequest struct {
+	// @Description 导出格式
+	// @Example "csv"
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=csv xlsx" example:"csv"`
+
+	// @Description 导出的列，逗号分隔，默认全部：id,name,description,tags,created_at,updated_at
+	// @Example "id,name,tags"
+	Columns string `json:"columns" form:"columns" binding:"omitempty,max=200" example:"id,name,tags"`
+
+	// @Description 标签选择器，逗号分隔且全部匹配
+	// @Example "env:prod"
+	Tags string `json:"tags" form:"tags" binding:"omitempty,max=500" sanitize:"trim,strip_control" example:"env:prod"`
+}
+
+// ImportApplicationsRequest 应用导入请求
+// @Description 导入应用的查询参数，文件通过multipart表单的file字段上传
+type ImportApplicationsRequest struct {
+	// @Description 文件格式，为空时根据文件扩展名判断
+	// @Example "csv"
+	Format string `json:"format" form:"format" binding:"omitempty,oneof=csv xlsx" example:"csv"`
+
+	// @Description 是否试运行，试运行时仅校验每一行而不创建应用
+	// @Example true
+	DryRun bool `json:"dry_run" form:"dry_run" example:"true"`
+}
+
+// BatchDeleteForceThreshold 批量删除的应用多于此数量时必须设置force
+const BatchDeleteForceThreshold = 100
+
 // BatchDeleteApplicationsRequest 批量删除应用请求
 // @Description 批量删除应用的请求参数
 type BatchDeleteApplicationsRequest struct {
 	// @Description 应用ID列表
 	// @Example [1, 2, 3]
-	IDs []uint `json:"ids" binding:"required,min=1,dive,required" example:"1,2,3"`
+	IDs []idgen.ID `json:"ids" binding:"required,min=1,dive,required" lookup:"exists=application" example:"1,2,3"`
 
-	// @Description 是否强制删除
+	// @Description 确认删除大批量应用，删除多于100个应用时必须为true
 	// @Example false
 	Force bool `json:"force" example:"false"`
+
+	// @Description 是否部分删除：为true时删除可删除的应用并报告其余应用，否则任一应用无法删除时全部不删除
+	// @Example false
+	ContinueOnError bool `json:"continue_on_error" example:"false"`
+
+	// @Description 是否永久删除：为true时立即删除应用及其变量和修订记录，否则软删除并由数据保留任务清理；仅GORM数据存储支持软删除
+	// @Example false
+	Permanent bool `json:"permanent" example:"false"`
 }
 
 // ApplicationBackupRequest 应用备份请求
@@ -133,7 +314,7 @@ type ApplicationBackupRequest struct {
 
 	// @Description 备份描述
 	// @Example "每日自动备份"
-	Description string `json:"description" binding:"omitempty,max=500" example:"每日自动备份"`
+	Description string `json:"description" binding:"omitempty,max=500" sanitize:"trim,strip_control" example:"每日自动备份"`
 
 	// @Description 是否包含数据
 	// @Example true
@@ -151,23 +332,59 @@ type ApplicationBackupResponse struct {
 	// @Example "backup_123456"
 	ID string `json:"id" example:"backup_123456"`
 
+	// @Description 执行备份的任务ID，可通过任务接口查询进度
+	// @Example "4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"
+	OperationID string `json:"operation_id,omitempty" example:"4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"`
+
+	// @Description 应用ID，隐藏内部ID时省略
+	// @Example 1
+	AppID idgen.ID `json:"app_id,omitempty" example:"1"`
+
 	// @Description 备份名称
 	// @Example "daily_backup_20240101"
 	Name string `json:"name" example:"daily_backup_20240101"`
 
-	// @Description 备份文件路径
+	// @Description 备份描述
+	// @Example "每日自动备份"
+	Description string `json:"description" example:"每日自动备份"`
+
+	// @Description 是否包含数据（变量和修订记录）
+	// @Example true
+	IncludeData bool `json:"include_data" example:"true"`
+
+	// @Description 是否压缩
+	// @Example true
+	Compress bool `json:"compress" example:"true"`
+
+	// @Description 备份文件路径，仅管理员可见
 	// @Example "/backups/app_1_20240101.tar.gz"
-	FilePath string `json:"file_path" example:"/backups/app_1_20240101.tar.gz"`
+	FilePath string `json:"file_path" visibility:"admin" example:"/backups/app_1_20240101.tar.gz"`
 
 	// @Description 备份文件大小（字节）
 	// @Example 1048576
 	FileSize int64 `json:"file_size" example:"1048576"`
 
-	// @Description 备份状态
+	// @Description 备份状态：pending、running、completed 或 failed
 	// @Example "completed"
 	Status string `json:"status" example:"completed"`
 
+	// @Description 失败原因
+	// @Example ""
+	Error string `json:"error,omitempty" example:""`
+
 	// @Description 创建时间
 	// @Example "2024-01-01T12:00:00Z"
-	CreatedAt time.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	CreatedAt timestamp.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+
+	// @Description 完成时间
+	// @Example "2024-01-01T12:00:05Z"
+	CompletedAt *timestamp.Time `json:"completed_at,omitempty" example:"2024-01-01T12:00:05Z"`
+}
+
+// ApplicationRestoreRequest 应用恢复请求
+// @Description 从备份恢复应用的请求参数
+type ApplicationRestoreRequest struct {
+	// @Description 恢复后的应用名称，为空时使用备份中的名称
+	// @Example "restored-app"
+	Name string `json:"name" binding:"omitempty,min=1,max=100" example:"restored-app"`
 }
@@ -0,0 +1,62 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// ApplicationRevisionResponse 应用修订记录响应
+// @Description 应用的一次变更，修订记录不可修改
+type ApplicationRevisionResponse struct {
+	// @Description 修订号，从1开始递增
+	// @Example 3
+	Revision int `json:"revision" example:"3"`
+
+	// @Description 变更类型：create、update 或 rollback
+	// @Example "update"
+	Action string `json:"action" example:"update"`
+
+	// @Description 变更人的用户ID
+	// @Example "42"
+	Actor string `json:"actor" example:"42"`
+
+	// @Description 回滚时恢复的修订号
+	// @Example 1
+	RestoredFrom int `json:"restored_from,omitempty" example:"1"`
+
+	// @Description 与上一修订相比变化的字段
+	Changes []FieldChangeResponse `json:"changes"`
+
+	// @Description 变更后的应用字段
+	Snapshot ApplicationSnapshotResponse `json:"snapshot"`
+
+	// @Description 变更时间
+	// @Example "2024-01-01T12:00:00Z"
+	CreatedAt timestamp.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// FieldChangeResponse 字段变更
+// @Description 字段的旧值和新值
+type FieldChangeResponse struct {
+	// @Description 字段名
+	// @Example "description"
+	Field string `json:"field" example:"description"`
+
+	// @Description 旧值
+	Old interface{} `json:"old"`
+
+	// @Description 新值
+	New interface{} `json:"new"`
+}
+
+// ApplicationSnapshotResponse 应用快照
+// @Description 修订记录中受版本管理的应用字段
+type ApplicationSnapshotResponse struct {
+	// @Description 应用名称
+	// @Example "示例应用"
+	Name string `json:"name" example:"示例应用"`
+
+	// @Description 应用描述
+	// @Example "这是一个示例应用"
+	Description string `json:"description" example:"这是一个示例应用"`
+
+	// @Description 应用标签
+	Tags []string `json:"tags"`
+}
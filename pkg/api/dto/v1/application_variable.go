@@ -0,0 +1,91 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// CreateApplicationVariableRequest 创建应用变量请求
+// @Description 创建应用变量的请求参数
+type CreateApplicationVariableRequest struct {
+	// @Description 变量名，大写字母、数字或下划线，且不能以数字开头
+	// @Example "DATABASE_URL"
+	Key string `json:"key" binding:"required,min=1,max=255" example:"DATABASE_URL"`
+
+	// @Description 变量值，最多64KB
+	// @Example "postgres://db:5432/app"
+	Value string `json:"value" binding:"max=65536" example:"postgres://db:5432/app"`
+
+	// @Description 是否为敏感变量，敏感变量加密存储且不在响应中返回变量值
+	// @Example false
+	Secret bool `json:"secret" example:"false"`
+}
+
+// UpdateApplicationVariableRequest 更新应用变量请求
+// @Description 更新应用变量的请求参数，未提供的字段保持不变
+type UpdateApplicationVariableRequest struct {
+	// @Description 变量值，最多64KB
+	Value *string `json:"value" binding:"omitempty,max=65536"`
+
+	// @Description 是否为敏感变量
+	Secret *bool `json:"secret"`
+}
+
+// ApplicationVariableResponse 应用变量响应
+// @Description 应用变量，敏感变量的值已脱敏
+type ApplicationVariableResponse struct {
+	// @Description 变量名
+	// @Example "DATABASE_URL"
+	Key string `json:"key" example:"DATABASE_URL"`
+
+	// @Description 变量值，敏感变量为 ******
+	// @Example "postgres://db:5432/app"
+	Value string `json:"value" example:"postgres://db:5432/app"`
+
+	// @Description 是否为敏感变量
+	// @Example false
+	Secret bool `json:"secret" example:"false"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
+
+// ImportApplicationVariablesRequest 批量导入应用变量请求
+// @Description 导入dotenv或JSON格式的变量，已存在的变量将被更新
+type ImportApplicationVariablesRequest struct {
+	// @Description 内容格式：dotenv 或 json（变量名到字符串值的对象）
+	// @Example "dotenv"
+	Format string `json:"format" binding:"required,oneof=dotenv json" example:"dotenv"`
+
+	// @Description 文件内容
+	// @Example "DATABASE_URL=postgres://db:5432/app\nLOG_LEVEL=info"
+	Content string `json:"content" binding:"required,max=1048576"`
+
+	// @Description 需要作为敏感变量导入的变量名
+	// @Example ["DATABASE_PASSWORD"]
+	Secrets []string `json:"secrets" binding:"omitempty,max=1000"`
+}
+
+// ImportApplicationVariablesResponse 批量导入应用变量响应
+// @Description 导入结果
+type ImportApplicationVariablesResponse struct {
+	// @Description 新建的变量数量
+	// @Example 2
+	Created int `json:"created" example:"2"`
+
+	// @Description 更新的变量数量
+	// @Example 1
+	Updated int `json:"updated" example:"1"`
+}
+
+// ExportApplicationVariablesRequest 导出应用变量请求
+// @Description 导出应用变量的查询参数
+type ExportApplicationVariablesRequest struct {
+	// @Description 导出格式：dotenv 或 json，默认 dotenv
+	// @Example "dotenv"
+	Format string `form:"format" binding:"omitempty,oneof=dotenv json" example:"dotenv"`
+
+	// @Description 是否包含敏感变量（仅管理员），默认不包含
+	// @Example false
+	IncludeSecrets bool `form:"include_secrets" example:"false"`
+}
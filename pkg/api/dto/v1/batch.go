@@ -0,0 +1,47 @@
+package v1
+
+import "encoding/json"
+
+// BatchRequest 批量请求
+// @Description 在一次往返中执行的多个API请求，数量不超过 server.batch.max_requests
+type BatchRequest struct {
+	// @Description 子请求，按顺序返回各自的结果
+	Requests []BatchItemRequest `json:"requests" binding:"required,min=1,dive"`
+}
+
+// BatchItemRequest 批量请求中的子请求
+// @Description 相对于当前API版本的请求，与批量请求共用认证信息
+type BatchItemRequest struct {
+	// @Description 请求方法
+	// @Example "GET"
+	Method string `json:"method" binding:"required,oneof=GET POST PUT PATCH DELETE" example:"GET"`
+
+	// @Description 相对于 /api/{version} 的路径，可带查询参数
+	// @Example "/applications?page=1&size=10"
+	Path string `json:"path" binding:"required,startswith=/" example:"/applications?page=1&size=10"`
+
+	// @Description JSON请求体
+	Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+}
+
+// BatchResponse 批量请求的结果
+// @Description 与子请求顺序一致的结果
+type BatchResponse struct {
+	// @Description 子请求的结果
+	Responses []BatchItemResponse `json:"responses"`
+}
+
+// BatchItemResponse 子请求的结果
+// @Description 子请求的状态码和响应体，未执行的子请求带有错误信息
+type BatchItemResponse struct {
+	// @Description HTTP状态码
+	// @Example 200
+	Status int `json:"status" example:"200"`
+
+	// @Description 响应体，JSON以外的响应为字符串
+	Body json.RawMessage `json:"body,omitempty" swaggertype:"object"`
+
+	// @Description 子请求未执行的原因
+	// @Example "batch requests cannot be nested"
+	Error string `json:"error,omitempty" example:"batch requests cannot be nested"`
+}
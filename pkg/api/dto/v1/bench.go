@@ -0,0 +1,83 @@
+package v1
+
+// BenchRequest 基准测试参数
+// @Description 基准测试的并发数和操作次数
+type BenchRequest struct {
+	// @Description 并发数，默认10，不超过 server.bench.max_concurrency
+	// @Example 32
+	Concurrency int `json:"concurrency" binding:"omitempty,min=1" example:"32"`
+
+	// @Description 操作总次数，默认1000，不超过 server.bench.max_iterations
+	// @Example 10000
+	Iterations int `json:"iterations" binding:"omitempty,min=1" example:"10000"`
+
+	// @Description JSON文档和缓存值的字节数，默认1024，不超过 server.bench.max_payload
+	// @Example 4096
+	PayloadSize int `json:"payload_size" binding:"omitempty,min=1" example:"4096"`
+}
+
+// BenchResponse 基准测试结果
+// @Description 基准测试的吞吐量和延迟分布，延迟单位为毫秒
+type BenchResponse struct {
+	// @Description 测试对象：datastore、cache 或 json
+	// @Example "datastore"
+	Target string `json:"target" example:"datastore"`
+
+	// @Description 并发数
+	// @Example 32
+	Concurrency int `json:"concurrency" example:"32"`
+
+	// @Description 完成的操作次数
+	// @Example 10000
+	Operations int `json:"operations" example:"10000"`
+
+	// @Description 失败的操作次数
+	// @Example 0
+	Errors int `json:"errors" example:"0"`
+
+	// @Description 第一个失败操作的错误信息
+	FirstError string `json:"first_error,omitempty"`
+
+	// @Description 总耗时（毫秒）
+	// @Example 812.5
+	ElapsedMs float64 `json:"elapsed_ms" example:"812.5"`
+
+	// @Description 每秒操作次数
+	// @Example 12307.7
+	Throughput float64 `json:"ops_per_second" example:"12307.7"`
+
+	// @Description 延迟统计
+	Latency LatencyResponse `json:"latency"`
+
+	// @Description 延迟直方图，按上限升序，最后一个桶为溢出桶
+	Histogram []HistogramBucketResponse `json:"histogram"`
+}
+
+// LatencyResponse 延迟统计
+// @Description 单次操作的延迟统计（毫秒）
+type LatencyResponse struct {
+	// @Example 0.02
+	MinMs float64 `json:"min_ms" example:"0.02"`
+	// @Example 2.4
+	MeanMs float64 `json:"mean_ms" example:"2.4"`
+	// @Example 1.9
+	P50Ms float64 `json:"p50_ms" example:"1.9"`
+	// @Example 4.1
+	P90Ms float64 `json:"p90_ms" example:"4.1"`
+	// @Example 9.8
+	P99Ms float64 `json:"p99_ms" example:"9.8"`
+	// @Example 31.2
+	MaxMs float64 `json:"max_ms" example:"31.2"`
+}
+
+// HistogramBucketResponse 延迟直方图桶
+// @Description 延迟不超过上限的操作次数
+type HistogramBucketResponse struct {
+	// @Description 桶上限，溢出桶为 +Inf
+	// @Example "2.5ms"
+	LE string `json:"le" example:"2.5ms"`
+
+	// @Description 落入该桶的操作次数
+	// @Example 421
+	Count int `json:"count" example:"421"`
+}
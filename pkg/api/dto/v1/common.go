@@ -1,6 +1,6 @@
 package v1
 
-import "time"
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
 
 // BaseRequest 基础请求结构
 type BaseRequest struct {
@@ -33,7 +33,7 @@ type PageRequest struct {
 type SearchRequest struct {
 	// @Description 搜索关键词
 	// @Example "搜索关键词"
-	Keyword string `json:"keyword" form:"keyword" binding:"omitempty,max=100" example:"搜索关键词"`
+	Keyword string `json:"keyword" form:"keyword" binding:"omitempty,max=100" sanitize:"trim,collapse,strip_control" example:"搜索关键词"`
 
 	// @Description 过滤条件
 	Filters map[string]interface{} `json:"filters,omitempty"`
@@ -106,12 +106,16 @@ type Pagination struct {
 // @Description 验证错误详细信息
 type ErrorDetail struct {
 	// @Description 错误字段
-	// @Example "username"
-	Field string `json:"field" example:"username"`
+	// @Example "Email"
+	Field string `json:"field" example:"Email"`
 
-	// @Description 错误原因
-	// @Example "用户名不能为空"
-	Reason string `json:"reason" example:"用户名不能为空"`
+	// @Description 错误原因，中文请求返回中文，其他语言返回英文
+	// @Example "Email必须是一个有效的邮箱"
+	Reason string `json:"reason" example:"Email必须是一个有效的邮箱"`
+
+	// @Description 校验失败的值，敏感字段（如密码）脱敏为******，空值省略
+	// @Example "notanemail"
+	Value interface{} `json:"value,omitempty" swaggertype:"string" example:"notanemail"`
 }
 
 // HealthCheckResponse 健康检查响应
@@ -131,7 +135,7 @@ type HealthCheckResponse struct {
 
 	// @Description 检查时间
 	// @Example "2024-01-01T12:00:00Z"
-	Timestamp time.Time `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T12:00:00Z"`
 
 	// @Description 详细检查信息
 	Details map[string]interface{} `json:"details,omitempty"`
@@ -210,6 +214,10 @@ type BulkOperationResponse struct {
 
 	// @Description 失败的项目详情
 	Failures []BulkFailureItem `json:"failures,omitempty"`
+
+	// @Description 是否为试运行，试运行时仅校验不落库
+	// @Example false
+	DryRun bool `json:"dry_run,omitempty" example:"false"`
 }
 
 // BulkFailureItem 批量操作失败项
@@ -223,47 +231,3 @@ type BulkFailureItem struct {
 	// @Example "项目不存在"
 	Reason string `json:"reason" example:"项目不存在"`
 }
-
-// FileUploadRequest 文件上传请求
-// @Description 文件上传参数
-type FileUploadRequest struct {
-	// @Description 文件描述
-	// @Example "用户头像"
-	Description string `json:"description" binding:"omitempty,max=200" example:"用户头像"`
-
-	// @Description 文件分类
-	// @Example "avatar"
-	Category string `json:"category" binding:"omitempty,max=50" example:"avatar"`
-
-	// @Description 是否公开文件
-	// @Example true
-	Public bool `json:"public" example:"true"`
-}
-
-// FileUploadResponse 文件上传响应
-// @Description 文件上传结果
-type FileUploadResponse struct {
-	// @Description 文件ID
-	// @Example "file_123456"
-	ID string `json:"id" example:"file_123456"`
-
-	// @Description 文件名
-	// @Example "avatar.jpg"
-	FileName string `json:"file_name" example:"avatar.jpg"`
-
-	// @Description 文件大小（字节）
-	// @Example 1024000
-	FileSize int64 `json:"file_size" example:"1024000"`
-
-	// @Description 文件类型
-	// @Example "image/jpeg"
-	ContentType string `json:"content_type" example:"image/jpeg"`
-
-	// @Description 文件URL
-	// @Example "https://cdn.example.com/files/avatar.jpg"
-	URL string `json:"url" example:"https://cdn.example.com/files/avatar.jpg"`
-
-	// @Description 上传时间
-	// @Example "2024-01-01T12:00:00Z"
-	UploadedAt time.Time `json:"uploaded_at" example:"2024-01-01T12:00:00Z"`
-}
@@ -0,0 +1,86 @@
+package v1
+
+// ContainerResponse 依赖注入容器诊断响应
+// @Description 容器中注册的bean及其注入、生命周期和健康状态
+type ContainerResponse struct {
+	// @Description 所有bean依赖均已满足且健康检查通过
+	// @Example true
+	Healthy bool `json:"healthy" example:"true"`
+
+	// @Description bean总数
+	// @Example 12
+	Total int `json:"total" example:"12"`
+
+	// @Description 不健康的bean数量
+	// @Example 0
+	Unhealthy int `json:"unhealthy" example:"0"`
+
+	// @Description bean列表，按注册顺序排列
+	Beans []BeanResponse `json:"beans"`
+}
+
+// BeanResponse bean诊断信息
+// @Description 单个bean的类型、依赖和状态
+type BeanResponse struct {
+	// @Description bean名称
+	// @Example "datastore"
+	Name string `json:"name" example:"datastore"`
+
+	// @Description bean的Go类型
+	// @Example "*memory.Memory"
+	Type string `json:"type" example:"*memory.Memory"`
+
+	// @Description 限定符，用于区分同一名称或接口的多个实现
+	Qualifiers []string `json:"qualifiers,omitempty"`
+
+	// @Description 生命周期：singleton、prototype 或 request
+	// @Example "singleton"
+	Lifecycle string `json:"lifecycle" example:"singleton"`
+
+	// @Description 注入字段列表
+	Dependencies []BeanDependencyResponse `json:"dependencies"`
+
+	// @Description 是否实现OnStart
+	Starter bool `json:"starter"`
+
+	// @Description 是否实现OnStop
+	Stopper bool `json:"stopper"`
+
+	// @Description 是否已由容器启动
+	Started bool `json:"started"`
+
+	// @Description 依赖均已满足且健康检查通过
+	Healthy bool `json:"healthy"`
+
+	// @Description 不健康的原因
+	HealthError string `json:"health_error,omitempty"`
+}
+
+// BeanDependencyResponse bean注入字段信息
+// @Description 注入字段及其解析结果
+type BeanDependencyResponse struct {
+	// @Description 字段名
+	// @Example "Store"
+	Field string `json:"field" example:"Store"`
+
+	// @Description inject标签，为空表示按类型注入
+	// @Example "datastore"
+	Tag string `json:"tag" example:"datastore"`
+
+	// @Description 字段类型
+	// @Example "datastore.DatastoreInterface"
+	Type string `json:"type" example:"datastore.DatastoreInterface"`
+
+	// @Description 实际注入的bean名称
+	// @Example "datastore"
+	Bean string `json:"bean,omitempty" example:"datastore"`
+
+	// @Description 切片字段按组注入的bean名称列表
+	Group []string `json:"group,omitempty"`
+
+	// @Description 是否注入成功
+	Satisfied bool `json:"satisfied"`
+
+	// @Description 注入失败原因
+	Error string `json:"error,omitempty"`
+}
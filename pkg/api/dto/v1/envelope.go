@@ -0,0 +1,524 @@
+package v1
+
+// 以下是API文档使用的响应类型。处理器以 response.Response 返回数据，
+// swag 无法从 response.Response{data=...} 推导其结构，注释中改用这些具体类型
+
+// Envelope 标准响应结构的公共字段，嵌入各响应的文档类型
+// @Description 成功响应的公共字段
+type Envelope struct {
+	// @Description 请求是否成功
+	// @Example true
+	Success bool `json:"success" example:"true"`
+
+	// @Description 业务状态码
+	// @Example 200
+	Code int `json:"code" example:"200"`
+
+	// @Description 响应消息
+	// @Example "操作成功"
+	Message string `json:"message" example:"操作成功"`
+
+	// @Description 时间戳
+	// @Example "2024-01-01T12:00:00Z"
+	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+
+	// @Description 请求ID
+	// @Example "req_123456789"
+	RequestID string `json:"request_id" example:"req_123456789"`
+}
+
+// ErrorEnvelope 错误响应
+// @Description 失败的响应，参数校验失败时 details 列出各字段的错误
+type ErrorEnvelope struct {
+	// @Description 请求是否成功
+	// @Example false
+	Success bool `json:"success" example:"false"`
+
+	// @Description 业务错误码
+	// @Example 20000
+	Code int `json:"code" example:"20000"`
+
+	// @Description 错误消息
+	// @Example "参数验证失败"
+	Message string `json:"message" example:"参数验证失败"`
+
+	// @Description 错误详情
+	// @Example "name is required"
+	Error string `json:"error,omitempty" example:"name is required"`
+
+	// @Description 参数校验错误
+	Details []ErrorDetail `json:"details,omitempty"`
+
+	// @Description 时间戳
+	// @Example "2024-01-01T12:00:00Z"
+	Timestamp string `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+
+	// @Description 请求ID
+	// @Example "req_123456789"
+	RequestID string `json:"request_id" example:"req_123456789"`
+}
+
+// ConfigSnapshotEnvelope 配置快照的文档类型
+type ConfigSnapshotEnvelope struct {
+	Envelope
+	// @Description 脱敏后的配置
+	Data map[string]interface{} `json:"data"`
+}
+
+// DashboardResponseEnvelope 管理面板响应的文档类型
+type DashboardResponseEnvelope struct {
+	Envelope
+	// @Description 管理面板响应
+	Data DashboardResponse `json:"data"`
+}
+
+// RecentErrorsResponseEnvelope 最近错误响应的文档类型
+type RecentErrorsResponseEnvelope struct {
+	Envelope
+	// @Description 最近错误响应
+	Data RecentErrorsResponse `json:"data"`
+}
+
+// AnalyticsEntriesResponseEnvelope 分析数据响应的文档类型
+type AnalyticsEntriesResponseEnvelope struct {
+	Envelope
+	// @Description 分析数据响应
+	Data AnalyticsEntriesResponse `json:"data"`
+}
+
+// DatastoreHistoryResponseEnvelope 数据存储统计历史响应的文档类型
+type DatastoreHistoryResponseEnvelope struct {
+	Envelope
+	// @Description 数据存储统计历史响应
+	Data DatastoreHistoryResponse `json:"data"`
+}
+
+// RetentionPreviewResponseEnvelope 数据保留预览的文档类型
+type RetentionPreviewResponseEnvelope struct {
+	Envelope
+	// @Description 数据保留预览
+	Data RetentionPreviewResponse `json:"data"`
+}
+
+// NetworkACLResponseEnvelope 网络访问控制规则的文档类型
+type NetworkACLResponseEnvelope struct {
+	Envelope
+	// @Description 网络访问控制规则
+	Data NetworkACLResponse `json:"data"`
+}
+
+// ApplicationResponseEnvelope 应用响应的文档类型
+type ApplicationResponseEnvelope struct {
+	Envelope
+	// @Description 应用响应
+	Data ApplicationResponse `json:"data"`
+}
+
+// ApplicationResponsePage 应用响应分页列表
+type ApplicationResponsePage struct {
+	// @Description 数据列表
+	Items []ApplicationResponse `json:"items"`
+
+	// @Description 分页信息
+	Pagination Pagination `json:"pagination"`
+}
+
+// ApplicationResponsePageEnvelope 应用响应分页列表的文档类型
+type ApplicationResponsePageEnvelope struct {
+	Envelope
+	// @Description 应用响应分页列表
+	Data ApplicationResponsePage `json:"data"`
+}
+
+// ApplicationStatsResponseEnvelope 应用统计响应的文档类型
+type ApplicationStatsResponseEnvelope struct {
+	Envelope
+	// @Description 应用统计响应
+	Data ApplicationStatsResponse `json:"data"`
+}
+
+// ApplicationBackupResponseEnvelope 应用备份响应的文档类型
+type ApplicationBackupResponseEnvelope struct {
+	Envelope
+	// @Description 应用备份响应
+	Data ApplicationBackupResponse `json:"data"`
+}
+
+// ApplicationBackupResponsePage 应用备份响应分页列表
+type ApplicationBackupResponsePage struct {
+	// @Description 数据列表
+	Items []ApplicationBackupResponse `json:"items"`
+
+	// @Description 分页信息
+	Pagination Pagination `json:"pagination"`
+}
+
+// ApplicationBackupResponsePageEnvelope 应用备份响应分页列表的文档类型
+type ApplicationBackupResponsePageEnvelope struct {
+	Envelope
+	// @Description 应用备份响应分页列表
+	Data ApplicationBackupResponsePage `json:"data"`
+}
+
+// ApplicationRevisionResponsePage 应用修订记录响应分页列表
+type ApplicationRevisionResponsePage struct {
+	// @Description 数据列表
+	Items []ApplicationRevisionResponse `json:"items"`
+
+	// @Description 分页信息
+	Pagination Pagination `json:"pagination"`
+}
+
+// ApplicationRevisionResponsePageEnvelope 应用修订记录响应分页列表的文档类型
+type ApplicationRevisionResponsePageEnvelope struct {
+	Envelope
+	// @Description 应用修订记录响应分页列表
+	Data ApplicationRevisionResponsePage `json:"data"`
+}
+
+// ApplicationVariableResponseEnvelope 应用变量响应的文档类型
+type ApplicationVariableResponseEnvelope struct {
+	Envelope
+	// @Description 应用变量响应
+	Data ApplicationVariableResponse `json:"data"`
+}
+
+// ApplicationVariableResponseListEnvelope 应用变量响应列表的文档类型
+type ApplicationVariableResponseListEnvelope struct {
+	Envelope
+	// @Description 应用变量响应列表
+	Data []ApplicationVariableResponse `json:"data"`
+}
+
+// ImportApplicationVariablesResponseEnvelope 批量导入应用变量响应的文档类型
+type ImportApplicationVariablesResponseEnvelope struct {
+	Envelope
+	// @Description 批量导入应用变量响应
+	Data ImportApplicationVariablesResponse `json:"data"`
+}
+
+// BatchResponseEnvelope 批量请求的结果的文档类型
+type BatchResponseEnvelope struct {
+	Envelope
+	// @Description 批量请求的结果
+	Data BatchResponse `json:"data"`
+}
+
+// BenchResponseEnvelope 基准测试结果的文档类型
+type BenchResponseEnvelope struct {
+	Envelope
+	// @Description 基准测试结果
+	Data BenchResponse `json:"data"`
+}
+
+// HealthCheckResponseEnvelope 健康检查响应的文档类型
+type HealthCheckResponseEnvelope struct {
+	Envelope
+	// @Description 健康检查响应
+	Data HealthCheckResponse `json:"data"`
+}
+
+// ContainerResponseEnvelope 依赖注入容器诊断响应的文档类型
+type ContainerResponseEnvelope struct {
+	Envelope
+	// @Description 依赖注入容器诊断响应
+	Data ContainerResponse `json:"data"`
+}
+
+// FeatureFlagResponseEnvelope 特性开关响应的文档类型
+type FeatureFlagResponseEnvelope struct {
+	Envelope
+	// @Description 特性开关响应
+	Data FeatureFlagResponse `json:"data"`
+}
+
+// FeatureFlagResponseListEnvelope 特性开关响应列表的文档类型
+type FeatureFlagResponseListEnvelope struct {
+	Envelope
+	// @Description 特性开关响应列表
+	Data []FeatureFlagResponse `json:"data"`
+}
+
+// EvaluatedFeatureFlagsResponseEnvelope 当前用户的特性开关求值结果的文档类型
+type EvaluatedFeatureFlagsResponseEnvelope struct {
+	Envelope
+	// @Description 当前用户的特性开关求值结果
+	Data EvaluatedFeatureFlagsResponse `json:"data"`
+}
+
+// ExperimentAssignmentsResponseEnvelope 当前用户的实验分组结果的文档类型
+type ExperimentAssignmentsResponseEnvelope struct {
+	Envelope
+	// @Description 当前用户的实验分组结果
+	Data ExperimentAssignmentsResponse `json:"data"`
+}
+
+// ImpersonationResponseEnvelope 模拟登录响应的文档类型
+type ImpersonationResponseEnvelope struct {
+	Envelope
+	// @Description 模拟登录响应
+	Data ImpersonationResponse `json:"data"`
+}
+
+// InvitationResponseListEnvelope 邀请响应列表的文档类型
+type InvitationResponseListEnvelope struct {
+	Envelope
+	// @Description 邀请响应列表
+	Data []InvitationResponse `json:"data"`
+}
+
+// CreateInvitationResponseEnvelope 创建邀请响应的文档类型
+type CreateInvitationResponseEnvelope struct {
+	Envelope
+	// @Description 创建邀请响应
+	Data CreateInvitationResponse `json:"data"`
+}
+
+// AcceptInvitationResponseEnvelope 接受邀请响应的文档类型
+type AcceptInvitationResponseEnvelope struct {
+	Envelope
+	// @Description 接受邀请响应
+	Data AcceptInvitationResponse `json:"data"`
+}
+
+// MeResponseEnvelope 当前用户响应的文档类型
+type MeResponseEnvelope struct {
+	Envelope
+	// @Description 当前用户响应
+	Data MeResponse `json:"data"`
+}
+
+// MePermissionsResponseEnvelope 当前用户权限响应的文档类型
+type MePermissionsResponseEnvelope struct {
+	Envelope
+	// @Description 当前用户权限响应
+	Data MePermissionsResponse `json:"data"`
+}
+
+// MePreferencesResponseEnvelope 当前用户偏好响应的文档类型
+type MePreferencesResponseEnvelope struct {
+	Envelope
+	// @Description 当前用户偏好响应
+	Data MePreferencesResponse `json:"data"`
+}
+
+// NotificationPreferenceResponseEnvelope 通知渠道偏好响应的文档类型
+type NotificationPreferenceResponseEnvelope struct {
+	Envelope
+	// @Description 通知渠道偏好响应
+	Data NotificationPreferenceResponse `json:"data"`
+}
+
+// NotificationPreferenceResponseListEnvelope 通知渠道偏好响应列表的文档类型
+type NotificationPreferenceResponseListEnvelope struct {
+	Envelope
+	// @Description 通知渠道偏好响应列表
+	Data []NotificationPreferenceResponse `json:"data"`
+}
+
+// NotificationDeliveryResponseListEnvelope 通知发送结果列表的文档类型
+type NotificationDeliveryResponseListEnvelope struct {
+	Envelope
+	// @Description 通知发送结果列表
+	Data []NotificationDeliveryResponse `json:"data"`
+}
+
+// EventListResponseEnvelope 领域事件列表响应的文档类型
+type EventListResponseEnvelope struct {
+	Envelope
+	// @Description 领域事件列表
+	Data EventListResponse `json:"data"`
+}
+
+// OperationResponseEnvelope 任务响应的文档类型
+type OperationResponseEnvelope struct {
+	Envelope
+	// @Description 任务响应
+	Data OperationResponse `json:"data"`
+}
+
+// OperationResponsePage 任务响应分页列表
+type OperationResponsePage struct {
+	// @Description 数据列表
+	Items []OperationResponse `json:"items"`
+
+	// @Description 分页信息
+	Pagination Pagination `json:"pagination"`
+}
+
+// OperationResponsePageEnvelope 任务响应分页列表的文档类型
+type OperationResponsePageEnvelope struct {
+	Envelope
+	// @Description 任务响应分页列表
+	Data OperationResponsePage `json:"data"`
+}
+
+// OrganizationResponseEnvelope 组织响应的文档类型
+type OrganizationResponseEnvelope struct {
+	Envelope
+	// @Description 组织响应
+	Data OrganizationResponse `json:"data"`
+}
+
+// OrganizationResponseListEnvelope 组织响应列表的文档类型
+type OrganizationResponseListEnvelope struct {
+	Envelope
+	// @Description 组织响应列表
+	Data []OrganizationResponse `json:"data"`
+}
+
+// OrganizationMemberResponseEnvelope 组织成员响应的文档类型
+type OrganizationMemberResponseEnvelope struct {
+	Envelope
+	// @Description 组织成员响应
+	Data OrganizationMemberResponse `json:"data"`
+}
+
+// OrganizationMemberResponseListEnvelope 组织成员响应列表的文档类型
+type OrganizationMemberResponseListEnvelope struct {
+	Envelope
+	// @Description 组织成员响应列表
+	Data []OrganizationMemberResponse `json:"data"`
+}
+
+// PartnerResponseEnvelope 合作方响应的文档类型
+type PartnerResponseEnvelope struct {
+	Envelope
+	// @Description 合作方响应
+	Data PartnerResponse `json:"data"`
+}
+
+// PartnerResponseListEnvelope 合作方响应列表的文档类型
+type PartnerResponseListEnvelope struct {
+	Envelope
+	// @Description 合作方响应列表
+	Data []PartnerResponse `json:"data"`
+}
+
+// PartnerSecretResponseEnvelope 合作方密钥响应的文档类型
+type PartnerSecretResponseEnvelope struct {
+	Envelope
+	// @Description 合作方密钥响应
+	Data PartnerSecretResponse `json:"data"`
+}
+
+// PolicyResponseEnvelope 政策文档响应的文档类型
+type PolicyResponseEnvelope struct {
+	Envelope
+	// @Description 政策文档响应
+	Data PolicyResponse `json:"data"`
+}
+
+// PolicyResponseListEnvelope 政策文档响应列表的文档类型
+type PolicyResponseListEnvelope struct {
+	Envelope
+	// @Description 政策文档响应列表
+	Data []PolicyResponse `json:"data"`
+}
+
+// PolicyConsentResponseEnvelope 政策同意记录响应的文档类型
+type PolicyConsentResponseEnvelope struct {
+	Envelope
+	// @Description 政策同意记录响应
+	Data PolicyConsentResponse `json:"data"`
+}
+
+// PolicyConsentResponseListEnvelope 政策同意记录响应列表的文档类型
+type PolicyConsentResponseListEnvelope struct {
+	Envelope
+	// @Description 政策同意记录响应列表
+	Data []PolicyConsentResponse `json:"data"`
+}
+
+// QuotaUsageResponseEnvelope 主体的配额用量响应的文档类型
+type QuotaUsageResponseEnvelope struct {
+	Envelope
+	// @Description 主体的配额用量响应
+	Data QuotaUsageResponse `json:"data"`
+}
+
+// SessionResponseListEnvelope 会话响应列表的文档类型
+type SessionResponseListEnvelope struct {
+	Envelope
+	// @Description 会话响应列表
+	Data []SessionResponse `json:"data"`
+}
+
+// SessionTokensResponseEnvelope 会话令牌响应的文档类型
+type SessionTokensResponseEnvelope struct {
+	Envelope
+	// @Description 会话令牌响应
+	Data SessionTokensResponse `json:"data"`
+}
+
+// RevokeSessionsResponseEnvelope 终止其他会话响应的文档类型
+type RevokeSessionsResponseEnvelope struct {
+	Envelope
+	// @Description 终止其他会话响应
+	Data RevokeSessionsResponse `json:"data"`
+}
+
+// RegisterResponseEnvelope 注册响应的文档类型
+type RegisterResponseEnvelope struct {
+	Envelope
+	// @Description 注册响应
+	Data RegisterResponse `json:"data"`
+}
+
+// SchemaTypesResponseEnvelope 文档类型列表响应的文档类型
+type SchemaTypesResponseEnvelope struct {
+	Envelope
+	// @Description 文档类型列表响应
+	Data SchemaTypesResponse `json:"data"`
+}
+
+// SchemaResponseEnvelope 文档类型的模式响应的文档类型
+type SchemaResponseEnvelope struct {
+	Envelope
+	// @Description 文档类型的模式响应
+	Data SchemaResponse `json:"data"`
+}
+
+// FileResponseEnvelope 文件响应的文档类型
+type FileResponseEnvelope struct {
+	Envelope
+	// @Description 文件响应
+	Data FileResponse `json:"data"`
+}
+
+// FileResponsePage 文件响应分页列表
+type FileResponsePage struct {
+	// @Description 数据列表
+	Items []FileResponse `json:"items"`
+
+	// @Description 分页信息
+	Pagination Pagination `json:"pagination"`
+}
+
+// FileResponsePageEnvelope 文件响应分页列表的文档类型
+type FileResponsePageEnvelope struct {
+	Envelope
+	// @Description 文件响应分页列表
+	Data FileResponsePage `json:"data"`
+}
+
+// FileUploadResponseEnvelope 文件上传响应的文档类型
+type FileUploadResponseEnvelope struct {
+	Envelope
+	// @Description 文件上传响应
+	Data FileUploadResponse `json:"data"`
+}
+
+// FileDownloadResponseEnvelope 文件下载响应的文档类型
+type FileDownloadResponseEnvelope struct {
+	Envelope
+	// @Description 文件下载响应
+	Data FileDownloadResponse `json:"data"`
+}
+
+// UploadSessionResponseEnvelope 断点续传会话响应的文档类型
+type UploadSessionResponseEnvelope struct {
+	Envelope
+	// @Description 断点续传会话响应
+	Data UploadSessionResponse `json:"data"`
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// ListEventsRequest 领域事件查询请求
+// @Description 从游标之后读取领域事件的请求参数
+type ListEventsRequest struct {
+	// @Description 游标，即最后收到的事件ID，为空时从保留的最早事件开始
+	// @Example 42
+	Cursor uint `json:"cursor" form:"cursor" example:"42"`
+
+	// @Description 资源类型，逗号分隔，即事件类型中第一个点号之前的部分，为空时返回全部类型
+	// @Example "application,organization"
+	ResourceType EnumSet `json:"resource_type,omitempty" form:"resource_type" binding:"omitempty,max=20,dive,max=100" swaggertype:"string" example:"application,organization"`
+
+	// @Description 没有新事件时等待的秒数，最长为 event_log.max_wait，0为立即返回
+	// @Example 30
+	Wait int `json:"wait" form:"wait" binding:"omitempty,min=0,max=3600" example:"30"`
+
+	// @Description 返回的最大事件数，最多为 event_log.page_size
+	// @Example 100
+	Limit int `json:"limit" form:"limit" binding:"omitempty,min=1,max=1000" example:"100"`
+}
+
+// EventResponse 领域事件响应
+// @Description 事件日志中的一个领域事件
+type EventResponse struct {
+	// @Description 事件ID，作为下次请求的游标和SSE事件ID
+	// @Example 42
+	ID idgen.ID `json:"id" example:"42"`
+
+	// @Description 事件类型
+	// @Example "application.deleted"
+	Type string `json:"type" example:"application.deleted"`
+
+	// @Description 资源类型
+	// @Example "application"
+	ResourceType string `json:"resource_type" example:"application"`
+
+	// @Description 事件所属组织ID，不属于组织时省略
+	// @Example 1
+	OrgID idgen.ID `json:"org_id,omitempty" example:"1"`
+
+	// @Description 事件内容，结构由事件类型决定
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+
+	// @Description 事件发生时间
+	// @Example "2024-01-01T12:00:00Z"
+	OccurredAt timestamp.Time `json:"occurred_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// EventListResponse 领域事件列表响应
+// @Description 游标之后的领域事件，按事件ID升序
+type EventListResponse struct {
+	// @Description 事件列表
+	Events []EventResponse `json:"events"`
+
+	// @Description 下次请求使用的游标，没有新事件时为请求的游标
+	// @Example 42
+	NextCursor idgen.ID `json:"next_cursor" example:"42"`
+}
@@ -0,0 +1,95 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// CreateFeatureFlagRequest 创建特性开关请求
+// @Description 创建特性开关的请求参数
+type CreateFeatureFlagRequest struct {
+	// @Description 开关标识，小写字母、数字、点、下划线或中划线
+	// @Example "new-dashboard"
+	Key string `json:"key" binding:"required,min=1,max=100" example:"new-dashboard"`
+
+	// @Description 开关描述，最多500个字符
+	// @Example "新版仪表盘"
+	Description string `json:"description" binding:"omitempty,max=500" example:"新版仪表盘"`
+
+	// @Description 是否启用
+	// @Example true
+	Enabled bool `json:"enabled" example:"true"`
+
+	// @Description 灰度百分比，0-100，默认100
+	// @Example 20
+	RolloutPercentage *int `json:"rollout_percentage" binding:"omitempty,min=0,max=100" example:"20"`
+
+	// @Description 定向用户ID列表
+	TargetUsers []string `json:"target_users" binding:"omitempty,max=1000"`
+
+	// @Description 定向租户ID列表
+	TargetTenants []string `json:"target_tenants" binding:"omitempty,max=1000"`
+}
+
+// UpdateFeatureFlagRequest 更新特性开关请求
+// @Description 更新特性开关的请求参数，未提供的字段保持不变
+type UpdateFeatureFlagRequest struct {
+	// @Description 开关描述，最多500个字符
+	Description *string `json:"description" binding:"omitempty,max=500"`
+
+	// @Description 是否启用
+	Enabled *bool `json:"enabled"`
+
+	// @Description 灰度百分比，0-100
+	RolloutPercentage *int `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+
+	// @Description 定向用户ID列表
+	TargetUsers []string `json:"target_users" binding:"omitempty,max=1000"`
+
+	// @Description 定向租户ID列表
+	TargetTenants []string `json:"target_tenants" binding:"omitempty,max=1000"`
+}
+
+// FeatureFlagResponse 特性开关响应
+// @Description 特性开关定义
+type FeatureFlagResponse struct {
+	// @Description 开关标识
+	// @Example "new-dashboard"
+	Key string `json:"key" example:"new-dashboard"`
+
+	// @Description 开关描述
+	Description string `json:"description" example:"新版仪表盘"`
+
+	// @Description 是否启用
+	Enabled bool `json:"enabled" example:"true"`
+
+	// @Description 灰度百分比
+	RolloutPercentage int `json:"rollout_percentage" example:"20"`
+
+	// @Description 定向用户ID列表
+	TargetUsers []string `json:"target_users"`
+
+	// @Description 定向租户ID列表
+	TargetTenants []string `json:"target_tenants"`
+
+	// @Description 来源：config(配置文件，只读) 或 datastore
+	// @Example "datastore"
+	Source string `json:"source" example:"datastore"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
+
+// EvaluatedFeatureFlagsResponse 当前用户的特性开关求值结果
+// @Description 特性开关标识到是否启用的映射
+type EvaluatedFeatureFlagsResponse struct {
+	// @Description 开关求值结果
+	Flags map[string]bool `json:"flags"`
+}
+
+// ExperimentAssignmentsResponse 当前用户的实验分组结果
+// @Description 实验标识到分组名称的映射，未参与的实验不出现
+type ExperimentAssignmentsResponse struct {
+	// @Description 实验分组结果
+	Variants map[string]string `json:"variants"`
+}
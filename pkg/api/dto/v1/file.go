@@ -0,0 +1,200 @@
+package v1
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// FileUploadRequest 创建文件上传请求
+// @Description 待上传文件的名称、类型和大小，上传地址只接受与之一致的内容
+type FileUploadRequest struct {
+	// @Description 文件名，不含路径
+	// @Example "report.pdf"
+	Name string `json:"name" binding:"required,min=1,max=255" sanitize:"trim,strip_control" example:"report.pdf"`
+
+	// @Description 文件类型，为空时为 application/octet-stream
+	// @Example "application/pdf"
+	ContentType string `json:"content_type" binding:"omitempty,max=255" example:"application/pdf"`
+
+	// @Description 文件大小（字节）
+	// @Example 1048576
+	Size int64 `json:"size" binding:"required,min=1" example:"1048576"`
+}
+
+// ListFilesRequest 文件列表请求
+// @Description 文件列表的分页参数
+type ListFilesRequest struct {
+	PageRequest
+}
+
+// FileResponse 文件响应
+// @Description 文件信息
+type FileResponse struct {
+	// @Description 文件ID，隐藏内部ID时省略
+	// @Example 1
+	ID idgen.ID `json:"id,omitempty" example:"1"`
+
+	// @Description 公开ID，可代替文件ID在路径中使用
+	// @Example "9b2e4c1a-6f3d-4e8b-a5c7-1d0f2e3b4a56"
+	PublicID string `json:"public_id" example:"9b2e4c1a-6f3d-4e8b-a5c7-1d0f2e3b4a56"`
+
+	// @Description 文件名
+	// @Example "report.pdf"
+	Name string `json:"name" example:"report.pdf"`
+
+	// @Description 文件类型
+	// @Example "application/pdf"
+	ContentType string `json:"content_type" example:"application/pdf"`
+
+	// @Description 文件大小（字节）
+	// @Example 1048576
+	Size int64 `json:"size" example:"1048576"`
+
+	// @Description 状态：pending（等待上传）或 uploaded（已上传）
+	// @Example "uploaded"
+	Status string `json:"status" example:"uploaded"`
+
+	// @Description 上传者用户ID
+	// @Example "1001"
+	OwnerID string `json:"owner_id,omitempty" example:"1001"`
+
+	// @Description 所属组织ID，隐藏内部ID时省略
+	// @Example 1
+	OrgID idgen.ID `json:"org_id,omitempty" example:"1"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 上传完成时间
+	UploadedAt *timestamp.Time `json:"uploaded_at,omitempty"`
+
+	// @Description 处理文件的任务ID，可通过任务接口查询进度；上传完成前省略
+	// @Example "4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"
+	OperationID string `json:"operation_id,omitempty" example:"4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"`
+
+	// @Description 文件内容的 SHA-256 校验和（十六进制），处理任务计算完成前省略
+	// @Example "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+
+	// @Description 扫描状态：pending（等待扫描）、clean（安全）或 quarantined（已隔离）；未启用扫描时省略
+	// @Example "clean"
+	ScanStatus string `json:"scan_status,omitempty" example:"clean"`
+
+	// @Description 隔离文件中检测到的恶意软件名称
+	// @Example "Win.Test.EICAR_HDB-1"
+	ScanSignature string `json:"scan_signature,omitempty" example:"Win.Test.EICAR_HDB-1"`
+}
+
+// FileVariantResponse 文件变体响应
+// @Description 由文件内容生成的变体，如图片的缩略图
+type FileVariantResponse struct {
+	// @Description 变体名称
+	// @Example "thumbnail_128"
+	Name string `json:"name" example:"thumbnail_128"`
+
+	// @Description 变体类型
+	// @Example "image/jpeg"
+	ContentType string `json:"content_type" example:"image/jpeg"`
+
+	// @Description 宽度（像素）
+	// @Example 128
+	Width int `json:"width" example:"128"`
+
+	// @Description 高度（像素）
+	// @Example 96
+	Height int `json:"height" example:"96"`
+
+	// @Description 大小（字节）
+	// @Example 5120
+	Size int64 `json:"size" example:"5120"`
+
+	// @Description 预签名下载地址
+	// @Example "https://bucket.s3.us-east-1.amazonaws.com/files/3c9d....thumbnail_128.jpg?X-Amz-Signature=..."
+	URL string `json:"url" example:"https://bucket.s3.us-east-1.amazonaws.com/files/3c9d....thumbnail_128.jpg?X-Amz-Signature=..."`
+
+	// @Description 下载地址的过期时间
+	ExpiresAt timestamp.Time `json:"expires_at"`
+}
+
+// PresignedURLResponse 预签名地址响应
+// @Description 客户端直接访问对象存储的预签名地址，过期后失效
+type PresignedURLResponse struct {
+	// @Description 预签名地址
+	// @Example "https://bucket.s3.us-east-1.amazonaws.com/files/3c9d...?X-Amz-Signature=..."
+	URL string `json:"url" example:"https://bucket.s3.us-east-1.amazonaws.com/files/3c9d...?X-Amz-Signature=..."`
+
+	// @Description 请求方法
+	// @Example "PUT"
+	Method string `json:"method" example:"PUT"`
+
+	// @Description 请求必须携带的请求头
+	// @Example {"Content-Type":"application/pdf"}
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// @Description 过期时间
+	ExpiresAt timestamp.Time `json:"expires_at"`
+}
+
+// FileUploadResponse 文件上传响应
+// @Description 文件信息，创建上传时附带上传地址，图片处理完成后附带变体
+type FileUploadResponse struct {
+	// @Description 文件信息
+	File FileResponse `json:"file"`
+
+	// @Description 上传地址，以 PUT 请求上传文件内容，Content-Length 须与文件大小一致；完成上传时省略
+	Upload *PresignedURLResponse `json:"upload,omitempty"`
+
+	// @Description 图片的缩略图等变体及其下载地址，图片处理完成后返回
+	Variants []FileVariantResponse `json:"variants,omitempty"`
+}
+
+// FileDownloadResponse 文件下载响应
+// @Description 文件信息和下载地址
+type FileDownloadResponse struct {
+	// @Description 文件信息
+	File FileResponse `json:"file"`
+
+	// @Description 下载地址，以 GET 请求下载文件内容
+	Download PresignedURLResponse `json:"download"`
+
+	// @Description 图片的缩略图等变体及其下载地址
+	Variants []FileVariantResponse `json:"variants,omitempty"`
+}
+
+// FinalizeUploadRequest 完成断点续传请求
+// @Description 客户端计算的文件内容校验和，与服务器组装的内容一致时完成上传
+type FinalizeUploadRequest struct {
+	// @Description 文件内容的 SHA-256 校验和（十六进制）
+	// @Example "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	ChecksumSHA256 string `json:"checksum_sha256" binding:"required,len=64,hexadecimal" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+}
+
+// UploadSessionResponse 断点续传会话响应
+// @Description 断点续传会话，文件内容从 offset 起按分块经 PATCH 请求上传
+type UploadSessionResponse struct {
+	// @Description 会话ID，隐藏内部ID时省略
+	// @Example 1
+	ID idgen.ID `json:"id,omitempty" example:"1"`
+
+	// @Description 公开ID，可代替会话ID在路径中使用
+	// @Example "5d8a3f0e-2b7c-4e1a-9c6d-7f0b1e2a3c4d"
+	PublicID string `json:"public_id" example:"5d8a3f0e-2b7c-4e1a-9c6d-7f0b1e2a3c4d"`
+
+	// @Description 文件信息
+	File FileResponse `json:"file"`
+
+	// @Description 已接收的字节数，即下一个分块的偏移量
+	// @Example 8388608
+	Offset int64 `json:"offset" example:"8388608"`
+
+	// @Description 文件大小（字节）
+	// @Example 104857600
+	Size int64 `json:"size" example:"104857600"`
+
+	// @Description 单个分块的最大字节数
+	// @Example 8388608
+	MaxChunkSize int64 `json:"max_chunk_size" example:"8388608"`
+
+	// @Description 会话过期时间，过期后未完成的上传被删除
+	ExpiresAt timestamp.Time `json:"expires_at"`
+}
@@ -0,0 +1,35 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// ImpersonateRequest 模拟登录请求
+// @Description 以目标用户的身份、角色和权限签发短期令牌，代为操作的管理员记录在令牌中，模拟期间的每个请求都写入审计日志
+type ImpersonateRequest struct {
+	// @Description 被模拟的用户ID
+	// @Example "1001"
+	UserID string `json:"user_id" binding:"required,max=100" example:"1001"`
+
+	// @Description 模拟原因，如工单号，记录在审计日志中
+	// @Example "TICKET-1234 排查应用配置问题"
+	Reason string `json:"reason" binding:"required,max=500" example:"TICKET-1234 排查应用配置问题"`
+}
+
+// ImpersonationResponse 模拟登录响应
+// @Description 模拟令牌，过期后需重新签发
+type ImpersonationResponse struct {
+	// @Description 模拟令牌，以Bearer方式使用
+	// @Example "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+
+	// @Description 过期时间
+	// @Example "2024-01-01T00:15:00Z"
+	ExpiresAt timestamp.Time `json:"expires_at" example:"2024-01-01T00:15:00Z"`
+
+	// @Description 被模拟的用户ID
+	// @Example "1001"
+	UserID string `json:"user_id" example:"1001"`
+
+	// @Description 代为操作的管理员用户ID
+	// @Example "1"
+	ImpersonatorID string `json:"impersonator_id" example:"1"`
+}
@@ -0,0 +1,137 @@
+package v1
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// CreateInvitationRequest 创建邀请请求
+// @Description 邀请用户通过邮件加入，可预设其角色和组织成员身份
+type CreateInvitationRequest struct {
+	// @Description 被邀请用户的邮箱，接受邀请后作为其用户ID
+	// @Example "alice@example.com"
+	Email string `json:"email" binding:"required,email,max=254" example:"alice@example.com"`
+
+	// @Description 被邀请用户的角色，为空时使用 invitations.default_role；只有管理员可以指定其他角色
+	// @Example "user"
+	Role string `json:"role" binding:"omitempty,max=50" example:"user"`
+
+	// @Description 被邀请用户的权限，只有管理员可以指定
+	// @Example ["app:read"]
+	Permissions []string `json:"permissions" binding:"omitempty,max=50,dive,max=100" example:"app:read"`
+
+	// @Description 接受邀请后加入的组织ID，为空时不加入组织；组织管理员只能邀请用户加入其管理的组织
+	// @Example 1
+	OrgID idgen.ID `json:"org_id" example:"1"`
+
+	// @Description 在组织中的角色：owner、admin 或 member，只有所有者可以邀请所有者
+	// @Example "member"
+	OrgRole string `json:"org_role" binding:"required_with=OrgID,omitempty,oneof=owner admin member" example:"member"`
+}
+
+// ListInvitationsRequest 邀请查询参数
+// @Description 邀请列表的查询条件
+type ListInvitationsRequest struct {
+	// @Description 组织ID，为空时查询全部邀请，需要管理员角色
+	// @Example 1
+	OrgID idgen.ID `json:"org_id" form:"org_id" example:"1"`
+
+	// @Description 状态：pending、accepted、revoked 或 expired，为空时不限制
+	// @Example "pending"
+	Status string `json:"status" form:"status" binding:"omitempty,oneof=pending accepted revoked expired" example:"pending"`
+}
+
+// AcceptInvitationRequest 接受邀请请求
+// @Description 以邀请邮件中的令牌接受邀请
+type AcceptInvitationRequest struct {
+	// @Description 邀请令牌
+	// @Example "7.1704067200.Zm9v..."
+	Token string `json:"token" binding:"required,max=200" example:"7.1704067200.Zm9v..."`
+
+	// @Description 用户名，创建用户时使用，为空时使用邀请的邮箱
+	// @Example "alice"
+	Username string `json:"username" binding:"omitempty,max=100" example:"alice"`
+
+	// @Description 密码；邀请的邮箱已注册时须为该用户的密码，否则为新用户的密码，至少 registration.min_password_length 个字符
+	// @Example "correct horse battery staple"
+	Password string `json:"password" binding:"required,max=72" example:"correct horse battery staple"`
+}
+
+// InvitationResponse 邀请响应
+// @Description 邀请信息，令牌不会返回
+type InvitationResponse struct {
+	// @Description 邀请ID，隐藏内部ID时省略
+	// @Example 7
+	ID idgen.ID `json:"id,omitempty" example:"7"`
+
+	// @Description 公开ID，可代替邀请ID在路径中使用
+	// @Example "3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"
+	PublicID string `json:"public_id" example:"3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"`
+
+	// @Description 被邀请用户的邮箱
+	// @Example "alice@example.com"
+	Email string `json:"email" example:"alice@example.com"`
+
+	// @Description 被邀请用户的角色
+	// @Example "user"
+	Role string `json:"role" example:"user"`
+
+	// @Description 被邀请用户的权限
+	// @Example ["app:read"]
+	Permissions []string `json:"permissions,omitempty" example:"app:read"`
+
+	// @Description 加入的组织ID，隐藏内部ID时省略
+	// @Example 1
+	OrgID idgen.ID `json:"org_id,omitempty" example:"1"`
+
+	// @Description 在组织中的角色
+	// @Example "member"
+	OrgRole string `json:"org_role,omitempty" example:"member"`
+
+	// @Description 邀请人用户ID
+	// @Example "1001"
+	InvitedBy string `json:"invited_by" example:"1001"`
+
+	// @Description 状态：pending、accepted、revoked 或 expired
+	// @Example "pending"
+	Status string `json:"status" example:"pending"`
+
+	// @Description 过期时间
+	ExpiresAt timestamp.Time `json:"expires_at"`
+
+	// @Description 接受邀请的用户ID
+	// @Example "alice@example.com"
+	AcceptedBy string `json:"accepted_by,omitempty" example:"alice@example.com"`
+
+	// @Description 接受时间
+	AcceptedAt *timestamp.Time `json:"accepted_at,omitempty"`
+
+	// @Description 撤销时间
+	RevokedAt *timestamp.Time `json:"revoked_at,omitempty"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+}
+
+// CreateInvitationResponse 创建邀请响应
+// @Description 创建的邀请；未启用邮件时返回邀请令牌，由邀请人转交被邀请用户
+type CreateInvitationResponse struct {
+	InvitationResponse
+
+	// @Description 邀请令牌，只在未启用邮件时返回一次
+	// @Example "7.1704067200.Zm9v..."
+	Token string `json:"token,omitempty" example:"7.1704067200.Zm9v..."`
+}
+
+// AcceptInvitationResponse 接受邀请响应
+// @Description 接受的邀请和被邀请的用户；启用会话时同时返回该用户的会话令牌
+type AcceptInvitationResponse struct {
+	// @Description 接受的邀请
+	Invitation InvitationResponse `json:"invitation"`
+
+	// @Description 被邀请的用户，新建或已注册的用户
+	User UserResponse `json:"user"`
+
+	// @Description 会话令牌，未启用会话时为空
+	Tokens *SessionTokensResponse `json:"tokens,omitempty"`
+}
@@ -0,0 +1,80 @@
+package v1
+
+// MeResponse 当前用户响应
+// @Description 访问令牌中的当前用户，前端据此初始化而无需知道内部ID
+type MeResponse struct {
+	// @Description 用户ID
+	// @Example "1001"
+	UserID string `json:"user_id" example:"1001"`
+
+	// @Description 用户名
+	// @Example "alice"
+	Username string `json:"username" example:"alice"`
+
+	// @Description 角色
+	// @Example "user"
+	Role string `json:"role" example:"user"`
+
+	// @Description 权限
+	// @Example ["application:read"]
+	Permissions []string `json:"permissions" example:"application:read"`
+
+	// @Description 访问令牌所属的会话ID，不属于会话的令牌为空
+	// @Example "12"
+	SessionID string `json:"session_id,omitempty" example:"12"`
+
+	// @Description 是否为模拟请求
+	// @Example false
+	Impersonated bool `json:"impersonated" example:"false"`
+
+	// @Description 模拟请求中代为操作的用户ID
+	// @Example "1"
+	ImpersonatorID string `json:"impersonator_id,omitempty" example:"1"`
+}
+
+// MePermissionsResponse 当前用户权限响应
+// @Description 当前用户的角色和权限，前端据此显示或隐藏功能
+type MePermissionsResponse struct {
+	// @Description 角色
+	// @Example "user"
+	Role string `json:"role" example:"user"`
+
+	// @Description 权限
+	// @Example ["application:read"]
+	Permissions []string `json:"permissions" example:"application:read"`
+}
+
+// MePreferencesResponse 当前用户偏好响应
+// @Description 当前用户的偏好设置，未设置的偏好为默认值
+type MePreferencesResponse struct {
+	// @Description 偏好语言
+	// @Example "zh-CN"
+	Language string `json:"language" example:"zh-CN"`
+
+	// @Description 偏好时区，IANA时区名称
+	// @Example "Asia/Shanghai"
+	TimeZone string `json:"time_zone" example:"Asia/Shanghai"`
+
+	// @Description 接收通知的渠道，为空时使用所有已启用的渠道
+	// @Example ["sms"]
+	NotificationChannels []string `json:"notification_channels" example:"sms"`
+
+	// @Description 通知渠道偏好
+	Notifications []NotificationPreferenceResponse `json:"notifications"`
+}
+
+// UpdateMyPreferencesRequest 更新当前用户偏好请求
+// @Description 部分更新当前用户的偏好：省略的字段保持不变，空值恢复默认值
+type UpdateMyPreferencesRequest struct {
+	// @Description 偏好语言，支持的语言之一
+	// @Example "en-US"
+	Language *string `json:"language" example:"en-US"`
+
+	// @Description 偏好时区，IANA时区名称
+	// @Example "Asia/Shanghai"
+	TimeZone *string `json:"time_zone" example:"Asia/Shanghai"`
+
+	// @Description 接收通知的渠道：sms、push 或 webhook，空数组表示所有已启用的渠道
+	// @Example ["sms"]
+	NotificationChannels *[]string `json:"notification_channels" example:"sms"`
+}
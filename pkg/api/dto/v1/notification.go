@@ -0,0 +1,60 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// SetNotificationPreferenceRequest 设置通知渠道偏好请求
+// @Description 设置当前用户在一个通知渠道上的地址和开关，已存在时整体替换
+type SetNotificationPreferenceRequest struct {
+	// @Description 渠道地址：sms 为E.164格式手机号，push 为设备令牌，webhook 为http(s)地址
+	// @Example "+8613800138000"
+	Address string `json:"address" binding:"required,max=4096" example:"+8613800138000"`
+
+	// @Description 是否接收该渠道的通知，默认接收
+	// @Example true
+	Enabled *bool `json:"enabled" example:"true"`
+
+	// @Description 通知语言，为空时使用默认语言
+	// @Example "zh-CN"
+	Language string `json:"language" binding:"omitempty,max=10" example:"zh-CN"`
+}
+
+// NotificationPreferenceResponse 通知渠道偏好响应
+// @Description 用户在一个通知渠道上的地址和开关
+type NotificationPreferenceResponse struct {
+	// @Description 通知渠道：sms、push 或 webhook
+	// @Example "sms"
+	Channel string `json:"channel" example:"sms"`
+
+	// @Description 渠道地址
+	// @Example "+8613800138000"
+	Address string `json:"address" example:"+8613800138000"`
+
+	// @Description 是否接收该渠道的通知
+	// @Example true
+	Enabled bool `json:"enabled" example:"true"`
+
+	// @Description 通知语言，为空时使用默认语言
+	// @Example "zh-CN"
+	Language string `json:"language,omitempty" example:"zh-CN"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
+
+// NotificationDeliveryResponse 通知发送结果
+// @Description 通知在一个渠道上的发送结果
+type NotificationDeliveryResponse struct {
+	// @Description 通知渠道
+	// @Example "sms"
+	Channel string `json:"channel" example:"sms"`
+
+	// @Description 发送状态：sent、failed、rate_limited 或 unavailable（渠道未配置服务商）
+	// @Example "sent"
+	Status string `json:"status" example:"sent"`
+
+	// @Description 失败原因
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,100 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// ListOperationsRequest 任务列表请求
+// @Description 获取任务列表的请求参数
+type ListOperationsRequest struct {
+	PageRequest
+
+	// @Description 任务类型过滤
+	// @Example "application_import"
+	Type string `json:"type" form:"type" binding:"omitempty,oneof=application_backup application_import application_batch_delete email_delivery file_processing" example:"application_import"`
+
+	// @Description 任务状态过滤
+	// @Example "running"
+	Status string `json:"status" form:"status" binding:"omitempty,oneof=pending running completed failed" example:"running"`
+}
+
+// OperationResponse 任务响应
+// @Description 在后台执行的长时间运行任务
+type OperationResponse struct {
+	// @Description 任务ID
+	// @Example "4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"
+	ID string `json:"id" example:"4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"`
+
+	// @Description 任务类型：application_backup、application_import、application_batch_delete、email_delivery 或 file_processing
+	// @Example "application_import"
+	Type string `json:"type" example:"application_import"`
+
+	// @Description 任务状态：pending、running、completed 或 failed
+	// @Example "completed"
+	Status string `json:"status" example:"completed"`
+
+	// @Description 进度百分比，完成时为100
+	// @Example 100
+	Progress int `json:"progress" example:"100"`
+
+	// @Description 进度说明
+	// @Example "writing archive"
+	Message string `json:"message,omitempty" example:"writing archive"`
+
+	// @Description 任务完成后的结果，结构由任务类型决定，如导入和批量删除为批量操作结果
+	Result json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+
+	// @Description 失败原因
+	// @Example ""
+	Error string `json:"error,omitempty" example:""`
+
+	// @Description 发起任务的用户ID
+	// @Example "42"
+	Actor string `json:"actor,omitempty" example:"42"`
+
+	// @Description 创建时间
+	// @Example "2024-01-01T12:00:00Z"
+	CreatedAt timestamp.Time `json:"created_at" example:"2024-01-01T12:00:00Z"`
+
+	// @Description 更新时间
+	// @Example "2024-01-01T12:00:03Z"
+	UpdatedAt timestamp.Time `json:"updated_at" example:"2024-01-01T12:00:03Z"`
+
+	// @Description 完成时间
+	// @Example "2024-01-01T12:00:05Z"
+	CompletedAt *timestamp.Time `json:"completed_at,omitempty" example:"2024-01-01T12:00:05Z"`
+}
+
+// OperationEventResponse 任务进度事件
+// @Description 长时间运行任务的一次状态或进度变化，作为SSE事件的data发送
+type OperationEventResponse struct {
+	// @Description 任务ID
+	// @Example "4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"
+	OperationID string `json:"operation_id" example:"4f1c2a9e-7d3b-4c55-9b8e-2f6a1d0c3e7b"`
+
+	// @Description 事件序号，从1开始递增，同时作为SSE事件ID
+	// @Example 2
+	Sequence uint64 `json:"sequence" example:"2"`
+
+	// @Description 任务类型
+	// @Example "application_backup"
+	Type string `json:"type" example:"application_backup"`
+
+	// @Description 任务状态：pending、running、completed 或 failed
+	// @Example "running"
+	Status string `json:"status" example:"running"`
+
+	// @Description 进度百分比，任务结束时为100
+	// @Example 50
+	Progress int `json:"progress" example:"50"`
+
+	// @Description 进度说明或失败原因
+	// @Example "uploading archive"
+	Message string `json:"message,omitempty" example:"uploading archive"`
+
+	// @Description 事件时间
+	// @Example "2024-01-01T12:00:00Z"
+	Timestamp timestamp.Time `json:"timestamp" example:"2024-01-01T12:00:00Z"`
+}
@@ -0,0 +1,102 @@
+package v1
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// CreateOrganizationRequest 创建组织请求
+// @Description 创建组织，创建者成为其所有者
+type CreateOrganizationRequest struct {
+	// @Description 组织名称，小写字母、数字或中划线，以字母开头
+	// @Example "acme"
+	Name string `json:"name" binding:"required,max=50" example:"acme"`
+
+	// @Description 组织描述，最多500个字符
+	// @Example "ACME研发团队"
+	Description string `json:"description" binding:"omitempty,max=500" example:"ACME研发团队"`
+}
+
+// UpdateOrganizationRequest 更新组织请求
+// @Description 替换组织的名称和描述
+type UpdateOrganizationRequest struct {
+	// @Description 组织名称，小写字母、数字或中划线，以字母开头
+	// @Example "acme"
+	Name string `json:"name" binding:"required,max=50" example:"acme"`
+
+	// @Description 组织描述，最多500个字符
+	// @Example "ACME研发团队"
+	Description string `json:"description" binding:"omitempty,max=500" example:"ACME研发团队"`
+}
+
+// OrganizationResponse 组织响应
+// @Description 组织信息及当前用户在其中的角色
+type OrganizationResponse struct {
+	// @Description 组织ID，作为请求头X-Organization-ID的值；隐藏内部ID时省略
+	// @Example 1
+	ID idgen.ID `json:"id,omitempty" example:"1"`
+
+	// @Description 公开ID，可代替组织ID在路径和请求头X-Organization-ID中使用
+	// @Example "3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"
+	PublicID string `json:"public_id" example:"3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"`
+
+	// @Description 组织名称
+	// @Example "acme"
+	Name string `json:"name" example:"acme"`
+
+	// @Description 组织描述
+	// @Example "ACME研发团队"
+	Description string `json:"description" example:"ACME研发团队"`
+
+	// @Description 当前用户在组织中的角色：owner、admin 或 member
+	// @Example "owner"
+	Role string `json:"role,omitempty" example:"owner"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
+
+// AddOrganizationMemberRequest 添加组织成员请求
+// @Description 将用户加入组织
+type AddOrganizationMemberRequest struct {
+	// @Description 用户ID
+	// @Example "1002"
+	UserID string `json:"user_id" binding:"required,max=100" example:"1002"`
+
+	// @Description 角色：owner、admin 或 member，只有所有者可以添加所有者
+	// @Example "member"
+	Role string `json:"role" binding:"required,oneof=owner admin member" example:"member"`
+}
+
+// UpdateOrganizationMemberRequest 更新组织成员请求
+// @Description 修改成员在组织中的角色
+type UpdateOrganizationMemberRequest struct {
+	// @Description 角色：owner、admin 或 member，只有所有者可以授予或撤销所有者角色
+	// @Example "admin"
+	Role string `json:"role" binding:"required,oneof=owner admin member" example:"admin"`
+}
+
+// OrganizationMemberResponse 组织成员响应
+// @Description 用户在组织中的成员身份
+type OrganizationMemberResponse struct {
+	// @Description 组织ID，隐藏内部ID时省略
+	// @Example 1
+	OrgID idgen.ID `json:"org_id,omitempty" example:"1"`
+
+	// @Description 用户ID
+	// @Example "1002"
+	UserID string `json:"user_id" example:"1002"`
+
+	// @Description 角色
+	// @Example "member"
+	Role string `json:"role" example:"member"`
+
+	// @Description 加入时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
@@ -0,0 +1,69 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// CreatePartnerRequest 创建合作方请求
+// @Description 创建合作方并生成签名密钥，密钥只在响应中返回一次
+type CreatePartnerRequest struct {
+	// @Description 合作方名称，作为请求头X-Signature-Key的值；小写字母、数字、下划线或中划线，以字母开头
+	// @Example "acme"
+	Name string `json:"name" binding:"required,max=50" example:"acme"`
+
+	// @Description 合作方描述，最多500个字符
+	// @Example "ACME订单同步"
+	Description string `json:"description" binding:"omitempty,max=500" example:"ACME订单同步"`
+
+	// @Description 是否启用，默认启用
+	// @Example true
+	Enabled *bool `json:"enabled" example:"true"`
+}
+
+// UpdatePartnerRequest 更新合作方请求
+// @Description 替换合作方的描述和启用状态，停用的合作方的签名请求被拒绝
+type UpdatePartnerRequest struct {
+	// @Description 合作方描述，最多500个字符
+	// @Example "ACME订单同步"
+	Description string `json:"description" binding:"omitempty,max=500" example:"ACME订单同步"`
+
+	// @Description 是否启用
+	// @Example false
+	Enabled *bool `json:"enabled" binding:"required" example:"false"`
+}
+
+// PartnerResponse 合作方响应
+// @Description 合作方信息，不包含签名密钥
+type PartnerResponse struct {
+	// @Description 合作方名称
+	// @Example "acme"
+	Name string `json:"name" example:"acme"`
+
+	// @Description 合作方描述
+	// @Example "ACME订单同步"
+	Description string `json:"description" example:"ACME订单同步"`
+
+	// @Description 是否启用
+	// @Example true
+	Enabled bool `json:"enabled" example:"true"`
+
+	// @Description 上次轮换密钥的时间，从未轮换时为空
+	SecretRotatedAt *timestamp.Time `json:"secret_rotated_at,omitempty"`
+
+	// @Description 轮换前的密钥失效时间，在此之前两个密钥均可签名
+	PreviousSecretExpiresAt *timestamp.Time `json:"previous_secret_expires_at,omitempty"`
+
+	// @Description 创建时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 更新时间
+	UpdatedAt timestamp.Time `json:"updated_at"`
+}
+
+// PartnerSecretResponse 合作方密钥响应
+// @Description 创建合作方或轮换密钥时返回的新密钥，之后无法再次获取
+type PartnerSecretResponse struct {
+	PartnerResponse
+
+	// @Description 签名密钥，用于计算请求头X-Signature的HMAC-SHA256
+	// @Example "5f2b8c0e9a7d4e1f..."
+	Secret string `json:"secret" example:"5f2b8c0e9a7d4e1f..."`
+}
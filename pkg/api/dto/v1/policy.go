@@ -0,0 +1,85 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// PublishPolicyRequest 发布政策文档请求
+// @Description 以一种语言发布政策的当前版本或下一版本，下一版本发布后用户需重新接受
+type PublishPolicyRequest struct {
+	// @Description 版本号，为当前版本时增加一种语言，为当前版本加一时发布新版本
+	// @Example 2
+	Version int `json:"version" binding:"required,min=1" example:"2"`
+
+	// @Description 文档语言
+	// @Example "zh-CN"
+	Language string `json:"language" binding:"required,max=10" example:"zh-CN"`
+
+	// @Description 标题
+	// @Example "服务条款"
+	Title string `json:"title" binding:"required,max=200" sanitize:"trim,collapse,strip_control" example:"服务条款"`
+
+	// @Description 正文
+	// @Example "欢迎使用本服务……"
+	Content string `json:"content" binding:"required" sanitize:"trim,strip_control,html" example:"欢迎使用本服务……"`
+
+	// @Description 相对上一版本的变更说明
+	// @Example "新增数据保留条款"
+	Summary string `json:"summary" sanitize:"trim,strip_control" example:"新增数据保留条款"`
+}
+
+// AcceptPolicyRequest 接受政策请求
+// @Description 接受政策的当前版本，版本号须与获取到的版本一致
+type AcceptPolicyRequest struct {
+	// @Description 接受的版本号
+	// @Example 2
+	Version int `json:"version" binding:"required,min=1" example:"2"`
+}
+
+// PolicyResponse 政策文档响应
+// @Description 政策某一版本在一种语言下的文档
+type PolicyResponse struct {
+	// @Description 政策名称
+	// @Example "terms"
+	Name string `json:"name" example:"terms"`
+
+	// @Description 版本号
+	// @Example 2
+	Version int `json:"version" example:"2"`
+
+	// @Description 文档语言，没有请求语言的文档时为默认语言或其他已发布的语言
+	// @Example "zh-CN"
+	Language string `json:"language" example:"zh-CN"`
+
+	// @Description 标题
+	// @Example "服务条款"
+	Title string `json:"title" example:"服务条款"`
+
+	// @Description 正文
+	// @Example "欢迎使用本服务……"
+	Content string `json:"content" example:"欢迎使用本服务……"`
+
+	// @Description 相对上一版本的变更说明
+	// @Example "新增数据保留条款"
+	Summary string `json:"summary,omitempty" example:"新增数据保留条款"`
+
+	// @Description 发布时间
+	PublishedAt timestamp.Time `json:"published_at"`
+}
+
+// PolicyConsentResponse 政策同意记录响应
+// @Description 用户接受的政策版本
+type PolicyConsentResponse struct {
+	// @Description 政策名称
+	// @Example "terms"
+	Policy string `json:"policy" example:"terms"`
+
+	// @Description 接受的版本号
+	// @Example 2
+	Version int `json:"version" example:"2"`
+
+	// @Description 接受时的语言
+	// @Example "zh-CN"
+	Language string `json:"language,omitempty" example:"zh-CN"`
+
+	// @Description 接受时间
+	AcceptedAt timestamp.Time `json:"accepted_at"`
+}
@@ -0,0 +1,202 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateLayout 时间范围中只有日期时的格式
+const dateLayout = "2006-01-02"
+
+// TimeRange 时间范围查询参数，格式为 from,to，两端均包含且可以省略其一，如 2024-01-01,2024-01-31 或 2024-01-01T08:00:00Z,；
+// 时间为RFC3339格式或日期，只有日期的结束时间包含当天
+type TimeRange struct {
+	From time.Time `json:"-" form:"-"`
+	To   time.Time `json:"-" form:"-"`
+}
+
+// UnmarshalText 解析 from,to 格式的时间范围，起始时间晚于结束时间时返回错误
+func (r *TimeRange) UnmarshalText(text []byte) error {
+	from, to, ok := strings.Cut(string(text), ",")
+	if !ok {
+		return errors.New("时间范围格式为 from,to")
+	}
+
+	var result TimeRange
+	var err error
+	if result.From, err = parseRangeTime(from, false); err != nil {
+		return err
+	}
+	if result.To, err = parseRangeTime(to, true); err != nil {
+		return err
+	}
+	if !result.From.IsZero() && !result.To.IsZero() && result.From.After(result.To) {
+		return errors.New("起始时间晚于结束时间")
+	}
+	*r = result
+	return nil
+}
+
+// MarshalText 以 from,to 格式输出时间范围
+func (r TimeRange) MarshalText() ([]byte, error) {
+	var from, to string
+	if !r.From.IsZero() {
+		from = r.From.Format(time.RFC3339Nano)
+	}
+	if !r.To.IsZero() {
+		to = r.To.Format(time.RFC3339Nano)
+	}
+	return []byte(from + "," + to), nil
+}
+
+// IsZero 两端都省略时返回true
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// parseRangeTime 解析时间范围的一端，空字符串返回零值；end 为true时只有日期的时间取当天最后一刻
+func parseRangeTime(value string, end bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if date, err := time.Parse(dateLayout, value); err == nil {
+		if end {
+			date = date.Add(24*time.Hour - time.Nanosecond)
+		}
+		return date, nil
+	}
+	// 未编码的 + 在查询字符串中被解码为空格，如 2024-01-01T08:00:00+08:00
+	t, err := time.Parse(time.RFC3339, strings.ReplaceAll(value, " ", "+"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("时间 %q 不是RFC3339格式或日期", value)
+	}
+	return t, nil
+}
+
+// IDList 逗号分隔的ID列表，如 1,2,3；重复的ID只保留一个
+type IDList []uint
+
+// UnmarshalText 解析逗号分隔的ID
+func (l *IDList) UnmarshalText(text []byte) error {
+	var ids IDList
+	for _, value := range splitList(string(text)) {
+		id, err := strconv.ParseUint(value, 10, 0)
+		if err != nil || id == 0 {
+			return fmt.Errorf("ID %q 不是正整数", value)
+		}
+		if !slices.Contains(ids, uint(id)) {
+			ids = append(ids, uint(id))
+		}
+	}
+	*l = ids
+	return nil
+}
+
+// MarshalText 以逗号分隔输出ID
+func (l IDList) MarshalText() ([]byte, error) {
+	values := make([]string, len(l))
+	for i, id := range l {
+		values[i] = strconv.FormatUint(uint64(id), 10)
+	}
+	return []byte(strings.Join(values, ",")), nil
+}
+
+// EnumSet 逗号分隔的枚举值集合，如 active,inactive；取值范围由字段的 binding:"dive,oneof=..." 校验
+type EnumSet []string
+
+// UnmarshalText 解析逗号分隔的枚举值
+func (s *EnumSet) UnmarshalText(text []byte) error {
+	var values EnumSet
+	for _, value := range splitList(string(text)) {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	*s = values
+	return nil
+}
+
+// MarshalText 以逗号分隔输出枚举值
+func (s EnumSet) MarshalText() ([]byte, error) {
+	return []byte(strings.Join(s, ",")), nil
+}
+
+// Contains 集合包含 value 时返回true
+func (s EnumSet) Contains(value string) bool {
+	return slices.Contains(s, value)
+}
+
+// SortField 排序表达式中的一项
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// SortExpression 排序表达式，逗号分隔的 字段[:asc|desc]，如 name:asc,created_at:desc，方向省略时为升序；
+// 可排序的字段由字段的 binding:"sortable=..." 校验
+type SortExpression []SortField
+
+// UnmarshalText 解析排序表达式，同一字段出现多次时返回错误
+func (e *SortExpression) UnmarshalText(text []byte) error {
+	var expr SortExpression
+	for _, item := range splitList(string(text)) {
+		name, direction, _ := strings.Cut(item, ":")
+		var desc bool
+		switch strings.ToLower(direction) {
+		case "", "asc":
+		case "desc":
+			desc = true
+		default:
+			return fmt.Errorf("排序方向 %q 只能是 asc 或 desc", direction)
+		}
+		if expr.has(name) {
+			return fmt.Errorf("排序字段 %s 重复", name)
+		}
+		expr = append(expr, SortField{Field: name, Desc: desc})
+	}
+	*e = expr
+	return nil
+}
+
+// MarshalText 以 字段:方向 的形式输出排序表达式
+func (e SortExpression) MarshalText() ([]byte, error) {
+	items := make([]string, len(e))
+	for i, f := range e {
+		direction := "asc"
+		if f.Desc {
+			direction = "desc"
+		}
+		items[i] = f.Field + ":" + direction
+	}
+	return []byte(strings.Join(items, ",")), nil
+}
+
+// Fields 返回排序的字段名，由 sortable 校验器使用
+func (e SortExpression) Fields() []string {
+	names := make([]string, len(e))
+	for i, f := range e {
+		names[i] = f.Field
+	}
+	return names
+}
+
+// has 表达式包含字段时返回true
+func (e SortExpression) has(name string) bool {
+	return slices.ContainsFunc(e, func(f SortField) bool { return f.Field == name })
+}
+
+// splitList 按逗号拆分列表，去掉空白和空项
+func splitList(text string) []string {
+	var items []string
+	for _, item := range strings.Split(text, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,56 @@
+package v1
+
+import "github.com/make-bin/server-tpl/pkg/utils/timestamp"
+
+// QuotaUsageResponse 主体的配额用量响应
+// @Description 主体在各配额等级当前周期内的用量
+type QuotaUsageResponse struct {
+	// @Description 配额主体，已认证用户为 user:<id>，未认证请求为 ip:<客户端IP>
+	// @Example "user:42"
+	Principal string `json:"principal" example:"user:42"`
+
+	// @Description 有限额的配额等级，按名称排列
+	Classes []QuotaClassUsageResponse `json:"classes"`
+}
+
+// QuotaClassUsageResponse 配额等级的用量
+// @Description 一个配额等级在各周期内的用量
+type QuotaClassUsageResponse struct {
+	// @Description 配额等级
+	// @Example "default"
+	Class string `json:"class" example:"default"`
+
+	// @Description 有限额的周期
+	Windows []QuotaWindowResponse `json:"windows"`
+}
+
+// QuotaWindowResponse 配额周期的用量
+// @Description 当前自然日或自然月（UTC）内的请求数
+type QuotaWindowResponse struct {
+	// @Description 周期：day 或 month
+	// @Example "day"
+	Period string `json:"period" example:"day"`
+
+	// @Description 周期内允许的请求数
+	// @Example 10000
+	Limit int64 `json:"limit" example:"10000"`
+
+	// @Description 周期内已计入的请求数，包括超限被拒绝的请求
+	// @Example 1234
+	Used int64 `json:"used" example:"1234"`
+
+	// @Description 周期内剩余的请求数
+	// @Example 8766
+	Remaining int64 `json:"remaining" example:"8766"`
+
+	// @Description 周期重置时间
+	// @Example "2024-01-02T00:00:00Z"
+	ResetAt timestamp.Time `json:"reset_at" example:"2024-01-02T00:00:00Z"`
+}
+
+// ResetQuotaRequest 重置配额用量请求
+type ResetQuotaRequest struct {
+	// @Description 只重置该配额等级，为空时重置全部等级
+	// @Example "default"
+	Class string `form:"class" json:"class" example:"default"`
+}
@@ -0,0 +1,23 @@
+package v1
+
+// SchemaTypesResponse 文档类型列表响应
+// @Description 提供JSON Schema和示例的DTO类型名称
+type SchemaTypesResponse struct {
+	// @Description 类型名称，按字母排序
+	// @Example ["ApplicationResponse","CreateApplicationRequest"]
+	Types []string `json:"types" example:"ApplicationResponse,CreateApplicationRequest"`
+}
+
+// SchemaResponse 文档类型的模式响应
+// @Description DTO类型的JSON Schema（2020-12）及由example标签生成的示例，用于消费者驱动的契约测试
+type SchemaResponse struct {
+	// @Description 类型名称
+	// @Example "ApplicationResponse"
+	Name string `json:"name" example:"ApplicationResponse"`
+
+	// @Description JSON Schema，嵌套类型在 $defs 中
+	Schema interface{} `json:"schema" swaggertype:"object"`
+
+	// @Description 符合该模式的示例数据
+	Example interface{} `json:"example" swaggertype:"object"`
+}
@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// SessionResponse 会话响应
+// @Description 用户在一台设备上的登录会话，不包含刷新令牌
+type SessionResponse struct {
+	// @Description 会话ID，隐藏内部ID时省略
+	// @Example 12
+	ID idgen.ID `json:"id,omitempty" example:"12"`
+
+	// @Description 公开ID，可代替会话ID在路径中使用
+	// @Example "3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"
+	PublicID string `json:"public_id" example:"3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"`
+
+	// @Description 设备最近一次请求的User-Agent
+	// @Example "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"
+	UserAgent string `json:"user_agent" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"`
+
+	// @Description 设备最近一次请求的客户端IP
+	// @Example "203.0.113.10"
+	IP string `json:"ip" example:"203.0.113.10"`
+
+	// @Description 是否为当前请求使用的会话
+	// @Example true
+	Current bool `json:"current" example:"true"`
+
+	// @Description 登录时间
+	CreatedAt timestamp.Time `json:"created_at"`
+
+	// @Description 最近访问时间，按 security.sessions.touch_interval 记录
+	LastSeenAt timestamp.Time `json:"last_seen_at"`
+
+	// @Description 刷新令牌过期时间，过期后需重新登录
+	ExpiresAt timestamp.Time `json:"expires_at"`
+}
+
+// RefreshSessionRequest 刷新会话请求
+// @Description 以刷新令牌换取新的访问令牌和刷新令牌，原刷新令牌随即失效
+type RefreshSessionRequest struct {
+	// @Description 登录或上次刷新时返回的刷新令牌
+	// @Example "12.q3J9..."
+	RefreshToken string `json:"refresh_token" binding:"required,max=200" example:"12.q3J9..."`
+}
+
+// SessionTokensResponse 会话令牌响应
+// @Description 会话的访问令牌和刷新令牌，刷新令牌只在此响应中返回一次
+type SessionTokensResponse struct {
+	// @Description 访问令牌，以Bearer方式使用
+	// @Example "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."
+	AccessToken string `json:"access_token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+
+	// @Description 访问令牌过期时间
+	// @Example "2024-01-01T00:15:00Z"
+	AccessTokenExpiresAt timestamp.Time `json:"access_token_expires_at" example:"2024-01-01T00:15:00Z"`
+
+	// @Description 刷新令牌，每次刷新后替换，已替换的令牌再次使用会终止会话
+	// @Example "12.q3J9..."
+	RefreshToken string `json:"refresh_token" example:"12.q3J9..."`
+
+	// @Description 刷新令牌过期时间
+	// @Example "2024-01-31T00:00:00Z"
+	RefreshTokenExpiresAt timestamp.Time `json:"refresh_token_expires_at" example:"2024-01-31T00:00:00Z"`
+
+	// @Description 会话ID，隐藏内部ID时省略
+	// @Example 12
+	SessionID idgen.ID `json:"session_id,omitempty" example:"12"`
+
+	// @Description 会话的公开ID
+	// @Example "3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"
+	SessionPublicID string `json:"session_public_id" example:"3f0c9a52-8d4e-4b61-9a7e-2c5d1e8f6b40"`
+}
+
+// RevokeSessionsResponse 终止其他会话响应
+// @Description 被终止的会话数量
+type RevokeSessionsResponse struct {
+	// @Description 被终止的会话数量
+	// @Example 2
+	Revoked int `json:"revoked" example:"2"`
+}
@@ -0,0 +1,206 @@
+// Code generated by "go run ./cmd/gen types"; DO NOT EDIT.
+
+package v1
+
+// Types are the DTO structs by name, whose JSON schemas and example fixtures
+// are served under /api/v1/_schema
+var Types = map[string]interface{}{
+	"AcceptInvitationRequest":                    AcceptInvitationRequest{},
+	"AcceptInvitationResponse":                   AcceptInvitationResponse{},
+	"AcceptInvitationResponseEnvelope":           AcceptInvitationResponseEnvelope{},
+	"AcceptPolicyRequest":                        AcceptPolicyRequest{},
+	"AddOrganizationMemberRequest":               AddOrganizationMemberRequest{},
+	"AnalyticsEntriesResponse":                   AnalyticsEntriesResponse{},
+	"AnalyticsEntriesResponseEnvelope":           AnalyticsEntriesResponseEnvelope{},
+	"AnalyticsEntryResponse":                     AnalyticsEntryResponse{},
+	"AnalyticsQueryRequest":                      AnalyticsQueryRequest{},
+	"ApplicationBackupRequest":                   ApplicationBackupRequest{},
+	"ApplicationBackupResponse":                  ApplicationBackupResponse{},
+	"ApplicationBackupResponseEnvelope":          ApplicationBackupResponseEnvelope{},
+	"ApplicationBackupResponsePage":              ApplicationBackupResponsePage{},
+	"ApplicationBackupResponsePageEnvelope":      ApplicationBackupResponsePageEnvelope{},
+	"ApplicationListResponse":                    ApplicationListResponse{},
+	"ApplicationResponse":                        ApplicationResponse{},
+	"ApplicationResponseEnvelope":                ApplicationResponseEnvelope{},
+	"ApplicationResponsePage":                    ApplicationResponsePage{},
+	"ApplicationResponsePageEnvelope":            ApplicationResponsePageEnvelope{},
+	"ApplicationRestoreRequest":                  ApplicationRestoreRequest{},
+	"ApplicationRevisionResponse":                ApplicationRevisionResponse{},
+	"ApplicationRevisionResponsePage":            ApplicationRevisionResponsePage{},
+	"ApplicationRevisionResponsePageEnvelope":    ApplicationRevisionResponsePageEnvelope{},
+	"ApplicationSnapshotResponse":                ApplicationSnapshotResponse{},
+	"ApplicationStatsResponse":                   ApplicationStatsResponse{},
+	"ApplicationStatsResponseEnvelope":           ApplicationStatsResponseEnvelope{},
+	"ApplicationTagsRequest":                     ApplicationTagsRequest{},
+	"ApplicationVariableResponse":                ApplicationVariableResponse{},
+	"ApplicationVariableResponseEnvelope":        ApplicationVariableResponseEnvelope{},
+	"ApplicationVariableResponseListEnvelope":    ApplicationVariableResponseListEnvelope{},
+	"BaseRequest":                                BaseRequest{},
+	"BatchDeleteApplicationsRequest":             BatchDeleteApplicationsRequest{},
+	"BatchIDRequest":                             BatchIDRequest{},
+	"BatchItemRequest":                           BatchItemRequest{},
+	"BatchItemResponse":                          BatchItemResponse{},
+	"BatchRequest":                               BatchRequest{},
+	"BatchResponse":                              BatchResponse{},
+	"BatchResponseEnvelope":                      BatchResponseEnvelope{},
+	"BeanDependencyResponse":                     BeanDependencyResponse{},
+	"BeanResponse":                               BeanResponse{},
+	"BenchRequest":                               BenchRequest{},
+	"BenchResponse":                              BenchResponse{},
+	"BenchResponseEnvelope":                      BenchResponseEnvelope{},
+	"BulkFailureItem":                            BulkFailureItem{},
+	"BulkOperationResponse":                      BulkOperationResponse{},
+	"CacheStatsResponse":                         CacheStatsResponse{},
+	"ConfigSnapshotEnvelope":                     ConfigSnapshotEnvelope{},
+	"ConnectionStatsResponse":                    ConnectionStatsResponse{},
+	"ContainerResponse":                          ContainerResponse{},
+	"ContainerResponseEnvelope":                  ContainerResponseEnvelope{},
+	"CreateApplicationRequest":                   CreateApplicationRequest{},
+	"CreateApplicationVariableRequest":           CreateApplicationVariableRequest{},
+	"CreateFeatureFlagRequest":                   CreateFeatureFlagRequest{},
+	"CreateInvitationRequest":                    CreateInvitationRequest{},
+	"CreateInvitationResponse":                   CreateInvitationResponse{},
+	"CreateInvitationResponseEnvelope":           CreateInvitationResponseEnvelope{},
+	"CreateOrganizationRequest":                  CreateOrganizationRequest{},
+	"CreatePartnerRequest":                       CreatePartnerRequest{},
+	"DashboardResponse":                          DashboardResponse{},
+	"DashboardResponseEnvelope":                  DashboardResponseEnvelope{},
+	"DatastoreHistoryRequest":                    DatastoreHistoryRequest{},
+	"DatastoreHistoryResponse":                   DatastoreHistoryResponse{},
+	"DatastoreHistoryResponseEnvelope":           DatastoreHistoryResponseEnvelope{},
+	"DatastorePointResponse":                     DatastorePointResponse{},
+	"DatastoreSeriesResponse":                    DatastoreSeriesResponse{},
+	"DatastoreStatsResponse":                     DatastoreStatsResponse{},
+	"Envelope":                                   Envelope{},
+	"ErrorDetail":                                ErrorDetail{},
+	"ErrorEnvelope":                              ErrorEnvelope{},
+	"ErrorEventResponse":                         ErrorEventResponse{},
+	"EvaluatedFeatureFlagsResponse":              EvaluatedFeatureFlagsResponse{},
+	"EvaluatedFeatureFlagsResponseEnvelope":      EvaluatedFeatureFlagsResponseEnvelope{},
+	"EventListResponse":                          EventListResponse{},
+	"EventListResponseEnvelope":                  EventListResponseEnvelope{},
+	"EventResponse":                              EventResponse{},
+	"ExperimentAssignmentsResponse":              ExperimentAssignmentsResponse{},
+	"ExperimentAssignmentsResponseEnvelope":      ExperimentAssignmentsResponseEnvelope{},
+	"ExportApplicationVariablesRequest":          ExportApplicationVariablesRequest{},
+	"ExportApplicationsRequest":                  ExportApplicationsRequest{},
+	"FeatureFlagResponse":                        FeatureFlagResponse{},
+	"FeatureFlagResponseEnvelope":                FeatureFlagResponseEnvelope{},
+	"FeatureFlagResponseListEnvelope":            FeatureFlagResponseListEnvelope{},
+	"FieldChangeResponse":                        FieldChangeResponse{},
+	"FileDownloadResponse":                       FileDownloadResponse{},
+	"FileDownloadResponseEnvelope":               FileDownloadResponseEnvelope{},
+	"FileResponse":                               FileResponse{},
+	"FileResponseEnvelope":                       FileResponseEnvelope{},
+	"FileResponsePage":                           FileResponsePage{},
+	"FileResponsePageEnvelope":                   FileResponsePageEnvelope{},
+	"FileUploadRequest":                          FileUploadRequest{},
+	"FileUploadResponse":                         FileUploadResponse{},
+	"FileUploadResponseEnvelope":                 FileUploadResponseEnvelope{},
+	"FileVariantResponse":                        FileVariantResponse{},
+	"FinalizeUploadRequest":                      FinalizeUploadRequest{},
+	"HealthCheckResponse":                        HealthCheckResponse{},
+	"HealthCheckResponseEnvelope":                HealthCheckResponseEnvelope{},
+	"HistogramBucketResponse":                    HistogramBucketResponse{},
+	"IDRequest":                                  IDRequest{},
+	"ImpersonateRequest":                         ImpersonateRequest{},
+	"ImpersonationResponse":                      ImpersonationResponse{},
+	"ImpersonationResponseEnvelope":              ImpersonationResponseEnvelope{},
+	"ImportApplicationVariablesRequest":          ImportApplicationVariablesRequest{},
+	"ImportApplicationVariablesResponse":         ImportApplicationVariablesResponse{},
+	"ImportApplicationVariablesResponseEnvelope": ImportApplicationVariablesResponseEnvelope{},
+	"ImportApplicationsRequest":                  ImportApplicationsRequest{},
+	"InvitationResponse":                         InvitationResponse{},
+	"InvitationResponseListEnvelope":             InvitationResponseListEnvelope{},
+	"LatencyResponse":                            LatencyResponse{},
+	"ListApplicationsRequest":                    ListApplicationsRequest{},
+	"ListEventsRequest":                          ListEventsRequest{},
+	"ListFilesRequest":                           ListFilesRequest{},
+	"ListInvitationsRequest":                     ListInvitationsRequest{},
+	"ListOperationsRequest":                      ListOperationsRequest{},
+	"LoginRequest":                               LoginRequest{},
+	"MePermissionsResponse":                      MePermissionsResponse{},
+	"MePermissionsResponseEnvelope":              MePermissionsResponseEnvelope{},
+	"MePreferencesResponse":                      MePreferencesResponse{},
+	"MePreferencesResponseEnvelope":              MePreferencesResponseEnvelope{},
+	"MeResponse":                                 MeResponse{},
+	"MeResponseEnvelope":                         MeResponseEnvelope{},
+	"NetworkACLResponse":                         NetworkACLResponse{},
+	"NetworkACLResponseEnvelope":                 NetworkACLResponseEnvelope{},
+	"NetworkACLRuleRequest":                      NetworkACLRuleRequest{},
+	"NotificationDeliveryResponse":               NotificationDeliveryResponse{},
+	"NotificationDeliveryResponseListEnvelope":   NotificationDeliveryResponseListEnvelope{},
+	"NotificationPreferenceResponse":             NotificationPreferenceResponse{},
+	"NotificationPreferenceResponseEnvelope":     NotificationPreferenceResponseEnvelope{},
+	"NotificationPreferenceResponseListEnvelope": NotificationPreferenceResponseListEnvelope{},
+	"OperationEventResponse":                     OperationEventResponse{},
+	"OperationResponse":                          OperationResponse{},
+	"OperationResponseEnvelope":                  OperationResponseEnvelope{},
+	"OperationResponsePage":                      OperationResponsePage{},
+	"OperationResponsePageEnvelope":              OperationResponsePageEnvelope{},
+	"OrganizationMemberResponse":                 OrganizationMemberResponse{},
+	"OrganizationMemberResponseEnvelope":         OrganizationMemberResponseEnvelope{},
+	"OrganizationMemberResponseListEnvelope":     OrganizationMemberResponseListEnvelope{},
+	"OrganizationResponse":                       OrganizationResponse{},
+	"OrganizationResponseEnvelope":               OrganizationResponseEnvelope{},
+	"OrganizationResponseListEnvelope":           OrganizationResponseListEnvelope{},
+	"PageRequest":                                PageRequest{},
+	"Pagination":                                 Pagination{},
+	"PaginationResponse":                         PaginationResponse{},
+	"PartnerResponse":                            PartnerResponse{},
+	"PartnerResponseEnvelope":                    PartnerResponseEnvelope{},
+	"PartnerResponseListEnvelope":                PartnerResponseListEnvelope{},
+	"PartnerSecretResponse":                      PartnerSecretResponse{},
+	"PartnerSecretResponseEnvelope":              PartnerSecretResponseEnvelope{},
+	"PatchApplicationRequest":                    PatchApplicationRequest{},
+	"PolicyConsentResponse":                      PolicyConsentResponse{},
+	"PolicyConsentResponseEnvelope":              PolicyConsentResponseEnvelope{},
+	"PolicyConsentResponseListEnvelope":          PolicyConsentResponseListEnvelope{},
+	"PolicyResponse":                             PolicyResponse{},
+	"PolicyResponseEnvelope":                     PolicyResponseEnvelope{},
+	"PolicyResponseListEnvelope":                 PolicyResponseListEnvelope{},
+	"PresignedURLResponse":                       PresignedURLResponse{},
+	"ProfileRequest":                             ProfileRequest{},
+	"PublishPolicyRequest":                       PublishPolicyRequest{},
+	"QuotaClassUsageResponse":                    QuotaClassUsageResponse{},
+	"QuotaUsageResponse":                         QuotaUsageResponse{},
+	"QuotaUsageResponseEnvelope":                 QuotaUsageResponseEnvelope{},
+	"QuotaWindowResponse":                        QuotaWindowResponse{},
+	"RecentErrorsRequest":                        RecentErrorsRequest{},
+	"RecentErrorsResponse":                       RecentErrorsResponse{},
+	"RecentErrorsResponseEnvelope":               RecentErrorsResponseEnvelope{},
+	"RefreshSessionRequest":                      RefreshSessionRequest{},
+	"RegisterRequest":                            RegisterRequest{},
+	"RegisterResponse":                           RegisterResponse{},
+	"RegisterResponseEnvelope":                   RegisterResponseEnvelope{},
+	"ResetQuotaRequest":                          ResetQuotaRequest{},
+	"Response":                                   Response{},
+	"RetentionPolicyResponse":                    RetentionPolicyResponse{},
+	"RetentionPreviewResponse":                   RetentionPreviewResponse{},
+	"RetentionPreviewResponseEnvelope":           RetentionPreviewResponseEnvelope{},
+	"RevokeSessionsResponse":                     RevokeSessionsResponse{},
+	"RevokeSessionsResponseEnvelope":             RevokeSessionsResponseEnvelope{},
+	"RuntimeStatsResponse":                       RuntimeStatsResponse{},
+	"SchemaResponse":                             SchemaResponse{},
+	"SchemaResponseEnvelope":                     SchemaResponseEnvelope{},
+	"SchemaTypesResponse":                        SchemaTypesResponse{},
+	"SchemaTypesResponseEnvelope":                SchemaTypesResponseEnvelope{},
+	"SearchRequest":                              SearchRequest{},
+	"SessionResponse":                            SessionResponse{},
+	"SessionResponseListEnvelope":                SessionResponseListEnvelope{},
+	"SessionTokensResponse":                      SessionTokensResponse{},
+	"SessionTokensResponseEnvelope":              SessionTokensResponseEnvelope{},
+	"SetNotificationPreferenceRequest":           SetNotificationPreferenceRequest{},
+	"SortField":                                  SortField{},
+	"UpdateApplicationRequest":                   UpdateApplicationRequest{},
+	"UpdateApplicationVariableRequest":           UpdateApplicationVariableRequest{},
+	"UpdateFeatureFlagRequest":                   UpdateFeatureFlagRequest{},
+	"UpdateMyPreferencesRequest":                 UpdateMyPreferencesRequest{},
+	"UpdateOrganizationMemberRequest":            UpdateOrganizationMemberRequest{},
+	"UpdateOrganizationRequest":                  UpdateOrganizationRequest{},
+	"UpdatePartnerRequest":                       UpdatePartnerRequest{},
+	"UploadSessionResponse":                      UploadSessionResponse{},
+	"UploadSessionResponseEnvelope":              UploadSessionResponseEnvelope{},
+	"UserResponse":                               UserResponse{},
+	"VerifyEmailRequest":                         VerifyEmailRequest{},
+}
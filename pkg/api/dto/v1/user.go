@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// RegisterRequest 注册请求
+// @Description 以邮箱和密码注册用户；仅限邀请注册时需要邀请令牌
+type RegisterRequest struct {
+	// @Description 邮箱，作为用户ID；以邀请令牌注册时必须与邀请的邮箱一致
+	// @Example "alice@example.com"
+	Email string `json:"email" binding:"required,email,max=254" example:"alice@example.com"`
+
+	// @Description 用户名，为空时使用邮箱
+	// @Example "alice"
+	Username string `json:"username" binding:"omitempty,max=100" example:"alice"`
+
+	// @Description 密码，至少 registration.min_password_length 个字符
+	// @Example "correct horse battery staple"
+	Password string `json:"password" binding:"required,max=72" example:"correct horse battery staple"`
+
+	// @Description 邀请令牌，registration.mode 为 invite_only 时必填；以邀请预设的角色、权限和组织注册
+	// @Example "7.1704067200.Zm9v..."
+	InvitationToken string `json:"invitation_token" binding:"omitempty,max=200" example:"7.1704067200.Zm9v..."`
+}
+
+// VerifyEmailRequest 验证邮箱请求
+// @Description 以公开注册时邮件中的令牌验证邮箱
+type VerifyEmailRequest struct {
+	// @Description 邮箱验证令牌
+	// @Example "7.1704067200.Zm9v..."
+	Token string `json:"token" binding:"required,max=200" example:"7.1704067200.Zm9v..."`
+}
+
+// LoginRequest 登录请求
+// @Description 以邮箱和密码登录
+type LoginRequest struct {
+	// @Description 邮箱，即用户ID
+	// @Example "alice@example.com"
+	Email string `json:"email" binding:"required,max=254" example:"alice@example.com"`
+
+	// @Description 密码
+	// @Example "correct horse battery staple"
+	Password string `json:"password" binding:"required,max=72" example:"correct horse battery staple"`
+}
+
+// UserResponse 用户响应
+// @Description 用户信息，密码不会返回
+type UserResponse struct {
+	// @Description 用户ID，即邮箱
+	// @Example "alice@example.com"
+	ID string `json:"id" example:"alice@example.com"`
+
+	// @Description 邮箱
+	// @Example "alice@example.com"
+	Email string `json:"email" example:"alice@example.com"`
+
+	// @Description 用户名
+	// @Example "alice"
+	Username string `json:"username" example:"alice"`
+
+	// @Description 角色
+	// @Example "user"
+	Role string `json:"role" example:"user"`
+
+	// @Description 权限
+	// @Example ["app:read"]
+	Permissions []string `json:"permissions,omitempty" example:"app:read"`
+
+	// @Description 注册时使用的邀请ID，公开注册或隐藏内部ID时为空
+	// @Example 7
+	InvitationID idgen.ID `json:"invitation_id,omitempty" example:"7"`
+
+	// @Description 是否已验证邮箱，公开注册的用户验证邮箱前无法登录
+	// @Example true
+	EmailVerified bool `json:"email_verified" example:"true"`
+
+	// @Description 注册时间
+	CreatedAt timestamp.Time `json:"created_at"`
+}
+
+// RegisterResponse 注册响应
+// @Description 注册的用户；启用会话时同时返回其会话令牌，公开注册的用户验证邮箱后才返回
+type RegisterResponse struct {
+	// @Description 注册的用户
+	User UserResponse `json:"user"`
+
+	// @Description 会话令牌，未启用会话或尚未验证邮箱时为空
+	Tokens *SessionTokensResponse `json:"tokens,omitempty"`
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// eventLog 支持依赖注入的领域事件API结构
+type eventLog struct {
+	Config          *config.Config                   `inject:"config"`
+	EventLogService service.EventLogServiceInterface `inject:""`
+	handler         *handler.EventHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newEventLog())
+}
+
+// newEventLog 创建依赖注入版本的领域事件API
+func newEventLog() APIInterface {
+	return &eventLog{}
+}
+
+// RoutePolicies 长轮询和SSE为长连接，不计入过载保护的负载
+func (a *eventLog) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	return map[string]middleware.RoutePolicy{
+		"GET /events": {Priority: middleware.PriorityCritical},
+	}
+}
+
+// InitAPIServiceRoute 初始化领域事件API路由，未启用事件日志时不注册路由
+func (a *eventLog) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	a.handler = handler.NewEventHandler(a.EventLogService, &a.Config.EventLog)
+
+	// 长轮询或以SSE读取领域事件
+	rg.GET("/events", a.handler.ListEvents)
+}
+
+// enabled 判断是否启用事件日志
+func (a *eventLog) enabled() bool {
+	return a.Config != nil && a.EventLogService != nil && a.EventLogService.Enabled()
+}
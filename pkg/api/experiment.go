@@ -0,0 +1,33 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// experiment 支持依赖注入的实验API结构
+type experiment struct {
+	ExperimentService service.ExperimentServiceInterface `inject:""`
+	handler           *handler.ExperimentHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newExperiment())
+}
+
+// newExperiment 创建依赖注入版本的实验API
+func newExperiment() APIInterface {
+	return &experiment{}
+}
+
+// InitAPIServiceRoute 初始化实验API路由
+func (a *experiment) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.ExperimentService == nil {
+		return
+	}
+	a.handler = handler.NewExperimentHandler(a.ExperimentService)
+
+	rg.GET("/experiments", a.handler.GetAssignments)
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// featureFlag 支持依赖注入的特性开关API结构
+type featureFlag struct {
+	FeatureFlagService service.FeatureFlagServiceInterface `inject:""`
+	handler            *handler.FeatureFlagHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newFeatureFlag())
+}
+
+// newFeatureFlag 创建依赖注入版本的特性开关API
+func newFeatureFlag() APIInterface {
+	return &featureFlag{}
+}
+
+// InitAPIServiceRoute 初始化特性开关API路由
+func (a *featureFlag) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.FeatureFlagService == nil {
+		return
+	}
+	a.handler = handler.NewFeatureFlagHandler(a.FeatureFlagService)
+
+	featureFlagGroup := rg.Group("/feature-flags")
+	{
+		// 当前用户的开关求值结果
+		featureFlagGroup.GET("", a.handler.EvaluateFeatureFlags)
+
+		// 开关定义管理（仅管理员）
+		definitionGroup := featureFlagGroup.Group("/definitions", middleware.RequireRole("admin"))
+		definitionGroup.GET("", a.handler.ListFeatureFlags)
+		definitionGroup.POST("", a.handler.CreateFeatureFlag)
+		definitionGroup.GET("/:key", a.handler.GetFeatureFlag)
+		definitionGroup.PUT("/:key", a.handler.UpdateFeatureFlag)
+		definitionGroup.DELETE("/:key", a.handler.DeleteFeatureFlag)
+	}
+}
@@ -0,0 +1,261 @@
+package fields
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// QueryParam 字段选择的查询参数，如 ?fields=id,name,status
+const QueryParam = "fields"
+
+// UnknownFieldError 请求的字段不存在
+type UnknownFieldError struct {
+	Fields    []string
+	Available []string
+}
+
+func (e *UnknownFieldError) Error() string {
+	return fmt.Sprintf("unknown fields %s, available fields: %s",
+		strings.Join(e.Fields, ","), strings.Join(e.Available, ","))
+}
+
+// Parse 解析逗号分隔的字段列表，忽略空白和空项
+func Parse(raw string) []string {
+	var names []string
+	for _, name := range strings.Split(raw, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// Select 只保留data中的指定字段。data为结构体或结构体指针时返回按字段顺序编码的对象，
+// 为结构体切片时逐个处理；其他类型原样返回。names为空时不做处理。
+func Select(data interface{}, names []string) (interface{}, error) {
+	if len(names) == 0 || data == nil {
+		return data, nil
+	}
+
+	value := reflect.ValueOf(data)
+	for value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return data, nil
+		}
+		value = value.Elem()
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		meta := metadataOf(value.Type())
+		selected, err := meta.selection(names)
+		if err != nil {
+			return nil, err
+		}
+		return meta.project(value, selected), nil
+	case reflect.Slice, reflect.Array:
+		elem := indirectType(value.Type().Elem())
+		if elem.Kind() != reflect.Struct {
+			return data, nil
+		}
+		meta := metadataOf(elem)
+		selected, err := meta.selection(names)
+		if err != nil {
+			return nil, err
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = meta.project(value.Index(i), selected)
+		}
+		return items, nil
+	default:
+		return data, nil
+	}
+}
+
+// field 结构体中一个编码为JSON的字段
+type field struct {
+	name       string
+	index      []int
+	omitEmpty  bool
+	visibility *visibility // 为空时对所有调用者可见
+}
+
+// metadata 结构体类型的JSON字段，按编码顺序排列
+type metadata struct {
+	fields []field
+	byName map[string]int
+}
+
+// cache 按类型缓存的字段元数据
+var cache sync.Map // map[reflect.Type]*metadata
+
+// metadataOf 返回结构体类型的字段元数据，首次使用时通过反射生成
+func metadataOf(t reflect.Type) *metadata {
+	if meta, ok := cache.Load(t); ok {
+		return meta.(*metadata)
+	}
+
+	meta := &metadata{byName: make(map[string]int)}
+	collect(t, nil, meta)
+	actual, _ := cache.LoadOrStore(t, meta)
+	return actual.(*metadata)
+}
+
+// collect 按encoding/json的规则收集字段，未命名的嵌入结构体字段展开到外层
+func collect(t reflect.Type, index []int, meta *metadata) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		path := append(append([]int{}, index...), i)
+
+		if sf.Anonymous && name == "" && indirectType(sf.Type).Kind() == reflect.Struct {
+			collect(indirectType(sf.Type), path, meta)
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		// 外层字段优先于嵌入结构体中的同名字段
+		if _, exists := meta.byName[name]; exists {
+			continue
+		}
+		meta.byName[name] = len(meta.fields)
+		meta.fields = append(meta.fields, field{
+			name:       name,
+			index:      path,
+			omitEmpty:  strings.Contains(","+options+",", ",omitempty,"),
+			visibility: parseVisibility(sf.Tag.Get(VisibilityTag)),
+		})
+	}
+}
+
+// names 返回全部字段名
+func (m *metadata) names() []string {
+	names := make([]string, len(m.fields))
+	for i, f := range m.fields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// selection 校验字段名并返回按编码顺序排列的字段
+func (m *metadata) selection(names []string) ([]field, error) {
+	wanted := make(map[int]bool, len(names))
+	var unknown []string
+	for _, name := range names {
+		i, ok := m.byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		wanted[i] = true
+	}
+	if len(unknown) > 0 {
+		return nil, &UnknownFieldError{Fields: unknown, Available: m.names()}
+	}
+
+	selected := make([]field, 0, len(wanted))
+	for i, f := range m.fields {
+		if wanted[i] {
+			selected = append(selected, f)
+		}
+	}
+	return selected, nil
+}
+
+// project 取出结构体值中选中的字段
+func (m *metadata) project(value reflect.Value, selected []field) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+
+	obj := make(object, 0, len(selected))
+	for _, f := range selected {
+		fv, err := value.FieldByIndexErr(f.index)
+		if err != nil {
+			// 嵌入的结构体指针为nil，encoding/json同样省略其字段
+			continue
+		}
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		obj = append(obj, member{name: f.name, value: fv.Interface(), visibility: f.visibility})
+	}
+	return obj
+}
+
+// member 对象的一个成员，保留字段的可见性供 Mask 使用
+type member struct {
+	name       string
+	value      interface{}
+	visibility *visibility
+}
+
+// object 保持字段顺序的JSON对象
+type object []member
+
+// MarshalJSON 按字段顺序编码对象
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, m := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(m.name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(m.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// indirectType 返回指针指向的类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isEmptyValue 与encoding/json的omitempty判断一致
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+package fields
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// VisibilityTag 限制字段可见范围的结构体标签。值为角色或权限，多个用|分隔，
+// 调用者具有其中之一时可见，否则省略该字段；带mask选项的字符串字段改为返回 MaskedValue，
+// 如 `visibility:"admin"`、`visibility:"admin|application:secrets,mask"`
+const VisibilityTag = "visibility"
+
+// MaskedValue 对调用者不可见的mask字段返回的值
+const MaskedValue = "******"
+
+// Caller 调用者的角色和权限，来自JWT认证中间件设置的 user_role 和 user_permissions
+type Caller struct {
+	Role        string
+	Permissions []string
+}
+
+// Has 判断调用者是否具有该角色或权限
+func (c Caller) Has(name string) bool {
+	return name != "" && (c.Role == name || slices.Contains(c.Permissions, name))
+}
+
+// visibility 字段的可见范围
+type visibility struct {
+	allowed []string
+	mask    bool
+}
+
+// parseVisibility 解析visibility标签，未设置时返回nil
+func parseVisibility(tag string) *visibility {
+	if tag == "" {
+		return nil
+	}
+	names, options, _ := strings.Cut(tag, ",")
+	v := &visibility{mask: strings.Contains(","+options+",", ",mask,")}
+	for _, name := range strings.Split(names, "|") {
+		if name = strings.TrimSpace(name); name != "" {
+			v.allowed = append(v.allowed, name)
+		}
+	}
+	return v
+}
+
+// visibleTo 判断字段对调用者是否可见
+func (v *visibility) visibleTo(caller Caller) bool {
+	if v == nil {
+		return true
+	}
+	for _, name := range v.allowed {
+		if caller.Has(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply 返回字段对调用者的值，第二个返回值为false时省略该字段
+func (v *visibility) apply(value reflect.Value, caller Caller) (interface{}, bool) {
+	if v.visibleTo(caller) {
+		return mask(value, caller), true
+	}
+	if v.mask && value.Kind() == reflect.String {
+		return MaskedValue, true
+	}
+	return nil, false
+}
+
+// Mask 按调用者的角色和权限处理data中带visibility标签的字段，包括嵌套的结构体、切片、
+// map和 Select 的结果。不含受限字段的值原样返回；含有时结构体被编码为按字段顺序的对象。
+// 实现了json.Marshaler的类型自行编码，不检查其中的字段
+func Mask(data interface{}, caller Caller) interface{} {
+	if data == nil {
+		return nil
+	}
+	return mask(reflect.ValueOf(data), caller)
+}
+
+// mask 返回处理后的值
+func mask(value reflect.Value, caller Caller) interface{} {
+	if !value.IsValid() {
+		return nil
+	}
+	if value.Type() == objectType {
+		return maskObject(value.Interface().(object), caller)
+	}
+	if !restricted(value.Type()) {
+		return value.Interface()
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return value.Interface()
+		}
+		return mask(value.Elem(), caller)
+	case reflect.Struct:
+		meta := metadataOf(value.Type())
+		obj := make(object, 0, len(meta.fields))
+		for _, f := range meta.fields {
+			fv, err := value.FieldByIndexErr(f.index)
+			if err != nil {
+				continue
+			}
+			if f.omitEmpty && isEmptyValue(fv) {
+				continue
+			}
+			if v, ok := f.visibility.apply(fv, caller); ok {
+				obj = append(obj, member{name: f.name, value: v})
+			}
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return value.Interface()
+		}
+		items := make([]interface{}, value.Len())
+		for i := range items {
+			items[i] = mask(value.Index(i), caller)
+		}
+		return items
+	case reflect.Map:
+		if value.IsNil() {
+			return value.Interface()
+		}
+		masked := reflect.MakeMapWithSize(reflect.MapOf(value.Type().Key(), interfaceType), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			v := mask(iter.Value(), caller)
+			if v == nil {
+				masked.SetMapIndex(iter.Key(), reflect.Zero(interfaceType))
+				continue
+			}
+			masked.SetMapIndex(iter.Key(), reflect.ValueOf(v))
+		}
+		return masked.Interface()
+	default:
+		return value.Interface()
+	}
+}
+
+// maskObject 处理 Select 的结果，成员保留了原字段的可见性
+func maskObject(obj object, caller Caller) object {
+	masked := make(object, 0, len(obj))
+	for _, m := range obj {
+		if m.visibility == nil {
+			masked = append(masked, member{name: m.name, value: Mask(m.value, caller)})
+			continue
+		}
+		if v, ok := m.visibility.apply(reflect.ValueOf(m.value), caller); ok {
+			masked = append(masked, member{name: m.name, value: v})
+		}
+	}
+	return masked
+}
+
+var (
+	objectType    = reflect.TypeOf(object(nil))
+	interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+	marshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+)
+
+// restrictedCache 按类型缓存是否可能含有受限字段
+var restrictedCache sync.Map // map[reflect.Type]bool
+
+// restricted 判断类型的值是否可能含有带visibility标签的字段，接口类型取决于其中的值
+func restricted(t reflect.Type) bool {
+	if r, ok := restrictedCache.Load(t); ok {
+		return r.(bool)
+	}
+	r := containsRestricted(t, make(map[reflect.Type]bool))
+	restrictedCache.Store(t, r)
+	return r
+}
+
+// containsRestricted 递归检查类型，visiting记录检查中的类型以终止循环引用
+func containsRestricted(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	if t.Kind() == reflect.Interface {
+		return true
+	}
+	if t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType) || visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return containsRestricted(t.Elem(), visiting)
+	case reflect.Map:
+		return containsRestricted(t.Elem(), visiting)
+	case reflect.Struct:
+		for _, f := range metadataOf(t).fields {
+			if f.visibility != nil || containsRestricted(t.FieldByIndex(f.index).Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
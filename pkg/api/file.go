@@ -0,0 +1,88 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// file 支持依赖注入的文件API结构
+type file struct {
+	Config      *config.Config               `inject:"config"`
+	FileService service.FileServiceInterface `inject:""`
+	handler     *handler.FileHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newFile())
+}
+
+// newFile 创建依赖注入版本的文件API
+func newFile() APIInterface {
+	return &file{}
+}
+
+// Module 所属的文件模块，模块禁用时不注册路由
+func (a *file) Module() string {
+	return module.Files
+}
+
+// RoutePolicies 文件路由使用默认策略；未指定组织时访问当前用户自己的文件
+func (a *file) RoutePolicies() map[string]middleware.RoutePolicy {
+	return nil
+}
+
+// InitAPIServiceRoute 初始化文件API路由，未启用文件时不注册路由
+func (a *file) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	a.handler = handler.NewFileHandler(a.FileService, a.Config.Files.Uploads.MaxChunkSize)
+
+	fileGroup := rg.Group("/files", middleware.PublicIDMiddleware("id", a.resolveFileID, model.ErrFileNotFound, "file_not_found"))
+	{
+		// 创建上传并获取预签名上传地址，上传后确认完成
+		fileGroup.POST("", a.handler.CreateUpload)
+		fileGroup.POST("/:id/complete", a.handler.CompleteUpload)
+
+		fileGroup.GET("", a.handler.ListFiles)
+		fileGroup.GET("/:id", a.handler.GetFile)
+		fileGroup.GET("/:id/download", a.handler.DownloadFile)
+		fileGroup.GET("/:id/content", a.handler.DownloadContent)
+		fileGroup.DELETE("/:id", a.handler.DeleteFile)
+	}
+
+	if !a.Config.Files.Uploads.Enabled {
+		return
+	}
+	uploadGroup := rg.Group("/uploads", middleware.PublicIDMiddleware("id", a.resolveUploadSessionID, model.ErrUploadSessionNotFound, "upload_session_not_found"))
+	{
+		// 断点续传：创建会话，按偏移量上传分块，以校验和完成
+		uploadGroup.POST("", a.handler.CreateUploadSession)
+		uploadGroup.GET("/:id", a.handler.GetUploadSession)
+		uploadGroup.HEAD("/:id", a.handler.GetUploadSession)
+		uploadGroup.PATCH("/:id", a.handler.UploadChunk)
+		uploadGroup.POST("/:id/complete", a.handler.FinalizeUpload)
+		uploadGroup.DELETE("/:id", a.handler.AbortUpload)
+	}
+}
+
+// resolveFileID 返回公开ID对应的文件ID
+func (a *file) resolveFileID(c *gin.Context, publicID string) (uint, error) {
+	return a.FileService.ResolveFileID(c.Request.Context(), publicID)
+}
+
+// resolveUploadSessionID 返回公开ID对应的上传会话ID
+func (a *file) resolveUploadSessionID(c *gin.Context, publicID string) (uint, error) {
+	return a.FileService.ResolveUploadSessionID(c.Request.Context(), publicID)
+}
+
+// enabled 判断是否启用文件
+func (a *file) enabled() bool {
+	return a.Config != nil && a.Config.Files.Enabled && a.FileService != nil
+}
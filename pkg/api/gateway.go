@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/gateway"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	applicationv1 "github.com/make-bin/server-tpl/pkg/api/proto/application/v1"
+	"github.com/make-bin/server-tpl/pkg/api/rpc"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// gatewayAPI proto服务的REST转码接口，路由由proto文件中的HTTP绑定生成，
+// 与REST接口共用认证、限流和配额中间件，未启用时不注册路由
+type gatewayAPI struct {
+	Config             *config.Config                      `inject:"config"`
+	ApplicationService service.ApplicationServiceInterface `inject:""`
+}
+
+// gatewayMethods 网关路由接受的请求方法，与 gin 的 Any 相同
+var gatewayMethods = []string{
+	http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch,
+	http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodConnect,
+	http.MethodTrace,
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newGatewayAPI())
+}
+
+// newGatewayAPI 创建依赖注入版本的网关接口
+func newGatewayAPI() APIInterface {
+	return &gatewayAPI{}
+}
+
+// enabled 是否启用网关
+func (a *gatewayAPI) enabled() bool {
+	return a.Config != nil && a.Config.Server.Gateway.Enabled && a.ApplicationService != nil
+}
+
+// RoutePolicies 网关调用的服务与REST接口相同，启用组织时同样需要当前组织的成员身份
+func (a *gatewayAPI) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	path := a.Config.Server.Gateway.Prefix + "/*path"
+	policies := make(map[string]middleware.RoutePolicy, len(gatewayMethods))
+	for _, method := range gatewayMethods {
+		policies[method+" "+path] = middleware.RoutePolicy{OrgRole: model.OrganizationRoleMember}
+	}
+	return policies
+}
+
+// UndocumentedRoutes 网关路由由proto文件描述，不在API文档中
+func (a *gatewayAPI) UndocumentedRoutes() []string {
+	if a.Config == nil {
+		return nil
+	}
+	return []string{a.Config.Server.Gateway.Prefix + "/*path"}
+}
+
+// InitAPIServiceRoute 初始化网关路由，proto服务在进程内调用
+func (a *gatewayAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	prefix := a.Config.Server.Gateway.Prefix
+
+	mux := gateway.NewServeMux()
+	if err := applicationv1.RegisterApplicationServiceHandlerServer(context.Background(), mux, rpc.NewApplicationServer(a.ApplicationService)); err != nil {
+		logger.Error("Failed to register application service gateway: %v", err)
+		return
+	}
+	rg.Match(gatewayMethods, prefix+"/*path", gateway.Handler(mux, rg.BasePath()+prefix))
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// ginContextKey 请求上下文中gin上下文的键，错误处理器通过它写出标准错误响应
+type ginContextKey struct{}
+
+// errorCode gRPC状态码对应的业务错误码和消息键
+type errorCode struct {
+	code       int
+	messageKey string
+}
+
+// errorCodes gRPC状态码与业务错误码的映射，未列出的状态码返回服务器内部错误
+var errorCodes = map[codes.Code]errorCode{
+	codes.InvalidArgument:    {response.CodeInvalidParameter, "invalid_parameter"},
+	codes.OutOfRange:         {response.CodeInvalidParameter, "invalid_parameter"},
+	codes.FailedPrecondition: {response.CodeInvalidParameter, "invalid_parameter"},
+	codes.NotFound:           {response.CodeNotFound, "not_found"},
+	codes.AlreadyExists:      {response.CodeConflict, "conflict"},
+	codes.Aborted:            {response.CodeConflict, "conflict"},
+	codes.Unauthenticated:    {response.CodeUnauthorized, "unauthorized"},
+	codes.PermissionDenied:   {response.CodeForbidden, "forbidden"},
+	codes.Unavailable:        {response.CodeServiceUnavailable, "service_overloaded"},
+}
+
+// NewServeMux 创建网关路由：JSON字段使用proto中的下划线名称并输出零值，
+// 与REST接口的字段风格一致；错误写出标准错误响应
+func NewServeMux() *runtime.ServeMux {
+	return runtime.NewServeMux(
+		runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+			MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+			UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+		}),
+		runtime.WithErrorHandler(handleError),
+	)
+}
+
+// Handler 返回挂载网关的gin处理器，请求路径去掉prefix后交给网关路由。
+// 成功响应包装为标准响应结构，只支持一元调用。
+func Handler(mux http.Handler, prefix string) gin.HandlerFunc {
+	h := http.StripPrefix(prefix, mux)
+	return func(c *gin.Context) {
+		rec := &recorder{header: make(http.Header)}
+		req := c.Request.WithContext(context.WithValue(c.Request.Context(), ginContextKey{}, c))
+		h.ServeHTTP(rec, req)
+		if c.Writer.Written() {
+			// 错误处理器已写出错误响应
+			return
+		}
+
+		for key, values := range rec.header {
+			if key == "Content-Type" || key == "Content-Length" {
+				continue
+			}
+			c.Writer.Header()[key] = values
+		}
+		response.Success(c, json.RawMessage(rec.body.Bytes()))
+	}
+}
+
+// handleError 将gRPC状态转换为HTTP状态码和业务错误码，写出标准错误响应
+func handleError(ctx context.Context, mux *runtime.ServeMux, marshaler runtime.Marshaler, w http.ResponseWriter, r *http.Request, err error) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	if !ok {
+		runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+		return
+	}
+
+	st := status.Convert(err)
+	mapped, ok := errorCodes[st.Code()]
+	if !ok {
+		mapped = errorCode{response.CodeInternalServerError, "internal_error"}
+	}
+	response.Error(c, runtime.HTTPStatusFromCode(st.Code()), mapped.code, mapped.messageKey, errors.New(st.Message()))
+}
+
+// recorder 缓存网关写出的成功响应
+type recorder struct {
+	header http.Header
+	body   bytes.Buffer
+}
+
+// Header 实现 http.ResponseWriter
+func (r *recorder) Header() http.Header {
+	return r.header
+}
+
+// Write 实现 http.ResponseWriter
+func (r *recorder) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
+
+// WriteHeader 实现 http.ResponseWriter，成功响应统一使用200
+func (r *recorder) WriteHeader(int) {}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"github.com/99designs/gqlgen/graphql/playground"
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/graphql"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// graphqlAPI GraphQL接口，与REST接口共用认证、限流和配额中间件，未启用时不注册路由
+type graphqlAPI struct {
+	Config                     *config.Config                              `inject:"config"`
+	ApplicationService         service.ApplicationServiceInterface         `inject:""`
+	ApplicationVariableService service.ApplicationVariableServiceInterface `inject:""`
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newGraphQLAPI())
+}
+
+// newGraphQLAPI 创建依赖注入版本的GraphQL接口
+func newGraphQLAPI() APIInterface {
+	return &graphqlAPI{}
+}
+
+// enabled 是否启用GraphQL接口
+func (a *graphqlAPI) enabled() bool {
+	return a.Config != nil && a.Config.Server.GraphQL.Enabled && a.ApplicationService != nil
+}
+
+// RoutePolicies 查询请求需要认证，启用组织时还需要当前组织的成员身份；调试页面只返回静态页面，无需认证
+func (a *graphqlAPI) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	scoped := middleware.RoutePolicy{OrgRole: model.OrganizationRoleMember}
+	policies := map[string]middleware.RoutePolicy{
+		"GET /graphql":  scoped,
+		"POST /graphql": scoped,
+	}
+	if a.Config.Server.GraphQL.Playground {
+		policies["GET /graphql/playground"] = middleware.RoutePolicy{Public: true, Quota: middleware.QuotaNone}
+	}
+	return policies
+}
+
+// UndocumentedRoutes GraphQL接口由其schema描述，不在API文档中
+func (a *graphqlAPI) UndocumentedRoutes() []string {
+	return []string{"/graphql", "/graphql/playground"}
+}
+
+// InitAPIServiceRoute 初始化GraphQL路由
+func (a *graphqlAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	cfg := &a.Config.Server.GraphQL
+
+	h := gin.WrapH(graphql.NewHandler(graphql.NewResolver(a.ApplicationService, a.ApplicationVariableService), cfg))
+	rg.GET("/graphql", h)
+	rg.POST("/graphql", h)
+	if cfg.Playground {
+		rg.GET("/graphql/playground", gin.WrapH(playground.Handler("GraphQL", rg.BasePath()+"/graphql")))
+	}
+}
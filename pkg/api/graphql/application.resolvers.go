@@ -0,0 +1,122 @@
+package graphql
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.45
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/api/graphql/generated"
+	"github.com/make-bin/server-tpl/pkg/api/graphql/gqlmodel"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Tags is the resolver for the tags field.
+func (r *applicationResolver) Tags(ctx context.Context, obj *model.Application) ([]string, error) {
+	if obj.Tags == nil {
+		return []string{}, nil
+	}
+	return obj.Tags, nil
+}
+
+// Variables is the resolver for the variables field.
+func (r *applicationResolver) Variables(ctx context.Context, obj *model.Application) ([]*model.ApplicationVariable, error) {
+	loaders := loadersFrom(ctx)
+	if r.VariableService == nil || loaders == nil {
+		return []*model.ApplicationVariable{}, nil
+	}
+	variables, err := loaders.variables.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, err
+	}
+	if variables == nil {
+		return []*model.ApplicationVariable{}, nil
+	}
+	return variables, nil
+}
+
+// CreateApplication is the resolver for the createApplication field.
+func (r *mutationResolver) CreateApplication(ctx context.Context, input gqlmodel.CreateApplicationInput) (*model.Application, error) {
+	app := &model.Application{Name: input.Name, Tags: input.Tags}
+	if input.Description != nil {
+		app.Description = *input.Description
+	}
+	return r.ApplicationService.CreateApplication(ctx, app)
+}
+
+// UpdateApplication is the resolver for the updateApplication field.
+func (r *mutationResolver) UpdateApplication(ctx context.Context, id uint, input gqlmodel.UpdateApplicationInput) (*model.Application, error) {
+	patch := &model.ApplicationPatch{Name: input.Name, Description: input.Description}
+	if input.Tags != nil {
+		tags := model.StringList(input.Tags)
+		patch.Tags = &tags
+	}
+	return r.ApplicationService.PatchApplication(ctx, id, patch)
+}
+
+// DeleteApplication is the resolver for the deleteApplication field.
+func (r *mutationResolver) DeleteApplication(ctx context.Context, id uint) (bool, error) {
+	if err := r.ApplicationService.DeleteApplication(ctx, id); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Application is the resolver for the application field.
+func (r *queryResolver) Application(ctx context.Context, id uint) (*model.Application, error) {
+	return nullIfNotFound(r.ApplicationService.GetApplicationByID(ctx, id))
+}
+
+// ApplicationByName is the resolver for the applicationByName field.
+func (r *queryResolver) ApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+	return nullIfNotFound(r.ApplicationService.GetApplicationByName(ctx, name))
+}
+
+// Applications is the resolver for the applications field.
+func (r *queryResolver) Applications(ctx context.Context, page *int, pageSize *int, tags []string) (*gqlmodel.ApplicationPage, error) {
+	// 显式传入null时使用默认分页
+	pageNum, size := 1, defaultPageSize
+	if page != nil {
+		pageNum = *page
+	}
+	if pageSize != nil {
+		size = *pageSize
+	}
+	if pageNum < 1 {
+		return nil, badUserInput(ctx, "page must be at least 1")
+	}
+	if size < 1 || size > maxPageSize {
+		return nil, badUserInput(ctx, fmt.Sprintf("pageSize must be between 1 and %d", maxPageSize))
+	}
+	selector, err := model.ParseTagSelector(strings.Join(tags, ","))
+	if err != nil {
+		return nil, err
+	}
+
+	apps, total, err := r.ApplicationService.ListApplicationsByTags(ctx, selector, pageNum, size)
+	if err != nil {
+		return nil, err
+	}
+	return &gqlmodel.ApplicationPage{Items: apps, Total: int(total), Page: pageNum, PageSize: size}, nil
+}
+
+// Value is the resolver for the value field.
+func (r *variableResolver) Value(ctx context.Context, obj *model.ApplicationVariable) (string, error) {
+	if obj.Secret {
+		return config.RedactedValue, nil
+	}
+	return obj.Value, nil
+}
+
+// Application returns generated.ApplicationResolver implementation.
+func (r *Resolver) Application() generated.ApplicationResolver { return &applicationResolver{r} }
+
+// Variable returns generated.VariableResolver implementation.
+func (r *Resolver) Variable() generated.VariableResolver { return &variableResolver{r} }
+
+type applicationResolver struct{ *Resolver }
+type variableResolver struct{ *Resolver }
@@ -0,0 +1,53 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/vikstrous/dataloadgen"
+)
+
+// loaderWait 收集同一批次键的等待时间
+const loaderWait = 2 * time.Millisecond
+
+// loadersKey 请求上下文中数据加载器的键
+type loadersKey struct{}
+
+// Loaders 单个请求内的数据加载器，将同一层级的字段解析合并为一次数据存储查询，避免N+1查询
+type Loaders struct {
+	variables *dataloadgen.Loader[uint, []*model.ApplicationVariable]
+}
+
+// NewLoaders 创建数据加载器，加载结果只在当前请求内缓存
+func NewLoaders(variableService service.ApplicationVariableServiceInterface) *Loaders {
+	return &Loaders{
+		variables: dataloadgen.NewLoader(func(ctx context.Context, appIDs []uint) ([][]*model.ApplicationVariable, []error) {
+			byApp, err := variableService.ListVariablesByApplications(ctx, appIDs)
+			if err != nil {
+				return nil, []error{err}
+			}
+			result := make([][]*model.ApplicationVariable, len(appIDs))
+			for i, id := range appIDs {
+				result[i] = byApp[id]
+			}
+			return result, nil
+		}, dataloadgen.WithWait(loaderWait)),
+	}
+}
+
+// WithLoaders 中间件，为每个请求创建新的数据加载器
+func WithLoaders(variableService service.ApplicationVariableServiceInterface, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), loadersKey{}, NewLoaders(variableService))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// loadersFrom 返回请求上下文中的数据加载器
+func loadersFrom(ctx context.Context) *Loaders {
+	loaders, _ := ctx.Value(loadersKey{}).(*Loaders)
+	return loaders
+}
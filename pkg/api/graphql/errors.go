@@ -0,0 +1,97 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// GraphQL错误码，写入错误的 extensions.code
+const (
+	CodeBadUserInput = "BAD_USER_INPUT"
+	CodeNotFound     = "NOT_FOUND"
+	CodeConflict     = "CONFLICT"
+	CodeForbidden    = "FORBIDDEN"
+	CodeInternal     = "INTERNAL"
+)
+
+const (
+	// defaultPageSize 未指定时的分页大小
+	defaultPageSize = 20
+	// maxPageSize 分页大小上限，与REST接口一致
+	maxPageSize = 100
+)
+
+// badUserInput 返回参数错误
+func badUserInput(ctx context.Context, message string) error {
+	err := gqlerror.ErrorPathf(graphql.GetPath(ctx), "%s", message)
+	errcode.Set(err, CodeBadUserInput)
+	return err
+}
+
+// nullIfNotFound 将应用不存在转换为null结果
+func nullIfNotFound(app *model.Application, err error) (*model.Application, error) {
+	if errors.Is(err, model.ErrApplicationNotFound) {
+		return nil, nil
+	}
+	return app, err
+}
+
+// presentError 将解析错误转换为GraphQL错误：领域错误按类型设置错误码，
+// 解析器返回的其他错误记录日志后只返回通用消息，避免泄露内部细节。
+// gqlgen将所有错误包装为 *gqlerror.Error，查询本身和gqlgen产生的错误原样返回。
+func presentError(ctx context.Context, err error) *gqlerror.Error {
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) {
+		gqlErr = gqlerror.WrapPath(graphql.GetPath(ctx), err)
+	}
+	cause := gqlErr.Err
+	if cause == nil {
+		return gqlErr
+	}
+
+	var domainErr *model.DomainError
+	var causeErr *gqlerror.Error
+	switch {
+	case errors.As(cause, &causeErr):
+		if causeErr.Path == nil {
+			causeErr.Path = gqlErr.Path
+		}
+		return causeErr
+	case errors.Is(cause, model.ErrApplicationNotFound):
+		errcode.Set(gqlErr, CodeNotFound)
+	case errors.Is(cause, model.ErrApplicationNameExists):
+		errcode.Set(gqlErr, CodeConflict)
+	case errors.Is(cause, model.ErrAccessDenied):
+		errcode.Set(gqlErr, CodeForbidden)
+	case errors.As(cause, &domainErr):
+		errcode.Set(gqlErr, CodeBadUserInput)
+	case !isResolverField(ctx):
+		return gqlErr
+	default:
+		logger.Error("GraphQL resolver failed at %s: %v", gqlErr.Path, cause)
+		gqlErr = gqlerror.ErrorPathf(gqlErr.Path, "internal error")
+		errcode.Set(gqlErr, CodeInternal)
+	}
+	return gqlErr
+}
+
+// isResolverField 当前字段是否由解析器解析
+func isResolverField(ctx context.Context) bool {
+	fc := graphql.GetFieldContext(ctx)
+	return fc != nil && fc.IsResolver
+}
+
+// recoverPanic 记录解析器的panic并返回通用错误
+func recoverPanic(ctx context.Context, recovered interface{}) error {
+	logger.Error("GraphQL resolver panic at %s: %v\n%s", graphql.GetPath(ctx), recovered, debug.Stack())
+	err := gqlerror.ErrorPathf(graphql.GetPath(ctx), "internal error")
+	errcode.Set(err, CodeInternal)
+	return err
+}
@@ -0,0 +1,49 @@
+package gqlmodel
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// MarshalUintID writes a datastore ID as a GraphQL ID, which is a string
+func MarshalUintID(id uint) graphql.Marshaler {
+	return graphql.WriterFunc(func(w io.Writer) {
+		_, _ = io.WriteString(w, strconv.Quote(strconv.FormatUint(uint64(id), 10)))
+	})
+}
+
+// UnmarshalUintID reads a GraphQL ID given as a string or an integer
+func UnmarshalUintID(v interface{}) (uint, error) {
+	var s string
+	switch v := v.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case int:
+		s = strconv.Itoa(v)
+	case int64:
+		s = strconv.FormatInt(v, 10)
+	default:
+		return 0, invalidID(fmt.Sprintf("%T", v))
+	}
+
+	id, err := strconv.ParseUint(s, 10, 0)
+	if err != nil || id == 0 {
+		return 0, invalidID(strconv.Quote(s))
+	}
+	return uint(id), nil
+}
+
+// invalidID returns an input error, reported to the client like a validation error
+func invalidID(value string) error {
+	err := gqlerror.Errorf("%s is not a valid ID", value)
+	errcode.Set(err, errcode.ValidationFailed)
+	return err
+}
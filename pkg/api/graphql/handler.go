@@ -0,0 +1,56 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/lru"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/make-bin/server-tpl/pkg/api/graphql/generated"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+const (
+	// queryCacheSize 已解析查询的缓存数量
+	queryCacheSize = 1000
+	// variablesPerApplication 估算复杂度时每个应用的变量数量
+	variablesPerApplication = 10
+)
+
+// NewHandler 创建GraphQL HTTP处理器，按配置启用内省、复杂度限制和深度限制，
+// 每个请求使用独立的数据加载器
+func NewHandler(resolver *Resolver, cfg *config.GraphQLConfig) http.Handler {
+	schemaConfig := generated.Config{Resolvers: resolver}
+	// 列表字段的复杂度按返回的元素数量放大
+	schemaConfig.Complexity.Query.Applications = func(childComplexity int, page *int, pageSize *int, tags []string) int {
+		size := defaultPageSize
+		if pageSize != nil && *pageSize > 0 {
+			size = *pageSize
+		}
+		return 1 + size*childComplexity
+	}
+	schemaConfig.Complexity.Application.Variables = func(childComplexity int) int {
+		return 1 + variablesPerApplication*childComplexity
+	}
+
+	srv := handler.New(generated.NewExecutableSchema(schemaConfig))
+	srv.AddTransport(transport.Options{})
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.SetQueryCache(lru.New(queryCacheSize))
+
+	if cfg.Introspection {
+		srv.Use(extension.Introspection{})
+	}
+	if cfg.MaxComplexity > 0 {
+		srv.Use(extension.FixedComplexityLimit(cfg.MaxComplexity))
+	}
+	if cfg.MaxDepth > 0 {
+		srv.Use(NewDepthLimit(cfg.MaxDepth))
+	}
+	srv.SetErrorPresenter(presentError)
+	srv.SetRecoverFunc(recoverPanic)
+
+	return WithLoaders(resolver.VariableService, srv)
+}
@@ -0,0 +1,83 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/errcode"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+)
+
+// errDepthLimit 查询深度超限的错误码
+const errDepthLimit = "DEPTH_LIMIT_EXCEEDED"
+
+// DepthLimit 限制查询的嵌套深度，拒绝深度超过上限的操作。
+// 内省字段不计入深度，片段按展开后的深度计算。
+type DepthLimit struct {
+	limit int
+}
+
+var _ interface {
+	graphql.OperationContextMutator
+	graphql.HandlerExtension
+} = &DepthLimit{}
+
+// NewDepthLimit 创建深度限制扩展
+func NewDepthLimit(limit int) *DepthLimit {
+	return &DepthLimit{limit: limit}
+}
+
+// ExtensionName 扩展名称
+func (d *DepthLimit) ExtensionName() string {
+	return "DepthLimit"
+}
+
+// Validate 校验扩展配置
+func (d *DepthLimit) Validate(schema graphql.ExecutableSchema) error {
+	if d.limit <= 0 {
+		return fmt.Errorf("depth limit must be positive")
+	}
+	return nil
+}
+
+// MutateOperationContext 在执行前计算操作深度
+func (d *DepthLimit) MutateOperationContext(ctx context.Context, rc *graphql.OperationContext) *gqlerror.Error {
+	op := rc.Doc.Operations.ForName(rc.OperationName)
+	if op == nil {
+		return nil
+	}
+	if depth := selectionDepth(op.SelectionSet, rc.Doc.Fragments); depth > d.limit {
+		err := gqlerror.Errorf("operation has depth %d, which exceeds the limit of %d", depth, d.limit)
+		errcode.Set(err, errDepthLimit)
+		return err
+	}
+	return nil
+}
+
+// selectionDepth 返回选择集的最大字段嵌套深度，文档已通过校验，片段不存在循环引用
+func selectionDepth(set ast.SelectionSet, fragments ast.FragmentDefinitionList) int {
+	depth := 0
+	for _, selection := range set {
+		var d int
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(selection.Name, "__") {
+				continue
+			}
+			d = 1 + selectionDepth(selection.SelectionSet, fragments)
+		case *ast.InlineFragment:
+			d = selectionDepth(selection.SelectionSet, fragments)
+		case *ast.FragmentSpread:
+			if fragment := fragments.ForName(selection.Name); fragment != nil {
+				d = selectionDepth(fragment.SelectionSet, fragments)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
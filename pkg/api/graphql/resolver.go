@@ -0,0 +1,19 @@
+package graphql
+
+import (
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// Resolver 解析GraphQL查询，字段解析委托给领域服务
+type Resolver struct {
+	ApplicationService service.ApplicationServiceInterface
+	VariableService    service.ApplicationVariableServiceInterface
+}
+
+// NewResolver 创建GraphQL解析器，variableService 可以为nil，此时应用的变量为空列表
+func NewResolver(applicationService service.ApplicationServiceInterface, variableService service.ApplicationVariableServiceInterface) *Resolver {
+	return &Resolver{
+		ApplicationService: applicationService,
+		VariableService:    variableService,
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/monitor"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+const (
+	// dashboardErrors 管理面板中返回的最近错误数量
+	dashboardErrors = 10
+	// defaultRecentErrors 未指定时最近错误接口返回的数量
+	defaultRecentErrors = 50
+)
+
+// RecentErrors 保留最近错误事件的错误上报
+type RecentErrors interface {
+	Recent(limit int) ([]errorreport.Event, uint64)
+}
+
+// AdminHandler 管理面板处理器
+type AdminHandler struct {
+	pprof     *pprof.PProfManager
+	store     datastore.DatastoreInterface
+	cache     datastore.Cache
+	recent    RecentErrors
+	config    *config.Config
+	assembler *assembler.AdminAssembler
+}
+
+// NewAdminHandler 创建管理面板处理器，数据存储和缓存不支持统计时对应数据为空
+func NewAdminHandler(pprofManager *pprof.PProfManager, store datastore.DatastoreInterface, cache datastore.Cache, recent RecentErrors, cfg *config.Config) *AdminHandler {
+	return &AdminHandler{
+		pprof:     pprofManager,
+		store:     store,
+		cache:     cache,
+		recent:    recent,
+		config:    cfg,
+		assembler: assembler.NewAdminAssembler(),
+	}
+}
+
+// GetDashboard godoc
+// @Summary 获取管理面板数据
+// @Description 汇总运行时、数据存储、缓存命中率和最近错误，供内部管理面板使用
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.DashboardResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/dashboard [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetDashboard(c *gin.Context) {
+	resp := &v1.DashboardResponse{
+		Runtime:   h.assembler.ToRuntimeResponse(h.pprof.GetRuntimeStats()),
+		Errors:    h.recentErrors(dashboardErrors),
+		Timestamp: timestamp.Now(),
+	}
+	if provider, ok := h.store.(datastore.StatsProvider); ok {
+		resp.Datastore = h.assembler.ToDatastoreResponse(provider.Stats())
+	}
+	if provider, ok := h.cache.(datastore.CacheStatsProvider); ok {
+		resp.Cache = h.assembler.ToCacheResponse(provider.Stats())
+	}
+
+	response.Success(c, resp)
+}
+
+// ListRecentErrors godoc
+// @Summary 获取最近错误
+// @Description 列出内存中保留的最近错误事件，按时间倒序，不受错误上报采样影响
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param limit query int false "返回数量" default(50) minimum(1) maximum(1000)
+// @Success 200 {object} v1.RecentErrorsResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/errors [get]
+// @Security BearerAuth
+func (h *AdminHandler) ListRecentErrors(c *gin.Context) {
+	var req v1.RecentErrorsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultRecentErrors
+	}
+
+	response.Success(c, h.recentErrors(req.Limit))
+}
+
+// GetDatastoreHistory godoc
+// @Summary 获取数据存储耗时历史
+// @Description 返回各数据存储操作（按表）的执行次数、平均和最大耗时及错误数的历史，重启后仍保留，适合绘制图表
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param operation query string false "操作"
+// @Param table query string false "表名"
+// @Param since query string false "起始时间（RFC3339）"
+// @Param until query string false "结束时间（RFC3339）"
+// @Param resolution query string false "精度" Enums(raw, 1h)
+// @Success 200 {object} v1.DatastoreHistoryResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "查询失败"
+// @Router /admin/datastore/history [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetDatastoreHistory(c *gin.Context) {
+	var req v1.DatastoreHistoryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	historyCfg := h.config.Monitor.History
+	rawCutoff := time.Now().Add(-historyCfg.RawRetention)
+	query := monitor.HistoryQuery{
+		Operation:  req.Operation,
+		Table:      req.Table,
+		Since:      rawCutoff,
+		Resolution: req.Resolution,
+		MaxPoints:  historyCfg.MaxPoints,
+	}
+	if req.Since != nil {
+		query.Since = *req.Since
+	}
+	if req.Until != nil {
+		query.Until = *req.Until
+	}
+	if query.Resolution == "" {
+		// 原始样本超过保留时长后已按小时汇总
+		query.Resolution = model.MetricResolutionRaw
+		if query.Since.Before(rawCutoff) {
+			query.Resolution = model.MetricResolutionHour
+		}
+	}
+
+	series, err := monitor.QueryHistory(c.Request.Context(), h.store, query)
+	if err != nil {
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToDatastoreHistoryResponse(query.Resolution, series))
+}
+
+// GetConfig godoc
+// @Summary 获取配置快照
+// @Description 返回当前生效的配置，密码、密钥、令牌等敏感值已脱敏
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.ConfigSnapshotEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/config [get]
+// @Security BearerAuth
+func (h *AdminHandler) GetConfig(c *gin.Context) {
+	response.Success(c, config.Snapshot(h.config, true))
+}
+
+// recentErrors 返回最近的错误事件
+func (h *AdminHandler) recentErrors(limit int) v1.RecentErrorsResponse {
+	if h.recent == nil {
+		return h.assembler.ToRecentErrorsResponse(nil, 0)
+	}
+	events, total := h.recent.Recent(limit)
+	return h.assembler.ToRecentErrorsResponse(events, total)
+}
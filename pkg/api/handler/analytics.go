@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+)
+
+// defaultAnalyticsEntries 未指定时分析数据接口返回的数量
+const defaultAnalyticsEntries = 100
+
+// AnalyticsHandler 分析数据查询处理器
+type AnalyticsHandler struct {
+	sink      analytics.Sink
+	assembler *assembler.AdminAssembler
+}
+
+// NewAnalyticsHandler 创建分析数据查询处理器
+func NewAnalyticsHandler(sink analytics.Sink) *AnalyticsHandler {
+	return &AnalyticsHandler{
+		sink:      sink,
+		assembler: assembler.NewAdminAssembler(),
+	}
+}
+
+// ListAnalyticsEntries godoc
+// @Summary 查询最近的访问日志和审计事件
+// @Description 从分析数据接收器（内存或ClickHouse）查询最近的记录，按时间倒序，尚在队列中未写入的记录不会返回
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param kind query string false "数据类型" Enums(access, audit)
+// @Param user_id query string false "用户ID"
+// @Param impersonator_id query string false "代为操作的管理员用户ID"
+// @Param since query string false "起始时间（RFC3339）"
+// @Param limit query int false "返回数量" default(100) minimum(1) maximum(1000)
+// @Success 200 {object} v1.AnalyticsEntriesResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "查询失败"
+// @Router /admin/analytics [get]
+// @Security BearerAuth
+func (h *AnalyticsHandler) ListAnalyticsEntries(c *gin.Context) {
+	var req v1.AnalyticsQueryRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	query := analytics.Query{
+		Kind:           analytics.Kind(req.Kind),
+		UserID:         req.UserID,
+		ImpersonatorID: req.ImpersonatorID,
+		Limit:          req.Limit,
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultAnalyticsEntries
+	}
+	if req.Since != nil {
+		query.Since = *req.Since
+	}
+
+	entries, err := h.sink.Query(c.Request.Context(), query)
+	if err != nil {
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToAnalyticsEntriesResponse(entries))
+}
@@ -1,34 +1,69 @@
 package handler
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
 	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
 	"github.com/make-bin/server-tpl/pkg/api/response"
 	"github.com/make-bin/server-tpl/pkg/api/validation"
 	"github.com/make-bin/server-tpl/pkg/domain/model"
 	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
+// mergePatchContentType JSON Merge Patch (RFC 7396) 的媒体类型
+const mergePatchContentType = "application/merge-patch+json"
+
 // ApplicationHandler 应用处理器
 type ApplicationHandler struct {
 	applicationService service.ApplicationServiceInterface
+	variableService    service.ApplicationVariableServiceInterface
+	operationService   service.OperationServiceInterface
+	assembler          *assembler.ApplicationAssembler
+	variableAssembler  *assembler.ApplicationVariableAssembler
+	operationAssembler *assembler.OperationAssembler
 	validator          *validator.Validate
+	lookups            *validation.Lookups
 }
 
-// NewApplicationHandler 创建应用处理器
-func NewApplicationHandler(applicationService service.ApplicationServiceInterface) *ApplicationHandler {
+// NewApplicationHandler 创建应用处理器，variableService 为nil时应用详情不包含变量，
+// operationService 为nil时导入和批量删除在请求内同步执行
+func NewApplicationHandler(applicationService service.ApplicationServiceInterface, variableService service.ApplicationVariableServiceInterface, operationService service.OperationServiceInterface) *ApplicationHandler {
 	validator := validator.New()
 	validation.RegisterCustomValidators(validator)
 
+	lookups := validation.NewLookups()
+	lookups.Register(v1.LookupApplication, validation.Lookup(func(ctx context.Context, ids []idgen.ID) ([]idgen.ID, error) {
+		apps, _, err := applicationService.QueryApplications(ctx, model.ApplicationQuery{IDs: idgen.Uints(ids)})
+		if err != nil {
+			return nil, err
+		}
+		existing := make([]idgen.ID, len(apps))
+		for i, app := range apps {
+			existing[i] = idgen.ID(app.ID)
+		}
+		return existing, nil
+	}))
+	lookups.Register(v1.LookupApplicationName, validation.Lookup(applicationService.ExistingApplicationNames))
+
 	return &ApplicationHandler{
 		applicationService: applicationService,
+		variableService:    variableService,
+		operationService:   operationService,
+		assembler:          assembler.NewApplicationAssembler(),
+		variableAssembler:  assembler.NewApplicationVariableAssembler(),
+		operationAssembler: assembler.NewOperationAssembler(),
 		validator:          validator,
+		lookups:            lookups,
 	}
 }
 
@@ -39,21 +74,16 @@ func NewApplicationHandler(applicationService service.ApplicationServiceInterfac
 // @Accept json
 // @Produce json
 // @Param request body v1.CreateApplicationRequest true "应用创建请求"
-// @Success 201 {object} response.Response{data=v1.ApplicationResponse} "应用创建成功"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 409 {object} response.Response{error=string} "应用已存在"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Success 201 {object} v1.ApplicationResponseEnvelope "应用创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或名称已被使用"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 409 {object} v1.ErrorEnvelope "并发创建了同名应用"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications [post]
 // @Security BearerAuth
 func (h *ApplicationHandler) CreateApplication(c *gin.Context) {
 	var req v1.CreateApplicationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			details := response.ParseValidationErrors(validationErrors)
-			response.ValidationError(c, details)
-		} else {
-			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
-		}
+	if !bindJSON(c, &req) || !checkLookups(c, h.lookups, &req) {
 		return
 	}
 
@@ -61,15 +91,21 @@ func (h *ApplicationHandler) CreateApplication(c *gin.Context) {
 	app := &model.Application{
 		Name:        req.Name,
 		Description: req.Description,
+		Tags:        req.Tags,
 	}
 
 	// 创建应用
 	createdApp, err := h.applicationService.CreateApplication(c.Request.Context(), app)
 	if err != nil {
 		logger.Error("Failed to create application: %v", err)
-		if errors.Is(err, model.ErrApplicationNotFound) {
-			response.BusinessError(c, response.CodeAppNotFound, "app_exists", err)
-		} else {
+		switch {
+		case errors.Is(err, model.ErrApplicationNameExists):
+			response.Error(c, http.StatusConflict, response.CodeAppExists, "app_exists", err)
+		case isTagError(err):
+			response.Error(c, http.StatusBadRequest, response.CodeAppTagsInvalid, "app_tags_invalid", err)
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		default:
 			response.InternalServerError(c, "internal_error", err)
 		}
 		return
@@ -86,16 +122,18 @@ func (h *ApplicationHandler) CreateApplication(c *gin.Context) {
 // @Tags 应用管理
 // @Accept json
 // @Produce json
-// @Param id path int true "应用ID" minimum(1)
-// @Success 200 {object} response.Response{data=v1.ApplicationResponse} "获取成功"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 404 {object} response.Response{error=string} "应用不存在"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param fields query string false "只返回指定字段，逗号分隔，如 id,name,tags；字段不存在时返回400"
+// @Success 200 {object} v1.ApplicationResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications/{id} [get]
 // @Security BearerAuth
 func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := strconv.ParseUint(idStr, 10, 0)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
 		return
@@ -104,52 +142,75 @@ func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 	app, err := h.applicationService.GetApplicationByID(c.Request.Context(), uint(id))
 	if err != nil {
 		logger.Error("Failed to get application: %v", err)
-		if errors.Is(err, model.ErrApplicationNotFound) {
+		switch {
+		case errors.Is(err, model.ErrApplicationNotFound):
 			response.NotFound(c, "app_not_found", err)
-		} else {
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		default:
 			response.InternalServerError(c, "internal_error", err)
 		}
 		return
 	}
 
 	resp := h.convertToApplicationResponse(app)
+
+	// 应用详情包含非敏感变量
+	if h.variableService != nil {
+		variables, err := h.variableService.ListVariables(c.Request.Context(), app.ID)
+		if err != nil {
+			logger.Error("Failed to list application variables: %v", err)
+			response.InternalServerError(c, "internal_error", err)
+			return
+		}
+		resp.Variables = h.variableAssembler.ToPublicValues(variables)
+	}
+
 	response.Success(c, resp)
 }
 
 // ListApplications godoc
 // @Summary 获取应用列表
-// @Description 分页获取应用列表
+// @Description 分页获取应用列表，可按ID、状态、标签和时间范围过滤并按多个字段排序
 // @Tags 应用管理
 // @Accept json
 // @Produce json
 // @Param page query int false "页码" default(1) minimum(1)
 // @Param size query int false "每页数量" default(10) minimum(1) maximum(100)
-// @Param keyword query string false "搜索关键词" maxlength(100)
-// @Param sort_by query string false "排序字段" example("created_at")
-// @Param sort_desc query bool false "排序方向" default(true)
-// @Param status query string false "应用状态" Enums(active, inactive, deleted)
-// @Success 200 {object} response.Response{data=response.PaginationResponse{items=[]v1.ApplicationResponse}} "获取成功"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Param ids query string false "应用ID，逗号分隔，最多100个，如 1,2,3"
+// @Param status query string false "应用状态，逗号分隔且匹配任意一个，可选 active, inactive, deleted"
+// @Param tags query string false "标签选择器，逗号分隔且全部匹配，如 env:prod,team:core"
+// @Param created_at query string false "创建时间范围 from,to，RFC3339时间或日期，可省略一端，如 2024-01-01,2024-01-31"
+// @Param updated_at query string false "更新时间范围 from,to，RFC3339时间或日期，可省略一端，如 2024-01-01T00:00:00Z,"
+// @Param sort query string false "排序表达式，逗号分隔的 字段:asc|desc，字段可选 id, name, created_at, updated_at，最多3个" example("name:asc,created_at:desc")
+// @Param fields query string false "只返回指定字段，逗号分隔，如 id,name,tags；字段不存在时返回400"
+// @Success 200 {object} v1.ApplicationResponsePageEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications [get]
 // @Security BearerAuth
 func (h *ApplicationHandler) ListApplications(c *gin.Context) {
 	var req v1.ListApplicationsRequest
-	if err := c.ShouldBindQuery(&req); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			details := response.ParseValidationErrors(validationErrors)
-			response.ValidationError(c, details)
-		} else {
-			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
-		}
+	if !bindQuery(c, &req) {
 		return
 	}
+	page := req.Pagination()
 
-	// 设置默认值
-	req.PageRequest.Validate()
+	// 列表不含已删除的应用，状态都是active
+	if len(req.Status) > 0 && !req.Status.Contains(model.ApplicationStatusActive) {
+		response.Page(c, []v1.ApplicationResponse{}, page.Page, page.Size, 0)
+		return
+	}
+
+	// 解析标签选择器
+	selector, err := model.ParseTagSelector(req.Tags)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeAppTagsInvalid, "app_tags_invalid", err)
+		return
+	}
 
 	// 调用服务
-	apps, total, err := h.applicationService.ListApplications(c.Request.Context(), req.Page, req.Size)
+	apps, total, err := h.applicationService.QueryApplications(c.Request.Context(), h.assembler.ToQuery(&req, selector))
 	if err != nil {
 		logger.Error("Failed to list applications: %v", err)
 		response.InternalServerError(c, "internal_error", err)
@@ -162,33 +223,211 @@ func (h *ApplicationHandler) ListApplications(c *gin.Context) {
 		items[i] = h.convertToApplicationResponse(app)
 	}
 
-	response.Page(c, items, req.Page, req.Size, int(total))
+	response.Page(c, items, page.Page, page.Size, int(total))
 }
 
 // UpdateApplication godoc
 // @Summary 更新应用
-// @Description 更新应用信息
+// @Description 更新应用信息，空的名称和描述以及省略的标签保持不变；需要清空描述时使用PATCH
 // @Tags 应用管理
 // @Accept json
 // @Produce json
-// @Param id path int true "应用ID" minimum(1)
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
 // @Param request body v1.UpdateApplicationRequest true "应用更新请求"
-// @Success 200 {object} response.Response{data=v1.ApplicationResponse} "更新成功"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 404 {object} response.Response{error=string} "应用不存在"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Success 200 {object} v1.ApplicationResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "应用已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications/{id} [put]
 // @Security BearerAuth
 func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := strconv.ParseUint(idStr, 10, 0)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
 		return
 	}
 
 	var req v1.UpdateApplicationRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	app, err := h.applicationService.PatchApplication(c.Request.Context(), uint(id), h.assembler.ToUpdatePatch(&req))
+	h.respondUpdate(c, app, err)
+}
+
+// PatchApplication godoc
+// @Summary 部分更新应用
+// @Description 按JSON Merge Patch (RFC 7396) 部分更新应用：省略的字段保持不变，description或tags为null时清空，name不能为null。
+// @Description 请求体为application/merge-patch+json或application/json，其他类型返回415。
+// @Tags 应用管理
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param request body v1.PatchApplicationRequest true "应用合并补丁"
+// @Success 200 {object} v1.ApplicationResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "应用已存在"
+// @Failure 415 {object} v1.ErrorEnvelope "不支持的媒体类型"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id} [patch]
+// @Security BearerAuth
+func (h *ApplicationHandler) PatchApplication(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	if contentType := c.ContentType(); contentType != mergePatchContentType && contentType != gin.MIMEJSON {
+		response.Error(c, http.StatusUnsupportedMediaType, response.CodeUnsupportedMediaType, "unsupported_media_type",
+			fmt.Errorf("unsupported content type %q, expected %s", contentType, mergePatchContentType))
+		return
+	}
+
+	var req v1.PatchApplicationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	app, err := h.applicationService.PatchApplication(c.Request.Context(), uint(id), h.assembler.ToPatch(&req))
+	h.respondUpdate(c, app, err)
+}
+
+// respondUpdate 返回应用更新结果
+func (h *ApplicationHandler) respondUpdate(c *gin.Context, app *model.Application, err error) {
+	if err != nil {
+		logger.Error("Failed to update application: %v", err)
+		switch {
+		case errors.Is(err, model.ErrApplicationNameExists):
+			response.Error(c, http.StatusConflict, response.CodeAppExists, "app_exists", err)
+		case errors.Is(err, model.ErrApplicationNotFound):
+			response.NotFound(c, "app_not_found", err)
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		case isTagError(err):
+			response.Error(c, http.StatusBadRequest, response.CodeAppTagsInvalid, "app_tags_invalid", err)
+		default:
+			response.InternalServerError(c, "internal_error", err)
+		}
+		return
+	}
+
+	resp := h.convertToApplicationResponse(app)
+	response.WithMessage(c, resp, "app_updated")
+}
+
+// AddApplicationTags godoc
+// @Summary 添加应用标签
+// @Description 为应用添加标签，已有标签保持不变
+// @Tags 应用管理
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param request body v1.ApplicationTagsRequest true "标签请求"
+// @Success 200 {object} v1.ApplicationResponseEnvelope "添加成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/tags [post]
+// @Security BearerAuth
+func (h *ApplicationHandler) AddApplicationTags(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	var req v1.ApplicationTagsRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	app, err := h.applicationService.AddApplicationTags(c.Request.Context(), uint(id), req.Tags)
+	h.respondTags(c, app, err)
+}
+
+// RemoveApplicationTag godoc
+// @Summary 删除应用标签
+// @Description 删除应用的指定标签，应用没有该标签时不做处理
+// @Tags 应用管理
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param tag path string true "标签，如 env:prod，可含 \"/\"，如 team:core/api"
+// @Success 200 {object} v1.ApplicationResponseEnvelope "删除成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/tags/{tag} [delete]
+// @Security BearerAuth
+func (h *ApplicationHandler) RemoveApplicationTag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	// 通配参数以 "/" 开头，其后为完整标签
+	tag := strings.TrimPrefix(c.Param("tag"), "/")
+	app, err := h.applicationService.RemoveApplicationTags(c.Request.Context(), uint(id), []string{tag})
+	h.respondTags(c, app, err)
+}
+
+// respondTags 返回标签更新结果
+func (h *ApplicationHandler) respondTags(c *gin.Context, app *model.Application, err error) {
+	if err != nil {
+		logger.Error("Failed to update application tags: %v", err)
+		switch {
+		case errors.Is(err, model.ErrApplicationNotFound):
+			response.NotFound(c, "app_not_found", err)
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		case isTagError(err):
+			response.Error(c, http.StatusBadRequest, response.CodeAppTagsInvalid, "app_tags_invalid", err)
+		default:
+			response.InternalServerError(c, "internal_error", err)
+		}
+		return
+	}
+
+	resp := h.convertToApplicationResponse(app)
+	response.WithMessage(c, resp, "app_tags_updated")
+}
+
+// ListApplicationRevisions godoc
+// @Summary 获取应用修订记录
+// @Description 分页获取应用的修订记录，最新的在前
+// @Tags 应用管理
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param page query int false "页码" default(1) minimum(1)
+// @Param size query int false "每页数量" default(10) minimum(1) maximum(100)
+// @Success 200 {object} v1.ApplicationRevisionResponsePageEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/revisions [get]
+// @Security BearerAuth
+func (h *ApplicationHandler) ListApplicationRevisions(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	var req v1.PageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
 			details := response.ParseValidationErrors(validationErrors)
 			response.ValidationError(c, details)
@@ -197,37 +436,72 @@ func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 		}
 		return
 	}
+	req.Validate()
 
-	// 获取现有应用
-	app, err := h.applicationService.GetApplicationByID(c.Request.Context(), uint(id))
+	revisions, total, err := h.applicationService.ListApplicationRevisions(c.Request.Context(), uint(id), req.Page, req.Size)
 	if err != nil {
-		logger.Error("Failed to get application: %v", err)
-		if errors.Is(err, model.ErrApplicationNotFound) {
+		logger.Error("Failed to list application revisions: %v", err)
+		switch {
+		case errors.Is(err, model.ErrApplicationNotFound):
 			response.NotFound(c, "app_not_found", err)
-		} else {
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		default:
 			response.InternalServerError(c, "internal_error", err)
 		}
 		return
 	}
 
-	// 更新字段
-	if req.Name != "" {
-		app.Name = req.Name
+	response.Page(c, h.assembler.ToRevisionResponseList(revisions), req.Page, req.Size, int(total))
+}
+
+// RollbackApplication godoc
+// @Summary 回滚应用
+// @Description 将应用恢复到指定修订的状态，回滚本身记录为新的修订
+// @Tags 应用管理
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param revision path int true "修订号" minimum(1)
+// @Success 200 {object} v1.ApplicationResponseEnvelope "回滚成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用或修订记录不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "应用名称已被占用"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/rollback/{revision} [post]
+// @Security BearerAuth
+func (h *ApplicationHandler) RollbackApplication(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
 	}
-	if req.Description != "" {
-		app.Description = req.Description
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil || revision < 1 {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("invalid revision %q", c.Param("revision")))
+		return
 	}
 
-	// 更新应用
-	updatedApp, err := h.applicationService.UpdateApplication(c.Request.Context(), app)
+	app, err := h.applicationService.RollbackApplication(c.Request.Context(), uint(id), revision)
 	if err != nil {
-		logger.Error("Failed to update application: %v", err)
-		response.InternalServerError(c, "internal_error", err)
+		logger.Error("Failed to roll back application: %v", err)
+		switch {
+		case errors.Is(err, model.ErrApplicationNotFound):
+			response.NotFound(c, "app_not_found", err)
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		case errors.Is(err, model.ErrRevisionNotFound):
+			response.Error(c, http.StatusNotFound, response.CodeAppRevisionNotFound, "app_revision_not_found", err)
+		case errors.Is(err, model.ErrApplicationNameExists):
+			response.Error(c, http.StatusConflict, response.CodeAppExists, "app_exists", err)
+		default:
+			response.InternalServerError(c, "internal_error", err)
+		}
 		return
 	}
 
-	resp := h.convertToApplicationResponse(updatedApp)
-	response.WithMessage(c, resp, "app_updated")
+	response.WithMessage(c, h.convertToApplicationResponse(app), "app_rolled_back")
 }
 
 // DeleteApplication godoc
@@ -236,16 +510,17 @@ func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
 // @Tags 应用管理
 // @Accept json
 // @Produce json
-// @Param id path int true "应用ID" minimum(1)
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
 // @Success 204 "删除成功"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 404 {object} response.Response{error=string} "应用不存在"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications/{id} [delete]
 // @Security BearerAuth
 func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 	idStr := c.Param("id")
-	id, err := strconv.ParseUint(idStr, 10, 32)
+	id, err := strconv.ParseUint(idStr, 10, 0)
 	if err != nil {
 		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
 		return
@@ -254,9 +529,12 @@ func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 	err = h.applicationService.DeleteApplication(c.Request.Context(), uint(id))
 	if err != nil {
 		logger.Error("Failed to delete application: %v", err)
-		if errors.Is(err, model.ErrApplicationNotFound) {
+		switch {
+		case errors.Is(err, model.ErrApplicationNotFound):
 			response.NotFound(c, "app_not_found", err)
-		} else {
+		case errors.Is(err, model.ErrAccessDenied):
+			response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+		default:
 			response.InternalServerError(c, "internal_error", err)
 		}
 		return
@@ -271,8 +549,8 @@ func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 // @Tags 应用管理
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.Response{data=v1.ApplicationStatsResponse} "获取成功"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Success 200 {object} v1.ApplicationStatsResponseEnvelope "获取成功"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications/stats [get]
 // @Security BearerAuth
 func (h *ApplicationHandler) GetApplicationStats(c *gin.Context) {
@@ -291,51 +569,93 @@ func (h *ApplicationHandler) GetApplicationStats(c *gin.Context) {
 
 // BatchDeleteApplications godoc
 // @Summary 批量删除应用
-// @Description 批量删除多个应用
+// @Description 以后台任务在同一事务中批量删除多个应用，每张表只执行一条删除语句，结果逐一报告未删除的应用及原因。
+// @Description 未设置continue_on_error时任一应用无法删除则全部不删除，删除多于100个应用时须设置force确认；创建任务前逐一检查应用是否存在，不存在的ID作为参数错误返回。
 // @Tags 应用管理
 // @Accept json
 // @Produce json
 // @Param request body v1.BatchDeleteApplicationsRequest true "批量删除请求"
-// @Success 200 {object} response.Response{data=v1.BulkOperationResponse} "操作完成"
-// @Failure 400 {object} response.Response{error=string} "参数错误"
-// @Failure 500 {object} response.Response{error=string} "服务器内部错误"
+// @Success 202 {object} v1.OperationResponseEnvelope "删除任务已创建"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或应用不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "应用被并发删除，请重试"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
 // @Router /applications/batch-delete [post]
 // @Security BearerAuth
 func (h *ApplicationHandler) BatchDeleteApplications(c *gin.Context) {
 	var req v1.BatchDeleteApplicationsRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		if validationErrors, ok := err.(validator.ValidationErrors); ok {
-			details := response.ParseValidationErrors(validationErrors)
-			response.ValidationError(c, details)
-		} else {
-			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
-		}
+	if !bindJSON(c, &req) || !checkLookups(c, h.lookups, &req) {
 		return
 	}
 
-	var failures []v1.BulkFailureItem
-	successCount := 0
-
-	for _, id := range req.IDs {
-		err := h.applicationService.DeleteApplication(c.Request.Context(), id)
+	h.runOperation(c, model.OperationTypeApplicationBatchDelete, "success", func(ctx context.Context, progress service.ProgressFunc) (interface{}, error) {
+		// 在同一工作单元内删除，未设置continue_on_error时任一失败则全部不删除
+		ids := distinctIDs(idgen.Uints(req.IDs))
+		failures, err := h.applicationService.BatchDeleteApplications(ctx, ids, service.BatchDeleteOptions{
+			ContinueOnError: req.ContinueOnError,
+			Permanent:       req.Permanent,
+		})
 		if err != nil {
-			failures = append(failures, v1.BulkFailureItem{
-				ID:     strconv.FormatUint(uint64(id), 10),
-				Reason: err.Error(),
+			return nil, err
+		}
+
+		result := v1.BulkOperationResponse{
+			SuccessCount: len(ids) - len(failures),
+			FailureCount: len(failures),
+			TotalCount:   len(ids),
+		}
+		for _, failure := range failures {
+			result.Failures = append(result.Failures, v1.BulkFailureItem{
+				ID:     strconv.FormatUint(uint64(failure.ID), 10),
+				Reason: failure.Err.Error(),
 			})
-		} else {
-			successCount++
 		}
+		return result, nil
+	}, func(c *gin.Context, err error) {
+		switch {
+		case errors.Is(err, model.ErrApplicationBatchConflict):
+			response.Conflict(c, "conflict", err)
+		default:
+			logger.Error("Failed to batch delete applications: %v", err)
+			response.InternalServerError(c, "internal_error", err)
+		}
+	})
+}
+
+// distinctIDs 返回去重后的ID，保持首次出现的顺序
+func distinctIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	out := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// runOperation 以后台任务执行fn并返回202和任务，客户端通过任务接口获取结果。
+// 未配置任务服务时在请求内同步执行：成功时以messageKey返回结果，失败时交给onError处理。
+func (h *ApplicationHandler) runOperation(c *gin.Context, opType, messageKey string, fn service.OperationFunc, onError func(*gin.Context, error)) {
+	ctx := c.Request.Context()
+	if h.operationService == nil {
+		result, err := fn(ctx, func(int, string) {})
+		if err != nil {
+			onError(c, err)
+			return
+		}
+		response.WithMessage(c, result, messageKey)
+		return
 	}
 
-	result := v1.BulkOperationResponse{
-		SuccessCount: successCount,
-		FailureCount: len(failures),
-		TotalCount:   len(req.IDs),
-		Failures:     failures,
+	op, err := h.operationService.StartOperation(ctx, &model.Operation{Type: opType}, fn)
+	if err != nil {
+		logger.Error("Failed to start %s operation: %v", opType, err)
+		response.InternalServerError(c, "internal_error", err)
+		return
 	}
 
-	response.Success(c, result)
+	response.Accepted(c, h.operationAssembler.ToResponse(op), "operation_accepted")
 }
 
 // HealthCheck godoc
@@ -344,8 +664,8 @@ func (h *ApplicationHandler) BatchDeleteApplications(c *gin.Context) {
 // @Tags 系统
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.Response{data=v1.HealthCheckResponse} "服务正常"
-// @Failure 500 {object} response.Response{error=string} "服务异常"
+// @Success 200 {object} v1.HealthCheckResponseEnvelope "服务正常"
+// @Failure 500 {object} v1.ErrorEnvelope "服务异常"
 // @Router /applications/health [get]
 func (h *ApplicationHandler) HealthCheck(c *gin.Context) {
 	healthResp := v1.HealthCheckResponse{
@@ -359,12 +679,10 @@ func (h *ApplicationHandler) HealthCheck(c *gin.Context) {
 
 // convertToApplicationResponse 转换为应用响应
 func (h *ApplicationHandler) convertToApplicationResponse(app *model.Application) v1.ApplicationResponse {
-	return v1.ApplicationResponse{
-		ID:          app.ID,
-		Name:        app.Name,
-		Description: app.Description,
-		Status:      "active", // 这里应该从模型中获取状态
-		CreatedAt:   app.CreatedAt,
-		UpdatedAt:   app.UpdatedAt,
-	}
+	return *h.assembler.ToResponse(app)
+}
+
+// isTagError 判断是否为标签校验错误
+func isTagError(err error) bool {
+	return errors.Is(err, model.ErrTagInvalid) || errors.Is(err, model.ErrTooManyTags)
 }
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ApplicationBackupHandler 应用备份处理器
+type ApplicationBackupHandler struct {
+	backupService        service.ApplicationBackupServiceInterface
+	assembler            *assembler.ApplicationBackupAssembler
+	applicationAssembler *assembler.ApplicationAssembler
+}
+
+// NewApplicationBackupHandler 创建应用备份处理器
+func NewApplicationBackupHandler(backupService service.ApplicationBackupServiceInterface) *ApplicationBackupHandler {
+	return &ApplicationBackupHandler{
+		backupService:        backupService,
+		assembler:            assembler.NewApplicationBackupAssembler(),
+		applicationAssembler: assembler.NewApplicationAssembler(),
+	}
+}
+
+// CreateBackup godoc
+// @Summary 创建应用备份
+// @Description 异步备份应用，可选包含变量和修订记录；返回的备份任务通过查询接口轮询状态
+// @Tags 应用备份
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param request body v1.ApplicationBackupRequest true "应用备份请求"
+// @Success 202 {object} v1.ApplicationBackupResponseEnvelope "备份任务已创建"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/backups [post]
+// @Security BearerAuth
+func (h *ApplicationBackupHandler) CreateBackup(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.ApplicationBackupRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	backup, err := h.backupService.CreateBackup(c.Request.Context(), h.assembler.ToModel(appID, &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Accepted(c, h.assembler.ToResponse(backup), "app_backup_created")
+}
+
+// ListBackups godoc
+// @Summary 获取应用备份列表
+// @Description 分页获取应用的备份，最新的在前；应用删除后仍可查询
+// @Tags 应用备份
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param page query int false "页码" default(1) minimum(1)
+// @Param size query int false "每页数量" default(10) minimum(1) maximum(100)
+// @Success 200 {object} v1.ApplicationBackupResponsePageEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/backups [get]
+// @Security BearerAuth
+func (h *ApplicationBackupHandler) ListBackups(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.PageRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+	req.Validate()
+
+	backups, total, err := h.backupService.ListBackups(c.Request.Context(), appID, req.Page, req.Size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Page(c, h.assembler.ToResponseList(backups), req.Page, req.Size, int(total))
+}
+
+// GetBackup godoc
+// @Summary 获取应用备份
+// @Description 根据备份ID获取备份任务的状态和结果
+// @Tags 应用备份
+// @Accept json
+// @Produce json
+// @Param backup_id path string true "备份ID"
+// @Success 200 {object} v1.ApplicationBackupResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "备份不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/backups/{backup_id} [get]
+// @Security BearerAuth
+func (h *ApplicationBackupHandler) GetBackup(c *gin.Context) {
+	backup, err := h.backupService.GetBackup(c.Request.Context(), c.Param("backup_id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(backup))
+}
+
+// RestoreBackup godoc
+// @Summary 从备份恢复应用
+// @Description 根据已完成的备份重新创建应用及其变量和修订记录，恢复的应用使用新的ID
+// @Tags 应用备份
+// @Accept json
+// @Produce json
+// @Param backup_id path string true "备份ID"
+// @Param request body v1.ApplicationRestoreRequest false "应用恢复请求"
+// @Success 201 {object} v1.ApplicationResponseEnvelope "恢复成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "备份不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "备份尚未完成或应用名称已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/backups/{backup_id}/restore [post]
+// @Security BearerAuth
+func (h *ApplicationBackupHandler) RestoreBackup(c *gin.Context) {
+	// 请求体可选，为空时使用备份中的应用名称
+	var req v1.ApplicationRestoreRequest
+	if c.Request.ContentLength != 0 && !bindJSON(c, &req) {
+		return
+	}
+
+	app, err := h.backupService.RestoreBackup(c.Request.Context(), c.Param("backup_id"), req.Name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.applicationAssembler.ToResponse(app), "app_restored")
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *ApplicationBackupHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrApplicationNotFound):
+		response.NotFound(c, "app_not_found", err)
+	case errors.Is(err, model.ErrAccessDenied):
+		response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+	case errors.Is(err, model.ErrBackupNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeAppBackupNotFound, "app_backup_not_found", err)
+	case errors.Is(err, model.ErrBackupNotCompleted):
+		response.Error(c, http.StatusConflict, response.CodeAppBackupNotReady, "app_backup_not_ready", err)
+	case errors.Is(err, model.ErrApplicationNameExists):
+		response.Error(c, http.StatusConflict, response.CodeAppExists, "app_exists", err)
+	case errors.Is(err, model.ErrBackupCorrupted):
+		logger.Error("Application backup is corrupted: %v", err)
+		response.Error(c, http.StatusInternalServerError, response.CodeAppBackupFailed, "app_backup_failed", err)
+	default:
+		logger.Error("Application backup operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/xlsx"
+)
+
+const (
+	// exportPageSize 导出时每批读取的应用数量
+	exportPageSize = 100
+	// maxImportFileSize 导入文件的大小上限
+	maxImportFileSize = 10 << 20
+	// maxImportRows 单次导入的最大数据行数
+	maxImportRows = 10000
+)
+
+// recordWriter 表格记录写入器，CSV和XLSX导出共用
+type recordWriter interface {
+	Write(record []string) error
+	Flush() error
+	Close() error
+}
+
+// csvRecordWriter 转义公式后写入CSV记录
+type csvRecordWriter struct {
+	w *csv.Writer
+}
+
+// Write 写入一条记录
+func (w csvRecordWriter) Write(record []string) error {
+	escaped := make([]string, len(record))
+	for i, value := range record {
+		escaped[i] = assembler.EscapeFormula(value)
+	}
+	return w.w.Write(escaped)
+}
+
+// Flush 刷新缓冲的记录
+func (w csvRecordWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+// Close 刷新并结束写入
+func (w csvRecordWriter) Close() error {
+	return w.Flush()
+}
+
+// ExportApplications godoc
+// @Summary 导出应用
+// @Description 以CSV或XLSX文件流式导出应用，支持选择列和与列表相同的标签过滤
+// @Tags 应用管理
+// @Accept json
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "导出格式" Enums(csv, xlsx) default(csv)
+// @Param columns query string false "导出的列，逗号分隔" default(id,name,description,tags,created_at,updated_at)
+// @Param tags query string false "标签选择器，逗号分隔且全部匹配"
+// @Success 200 {file} file "应用文件"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/export [get]
+// @Security BearerAuth
+func (h *ApplicationHandler) ExportApplications(c *gin.Context) {
+	var req v1.ExportApplicationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	columns, err := h.assembler.ParseColumns(req.Columns)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+	selector, err := model.ParseTagSelector(req.Tags)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeAppTagsInvalid, "app_tags_invalid", err)
+		return
+	}
+
+	// 写出文件前读取第一批，错误仍可以JSON响应返回
+	ctx := c.Request.Context()
+	apps, total, err := h.applicationService.ListApplicationsByTags(ctx, selector, 1, exportPageSize)
+	if err != nil {
+		logger.Error("Failed to export applications: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	filename := "applications-" + time.Now().UTC().Format("20060102")
+	var writer recordWriter
+	if req.Format == assembler.ApplicationFormatXLSX {
+		c.Header("Content-Type", xlsx.ContentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".xlsx"))
+		c.Status(http.StatusOK)
+		if writer, err = xlsx.NewWriter(c.Writer, "applications"); err != nil {
+			logger.Error("Failed to export applications: %v", err)
+			return
+		}
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".csv"))
+		c.Status(http.StatusOK)
+		writer = csvRecordWriter{w: csv.NewWriter(c.Writer)}
+	}
+
+	// 响应头已发送，此后的错误只能记录日志并中断输出
+	if err := writer.Write(columns); err != nil {
+		logger.Error("Failed to export applications: %v", err)
+		return
+	}
+	for page := 1; ; page++ {
+		if page > 1 {
+			if apps, total, err = h.applicationService.ListApplicationsByTags(ctx, selector, page, exportPageSize); err != nil {
+				logger.Error("Failed to export applications: %v", err)
+				return
+			}
+		}
+
+		for _, app := range apps {
+			if err := writer.Write(h.assembler.ToRecord(app, columns)); err != nil {
+				logger.Error("Failed to export applications: %v", err)
+				return
+			}
+		}
+		if err := writer.Flush(); err != nil {
+			logger.Error("Failed to export applications: %v", err)
+			return
+		}
+		c.Writer.Flush()
+
+		if len(apps) < exportPageSize || int64(page*exportPageSize) >= total {
+			break
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		logger.Error("Failed to export applications: %v", err)
+	}
+}
+
+// ImportApplications godoc
+// @Summary 导入应用
+// @Description 从CSV或XLSX文件导入应用，首行为列名且必须包含name列，可包含description和tags列（tags以逗号分隔）。
+// @Description 文件在请求内解析和校验格式，之后以后台任务逐行校验和创建，失败的行在任务结果中以行号列出；试运行时仅校验不创建。
+// @Tags 应用管理
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV或XLSX文件"
+// @Param format query string false "文件格式，为空时根据扩展名判断" Enums(csv, xlsx)
+// @Param dry_run query bool false "是否试运行" default(false)
+// @Success 202 {object} v1.OperationResponseEnvelope "导入任务已创建，结果为批量操作结果，失败项ID为行号"
+// @Failure 400 {object} v1.ErrorEnvelope "文件或参数错误"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/import [post]
+// @Security BearerAuth
+func (h *ApplicationHandler) ImportApplications(c *gin.Context) {
+	var req v1.ImportApplicationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	// 限制请求体大小，为multipart表单的其余部分预留空间
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeFileUploadFailed, "file_upload_failed", err)
+		return
+	}
+	if header.Size > maxImportFileSize {
+		response.Error(c, http.StatusBadRequest, response.CodeFileTooBig, "file_too_big", fmt.Errorf("the file exceeds %d bytes", maxImportFileSize))
+		return
+	}
+
+	format := req.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(header.Filename)), ".")
+	}
+	if format != assembler.ApplicationFormatCSV && format != assembler.ApplicationFormatXLSX {
+		response.Error(c, http.StatusBadRequest, response.CodeFileTypeNotSupported, "file_type_not_supported", fmt.Errorf("unsupported file %q, expected .csv or .xlsx", header.Filename))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeFileUploadFailed, "file_upload_failed", err)
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeFileUploadFailed, "file_upload_failed", err)
+		return
+	}
+
+	records, err := readRecords(format, data, maxImportRows+1)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeFileCorrupted, "file_corrupted", err)
+		return
+	}
+	if len(records) > maxImportRows+1 {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("the file has more than %d rows", maxImportRows))
+		return
+	}
+
+	apps, rows, err := h.assembler.FromRecords(records)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	messageKey := "app_imported"
+	if req.DryRun {
+		messageKey = "app_import_validated"
+	}
+	h.runOperation(c, model.OperationTypeApplicationImport, messageKey, func(ctx context.Context, progress service.ProgressFunc) (interface{}, error) {
+		failures, err := h.applicationService.ImportApplications(ctx, apps, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+
+		result := v1.BulkOperationResponse{
+			SuccessCount: len(apps) - len(failures),
+			FailureCount: len(failures),
+			TotalCount:   len(apps),
+			DryRun:       req.DryRun,
+		}
+		for _, failure := range failures {
+			result.Failures = append(result.Failures, v1.BulkFailureItem{
+				ID:     strconv.Itoa(rows[failure.Index]),
+				Reason: failure.Err.Error(),
+			})
+		}
+		return result, nil
+	}, func(c *gin.Context, err error) {
+		logger.Error("Failed to import applications: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	})
+}
+
+// readRecords 读取CSV或XLSX文件的全部行，XLSX文件超过maxRows行时返回错误
+func readRecords(format string, data []byte, maxRows int) ([][]string, error) {
+	if format == assembler.ApplicationFormatXLSX {
+		return xlsx.ReadAll(bytes.NewReader(data), int64(len(data)), maxRows)
+	}
+
+	// 去除电子表格软件保存CSV时添加的UTF-8 BOM
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
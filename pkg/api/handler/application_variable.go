@@ -0,0 +1,329 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ApplicationVariableHandler 应用变量处理器
+type ApplicationVariableHandler struct {
+	variableService service.ApplicationVariableServiceInterface
+	assembler       *assembler.ApplicationVariableAssembler
+}
+
+// NewApplicationVariableHandler 创建应用变量处理器
+func NewApplicationVariableHandler(variableService service.ApplicationVariableServiceInterface) *ApplicationVariableHandler {
+	return &ApplicationVariableHandler{
+		variableService: variableService,
+		assembler:       assembler.NewApplicationVariableAssembler(),
+	}
+}
+
+// ListVariables godoc
+// @Summary 获取应用变量列表
+// @Description 获取应用的全部变量，敏感变量的值已脱敏
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.ApplicationVariableResponseListEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables [get]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) ListVariables(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	variables, err := h.variableService.ListVariables(c.Request.Context(), appID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(variables))
+}
+
+// GetVariable godoc
+// @Summary 获取应用变量
+// @Description 根据变量名获取应用变量，敏感变量的值已脱敏
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param key path string true "变量名"
+// @Success 200 {object} v1.ApplicationVariableResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用或变量不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables/{key} [get]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) GetVariable(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	variable, err := h.variableService.GetVariable(c.Request.Context(), appID, c.Param("key"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(variable))
+}
+
+// CreateVariable godoc
+// @Summary 创建应用变量
+// @Description 为应用创建变量，敏感变量加密存储
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param request body v1.CreateApplicationVariableRequest true "应用变量创建请求"
+// @Success 201 {object} v1.ApplicationVariableResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "变量已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables [post]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) CreateVariable(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.CreateApplicationVariableRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	variable, err := h.variableService.CreateVariable(c.Request.Context(), h.assembler.ToModel(appID, &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToResponse(variable), "app_variable_created")
+}
+
+// UpdateVariable godoc
+// @Summary 更新应用变量
+// @Description 更新应用变量的值或敏感标记，未提供的字段保持不变
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param key path string true "变量名"
+// @Param request body v1.UpdateApplicationVariableRequest true "应用变量更新请求"
+// @Success 200 {object} v1.ApplicationVariableResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用或变量不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables/{key} [put]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) UpdateVariable(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.UpdateApplicationVariableRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	variable, err := h.variableService.GetVariable(c.Request.Context(), appID, c.Param("key"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	h.assembler.ApplyUpdate(variable, &req)
+
+	result, err := h.variableService.UpdateVariable(c.Request.Context(), variable)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToResponse(result), "app_variable_updated")
+}
+
+// DeleteVariable godoc
+// @Summary 删除应用变量
+// @Description 删除应用的指定变量
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param key path string true "变量名"
+// @Success 204 "删除成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用或变量不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables/{key} [delete]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) DeleteVariable(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.variableService.DeleteVariable(c.Request.Context(), appID, c.Param("key")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ImportVariables godoc
+// @Summary 导入应用变量
+// @Description 导入dotenv或JSON格式的变量，已存在的变量将被更新；任一变量无效则全部不导入
+// @Tags 应用变量
+// @Accept json
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param request body v1.ImportApplicationVariablesRequest true "导入请求"
+// @Success 200 {object} v1.ImportApplicationVariablesResponseEnvelope "导入成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "无权操作该应用"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables/import [post]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) ImportVariables(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.ImportApplicationVariablesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	variables, err := h.assembler.FromImport(appID, &req)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeAppVariableInvalid, "app_variable_invalid", err)
+		return
+	}
+
+	created, updated, err := h.variableService.ImportVariables(c.Request.Context(), appID, variables)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, v1.ImportApplicationVariablesResponse{Created: created, Updated: updated}, "app_variables_imported")
+}
+
+// ExportVariables godoc
+// @Summary 导出应用变量
+// @Description 以dotenv或JSON文件导出应用变量，默认不包含敏感变量
+// @Tags 应用变量
+// @Accept json
+// @Produce plain
+// @Produce json
+// @Param id path string true "应用ID或公开ID（UUID）" example(1)
+// @Param format query string false "导出格式" Enums(dotenv, json) default(dotenv)
+// @Param include_secrets query bool false "是否包含敏感变量（仅管理员）" default(false)
+// @Success 200 {file} file "变量文件"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "应用不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /applications/{id}/variables/export [get]
+// @Security BearerAuth
+func (h *ApplicationVariableHandler) ExportVariables(c *gin.Context) {
+	appID, ok := applicationID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.ExportApplicationVariablesRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	// 敏感变量仅允许管理员导出
+	if user, _ := principal.CurrentUser(c); req.IncludeSecrets && !user.HasRole("admin") {
+		response.Forbidden(c, "forbidden", fmt.Errorf("only administrators can export secret variables"))
+		return
+	}
+
+	variables, err := h.variableService.ListVariables(c.Request.Context(), appID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	data, err := h.assembler.ToExport(variables, req.Format, req.IncludeSecrets)
+	if err != nil {
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	contentType, filename := "text/plain; charset=utf-8", fmt.Sprintf("application-%d.env", appID)
+	if req.Format == assembler.VariableFormatJSON {
+		contentType, filename = "application/json; charset=utf-8", fmt.Sprintf("application-%d.json", appID)
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Data(http.StatusOK, contentType, data)
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *ApplicationVariableHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrApplicationNotFound):
+		response.NotFound(c, "app_not_found", err)
+	case errors.Is(err, model.ErrAccessDenied):
+		response.Error(c, http.StatusForbidden, response.CodeAppPermissionDenied, "app_permission_denied", err)
+	case errors.Is(err, model.ErrVariableNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeAppVariableNotFound, "app_variable_not_found", err)
+	case errors.Is(err, model.ErrVariableExists):
+		response.Error(c, http.StatusConflict, response.CodeAppVariableExists, "app_variable_exists", err)
+	case errors.Is(err, model.ErrVariableKeyRequired), errors.Is(err, model.ErrVariableKeyInvalid),
+		errors.Is(err, model.ErrVariableValueTooLong):
+		response.Error(c, http.StatusBadRequest, response.CodeAppVariableInvalid, "app_variable_invalid", err)
+	default:
+		logger.Error("Application variable operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
+
+// applicationID 解析路径中的应用ID，失败时写入参数错误响应
+func applicationID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return 0, false
+	}
+	return uint(id), true
+}
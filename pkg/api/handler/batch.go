@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// BatchPath 批量请求端点相对于API版本的路径
+const BatchPath = "/batch"
+
+// batchForwardedHeaders 子请求沿用的批量请求头：认证、CSRF、内容协商、客户端IP和链路信息
+var batchForwardedHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"X-CSRF-Token",
+	"Accept",
+	"Accept-Language",
+	"User-Agent",
+	"X-Forwarded-For",
+	"X-Real-IP",
+	"Traceparent",
+	"Tracestate",
+}
+
+// BatchHandler 批量请求处理器，将子请求交给路由重新分发，
+// 子请求各自经过完整的中间件链，分别认证、限流并计入配额
+type BatchHandler struct {
+	router   http.Handler
+	basePath string
+	config   config.BatchConfig
+}
+
+// NewBatchHandler 创建批量请求处理器，子请求的路径相对于basePath
+func NewBatchHandler(router http.Handler, basePath string, cfg config.BatchConfig) *BatchHandler {
+	return &BatchHandler{
+		router:   router,
+		basePath: strings.TrimSuffix(basePath, "/"),
+		config:   cfg,
+	}
+}
+
+// Batch godoc
+// @Summary 批量请求
+// @Description 在一次往返中执行多个API请求，子请求与批量请求共用认证信息，最多同时执行 server.batch.max_concurrency 个，按顺序返回各自的状态码和响应体
+// @Tags 批量
+// @Accept json
+// @Produce json
+// @Param request body v1.BatchRequest true "子请求"
+// @Success 200 {object} v1.BatchResponseEnvelope "执行完成，子请求的结果见各自的状态码"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Router /batch [post]
+// @Security BearerAuth
+func (h *BatchHandler) Batch(c *gin.Context) {
+	var req v1.BatchRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if len(req.Requests) > h.config.MaxRequests {
+		err := fmt.Errorf("a batch must not exceed %d requests", h.config.MaxRequests)
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	results := make([]v1.BatchItemResponse, len(req.Requests))
+	slots := make(chan struct{}, max(h.config.MaxConcurrency, 1))
+	var wg sync.WaitGroup
+	for i, item := range req.Requests {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, item v1.BatchItemRequest) {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			results[i] = h.execute(c, item)
+		}(i, item)
+	}
+	wg.Wait()
+
+	response.Success(c, v1.BatchResponse{Responses: results})
+}
+
+// execute 执行一个子请求并返回其结果，路径无效的子请求不执行
+func (h *BatchHandler) execute(c *gin.Context, item v1.BatchItemRequest) v1.BatchItemResponse {
+	target, err := url.Parse(item.Path)
+	if err != nil || target.Scheme != "" || target.Host != "" {
+		return v1.BatchItemResponse{Status: http.StatusBadRequest, Error: "invalid path: " + item.Path}
+	}
+	if path.Clean(target.Path) == BatchPath {
+		return v1.BatchItemResponse{Status: http.StatusBadRequest, Error: "batch requests cannot be nested"}
+	}
+
+	target.Path = h.basePath + target.Path
+	subReq, err := http.NewRequestWithContext(c.Request.Context(), item.Method, target.String(), bytes.NewReader(item.Body))
+	if err != nil {
+		return v1.BatchItemResponse{Status: http.StatusBadRequest, Error: err.Error()}
+	}
+	for _, name := range batchForwardedHeaders {
+		if values := c.Request.Header.Values(name); len(values) > 0 {
+			subReq.Header[name] = values
+		}
+	}
+	if len(item.Body) > 0 {
+		subReq.Header.Set("Content-Type", "application/json")
+	}
+	subReq.Host = c.Request.Host
+	subReq.RemoteAddr = c.Request.RemoteAddr
+	subReq.TLS = c.Request.TLS
+
+	recorder := &batchRecorder{header: make(http.Header)}
+	h.router.ServeHTTP(recorder, subReq)
+
+	result := v1.BatchItemResponse{Status: recorder.statusCode()}
+	body := recorder.body.Bytes()
+	switch {
+	case len(body) == 0:
+	case json.Valid(body):
+		result.Body = body
+	default:
+		result.Body, _ = json.Marshal(string(body))
+	}
+	return result
+}
+
+// batchRecorder 记录子请求的响应
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header 实现http.ResponseWriter
+func (r *batchRecorder) Header() http.Header {
+	return r.header
+}
+
+// WriteHeader 实现http.ResponseWriter，只记录第一次写出的状态码
+func (r *batchRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
+
+// Write 实现http.ResponseWriter
+func (r *batchRecorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+// Flush 实现http.Flusher，流式响应在子请求结束后一并返回
+func (r *batchRecorder) Flush() {}
+
+// statusCode 返回记录的状态码，处理器没有写出响应时为200
+func (r *batchRecorder) statusCode() int {
+	if r.status == 0 {
+		return http.StatusOK
+	}
+	return r.status
+}
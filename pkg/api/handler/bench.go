@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/bench"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+const (
+	// defaultBenchConcurrency 未指定时的并发数
+	defaultBenchConcurrency = 10
+	// defaultBenchIterations 未指定时的操作次数
+	defaultBenchIterations = 1000
+	// defaultBenchPayload 未指定时JSON文档和缓存值的字节数
+	defaultBenchPayload = 1024
+	// benchCacheTTL 基准测试缓存项的过期时间，测试结束后逐项删除
+	benchCacheTTL = time.Minute
+)
+
+// benchDocument 基准测试的JSON文档
+type benchDocument struct {
+	ID        string            `json:"id"`
+	Name      string            `json:"name"`
+	Tags      map[string]string `json:"tags"`
+	CreatedAt time.Time         `json:"created_at"`
+	Items     []string          `json:"items"`
+}
+
+// BenchHandler 基准测试处理器，在完整的中间件链之后直接调用数据存储、缓存和JSON编解码
+type BenchHandler struct {
+	store     datastore.DatastoreInterface
+	cache     datastore.Cache
+	config    config.BenchConfig
+	assembler *assembler.BenchAssembler
+}
+
+// NewBenchHandler 创建基准测试处理器
+func NewBenchHandler(store datastore.DatastoreInterface, cache datastore.Cache, cfg config.BenchConfig) *BenchHandler {
+	return &BenchHandler{
+		store:     store,
+		cache:     cache,
+		config:    cfg,
+		assembler: assembler.NewBenchAssembler(),
+	}
+}
+
+// BenchDatastore godoc
+// @Summary 数据存储基准测试
+// @Description 每次操作创建、读取并删除一个临时应用，返回延迟直方图，仅用于开发环境
+// @Tags 基准测试
+// @Accept json
+// @Produce json
+// @Param request body v1.BenchRequest false "测试参数"
+// @Success 200 {object} v1.BenchResponseEnvelope "测试完成"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Router /_bench/datastore [post]
+// @Security BearerAuth
+func (h *BenchHandler) BenchDatastore(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+	prefix := "bench-" + uuid.NewString()[:8] + "-"
+
+	h.run(c, "datastore", req, func(ctx context.Context, i int) error {
+		app, err := h.store.CreateApplication(ctx, &model.Application{
+			Name:        prefix + strconv.Itoa(i),
+			Description: "benchmark",
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := h.store.GetApplicationByID(ctx, app.ID); err != nil {
+			return err
+		}
+		return h.store.DeleteApplication(ctx, app.ID)
+	})
+}
+
+// BenchCache godoc
+// @Summary 缓存基准测试
+// @Description 每次操作写入并读取一个缓存项，返回延迟直方图，仅用于开发环境
+// @Tags 基准测试
+// @Accept json
+// @Produce json
+// @Param request body v1.BenchRequest false "测试参数"
+// @Success 200 {object} v1.BenchResponseEnvelope "测试完成"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Router /_bench/cache [post]
+// @Security BearerAuth
+func (h *BenchHandler) BenchCache(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+	prefix := "bench:" + uuid.NewString()[:8] + ":"
+	value := strings.Repeat("x", req.PayloadSize)
+
+	h.run(c, "cache", req, func(ctx context.Context, i int) error {
+		key := prefix + strconv.Itoa(i)
+		if err := h.cache.Set(ctx, key, value, benchCacheTTL); err != nil {
+			return err
+		}
+		if _, err := h.cache.Get(ctx, key); err != nil {
+			return err
+		}
+		return h.cache.Delete(ctx, key)
+	})
+}
+
+// BenchJSON godoc
+// @Summary JSON序列化基准测试
+// @Description 每次操作编码并解码一个指定大小的JSON文档，返回延迟直方图，仅用于开发环境
+// @Tags 基准测试
+// @Accept json
+// @Produce json
+// @Param request body v1.BenchRequest false "测试参数"
+// @Success 200 {object} v1.BenchResponseEnvelope "测试完成"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Router /_bench/json [post]
+// @Security BearerAuth
+func (h *BenchHandler) BenchJSON(c *gin.Context) {
+	req, ok := h.bindRequest(c)
+	if !ok {
+		return
+	}
+	doc := newBenchDocument(req.PayloadSize)
+
+	h.run(c, "json", req, func(ctx context.Context, i int) error {
+		data, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		var decoded benchDocument
+		return json.Unmarshal(data, &decoded)
+	})
+}
+
+// bindRequest 解析测试参数，填充默认值并按配置上限校验
+func (h *BenchHandler) bindRequest(c *gin.Context) (v1.BenchRequest, bool) {
+	var req v1.BenchRequest
+	if c.Request.ContentLength != 0 {
+		if !bindJSON(c, &req) {
+			return req, false
+		}
+	}
+	if req.Concurrency == 0 {
+		req.Concurrency = defaultBenchConcurrency
+	}
+	if req.Iterations == 0 {
+		req.Iterations = defaultBenchIterations
+	}
+	if req.PayloadSize == 0 {
+		req.PayloadSize = defaultBenchPayload
+	}
+
+	var err error
+	switch {
+	case req.Concurrency > h.config.MaxConcurrency:
+		err = fmt.Errorf("concurrency must not exceed %d", h.config.MaxConcurrency)
+	case req.Iterations > h.config.MaxIterations:
+		err = fmt.Errorf("iterations must not exceed %d", h.config.MaxIterations)
+	case int64(req.PayloadSize) > int64(h.config.MaxPayload):
+		err = fmt.Errorf("payload_size must not exceed %s", h.config.MaxPayload)
+	}
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return req, false
+	}
+	return req, true
+}
+
+// run 执行基准测试并返回结果。测试时长取决于参数和基础设施，因此取消响应的写超时，
+// 客户端断开时提前结束
+func (h *BenchHandler) run(c *gin.Context, target string, req v1.BenchRequest, op bench.Op) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{})
+
+	result := bench.Run(c.Request.Context(), bench.Options{
+		Concurrency: req.Concurrency,
+		Iterations:  req.Iterations,
+	}, op)
+	response.Success(c, h.assembler.ToBenchResponse(target, result))
+}
+
+// newBenchDocument 创建编码后约为size字节的JSON文档
+func newBenchDocument(size int) *benchDocument {
+	doc := &benchDocument{
+		ID:        uuid.NewString(),
+		Name:      "benchmark",
+		Tags:      map[string]string{"env": "bench", "team": "core"},
+		CreatedAt: time.Now(),
+	}
+	base, _ := json.Marshal(doc)
+	// 每个元素编码为带引号和逗号的19字节
+	const item = "0123456789abcdef"
+	for remaining := size - len(base); remaining > 0; remaining -= len(item) + 3 {
+		doc.Items = append(doc.Items, item)
+	}
+	return doc
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/api/schema"
+	"github.com/make-bin/server-tpl/pkg/api/validation"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// bindJSON 绑定JSON请求体，失败时写入校验错误响应；当前路由拒绝未知字段时（见 middleware.RejectsUnknownFields），
+// 请求体中DTO没有的字段逐一作为校验错误返回，如拼错的 descripton
+func bindJSON(c *gin.Context, req interface{}) bool {
+	var err error
+	if middleware.RejectsUnknownFields(c) {
+		var body []byte
+		if body, err = c.GetRawData(); err == nil {
+			if unknown, _ := schema.UnknownFields(req, body); len(unknown) > 0 {
+				details := make([]response.ErrorDetail, len(unknown))
+				for i, field := range unknown {
+					details[i] = response.ErrorDetail{Field: field, Reason: "未知字段"}
+				}
+				response.ValidationError(c, details)
+				return false
+			}
+			err = binding.JSON.BindBody(body, req)
+		}
+	} else {
+		err = c.ShouldBindJSON(req)
+	}
+
+	return bindingResult(c, err)
+}
+
+// bindQuery 绑定查询参数，失败时写入校验错误响应；实现 encoding.TextUnmarshaler 的字段（如 v1.TimeRange、v1.SortExpression）
+// 由其 UnmarshalText 解析，重复的参数按逗号连接，解析失败时以参数名作为校验错误的字段
+func bindQuery(c *gin.Context, req interface{}) bool {
+	query := c.Request.URL.Query()
+	var details []response.ErrorDetail
+	textFields(reflect.ValueOf(req).Elem(), func(name string, field encoding.TextUnmarshaler) {
+		values, ok := query[name]
+		if !ok {
+			return
+		}
+		delete(query, name)
+		text := strings.Join(values, ",")
+		if text == "" {
+			return
+		}
+		if err := field.UnmarshalText([]byte(text)); err != nil {
+			details = append(details, response.ErrorDetail{Field: name, Reason: err.Error()})
+		}
+	})
+	if len(details) > 0 {
+		response.ValidationError(c, details)
+		return false
+	}
+
+	err := binding.MapFormWithTag(req, query, "form")
+	if err == nil {
+		err = binding.Validator.ValidateStruct(req)
+	}
+	return bindingResult(c, err)
+}
+
+// textFields 对结构体中实现 encoding.TextUnmarshaler 的字段调用 fn，name 为其form标签，展开匿名嵌入的结构体
+func textFields(v reflect.Value, fn func(name string, field encoding.TextUnmarshaler)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, _, _ := strings.Cut(sf.Tag.Get("form"), ",")
+		if name == "-" || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		field := v.Field(i)
+		if u, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			if name == "" {
+				name = sf.Name
+			}
+			fn(name, u)
+			continue
+		}
+		if sf.Anonymous && field.Kind() == reflect.Struct {
+			textFields(field, fn)
+		}
+	}
+}
+
+// bindingResult 绑定出错时写入校验错误响应并返回false
+func bindingResult(c *gin.Context, err error) bool {
+	if err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return false
+	}
+	return true
+}
+
+// checkLookups 执行请求DTO的 lookup 标签声明的数据存储校验（见 validation.Lookups），失败时写入校验错误响应，
+// 查询失败时返回500
+func checkLookups(c *gin.Context, lookups *validation.Lookups, req interface{}) bool {
+	err := lookups.Validate(c.Request.Context(), req)
+	if err == nil {
+		return true
+	}
+	if validationErrors, ok := err.(validator.ValidationErrors); ok {
+		response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+	} else {
+		logger.Error("Failed to check request lookups: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+	return false
+}
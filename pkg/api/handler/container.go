@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/container"
+)
+
+// ContainerHandler 依赖注入容器诊断处理器
+type ContainerHandler struct {
+	inspector container.Inspector
+	assembler *assembler.ContainerAssembler
+}
+
+// NewContainerHandler 创建容器诊断处理器
+func NewContainerHandler(inspector container.Inspector) *ContainerHandler {
+	return &ContainerHandler{
+		inspector: inspector,
+		assembler: assembler.NewContainerAssembler(),
+	}
+}
+
+// DescribeContainer godoc
+// @Summary 获取容器诊断信息
+// @Description 列出已注册的bean、类型、注入关系、依赖是否满足以及健康检查结果
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.ContainerResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/container [get]
+// @Security BearerAuth
+func (h *ContainerHandler) DescribeContainer(c *gin.Context) {
+	response.Success(c, h.assembler.ToResponse(h.inspector.Describe()))
+}
@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/api/sse"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// eventWriteGrace 长轮询等待结束后写出响应的时间
+const eventWriteGrace = 10 * time.Second
+
+// EventHandler 领域事件处理器
+type EventHandler struct {
+	eventLogService service.EventLogServiceInterface
+	config          *config.EventLogConfig
+	assembler       *assembler.EventAssembler
+	heartbeat       time.Duration
+}
+
+// NewEventHandler 创建领域事件处理器
+func NewEventHandler(eventLogService service.EventLogServiceInterface, cfg *config.EventLogConfig) *EventHandler {
+	return &EventHandler{
+		eventLogService: eventLogService,
+		config:          cfg,
+		assembler:       assembler.NewEventAssembler(),
+		heartbeat:       sse.DefaultHeartbeat,
+	}
+}
+
+// ListEvents godoc
+// @Summary 读取领域事件
+// @Description 从游标之后读取事件日志中的领域事件，按事件ID升序；指定wait时没有新事件则长轮询等待，Accept为text/event-stream时以Server-Sent Events持续推送（事件类型为领域事件类型，事件ID为游标，重连时通过Last-Event-ID请求头继续）。当前组织下只返回该组织的事件，未指定组织时需要管理员角色并返回全部事件。
+// @Tags 领域事件
+// @Accept json
+// @Produce json,text/event-stream
+// @Param cursor query int false "最后收到的事件ID，为空时从保留的最早事件开始"
+// @Param resource_type query string false "资源类型，逗号分隔，如 application,organization"
+// @Param wait query int false "没有新事件时等待的秒数" minimum(0) maximum(3600)
+// @Param limit query int false "返回的最大事件数" minimum(1) maximum(1000)
+// @Param Last-Event-ID header string false "SSE重连时最后收到的事件ID，优先于cursor"
+// @Success 200 {object} v1.EventListResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "未指定组织且不是管理员"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /events [get]
+// @Security BearerAuth
+func (h *EventHandler) ListEvents(c *gin.Context) {
+	var req v1.ListEventsRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	if _, ok := model.OrganizationFromContext(c.Request.Context()); !ok {
+		if user, _ := principal.CurrentUser(c); !user.HasRole("admin") {
+			response.Forbidden(c, "permission_denied", fmt.Errorf("the events of every organization require the admin role, select an organization"))
+			return
+		}
+	}
+
+	query := service.EventQuery{After: req.Cursor, ResourceTypes: req.ResourceType, Limit: req.Limit}
+	if strings.Contains(c.GetHeader("Accept"), sse.ContentType) {
+		h.streamEvents(c, query)
+		return
+	}
+
+	wait := time.Duration(req.Wait) * time.Second
+	if wait > h.config.MaxWait {
+		wait = h.config.MaxWait
+	}
+	if wait > 0 {
+		_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(wait + eventWriteGrace))
+	}
+
+	events, err := h.eventLogService.WaitEvents(c.Request.Context(), query, wait)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			// 客户端已断开
+			return
+		}
+		logger.Error("Failed to list events: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToListResponse(events, req.Cursor))
+}
+
+// streamEvents 以SSE推送游标之后的事件，Last-Event-ID优先于cursor参数
+func (h *EventHandler) streamEvents(c *gin.Context, query service.EventQuery) {
+	if lastEventID := sse.LastEventID(c); lastEventID != "" {
+		after, err := strconv.ParseUint(lastEventID, 10, 0)
+		if err != nil {
+			response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("invalid last event id %q", lastEventID))
+			return
+		}
+		query.After = uint(after)
+	}
+
+	stream, err := sse.NewStream(c, sse.DefaultRetry)
+	if err != nil {
+		// 客户端已断开
+		return
+	}
+
+	ctx := c.Request.Context()
+	for {
+		// 等待时长即心跳间隔，没有事件时发送心跳
+		events, err := h.eventLogService.WaitEvents(ctx, query, h.heartbeat)
+		if err != nil {
+			if ctx.Err() == nil {
+				logger.Error("Event stream failed: %v", err)
+			}
+			return
+		}
+		if len(events) == 0 {
+			err = stream.Heartbeat()
+		}
+		for _, e := range events {
+			if err = stream.Send(sse.Event{
+				ID:    strconv.FormatUint(uint64(e.ID), 10),
+				Event: e.EventType,
+				Data:  h.assembler.ToResponse(e),
+			}); err != nil {
+				break
+			}
+			query.After = e.ID
+		}
+		if err != nil {
+			logger.Debug("Event stream closed: %v", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+)
+
+// ExperimentHandler 实验处理器
+type ExperimentHandler struct {
+	experimentService service.ExperimentServiceInterface
+}
+
+// NewExperimentHandler 创建实验处理器
+func NewExperimentHandler(experimentService service.ExperimentServiceInterface) *ExperimentHandler {
+	return &ExperimentHandler{
+		experimentService: experimentService,
+	}
+}
+
+// GetAssignments godoc
+// @Summary 获取当前用户的实验分组
+// @Description 对当前用户参与的全部实验进行分组，并记录曝光事件
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.ExperimentAssignmentsResponseEnvelope "获取成功"
+// @Router /experiments [get]
+// @Security BearerAuth
+func (h *ExperimentHandler) GetAssignments(c *gin.Context) {
+	evalCtx, ok := featureflags.FromContext(c.Request.Context())
+	if !ok {
+		evalCtx = middleware.EvaluationContext(c)
+	}
+
+	variants := h.experimentService.AssignAll(c.Request.Context(), evalCtx)
+	response.Success(c, v1.ExperimentAssignmentsResponse{Variants: variants})
+}
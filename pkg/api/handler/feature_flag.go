@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// FeatureFlagHandler 特性开关处理器
+type FeatureFlagHandler struct {
+	featureFlagService service.FeatureFlagServiceInterface
+	assembler          *assembler.FeatureFlagAssembler
+}
+
+// NewFeatureFlagHandler 创建特性开关处理器
+func NewFeatureFlagHandler(featureFlagService service.FeatureFlagServiceInterface) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+		assembler:          assembler.NewFeatureFlagAssembler(),
+	}
+}
+
+// EvaluateFeatureFlags godoc
+// @Summary 获取当前用户的特性开关
+// @Description 按当前用户和租户对所有特性开关求值
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.EvaluatedFeatureFlagsResponseEnvelope "获取成功"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags [get]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) EvaluateFeatureFlags(c *gin.Context) {
+	evalCtx, ok := featureflags.FromContext(c.Request.Context())
+	if !ok {
+		evalCtx = middleware.EvaluationContext(c)
+	}
+
+	flags, err := h.featureFlagService.EvaluateAll(c.Request.Context(), evalCtx)
+	if err != nil {
+		logger.Error("Failed to evaluate feature flags: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, v1.EvaluatedFeatureFlagsResponse{Flags: flags})
+}
+
+// ListFeatureFlags godoc
+// @Summary 获取特性开关定义列表
+// @Description 获取配置文件和数据存储中的全部特性开关定义
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.FeatureFlagResponseListEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags/definitions [get]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) ListFeatureFlags(c *gin.Context) {
+	flags, err := h.featureFlagService.ListFeatureFlags(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to list feature flags: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(flags))
+}
+
+// GetFeatureFlag godoc
+// @Summary 获取特性开关定义
+// @Description 根据标识获取特性开关定义
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Param key path string true "开关标识"
+// @Success 200 {object} v1.FeatureFlagResponseEnvelope "获取成功"
+// @Failure 404 {object} v1.ErrorEnvelope "开关不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags/definitions/{key} [get]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) GetFeatureFlag(c *gin.Context) {
+	flag, err := h.featureFlagService.GetFeatureFlag(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(flag))
+}
+
+// CreateFeatureFlag godoc
+// @Summary 创建特性开关
+// @Description 创建特性开关，与配置文件中同名的开关将覆盖配置
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Param request body v1.CreateFeatureFlagRequest true "特性开关创建请求"
+// @Success 201 {object} v1.FeatureFlagResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 409 {object} v1.ErrorEnvelope "开关已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags/definitions [post]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) CreateFeatureFlag(c *gin.Context) {
+	var req v1.CreateFeatureFlagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	flag, err := h.featureFlagService.CreateFeatureFlag(c.Request.Context(), h.assembler.ToModel(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToResponse(flag), "feature_flag_created")
+}
+
+// UpdateFeatureFlag godoc
+// @Summary 更新特性开关
+// @Description 更新数据存储中的特性开关，配置文件中的开关为只读
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Param key path string true "开关标识"
+// @Param request body v1.UpdateFeatureFlagRequest true "特性开关更新请求"
+// @Success 200 {object} v1.FeatureFlagResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 404 {object} v1.ErrorEnvelope "开关不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "开关只读"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags/definitions/{key} [put]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) UpdateFeatureFlag(c *gin.Context) {
+	var req v1.UpdateFeatureFlagRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	flag, err := h.featureFlagService.GetFeatureFlag(c.Request.Context(), c.Param("key"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	// 复制一份，避免修改配置中的定义
+	updated := *flag
+	h.assembler.ApplyUpdate(&updated, &req)
+
+	result, err := h.featureFlagService.UpdateFeatureFlag(c.Request.Context(), &updated)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToResponse(result), "feature_flag_updated")
+}
+
+// DeleteFeatureFlag godoc
+// @Summary 删除特性开关
+// @Description 删除数据存储中的特性开关，同名配置开关将重新生效
+// @Tags 特性开关
+// @Accept json
+// @Produce json
+// @Param key path string true "开关标识"
+// @Success 204 "删除成功"
+// @Failure 404 {object} v1.ErrorEnvelope "开关不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "开关只读"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /feature-flags/definitions/{key} [delete]
+// @Security BearerAuth
+func (h *FeatureFlagHandler) DeleteFeatureFlag(c *gin.Context) {
+	if err := h.featureFlagService.DeleteFeatureFlag(c.Request.Context(), c.Param("key")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *FeatureFlagHandler) handleError(c *gin.Context, err error) {
+	var domainErr *model.DomainError
+	switch {
+	case errors.Is(err, model.ErrFeatureFlagNotFound):
+		response.NotFound(c, "not_found", err)
+	case errors.Is(err, model.ErrFeatureFlagExists), errors.Is(err, model.ErrFeatureFlagReadOnly):
+		response.Conflict(c, "conflict", err)
+	case errors.As(err, &domainErr):
+		response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+	default:
+		logger.Error("Feature flag operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// FileHandler 文件处理器，文件内容经预签名地址在客户端和对象存储之间直接传输，只有校验后的下载经过API服务
+type FileHandler struct {
+	fileService  service.FileServiceInterface
+	assembler    *assembler.FileAssembler
+	maxChunkSize int64
+}
+
+// NewFileHandler 创建文件处理器，maxChunkSize为断点续传的分块大小上限
+func NewFileHandler(fileService service.FileServiceInterface, maxChunkSize int64) *FileHandler {
+	return &FileHandler{
+		fileService:  fileService,
+		assembler:    assembler.NewFileAssembler(),
+		maxChunkSize: maxChunkSize,
+	}
+}
+
+// CreateUpload godoc
+// @Summary 创建文件上传
+// @Description 记录等待上传的文件并返回预签名上传地址。客户端以 PUT 请求将内容上传到该地址，须携带返回的请求头，Content-Length 须与声明的大小一致；上传后调用完成上传接口。文件属于当前组织，未指定组织时属于当前用户
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param request body v1.FileUploadRequest true "文件信息"
+// @Success 201 {object} v1.FileUploadResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或文件类型不允许"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权上传"
+// @Failure 413 {object} v1.ErrorEnvelope "文件过大"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files [post]
+// @Security BearerAuth
+func (h *FileHandler) CreateUpload(c *gin.Context) {
+	var req v1.FileUploadRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	file, upload, err := h.fileService.CreateUpload(c.Request.Context(), h.assembler.ToModel(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToUploadResponse(file, upload, nil), "file_upload_created")
+}
+
+// ListFiles godoc
+// @Summary 获取文件列表
+// @Description 分页获取当前组织的文件，未指定组织时获取当前用户的文件，最新的在前
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1) minimum(1)
+// @Param size query int false "每页数量" default(10) minimum(1) maximum(100)
+// @Success 200 {object} v1.FileResponsePageEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files [get]
+// @Security BearerAuth
+func (h *FileHandler) ListFiles(c *gin.Context) {
+	var req v1.ListFilesRequest
+	if !bindQuery(c, &req) {
+		return
+	}
+	req.Validate()
+
+	files, total, err := h.fileService.ListFiles(c.Request.Context(), req.Page, req.Size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Page(c, h.assembler.ToResponseList(files), req.Page, req.Size, int(total))
+}
+
+// GetFile godoc
+// @Summary 获取文件
+// @Description 获取文件信息
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "文件ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.FileResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问"
+// @Failure 404 {object} v1.ErrorEnvelope "文件不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files/{id} [get]
+// @Security BearerAuth
+func (h *FileHandler) GetFile(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.fileService.GetFile(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(file))
+}
+
+// CompleteUpload godoc
+// @Summary 完成文件上传
+// @Description 确认文件内容已按声明的大小上传到对象存储，将文件标记为已上传；对已上传的文件重复调用返回该文件。文件在后台任务中计算SHA-256校验和，启用扫描时进行恶意软件扫描，启用图片处理时去除图片元数据并生成缩略图；响应的 operation_id 为该任务，处理完成后再次调用返回校验和、扫描结果和缩略图的下载地址
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "文件ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.FileUploadResponseEnvelope "上传完成"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问"
+// @Failure 404 {object} v1.ErrorEnvelope "文件不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "文件内容未上传或大小不一致"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files/{id}/complete [post]
+// @Security BearerAuth
+func (h *FileHandler) CompleteUpload(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	file, err := h.fileService.CompleteUpload(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	variants, err := h.fileService.VariantURLs(c.Request.Context(), file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToUploadResponse(file, nil, variants), "file_uploaded")
+}
+
+// DownloadFile godoc
+// @Summary 获取文件下载地址
+// @Description 返回已上传文件的预签名下载地址，客户端以 GET 请求从该地址下载内容，可与 checksum_sha256 比对校验；图片同时返回缩略图的下载地址。等待扫描和已隔离的文件不可下载
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "文件ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.FileDownloadResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问或文件已隔离"
+// @Failure 404 {object} v1.ErrorEnvelope "文件不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "文件尚未上传完成或等待扫描"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files/{id}/download [get]
+// @Security BearerAuth
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	file, download, err := h.fileService.DownloadURL(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	variants, err := h.fileService.VariantURLs(c.Request.Context(), file)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToDownloadResponse(file, download, variants))
+}
+
+// DownloadContent godoc
+// @Summary 下载文件内容
+// @Description 经API服务下载文件内容，内容与 SHA-256 校验和一致时才返回。ETag 为校验和，支持 If-None-Match 和 Range 请求。等待扫描、已隔离或尚未计算校验和的文件不可下载
+// @Tags 文件
+// @Produce octet-stream
+// @Param id path string true "文件ID或公开ID（UUID）" example(1)
+// @Param If-None-Match header string false "此前返回的ETag，内容未变化时返回304"
+// @Param Range header string false "下载的字节范围，如 bytes=0-1023"
+// @Success 200 {file} file "文件内容"
+// @Success 206 {file} file "部分内容"
+// @Success 304 "内容未变化"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问或文件已隔离"
+// @Failure 404 {object} v1.ErrorEnvelope "文件不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "文件尚未上传完成、等待扫描或仍在处理"
+// @Failure 500 {object} v1.ErrorEnvelope "文件内容与校验和不一致或服务器内部错误"
+// @Router /files/{id}/content [get]
+// @Security BearerAuth
+func (h *FileHandler) DownloadContent(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	file, content, err := h.fileService.OpenContent(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	defer content.Close()
+
+	var modified time.Time
+	if file.UploadedAt != nil {
+		modified = *file.UploadedAt
+	}
+	c.Header("ETag", fmt.Sprintf("%q", file.ChecksumSHA256))
+	c.Header("Content-Type", file.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", file.Name))
+	http.ServeContent(c.Writer, c.Request, file.Name, modified, content)
+}
+
+// DeleteFile godoc
+// @Summary 删除文件
+// @Description 删除文件及其在对象存储中的内容、变体和断点续传会话
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "文件ID或公开ID（UUID）" example(1)
+// @Success 204 "删除成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权删除"
+// @Failure 404 {object} v1.ErrorEnvelope "文件不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /files/{id} [delete]
+// @Security BearerAuth
+func (h *FileHandler) DeleteFile(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.fileService.DeleteFile(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// fileID 解析路径中的文件或上传会话ID，失败时写入参数错误响应
+func fileID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// handleError 将文件服务的错误映射为响应
+func (h *FileHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrFileNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeFileNotFound, "file_not_found", err)
+	case errors.Is(err, model.ErrFileNameInvalid), errors.Is(err, model.ErrFileContentTypeInvalid), errors.Is(err, model.ErrFileSizeInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeFileInvalid, "file_invalid", err)
+	case errors.Is(err, model.ErrFileContentTypeNotAllowed):
+		response.Error(c, http.StatusBadRequest, response.CodeFileTypeNotSupported, "file_content_type_not_allowed", err)
+	case errors.Is(err, model.ErrFileTooLarge):
+		response.Error(c, http.StatusRequestEntityTooLarge, response.CodeFileTooBig, "file_too_large", err)
+	case errors.Is(err, model.ErrFileNotUploaded):
+		response.Error(c, http.StatusConflict, response.CodeFileNotUploaded, "file_not_uploaded", err)
+	case errors.Is(err, model.ErrFileUploadMissing):
+		response.Error(c, http.StatusConflict, response.CodeFileUploadMissing, "file_upload_missing", err)
+	case errors.Is(err, model.ErrFileScanPending):
+		response.Error(c, http.StatusConflict, response.CodeFileScanPending, "file_scan_pending", err)
+	case errors.Is(err, model.ErrFileQuarantined):
+		response.Error(c, http.StatusForbidden, response.CodeFileVirusDetected, "file_quarantined", err)
+	case errors.Is(err, model.ErrFileNotVerified):
+		response.Error(c, http.StatusConflict, response.CodeFileNotVerified, "file_not_verified", err)
+	case errors.Is(err, model.ErrFileChecksumMismatch):
+		response.Error(c, http.StatusInternalServerError, response.CodeFileCorrupted, "file_corrupted", err)
+	case errors.Is(err, model.ErrUploadSessionNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeUploadSessionNotFound, "upload_session_not_found", err)
+	case errors.Is(err, model.ErrUploadSessionExpired):
+		response.Error(c, http.StatusGone, response.CodeUploadSessionExpired, "upload_session_expired", err)
+	case errors.Is(err, model.ErrUploadOffsetMismatch):
+		response.Error(c, http.StatusConflict, response.CodeUploadOffsetMismatch, "upload_offset_mismatch", err)
+	case errors.Is(err, model.ErrUploadChunkTooLarge):
+		response.Error(c, http.StatusRequestEntityTooLarge, response.CodeFileTooBig, "upload_chunk_too_large", err)
+	case errors.Is(err, model.ErrUploadChunkOverflow):
+		response.Error(c, http.StatusRequestEntityTooLarge, response.CodeFileTooBig, "upload_chunk_overflow", err)
+	case errors.Is(err, model.ErrUploadIncomplete):
+		response.Error(c, http.StatusConflict, response.CodeUploadIncomplete, "upload_incomplete", err)
+	case errors.Is(err, model.ErrUploadChecksumInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+	case errors.Is(err, model.ErrUploadChecksumMismatch):
+		response.Error(c, http.StatusUnprocessableEntity, response.CodeUploadChecksumMismatch, "upload_checksum_mismatch", err)
+	case errors.Is(err, model.ErrAccessDenied):
+		response.Error(c, http.StatusForbidden, response.CodeFilePermissionDenied, "file_permission_denied", err)
+	default:
+		logger.Error("File operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
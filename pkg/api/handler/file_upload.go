@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// 断点续传的请求头，参照 tus 协议
+const (
+	headerUploadOffset  = "Upload-Offset"
+	headerUploadLength  = "Upload-Length"
+	headerUploadExpires = "Upload-Expires"
+)
+
+// CreateUploadSession godoc
+// @Summary 创建断点续传会话
+// @Description 记录等待上传的文件并创建断点续传会话，文件内容经API服务分块上传，适用于网络不稳定时的大文件。文件的校验规则与创建文件上传相同
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param request body v1.FileUploadRequest true "文件信息"
+// @Success 201 {object} v1.UploadSessionResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或文件类型不允许"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权上传"
+// @Failure 413 {object} v1.ErrorEnvelope "文件过大"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /uploads [post]
+// @Security BearerAuth
+func (h *FileHandler) CreateUploadSession(c *gin.Context) {
+	var req v1.FileUploadRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	file, session, err := h.fileService.CreateUploadSession(c.Request.Context(), h.assembler.ToModel(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.setUploadHeaders(c, file, session)
+	response.Created(c, h.assembler.ToUploadSessionResponse(file, session, h.maxChunkSize), "upload_session_created")
+}
+
+// GetUploadSession godoc
+// @Summary 获取断点续传会话
+// @Description 获取会话及已接收的字节数，中断后从 offset 续传。HEAD 请求只返回 Upload-Offset、Upload-Length 和 Upload-Expires 响应头
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "会话ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.UploadSessionResponseEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问"
+// @Failure 404 {object} v1.ErrorEnvelope "会话不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /uploads/{id} [get]
+// @Router /uploads/{id} [head]
+// @Security BearerAuth
+func (h *FileHandler) GetUploadSession(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	file, session, err := h.fileService.GetUploadSession(c.Request.Context(), id)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.setUploadHeaders(c, file, session)
+	c.Header("Cache-Control", "no-store")
+	if c.Request.Method == http.MethodHead {
+		c.Status(http.StatusOK)
+		return
+	}
+	response.Success(c, h.assembler.ToUploadSessionResponse(file, session, h.maxChunkSize))
+}
+
+// UploadChunk godoc
+// @Summary 上传分块
+// @Description 上传从 Upload-Offset 起的一个分块，偏移量须与已接收的字节数一致，分块不超过 max_chunk_size。分块完整接收后才会保存，中断的分块须从原偏移量重新上传。成功时 Upload-Offset 响应头为新的偏移量
+// @Tags 文件
+// @Accept application/offset+octet-stream
+// @Produce json
+// @Param id path string true "会话ID或公开ID（UUID）" example(1)
+// @Param Upload-Offset header int true "分块的偏移量"
+// @Param chunk body string true "分块内容"
+// @Success 204 "上传成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问"
+// @Failure 404 {object} v1.ErrorEnvelope "会话不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "偏移量不一致"
+// @Failure 410 {object} v1.ErrorEnvelope "会话已过期"
+// @Failure 413 {object} v1.ErrorEnvelope "分块过大或超出文件大小"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /uploads/{id} [patch]
+// @Security BearerAuth
+func (h *FileHandler) UploadChunk(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+	offset, err := strconv.ParseInt(c.GetHeader(headerUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	file, session, err := h.fileService.AppendUploadChunk(c.Request.Context(), id, offset, c.Request.Body)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.setUploadHeaders(c, file, session)
+	response.NoContent(c)
+}
+
+// FinalizeUpload godoc
+// @Summary 完成断点续传
+// @Description 按顺序组装已上传的分块并计算 SHA-256，与提交的校验和一致时删除会话并完成文件上传，之后与完成文件上传相同
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "会话ID或公开ID（UUID）" example(1)
+// @Param request body v1.FinalizeUploadRequest true "文件内容的校验和"
+// @Success 200 {object} v1.FileUploadResponseEnvelope "上传完成"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权访问"
+// @Failure 404 {object} v1.ErrorEnvelope "会话不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "文件内容尚未上传完整"
+// @Failure 410 {object} v1.ErrorEnvelope "会话已过期"
+// @Failure 422 {object} v1.ErrorEnvelope "校验和不一致"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /uploads/{id}/complete [post]
+// @Security BearerAuth
+func (h *FileHandler) FinalizeUpload(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+	var req v1.FinalizeUploadRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	file, err := h.fileService.FinalizeUploadSession(c.Request.Context(), id, req.ChecksumSHA256)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToUploadResponse(file, nil, nil), "file_uploaded")
+}
+
+// AbortUpload godoc
+// @Summary 取消断点续传
+// @Description 删除会话、已上传的分块和等待上传的文件
+// @Tags 文件
+// @Accept json
+// @Produce json
+// @Param id path string true "会话ID或公开ID（UUID）" example(1)
+// @Success 204 "删除成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权删除"
+// @Failure 404 {object} v1.ErrorEnvelope "会话不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /uploads/{id} [delete]
+// @Security BearerAuth
+func (h *FileHandler) AbortUpload(c *gin.Context) {
+	id, ok := fileID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.fileService.AbortUploadSession(c.Request.Context(), id); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// setUploadHeaders 设置断点续传会话的偏移量、文件大小和过期时间响应头
+func (h *FileHandler) setUploadHeaders(c *gin.Context, file *model.File, session *model.UploadSession) {
+	c.Header(headerUploadOffset, strconv.FormatInt(session.Offset, 10))
+	c.Header(headerUploadLength, strconv.FormatInt(file.Size, 10))
+	c.Header(headerUploadExpires, session.ExpiresAt.UTC().Format(http.TimeFormat))
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// ImpersonationHandler 模拟登录处理器，供客服人员以用户身份排查问题
+type ImpersonationHandler struct {
+	cfg         *config.SecurityConfig
+	userService service.UserServiceInterface
+	audit       analytics.Sink
+	clock       clock.Clock
+}
+
+// NewImpersonationHandler 创建模拟登录处理器，被模拟用户的身份从userService读取，签发的令牌记录在audit中
+func NewImpersonationHandler(cfg *config.SecurityConfig, userService service.UserServiceInterface, audit analytics.Sink, clk clock.Clock) *ImpersonationHandler {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &ImpersonationHandler{
+		cfg:         cfg,
+		userService: userService,
+		audit:       audit,
+		clock:       clk,
+	}
+}
+
+// Impersonate godoc
+// @Summary 模拟登录
+// @Description 签发以目标用户身份访问的短期令牌，令牌的角色和权限取自用户存储中的该用户，同时记录代为操作的当前用户；签发和此后每个使用该令牌的请求都写入审计日志。
+// @Description 模拟令牌不能再次模拟，也不能模拟受保护角色（security.impersonation.protected_roles）的用户
+// @Tags 模拟登录
+// @Accept json
+// @Produce json
+// @Param request body v1.ImpersonateRequest true "模拟登录请求"
+// @Success 201 {object} v1.ImpersonationResponseEnvelope "签发成功"
+// @Failure 400 {object} v1.ErrorEnvelope "请求参数错误或模拟自己"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足或不允许模拟该用户"
+// @Failure 404 {object} v1.ErrorEnvelope "被模拟的用户不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /impersonations [post]
+// @Security BearerAuth
+func (h *ImpersonationHandler) Impersonate(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if middleware.CurrentImpersonator(c) != nil {
+		response.Error(c, http.StatusForbidden, response.CodeImpersonationActionForbidden, "impersonation_action_forbidden",
+			fmt.Errorf("an impersonation token cannot start another impersonation"))
+		return
+	}
+
+	var req v1.ImpersonateRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	// 令牌的身份取自用户存储，不信任请求中的角色和权限
+	impersonated, err := h.userService.GetUser(c.Request.Context(), req.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrUserNotFound) {
+			response.Error(c, http.StatusNotFound, response.CodeImpersonationUserNotFound, "impersonation_user_not_found", err)
+			return
+		}
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+	if impersonated.UserID() == user.UserID {
+		response.Error(c, http.StatusBadRequest, response.CodeImpersonationInvalid, "impersonation_invalid",
+			fmt.Errorf("users cannot impersonate themselves"))
+		return
+	}
+	if slices.Contains(h.cfg.Impersonation.ProtectedRoles, impersonated.Role) {
+		response.Error(c, http.StatusForbidden, response.CodeImpersonationNotAllowed, "impersonation_not_allowed",
+			fmt.Errorf("users with the %s role cannot be impersonated", impersonated.Role))
+		return
+	}
+
+	impersonator := middleware.Impersonator{
+		UserID:   user.UserID,
+		Username: user.Username,
+		Role:     user.Role,
+		Reason:   req.Reason,
+	}
+	target := middleware.JWTClaims{
+		UserID:      impersonated.UserID(),
+		Username:    impersonated.Username,
+		Role:        impersonated.Role,
+		Permissions: impersonated.Permissions,
+	}
+	token, expiresAt, err := middleware.IssueImpersonationToken(h.cfg, h.clock, target, impersonator)
+	if err != nil {
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	h.audit.Record(c.Request.Context(), middleware.NewImpersonationAuditEntry(c, middleware.AuditActionImpersonationStarted, h.clock.Now(), target.UserID, &impersonator))
+	logger.Info("User %s impersonating user %s until %s: %s", user.UserID, target.UserID, expiresAt.Format(time.RFC3339), req.Reason)
+
+	response.Created(c, v1.ImpersonationResponse{
+		Token:          token,
+		ExpiresAt:      timestamp.New(expiresAt),
+		UserID:         target.UserID,
+		ImpersonatorID: user.UserID,
+	}, "impersonation_started")
+}
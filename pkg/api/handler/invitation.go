@@ -0,0 +1,226 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// InvitationHandler 邀请处理器，管理员和组织管理员通过邮件邀请用户，被邀请用户以邀请令牌接受邀请
+type InvitationHandler struct {
+	invitationService service.InvitationServiceInterface
+	// userService 创建或认证接受邀请的用户
+	userService service.UserServiceInterface
+	// sessions 为接受邀请的用户创建会话，未启用会话时为nil
+	sessions      *SessionHandler
+	clock         clock.Clock
+	assembler     *assembler.InvitationAssembler
+	userAssembler *assembler.UserAssembler
+}
+
+// NewInvitationHandler 创建邀请处理器，sessions 为nil时接受邀请不签发会话令牌
+func NewInvitationHandler(invitationService service.InvitationServiceInterface, userService service.UserServiceInterface, sessions *SessionHandler, clk clock.Clock) *InvitationHandler {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &InvitationHandler{
+		invitationService: invitationService,
+		userService:       userService,
+		sessions:          sessions,
+		clock:             clk,
+		assembler:         assembler.NewInvitationAssembler(),
+		userAssembler:     assembler.NewUserAssembler(),
+	}
+}
+
+// CreateInvitation godoc
+// @Summary 创建邀请
+// @Description 通过邮件邀请用户，邀请令牌在 invitations.ttl 后过期。管理员可邀请任何用户并预设角色和权限；组织管理员只能以默认角色邀请用户加入其管理的组织，只有所有者可以邀请所有者。未启用邮件时响应返回邀请令牌
+// @Tags 邀请
+// @Accept json
+// @Produce json
+// @Param request body v1.CreateInvitationRequest true "邀请信息"
+// @Success 201 {object} v1.CreateInvitationResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权邀请"
+// @Failure 404 {object} v1.ErrorEnvelope "组织不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /invitations [post]
+// @Security BearerAuth
+func (h *InvitationHandler) CreateInvitation(c *gin.Context) {
+	var req v1.CreateInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	invitation, token, err := h.invitationService.CreateInvitation(c.Request.Context(), h.assembler.ToModel(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, v1.CreateInvitationResponse{
+		InvitationResponse: *h.assembler.ToResponse(invitation, h.clock.Now()),
+		Token:              token,
+	}, "invitation_created")
+}
+
+// ListInvitations godoc
+// @Summary 获取邀请列表
+// @Description 获取组织的邀请，按创建时间倒序；不指定组织时获取全部邀请，需要管理员角色。组织管理员可查看其管理的组织的邀请
+// @Tags 邀请
+// @Accept json
+// @Produce json
+// @Param org_id query int false "组织ID"
+// @Param status query string false "状态" Enums(pending, accepted, revoked, expired)
+// @Success 200 {object} v1.InvitationResponseListEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权查看"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /invitations [get]
+// @Security BearerAuth
+func (h *InvitationHandler) ListInvitations(c *gin.Context) {
+	var req v1.ListInvitationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+
+	invitations, err := h.invitationService.ListInvitations(c.Request.Context(), uint(req.OrgID), req.Status)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(invitations, h.clock.Now()))
+}
+
+// RevokeInvitation godoc
+// @Summary 撤销邀请
+// @Description 撤销尚未接受的邀请，其令牌随即失效
+// @Tags 邀请
+// @Accept json
+// @Produce json
+// @Param id path string true "邀请ID或公开ID（UUID）" example(7)
+// @Success 204 "撤销成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "无权撤销"
+// @Failure 404 {object} v1.ErrorEnvelope "邀请不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "邀请已被接受或撤销"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /invitations/{id} [delete]
+// @Security BearerAuth
+func (h *InvitationHandler) RevokeInvitation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	if err := h.invitationService.RevokeInvitation(c.Request.Context(), uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// AcceptInvitation godoc
+// @Summary 接受邀请
+// @Description 以邀请令牌接受邀请，无需认证。被邀请用户以邮箱为用户ID，加入邀请的组织。邮箱已注册时须提供该用户的密码，用户保留其角色；否则以密码和邀请预设的角色和权限创建用户。启用会话时为该用户创建会话并返回其令牌
+// @Tags 邀请
+// @Accept json
+// @Produce json
+// @Param request body v1.AcceptInvitationRequest true "邀请令牌和密码"
+// @Success 200 {object} v1.AcceptInvitationResponseEnvelope "接受成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误、密码太短或邀请令牌无效"
+// @Failure 401 {object} v1.ErrorEnvelope "已注册用户的密码错误"
+// @Failure 409 {object} v1.ErrorEnvelope "邀请已被接受或撤销"
+// @Failure 410 {object} v1.ErrorEnvelope "邀请已过期"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /invitations/accept [post]
+func (h *InvitationHandler) AcceptInvitation(c *gin.Context) {
+	var req v1.AcceptInvitationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, invitation, err := h.userService.AcceptInvitation(c.Request.Context(), &service.InvitationAcceptance{
+		Token:    req.Token,
+		Username: req.Username,
+		Password: req.Password,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	result := v1.AcceptInvitationResponse{
+		Invitation: *h.assembler.ToResponse(invitation, h.clock.Now()),
+		User:       *h.userAssembler.ToResponse(user),
+	}
+	if h.sessions != nil {
+		result.Tokens, err = h.sessions.StartSession(c, middleware.JWTClaims{
+			UserID:      user.UserID(),
+			Username:    user.Username,
+			Role:        user.Role,
+			Permissions: user.Permissions,
+		})
+		if err != nil {
+			h.handleError(c, err)
+			return
+		}
+	}
+
+	response.WithMessage(c, result, "invitation_accepted")
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *InvitationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrInvitationNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeInvitationNotFound, "invitation_not_found", err)
+	case errors.Is(err, model.ErrInvitationEmailInvalid), errors.Is(err, model.ErrOrganizationRoleInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeInvitationInvalid, "invitation_invalid", err)
+	case errors.Is(err, model.ErrInvitationTokenInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeInvitationTokenInvalid, "invitation_token_invalid", err)
+	case errors.Is(err, model.ErrInvitationExpired):
+		response.Error(c, http.StatusGone, response.CodeInvitationExpired, "invitation_expired", err)
+	case errors.Is(err, model.ErrInvitationNotPending):
+		response.Error(c, http.StatusConflict, response.CodeInvitationNotPending, "invitation_not_pending", err)
+	case errors.Is(err, model.ErrInvitationForbidden):
+		response.Error(c, http.StatusForbidden, response.CodeInvitationForbidden, "invitation_forbidden", err)
+	case errors.Is(err, model.ErrOrganizationNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeOrganizationNotFound, "organization_not_found", err)
+	case errors.Is(err, model.ErrUserCredentialsInvalid):
+		response.Error(c, http.StatusUnauthorized, response.CodePasswordError, "login_invalid", err)
+	case errors.Is(err, model.ErrUserEmailInvalid), errors.Is(err, model.ErrUserUsernameTooLong):
+		response.Error(c, http.StatusBadRequest, response.CodeValidationError, "user_invalid", err)
+	case errors.Is(err, model.ErrUserPasswordTooShort):
+		response.Error(c, http.StatusBadRequest, response.CodePasswordTooWeak, "user_password_too_short", err)
+	case errors.Is(err, model.ErrUserExists):
+		response.Error(c, http.StatusConflict, response.CodeEmailExists, "user_email_exists", err)
+	default:
+		logger.Error("Invitation operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
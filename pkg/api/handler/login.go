@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// LoginHandler 登录处理器，校验邮箱和密码后创建会话
+type LoginHandler struct {
+	userService service.UserServiceInterface
+	sessions    *SessionHandler
+}
+
+// NewLoginHandler 创建登录处理器
+func NewLoginHandler(userService service.UserServiceInterface, sessions *SessionHandler) *LoginHandler {
+	return &LoginHandler{
+		userService: userService,
+		sessions:    sessions,
+	}
+}
+
+// Login godoc
+// @Summary 登录
+// @Description 以邮箱和密码登录，无需认证，成功时创建会话并返回其令牌。凭证错误返回401并计入登录防暴力破解的失败次数，失败过多时返回429
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Param request body v1.LoginRequest true "登录信息"
+// @Success 200 {object} v1.SessionTokensResponseEnvelope "登录成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "邮箱或密码错误"
+// @Failure 403 {object} v1.ErrorEnvelope "公开注册的用户尚未验证邮箱"
+// @Failure 429 {object} v1.ErrorEnvelope "登录失败次数过多"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /auth/login [post]
+func (h *LoginHandler) Login(c *gin.Context) {
+	var req v1.LoginRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.Authenticate(c.Request.Context(), req.Email, req.Password)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	tokens, err := h.sessions.StartSession(c, middleware.JWTClaims{
+		UserID:      user.UserID(),
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: user.Permissions,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, tokens, "logged_in")
+}
+
+// handleError 将领域错误映射为HTTP响应，凭证错误以401响应供登录防暴力破解计数
+func (h *LoginHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrUserCredentialsInvalid):
+		response.Error(c, http.StatusUnauthorized, response.CodePasswordError, "login_invalid", err)
+	case errors.Is(err, model.ErrUserEmailUnverified):
+		response.Error(c, http.StatusForbidden, response.CodeRegistrationEmailUnverified, "user_email_unverified", err)
+	default:
+		logger.Error("Login failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// MeHandler 当前用户处理器，前端以访问令牌获取当前用户、权限和偏好以完成初始化
+type MeHandler struct {
+	notificationService   service.NotificationServiceInterface
+	preferenceService     service.UserPreferenceServiceInterface
+	assembler             *assembler.MeAssembler
+	notificationAssembler *assembler.NotificationAssembler
+}
+
+// NewMeHandler 创建当前用户处理器
+func NewMeHandler(notificationService service.NotificationServiceInterface, preferenceService service.UserPreferenceServiceInterface) *MeHandler {
+	return &MeHandler{
+		notificationService:   notificationService,
+		preferenceService:     preferenceService,
+		assembler:             assembler.NewMeAssembler(),
+		notificationAssembler: assembler.NewNotificationAssembler(),
+	}
+}
+
+// GetMe godoc
+// @Summary 获取当前用户
+// @Description 返回访问令牌中的当前用户，模拟请求同时返回代为操作的用户
+// @Tags 当前用户
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.MeResponseEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Router /users/me [get]
+// @Security BearerAuth
+func (h *MeHandler) GetMe(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(user))
+}
+
+// GetMyPermissions godoc
+// @Summary 获取当前用户权限
+// @Description 返回当前用户的角色和权限
+// @Tags 当前用户
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.MePermissionsResponseEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Router /users/me/permissions [get]
+// @Security BearerAuth
+func (h *MeHandler) GetMyPermissions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	response.Success(c, h.assembler.ToPermissionsResponse(user))
+}
+
+// GetMyPreferences godoc
+// @Summary 获取当前用户偏好
+// @Description 返回当前用户的语言、时区和通知渠道偏好，未设置的偏好为默认值，同时返回已设置的通知渠道
+// @Tags 当前用户
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.MePreferencesResponseEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/preferences [get]
+// @Security BearerAuth
+func (h *MeHandler) GetMyPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	h.respondPreferences(c, user.UserID, "")
+}
+
+// UpdateMyPreferences godoc
+// @Summary 更新当前用户偏好
+// @Description 部分更新当前用户的偏好：省略的字段保持不变，空值恢复默认值。偏好语言和时区应用于该用户的后续请求和通知
+// @Tags 当前用户
+// @Accept json
+// @Produce json
+// @Param request body v1.UpdateMyPreferencesRequest true "偏好设置"
+// @Success 200 {object} v1.MePreferencesResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或偏好无效"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/preferences [patch]
+// @Security BearerAuth
+func (h *MeHandler) UpdateMyPreferences(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req v1.UpdateMyPreferencesRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if _, err := h.preferenceService.UpdatePreferences(c.Request.Context(), user.UserID, h.assembler.ToPreferencesPatch(&req)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	h.respondPreferences(c, user.UserID, "preferences_updated")
+}
+
+// ResetMyPreferences godoc
+// @Summary 恢复当前用户默认偏好
+// @Description 删除当前用户的语言、时区和通知渠道偏好，恢复默认值；通知渠道偏好不受影响
+// @Tags 当前用户
+// @Accept json
+// @Produce json
+// @Success 204 "恢复成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/preferences [delete]
+// @Security BearerAuth
+func (h *MeHandler) ResetMyPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.preferenceService.ResetPreferences(c.Request.Context(), userID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// respondPreferences 返回用户填充默认值后的偏好及其通知渠道偏好，message为空时使用默认消息
+func (h *MeHandler) respondPreferences(c *gin.Context, userID, message string) {
+	prefs, err := h.preferenceService.EffectivePreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	notifications, err := h.notificationService.ListPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	if prefs.Language == "" {
+		// 未设置语言时为当前请求检测到的语言
+		prefs.Language = i18n.GetLanguage(c)
+	}
+	data := h.assembler.ToPreferencesResponse(prefs, h.notificationAssembler.ToResponseList(notifications))
+	if message == "" {
+		response.Success(c, data)
+		return
+	}
+	response.WithMessage(c, data, message)
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *MeHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrPreferencesLanguageInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodePreferencesInvalid, "preference_language_invalid", err)
+	case errors.Is(err, model.ErrPreferencesTimeZoneInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodePreferencesInvalid, "preference_time_zone_invalid", err)
+	case errors.Is(err, model.ErrNotificationChannelInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodePreferencesInvalid, "preferences_invalid", err)
+	default:
+		logger.Error("Preference operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
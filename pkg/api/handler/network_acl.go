@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/netacl"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// NetworkACLHandler 网络访问控制处理器，查看和在运行时替换按IP和国家拦截请求的规则
+type NetworkACLHandler struct {
+	acl       *netacl.Manager
+	assembler *assembler.AdminAssembler
+}
+
+// NewNetworkACLHandler 创建网络访问控制处理器
+func NewNetworkACLHandler(acl *netacl.Manager) *NetworkACLHandler {
+	return &NetworkACLHandler{
+		acl:       acl,
+		assembler: assembler.NewAdminAssembler(),
+	}
+}
+
+// GetNetworkACL godoc
+// @Summary 获取网络访问控制规则
+// @Description 获取当前生效的全局规则和分组规则，包括运行时替换的规则
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.NetworkACLResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/network-acl [get]
+// @Security BearerAuth
+func (h *NetworkACLHandler) GetNetworkACL(c *gin.Context) {
+	global, groups := h.acl.Rules()
+	response.Success(c, h.assembler.ToNetworkACLResponse(h.acl.GeoIPEnabled(), global, groups))
+}
+
+// UpdateGlobalRule godoc
+// @Summary 替换全局网络访问控制规则
+// @Description 替换对所有请求生效的规则，仅对当前实例生效，重启后恢复为配置的规则。注意不要拒绝管理员自己的地址
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param request body v1.NetworkACLRuleRequest true "规则"
+// @Success 200 {object} v1.NetworkACLResponseEnvelope "替换成功"
+// @Failure 400 {object} v1.ErrorEnvelope "规则无效"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/network-acl/global [put]
+// @Security BearerAuth
+func (h *NetworkACLHandler) UpdateGlobalRule(c *gin.Context) {
+	var req v1.NetworkACLRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.acl.SetGlobal(c.Request.Context(), h.assembler.ToNetworkACLRule(&req)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	global, groups := h.acl.Rules()
+	response.WithMessage(c, h.assembler.ToNetworkACLResponse(h.acl.GeoIPEnabled(), global, groups), "network_acl_updated")
+}
+
+// UpdateGroupRule godoc
+// @Summary 创建或替换分组网络访问控制规则
+// @Description 创建或替换对路径前缀下的请求生效的分组规则，仅对当前实例生效，重启后恢复为配置的规则
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param name path string true "分组名称" example(admin)
+// @Param request body v1.NetworkACLRuleRequest true "规则，paths不能为空"
+// @Success 200 {object} v1.NetworkACLResponseEnvelope "替换成功"
+// @Failure 400 {object} v1.ErrorEnvelope "规则无效"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/network-acl/groups/{name} [put]
+// @Security BearerAuth
+func (h *NetworkACLHandler) UpdateGroupRule(c *gin.Context) {
+	var req v1.NetworkACLRuleRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	if err := h.acl.SetGroup(c.Request.Context(), c.Param("name"), h.assembler.ToNetworkACLRule(&req)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	global, groups := h.acl.Rules()
+	response.WithMessage(c, h.assembler.ToNetworkACLResponse(h.acl.GeoIPEnabled(), global, groups), "network_acl_updated")
+}
+
+// DeleteGroupRule godoc
+// @Summary 删除分组网络访问控制规则
+// @Description 删除分组规则，仅对当前实例生效，重启后恢复为配置的规则
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param name path string true "分组名称" example(admin)
+// @Success 204 "删除成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "分组不存在"
+// @Router /admin/network-acl/groups/{name} [delete]
+// @Security BearerAuth
+func (h *NetworkACLHandler) DeleteGroupRule(c *gin.Context) {
+	if err := h.acl.DeleteGroup(c.Request.Context(), c.Param("name")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// handleError 将网络访问控制错误映射为HTTP响应
+func (h *NetworkACLHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, netacl.ErrInvalidRule), errors.Is(err, netacl.ErrGeoIPUnavailable):
+		response.Error(c, http.StatusBadRequest, response.CodeNetworkACLInvalid, "network_acl_invalid", err)
+	case errors.Is(err, netacl.ErrGroupNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeNetworkACLGroupNotFound, "network_acl_group_not_found", err)
+	default:
+		logger.Error("Network ACL operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,186 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// NotificationHandler 通知渠道偏好处理器，管理当前用户的通知渠道
+type NotificationHandler struct {
+	notificationService service.NotificationServiceInterface
+	assembler           *assembler.NotificationAssembler
+}
+
+// NewNotificationHandler 创建通知渠道偏好处理器
+func NewNotificationHandler(notificationService service.NotificationServiceInterface) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		assembler:           assembler.NewNotificationAssembler(),
+	}
+}
+
+// ListPreferences godoc
+// @Summary 获取通知渠道偏好
+// @Description 获取当前用户设置的全部通知渠道
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.NotificationPreferenceResponseListEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /notification-preferences [get]
+// @Security BearerAuth
+func (h *NotificationHandler) ListPreferences(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.notificationService.ListPreferences(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(prefs))
+}
+
+// SetPreference godoc
+// @Summary 设置通知渠道偏好
+// @Description 设置当前用户在通知渠道上的地址、开关和语言，已存在时整体替换
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param channel path string true "通知渠道" Enums(sms, push, webhook)
+// @Param request body v1.SetNotificationPreferenceRequest true "通知渠道偏好"
+// @Success 200 {object} v1.NotificationPreferenceResponseEnvelope "设置成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /notification-preferences/{channel} [put]
+// @Security BearerAuth
+func (h *NotificationHandler) SetPreference(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	var req v1.SetNotificationPreferenceRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	pref, err := h.notificationService.SetPreference(c.Request.Context(), h.assembler.ToModel(userID, c.Param("channel"), &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToResponse(pref), "notification_preference_updated")
+}
+
+// DeletePreference godoc
+// @Summary 删除通知渠道偏好
+// @Description 删除当前用户的通知渠道，之后不再通过该渠道发送通知
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param channel path string true "通知渠道" Enums(sms, push, webhook)
+// @Success 204 "删除成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 404 {object} v1.ErrorEnvelope "通知渠道偏好不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /notification-preferences/{channel} [delete]
+// @Security BearerAuth
+func (h *NotificationHandler) DeletePreference(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.DeletePreference(c.Request.Context(), userID, c.Param("channel")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// TestPreference godoc
+// @Summary 发送测试通知
+// @Description 通过当前用户已启用的通知渠道发送一条测试通知，返回发送结果
+// @Tags 通知
+// @Accept json
+// @Produce json
+// @Param channel path string true "通知渠道" Enums(sms, push, webhook)
+// @Success 200 {object} v1.NotificationDeliveryResponseListEnvelope "已发送"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 404 {object} v1.ErrorEnvelope "通知渠道偏好不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "通知渠道未启用"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /notification-preferences/{channel}/test [post]
+// @Security BearerAuth
+func (h *NotificationHandler) TestPreference(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	channel := c.Param("channel")
+	if _, err := h.notificationService.GetPreference(ctx, userID, channel); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	deliveries, err := h.notificationService.Notify(ctx, userID, &service.Notification{
+		Template: service.NotificationTemplateTest,
+		Channels: []string{channel},
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToDeliveryResponseList(deliveries), "notification_sent")
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *NotificationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrNotificationPreferenceNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeNotificationPreferenceNotFound, "notification_preference_not_found", err)
+	case errors.Is(err, model.ErrNotificationChannelInvalid), errors.Is(err, model.ErrNotificationAddressInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeNotificationPreferenceInvalid, "notification_preference_invalid", err)
+	case errors.Is(err, model.ErrNotificationChannelsUnavailable):
+		response.Error(c, http.StatusConflict, response.CodeNotificationChannelDisabled, "notification_channel_disabled", err)
+	default:
+		logger.Error("Notification operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
+
+// currentUserID 返回当前认证用户的ID，缺失时写入未认证响应
+func currentUserID(c *gin.Context) (string, bool) {
+	user, ok := currentUser(c)
+	return user.UserID, ok
+}
+
+// currentUser 返回当前认证用户，未认证时写入未认证响应
+func currentUser(c *gin.Context) (principal.Principal, bool) {
+	user, ok := principal.CurrentUser(c)
+	if !ok {
+		response.Unauthorized(c, "unauthorized", fmt.Errorf("authentication required"))
+	}
+	return user, ok
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/api/sse"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// operationProgressEvent 任务进度SSE事件的类型
+const operationProgressEvent = "progress"
+
+// OperationHandler 任务处理器
+type OperationHandler struct {
+	operationService      service.OperationServiceInterface
+	operationEventService service.OperationEventServiceInterface
+	assembler             *assembler.OperationAssembler
+	heartbeat             time.Duration
+}
+
+// NewOperationHandler 创建任务处理器
+func NewOperationHandler(operationService service.OperationServiceInterface, operationEventService service.OperationEventServiceInterface) *OperationHandler {
+	return &OperationHandler{
+		operationService:      operationService,
+		operationEventService: operationEventService,
+		assembler:             assembler.NewOperationAssembler(),
+		heartbeat:             sse.DefaultHeartbeat,
+	}
+}
+
+// ListOperations godoc
+// @Summary 获取任务列表
+// @Description 分页获取后台任务，按创建时间倒序，可按类型和状态过滤；非管理员只能看到自己在当前组织中发起的任务
+// @Tags 任务
+// @Accept json
+// @Produce json
+// @Param page query int false "页码" default(1) minimum(1)
+// @Param size query int false "每页数量" default(10) minimum(1) maximum(100)
+// @Param type query string false "任务类型" Enums(application_backup, application_import, application_batch_delete, email_delivery, file_processing)
+// @Param status query string false "任务状态" Enums(pending, running, completed, failed)
+// @Param fields query string false "只返回指定字段，逗号分隔，如 id,status,progress；字段不存在时返回400"
+// @Success 200 {object} v1.OperationResponsePageEnvelope "获取成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /operations [get]
+// @Security BearerAuth
+func (h *OperationHandler) ListOperations(c *gin.Context) {
+	var req v1.ListOperationsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			response.ValidationError(c, response.ParseValidationErrors(validationErrors))
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return
+	}
+	req.Validate()
+
+	ops, total, err := h.operationService.ListOperations(c.Request.Context(), req.Type, req.Status, req.Page, req.Size)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Page(c, h.assembler.ToResponseList(ops), req.Page, req.Size, int(total))
+}
+
+// GetOperation godoc
+// @Summary 获取任务
+// @Description 根据任务ID获取任务的状态、进度和结果；其他用户的任务返回404
+// @Tags 任务
+// @Accept json
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param fields query string false "只返回指定字段，逗号分隔，如 id,status,progress；字段不存在时返回400"
+// @Success 200 {object} v1.OperationResponseEnvelope "获取成功"
+// @Failure 404 {object} v1.ErrorEnvelope "任务不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /operations/{id} [get]
+// @Security BearerAuth
+func (h *OperationHandler) GetOperation(c *gin.Context) {
+	op, err := h.operationService.GetOperation(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(op))
+}
+
+// StreamOperationEvents godoc
+// @Summary 订阅任务进度
+// @Description 以Server-Sent Events推送长时间运行任务的状态和进度，事件类型为progress，data为任务进度事件，事件ID为序号。
+// @Description 断线重连时通过Last-Event-ID请求头（或last_event_id查询参数）从下一个事件继续；任务结束后连接关闭，之后的重连返回204。
+// @Description 空闲时定期发送注释行作为心跳。事件在任务结束后保留15分钟，之后只发送一次ID为0的当前状态。
+// @Tags 任务
+// @Produce text/event-stream
+// @Param id path string true "任务ID"
+// @Param Last-Event-ID header string false "最后收到的事件ID"
+// @Param last_event_id query string false "最后收到的事件ID，无法设置请求头时使用"
+// @Success 200 {object} v1.OperationEventResponse "任务进度事件流"
+// @Success 204 "任务已结束且没有新的事件"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 404 {object} v1.ErrorEnvelope "任务不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /operations/{id}/events [get]
+// @Security BearerAuth
+func (h *OperationHandler) StreamOperationEvents(c *gin.Context) {
+	var after uint64
+	lastEventID := sse.LastEventID(c)
+	if lastEventID != "" {
+		var err error
+		if after, err = strconv.ParseUint(lastEventID, 10, 64); err != nil {
+			response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("invalid last event id %q", lastEventID))
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	// 先按调用者的范围读取任务，其他用户的任务返回404
+	op, err := h.operationService.GetOperation(ctx, c.Param("id"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	events, err := h.operationEventService.Watch(ctx, op.OperationID, after)
+	if errors.Is(err, model.ErrOperationNotFound) {
+		h.streamSnapshot(c, op, lastEventID != "")
+		return
+	}
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	stream, err := sse.NewStream(c, sse.DefaultRetry)
+	if err != nil {
+		// 客户端已断开
+		return
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+			err = stream.Send(sse.Event{
+				ID:    strconv.FormatUint(e.Sequence, 10),
+				Event: operationProgressEvent,
+				Data:  h.assembler.ToEventResponse(e),
+			})
+		case <-heartbeat.C:
+			err = stream.Heartbeat()
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			logger.Debug("Operation event stream closed: %v", err)
+			return
+		}
+	}
+}
+
+// streamSnapshot 事件已不在内存中（如任务早已结束或服务重启）时，根据任务记录发送一次当前状态
+func (h *OperationHandler) streamSnapshot(c *gin.Context, op *model.Operation, resumed bool) {
+	// 客户端已收到过快照，结束的任务不再重复发送
+	if resumed && op.Finished() {
+		response.NoContent(c)
+		return
+	}
+
+	stream, err := sse.NewStream(c, sse.DefaultRetry)
+	if err != nil {
+		return
+	}
+	if err := stream.Send(sse.Event{
+		ID:    "0",
+		Event: operationProgressEvent,
+		Data:  h.assembler.ToSnapshotEvent(op),
+	}); err != nil {
+		logger.Debug("Operation event stream closed: %v", err)
+	}
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *OperationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrOperationNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeOperationNotFound, "operation_not_found", err)
+	case errors.Is(err, model.ErrOperationFinished):
+		// 204使EventSource停止重连
+		response.NoContent(c)
+	default:
+		logger.Error("Operation event stream failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,370 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// OrganizationHandler 组织处理器，管理组织及其成员；组织路由的当前组织由组织中间件从 org_id 路径参数解析
+type OrganizationHandler struct {
+	organizationService service.OrganizationServiceInterface
+	assembler           *assembler.OrganizationAssembler
+}
+
+// NewOrganizationHandler 创建组织处理器
+func NewOrganizationHandler(organizationService service.OrganizationServiceInterface) *OrganizationHandler {
+	return &OrganizationHandler{
+		organizationService: organizationService,
+		assembler:           assembler.NewOrganizationAssembler(),
+	}
+}
+
+// CreateOrganization godoc
+// @Summary 创建组织
+// @Description 创建组织，当前用户成为其所有者
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param request body v1.CreateOrganizationRequest true "组织信息"
+// @Success 201 {object} v1.OrganizationResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 409 {object} v1.ErrorEnvelope "组织已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations [post]
+// @Security BearerAuth
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	var req v1.CreateOrganizationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	org, err := h.organizationService.CreateOrganization(c.Request.Context(), h.assembler.ToModel(&req), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToResponse(org, model.OrganizationRoleOwner), "organization_created")
+}
+
+// ListOrganizations godoc
+// @Summary 获取我的组织
+// @Description 获取当前用户所属的组织及其在各组织中的角色
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.OrganizationResponseListEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) ListOrganizations(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	orgs, memberships, err := h.organizationService.ListOrganizations(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(orgs, memberships))
+}
+
+// GetOrganization godoc
+// @Summary 获取组织
+// @Description 获取当前用户所属的组织
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.OrganizationResponseEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "不是组织成员"
+// @Failure 404 {object} v1.ErrorEnvelope "组织不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id} [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	org, err := h.organizationService.GetOrganization(c.Request.Context(), user.OrganizationID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(org, user.OrganizationRole))
+}
+
+// UpdateOrganization godoc
+// @Summary 更新组织
+// @Description 替换组织的名称和描述，需要管理员或所有者角色
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Param request body v1.UpdateOrganizationRequest true "组织信息"
+// @Success 200 {object} v1.OrganizationResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "角色不足"
+// @Failure 409 {object} v1.ErrorEnvelope "组织名称已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id} [put]
+// @Security BearerAuth
+func (h *OrganizationHandler) UpdateOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req v1.UpdateOrganizationRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	org, err := h.organizationService.UpdateOrganization(c.Request.Context(), h.assembler.ToUpdateModel(user.OrganizationID, &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToResponse(org, user.OrganizationRole), "organization_updated")
+}
+
+// DeleteOrganization godoc
+// @Summary 删除组织
+// @Description 删除组织及其成员，需要所有者角色；仍有应用的组织不能删除
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Success 204 "删除成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "角色不足"
+// @Failure 409 {object} v1.ErrorEnvelope "组织仍有应用"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id} [delete]
+// @Security BearerAuth
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	if err := h.organizationService.DeleteOrganization(c.Request.Context(), user.OrganizationID); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// ListMembers godoc
+// @Summary 获取组织成员
+// @Description 获取组织的成员及其角色，按用户ID排序
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Success 200 {object} v1.OrganizationMemberResponseListEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "不是组织成员"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id}/members [get]
+// @Security BearerAuth
+func (h *OrganizationHandler) ListMembers(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	members, err := h.organizationService.ListMembers(c.Request.Context(), user.OrganizationID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToMemberResponseList(members))
+}
+
+// AddMember godoc
+// @Summary 添加组织成员
+// @Description 将用户加入组织，需要管理员或所有者角色；只有所有者可以添加所有者
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Param request body v1.AddOrganizationMemberRequest true "成员信息"
+// @Success 201 {object} v1.OrganizationMemberResponseEnvelope "添加成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "角色不足"
+// @Failure 409 {object} v1.ErrorEnvelope "用户已是组织成员"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id}/members [post]
+// @Security BearerAuth
+func (h *OrganizationHandler) AddMember(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req v1.AddOrganizationMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if req.Role == model.OrganizationRoleOwner && !h.requireOwner(c, user) {
+		return
+	}
+
+	member, err := h.organizationService.AddMember(c.Request.Context(), h.assembler.ToMemberModel(user.OrganizationID, &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToMemberResponse(member), "organization_member_added")
+}
+
+// UpdateMember godoc
+// @Summary 修改组织成员角色
+// @Description 修改成员的角色，需要管理员或所有者角色；只有所有者可以授予或撤销所有者角色，组织至少保留一名所有者
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Param user_id path string true "用户ID" example(1002)
+// @Param request body v1.UpdateOrganizationMemberRequest true "角色"
+// @Success 200 {object} v1.OrganizationMemberResponseEnvelope "修改成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "角色不足"
+// @Failure 404 {object} v1.ErrorEnvelope "成员不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "组织至少需要一名所有者"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id}/members/{user_id} [put]
+// @Security BearerAuth
+func (h *OrganizationHandler) UpdateMember(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	var req v1.UpdateOrganizationMemberRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+	if !h.canManage(c, user, c.Param("user_id"), req.Role) {
+		return
+	}
+
+	member, err := h.organizationService.UpdateMemberRole(c.Request.Context(), user.OrganizationID, c.Param("user_id"), req.Role)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToMemberResponse(member), "organization_member_updated")
+}
+
+// RemoveMember godoc
+// @Summary 移除组织成员
+// @Description 将用户移出组织，需要管理员或所有者角色；只有所有者可以移除所有者，组织至少保留一名所有者
+// @Tags 组织
+// @Accept json
+// @Produce json
+// @Param org_id path string true "组织ID或公开ID（UUID）" example(1)
+// @Param user_id path string true "用户ID" example(1002)
+// @Success 204 "移除成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "角色不足"
+// @Failure 404 {object} v1.ErrorEnvelope "成员不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "组织至少需要一名所有者"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /organizations/{org_id}/members/{user_id} [delete]
+// @Security BearerAuth
+func (h *OrganizationHandler) RemoveMember(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	if !h.canManage(c, user, c.Param("user_id"), "") {
+		return
+	}
+
+	if err := h.organizationService.RemoveMember(c.Request.Context(), user.OrganizationID, c.Param("user_id")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// canManage 检查当前用户能否将成员userID的角色改为role（为空表示移除），涉及所有者时需要当前用户是所有者
+func (h *OrganizationHandler) canManage(c *gin.Context, user principal.Principal, userID, role string) bool {
+	if role == model.OrganizationRoleOwner {
+		return h.requireOwner(c, user)
+	}
+	member, err := h.organizationService.GetMembership(c.Request.Context(), user.OrganizationID, userID)
+	if err != nil {
+		h.handleError(c, err)
+		return false
+	}
+	if member.Role == model.OrganizationRoleOwner {
+		return h.requireOwner(c, user)
+	}
+	return true
+}
+
+// requireOwner 当前用户不是组织所有者时返回403
+func (h *OrganizationHandler) requireOwner(c *gin.Context, user principal.Principal) bool {
+	if user.OrganizationRole == model.OrganizationRoleOwner {
+		return true
+	}
+	response.Error(c, http.StatusForbidden, response.CodeOrganizationAccessDenied, "organization_owner_only",
+		errors.New("only owners may grant, revoke or remove the owner role"))
+	return false
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *OrganizationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrOrganizationNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeOrganizationNotFound, "organization_not_found", err)
+	case errors.Is(err, model.ErrOrganizationExists):
+		response.Error(c, http.StatusConflict, response.CodeOrganizationExists, "organization_exists", err)
+	case errors.Is(err, model.ErrOrganizationNameInvalid), errors.Is(err, model.ErrOrganizationDescriptionTooLong):
+		response.Error(c, http.StatusBadRequest, response.CodeOrganizationInvalid, "organization_invalid", err)
+	case errors.Is(err, model.ErrOrganizationNotEmpty):
+		response.Error(c, http.StatusConflict, response.CodeOrganizationNotEmpty, "organization_not_empty", err)
+	case errors.Is(err, model.ErrOrganizationMemberNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeOrganizationMemberNotFound, "organization_member_not_found", err)
+	case errors.Is(err, model.ErrOrganizationMemberExists):
+		response.Error(c, http.StatusConflict, response.CodeOrganizationMemberExists, "organization_member_exists", err)
+	case errors.Is(err, model.ErrOrganizationRoleInvalid), errors.Is(err, model.ErrOrganizationMemberUserRequired):
+		response.Error(c, http.StatusBadRequest, response.CodeOrganizationInvalid, "organization_member_invalid", err)
+	case errors.Is(err, model.ErrOrganizationOwnerRequired):
+		response.Error(c, http.StatusConflict, response.CodeOrganizationOwnerRequired, "organization_owner_required", err)
+	default:
+		logger.Error("Organization operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// PartnerHandler 合作方处理器，管理调用签名路由的合作方及其签名密钥
+type PartnerHandler struct {
+	partnerService service.PartnerServiceInterface
+	assembler      *assembler.PartnerAssembler
+}
+
+// NewPartnerHandler 创建合作方处理器
+func NewPartnerHandler(partnerService service.PartnerServiceInterface) *PartnerHandler {
+	return &PartnerHandler{
+		partnerService: partnerService,
+		assembler:      assembler.NewPartnerAssembler(),
+	}
+}
+
+// ListPartners godoc
+// @Summary 获取合作方列表
+// @Description 获取所有合作方，按名称排序，不包含签名密钥
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.PartnerResponseListEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners [get]
+// @Security BearerAuth
+func (h *PartnerHandler) ListPartners(c *gin.Context) {
+	partners, err := h.partnerService.ListPartners(c.Request.Context())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(partners))
+}
+
+// GetPartner godoc
+// @Summary 获取合作方
+// @Description 按名称获取合作方，不包含签名密钥
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Param name path string true "合作方名称" example(acme)
+// @Success 200 {object} v1.PartnerResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "合作方不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners/{name} [get]
+// @Security BearerAuth
+func (h *PartnerHandler) GetPartner(c *gin.Context) {
+	partner, err := h.partnerService.GetPartner(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(partner))
+}
+
+// CreatePartner godoc
+// @Summary 创建合作方
+// @Description 创建合作方并生成签名密钥；密钥只在此响应中返回一次，存储时加密
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Param request body v1.CreatePartnerRequest true "合作方信息"
+// @Success 201 {object} v1.PartnerSecretResponseEnvelope "创建成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 409 {object} v1.ErrorEnvelope "合作方已存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners [post]
+// @Security BearerAuth
+func (h *PartnerHandler) CreatePartner(c *gin.Context) {
+	var req v1.CreatePartnerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	partner, secret, err := h.partnerService.CreatePartner(c.Request.Context(), h.assembler.ToModel(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToSecretResponse(partner, secret), "partner_created")
+}
+
+// UpdatePartner godoc
+// @Summary 更新合作方
+// @Description 替换合作方的描述和启用状态；停用后其签名请求立即被拒绝
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Param name path string true "合作方名称" example(acme)
+// @Param request body v1.UpdatePartnerRequest true "合作方信息"
+// @Success 200 {object} v1.PartnerResponseEnvelope "更新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "合作方不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners/{name} [put]
+// @Security BearerAuth
+func (h *PartnerHandler) UpdatePartner(c *gin.Context) {
+	var req v1.UpdatePartnerRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	partner, err := h.partnerService.UpdatePartner(c.Request.Context(), h.assembler.ToUpdateModel(c.Param("name"), &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToResponse(partner), "partner_updated")
+}
+
+// DeletePartner godoc
+// @Summary 删除合作方
+// @Description 删除合作方，其签名请求立即被拒绝
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Param name path string true "合作方名称" example(acme)
+// @Success 204 "删除成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "合作方不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners/{name} [delete]
+// @Security BearerAuth
+func (h *PartnerHandler) DeletePartner(c *gin.Context) {
+	if err := h.partnerService.DeletePartner(c.Request.Context(), c.Param("name")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// RotatePartnerSecret godoc
+// @Summary 轮换合作方密钥
+// @Description 生成新的签名密钥并只在此响应中返回一次；原密钥在 security.signing.rotation_grace 内仍可签名，以便合作方切换
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Param name path string true "合作方名称" example(acme)
+// @Success 200 {object} v1.PartnerSecretResponseEnvelope "轮换成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 404 {object} v1.ErrorEnvelope "合作方不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/partners/{name}/rotate [post]
+// @Security BearerAuth
+func (h *PartnerHandler) RotatePartnerSecret(c *gin.Context) {
+	partner, secret, err := h.partnerService.RotatePartnerSecret(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToSecretResponse(partner, secret), "partner_secret_rotated")
+}
+
+// GetCurrentPartner godoc
+// @Summary 获取当前合作方
+// @Description 返回签名请求的合作方信息，可用于合作方验证签名实现。请求需携带X-Signature-Key、X-Signature-Timestamp、X-Signature-Nonce和X-Signature请求头
+// @Tags 合作方
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.PartnerResponseEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "签名缺失、无效、过期或重放"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /partners/me [get]
+func (h *PartnerHandler) GetCurrentPartner(c *gin.Context) {
+	name := middleware.CurrentPartner(c)
+	if name == "" {
+		response.Error(c, http.StatusUnauthorized, response.CodeSignatureMissing, "signature_missing", errors.New("request is not signed by a partner"))
+		return
+	}
+
+	partner, err := h.partnerService.GetPartner(c.Request.Context(), name)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(partner))
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *PartnerHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrPartnerNotFound):
+		response.Error(c, http.StatusNotFound, response.CodePartnerNotFound, "partner_not_found", err)
+	case errors.Is(err, model.ErrPartnerNameInvalid), errors.Is(err, model.ErrPartnerDescriptionTooLong):
+		response.Error(c, http.StatusBadRequest, response.CodePartnerInvalid, "partner_invalid", err)
+	case errors.Is(err, model.ErrPartnerExists):
+		response.Error(c, http.StatusConflict, response.CodePartnerExists, "partner_exists", err)
+	default:
+		logger.Error("Partner operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
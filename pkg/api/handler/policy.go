@@ -0,0 +1,190 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// PolicyHandler 政策处理器，提供服务条款等政策文档及用户的同意记录
+type PolicyHandler struct {
+	policyService service.PolicyServiceInterface
+	assembler     *assembler.PolicyAssembler
+}
+
+// NewPolicyHandler 创建政策处理器
+func NewPolicyHandler(policyService service.PolicyServiceInterface) *PolicyHandler {
+	return &PolicyHandler{
+		policyService: policyService,
+		assembler:     assembler.NewPolicyAssembler(),
+	}
+}
+
+// ListPolicies godoc
+// @Summary 获取政策列表
+// @Description 获取每个政策的当前版本，文档语言按lang参数、Accept-Language请求头或lang Cookie选择
+// @Tags 政策
+// @Accept json
+// @Produce json
+// @Param lang query string false "语言" example(zh-CN)
+// @Success 200 {object} v1.PolicyResponseListEnvelope "获取成功"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /policies [get]
+func (h *PolicyHandler) ListPolicies(c *gin.Context) {
+	docs, err := h.policyService.ListCurrentPolicies(c.Request.Context(), i18n.GetLanguage(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponseList(docs))
+}
+
+// GetPolicy godoc
+// @Summary 获取政策
+// @Description 获取政策的当前版本，没有请求语言的文档时返回默认语言或其他已发布语言的文档
+// @Tags 政策
+// @Accept json
+// @Produce json
+// @Param name path string true "政策名称" example(terms)
+// @Param lang query string false "语言" example(zh-CN)
+// @Success 200 {object} v1.PolicyResponseEnvelope "获取成功"
+// @Failure 404 {object} v1.ErrorEnvelope "政策不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /policies/{name} [get]
+func (h *PolicyHandler) GetPolicy(c *gin.Context) {
+	doc, err := h.policyService.GetCurrentPolicy(c.Request.Context(), c.Param("name"), i18n.GetLanguage(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToResponse(doc))
+}
+
+// PublishPolicy godoc
+// @Summary 发布政策文档
+// @Description 以一种语言发布政策的当前版本或下一版本；下一版本发布后成为当前版本，用户需重新接受
+// @Tags 政策
+// @Accept json
+// @Produce json
+// @Param name path string true "政策名称" example(terms)
+// @Param request body v1.PublishPolicyRequest true "政策文档"
+// @Success 201 {object} v1.PolicyResponseEnvelope "发布成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 409 {object} v1.ErrorEnvelope "该语言的版本已发布"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /policies/{name}/versions [post]
+// @Security BearerAuth
+func (h *PolicyHandler) PublishPolicy(c *gin.Context) {
+	var req v1.PublishPolicyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	doc, err := h.policyService.PublishPolicy(c.Request.Context(), h.assembler.ToModel(c.Param("name"), &req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Created(c, h.assembler.ToResponse(doc), "policy_published")
+}
+
+// AcceptPolicy godoc
+// @Summary 接受政策
+// @Description 当前用户接受政策的当前版本，记录接受时的语言、IP和User-Agent；重复接受返回已有记录。模拟登录时不能代为接受
+// @Tags 政策
+// @Accept json
+// @Produce json
+// @Param name path string true "政策名称" example(terms)
+// @Param request body v1.AcceptPolicyRequest true "接受的版本"
+// @Success 200 {object} v1.PolicyConsentResponseEnvelope "接受成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 403 {object} v1.ErrorEnvelope "模拟登录时不允许接受"
+// @Failure 404 {object} v1.ErrorEnvelope "政策不存在"
+// @Failure 409 {object} v1.ErrorEnvelope "版本不是当前版本"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /policies/{name}/accept [post]
+// @Security BearerAuth
+func (h *PolicyHandler) AcceptPolicy(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	// 同意须由用户本人作出
+	if middleware.CurrentImpersonator(c) != nil {
+		response.Error(c, http.StatusForbidden, response.CodeImpersonationActionForbidden, "impersonation_action_forbidden",
+			errors.New("policies cannot be accepted on behalf of an impersonated user"))
+		return
+	}
+
+	var req v1.AcceptPolicyRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	consent := h.assembler.ToConsentModel(userID, c.Param("name"), i18n.GetLanguage(c), c.ClientIP(), c.Request.UserAgent(), &req)
+	consent, err := h.policyService.AcceptPolicy(c.Request.Context(), consent)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, h.assembler.ToConsentResponse(consent), "policy_accepted")
+}
+
+// ListConsents godoc
+// @Summary 获取政策同意记录
+// @Description 获取当前用户接受过的政策版本，按接受时间倒序
+// @Tags 政策
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.PolicyConsentResponseListEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /policies/consents [get]
+// @Security BearerAuth
+func (h *PolicyHandler) ListConsents(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+
+	consents, err := h.policyService.ListConsents(c.Request.Context(), userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToConsentResponseList(consents))
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *PolicyHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrPolicyNotFound):
+		response.Error(c, http.StatusNotFound, response.CodePolicyNotFound, "policy_not_found", err)
+	case errors.Is(err, model.ErrPolicyNameInvalid), errors.Is(err, model.ErrPolicyVersionInvalid),
+		errors.Is(err, model.ErrPolicyLanguageInvalid), errors.Is(err, model.ErrPolicyContentInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodePolicyInvalid, "policy_invalid", err)
+	case errors.Is(err, model.ErrPolicyDocumentExists):
+		response.Error(c, http.StatusConflict, response.CodePolicyDocumentExists, "policy_document_exists", err)
+	case errors.Is(err, model.ErrPolicyVersionOutdated):
+		response.Error(c, http.StatusConflict, response.CodePolicyVersionOutdated, "policy_version_outdated", err)
+	default:
+		logger.Error("Policy operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
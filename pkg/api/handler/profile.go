@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+)
+
+const (
+	// defaultProfileDuration 未指定时CPU分析和执行跟踪的采集时长
+	defaultProfileDuration = 5 * time.Second
+	// profileWriteGrace 采集结束后写出文件的时间
+	profileWriteGrace = 30 * time.Second
+)
+
+// ProfileHandler 按需性能分析处理器
+type ProfileHandler struct {
+	pprof       *pprof.PProfManager
+	maxDuration time.Duration
+}
+
+// NewProfileHandler 创建按需性能分析处理器，maxDuration为0时不限制采集时长
+func NewProfileHandler(pprofManager *pprof.PProfManager, maxDuration time.Duration) *ProfileHandler {
+	return &ProfileHandler{pprof: pprofManager, maxDuration: maxDuration}
+}
+
+// DownloadProfile godoc
+// @Summary 下载性能分析文件
+// @Description 采集单个性能分析并以文件下载：cpu和trace采集指定秒数，heap、allocs、goroutine、block、mutex为当前快照
+// @Tags 管理
+// @Produce octet-stream
+// @Param name path string true "分析类型" Enums(cpu, trace, heap, allocs, goroutine, block, mutex)
+// @Param seconds query int false "采集秒数" default(5) minimum(1)
+// @Success 200 {file} file "分析文件"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 409 {object} v1.ErrorEnvelope "已有CPU分析或执行跟踪在进行"
+// @Router /admin/profiles/{name} [get]
+// @Security BearerAuth
+func (h *ProfileHandler) DownloadProfile(c *gin.Context) {
+	_, duration, ok := h.bind(c)
+	if !ok {
+		return
+	}
+
+	files, ok := h.capture(c, []string{c.Param("name")}, duration)
+	if !ok {
+		return
+	}
+	file := files[0]
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", profileFilename(file.Name)))
+	c.Data(http.StatusOK, "application/octet-stream", file.Data)
+}
+
+// DownloadProfileBundle godoc
+// @Summary 下载性能分析包
+// @Description 同时采集多种性能分析并打包为tar.gz下载，cpu和trace同时采集指定秒数，其他类型在采集结束后快照
+// @Tags 管理
+// @Produce application/gzip
+// @Param profiles query string false "分析类型，逗号分隔" default(heap,goroutine,cpu)
+// @Param seconds query int false "采集秒数" default(5) minimum(1)
+// @Success 200 {file} file "tar.gz分析包"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 409 {object} v1.ErrorEnvelope "已有CPU分析或执行跟踪在进行"
+// @Router /admin/profiles [get]
+// @Security BearerAuth
+func (h *ProfileHandler) DownloadProfileBundle(c *gin.Context) {
+	req, duration, ok := h.bind(c)
+	if !ok {
+		return
+	}
+
+	names := pprof.DefaultProfiles
+	if req.Profiles != "" {
+		names = nil
+		for _, name := range strings.Split(req.Profiles, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	files, ok := h.capture(c, names, duration)
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := pprof.WriteBundle(&buf, files); err != nil {
+		logger.Error("Failed to bundle profiles: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", profileFilename("profiles.tar.gz")))
+	c.Data(http.StatusOK, "application/gzip", buf.Bytes())
+}
+
+// bind 解析查询参数和采集时长
+func (h *ProfileHandler) bind(c *gin.Context) (v1.ProfileRequest, time.Duration, bool) {
+	var req v1.ProfileRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		if validationErrors, ok := err.(validator.ValidationErrors); ok {
+			details := response.ParseValidationErrors(validationErrors)
+			response.ValidationError(c, details)
+		} else {
+			response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", err)
+		}
+		return req, 0, false
+	}
+
+	duration := defaultProfileDuration
+	if req.Seconds > 0 {
+		duration = time.Duration(req.Seconds) * time.Second
+	}
+	if h.maxDuration > 0 && duration > h.maxDuration {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter",
+			fmt.Errorf("seconds must be at most %d", int(h.maxDuration.Seconds())))
+		return req, 0, false
+	}
+	return req, duration, true
+}
+
+// capture 采集性能分析，采集期间延长响应的写超时
+func (h *ProfileHandler) capture(c *gin.Context, names []string, duration time.Duration) ([]pprof.ProfileFile, bool) {
+	_ = http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(duration + profileWriteGrace))
+
+	files, err := h.pprof.Capture(c.Request.Context(), pprof.CaptureOptions{Profiles: names, Duration: duration})
+	switch {
+	case err == nil:
+		return files, true
+	case errors.Is(err, pprof.ErrUnknownProfile):
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+	case errors.Is(err, pprof.ErrProfileInProgress):
+		response.Conflict(c, "conflict", err)
+	case errors.Is(err, context.Canceled):
+		// 客户端已断开
+		c.Abort()
+	default:
+		logger.Error("Failed to capture profiles: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+	return nil, false
+}
+
+// profileFilename 为下载文件名加上采集时间
+func profileFilename(name string) string {
+	base, ext, _ := strings.Cut(name, ".")
+	return fmt.Sprintf("%s-%s.%s", base, time.Now().UTC().Format("20060102T150405Z"), ext)
+}
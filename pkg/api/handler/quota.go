@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// QuotaHandler 配额管理处理器
+type QuotaHandler struct {
+	quotas    *quota.Manager
+	assembler *assembler.QuotaAssembler
+}
+
+// NewQuotaHandler 创建配额管理处理器
+func NewQuotaHandler(quotas *quota.Manager) *QuotaHandler {
+	return &QuotaHandler{
+		quotas:    quotas,
+		assembler: assembler.NewQuotaAssembler(),
+	}
+}
+
+// GetQuotaUsage godoc
+// @Summary 获取配额用量
+// @Description 获取主体在各配额等级当前自然日和自然月（UTC）内的请求数、限额和重置时间
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param principal path string true "配额主体，如 user:42 或 ip:203.0.113.7"
+// @Success 200 {object} v1.QuotaUsageResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/quotas/{principal} [get]
+// @Security BearerAuth
+func (h *QuotaHandler) GetQuotaUsage(c *gin.Context) {
+	principal := c.Param("principal")
+	usages, err := h.quotas.Usage(c.Request.Context(), principal)
+	if err != nil {
+		logger.Error("Failed to read quota usage: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+
+	response.Success(c, h.assembler.ToUsageResponse(principal, usages))
+}
+
+// ResetQuotaUsage godoc
+// @Summary 重置配额用量
+// @Description 清除主体在当前周期内的请求计数，可只重置一个配额等级，返回重置后的用量
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Param principal path string true "配额主体，如 user:42 或 ip:203.0.113.7"
+// @Param class query string false "只重置该配额等级，为空时重置全部等级"
+// @Success 200 {object} v1.QuotaUsageResponseEnvelope "重置成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /admin/quotas/{principal} [delete]
+// @Security BearerAuth
+func (h *QuotaHandler) ResetQuotaUsage(c *gin.Context) {
+	var req v1.ResetQuotaRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	ctx := c.Request.Context()
+	principal := c.Param("principal")
+	if err := h.quotas.Reset(ctx, principal, req.Class); err != nil {
+		logger.Error("Failed to reset quota usage: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+	logger.Info("Quota usage of %s reset, class: %q", principal, req.Class)
+
+	usages, err := h.quotas.Usage(ctx, principal)
+	if err != nil {
+		logger.Error("Failed to read quota usage: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		return
+	}
+	response.WithMessage(c, h.assembler.ToUsageResponse(principal, usages), "quota_reset")
+}
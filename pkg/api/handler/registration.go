@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// RegistrationHandler 注册处理器，按 registration 配置的注册策略创建用户
+type RegistrationHandler struct {
+	userService service.UserServiceInterface
+	// sessions 为注册的用户创建会话，未启用会话时为nil
+	sessions  *SessionHandler
+	assembler *assembler.UserAssembler
+}
+
+// NewRegistrationHandler 创建注册处理器，sessions 为nil时注册不签发会话令牌
+func NewRegistrationHandler(userService service.UserServiceInterface, sessions *SessionHandler) *RegistrationHandler {
+	return &RegistrationHandler{
+		userService: userService,
+		sessions:    sessions,
+		assembler:   assembler.NewUserAssembler(),
+	}
+}
+
+// Register godoc
+// @Summary 注册
+// @Description 以邮箱和密码注册用户，无需认证。公开注册时邮箱域名须在 registration.allowed_domains 中，以默认角色注册，并向邮箱发送验证令牌，返回202；用户验证邮箱后才能登录。重复公开注册未验证的邮箱时替换其密码并重新发送验证令牌。以邀请令牌注册时使用邀请预设的角色和组织。已验证的用户按邮箱域名加入 registration.tenants 配置的组织；启用会话时创建会话并返回其令牌
+// @Tags 注册
+// @Accept json
+// @Produce json
+// @Param request body v1.RegisterRequest true "注册信息"
+// @Success 201 {object} v1.RegisterResponseEnvelope "注册成功"
+// @Success 202 {object} v1.RegisterResponseEnvelope "已发送验证邮件"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或邀请令牌无效"
+// @Failure 403 {object} v1.ErrorEnvelope "注册策略不允许"
+// @Failure 409 {object} v1.ErrorEnvelope "邮箱已注册或邀请已被接受"
+// @Failure 410 {object} v1.ErrorEnvelope "邀请已过期"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /auth/register [post]
+func (h *RegistrationHandler) Register(c *gin.Context) {
+	var req v1.RegisterRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.RegisterUser(c.Request.Context(), h.assembler.ToRegistration(&req))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	result := v1.RegisterResponse{User: *h.assembler.ToResponse(user)}
+	if !user.Verified() {
+		response.Accepted(c, result, "verification_mailed")
+		return
+	}
+	if !h.startSession(c, user, &result) {
+		return
+	}
+
+	response.Created(c, result, "user_registered")
+}
+
+// VerifyEmail godoc
+// @Summary 验证邮箱
+// @Description 以公开注册时邮件中的令牌验证邮箱，无需认证。验证后用户按邮箱域名加入 registration.tenants 配置的组织；启用会话时创建会话并返回其令牌
+// @Tags 注册
+// @Accept json
+// @Produce json
+// @Param request body v1.VerifyEmailRequest true "邮箱验证令牌"
+// @Success 200 {object} v1.RegisterResponseEnvelope "验证成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误或验证令牌无效"
+// @Failure 410 {object} v1.ErrorEnvelope "验证令牌已过期"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /auth/register/verify [post]
+func (h *RegistrationHandler) VerifyEmail(c *gin.Context) {
+	var req v1.VerifyEmailRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	user, err := h.userService.VerifyEmail(c.Request.Context(), req.Token)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	result := v1.RegisterResponse{User: *h.assembler.ToResponse(user)}
+	if !h.startSession(c, user, &result) {
+		return
+	}
+
+	response.WithMessage(c, result, "email_verified")
+}
+
+// startSession 启用会话时为用户创建会话，令牌写入result；失败时写入错误响应并返回false
+func (h *RegistrationHandler) startSession(c *gin.Context, user *model.User, result *v1.RegisterResponse) bool {
+	if h.sessions == nil {
+		return true
+	}
+	var err error
+	result.Tokens, err = h.sessions.StartSession(c, middleware.JWTClaims{
+		UserID:      user.UserID(),
+		Username:    user.Username,
+		Role:        user.Role,
+		Permissions: user.Permissions,
+	})
+	if err != nil {
+		h.handleError(c, err)
+		return false
+	}
+	return true
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *RegistrationHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrUserExists):
+		response.Error(c, http.StatusConflict, response.CodeEmailExists, "user_email_exists", err)
+	case errors.Is(err, model.ErrUserEmailInvalid), errors.Is(err, model.ErrUserUsernameTooLong):
+		response.Error(c, http.StatusBadRequest, response.CodeValidationError, "user_invalid", err)
+	case errors.Is(err, model.ErrUserPasswordTooShort):
+		response.Error(c, http.StatusBadRequest, response.CodePasswordTooWeak, "user_password_too_short", err)
+	case errors.Is(err, model.ErrRegistrationInvitationRequired):
+		response.Error(c, http.StatusForbidden, response.CodeRegistrationInvitationRequired, "registration_invitation_required", err)
+	case errors.Is(err, model.ErrRegistrationDomainNotAllowed):
+		response.Error(c, http.StatusForbidden, response.CodeRegistrationDomainNotAllowed, "registration_domain_not_allowed", err)
+	case errors.Is(err, model.ErrRegistrationInvitationMismatched):
+		response.Error(c, http.StatusForbidden, response.CodeRegistrationInvitationMismatched, "registration_invitation_mismatched", err)
+	case errors.Is(err, model.ErrInvitationTokenInvalid), errors.Is(err, model.ErrInvitationNotFound):
+		response.Error(c, http.StatusBadRequest, response.CodeInvitationTokenInvalid, "invitation_token_invalid", err)
+	case errors.Is(err, model.ErrInvitationExpired):
+		response.Error(c, http.StatusGone, response.CodeInvitationExpired, "invitation_expired", err)
+	case errors.Is(err, model.ErrRegistrationTokenInvalid):
+		response.Error(c, http.StatusBadRequest, response.CodeRegistrationTokenInvalid, "registration_token_invalid", err)
+	case errors.Is(err, model.ErrRegistrationTokenExpired):
+		response.Error(c, http.StatusGone, response.CodeRegistrationTokenExpired, "registration_token_expired", err)
+	case errors.Is(err, model.ErrInvitationNotPending):
+		response.Error(c, http.StatusConflict, response.CodeInvitationNotPending, "invitation_not_pending", err)
+	default:
+		logger.Error("Registration failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/retention"
+)
+
+// RetentionHandler 数据保留处理器
+type RetentionHandler struct {
+	retention *retention.Manager
+	assembler *assembler.AdminAssembler
+}
+
+// NewRetentionHandler 创建数据保留处理器
+func NewRetentionHandler(manager *retention.Manager) *RetentionHandler {
+	return &RetentionHandler{
+		retention: manager,
+		assembler: assembler.NewAdminAssembler(),
+	}
+}
+
+// PreviewRetention godoc
+// @Summary 预览数据保留
+// @Description 统计各保留策略当前过期的记录数，即下次运行将删除的记录，不删除任何数据；统计失败的策略带有错误信息
+// @Tags 管理
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.RetentionPreviewResponseEnvelope "获取成功"
+// @Failure 403 {object} v1.ErrorEnvelope "权限不足"
+// @Router /admin/retention [get]
+// @Security BearerAuth
+func (h *RetentionHandler) PreviewRetention(c *gin.Context) {
+	reports := h.retention.Preview(c.Request.Context())
+	response.Success(c, h.assembler.ToRetentionPreviewResponse(h.retention.Enabled(), h.retention.Interval(), reports))
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/api/schema"
+)
+
+// SchemaHandler 文档类型处理器，由DTO结构体生成JSON Schema和示例，供消费者驱动的契约测试使用
+type SchemaHandler struct {
+	types map[string]interface{}
+}
+
+// NewSchemaHandler 创建文档类型处理器，types 为按名称注册的DTO结构体
+func NewSchemaHandler(types map[string]interface{}) *SchemaHandler {
+	return &SchemaHandler{types: types}
+}
+
+// ListTypes godoc
+// @Summary 获取文档类型列表
+// @Description 列出提供JSON Schema和示例的DTO类型名称
+// @Tags 文档
+// @Produce json
+// @Success 200 {object} v1.SchemaTypesResponseEnvelope "获取成功"
+// @Router /_schema [get]
+func (h *SchemaHandler) ListTypes(c *gin.Context) {
+	names := make([]string, 0, len(h.types))
+	for name := range h.types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	response.Success(c, v1.SchemaTypesResponse{Types: names})
+}
+
+// GetType godoc
+// @Summary 获取文档类型的模式
+// @Description 返回DTO类型的JSON Schema（2020-12）及由example标签生成的示例数据；请求类型中binding:"required"的字段必填，其他类型中总会输出的字段必填
+// @Tags 文档
+// @Produce json
+// @Param type path string true "类型名称" example(ApplicationResponse)
+// @Success 200 {object} v1.SchemaResponseEnvelope "获取成功"
+// @Failure 404 {object} v1.ErrorEnvelope "类型不存在"
+// @Router /_schema/{type} [get]
+func (h *SchemaHandler) GetType(c *gin.Context) {
+	name := c.Param("type")
+	dto, ok := h.types[name]
+	if !ok {
+		response.Error(c, http.StatusNotFound, response.CodeSchemaTypeNotFound, "schema_type_not_found", nil)
+		return
+	}
+
+	response.Success(c, v1.SchemaResponse{
+		Name:    name,
+		Schema:  schema.Generate(dto),
+		Example: schema.Example(dto),
+	})
+}
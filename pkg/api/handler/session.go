@@ -0,0 +1,212 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	assembler "github.com/make-bin/server-tpl/pkg/api/assembler/v1"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// SessionHandler 会话处理器，用户查看自己在各设备上的登录会话并远程终止，客户端以刷新令牌续期访问令牌
+type SessionHandler struct {
+	sessionService service.SessionServiceInterface
+	cfg            *config.SecurityConfig
+	clock          clock.Clock
+	assembler      *assembler.SessionAssembler
+}
+
+// NewSessionHandler 创建会话处理器
+func NewSessionHandler(sessionService service.SessionServiceInterface, cfg *config.SecurityConfig, clk clock.Clock) *SessionHandler {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &SessionHandler{
+		sessionService: sessionService,
+		cfg:            cfg,
+		clock:          clk,
+		assembler:      assembler.NewSessionAssembler(),
+	}
+}
+
+// StartSession 为通过认证的用户创建会话并签发其访问令牌和刷新令牌，供登录处理器在校验凭证后调用；
+// 会话记录当前请求的User-Agent和客户端IP
+func (h *SessionHandler) StartSession(c *gin.Context, claims middleware.JWTClaims) (*v1.SessionTokensResponse, error) {
+	session, refreshToken, err := h.sessionService.CreateSession(c.Request.Context(), &model.Session{
+		UserID:      claims.UserID,
+		Username:    claims.Username,
+		Role:        claims.Role,
+		Permissions: claims.Permissions,
+		UserAgent:   c.Request.UserAgent(),
+		IP:          c.ClientIP(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return h.issue(session, refreshToken)
+}
+
+// ListSessions godoc
+// @Summary 获取我的会话
+// @Description 获取当前用户未过期的登录会话，按最近访问时间倒序，current标记当前请求使用的会话
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.SessionResponseListEnvelope "获取成功"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证或会话已终止"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/sessions [get]
+// @Security BearerAuth
+func (h *SessionHandler) ListSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+
+	sessions, err := h.sessionService.ListSessions(c.Request.Context(), user.UserID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	current, _ := service.ParseSessionTokenID(user.SessionID)
+	response.Success(c, h.assembler.ToResponseList(sessions, current))
+}
+
+// DeleteSession godoc
+// @Summary 终止会话
+// @Description 终止当前用户的一个会话：其刷新令牌立即失效，已签发的访问令牌在过期前被拒绝。可终止当前会话以退出登录
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Param id path string true "会话ID或公开ID（UUID）" example(12)
+// @Success 204 "终止成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证或会话已终止"
+// @Failure 404 {object} v1.ErrorEnvelope "会话不存在"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/sessions/{id} [delete]
+// @Security BearerAuth
+func (h *SessionHandler) DeleteSession(c *gin.Context) {
+	userID, ok := currentUserID(c)
+	if !ok {
+		return
+	}
+	id, err := strconv.ParseUint(c.Param("id"), 10, 0)
+	if err != nil {
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", err)
+		return
+	}
+
+	if err := h.sessionService.RevokeSession(c.Request.Context(), userID, uint(id)); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.NoContent(c)
+}
+
+// DeleteOtherSessions godoc
+// @Summary 终止其他会话
+// @Description 终止当前用户除当前会话以外的所有会话，用于在其他设备上退出登录。请求需使用会话签发的访问令牌
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Success 200 {object} v1.RevokeSessionsResponseEnvelope "终止成功"
+// @Failure 400 {object} v1.ErrorEnvelope "请求未使用会话令牌"
+// @Failure 401 {object} v1.ErrorEnvelope "未认证或会话已终止"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /users/me/sessions [delete]
+// @Security BearerAuth
+func (h *SessionHandler) DeleteOtherSessions(c *gin.Context) {
+	user, ok := currentUser(c)
+	if !ok {
+		return
+	}
+	current, ok := service.ParseSessionTokenID(user.SessionID)
+	if !ok {
+		h.handleError(c, model.ErrCurrentSessionUnavailable)
+		return
+	}
+
+	revoked, err := h.sessionService.RevokeOtherSessions(c.Request.Context(), user.UserID, current)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, v1.RevokeSessionsResponse{Revoked: revoked}, "sessions_revoked")
+}
+
+// Refresh godoc
+// @Summary 刷新会话
+// @Description 以刷新令牌换取新的访问令牌和刷新令牌，原刷新令牌随即失效；已失效的刷新令牌再次使用时视为泄露，整个会话被终止
+// @Tags 会话
+// @Accept json
+// @Produce json
+// @Param request body v1.RefreshSessionRequest true "刷新令牌"
+// @Success 200 {object} v1.SessionTokensResponseEnvelope "刷新成功"
+// @Failure 400 {object} v1.ErrorEnvelope "参数错误"
+// @Failure 401 {object} v1.ErrorEnvelope "刷新令牌无效或已过期"
+// @Failure 500 {object} v1.ErrorEnvelope "服务器内部错误"
+// @Router /auth/refresh [post]
+func (h *SessionHandler) Refresh(c *gin.Context) {
+	var req v1.RefreshSessionRequest
+	if !bindJSON(c, &req) {
+		return
+	}
+
+	session, refreshToken, err := h.sessionService.RefreshSession(c.Request.Context(), req.RefreshToken, c.Request.UserAgent(), c.ClientIP())
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+	tokens, err := h.issue(session, refreshToken)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	response.WithMessage(c, tokens, "session_refreshed")
+}
+
+// issue 签发会话的访问令牌
+func (h *SessionHandler) issue(session *model.Session, refreshToken string) (*v1.SessionTokensResponse, error) {
+	claims := middleware.JWTClaims{
+		UserID:      session.UserID,
+		Username:    session.Username,
+		Role:        session.Role,
+		Permissions: session.Permissions,
+	}
+	accessToken, expiresAt, err := middleware.IssueAccessToken(h.cfg, h.clock, claims, service.SessionTokenID(session.ID))
+	if err != nil {
+		return nil, err
+	}
+	return h.assembler.ToTokensResponse(session, accessToken, expiresAt, refreshToken), nil
+}
+
+// handleError 将领域错误映射为HTTP响应
+func (h *SessionHandler) handleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, model.ErrSessionNotFound):
+		response.Error(c, http.StatusNotFound, response.CodeSessionNotFound, "session_not_found", err)
+	case errors.Is(err, model.ErrCurrentSessionUnavailable):
+		response.Error(c, http.StatusBadRequest, response.CodeInvalidToken, "session_unavailable", err)
+	case errors.Is(err, model.ErrRefreshTokenInvalid):
+		response.Error(c, http.StatusUnauthorized, response.CodeInvalidToken, "refresh_token_invalid", err)
+	case errors.Is(err, model.ErrSessionExpired):
+		response.Error(c, http.StatusUnauthorized, response.CodeRefreshTokenExpired, "refresh_token_expired", err)
+	default:
+		logger.Error("Session operation failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+	}
+}
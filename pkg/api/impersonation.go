@@ -0,0 +1,61 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// impersonation 支持依赖注入的模拟登录API结构
+type impersonation struct {
+	Config      *config.Config               `inject:"config"`
+	UserService service.UserServiceInterface `inject:""`
+	Analytics   analytics.Sink               `inject:"analytics"`
+	Clock       clock.Clock                  `inject:"clock"`
+	handler     *handler.ImpersonationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newImpersonation())
+}
+
+// newImpersonation 创建依赖注入版本的模拟登录API
+func newImpersonation() APIInterface {
+	return &impersonation{}
+}
+
+// Module 所属的认证模块，模块禁用时不注册路由
+func (a *impersonation) Module() string {
+	return module.Auth
+}
+
+// RoutePolicies 仅 security.impersonation.roles 中的角色可以模拟其他用户
+func (a *impersonation) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	return map[string]middleware.RoutePolicy{
+		"POST /impersonations": {Roles: a.Config.Security.Impersonation.Roles},
+	}
+}
+
+// InitAPIServiceRoute 初始化模拟登录API路由，未启用时不注册路由
+func (a *impersonation) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	a.handler = handler.NewImpersonationHandler(&a.Config.Security, a.UserService, a.Analytics, a.Clock)
+
+	rg.POST("/impersonations", a.handler.Impersonate)
+}
+
+// enabled 判断是否启用模拟登录
+func (a *impersonation) enabled() bool {
+	return a.Config != nil && a.Config.Security.Impersonation.Enabled && a.Analytics != nil
+}
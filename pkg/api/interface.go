@@ -3,32 +3,89 @@ package api
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// API versions served under /api/{version}
+const (
+	V1 = "v1"
+	V2 = "v2"
 )
 
 var registeredAPIInterfaces []APIInterface
 
+// registeredVersions versions served by each registered APIInterface, by index
+var registeredVersions [][]string
+
 var registerValidationInterfaces map[string]validator.Func
 
 type APIInterface interface {
 	InitAPIServiceRoute(rg *gin.RouterGroup)
 }
 
-// RegisterAPIInterface register APIInterface
-func RegisterAPIInterface(api APIInterface) {
+// RoutePolicyProvider is implemented by APIInterfaces whose routes differ from the
+// default policy (authenticated, CSRF protected, default rate limit). Keys are
+// "METHOD /path" relative to the version route group, e.g. "GET /applications/health".
+type RoutePolicyProvider interface {
+	RoutePolicies() map[string]middleware.RoutePolicy
+}
+
+// CacheInvalidationProvider is implemented by APIInterfaces whose routes cache
+// responses under CacheTags of their route policies. Keys are domain event
+// types, values the tags the events invalidate, e.g.
+// "application.deleted": {"applications"}.
+type CacheInvalidationProvider interface {
+	CacheInvalidations() map[string][]string
+}
+
+// UndocumentedRoutesProvider is implemented by APIInterfaces whose routes are
+// described by their own schema instead of swag annotations, e.g. GraphQL.
+// Paths are relative to the version route group, e.g. "/graphql"; all methods
+// of them are skipped by the route documentation check.
+type UndocumentedRoutesProvider interface {
+	UndocumentedRoutes() []string
+}
+
+// RegisterAPIInterface register APIInterface for the given versions, v1 when none is given.
+// InitAPIServiceRoute is called once with the route group of each version.
+func RegisterAPIInterface(api APIInterface, versions ...string) {
+	if len(versions) == 0 {
+		versions = []string{V1}
+	}
 	registeredAPIInterfaces = append(registeredAPIInterfaces, api)
+	registeredVersions = append(registeredVersions, versions)
 }
 
 func GetRegisterAPIInterfaces() []APIInterface {
 	return registeredAPIInterfaces
 }
 
-// InitAPI convert APIinterface to beans type
+// GetVersionAPIInterfaces returns the APIInterfaces registered for a version,
+// leaving out those of disabled modules
+func GetVersionAPIInterfaces(version string) []APIInterface {
+	var apis []APIInterface
+	for i, versions := range registeredVersions {
+		if !module.Includes(registeredAPIInterfaces[i]) {
+			continue
+		}
+		for _, v := range versions {
+			if v == version {
+				apis = append(apis, registeredAPIInterfaces[i])
+				break
+			}
+		}
+	}
+	return apis
+}
+
+// InitAPI convert APIinterface to beans type, leaving out those of disabled modules
 func InitAPI() []interface{} {
 	var beans []interface{}
 	for i := range registeredAPIInterfaces {
 		beans = append(beans, registeredAPIInterfaces[i])
 	}
-	return beans
+	return module.Filter(beans)
 }
 
 // RegisterValidationInterface register validation function
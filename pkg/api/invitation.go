@@ -0,0 +1,77 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// invitation 支持依赖注入的邀请API结构
+type invitation struct {
+	Config            *config.Config                     `inject:"config"`
+	Clock             clock.Clock                        `inject:"clock"`
+	InvitationService service.InvitationServiceInterface `inject:""`
+	UserService       service.UserServiceInterface       `inject:""`
+	SessionService    service.SessionServiceInterface    `inject:""`
+	handler           *handler.InvitationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newInvitation())
+}
+
+// newInvitation 创建依赖注入版本的邀请API
+func newInvitation() APIInterface {
+	return &invitation{}
+}
+
+// Module 所属的认证模块，模块禁用时不注册路由
+func (a *invitation) Module() string {
+	return module.Auth
+}
+
+// RoutePolicies 接受邀请以邀请令牌认证，无需访问令牌；令牌在请求体中而非Cookie中，无需CSRF防护
+func (a *invitation) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	return map[string]middleware.RoutePolicy{
+		"POST /invitations/accept": {Public: true, CSRFExempt: true},
+	}
+}
+
+// InitAPIServiceRoute 初始化邀请API路由，未启用时不注册路由；接受邀请时创建或认证被邀请用户，启用会话时签发会话令牌
+func (a *invitation) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	var sessions *handler.SessionHandler
+	if a.Config.Security.Sessions.Enabled && a.SessionService != nil {
+		sessions = handler.NewSessionHandler(a.SessionService, &a.Config.Security, a.Clock)
+	}
+	a.handler = handler.NewInvitationHandler(a.InvitationService, a.UserService, sessions, a.Clock)
+
+	invitationGroup := rg.Group("/invitations", middleware.PublicIDMiddleware("id", a.resolveInvitationID, model.ErrInvitationNotFound, "invitation_not_found"))
+	{
+		invitationGroup.GET("", a.handler.ListInvitations)
+		invitationGroup.POST("", a.handler.CreateInvitation)
+		invitationGroup.DELETE("/:id", a.handler.RevokeInvitation)
+		invitationGroup.POST("/accept", a.handler.AcceptInvitation)
+	}
+}
+
+// resolveInvitationID 返回公开ID对应的邀请ID
+func (a *invitation) resolveInvitationID(c *gin.Context, publicID string) (uint, error) {
+	return a.InvitationService.ResolveInvitationID(c.Request.Context(), publicID)
+}
+
+// enabled 判断是否启用邀请
+func (a *invitation) enabled() bool {
+	return a.Config != nil && a.Config.Invitations.Enabled && a.InvitationService != nil && a.UserService != nil
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// me 支持依赖注入的当前用户API结构
+type me struct {
+	NotificationService service.NotificationServiceInterface   `inject:""`
+	PreferenceService   service.UserPreferenceServiceInterface `inject:""`
+	handler             *handler.MeHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newMe())
+}
+
+// newMe 创建依赖注入版本的当前用户API
+func newMe() APIInterface {
+	return &me{}
+}
+
+// InitAPIServiceRoute 初始化当前用户API路由，路由作用于当前认证用户
+func (a *me) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.NotificationService == nil || a.PreferenceService == nil {
+		return
+	}
+	a.handler = handler.NewMeHandler(a.NotificationService, a.PreferenceService)
+
+	meGroup := rg.Group("/users/me")
+	{
+		meGroup.GET("", a.handler.GetMe)
+		meGroup.GET("/permissions", a.handler.GetMyPermissions)
+		meGroup.GET("/preferences", a.handler.GetMyPreferences)
+		meGroup.PATCH("/preferences", a.handler.UpdateMyPreferences)
+		meGroup.DELETE("/preferences", a.handler.ResetMyPreferences)
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+// AccessLog returns a middleware that records every served request in the
+// analytics sink. Recording only queues the entry, it does not wait for the
+// sink to store it.
+func AccessLog(sink analytics.Sink, clk clock.Clock) gin.HandlerFunc {
+	if clk == nil {
+		clk = clock.New()
+	}
+
+	return func(c *gin.Context) {
+		start := clk.Now()
+		c.Next()
+
+		entry := &analytics.Entry{
+			Kind:      analytics.KindAccess,
+			Timestamp: start,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			Route:     c.FullPath(),
+			Status:    c.Writer.Status(),
+			Duration:  clk.Now().Sub(start),
+			ClientIP:  c.ClientIP(),
+			UserAgent: c.Request.UserAgent(),
+		}
+		if requestID, exists := c.Get("request_id"); exists {
+			entry.RequestID = fmt.Sprintf("%v", requestID)
+		}
+		if userID, exists := c.Get(principal.KeyUserID); exists {
+			entry.UserID = fmt.Sprintf("%v", userID)
+		}
+		if impersonatorID, exists := c.Get(principal.KeyImpersonatorID); exists {
+			entry.ImpersonatorID = fmt.Sprintf("%v", impersonatorID)
+		}
+		sink.Record(c.Request.Context(), entry)
+	}
+}
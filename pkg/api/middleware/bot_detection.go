@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/botdetect"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// 人机验证请求头
+const (
+	// ChallengeTokenHeader 客户端完成人机验证后携带的令牌
+	ChallengeTokenHeader = "X-Challenge-Token"
+	// ChallengeProviderHeader 需要人机验证时返回的验证提供方，客户端据此展示对应的验证组件
+	ChallengeProviderHeader = "X-Challenge-Provider"
+)
+
+// BotDetectionMiddleware 人机识别中间件，按请求头和客户端IP的请求频率为请求评分。
+// 评分达到拦截阈值的请求返回403；路由策略声明了 Challenge 的高风险路由在评分达到验证阈值时，
+// 需携带 X-Challenge-Token 通过人机验证，通过后该IP在一段时间内不再需要验证。
+// 签名路由由合作方服务端调用，不参与评分；监控模式下只记录日志不拦截
+func BotDetectionMiddleware(detector *botdetect.Detector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := CurrentRoutePolicy(c)
+		if policy.Signed {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		assessment := detector.Assess(c.Request.Context(), c.Request, ip, policy.Challenge)
+		if assessment.Action == botdetect.ActionAllow {
+			c.Next()
+			return
+		}
+
+		fields := map[string]interface{}{
+			logger.FieldMethod: c.Request.Method,
+			logger.FieldPath:   c.Request.URL.Path,
+			logger.FieldIP:     ip,
+			"action":           assessment.Action,
+			"score":            assessment.Score,
+			"signals":          assessment.Signals,
+		}
+		if detector.Monitor() {
+			logger.WithContext(c.Request.Context()).WithFields(fields).Info("Request would be challenged or blocked by bot detection")
+			c.Next()
+			return
+		}
+
+		if assessment.Action == botdetect.ActionBlock {
+			logger.WithContext(c.Request.Context()).WithFields(fields).Warn("Request blocked by bot detection")
+			response.Error(c, http.StatusForbidden, response.CodeBotDetected, "bot_detected", fmt.Errorf("request looks automated"))
+			c.Abort()
+			return
+		}
+
+		if passChallenge(c, detector, ip, fields) {
+			c.Next()
+		}
+	}
+}
+
+// passChallenge 校验 X-Challenge-Token 中的人机验证令牌，未携带或未通过时返回403并中止请求。
+// 验证提供方不可用时放行，避免其故障导致登录和注册不可用
+func passChallenge(c *gin.Context, detector *botdetect.Detector, ip string, fields map[string]interface{}) bool {
+	token := c.GetHeader(ChallengeTokenHeader)
+	if token == "" {
+		c.Header(ChallengeProviderHeader, detector.Provider())
+		response.Error(c, http.StatusForbidden, response.CodeChallengeRequired, "challenge_required",
+			fmt.Errorf("solve the %s challenge and send its token in the %s header", detector.Provider(), ChallengeTokenHeader))
+		c.Abort()
+		return false
+	}
+
+	if err := detector.VerifyChallenge(c.Request.Context(), token, ip); err != nil {
+		if !errors.Is(err, botdetect.ErrChallengeFailed) {
+			logger.Error("Challenge verification skipped: %v", err)
+			return true
+		}
+		logger.WithContext(c.Request.Context()).WithFields(fields).Warn("Challenge verification failed")
+		c.Header(ChallengeProviderHeader, detector.Provider())
+		response.Error(c, http.StatusForbidden, response.CodeChallengeFailed, "challenge_failed", err)
+		c.Abort()
+		return false
+	}
+	return true
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/sync/singleflight"
+)
+
+// 请求合并的结果
+const (
+	// coalesceExecuted 执行了处理器，响应可能被其他请求共享
+	coalesceExecuted = "executed"
+	// coalesceShared 等待并复用了另一个请求的响应
+	coalesceShared = "coalesced"
+	// coalesceFallback 另一个请求的响应无法共享，自行执行了处理器
+	coalesceFallback = "fallback"
+)
+
+// 合并的请求数，按路由和结果统计
+var coalescedRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_coalesced_requests_total",
+		Help: "Total number of coalescing API requests by route and result",
+	},
+	[]string{"route", "result"},
+)
+
+// coalescedResponse 执行处理器的请求记录的响应，shared为false时不可共享
+type coalescedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+	shared bool
+}
+
+// recordingWriter 在写给客户端的同时记录响应体，超出上限后不再记录，
+// 用于共享或缓存响应
+type recordingWriter struct {
+	gin.ResponseWriter
+	body     bytes.Buffer
+	limit    int64
+	overflow bool
+}
+
+// Write 实现io.Writer
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.record(data)
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString 实现io.StringWriter
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.record([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+// record 记录写出的响应体
+func (w *recordingWriter) record(data []byte) {
+	if w.overflow {
+		return
+	}
+	if w.limit > 0 && int64(w.body.Len()+len(data)) > w.limit {
+		w.overflow = true
+		w.body.Reset()
+		return
+	}
+	w.body.Write(data)
+}
+
+// CoalescingMiddleware 请求合并中间件：路由策略启用Coalesce时，同一路由、路径、查询参数、
+// 主体及Accept、Accept-Language和X-Response-Format请求头的并发GET请求只执行一次处理器，其余请求复用其状态码、
+// 处理器设置的响应头和响应体（包括其中的request_id）。需在认证之后执行，以便按用户区分。
+// 执行的请求被取消、panic或响应超过MaxBodySize时，等待的请求各自执行处理器
+func CoalescingMiddleware(cfg *config.CoalescingConfig) gin.HandlerFunc {
+	var group singleflight.Group
+	limit := int64(cfg.MaxBodySize)
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet || !CurrentRoutePolicy(c).Coalesce {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		executed := false
+		var recovered interface{}
+		result, _, _ := group.Do(coalescingKey(c), func() (interface{}, error) {
+			executed = true
+			return executeCoalesced(c, limit, &recovered), nil
+		})
+		if recovered != nil {
+			// 交由外层的恢复中间件处理
+			panic(recovered)
+		}
+		if executed {
+			coalescedRequestsTotal.WithLabelValues(route, coalesceExecuted).Inc()
+			return
+		}
+
+		resp := result.(*coalescedResponse)
+		if !resp.shared {
+			coalescedRequestsTotal.WithLabelValues(route, coalesceFallback).Inc()
+			c.Next()
+			return
+		}
+		coalescedRequestsTotal.WithLabelValues(route, coalesceShared).Inc()
+		header := c.Writer.Header()
+		for name, values := range resp.header {
+			header[name] = slices.Clone(values)
+		}
+		c.Writer.WriteHeader(resp.status)
+		_, _ = c.Writer.Write(resp.body)
+		c.Abort()
+	}
+}
+
+// executeCoalesced 执行后续处理器并记录可共享的响应，只记录处理器新增或修改的响应头，
+// 之前的中间件设置的响应头（如请求ID和配额）属于各个请求自己
+func executeCoalesced(c *gin.Context, limit int64, recovered *interface{}) (resp *coalescedResponse) {
+	before := c.Writer.Header().Clone()
+	writer := &recordingWriter{ResponseWriter: c.Writer, limit: limit}
+	c.Writer = writer
+	resp = &coalescedResponse{}
+	defer func() {
+		c.Writer = writer.ResponseWriter
+		if r := recover(); r != nil {
+			*recovered = r
+		}
+	}()
+
+	c.Next()
+
+	resp.status = writer.Status()
+	resp.header = changedHeader(before, writer.Header())
+	resp.body = writer.body.Bytes()
+	resp.shared = !writer.overflow && c.Request.Context().Err() == nil
+	return resp
+}
+
+// coalescingKey 返回可以合并的请求共同的键，响应格式决定响应体是否带外层包装
+func coalescingKey(c *gin.Context) string {
+	return fmt.Sprintf("%s\n%s?%s\n%s\n%d\n%s\n%s\n%s",
+		c.FullPath(),
+		c.Request.URL.Path,
+		c.Request.URL.Query().Encode(),
+		QuotaPrincipal(c),
+		c.GetUint(principal.KeyOrganizationID),
+		c.GetHeader("Accept"),
+		c.GetHeader("Accept-Language"),
+		c.GetHeader(response.FormatHeader),
+	)
+}
+
+// changedHeader 返回after中相对before新增或修改的响应头
+func changedHeader(before, after http.Header) http.Header {
+	changed := make(http.Header)
+	for name, values := range after {
+		if !slices.Equal(before[name], values) {
+			changed[name] = values
+		}
+	}
+	return changed
+}
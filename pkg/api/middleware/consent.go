@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ConsentChecker 查询用户尚未接受最新版本的政策，由政策服务实现
+type ConsentChecker interface {
+	PendingPolicies(ctx context.Context, userID string, names []string) ([]string, error)
+}
+
+// ConsentMiddleware 政策同意中间件，路由策略声明了 Consent 时，用户接受列出政策的最新版本之前返回403。
+// 需在JWT认证之后执行；未认证的请求不检查
+func ConsentMiddleware(checker ConsentChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		names := CurrentRoutePolicy(c).Consent
+		userID := c.GetString(principal.KeyUserID)
+		if len(names) == 0 || userID == "" {
+			c.Next()
+			return
+		}
+
+		pending, err := checker.PendingPolicies(c.Request.Context(), userID, names)
+		if err != nil {
+			// 无法确认用户已同意时拒绝访问
+			logger.Error("Policy consent check failed: %v", err)
+			response.InternalServerError(c, "internal_error", err)
+			c.Abort()
+			return
+		}
+		if len(pending) > 0 {
+			response.Error(c, http.StatusForbidden, response.CodePolicyConsentRequired, "policy_consent_required",
+				fmt.Errorf("the current version of these policies must be accepted: %s", strings.Join(pending, ", ")))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
 )
 
 // CORS returns a CORS middleware with default configuration
@@ -12,8 +13,8 @@ func CORS() gin.HandlerFunc {
 	config := cors.Config{
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Length", "Content-Type", "Authorization", "X-Requested-With", response.FormatHeader, ChallengeTokenHeader},
+		ExposeHeaders:    []string{"Content-Length", response.FormatHeader, response.TotalCountHeader, response.PageHeader, response.PageSizeHeader, response.TotalPagesHeader, RateLimitLimitHeader, RateLimitRemainingHeader, RateLimitResetHeader, "Retry-After", ChallengeProviderHeader},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}
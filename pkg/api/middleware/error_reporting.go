@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/utils/errors"
+)
+
+// ErrorReporting returns a middleware that reports panics and 5xx responses.
+// Panics are re-raised so that Recovery still renders the response.
+func ErrorReporting(reporter errorreport.Reporter) gin.HandlerFunc {
+	if reporter == nil {
+		reporter = errorreport.NewNoopReporter()
+	}
+
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				event := newErrorEvent(c)
+				event.Level = errorreport.LevelFatal
+				event.Panic = true
+				event.StatusCode = http.StatusInternalServerError
+				event.Message = fmt.Sprintf("panic: %v", recovered)
+				if err, ok := recovered.(error); ok {
+					event.Error = err
+				}
+				event.StackTrace = string(debug.Stack())
+				reporter.Report(c.Request.Context(), event)
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusInternalServerError {
+			return
+		}
+
+		event := newErrorEvent(c)
+		event.StatusCode = status
+		if value, exists := c.Get(response.ErrorContextKey); exists {
+			if err, ok := value.(error); ok {
+				event.Error = err
+			}
+		}
+		if event.Error == nil && len(c.Errors) > 0 {
+			event.Error = c.Errors.Last().Err
+		}
+		if event.Error != nil {
+			event.Message = event.Error.Error()
+			event.StackTrace = errors.GetStackTrace(event.Error)
+		} else {
+			event.Message = fmt.Sprintf("%s %s returned %d", event.Method, event.Route, status)
+		}
+
+		reporter.Report(c.Request.Context(), event)
+	}
+}
+
+// newErrorEvent builds an event populated with request metadata
+func newErrorEvent(c *gin.Context) *errorreport.Event {
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+
+	event := &errorreport.Event{
+		Level:  errorreport.LevelError,
+		Route:  route,
+		Method: c.Request.Method,
+	}
+	if requestID, exists := c.Get("request_id"); exists {
+		event.RequestID = fmt.Sprintf("%v", requestID)
+	}
+	if userID, exists := c.Get(principal.KeyUserID); exists {
+		event.UserID = fmt.Sprintf("%v", userID)
+	}
+	return event
+}
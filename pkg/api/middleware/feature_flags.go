@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+)
+
+// FeatureFlagMiddleware 特性开关中间件，将求值器与当前请求的用户/租户信息放入请求上下文
+// 需在认证中间件之后执行，处理器和服务可通过 featureflags.IsEnabled(ctx, key) 判断开关
+func FeatureFlagMiddleware(evaluator featureflags.Evaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := featureflags.WithEvaluator(c.Request.Context(), evaluator, EvaluationContext(c))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ExperimentMiddleware 实验分组中间件，将分组器放入请求上下文
+// 分组在首次调用 featureflags.Variant(ctx, key) 时才进行，并发布曝光事件
+func ExperimentMiddleware(assigner featureflags.Assigner) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := featureflags.WithAssigner(c.Request.Context(), assigner, EvaluationContext(c))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// EvaluationContext 从Gin上下文构建特性开关求值上下文
+func EvaluationContext(c *gin.Context) featureflags.EvaluationContext {
+	evalCtx := featureflags.EvaluationContext{}
+	if userID, exists := c.Get(principal.KeyUserID); exists {
+		evalCtx.UserID = fmt.Sprintf("%v", userID)
+	}
+	if tenantID, exists := c.Get("tenant_id"); exists {
+		evalCtx.TenantID = fmt.Sprintf("%v", tenantID)
+	}
+	return evalCtx
+}
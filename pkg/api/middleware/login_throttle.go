@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/botdetect"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/loginguard"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// maxLoginBody 读取登录请求体以获取用户名的上限，更大的请求体不解析用户名
+const maxLoginBody = 64 << 10
+
+// LoginThrottleMiddleware 登录防暴力破解中间件，路由策略声明了 Login 时按用户名和客户端IP统计失败次数，
+// 处理器以401响应表示凭证错误，2xx响应表示登录成功并清除该用户名的失败次数。
+// 失败次数较多时下次尝试需等待递增的时间，之后需通过人机验证（detector 为空时不要求），
+// 达到上限时锁定该用户名或IP，锁定期间返回429和Retry-After。用户名取自JSON请求体中配置的字段
+func LoginThrottleMiddleware(guard *loginguard.Guard, detector *botdetect.Detector) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentRoutePolicy(c).Login {
+			c.Next()
+			return
+		}
+
+		ip := c.ClientIP()
+		username := loginUsername(c, guard.UsernameFields())
+		status, err := guard.Check(c.Request.Context(), username, ip)
+		if err != nil {
+			// 计数存储不可用时放行，避免其故障导致无法登录
+			logger.Warn("Login throttle check skipped: %v", err)
+			c.Next()
+			return
+		}
+
+		fields := map[string]interface{}{
+			logger.FieldMethod: c.Request.Method,
+			logger.FieldPath:   c.Request.URL.Path,
+			logger.FieldIP:     ip,
+			"reason":           status.Reason(),
+		}
+		switch status.Reason() {
+		case loginguard.ReasonLocked:
+			logger.WithContext(c.Request.Context()).WithFields(fields).Warn("Login attempt rejected while locked out")
+			abortLoginThrottled(c, status.RetryAfter, response.CodeLoginLocked, "login_locked", fmt.Errorf("too many failed logins, try again later"))
+			return
+		case loginguard.ReasonDelayed:
+			abortLoginThrottled(c, status.RetryAfter, response.CodeLoginDelayed, "login_delayed", fmt.Errorf("too many failed logins, wait before retrying"))
+			return
+		case loginguard.ReasonChallengeRequired:
+			// 每次尝试都需要新的验证令牌，验证通过记录不适用于登录
+			if detector.Enabled() && detector.Provider() != "" && !passChallenge(c, detector, ip, fields) {
+				return
+			}
+		}
+
+		c.Next()
+
+		switch code := c.Writer.Status(); {
+		case code == http.StatusUnauthorized:
+			err = guard.RecordFailure(c.Request.Context(), username, ip)
+		case code >= 200 && code < 300:
+			err = guard.RecordSuccess(c.Request.Context(), username)
+		}
+		if err != nil {
+			logger.Warn("Failed to record login attempt: %v", err)
+		}
+	}
+}
+
+// abortLoginThrottled 返回429、Retry-After和错误码并中止请求
+func abortLoginThrottled(c *gin.Context, retryAfter time.Duration, code int, messageKey string, err error) {
+	c.Header("Retry-After", strconv.FormatInt(int64(math.Ceil(retryAfter.Seconds())), 10))
+	response.Error(c, http.StatusTooManyRequests, code, messageKey, err)
+	c.Abort()
+}
+
+// loginUsername 从JSON请求体的 fields 字段中取第一个非空的用户名并规范化，读取后恢复请求体供处理器读取
+func loginUsername(c *gin.Context, fields []string) string {
+	if c.Request.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxLoginBody+1))
+	c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+	if err != nil || len(body) > maxLoginBody {
+		return ""
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal(body, &values); err != nil {
+		return ""
+	}
+	for _, field := range fields {
+		if username, ok := values[field].(string); ok {
+			if normalized := loginguard.NormalizeUsername(username); normalized != "" {
+				return normalized
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// OrganizationParam 指定当前组织的路径参数名
+const OrganizationParam = "org_id"
+
+// OrgMemberships 查询用户在组织中的成员身份，由组织服务实现；不是成员时返回 model.ErrOrganizationMemberNotFound，
+// 公开ID不存在时返回 model.ErrOrganizationNotFound
+type OrgMemberships interface {
+	GetMembership(ctx context.Context, orgID uint, userID string) (*model.OrganizationMember, error)
+	ResolveOrganizationID(ctx context.Context, publicID string) (uint, error)
+}
+
+// OrganizationMiddleware 组织中间件，从 org_id 路径参数或header请求头解析当前组织，值为组织ID或公开ID，
+// 用户不是其成员或组织不存在时返回403；通过后当前组织设置到gin上下文和请求上下文，服务只访问该组织的资源。
+// 路由策略声明了 OrgRole 时，未指定组织返回400，用户角色低于 OrgRole 返回403。
+// 需在JWT认证之后执行；未认证的请求不解析
+func OrganizationMiddleware(memberships OrgMemberships, header string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		required := CurrentRoutePolicy(c).OrgRole
+		user, ok := principal.CurrentUser(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		value := c.Param(OrganizationParam)
+		if value == "" {
+			value = c.GetHeader(header)
+		}
+		if value == "" {
+			if required != "" {
+				response.Error(c, http.StatusBadRequest, response.CodeOrganizationRequired, "organization_required",
+					fmt.Errorf("the organization must be given in the %s header", header))
+				c.Abort()
+				return
+			}
+			c.Next()
+			return
+		}
+
+		orgID, err := organizationID(c.Request.Context(), memberships, value)
+		if err == nil && orgID == 0 {
+			response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter",
+				fmt.Errorf("invalid organization ID %q", value))
+			c.Abort()
+			return
+		}
+
+		var member *model.OrganizationMember
+		if err == nil {
+			member, err = memberships.GetMembership(c.Request.Context(), orgID, user.UserID)
+		}
+		if err != nil {
+			if errors.Is(err, model.ErrOrganizationMemberNotFound) || errors.Is(err, model.ErrOrganizationNotFound) {
+				response.Error(c, http.StatusForbidden, response.CodeOrganizationAccessDenied, "organization_access_denied", err)
+			} else {
+				logger.Error("Organization membership check failed: %v", err)
+				response.InternalServerError(c, "internal_error", err)
+			}
+			c.Abort()
+			return
+		}
+		if required != "" && !model.OrganizationRoleAtLeast(member.Role, required) {
+			response.Error(c, http.StatusForbidden, response.CodeOrganizationAccessDenied, "organization_role_insufficient",
+				fmt.Errorf("the %s role is required in the organization", required))
+			c.Abort()
+			return
+		}
+
+		principal.SetOrganization(c, orgID, member.Role)
+		ctx := model.WithOrganization(c.Request.Context(), orgID)
+		if subject, ok := model.SubjectFromContext(ctx); ok {
+			subject.OrgID, subject.OrgRole = orgID, member.Role
+			ctx = model.WithSubject(ctx, subject)
+		}
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// organizationID 返回组织ID或公开ID对应的组织ID，value无效时返回0
+func organizationID(ctx context.Context, memberships OrgMemberships, value string) (uint, error) {
+	if model.IsPublicID(value) {
+		return memberships.ResolveOrganizationID(ctx, value)
+	}
+	id, err := strconv.ParseUint(value, 10, 0)
+	if err != nil {
+		return 0, nil
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitNone 不限流的限流等级
+const RateLimitNone = "none"
+
+// QuotaNone 不计入配额的配额等级
+const QuotaNone = "none"
+
+// 路由优先级，过载时低优先级请求先被丢弃
+const (
+	PriorityLow    = "low"
+	PriorityNormal = ""
+	// PriorityCritical 从不丢弃，也不计入负载，用于健康检查和长连接
+	PriorityCritical = "critical"
+)
+
+// JSON请求体中未知字段的处理方式，空为 StrictJSONMiddleware 设置的全局方式
+const (
+	UnknownFieldsReject = "reject"
+	UnknownFieldsAllow  = "allow"
+)
+
+// routePolicyKey 当前路由策略在上下文中的键
+const routePolicyKey = "route_policy"
+
+// RoutePolicy 路由的安全策略，零值为默认策略：需要认证、受CSRF保护并使用默认限流和默认配额
+type RoutePolicy struct {
+	// Public 无需认证；携带有效令牌时仍设置用户信息，无效令牌被忽略
+	Public bool
+	// Roles 允许访问的角色，设置后路由总是需要认证
+	Roles []string
+	// RateLimit 限流等级，对应 security.rate_limit_classes 中的名称，none 表示不限流，空为默认限流
+	RateLimit string
+	// CSRFExempt 不进行CSRF检查，用于以令牌而非Cookie认证的机器客户端
+	CSRFExempt bool
+	// Quota 配额等级，对应 quota.classes 中的名称，none 表示不计配额，空为 default 等级
+	Quota string
+	// Priority 过载时的优先级，见 PriorityLow 和 PriorityCritical，空为普通优先级
+	Priority string
+	// Coalesce 合并相同的并发GET请求，只执行一次处理器，用于开销大且频繁访问的只读路由
+	Coalesce bool
+	// CacheTTL 启用响应缓存时GET响应的缓存时间，0为不缓存
+	CacheTTL time.Duration
+	// CacheTags 缓存响应所属的分组，见 CacheInvalidationProvider，任一分组失效时响应失效
+	CacheTags []string
+	// Consent 访问前需接受最新版本的政策名称，如 terms；尚未发布的政策不要求接受
+	Consent []string
+	// Signed 需要合作方的HMAC请求签名，见 SignatureMiddleware；签名校验通过的请求无需JWT令牌
+	Signed bool
+	// Challenge 高风险路由（如登录、注册），风险评分达到 security.bot_detection.challenge_score 时需通过人机验证，见 BotDetectionMiddleware
+	Challenge bool
+	// Login 登录等校验凭证的路由，按用户名和客户端IP限制失败次数，处理器以401表示凭证错误，见 LoginThrottleMiddleware
+	Login bool
+	// OrgRole 启用组织时，路由需要当前组织，且用户在其中的角色不低于该角色，如 member 或 admin，见 OrganizationMiddleware
+	OrgRole string
+	// UnknownFields JSON请求体中DTO没有的字段，reject 以校验错误拒绝，allow 忽略，空为全局设置，见 RejectsUnknownFields
+	UnknownFields string
+	// Markup 可以包含HTML标记的查询参数，InputValidationMiddleware 不检查，由请求DTO的 sanitize 标签清理
+	Markup []string
+}
+
+// RoutePolicies 按请求方法和路由模板保存的路由策略，在路由初始化期间设置
+type RoutePolicies struct {
+	policies map[string]RoutePolicy
+}
+
+// NewRoutePolicies 创建路由策略表
+func NewRoutePolicies() *RoutePolicies {
+	return &RoutePolicies{policies: make(map[string]RoutePolicy)}
+}
+
+// Set 设置路由的策略，path为完整的路由模板，如 /api/v1/applications/:id
+func (p *RoutePolicies) Set(method, path string, policy RoutePolicy) {
+	p.policies[method+" "+path] = policy
+}
+
+// Get 返回路由的策略，未设置时返回默认策略
+func (p *RoutePolicies) Get(method, path string) RoutePolicy {
+	return p.policies[method+" "+path]
+}
+
+// Routes 返回设置了策略的路由（"METHOD /完整路径"），按字母顺序排列
+func (p *RoutePolicies) Routes() []string {
+	routes := make([]string, 0, len(p.policies))
+	for route := range p.policies {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+// RoutePolicyMiddleware 查找当前路由的策略并放入上下文，需在认证、限流和CSRF中间件之前执行
+func RoutePolicyMiddleware(policies *RoutePolicies) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(routePolicyKey, policies.Get(c.Request.Method, c.FullPath()))
+		c.Next()
+	}
+}
+
+// CurrentRoutePolicy 返回当前路由的策略，没有经过 RoutePolicyMiddleware 时返回默认策略
+func CurrentRoutePolicy(c *gin.Context) RoutePolicy {
+	if policy, ok := c.Get(routePolicyKey); ok {
+		return policy.(RoutePolicy)
+	}
+	return RoutePolicy{}
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// PreferenceResolver 返回用户填充默认值后的偏好设置，由用户偏好服务实现
+type PreferenceResolver interface {
+	EffectivePreferences(ctx context.Context, userID string) (*model.UserPreferences, error)
+}
+
+// PreferencesMiddleware 用户偏好中间件，为已认证用户应用其偏好的语言和时区，
+// 请求的翻译器和本地化器随之使用；lang查询参数仍优先于偏好语言
+func PreferencesMiddleware(resolver PreferenceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := principal.CurrentUser(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		prefs, err := resolver.EffectivePreferences(c.Request.Context(), user.UserID)
+		if err != nil {
+			// 偏好不可用时按请求头检测语言，不影响请求本身
+			logger.Warn("Failed to load preferences of user %s: %v", user.UserID, err)
+			c.Next()
+			return
+		}
+		i18n.SetPreferences(c, prefs.Language, prefs.TimeZone)
+
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// PublicIDResolver 返回公开ID对应的内部ID，由资源的服务实现
+type PublicIDResolver func(c *gin.Context, publicID string) (uint, error)
+
+// PublicIDMiddleware 公开ID中间件，路径参数 param 为UUID形式的公开ID时替换为 resolve 返回的内部ID，
+// 处理器只解析数字ID；数字ID原样传递。resolve 返回的错误匹配 notFound 时返回404，消息为 messageKey
+func PublicIDMiddleware(param string, resolve PublicIDResolver, notFound error, messageKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value := c.Param(param)
+		if !model.IsPublicID(value) {
+			c.Next()
+			return
+		}
+
+		id, err := resolve(c, value)
+		if err != nil {
+			if errors.Is(err, notFound) {
+				response.NotFound(c, messageKey, err)
+			} else {
+				logger.Error("Failed to resolve public ID %s: %v", value, err)
+				response.InternalServerError(c, "internal_error", err)
+			}
+			c.Abort()
+			return
+		}
+
+		for i := range c.Params {
+			if c.Params[i].Key == param {
+				c.Params[i].Value = strconv.FormatUint(uint64(id), 10)
+			}
+		}
+		c.Next()
+	}
+}
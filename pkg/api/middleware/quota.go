@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// 配额响应头
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+// QuotaMiddleware 按主体统计路由配额等级的每日和每月请求数，超出配额返回429和Retry-After。
+// 需在JWT认证之后执行，以便按用户而非IP计数
+func QuotaMiddleware(manager *quota.Manager) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		class := CurrentRoutePolicy(c).Quota
+		if class == QuotaNone {
+			c.Next()
+			return
+		}
+
+		principal := QuotaPrincipal(c)
+		windows, err := manager.Consume(c.Request.Context(), class, principal)
+		if err != nil {
+			// 配额存储不可用时放行，避免其故障导致整个API不可用
+			logger.Warn("Quota check skipped: %v", err)
+			c.Next()
+			return
+		}
+		if len(windows) == 0 {
+			c.Next()
+			return
+		}
+
+		window := headerWindow(windows)
+		c.Header(RateLimitLimitHeader, strconv.FormatInt(window.Limit, 10))
+		c.Header(RateLimitRemainingHeader, strconv.FormatInt(window.Remaining(), 10))
+		c.Header(RateLimitResetHeader, strconv.FormatInt(window.Reset.Unix(), 10))
+
+		if window.Exceeded() {
+			retryAfter := int64(time.Until(window.Reset).Seconds()) + 1
+			c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+			logger.Warn("Quota %s exceeded for principal: %s", window.Period, principal)
+			response.TooManyRequests(c, "quota_exceeded", fmt.Errorf("%s请求配额已用尽", window.Period))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// QuotaPrincipal 返回请求计入配额的主体：已认证用户为 user:<id>，签名校验通过的合作方为 partner:<名称>，否则为 ip:<客户端IP>
+func QuotaPrincipal(c *gin.Context) string {
+	if userID := c.GetString(principal.KeyUserID); userID != "" {
+		return "user:" + userID
+	}
+	if partner := CurrentPartner(c); partner != "" {
+		return "partner:" + partner
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// headerWindow 选择写入响应头的周期：超限时为最晚重置的超限周期，否则为剩余最少的周期
+func headerWindow(windows []quota.Window) quota.Window {
+	var exceeded *quota.Window
+	for i := range windows {
+		if windows[i].Exceeded() && (exceeded == nil || windows[i].Reset.After(exceeded.Reset)) {
+			exceeded = &windows[i]
+		}
+	}
+	if exceeded != nil {
+		return *exceeded
+	}
+
+	selected := windows[0]
+	for _, window := range windows[1:] {
+		if window.Remaining() < selected.Remaining() {
+			selected = window
+		}
+	}
+	return selected
+}
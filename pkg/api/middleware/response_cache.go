@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/httpcache"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// CacheStatusHeader 响应是否来自缓存：HIT、MISS或BYPASS
+const CacheStatusHeader = "X-Cache"
+
+// 响应缓存的结果
+const (
+	cacheHit    = "hit"
+	cacheMiss   = "miss"
+	cacheBypass = "bypass"
+)
+
+// cacheVary 缓存响应所区分的请求头，用户和租户由认证令牌决定
+const cacheVary = "Authorization, Accept, Accept-Language, " + response.FormatHeader
+
+// 响应缓存的请求数，按路由和结果统计
+var responseCacheRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_response_cache_requests_total",
+		Help: "Total number of cacheable API requests by route and result",
+	},
+	[]string{"route", "result"},
+)
+
+// ResponseCacheMiddleware 响应缓存中间件：路由策略设置CacheTTL时缓存GET请求的200响应，
+// 按路由、路径、查询参数、用户、租户及Accept、Accept-Language和X-Response-Format请求头区分，
+// 响应带Cache-Control、Vary、Age和X-Cache头。响应按路由策略的CacheTags分组，
+// 由领域事件或Invalidate使其失效。请求头Cache-Control为no-cache时不读取缓存，
+// 为no-store时既不读取也不写入。需在认证之后执行
+func ResponseCacheMiddleware(cache *httpcache.Cache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		policy := CurrentRoutePolicy(c)
+		if c.Request.Method != http.MethodGet || policy.CacheTTL <= 0 {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		directives := strings.ToLower(c.GetHeader("Cache-Control"))
+		if strings.Contains(directives, "no-store") {
+			responseCacheRequestsTotal.WithLabelValues(route, cacheBypass).Inc()
+			c.Header(CacheStatusHeader, "BYPASS")
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		key, err := cache.Key(ctx, responseCacheKey(c), policy.CacheTags)
+		if err != nil {
+			// 缓存存储不可用时直接处理，避免其故障导致路由不可用
+			logger.Warn("Response cache skipped: %v", err)
+			responseCacheRequestsTotal.WithLabelValues(route, cacheBypass).Inc()
+			c.Next()
+			return
+		}
+
+		cacheControl := "private"
+		if c.GetString(principal.KeyUserID) == "" {
+			cacheControl = "public"
+		}
+		c.Header("Cache-Control", fmt.Sprintf("%s, max-age=%d", cacheControl, int(policy.CacheTTL.Seconds())))
+		c.Header("Vary", cacheVary)
+
+		if !strings.Contains(directives, "no-cache") {
+			entry, err := cache.Get(ctx, key)
+			if err != nil {
+				logger.Warn("Failed to read cached response: %v", err)
+			}
+			if entry != nil {
+				responseCacheRequestsTotal.WithLabelValues(route, cacheHit).Inc()
+				header := c.Writer.Header()
+				for name, values := range entry.Header {
+					header[name] = values
+				}
+				age := int(cache.Now().Sub(entry.StoredAt).Seconds())
+				c.Header("Age", strconv.Itoa(max(age, 0)))
+				c.Header(CacheStatusHeader, "HIT")
+				c.Writer.WriteHeader(entry.Status)
+				_, _ = c.Writer.Write(entry.Body)
+				c.Abort()
+				return
+			}
+		}
+
+		responseCacheRequestsTotal.WithLabelValues(route, cacheMiss).Inc()
+		c.Header(CacheStatusHeader, "MISS")
+		before := c.Writer.Header().Clone()
+		writer := &recordingWriter{ResponseWriter: c.Writer, limit: cache.MaxBodySize()}
+		c.Writer = writer
+		defer func() { c.Writer = writer.ResponseWriter }()
+
+		c.Next()
+
+		if writer.Status() != http.StatusOK || writer.overflow || ctx.Err() != nil || len(c.Errors) > 0 {
+			return
+		}
+		entry := &httpcache.Entry{
+			Status:   writer.Status(),
+			Header:   changedHeader(before, writer.Header()),
+			Body:     writer.body.Bytes(),
+			StoredAt: cache.Now(),
+		}
+		if err := cache.Set(ctx, key, entry, policy.CacheTTL); err != nil {
+			logger.Warn("Failed to cache response: %v", err)
+		}
+	}
+}
+
+// responseCacheKey 返回可以共用缓存响应的请求共同的键，匿名请求共用同一个主体
+func responseCacheKey(c *gin.Context) string {
+	subject := "anonymous"
+	if userID := c.GetString(principal.KeyUserID); userID != "" {
+		subject = "user:" + userID
+	}
+	// 同一用户在不同组织中看到的资源不同
+	if orgID := c.GetUint(principal.KeyOrganizationID); orgID != 0 {
+		subject += fmt.Sprintf("@org:%d", orgID)
+	}
+	tenant := ""
+	if tenantID, exists := c.Get("tenant_id"); exists {
+		tenant = fmt.Sprintf("%v", tenantID)
+	}
+	// 响应格式决定响应体是否带外层包装；响应中的时间按用户偏好的时区输出，偏好修改后不再命中旧的缓存
+	return fmt.Sprintf("%s\n%s?%s\n%s\n%s\n%s\n%s\n%s\n%s",
+		c.FullPath(),
+		c.Request.URL.Path,
+		c.Request.URL.Query().Encode(),
+		subject,
+		tenant,
+		c.GetHeader("Accept"),
+		c.GetHeader("Accept-Language"),
+		c.GetHeader(response.FormatHeader),
+		i18n.DetectTimeZone(c),
+	)
+}
@@ -1,12 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"regexp"
 	"strconv"
@@ -15,7 +17,11 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
 	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 	"golang.org/x/time/rate"
 )
@@ -26,31 +32,13 @@ type JWTClaims struct {
 	Username    string   `json:"username"`
 	Role        string   `json:"role"`
 	Permissions []string `json:"permissions"`
+	// Impersonator 模拟令牌中代为操作的用户，普通令牌为nil
+	Impersonator *Impersonator `json:"act,omitempty"`
+	// SessionID 签发令牌的会话，会话终止后令牌被拒绝；不属于会话的令牌为空
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// SecurityConfig 安全配置
-type SecurityConfig struct {
-	JWTSecret        string   `json:"jwt_secret"`
-	RateLimitRPS     int      `json:"rate_limit_rps"`
-	RateLimitBurst   int      `json:"rate_limit_burst"`
-	MaxFileSize      int64    `json:"max_file_size"`
-	AllowedFileTypes []string `json:"allowed_file_types"`
-	CSRFEnabled      bool     `json:"csrf_enabled"`
-	EncryptionKey    string   `json:"encryption_key"`
-}
-
-// DefaultSecurityConfig 默认安全配置
-var DefaultSecurityConfig = &SecurityConfig{
-	JWTSecret:        "your-secret-key",
-	RateLimitRPS:     100,
-	RateLimitBurst:   200,
-	MaxFileSize:      10 * 1024 * 1024, // 10MB
-	AllowedFileTypes: []string{"image/jpeg", "image/png", "image/gif", "application/pdf"},
-	CSRFEnabled:      true,
-	EncryptionKey:    "your-encryption-key-32-characters",
-}
-
 // SecurityHeadersMiddleware 安全响应头中间件
 func SecurityHeadersMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -79,18 +67,20 @@ func SecurityHeadersMiddleware() gin.HandlerFunc {
 	}
 }
 
-// JWTAuthMiddleware JWT认证中间件
-func JWTAuthMiddleware(config *SecurityConfig) gin.HandlerFunc {
+// JWTAuthMiddleware JWT认证中间件，令牌的过期时间按clk判断
+func JWTAuthMiddleware(cfg *config.SecurityConfig, clk clock.Clock) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 跳过某些路径
-		if isSkipPath(c.Request.URL.Path) {
-			c.Next()
-			return
-		}
+		// 公开路由、跳过的路径和签名校验通过的合作方请求无需认证，限定角色的路由总是需要认证
+		policy := CurrentRoutePolicy(c)
+		public := len(policy.Roles) == 0 && (policy.Public || isSkipPath(c.Request.URL.Path) || CurrentPartner(c) != "")
 
 		// 从请求头获取token
 		token := c.GetHeader("Authorization")
 		if token == "" {
+			if public {
+				c.Next()
+				return
+			}
 			response.Unauthorized(c, "unauthorized", fmt.Errorf("未提供认证令牌"))
 			c.Abort()
 			return
@@ -102,18 +92,51 @@ func JWTAuthMiddleware(config *SecurityConfig) gin.HandlerFunc {
 		}
 
 		// 验证JWT token
-		claims, err := validateJWTToken(token, config.JWTSecret)
+		claims, err := validateJWTToken(token, cfg.JWTSecret, clk)
 		if err != nil {
+			if public {
+				c.Next()
+				return
+			}
 			response.Unauthorized(c, "invalid_token", err)
 			c.Abort()
 			return
 		}
 
-		// 将用户信息设置到上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("user_role", claims.Role)
-		c.Set("user_permissions", claims.Permissions)
-		c.Set("username", claims.Username)
+		// 未启用模拟登录时不接受模拟令牌，公开路由也不例外，以免模拟请求未被审计
+		if claims.Impersonator != nil && !cfg.Impersonation.Enabled {
+			response.Unauthorized(c, "invalid_token", fmt.Errorf("模拟登录未启用"))
+			c.Abort()
+			return
+		}
+
+		// 将用户信息设置到上下文，处理器通过 principal.CurrentUser 读取
+		user := principal.Principal{
+			UserID:      claims.UserID,
+			Username:    claims.Username,
+			Role:        claims.Role,
+			Permissions: claims.Permissions,
+			SessionID:   claims.SessionID,
+		}
+
+		// 请求上下文携带用户ID，供日志和领域服务（如修订记录）使用；授权主体供服务检查访问策略
+		ctx := context.WithValue(c.Request.Context(), logger.FieldUserID, claims.UserID)
+		ctx = model.WithSubject(ctx, model.Subject{UserID: claims.UserID, Role: claims.Role})
+		if claims.Impersonator != nil {
+			// 模拟请求同时携带代为操作的用户ID
+			user.ImpersonatorID = claims.Impersonator.UserID
+			c.Set("impersonator", claims.Impersonator)
+			ctx = context.WithValue(ctx, logger.FieldImpersonatorID, claims.Impersonator.UserID)
+		}
+		principal.Set(c, user)
+		c.Request = c.Request.WithContext(ctx)
+
+		// 路由策略限定的角色
+		if len(policy.Roles) > 0 && !containsString(policy.Roles, claims.Role) {
+			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
+			c.Abort()
+			return
+		}
 
 		c.Next()
 	}
@@ -122,65 +145,83 @@ func JWTAuthMiddleware(config *SecurityConfig) gin.HandlerFunc {
 // RequireRole 角色授权中间件
 func RequireRole(roles ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		userRole, exists := c.Get("user_role")
-		if !exists {
+		user, _ := principal.CurrentUser(c)
+		if !user.HasRole(roles...) {
 			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
 			c.Abort()
 			return
 		}
 
-		// 检查用户角色是否在允许的角色列表中
-		hasRole := false
-		for _, role := range roles {
-			if userRole == role {
-				hasRole = true
-				break
+		c.Next()
+	}
+}
+
+// AllowIPOrRole 来源IP在允许列表中，或携带指定角色的有效JWT时放行，
+// 用于挂载在API分组之外的诊断路由（如pprof）。允许列表项为IP或CIDR
+func AllowIPOrRole(cfg *config.SecurityConfig, clk clock.Clock, allowedIPs []string, roles ...string) gin.HandlerFunc {
+	networks := parseNetworks(allowedIPs)
+	return func(c *gin.Context) {
+		if ip := net.ParseIP(c.ClientIP()); ip != nil {
+			for _, network := range networks {
+				if network.Contains(ip) {
+					c.Next()
+					return
+				}
 			}
 		}
 
-		if !hasRole {
-			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if token == "" {
+			response.Unauthorized(c, "unauthorized", fmt.Errorf("未提供认证令牌"))
 			c.Abort()
 			return
 		}
-
-		c.Next()
-	}
-}
-
-// RequirePermission 权限授权中间件
-func RequirePermission(permissions ...string) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		userPermissions, exists := c.Get("user_permissions")
-		if !exists {
-			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
+		claims, err := validateJWTToken(token, cfg.JWTSecret, clk)
+		if err != nil {
+			response.Unauthorized(c, "invalid_token", err)
 			c.Abort()
 			return
 		}
-
-		// 检查用户权限
-		userPerms, ok := userPermissions.([]string)
-		if !ok {
+		// 诊断路由不经过模拟请求审计，不接受模拟令牌
+		if claims.Impersonator != nil || !containsString(roles, claims.Role) {
 			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
 			c.Abort()
 			return
 		}
 
-		// 检查是否有所需权限
-		hasPermission := false
-		for _, requiredPerm := range permissions {
-			for _, userPerm := range userPerms {
-				if userPerm == requiredPerm {
-					hasPermission = true
-					break
-				}
-			}
-			if hasPermission {
-				break
+		c.Set("user_id", claims.UserID)
+		c.Set("user_role", claims.Role)
+		c.Next()
+	}
+}
+
+// parseNetworks 解析IP或CIDR列表，单个IP视为只包含该地址的网段
+func parseNetworks(values []string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
 			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
 		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			logger.Warn("Ignoring invalid IP allowlist entry %q: %v", value, err)
+			continue
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
 
-		if !hasPermission {
+// RequirePermission 权限授权中间件
+func RequirePermission(permissions ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, _ := principal.CurrentUser(c)
+		if !user.HasPermission(permissions...) {
 			response.Forbidden(c, "permission_denied", fmt.Errorf("权限不足"))
 			c.Abort()
 			return
@@ -190,11 +231,25 @@ func RequirePermission(permissions ...string) gin.HandlerFunc {
 	}
 }
 
-// RateLimitMiddleware 限流中间件
-func RateLimitMiddleware(config *SecurityConfig) gin.HandlerFunc {
-	limiter := rate.NewLimiter(rate.Limit(config.RateLimitRPS), config.RateLimitBurst)
+// RateLimitMiddleware 限流中间件，路由策略可选择 security.rate_limit_classes 中的限流等级，未知的等级使用默认限流
+func RateLimitMiddleware(cfg *config.SecurityConfig) gin.HandlerFunc {
+	defaultLimiter := rate.NewLimiter(rate.Limit(cfg.RateLimitRPS), cfg.RateLimitBurst)
+	classLimiters := make(map[string]*rate.Limiter, len(cfg.RateLimitClasses))
+	for name, class := range cfg.RateLimitClasses {
+		classLimiters[name] = rate.NewLimiter(rate.Limit(class.RPS), class.Burst)
+	}
 
 	return func(c *gin.Context) {
+		class := CurrentRoutePolicy(c).RateLimit
+		if class == RateLimitNone {
+			c.Next()
+			return
+		}
+		limiter := defaultLimiter
+		if classLimiter, ok := classLimiters[class]; ok {
+			limiter = classLimiter
+		}
+
 		// 获取客户端标识
 		clientID := getClientID(c)
 
@@ -211,9 +266,11 @@ func RateLimitMiddleware(config *SecurityConfig) gin.HandlerFunc {
 }
 
 // CSRFMiddleware CSRF防护中间件
-func CSRFMiddleware(config *SecurityConfig) gin.HandlerFunc {
+func CSRFMiddleware(cfg *config.SecurityConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if !config.CSRFEnabled {
+		// 签名路由由合作方服务端调用，不使用Cookie
+		policy := CurrentRoutePolicy(c)
+		if !cfg.CSRFEnabled || policy.CSRFExempt || policy.Signed {
 			c.Next()
 			return
 		}
@@ -244,7 +301,7 @@ func CSRFMiddleware(config *SecurityConfig) gin.HandlerFunc {
 }
 
 // FileUploadSecurityMiddleware 文件上传安全中间件
-func FileUploadSecurityMiddleware(config *SecurityConfig) gin.HandlerFunc {
+func FileUploadSecurityMiddleware(cfg *config.SecurityConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		file, header, err := c.Request.FormFile("file")
 		if err != nil {
@@ -257,7 +314,7 @@ func FileUploadSecurityMiddleware(config *SecurityConfig) gin.HandlerFunc {
 		defer file.Close()
 
 		// 1. 检查文件大小
-		if header.Size > config.MaxFileSize {
+		if header.Size > int64(cfg.MaxFileSize) {
 			response.Error(c, http.StatusBadRequest, response.CodeFileTooBig, "file_too_big", fmt.Errorf("文件大小超过限制"))
 			c.Abort()
 			return
@@ -265,7 +322,7 @@ func FileUploadSecurityMiddleware(config *SecurityConfig) gin.HandlerFunc {
 
 		// 2. 检查文件类型
 		contentType := header.Header.Get("Content-Type")
-		if !isAllowedFileType(contentType, config.AllowedFileTypes) {
+		if !isAllowedFileType(contentType, cfg.AllowedFileTypes) {
 			response.Error(c, http.StatusBadRequest, response.CodeFileTypeNotSupported, "file_type_not_supported", fmt.Errorf("不支持的文件类型"))
 			c.Abort()
 			return
@@ -290,23 +347,32 @@ func FileUploadSecurityMiddleware(config *SecurityConfig) gin.HandlerFunc {
 	}
 }
 
-// InputValidationMiddleware 输入验证中间件
+// InputValidationMiddleware 输入验证中间件，逐一检查解码后的查询参数；路由策略的 Markup 参数不检查，
+// 由请求DTO的 sanitize 标签清理（见 sanitize.Struct）
 func InputValidationMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// 检查SQL注入
-		if containsSQLInjection(c.Request.URL.RawQuery) {
-			logger.Warn("SQL injection attempt detected from %s", getClientID(c))
-			response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("检测到非法参数"))
-			c.Abort()
-			return
-		}
+		markup := CurrentRoutePolicy(c).Markup
+		for name, values := range c.Request.URL.Query() {
+			if containsString(markup, name) {
+				continue
+			}
+			for _, value := range values {
+				// 检查SQL注入
+				if containsSQLInjection(value) {
+					logger.Warn("SQL injection attempt detected from %s", getClientID(c))
+					response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("检测到非法参数"))
+					c.Abort()
+					return
+				}
 
-		// 检查XSS攻击
-		if containsXSS(c.Request.URL.RawQuery) {
-			logger.Warn("XSS attempt detected from %s", getClientID(c))
-			response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("检测到非法参数"))
-			c.Abort()
-			return
+				// 检查XSS攻击
+				if containsXSS(value) {
+					logger.Warn("XSS attempt detected from %s", getClientID(c))
+					response.Error(c, http.StatusBadRequest, response.CodeInvalidParameter, "invalid_parameter", fmt.Errorf("检测到非法参数"))
+					c.Abort()
+					return
+				}
+			}
 		}
 
 		c.Next()
@@ -319,7 +385,6 @@ func InputValidationMiddleware() gin.HandlerFunc {
 func isSkipPath(path string) bool {
 	skipPaths := []string{
 		"/health",
-		"/api/v1/applications/health",
 		"/swagger",
 		"/metrics",
 	}
@@ -332,15 +397,25 @@ func isSkipPath(path string) bool {
 	return false
 }
 
-// validateJWTToken 验证JWT token
-func validateJWTToken(tokenString, secret string) (*JWTClaims, error) {
+// containsString 检查列表是否包含指定字符串
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// validateJWTToken 验证JWT token，过期和生效时间按clk判断
+func validateJWTToken(tokenString, secret string, clk clock.Clock) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
 		// 验证签名方法
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
 		return []byte(secret), nil
-	})
+	}, jwt.WithTimeFunc(clk.Now))
 
 	if err != nil {
 		return nil, err
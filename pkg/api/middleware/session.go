@@ -0,0 +1,92 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/denylist"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// SessionToucher 记录会话的最近访问时间和设备，由会话服务实现；会话已删除时返回 model.ErrSessionNotFound
+type SessionToucher interface {
+	TouchSession(ctx context.Context, id uint, userAgent, ip string) error
+}
+
+// IssueAccessToken 签发会话sessionID的访问令牌，有效期为 security.sessions.access_token_ttl，
+// 返回令牌及其过期时间
+func IssueAccessToken(cfg *config.SecurityConfig, clk clock.Clock, claims JWTClaims, sessionID string) (string, time.Time, error) {
+	now := clk.Now()
+	expiresAt := now.Add(cfg.Sessions.AccessTokenTTL)
+
+	claims.SessionID = sessionID
+	claims.Impersonator = nil
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   claims.UserID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign access token: %w", err)
+	}
+	return token, expiresAt, nil
+}
+
+// CurrentSessionID 返回访问令牌所属的会话，不属于会话的令牌返回空字符串
+func CurrentSessionID(c *gin.Context) string {
+	return c.GetString(principal.KeySessionID)
+}
+
+// SessionMiddleware 会话中间件，在JWT认证之后拒绝已终止会话的访问令牌，
+// 并记录会话的最近访问时间、User-Agent和客户端IP。不属于会话的令牌不受影响
+func SessionMiddleware(deny *denylist.Denylist, sessions SessionToucher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sid := CurrentSessionID(c)
+		if sid == "" {
+			c.Next()
+			return
+		}
+
+		denied, err := deny.Denied(c.Request.Context(), sid)
+		if err != nil {
+			// 拒绝列表不可用时放行，访问令牌的有效期较短
+			logger.Warn("Session denylist check skipped: %v", err)
+		}
+		if denied {
+			abortSessionRevoked(c)
+			return
+		}
+
+		if id, err := strconv.ParseUint(sid, 10, 0); err == nil && id > 0 {
+			if err := sessions.TouchSession(c.Request.Context(), uint(id), c.Request.UserAgent(), c.ClientIP()); err != nil {
+				if errors.Is(err, model.ErrSessionNotFound) {
+					abortSessionRevoked(c)
+					return
+				}
+				logger.Warn("Failed to record session activity: %v", err)
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// abortSessionRevoked 返回401并中止请求
+func abortSessionRevoked(c *gin.Context) {
+	response.Error(c, http.StatusUnauthorized, response.CodeSessionRevoked, "session_revoked", errors.New("session has been terminated"))
+	c.Abort()
+}
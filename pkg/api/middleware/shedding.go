@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// latencyWindow 统计平均延迟的时间窗口
+const latencyWindow = time.Second
+
+// 丢弃请求的原因
+const (
+	shedReasonInFlight = "in_flight"
+	shedReasonLatency  = "latency"
+)
+
+var (
+	// 被丢弃的请求数
+	loadShedRequestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_load_shed_requests_total",
+			Help: "Total number of API requests shed under load",
+		},
+		[]string{"priority", "reason"},
+	)
+
+	// 计入负载的并发请求数
+	loadSheddingInFlight = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "http_load_shedding_in_flight_requests",
+			Help: "Number of in-flight API requests counted for load shedding",
+		},
+	)
+
+	// 上一窗口的平均延迟
+	loadSheddingLatency = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "http_load_shedding_latency_seconds",
+			Help: "Average API request latency of the last window counted for load shedding",
+		},
+	)
+)
+
+// loadShedder 统计并发请求数和平均延迟，决定过载时是否丢弃请求
+type loadShedder struct {
+	maxInFlight int64
+	lowInFlight int64
+	maxLatency  time.Duration
+
+	inFlight atomic.Int64
+
+	mu          sync.Mutex
+	windowStart time.Time
+	windowSum   time.Duration
+	windowCount int64
+	latency     time.Duration
+}
+
+// LoadSheddingMiddleware 过载保护中间件：并发请求数超过阈值时丢弃普通和低优先级请求，
+// 接近阈值或平均延迟过高时先丢弃低优先级请求，返回503和Retry-After。关键路由从不丢弃
+func LoadSheddingMiddleware(cfg *config.LoadSheddingConfig) gin.HandlerFunc {
+	shedder := &loadShedder{
+		maxInFlight: int64(cfg.MaxInFlight),
+		lowInFlight: int64(math.Ceil(float64(cfg.MaxInFlight) * cfg.LowPriorityRatio)),
+		maxLatency:  cfg.MaxLatency,
+		windowStart: time.Now(),
+	}
+	retryAfter := strconv.Itoa(int(math.Max(1, math.Ceil(cfg.RetryAfter.Seconds()))))
+
+	return func(c *gin.Context) {
+		priority := CurrentRoutePolicy(c).Priority
+		if priority == PriorityCritical {
+			c.Next()
+			return
+		}
+
+		if reason := shedder.shed(priority); reason != "" {
+			loadShedRequestsTotal.WithLabelValues(priorityLabel(priority), reason).Inc()
+			c.Header("Retry-After", retryAfter)
+			response.Error(c, http.StatusServiceUnavailable, response.CodeServiceUnavailable, "service_overloaded", fmt.Errorf("服务繁忙，请稍后重试"))
+			c.Abort()
+			return
+		}
+
+		shedder.inFlight.Add(1)
+		loadSheddingInFlight.Inc()
+		start := time.Now()
+		defer func() {
+			shedder.inFlight.Add(-1)
+			loadSheddingInFlight.Dec()
+			shedder.observe(time.Since(start))
+		}()
+
+		c.Next()
+	}
+}
+
+// shed 返回丢弃请求的原因，不丢弃时为空
+func (s *loadShedder) shed(priority string) string {
+	inFlight := s.inFlight.Load()
+	if s.maxInFlight > 0 && inFlight >= s.maxInFlight {
+		return shedReasonInFlight
+	}
+	if priority != PriorityLow {
+		return ""
+	}
+	if s.maxInFlight > 0 && inFlight >= s.lowInFlight {
+		return shedReasonInFlight
+	}
+	if s.maxLatency > 0 && s.averageLatency() > s.maxLatency {
+		return shedReasonLatency
+	}
+	return ""
+}
+
+// observe 记录一个完成的请求的延迟
+func (s *loadShedder) observe(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll(time.Now())
+	s.windowSum += d
+	s.windowCount++
+}
+
+// averageLatency 返回上一窗口的平均延迟
+func (s *loadShedder) averageLatency() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roll(time.Now())
+	return s.latency
+}
+
+// roll 窗口结束时计算其平均延迟并开始新窗口；窗口内没有完成的请求时延迟为0，
+// 避免只有低优先级流量时因无新样本而一直丢弃。调用方需持有锁
+func (s *loadShedder) roll(now time.Time) {
+	if now.Sub(s.windowStart) < latencyWindow {
+		return
+	}
+	s.latency = 0
+	if s.windowCount > 0 {
+		s.latency = s.windowSum / time.Duration(s.windowCount)
+	}
+	loadSheddingLatency.Set(s.latency.Seconds())
+	s.windowStart, s.windowSum, s.windowCount = now, 0, 0
+}
+
+// priorityLabel 返回优先级的指标标签
+func priorityLabel(priority string) string {
+	if priority == PriorityNormal {
+		return "normal"
+	}
+	return priority
+}
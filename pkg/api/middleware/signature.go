@@ -0,0 +1,108 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/response"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/signing"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// PartnerSecrets 查询合作方当前有效的签名密钥，由合作方服务实现；
+// 合作方不存在时返回 model.ErrPartnerNotFound，已停用时返回 model.ErrPartnerDisabled
+type PartnerSecrets interface {
+	PartnerSecrets(ctx context.Context, name string) ([]string, error)
+}
+
+// SignatureMiddleware 请求签名中间件，路由策略声明了 Signed 时校验合作方的HMAC签名，
+// 签名覆盖请求方法、路径和查询参数、时间戳、随机数及请求体，时间戳超出允许偏差或随机数重复使用的请求被拒绝。
+// 校验通过后设置 partner_id，此后的JWT认证不再要求令牌。maxBody 为读取请求体的上限
+func SignatureMiddleware(verifier *signing.Verifier, partners PartnerSecrets, maxBody int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !CurrentRoutePolicy(c).Signed {
+			c.Next()
+			return
+		}
+
+		sig, err := signing.Parse(c.Request)
+		if err != nil {
+			abortSignature(c, err)
+			return
+		}
+
+		var body []byte
+		if c.Request.Body != nil {
+			body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxBody+1))
+			if err != nil {
+				response.Error(c, http.StatusBadRequest, response.CodeValidationError, "validation_error", fmt.Errorf("failed to read request body: %w", err))
+				c.Abort()
+				return
+			}
+			if int64(len(body)) > maxBody {
+				response.Error(c, http.StatusRequestEntityTooLarge, response.CodePayloadTooLarge, "payload_too_large",
+					fmt.Errorf("signed request body exceeds %d bytes", maxBody))
+				c.Abort()
+				return
+			}
+			// 处理器仍需读取请求体
+			c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		secrets, err := partners.PartnerSecrets(c.Request.Context(), sig.KeyID)
+		if err != nil {
+			if errors.Is(err, model.ErrPartnerNotFound) || errors.Is(err, model.ErrPartnerDisabled) {
+				// 不区分合作方不存在和签名错误，避免探测合作方名称
+				abortSignature(c, signing.ErrSignatureInvalid)
+				return
+			}
+			logger.Error("Failed to load secrets of partner %s: %v", sig.KeyID, err)
+			response.InternalServerError(c, "internal_error", err)
+			c.Abort()
+			return
+		}
+
+		if err := verifier.Verify(c.Request.Context(), c.Request, sig, body, secrets); err != nil {
+			abortSignature(c, err)
+			return
+		}
+
+		c.Set("partner_id", sig.KeyID)
+		c.Next()
+	}
+}
+
+// CurrentPartner 返回签名校验通过的合作方名称，未签名的请求返回空字符串
+func CurrentPartner(c *gin.Context) string {
+	return c.GetString("partner_id")
+}
+
+// abortSignature 按签名错误返回401，无法记录随机数时返回500（无法确认请求未被重放时拒绝访问）
+func abortSignature(c *gin.Context, err error) {
+	var code int
+	var key string
+	switch {
+	case errors.Is(err, signing.ErrSignatureMissing):
+		code, key = response.CodeSignatureMissing, "signature_missing"
+	case errors.Is(err, signing.ErrSignatureExpired):
+		code, key = response.CodeSignatureExpired, "signature_expired"
+	case errors.Is(err, signing.ErrNonceReused):
+		code, key = response.CodeSignatureReplayed, "signature_replayed"
+	case errors.Is(err, signing.ErrSignatureInvalid):
+		code, key = response.CodeSignatureInvalid, "signature_invalid"
+	default:
+		logger.Error("Request signature verification failed: %v", err)
+		response.InternalServerError(c, "internal_error", err)
+		c.Abort()
+		return
+	}
+	logger.Warn("Rejected signed request to %s: %v", c.Request.URL.Path, err)
+	response.Error(c, http.StatusUnauthorized, code, key, err)
+	c.Abort()
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import "github.com/gin-gonic/gin"
+
+// strictJSONKey 全局拒绝未知字段在上下文中的键
+const strictJSONKey = "strict_json"
+
+// StrictJSONMiddleware 全局拒绝JSON请求体中的未知字段，路由策略的 UnknownFields 可单独允许
+func StrictJSONMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(strictJSONKey, true)
+		c.Next()
+	}
+}
+
+// RejectsUnknownFields 判断当前路由是否拒绝JSON请求体中的未知字段：路由策略的 UnknownFields 优先，
+// 为空时取决于是否经过 StrictJSONMiddleware
+func RejectsUnknownFields(c *gin.Context) bool {
+	switch CurrentRoutePolicy(c).UnknownFields {
+	case UnknownFieldsReject:
+		return true
+	case UnknownFieldsAllow:
+		return false
+	}
+	return c.GetBool(strictJSONKey)
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersionHeader 响应中标明所用API版本的响应头
+const APIVersionHeader = "X-API-Version"
+
+// apiVersionKey 请求使用的API版本在上下文中的键
+const apiVersionKey = "api_version"
+
+// vendorMediaType 匹配带版本的厂商媒体类型
+var vendorMediaType = regexp.MustCompile(`^application/vnd\.server-tpl\.(v[0-9]+)\+json$`)
+
+// VersionPolicy API版本的弃用策略，零值表示未弃用
+type VersionPolicy struct {
+	// DeprecatedSince 弃用时间，通过Deprecation响应头（RFC 9745）告知客户端
+	DeprecatedSince time.Time
+	// Sunset 停止服务的时间，通过Sunset响应头（RFC 8594）告知客户端
+	Sunset time.Time
+	// Link 弃用说明或迁移文档的地址
+	Link string
+}
+
+// APIVersionMiddleware 标记请求使用的API版本，并为已弃用或计划下线的版本添加Deprecation、Sunset和Link响应头
+func APIVersionMiddleware(version string, policy VersionPolicy) gin.HandlerFunc {
+	var deprecation, sunset, link string
+	if !policy.DeprecatedSince.IsZero() {
+		deprecation = fmt.Sprintf("@%d", policy.DeprecatedSince.Unix())
+	}
+	if !policy.Sunset.IsZero() {
+		sunset = policy.Sunset.UTC().Format(http.TimeFormat)
+	}
+	if policy.Link != "" {
+		switch {
+		case deprecation != "":
+			link = fmt.Sprintf("<%s>; rel=\"deprecation\"", policy.Link)
+		case sunset != "":
+			link = fmt.Sprintf("<%s>; rel=\"sunset\"", policy.Link)
+		}
+	}
+
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Header(APIVersionHeader, version)
+		if deprecation != "" {
+			c.Header("Deprecation", deprecation)
+		}
+		if sunset != "" {
+			c.Header("Sunset", sunset)
+		}
+		if link != "" {
+			c.Writer.Header().Add("Link", link)
+		}
+		c.Next()
+	}
+}
+
+// APIVersion 返回请求使用的API版本，不在版本路由组中时为空
+func APIVersion(c *gin.Context) string {
+	return c.GetString(apiVersionKey)
+}
+
+// AcceptedVersion 从Accept请求头中解析API版本，支持 application/vnd.server-tpl.v2+json
+// 和 application/json; version=2 两种形式，没有指定版本时返回空
+func AcceptedVersion(accept string) string {
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+		if match := vendorMediaType.FindStringSubmatch(mediaType); match != nil {
+			return match[1]
+		}
+		if version := params["version"]; version != "" {
+			if !strings.HasPrefix(version, "v") {
+				version = "v" + version
+			}
+			return version
+		}
+	}
+	return ""
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/netacl"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// networkACL 网络访问控制管理API结构，未启用网络访问控制时不注册路由
+type networkACL struct {
+	ACL     *netacl.Manager `inject:"network_acl"`
+	handler *handler.NetworkACLHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newNetworkACL())
+}
+
+// newNetworkACL 创建依赖注入版本的网络访问控制管理API
+func newNetworkACL() APIInterface {
+	return &networkACL{}
+}
+
+// Module 所属的管理模块，模块禁用时不注册路由
+func (a *networkACL) Module() string {
+	return module.Admin
+}
+
+// RoutePolicies 网络访问控制管理仅限管理员
+func (a *networkACL) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.ACL.Enabled() {
+		return nil
+	}
+	admin := middleware.RoutePolicy{Roles: []string{"admin"}}
+	return map[string]middleware.RoutePolicy{
+		"GET /admin/network-acl":                 admin,
+		"PUT /admin/network-acl/global":          admin,
+		"PUT /admin/network-acl/groups/:name":    admin,
+		"DELETE /admin/network-acl/groups/:name": admin,
+	}
+}
+
+// InitAPIServiceRoute 初始化网络访问控制管理API路由
+func (a *networkACL) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.ACL.Enabled() {
+		return
+	}
+	a.handler = handler.NewNetworkACLHandler(a.ACL)
+
+	aclGroup := rg.Group("/admin/network-acl")
+	aclGroup.GET("", a.handler.GetNetworkACL)
+	aclGroup.PUT("/global", a.handler.UpdateGlobalRule)
+	aclGroup.PUT("/groups/:name", a.handler.UpdateGroupRule)
+	aclGroup.DELETE("/groups/:name", a.handler.DeleteGroupRule)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// notificationPreference 支持依赖注入的通知渠道偏好API结构
+type notificationPreference struct {
+	NotificationService service.NotificationServiceInterface `inject:""`
+	handler             *handler.NotificationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newNotificationPreference())
+}
+
+// newNotificationPreference 创建依赖注入版本的通知渠道偏好API
+func newNotificationPreference() APIInterface {
+	return &notificationPreference{}
+}
+
+// InitAPIServiceRoute 初始化通知渠道偏好API路由，路由作用于当前认证用户
+func (a *notificationPreference) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.NotificationService == nil {
+		return
+	}
+	a.handler = handler.NewNotificationHandler(a.NotificationService)
+
+	preferenceGroup := rg.Group("/notification-preferences")
+	{
+		preferenceGroup.GET("", a.handler.ListPreferences)
+		preferenceGroup.PUT("/:channel", a.handler.SetPreference)
+		preferenceGroup.DELETE("/:channel", a.handler.DeletePreference)
+
+		// 通过渠道发送测试通知
+		preferenceGroup.POST("/:channel/test", a.handler.TestPreference)
+	}
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// operation 支持依赖注入的任务API结构
+type operation struct {
+	OperationService      service.OperationServiceInterface      `inject:""`
+	OperationEventService service.OperationEventServiceInterface `inject:""`
+	handler               *handler.OperationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newOperation())
+}
+
+// newOperation 创建依赖注入版本的任务API
+func newOperation() APIInterface {
+	return &operation{}
+}
+
+// RoutePolicies 任务进度SSE为长连接，不计入过载保护的负载
+func (a *operation) RoutePolicies() map[string]middleware.RoutePolicy {
+	return map[string]middleware.RoutePolicy{
+		"GET /operations/:id/events": {Priority: middleware.PriorityCritical},
+	}
+}
+
+// InitAPIServiceRoute 初始化任务API路由
+func (a *operation) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.OperationService == nil || a.OperationEventService == nil {
+		return
+	}
+	a.handler = handler.NewOperationHandler(a.OperationService, a.OperationEventService)
+
+	operationGroup := rg.Group("/operations")
+	{
+		// 查询任务状态和结果
+		operationGroup.GET("", a.handler.ListOperations)
+		operationGroup.GET("/:id", a.handler.GetOperation)
+
+		// 以SSE推送任务进度
+		operationGroup.GET("/:id/events", a.handler.StreamOperationEvents)
+	}
+}
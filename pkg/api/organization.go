@@ -0,0 +1,71 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// organization 支持依赖注入的组织API结构
+type organization struct {
+	Config              *config.Config                       `inject:"config"`
+	OrganizationService service.OrganizationServiceInterface `inject:""`
+	handler             *handler.OrganizationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newOrganization())
+}
+
+// newOrganization 创建依赖注入版本的组织API
+func newOrganization() APIInterface {
+	return &organization{}
+}
+
+// RoutePolicies 组织路由按成员角色授权：成员可查看，管理员可管理成员和组织信息，所有者可删除组织
+func (a *organization) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	member := middleware.RoutePolicy{OrgRole: model.OrganizationRoleMember}
+	admin := middleware.RoutePolicy{OrgRole: model.OrganizationRoleAdmin}
+	return map[string]middleware.RoutePolicy{
+		"GET /organizations/:org_id":                     member,
+		"PUT /organizations/:org_id":                     admin,
+		"DELETE /organizations/:org_id":                  {OrgRole: model.OrganizationRoleOwner},
+		"GET /organizations/:org_id/members":             member,
+		"POST /organizations/:org_id/members":            admin,
+		"PUT /organizations/:org_id/members/:user_id":    admin,
+		"DELETE /organizations/:org_id/members/:user_id": admin,
+	}
+}
+
+// InitAPIServiceRoute 初始化组织API路由，未启用时不注册路由
+func (a *organization) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	a.handler = handler.NewOrganizationHandler(a.OrganizationService)
+
+	orgGroup := rg.Group("/organizations")
+	{
+		orgGroup.GET("", a.handler.ListOrganizations)
+		orgGroup.POST("", a.handler.CreateOrganization)
+		orgGroup.GET("/:org_id", a.handler.GetOrganization)
+		orgGroup.PUT("/:org_id", a.handler.UpdateOrganization)
+		orgGroup.DELETE("/:org_id", a.handler.DeleteOrganization)
+		orgGroup.GET("/:org_id/members", a.handler.ListMembers)
+		orgGroup.POST("/:org_id/members", a.handler.AddMember)
+		orgGroup.PUT("/:org_id/members/:user_id", a.handler.UpdateMember)
+		orgGroup.DELETE("/:org_id/members/:user_id", a.handler.RemoveMember)
+	}
+}
+
+// enabled 判断是否启用组织
+func (a *organization) enabled() bool {
+	return a.Config != nil && a.Config.Organizations.Enabled && a.OrganizationService != nil
+}
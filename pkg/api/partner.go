@@ -0,0 +1,66 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// partner 支持依赖注入的合作方API结构
+type partner struct {
+	PartnerService service.PartnerServiceInterface `inject:""`
+	handler        *handler.PartnerHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newPartner())
+}
+
+// newPartner 创建依赖注入版本的合作方API
+func newPartner() APIInterface {
+	return &partner{}
+}
+
+// Module 所属的管理模块，模块禁用时不注册路由
+func (a *partner) Module() string {
+	return module.Admin
+}
+
+// RoutePolicies 合作方管理仅限管理员；/partners 下的路由由合作方以HMAC签名调用，不使用JWT令牌
+func (a *partner) RoutePolicies() map[string]middleware.RoutePolicy {
+	admin := middleware.RoutePolicy{Roles: []string{"admin"}}
+	return map[string]middleware.RoutePolicy{
+		"GET /admin/partners":               admin,
+		"POST /admin/partners":              admin,
+		"GET /admin/partners/:name":         admin,
+		"PUT /admin/partners/:name":         admin,
+		"DELETE /admin/partners/:name":      admin,
+		"POST /admin/partners/:name/rotate": admin,
+		"GET /partners/me":                  {Signed: true},
+	}
+}
+
+// InitAPIServiceRoute 初始化合作方API路由
+func (a *partner) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.PartnerService == nil {
+		return
+	}
+	a.handler = handler.NewPartnerHandler(a.PartnerService)
+
+	adminGroup := rg.Group("/admin/partners")
+	{
+		adminGroup.GET("", a.handler.ListPartners)
+		adminGroup.POST("", a.handler.CreatePartner)
+		adminGroup.GET("/:name", a.handler.GetPartner)
+		adminGroup.PUT("/:name", a.handler.UpdatePartner)
+		adminGroup.DELETE("/:name", a.handler.DeletePartner)
+		adminGroup.POST("/:name/rotate", a.handler.RotatePartnerSecret)
+	}
+
+	// 合作方调用的签名路由
+	partnerGroup := rg.Group("/partners")
+	partnerGroup.GET("/me", a.handler.GetCurrentPartner)
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+// policy 支持依赖注入的政策API结构
+type policy struct {
+	PolicyService service.PolicyServiceInterface `inject:""`
+	handler       *handler.PolicyHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newPolicy())
+}
+
+// newPolicy 创建依赖注入版本的政策API
+func newPolicy() APIInterface {
+	return &policy{}
+}
+
+// RoutePolicies 政策文档公开，以便用户在注册或登录前阅读；发布政策需要管理员角色
+func (a *policy) RoutePolicies() map[string]middleware.RoutePolicy {
+	return map[string]middleware.RoutePolicy{
+		"GET /policies":                 {Public: true},
+		"GET /policies/:name":           {Public: true},
+		"POST /policies/:name/versions": {Roles: []string{"admin"}},
+	}
+}
+
+// InitAPIServiceRoute 初始化政策API路由
+func (a *policy) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.PolicyService == nil {
+		return
+	}
+	a.handler = handler.NewPolicyHandler(a.PolicyService)
+
+	policyGroup := rg.Group("/policies")
+	{
+		policyGroup.GET("", a.handler.ListPolicies)
+		policyGroup.GET("/:name", a.handler.GetPolicy)
+		policyGroup.POST("/:name/versions", a.handler.PublishPolicy)
+
+		// 当前用户的同意记录
+		policyGroup.GET("/consents", a.handler.ListConsents)
+		policyGroup.POST("/:name/accept", a.handler.AcceptPolicy)
+	}
+}
@@ -0,0 +1,411 @@
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// SchemaURL Postman集合v2.1格式，Insomnia等工具也可导入
+const SchemaURL = "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"
+
+// 集合与环境中的变量，请求以 {{baseUrl}} 和 {{token}} 引用
+const (
+	BaseURLVariable = "baseUrl"
+	TokenVariable   = "token"
+)
+
+// maxExampleDepth 生成请求体示例时展开嵌套类型的最大深度，避免循环引用
+const maxExampleDepth = 8
+
+// methods 按此顺序生成同一路径的请求
+var methods = []string{"get", "post", "put", "patch", "delete", "head", "options"}
+
+// Collection Postman集合
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []Item     `json:"item"`
+	Auth     *Auth      `json:"auth,omitempty"`
+	Variable []Variable `json:"variable,omitempty"`
+}
+
+// Info 集合信息
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Schema      string `json:"schema"`
+}
+
+// Item 集合中的请求，或包含请求的文件夹
+type Item struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Item        []Item   `json:"item,omitempty"`
+	Request     *Request `json:"request,omitempty"`
+}
+
+// Request 请求
+type Request struct {
+	Method      string   `json:"method"`
+	Header      []Header `json:"header"`
+	URL         URL      `json:"url"`
+	Body        *Body    `json:"body,omitempty"`
+	Auth        *Auth    `json:"auth,omitempty"` // 为空时使用集合的认证
+	Description string   `json:"description,omitempty"`
+}
+
+// Header 请求头
+type Header struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// URL 请求地址，路径参数以 :name 表示
+type URL struct {
+	Raw      string       `json:"raw"`
+	Host     []string     `json:"host"`
+	Path     []string     `json:"path"`
+	Query    []QueryParam `json:"query,omitempty"`
+	Variable []Variable   `json:"variable,omitempty"`
+}
+
+// QueryParam 查询参数，可选参数默认不启用
+type QueryParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// Body 请求体，mode 为 raw 或 formdata
+type Body struct {
+	Mode     string       `json:"mode"`
+	Raw      string       `json:"raw,omitempty"`
+	FormData []FormParam  `json:"formdata,omitempty"`
+	Options  *BodyOptions `json:"options,omitempty"`
+}
+
+// FormParam 表单字段，type 为 text 或 file
+type FormParam struct {
+	Key         string `json:"key"`
+	Value       string `json:"value,omitempty"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Disabled    bool   `json:"disabled,omitempty"`
+}
+
+// BodyOptions 请求体选项
+type BodyOptions struct {
+	Raw RawOptions `json:"raw"`
+}
+
+// RawOptions raw请求体的语言
+type RawOptions struct {
+	Language string `json:"language"`
+}
+
+// Auth 认证方式，type 为 bearer 或 noauth
+type Auth struct {
+	Type   string     `json:"type"`
+	Bearer []Variable `json:"bearer,omitempty"`
+}
+
+// Variable 变量
+type Variable struct {
+	Key         string `json:"key"`
+	Value       string `json:"value"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// Environment Postman环境，为集合的变量设置值
+type Environment struct {
+	Name   string             `json:"name"`
+	Values []EnvironmentValue `json:"values"`
+	Scope  string             `json:"_postman_variable_scope"`
+}
+
+// EnvironmentValue 环境变量
+type EnvironmentValue struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Options 转换选项
+type Options struct {
+	// Name 集合名称，为空时使用文档标题
+	Name string
+	// BaseURL baseUrl 变量的默认值，为空时由文档的 schemes、host 和 basePath 得出
+	BaseURL string
+}
+
+// swagger 文档中转换用到的部分
+type swagger struct {
+	Info struct {
+		Title       string `json:"title"`
+		Description string `json:"description"`
+	} `json:"info"`
+	Schemes     []string                              `json:"schemes"`
+	Host        string                                `json:"host"`
+	BasePath    string                                `json:"basePath"`
+	Paths       map[string]map[string]json.RawMessage `json:"paths"`
+	Definitions map[string]*schema                    `json:"definitions"`
+}
+
+// operation 接口
+type operation struct {
+	Summary     string                `json:"summary"`
+	Description string                `json:"description"`
+	Tags        []string              `json:"tags"`
+	Consumes    []string              `json:"consumes"`
+	Parameters  []parameter           `json:"parameters"`
+	Security    []map[string][]string `json:"security"`
+}
+
+// parameter 接口参数，in 为 path、query、header、body 或 formData
+type parameter struct {
+	Name        string        `json:"name"`
+	In          string        `json:"in"`
+	Description string        `json:"description"`
+	Required    bool          `json:"required"`
+	Type        string        `json:"type"`
+	Default     interface{}   `json:"default"`
+	Example     interface{}   `json:"example"`
+	Enum        []interface{} `json:"enum"`
+	Schema      *schema       `json:"schema"`
+}
+
+// schema 数据类型
+type schema struct {
+	Ref        string             `json:"$ref"`
+	Type       string             `json:"type"`
+	Properties map[string]*schema `json:"properties"`
+	Items      *schema            `json:"items"`
+	AllOf      []*schema          `json:"allOf"`
+	Example    interface{}        `json:"example"`
+	Default    interface{}        `json:"default"`
+	Enum       []interface{}      `json:"enum"`
+}
+
+// Convert 将Swagger 2.0 JSON文档转换为Postman集合。每个接口生成一个请求，按第一个标签分组到文件夹；
+// 请求地址以 {{baseUrl}} 开头，需要认证的接口以 {{token}} 作为Bearer令牌，JSON请求体由文档的示例值生成
+func Convert(spec []byte, opts Options) (*Collection, error) {
+	var doc swagger
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid swagger document: %w", err)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = doc.Info.Title
+	}
+	baseURL := opts.BaseURL
+	if baseURL == "" {
+		baseURL = DefaultBaseURL(spec)
+	}
+
+	collection := &Collection{
+		Info: Info{Name: name, Description: doc.Info.Description, Schema: SchemaURL},
+		Auth: &Auth{Type: "bearer", Bearer: []Variable{{Key: "token", Value: "{{" + TokenVariable + "}}", Type: "string"}}},
+		Variable: []Variable{
+			{Key: BaseURLVariable, Value: baseURL, Type: "string"},
+			{Key: TokenVariable, Value: "", Type: "string"},
+		},
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	folders := make(map[string]int)
+	for _, path := range paths {
+		for _, method := range methods {
+			raw, ok := doc.Paths[path][method]
+			if !ok {
+				continue
+			}
+			var op operation
+			if err := json.Unmarshal(raw, &op); err != nil {
+				return nil, fmt.Errorf("invalid operation %s %s: %w", strings.ToUpper(method), path, err)
+			}
+			item := Item{Name: op.Summary, Request: doc.request(method, path, &op)}
+			if item.Name == "" {
+				item.Name = strings.ToUpper(method) + " " + path
+			}
+
+			if len(op.Tags) == 0 {
+				collection.Item = append(collection.Item, item)
+				continue
+			}
+			i, ok := folders[op.Tags[0]]
+			if !ok {
+				i = len(collection.Item)
+				folders[op.Tags[0]] = i
+				collection.Item = append(collection.Item, Item{Name: op.Tags[0]})
+			}
+			collection.Item[i].Item = append(collection.Item[i].Item, item)
+		}
+	}
+	return collection, nil
+}
+
+// NewEnvironment 创建设置集合变量的环境，令牌留空由使用者填写
+func NewEnvironment(name, baseURL string) *Environment {
+	return &Environment{
+		Name: name,
+		Values: []EnvironmentValue{
+			{Key: BaseURLVariable, Value: baseURL, Type: "default", Enabled: true},
+			{Key: TokenVariable, Value: "", Type: "secret", Enabled: true},
+		},
+		Scope: "environment",
+	}
+}
+
+// DefaultBaseURL 返回文档的 schemes、host 和 basePath 组成的地址，如 http://localhost:8080/api/v1
+func DefaultBaseURL(spec []byte) string {
+	var doc swagger
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		return ""
+	}
+	scheme := "http"
+	if len(doc.Schemes) > 0 {
+		scheme = doc.Schemes[0]
+	}
+	host := doc.Host
+	if host == "" {
+		host = "localhost"
+	}
+	return scheme + "://" + host + doc.BasePath
+}
+
+// request 生成接口的请求
+func (doc *swagger) request(method, path string, op *operation) *Request {
+	req := &Request{
+		Method:      strings.ToUpper(method),
+		Header:      []Header{},
+		Description: op.Description,
+		URL:         URL{Host: []string{"{{" + BaseURLVariable + "}}"}},
+	}
+	if len(op.Security) == 0 {
+		req.Auth = &Auth{Type: "noauth"}
+	}
+
+	for _, segment := range strings.Split(strings.Trim(path, "/"), "/") {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segment = ":" + segment[1:len(segment)-1]
+		}
+		req.URL.Path = append(req.URL.Path, segment)
+	}
+
+	var enabledQuery []string
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			req.URL.Variable = append(req.URL.Variable, Variable{Key: param.Name, Value: param.value(), Description: param.Description})
+		case "query":
+			query := QueryParam{Key: param.Name, Value: param.value(), Description: param.Description, Disabled: !param.Required}
+			req.URL.Query = append(req.URL.Query, query)
+			if !query.Disabled {
+				enabledQuery = append(enabledQuery, url.QueryEscape(query.Key)+"="+url.QueryEscape(query.Value))
+			}
+		case "header":
+			req.Header = append(req.Header, Header{Key: param.Name, Value: param.value(), Description: param.Description, Disabled: !param.Required})
+		case "body":
+			body, _ := json.MarshalIndent(doc.example(param.Schema, 0), "", "  ")
+			req.Body = &Body{Mode: "raw", Raw: string(body), Options: &BodyOptions{Raw: RawOptions{Language: "json"}}}
+			req.Header = append(req.Header, Header{Key: "Content-Type", Value: "application/json"})
+		case "formData":
+			if req.Body == nil {
+				req.Body = &Body{Mode: "formdata"}
+			}
+			field := FormParam{Key: param.Name, Type: "text", Description: param.Description, Disabled: !param.Required}
+			if param.Type == "file" {
+				field.Type = "file"
+			} else {
+				field.Value = param.value()
+			}
+			req.Body.FormData = append(req.Body.FormData, field)
+		}
+	}
+
+	req.URL.Raw = "{{" + BaseURLVariable + "}}/" + strings.Join(req.URL.Path, "/")
+	if len(enabledQuery) > 0 {
+		req.URL.Raw += "?" + strings.Join(enabledQuery, "&")
+	}
+	return req
+}
+
+// value 返回参数的示例值、默认值或第一个枚举值
+func (p *parameter) value() string {
+	for _, v := range []interface{}{p.Example, p.Default} {
+		if v != nil {
+			return fmt.Sprint(v)
+		}
+	}
+	if len(p.Enum) > 0 {
+		return fmt.Sprint(p.Enum[0])
+	}
+	return ""
+}
+
+// example 由数据类型的示例值生成示例，没有示例值的字段使用其类型的零值
+func (doc *swagger) example(s *schema, depth int) interface{} {
+	if s == nil || depth > maxExampleDepth {
+		return nil
+	}
+	if s.Example != nil {
+		return s.Example
+	}
+	if s.Ref != "" {
+		return doc.example(doc.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")], depth+1)
+	}
+	if len(s.AllOf) > 0 {
+		merged := make(map[string]interface{})
+		for _, part := range s.AllOf {
+			value := doc.example(part, depth+1)
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return value
+			}
+			for k, v := range object {
+				merged[k] = v
+			}
+		}
+		return merged
+	}
+	if s.Default != nil {
+		return s.Default
+	}
+	if len(s.Enum) > 0 {
+		return s.Enum[0]
+	}
+
+	switch s.Type {
+	case "object", "":
+		object := make(map[string]interface{}, len(s.Properties))
+		for name, property := range s.Properties {
+			object[name] = doc.example(property, depth+1)
+		}
+		return object
+	case "array":
+		if s.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{doc.example(s.Items, depth+1)}
+	case "string":
+		return ""
+	case "integer", "number":
+		return 0
+	case "boolean":
+		return false
+	}
+	return nil
+}
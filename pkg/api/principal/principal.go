@@ -0,0 +1,95 @@
+package principal
+
+import (
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// 认证主体在gin上下文中的键，由JWT认证中间件设置
+const (
+	KeyUserID         = "user_id"
+	KeyUsername       = "username"
+	KeyRole           = "user_role"
+	KeyPermissions    = "user_permissions"
+	KeySessionID      = "session_id"
+	KeyImpersonatorID = "impersonator_id"
+)
+
+// 当前组织在gin上下文中的键，由组织中间件设置
+const (
+	KeyOrganizationID   = "organization_id"
+	KeyOrganizationRole = "organization_role"
+)
+
+// Principal 当前请求的认证用户，取自访问令牌的声明
+type Principal struct {
+	UserID      string
+	Username    string
+	Role        string
+	Permissions []string
+	// SessionID 签发访问令牌的会话，不属于会话的令牌为空
+	SessionID string
+	// ImpersonatorID 模拟令牌中代为操作的用户，普通令牌为空
+	ImpersonatorID string
+	// OrganizationID 请求的当前组织，未指定组织时为0
+	OrganizationID uint
+	// OrganizationRole 用户在当前组织中的角色：owner、admin 或 member
+	OrganizationRole string
+}
+
+// Set 将认证用户设置到gin上下文
+func Set(c *gin.Context, p Principal) {
+	c.Set(KeyUserID, p.UserID)
+	c.Set(KeyUsername, p.Username)
+	c.Set(KeyRole, p.Role)
+	c.Set(KeyPermissions, p.Permissions)
+	if p.SessionID != "" {
+		c.Set(KeySessionID, p.SessionID)
+	}
+	if p.ImpersonatorID != "" {
+		c.Set(KeyImpersonatorID, p.ImpersonatorID)
+	}
+}
+
+// SetOrganization 将请求的当前组织及用户在其中的角色设置到gin上下文
+func SetOrganization(c *gin.Context, orgID uint, role string) {
+	c.Set(KeyOrganizationID, orgID)
+	c.Set(KeyOrganizationRole, role)
+}
+
+// CurrentUser 返回当前请求的认证用户，未认证的请求返回false
+func CurrentUser(c *gin.Context) (Principal, bool) {
+	p := Principal{
+		UserID:         c.GetString(KeyUserID),
+		Username:       c.GetString(KeyUsername),
+		Role:           c.GetString(KeyRole),
+		Permissions:    c.GetStringSlice(KeyPermissions),
+		SessionID:      c.GetString(KeySessionID),
+		ImpersonatorID: c.GetString(KeyImpersonatorID),
+
+		OrganizationID:   c.GetUint(KeyOrganizationID),
+		OrganizationRole: c.GetString(KeyOrganizationRole),
+	}
+	return p, p.UserID != ""
+}
+
+// HasRole 判断用户的角色是否为roles之一
+func (p Principal) HasRole(roles ...string) bool {
+	return p.Role != "" && slices.Contains(roles, p.Role)
+}
+
+// HasPermission 判断用户是否具有permissions中的任一权限
+func (p Principal) HasPermission(permissions ...string) bool {
+	for _, permission := range permissions {
+		if slices.Contains(p.Permissions, permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// Impersonated 判断请求是否使用模拟令牌
+func (p Principal) Impersonated() bool {
+	return p.ImpersonatorID != ""
+}
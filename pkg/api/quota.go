@@ -0,0 +1,54 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// quotaAdmin 配额管理API结构，未启用配额时不注册路由
+type quotaAdmin struct {
+	Quotas  *quota.Manager `inject:"quota"`
+	handler *handler.QuotaHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newQuotaAdmin())
+}
+
+// newQuotaAdmin 创建依赖注入版本的配额管理API
+func newQuotaAdmin() APIInterface {
+	return &quotaAdmin{}
+}
+
+// Module 所属的管理模块，模块禁用时不注册路由
+func (a *quotaAdmin) Module() string {
+	return module.Admin
+}
+
+// RoutePolicies 配额管理仅限管理员，且不计入配额，以便配额用尽时仍可重置
+func (a *quotaAdmin) RoutePolicies() map[string]middleware.RoutePolicy {
+	if a.Quotas == nil || !a.Quotas.Enabled() {
+		return nil
+	}
+	admin := middleware.RoutePolicy{Roles: []string{"admin"}, Quota: middleware.QuotaNone}
+	return map[string]middleware.RoutePolicy{
+		"GET /admin/quotas/:principal":    admin,
+		"DELETE /admin/quotas/:principal": admin,
+	}
+}
+
+// InitAPIServiceRoute 初始化配额管理API路由
+func (a *quotaAdmin) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if a.Quotas == nil || !a.Quotas.Enabled() {
+		return
+	}
+	a.handler = handler.NewQuotaHandler(a.Quotas)
+
+	quotaGroup := rg.Group("/admin/quotas")
+	quotaGroup.GET("/:principal", a.handler.GetQuotaUsage)
+	quotaGroup.DELETE("/:principal", a.handler.ResetQuotaUsage)
+}
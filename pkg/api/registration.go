@@ -0,0 +1,69 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// registration 支持依赖注入的注册API结构
+type registration struct {
+	Config         *config.Config                  `inject:"config"`
+	Clock          clock.Clock                     `inject:"clock"`
+	UserService    service.UserServiceInterface    `inject:""`
+	SessionService service.SessionServiceInterface `inject:""`
+	handler        *handler.RegistrationHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newRegistration())
+}
+
+// newRegistration 创建依赖注入版本的注册API
+func newRegistration() APIInterface {
+	return &registration{}
+}
+
+// Module 所属的认证模块，模块禁用时不注册路由
+func (a *registration) Module() string {
+	return module.Auth
+}
+
+// RoutePolicies 注册和验证邮箱无需访问令牌，请求不携带Cookie，无需CSRF防护；注册是高风险路由，可疑请求需通过人机验证
+func (a *registration) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	return map[string]middleware.RoutePolicy{
+		"POST /auth/register":        {Public: true, CSRFExempt: true, Challenge: true},
+		"POST /auth/register/verify": {Public: true, CSRFExempt: true},
+	}
+}
+
+// InitAPIServiceRoute 初始化注册API路由，未启用时不注册路由；启用会话时注册签发会话令牌
+func (a *registration) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	var sessions *handler.SessionHandler
+	if a.Config.Security.Sessions.Enabled && a.SessionService != nil {
+		sessions = handler.NewSessionHandler(a.SessionService, &a.Config.Security, a.Clock)
+	}
+	a.handler = handler.NewRegistrationHandler(a.UserService, sessions)
+
+	authGroup := rg.Group("/auth")
+	{
+		authGroup.POST("/register", a.handler.Register)
+		authGroup.POST("/register/verify", a.handler.VerifyEmail)
+	}
+}
+
+// enabled 判断是否启用注册
+func (a *registration) enabled() bool {
+	return a.Config != nil && a.Config.Registration.Enabled && a.UserService != nil
+}
@@ -66,6 +66,13 @@ const (
 	CodeAppDependencyError    = 31011
 	CodeAppDeploymentFailed   = 31012
 	CodeAppBackupFailed       = 31013
+	CodeAppTagsInvalid        = 31014
+	CodeAppVariableNotFound   = 31015
+	CodeAppVariableExists     = 31016
+	CodeAppVariableInvalid    = 31017
+	CodeAppRevisionNotFound   = 31018
+	CodeAppBackupNotFound     = 31019
+	CodeAppBackupNotReady     = 31020
 
 	// 订单相关错误 (32000-32999)
 	CodeOrderNotFound        = 32000
@@ -110,6 +117,16 @@ const (
 	CodeFileStorageQuotaExceeded = 34007
 	CodeFileVirusDetected        = 34008
 	CodeFileProcessingFailed     = 34009
+	CodeFileInvalid              = 34010
+	CodeFileNotUploaded          = 34011
+	CodeFileUploadMissing        = 34012
+	CodeFileScanPending          = 34013
+	CodeFileNotVerified          = 34014
+	CodeUploadSessionNotFound    = 34015
+	CodeUploadSessionExpired     = 34016
+	CodeUploadOffsetMismatch     = 34017
+	CodeUploadIncomplete         = 34018
+	CodeUploadChecksumMismatch   = 34019
 
 	// 权限相关错误 (35000-35999)
 	CodePermissionDenied    = 35000
@@ -126,6 +143,87 @@ const (
 	CodeSessionExpired      = 35011
 	CodeLoginRequired       = 35012
 	CodeAccountLocked       = 35013
+
+	// 任务相关错误 (36000-36999)
+	CodeOperationNotFound = 36000
+
+	// 通知相关错误 (37000-37999)
+	CodeNotificationPreferenceNotFound = 37000
+	CodeNotificationPreferenceInvalid  = 37001
+	CodeNotificationChannelDisabled    = 37002
+
+	// 政策相关错误 (38000-38999)
+	CodePolicyNotFound        = 38000
+	CodePolicyInvalid         = 38001
+	CodePolicyDocumentExists  = 38002
+	CodePolicyVersionOutdated = 38003
+	CodePolicyConsentRequired = 38004
+
+	// 模拟登录相关错误 (39000-39999)
+	CodeImpersonationNotAllowed      = 39000
+	CodeImpersonationInvalid         = 39001
+	CodeImpersonationActionForbidden = 39002
+	CodeImpersonationUserNotFound    = 39003
+
+	// 合作方相关错误 (40000-40999)
+	CodeSignatureMissing  = 40000
+	CodeSignatureInvalid  = 40001
+	CodeSignatureExpired  = 40002
+	CodeSignatureReplayed = 40003
+	CodePartnerNotFound   = 40004
+	CodePartnerExists     = 40005
+	CodePartnerInvalid    = 40006
+
+	// 网络访问控制相关错误 (41000-41999)
+	CodeNetworkAccessDenied     = 41000
+	CodeNetworkACLInvalid       = 41001
+	CodeNetworkACLGroupNotFound = 41002
+
+	// 人机识别相关错误 (42000-42999)
+	CodeBotDetected       = 42000
+	CodeChallengeRequired = 42001
+	CodeChallengeFailed   = 42002
+
+	// 登录防暴力破解相关错误 (43000-43999)
+	CodeLoginLocked  = 43000
+	CodeLoginDelayed = 43001
+
+	// 会话相关错误 (44000-44999)
+	CodeSessionNotFound = 44000
+	CodeSessionRevoked  = 44001
+
+	// 用户偏好相关错误 (45000-45999)
+	CodePreferencesInvalid = 45000
+
+	// 组织相关错误 (46000-46999)
+	CodeOrganizationNotFound       = 46000
+	CodeOrganizationExists         = 46001
+	CodeOrganizationInvalid        = 46002
+	CodeOrganizationRequired       = 46003
+	CodeOrganizationAccessDenied   = 46004
+	CodeOrganizationNotEmpty       = 46005
+	CodeOrganizationMemberNotFound = 46006
+	CodeOrganizationMemberExists   = 46007
+	CodeOrganizationOwnerRequired  = 46008
+
+	// 邀请相关错误 (47000-47999)
+	CodeInvitationNotFound     = 47000
+	CodeInvitationInvalid      = 47001
+	CodeInvitationTokenInvalid = 47002
+	CodeInvitationExpired      = 47003
+	CodeInvitationNotPending   = 47004
+	CodeInvitationForbidden    = 47005
+
+	// 注册相关错误 (48000-48999)
+	CodeRegistrationInvitationRequired   = 48000
+	CodeRegistrationDomainNotAllowed     = 48001
+	CodeRegistrationInvitationMismatched = 48002
+	CodeRegistrationTokenInvalid         = 48003
+	CodeRegistrationTokenExpired         = 48004
+	CodeRegistrationEmailUnverified      = 48005
+
+	// 模式相关错误 (49000-49999)
+	CodeSchemaTypeNotFound = 49000
 )
 
 // 错误码消息映射表
@@ -188,6 +286,13 @@ var errorCodeMap = map[int]string{
 	CodeAppDependencyError:    "应用依赖错误",
 	CodeAppDeploymentFailed:   "应用部署失败",
 	CodeAppBackupFailed:       "应用备份失败",
+	CodeAppTagsInvalid:        "应用标签无效",
+	CodeAppVariableNotFound:   "应用变量不存在",
+	CodeAppVariableExists:     "应用变量已存在",
+	CodeAppVariableInvalid:    "应用变量无效",
+	CodeAppRevisionNotFound:   "应用修订记录不存在",
+	CodeAppBackupNotFound:     "应用备份不存在",
+	CodeAppBackupNotReady:     "应用备份尚未完成",
 
 	// 订单相关错误
 	CodeOrderNotFound:        "订单不存在",
@@ -232,6 +337,16 @@ var errorCodeMap = map[int]string{
 	CodeFileStorageQuotaExceeded: "文件存储配额已超出",
 	CodeFileVirusDetected:        "检测到病毒文件",
 	CodeFileProcessingFailed:     "文件处理失败",
+	CodeFileInvalid:              "文件参数无效",
+	CodeFileNotUploaded:          "文件尚未上传完成",
+	CodeFileUploadMissing:        "文件内容未上传",
+	CodeFileScanPending:          "文件正在进行安全扫描",
+	CodeFileNotVerified:          "文件校验和尚未计算",
+	CodeUploadSessionNotFound:    "上传会话不存在",
+	CodeUploadSessionExpired:     "上传会话已过期",
+	CodeUploadOffsetMismatch:     "分块偏移量不匹配",
+	CodeUploadIncomplete:         "文件内容尚未上传完整",
+	CodeUploadChecksumMismatch:   "上传内容校验和不匹配",
 
 	// 权限相关错误
 	CodePermissionDenied:    "权限被拒绝",
@@ -248,6 +363,83 @@ var errorCodeMap = map[int]string{
 	CodeSessionExpired:      "会话已过期",
 	CodeLoginRequired:       "需要登录",
 	CodeAccountLocked:       "账户已锁定",
+
+	// 任务相关错误
+	CodeOperationNotFound: "任务不存在",
+
+	// 通知相关错误
+	CodeNotificationPreferenceNotFound: "通知渠道偏好不存在",
+	CodeNotificationPreferenceInvalid:  "通知渠道偏好无效",
+	CodeNotificationChannelDisabled:    "通知渠道未启用",
+
+	// 政策相关错误
+	CodePolicyNotFound:        "政策不存在",
+	CodePolicyInvalid:         "政策文档无效",
+	CodePolicyDocumentExists:  "政策版本已发布",
+	CodePolicyVersionOutdated: "政策版本不是最新版本",
+	CodePolicyConsentRequired: "需要接受最新版本的政策",
+
+	// 模拟登录相关错误
+	CodeImpersonationNotAllowed:      "不允许模拟该用户",
+	CodeImpersonationInvalid:         "模拟登录请求无效",
+	CodeImpersonationActionForbidden: "模拟登录时不允许该操作",
+	CodeImpersonationUserNotFound:    "被模拟的用户不存在",
+
+	// 合作方相关错误
+	CodeSignatureMissing:  "请求未签名",
+	CodeSignatureInvalid:  "请求签名无效",
+	CodeSignatureExpired:  "请求签名已过期",
+	CodeSignatureReplayed: "请求已被使用",
+	CodePartnerNotFound:   "合作方不存在",
+	CodePartnerExists:     "合作方已存在",
+	CodePartnerInvalid:    "合作方信息无效",
+
+	// 网络访问控制相关错误
+	CodeNetworkAccessDenied:     "来源网络不允许访问",
+	CodeNetworkACLInvalid:       "网络访问控制规则无效",
+	CodeNetworkACLGroupNotFound: "网络访问控制分组不存在",
+
+	CodeBotDetected:       "请求被识别为自动化程序",
+	CodeChallengeRequired: "需要完成人机验证",
+	CodeChallengeFailed:   "人机验证未通过",
+
+	CodeLoginLocked:  "登录失败次数过多，已暂时锁定",
+	CodeLoginDelayed: "登录失败次数过多，请稍后再试",
+
+	// 会话相关错误
+	CodeSessionNotFound: "会话不存在",
+	CodeSessionRevoked:  "会话已终止",
+
+	// 用户偏好相关错误
+	CodePreferencesInvalid: "用户偏好无效",
+
+	// 组织相关错误
+	CodeOrganizationNotFound:       "组织不存在",
+	CodeOrganizationExists:         "组织已存在",
+	CodeOrganizationInvalid:        "组织参数无效",
+	CodeOrganizationRequired:       "未指定组织",
+	CodeOrganizationAccessDenied:   "无权访问该组织",
+	CodeOrganizationNotEmpty:       "组织仍有应用",
+	CodeOrganizationMemberNotFound: "组织成员不存在",
+	CodeOrganizationMemberExists:   "用户已是组织成员",
+	CodeOrganizationOwnerRequired:  "组织至少需要一名所有者",
+	CodeInvitationNotFound:         "邀请不存在",
+	CodeInvitationInvalid:          "邀请参数无效",
+	CodeInvitationTokenInvalid:     "邀请令牌无效",
+	CodeInvitationExpired:          "邀请已过期",
+	CodeInvitationNotPending:       "邀请已被接受或撤销",
+	CodeInvitationForbidden:        "无权管理该邀请",
+
+	// 注册相关错误
+	CodeRegistrationInvitationRequired:   "注册需要邀请",
+	CodeRegistrationDomainNotAllowed:     "邮箱域名不允许注册",
+	CodeRegistrationInvitationMismatched: "邮箱与邀请不一致",
+	CodeRegistrationTokenInvalid:         "邮箱验证令牌无效",
+	CodeRegistrationTokenExpired:         "邮箱验证令牌已过期",
+	CodeRegistrationEmailUnverified:      "邮箱未验证",
+
+	// 模式相关错误
+	CodeSchemaTypeNotFound: "类型不存在",
 }
 
 // GetErrorMessage 获取错误消息
@@ -2,13 +2,43 @@ package response
 
 import (
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-playground/validator/v10"
+	"github.com/make-bin/server-tpl/pkg/api/fields"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/api/validation"
 	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
 )
 
+// ErrorContextKey 服务端错误在上下文中的键，供错误上报中间件读取
+const ErrorContextKey = "response_error"
+
+const (
+	// FormatHeader 客户端选择响应格式的请求头
+	FormatHeader = "X-Response-Format"
+	// FormatEnvelope 默认格式，数据包装在标准响应结构中
+	FormatEnvelope = "envelope"
+	// FormatRaw 成功响应直接返回数据，分页信息放在响应头中；错误响应仍使用标准响应结构
+	FormatRaw = "raw"
+)
+
+// 原始格式下分页列表的响应头
+const (
+	TotalCountHeader = "X-Total-Count"
+	PageHeader       = "X-Page"
+	PageSizeHeader   = "X-Page-Size"
+	TotalPagesHeader = "X-Total-Pages"
+)
+
+// formatContextKey 路由默认响应格式在上下文中的键
+const formatContextKey = "response_format"
+
 // Response 标准响应结构
 type Response struct {
 	Success   bool        `json:"success"`
@@ -35,14 +65,26 @@ type Pagination struct {
 	Pages int `json:"pages"`
 }
 
-// ErrorDetail 错误详情
+// ErrorDetail 错误详情，Value 为校验失败的值，敏感字段脱敏
 type ErrorDetail struct {
-	Field  string `json:"field"`
-	Reason string `json:"reason"`
+	Field  string      `json:"field"`
+	Reason string      `json:"reason"`
+	Value  interface{} `json:"value,omitempty"`
+
+	// fieldError 校验错误，ValidationError 按请求的语言翻译 Reason
+	fieldError validator.FieldError
 }
 
+// sensitiveFields 字段名（小写、去掉下划线）包含这些词时校验失败的值被脱敏
+var sensitiveFields = []string{"password", "passwd", "secret", "token", "apikey", "privatekey", "credential"}
+
 // Success 成功响应
 func Success(c *gin.Context, data interface{}) {
+	data, ok := selectFields(c, localizeTimes(c, data))
+	if !ok {
+		return
+	}
+
 	requestID := getRequestID(c)
 	response := Response{
 		Success:   true,
@@ -53,7 +95,7 @@ func Success(c *gin.Context, data interface{}) {
 		RequestID: requestID,
 	}
 
-	c.JSON(http.StatusOK, response)
+	write(c, http.StatusOK, response)
 }
 
 // Error 错误响应
@@ -69,6 +111,9 @@ func Error(c *gin.Context, statusCode int, code int, message string, err error)
 
 	if err != nil {
 		response.Error = err.Error()
+		if statusCode >= http.StatusInternalServerError {
+			c.Set(ErrorContextKey, err)
+		}
 	}
 
 	c.JSON(statusCode, response)
@@ -92,8 +137,15 @@ func Page(c *gin.Context, items interface{}, page, size, total int) {
 	Success(c, data)
 }
 
-// ValidationError 参数验证错误
+// ValidationError 参数验证错误，由 ParseValidationErrors 得到的详情按请求的语言翻译
 func ValidationError(c *gin.Context, details []ErrorDetail) {
+	lang := i18n.GetLanguage(c)
+	for i := range details {
+		if details[i].fieldError != nil {
+			details[i].Reason = validation.Translate(details[i].fieldError, lang)
+		}
+	}
+
 	requestID := getRequestID(c)
 	response := Response{
 		Success:   false,
@@ -113,6 +165,95 @@ func BusinessError(c *gin.Context, code int, messageKey string, err error) {
 	Error(c, statusCode, code, messageKey, err)
 }
 
+// DefaultFormat 设置路由的默认响应格式，请求头X-Response-Format可以覆盖
+func DefaultFormat(format string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(formatContextKey, format)
+		c.Next()
+	}
+}
+
+// IsRaw 判断请求是否使用原始响应格式，请求头优先于路由默认格式，无效的值被忽略
+func IsRaw(c *gin.Context) bool {
+	switch c.GetHeader(FormatHeader) {
+	case FormatRaw:
+		return true
+	case FormatEnvelope:
+		return false
+	}
+	return c.GetString(formatContextKey) == FormatRaw
+}
+
+// write 写出成功响应，原始格式下只写出数据；调用者不可见的字段在此统一处理
+func write(c *gin.Context, statusCode int, response Response) {
+	response.Data = maskFields(c, response.Data)
+	c.Writer.Header().Add("Vary", FormatHeader)
+	if !IsRaw(c) {
+		c.JSON(statusCode, response)
+		return
+	}
+
+	c.Header(FormatHeader, FormatRaw)
+	data := response.Data
+	if page, ok := data.(PaginationResponse); ok {
+		c.Header(TotalCountHeader, strconv.Itoa(page.Pagination.Total))
+		c.Header(PageHeader, strconv.Itoa(page.Pagination.Page))
+		c.Header(PageSizeHeader, strconv.Itoa(page.Pagination.Size))
+		c.Header(TotalPagesHeader, strconv.Itoa(page.Pagination.Pages))
+		data = page.Items
+	}
+	c.JSON(statusCode, data)
+}
+
+// selectFields 按GET请求的fields查询参数裁剪响应数据，分页响应裁剪其中的条目；
+// 字段不存在时返回400且第二个返回值为false
+func selectFields(c *gin.Context, data interface{}) (interface{}, bool) {
+	if c.Request == nil || c.Request.Method != http.MethodGet {
+		return data, true
+	}
+	names := fields.Parse(c.Query(fields.QueryParam))
+	if len(names) == 0 {
+		return data, true
+	}
+
+	var err error
+	if page, ok := data.(PaginationResponse); ok {
+		page.Items, err = fields.Select(page.Items, names)
+		data = page
+	} else {
+		data, err = fields.Select(data, names)
+	}
+	if err != nil {
+		Error(c, http.StatusBadRequest, CodeInvalidParameter, "invalid_parameter", err)
+		return nil, false
+	}
+	return data, true
+}
+
+// localizeTimes 将响应数据中的 timestamp.Time 转换到请求本地化器的时区（用户偏好的时区，默认UTC），
+// 时间仍按RFC3339编码并带有该时区的偏移
+func localizeTimes(c *gin.Context, data interface{}) interface{} {
+	loc := i18n.LocalizerFromContext(c).GetTimeZone()
+	if loc == nil || loc == time.UTC {
+		return data
+	}
+	return timestamp.In(data, loc)
+}
+
+// maskFields 按JWT中的角色和权限处理响应数据中带visibility标签的字段，分页响应处理其中的条目
+func maskFields(c *gin.Context, data interface{}) interface{} {
+	user, _ := principal.CurrentUser(c)
+	caller := fields.Caller{
+		Role:        user.Role,
+		Permissions: user.Permissions,
+	}
+	if page, ok := data.(PaginationResponse); ok {
+		page.Items = fields.Mask(page.Items, caller)
+		return page
+	}
+	return fields.Mask(data, caller)
+}
+
 // getRequestID 获取请求ID
 func getRequestID(c *gin.Context) string {
 	if requestID, exists := c.Get("request_id"); exists {
@@ -125,14 +266,14 @@ func getRequestID(c *gin.Context) string {
 
 // getMessage 获取本地化消息
 func getMessage(c *gin.Context, key string) string {
-	// 使用国际化工具获取消息
-	if translator, exists := c.Get("translator"); exists {
-		if t, ok := translator.(i18n.Translator); ok {
-			return t.Translate(key)
+	// 使用国际化工具获取消息（LanguageMiddleware设置的或请求作用域的翻译器）
+	if t, ok := i18n.FromContext(c); ok {
+		if message := t.Translate(key); message != key {
+			return message
 		}
 	}
 
-	// 如果没有翻译器，返回预定义消息
+	// 如果没有翻译器或缺少翻译，返回预定义消息
 	if message, exists := getDefaultMessage(key); exists {
 		return message
 	}
@@ -143,20 +284,170 @@ func getMessage(c *gin.Context, key string) string {
 // getDefaultMessage 获取默认消息
 func getDefaultMessage(key string) (string, bool) {
 	messages := map[string]string{
-		"success":          "操作成功",
-		"validation_error": "参数验证失败",
-		"user_not_found":   "用户不存在",
-		"user_created":     "用户创建成功",
-		"user_updated":     "用户更新成功",
-		"user_deleted":     "用户删除成功",
-		"app_not_found":    "应用不存在",
-		"app_created":      "应用创建成功",
-		"app_updated":      "应用更新成功",
-		"app_deleted":      "应用删除成功",
-		"internal_error":   "服务器内部错误",
-		"unauthorized":     "未授权访问",
-		"forbidden":        "权限不足",
-		"not_found":        "资源不存在",
+		"success":                "操作成功",
+		"validation_error":       "参数验证失败",
+		"user_not_found":         "用户不存在",
+		"user_created":           "用户创建成功",
+		"user_updated":           "用户更新成功",
+		"user_deleted":           "用户删除成功",
+		"app_not_found":          "应用不存在",
+		"app_created":            "应用创建成功",
+		"app_updated":            "应用更新成功",
+		"app_deleted":            "应用删除成功",
+		"app_tags_invalid":       "应用标签无效",
+		"app_tags_updated":       "应用标签更新成功",
+		"app_permission_denied":  "无权对该应用执行此操作",
+		"app_variable_not_found": "应用变量不存在",
+		"app_variable_exists":    "应用变量已存在",
+		"app_variable_invalid":   "应用变量无效",
+		"app_variable_created":   "应用变量创建成功",
+		"app_variable_updated":   "应用变量更新成功",
+		"app_variables_imported": "应用变量导入成功",
+		"app_revision_not_found": "应用修订记录不存在",
+		"app_rolled_back":        "应用回滚成功",
+		"app_backup_created":     "应用备份任务已创建",
+		"app_backup_not_found":   "应用备份不存在",
+		"app_backup_not_ready":   "应用备份尚未完成",
+		"app_backup_failed":      "应用备份失败",
+		"app_restored":           "应用恢复成功",
+		"app_imported":           "应用导入完成",
+		"app_import_validated":   "应用导入校验完成",
+		"operation_not_found":    "任务不存在",
+		"operation_accepted":     "任务已创建",
+		"feature_flag_created":   "特性开关创建成功",
+		"feature_flag_updated":   "特性开关更新成功",
+		"unsupported_media_type": "不支持的媒体类型",
+		"payload_too_large":      "请求体过大",
+		"quota_exceeded":         "请求配额已用尽",
+		"quota_reset":            "配额用量已重置",
+		"service_overloaded":     "服务繁忙，请稍后重试",
+		"conflict":               "资源冲突",
+		"internal_error":         "服务器内部错误",
+		"unauthorized":           "未授权访问",
+		"forbidden":              "权限不足",
+		"not_found":              "资源不存在",
+
+		"notification_preference_not_found": "通知渠道偏好不存在",
+		"notification_preference_invalid":   "通知渠道偏好无效",
+		"notification_preference_updated":   "通知渠道偏好设置成功",
+		"notification_channel_disabled":     "通知渠道未启用",
+		"notification_sent":                 "通知已发送",
+
+		"policy_not_found":        "政策不存在",
+		"policy_invalid":          "政策文档无效",
+		"policy_document_exists":  "政策版本已发布",
+		"policy_published":        "政策发布成功",
+		"policy_version_outdated": "政策版本不是最新版本，请重新获取",
+		"policy_accepted":         "已接受政策",
+		"policy_consent_required": "请先接受最新版本的政策",
+
+		"impersonation_started":          "模拟登录令牌已签发",
+		"impersonation_not_allowed":      "不允许模拟该用户",
+		"impersonation_invalid":          "模拟登录请求无效",
+		"impersonation_action_forbidden": "模拟登录时不允许该操作",
+		"impersonation_user_not_found":   "被模拟的用户不存在",
+
+		"signature_missing":      "请求未签名",
+		"signature_invalid":      "请求签名无效",
+		"signature_expired":      "请求签名已过期，请检查时钟",
+		"signature_replayed":     "请求已被使用，请使用新的随机数",
+		"partner_not_found":      "合作方不存在",
+		"partner_exists":         "合作方已存在",
+		"partner_invalid":        "合作方信息无效",
+		"partner_created":        "合作方创建成功",
+		"partner_updated":        "合作方更新成功",
+		"partner_deleted":        "合作方删除成功",
+		"partner_secret_rotated": "合作方密钥已轮换",
+
+		"network_access_denied":       "来源网络不允许访问",
+		"network_acl_invalid":         "网络访问控制规则无效",
+		"network_acl_group_not_found": "网络访问控制分组不存在",
+		"network_acl_updated":         "网络访问控制规则已更新",
+		"network_acl_group_deleted":   "网络访问控制分组已删除",
+
+		"bot_detected":       "请求被识别为自动化程序",
+		"challenge_required": "需要完成人机验证",
+		"challenge_failed":   "人机验证未通过",
+
+		"login_locked":  "登录失败次数过多，已暂时锁定",
+		"login_delayed": "登录失败次数过多，请稍后再试",
+
+		"session_not_found":     "会话不存在",
+		"session_revoked":       "会话已终止，请重新登录",
+		"session_unavailable":   "当前请求未使用会话令牌",
+		"session_refreshed":     "会话已刷新",
+		"logged_in":             "登录成功",
+		"login_invalid":         "邮箱或密码错误",
+		"sessions_revoked":      "其他会话已终止",
+		"refresh_token_invalid": "刷新令牌无效",
+		"refresh_token_expired": "刷新令牌已过期，请重新登录",
+
+		"preferences_updated":          "偏好已更新",
+		"preferences_invalid":          "偏好设置无效",
+		"preference_language_invalid":  "不支持的语言",
+		"preference_time_zone_invalid": "时区无效",
+
+		"organization_not_found":         "组织不存在",
+		"organization_exists":            "组织名称已存在",
+		"organization_invalid":           "组织参数无效",
+		"organization_required":          "请指定组织",
+		"organization_access_denied":     "您不是该组织的成员",
+		"organization_role_insufficient": "您在该组织中的角色无权执行此操作",
+		"organization_not_empty":         "组织仍有应用，请先删除或迁移应用",
+		"organization_member_not_found":  "组织成员不存在",
+		"organization_member_exists":     "用户已是组织成员",
+		"organization_member_invalid":    "组织成员参数无效",
+		"organization_owner_required":    "组织至少需要保留一名所有者",
+		"organization_owner_only":        "只有所有者可以管理所有者",
+		"organization_created":           "组织创建成功",
+		"organization_updated":           "组织更新成功",
+		"organization_member_added":      "组织成员添加成功",
+		"organization_member_updated":    "组织成员角色修改成功",
+		"invitation_not_found":           "邀请不存在",
+		"invitation_invalid":             "邀请参数无效",
+		"invitation_token_invalid":       "邀请链接无效",
+		"invitation_expired":             "邀请已过期，请联系邀请人重新邀请",
+		"invitation_not_pending":         "邀请已被接受或撤销",
+		"invitation_forbidden":           "您无权管理该组织的邀请或预设该角色",
+		"invitation_created":             "邀请已发送",
+		"invitation_accepted":            "已接受邀请",
+
+		"user_email_exists":                  "该邮箱已注册",
+		"user_invalid":                       "用户信息无效",
+		"user_password_too_short":            "密码太短",
+		"registration_invitation_required":   "当前仅限受邀用户注册",
+		"registration_domain_not_allowed":    "该邮箱域名不允许注册",
+		"registration_invitation_mismatched": "注册邮箱与邀请邮箱不一致",
+		"user_registered":                    "注册成功",
+		"registration_token_invalid":         "邮箱验证链接无效",
+		"registration_token_expired":         "邮箱验证链接已过期，请重新注册",
+		"user_email_unverified":              "邮箱尚未验证，请查收验证邮件",
+		"verification_mailed":                "验证邮件已发送，请验证邮箱以完成注册",
+		"email_verified":                     "邮箱已验证，注册成功",
+
+		"schema_type_not_found": "类型不存在",
+
+		"file_not_found":                "文件不存在",
+		"file_invalid":                  "文件名、类型或大小无效",
+		"file_content_type_not_allowed": "不允许上传该类型的文件",
+		"file_too_large":                "文件超过大小上限",
+		"file_not_uploaded":             "文件尚未上传完成",
+		"file_upload_missing":           "未找到与声明大小一致的已上传内容，请重新上传",
+		"file_permission_denied":        "您无权访问该文件",
+		"file_upload_created":           "上传地址已生成",
+		"file_uploaded":                 "文件上传完成",
+		"file_scan_pending":             "文件正在进行安全扫描，请稍后下载",
+		"file_quarantined":              "文件检测到恶意软件，已被隔离",
+		"file_not_verified":             "文件仍在处理中，请稍后下载",
+		"file_corrupted":                "文件内容与校验和不一致",
+		"upload_session_not_found":      "上传会话不存在",
+		"upload_session_created":        "上传会话已创建",
+		"upload_session_expired":        "上传会话已过期，请重新上传",
+		"upload_offset_mismatch":        "分块偏移量与已上传的字节数不一致，请查询偏移量后续传",
+		"upload_chunk_too_large":        "分块超过大小上限",
+		"upload_chunk_overflow":         "分块超出声明的文件大小",
+		"upload_incomplete":             "文件内容尚未上传完整",
+		"upload_checksum_mismatch":      "上传内容与校验和不一致，请删除会话后重新上传",
 	}
 
 	message, exists := messages[key]
@@ -187,15 +478,17 @@ func getHTTPStatusFromCode(code int) int {
 	}
 }
 
-// ParseValidationErrors 解析验证错误
+// ParseValidationErrors 将校验错误转换为错误详情，消息为默认语言，写入 ValidationError 时按请求的语言翻译
 func ParseValidationErrors(err error) []ErrorDetail {
 	var details []ErrorDetail
 
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, validationError := range validationErrors {
 			detail := ErrorDetail{
-				Field:  validationError.Field(),
-				Reason: getValidationErrorMessage(validationError),
+				Field:      validationError.Field(),
+				Reason:     validation.Translate(validationError, i18n.DefaultLanguage),
+				Value:      detailValue(validationError),
+				fieldError: validationError,
 			}
 			details = append(details, detail)
 		}
@@ -204,40 +497,41 @@ func ParseValidationErrors(err error) []ErrorDetail {
 	return details
 }
 
-// getValidationErrorMessage 获取验证错误消息
-func getValidationErrorMessage(ve validator.FieldError) string {
-	switch ve.Tag() {
-	case "required":
-		return "此字段是必需的"
-	case "email":
-		return "请提供有效的邮箱地址"
-	case "min":
-		return "值太小，最小值为 " + ve.Param()
-	case "max":
-		return "值太大，最大值为 " + ve.Param()
-	case "len":
-		return "长度必须为 " + ve.Param()
-	case "alphanum":
-		return "只能包含字母和数字"
-	case "phone":
-		return "请提供有效的手机号"
-	case "username":
-		return "用户名格式不正确"
-	case "password":
-		return "密码强度不足"
-	case "gte":
-		return "值必须大于或等于 " + ve.Param()
-	case "lte":
-		return "值必须小于或等于 " + ve.Param()
-	case "oneof":
-		return "值必须是以下之一：" + ve.Param()
+// detailValue 返回校验失败的基本类型的值，敏感字段返回脱敏值；空值和其他类型返回nil
+func detailValue(fe validator.FieldError) interface{} {
+	value := reflect.ValueOf(fe.Value())
+	for value.Kind() == reflect.Ptr && !value.IsNil() {
+		value = value.Elem()
+	}
+	if !value.IsValid() || value.IsZero() {
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
 	default:
-		return "字段验证失败"
+		return nil
+	}
+
+	name := strings.ToLower(strings.ReplaceAll(fe.Field(), "_", ""))
+	for _, sensitive := range sensitiveFields {
+		if strings.Contains(name, sensitive) {
+			return fields.MaskedValue
+		}
 	}
+	return value.Interface()
 }
 
 // WithMessage 自定义消息响应
 func WithMessage(c *gin.Context, data interface{}, messageKey string) {
+	data, ok := selectFields(c, localizeTimes(c, data))
+	if !ok {
+		return
+	}
+
 	requestID := getRequestID(c)
 	response := Response{
 		Success:   true,
@@ -248,7 +542,7 @@ func WithMessage(c *gin.Context, data interface{}, messageKey string) {
 		RequestID: requestID,
 	}
 
-	c.JSON(http.StatusOK, response)
+	write(c, http.StatusOK, response)
 }
 
 // NoContent 无内容响应
@@ -263,12 +557,12 @@ func Created(c *gin.Context, data interface{}, messageKey string) {
 		Success:   true,
 		Code:      CodeSuccess,
 		Message:   getMessage(c, messageKey),
-		Data:      data,
+		Data:      localizeTimes(c, data),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		RequestID: requestID,
 	}
 
-	c.JSON(http.StatusCreated, response)
+	write(c, http.StatusCreated, response)
 }
 
 // Accepted 已接受响应
@@ -278,12 +572,12 @@ func Accepted(c *gin.Context, data interface{}, messageKey string) {
 		Success:   true,
 		Code:      CodeSuccess,
 		Message:   getMessage(c, messageKey),
-		Data:      data,
+		Data:      localizeTimes(c, data),
 		Timestamp: time.Now().UTC().Format(time.RFC3339),
 		RequestID: requestID,
 	}
 
-	c.JSON(http.StatusAccepted, response)
+	write(c, http.StatusAccepted, response)
 }
 
 // Unauthorized 未授权响应
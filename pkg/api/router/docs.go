@@ -0,0 +1,90 @@
+package router
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api"
+	"github.com/make-bin/server-tpl/pkg/api/postman"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// swaggerDoc 生成的API文档中启动检查用到的部分
+type swaggerDoc struct {
+	BasePath string                                `json:"basePath"`
+	Paths    map[string]map[string]json.RawMessage `json:"paths"`
+}
+
+// setupSwaggerRoutes 设置Swagger文档路由，/swagger/doc.json 返回 go generate ./pkg/api 生成的API文档；
+// /swagger/postman.json 和 /swagger/postman_environment.json 返回由文档转换的Postman集合及其环境，
+// 环境中的 baseUrl 为本次请求访问的地址
+func setupSwaggerRoutes(engine *gin.Engine) {
+	spec := api.SwaggerSpec()
+	engine.GET("/swagger/doc.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", spec)
+	})
+	engine.GET("/swagger/postman.json", func(c *gin.Context) {
+		collection, err := postman.Convert(spec, postman.Options{BaseURL: requestBaseURL(c, spec)})
+		if err != nil {
+			logger.Error("Failed to convert the API documentation to a Postman collection: %v", err)
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, collection)
+	})
+	engine.GET("/swagger/postman_environment.json", func(c *gin.Context) {
+		c.JSON(http.StatusOK, postman.NewEnvironment(c.Request.Host, requestBaseURL(c, spec)))
+	})
+	// 这里可以添加Swagger UI路由
+	// engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+}
+
+// checkRouteDocs 对API文档中没有的路由给出警告，通常是处理器缺少swag注释或修改注释后没有重新生成文档；
+// 只检查文档BasePath下的路由，其他API版本和系统路由不在文档中；undocumented 中的路径由各自的schema描述，不做检查
+func checkRouteDocs(engine *gin.Engine, spec []byte, undocumented []string) {
+	var doc swaggerDoc
+	if err := json.Unmarshal(spec, &doc); err != nil {
+		logger.Warn("Failed to parse the API documentation: %v", err)
+		return
+	}
+
+	var missing int
+	for _, route := range engine.Routes() {
+		path, ok := strings.CutPrefix(route.Path, doc.BasePath)
+		if !ok || !strings.HasPrefix(path, "/") || slices.Contains(undocumented, route.Path) {
+			continue
+		}
+		if _, ok := doc.Paths[swaggerPath(path)][strings.ToLower(route.Method)]; !ok {
+			logger.Warn("Route %s %s has no API documentation", route.Method, route.Path)
+			missing++
+		}
+	}
+	if missing > 0 {
+		logger.Warn("%d route(s) have no API documentation: annotate their handlers and run \"go generate ./pkg/api\"", missing)
+	}
+}
+
+// requestBaseURL 返回本次请求访问的API地址，由请求的协议、主机和文档的BasePath组成
+func requestBaseURL(c *gin.Context, spec []byte) string {
+	var doc swaggerDoc
+	_ = json.Unmarshal(spec, &doc)
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host + doc.BasePath
+}
+
+// swaggerPath 将gin的路由参数（:id、*path）转换为Swagger的路径参数（{id}、{path}）
+func swaggerPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
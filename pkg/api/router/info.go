@@ -0,0 +1,127 @@
+package router
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/utils/buildinfo"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// SystemInfo 系统信息，由/info返回，并在启动横幅中记录。版本、提交和构建时间来自构建，
+// 模块和API列表反映实际注册的模块和路由
+type SystemInfo struct {
+	ServiceName string        `json:"service_name"`
+	Version     string        `json:"version"`
+	BuildTime   string        `json:"build_time"`
+	GoVersion   string        `json:"go_version"`
+	GitCommit   string        `json:"git_commit"`
+	Modified    bool          `json:"modified"`
+	Environment string        `json:"environment"`
+	Modules     []module.Info `json:"modules"`
+	APIs        []APIInfo     `json:"apis"`
+}
+
+// APIInfo 已注册的API接口在一个API版本下的路由，未注册路由时为禁用
+type APIInfo struct {
+	Name       string `json:"name"`
+	APIVersion string `json:"api_version"`
+	Module     string `json:"module,omitempty"`
+	Routes     int    `json:"routes"`
+	Enabled    bool   `json:"enabled"`
+}
+
+// SystemInfoEnvelope 系统信息的文档类型
+type SystemInfoEnvelope struct {
+	v1.Envelope
+	Data SystemInfo `json:"data"`
+}
+
+// NewSystemInfo 根据配置和构建生成系统信息，没有构建版本时使用配置的版本；
+// 模块列表为注册的模块，API列表在初始化路由时记录
+func NewSystemInfo(cfg *config.Config) *SystemInfo {
+	info := defaultSystemInfo()
+	info.ServiceName = cfg.App.Name
+	if info.Version == "" {
+		info.Version = cfg.App.Version
+	}
+	info.Environment = cfg.App.Env
+	info.Modules = module.List()
+	for i := range info.Modules {
+		if info.Modules[i].Version == "" {
+			info.Modules[i].Version = info.Version
+		}
+	}
+	return info
+}
+
+// defaultSystemInfo 未提供配置时/info返回的系统信息
+func defaultSystemInfo() *SystemInfo {
+	build := buildinfo.Get()
+	return &SystemInfo{
+		ServiceName: "server-tpl",
+		Version:     build.Version,
+		BuildTime:   build.Date,
+		GoVersion:   build.GoVersion,
+		GitCommit:   build.Commit,
+		Modified:    build.Modified,
+		Environment: gin.Mode(),
+		Modules:     []module.Info{},
+		APIs:        []APIInfo{},
+	}
+}
+
+// recordAPI 记录API接口在一个API版本下注册的路由数
+func (i *SystemInfo) recordAPI(apiInterface api.APIInterface, apiVersion string, routes int) {
+	entry := APIInfo{
+		Name:       apiName(apiInterface),
+		APIVersion: apiVersion,
+		Routes:     routes,
+		Enabled:    routes > 0,
+	}
+	if component, ok := apiInterface.(module.Component); ok {
+		entry.Module = component.Module()
+	}
+	i.APIs = append(i.APIs, entry)
+}
+
+// EnabledModules 返回已启用模块的名称
+func (i *SystemInfo) EnabledModules() []string {
+	var enabled []string
+	for _, m := range i.Modules {
+		if m.Enabled {
+			enabled = append(enabled, m.Name)
+		}
+	}
+	return enabled
+}
+
+// apiName API接口的名称，为其类型名去掉API后缀的蛇形命名，如networkACL为network_acl，adminAPI为admin
+func apiName(apiInterface api.APIInterface) string {
+	t := reflect.TypeOf(apiInterface)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	name := t.Name()
+	if trimmed := strings.TrimSuffix(name, "API"); trimmed != "" {
+		name = trimmed
+	}
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// 单词边界：小写字母之后，或缩写词与下一个单词之间
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
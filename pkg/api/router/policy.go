@@ -0,0 +1,60 @@
+package router
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// setRoutePolicies 将API声明的路由策略加入策略表，返回加入的路由（"METHOD /完整路径"）
+func setRoutePolicies(policies *middleware.RoutePolicies, basePath string, declared map[string]middleware.RoutePolicy, routerConfig *RouterConfig) []string {
+	var routes []string
+	for route, policy := range declared {
+		method, path, ok := strings.Cut(route, " ")
+		if !ok || method == "" || !strings.HasPrefix(path, "/") {
+			logger.Warn("Ignoring route policy %q: expected \"METHOD /path\"", route)
+			continue
+		}
+		if class := policy.RateLimit; class != "" && class != middleware.RateLimitNone && routerConfig.SecurityConfig != nil {
+			if _, ok := routerConfig.SecurityConfig.RateLimitClasses[class]; !ok {
+				logger.Warn("Route %s uses unknown rate limit class %q, the default limit applies", route, class)
+			}
+		}
+		if class := policy.Quota; class != "" && class != middleware.QuotaNone && routerConfig.Quota != nil {
+			if !routerConfig.Quota.HasClass(class) {
+				logger.Warn("Route %s uses unknown quota class %q, it is not counted against a quota", route, class)
+			}
+		}
+
+		method = strings.ToUpper(method)
+		fullPath := strings.TrimSuffix(basePath, "/") + path
+		policies.Set(method, fullPath, policy)
+		routes = append(routes, method+" "+fullPath)
+	}
+	return routes
+}
+
+// checkRoutePolicies 对没有对应路由的策略给出警告，通常是路径写错
+func checkRoutePolicies(engine *gin.Engine, declared []string) {
+	for _, route := range UnmatchedRoutePolicies(engine, declared) {
+		logger.Warn("Route policy for %s matches no route", route)
+	}
+}
+
+// UnmatchedRoutePolicies 返回declared中没有对应路由的策略（"METHOD /完整路径"），
+// 这些策略不会生效，其路由使用默认策略
+func UnmatchedRoutePolicies(engine *gin.Engine, declared []string) []string {
+	routes := make(map[string]bool)
+	for _, route := range engine.Routes() {
+		routes[route.Method+" "+route.Path] = true
+	}
+	var unmatched []string
+	for _, route := range declared {
+		if !routes[route] {
+			unmatched = append(unmatched, route)
+		}
+	}
+	return unmatched
+}
@@ -7,12 +7,27 @@ import (
 	"github.com/go-playground/validator/v10"
 	"github.com/make-bin/server-tpl/pkg/api"
 	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
 	"github.com/make-bin/server-tpl/pkg/api/middleware"
 	"github.com/make-bin/server-tpl/pkg/api/response"
 	"github.com/make-bin/server-tpl/pkg/api/validation"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/botdetect"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/denylist"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/httpcache"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/loginguard"
 	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/netacl"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/signing"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
 	"github.com/make-bin/server-tpl/pkg/utils/container"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
 )
 
 // CORSConfig CORS配置
@@ -26,11 +41,44 @@ type CORSConfig struct {
 
 // RouterConfig 路由配置
 type RouterConfig struct {
-	EnableAuth     bool                       `json:"enable_auth"`
-	EnableSecurity bool                       `json:"enable_security"`
-	SecurityConfig *middleware.SecurityConfig `json:"security_config"`
-	CORSConfig     *CORSConfig                `json:"cors_config"`
-	Validator      *validator.Validate        `json:"-"`
+	EnableAuth         bool                              `json:"enable_auth"`
+	EnableSecurity     bool                              `json:"enable_security"`
+	SecurityConfig     *config.SecurityConfig            `json:"security_config"`
+	CORSConfig         *CORSConfig                       `json:"cors_config"`
+	Validator          *validator.Validate               `json:"-"`
+	ErrorReporter      errorreport.Reporter              `json:"-"`
+	AccessLog          analytics.Sink                    `json:"-"`
+	ImpersonationAudit analytics.Sink                    `json:"-"` // 为空时不审计模拟请求
+	RequestID          *infra_middleware.RequestIDConfig `json:"request_id"`
+	FeatureFlags       featureflags.Evaluator            `json:"-"`
+	Experiments        featureflags.Assigner             `json:"-"`
+	Container          *container.SimpleContainer        `json:"-"`
+	APIConfig          *config.APIConfig                 `json:"api_config"`
+	Quota              *quota.Manager                    `json:"-"`
+	Consent            middleware.ConsentChecker         `json:"-"`
+	Signing            *signing.Verifier                 `json:"-"` // 为空时签名路由只接受JWT认证
+	Partners           middleware.PartnerSecrets         `json:"-"`
+	NetworkACL         *netacl.Manager                   `json:"-"` // 为空时不限制来源网络
+	NetworkACLAudit    analytics.Sink                    `json:"-"` // 为空时不审计被拦截的请求
+	BotDetection       *botdetect.Detector               `json:"-"` // 为空时不进行人机识别
+	LoginThrottle      *loginguard.Guard                 `json:"-"` // 为空时不限制登录失败次数
+	Denylist           *denylist.Denylist                `json:"-"` // 为空时不检查访问令牌的会话是否已终止
+	Sessions           middleware.SessionToucher         `json:"-"`
+	Preferences        middleware.PreferenceResolver     `json:"-"` // 为空时不应用用户偏好的语言和时区
+	Organizations      middleware.OrgMemberships         `json:"-"` // 为空时不解析当前组织，应用不按组织隔离
+	OrganizationHeader string                            `json:"organization_header"`
+	LoadShedding       *config.LoadSheddingConfig        `json:"load_shedding"`
+	Coalescing         *config.CoalescingConfig          `json:"coalescing"`
+	ResponseCache      *httpcache.Cache                  `json:"-"` // 为空时不缓存响应
+	Batch              *config.BatchConfig               `json:"batch"`
+	PProf              *pprof.PProfManager               `json:"-"`
+	PProfAllowedIPs    []string                          `json:"pprof_allowed_ips"`
+	MetricsPath        string                            `json:"metrics_path"` // 为空时不提供指标抓取端点
+	SystemInfo         *SystemInfo                       `json:"system_info"`  // 为空时/info返回默认信息
+	Clock              clock.Clock                       `json:"-"`
+	RoutePolicies      *middleware.RoutePolicies         `json:"-"`                // 为空时新建；传入时初始化后可查看各API声明的路由策略
+	CheckRouteDocs     bool                              `json:"check_route_docs"` // 开发模式下为true，启动时对没有API文档的路由给出警告
+	StrictJSON         bool                              `json:"strict_json"`      // 拒绝JSON请求体中的未知字段，路由策略的 UnknownFields 可覆盖
 }
 
 // DefaultRouterConfig 默认路由配置
@@ -41,7 +89,7 @@ func DefaultRouterConfig() *RouterConfig {
 	return &RouterConfig{
 		EnableAuth:     true,
 		EnableSecurity: true,
-		SecurityConfig: middleware.DefaultSecurityConfig,
+		SecurityConfig: config.DefaultSecurityConfig(),
 		CORSConfig: &CORSConfig{
 			AllowedOrigins:   []string{"*"},
 			AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
@@ -49,7 +97,10 @@ func DefaultRouterConfig() *RouterConfig {
 			AllowCredentials: true,
 			MaxAge:           3600,
 		},
-		Validator: v,
+		Validator:   v,
+		APIConfig:   config.DefaultAPIConfig(),
+		Clock:       clock.New(),
+		MetricsPath: "/metrics",
 	}
 }
 
@@ -90,23 +141,62 @@ func initRouterWithConfig(engine *gin.Engine, c *container.Container, config *Ro
 	// 应用全局中间件
 	setupGlobalMiddleware(engine, config)
 
-	// 创建API v1路由组
-	v1 := engine.Group("/api/v1")
+	// API级别中间件在各版本间共享，限流等状态不按版本区分
+	policies := config.RoutePolicies
+	if policies == nil {
+		policies = middleware.NewRoutePolicies()
+	}
+	apiMiddleware := newAPIMiddleware(config, policies)
 
-	// 应用API级别中间件
-	setupAPIMiddleware(v1, config)
+	// 系统信息，初始化API接口时记录各接口注册的路由数
+	info := config.SystemInfo
+	if info == nil {
+		info = defaultSystemInfo()
+	}
 
-	// 初始化所有注册的API接口
-	apiInterfaces := api.GetRegisterAPIInterfaces()
-	for _, apiInterface := range apiInterfaces {
-		apiInterface.InitAPIServiceRoute(v1)
+	// 为每个API版本创建路由组，并初始化注册到该版本的API接口
+	var declared, undocumented []string
+	for _, version := range config.APIConfig.Versions {
+		group := engine.Group("/api/" + version.Name)
+		group.Use(middleware.APIVersionMiddleware(version.Name, versionPolicy(version)))
+		group.Use(apiMiddleware...)
+
+		for _, apiInterface := range api.GetVersionAPIInterfaces(version.Name) {
+			if provider, ok := apiInterface.(api.RoutePolicyProvider); ok {
+				declared = append(declared, setRoutePolicies(policies, group.BasePath(), provider.RoutePolicies(), config)...)
+			}
+			if provider, ok := apiInterface.(api.UndocumentedRoutesProvider); ok {
+				for _, path := range provider.UndocumentedRoutes() {
+					undocumented = append(undocumented, group.BasePath()+path)
+				}
+			}
+			if provider, ok := apiInterface.(api.CacheInvalidationProvider); ok && config.ResponseCache != nil {
+				for eventType, tags := range provider.CacheInvalidations() {
+					config.ResponseCache.InvalidateOn(eventType, tags...)
+				}
+			}
+			routes := len(engine.Routes())
+			apiInterface.InitAPIServiceRoute(group)
+			info.recordAPI(apiInterface, version.Name, len(engine.Routes())-routes)
+		}
+		if config.Batch != nil && config.Batch.Enabled {
+			declared = append(declared, setupBatchRoute(engine, group, policies, config)...)
+		}
 	}
+	checkRoutePolicies(engine, declared)
 
 	// 添加系统级路由
-	setupSystemRoutes(engine)
+	setupSystemRoutes(engine, info, config)
+
+	// 添加性能分析路由
+	setupProfilingRoutes(engine, config)
 
 	// 添加Swagger文档路由
 	setupSwaggerRoutes(engine)
+
+	if config.CheckRouteDocs {
+		checkRouteDocs(engine, api.SwaggerSpec(), undocumented)
+	}
 }
 
 // setupGlobalMiddleware 设置全局中间件
@@ -115,14 +205,41 @@ func setupGlobalMiddleware(engine *gin.Engine, config *RouterConfig) {
 	loggerManager := logger.NewManager(&logger.LogConfig{})
 
 	// 请求ID中间件（最先执行）
-	engine.Use(infra_middleware.GinMiddleware(infra_middleware.NewRequestIDMiddleware()))
+	requestIDConfig := infra_middleware.RequestIDConfig{}
+	if config.RequestID != nil {
+		requestIDConfig = *config.RequestID
+	}
+	engine.Use(infra_middleware.GinMiddleware(infra_middleware.NewRequestIDMiddlewareWithConfig(requestIDConfig)))
+
+	// 请求作用域中间件，使请求作用域bean（如翻译器）可在处理器中解析
+	if config.Container != nil {
+		engine.Use(container.RequestScopeMiddleware(config.Container))
+	}
+
+	// 链路上下文中间件
+	engine.Use(middleware.TraceContext())
 
 	// 日志中间件
 	engine.Use(infra_middleware.GinMiddleware(infra_middleware.NewLoggerMiddleware(loggerManager)))
 
+	// 访问日志分析中间件，在请求结束后将请求记录到分析数据接收器
+	if config.AccessLog != nil {
+		engine.Use(middleware.AccessLog(config.AccessLog, config.Clock))
+	}
+
+	// 网络访问控制中间件（在日志之后，以便记录被拦截的请求；对所有路由生效）
+	if config.NetworkACL.Enabled() {
+		engine.Use(middleware.NetworkACLMiddleware(config.NetworkACL, config.NetworkACLAudit, config.Clock))
+	}
+
 	// 恢复中间件
 	engine.Use(middleware.Recovery())
 
+	// 错误上报中间件（位于恢复中间件之内，以便捕获panic后交由其响应）
+	if config.ErrorReporter != nil {
+		engine.Use(middleware.ErrorReporting(config.ErrorReporter))
+	}
+
 	// 安全响应头中间件
 	if config.EnableSecurity {
 		engine.Use(middleware.SecurityHeadersMiddleware())
@@ -138,41 +255,130 @@ func setupGlobalMiddleware(engine *gin.Engine, config *RouterConfig) {
 	engine.Use(infra_middleware.GinMiddleware(infra_middleware.NewErrorHandlerMiddleware()))
 }
 
-// setupAPIMiddleware 设置API级别中间件
-func setupAPIMiddleware(rg *gin.RouterGroup, config *RouterConfig) {
+// newAPIMiddleware 创建API级别中间件，路由策略中间件最先执行，供之后的中间件读取当前路由的策略
+func newAPIMiddleware(config *RouterConfig, policies *middleware.RoutePolicies) gin.HandlersChain {
+	handlers := gin.HandlersChain{middleware.RoutePolicyMiddleware(policies)}
+	if config.LoadShedding != nil && config.LoadShedding.Enabled {
+		// 过载保护中间件（最先执行，使被丢弃的请求尽量少占用资源）
+		handlers = append(handlers, middleware.LoadSheddingMiddleware(config.LoadShedding))
+	}
+	if config.BotDetection.Enabled() {
+		// 人机识别中间件（在限流和认证之前，以便对登录和注册等公开路由生效）
+		handlers = append(handlers, middleware.BotDetectionMiddleware(config.BotDetection))
+	}
+	if config.LoginThrottle.Enabled() {
+		// 登录防暴力破解中间件（与通用限流分开，按用户名和IP统计登录失败次数）
+		handlers = append(handlers, middleware.LoginThrottleMiddleware(config.LoginThrottle, config.BotDetection))
+	}
 	if config.EnableSecurity {
 		// 输入验证中间件
-		rg.Use(middleware.InputValidationMiddleware())
+		handlers = append(handlers, middleware.InputValidationMiddleware())
 
 		// 限流中间件
-		rg.Use(middleware.RateLimitMiddleware(config.SecurityConfig))
+		handlers = append(handlers, middleware.RateLimitMiddleware(config.SecurityConfig))
 
 		// CSRF防护中间件
-		rg.Use(middleware.CSRFMiddleware(config.SecurityConfig))
+		handlers = append(handlers, middleware.CSRFMiddleware(config.SecurityConfig))
+	}
+
+	if config.Signing != nil && config.Partners != nil {
+		// 请求签名中间件（在认证之前，签名校验通过的合作方请求无需令牌）
+		handlers = append(handlers, middleware.SignatureMiddleware(config.Signing, config.Partners, int64(config.SecurityConfig.Signing.MaxBodySize)))
 	}
 
 	if config.EnableAuth {
+		if config.ImpersonationAudit != nil {
+			// 模拟请求审计中间件（在认证之前，被角色、同意或配额拒绝的模拟请求同样记录）
+			handlers = append(handlers, middleware.ImpersonationAuditMiddleware(config.ImpersonationAudit, config.Clock))
+		}
+
 		// JWT认证中间件
-		rg.Use(middleware.JWTAuthMiddleware(config.SecurityConfig))
+		handlers = append(handlers, middleware.JWTAuthMiddleware(config.SecurityConfig, config.Clock))
+
+		if config.Denylist != nil && config.Sessions != nil {
+			// 会话中间件（认证之后，拒绝已终止会话的访问令牌）
+			handlers = append(handlers, middleware.SessionMiddleware(config.Denylist, config.Sessions))
+		}
+
+		if config.Preferences != nil {
+			// 用户偏好中间件（认证之后，按用户应用偏好的语言和时区）
+			handlers = append(handlers, middleware.PreferencesMiddleware(config.Preferences))
+		}
+
+		if config.Organizations != nil {
+			// 组织中间件（认证之后，解析当前组织并检查成员身份）
+			handlers = append(handlers, middleware.OrganizationMiddleware(config.Organizations, config.OrganizationHeader))
+		}
 	}
+
+	if config.Consent != nil {
+		// 政策同意中间件（认证之后，以便按用户检查；未接受的请求不计入配额）
+		handlers = append(handlers, middleware.ConsentMiddleware(config.Consent))
+	}
+
+	if config.Quota != nil {
+		// 配额中间件（认证之后，以便按用户计数）
+		handlers = append(handlers, middleware.QuotaMiddleware(config.Quota))
+	}
+
+	if config.FeatureFlags != nil {
+		// 特性开关中间件（认证之后，以便按用户求值）
+		handlers = append(handlers, middleware.FeatureFlagMiddleware(config.FeatureFlags))
+	}
+
+	if config.Experiments != nil {
+		// 实验分组中间件
+		handlers = append(handlers, middleware.ExperimentMiddleware(config.Experiments))
+	}
+
+	if config.StrictJSON {
+		// 严格JSON中间件（处理器绑定请求体时拒绝未知字段，路由策略可单独允许）
+		handlers = append(handlers, middleware.StrictJSONMiddleware())
+	}
+
+	if config.ResponseCache != nil {
+		// 响应缓存中间件（认证之后，以便按用户区分；命中时不再合并或执行处理器）
+		handlers = append(handlers, middleware.ResponseCacheMiddleware(config.ResponseCache))
+	}
+
+	if config.Coalescing != nil && config.Coalescing.Enabled {
+		// 请求合并中间件（最后执行，合并的请求各自经过认证并计入配额）
+		handlers = append(handlers, middleware.CoalescingMiddleware(config.Coalescing))
+	}
+	return handlers
+}
+
+// setupBatchRoute 设置版本的批量请求端点，子请求经由引擎分发到该版本的路由。
+// 子请求各自计入配额，批量请求本身不再计入
+func setupBatchRoute(engine *gin.Engine, group *gin.RouterGroup, policies *middleware.RoutePolicies, config *RouterConfig) []string {
+	batchHandler := handler.NewBatchHandler(engine, group.BasePath(), *config.Batch)
+	group.POST(handler.BatchPath, batchHandler.Batch)
+	return setRoutePolicies(policies, group.BasePath(), map[string]middleware.RoutePolicy{
+		"POST " + handler.BatchPath: {Quota: middleware.QuotaNone},
+	}, config)
 }
 
 // setupSystemRoutes 设置系统路由
-func setupSystemRoutes(engine *gin.Engine) {
+func setupSystemRoutes(engine *gin.Engine, info *SystemInfo, config *RouterConfig) {
 	// 根级健康检查
 	engine.GET("/health", healthCheck)
 
 	// 系统信息
-	engine.GET("/info", systemInfo)
+	engine.GET("/info", systemInfo(info))
 
-	// 性能指标
-	engine.GET("/metrics", infra_middleware.MetricsHandler())
+	// 性能指标，推送或OTLP导出模式下不提供抓取端点
+	if config.MetricsPath != "" {
+		engine.GET(config.MetricsPath, infra_middleware.MetricsHandler())
+	}
 }
 
-// setupSwaggerRoutes 设置Swagger文档路由
-func setupSwaggerRoutes(engine *gin.Engine) {
-	// 这里可以添加Swagger UI路由
-	// engine.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+// setupProfilingRoutes 设置性能分析路由，位于API分组之外，
+// 来源IP在允许列表中或携带管理员令牌时才可访问
+func setupProfilingRoutes(engine *gin.Engine, config *RouterConfig) {
+	if config.PProf == nil {
+		return
+	}
+	config.PProf.RegisterRoutes(engine, middleware.AllowIPOrRole(config.SecurityConfig, config.Clock, config.PProfAllowedIPs, "admin"))
 }
 
 // healthCheck 健康检查处理器
@@ -181,15 +387,15 @@ func setupSwaggerRoutes(engine *gin.Engine) {
 // @Tags 系统
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.Response{data=v1.HealthCheckResponse} "系统正常"
-// @Failure 500 {object} response.Response{error=string} "系统异常"
+// @Success 200 {object} v1.HealthCheckResponseEnvelope "系统正常"
+// @Failure 500 {object} v1.ErrorEnvelope "系统异常"
 // @Router /health [get]
 func healthCheck(c *gin.Context) {
 	healthResp := v1.HealthCheckResponse{
 		Status:    "ok",
 		Message:   "系统运行正常",
 		Version:   "1.0.0",
-		Timestamp: time.Now(),
+		Timestamp: timestamp.Now(),
 		Details: map[string]interface{}{
 			"uptime":   time.Since(time.Now()).String(),
 			"database": "connected",
@@ -204,31 +410,16 @@ func healthCheck(c *gin.Context) {
 
 // systemInfo 系统信息处理器
 // @Summary 获取系统信息
-// @Description 获取系统基本信息
+// @Description 获取服务名称、构建的版本、提交和时间，以及模块和API接口的启用情况
 // @Tags 系统
 // @Accept json
 // @Produce json
-// @Success 200 {object} response.Response{data=map[string]interface{}} "获取成功"
+// @Success 200 {object} SystemInfoEnvelope "获取成功"
 // @Router /info [get]
-func systemInfo(c *gin.Context) {
-	info := map[string]interface{}{
-		"service_name": "server-tpl",
-		"version":      "1.0.0",
-		"build_time":   "2024-01-01T00:00:00Z",
-		"go_version":   "1.21.0",
-		"git_commit":   "unknown",
-		"environment":  gin.Mode(),
-		"features": map[string]bool{
-			"authentication":       true,
-			"authorization":        true,
-			"rate_limiting":        true,
-			"csrf_protection":      true,
-			"file_upload":          true,
-			"internationalization": true,
-		},
+func systemInfo(info *SystemInfo) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response.Success(c, info)
 	}
-
-	response.Success(c, info)
 }
 
 // RegisterRoutes 注册路由（向后兼容）
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// apiPrefix API路由的路径前缀
+const apiPrefix = "/api/"
+
+// versionDateLayout 版本弃用和下线日期的格式
+const versionDateLayout = "2006-01-02"
+
+// versionPolicy 将版本配置转换为弃用策略，配置校验已保证日期格式正确
+func versionPolicy(version config.APIVersionConfig) middleware.VersionPolicy {
+	policy := middleware.VersionPolicy{Link: version.Link}
+	if version.DeprecatedSince != "" {
+		if t, err := time.Parse(versionDateLayout, version.DeprecatedSince); err == nil {
+			policy.DeprecatedSince = t
+		} else {
+			logger.Warn("Ignoring deprecated_since of API %s: %v", version.Name, err)
+		}
+	}
+	if version.Sunset != "" {
+		if t, err := time.Parse(versionDateLayout, version.Sunset); err == nil {
+			policy.Sunset = t
+		} else {
+			logger.Warn("Ignoring sunset of API %s: %v", version.Name, err)
+		}
+	}
+	return policy
+}
+
+// VersionNegotiation 在路由之前将不带版本的/api请求改写到/api/{version}，
+// 版本取自Accept请求头（见 middleware.AcceptedVersion），未指定时使用默认版本
+func VersionNegotiation(next http.Handler, apiConfig *config.APIConfig) http.Handler {
+	versions := make(map[string]bool, len(apiConfig.Versions))
+	for _, version := range apiConfig.Versions {
+		versions[version.Name] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, apiPrefix)
+		if !ok || versions[strings.SplitN(rest, "/", 2)[0]] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version := middleware.AcceptedVersion(r.Header.Get("Accept"))
+		if version == "" {
+			version = apiConfig.DefaultVersion
+		}
+		w.Header().Add("Vary", "Accept")
+
+		// 请求的版本不存在时改写后的路径没有路由，返回404
+		r.URL.Path = apiPrefix + version + "/" + rest
+		if r.URL.RawPath != "" {
+			r.URL.RawPath = apiPrefix + version + "/" + strings.TrimPrefix(r.URL.RawPath, apiPrefix)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,132 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+	applicationv1 "github.com/make-bin/server-tpl/pkg/api/proto/application/v1"
+	"github.com/make-bin/server-tpl/pkg/api/validation"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	// defaultPageSize 未指定时的分页大小，与REST接口一致
+	defaultPageSize = 10
+	// maxPageSize 分页大小上限，与REST接口一致
+	maxPageSize = 100
+)
+
+// ApplicationServer 应用服务的proto实现，网关在进程内调用，也可以注册到gRPC服务器
+type ApplicationServer struct {
+	applicationv1.UnimplementedApplicationServiceServer
+	applicationService service.ApplicationServiceInterface
+	validator          *validator.Validate
+}
+
+// NewApplicationServer 创建应用服务的proto实现
+func NewApplicationServer(applicationService service.ApplicationServiceInterface) *ApplicationServer {
+	v := validator.New()
+	validation.RegisterCustomValidators(v)
+	return &ApplicationServer{applicationService: applicationService, validator: v}
+}
+
+// GetApplication 根据ID获取应用
+func (s *ApplicationServer) GetApplication(ctx context.Context, req *applicationv1.GetApplicationRequest) (*applicationv1.Application, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	app, err := s.applicationService.GetApplicationByID(ctx, uint(req.GetId()))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toApplication(app), nil
+}
+
+// ListApplications 分页获取匹配标签选择器的应用
+func (s *ApplicationServer) ListApplications(ctx context.Context, req *applicationv1.ListApplicationsRequest) (*applicationv1.ListApplicationsResponse, error) {
+	page, size := int(req.GetPage()), int(req.GetPageSize())
+	if page == 0 {
+		page = 1
+	}
+	if size == 0 {
+		size = defaultPageSize
+	}
+	if page < 1 {
+		return nil, status.Error(codes.InvalidArgument, "page must be at least 1")
+	}
+	if size < 1 || size > maxPageSize {
+		return nil, status.Error(codes.InvalidArgument, fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+	}
+	selector, err := model.ParseTagSelector(req.GetTags())
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	apps, total, err := s.applicationService.ListApplicationsByTags(ctx, selector, page, size)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	resp := &applicationv1.ListApplicationsResponse{
+		Applications: make([]*applicationv1.Application, 0, len(apps)),
+		Total:        total,
+		Page:         int32(page),
+		PageSize:     int32(size),
+	}
+	for _, app := range apps {
+		resp.Applications = append(resp.Applications, toApplication(app))
+	}
+	return resp, nil
+}
+
+// CreateApplication 创建应用
+func (s *ApplicationServer) CreateApplication(ctx context.Context, req *applicationv1.CreateApplicationRequest) (*applicationv1.Application, error) {
+	in := req.GetApplication()
+	if in == nil {
+		return nil, status.Error(codes.InvalidArgument, "application is required")
+	}
+	// 与REST接口使用相同的校验规则
+	values := map[string]string{"name": in.GetName(), "description": in.GetDescription()}
+	for _, rule := range validation.GetValidationRules("application") {
+		if err := validation.ValidateVar(s.validator, values[rule.Field], rule.Rule); err != nil {
+			return nil, status.Error(codes.InvalidArgument, rule.Field+": "+rule.Message)
+		}
+	}
+	app, err := s.applicationService.CreateApplication(ctx, &model.Application{
+		Name:        in.GetName(),
+		Description: in.GetDescription(),
+		Tags:        in.GetTags(),
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toApplication(app), nil
+}
+
+// DeleteApplication 删除应用及其关联数据
+func (s *ApplicationServer) DeleteApplication(ctx context.Context, req *applicationv1.DeleteApplicationRequest) (*emptypb.Empty, error) {
+	if req.GetId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "id is required")
+	}
+	if err := s.applicationService.DeleteApplication(ctx, uint(req.GetId())); err != nil {
+		return nil, statusError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// toApplication 将领域模型转换为proto消息
+func toApplication(app *model.Application) *applicationv1.Application {
+	return &applicationv1.Application{
+		Id:          uint64(app.ID),
+		Name:        app.Name,
+		Description: app.Description,
+		Tags:        app.Tags,
+		CreateTime:  timestamppb.New(app.CreatedAt.Time),
+		UpdateTime:  timestamppb.New(app.UpdatedAt.Time),
+	}
+}
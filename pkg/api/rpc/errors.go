@@ -0,0 +1,28 @@
+package rpc
+
+import (
+	"errors"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// statusError 将领域错误转换为gRPC状态，其他错误记录日志后只返回通用消息，避免泄露内部细节
+func statusError(err error) error {
+	var domainErr *model.DomainError
+	switch {
+	case errors.Is(err, model.ErrApplicationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, model.ErrApplicationNameExists):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, model.ErrAccessDenied):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.As(err, &domainErr):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		logger.Error("RPC failed: %v", err)
+		return status.Error(codes.Internal, "internal error")
+	}
+}
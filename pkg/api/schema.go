@@ -0,0 +1,42 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+)
+
+// schemaAPI 文档类型API，发布v1 DTO的JSON Schema和示例，类型列表由 go generate ./pkg/api 生成
+type schemaAPI struct {
+	handler *handler.SchemaHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newSchemaAPI())
+}
+
+// newSchemaAPI 创建文档类型API
+func newSchemaAPI() APIInterface {
+	return &schemaAPI{}
+}
+
+// RoutePolicies 文档类型与API文档一样公开，无需访问令牌
+func (a *schemaAPI) RoutePolicies() map[string]middleware.RoutePolicy {
+	return map[string]middleware.RoutePolicy{
+		"GET /_schema":       {Public: true},
+		"GET /_schema/:type": {Public: true},
+	}
+}
+
+// InitAPIServiceRoute 初始化文档类型路由
+func (a *schemaAPI) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	a.handler = handler.NewSchemaHandler(v1.Types)
+
+	schemaGroup := rg.Group("/_schema")
+	{
+		schemaGroup.GET("", a.handler.ListTypes)
+		schemaGroup.GET("/:type", a.handler.GetType)
+	}
+}
@@ -0,0 +1,350 @@
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Draft 生成的JSON Schema版本
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// maxExampleDepth 生成示例时展开嵌套结构体的最大深度
+const maxExampleDepth = 8
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage(nil))
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Schema JSON Schema文档或其中的子模式
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	Examples             []interface{}      `json:"examples,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Generate 由结构体生成JSON Schema，字段名取自json标签，约束取自binding标签，示例取自example标签；
+// 嵌套的结构体放在 $defs 中以 $ref 引用。名称以Request结尾的类型只有binding:"required"的字段必填，
+// 其他类型（响应）中没有omitempty的字段总会输出，也是必填；可为nil的指针、切片和映射允许null
+func Generate(v interface{}) *Schema {
+	t := indirect(reflect.TypeOf(v))
+	g := &generator{root: t, defs: make(map[string]*Schema)}
+	s := g.structSchema(t)
+	s.Schema = Draft
+	s.Title = t.Name()
+	if len(g.defs) > 0 {
+		s.Defs = g.defs
+	}
+	return s
+}
+
+// Example 由结构体生成示例数据，字段取example标签的值，没有example标签的字段取其类型的零值，
+// 切片包含一个元素的示例
+func Example(v interface{}) interface{} {
+	return example(indirect(reflect.TypeOf(v)), "", 0)
+}
+
+// generator 生成一个类型的JSON Schema，记录引用的结构体
+type generator struct {
+	root reflect.Type
+	defs map[string]*Schema
+}
+
+// typeSchema 返回类型的模式，binding 和 exampleTag 为字段的binding规则和example标签
+func (g *generator) typeSchema(t reflect.Type, binding []string, exampleTag string) *Schema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case isText(t):
+		// 以文本编码的类型（如 v1.SortExpression）的binding规则约束解析后的值，不对应字符串约束
+		s = &Schema{Type: "string"}
+		if exampleTag != "" {
+			s.Examples = []interface{}{exampleTag}
+		}
+		return s
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		s = &Schema{}
+	case t.Kind() == reflect.Struct:
+		s = g.ref(t)
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s = &Schema{Type: "string", Format: "byte"}
+			break
+		}
+		var elem []string
+		if i := slices.Index(binding, "dive"); i >= 0 {
+			binding, elem = binding[:i], binding[i+1:]
+		}
+		s = &Schema{Type: "array", Items: g.typeSchema(t.Elem(), elem, "")}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem(), nil, "")}
+	default:
+		s = &Schema{Type: jsonType(t)}
+	}
+
+	constrain(s, t, binding)
+	if exampleTag != "" && s.Ref == "" {
+		s.Examples = []interface{}{exampleValue(t, exampleTag)}
+	}
+	return s
+}
+
+// ref 返回结构体的引用，首次引用时在 $defs 中生成它的模式；根类型以 # 引用
+func (g *generator) ref(t reflect.Type) *Schema {
+	if t == g.root {
+		return &Schema{Ref: "#"}
+	}
+	name := t.Name()
+	if name == "" {
+		return g.structSchema(t)
+	}
+	if _, ok := g.defs[name]; !ok {
+		g.defs[name] = nil // 占位，避免循环引用时重复生成
+		g.defs[name] = g.structSchema(t)
+	}
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// structSchema 返回结构体的对象模式，匿名嵌入的结构体字段展开到外层
+func (g *generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	request := strings.HasSuffix(t.Name(), "Request")
+	for _, f := range fields(t) {
+		property := g.typeSchema(f.typ, f.binding, f.example)
+		nullable := !f.omitempty && (f.typ.Kind() == reflect.Pointer || f.typ.Kind() == reflect.Slice || f.typ.Kind() == reflect.Map)
+		if nullable && f.typ != rawMessageType && (f.typ.Kind() == reflect.Pointer || !isText(f.typ)) {
+			property = &Schema{AnyOf: []*Schema{property, {Type: "null"}}}
+		}
+		s.Properties[f.name] = property
+		rules := f.binding
+		if i := slices.Index(rules, "dive"); i >= 0 {
+			rules = rules[:i]
+		}
+		if (request && slices.Contains(rules, "required")) || (!request && !f.omitempty) {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+// field 结构体在JSON中的字段
+type field struct {
+	name      string
+	typ       reflect.Type
+	omitempty bool
+	binding   []string
+	example   string
+}
+
+// fields 按encoding/json的规则返回结构体的字段，展开匿名嵌入的结构体
+func fields(t reflect.Type) []field {
+	var result []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" && indirect(f.Type).Kind() == reflect.Struct {
+			result = append(result, fields(indirect(f.Type))...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		var binding []string
+		if b := f.Tag.Get("binding"); b != "" {
+			binding = strings.Split(b, ",")
+		}
+		result = append(result, field{
+			name:      name,
+			typ:       f.Type,
+			omitempty: strings.Contains(","+options+",", ",omitempty,"),
+			binding:   binding,
+			example:   f.Tag.Get("example"),
+		})
+	}
+	return result
+}
+
+// constrain 将binding标签的长度、范围、枚举和格式约束加到模式上
+func constrain(s *Schema, t reflect.Type, binding []string) {
+	for _, rule := range binding {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "email":
+			s.Format = "email"
+		case "url":
+			s.Format = "uri"
+		case "uuid":
+			s.Format = "uuid"
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				s.Enum = append(s.Enum, exampleValue(t, v))
+			}
+		case "min", "max", "len", "gte", "lte":
+			n, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				continue
+			}
+			lower := name == "min" || name == "gte" || name == "len"
+			upper := name == "max" || name == "lte" || name == "len"
+			switch s.Type {
+			case "string":
+				if lower {
+					s.MinLength = intPtr(n)
+				}
+				if upper {
+					s.MaxLength = intPtr(n)
+				}
+			case "array":
+				if lower {
+					s.MinItems = intPtr(n)
+				}
+				if upper {
+					s.MaxItems = intPtr(n)
+				}
+			case "integer", "number":
+				if lower {
+					s.Minimum = &n
+				}
+				if upper {
+					s.Maximum = &n
+				}
+			}
+		}
+	}
+}
+
+// example 返回类型的示例，tag 为字段的example标签
+func example(t reflect.Type, tag string, depth int) interface{} {
+	t = indirect(t)
+	if tag != "" {
+		return exampleValue(t, tag)
+	}
+	switch {
+	case t == timeType:
+		return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).Format(time.RFC3339)
+	case isText(t):
+		text, _ := reflect.New(t).Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text)
+	case t == rawMessageType || t.Kind() == reflect.Interface:
+		return nil
+	case t.Kind() == reflect.Struct:
+		if depth > maxExampleDepth {
+			return nil
+		}
+		object := make(map[string]interface{})
+		for _, f := range fields(t) {
+			object[f.name] = example(f.typ, f.example, depth+1)
+		}
+		return object
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return ""
+		}
+		if depth > maxExampleDepth {
+			return []interface{}{}
+		}
+		return []interface{}{example(t.Elem(), "", depth+1)}
+	case t.Kind() == reflect.Map:
+		return map[string]interface{}{}
+	}
+	return reflect.Zero(t).Interface()
+}
+
+// exampleValue 将example标签转换为类型的值，切片的示例以逗号分隔；无法转换时返回原字符串
+func exampleValue(t reflect.Type, tag string) interface{} {
+	t = indirect(t)
+	if isText(t) {
+		return tag
+	}
+	if (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8 {
+		var values []interface{}
+		for _, v := range strings.Split(tag, ",") {
+			values = append(values, exampleValue(t.Elem(), v))
+		}
+		return values
+	}
+	switch jsonType(t) {
+	case "integer":
+		if n, err := strconv.ParseInt(tag, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(tag, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(tag); err == nil {
+			return b
+		}
+	}
+	return tag
+}
+
+// jsonType 返回基本类型在JSON Schema中的类型
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.String:
+		return "string"
+	}
+	return ""
+}
+
+// isText 类型（除time.Time外）实现 encoding.TextMarshaler 时返回true，它在JSON中编码为字符串
+func isText(t reflect.Type) bool {
+	return t != timeType && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType))
+}
+
+// indirect 返回指针指向的类型
+func indirect(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
+
+// intPtr 返回整数指针
+func intPtr(n float64) *int {
+	i := int(n)
+	return &i
+}
@@ -0,0 +1,88 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// UnknownFields 返回JSON数据中结构体没有的字段，如 descripton、tags[0].nme，按路径排序；
+// 字段名与encoding/json一样不区分大小写，嵌套的对象和数组逐层检查，映射、interface{}
+// 和自行实现json.Unmarshaler的类型可包含任意字段；data 不是合法JSON时返回错误
+func UnknownFields(v interface{}, data []byte) ([]string, error) {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	var unknown []string
+	collectUnknown(reflect.TypeOf(v), value, "", &unknown)
+	sort.Strings(unknown)
+	return unknown, nil
+}
+
+// collectUnknown 将 value 中类型 t 没有的字段路径加入 unknown
+func collectUnknown(t reflect.Type, value interface{}, path string, unknown *[]string) {
+	t = indirect(t)
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		known := fields(t)
+		for key, v := range object {
+			f, ok := lookupField(known, key)
+			if !ok {
+				*unknown = append(*unknown, joinPath(path, key))
+				continue
+			}
+			collectUnknown(f.typ, v, joinPath(path, key), unknown)
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return
+		}
+		for i, item := range items {
+			collectUnknown(t.Elem(), item, path+"["+strconv.Itoa(i)+"]", unknown)
+		}
+	case reflect.Map:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, v := range object {
+			collectUnknown(t.Elem(), v, joinPath(path, key), unknown)
+		}
+	}
+}
+
+// lookupField 按encoding/json的规则查找字段，优先精确匹配，其次不区分大小写
+func lookupField(known []field, key string) (field, bool) {
+	for _, f := range known {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range known {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// joinPath 返回嵌套字段的路径
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/handler"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/principal"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// session 支持依赖注入的会话API结构
+type session struct {
+	Config         *config.Config                  `inject:"config"`
+	Clock          clock.Clock                     `inject:"clock"`
+	SessionService service.SessionServiceInterface `inject:""`
+	UserService    service.UserServiceInterface    `inject:""`
+	handler        *handler.SessionHandler
+}
+
+// init 注册API接口
+func init() {
+	RegisterAPIInterface(newSession())
+}
+
+// newSession 创建依赖注入版本的会话API
+func newSession() APIInterface {
+	return &session{}
+}
+
+// Module 所属的认证模块，模块禁用时不注册路由
+func (a *session) Module() string {
+	return module.Auth
+}
+
+// RoutePolicies 登录和刷新会话无需访问令牌，凭证在请求体中而非Cookie中，无需CSRF防护；
+// 两者都校验凭证，失败次数受登录防暴力破解限制，刷新令牌没有用户名，只按客户端IP计数
+func (a *session) RoutePolicies() map[string]middleware.RoutePolicy {
+	if !a.enabled() {
+		return nil
+	}
+	return map[string]middleware.RoutePolicy{
+		"POST /auth/login":   {Public: true, CSRFExempt: true, Login: true, Challenge: true},
+		"POST /auth/refresh": {Public: true, CSRFExempt: true, Login: true},
+	}
+}
+
+// InitAPIServiceRoute 初始化会话API路由，未启用时不注册路由
+func (a *session) InitAPIServiceRoute(rg *gin.RouterGroup) {
+	if !a.enabled() {
+		return
+	}
+	a.handler = handler.NewSessionHandler(a.SessionService, &a.Config.Security, a.Clock)
+
+	sessionGroup := rg.Group("/users/me/sessions", middleware.PublicIDMiddleware("id", a.resolveSessionID, model.ErrSessionNotFound, "session_not_found"))
+	{
+		sessionGroup.GET("", a.handler.ListSessions)
+		sessionGroup.DELETE("", a.handler.DeleteOtherSessions)
+		sessionGroup.DELETE("/:id", a.handler.DeleteSession)
+	}
+
+	rg.POST("/auth/login", handler.NewLoginHandler(a.UserService, a.handler).Login)
+	rg.POST("/auth/refresh", a.handler.Refresh)
+}
+
+// resolveSessionID 返回当前用户公开ID对应的会话ID，其他用户的会话不存在
+func (a *session) resolveSessionID(c *gin.Context, publicID string) (uint, error) {
+	user, _ := principal.CurrentUser(c)
+	return a.SessionService.ResolveSessionID(c.Request.Context(), user.UserID, publicID)
+}
+
+// enabled 判断是否启用会话
+func (a *session) enabled() bool {
+	return a.Config != nil && a.Config.Security.Sessions.Enabled && a.SessionService != nil && a.UserService != nil
+}
@@ -0,0 +1,120 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ContentType SSE响应的媒体类型
+	ContentType = "text/event-stream"
+	// LastEventIDHeader 客户端重连时携带最后收到的事件ID的请求头
+	LastEventIDHeader = "Last-Event-ID"
+	// DefaultHeartbeat 默认心跳间隔，需小于代理的空闲超时
+	DefaultHeartbeat = 15 * time.Second
+	// DefaultRetry 建议客户端断线后的重连间隔
+	DefaultRetry = 3 * time.Second
+)
+
+// writeTimeout 单次写入的超时，流式响应不受服务器WriteTimeout限制
+const writeTimeout = 30 * time.Second
+
+// Event SSE事件
+type Event struct {
+	// ID 事件ID，客户端重连时通过Last-Event-ID请求头带回
+	ID string
+	// Event 事件类型，为空时客户端按message处理
+	Event string
+	// Data 事件数据，字符串原样发送，其余类型编码为JSON
+	Data interface{}
+}
+
+// Stream SSE流写入器
+type Stream struct {
+	writer     gin.ResponseWriter
+	controller *http.ResponseController
+}
+
+// NewStream 发送SSE响应头并开始事件流，retry为建议客户端使用的重连间隔
+func NewStream(c *gin.Context, retry time.Duration) (*Stream, error) {
+	c.Header("Content-Type", ContentType)
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// 禁止反向代理缓冲事件
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	s := &Stream{writer: c.Writer, controller: http.NewResponseController(c.Writer)}
+	if retry > 0 {
+		if err := s.write(fmt.Sprintf("retry: %d\n\n", retry.Milliseconds())); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Send 发送一个事件
+func (s *Stream) Send(event Event) error {
+	var data string
+	switch v := event.Data.(type) {
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	var buf strings.Builder
+	if event.ID != "" {
+		fmt.Fprintf(&buf, "id: %s\n", singleLine(event.ID))
+	}
+	if event.Event != "" {
+		fmt.Fprintf(&buf, "event: %s\n", singleLine(event.Event))
+	}
+	// 多行数据按行拆分为多个data字段
+	for _, line := range strings.Split(strings.ReplaceAll(data, "\r\n", "\n"), "\n") {
+		fmt.Fprintf(&buf, "data: %s\n", line)
+	}
+	buf.WriteString("\n")
+	return s.write(buf.String())
+}
+
+// Heartbeat 发送注释行保持连接，客户端会忽略它
+func (s *Stream) Heartbeat() error {
+	return s.write(": heartbeat\n\n")
+}
+
+// write 写入并立即刷新，每次写入前延长写超时
+func (s *Stream) write(text string) error {
+	// 不支持写超时的ResponseWriter忽略该错误
+	_ = s.controller.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := s.writer.WriteString(text); err != nil {
+		return err
+	}
+	s.writer.Flush()
+	return nil
+}
+
+// LastEventID 返回客户端最后收到的事件ID，优先读取Last-Event-ID请求头，
+// 其次读取last_event_id查询参数（用于无法设置请求头的客户端）
+func LastEventID(c *gin.Context) string {
+	if id := c.GetHeader(LastEventIDHeader); id != "" {
+		return id
+	}
+	return c.Query("last_event_id")
+}
+
+// singleLine 去除字段值中的换行，避免破坏事件格式
+func singleLine(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
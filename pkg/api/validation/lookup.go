@@ -0,0 +1,242 @@
+package validation
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	"golang.org/x/sync/errgroup"
+)
+
+// LookupTag 声明数据存储校验的字段标签，格式为 规则=查询名，如 lookup:"unique=application_name"；
+// 切片字段校验每个元素，空值不检查
+const LookupTag = "lookup"
+
+// 数据存储校验的规则，也是校验错误的标签
+const (
+	// LookupExists 值必须已存在，如引用的ID
+	LookupExists = "exists"
+	// LookupUnique 值必须尚未使用，如应用名称
+	LookupUnique = "unique"
+)
+
+// LookupFunc 批量查询数据存储，返回 values 中已存在的值
+type LookupFunc func(ctx context.Context, values []interface{}) ([]interface{}, error)
+
+// Lookup 以类型化的批量查询创建 LookupFunc，类型不是 T 的值视为不存在
+func Lookup[T comparable](fn func(ctx context.Context, values []T) ([]T, error)) LookupFunc {
+	return func(ctx context.Context, values []interface{}) ([]interface{}, error) {
+		typed := make([]T, 0, len(values))
+		for _, value := range values {
+			if v, ok := value.(T); ok {
+				typed = append(typed, v)
+			}
+		}
+		existing, err := fn(ctx, typed)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]interface{}, len(existing))
+		for i, v := range existing {
+			result[i] = v
+		}
+		return result, nil
+	}
+}
+
+// Lookups 请求DTO的数据存储校验，在绑定和字段校验之后、调用服务之前执行；
+// 查询按名称注册，由字段的 lookup 标签引用
+type Lookups struct {
+	funcs map[string]LookupFunc
+}
+
+// NewLookups 创建没有注册查询的数据存储校验
+func NewLookups() *Lookups {
+	return &Lookups{funcs: make(map[string]LookupFunc)}
+}
+
+// Register 注册名为 name 的查询，已有的同名查询被替换
+func (l *Lookups) Register(name string, fn LookupFunc) {
+	l.funcs[name] = fn
+}
+
+// lookupCheck 一个字段值的数据存储校验
+type lookupCheck struct {
+	namespace string
+	field     string
+	rule      string
+	lookup    string
+	value     reflect.Value
+}
+
+// Validate 执行 obj（结构体指针）中 lookup 标签声明的校验：同一查询的所有值合并为一次查询，不同的查询并发执行。
+// 校验失败时返回 validator.ValidationErrors，可以和字段校验的错误一样由 response.ParseValidationErrors 处理；
+// 查询失败或标签无效时返回其错误
+func (l *Lookups) Validate(ctx context.Context, obj interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(obj))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("validation: lookups require a struct, got %T", obj)
+	}
+
+	var checks []lookupCheck
+	if err := collectLookups(v, v.Type().Name(), &checks); err != nil {
+		return err
+	}
+	if len(checks) == 0 {
+		return nil
+	}
+
+	// 按查询合并去重后的值
+	values := make(map[string][]interface{})
+	seen := make(map[string]map[interface{}]bool)
+	for _, check := range checks {
+		if _, ok := l.funcs[check.lookup]; !ok {
+			return fmt.Errorf("validation: lookup %q of field %s is not registered", check.lookup, check.namespace)
+		}
+		if seen[check.lookup] == nil {
+			seen[check.lookup] = make(map[interface{}]bool)
+		}
+		value := check.value.Interface()
+		if !seen[check.lookup][value] {
+			seen[check.lookup][value] = true
+			values[check.lookup] = append(values[check.lookup], value)
+		}
+	}
+
+	// 每个查询只写入自己的结果集合
+	existing := make(map[string]map[interface{}]bool, len(values))
+	for name := range values {
+		existing[name] = make(map[interface{}]bool)
+	}
+	group, groupCtx := errgroup.WithContext(ctx)
+	for name := range values {
+		name := name
+		group.Go(func() error {
+			found, err := l.funcs[name](groupCtx, values[name])
+			if err != nil {
+				return fmt.Errorf("validation: lookup %q: %w", name, err)
+			}
+			for _, value := range found {
+				existing[name][value] = true
+			}
+			return nil
+		})
+	}
+	if err := group.Wait(); err != nil {
+		return err
+	}
+
+	var errs validator.ValidationErrors
+	for _, check := range checks {
+		found := existing[check.lookup][check.value.Interface()]
+		if (check.rule == LookupExists && !found) || (check.rule == LookupUnique && found) {
+			errs = append(errs, &lookupError{check: check})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// collectLookups 收集结构体中 lookup 标签声明的校验，展开匿名嵌入的结构体
+func collectLookups(v reflect.Value, namespace string, checks *[]lookupCheck) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fv := v.Field(i)
+		if sf.Anonymous && reflect.Indirect(fv).Kind() == reflect.Struct {
+			if embedded := reflect.Indirect(fv); embedded.IsValid() {
+				if err := collectLookups(embedded, namespace, checks); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		tag, ok := sf.Tag.Lookup(LookupTag)
+		if !ok || !sf.IsExported() {
+			continue
+		}
+		rule, lookup, _ := strings.Cut(tag, "=")
+		if (rule != LookupExists && rule != LookupUnique) || lookup == "" {
+			return fmt.Errorf("validation: invalid lookup tag %q on field %s.%s", tag, namespace, sf.Name)
+		}
+
+		check := lookupCheck{rule: rule, lookup: lookup}
+		fv = reflect.Indirect(fv)
+		switch {
+		case !fv.IsValid():
+		case fv.Kind() == reflect.Slice || fv.Kind() == reflect.Array:
+			for j := 0; j < fv.Len(); j++ {
+				elem := reflect.Indirect(fv.Index(j))
+				if !elem.IsValid() || elem.IsZero() {
+					continue
+				}
+				check.field = sf.Name + "[" + strconv.Itoa(j) + "]"
+				check.namespace = namespace + "." + check.field
+				check.value = elem
+				*checks = append(*checks, check)
+			}
+		case !fv.IsZero():
+			check.field = sf.Name
+			check.namespace = namespace + "." + sf.Name
+			check.value = fv
+			*checks = append(*checks, check)
+		}
+	}
+	return nil
+}
+
+// lookupError 数据存储校验失败的字段错误，实现 validator.FieldError
+type lookupError struct {
+	check lookupCheck
+}
+
+// Tag 返回校验规则（exists 或 unique）
+func (e *lookupError) Tag() string { return e.check.rule }
+
+// ActualTag 返回校验规则
+func (e *lookupError) ActualTag() string { return e.check.rule }
+
+// Namespace 返回字段的完整路径，如 BatchDeleteApplicationsRequest.IDs[0]
+func (e *lookupError) Namespace() string { return e.check.namespace }
+
+// StructNamespace 返回字段的完整路径
+func (e *lookupError) StructNamespace() string { return e.check.namespace }
+
+// Field 返回字段名，切片元素带下标，如 IDs[0]
+func (e *lookupError) Field() string { return e.check.field }
+
+// StructField 返回字段名
+func (e *lookupError) StructField() string { return e.check.field }
+
+// Value 返回校验失败的值
+func (e *lookupError) Value() interface{} { return e.check.value.Interface() }
+
+// Param 返回查询名
+func (e *lookupError) Param() string { return e.check.lookup }
+
+// Kind 返回值的类型种类
+func (e *lookupError) Kind() reflect.Kind { return e.check.value.Kind() }
+
+// Type 返回值的类型
+func (e *lookupError) Type() reflect.Type { return e.check.value.Type() }
+
+// Translate 以 lookup_规则 的翻译返回错误消息，没有翻译时返回 Error()
+func (e *lookupError) Translate(trans ut.Translator) string {
+	message, err := trans.T(LookupTag+"_"+e.check.rule, e.check.field)
+	if err != nil {
+		return e.Error()
+	}
+	return message
+}
+
+// Error 返回与 validator 字段错误格式相同的消息
+func (e *lookupError) Error() string {
+	return fmt.Sprintf("Key: '%s' Error:Field validation for '%s' failed on the '%s' lookup of '%s'", e.check.namespace, e.check.field, e.check.rule, e.check.lookup)
+}
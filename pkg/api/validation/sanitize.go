@@ -0,0 +1,28 @@
+package validation
+
+import (
+	"github.com/gin-gonic/gin/binding"
+	"github.com/make-bin/server-tpl/pkg/utils/sanitize"
+)
+
+// sanitizingValidator 先按 sanitize 标签清理请求DTO的字符串字段再验证的请求绑定验证器
+type sanitizingValidator struct {
+	binding.StructValidator
+}
+
+// NewSanitizingValidator 包装请求绑定的验证器，使 ShouldBind 等绑定在验证前执行 sanitize.Struct；
+// 已包装的验证器原样返回
+func NewSanitizingValidator(v binding.StructValidator) binding.StructValidator {
+	if _, ok := v.(sanitizingValidator); ok {
+		return v
+	}
+	return sanitizingValidator{v}
+}
+
+// ValidateStruct 清理 obj 的字符串字段后验证
+func (v sanitizingValidator) ValidateStruct(obj interface{}) error {
+	if err := sanitize.Struct(obj); err != nil {
+		return err
+	}
+	return v.StructValidator.ValidateStruct(obj)
+}
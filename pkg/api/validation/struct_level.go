@@ -0,0 +1,103 @@
+package validation
+
+import (
+	"cmp"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+)
+
+// pageSortColumns 通用分页请求可排序的字段
+var pageSortColumns = []string{"id", "created_at", "updated_at"}
+
+// RegisterStructValidations 注册请求DTO的跨字段验证，嵌入的结构体（如 v1.PageRequest）也会被验证
+func RegisterStructValidations(v *validator.Validate) {
+	v.RegisterStructValidation(validatePageRequest, v1.PageRequest{})
+	v.RegisterStructValidation(validateBatchDeleteApplications, v1.BatchDeleteApplicationsRequest{})
+	v.RegisterStructValidation(validateDatastoreHistory, v1.DatastoreHistoryRequest{})
+}
+
+// validatePageRequest 指定降序时排序字段只能是通用的可排序字段
+func validatePageRequest(sl validator.StructLevel) {
+	OneOfWhen(sl, "SortBy", pageSortColumns, "SortDesc")
+}
+
+// validateBatchDeleteApplications 删除的应用超过 v1.BatchDeleteForceThreshold 个时必须设置force
+func validateBatchDeleteApplications(sl validator.StructLevel) {
+	RequiredIfLenGreater(sl, "Force", "IDs", v1.BatchDeleteForceThreshold)
+}
+
+// validateDatastoreHistory 起始时间不能晚于结束时间
+func validateDatastoreHistory(sl validator.StructLevel) {
+	FieldsInOrder(sl, "Since", "Until")
+}
+
+// FieldsInOrder 报告 from 字段大于 to 字段的错误（ltefield），字段为时间、数字或字符串及其指针，任一为空时不检查
+func FieldsInOrder(sl validator.StructLevel, from, to string) {
+	a, b := structField(sl, from), structField(sl, to)
+	if !a.IsValid() || !b.IsValid() || a.IsZero() || b.IsZero() {
+		return
+	}
+	if order, ok := compareFields(a, b); ok && order > 0 {
+		sl.ReportError(a.Interface(), from, from, "ltefield", to)
+	}
+}
+
+// OneOfWhen 在 when 字段不为空时要求 field 字段（字符串）为空或是 allowed 之一（oneof）
+func OneOfWhen(sl validator.StructLevel, field string, allowed []string, when string) {
+	condition, value := structField(sl, when), structField(sl, field)
+	if !condition.IsValid() || condition.IsZero() || !value.IsValid() || value.Kind() != reflect.String {
+		return
+	}
+	if s := value.String(); s != "" && !slices.Contains(allowed, s) {
+		sl.ReportError(s, field, field, "oneof", strings.Join(allowed, " "))
+	}
+}
+
+// RequiredIfLenGreater 在 lenField 字段（切片、映射或字符串）的长度超过 max 时要求 field 字段不为空（required_if_len_gt）
+func RequiredIfLenGreater(sl validator.StructLevel, field, lenField string, max int) {
+	value, list := structField(sl, field), structField(sl, lenField)
+	if !list.IsValid() || !value.IsValid() {
+		return
+	}
+	switch list.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Map, reflect.String:
+	default:
+		return
+	}
+	if list.Len() > max && value.IsZero() {
+		sl.ReportError(value.Interface(), field, field, "required_if_len_gt", lenField+" "+strconv.Itoa(max))
+	}
+}
+
+// structField 返回当前结构体的字段，指针字段返回其指向的值，nil指针返回无效值
+func structField(sl validator.StructLevel, name string) reflect.Value {
+	return reflect.Indirect(sl.Current().FieldByName(name))
+}
+
+// compareFields 比较两个同类的字段，返回-1、0或1；类型不可比较时第二个返回值为false
+func compareFields(a, b reflect.Value) (int, bool) {
+	if at, ok := a.Interface().(time.Time); ok {
+		if bt, ok := b.Interface().(time.Time); ok {
+			return at.Compare(bt), true
+		}
+		return 0, false
+	}
+
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int()), true
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case a.CanFloat() && b.CanFloat():
+		return cmp.Compare(a.Float(), b.Float()), true
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), true
+	}
+	return 0, false
+}
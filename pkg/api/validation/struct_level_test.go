@@ -0,0 +1,150 @@
+package validation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	v1 "github.com/make-bin/server-tpl/pkg/api/dto/v1"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+)
+
+type orderedRequest struct {
+	From    *time.Time
+	To      *time.Time
+	Min     int
+	Max     int
+	First   string
+	Last    string
+	Invalid bool
+}
+
+type oneOfWhenRequest struct {
+	Mode   string
+	Strict bool
+	Count  int
+}
+
+type requiredIfLenRequest struct {
+	Confirm bool
+	Items   []string
+	Labels  map[string]string
+	Name    string
+	Amount  int
+}
+
+// newStructLevelValidator 创建使用gin绑定标签并注册了测试结构体和DTO跨字段验证的验证器
+func newStructLevelValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	RegisterStructValidations(v)
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		FieldsInOrder(sl, "From", "To")
+		FieldsInOrder(sl, "Min", "Max")
+		FieldsInOrder(sl, "First", "Last")
+		FieldsInOrder(sl, "Invalid", "Min")
+	}, orderedRequest{})
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		OneOfWhen(sl, "Mode", []string{"fast", "safe"}, "Strict")
+		OneOfWhen(sl, "Count", []string{"1"}, "Strict")
+	}, oneOfWhenRequest{})
+	v.RegisterStructValidation(func(sl validator.StructLevel) {
+		RequiredIfLenGreater(sl, "Confirm", "Items", 2)
+		RequiredIfLenGreater(sl, "Confirm", "Labels", 1)
+		RequiredIfLenGreater(sl, "Confirm", "Name", 5)
+		RequiredIfLenGreater(sl, "Confirm", "Amount", 0)
+	}, requiredIfLenRequest{})
+	return v
+}
+
+// structErrors 返回验证错误的字段和标签，格式为 Field:tag
+func structErrors(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		t.Fatalf("unexpected error %v", err)
+	}
+	fields := make([]string, 0, len(validationErrors))
+	for _, fe := range validationErrors {
+		fields = append(fields, fe.Field()+":"+fe.Tag())
+	}
+	return fields
+}
+
+func TestStructLevelValidations(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	ids := func(n int) []idgen.ID {
+		list := make([]idgen.ID, n)
+		for i := range list {
+			list[i] = idgen.ID(i + 1)
+		}
+		return list
+	}
+
+	tests := []struct {
+		name string
+		obj  interface{}
+		want []string
+	}{
+		// FieldsInOrder
+		{"fields in order", orderedRequest{From: &earlier, To: &later, Min: 1, Max: 2, First: "a", Last: "b"}, nil},
+		{"equal fields", orderedRequest{From: &earlier, To: &earlier, Min: 2, Max: 2}, nil},
+		{"times out of order", orderedRequest{From: &later, To: &earlier}, []string{"From:ltefield"}},
+		{"numbers out of order", orderedRequest{Min: 3, Max: 2}, []string{"Min:ltefield"}},
+		{"strings out of order", orderedRequest{First: "b", Last: "a"}, []string{"First:ltefield"}},
+		{"nil pointer not checked", orderedRequest{From: &later}, nil},
+		{"zero value not checked", orderedRequest{Min: 3}, nil},
+		{"incomparable kinds not checked", orderedRequest{Invalid: true, Min: 1, Max: 1}, nil},
+
+		// OneOfWhen
+		{"allowed value when set", oneOfWhenRequest{Mode: "safe", Strict: true}, nil},
+		{"empty value when set", oneOfWhenRequest{Strict: true}, nil},
+		{"other value when set", oneOfWhenRequest{Mode: "slow", Strict: true}, []string{"Mode:oneof"}},
+		{"other value when unset", oneOfWhenRequest{Mode: "slow"}, nil},
+		{"non-string field not checked", oneOfWhenRequest{Strict: true, Count: 2}, nil},
+
+		// RequiredIfLenGreater
+		{"short list", requiredIfLenRequest{Items: []string{"a", "b"}}, nil},
+		{"long list confirmed", requiredIfLenRequest{Items: []string{"a", "b", "c"}, Confirm: true}, nil},
+		{"long list unconfirmed", requiredIfLenRequest{Items: []string{"a", "b", "c"}}, []string{"Confirm:required_if_len_gt"}},
+		{"long map unconfirmed", requiredIfLenRequest{Labels: map[string]string{"a": "1", "b": "2"}}, []string{"Confirm:required_if_len_gt"}},
+		{"long string unconfirmed", requiredIfLenRequest{Name: "abcdef"}, []string{"Confirm:required_if_len_gt"}},
+		{"field without length not checked", requiredIfLenRequest{Amount: 10}, nil},
+
+		// v1.PageRequest
+		{"page sorted ascending by any field", v1.PageRequest{SortBy: "name"}, nil},
+		{"page sorted descending by common field", v1.PageRequest{SortBy: "created_at", SortDesc: true}, nil},
+		{"page sorted descending by other field", v1.PageRequest{SortBy: "name", SortDesc: true}, []string{"SortBy:oneof"}},
+
+		// v1.BatchDeleteApplicationsRequest
+		{"batch delete under threshold", v1.BatchDeleteApplicationsRequest{IDs: ids(v1.BatchDeleteForceThreshold)}, nil},
+		{"batch delete over threshold forced", v1.BatchDeleteApplicationsRequest{IDs: ids(v1.BatchDeleteForceThreshold + 1), Force: true}, nil},
+		{"batch delete over threshold", v1.BatchDeleteApplicationsRequest{IDs: ids(v1.BatchDeleteForceThreshold + 1)}, []string{"Force:required_if_len_gt"}},
+		{"batch delete over threshold partially", v1.BatchDeleteApplicationsRequest{IDs: ids(v1.BatchDeleteForceThreshold + 1), ContinueOnError: true}, []string{"Force:required_if_len_gt"}},
+
+		// v1.DatastoreHistoryRequest
+		{"history in order", v1.DatastoreHistoryRequest{Since: &earlier, Until: &later}, nil},
+		{"history open ended", v1.DatastoreHistoryRequest{Since: &later}, nil},
+		{"history out of order", v1.DatastoreHistoryRequest{Since: &later, Until: &earlier}, []string{"Since:ltefield"}},
+	}
+
+	v := newStructLevelValidator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := structErrors(t, v.Struct(tt.obj))
+			if len(got) != len(tt.want) {
+				t.Fatalf("errors = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("errors = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package validation
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales"
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/zh"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	zh_translations "github.com/go-playground/validator/v10/translations/zh"
+)
+
+// 错误消息的语言
+const (
+	LanguageZh = "zh"
+	LanguageEn = "en"
+)
+
+// translators 各语言的错误消息翻译器，所有验证器共用
+var translators = map[string]ut.Translator{
+	LanguageZh: newTranslator(zh.New()),
+	LanguageEn: newTranslator(en.New()),
+}
+
+// registerMu 串行化翻译的注册，翻译器的消息由第一个注册的验证器添加
+var registerMu sync.Mutex
+
+// customTranslations 自定义验证器的错误消息，{0}为字段名，{1}为验证器参数；
+// multiParamTags 中的标签依次为以空格分隔的各个参数和字段名（占位符须按顺序出现）
+var customTranslations = map[string]map[string]string{
+	LanguageZh: {
+		"phone":    "{0}必须是有效的手机号",
+		"username": "{0}必须为3-50位字母、数字、下划线或中划线",
+		"password": "{0}强度不足，至少8位且包含大小写字母、数字、特殊字符中的3种",
+		"idcard":   "{0}必须是有效的身份证号",
+		"chinese":  "{0}只能包含中文",
+		"url_path": "{0}必须是以/开头的URL路径",
+		"app_name": "{0}只能包含字母、数字、中文、下划线、中划线和空格",
+		"sortable": "{0}只能按以下字段排序：{1}",
+
+		"required_if_len_gt": "{0}多于{1}个时{2}为必填字段",
+		"lookup_exists":      "{0}不存在",
+		"lookup_unique":      "{0}已被使用",
+	},
+	LanguageEn: {
+		"phone":    "{0} must be a valid phone number",
+		"username": "{0} must be 3-50 letters, digits, underscores or hyphens",
+		"password": "{0} must be at least 8 characters with 3 of upper case, lower case, digits and symbols",
+		"idcard":   "{0} must be a valid ID card number",
+		"chinese":  "{0} must contain only Chinese characters",
+		"url_path": "{0} must be a URL path starting with /",
+		"app_name": "{0} must contain only letters, digits, Chinese characters, underscores, hyphens and spaces",
+		"sortable": "{0} can only sort by: {1}",
+
+		"required_if_len_gt": "When {0} has more than {1} items, {2} is required",
+		"lookup_exists":      "{0} does not exist",
+		"lookup_unique":      "{0} is already in use",
+	},
+}
+
+// multiParamTags 有多个参数的验证标签
+var multiParamTags = map[string]bool{"required_if_len_gt": true}
+
+// fallbackMessages 没有翻译的验证标签的错误消息
+var fallbackMessages = map[string]string{
+	LanguageZh: "字段验证失败",
+	LanguageEn: "field validation failed",
+}
+
+// RegisterTranslations 在验证器上注册中英文错误消息，包括内置标签和自定义验证器的消息
+func RegisterTranslations(v *validator.Validate) error {
+	registerMu.Lock()
+	defer registerMu.Unlock()
+
+	if err := zh_translations.RegisterDefaultTranslations(v, translators[LanguageZh]); err != nil {
+		return err
+	}
+	if err := en_translations.RegisterDefaultTranslations(v, translators[LanguageEn]); err != nil {
+		return err
+	}
+
+	for lang, messages := range customTranslations {
+		trans := translators[lang]
+		for tag, message := range messages {
+			message := message
+			err := v.RegisterTranslation(tag, trans, func(trans ut.Translator) error {
+				return trans.Add(tag, message, false)
+			}, translateCustom)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Language 返回 i18n 语言代码（如 zh-CN、en-US）对应的错误消息语言，中文以外的语言使用英文
+func Language(lang string) string {
+	if strings.HasPrefix(strings.ToLower(lang), LanguageZh) {
+		return LanguageZh
+	}
+	return LanguageEn
+}
+
+// Translate 返回校验错误在 i18n 语言 lang 下的消息，验证标签没有翻译时返回通用消息
+func Translate(fe validator.FieldError, lang string) string {
+	lang = Language(lang)
+	if message := fe.Translate(translators[lang]); message != fe.Error() {
+		return message
+	}
+	return fallbackMessages[lang]
+}
+
+// translateCustom 以字段名和验证器参数翻译自定义验证器的错误
+func translateCustom(trans ut.Translator, fe validator.FieldError) string {
+	params := []string{fe.Field(), fe.Param()}
+	if multiParamTags[fe.Tag()] {
+		params = append(strings.Fields(fe.Param()), fe.Field())
+	}
+	message, err := trans.T(fe.Tag(), params...)
+	if err != nil {
+		return fe.Error()
+	}
+	return message
+}
+
+// sharedTranslator 忽略已有的翻译，使多个验证器实例可以在同一个翻译器上注册相同的消息，
+// 注册之后翻译器只被读取
+type sharedTranslator struct {
+	ut.Translator
+}
+
+// newTranslator 返回语言的翻译器
+func newTranslator(locale locales.Translator) ut.Translator {
+	trans, _ := ut.New(locale, locale).GetTranslator(locale.Locale())
+	return sharedTranslator{trans}
+}
+
+// Add 添加翻译，已有的翻译保持不变
+func (t sharedTranslator) Add(key interface{}, text string, override bool) error {
+	return ignoreConflict(t.Translator.Add(key, text, override))
+}
+
+// AddCardinal 添加基数复数规则的翻译，已有的翻译保持不变
+func (t sharedTranslator) AddCardinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddCardinal(key, text, rule, override))
+}
+
+// AddOrdinal 添加序数复数规则的翻译，已有的翻译保持不变
+func (t sharedTranslator) AddOrdinal(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddOrdinal(key, text, rule, override))
+}
+
+// AddRange 添加范围复数规则的翻译，已有的翻译保持不变
+func (t sharedTranslator) AddRange(key interface{}, text string, rule locales.PluralRule, override bool) error {
+	return ignoreConflict(t.Translator.AddRange(key, text, rule, override))
+}
+
+// ignoreConflict 忽略翻译已存在的错误
+func ignoreConflict(err error) error {
+	var conflict *ut.ErrConflictingTranslation
+	if errors.As(err, &conflict) {
+		return nil
+	}
+	return err
+}
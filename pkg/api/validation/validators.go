@@ -2,12 +2,14 @@ package validation
 
 import (
 	"regexp"
+	"slices"
+	"strings"
 	"unicode"
 
 	"github.com/go-playground/validator/v10"
 )
 
-// RegisterCustomValidators 注册自定义验证器
+// RegisterCustomValidators 注册自定义验证器、跨字段验证（见 RegisterStructValidations）及中英文错误消息（见 RegisterTranslations）
 func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("phone", validatePhone)
 	v.RegisterValidation("username", validateUsername)
@@ -16,6 +18,9 @@ func RegisterCustomValidators(v *validator.Validate) {
 	v.RegisterValidation("chinese", validateChinese)
 	v.RegisterValidation("url_path", validateURLPath)
 	v.RegisterValidation("app_name", validateAppName)
+	v.RegisterValidation("sortable", validateSortable)
+	RegisterStructValidations(v)
+	RegisterTranslations(v)
 }
 
 // validatePhone 验证手机号
@@ -181,6 +186,27 @@ func validateAppName(fl validator.FieldLevel) bool {
 	return matched
 }
 
+// sortFields 由排序表达式实现，返回排序的字段名，如 dto/v1.SortExpression
+type sortFields interface {
+	Fields() []string
+}
+
+// validateSortable 验证排序表达式只包含参数列出的字段，如 sortable=id name created_at
+func validateSortable(fl validator.FieldLevel) bool {
+	expr, ok := fl.Field().Interface().(sortFields)
+	if !ok {
+		return false
+	}
+
+	allowed := strings.Fields(fl.Param())
+	for _, field := range expr.Fields() {
+		if !slices.Contains(allowed, field) {
+			return false
+		}
+	}
+	return true
+}
+
 // ValidationRule 验证规则结构
 type ValidationRule struct {
 	Field   string `json:"field"`
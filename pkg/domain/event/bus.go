@@ -0,0 +1,118 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// WildcardType subscribes a handler to every event type
+const WildcardType = "*"
+
+// Event represents a domain event
+type Event struct {
+	Type      string                 `json:"type"`
+	Payload   interface{}            `json:"payload"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	Timestamp time.Time              `json:"timestamp"`
+}
+
+// NewEvent creates an event of the given type with the current timestamp
+func NewEvent(eventType string, payload interface{}) Event {
+	return Event{
+		Type:      eventType,
+		Payload:   payload,
+		Timestamp: time.Now(),
+	}
+}
+
+// Handler handles a published event
+type Handler func(ctx context.Context, event Event)
+
+// Bus defines the interface for publishing and subscribing to domain events
+type Bus interface {
+	// Publish delivers the event to every handler subscribed to its type
+	Publish(ctx context.Context, event Event)
+	// Subscribe registers a handler for an event type and returns a function that removes it
+	Subscribe(eventType string, handler Handler) (unsubscribe func())
+}
+
+// subscription pairs a handler with an identifier so it can be removed
+type subscription struct {
+	id      uint64
+	handler Handler
+}
+
+// InMemoryBus is a synchronous, in-process event bus
+type InMemoryBus struct {
+	mutex         sync.RWMutex
+	subscriptions map[string][]subscription
+	nextID        uint64
+}
+
+// NewInMemoryBus creates a new in-memory event bus
+func NewInMemoryBus() *InMemoryBus {
+	return &InMemoryBus{
+		subscriptions: make(map[string][]subscription),
+	}
+}
+
+// Publish implements Bus. Handlers run synchronously in subscription order;
+// a panicking handler is logged and does not affect the others.
+func (b *InMemoryBus) Publish(ctx context.Context, event Event) {
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+
+	b.mutex.RLock()
+	handlers := make([]Handler, 0, len(b.subscriptions[event.Type])+len(b.subscriptions[WildcardType]))
+	for _, sub := range b.subscriptions[event.Type] {
+		handlers = append(handlers, sub.handler)
+	}
+	if event.Type != WildcardType {
+		for _, sub := range b.subscriptions[WildcardType] {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mutex.RUnlock()
+
+	logger.Debug("Publishing event %s to %d handler(s)", event.Type, len(handlers))
+	for _, handler := range handlers {
+		b.dispatch(ctx, event, handler)
+	}
+}
+
+// Subscribe implements Bus
+func (b *InMemoryBus) Subscribe(eventType string, handler Handler) func() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.nextID++
+	id := b.nextID
+	b.subscriptions[eventType] = append(b.subscriptions[eventType], subscription{id: id, handler: handler})
+
+	return func() {
+		b.mutex.Lock()
+		defer b.mutex.Unlock()
+
+		subs := b.subscriptions[eventType]
+		for i, sub := range subs {
+			if sub.id == id {
+				b.subscriptions[eventType] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// dispatch invokes a handler, recovering from panics
+func (b *InMemoryBus) dispatch(ctx context.Context, event Event, handler Handler) {
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Event handler for %s panicked: %v", event.Type, r)
+		}
+	}()
+	handler(ctx, event)
+}
@@ -1,12 +1,24 @@
 package model
 
+import "strconv"
+
 // Application represents the application domain model
 type Application struct {
 	BaseModel
-	Name        string `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
-	Description string `gorm:"type:text" json:"description"`
+	OrgID       uint       `gorm:"not null;default:0;uniqueIndex:idx_applications_org_name" json:"org_id"` // owning organization, 0 when created without one
+	OwnerID     string     `gorm:"type:varchar(100);not null;default:'';index" json:"owner_id"`            // creating user, empty when created by the system
+	Name        string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_applications_org_name" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	Tags        StringList `gorm:"type:jsonb;not null;default:'[]';index:,type:gin" json:"tags"`
 }
 
+// Application statuses. Applications have no status column: the status is
+// derived from their soft deletion.
+const (
+	ApplicationStatusActive  = "active"
+	ApplicationStatusDeleted = "deleted"
+)
+
 // TableName returns the table name for the Application model
 func (a *Application) TableName() string {
 	return "applications"
@@ -17,14 +29,73 @@ func (a *Application) ShortTableName() string {
 	return "app"
 }
 
+// GetTags implements Tagged
+func (a *Application) GetTags() StringList {
+	return a.Tags
+}
+
+// Status returns ApplicationStatusDeleted once the application is soft
+// deleted, ApplicationStatusActive otherwise
+func (a *Application) Status() string {
+	if a.DeletedAt.Valid {
+		return ApplicationStatusDeleted
+	}
+	return ApplicationStatusActive
+}
+
 // Index returns indexable fields for the Application model
 func (a *Application) Index() map[string]interface{} {
 	index := a.BaseModel.Index()
+	index["org_id"] = a.OrgID
+	index["owner_id"] = a.OwnerID
 	index["name"] = a.Name
 	index["description"] = a.Description
 	return index
 }
 
+// AuthorizationResource returns the application as the resource of an authorized action
+func (a *Application) AuthorizationResource() Resource {
+	return Resource{
+		Type:    ResourceApplication,
+		ID:      strconv.FormatUint(uint64(a.ID), 10),
+		OwnerID: a.OwnerID,
+		OrgID:   a.OrgID,
+	}
+}
+
+// ApplicationPatch is a partial update of an application. Nil fields are left
+// unchanged, so that an empty description or tag list can be set explicitly.
+type ApplicationPatch struct {
+	Name        *string
+	Description *string
+	Tags        *StringList
+}
+
+// ApplyTo sets the fields present in the patch on app
+func (p *ApplicationPatch) ApplyTo(app *Application) {
+	if p.Name != nil {
+		app.Name = *p.Name
+	}
+	if p.Description != nil {
+		app.Description = *p.Description
+	}
+	if p.Tags != nil {
+		app.Tags = append(StringList{}, *p.Tags...)
+	}
+}
+
+// ApplicationQuery selects a page of applications. Empty fields do not restrict
+// the results, which are ordered by Sort and then by ID.
+type ApplicationQuery struct {
+	Page      int
+	Size      int
+	IDs       []uint
+	Tags      TagSelector
+	CreatedAt TimeRange
+	UpdatedAt TimeRange
+	Sort      []SortOrder
+}
+
 // Validate performs business rule validation on the Application model
 func (a *Application) Validate() error {
 	if a.Name == "" {
@@ -36,6 +107,9 @@ func (a *Application) Validate() error {
 	if len(a.Description) > 500 {
 		return ErrApplicationDescriptionTooLong
 	}
+	if len(a.Tags) > MaxTags {
+		return ErrTooManyTags
+	}
 	return nil
 }
 
@@ -45,6 +119,10 @@ var (
 	ErrApplicationNameTooLong        = NewDomainError("application name too long")
 	ErrApplicationDescriptionTooLong = NewDomainError("application description too long")
 	ErrApplicationNotFound           = NewDomainError("application not found")
+	ErrApplicationNameExists         = NewDomainError("application with this name already exists")
+	ErrApplicationNameRepeated       = NewDomainError("application name appears more than once")
+	ErrApplicationBatchAborted       = NewDomainError("application not deleted because other applications of the batch cannot be deleted")
+	ErrApplicationBatchConflict      = NewDomainError("applications of the batch were deleted concurrently")
 )
 
 // DomainError represents domain-specific errors
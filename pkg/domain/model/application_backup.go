@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// Backup statuses
+const (
+	BackupStatusPending   = OperationStatusPending
+	BackupStatusRunning   = OperationStatusRunning
+	BackupStatusCompleted = OperationStatusCompleted
+	BackupStatusFailed    = OperationStatusFailed
+)
+
+// ApplicationBackup tracks a backup job of an application and the archive it
+// produced. Backups outlive the application so that it can be restored.
+type ApplicationBackup struct {
+	BaseModel
+	BackupID    string     `gorm:"type:varchar(36);not null;uniqueIndex" json:"backup_id"`
+	OperationID string     `gorm:"type:varchar(36);index" json:"operation_id"` // operation running the backup
+	AppID       uint       `gorm:"not null;index" json:"app_id"`
+	OrgID       uint       `gorm:"not null;default:0;index" json:"org_id"` // organization owning the application when backed up
+	Name        string     `gorm:"type:varchar(100);not null" json:"name"`
+	Description string     `gorm:"type:text" json:"description"`
+	IncludeData bool       `gorm:"not null;default:false" json:"include_data"` // include variables and revisions
+	Compress    bool       `gorm:"not null;default:false" json:"compress"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	StorageKey  string     `gorm:"type:varchar(255)" json:"storage_key"`
+	Size        int64      `gorm:"not null;default:0" json:"size"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for the ApplicationBackup model
+func (b *ApplicationBackup) TableName() string {
+	return "application_backups"
+}
+
+// ShortTableName returns abbreviated table name
+func (b *ApplicationBackup) ShortTableName() string {
+	return "ab"
+}
+
+// Index returns indexable fields for the ApplicationBackup model
+func (b *ApplicationBackup) Index() map[string]interface{} {
+	index := b.BaseModel.Index()
+	index["backup_id"] = b.BackupID
+	index["app_id"] = b.AppID
+	index["org_id"] = b.OrgID
+	index["status"] = b.Status
+	return index
+}
+
+// Domain errors for ApplicationBackup
+var (
+	ErrBackupNotFound     = NewDomainError("backup not found")
+	ErrBackupNotCompleted = NewDomainError("backup is not completed")
+	ErrBackupCorrupted    = NewDomainError("backup archive is corrupted")
+)
@@ -0,0 +1,158 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// Revision actions
+const (
+	RevisionActionCreate   = "create"
+	RevisionActionUpdate   = "update"
+	RevisionActionRollback = "rollback"
+)
+
+// ApplicationRevision is an immutable record of a change to an application.
+// Snapshot holds the application fields after the change and Changes the
+// fields that differ from the previous revision.
+type ApplicationRevision struct {
+	BaseModel
+	AppID        uint                `gorm:"not null;uniqueIndex:idx_application_revisions_app_revision" json:"app_id"`
+	Revision     int                 `gorm:"not null;uniqueIndex:idx_application_revisions_app_revision" json:"revision"`
+	Action       string              `gorm:"type:varchar(20);not null" json:"action"`
+	Actor        string              `gorm:"type:varchar(100)" json:"actor"`
+	RestoredFrom int                 `gorm:"not null;default:0" json:"restored_from,omitempty"` // revision restored by a rollback
+	Snapshot     ApplicationSnapshot `gorm:"type:jsonb;not null" json:"snapshot"`
+	Changes      FieldChanges        `gorm:"type:jsonb;not null;default:'[]'" json:"changes"`
+}
+
+// TableName returns the table name for the ApplicationRevision model
+func (r *ApplicationRevision) TableName() string {
+	return "application_revisions"
+}
+
+// ShortTableName returns abbreviated table name
+func (r *ApplicationRevision) ShortTableName() string {
+	return "ar"
+}
+
+// Index returns indexable fields for the ApplicationRevision model
+func (r *ApplicationRevision) Index() map[string]interface{} {
+	index := r.BaseModel.Index()
+	index["app_id"] = r.AppID
+	index["revision"] = r.Revision
+	index["action"] = r.Action
+	return index
+}
+
+// ApplicationSnapshot holds the versioned fields of an application
+type ApplicationSnapshot struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Tags        StringList `json:"tags"`
+}
+
+// SnapshotOf returns the versioned fields of app
+func SnapshotOf(app *Application) ApplicationSnapshot {
+	tags := StringList{}
+	if app.Tags != nil {
+		tags = append(tags, app.Tags...)
+	}
+	return ApplicationSnapshot{Name: app.Name, Description: app.Description, Tags: tags}
+}
+
+// ApplyTo restores the versioned fields of app from the snapshot
+func (s ApplicationSnapshot) ApplyTo(app *Application) {
+	app.Name = s.Name
+	app.Description = s.Description
+	app.Tags = append(StringList{}, s.Tags...)
+}
+
+// Diff returns the fields that changed from previous to s
+func (s ApplicationSnapshot) Diff(previous ApplicationSnapshot) FieldChanges {
+	changes := FieldChanges{}
+	if s.Name != previous.Name {
+		changes = append(changes, FieldChange{Field: "name", Old: previous.Name, New: s.Name})
+	}
+	if s.Description != previous.Description {
+		changes = append(changes, FieldChange{Field: "description", Old: previous.Description, New: s.Description})
+	}
+	if !equalStrings(s.Tags, previous.Tags) {
+		changes = append(changes, FieldChange{Field: "tags", Old: previous.Tags, New: s.Tags})
+	}
+	return changes
+}
+
+// Value implements driver.Valuer
+func (s ApplicationSnapshot) Value() (driver.Value, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (s *ApplicationSnapshot) Scan(value interface{}) error {
+	return scanJSON(value, s)
+}
+
+// FieldChange describes the old and new value of a changed field
+type FieldChange struct {
+	Field string      `json:"field"`
+	Old   interface{} `json:"old"`
+	New   interface{} `json:"new"`
+}
+
+// FieldChanges is a list of field changes stored as a JSON array
+type FieldChanges []FieldChange
+
+// Value implements driver.Valuer
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]FieldChange(c))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (c *FieldChanges) Scan(value interface{}) error {
+	return scanJSON(value, c)
+}
+
+// scanJSON decodes a JSON column value into dest
+func scanJSON(value interface{}, dest interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, dest)
+	}
+}
+
+// equalStrings reports whether two lists hold the same strings in the same order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Domain errors for ApplicationRevision
+var (
+	ErrRevisionNotFound = NewDomainError("revision not found")
+)
@@ -0,0 +1,61 @@
+package model
+
+import "regexp"
+
+// MaxVariableValueLength is the maximum length of a variable value in bytes
+const MaxVariableValueLength = 64 * 1024
+
+// variableKeyPattern follows environment variable naming so variables can be exported as dotenv files
+var variableKeyPattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// ApplicationVariable is a key-value configuration entry of an application.
+// Values of secret variables are stored encrypted.
+type ApplicationVariable struct {
+	BaseModel
+	AppID  uint   `gorm:"not null;uniqueIndex:idx_application_variables_app_key" json:"app_id"`
+	Key    string `gorm:"type:varchar(255);not null;uniqueIndex:idx_application_variables_app_key" json:"key"`
+	Value  string `gorm:"type:text;not null" json:"value"`
+	Secret bool   `gorm:"not null" json:"secret"`
+}
+
+// TableName returns the table name for the ApplicationVariable model
+func (v *ApplicationVariable) TableName() string {
+	return "application_variables"
+}
+
+// ShortTableName returns abbreviated table name
+func (v *ApplicationVariable) ShortTableName() string {
+	return "av"
+}
+
+// Index returns indexable fields for the ApplicationVariable model
+func (v *ApplicationVariable) Index() map[string]interface{} {
+	index := v.BaseModel.Index()
+	index["app_id"] = v.AppID
+	index["key"] = v.Key
+	index["secret"] = v.Secret
+	return index
+}
+
+// Validate performs business rule validation on the ApplicationVariable model
+func (v *ApplicationVariable) Validate() error {
+	if v.Key == "" {
+		return ErrVariableKeyRequired
+	}
+	if len(v.Key) > 255 || !variableKeyPattern.MatchString(v.Key) {
+		return ErrVariableKeyInvalid
+	}
+	if len(v.Value) > MaxVariableValueLength {
+		return ErrVariableValueTooLong
+	}
+	return nil
+}
+
+// Domain errors for ApplicationVariable
+var (
+	ErrVariableKeyRequired  = NewDomainError("variable key is required")
+	ErrVariableKeyInvalid   = NewDomainError("variable key must use upper case letters, digits and underscores, e.g. DATABASE_URL")
+	ErrVariableValueTooLong = NewDomainError("variable value too long")
+	ErrVariableNotFound     = NewDomainError("variable not found")
+	ErrVariableExists       = NewDomainError("variable with this key already exists")
+)
@@ -0,0 +1,64 @@
+package model
+
+import "context"
+
+// Actions authorized on resources
+const (
+	ActionCreate = "create"
+	ActionRead   = "read"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+)
+
+// Resource types of authorized actions
+const (
+	ResourceApplication = "application"
+	ResourceFile        = "file"
+)
+
+// Subject is the user performing an action, with their role in the active
+// organization when there is one
+type Subject struct {
+	UserID  string `json:"user_id"`
+	Role    string `json:"role,omitempty"`
+	OrgID   uint   `json:"org_id,omitempty"`
+	OrgRole string `json:"org_role,omitempty"`
+}
+
+// Resource identifies the target of an action. OwnerID and OrgID are empty for
+// resources that are not created yet, or have no owner or organization.
+type Resource struct {
+	Type    string `json:"type"`
+	ID      string `json:"id,omitempty"`
+	OwnerID string `json:"owner_id,omitempty"`
+	OrgID   uint   `json:"org_id,omitempty"`
+}
+
+// AuthorizationDecision is the outcome of authorizing an action. Reason names
+// what granted the action (no_policy, role, owner or org_role), or is
+// not_granted when it was denied.
+type AuthorizationDecision struct {
+	Subject  Subject  `json:"subject"`
+	Action   string   `json:"action"`
+	Resource Resource `json:"resource"`
+	Allowed  bool     `json:"allowed"`
+	Reason   string   `json:"reason"`
+}
+
+// subjectContextKey is the context key of the subject of a request
+type subjectContextKey struct{}
+
+// WithSubject returns a context carrying the user performing its actions
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// SubjectFromContext returns the user performing the actions of ctx. Contexts
+// without one, such as startup tasks, act on behalf of the system.
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	subject, ok := ctx.Value(subjectContextKey{}).(Subject)
+	return subject, ok
+}
+
+// ErrAccessDenied is returned when no policy grants an action to the subject
+var ErrAccessDenied = NewDomainError("access denied")
@@ -5,14 +5,20 @@ import (
 	"strings"
 	"time"
 
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
 	"gorm.io/gorm"
 )
 
-// BaseModel contains common fields for all domain models
+// BaseModel contains common fields for all domain models. IDs follow the
+// strategy of idgen and timestamps are stored in UTC. PublicID is a random
+// UUID identifying the entity in the API independently of its database key.
 type BaseModel struct {
 	ID        uint           `gorm:"primaryKey" json:"id"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	PublicID  string         `gorm:"size:36;index" json:"public_id"`
+	CreatedAt timestamp.Time `json:"created_at"`
+	UpdatedAt timestamp.Time `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
@@ -40,24 +46,34 @@ func (b *BaseModel) SetID(id uint) {
 	b.ID = id
 }
 
+// GetPublicID returns the public ID of the entity
+func (b *BaseModel) GetPublicID() string {
+	return b.PublicID
+}
+
+// SetPublicID sets the public ID of the entity
+func (b *BaseModel) SetPublicID(id string) {
+	b.PublicID = id
+}
+
 // GetCreatedAt returns the creation time of the entity
 func (b *BaseModel) GetCreatedAt() time.Time {
-	return b.CreatedAt
+	return b.CreatedAt.Time
 }
 
 // GetUpdatedAt returns the last update time of the entity
 func (b *BaseModel) GetUpdatedAt() time.Time {
-	return b.UpdatedAt
+	return b.UpdatedAt.Time
 }
 
 // SetCreateTime sets the creation time
 func (b *BaseModel) SetCreateTime(t time.Time) {
-	b.CreatedAt = t
+	b.CreatedAt = timestamp.New(t)
 }
 
 // SetUpdateTime sets the update time
 func (b *BaseModel) SetUpdateTime(t time.Time) {
-	b.UpdatedAt = t
+	b.UpdatedAt = timestamp.New(t)
 }
 
 // PrimaryKey returns the primary key as string
@@ -91,21 +107,57 @@ func (b *BaseModel) ShortTableName() string {
 func (b *BaseModel) Index() map[string]interface{} {
 	return map[string]interface{}{
 		"id":         b.ID,
-		"created_at": b.CreatedAt,
-		"updated_at": b.UpdatedAt,
+		"public_id":  b.PublicID,
+		"created_at": b.CreatedAt.Time,
+		"updated_at": b.UpdatedAt.Time,
 	}
 }
 
-// BeforeCreate GORM hook
+// BeforeCreate GORM hook, generates the ID unless the database allocates it
+// and the public ID unless one is set; timestamps are read from the clock of
+// the connection
 func (b *BaseModel) BeforeCreate(tx *gorm.DB) error {
-	now := time.Now()
+	if b.ID == 0 {
+		b.ID = idgen.Next()
+	}
+	AssignPublicID(b)
+	now := timestamp.New(tx.NowFunc())
 	b.CreatedAt = now
 	b.UpdatedAt = now
 	return nil
 }
 
-// BeforeUpdate GORM hook
+// BeforeUpdate GORM hook, timestamps are read from the clock of the connection
 func (b *BaseModel) BeforeUpdate(tx *gorm.DB) error {
-	b.UpdatedAt = time.Now()
+	b.UpdatedAt = timestamp.New(tx.NowFunc())
 	return nil
 }
+
+// PublicIdentified is implemented by entities with a public ID, those embedding BaseModel
+type PublicIdentified interface {
+	GetPublicID() string
+	SetPublicID(id string)
+}
+
+// AssignPublicID gives entity a new random public ID unless it has one.
+// Entities without public IDs are left unchanged.
+func AssignPublicID(entity interface{}) {
+	if e, ok := entity.(PublicIdentified); ok && e.GetPublicID() == "" {
+		e.SetPublicID(uuid.NewString())
+	}
+}
+
+// KeepPublicID sets the public ID of an updated entity to the stored one, or
+// to a new one when none was stored, as public IDs never change
+func KeepPublicID(entity interface{}, stored string) {
+	if e, ok := entity.(PublicIdentified); ok {
+		e.SetPublicID(stored)
+		AssignPublicID(e)
+	}
+}
+
+// IsPublicID reports whether s has the form of a public ID, which never
+// parses as a numeric ID
+func IsPublicID(s string) bool {
+	return len(s) == 36 && uuid.Validate(s) == nil
+}
@@ -0,0 +1,48 @@
+package model
+
+import "time"
+
+// Datastore metric resolutions
+const (
+	// MetricResolutionRaw samples cover one collection interval
+	MetricResolutionRaw = "raw"
+	// MetricResolutionHour samples are raw samples rolled up into hours
+	MetricResolutionHour = "1h"
+)
+
+// DatastoreMetric is the activity of one datastore operation on one table,
+// recorded by one instance during the interval starting at BucketStart.
+// Durations are stored in nanoseconds.
+type DatastoreMetric struct {
+	BaseModel
+	Instance    string    `gorm:"type:varchar(255);not null;index" json:"instance"`
+	Resolution  string    `gorm:"type:varchar(10);not null;index:idx_datastore_metrics_series,priority:1" json:"resolution"`
+	Operation   string    `gorm:"type:varchar(50);not null;index:idx_datastore_metrics_series,priority:2" json:"operation"`
+	Table       string    `gorm:"column:table_name;type:varchar(255);not null;index:idx_datastore_metrics_series,priority:3" json:"table_name"`
+	BucketStart time.Time `gorm:"not null;index:idx_datastore_metrics_series,priority:4" json:"bucket_start"`
+	Count       int64     `gorm:"not null;default:0" json:"count"`
+	TotalTime   int64     `gorm:"not null;default:0" json:"total_time"`
+	MaxTime     int64     `gorm:"not null;default:0" json:"max_time"`
+	Errors      int64     `gorm:"not null;default:0" json:"errors"`
+}
+
+// TableName returns the table name for the DatastoreMetric model
+func (m *DatastoreMetric) TableName() string {
+	return "datastore_metrics"
+}
+
+// ShortTableName returns abbreviated table name
+func (m *DatastoreMetric) ShortTableName() string {
+	return "dm"
+}
+
+// Index returns indexable fields for the DatastoreMetric model
+func (m *DatastoreMetric) Index() map[string]interface{} {
+	index := m.BaseModel.Index()
+	index["instance"] = m.Instance
+	index["resolution"] = m.Resolution
+	index["operation"] = m.Operation
+	index["table_name"] = m.Table
+	index["bucket_start"] = m.BucketStart
+	return index
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// DomainEvent is a domain event published on the event bus and kept in the
+// event log, so that clients can replay the events after a cursor. The ID is
+// the cursor. OrgID is the organization the event belongs to, 0 for events
+// outside organizations.
+type DomainEvent struct {
+	BaseModel
+	EventType    string    `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	ResourceType string    `gorm:"type:varchar(100);not null;index" json:"resource_type"`
+	OrgID        uint      `gorm:"not null;default:0;index" json:"org_id"`
+	Payload      RawJSON   `gorm:"type:jsonb" json:"payload,omitempty"`
+	OccurredAt   time.Time `gorm:"not null" json:"occurred_at"`
+}
+
+// TableName returns the table name for the DomainEvent model
+func (e *DomainEvent) TableName() string {
+	return "domain_events"
+}
+
+// ShortTableName returns abbreviated table name
+func (e *DomainEvent) ShortTableName() string {
+	return "de"
+}
+
+// Index returns indexable fields for the DomainEvent model
+func (e *DomainEvent) Index() map[string]interface{} {
+	index := e.BaseModel.Index()
+	index["event_type"] = e.EventType
+	index["resource_type"] = e.ResourceType
+	index["org_id"] = e.OrgID
+	return index
+}
+
+// EventResourceType returns the resource type of an event type, the part
+// before the first dot: "application" for "application.deleted"
+func EventResourceType(eventType string) string {
+	resourceType, _, _ := strings.Cut(eventType, ".")
+	return resourceType
+}
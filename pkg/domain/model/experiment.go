@@ -0,0 +1,82 @@
+package model
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+)
+
+// Experiment represents an A/B experiment definition
+type Experiment struct {
+	Key         string              `json:"key"`
+	Description string              `json:"description"`
+	Enabled     bool                `json:"enabled"`
+	FlagKey     string              `json:"flag_key,omitempty"`
+	Variants    []ExperimentVariant `json:"variants"`
+}
+
+// ExperimentVariant represents a weighted experiment variant
+type ExperimentVariant struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// Validate performs business rule validation on the Experiment model
+func (e *Experiment) Validate() error {
+	if e.Key == "" {
+		return ErrExperimentKeyRequired
+	}
+	if len(e.Variants) == 0 {
+		return ErrExperimentVariantsRequired
+	}
+	total := 0
+	for _, variant := range e.Variants {
+		if variant.Name == "" || variant.Weight < 0 {
+			return ErrExperimentVariantInvalid
+		}
+		total += variant.Weight
+	}
+	if total == 0 {
+		return ErrExperimentVariantInvalid
+	}
+	return nil
+}
+
+// Assign deterministically assigns a variant by hashing the user ID (or the
+// tenant ID when there is no user) together with the experiment key.
+func (e *Experiment) Assign(evalCtx featureflags.EvaluationContext) (string, bool) {
+	if !e.Enabled {
+		return "", false
+	}
+
+	subject := evalCtx.UserID
+	if subject == "" {
+		subject = evalCtx.TenantID
+	}
+	if subject == "" {
+		return "", false
+	}
+
+	total := 0
+	for _, variant := range e.Variants {
+		total += variant.Weight
+	}
+	if total <= 0 {
+		return "", false
+	}
+
+	point := int(featureflags.Hash(e.Key, subject) % uint32(total))
+	for _, variant := range e.Variants {
+		if point < variant.Weight {
+			return variant.Name, true
+		}
+		point -= variant.Weight
+	}
+	return "", false
+}
+
+// Domain errors for Experiment
+var (
+	ErrExperimentKeyRequired      = NewDomainError("experiment key is required")
+	ErrExperimentVariantsRequired = NewDomainError("experiment requires at least one variant")
+	ErrExperimentVariantInvalid   = NewDomainError("experiment variants must have a name and a non-negative weight, with a positive total")
+	ErrExperimentNotFound         = NewDomainError("experiment not found")
+)
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+)
+
+// FeatureFlag represents a feature flag definition
+type FeatureFlag struct {
+	BaseModel
+	Key               string     `gorm:"type:varchar(100);not null;uniqueIndex" json:"key"`
+	Description       string     `gorm:"type:text" json:"description"`
+	Enabled           bool       `gorm:"not null" json:"enabled"`
+	RolloutPercentage int        `gorm:"not null" json:"rollout_percentage"`
+	TargetUsers       StringList `gorm:"type:text" json:"target_users"`
+	TargetTenants     StringList `gorm:"type:text" json:"target_tenants"`
+}
+
+// TableName returns the table name for the FeatureFlag model
+func (f *FeatureFlag) TableName() string {
+	return "feature_flags"
+}
+
+// ShortTableName returns abbreviated table name
+func (f *FeatureFlag) ShortTableName() string {
+	return "ff"
+}
+
+// Index returns indexable fields for the FeatureFlag model
+func (f *FeatureFlag) Index() map[string]interface{} {
+	index := f.BaseModel.Index()
+	index["key"] = f.Key
+	index["enabled"] = f.Enabled
+	return index
+}
+
+// Validate performs business rule validation on the FeatureFlag model
+func (f *FeatureFlag) Validate() error {
+	if f.Key == "" {
+		return ErrFeatureFlagKeyRequired
+	}
+	if len(f.Key) > 100 || !featureFlagKeyPattern.MatchString(f.Key) {
+		return ErrFeatureFlagKeyInvalid
+	}
+	if f.RolloutPercentage < 0 || f.RolloutPercentage > 100 {
+		return ErrFeatureFlagRolloutInvalid
+	}
+	return nil
+}
+
+// Evaluate reports whether the flag is on for the evaluation context.
+// A disabled flag is always off; explicitly targeted users and tenants are
+// always on; everyone else falls into the percentage rollout, bucketed by
+// user ID (or tenant ID when there is no user).
+func (f *FeatureFlag) Evaluate(evalCtx featureflags.EvaluationContext) bool {
+	if !f.Enabled {
+		return false
+	}
+	if evalCtx.UserID != "" && f.TargetUsers.Contains(evalCtx.UserID) {
+		return true
+	}
+	if evalCtx.TenantID != "" && f.TargetTenants.Contains(evalCtx.TenantID) {
+		return true
+	}
+	if f.RolloutPercentage >= 100 {
+		return true
+	}
+	if f.RolloutPercentage <= 0 {
+		return false
+	}
+
+	subject := evalCtx.UserID
+	if subject == "" {
+		subject = evalCtx.TenantID
+	}
+	if subject == "" {
+		return false
+	}
+	return featureflags.Bucket(f.Key, subject) < f.RolloutPercentage
+}
+
+var featureFlagKeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]*$`)
+
+// Domain errors for FeatureFlag
+var (
+	ErrFeatureFlagKeyRequired    = NewDomainError("feature flag key is required")
+	ErrFeatureFlagKeyInvalid     = NewDomainError("feature flag key is invalid")
+	ErrFeatureFlagRolloutInvalid = NewDomainError("feature flag rollout percentage must be between 0 and 100")
+	ErrFeatureFlagNotFound       = NewDomainError("feature flag not found")
+	ErrFeatureFlagExists         = NewDomainError("feature flag with this key already exists")
+	ErrFeatureFlagReadOnly       = NewDomainError("feature flag is defined in configuration and cannot be modified")
+)
+
+// StringList is a list of strings stored as a JSON array
+type StringList []string
+
+// Contains reports whether the list contains the value
+func (l StringList) Contains(value string) bool {
+	for _, item := range l {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	var data []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for StringList: %T", value)
+	}
+	if len(data) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(l))
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"mime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Maximum lengths of file fields
+const (
+	MaxFileNameLength        = 255
+	MaxFileContentTypeLength = 255
+)
+
+// File statuses. A file is pending until the client reports that its upload
+// through the presigned URL completed.
+const (
+	FileStatusPending  = "pending"
+	FileStatusUploaded = "uploaded"
+)
+
+// File scan statuses. Files uploaded without scanning have no scan status.
+const (
+	FileScanStatusPending     = "pending"
+	FileScanStatusClean       = "clean"
+	FileScanStatusQuarantined = "quarantined"
+)
+
+// File tracks an object of the object storage uploaded by a client. The
+// content goes directly between the client and the storage through presigned
+// URLs; the API only issues the URLs and records the file.
+type File struct {
+	BaseModel
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	ContentType string     `gorm:"type:varchar(255);not null" json:"content_type"`
+	Size        int64      `gorm:"not null;default:0" json:"size"`
+	StorageKey  string     `gorm:"type:varchar(500);not null;uniqueIndex" json:"storage_key"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	OwnerID     string     `gorm:"type:varchar(100);index" json:"owner_id"`
+	OrgID       uint       `gorm:"not null;default:0;index" json:"org_id"`
+	UploadedAt  *time.Time `json:"uploaded_at,omitempty"`
+	// OperationID is the operation processing the uploaded content
+	OperationID string       `gorm:"type:varchar(36);index" json:"operation_id,omitempty"`
+	Variants    FileVariants `gorm:"type:jsonb;not null;default:'[]'" json:"variants"`
+	// ChecksumSHA256 is the hex SHA-256 of the content, computed once uploaded
+	ChecksumSHA256 string `gorm:"type:varchar(64)" json:"checksum_sha256,omitempty"`
+	ScanStatus     string `gorm:"type:varchar(20);index" json:"scan_status,omitempty"`
+	// ScanSignature names the malware found in a quarantined file
+	ScanSignature string `gorm:"type:varchar(255)" json:"scan_signature,omitempty"`
+}
+
+// TableName returns the table name for the File model
+func (f *File) TableName() string {
+	return "files"
+}
+
+// ShortTableName returns abbreviated table name
+func (f *File) ShortTableName() string {
+	return "file"
+}
+
+// Index returns indexable fields for the File model
+func (f *File) Index() map[string]interface{} {
+	index := f.BaseModel.Index()
+	index["storage_key"] = f.StorageKey
+	index["status"] = f.Status
+	index["owner_id"] = f.OwnerID
+	index["org_id"] = f.OrgID
+	index["scan_status"] = f.ScanStatus
+	return index
+}
+
+// Validate performs business rule validation on the File model
+func (f *File) Validate() error {
+	if f.Name == "" || len(f.Name) > MaxFileNameLength || strings.ContainsAny(f.Name, "/\\\x00") {
+		return ErrFileNameInvalid
+	}
+	if len(f.ContentType) > MaxFileContentTypeLength {
+		return ErrFileContentTypeInvalid
+	}
+	if _, _, err := mime.ParseMediaType(f.ContentType); err != nil {
+		return ErrFileContentTypeInvalid
+	}
+	if f.Size <= 0 {
+		return ErrFileSizeInvalid
+	}
+	return nil
+}
+
+// AuthorizationResource returns the file as the resource of an authorized action
+func (f *File) AuthorizationResource() Resource {
+	return Resource{
+		Type:    ResourceFile,
+		ID:      strconv.FormatUint(uint64(f.ID), 10),
+		OwnerID: f.OwnerID,
+		OrgID:   f.OrgID,
+	}
+}
+
+// Downloadable returns nil when the content of the file may be downloaded: it
+// is uploaded and, when scanned, found clean
+func (f *File) Downloadable() error {
+	switch {
+	case f.Status != FileStatusUploaded:
+		return ErrFileNotUploaded
+	case f.ScanStatus == FileScanStatusPending:
+		return ErrFileScanPending
+	case f.ScanStatus == FileScanStatusQuarantined:
+		return ErrFileQuarantined
+	}
+	return nil
+}
+
+// FileVariant is a rendition of a file derived from its content, such as a
+// thumbnail of an image, stored next to it
+type FileVariant struct {
+	Name        string `json:"name"`
+	StorageKey  string `json:"storage_key"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	Size        int64  `json:"size"`
+}
+
+// FileVariants is a list of file variants stored as a JSON array
+type FileVariants []FileVariant
+
+// Value implements driver.Valuer
+func (v FileVariants) Value() (driver.Value, error) {
+	if v == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]FileVariant(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (v *FileVariants) Scan(value interface{}) error {
+	return scanJSON(value, v)
+}
+
+// Domain errors for files
+var (
+	ErrFileNotFound              = NewDomainError("file not found")
+	ErrFileNameInvalid           = NewDomainError("file name must be 1 to 255 characters without slashes")
+	ErrFileContentTypeInvalid    = NewDomainError("file content type must be a valid media type")
+	ErrFileContentTypeNotAllowed = NewDomainError("file content type is not allowed")
+	ErrFileSizeInvalid           = NewDomainError("file size must be positive")
+	ErrFileTooLarge              = NewDomainError("file exceeds the maximum size")
+	ErrFileNotUploaded           = NewDomainError("file upload is not completed")
+	ErrFileUploadMissing         = NewDomainError("file was not uploaded with the declared size")
+	ErrFileScanPending           = NewDomainError("file is not scanned yet")
+	ErrFileQuarantined           = NewDomainError("file is quarantined as malware")
+	ErrFileNotVerified           = NewDomainError("file checksum is not computed yet")
+	ErrFileChecksumMismatch      = NewDomainError("file content does not match its checksum")
+)
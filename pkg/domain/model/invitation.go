@@ -0,0 +1,98 @@
+package model
+
+import (
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// Maximum lengths of invitation fields
+const (
+	MaxInvitationEmailLength = 254
+)
+
+// Invitation statuses. A pending invitation whose token has expired is
+// reported as expired.
+const (
+	InvitationStatusPending  = "pending"
+	InvitationStatusAccepted = "accepted"
+	InvitationStatusRevoked  = "revoked"
+	InvitationStatusExpired  = "expired"
+)
+
+// Invitation invites a user by email. Accepting it provisions the user with
+// Role and Permissions, and with a membership of OrgID with OrgRole when OrgID
+// is set. Nonce is the random part of the signed token mailed to the user.
+type Invitation struct {
+	BaseModel
+	Email       string     `gorm:"type:varchar(254);not null;index" json:"email"`
+	Role        string     `gorm:"type:varchar(50);not null" json:"role"`
+	Permissions StringList `gorm:"type:text" json:"permissions"`
+	OrgID       uint       `gorm:"not null;default:0;index" json:"org_id"`
+	OrgRole     string     `gorm:"type:varchar(20)" json:"org_role"`
+	InvitedBy   string     `gorm:"type:varchar(100);not null;index" json:"invited_by"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Nonce       string     `gorm:"type:varchar(64);not null" json:"-"`
+	ExpiresAt   time.Time  `gorm:"index" json:"expires_at"`
+	AcceptedBy  string     `gorm:"type:varchar(100)" json:"accepted_by"`
+	AcceptedAt  *time.Time `json:"accepted_at"`
+	RevokedAt   *time.Time `json:"revoked_at"`
+}
+
+// TableName returns the table name for the Invitation model
+func (i *Invitation) TableName() string {
+	return "invitations"
+}
+
+// ShortTableName returns abbreviated table name
+func (i *Invitation) ShortTableName() string {
+	return "inv"
+}
+
+// Index returns indexable fields for the Invitation model
+func (i *Invitation) Index() map[string]interface{} {
+	index := i.BaseModel.Index()
+	index["email"] = i.Email
+	index["org_id"] = i.OrgID
+	index["invited_by"] = i.InvitedBy
+	index["status"] = i.Status
+	return index
+}
+
+// Validate performs business rule validation on the Invitation model
+func (i *Invitation) Validate() error {
+	if len(i.Email) > MaxInvitationEmailLength {
+		return ErrInvitationEmailInvalid
+	}
+	if addr, err := mail.ParseAddress(i.Email); err != nil || addr.Address != i.Email {
+		return ErrInvitationEmailInvalid
+	}
+	if i.OrgID != 0 && !IsOrganizationRole(i.OrgRole) {
+		return ErrOrganizationRoleInvalid
+	}
+	return nil
+}
+
+// NormalizeEmail returns the email address of an invitation in lowercase,
+// which is also the user ID of the provisioned user
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// StatusAt returns the status of the invitation at now
+func (i *Invitation) StatusAt(now time.Time) string {
+	if i.Status == InvitationStatusPending && !now.Before(i.ExpiresAt) {
+		return InvitationStatusExpired
+	}
+	return i.Status
+}
+
+// Domain errors for invitations
+var (
+	ErrInvitationEmailInvalid = NewDomainError("invitation email must be a valid email address")
+	ErrInvitationNotFound     = NewDomainError("invitation not found")
+	ErrInvitationTokenInvalid = NewDomainError("invitation token is invalid")
+	ErrInvitationExpired      = NewDomainError("invitation has expired")
+	ErrInvitationNotPending   = NewDomainError("invitation was already accepted or revoked")
+	ErrInvitationForbidden    = NewDomainError("not allowed to manage invitations of this organization or role")
+)
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Notification channels
+const (
+	NotificationChannelSMS     = "sms"
+	NotificationChannelPush    = "push"
+	NotificationChannelWebhook = "webhook"
+)
+
+// NotificationChannels lists the supported notification channels
+var NotificationChannels = []string{NotificationChannelSMS, NotificationChannelPush, NotificationChannelWebhook}
+
+// MaxNotificationAddressLength is the maximum length of a channel address, enough for push device tokens
+const MaxNotificationAddressLength = 4096
+
+// phoneNumberPattern matches E.164 phone numbers, e.g. +8613800138000
+var phoneNumberPattern = regexp.MustCompile(`^\+[1-9][0-9]{6,14}$`)
+
+// NotificationPreference is the address and opt-in of a user for a notification channel
+type NotificationPreference struct {
+	BaseModel
+	UserID  string `gorm:"type:varchar(100);not null;uniqueIndex:idx_notification_preferences_user_channel" json:"user_id"`
+	Channel string `gorm:"type:varchar(20);not null;uniqueIndex:idx_notification_preferences_user_channel" json:"channel"`
+	// Address is a phone number for sms, a device token for push and a URL for webhook
+	Address string `gorm:"type:text;not null" json:"address"`
+	Enabled bool   `gorm:"not null" json:"enabled"`
+	// Language of the notifications, empty for the configured default
+	Language string `gorm:"type:varchar(10)" json:"language"`
+}
+
+// TableName returns the table name for the NotificationPreference model
+func (p *NotificationPreference) TableName() string {
+	return "notification_preferences"
+}
+
+// ShortTableName returns abbreviated table name
+func (p *NotificationPreference) ShortTableName() string {
+	return "np"
+}
+
+// Index returns indexable fields for the NotificationPreference model
+func (p *NotificationPreference) Index() map[string]interface{} {
+	index := p.BaseModel.Index()
+	index["user_id"] = p.UserID
+	index["channel"] = p.Channel
+	index["enabled"] = p.Enabled
+	return index
+}
+
+// Validate performs business rule validation on the NotificationPreference model
+func (p *NotificationPreference) Validate() error {
+	if !IsNotificationChannel(p.Channel) {
+		return ErrNotificationChannelInvalid
+	}
+	if p.Address == "" || len(p.Address) > MaxNotificationAddressLength {
+		return ErrNotificationAddressInvalid
+	}
+	switch p.Channel {
+	case NotificationChannelSMS:
+		if !phoneNumberPattern.MatchString(p.Address) {
+			return ErrNotificationAddressInvalid
+		}
+	case NotificationChannelWebhook:
+		u, err := url.Parse(p.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || internalHost(u.Hostname()) {
+			return ErrNotificationAddressInvalid
+		}
+	}
+	return nil
+}
+
+// internalHost reports whether a webhook host obviously names the server or its
+// network. Host names resolving to internal addresses are rejected when the
+// webhook is called.
+func internalHost(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast())
+}
+
+// IsNotificationChannel reports whether channel is a supported notification channel
+func IsNotificationChannel(channel string) bool {
+	for _, c := range NotificationChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// Domain errors for NotificationPreference
+var (
+	ErrNotificationChannelInvalid      = NewDomainError("notification channel must be one of sms, push or webhook")
+	ErrNotificationAddressInvalid      = NewDomainError("notification address must be an E.164 phone number for sms, a device token for push or a public http(s) URL for webhook")
+	ErrNotificationPreferenceNotFound  = NewDomainError("notification preference not found")
+	ErrNotificationChannelsUnavailable = NewDomainError("user has no enabled notification channel")
+)
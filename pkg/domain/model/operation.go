@@ -0,0 +1,113 @@
+package model
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"time"
+)
+
+// Operation types
+const (
+	OperationTypeApplicationBackup      = "application_backup"
+	OperationTypeApplicationImport      = "application_import"
+	OperationTypeApplicationBatchDelete = "application_batch_delete"
+	OperationTypeEmailDelivery          = "email_delivery"
+	OperationTypeFileProcessing         = "file_processing"
+)
+
+// Operation statuses, shared by the jobs that report progress
+const (
+	OperationStatusPending   = "pending"
+	OperationStatusRunning   = "running"
+	OperationStatusCompleted = "completed"
+	OperationStatusFailed    = "failed"
+)
+
+// Operation tracks a long-running action executed in the background. Result
+// holds the JSON outcome of a completed operation and Error the reason of a
+// failed one.
+type Operation struct {
+	BaseModel
+	OperationID string     `gorm:"type:varchar(36);not null;uniqueIndex" json:"operation_id"`
+	Type        string     `gorm:"type:varchar(50);not null;index" json:"type"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Progress    int        `gorm:"not null;default:0" json:"progress"` // percentage between 0 and 100
+	Message     string     `gorm:"type:text" json:"message,omitempty"`
+	Result      RawJSON    `gorm:"type:jsonb" json:"result,omitempty"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	Actor       string     `gorm:"type:varchar(100);index" json:"actor"`   // user who started the operation
+	OrgID       uint       `gorm:"not null;default:0;index" json:"org_id"` // organization it was started in, 0 without one
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// TableName returns the table name for the Operation model
+func (o *Operation) TableName() string {
+	return "operations"
+}
+
+// ShortTableName returns abbreviated table name
+func (o *Operation) ShortTableName() string {
+	return "op"
+}
+
+// Index returns indexable fields for the Operation model
+func (o *Operation) Index() map[string]interface{} {
+	index := o.BaseModel.Index()
+	index["operation_id"] = o.OperationID
+	index["type"] = o.Type
+	index["status"] = o.Status
+	index["actor"] = o.Actor
+	index["org_id"] = o.OrgID
+	return index
+}
+
+// Finished reports whether the operation has completed or failed
+func (o *Operation) Finished() bool {
+	return o.Status == OperationStatusCompleted || o.Status == OperationStatusFailed
+}
+
+// RawJSON is an encoded JSON document stored in a JSON column; empty is NULL
+type RawJSON []byte
+
+// Value implements driver.Valuer
+func (j RawJSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return string(j), nil
+}
+
+// Scan implements sql.Scanner
+func (j *RawJSON) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append(RawJSON(nil), v...)
+	case string:
+		*j = RawJSON(v)
+	default:
+		return fmt.Errorf("cannot scan %T into %T", value, j)
+	}
+	return nil
+}
+
+// MarshalJSON returns the document itself
+func (j RawJSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+// UnmarshalJSON stores a copy of the document
+func (j *RawJSON) UnmarshalJSON(data []byte) error {
+	*j = append(RawJSON(nil), data...)
+	return nil
+}
+
+// Domain errors for operations
+var (
+	ErrOperationNotFound = NewDomainError("operation not found")
+	ErrOperationFinished = NewDomainError("operation has finished")
+)
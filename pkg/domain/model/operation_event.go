@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// OperationProgress is a progress update reported by a long-running operation
+type OperationProgress struct {
+	OperationID string `json:"operation_id"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Progress    int    `json:"progress"` // percentage between 0 and 100
+	Message     string `json:"message,omitempty"`
+}
+
+// Finished reports whether the update ends the operation
+func (p *OperationProgress) Finished() bool {
+	return p.Status == OperationStatusCompleted || p.Status == OperationStatusFailed
+}
+
+// OperationEvent is a recorded progress update. Sequence numbers start at 1 and
+// increase per operation, so that a reconnecting client resumes after the last
+// event it received.
+type OperationEvent struct {
+	OperationProgress
+	Sequence  uint64    `json:"sequence"`
+	Timestamp time.Time `json:"timestamp"`
+}
@@ -0,0 +1,147 @@
+package model
+
+import (
+	"context"
+	"regexp"
+)
+
+// Maximum lengths of organization fields
+const (
+	MaxOrganizationNameLength        = 50
+	MaxOrganizationDescriptionLength = 500
+)
+
+// Organization membership roles, from the most to the least privileged. Owners
+// manage the organization and its owners, admins manage its members, and
+// members use its resources.
+const (
+	OrganizationRoleOwner  = "owner"
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleMember = "member"
+)
+
+// organizationRoleRanks orders the membership roles by privilege
+var organizationRoleRanks = map[string]int{
+	OrganizationRoleMember: 1,
+	OrganizationRoleAdmin:  2,
+	OrganizationRoleOwner:  3,
+}
+
+// organizationNamePattern matches organization names, e.g. acme or acme-labs
+var organizationNamePattern = regexp.MustCompile(`^[a-z][a-z0-9-]*$`)
+
+// Organization groups users and owns the applications they share
+type Organization struct {
+	BaseModel
+	Name        string `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	Description string `gorm:"type:varchar(500)" json:"description"`
+}
+
+// TableName returns the table name for the Organization model
+func (o *Organization) TableName() string {
+	return "organizations"
+}
+
+// ShortTableName returns abbreviated table name
+func (o *Organization) ShortTableName() string {
+	return "org"
+}
+
+// Index returns indexable fields for the Organization model
+func (o *Organization) Index() map[string]interface{} {
+	index := o.BaseModel.Index()
+	index["name"] = o.Name
+	return index
+}
+
+// Validate performs business rule validation on the Organization model
+func (o *Organization) Validate() error {
+	if len(o.Name) > MaxOrganizationNameLength || !organizationNamePattern.MatchString(o.Name) {
+		return ErrOrganizationNameInvalid
+	}
+	if len(o.Description) > MaxOrganizationDescriptionLength {
+		return ErrOrganizationDescriptionTooLong
+	}
+	return nil
+}
+
+// OrganizationMember is the membership of a user in an organization
+type OrganizationMember struct {
+	BaseModel
+	OrgID  uint   `gorm:"not null;uniqueIndex:idx_organization_members_org_user" json:"org_id"`
+	UserID string `gorm:"type:varchar(100);not null;uniqueIndex:idx_organization_members_org_user;index" json:"user_id"`
+	Role   string `gorm:"type:varchar(20);not null" json:"role"`
+}
+
+// TableName returns the table name for the OrganizationMember model
+func (m *OrganizationMember) TableName() string {
+	return "organization_members"
+}
+
+// ShortTableName returns abbreviated table name
+func (m *OrganizationMember) ShortTableName() string {
+	return "om"
+}
+
+// Index returns indexable fields for the OrganizationMember model
+func (m *OrganizationMember) Index() map[string]interface{} {
+	index := m.BaseModel.Index()
+	index["org_id"] = m.OrgID
+	index["user_id"] = m.UserID
+	index["role"] = m.Role
+	return index
+}
+
+// Validate performs business rule validation on the OrganizationMember model
+func (m *OrganizationMember) Validate() error {
+	if m.UserID == "" {
+		return ErrOrganizationMemberUserRequired
+	}
+	if !IsOrganizationRole(m.Role) {
+		return ErrOrganizationRoleInvalid
+	}
+	return nil
+}
+
+// IsOrganizationRole reports whether role is a membership role
+func IsOrganizationRole(role string) bool {
+	_, ok := organizationRoleRanks[role]
+	return ok
+}
+
+// OrganizationRoleAtLeast reports whether role is as privileged as least. Unknown
+// roles are never privileged enough.
+func OrganizationRoleAtLeast(role, least string) bool {
+	rank, ok := organizationRoleRanks[role]
+	return ok && rank >= organizationRoleRanks[least]
+}
+
+// organizationContextKey is the context key of the active organization
+type organizationContextKey struct{}
+
+// WithOrganization returns a context scoped to the organization orgID. Services
+// only read and create the resources of the active organization of a context.
+func WithOrganization(ctx context.Context, orgID uint) context.Context {
+	return context.WithValue(ctx, organizationContextKey{}, orgID)
+}
+
+// OrganizationFromContext returns the active organization of ctx. Contexts
+// without one, such as startup tasks, are not scoped.
+func OrganizationFromContext(ctx context.Context) (uint, bool) {
+	orgID, ok := ctx.Value(organizationContextKey{}).(uint)
+	return orgID, ok
+}
+
+// Domain errors for organizations
+var (
+	ErrOrganizationNameInvalid        = NewDomainError("organization name must be lowercase letters, digits and -, starting with a letter, at most 50 characters")
+	ErrOrganizationDescriptionTooLong = NewDomainError("organization description must be at most 500 characters")
+	ErrOrganizationNotFound           = NewDomainError("organization not found")
+	ErrOrganizationExists             = NewDomainError("organization with this name already exists")
+	ErrOrganizationNotEmpty           = NewDomainError("organization still owns applications")
+	ErrOrganizationMemberUserRequired = NewDomainError("organization member user is required")
+	ErrOrganizationRoleInvalid        = NewDomainError("organization role must be one of owner, admin or member")
+	ErrOrganizationMemberNotFound     = NewDomainError("organization member not found")
+	ErrOrganizationMemberExists       = NewDomainError("user is already a member of the organization")
+	ErrOrganizationOwnerRequired      = NewDomainError("an organization must keep at least one owner")
+)
@@ -0,0 +1,75 @@
+package model
+
+import "time"
+
+// Outbox message statuses
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusPublished = "published"
+	OutboxStatusFailed    = "failed"
+)
+
+// OutboxMessage is a domain event stored in the transaction that produced it,
+// waiting to be published to the message broker. MessageID is kept as the
+// broker message ID so that consumers can drop redeliveries.
+type OutboxMessage struct {
+	BaseModel
+	MessageID string  `gorm:"type:varchar(36);not null;uniqueIndex" json:"message_id"`
+	Topic     string  `gorm:"type:varchar(255);not null" json:"topic"`
+	EventType string  `gorm:"type:varchar(100);not null;index" json:"event_type"`
+	Headers   RawJSON `gorm:"type:jsonb" json:"headers,omitempty"`
+	Payload   []byte  `json:"payload"`
+	Status    string  `gorm:"type:varchar(20);not null;index:idx_outbox_messages_status_next_attempt" json:"status"`
+	Attempts  int     `gorm:"not null;default:0" json:"attempts"`
+	// NextAttemptAt is when the relay publishes the message, delayed after a failed attempt
+	NextAttemptAt time.Time  `gorm:"not null;index:idx_outbox_messages_status_next_attempt" json:"next_attempt_at"`
+	LastError     string     `gorm:"type:text" json:"last_error,omitempty"`
+	PublishedAt   *time.Time `json:"published_at,omitempty"`
+}
+
+// TableName returns the table name for the OutboxMessage model
+func (m *OutboxMessage) TableName() string {
+	return "outbox_messages"
+}
+
+// ShortTableName returns abbreviated table name
+func (m *OutboxMessage) ShortTableName() string {
+	return "om"
+}
+
+// Index returns indexable fields for the OutboxMessage model
+func (m *OutboxMessage) Index() map[string]interface{} {
+	index := m.BaseModel.Index()
+	index["message_id"] = m.MessageID
+	index["event_type"] = m.EventType
+	index["status"] = m.Status
+	index["next_attempt_at"] = m.NextAttemptAt
+	return index
+}
+
+// ProcessedMessage records a broker message handled by a consumer group, so
+// that a redelivered message is not handled twice
+type ProcessedMessage struct {
+	BaseModel
+	ConsumerGroup string `gorm:"type:varchar(255);not null;uniqueIndex:idx_processed_messages_group_message" json:"consumer_group"`
+	MessageID     string `gorm:"type:varchar(255);not null;uniqueIndex:idx_processed_messages_group_message" json:"message_id"`
+	Topic         string `gorm:"type:varchar(255);not null" json:"topic"`
+}
+
+// TableName returns the table name for the ProcessedMessage model
+func (m *ProcessedMessage) TableName() string {
+	return "processed_messages"
+}
+
+// ShortTableName returns abbreviated table name
+func (m *ProcessedMessage) ShortTableName() string {
+	return "pm"
+}
+
+// Index returns indexable fields for the ProcessedMessage model
+func (m *ProcessedMessage) Index() map[string]interface{} {
+	index := m.BaseModel.Index()
+	index["consumer_group"] = m.ConsumerGroup
+	index["message_id"] = m.MessageID
+	return index
+}
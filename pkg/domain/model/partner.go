@@ -0,0 +1,69 @@
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// Maximum lengths of partner fields
+const (
+	MaxPartnerNameLength        = 50
+	MaxPartnerDescriptionLength = 500
+)
+
+// partnerNamePattern matches partner names, e.g. acme or acme-billing
+var partnerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// Partner is an integration partner calling the signed partner routes. Its name
+// is the key ID of its requests; secrets are stored encrypted and never
+// serialized. After a rotation the previous secret keeps verifying requests
+// until PreviousSecretExpiresAt, so that the partner can switch without downtime.
+type Partner struct {
+	BaseModel
+	Name        string `gorm:"type:varchar(50);not null;uniqueIndex" json:"name"`
+	Description string `gorm:"type:varchar(500)" json:"description"`
+	Enabled     bool   `gorm:"not null" json:"enabled"`
+	Secret      string `gorm:"type:text;not null" json:"-"`
+	// PreviousSecret is the secret replaced by the last rotation
+	PreviousSecret          string     `gorm:"type:text" json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+	SecretRotatedAt         *time.Time `json:"secret_rotated_at,omitempty"`
+}
+
+// TableName returns the table name for the Partner model
+func (p *Partner) TableName() string {
+	return "partners"
+}
+
+// ShortTableName returns abbreviated table name
+func (p *Partner) ShortTableName() string {
+	return "pt"
+}
+
+// Index returns indexable fields for the Partner model
+func (p *Partner) Index() map[string]interface{} {
+	index := p.BaseModel.Index()
+	index["name"] = p.Name
+	index["enabled"] = p.Enabled
+	return index
+}
+
+// Validate performs business rule validation on the Partner model
+func (p *Partner) Validate() error {
+	if len(p.Name) > MaxPartnerNameLength || !partnerNamePattern.MatchString(p.Name) {
+		return ErrPartnerNameInvalid
+	}
+	if len(p.Description) > MaxPartnerDescriptionLength {
+		return ErrPartnerDescriptionTooLong
+	}
+	return nil
+}
+
+// Domain errors for partners
+var (
+	ErrPartnerNameInvalid        = NewDomainError("partner name must be lowercase letters, digits, - and _, starting with a letter, at most 50 characters")
+	ErrPartnerDescriptionTooLong = NewDomainError("partner description must be at most 500 characters")
+	ErrPartnerNotFound           = NewDomainError("partner not found")
+	ErrPartnerExists             = NewDomainError("partner with this name already exists")
+	ErrPartnerDisabled           = NewDomainError("partner is disabled")
+)
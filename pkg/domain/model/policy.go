@@ -0,0 +1,122 @@
+package model
+
+import (
+	"regexp"
+
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+)
+
+// Built-in policy names
+const (
+	PolicyTerms   = "terms"
+	PolicyPrivacy = "privacy"
+)
+
+// Maximum lengths of policy document fields
+const (
+	MaxPolicyNameLength  = 50
+	MaxPolicyTitleLength = 200
+	// MaxPolicyUserAgentLength is the maximum length of the user agent recorded with a consent
+	MaxPolicyUserAgentLength = 500
+)
+
+// policyNamePattern matches policy names, e.g. terms or cookie-policy
+var policyNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*$`)
+
+// PolicyDocument is the text of a policy version in one language. A version is
+// published in one or more languages; the highest version of a policy is the
+// current one, which users accept.
+type PolicyDocument struct {
+	BaseModel
+	Name     string `gorm:"type:varchar(50);not null;uniqueIndex:idx_policy_documents_name_version_language" json:"name"`
+	Version  int    `gorm:"not null;uniqueIndex:idx_policy_documents_name_version_language" json:"version"`
+	Language string `gorm:"type:varchar(10);not null;uniqueIndex:idx_policy_documents_name_version_language" json:"language"`
+	Title    string `gorm:"type:varchar(200);not null" json:"title"`
+	Content  string `gorm:"type:text;not null" json:"content"`
+	// Summary describes the changes from the previous version
+	Summary string `gorm:"type:text" json:"summary"`
+}
+
+// TableName returns the table name for the PolicyDocument model
+func (d *PolicyDocument) TableName() string {
+	return "policy_documents"
+}
+
+// ShortTableName returns abbreviated table name
+func (d *PolicyDocument) ShortTableName() string {
+	return "pd"
+}
+
+// Index returns indexable fields for the PolicyDocument model
+func (d *PolicyDocument) Index() map[string]interface{} {
+	index := d.BaseModel.Index()
+	index["name"] = d.Name
+	index["version"] = d.Version
+	index["language"] = d.Language
+	return index
+}
+
+// Validate performs business rule validation on the PolicyDocument model
+func (d *PolicyDocument) Validate() error {
+	if !IsPolicyName(d.Name) {
+		return ErrPolicyNameInvalid
+	}
+	if d.Version < 1 {
+		return ErrPolicyVersionInvalid
+	}
+	if _, ok := i18n.LanguageMap[d.Language]; !ok {
+		return ErrPolicyLanguageInvalid
+	}
+	if d.Title == "" || len(d.Title) > MaxPolicyTitleLength || d.Content == "" {
+		return ErrPolicyContentInvalid
+	}
+	return nil
+}
+
+// IsPolicyName reports whether name is a valid policy name
+func IsPolicyName(name string) bool {
+	return len(name) <= MaxPolicyNameLength && policyNamePattern.MatchString(name)
+}
+
+// PolicyConsent records that a user accepted a version of a policy
+type PolicyConsent struct {
+	BaseModel
+	UserID  string `gorm:"type:varchar(100);not null;uniqueIndex:idx_policy_consents_user_policy_version" json:"user_id"`
+	Policy  string `gorm:"type:varchar(50);not null;uniqueIndex:idx_policy_consents_user_policy_version" json:"policy"`
+	Version int    `gorm:"not null;uniqueIndex:idx_policy_consents_user_policy_version" json:"version"`
+	// Language of the document the user accepted
+	Language string `gorm:"type:varchar(10)" json:"language"`
+	// IPAddress and UserAgent of the request that accepted the policy, kept as evidence
+	IPAddress string `gorm:"type:varchar(45)" json:"ip_address"`
+	UserAgent string `gorm:"type:varchar(500)" json:"user_agent"`
+}
+
+// TableName returns the table name for the PolicyConsent model
+func (c *PolicyConsent) TableName() string {
+	return "policy_consents"
+}
+
+// ShortTableName returns abbreviated table name
+func (c *PolicyConsent) ShortTableName() string {
+	return "pc"
+}
+
+// Index returns indexable fields for the PolicyConsent model
+func (c *PolicyConsent) Index() map[string]interface{} {
+	index := c.BaseModel.Index()
+	index["user_id"] = c.UserID
+	index["policy"] = c.Policy
+	index["version"] = c.Version
+	return index
+}
+
+// Domain errors for policies
+var (
+	ErrPolicyNameInvalid     = NewDomainError("policy name must be lowercase letters, digits, - and _, starting with a letter, at most 50 characters")
+	ErrPolicyVersionInvalid  = NewDomainError("policy version must be the current version or the next one")
+	ErrPolicyLanguageInvalid = NewDomainError("policy language is not supported")
+	ErrPolicyContentInvalid  = NewDomainError("policy title must be 1 to 200 characters and content must not be empty")
+	ErrPolicyDocumentExists  = NewDomainError("policy version is already published in this language")
+	ErrPolicyNotFound        = NewDomainError("policy not found")
+	ErrPolicyVersionOutdated = NewDomainError("policy version is not the current version")
+)
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// TimeRange selects the times between From and To inclusively. A zero From or
+// To leaves that side of the range open.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// IsZero reports whether the range is open on both sides
+func (r TimeRange) IsZero() bool {
+	return r.From.IsZero() && r.To.IsZero()
+}
+
+// SortOrder orders query results by a field, ascending unless Desc is set
+type SortOrder struct {
+	Field string
+	Desc  bool
+}
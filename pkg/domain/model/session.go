@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// Maximum lengths of session fields
+const (
+	MaxSessionUserAgentLength = 500
+)
+
+// Session is a login of a user on a device. It holds the hashes of its current
+// refresh token, which is replaced at each refresh, and of the token it
+// replaced, whose replay reveals a leak, and the claims of the
+// access tokens it issues. UserAgent, IP and LastSeenAt describe the device
+// for the session listing.
+type Session struct {
+	BaseModel
+	UserID      string     `gorm:"type:varchar(100);not null;index" json:"user_id"`
+	Username    string     `gorm:"type:varchar(100)" json:"username"`
+	Role        string     `gorm:"type:varchar(50)" json:"role"`
+	Permissions StringList `gorm:"type:text" json:"permissions"`
+	// RefreshTokenHash is the hex SHA-256 of the secret part of the refresh token
+	RefreshTokenHash string `gorm:"type:varchar(64);not null" json:"-"`
+	// PreviousRefreshTokenHash is the hash of the secret replaced by the last refresh
+	PreviousRefreshTokenHash string    `gorm:"type:varchar(64)" json:"-"`
+	UserAgent                string    `gorm:"type:varchar(500)" json:"user_agent"`
+	IP                       string    `gorm:"type:varchar(45)" json:"ip"`
+	LastSeenAt               time.Time `json:"last_seen_at"`
+	ExpiresAt                time.Time `gorm:"index" json:"expires_at"`
+}
+
+// TableName returns the table name for the Session model
+func (s *Session) TableName() string {
+	return "sessions"
+}
+
+// ShortTableName returns abbreviated table name
+func (s *Session) ShortTableName() string {
+	return "ss"
+}
+
+// Index returns indexable fields for the Session model
+func (s *Session) Index() map[string]interface{} {
+	index := s.BaseModel.Index()
+	index["user_id"] = s.UserID
+	index["last_seen_at"] = s.LastSeenAt
+	index["refresh_token_hash"] = s.RefreshTokenHash
+	return index
+}
+
+// Validate performs business rule validation on the Session model
+func (s *Session) Validate() error {
+	if s.UserID == "" {
+		return ErrSessionUserRequired
+	}
+	return nil
+}
+
+// Expired reports whether the refresh token of the session has expired at now
+func (s *Session) Expired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
+
+// Domain errors for sessions
+var (
+	ErrSessionUserRequired       = NewDomainError("session user is required")
+	ErrSessionNotFound           = NewDomainError("session not found")
+	ErrSessionExpired            = NewDomainError("session has expired")
+	ErrRefreshTokenInvalid       = NewDomainError("refresh token is invalid")
+	ErrCurrentSessionUnavailable = NewDomainError("request is not authenticated by a session")
+)
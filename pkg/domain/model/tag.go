@@ -0,0 +1,147 @@
+package model
+
+import (
+	"sort"
+	"strings"
+)
+
+// MaxTags is the maximum number of tags on a single entity
+const MaxTags = 50
+
+// maxTagLength is the maximum length of a single tag
+const maxTagLength = 100
+
+// Tagged is implemented by entities that carry tags and support tag selectors
+type Tagged interface {
+	GetTags() StringList
+}
+
+// NormalizeTags trims, validates, de-duplicates and sorts tags. A tag is either
+// a plain label ("beta") or a key/value pair ("env:prod"); keys are lower case.
+func NormalizeTags(tags []string) (StringList, error) {
+	seen := make(map[string]bool, len(tags))
+	normalized := make(StringList, 0, len(tags))
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			normalized = append(normalized, tag)
+		}
+	}
+	if len(normalized) > MaxTags {
+		return nil, ErrTooManyTags
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// normalizeTag trims a tag and lower cases its key
+func normalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	key, value, hasValue := strings.Cut(tag, ":")
+	key = strings.ToLower(strings.TrimSpace(key))
+	value = strings.TrimSpace(value)
+
+	if !validTagPart(key) || (hasValue && !validTagPart(value)) || len(tag) > maxTagLength {
+		return "", ErrTagInvalid
+	}
+	if hasValue {
+		return key + ":" + value, nil
+	}
+	return key, nil
+}
+
+// validTagPart reports whether a tag key or value only uses letters, digits and "_.-/"
+func validTagPart(part string) bool {
+	if part == "" {
+		return false
+	}
+	for _, r := range part {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '_', r == '.', r == '-', r == '/':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// AddTags returns tags with the given tags added
+func AddTags(tags StringList, add []string) (StringList, error) {
+	return NormalizeTags(append(append([]string{}, tags...), add...))
+}
+
+// RemoveTags returns tags without the given tags
+func RemoveTags(tags StringList, remove []string) (StringList, error) {
+	removed := make(map[string]bool, len(remove))
+	for _, tag := range remove {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		removed[tag] = true
+	}
+
+	result := make(StringList, 0, len(tags))
+	for _, tag := range tags {
+		if !removed[tag] {
+			result = append(result, tag)
+		}
+	}
+	return result, nil
+}
+
+// TagSelector selects entities by tag. Every term must match: "key:value"
+// matches that exact tag, "key" matches the plain tag or any value of the key.
+type TagSelector []string
+
+// ParseTagSelector parses a comma separated selector such as "env:prod,team:core"
+func ParseTagSelector(selector string) (TagSelector, error) {
+	if strings.TrimSpace(selector) == "" {
+		return nil, nil
+	}
+
+	terms := strings.Split(selector, ",")
+	result := make(TagSelector, 0, len(terms))
+	for _, term := range terms {
+		term, err := normalizeTag(term)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, term)
+	}
+	return result, nil
+}
+
+// Matches reports whether tags satisfy every term of the selector
+func (s TagSelector) Matches(tags StringList) bool {
+	for _, term := range s {
+		if !matchesTerm(term, tags) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesTerm reports whether a single selector term matches tags
+func matchesTerm(term string, tags StringList) bool {
+	for _, tag := range tags {
+		if tag == term {
+			return true
+		}
+		if !strings.Contains(term, ":") && strings.HasPrefix(tag, term+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// Domain errors for tags
+var (
+	ErrTagInvalid  = NewDomainError("tags must be labels or key:value pairs of letters, digits and _.-/ up to 100 characters")
+	ErrTooManyTags = NewDomainError("too many tags")
+)
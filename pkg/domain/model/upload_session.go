@@ -0,0 +1,82 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+)
+
+// UploadSession tracks the resumable upload of a pending file through the
+// API. The client sends the content in chunks at increasing offsets, each
+// stored as a part object, then finalizes the session, which assembles the
+// parts into the object of the file.
+type UploadSession struct {
+	BaseModel
+	FileID uint `gorm:"not null;uniqueIndex" json:"file_id"`
+	// Offset is the number of bytes received, the offset of the next chunk
+	Offset    int64       `gorm:"not null;default:0" json:"offset"`
+	Parts     UploadParts `gorm:"type:jsonb;not null;default:'[]'" json:"parts"`
+	ExpiresAt time.Time   `gorm:"not null;index" json:"expires_at"`
+}
+
+// TableName returns the table name for the UploadSession model
+func (u *UploadSession) TableName() string {
+	return "upload_sessions"
+}
+
+// ShortTableName returns abbreviated table name
+func (u *UploadSession) ShortTableName() string {
+	return "us"
+}
+
+// Index returns indexable fields for the UploadSession model
+func (u *UploadSession) Index() map[string]interface{} {
+	index := u.BaseModel.Index()
+	index["file_id"] = u.FileID
+	index["expires_at"] = u.ExpiresAt
+	return index
+}
+
+// Expired reports whether the session expired at now
+func (u *UploadSession) Expired(now time.Time) bool {
+	return !now.Before(u.ExpiresAt)
+}
+
+// UploadPart is a chunk of an upload session stored as an object
+type UploadPart struct {
+	Offset     int64  `json:"offset"`
+	Size       int64  `json:"size"`
+	StorageKey string `json:"storage_key"`
+}
+
+// UploadParts is a list of upload parts stored as a JSON array, by offset
+type UploadParts []UploadPart
+
+// Value implements driver.Valuer
+func (p UploadParts) Value() (driver.Value, error) {
+	if p == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal([]UploadPart(p))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (p *UploadParts) Scan(value interface{}) error {
+	return scanJSON(value, p)
+}
+
+// Domain errors for upload sessions
+var (
+	ErrUploadSessionNotFound  = NewDomainError("upload session not found")
+	ErrUploadSessionExpired   = NewDomainError("upload session expired")
+	ErrUploadOffsetMismatch   = NewDomainError("chunk offset does not match the upload offset")
+	ErrUploadChunkTooLarge    = NewDomainError("chunk exceeds the maximum chunk size")
+	ErrUploadChunkOverflow    = NewDomainError("chunk exceeds the declared file size")
+	ErrUploadIncomplete       = NewDomainError("upload did not receive the declared file size")
+	ErrUploadChecksumInvalid  = NewDomainError("checksum must be a hex SHA-256")
+	ErrUploadChecksumMismatch = NewDomainError("uploaded content does not match the checksum")
+)
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// Maximum lengths of user fields
+const (
+	MaxUserEmailLength    = 254
+	MaxUserUsernameLength = 100
+)
+
+// User is a user who signed up. Their email address, in lowercase, is their
+// user ID. InvitationID is the invitation they signed up with, 0 for open signup.
+// VerificationNonce is the random part of the signed token mailed to users
+// signing up openly; it is cleared once they verify their email address.
+type User struct {
+	BaseModel
+	Email             string     `gorm:"type:varchar(254);not null;uniqueIndex" json:"email"`
+	Username          string     `gorm:"type:varchar(100)" json:"username"`
+	Role              string     `gorm:"type:varchar(50);not null" json:"role"`
+	Permissions       StringList `gorm:"type:text" json:"permissions"`
+	PasswordHash      string     `gorm:"type:varchar(100);not null" json:"-"` // bcrypt
+	InvitationID      uint       `gorm:"not null;default:0" json:"invitation_id"`
+	VerificationNonce string     `gorm:"type:varchar(64)" json:"-"`
+}
+
+// TableName returns the table name for the User model
+func (u *User) TableName() string {
+	return "users"
+}
+
+// ShortTableName returns abbreviated table name
+func (u *User) ShortTableName() string {
+	return "usr"
+}
+
+// Index returns indexable fields for the User model
+func (u *User) Index() map[string]interface{} {
+	index := u.BaseModel.Index()
+	index["email"] = u.Email
+	return index
+}
+
+// Validate performs business rule validation on the User model
+func (u *User) Validate() error {
+	if len(u.Email) > MaxUserEmailLength {
+		return ErrUserEmailInvalid
+	}
+	if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+		return ErrUserEmailInvalid
+	}
+	if len(u.Username) > MaxUserUsernameLength {
+		return ErrUserUsernameTooLong
+	}
+	return nil
+}
+
+// Verified reports whether the user owns their email address: they signed up
+// with an invitation mailed to it, or verified it
+func (u *User) Verified() bool {
+	return u.VerificationNonce == ""
+}
+
+// UserID returns the ID of the user in access tokens and memberships
+func (u *User) UserID() string {
+	return u.Email
+}
+
+// EmailDomain returns the domain of an email address in lowercase
+func EmailDomain(email string) string {
+	if i := strings.LastIndex(email, "@"); i >= 0 {
+		return strings.ToLower(email[i+1:])
+	}
+	return ""
+}
+
+// Domain errors for users and their registration
+var (
+	ErrUserEmailInvalid                 = NewDomainError("user email must be a valid email address")
+	ErrUserUsernameTooLong              = NewDomainError("username must be at most 100 characters")
+	ErrUserExists                       = NewDomainError("a user with this email already exists")
+	ErrUserPasswordTooShort             = NewDomainError("password is too short")
+	ErrUserCredentialsInvalid           = NewDomainError("email or password is incorrect")
+	ErrUserNotFound                     = NewDomainError("user not found")
+	ErrUserEmailUnverified              = NewDomainError("email address is not verified")
+	ErrRegistrationInvitationRequired   = NewDomainError("signup requires an invitation")
+	ErrRegistrationDomainNotAllowed     = NewDomainError("signup is not open to this email domain")
+	ErrRegistrationInvitationMismatched = NewDomainError("email does not match the invitation")
+	ErrRegistrationTokenInvalid         = NewDomainError("email verification token is invalid")
+	ErrRegistrationTokenExpired         = NewDomainError("email verification token has expired")
+)
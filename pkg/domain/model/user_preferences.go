@@ -0,0 +1,87 @@
+package model
+
+import (
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+)
+
+// UserPreferences holds the settings a user chose. Empty fields fall back to
+// the configured defaults.
+type UserPreferences struct {
+	BaseModel
+	UserID string `gorm:"type:varchar(100);not null;uniqueIndex" json:"user_id"`
+	// Language is one of i18n.LanguageMap
+	Language string `gorm:"type:varchar(10)" json:"language"`
+	// TimeZone is an IANA time zone name, e.g. Asia/Shanghai
+	TimeZone string `gorm:"type:varchar(64)" json:"time_zone"`
+	// NotificationChannels restricts the channels notifications are sent on,
+	// empty for every enabled channel
+	NotificationChannels StringList `gorm:"type:text" json:"notification_channels"`
+}
+
+// TableName returns the table name for the UserPreferences model
+func (p *UserPreferences) TableName() string {
+	return "user_preferences"
+}
+
+// ShortTableName returns abbreviated table name
+func (p *UserPreferences) ShortTableName() string {
+	return "up"
+}
+
+// Index returns indexable fields for the UserPreferences model
+func (p *UserPreferences) Index() map[string]interface{} {
+	index := p.BaseModel.Index()
+	index["user_id"] = p.UserID
+	return index
+}
+
+// Validate performs business rule validation on the UserPreferences model
+func (p *UserPreferences) Validate() error {
+	if p.UserID == "" {
+		return ErrPreferencesUserRequired
+	}
+	if _, ok := i18n.LanguageMap[p.Language]; p.Language != "" && !ok {
+		return ErrPreferencesLanguageInvalid
+	}
+	if p.TimeZone != "" {
+		if _, err := time.LoadLocation(p.TimeZone); err != nil {
+			return ErrPreferencesTimeZoneInvalid
+		}
+	}
+	for _, channel := range p.NotificationChannels {
+		if !IsNotificationChannel(channel) {
+			return ErrNotificationChannelInvalid
+		}
+	}
+	return nil
+}
+
+// UserPreferencesPatch is a partial update of the preferences of a user. Nil
+// fields are left unchanged; an empty value restores the default.
+type UserPreferencesPatch struct {
+	Language             *string
+	TimeZone             *string
+	NotificationChannels *StringList
+}
+
+// ApplyTo sets the fields present in the patch on prefs
+func (p *UserPreferencesPatch) ApplyTo(prefs *UserPreferences) {
+	if p.Language != nil {
+		prefs.Language = *p.Language
+	}
+	if p.TimeZone != nil {
+		prefs.TimeZone = *p.TimeZone
+	}
+	if p.NotificationChannels != nil {
+		prefs.NotificationChannels = append(StringList{}, *p.NotificationChannels...)
+	}
+}
+
+// Domain errors for UserPreferences
+var (
+	ErrPreferencesUserRequired    = NewDomainError("preferences user is required")
+	ErrPreferencesLanguageInvalid = NewDomainError("language is not supported")
+	ErrPreferencesTimeZoneInvalid = NewDomainError("time zone must be an IANA time zone name")
+)
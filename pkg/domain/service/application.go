@@ -2,26 +2,43 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/make-bin/server-tpl/pkg/domain/event"
 	"github.com/make-bin/server-tpl/pkg/domain/model"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
-// ApplicationService implements ApplicationServiceInterface
+// EventTypeApplicationDeleted is published after an application deletion is committed
+const EventTypeApplicationDeleted = "application.deleted"
+
+// ApplicationDeleted is the payload of an application deleted event
+type ApplicationDeleted struct {
+	ID uint `json:"id"`
+}
+
+// ApplicationService implements ApplicationServiceInterface with an explicitly wired datastore
 type ApplicationService struct {
-	datastore datastore.DatastoreInterface
+	applicationService
 }
 
 // applicationService 内部实现，支持依赖注入
 type applicationService struct {
-	Store datastore.DatastoreInterface `inject:"datastore"`
+	Store         datastore.DatastoreInterface  `inject:"datastore"`
+	UnitOfWork    datastore.UnitOfWorkManager   `inject:"unit_of_work"`
+	Config        *config.Config                `inject:"config"`
+	Authorization AuthorizationServiceInterface `inject:""`
 }
 
 // NewApplicationService creates a new ApplicationService instance
 func NewApplicationService(ds datastore.DatastoreInterface) ApplicationServiceInterface {
 	return &ApplicationService{
-		datastore: ds,
+		applicationService: applicationService{
+			Store:      ds,
+			UnitOfWork: datastore.NewUnitOfWorkManager(ds, nil),
+		},
 	}
 }
 
@@ -30,28 +47,65 @@ func NewApplicationServiceForDI() ApplicationServiceInterface {
 	return &applicationService{}
 }
 
-// CreateApplication creates a new application
-func (s *ApplicationService) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+// repository returns the application repository backed by the injected datastore,
+// or by the transaction of the unit of work carried by ctx
+func (s *applicationService) repository(ctx context.Context) (datastore.Repository[*model.Application], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.Application](uow.Store())
+	}
+	return datastore.NewRepository[*model.Application](s.Store)
+}
+
+// CreateApplication creates a new application, owned by the user and the active organization of ctx
+func (s *applicationService) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
 	logger.Info("Creating application: %s", app.Name)
 
-	// Validate domain rules
-	if err := app.Validate(); err != nil {
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		app.OrgID = orgID
+	}
+	if app.OwnerID == "" {
+		app.OwnerID = actorFromContext(ctx)
+	}
+	if err := s.authorize(ctx, model.ActionCreate, app); err != nil {
 		return nil, err
 	}
 
-	// Check if application with same name exists
-	existing, err := s.datastore.GetApplicationByName(ctx, app.Name)
-	if err != nil && err != datastore.ErrNotFound {
+	// Validate domain rules
+	if err := normalizeApplication(app); err != nil {
 		return nil, err
 	}
-	if existing != nil {
-		return nil, model.NewDomainError("application with this name already exists")
-	}
 
-	// Create application
-	result, err := s.datastore.CreateApplication(ctx, app)
+	var result *model.Application
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.repository(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Check if application with same name exists
+		existing, err := s.findByName(ctx, repo, app.OrgID, app.Name)
+		if err != nil && err != datastore.ErrNotFound {
+			return err
+		}
+		if existing != nil {
+			return model.ErrApplicationNameExists
+		}
+
+		// Create application
+		result, err = repo.Create(ctx, app)
+		if err != nil {
+			if err == datastore.ErrDuplicateKey {
+				return model.ErrApplicationNameExists
+			}
+			return err
+		}
+
+		return s.recordRevision(ctx, result, nil, model.RevisionActionCreate, 0)
+	})
 	if err != nil {
-		logger.Error("Failed to create application: %v", err)
+		if err != model.ErrApplicationNameExists {
+			logger.Error("Failed to create application: %v", err)
+		}
 		return nil, err
 	}
 
@@ -60,10 +114,15 @@ func (s *ApplicationService) CreateApplication(ctx context.Context, app *model.A
 }
 
 // GetApplicationByID retrieves an application by ID
-func (s *ApplicationService) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
+func (s *applicationService) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
 	logger.Info("Getting application by ID: %d", id)
 
-	app, err := s.datastore.GetApplicationByID(ctx, id)
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	app, err := repo.Get(ctx, id)
 	if err != nil {
 		if err == datastore.ErrNotFound {
 			return nil, model.ErrApplicationNotFound
@@ -71,15 +130,44 @@ func (s *ApplicationService) GetApplicationByID(ctx context.Context, id uint) (*
 		logger.Error("Failed to get application by ID: %v", err)
 		return nil, err
 	}
+	if !inOrganization(ctx, s.Config, app) {
+		return nil, model.ErrApplicationNotFound
+	}
+	if err := s.authorize(ctx, model.ActionRead, app); err != nil {
+		return nil, err
+	}
 
 	return app, nil
 }
 
-// GetApplicationByName retrieves an application by name
-func (s *ApplicationService) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+// ResolveApplicationID returns the ID of the application with a public ID
+func (s *applicationService) ResolveApplicationID(ctx context.Context, publicID string) (uint, error) {
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return 0, err
+	}
+	app, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrApplicationNotFound
+		}
+		return 0, err
+	}
+	return app.ID, nil
+}
+
+// GetApplicationByName retrieves an application by name, among the applications
+// of the active organization of ctx or else those without an organization
+func (s *applicationService) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
 	logger.Info("Getting application by name: %s", name)
 
-	app, err := s.datastore.GetApplicationByName(ctx, name)
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orgID, _ := model.OrganizationFromContext(ctx)
+	app, err := s.findByName(ctx, repo, orgID, name)
 	if err != nil {
 		if err == datastore.ErrNotFound {
 			return nil, model.ErrApplicationNotFound
@@ -92,10 +180,49 @@ func (s *ApplicationService) GetApplicationByName(ctx context.Context, name stri
 }
 
 // ListApplications retrieves a paginated list of applications
-func (s *ApplicationService) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
-	logger.Info("Listing applications: page=%d, pageSize=%d", page, pageSize)
+func (s *applicationService) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
+	return s.ListApplicationsByTags(ctx, nil, page, pageSize)
+}
+
+// ListApplicationsByTags retrieves a paginated list of the applications matching the tag selector
+func (s *applicationService) ListApplicationsByTags(ctx context.Context, selector model.TagSelector, page, pageSize int) ([]*model.Application, int64, error) {
+	return s.QueryApplications(ctx, model.ApplicationQuery{Page: page, Size: pageSize, Tags: selector})
+}
+
+// QueryApplications retrieves a paginated list of the applications matching the query
+func (s *applicationService) QueryApplications(ctx context.Context, query model.ApplicationQuery) ([]*model.Application, int64, error) {
+	logger.Info("Listing applications: page=%d, pageSize=%d, tags=%v, ids=%v, sort=%v", query.Page, query.Size, query.Tags, query.IDs, query.Sort)
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
 
-	apps, total, err := s.datastore.ListApplications(ctx, page, pageSize)
+	filters, ok := organizationFilters(ctx, s.Config)
+	if !ok {
+		return []*model.Application{}, 0, nil
+	}
+	if len(query.IDs) > 0 {
+		if filters == nil {
+			filters = make(map[string]interface{})
+		}
+		filters["id"] = query.IDs
+	}
+	ranges := make(map[string]datastore.Range)
+	addTimeRange(ranges, "created_at", query.CreatedAt)
+	addTimeRange(ranges, "updated_at", query.UpdatedAt)
+	sort := make([]datastore.SortOrder, len(query.Sort))
+	for i, order := range query.Sort {
+		sort[i] = datastore.SortOrder{Column: order.Field, Desc: order.Desc}
+	}
+
+	total, err := repo.Count(ctx, datastore.ListOptions{Tags: query.Tags, Filters: filters, Ranges: ranges})
+	if err != nil {
+		logger.Error("Failed to count applications: %v", err)
+		return nil, 0, err
+	}
+
+	apps, err := repo.List(ctx, datastore.ListOptions{Page: query.Page, Size: query.Size, Tags: query.Tags, Filters: filters, Ranges: ranges, Sort: sort})
 	if err != nil {
 		logger.Error("Failed to list applications: %v", err)
 		return nil, 0, err
@@ -104,39 +231,42 @@ func (s *ApplicationService) ListApplications(ctx context.Context, page, pageSiz
 	return apps, total, nil
 }
 
-// UpdateApplication updates an existing application
-func (s *ApplicationService) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
-	logger.Info("Updating application: %d", app.ID)
-
-	// Validate domain rules
-	if err := app.Validate(); err != nil {
+// ExistingApplicationNames returns the names already used by applications of the organization of ctx,
+// with the uniqueness scope of CreateApplication
+func (s *applicationService) ExistingApplicationNames(ctx context.Context, names []string) ([]string, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	repo, err := s.repository(ctx)
+	if err != nil {
 		return nil, err
 	}
 
-	// Check if application exists
-	existing, err := s.datastore.GetApplicationByID(ctx, app.ID)
+	orgID, _ := model.OrganizationFromContext(ctx)
+	apps, err := repo.List(ctx, datastore.ListOptions{
+		Filters: map[string]interface{}{"org_id": orgID, "name": names},
+	})
 	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrApplicationNotFound
-		}
+		logger.Error("Failed to look up application names: %v", err)
 		return nil, err
 	}
 
-	// Check if another application with same name exists
-	if existing.Name != app.Name {
-		nameExists, err := s.datastore.GetApplicationByName(ctx, app.Name)
-		if err != nil && err != datastore.ErrNotFound {
-			return nil, err
-		}
-		if nameExists != nil {
-			return nil, model.NewDomainError("application with this name already exists")
-		}
+	existing := make([]string, len(apps))
+	for i, app := range apps {
+		existing[i] = app.Name
 	}
+	return existing, nil
+}
+
+// UpdateApplication updates an existing application and records the change as a revision
+func (s *applicationService) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	logger.Info("Updating application: %d", app.ID)
 
-	// Update application
-	result, err := s.datastore.UpdateApplication(ctx, app)
+	result, err := s.update(ctx, app.ID, model.RevisionActionUpdate, 0, func(current *model.Application) error {
+		*current = *app
+		return nil
+	})
 	if err != nil {
-		logger.Error("Failed to update application: %v", err)
 		return nil, err
 	}
 
@@ -144,23 +274,134 @@ func (s *ApplicationService) UpdateApplication(ctx context.Context, app *model.A
 	return result, nil
 }
 
-// DeleteApplication deletes an application by ID
-func (s *ApplicationService) DeleteApplication(ctx context.Context, id uint) error {
-	logger.Info("Deleting application: %d", id)
+// PatchApplication applies a partial update to an application and records the change as a revision
+func (s *applicationService) PatchApplication(ctx context.Context, id uint, patch *model.ApplicationPatch) (*model.Application, error) {
+	logger.Info("Patching application: %d", id)
 
-	// Check if application exists
-	_, err := s.datastore.GetApplicationByID(ctx, id)
+	result, err := s.update(ctx, id, model.RevisionActionUpdate, 0, func(current *model.Application) error {
+		patch.ApplyTo(current)
+		return nil
+	})
 	if err != nil {
-		if err == datastore.ErrNotFound {
+		return nil, err
+	}
+
+	logger.Info("Application patched successfully: %d", result.ID)
+	return result, nil
+}
+
+// AddApplicationTags adds tags to an application, keeping its existing tags
+func (s *applicationService) AddApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error) {
+	logger.Info("Adding tags to application %d: %v", id, tags)
+
+	return s.updateTags(ctx, id, func(current model.StringList) (model.StringList, error) {
+		return model.AddTags(current, tags)
+	})
+}
+
+// RemoveApplicationTags removes tags from an application. Tags it does not carry are ignored.
+func (s *applicationService) RemoveApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error) {
+	logger.Info("Removing tags from application %d: %v", id, tags)
+
+	return s.updateTags(ctx, id, func(current model.StringList) (model.StringList, error) {
+		return model.RemoveTags(current, tags)
+	})
+}
+
+// updateTags replaces the tags of an application with the result of change
+func (s *applicationService) updateTags(ctx context.Context, id uint, change func(model.StringList) (model.StringList, error)) (*model.Application, error) {
+	return s.update(ctx, id, model.RevisionActionUpdate, 0, func(app *model.Application) error {
+		tags, err := change(app.Tags)
+		if err != nil {
+			return err
+		}
+		app.Tags = tags
+		return nil
+	})
+}
+
+// update applies change to an application in a unit of work, validates and
+// stores the result and records a revision of the changed fields
+func (s *applicationService) update(ctx context.Context, id uint, action string, restoredFrom int, change func(*model.Application) error) (*model.Application, error) {
+	var result *model.Application
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.repository(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Check if application exists
+		app, err := repo.Get(ctx, id)
+		if err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrApplicationNotFound
+			}
+			return err
+		}
+		if !inOrganization(ctx, s.Config, app) {
 			return model.ErrApplicationNotFound
 		}
-		return err
+		if err := s.authorize(ctx, model.ActionUpdate, app); err != nil {
+			return err
+		}
+		previous := model.SnapshotOf(app)
+		orgID, ownerID, publicID := app.OrgID, app.OwnerID, app.PublicID
+
+		if err := change(app); err != nil {
+			return err
+		}
+		app.ID, app.OrgID, app.OwnerID, app.PublicID = id, orgID, ownerID, publicID
+
+		// Validate domain rules
+		if err := normalizeApplication(app); err != nil {
+			return err
+		}
+
+		// Check if another application with same name exists
+		if app.Name != previous.Name {
+			nameExists, err := s.findByName(ctx, repo, app.OrgID, app.Name)
+			if err != nil && err != datastore.ErrNotFound {
+				return err
+			}
+			if nameExists != nil {
+				return model.ErrApplicationNameExists
+			}
+		}
+
+		// Update application
+		result, err = repo.Update(ctx, app)
+		if err != nil {
+			switch err {
+			case datastore.ErrNotFound:
+				return model.ErrApplicationNotFound
+			case datastore.ErrDuplicateKey:
+				return model.ErrApplicationNameExists
+			}
+			return err
+		}
+
+		return s.recordRevision(ctx, result, &previous, action, restoredFrom)
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to update application: %v", err)
+		}
+		return nil, err
 	}
+	return result, nil
+}
 
-	// Delete application
-	err = s.datastore.DeleteApplication(ctx, id)
+// DeleteApplication deletes an application by ID
+func (s *applicationService) DeleteApplication(ctx context.Context, id uint) error {
+	logger.Info("Deleting application: %d", id)
+
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		return s.deleteApplication(ctx, uow, id)
+	})
 	if err != nil {
-		logger.Error("Failed to delete application: %v", err)
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to delete application: %v", err)
+		}
 		return err
 	}
 
@@ -168,142 +409,259 @@ func (s *ApplicationService) DeleteApplication(ctx context.Context, id uint) err
 	return nil
 }
 
-// 为依赖注入版本实现相同的方法
+// BatchDeleteOptions controls a batch delete of applications
+type BatchDeleteOptions struct {
+	// ContinueOnError deletes the applications that can be deleted when others
+	// of the batch cannot, which otherwise leave every application in place
+	ContinueOnError bool
+	// Permanent removes the applications and their dependents instead of soft
+	// deleting them, see datastore.DeleteOptions
+	Permanent bool
+}
 
-// CreateApplication creates a new application (DI version)
-func (s *applicationService) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
-	logger.Info("Creating application: %s", app.Name)
+// BatchDeleteFailure reports why the application with ID was not deleted
+type BatchDeleteFailure struct {
+	ID  uint
+	Err error
+}
 
-	// Validate domain rules
-	if err := app.Validate(); err != nil {
-		return nil, err
-	}
+// BatchDeleteApplications deletes applications in one unit of work with a single
+// statement per table. The applications are loaded and checked first, every
+// one that is missing, outside the organization of ctx or not deletable by the
+// subject of ctx is reported. Without opts.ContinueOnError a failure leaves every
+// application in place and the others are reported with
+// model.ErrApplicationBatchAborted. Applications deleted concurrently roll the
+// batch back with model.ErrApplicationBatchConflict.
+func (s *applicationService) BatchDeleteApplications(ctx context.Context, ids []uint, opts BatchDeleteOptions) ([]BatchDeleteFailure, error) {
+	logger.Info("Batch deleting applications: %v, continue_on_error=%t, permanent=%t", ids, opts.ContinueOnError, opts.Permanent)
+
+	var failures []BatchDeleteFailure
+	var deleted []uint
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		failures, deleted = nil, nil
+		repo, err := s.repository(ctx)
+		if err != nil {
+			return err
+		}
 
-	// Check if application with same name exists
-	existing, err := s.Store.GetApplicationByName(ctx, app.Name)
-	if err != nil && err != datastore.ErrNotFound {
-		return nil, err
-	}
-	if existing != nil {
-		return nil, model.NewDomainError("application with this name already exists")
-	}
+		apps, err := repo.List(ctx, datastore.ListOptions{Filters: map[string]interface{}{"id": ids}})
+		if err != nil {
+			return err
+		}
+		found := make(map[uint]*model.Application, len(apps))
+		for _, app := range apps {
+			found[app.ID] = app
+		}
 
-	// Create application
-	result, err := s.Store.CreateApplication(ctx, app)
-	if err != nil {
-		logger.Error("Failed to create application: %v", err)
-		return nil, err
-	}
+		seen := make(map[uint]bool, len(ids))
+		for _, id := range ids {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			app, ok := found[id]
+			if !ok || !inOrganization(ctx, s.Config, app) {
+				failures = append(failures, BatchDeleteFailure{ID: id, Err: model.ErrApplicationNotFound})
+				continue
+			}
+			if err := s.authorize(ctx, model.ActionDelete, app); err != nil {
+				if !errors.Is(err, model.ErrAccessDenied) {
+					return err
+				}
+				failures = append(failures, BatchDeleteFailure{ID: id, Err: err})
+				continue
+			}
+			deleted = append(deleted, id)
+		}
 
-	logger.Info("Application created successfully: %d", result.ID)
-	return result, nil
-}
+		if len(failures) > 0 && !opts.ContinueOnError {
+			for _, id := range deleted {
+				failures = append(failures, BatchDeleteFailure{ID: id, Err: model.ErrApplicationBatchAborted})
+			}
+			deleted = nil
+			return nil
+		}
+		if len(deleted) == 0 {
+			return nil
+		}
 
-// GetApplicationByID retrieves an application by ID (DI version)
-func (s *applicationService) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
-	logger.Info("Getting application by ID: %d", id)
+		removed, err := repo.DeleteMatching(ctx, datastore.DeleteOptions{Filters: map[string]interface{}{"id": deleted}, Permanent: opts.Permanent})
+		if err != nil {
+			return err
+		}
+		if removed != int64(len(deleted)) {
+			return model.ErrApplicationBatchConflict
+		}
+		if err := s.deleteDependentsOf(ctx, deleted, opts.Permanent); err != nil {
+			return err
+		}
 
-	app, err := s.Store.GetApplicationByID(ctx, id)
+		for _, id := range deleted {
+			uow.Publish(event.NewEvent(EventTypeApplicationDeleted, ApplicationDeleted{ID: id}))
+		}
+		return nil
+	})
 	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrApplicationNotFound
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to batch delete applications: %v", err)
 		}
-		logger.Error("Failed to get application by ID: %v", err)
 		return nil, err
 	}
 
-	return app, nil
+	logger.Info("Applications deleted successfully: %d, failed: %d", len(deleted), len(failures))
+	return failures, nil
 }
 
-// GetApplicationByName retrieves an application by name (DI version)
-func (s *applicationService) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
-	logger.Info("Getting application by name: %s", name)
+// deleteApplication deletes an application within the unit of work and records the event
+func (s *applicationService) deleteApplication(ctx context.Context, uow datastore.UnitOfWork, id uint) error {
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return err
+	}
 
-	app, err := s.Store.GetApplicationByName(ctx, name)
+	app, err := repo.Get(ctx, id)
+	if err == datastore.ErrNotFound || (err == nil && !inOrganization(ctx, s.Config, app)) {
+		return model.ErrApplicationNotFound
+	}
 	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, model.ActionDelete, app); err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, id); err != nil {
 		if err == datastore.ErrNotFound {
-			return nil, model.ErrApplicationNotFound
+			return model.ErrApplicationNotFound
 		}
-		logger.Error("Failed to get application by name: %v", err)
-		return nil, err
+		return err
+	}
+	if err := s.deleteDependents(ctx, id); err != nil {
+		return err
 	}
 
-	return app, nil
+	uow.Publish(event.NewEvent(EventTypeApplicationDeleted, ApplicationDeleted{ID: id}))
+	return nil
 }
 
-// ListApplications retrieves a paginated list of applications (DI version)
-func (s *applicationService) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
-	logger.Info("Listing applications: page=%d, pageSize=%d", page, pageSize)
+// deleteDependents deletes the variables and revisions of a deleted application
+func (s *applicationService) deleteDependents(ctx context.Context, id uint) error {
+	return s.deleteDependentsOf(ctx, []uint{id}, false)
+}
+
+// deleteDependentsOf deletes the variables and revisions of deleted applications
+// with a single statement per table
+func (s *applicationService) deleteDependentsOf(ctx context.Context, ids []uint, permanent bool) error {
+	opts := datastore.DeleteOptions{Filters: map[string]interface{}{"app_id": ids}, Permanent: permanent}
 
-	apps, total, err := s.Store.ListApplications(ctx, page, pageSize)
+	variables, err := variableRepository(ctx, s.Store)
 	if err != nil {
-		logger.Error("Failed to list applications: %v", err)
-		return nil, 0, err
+		return err
+	}
+	if _, err := variables.DeleteMatching(ctx, opts); err != nil {
+		return err
 	}
 
-	return apps, total, nil
+	revisions, err := revisionRepository(ctx, s.Store)
+	if err != nil {
+		return err
+	}
+	_, err = revisions.DeleteMatching(ctx, opts)
+	return err
 }
 
-// UpdateApplication updates an existing application (DI version)
-func (s *applicationService) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
-	logger.Info("Updating application: %d", app.ID)
-
-	// Validate domain rules
-	if err := app.Validate(); err != nil {
-		return nil, err
+// deleteAll deletes every entity matching filters
+func deleteAll[T model.Entity](ctx context.Context, repo datastore.Repository[T], filters map[string]interface{}) error {
+	entities, err := repo.List(ctx, datastore.ListOptions{Filters: filters})
+	if err != nil {
+		return err
 	}
+	for _, entity := range entities {
+		if err := repo.Delete(ctx, entity.GetID()); err != nil && err != datastore.ErrNotFound {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Check if application exists
-	existing, err := s.Store.GetApplicationByID(ctx, app.ID)
+// normalizeApplication normalizes the tags of app and validates its domain rules
+func normalizeApplication(app *model.Application) error {
+	tags, err := model.NormalizeTags(app.Tags)
 	if err != nil {
-		if err == datastore.ErrNotFound {
-			return nil, model.ErrApplicationNotFound
-		}
-		return nil, err
+		return err
 	}
+	app.Tags = tags
+	return app.Validate()
+}
 
-	// Check if another application with same name exists
-	if existing.Name != app.Name {
-		nameExists, err := s.Store.GetApplicationByName(ctx, app.Name)
-		if err != nil && err != datastore.ErrNotFound {
-			return nil, err
-		}
-		if nameExists != nil {
-			return nil, model.NewDomainError("application with this name already exists")
-		}
+// inOrganization reports whether app belongs to the active organization of ctx.
+// Every application is in scope of contexts without one, except users acting
+// without an organization while organizations are enabled, see unscopedUser.
+func inOrganization(ctx context.Context, cfg *config.Config, app *model.Application) bool {
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		return app.OrgID == orgID
 	}
+	return !unscopedUser(ctx, cfg)
+}
 
-	// Update application
-	result, err := s.Store.UpdateApplication(ctx, app)
-	if err != nil {
-		logger.Error("Failed to update application: %v", err)
-		return nil, err
+// authorize returns model.ErrAccessDenied unless the subject of ctx may perform
+// action on app. Services wired without an authorization service do not check.
+func (s *applicationService) authorize(ctx context.Context, action string, app *model.Application) error {
+	if s.Authorization == nil {
+		return nil
 	}
+	return s.Authorization.Authorize(ctx, action, app.AuthorizationResource())
+}
 
-	logger.Info("Application updated successfully: %d", result.ID)
-	return result, nil
+// organizationFilters returns the filters restricting applications to the active
+// organization of ctx, nil for contexts without one. It returns false when no
+// application is in scope of ctx, see unscopedUser.
+func organizationFilters(ctx context.Context, cfg *config.Config) (map[string]interface{}, bool) {
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		return map[string]interface{}{"org_id": orgID}, true
+	}
+	return nil, !unscopedUser(ctx, cfg)
 }
 
-// DeleteApplication deletes an application by ID (DI version)
-func (s *applicationService) DeleteApplication(ctx context.Context, id uint) error {
-	logger.Info("Deleting application: %d", id)
+// unscopedUser reports whether ctx carries a user but no active organization
+// while organizations are enabled. Such a request reached the service through
+// a route without an OrgRole policy; it is denied rather than given every
+// organization. Contexts without a subject, such as startup tasks, act on
+// behalf of the system and stay unscoped.
+func unscopedUser(ctx context.Context, cfg *config.Config) bool {
+	if cfg == nil || !cfg.Organizations.Enabled {
+		return false
+	}
+	_, ok := model.SubjectFromContext(ctx)
+	return ok
+}
 
-	// Check if application exists
-	_, err := s.Store.GetApplicationByID(ctx, id)
-	if err != nil {
-		if err == datastore.ErrNotFound {
-			return model.ErrApplicationNotFound
-		}
-		return err
+// addTimeRange adds the range bounding column to ranges unless it is open on both sides
+func addTimeRange(ranges map[string]datastore.Range, column string, r model.TimeRange) {
+	if r.IsZero() {
+		return
+	}
+	var bounds datastore.Range
+	if !r.From.IsZero() {
+		bounds.From = r.From
+	}
+	if !r.To.IsZero() {
+		bounds.To = r.To
 	}
+	ranges[column] = bounds
+}
 
-	// Delete application
-	err = s.Store.DeleteApplication(ctx, id)
+// findByName returns the application of an organization with the given name or datastore.ErrNotFound
+func (s *applicationService) findByName(ctx context.Context, repo datastore.Repository[*model.Application], orgID uint, name string) (*model.Application, error) {
+	apps, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"org_id": orgID, "name": name},
+	})
 	if err != nil {
-		logger.Error("Failed to delete application: %v", err)
-		return err
+		return nil, err
 	}
-
-	logger.Info("Application deleted successfully: %d", id)
-	return nil
+	if len(apps) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return apps[0], nil
 }
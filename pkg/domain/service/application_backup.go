@@ -0,0 +1,504 @@
+package service
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/storage"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// backupArchiveVersion is the version of the backup archive layout
+const backupArchiveVersion = 1
+
+// Entries of a backup archive
+const (
+	backupManifestEntry    = "manifest.json"
+	backupApplicationEntry = "application.json"
+	backupVariablesEntry   = "variables.json"
+	backupRevisionsEntry   = "revisions.json"
+)
+
+// ApplicationBackupServiceInterface defines the interface for application backup service.
+// Backups run asynchronously as operations: CreateBackup returns a pending backup whose status is polled with GetBackup.
+type ApplicationBackupServiceInterface interface {
+	CreateBackup(ctx context.Context, backup *model.ApplicationBackup) (*model.ApplicationBackup, error)
+	GetBackup(ctx context.Context, backupID string) (*model.ApplicationBackup, error)
+	// ListBackups lists the backups of an application, newest first, including those of a deleted application
+	ListBackups(ctx context.Context, appID uint, page, pageSize int) ([]*model.ApplicationBackup, int64, error)
+	// RestoreBackup recreates the application of a completed backup, under name when it is not empty
+	RestoreBackup(ctx context.Context, backupID, name string) (*model.Application, error)
+}
+
+// applicationBackupService 内部实现，支持依赖注入
+type applicationBackupService struct {
+	Store         datastore.DatastoreInterface  `inject:"datastore"`
+	UnitOfWork    datastore.UnitOfWorkManager   `inject:"unit_of_work"`
+	Config        *config.Config                `inject:"config"`
+	Storage       storage.Storage               `inject:"storage"`
+	Operations    OperationServiceInterface     `inject:""`
+	Authorization AuthorizationServiceInterface `inject:""`
+}
+
+// NewApplicationBackupServiceForDI 创建支持依赖注入的应用备份服务实例
+func NewApplicationBackupServiceForDI() ApplicationBackupServiceInterface {
+	return &applicationBackupService{}
+}
+
+// backupManifest describes the content of a backup archive
+type backupManifest struct {
+	Version     int       `json:"version"`
+	BackupID    string    `json:"backup_id"`
+	AppID       uint      `json:"app_id"`
+	Name        string    `json:"name"`
+	IncludeData bool      `json:"include_data"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// archiveEntry is a JSON document written to a backup archive
+type archiveEntry struct {
+	name  string
+	value interface{}
+}
+
+// backupResult is the result of a completed backup operation
+type backupResult struct {
+	BackupID string `json:"backup_id"`
+	Size     int64  `json:"size"`
+}
+
+// backupContents holds the entities read from a backup archive
+type backupContents struct {
+	Manifest    backupManifest
+	Application *model.Application
+	Variables   []*model.ApplicationVariable
+	Revisions   []*model.ApplicationRevision
+}
+
+// OnStart marks the backups left unfinished by a previous process as failed
+func (s *applicationBackupService) OnStart(ctx context.Context) error {
+	repo, err := s.backups()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range []string{model.BackupStatusPending, model.BackupStatusRunning} {
+		backups, err := repo.List(ctx, datastore.ListOptions{Filters: map[string]interface{}{"status": status}})
+		if err != nil {
+			return err
+		}
+		for _, backup := range backups {
+			logger.Warn("Backup %s of application %d was interrupted", backup.BackupID, backup.AppID)
+			s.finish(ctx, repo, backup, 0, errors.New("interrupted by server shutdown"))
+		}
+	}
+	return nil
+}
+
+// backups returns the backup repository. Backup jobs are tracked outside of
+// units of work so that their status is visible to the job goroutine and to
+// pollers immediately.
+func (s *applicationBackupService) backups() (datastore.Repository[*model.ApplicationBackup], error) {
+	return datastore.NewRepository[*model.ApplicationBackup](s.Store)
+}
+
+// applications returns the application service sharing the dependencies of s
+func (s *applicationBackupService) applications() *applicationService {
+	return &applicationService{Store: s.Store, UnitOfWork: s.UnitOfWork, Config: s.Config, Authorization: s.Authorization}
+}
+
+// CreateBackup records a pending backup of an application and starts the backup job
+func (s *applicationBackupService) CreateBackup(ctx context.Context, backup *model.ApplicationBackup) (*model.ApplicationBackup, error) {
+	logger.Info("Creating backup %s of application %d", backup.Name, backup.AppID)
+
+	app, err := s.applications().GetApplicationByID(ctx, backup.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := s.backups()
+	if err != nil {
+		return nil, err
+	}
+
+	backup.OrgID = app.OrgID
+	backup.BackupID = uuid.NewString()
+	backup.OperationID = uuid.NewString()
+	backup.Status = model.BackupStatusPending
+	backup.StorageKey = fmt.Sprintf("backups/applications/%d/%s.tar", backup.AppID, backup.BackupID)
+	if backup.Compress {
+		backup.StorageKey += ".gz"
+	}
+
+	result, err := repo.Create(ctx, backup)
+	if err != nil {
+		logger.Error("Failed to create backup: %v", err)
+		return nil, err
+	}
+
+	// The job works on its own copy and outlives the request
+	job := *result
+	op := &model.Operation{OperationID: result.OperationID, Type: model.OperationTypeApplicationBackup}
+	if _, err := s.Operations.StartOperation(ctx, op, func(ctx context.Context, progress ProgressFunc) (interface{}, error) {
+		return s.run(ctx, repo, &job, progress)
+	}); err != nil {
+		s.finish(ctx, repo, result, 0, err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetBackup retrieves a backup by its backup ID, among the backups of the active organization of ctx
+func (s *applicationBackupService) GetBackup(ctx context.Context, backupID string) (*model.ApplicationBackup, error) {
+	repo, err := s.backups()
+	if err != nil {
+		return nil, err
+	}
+
+	backups, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"backup_id": backupID},
+	})
+	if err != nil {
+		logger.Error("Failed to get backup: %v", err)
+		return nil, err
+	}
+	if len(backups) == 0 {
+		return nil, model.ErrBackupNotFound
+	}
+	if orgID, ok := model.OrganizationFromContext(ctx); ok && backups[0].OrgID != orgID {
+		return nil, model.ErrBackupNotFound
+	}
+	return backups[0], nil
+}
+
+// ListBackups retrieves a paginated list of the backups of an application
+func (s *applicationBackupService) ListBackups(ctx context.Context, appID uint, page, pageSize int) ([]*model.ApplicationBackup, int64, error) {
+	logger.Info("Listing backups of application %d: page=%d, pageSize=%d", appID, page, pageSize)
+
+	if _, ok := model.OrganizationFromContext(ctx); ok {
+		if _, err := s.applications().GetApplicationByID(ctx, appID); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	repo, err := s.backups()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := map[string]interface{}{"app_id": appID}
+	total, err := repo.Count(ctx, datastore.ListOptions{Filters: filters})
+	if err != nil {
+		logger.Error("Failed to count backups: %v", err)
+		return nil, 0, err
+	}
+
+	backups, err := repo.List(ctx, datastore.ListOptions{
+		Page:     page,
+		Size:     pageSize,
+		SortBy:   "id",
+		SortDesc: true,
+		Filters:  filters,
+	})
+	if err != nil {
+		logger.Error("Failed to list backups: %v", err)
+		return nil, 0, err
+	}
+
+	return backups, total, nil
+}
+
+// RestoreBackup recreates an application with its variables and revisions from
+// a backup archive in one unit of work. The restored application gets a new ID.
+func (s *applicationBackupService) RestoreBackup(ctx context.Context, backupID, name string) (*model.Application, error) {
+	logger.Info("Restoring backup %s", backupID)
+
+	backup, err := s.GetBackup(ctx, backupID)
+	if err != nil {
+		return nil, err
+	}
+	if backup.Status != model.BackupStatusCompleted {
+		return nil, model.ErrBackupNotCompleted
+	}
+
+	contents, err := s.readArchive(ctx, backup)
+	if err != nil {
+		logger.Error("Failed to read backup %s: %v", backupID, err)
+		return nil, err
+	}
+
+	app := contents.Application
+	app.ID = 0
+	app.OrgID = backup.OrgID
+	if name != "" {
+		app.Name = name
+	}
+	// The restoring user owns the restored application
+	if actor := actorFromContext(ctx); actor != "" {
+		app.OwnerID = actor
+	}
+	if err := normalizeApplication(app); err != nil {
+		return nil, err
+	}
+
+	apps := s.applications()
+	if err := apps.authorize(ctx, model.ActionCreate, app); err != nil {
+		return nil, err
+	}
+	var result *model.Application
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := apps.repository(ctx)
+		if err != nil {
+			return err
+		}
+
+		existing, err := apps.findByName(ctx, repo, app.OrgID, app.Name)
+		if err != nil && err != datastore.ErrNotFound {
+			return err
+		}
+		if existing != nil {
+			return model.ErrApplicationNameExists
+		}
+
+		result, err = repo.Create(ctx, app)
+		if err != nil {
+			if err == datastore.ErrDuplicateKey {
+				return model.ErrApplicationNameExists
+			}
+			return err
+		}
+
+		// Variable values are restored as stored, secrets stay encrypted with the same key
+		variables, err := variableRepository(ctx, s.Store)
+		if err != nil {
+			return err
+		}
+		for _, variable := range contents.Variables {
+			variable.ID = 0
+			variable.AppID = result.ID
+			if _, err := variables.Create(ctx, variable); err != nil {
+				return fmt.Errorf("failed to restore variable %s: %w", variable.Key, err)
+			}
+		}
+
+		revisions, err := revisionRepository(ctx, s.Store)
+		if err != nil {
+			return err
+		}
+		var previous *model.ApplicationSnapshot
+		for _, revision := range contents.Revisions {
+			revision.ID = 0
+			revision.AppID = result.ID
+			if _, err := revisions.Create(ctx, revision); err != nil {
+				return fmt.Errorf("failed to restore revision %d: %w", revision.Revision, err)
+			}
+			previous = &revision.Snapshot
+		}
+
+		// Without a restored history the application starts a new one; a
+		// restore under a new name is recorded as an update of the history
+		action := model.RevisionActionUpdate
+		if previous == nil {
+			action = model.RevisionActionCreate
+		}
+		return apps.recordRevision(ctx, result, previous, action, 0)
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to restore backup %s: %v", backupID, err)
+		}
+		return nil, err
+	}
+
+	logger.Info("Backup %s restored as application %d", backupID, result.ID)
+	return result, nil
+}
+
+// run writes the archive of a backup to the storage and records the outcome,
+// which is also the outcome of the backup operation
+func (s *applicationBackupService) run(ctx context.Context, repo datastore.Repository[*model.ApplicationBackup], backup *model.ApplicationBackup, progress ProgressFunc) (interface{}, error) {
+	backup.Status = model.BackupStatusRunning
+	if _, err := repo.Update(ctx, backup); err != nil {
+		logger.Error("Failed to update backup %s: %v", backup.BackupID, err)
+	}
+	progress(10, "writing archive")
+
+	var size int64
+	data, err := s.writeArchive(ctx, backup)
+	if err == nil {
+		progress(50, "uploading archive")
+		size, err = s.Storage.Put(ctx, backup.StorageKey, bytes.NewReader(data))
+	}
+	s.finish(ctx, repo, backup, size, err)
+	if err != nil {
+		return nil, err
+	}
+	return backupResult{BackupID: backup.BackupID, Size: size}, nil
+}
+
+// finish records the outcome of a backup job
+func (s *applicationBackupService) finish(ctx context.Context, repo datastore.Repository[*model.ApplicationBackup], backup *model.ApplicationBackup, size int64, jobErr error) {
+	now := time.Now()
+	backup.CompletedAt = &now
+	if jobErr != nil {
+		logger.Error("Backup %s of application %d failed: %v", backup.BackupID, backup.AppID, jobErr)
+		backup.Status = model.BackupStatusFailed
+		backup.Error = jobErr.Error()
+	} else {
+		logger.Info("Backup %s of application %d completed: %d bytes", backup.BackupID, backup.AppID, size)
+		backup.Status = model.BackupStatusCompleted
+		backup.Size = size
+	}
+
+	if _, err := repo.Update(ctx, backup); err != nil {
+		logger.Error("Failed to update backup %s: %v", backup.BackupID, err)
+	}
+}
+
+// writeArchive serializes the application of a backup into a tar archive,
+// gzip compressed when requested
+func (s *applicationBackupService) writeArchive(ctx context.Context, backup *model.ApplicationBackup) ([]byte, error) {
+	app, err := s.applications().GetApplicationByID(ctx, backup.AppID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []archiveEntry{
+		{backupManifestEntry, backupManifest{
+			Version:     backupArchiveVersion,
+			BackupID:    backup.BackupID,
+			AppID:       backup.AppID,
+			Name:        backup.Name,
+			IncludeData: backup.IncludeData,
+			CreatedAt:   time.Now(),
+		}},
+		{backupApplicationEntry, app},
+	}
+
+	if backup.IncludeData {
+		filters := map[string]interface{}{"app_id": backup.AppID}
+
+		variableRepo, err := variableRepository(ctx, s.Store)
+		if err != nil {
+			return nil, err
+		}
+		variables, err := variableRepo.List(ctx, datastore.ListOptions{SortBy: "key", Filters: filters})
+		if err != nil {
+			return nil, err
+		}
+
+		revisionRepo, err := revisionRepository(ctx, s.Store)
+		if err != nil {
+			return nil, err
+		}
+		revisions, err := revisionRepo.List(ctx, datastore.ListOptions{SortBy: "revision", Filters: filters})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, archiveEntry{backupVariablesEntry, variables}, archiveEntry{backupRevisionsEntry, revisions})
+	}
+
+	var buf bytes.Buffer
+	var out io.Writer = &buf
+	var gz *gzip.Writer
+	if backup.Compress {
+		gz = gzip.NewWriter(&buf)
+		out = gz
+	}
+
+	tw := tar.NewWriter(out)
+	for _, entry := range entries {
+		data, err := json.MarshalIndent(entry.value, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", entry.name, err)
+		}
+		header := &tar.Header{Name: entry.name, Mode: 0644, Size: int64(len(data)), ModTime: time.Now()}
+		if err := tw.WriteHeader(header); err != nil {
+			return nil, err
+		}
+		if _, err := tw.Write(data); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// readArchive reads the archive of a backup from the storage
+func (s *applicationBackupService) readArchive(ctx context.Context, backup *model.ApplicationBackup) (*backupContents, error) {
+	object, err := s.Storage.Get(ctx, backup.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, fmt.Errorf("%w: archive %s is missing", model.ErrBackupCorrupted, backup.StorageKey)
+		}
+		return nil, err
+	}
+	defer object.Close()
+
+	// Compressed archives are recognized by the gzip magic number
+	var in io.Reader = bufio.NewReader(object)
+	if magic, _ := in.(*bufio.Reader).Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(in)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrBackupCorrupted, err)
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	contents := &backupContents{}
+	tr := tar.NewReader(in)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", model.ErrBackupCorrupted, err)
+		}
+
+		var dest interface{}
+		switch header.Name {
+		case backupManifestEntry:
+			dest = &contents.Manifest
+		case backupApplicationEntry:
+			dest = &contents.Application
+		case backupVariablesEntry:
+			dest = &contents.Variables
+		case backupRevisionsEntry:
+			dest = &contents.Revisions
+		default:
+			continue
+		}
+		if err := json.NewDecoder(tr).Decode(dest); err != nil {
+			return nil, fmt.Errorf("%w: invalid %s: %v", model.ErrBackupCorrupted, header.Name, err)
+		}
+	}
+
+	if contents.Manifest.Version != backupArchiveVersion || contents.Application == nil {
+		return nil, fmt.Errorf("%w: unsupported archive version %d", model.ErrBackupCorrupted, contents.Manifest.Version)
+	}
+	return contents, nil
+}
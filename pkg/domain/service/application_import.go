@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ImportFailure reports why the application at Index of an import was not imported
+type ImportFailure struct {
+	Index int
+	Err   error
+}
+
+// ImportApplications validates and creates each application on its own, so that
+// invalid applications do not prevent the others from being imported. With
+// dryRun nothing is created and the failures that an import would report are returned.
+func (s *applicationService) ImportApplications(ctx context.Context, apps []*model.Application, dryRun bool) ([]ImportFailure, error) {
+	logger.Info("Importing applications: count=%d, dryRun=%t", len(apps), dryRun)
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var failures []ImportFailure
+	seen := make(map[string]bool, len(apps))
+	for i, app := range apps {
+		if app.Name != "" && seen[app.Name] {
+			failures = append(failures, ImportFailure{Index: i, Err: model.ErrApplicationNameRepeated})
+			continue
+		}
+		seen[app.Name] = true
+
+		if dryRun {
+			err = s.checkImport(ctx, repo, app)
+		} else {
+			_, err = s.CreateApplication(ctx, app)
+		}
+		if err != nil {
+			if _, ok := err.(*model.DomainError); !ok {
+				return nil, err
+			}
+			failures = append(failures, ImportFailure{Index: i, Err: err})
+		}
+	}
+
+	logger.Info("Applications imported: total=%d, failed=%d, dryRun=%t", len(apps), len(failures), dryRun)
+	return failures, nil
+}
+
+// checkImport applies the checks of CreateApplication without creating the application
+func (s *applicationService) checkImport(ctx context.Context, repo datastore.Repository[*model.Application], app *model.Application) error {
+	if err := normalizeApplication(app); err != nil {
+		return err
+	}
+
+	orgID, _ := model.OrganizationFromContext(ctx)
+	existing, err := s.findByName(ctx, repo, orgID, app.Name)
+	if err != nil && err != datastore.ErrNotFound {
+		return err
+	}
+	if existing != nil {
+		return model.ErrApplicationNameExists
+	}
+	return nil
+}
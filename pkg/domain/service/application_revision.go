@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// revisionRepository returns the revision repository backed by store, or by
+// the transaction of the unit of work carried by ctx
+func revisionRepository(ctx context.Context, store datastore.DatastoreInterface) (datastore.Repository[*model.ApplicationRevision], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.ApplicationRevision](uow.Store())
+	}
+	return datastore.NewRepository[*model.ApplicationRevision](store)
+}
+
+// ListApplicationRevisions retrieves a paginated list of the revisions of an application, newest first
+func (s *applicationService) ListApplicationRevisions(ctx context.Context, id uint, page, pageSize int) ([]*model.ApplicationRevision, int64, error) {
+	logger.Info("Listing revisions of application %d: page=%d, pageSize=%d", id, page, pageSize)
+
+	if _, err := s.GetApplicationByID(ctx, id); err != nil {
+		return nil, 0, err
+	}
+
+	repo, err := revisionRepository(ctx, s.Store)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := map[string]interface{}{"app_id": id}
+	total, err := repo.Count(ctx, datastore.ListOptions{Filters: filters})
+	if err != nil {
+		logger.Error("Failed to count application revisions: %v", err)
+		return nil, 0, err
+	}
+
+	revisions, err := repo.List(ctx, datastore.ListOptions{
+		Page:     page,
+		Size:     pageSize,
+		SortBy:   "revision",
+		SortDesc: true,
+		Filters:  filters,
+	})
+	if err != nil {
+		logger.Error("Failed to list application revisions: %v", err)
+		return nil, 0, err
+	}
+
+	return revisions, total, nil
+}
+
+// RollbackApplication restores the fields of an application from a revision.
+// The rollback is recorded as a new revision; restoring the current state is a no-op.
+func (s *applicationService) RollbackApplication(ctx context.Context, id uint, revision int) (*model.Application, error) {
+	logger.Info("Rolling back application %d to revision %d", id, revision)
+
+	var result *model.Application
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		if _, err := s.GetApplicationByID(ctx, id); err != nil {
+			return err
+		}
+
+		revisions, err := revisionRepository(ctx, s.Store)
+		if err != nil {
+			return err
+		}
+		target, err := findRevision(ctx, revisions, id, revision)
+		if err != nil {
+			return err
+		}
+
+		result, err = s.update(ctx, id, model.RevisionActionRollback, revision, func(app *model.Application) error {
+			target.Snapshot.ApplyTo(app)
+			return nil
+		})
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to roll back application: %v", err)
+		}
+		return nil, err
+	}
+
+	logger.Info("Application %d rolled back to revision %d", id, revision)
+	return result, nil
+}
+
+// recordRevision stores a revision of app after a change and applies the
+// retention limits. previous is nil for a newly created application. Nothing
+// is recorded when no versioned field changed.
+func (s *applicationService) recordRevision(ctx context.Context, app *model.Application, previous *model.ApplicationSnapshot, action string, restoredFrom int) error {
+	snapshot := model.SnapshotOf(app)
+	changes := snapshot.Diff(model.ApplicationSnapshot{Tags: model.StringList{}})
+	if previous != nil {
+		changes = snapshot.Diff(*previous)
+		if len(changes) == 0 {
+			return nil
+		}
+	}
+
+	repo, err := revisionRepository(ctx, s.Store)
+	if err != nil {
+		return err
+	}
+
+	latest, err := repo.List(ctx, datastore.ListOptions{
+		Size:     1,
+		SortBy:   "revision",
+		SortDesc: true,
+		Filters:  map[string]interface{}{"app_id": app.ID},
+	})
+	if err != nil {
+		return err
+	}
+	number := 1
+	if len(latest) > 0 {
+		number = latest[0].Revision + 1
+	}
+
+	if _, err := repo.Create(ctx, &model.ApplicationRevision{
+		AppID:        app.ID,
+		Revision:     number,
+		Action:       action,
+		Actor:        actorFromContext(ctx),
+		RestoredFrom: restoredFrom,
+		Snapshot:     snapshot,
+		Changes:      changes,
+	}); err != nil {
+		return fmt.Errorf("failed to record revision %d of application %d: %w", number, app.ID, err)
+	}
+
+	return s.pruneRevisions(ctx, repo, app.ID)
+}
+
+// pruneRevisions deletes the revisions beyond revisions.max_per_application and
+// those older than revisions.max_age, always keeping the latest revision
+func (s *applicationService) pruneRevisions(ctx context.Context, repo datastore.Repository[*model.ApplicationRevision], id uint) error {
+	if s.Config == nil {
+		return nil
+	}
+	limits := s.Config.Revisions
+	if limits.MaxPerApplication <= 0 && limits.MaxAge <= 0 {
+		return nil
+	}
+
+	revisions, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:   "revision",
+		SortDesc: true,
+		Filters:  map[string]interface{}{"app_id": id},
+	})
+	if err != nil {
+		return err
+	}
+
+	cutoff := time.Now().Add(-limits.MaxAge)
+	for i, revision := range revisions {
+		if i == 0 {
+			continue
+		}
+		expired := limits.MaxAge > 0 && revision.CreatedAt.Before(cutoff)
+		if (limits.MaxPerApplication > 0 && i >= limits.MaxPerApplication) || expired {
+			if err := repo.Delete(ctx, revision.ID); err != nil && err != datastore.ErrNotFound {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findRevision returns a revision of an application or model.ErrRevisionNotFound
+func findRevision(ctx context.Context, repo datastore.Repository[*model.ApplicationRevision], id uint, revision int) (*model.ApplicationRevision, error) {
+	revisions, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"app_id": id, "revision": revision},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(revisions) == 0 {
+		return nil, model.ErrRevisionNotFound
+	}
+	return revisions[0], nil
+}
+
+// actorFromContext returns the ID of the user performing the request, if known
+func actorFromContext(ctx context.Context) string {
+	if userID := ctx.Value(logger.FieldUserID); userID != nil {
+		return fmt.Sprint(userID)
+	}
+	return ""
+}
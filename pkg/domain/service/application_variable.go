@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/encryption"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ApplicationVariableServiceInterface defines the interface for application variable service.
+// Variables are returned with plain text values; secret values are encrypted at rest only.
+type ApplicationVariableServiceInterface interface {
+	ListVariables(ctx context.Context, appID uint) ([]*model.ApplicationVariable, error)
+	// ListVariablesByApplications lists the variables of several applications in
+	// one query, ordered by key and grouped by application ID
+	ListVariablesByApplications(ctx context.Context, appIDs []uint) (map[uint][]*model.ApplicationVariable, error)
+	GetVariable(ctx context.Context, appID uint, key string) (*model.ApplicationVariable, error)
+	CreateVariable(ctx context.Context, variable *model.ApplicationVariable) (*model.ApplicationVariable, error)
+	UpdateVariable(ctx context.Context, variable *model.ApplicationVariable) (*model.ApplicationVariable, error)
+	DeleteVariable(ctx context.Context, appID uint, key string) error
+
+	// ImportVariables creates or updates all variables in one unit of work and
+	// returns the number of created and updated variables
+	ImportVariables(ctx context.Context, appID uint, variables []*model.ApplicationVariable) (created, updated int, err error)
+}
+
+// applicationVariableService 内部实现，支持依赖注入
+type applicationVariableService struct {
+	Store         datastore.DatastoreInterface  `inject:"datastore"`
+	UnitOfWork    datastore.UnitOfWorkManager   `inject:"unit_of_work"`
+	Config        *config.Config                `inject:"config"`
+	Authorization AuthorizationServiceInterface `inject:""`
+}
+
+// NewApplicationVariableServiceForDI 创建支持依赖注入的应用变量服务实例
+func NewApplicationVariableServiceForDI() ApplicationVariableServiceInterface {
+	return &applicationVariableService{}
+}
+
+// variableRepository returns the variable repository backed by store, or by
+// the transaction of the unit of work carried by ctx
+func variableRepository(ctx context.Context, store datastore.DatastoreInterface) (datastore.Repository[*model.ApplicationVariable], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.ApplicationVariable](uow.Store())
+	}
+	return datastore.NewRepository[*model.ApplicationVariable](store)
+}
+
+// ListVariables lists the variables of an application ordered by key
+func (s *applicationVariableService) ListVariables(ctx context.Context, appID uint) ([]*model.ApplicationVariable, error) {
+	if err := s.checkApplication(ctx, appID, model.ActionRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:  "key",
+		Filters: map[string]interface{}{"app_id": appID},
+	})
+	if err != nil {
+		logger.Error("Failed to list application variables: %v", err)
+		return nil, err
+	}
+
+	for _, variable := range variables {
+		if err := s.decrypt(variable); err != nil {
+			return nil, err
+		}
+	}
+	return variables, nil
+}
+
+// ListVariablesByApplications lists the variables of several applications, skipping unknown applications
+func (s *applicationVariableService) ListVariablesByApplications(ctx context.Context, appIDs []uint) (map[uint][]*model.ApplicationVariable, error) {
+	result := make(map[uint][]*model.ApplicationVariable, len(appIDs))
+	if len(appIDs) == 0 {
+		return result, nil
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	variables, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:  "key",
+		Filters: map[string]interface{}{"app_id": appIDs},
+	})
+	if err != nil {
+		logger.Error("Failed to list application variables: %v", err)
+		return nil, err
+	}
+
+	for _, variable := range variables {
+		if err := s.decrypt(variable); err != nil {
+			return nil, err
+		}
+		result[variable.AppID] = append(result[variable.AppID], variable)
+	}
+	return result, nil
+}
+
+// GetVariable retrieves a variable of an application by key
+func (s *applicationVariableService) GetVariable(ctx context.Context, appID uint, key string) (*model.ApplicationVariable, error) {
+	if err := s.checkApplication(ctx, appID, model.ActionRead); err != nil {
+		return nil, err
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	variable, err := findVariable(ctx, repo, appID, key)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrVariableNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.decrypt(variable); err != nil {
+		return nil, err
+	}
+	return variable, nil
+}
+
+// CreateVariable creates a new variable
+func (s *applicationVariableService) CreateVariable(ctx context.Context, variable *model.ApplicationVariable) (*model.ApplicationVariable, error) {
+	logger.Info("Creating variable %s of application %d", variable.Key, variable.AppID)
+
+	if err := variable.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkApplication(ctx, variable.AppID, model.ActionUpdate); err != nil {
+		return nil, err
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := findVariable(ctx, repo, variable.AppID, variable.Key)
+	if err != nil && err != datastore.ErrNotFound {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, model.ErrVariableExists
+	}
+
+	return s.save(ctx, repo, variable, repo.Create)
+}
+
+// UpdateVariable replaces the value and secret flag of an existing variable
+func (s *applicationVariableService) UpdateVariable(ctx context.Context, variable *model.ApplicationVariable) (*model.ApplicationVariable, error) {
+	logger.Info("Updating variable %s of application %d", variable.Key, variable.AppID)
+
+	if err := variable.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.checkApplication(ctx, variable.AppID, model.ActionUpdate); err != nil {
+		return nil, err
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := findVariable(ctx, repo, variable.AppID, variable.Key)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrVariableNotFound
+		}
+		return nil, err
+	}
+	variable.ID = existing.ID
+
+	return s.save(ctx, repo, variable, repo.Update)
+}
+
+// DeleteVariable deletes a variable of an application by key
+func (s *applicationVariableService) DeleteVariable(ctx context.Context, appID uint, key string) error {
+	logger.Info("Deleting variable %s of application %d", key, appID)
+
+	if err := s.checkApplication(ctx, appID, model.ActionUpdate); err != nil {
+		return err
+	}
+
+	repo, err := variableRepository(ctx, s.Store)
+	if err != nil {
+		return err
+	}
+
+	variable, err := findVariable(ctx, repo, appID, key)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrVariableNotFound
+		}
+		return err
+	}
+
+	if err := repo.Delete(ctx, variable.ID); err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrVariableNotFound
+		}
+		logger.Error("Failed to delete application variable: %v", err)
+		return err
+	}
+	return nil
+}
+
+// ImportVariables creates or updates all variables in one unit of work. An
+// existing secret variable stays secret when it is updated.
+func (s *applicationVariableService) ImportVariables(ctx context.Context, appID uint, variables []*model.ApplicationVariable) (created, updated int, err error) {
+	logger.Info("Importing %d variables into application %d", len(variables), appID)
+
+	for _, variable := range variables {
+		variable.AppID = appID
+		if err := variable.Validate(); err != nil {
+			return 0, 0, fmt.Errorf("variable %s: %w", variable.Key, err)
+		}
+	}
+
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		created, updated = 0, 0
+		if err := s.checkApplication(ctx, appID, model.ActionUpdate); err != nil {
+			return err
+		}
+
+		repo, err := variableRepository(ctx, s.Store)
+		if err != nil {
+			return err
+		}
+
+		for _, variable := range variables {
+			existing, err := findVariable(ctx, repo, appID, variable.Key)
+			switch {
+			case err == datastore.ErrNotFound:
+				if _, err := s.save(ctx, repo, variable, repo.Create); err != nil {
+					return fmt.Errorf("variable %s: %w", variable.Key, err)
+				}
+				created++
+			case err != nil:
+				return err
+			default:
+				variable.ID = existing.ID
+				variable.Secret = variable.Secret || existing.Secret
+				if _, err := s.save(ctx, repo, variable, repo.Update); err != nil {
+					return fmt.Errorf("variable %s: %w", variable.Key, err)
+				}
+				updated++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to import application variables: %v", err)
+		return 0, 0, err
+	}
+
+	logger.Info("Application variables imported: %d created, %d updated", created, updated)
+	return created, updated, nil
+}
+
+// save encrypts secret values and stores the variable with write, returning it with its plain text value
+func (s *applicationVariableService) save(ctx context.Context, repo datastore.Repository[*model.ApplicationVariable], variable *model.ApplicationVariable,
+	write func(context.Context, *model.ApplicationVariable) (*model.ApplicationVariable, error)) (*model.ApplicationVariable, error) {
+	stored := *variable
+	if stored.Secret {
+		key, err := s.encryptionKey()
+		if err != nil {
+			return nil, err
+		}
+		if stored.Value, err = encryption.Encrypt(variable.Value, key); err != nil {
+			return nil, fmt.Errorf("failed to encrypt variable %s: %w", variable.Key, err)
+		}
+	}
+
+	result, err := write(ctx, &stored)
+	if err != nil {
+		switch err {
+		case datastore.ErrNotFound:
+			return nil, model.ErrVariableNotFound
+		case datastore.ErrDuplicateKey:
+			return nil, model.ErrVariableExists
+		}
+		logger.Error("Failed to save application variable: %v", err)
+		return nil, err
+	}
+
+	result.Value = variable.Value
+	return result, nil
+}
+
+// decrypt replaces the stored value of a secret variable with its plain text
+func (s *applicationVariableService) decrypt(variable *model.ApplicationVariable) error {
+	if !variable.Secret {
+		return nil
+	}
+	key, err := s.encryptionKey()
+	if err != nil {
+		return err
+	}
+	value, err := encryption.Decrypt(variable.Value, key)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt variable %s: %w", variable.Key, err)
+	}
+	variable.Value = value
+	return nil
+}
+
+// encryptionKey returns the key used for secret variables
+func (s *applicationVariableService) encryptionKey() (string, error) {
+	if s.Config == nil || s.Config.Security.EncryptionKey == "" {
+		return "", fmt.Errorf("security.encryption_key is not configured")
+	}
+	return s.Config.Security.EncryptionKey, nil
+}
+
+// checkApplication returns model.ErrApplicationNotFound when the application does
+// not exist or belongs to another organization, and model.ErrAccessDenied when
+// the subject of ctx may not perform action on it. Changing variables updates
+// the application.
+func (s *applicationVariableService) checkApplication(ctx context.Context, appID uint, action string) error {
+	store := s.Store
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		store = uow.Store()
+	}
+	repo, err := datastore.NewRepository[*model.Application](store)
+	if err != nil {
+		return err
+	}
+
+	app, err := repo.Get(ctx, appID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrApplicationNotFound
+		}
+		return err
+	}
+	if !inOrganization(ctx, s.Config, app) {
+		return model.ErrApplicationNotFound
+	}
+	if s.Authorization != nil {
+		return s.Authorization.Authorize(ctx, action, app.AuthorizationResource())
+	}
+	return nil
+}
+
+// findVariable returns the variable of an application with the given key or datastore.ErrNotFound
+func findVariable(ctx context.Context, repo datastore.Repository[*model.ApplicationVariable], appID uint, key string) (*model.ApplicationVariable, error) {
+	variables, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"app_id": appID, "key": key},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(variables) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return variables[0], nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"slices"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Audit actions of authorization decisions
+const (
+	AuditActionAuthorizationAllowed = "authorization.allowed"
+	AuditActionAuthorizationDenied  = "authorization.denied"
+)
+
+// Reasons of authorization decisions
+const (
+	AuthorizationReasonNoPolicy   = "no_policy"
+	AuthorizationReasonRole       = "role"
+	AuthorizationReasonOwner      = "owner"
+	AuthorizationReasonOrgRole    = "org_role"
+	AuthorizationReasonNotGranted = "not_granted"
+)
+
+// AuthorizationServiceInterface defines the interface for the access policies
+// consulted before acting on a resource
+type AuthorizationServiceInterface interface {
+	// Authorize returns model.ErrAccessDenied unless the subject of ctx may
+	// perform action on resource. Contexts without a subject act on behalf of
+	// the system and are always allowed.
+	Authorize(ctx context.Context, action string, resource model.Resource) error
+	// Decide evaluates the policies for subject, without recording the decision
+	Decide(subject model.Subject, action string, resource model.Resource) model.AuthorizationDecision
+}
+
+// authorizationService 内部实现，支持依赖注入
+type authorizationService struct {
+	Config    *config.Config `inject:"config"`
+	Analytics analytics.Sink `inject:"analytics"`
+}
+
+// NewAuthorizationServiceForDI 创建支持依赖注入的授权服务实例
+func NewAuthorizationServiceForDI() AuthorizationServiceInterface {
+	return &authorizationService{}
+}
+
+// Authorize decides on the action of the subject of ctx and records the decision in the audit log
+func (s *authorizationService) Authorize(ctx context.Context, action string, resource model.Resource) error {
+	if !s.Config.Authorization.Enabled {
+		return nil
+	}
+	subject, ok := model.SubjectFromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	decision := s.Decide(subject, action, resource)
+	s.audit(ctx, decision)
+	if !decision.Allowed {
+		logger.Warn("User %s may not %s %s %s", subject.UserID, action, resource.Type, resource.ID)
+		return model.ErrAccessDenied
+	}
+	return nil
+}
+
+// Decide allows the action when no policy covers it, or when any policy grants it
+func (s *authorizationService) Decide(subject model.Subject, action string, resource model.Resource) model.AuthorizationDecision {
+	decision := model.AuthorizationDecision{
+		Subject:  subject,
+		Action:   action,
+		Resource: resource,
+		Reason:   AuthorizationReasonNoPolicy,
+	}
+
+	covered := false
+	for _, policy := range s.Config.Authorization.Policies {
+		if policy.Resource != resource.Type || policy.Action != action {
+			continue
+		}
+		covered = true
+		if reason, ok := grants(policy, subject, resource); ok {
+			decision.Allowed, decision.Reason = true, reason
+			return decision
+		}
+	}
+	if !covered {
+		decision.Allowed = true
+		return decision
+	}
+	decision.Reason = AuthorizationReasonNotGranted
+	return decision
+}
+
+// audit records decision in the audit log as selected by authorization.audit,
+// when the analytics sink records audit entries
+func (s *authorizationService) audit(ctx context.Context, decision model.AuthorizationDecision) {
+	if s.Analytics == nil || !s.Config.Monitor.Analytics.Audit {
+		return
+	}
+	action := AuditActionAuthorizationDenied
+	switch {
+	case decision.Allowed && s.Config.Authorization.Audit != "all":
+		return
+	case decision.Allowed:
+		action = AuditActionAuthorizationAllowed
+	case s.Config.Authorization.Audit == "none":
+		return
+	}
+	s.Analytics.Record(ctx, analytics.NewAuditEntry(ctx, event.NewEvent(action, decision)))
+}
+
+// grants reports whether policy grants its action on resource to subject, and by what
+func grants(policy config.AuthorizationPolicy, subject model.Subject, resource model.Resource) (string, bool) {
+	switch {
+	case subject.Role != "" && slices.Contains(policy.Roles, subject.Role):
+		return AuthorizationReasonRole, true
+	case policy.Owner && resource.OwnerID != "" && resource.OwnerID == subject.UserID:
+		return AuthorizationReasonOwner, true
+	case policy.OrgRole != "" && resource.OrgID != 0 && resource.OrgID == subject.OrgID &&
+		model.OrganizationRoleAtLeast(subject.OrgRole, policy.OrgRole):
+		return AuthorizationReasonOrgRole, true
+	}
+	return "", false
+}
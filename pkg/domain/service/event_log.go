@@ -0,0 +1,210 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// EventQuery selects the events of the log returned to a client
+type EventQuery struct {
+	// After is the cursor, the ID of the last event received; 0 starts from the
+	// oldest event kept
+	After uint
+	// ResourceTypes restricts the events to these resource types, see
+	// model.EventResourceType; empty returns every type
+	ResourceTypes []string
+	// Limit bounds the events returned, 0 uses event_log.page_size
+	Limit int
+}
+
+// EventLogServiceInterface defines the interface for the log of domain events
+// that clients follow to sync their state
+type EventLogServiceInterface interface {
+	// Enabled reports whether domain events are logged
+	Enabled() bool
+	// ListEvents returns the events after the cursor of query, oldest first.
+	// With an organization in ctx only its events are returned.
+	ListEvents(ctx context.Context, query EventQuery) ([]*model.DomainEvent, error)
+	// WaitEvents returns the events of ListEvents, waiting up to wait for the
+	// next event when there are none yet
+	WaitEvents(ctx context.Context, query EventQuery, wait time.Duration) ([]*model.DomainEvent, error)
+}
+
+// eventLogService 内部实现，支持依赖注入
+type eventLogService struct {
+	Store    datastore.DatastoreInterface `inject:"datastore"`
+	EventBus event.Bus                    `inject:"eventbus"`
+	Config   *config.Config               `inject:"config"`
+
+	events  map[string]bool
+	exclude map[string]bool
+
+	mutex sync.Mutex
+	// changed is closed and replaced whenever this instance logs an event
+	changed     chan struct{}
+	unsubscribe func()
+}
+
+// NewEventLogServiceForDI 创建支持依赖注入的事件日志服务实例
+func NewEventLogServiceForDI() EventLogServiceInterface {
+	return &eventLogService{changed: make(chan struct{})}
+}
+
+// OnStart subscribes to the logged event types
+func (s *eventLogService) OnStart(ctx context.Context) error {
+	if !s.Enabled() || s.EventBus == nil {
+		return nil
+	}
+
+	s.events = make(map[string]bool, len(s.Config.EventLog.Events))
+	for _, eventType := range s.Config.EventLog.Events {
+		s.events[eventType] = true
+	}
+	s.exclude = make(map[string]bool, len(s.Config.EventLog.Exclude))
+	for _, eventType := range s.Config.EventLog.Exclude {
+		s.exclude[eventType] = true
+	}
+	s.unsubscribe = s.EventBus.Subscribe(event.WildcardType, s.record)
+	return nil
+}
+
+// OnStop unsubscribes from the event bus
+func (s *eventLogService) OnStop(ctx context.Context) error {
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	return nil
+}
+
+// Enabled reports whether the event log is enabled and the datastore supports
+// generic repositories
+func (s *eventLogService) Enabled() bool {
+	if s.Config == nil || !s.Config.EventLog.Enabled {
+		return false
+	}
+	_, err := s.repository()
+	return err == nil
+}
+
+// repository returns the domain event repository
+func (s *eventLogService) repository() (datastore.Repository[*model.DomainEvent], error) {
+	return datastore.NewRepository[*model.DomainEvent](s.Store)
+}
+
+// logs reports whether events of the type are logged
+func (s *eventLogService) logs(eventType string) bool {
+	return !s.exclude[eventType] && (s.events[event.WildcardType] || s.events[eventType])
+}
+
+// record stores a published event and wakes up the waiting requests. Events
+// are attributed to the organization of ctx, or else to the org_id field of
+// their payload.
+func (s *eventLogService) record(ctx context.Context, e event.Event) {
+	if !s.logs(e.Type) {
+		return
+	}
+
+	payload, err := json.Marshal(e.Payload)
+	if err != nil {
+		logger.Error("Failed to log event %s: %v", e.Type, err)
+		return
+	}
+	orgID, ok := model.OrganizationFromContext(ctx)
+	if !ok {
+		var scoped struct {
+			OrgID uint `json:"org_id"`
+		}
+		// Payloads that are not JSON objects belong to no organization
+		_ = json.Unmarshal(payload, &scoped)
+		orgID = scoped.OrgID
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		logger.Error("Failed to log event %s: %v", e.Type, err)
+		return
+	}
+	// The request may finish before the event is stored
+	if _, err := repo.Create(context.WithoutCancel(ctx), &model.DomainEvent{
+		EventType:    e.Type,
+		ResourceType: model.EventResourceType(e.Type),
+		OrgID:        orgID,
+		Payload:      payload,
+		OccurredAt:   e.Timestamp.UTC(),
+	}); err != nil {
+		logger.Error("Failed to log event %s: %v", e.Type, err)
+		return
+	}
+
+	s.mutex.Lock()
+	close(s.changed)
+	s.changed = make(chan struct{})
+	s.mutex.Unlock()
+}
+
+// ListEvents lists the events after a cursor
+func (s *eventLogService) ListEvents(ctx context.Context, query EventQuery) ([]*model.DomainEvent, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	limit := query.Limit
+	if limit <= 0 || limit > s.Config.EventLog.PageSize {
+		limit = s.Config.EventLog.PageSize
+	}
+	opts := datastore.ListOptions{Size: limit, SortBy: "id", Filters: map[string]interface{}{}}
+	if query.After > 0 {
+		opts.Ranges = map[string]datastore.Range{"id": {From: query.After + 1}}
+	}
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		opts.Filters["org_id"] = orgID
+	}
+	if len(query.ResourceTypes) > 0 {
+		opts.Filters["resource_type"] = query.ResourceTypes
+	}
+	return repo.List(ctx, opts)
+}
+
+// WaitEvents lists the events after a cursor, waiting for new ones. Events
+// logged by this instance end the wait at once, those of other instances are
+// found by reading the log every event_log.poll_interval.
+func (s *eventLogService) WaitEvents(ctx context.Context, query EventQuery, wait time.Duration) ([]*model.DomainEvent, error) {
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	var poll <-chan time.Time
+	if interval := s.Config.EventLog.PollInterval; interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+	}
+
+	for {
+		// Taken before listing so that an event logged meanwhile ends the wait
+		s.mutex.Lock()
+		changed := s.changed
+		s.mutex.Unlock()
+
+		events, err := s.ListEvents(ctx, query)
+		if err != nil || len(events) > 0 || wait <= 0 {
+			return events, err
+		}
+
+		select {
+		case <-changed:
+		case <-poll:
+		case <-deadline.C:
+			return events, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
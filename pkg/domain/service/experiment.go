@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// EventTypeExperimentExposure is published whenever a subject is assigned a variant
+const EventTypeExperimentExposure = "experiment.exposure"
+
+// ExperimentExposure is the payload of an experiment exposure event
+type ExperimentExposure struct {
+	Experiment string `json:"experiment"`
+	Variant    string `json:"variant"`
+	UserID     string `json:"user_id,omitempty"`
+	TenantID   string `json:"tenant_id,omitempty"`
+}
+
+// ExperimentServiceInterface defines the interface for experiment service
+type ExperimentServiceInterface interface {
+	featureflags.Assigner
+
+	ListExperiments(ctx context.Context) []*model.Experiment
+	// AssignAll assigns every experiment the subject is enrolled in
+	AssignAll(ctx context.Context, evalCtx featureflags.EvaluationContext) map[string]string
+}
+
+// experimentService 内部实现，支持依赖注入
+type experimentService struct {
+	Config       *config.Config              `inject:"config"`
+	EventBus     event.Bus                   `inject:"eventbus"`
+	FeatureFlags FeatureFlagServiceInterface `inject:""`
+}
+
+// NewExperimentServiceForDI 创建支持依赖注入的实验服务实例
+func NewExperimentServiceForDI() ExperimentServiceInterface {
+	return &experimentService{}
+}
+
+// ListExperiments returns the configured experiments that pass validation
+func (s *experimentService) ListExperiments(ctx context.Context) []*model.Experiment {
+	if s.Config == nil {
+		return nil
+	}
+
+	experiments := make([]*model.Experiment, 0, len(s.Config.Experiments))
+	for _, def := range s.Config.Experiments {
+		experiment := &model.Experiment{
+			Key:         def.Key,
+			Description: def.Description,
+			Enabled:     def.Enabled,
+			FlagKey:     def.Flag,
+		}
+		for _, variant := range def.Variants {
+			experiment.Variants = append(experiment.Variants, model.ExperimentVariant{
+				Name:   variant.Name,
+				Weight: variant.Weight,
+			})
+		}
+		if err := experiment.Validate(); err != nil {
+			logger.Warn("Ignoring invalid experiment %s: %v", def.Key, err)
+			continue
+		}
+		experiments = append(experiments, experiment)
+	}
+	return experiments
+}
+
+// Assign implements featureflags.Assigner and publishes an exposure event on assignment
+func (s *experimentService) Assign(ctx context.Context, key string, evalCtx featureflags.EvaluationContext) (string, bool) {
+	for _, experiment := range s.ListExperiments(ctx) {
+		if experiment.Key == key {
+			return s.assign(ctx, experiment, evalCtx)
+		}
+	}
+	return "", false
+}
+
+// AssignAll assigns every experiment the subject is enrolled in
+func (s *experimentService) AssignAll(ctx context.Context, evalCtx featureflags.EvaluationContext) map[string]string {
+	result := make(map[string]string)
+	for _, experiment := range s.ListExperiments(ctx) {
+		if variant, ok := s.assign(ctx, experiment, evalCtx); ok {
+			result[experiment.Key] = variant
+		}
+	}
+	return result
+}
+
+// assign checks the gating flag, assigns a variant and records the exposure
+func (s *experimentService) assign(ctx context.Context, experiment *model.Experiment, evalCtx featureflags.EvaluationContext) (string, bool) {
+	if experiment.FlagKey != "" && (s.FeatureFlags == nil || !s.FeatureFlags.IsEnabled(ctx, experiment.FlagKey, evalCtx)) {
+		return "", false
+	}
+
+	variant, ok := experiment.Assign(evalCtx)
+	if !ok {
+		return "", false
+	}
+
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(EventTypeExperimentExposure, ExperimentExposure{
+			Experiment: experiment.Key,
+			Variant:    variant,
+			UserID:     evalCtx.UserID,
+			TenantID:   evalCtx.TenantID,
+		}))
+	}
+	return variant, true
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/monitor"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// FeatureFlagServiceInterface defines the interface for feature flag service
+type FeatureFlagServiceInterface interface {
+	featureflags.Evaluator
+
+	CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	GetFeatureFlag(ctx context.Context, key string) (*model.FeatureFlag, error)
+	ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error)
+	UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, key string) error
+
+	// EvaluateAll evaluates every known flag for the evaluation context
+	EvaluateAll(ctx context.Context, evalCtx featureflags.EvaluationContext) (map[string]bool, error)
+}
+
+// featureFlagService 内部实现，支持依赖注入
+type featureFlagService struct {
+	Store  datastore.DatastoreInterface `inject:"datastore"`
+	Config *config.Config               `inject:"config"`
+}
+
+// NewFeatureFlagServiceForDI 创建支持依赖注入的特性开关服务实例
+func NewFeatureFlagServiceForDI() FeatureFlagServiceInterface {
+	return &featureFlagService{}
+}
+
+// CreateFeatureFlag creates a new feature flag. A flag with the same key as a
+// configured flag overrides the configured definition.
+func (s *featureFlagService) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	logger.Info("Creating feature flag: %s", flag.Key)
+
+	if err := flag.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.Store.CreateFeatureFlag(ctx, flag)
+	if err != nil {
+		if err == datastore.ErrDuplicateKey {
+			return nil, model.ErrFeatureFlagExists
+		}
+		logger.Error("Failed to create feature flag: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// GetFeatureFlag retrieves a feature flag by key, falling back to configuration
+func (s *featureFlagService) GetFeatureFlag(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	flag, err := s.Store.GetFeatureFlagByKey(ctx, key)
+	if err == nil {
+		return flag, nil
+	}
+	if err != datastore.ErrNotFound {
+		logger.Error("Failed to get feature flag: %v", err)
+		return nil, err
+	}
+
+	if flag, ok := s.configuredFlags()[key]; ok {
+		return flag, nil
+	}
+	return nil, model.ErrFeatureFlagNotFound
+}
+
+// ListFeatureFlags lists datastore and configured flags, datastore flags taking precedence
+func (s *featureFlagService) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	stored, err := s.Store.ListFeatureFlags(ctx)
+	if err != nil {
+		logger.Error("Failed to list feature flags: %v", err)
+		return nil, err
+	}
+
+	merged := s.configuredFlags()
+	for _, flag := range stored {
+		merged[flag.Key] = flag
+	}
+
+	flags := make([]*model.FeatureFlag, 0, len(merged))
+	for _, flag := range merged {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Key < flags[j].Key
+	})
+	return flags, nil
+}
+
+// UpdateFeatureFlag updates a feature flag stored in the datastore
+func (s *featureFlagService) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	logger.Info("Updating feature flag: %s", flag.Key)
+
+	if err := flag.Validate(); err != nil {
+		return nil, err
+	}
+
+	result, err := s.Store.UpdateFeatureFlag(ctx, flag)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, s.notFoundError(flag.Key)
+		}
+		logger.Error("Failed to update feature flag: %v", err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// DeleteFeatureFlag deletes a feature flag stored in the datastore
+func (s *featureFlagService) DeleteFeatureFlag(ctx context.Context, key string) error {
+	logger.Info("Deleting feature flag: %s", key)
+
+	if err := s.Store.DeleteFeatureFlag(ctx, key); err != nil {
+		if err == datastore.ErrNotFound {
+			return s.notFoundError(key)
+		}
+		logger.Error("Failed to delete feature flag: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// IsEnabled implements featureflags.Evaluator. Unknown flags and lookup errors evaluate to false.
+func (s *featureFlagService) IsEnabled(ctx context.Context, key string, evalCtx featureflags.EvaluationContext) bool {
+	flag, err := s.GetFeatureFlag(ctx, key)
+	if err != nil {
+		if err != model.ErrFeatureFlagNotFound {
+			logger.Warn("Feature flag %s evaluated as disabled: %v", key, err)
+		}
+		monitor.RecordFeatureFlagEvaluation(key, false)
+		return false
+	}
+
+	enabled := flag.Evaluate(evalCtx)
+	monitor.RecordFeatureFlagEvaluation(key, enabled)
+	return enabled
+}
+
+// EvaluateAll evaluates every known flag for the evaluation context
+func (s *featureFlagService) EvaluateAll(ctx context.Context, evalCtx featureflags.EvaluationContext) (map[string]bool, error) {
+	flags, err := s.ListFeatureFlags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]bool, len(flags))
+	for _, flag := range flags {
+		enabled := flag.Evaluate(evalCtx)
+		monitor.RecordFeatureFlagEvaluation(flag.Key, enabled)
+		result[flag.Key] = enabled
+	}
+	return result, nil
+}
+
+// configuredFlags returns the flags defined in configuration keyed by flag key
+func (s *featureFlagService) configuredFlags() map[string]*model.FeatureFlag {
+	flags := make(map[string]*model.FeatureFlag)
+	if s.Config == nil {
+		return flags
+	}
+
+	for _, def := range s.Config.FeatureFlags.Flags {
+		flags[def.Key] = &model.FeatureFlag{
+			Key:               def.Key,
+			Description:       def.Description,
+			Enabled:           def.Enabled,
+			RolloutPercentage: def.RolloutPercentage,
+			TargetUsers:       def.TargetUsers,
+			TargetTenants:     def.TargetTenants,
+		}
+	}
+	return flags
+}
+
+// notFoundError distinguishes configured (read-only) flags from unknown ones
+func (s *featureFlagService) notFoundError(key string) error {
+	if _, ok := s.configuredFlags()[key]; ok {
+		return model.ErrFeatureFlagReadOnly
+	}
+	return model.ErrFeatureFlagNotFound
+}
@@ -0,0 +1,713 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/scanner"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/storage"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/imaging"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// File event types, recorded in the audit log
+const (
+	EventTypeFileCreated     = "file.created"
+	EventTypeFileUploaded    = "file.uploaded"
+	EventTypeFileDownloaded  = "file.downloaded"
+	EventTypeFileDeleted     = "file.deleted"
+	EventTypeFileProcessed   = "file.processed"
+	EventTypeFileQuarantined = "file.quarantined"
+)
+
+// defaultFileContentType is the content type of files uploaded without one
+const defaultFileContentType = "application/octet-stream"
+
+// FileChanged is the payload of the file events
+type FileChanged struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	OwnerID     string `json:"owner_id,omitempty"`
+	OrgID       uint   `json:"org_id,omitempty"`
+}
+
+// FileProcessed is the result of the operation processing an uploaded file
+type FileProcessed struct {
+	ChecksumSHA256 string   `json:"checksum_sha256"`
+	ScanStatus     string   `json:"scan_status,omitempty"`
+	ScanSignature  string   `json:"scan_signature,omitempty"`
+	Variants       []string `json:"variants,omitempty"`
+}
+
+// newFileProcessed returns the processing result of file
+func newFileProcessed(file *model.File) *FileProcessed {
+	result := &FileProcessed{
+		ChecksumSHA256: file.ChecksumSHA256,
+		ScanStatus:     file.ScanStatus,
+		ScanSignature:  file.ScanSignature,
+	}
+	for _, variant := range file.Variants {
+		result.Variants = append(result.Variants, variant.Name)
+	}
+	return result
+}
+
+// PresignedURL is a URL of the object storage through which a client transfers
+// a file. Uploads must send Headers with exactly the given values.
+type PresignedURL struct {
+	URL       string
+	Method    string
+	Headers   map[string]string
+	ExpiresAt time.Time
+}
+
+// FileServiceInterface defines the interface for files transferred between
+// clients and the object storage through presigned URLs
+type FileServiceInterface interface {
+	// CreateUpload records a pending file owned by the user and the active
+	// organization of ctx, and returns the URL its content is uploaded to
+	CreateUpload(ctx context.Context, file *model.File) (*model.File, *PresignedURL, error)
+	// CompleteUpload checks that the content of a pending file was uploaded
+	// with its declared size and marks the file uploaded
+	CompleteUpload(ctx context.Context, id uint) (*model.File, error)
+	// GetFile returns a file visible to the subject of ctx
+	GetFile(ctx context.Context, id uint) (*model.File, error)
+	// ResolveFileID returns the ID of the file with a public ID
+	ResolveFileID(ctx context.Context, publicID string) (uint, error)
+	// ListFiles lists the files of the active organization of ctx, or else
+	// those owned by the user of ctx, newest first
+	ListFiles(ctx context.Context, page, pageSize int) ([]*model.File, int64, error)
+	// DownloadURL returns the URL the content of a downloadable file is downloaded from
+	DownloadURL(ctx context.Context, id uint) (*model.File, *PresignedURL, error)
+	// OpenContent returns the content of a downloadable file once verified
+	// against its checksum. The caller closes the content.
+	OpenContent(ctx context.Context, id uint) (*model.File, io.ReadSeekCloser, error)
+	// VariantURLs returns the URLs the variants of a file returned by the
+	// other methods are downloaded from, by variant name
+	VariantURLs(ctx context.Context, file *model.File) (map[string]*PresignedURL, error)
+	// DeleteFile deletes a file, its content, its variants and its upload session
+	DeleteFile(ctx context.Context, id uint) error
+
+	// CreateUploadSession records a pending file like CreateUpload, and the
+	// session its content is uploaded through in chunks
+	CreateUploadSession(ctx context.Context, file *model.File) (*model.File, *model.UploadSession, error)
+	// GetUploadSession returns an upload session and its file
+	GetUploadSession(ctx context.Context, id uint) (*model.File, *model.UploadSession, error)
+	// ResolveUploadSessionID returns the ID of the upload session with a public ID
+	ResolveUploadSessionID(ctx context.Context, publicID string) (uint, error)
+	// AppendUploadChunk stores a chunk sent at the current offset of an upload
+	// session and returns the session with its offset advanced
+	AppendUploadChunk(ctx context.Context, id uint, offset int64, r io.Reader) (*model.File, *model.UploadSession, error)
+	// FinalizeUploadSession assembles the chunks of a complete upload session
+	// once their SHA-256 matches checksum, and marks its file uploaded
+	FinalizeUploadSession(ctx context.Context, id uint, checksum string) (*model.File, error)
+	// AbortUploadSession deletes an upload session, its chunks and its file
+	AbortUploadSession(ctx context.Context, id uint) error
+	// PurgeExpiredUploads deletes the expired upload sessions with their chunks
+	// and pending files, and returns how many were deleted
+	PurgeExpiredUploads(ctx context.Context) (int, error)
+}
+
+// fileService 内部实现，支持依赖注入
+type fileService struct {
+	Store         datastore.DatastoreInterface  `inject:"datastore"`
+	Storage       storage.Storage               `inject:"storage"`
+	EventBus      event.Bus                     `inject:"eventbus"`
+	Config        *config.Config                `inject:"config"`
+	Clock         clock.Clock                   `inject:"clock"`
+	Authorization AuthorizationServiceInterface `inject:""`
+	Scanner       scanner.Scanner               `inject:"scanner"`
+	Operations    OperationServiceInterface     `inject:""`
+
+	// cancel and done stop the cleanup of expired upload sessions
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// init 注册文件模块，禁用时不迁移文件和断点续传会话
+func init() {
+	module.Register(module.Module{
+		Name:        module.Files,
+		Description: "File uploads and downloads, resumable uploads, scanning and image processing",
+		Models:      []interface{}{&model.File{}, &model.UploadSession{}},
+	})
+}
+
+// NewFileServiceForDI 创建支持依赖注入的文件服务实例
+func NewFileServiceForDI() FileServiceInterface {
+	return &fileService{}
+}
+
+// Module implements module.Component
+func (s *fileService) Module() string {
+	return module.Files
+}
+
+// files returns the file repository
+func (s *fileService) files() (datastore.Repository[*model.File], error) {
+	return datastore.NewRepository[*model.File](s.Store)
+}
+
+// CreateUpload records a pending file and presigns the upload of its content
+func (s *fileService) CreateUpload(ctx context.Context, file *model.File) (*model.File, *PresignedURL, error) {
+	cfg := s.Config.Files
+	if err := s.prepare(ctx, file); err != nil {
+		return nil, nil, err
+	}
+
+	expiresAt := s.Clock.Now().Add(cfg.UploadURLTTL)
+	url, err := s.Storage.Presign(ctx, file.StorageKey, storage.PresignOptions{
+		Method:        http.MethodPut,
+		Expires:       cfg.UploadURLTTL,
+		ContentType:   file.ContentType,
+		ContentLength: file.Size,
+	})
+	if err != nil {
+		logger.Error("Failed to presign the upload of file %s: %v", file.Name, err)
+		return nil, nil, err
+	}
+
+	result, err := s.create(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, &PresignedURL{
+		URL:       url,
+		Method:    http.MethodPut,
+		Headers:   map[string]string{"Content-Type": file.ContentType},
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+// CompleteUpload marks a pending file uploaded once its object is stored, then
+// starts processing its content. Completing an uploaded file again returns it
+// unchanged.
+func (s *fileService) CompleteUpload(ctx context.Context, id uint) (*model.File, error) {
+	file, err := s.get(ctx, id, model.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if file.Status == model.FileStatusUploaded {
+		return file, nil
+	}
+
+	objects, err := s.Storage.List(ctx, file.StorageKey)
+	if err != nil {
+		logger.Error("Failed to look up the content of file %d: %v", file.ID, err)
+		return nil, err
+	}
+	uploaded := false
+	for _, object := range objects {
+		if object.Key == file.StorageKey && object.Size == file.Size {
+			uploaded = true
+		}
+	}
+	if !uploaded {
+		return nil, model.ErrFileUploadMissing
+	}
+	return s.markUploaded(ctx, file)
+}
+
+// markUploaded marks a pending file whose object is stored uploaded and
+// starts the operation processing its content
+func (s *fileService) markUploaded(ctx context.Context, file *model.File) (*model.File, error) {
+	now := s.Clock.Now()
+	file.Status = model.FileStatusUploaded
+	file.UploadedAt = &now
+	file.OperationID = uuid.NewString()
+	if s.Config.Files.Scan.Enabled {
+		file.ScanStatus = model.FileScanStatusPending
+	}
+	repo, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	result, err := repo.Update(ctx, file)
+	if err != nil {
+		logger.Error("Failed to update file %d: %v", file.ID, err)
+		return nil, err
+	}
+	logger.Info("File %d uploaded (%d bytes)", result.ID, result.Size)
+	s.publish(ctx, EventTypeFileUploaded, result)
+
+	// The job works on its own copy and outlives the request
+	job := *result
+	op := &model.Operation{OperationID: result.OperationID, Type: model.OperationTypeFileProcessing}
+	if _, err := s.Operations.StartOperation(ctx, op, func(ctx context.Context, progress ProgressFunc) (interface{}, error) {
+		return s.process(ctx, repo, &job, progress)
+	}); err != nil {
+		logger.Error("Failed to start processing file %d: %v", result.ID, err)
+	}
+	return result, nil
+}
+
+// prepare validates a file about to be uploaded and fills in its owner,
+// organization and storage key after authorizing its creation
+func (s *fileService) prepare(ctx context.Context, file *model.File) error {
+	cfg := s.Config.Files
+	if file.ContentType == "" {
+		file.ContentType = defaultFileContentType
+	}
+	if err := file.Validate(); err != nil {
+		return err
+	}
+	if cfg.MaxSize > 0 && file.Size > cfg.MaxSize {
+		return model.ErrFileTooLarge
+	}
+	if !contentTypeAllowed(cfg.ContentTypes, file.ContentType) {
+		return model.ErrFileContentTypeNotAllowed
+	}
+
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		file.OrgID = orgID
+	}
+	file.OwnerID = actorFromContext(ctx)
+	if err := s.authorize(ctx, model.ActionCreate, file); err != nil {
+		return err
+	}
+	file.Status = model.FileStatusPending
+	file.StorageKey = cfg.KeyPrefix + uuid.NewString()
+	file.UploadedAt = nil
+	return nil
+}
+
+// create records a prepared file
+func (s *fileService) create(ctx context.Context, file *model.File) (*model.File, error) {
+	repo, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	result, err := repo.Create(ctx, file)
+	if err != nil {
+		logger.Error("Failed to create file: %v", err)
+		return nil, err
+	}
+	logger.Info("File %d created for upload by %s", result.ID, result.OwnerID)
+	s.publish(ctx, EventTypeFileCreated, result)
+	return result, nil
+}
+
+// GetFile retrieves a file
+func (s *fileService) GetFile(ctx context.Context, id uint) (*model.File, error) {
+	return s.get(ctx, id, model.ActionRead)
+}
+
+// ResolveFileID returns the ID of the file with a public ID
+func (s *fileService) ResolveFileID(ctx context.Context, publicID string) (uint, error) {
+	repo, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+	file, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrFileNotFound
+		}
+		return 0, err
+	}
+	return file.ID, nil
+}
+
+// ListFiles lists the files in scope of ctx
+func (s *fileService) ListFiles(ctx context.Context, page, pageSize int) ([]*model.File, int64, error) {
+	repo, err := s.files()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := map[string]interface{}{}
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		filters["org_id"] = orgID
+	} else if actor := actorFromContext(ctx); actor != "" {
+		filters["owner_id"] = actor
+		filters["org_id"] = uint(0)
+	}
+
+	total, err := repo.Count(ctx, datastore.ListOptions{Filters: filters})
+	if err != nil {
+		logger.Error("Failed to count files: %v", err)
+		return nil, 0, err
+	}
+	files, err := repo.List(ctx, datastore.ListOptions{Page: page, Size: pageSize, SortBy: "created_at", SortDesc: true, Filters: filters})
+	if err != nil {
+		logger.Error("Failed to list files: %v", err)
+		return nil, 0, err
+	}
+	return files, total, nil
+}
+
+// DownloadURL presigns the download of a downloadable file. The object
+// storage serves the content unverified; clients compare it with the checksum.
+func (s *fileService) DownloadURL(ctx context.Context, id uint) (*model.File, *PresignedURL, error) {
+	file, err := s.get(ctx, id, model.ActionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := file.Downloadable(); err != nil {
+		return nil, nil, err
+	}
+
+	ttl := s.Config.Files.DownloadURLTTL
+	expiresAt := s.Clock.Now().Add(ttl)
+	url, err := s.Storage.Presign(ctx, file.StorageKey, storage.PresignOptions{Method: http.MethodGet, Expires: ttl})
+	if err != nil {
+		logger.Error("Failed to presign the download of file %d: %v", file.ID, err)
+		return nil, nil, err
+	}
+	s.publish(ctx, EventTypeFileDownloaded, file)
+	return file, &PresignedURL{URL: url, Method: http.MethodGet, ExpiresAt: expiresAt}, nil
+}
+
+// OpenContent copies the content of a downloadable file to a temporary file
+// while hashing it, and returns the temporary file once the content matches the
+// checksum. Closing the content removes the temporary file.
+func (s *fileService) OpenContent(ctx context.Context, id uint) (*model.File, io.ReadSeekCloser, error) {
+	file, err := s.get(ctx, id, model.ActionRead)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := file.Downloadable(); err != nil {
+		return nil, nil, err
+	}
+	if file.ChecksumSHA256 == "" {
+		return nil, nil, model.ErrFileNotVerified
+	}
+
+	content, checksum, err := s.spool(ctx, file)
+	if err != nil {
+		logger.Error("Failed to read the content of file %d: %v", file.ID, err)
+		return nil, nil, err
+	}
+	if checksum != file.ChecksumSHA256 {
+		content.Close()
+		logger.Error("Content of file %d has checksum %s instead of %s", file.ID, checksum, file.ChecksumSHA256)
+		return nil, nil, model.ErrFileChecksumMismatch
+	}
+	s.publish(ctx, EventTypeFileDownloaded, file)
+	return file, content, nil
+}
+
+// VariantURLs presigns the download of each variant of a file
+func (s *fileService) VariantURLs(ctx context.Context, file *model.File) (map[string]*PresignedURL, error) {
+	ttl := s.Config.Files.DownloadURLTTL
+	expiresAt := s.Clock.Now().Add(ttl)
+	urls := make(map[string]*PresignedURL, len(file.Variants))
+	for _, variant := range file.Variants {
+		url, err := s.Storage.Presign(ctx, variant.StorageKey, storage.PresignOptions{Method: http.MethodGet, Expires: ttl})
+		if err != nil {
+			logger.Error("Failed to presign the download of variant %s of file %d: %v", variant.Name, file.ID, err)
+			return nil, err
+		}
+		urls[variant.Name] = &PresignedURL{URL: url, Method: http.MethodGet, ExpiresAt: expiresAt}
+	}
+	return urls, nil
+}
+
+// DeleteFile deletes the objects of a file and its variants, its upload
+// session, then its record
+func (s *fileService) DeleteFile(ctx context.Context, id uint) error {
+	file, err := s.get(ctx, id, model.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteUploadSessions(ctx, file); err != nil {
+		logger.Error("Failed to delete the upload session of file %d: %v", file.ID, err)
+		return err
+	}
+	keys := []string{file.StorageKey}
+	for _, variant := range file.Variants {
+		keys = append(keys, variant.StorageKey)
+	}
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Error("Failed to delete object %s of file %d: %v", key, file.ID, err)
+			return err
+		}
+	}
+
+	repo, err := s.files()
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, file.ID); err != nil {
+		logger.Error("Failed to delete file %d: %v", file.ID, err)
+		return err
+	}
+	logger.Info("File %d deleted", file.ID)
+	s.publish(ctx, EventTypeFileDeleted, file)
+	return nil
+}
+
+// process computes the checksum of an uploaded file, scans it when scanning
+// is enabled, then processes it when it is an image. Quarantined files are not
+// processed further. A failed scan leaves the file pending scan, and so not
+// downloadable; a failed image processing still records the checksum and scan.
+func (s *fileService) process(ctx context.Context, repo datastore.Repository[*model.File], file *model.File, progress ProgressFunc) (interface{}, error) {
+	content, checksum, err := s.spool(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+	file.ChecksumSHA256 = checksum
+	progress(10, "checksum computed")
+
+	if file.ScanStatus == model.FileScanStatusPending {
+		result, err := s.Scanner.Scan(ctx, content)
+		if err != nil {
+			logger.Error("Failed to scan file %d: %v", file.ID, err)
+			return nil, err
+		}
+		if !result.Clean {
+			file.ScanStatus = model.FileScanStatusQuarantined
+			file.ScanSignature = result.Signature
+			quarantined, err := repo.Update(ctx, file)
+			if err != nil {
+				return nil, err
+			}
+			logger.Warn("File %d quarantined: %s", quarantined.ID, result.Signature)
+			s.publish(ctx, EventTypeFileQuarantined, quarantined)
+			return newFileProcessed(quarantined), nil
+		}
+		file.ScanStatus = model.FileScanStatusClean
+		progress(40, "scanned clean")
+	}
+
+	var imageErr error
+	if s.processesImage(file) {
+		if _, err := content.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		data, err := io.ReadAll(content)
+		if err != nil {
+			return nil, err
+		}
+		if imageErr = s.processImage(ctx, file, data, progress); imageErr != nil {
+			logger.Error("Failed to process image file %d: %v", file.ID, imageErr)
+		}
+	}
+
+	result, err := repo.Update(ctx, file)
+	if err != nil {
+		return nil, err
+	}
+	if imageErr != nil {
+		return nil, imageErr
+	}
+	logger.Info("File %d processed into %d variants", result.ID, len(result.Variants))
+	s.publish(ctx, EventTypeFileProcessed, result)
+	return newFileProcessed(result), nil
+}
+
+// processesImage reports whether the content of file is an image processed on upload
+func (s *fileService) processesImage(file *model.File) bool {
+	if !s.Config.Files.Images.Enabled {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(file.ContentType)
+	return err == nil && imaging.Supported(mediaType)
+}
+
+// processImage strips the metadata of an uploaded image, rewriting its object
+// and checksum, and stores its thumbnails as variants of the file. Thumbnails
+// are rendered from the largest to the smallest, each from the previous one.
+func (s *fileService) processImage(ctx context.Context, file *model.File, data []byte, progress ProgressFunc) error {
+	cfg := s.Config.Files.Images
+	mediaType, _, _ := mime.ParseMediaType(file.ContentType)
+	// The orientation is lost with the metadata, thumbnails are turned upright instead
+	orientation := imaging.Orientation(data)
+
+	if cfg.StripMetadata {
+		stripped, err := imaging.StripMetadata(data, mediaType)
+		if err != nil {
+			return err
+		}
+		if len(stripped) != len(data) {
+			if _, err := s.Storage.Put(ctx, file.StorageKey, bytes.NewReader(stripped)); err != nil {
+				return err
+			}
+			logger.Info("Stripped %d bytes of metadata from file %d", len(data)-len(stripped), file.ID)
+			data = stripped
+			sum := sha256.Sum256(stripped)
+			file.ChecksumSHA256 = hex.EncodeToString(sum[:])
+			file.Size = int64(len(stripped))
+		}
+		progress(50, "metadata stripped")
+	}
+
+	img, err := imaging.Decode(data, cfg.MaxPixels)
+	if err != nil {
+		return err
+	}
+	sizes := slices.Clone(cfg.ThumbnailSizes)
+	slices.Sort(sizes)
+	slices.Reverse(sizes)
+
+	variants := model.FileVariants{}
+	for i, size := range sizes {
+		img = imaging.Thumbnail(img, size)
+		thumbnail := imaging.Orient(img, orientation)
+
+		var buf bytes.Buffer
+		contentType, err := imaging.Encode(&buf, thumbnail, mediaType, cfg.Quality)
+		if err != nil {
+			return err
+		}
+		variant := model.FileVariant{
+			Name:        "thumbnail_" + strconv.Itoa(size),
+			ContentType: contentType,
+			Width:       thumbnail.Bounds().Dx(),
+			Height:      thumbnail.Bounds().Dy(),
+		}
+		variant.StorageKey = variantKey(file.StorageKey, variant.Name, contentType)
+		if variant.Size, err = s.Storage.Put(ctx, variant.StorageKey, &buf); err != nil {
+			return err
+		}
+		variants = append(variants, variant)
+		progress(50+50*(i+1)/len(sizes), fmt.Sprintf("%s rendered", variant.Name))
+	}
+	file.Variants = variants
+	return nil
+}
+
+// spool copies the content of a file to a temporary file, rewound, and returns
+// it with the hex SHA-256 of the content. Closing it removes the temporary file.
+func (s *fileService) spool(ctx context.Context, file *model.File) (io.ReadSeekCloser, string, error) {
+	reader, err := s.Storage.Get(ctx, file.StorageKey)
+	if err != nil {
+		return nil, "", err
+	}
+	defer reader.Close()
+
+	tmp, err := os.CreateTemp("", "file-*")
+	if err != nil {
+		return nil, "", err
+	}
+	content := &tempFile{tmp}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), reader); err != nil {
+		content.Close()
+		return nil, "", err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		content.Close()
+		return nil, "", err
+	}
+	return content, hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// tempFile is a temporary file removed on close
+type tempFile struct {
+	*os.File
+}
+
+// Close closes and removes the file
+func (f *tempFile) Close() error {
+	err := f.File.Close()
+	if removeErr := os.Remove(f.Name()); err == nil {
+		err = removeErr
+	}
+	return err
+}
+
+// variantKey returns the storage key of a variant of the file stored under
+// key, next to it so that storages on file systems can hold both
+func variantKey(key, name, contentType string) string {
+	ext := ".png"
+	if contentType == imaging.ContentTypeJPEG {
+		ext = ".jpg"
+	}
+	return key + "." + name + ext
+}
+
+// get returns a file in scope of ctx after authorizing action on it. Files
+// of an organization are in scope of its members, the others of their owner;
+// contexts without a user act on behalf of the system and see every file.
+func (s *fileService) get(ctx context.Context, id uint, action string) (*model.File, error) {
+	repo, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+	file, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrFileNotFound
+		}
+		logger.Error("Failed to get file %d: %v", id, err)
+		return nil, err
+	}
+
+	orgID, inOrg := model.OrganizationFromContext(ctx)
+	actor := actorFromContext(ctx)
+	switch {
+	case inOrg && file.OrgID != orgID:
+		return nil, model.ErrFileNotFound
+	case !inOrg && actor != "" && (file.OrgID != 0 || file.OwnerID != actor):
+		return nil, model.ErrFileNotFound
+	}
+	if err := s.authorize(ctx, action, file); err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// authorize returns model.ErrAccessDenied unless the subject of ctx may perform action on file
+func (s *fileService) authorize(ctx context.Context, action string, file *model.File) error {
+	if s.Authorization == nil {
+		return nil
+	}
+	return s.Authorization.Authorize(ctx, action, file.AuthorizationResource())
+}
+
+// publish publishes a file event
+func (s *fileService) publish(ctx context.Context, eventType string, file *model.File) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(eventType, FileChanged{
+			ID:          file.ID,
+			Name:        file.Name,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			OwnerID:     file.OwnerID,
+			OrgID:       file.OrgID,
+		}))
+	}
+}
+
+// contentTypeAllowed reports whether the media type of contentType is one of
+// allowed, where type/* allows a whole type; an empty list allows any
+func contentTypeAllowed(allowed []string, contentType string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range allowed {
+		pattern = strings.ToLower(pattern)
+		if pattern == mediaType || strings.HasSuffix(pattern, "/*") && strings.HasPrefix(mediaType, strings.TrimSuffix(pattern, "*")) {
+			return true
+		}
+	}
+	return false
+}
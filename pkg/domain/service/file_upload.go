@@ -0,0 +1,426 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/storage"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// uploadPurgeBatchSize is the number of expired upload sessions deleted per query
+const uploadPurgeBatchSize = 100
+
+// uploadSessions returns the upload session repository
+func (s *fileService) uploadSessions() (datastore.Repository[*model.UploadSession], error) {
+	return datastore.NewRepository[*model.UploadSession](s.Store)
+}
+
+// OnStart starts deleting the expired upload sessions every cleanup interval
+// when resumable uploads are enabled
+func (s *fileService) OnStart(ctx context.Context) error {
+	cfg := s.Config.Files
+	if !cfg.Enabled || !cfg.Uploads.Enabled || cfg.Uploads.CleanupInterval <= 0 {
+		return nil
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.done = make(chan struct{})
+	go s.purgeUploadSessions(runCtx, cfg.Uploads.CleanupInterval)
+	return nil
+}
+
+// OnStop stops the cleanup of upload sessions, waiting for the running one up
+// to the deadline of ctx
+func (s *fileService) OnStop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// purgeUploadSessions deletes the expired upload sessions every interval until ctx is cancelled
+func (s *fileService) purgeUploadSessions(ctx context.Context, interval time.Duration) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if deleted, err := s.PurgeExpiredUploads(ctx); err != nil {
+				logger.Warn("Failed to delete expired upload sessions after deleting %d: %v", deleted, err)
+			} else if deleted > 0 {
+				logger.Info("Deleted %d expired upload sessions", deleted)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// CreateUploadSession records a pending file and the session its content is
+// uploaded through
+func (s *fileService) CreateUploadSession(ctx context.Context, file *model.File) (*model.File, *model.UploadSession, error) {
+	if err := s.prepare(ctx, file); err != nil {
+		return nil, nil, err
+	}
+	result, err := s.create(ctx, file)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := repo.Create(ctx, &model.UploadSession{
+		FileID:    result.ID,
+		ExpiresAt: s.Clock.Now().Add(s.Config.Files.Uploads.SessionTTL),
+	})
+	if err != nil {
+		logger.Error("Failed to create the upload session of file %d: %v", result.ID, err)
+		s.deleteRecord(ctx, result)
+		return nil, nil, err
+	}
+	logger.Info("Upload session %d created for file %d", session.ID, result.ID)
+	return result, session, nil
+}
+
+// GetUploadSession retrieves an upload session with its file
+func (s *fileService) GetUploadSession(ctx context.Context, id uint) (*model.File, *model.UploadSession, error) {
+	return s.getUploadSession(ctx, id, model.ActionRead)
+}
+
+// ResolveUploadSessionID returns the ID of the upload session with a public ID
+func (s *fileService) ResolveUploadSessionID(ctx context.Context, publicID string) (uint, error) {
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return 0, err
+	}
+	session, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrUploadSessionNotFound
+		}
+		return 0, err
+	}
+	return session.ID, nil
+}
+
+// AppendUploadChunk stores the chunk read from r as a part of the session
+// when sent at its current offset, and advances the offset. The chunk is read
+// in memory up to the maximum chunk size before anything is stored, so that a
+// chunk interrupted by the network leaves the session unchanged. Parts have
+// unique keys: of concurrent chunks at the same offset the last one recorded
+// wins and the others are left to the cleanup of the session.
+func (s *fileService) AppendUploadChunk(ctx context.Context, id uint, offset int64, r io.Reader) (*model.File, *model.UploadSession, error) {
+	file, session, err := s.getUploadSession(ctx, id, model.ActionUpdate)
+	if err != nil {
+		return nil, nil, err
+	}
+	if session.Expired(s.Clock.Now()) {
+		return nil, nil, model.ErrUploadSessionExpired
+	}
+	if offset != session.Offset {
+		return nil, nil, model.ErrUploadOffsetMismatch
+	}
+
+	maxChunkSize := s.Config.Files.Uploads.MaxChunkSize
+	data, err := io.ReadAll(io.LimitReader(r, maxChunkSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if int64(len(data)) > maxChunkSize {
+		return nil, nil, model.ErrUploadChunkTooLarge
+	}
+	if session.Offset+int64(len(data)) > file.Size {
+		return nil, nil, model.ErrUploadChunkOverflow
+	}
+	if len(data) == 0 {
+		return file, session, nil
+	}
+
+	part := model.UploadPart{
+		Offset:     offset,
+		Size:       int64(len(data)),
+		StorageKey: fmt.Sprintf("%s%020d-%s", partsPrefix(file), offset, uuid.NewString()[:8]),
+	}
+	if _, err := s.Storage.Put(ctx, part.StorageKey, bytes.NewReader(data)); err != nil {
+		logger.Error("Failed to store a chunk of file %d at offset %d: %v", file.ID, offset, err)
+		return nil, nil, err
+	}
+	session.Parts = append(session.Parts, part)
+	session.Offset += part.Size
+
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return nil, nil, err
+	}
+	result, err := repo.Update(ctx, session)
+	if err != nil {
+		logger.Error("Failed to update upload session %d: %v", session.ID, err)
+		return nil, nil, err
+	}
+	return file, result, nil
+}
+
+// FinalizeUploadSession assembles the parts of a fully received session into
+// the object of its file while hashing them, checks the SHA-256 sent by the
+// client, then deletes the session and marks the file uploaded. A mismatching
+// checksum leaves the session for the client to abort.
+func (s *fileService) FinalizeUploadSession(ctx context.Context, id uint, checksum string) (*model.File, error) {
+	checksum = strings.ToLower(checksum)
+	if sum, err := hex.DecodeString(checksum); err != nil || len(sum) != sha256.Size {
+		return nil, model.ErrUploadChecksumInvalid
+	}
+	file, session, err := s.getUploadSession(ctx, id, model.ActionUpdate)
+	if err != nil {
+		return nil, err
+	}
+	if session.Expired(s.Clock.Now()) {
+		return nil, model.ErrUploadSessionExpired
+	}
+	if session.Offset != file.Size {
+		return nil, model.ErrUploadIncomplete
+	}
+
+	parts := &partsReader{ctx: ctx, storage: s.Storage, parts: session.Parts}
+	defer parts.Close()
+	hash := sha256.New()
+	content := io.TeeReader(parts, hash)
+	if _, err := s.Storage.Put(ctx, file.StorageKey, content); err != nil {
+		logger.Error("Failed to assemble the upload of file %d: %v", file.ID, err)
+		return nil, err
+	}
+	if hex.EncodeToString(hash.Sum(nil)) != checksum {
+		if err := s.Storage.Delete(ctx, file.StorageKey); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Warn("Failed to delete the mismatching upload of file %d: %v", file.ID, err)
+		}
+		return nil, model.ErrUploadChecksumMismatch
+	}
+
+	if err := s.deleteUploadSession(ctx, file, session); err != nil {
+		return nil, err
+	}
+	file.ChecksumSHA256 = checksum
+	return s.markUploaded(ctx, file)
+}
+
+// AbortUploadSession deletes an upload session, its parts and its pending file
+func (s *fileService) AbortUploadSession(ctx context.Context, id uint) error {
+	file, session, err := s.getUploadSession(ctx, id, model.ActionDelete)
+	if err != nil {
+		return err
+	}
+	if err := s.deleteUploadSession(ctx, file, session); err != nil {
+		return err
+	}
+	if err := s.deleteRecord(ctx, file); err != nil {
+		return err
+	}
+	s.publish(ctx, EventTypeFileDeleted, file)
+	return nil
+}
+
+// PurgeExpiredUploads deletes the expired upload sessions with their parts and
+// pending files, and returns how many were deleted
+func (s *fileService) PurgeExpiredUploads(ctx context.Context) (int, error) {
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return 0, err
+	}
+	files, err := s.files()
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for ctx.Err() == nil {
+		sessions, err := repo.List(ctx, datastore.ListOptions{
+			Size:   uploadPurgeBatchSize,
+			SortBy: "expires_at",
+			Ranges: map[string]datastore.Range{"expires_at": {To: s.Clock.Now()}},
+		})
+		if err != nil || len(sessions) == 0 {
+			return deleted, err
+		}
+		for _, session := range sessions {
+			file, err := files.Get(ctx, session.FileID)
+			if errors.Is(err, datastore.ErrNotFound) {
+				file, err = &model.File{BaseModel: model.BaseModel{ID: session.FileID}}, nil
+			}
+			if err != nil {
+				return deleted, err
+			}
+			if err := s.deleteUploadSession(ctx, file, session); err != nil {
+				return deleted, err
+			}
+			if file.Status == model.FileStatusPending && file.StorageKey != "" {
+				if err := s.deleteRecord(ctx, file); err != nil {
+					return deleted, err
+				}
+			}
+			deleted++
+		}
+	}
+	return deleted, ctx.Err()
+}
+
+// getUploadSession returns an upload session and its file after authorizing
+// action on the file; sessions of files out of scope of ctx are not found
+func (s *fileService) getUploadSession(ctx context.Context, id uint, action string) (*model.File, *model.UploadSession, error) {
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return nil, nil, err
+	}
+	session, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, nil, model.ErrUploadSessionNotFound
+		}
+		logger.Error("Failed to get upload session %d: %v", id, err)
+		return nil, nil, err
+	}
+	file, err := s.get(ctx, session.FileID, action)
+	if err != nil {
+		if errors.Is(err, model.ErrFileNotFound) {
+			return nil, nil, model.ErrUploadSessionNotFound
+		}
+		return nil, nil, err
+	}
+	return file, session, nil
+}
+
+// deleteUploadSessions deletes the upload sessions of file and their parts
+func (s *fileService) deleteUploadSessions(ctx context.Context, file *model.File) error {
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return err
+	}
+	sessions, err := repo.List(ctx, datastore.ListOptions{Filters: map[string]interface{}{"file_id": file.ID}})
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		if err := s.deleteUploadSession(ctx, file, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteUploadSession deletes the parts stored for the session of file,
+// including those of chunks it did not record, then the session
+func (s *fileService) deleteUploadSession(ctx context.Context, file *model.File, session *model.UploadSession) error {
+	keys := make([]string, 0, len(session.Parts))
+	for _, part := range session.Parts {
+		keys = append(keys, part.StorageKey)
+	}
+	if file.StorageKey != "" {
+		objects, err := s.Storage.List(ctx, partsPrefix(file))
+		if err != nil {
+			return err
+		}
+		for _, object := range objects {
+			keys = append(keys, object.Key)
+		}
+	}
+	for _, key := range keys {
+		if err := s.Storage.Delete(ctx, key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+			logger.Error("Failed to delete part %s of upload session %d: %v", key, session.ID, err)
+			return err
+		}
+	}
+
+	repo, err := s.uploadSessions()
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, session.ID); err != nil && err != datastore.ErrNotFound {
+		logger.Error("Failed to delete upload session %d: %v", session.ID, err)
+		return err
+	}
+	return nil
+}
+
+// deleteRecord deletes the record of a file whose content is not stored
+func (s *fileService) deleteRecord(ctx context.Context, file *model.File) error {
+	repo, err := s.files()
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, file.ID); err != nil && err != datastore.ErrNotFound {
+		logger.Error("Failed to delete file %d: %v", file.ID, err)
+		return err
+	}
+	return nil
+}
+
+// partsPrefix returns the prefix of the storage keys of the upload parts of file
+func partsPrefix(file *model.File) string {
+	return file.StorageKey + ".parts/"
+}
+
+// partsReader reads the upload parts one after the other, opening each one
+// once the previous is read
+type partsReader struct {
+	ctx     context.Context
+	storage storage.Storage
+	parts   model.UploadParts
+	current io.ReadCloser
+}
+
+// Read implements io.Reader
+func (r *partsReader) Read(p []byte) (int, error) {
+	for {
+		if r.current == nil {
+			if len(r.parts) == 0 {
+				return 0, io.EOF
+			}
+			part, err := r.storage.Get(r.ctx, r.parts[0].StorageKey)
+			if err != nil {
+				return 0, fmt.Errorf("failed to read part at offset %d: %w", r.parts[0].Offset, err)
+			}
+			r.current = part
+			r.parts = r.parts[1:]
+		}
+		n, err := r.current.Read(p)
+		if err == io.EOF {
+			r.current.Close()
+			r.current = nil
+			if n == 0 {
+				continue
+			}
+			err = nil
+		}
+		return n, err
+	}
+}
+
+// Close closes the part being read
+func (r *partsReader) Close() error {
+	if r.current == nil {
+		return nil
+	}
+	err := r.current.Close()
+	r.current = nil
+	return err
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
 )
 
 // ApplicationServiceInterface defines the interface for application service
@@ -11,14 +12,55 @@ type ApplicationServiceInterface interface {
 	CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error)
 	GetApplicationByID(ctx context.Context, id uint) (*model.Application, error)
 	GetApplicationByName(ctx context.Context, name string) (*model.Application, error)
+	// ResolveApplicationID returns the ID of the application with a public ID;
+	// access is checked by the operations given the ID
+	ResolveApplicationID(ctx context.Context, publicID string) (uint, error)
 	ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error)
+	// ListApplicationsByTags lists the applications matching every term of the selector
+	ListApplicationsByTags(ctx context.Context, selector model.TagSelector, page, pageSize int) ([]*model.Application, int64, error)
+	// QueryApplications lists the applications matching every condition of the query
+	QueryApplications(ctx context.Context, query model.ApplicationQuery) ([]*model.Application, int64, error)
+	// ExistingApplicationNames returns the names already used by applications of the organization of ctx
+	ExistingApplicationNames(ctx context.Context, names []string) ([]string, error)
+	AddApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error)
+	RemoveApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error)
 	UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error)
+	// PatchApplication applies a partial update to the current state of an application
+	PatchApplication(ctx context.Context, id uint, patch *model.ApplicationPatch) (*model.Application, error)
 	DeleteApplication(ctx context.Context, id uint) error
+	// BatchDeleteApplications deletes applications in one transaction and reports those not deleted;
+	// without opts.ContinueOnError none are deleted when any cannot be
+	BatchDeleteApplications(ctx context.Context, ids []uint, opts BatchDeleteOptions) ([]BatchDeleteFailure, error)
+	// ImportApplications creates each valid application and reports the others; with dryRun nothing is created
+	ImportApplications(ctx context.Context, apps []*model.Application, dryRun bool) ([]ImportFailure, error)
+	// ListApplicationRevisions lists the revisions of an application, newest first
+	ListApplicationRevisions(ctx context.Context, id uint, page, pageSize int) ([]*model.ApplicationRevision, int64, error)
+	// RollbackApplication restores an application to a revision, recording a new revision
+	RollbackApplication(ctx context.Context, id uint, revision int) (*model.Application, error)
 }
 
-// InitServiceBean convert service interface to bean type
+// InitServiceBean convert service interface to bean type, leaving out the services of disabled modules
 func InitServiceBean() []interface{} {
-	return []interface{}{
+	return module.Filter([]interface{}{
 		NewApplicationServiceForDI(),
-	}
+		NewApplicationVariableServiceForDI(),
+		NewOperationServiceForDI(),
+		NewApplicationBackupServiceForDI(),
+		NewFileServiceForDI(),
+		NewOperationEventServiceForDI(),
+		NewEventLogServiceForDI(),
+		NewFeatureFlagServiceForDI(),
+		NewExperimentServiceForDI(),
+		NewMailServiceForDI(),
+		NewNotificationServiceForDI(),
+		NewPolicyServiceForDI(),
+		NewPartnerServiceForDI(),
+		NewSessionServiceForDI(),
+		NewUserPreferenceServiceForDI(),
+		NewOrganizationServiceForDI(),
+		NewAuthorizationServiceForDI(),
+		NewInvitationServiceForDI(),
+		NewUserServiceForDI(),
+		// gen:service-beans
+	})
 }
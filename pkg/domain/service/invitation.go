@@ -0,0 +1,454 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/monitor"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Invitation event types
+const (
+	EventTypeInvitationCreated  = "invitation.created"
+	EventTypeInvitationAccepted = "invitation.accepted"
+	EventTypeInvitationRevoked  = "invitation.revoked"
+)
+
+// MailTemplateInvitation is the template of invitation emails
+const MailTemplateInvitation = "invitation"
+
+// invitationAdminRole is the role managing every invitation, and the only one
+// inviting users with a role other than invitations.default_role or with permissions
+const invitationAdminRole = "admin"
+
+// InvitationChanged is the payload of the invitation events
+type InvitationChanged struct {
+	ID        uint   `json:"id"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
+	OrgID     uint   `json:"org_id,omitempty"`
+	OrgRole   string `json:"org_role,omitempty"`
+	InvitedBy string `json:"invited_by"`
+}
+
+// InvitationServiceInterface defines the interface for invitations onboarding users by email
+type InvitationServiceInterface interface {
+	// CreateInvitation stores an invitation and mails its token to the invited
+	// user. The token is returned only when mail is disabled, for the inviter
+	// to hand it over; it cannot be retrieved afterwards.
+	CreateInvitation(ctx context.Context, invitation *model.Invitation) (*model.Invitation, string, error)
+	// ListInvitations lists the invitations of an organization, or all
+	// invitations when orgID is 0, most recent first. A non-empty status only
+	// lists the invitations with that status, expired included.
+	ListInvitations(ctx context.Context, orgID uint, status string) ([]*model.Invitation, error)
+	// ResolveInvitationID returns the ID of the invitation with a public ID
+	ResolveInvitationID(ctx context.Context, publicID string) (uint, error)
+	// RevokeInvitation revokes a pending invitation, invalidating its token
+	RevokeInvitation(ctx context.Context, id uint) error
+	// AcceptInvitation accepts the invitation of token and adds the invited
+	// user, identified by their email, to its organization. Callers create or
+	// authenticate that user in the same unit of work, as
+	// UserServiceInterface.AcceptInvitation and RegisterUser do.
+	AcceptInvitation(ctx context.Context, token string) (*model.Invitation, error)
+}
+
+// invitationService 内部实现，支持依赖注入
+type invitationService struct {
+	Store         datastore.DatastoreInterface `inject:"datastore"`
+	UnitOfWork    datastore.UnitOfWorkManager  `inject:"unit_of_work"`
+	EventBus      event.Bus                    `inject:"eventbus"`
+	Config        *config.Config               `inject:"config"`
+	Clock         clock.Clock                  `inject:"clock"`
+	Mail          MailServiceInterface         `inject:""`
+	Organizations OrganizationServiceInterface `inject:""`
+}
+
+// NewInvitationServiceForDI 创建支持依赖注入的邀请服务实例
+func NewInvitationServiceForDI() InvitationServiceInterface {
+	return &invitationService{}
+}
+
+// invitationMail is the data of the invitation email
+type invitationMail struct {
+	Email     string
+	InvitedBy string
+	// Organization is the name of the organization the user joins, if any
+	Organization string
+	Role         string
+	Token        string
+	// AcceptURL links to the accept page with the token, empty when not configured
+	AcceptURL string
+	ExpiresAt time.Time
+}
+
+// invitations returns the invitation repository, within the unit of work carried by ctx
+func (s *invitationService) invitations(ctx context.Context) (datastore.Repository[*model.Invitation], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.Invitation](uow.Store())
+	}
+	return datastore.NewRepository[*model.Invitation](s.Store)
+}
+
+// CreateInvitation stores a pending invitation with a new token and mails it
+func (s *invitationService) CreateInvitation(ctx context.Context, invitation *model.Invitation) (*model.Invitation, string, error) {
+	invitation.Email = model.NormalizeEmail(invitation.Email)
+	if invitation.Role == "" {
+		invitation.Role = s.Config.Invitations.DefaultRole
+	}
+	if invitation.OrgID == 0 {
+		invitation.OrgRole = ""
+	}
+	if err := invitation.Validate(); err != nil {
+		return nil, "", err
+	}
+	if err := s.authorize(ctx, invitation.OrgID, s.presetsAccess(invitation), invitation.OrgRole); err != nil {
+		return nil, "", err
+	}
+
+	var orgName string
+	if invitation.OrgID != 0 {
+		org, err := s.Organizations.GetOrganization(ctx, invitation.OrgID)
+		if err != nil {
+			return nil, "", err
+		}
+		orgName = org.Name
+	}
+
+	nonce, err := newTokenNonce()
+	if err != nil {
+		return nil, "", err
+	}
+	invitation.InvitedBy = actorFromContext(ctx)
+	invitation.Status = model.InvitationStatusPending
+	invitation.Nonce = nonce
+	// Tokens carry the expiry in Unix seconds
+	invitation.ExpiresAt = s.Clock.Now().Add(s.Config.Invitations.TTL).Truncate(time.Second)
+
+	repo, err := s.invitations(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := repo.Create(ctx, invitation)
+	if err != nil {
+		logger.Error("Failed to create invitation: %v", err)
+		return nil, "", err
+	}
+	logger.Info("Invitation %d created for %s by %s", result.ID, result.Email, result.InvitedBy)
+	monitor.RecordInvitation("created")
+	s.publish(ctx, EventTypeInvitationCreated, result)
+
+	token := s.token(result)
+	_, err = s.Mail.Send(ctx, &Mail{
+		To:       []string{result.Email},
+		Template: MailTemplateInvitation,
+		Data: invitationMail{
+			Email:        result.Email,
+			InvitedBy:    result.InvitedBy,
+			Organization: orgName,
+			Role:         result.Role,
+			Token:        token,
+			AcceptURL:    s.acceptURL(token),
+			ExpiresAt:    result.ExpiresAt,
+		},
+	})
+	switch {
+	case errors.Is(err, ErrMailDisabled):
+		logger.Warn("Mail is disabled, invitation %d must be handed over to %s", result.ID, result.Email)
+		return result, token, nil
+	case err != nil:
+		// The inviter may revoke the invitation and invite again
+		logger.Error("Failed to mail invitation %d: %v", result.ID, err)
+		return nil, "", err
+	}
+	return result, "", nil
+}
+
+// ListInvitations lists the invitations the subject of ctx manages
+func (s *invitationService) ListInvitations(ctx context.Context, orgID uint, status string) ([]*model.Invitation, error) {
+	if err := s.authorize(ctx, orgID, false, ""); err != nil {
+		return nil, err
+	}
+	repo, err := s.invitations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filters := map[string]interface{}{}
+	if orgID != 0 {
+		filters["org_id"] = orgID
+	}
+	if status == model.InvitationStatusExpired {
+		filters["status"] = model.InvitationStatusPending
+	} else if status != "" {
+		filters["status"] = status
+	}
+	invitations, err := repo.List(ctx, datastore.ListOptions{SortBy: "created_at", SortDesc: true, Filters: filters})
+	if err != nil {
+		logger.Error("Failed to list invitations: %v", err)
+		return nil, err
+	}
+	if status == "" {
+		return invitations, nil
+	}
+
+	now := s.Clock.Now()
+	result := make([]*model.Invitation, 0, len(invitations))
+	for _, invitation := range invitations {
+		if invitation.StatusAt(now) == status {
+			result = append(result, invitation)
+		}
+	}
+	return result, nil
+}
+
+// ResolveInvitationID returns the ID of the invitation with a public ID
+func (s *invitationService) ResolveInvitationID(ctx context.Context, publicID string) (uint, error) {
+	repo, err := s.invitations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	invitation, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrInvitationNotFound
+		}
+		return 0, err
+	}
+	return invitation.ID, nil
+}
+
+// RevokeInvitation marks a pending invitation revoked
+func (s *invitationService) RevokeInvitation(ctx context.Context, id uint) error {
+	repo, err := s.invitations(ctx)
+	if err != nil {
+		return err
+	}
+	invitation, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrInvitationNotFound
+		}
+		return err
+	}
+	if err := s.authorize(ctx, invitation.OrgID, s.presetsAccess(invitation), invitation.OrgRole); err != nil {
+		return err
+	}
+	if invitation.Status != model.InvitationStatusPending {
+		return model.ErrInvitationNotPending
+	}
+
+	now := s.Clock.Now()
+	invitation.Status = model.InvitationStatusRevoked
+	invitation.RevokedAt = &now
+	if _, err := repo.Update(ctx, invitation); err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrInvitationNotFound
+		}
+		logger.Error("Failed to revoke invitation %d: %v", id, err)
+		return err
+	}
+
+	logger.Info("Invitation %d revoked by %s", id, actorFromContext(ctx))
+	monitor.RecordInvitation("revoked")
+	s.publish(ctx, EventTypeInvitationRevoked, invitation)
+	return nil
+}
+
+// AcceptInvitation verifies the token, marks its invitation accepted and adds the
+// membership of the invited user in one unit of work
+func (s *invitationService) AcceptInvitation(ctx context.Context, token string) (*model.Invitation, error) {
+	id, expires, signature, ok := parseSignedToken(token)
+	if !ok {
+		return nil, model.ErrInvitationTokenInvalid
+	}
+
+	var result *model.Invitation
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.invitations(ctx)
+		if err != nil {
+			return err
+		}
+		invitation, err := repo.Get(ctx, id)
+		if err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrInvitationTokenInvalid
+			}
+			return err
+		}
+		if expires != invitation.ExpiresAt.Unix() || !hmac.Equal([]byte(signature), []byte(s.sign(invitation))) {
+			return model.ErrInvitationTokenInvalid
+		}
+
+		now := s.Clock.Now()
+		switch invitation.StatusAt(now) {
+		case model.InvitationStatusPending:
+		case model.InvitationStatusExpired:
+			monitor.RecordInvitation(model.InvitationStatusExpired)
+			return model.ErrInvitationExpired
+		default:
+			return model.ErrInvitationNotPending
+		}
+
+		invitation.Status = model.InvitationStatusAccepted
+		invitation.AcceptedBy = invitation.Email
+		invitation.AcceptedAt = &now
+		if result, err = repo.Update(ctx, invitation); err != nil {
+			return err
+		}
+
+		if invitation.OrgID != 0 {
+			_, err := s.Organizations.AddMember(ctx, &model.OrganizationMember{
+				OrgID:  invitation.OrgID,
+				UserID: invitation.AcceptedBy,
+				Role:   invitation.OrgRole,
+			})
+			// Users already in the organization keep their role
+			if err != nil && !errors.Is(err, model.ErrOrganizationMemberExists) {
+				return err
+			}
+		}
+
+		uow.Publish(event.NewEvent(EventTypeInvitationAccepted, invitationChanged(invitation)))
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to accept invitation %d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	logger.Info("Invitation %d accepted by %s", result.ID, result.AcceptedBy)
+	monitor.RecordInvitationAccepted(result.AcceptedAt.Sub(result.CreatedAt.Time))
+	return result, nil
+}
+
+// authorize checks that the subject of ctx manages the invitations of orgID
+// with orgRole. Admins manage every invitation; the admins of an organization
+// manage its invitations that preset no access, and only its owners invite
+// owners. Contexts without a subject act on behalf of the system.
+func (s *invitationService) authorize(ctx context.Context, orgID uint, presetsAccess bool, orgRole string) error {
+	subject, ok := model.SubjectFromContext(ctx)
+	if !ok || subject.Role == invitationAdminRole {
+		return nil
+	}
+	if orgID == 0 || presetsAccess {
+		return model.ErrInvitationForbidden
+	}
+
+	member, err := s.Organizations.GetMembership(ctx, orgID, subject.UserID)
+	if err != nil {
+		if errors.Is(err, model.ErrOrganizationMemberNotFound) {
+			return model.ErrInvitationForbidden
+		}
+		return err
+	}
+	least := model.OrganizationRoleAdmin
+	if orgRole == model.OrganizationRoleOwner {
+		least = model.OrganizationRoleOwner
+	}
+	if !model.OrganizationRoleAtLeast(member.Role, least) {
+		return model.ErrInvitationForbidden
+	}
+	return nil
+}
+
+// presetsAccess reports whether an invitation grants a role other than the
+// default one, or permissions
+func (s *invitationService) presetsAccess(invitation *model.Invitation) bool {
+	return invitation.Role != s.Config.Invitations.DefaultRole || len(invitation.Permissions) > 0
+}
+
+// token returns the token of an invitation: <id>.<expiry in Unix seconds>.<signature>
+func (s *invitationService) token(invitation *model.Invitation) string {
+	return fmt.Sprintf("%d.%d.%s", invitation.ID, invitation.ExpiresAt.Unix(), s.sign(invitation))
+}
+
+// sign returns the HMAC-SHA256 of the ID, expiry and nonce of an invitation
+func (s *invitationService) sign(invitation *model.Invitation) string {
+	secret := s.Config.Invitations.Secret
+	if secret == "" {
+		secret = s.Config.Security.JWTSecret
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.%d.%s", invitation.ID, invitation.ExpiresAt.Unix(), invitation.Nonce)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// acceptURL returns the link of the accept page for token, empty when invitations.accept_url is not set
+func (s *invitationService) acceptURL(token string) string {
+	return tokenLink(s.Config.Invitations.AcceptURL, token)
+}
+
+// tokenLink returns link with token in its token query parameter, empty when link is
+func tokenLink(link, token string) string {
+	if link == "" {
+		return ""
+	}
+	separator := "?"
+	if strings.Contains(link, "?") {
+		separator = "&"
+	}
+	return link + separator + "token=" + url.QueryEscape(token)
+}
+
+// publish publishes an invitation event
+func (s *invitationService) publish(ctx context.Context, eventType string, invitation *model.Invitation) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(eventType, invitationChanged(invitation)))
+	}
+}
+
+// invitationChanged returns the event payload of an invitation
+func invitationChanged(invitation *model.Invitation) InvitationChanged {
+	return InvitationChanged{
+		ID:        invitation.ID,
+		Email:     invitation.Email,
+		Role:      invitation.Role,
+		OrgID:     invitation.OrgID,
+		OrgRole:   invitation.OrgRole,
+		InvitedBy: invitation.InvitedBy,
+	}
+}
+
+// newTokenNonce generates the random part of an invitation or email verification token
+func newTokenNonce() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// parseSignedToken splits an invitation or email verification token, <id>.<expiry
+// in Unix seconds>.<signature>, into the ID and expiry of its entity and its signature
+func parseSignedToken(token string) (uint, int64, string, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[2] == "" {
+		return 0, 0, "", false
+	}
+	id, err := strconv.ParseUint(parts[0], 10, 0)
+	if err != nil || id == 0 {
+		return 0, 0, "", false
+	}
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, "", false
+	}
+	return uint(id), expires, parts[2], true
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/mailer"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Mail templates sent by the built-in notifications
+const (
+	MailTemplateApplicationDeleted = "application_deleted"
+	MailTemplateUserRegistered     = "user_registered"
+)
+
+// ErrMailDisabled is returned when sending mail while mail.enabled is off
+var ErrMailDisabled = errors.New("mail is disabled")
+
+// Mail is an email rendered from a template
+type Mail struct {
+	To       []string
+	Template string
+	// Language of the template, defaults to mail.language
+	Language string
+	Data     interface{}
+}
+
+// MailServiceInterface defines the interface for sending email
+type MailServiceInterface interface {
+	// Send renders the mail and delivers it in the background as an operation,
+	// retrying failed attempts with exponential backoff up to mail.max_attempts
+	Send(ctx context.Context, mail *Mail) (*model.Operation, error)
+}
+
+// mailDeliveryResult is the result of a completed email delivery operation
+type mailDeliveryResult struct {
+	Template   string   `json:"template"`
+	Recipients []string `json:"recipients"`
+	Attempts   int      `json:"attempts"`
+}
+
+// applicationDeletedMail is the data of the application deleted notification
+type applicationDeletedMail struct {
+	ID        uint
+	Actor     string
+	DeletedAt time.Time
+}
+
+// userRegisteredMail is the data of the user registered notification
+type userRegisteredMail struct {
+	UserID       string
+	Username     string
+	Role         string
+	InvitationID uint
+	RegisteredAt time.Time
+}
+
+// mailService 内部实现，支持依赖注入
+type mailService struct {
+	Config     *config.Config            `inject:"config"`
+	Mailer     mailer.Sender             `inject:"mailer"`
+	Templates  *mailer.Templates         `inject:"mail_templates"`
+	Operations OperationServiceInterface `inject:""`
+	EventBus   event.Bus                 `inject:"eventbus"`
+
+	unsubscribe []func()
+	// stopping is closed on shutdown to abandon the deliveries waiting for a retry
+	stopping chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMailServiceForDI 创建支持依赖注入的邮件服务实例
+func NewMailServiceForDI() MailServiceInterface {
+	return &mailService{stopping: make(chan struct{})}
+}
+
+// OnStart subscribes the configured notifications to their domain events
+func (s *mailService) OnStart(ctx context.Context) error {
+	if s.Config == nil || !s.Config.Mail.Enabled || s.EventBus == nil {
+		return nil
+	}
+	if len(s.Config.Mail.Notifications.ApplicationDeleted) > 0 {
+		s.unsubscribe = append(s.unsubscribe, s.EventBus.Subscribe(EventTypeApplicationDeleted, s.notifyApplicationDeleted))
+	}
+	if len(s.Config.Mail.Notifications.UserRegistered) > 0 {
+		s.unsubscribe = append(s.unsubscribe, s.EventBus.Subscribe(EventTypeUserRegistered, s.notifyUserRegistered))
+	}
+	return nil
+}
+
+// OnStop unsubscribes from the event bus and stops retrying deliveries
+func (s *mailService) OnStop(ctx context.Context) error {
+	for _, unsubscribe := range s.unsubscribe {
+		unsubscribe()
+	}
+	s.stopOnce.Do(func() { close(s.stopping) })
+	return nil
+}
+
+// Send renders the mail and starts its delivery
+func (s *mailService) Send(ctx context.Context, mail *Mail) (*model.Operation, error) {
+	if s.Config == nil || !s.Config.Mail.Enabled || s.Mailer == nil || s.Templates == nil {
+		return nil, ErrMailDisabled
+	}
+	if len(mail.To) == 0 {
+		return nil, mailer.ErrNoRecipients
+	}
+
+	lang := mail.Language
+	if lang == "" {
+		lang = s.Config.Mail.Language
+	}
+	content, err := s.Templates.Render(mail.Template, lang, mail.Data)
+	if err != nil {
+		return nil, err
+	}
+	msg := &mailer.Message{
+		To:      mail.To,
+		Subject: content.Subject,
+		Text:    content.Text,
+		HTML:    content.HTML,
+	}
+
+	op := &model.Operation{Type: model.OperationTypeEmailDelivery}
+	return s.Operations.StartOperation(ctx, op, func(ctx context.Context, progress ProgressFunc) (interface{}, error) {
+		attempts, err := s.deliver(ctx, msg, progress)
+		if err != nil {
+			return nil, err
+		}
+		return &mailDeliveryResult{Template: mail.Template, Recipients: mail.To, Attempts: attempts}, nil
+	})
+}
+
+// deliver sends the message, retrying failed attempts, and returns the number of attempts made
+func (s *mailService) deliver(ctx context.Context, msg *mailer.Message, progress ProgressFunc) (int, error) {
+	maxAttempts := s.Config.Mail.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	backoff := s.Config.Mail.RetryBackoff
+
+	for attempt := 1; ; attempt++ {
+		progress((attempt-1)*100/maxAttempts, fmt.Sprintf("attempt %d of %d", attempt, maxAttempts))
+		err := s.Mailer.Send(ctx, msg)
+		if err == nil {
+			return attempt, nil
+		}
+		if attempt >= maxAttempts {
+			return attempt, fmt.Errorf("mail delivery failed after %d attempt(s): %w", attempt, err)
+		}
+
+		logger.Warn("Mail delivery attempt %d of %d failed, retrying in %s: %v", attempt, maxAttempts, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-s.stopping:
+			return attempt, fmt.Errorf("mail delivery interrupted by server shutdown: %w", err)
+		}
+		backoff *= 2
+	}
+}
+
+// notifyApplicationDeleted mails the configured recipients about a deleted application
+func (s *mailService) notifyApplicationDeleted(ctx context.Context, e event.Event) {
+	deleted, ok := e.Payload.(ApplicationDeleted)
+	if !ok {
+		logger.Warn("Ignoring application deleted event with payload %T", e.Payload)
+		return
+	}
+
+	_, err := s.Send(ctx, &Mail{
+		To:       s.Config.Mail.Notifications.ApplicationDeleted,
+		Template: MailTemplateApplicationDeleted,
+		Data: applicationDeletedMail{
+			ID:        deleted.ID,
+			Actor:     actorFromContext(ctx),
+			DeletedAt: e.Timestamp,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to send application %d deleted notification: %v", deleted.ID, err)
+	}
+}
+
+// notifyUserRegistered mails the configured recipients about a user who signed up
+func (s *mailService) notifyUserRegistered(ctx context.Context, e event.Event) {
+	registered, ok := e.Payload.(UserRegistered)
+	if !ok {
+		logger.Warn("Ignoring user registered event with payload %T", e.Payload)
+		return
+	}
+
+	_, err := s.Send(ctx, &Mail{
+		To:       s.Config.Mail.Notifications.UserRegistered,
+		Template: MailTemplateUserRegistered,
+		Data: userRegisteredMail{
+			UserID:       registered.UserID,
+			Username:     registered.Username,
+			Role:         registered.Role,
+			InvitationID: registered.InvitationID,
+			RegisteredAt: e.Timestamp,
+		},
+	})
+	if err != nil {
+		logger.Error("Failed to send user %s registered notification: %v", registered.UserID, err)
+	}
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/notification"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// NotificationTemplateTest is the template sent to check a notification channel
+const NotificationTemplateTest = "test"
+
+// Notification delivery statuses
+const (
+	NotificationStatusSent        = "sent"
+	NotificationStatusFailed      = "failed"
+	NotificationStatusRateLimited = "rate_limited"
+	NotificationStatusUnavailable = "unavailable"
+)
+
+// ErrNotificationsDisabled is returned when notifying while notification.enabled is off
+var ErrNotificationsDisabled = errors.New("notifications are disabled")
+
+// Notification is a message rendered from a template for each channel of a user
+type Notification struct {
+	Template string
+	Data     interface{}
+	// Channels restricts the channels notified, empty for every enabled channel of the user
+	Channels []string
+}
+
+// NotificationDelivery is the outcome of a notification on one channel
+type NotificationDelivery struct {
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+}
+
+// NotificationServiceInterface defines the interface for notifying users on the
+// channels they enabled and for managing their channel preferences
+type NotificationServiceInterface interface {
+	// Notify sends a notification to the enabled channels of a user and reports
+	// the outcome per channel. Delivery failures are reported, not returned.
+	Notify(ctx context.Context, userID string, n *Notification) ([]NotificationDelivery, error)
+	// ListPreferences lists the channel preferences of a user ordered by channel
+	ListPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error)
+	GetPreference(ctx context.Context, userID, channel string) (*model.NotificationPreference, error)
+	// SetPreference creates or replaces the preference of a user for a channel
+	SetPreference(ctx context.Context, pref *model.NotificationPreference) (*model.NotificationPreference, error)
+	DeletePreference(ctx context.Context, userID, channel string) error
+}
+
+// notificationService 内部实现，支持依赖注入
+type notificationService struct {
+	Store     datastore.DatastoreInterface `inject:"datastore"`
+	Config    *config.Config               `inject:"config"`
+	Notifier  *notification.Notifier       `inject:"notifier"`
+	Templates *notification.Templates      `inject:"notification_templates"`
+	// Preferences supplies the channels and language users chose for every notification
+	Preferences UserPreferenceServiceInterface `inject:""`
+}
+
+// NewNotificationServiceForDI 创建支持依赖注入的通知服务实例
+func NewNotificationServiceForDI() NotificationServiceInterface {
+	return &notificationService{}
+}
+
+// preferences returns the notification preference repository
+func (s *notificationService) preferences() (datastore.Repository[*model.NotificationPreference], error) {
+	return datastore.NewRepository[*model.NotificationPreference](s.Store)
+}
+
+// Notify renders the notification in the language of each channel and sends it
+func (s *notificationService) Notify(ctx context.Context, userID string, n *Notification) ([]NotificationDelivery, error) {
+	if s.Notifier == nil || !s.Notifier.Enabled() || s.Templates == nil {
+		return nil, ErrNotificationsDisabled
+	}
+
+	prefs, err := s.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	channels, language := n.Channels, ""
+	if s.Preferences != nil {
+		userPrefs, err := s.Preferences.GetPreferences(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if len(channels) == 0 {
+			channels = userPrefs.NotificationChannels
+		}
+		language = userPrefs.Language
+	}
+
+	var deliveries []NotificationDelivery
+	for _, pref := range prefs {
+		if !pref.Enabled || (len(channels) > 0 && !containsString(channels, pref.Channel)) {
+			continue
+		}
+		deliveries = append(deliveries, s.deliver(ctx, pref, n, language))
+	}
+	if len(deliveries) == 0 {
+		return nil, model.ErrNotificationChannelsUnavailable
+	}
+	return deliveries, nil
+}
+
+// deliver sends the notification on the channel of pref, in the language of the
+// channel, else the preferred language of the user
+func (s *notificationService) deliver(ctx context.Context, pref *model.NotificationPreference, n *Notification, language string) NotificationDelivery {
+	delivery := NotificationDelivery{Channel: pref.Channel}
+	if !s.Notifier.HasChannel(pref.Channel) {
+		delivery.Status = NotificationStatusUnavailable
+		return delivery
+	}
+
+	lang := pref.Language
+	if lang == "" {
+		lang = language
+	}
+	if lang == "" {
+		lang = s.Config.Notification.Language
+	}
+	content, err := s.Templates.Render(n.Template, pref.Channel, lang, n.Data)
+	if err != nil {
+		logger.Error("Failed to render notification %s for channel %s: %v", n.Template, pref.Channel, err)
+		delivery.Status, delivery.Error = NotificationStatusFailed, err.Error()
+		return delivery
+	}
+
+	err = s.Notifier.Send(ctx, &notification.Message{
+		Channel: pref.Channel,
+		To:      pref.Address,
+		Title:   content.Title,
+		Body:    content.Body,
+		Data:    map[string]interface{}{"template": n.Template},
+	})
+	switch {
+	case err == nil:
+		delivery.Status = NotificationStatusSent
+	case errors.Is(err, notification.ErrRateLimited):
+		logger.Warn("Notification %s to user %s via %s rate limited", n.Template, pref.UserID, pref.Channel)
+		delivery.Status, delivery.Error = NotificationStatusRateLimited, err.Error()
+	default:
+		logger.Error("Failed to send notification %s to user %s via %s: %v", n.Template, pref.UserID, pref.Channel, err)
+		delivery.Status, delivery.Error = NotificationStatusFailed, err.Error()
+	}
+	return delivery
+}
+
+// ListPreferences lists the channel preferences of a user
+func (s *notificationService) ListPreferences(ctx context.Context, userID string) ([]*model.NotificationPreference, error) {
+	repo, err := s.preferences()
+	if err != nil {
+		return nil, err
+	}
+
+	prefs, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:  "channel",
+		Filters: map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		logger.Error("Failed to list notification preferences: %v", err)
+		return nil, err
+	}
+	return prefs, nil
+}
+
+// GetPreference retrieves the preference of a user for a channel
+func (s *notificationService) GetPreference(ctx context.Context, userID, channel string) (*model.NotificationPreference, error) {
+	repo, err := s.preferences()
+	if err != nil {
+		return nil, err
+	}
+
+	pref, err := findPreference(ctx, repo, userID, channel)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotificationPreferenceNotFound
+		}
+		return nil, err
+	}
+	return pref, nil
+}
+
+// SetPreference creates or replaces the preference of a user for a channel
+func (s *notificationService) SetPreference(ctx context.Context, pref *model.NotificationPreference) (*model.NotificationPreference, error) {
+	logger.Info("Setting %s notification preference of user %s", pref.Channel, pref.UserID)
+
+	if err := pref.Validate(); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.preferences()
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := findPreference(ctx, repo, pref.UserID, pref.Channel)
+	switch {
+	case err == datastore.ErrNotFound:
+		result, err := repo.Create(ctx, pref)
+		if err != nil {
+			logger.Error("Failed to create notification preference: %v", err)
+			return nil, err
+		}
+		return result, nil
+	case err != nil:
+		return nil, err
+	}
+
+	pref.ID = existing.ID
+	pref.CreatedAt = existing.CreatedAt
+	result, err := repo.Update(ctx, pref)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrNotificationPreferenceNotFound
+		}
+		logger.Error("Failed to update notification preference: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeletePreference deletes the preference of a user for a channel
+func (s *notificationService) DeletePreference(ctx context.Context, userID, channel string) error {
+	logger.Info("Deleting %s notification preference of user %s", channel, userID)
+
+	repo, err := s.preferences()
+	if err != nil {
+		return err
+	}
+
+	pref, err := findPreference(ctx, repo, userID, channel)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrNotificationPreferenceNotFound
+		}
+		return err
+	}
+
+	if err := repo.Delete(ctx, pref.ID); err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrNotificationPreferenceNotFound
+		}
+		logger.Error("Failed to delete notification preference: %v", err)
+		return err
+	}
+	return nil
+}
+
+// findPreference returns the preference of a user for a channel or datastore.ErrNotFound
+func findPreference(ctx context.Context, repo datastore.Repository[*model.NotificationPreference], userID, channel string) (*model.NotificationPreference, error) {
+	prefs, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"user_id": userID, "channel": channel},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prefs) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return prefs[0], nil
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// operationAdminRole is the role that sees the operations of every user
+const operationAdminRole = "admin"
+
+// ProgressFunc reports the progress of a running operation as a percentage
+// between 0 and 100 with an optional message
+type ProgressFunc func(percent int, message string)
+
+// OperationFunc is the work of an operation. The returned result is stored as
+// JSON on completion; a returned error fails the operation.
+type OperationFunc func(ctx context.Context, progress ProgressFunc) (interface{}, error)
+
+// OperationServiceInterface defines the interface for operation service.
+// Operations run in the background and record their status, progress and outcome.
+type OperationServiceInterface interface {
+	// StartOperation records a pending operation and runs fn in the background.
+	// The operation ID and type are taken from op, an empty ID is generated.
+	StartOperation(ctx context.Context, op *model.Operation, fn OperationFunc) (*model.Operation, error)
+	// GetOperation returns an operation in scope of ctx, see ListOperations
+	GetOperation(ctx context.Context, operationID string) (*model.Operation, error)
+	// ListOperations lists operations newest first, filtered by type and status
+	// when not empty. Users other than admins only see the operations they
+	// started in the organization of ctx, or outside any organization.
+	ListOperations(ctx context.Context, opType, status string, page, pageSize int) ([]*model.Operation, int64, error)
+}
+
+// operationService 内部实现，支持依赖注入
+type operationService struct {
+	Store    datastore.DatastoreInterface `inject:"datastore"`
+	EventBus event.Bus                    `inject:"eventbus"`
+
+	jobs sync.WaitGroup
+}
+
+// NewOperationServiceForDI 创建支持依赖注入的任务服务实例
+func NewOperationServiceForDI() OperationServiceInterface {
+	return &operationService{}
+}
+
+// OnStart marks the operations left unfinished by a previous process as failed
+func (s *operationService) OnStart(ctx context.Context) error {
+	repo, err := s.operations()
+	if err != nil {
+		return err
+	}
+
+	for _, status := range []string{model.OperationStatusPending, model.OperationStatusRunning} {
+		ops, err := repo.List(ctx, datastore.ListOptions{Filters: map[string]interface{}{"status": status}})
+		if err != nil {
+			return err
+		}
+		for _, op := range ops {
+			logger.Warn("Operation %s (%s) was interrupted", op.OperationID, op.Type)
+			s.finish(ctx, repo, op, nil, errors.New("interrupted by server shutdown"))
+		}
+	}
+	return nil
+}
+
+// OnStop waits for the running operations until ctx expires
+func (s *operationService) OnStop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.jobs.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("operations still running: %w", ctx.Err())
+	}
+}
+
+// operations returns the operation repository. Operations are tracked outside
+// of units of work so that their status is visible to pollers immediately.
+func (s *operationService) operations() (datastore.Repository[*model.Operation], error) {
+	return datastore.NewRepository[*model.Operation](s.Store)
+}
+
+// StartOperation records a pending operation and starts its job
+func (s *operationService) StartOperation(ctx context.Context, op *model.Operation, fn OperationFunc) (*model.Operation, error) {
+	repo, err := s.operations()
+	if err != nil {
+		return nil, err
+	}
+
+	if op.OperationID == "" {
+		op.OperationID = uuid.NewString()
+	}
+	op.Status = model.OperationStatusPending
+	op.Progress = 0
+	op.Actor = actorFromContext(ctx)
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		op.OrgID = orgID
+	}
+
+	result, err := repo.Create(ctx, op)
+	if err != nil {
+		logger.Error("Failed to create operation: %v", err)
+		return nil, err
+	}
+	logger.Info("Operation %s (%s) started", result.OperationID, result.Type)
+	s.publish(ctx, result)
+
+	// The job works on its own copy and outlives the request
+	job := *result
+	jobCtx := operationContext(ctx)
+	s.jobs.Add(1)
+	go func() {
+		defer s.jobs.Done()
+		s.run(jobCtx, repo, &job, fn)
+	}()
+
+	return result, nil
+}
+
+// GetOperation retrieves an operation by its operation ID
+func (s *operationService) GetOperation(ctx context.Context, operationID string) (*model.Operation, error) {
+	repo, err := s.operations()
+	if err != nil {
+		return nil, err
+	}
+
+	// Operations out of scope are not found rather than forbidden, so that
+	// their IDs cannot be probed
+	filters := operationScope(ctx)
+	filters["operation_id"] = operationID
+	ops, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: filters,
+	})
+	if err != nil {
+		logger.Error("Failed to get operation: %v", err)
+		return nil, err
+	}
+	if len(ops) == 0 {
+		return nil, model.ErrOperationNotFound
+	}
+	return ops[0], nil
+}
+
+// ListOperations retrieves a paginated list of operations
+func (s *operationService) ListOperations(ctx context.Context, opType, status string, page, pageSize int) ([]*model.Operation, int64, error) {
+	logger.Info("Listing operations: type=%s, status=%s, page=%d, pageSize=%d", opType, status, page, pageSize)
+
+	repo, err := s.operations()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filters := operationScope(ctx)
+	if opType != "" {
+		filters["type"] = opType
+	}
+	if status != "" {
+		filters["status"] = status
+	}
+
+	total, err := repo.Count(ctx, datastore.ListOptions{Filters: filters})
+	if err != nil {
+		logger.Error("Failed to count operations: %v", err)
+		return nil, 0, err
+	}
+
+	ops, err := repo.List(ctx, datastore.ListOptions{
+		Page:     page,
+		Size:     pageSize,
+		SortBy:   "id",
+		SortDesc: true,
+		Filters:  filters,
+	})
+	if err != nil {
+		logger.Error("Failed to list operations: %v", err)
+		return nil, 0, err
+	}
+
+	return ops, total, nil
+}
+
+// operationScope returns the filters restricting operations to those visible
+// to the subject of ctx. Admins and contexts without a user, acting on behalf
+// of the system, see every operation.
+func operationScope(ctx context.Context) map[string]interface{} {
+	filters := map[string]interface{}{}
+	if subject, ok := model.SubjectFromContext(ctx); ok && subject.Role == operationAdminRole {
+		return filters
+	}
+	actor := actorFromContext(ctx)
+	if actor == "" {
+		return filters
+	}
+	filters["actor"] = actor
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		filters["org_id"] = orgID
+	} else {
+		filters["org_id"] = uint(0)
+	}
+	return filters
+}
+
+// run executes the job of an operation and records its outcome
+func (s *operationService) run(ctx context.Context, repo datastore.Repository[*model.Operation], op *model.Operation, fn OperationFunc) {
+	op.Status = model.OperationStatusRunning
+	s.update(ctx, repo, op)
+
+	progress := func(percent int, message string) {
+		if percent < 0 {
+			percent = 0
+		} else if percent > 100 {
+			percent = 100
+		}
+		op.Progress = percent
+		op.Message = message
+		s.update(ctx, repo, op)
+	}
+
+	result, err := func() (result interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("operation panicked: %v", r)
+			}
+		}()
+		return fn(ctx, progress)
+	}()
+	s.finish(ctx, repo, op, result, err)
+}
+
+// finish records the outcome of an operation
+func (s *operationService) finish(ctx context.Context, repo datastore.Repository[*model.Operation], op *model.Operation, result interface{}, jobErr error) {
+	if jobErr == nil && result != nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			jobErr = fmt.Errorf("failed to encode result: %w", err)
+		} else {
+			op.Result = data
+		}
+	}
+
+	now := time.Now()
+	op.CompletedAt = &now
+	if jobErr != nil {
+		if _, ok := jobErr.(*model.DomainError); ok {
+			logger.Warn("Operation %s (%s) failed: %v", op.OperationID, op.Type, jobErr)
+		} else {
+			logger.Error("Operation %s (%s) failed: %v", op.OperationID, op.Type, jobErr)
+		}
+		op.Status = model.OperationStatusFailed
+		op.Error = jobErr.Error()
+		op.Message = ""
+	} else {
+		logger.Info("Operation %s (%s) completed", op.OperationID, op.Type)
+		op.Status = model.OperationStatusCompleted
+		op.Progress = 100
+		op.Message = ""
+	}
+	s.update(ctx, repo, op)
+}
+
+// update stores the status of an operation and publishes it as progress
+func (s *operationService) update(ctx context.Context, repo datastore.Repository[*model.Operation], op *model.Operation) {
+	if _, err := repo.Update(ctx, op); err != nil {
+		logger.Error("Failed to update operation %s: %v", op.OperationID, err)
+	}
+	s.publish(ctx, op)
+}
+
+// publish publishes the status of an operation on the event bus
+func (s *operationService) publish(ctx context.Context, op *model.Operation) {
+	message := op.Message
+	if op.Status == model.OperationStatusFailed {
+		message = op.Error
+	}
+	publishOperationProgress(ctx, s.EventBus, model.OperationProgress{
+		OperationID: op.OperationID,
+		Type:        op.Type,
+		Status:      op.Status,
+		Progress:    op.Progress,
+		Message:     message,
+	})
+}
+
+// operationContext returns a context for a job started by a request. It keeps
+// the user and request IDs for logging and auditing, the active organization
+// scoping the job and the subject it is authorized for, but not the
+// cancellation of the request, nor any request scoped value that stops being
+// valid with it.
+func operationContext(ctx context.Context) context.Context {
+	jobCtx := context.Background()
+	for _, key := range []string{logger.FieldUserID, logger.FieldImpersonatorID, logger.FieldRequestID} {
+		if value := ctx.Value(key); value != nil {
+			jobCtx = context.WithValue(jobCtx, key, value)
+		}
+	}
+	if orgID, ok := model.OrganizationFromContext(ctx); ok {
+		jobCtx = model.WithOrganization(jobCtx, orgID)
+	}
+	if subject, ok := model.SubjectFromContext(ctx); ok {
+		jobCtx = model.WithSubject(jobCtx, subject)
+	}
+	return jobCtx
+}
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// EventTypeOperationProgress is published with a model.OperationProgress payload
+// whenever a long-running operation changes status or makes progress
+const EventTypeOperationProgress = "operation.progress"
+
+const (
+	// maxOperationEvents bounds the events kept per operation; the oldest are dropped first
+	maxOperationEvents = 1000
+	// operationEventRetention is how long the events of a finished operation are kept
+	operationEventRetention = 15 * time.Minute
+)
+
+// OperationEventServiceInterface defines the interface for following the
+// progress of long-running operations
+type OperationEventServiceInterface interface {
+	// Watch returns the events of an operation recorded after the given
+	// sequence number followed by the live ones. The channel is closed once
+	// the operation has finished or ctx is done; ErrOperationFinished is
+	// returned when the operation has finished and no events are left.
+	Watch(ctx context.Context, operationID string, after uint64) (<-chan *model.OperationEvent, error)
+}
+
+// operationEventService records the progress events published on the event
+// bus in memory, numbering them so that reconnecting clients can resume. Only
+// the operations of the current process are visible.
+type operationEventService struct {
+	EventBus event.Bus `inject:"eventbus"`
+
+	mutex       sync.Mutex
+	logs        map[string]*operationLog
+	unsubscribe func()
+}
+
+// operationLog holds the recorded events of one operation
+type operationLog struct {
+	events     []*model.OperationEvent
+	sequence   uint64
+	finishedAt time.Time
+	// changed is closed and replaced whenever an event is recorded
+	changed chan struct{}
+}
+
+// NewOperationEventServiceForDI 创建支持依赖注入的任务事件服务实例
+func NewOperationEventServiceForDI() OperationEventServiceInterface {
+	return &operationEventService{logs: make(map[string]*operationLog)}
+}
+
+// OnStart subscribes to the operation progress events
+func (s *operationEventService) OnStart(ctx context.Context) error {
+	if s.EventBus != nil {
+		s.unsubscribe = s.EventBus.Subscribe(EventTypeOperationProgress, s.handle)
+	}
+	return nil
+}
+
+// OnStop unsubscribes from the event bus
+func (s *operationEventService) OnStop(ctx context.Context) error {
+	if s.unsubscribe != nil {
+		s.unsubscribe()
+	}
+	return nil
+}
+
+// handle records a published progress event
+func (s *operationEventService) handle(ctx context.Context, e event.Event) {
+	progress, ok := e.Payload.(model.OperationProgress)
+	if !ok {
+		logger.Warn("Ignoring operation event with payload %T", e.Payload)
+		return
+	}
+	s.record(progress, e.Timestamp)
+}
+
+// record appends a progress update to the log of its operation and wakes up the watchers
+func (s *operationEventService) record(progress model.OperationProgress, timestamp time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.prune(timestamp)
+
+	log, ok := s.logs[progress.OperationID]
+	if !ok {
+		log = &operationLog{changed: make(chan struct{})}
+		s.logs[progress.OperationID] = log
+	}
+
+	log.sequence++
+	log.events = append(log.events, &model.OperationEvent{
+		OperationProgress: progress,
+		Sequence:          log.sequence,
+		Timestamp:         timestamp,
+	})
+	if len(log.events) > maxOperationEvents {
+		log.events = log.events[len(log.events)-maxOperationEvents:]
+	}
+	if progress.Finished() {
+		log.finishedAt = timestamp
+	}
+
+	close(log.changed)
+	log.changed = make(chan struct{})
+}
+
+// prune drops the logs of operations that finished before the retention period
+func (s *operationEventService) prune(now time.Time) {
+	for id, log := range s.logs {
+		if !log.finishedAt.IsZero() && now.Sub(log.finishedAt) > operationEventRetention {
+			delete(s.logs, id)
+		}
+	}
+}
+
+// since returns the events of an operation after a sequence number, whether
+// the operation has finished and a channel closed on the next event
+func (s *operationEventService) since(operationID string, after uint64) ([]*model.OperationEvent, bool, <-chan struct{}, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	log, ok := s.logs[operationID]
+	if !ok {
+		return nil, false, nil, model.ErrOperationNotFound
+	}
+
+	var events []*model.OperationEvent
+	for _, e := range log.events {
+		if e.Sequence > after {
+			events = append(events, e)
+		}
+	}
+	return events, !log.finishedAt.IsZero(), log.changed, nil
+}
+
+// Watch streams the events of an operation
+func (s *operationEventService) Watch(ctx context.Context, operationID string, after uint64) (<-chan *model.OperationEvent, error) {
+	events, finished, changed, err := s.since(operationID, after)
+	if err != nil {
+		return nil, err
+	}
+	if finished && len(events) == 0 {
+		return nil, model.ErrOperationFinished
+	}
+
+	ch := make(chan *model.OperationEvent)
+	go func() {
+		defer close(ch)
+		for {
+			for _, e := range events {
+				select {
+				case ch <- e:
+					after = e.Sequence
+				case <-ctx.Done():
+					return
+				}
+			}
+			if finished {
+				return
+			}
+
+			select {
+			case <-changed:
+			case <-ctx.Done():
+				return
+			}
+
+			// The log is gone once the retention period of the finished operation expired
+			if events, finished, changed, err = s.since(operationID, after); err != nil {
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// publishOperationProgress publishes a progress update of an operation on the event bus
+func publishOperationProgress(ctx context.Context, bus event.Bus, progress model.OperationProgress) {
+	if bus == nil {
+		return
+	}
+	bus.Publish(ctx, event.NewEvent(EventTypeOperationProgress, progress))
+}
@@ -0,0 +1,491 @@
+package service
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Organization event types
+const (
+	EventTypeOrganizationCreated       = "organization.created"
+	EventTypeOrganizationDeleted       = "organization.deleted"
+	EventTypeOrganizationMemberAdded   = "organization.member_added"
+	EventTypeOrganizationMemberRemoved = "organization.member_removed"
+)
+
+// OrganizationChanged is the payload of the organization events. UserID and Role
+// are set for membership events.
+type OrganizationChanged struct {
+	OrgID  uint   `json:"org_id"`
+	Name   string `json:"name,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Role   string `json:"role,omitempty"`
+}
+
+// OrganizationServiceInterface defines the interface for organizations and the
+// memberships of their users
+type OrganizationServiceInterface interface {
+	// CreateOrganization creates an organization with ownerID as its first owner
+	CreateOrganization(ctx context.Context, org *model.Organization, ownerID string) (*model.Organization, error)
+	GetOrganization(ctx context.Context, id uint) (*model.Organization, error)
+	// ResolveOrganizationID returns the ID of the organization with a public ID
+	ResolveOrganizationID(ctx context.Context, publicID string) (uint, error)
+	// ListOrganizations lists the organizations userID is a member of, with the memberships
+	ListOrganizations(ctx context.Context, userID string) ([]*model.Organization, []*model.OrganizationMember, error)
+	UpdateOrganization(ctx context.Context, org *model.Organization) (*model.Organization, error)
+	// DeleteOrganization deletes an organization and its memberships; organizations
+	// still owning applications are not deleted
+	DeleteOrganization(ctx context.Context, id uint) error
+	// GetMembership returns the membership of a user in an organization
+	GetMembership(ctx context.Context, orgID uint, userID string) (*model.OrganizationMember, error)
+	ListMembers(ctx context.Context, orgID uint) ([]*model.OrganizationMember, error)
+	AddMember(ctx context.Context, member *model.OrganizationMember) (*model.OrganizationMember, error)
+	// UpdateMemberRole changes the role of a member; the last owner cannot be demoted
+	UpdateMemberRole(ctx context.Context, orgID uint, userID, role string) (*model.OrganizationMember, error)
+	// RemoveMember removes a member; the last owner cannot be removed
+	RemoveMember(ctx context.Context, orgID uint, userID string) error
+}
+
+// organizationService 内部实现，支持依赖注入
+type organizationService struct {
+	Store      datastore.DatastoreInterface `inject:"datastore"`
+	UnitOfWork datastore.UnitOfWorkManager  `inject:"unit_of_work"`
+	EventBus   event.Bus                    `inject:"eventbus"`
+}
+
+// NewOrganizationServiceForDI 创建支持依赖注入的组织服务实例
+func NewOrganizationServiceForDI() OrganizationServiceInterface {
+	return &organizationService{}
+}
+
+// organizations returns the organization repository, within the unit of work carried by ctx
+func (s *organizationService) organizations(ctx context.Context) (datastore.Repository[*model.Organization], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.Organization](uow.Store())
+	}
+	return datastore.NewRepository[*model.Organization](s.Store)
+}
+
+// members returns the organization member repository, within the unit of work carried by ctx
+func (s *organizationService) members(ctx context.Context) (datastore.Repository[*model.OrganizationMember], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.OrganizationMember](uow.Store())
+	}
+	return datastore.NewRepository[*model.OrganizationMember](s.Store)
+}
+
+// CreateOrganization creates an organization and the owner membership of its creator in one unit of work
+func (s *organizationService) CreateOrganization(ctx context.Context, org *model.Organization, ownerID string) (*model.Organization, error) {
+	logger.Info("Creating organization %s for user %s", org.Name, ownerID)
+
+	if err := org.Validate(); err != nil {
+		return nil, err
+	}
+	owner := &model.OrganizationMember{UserID: ownerID, Role: model.OrganizationRoleOwner}
+	if err := owner.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result *model.Organization
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.organizations(ctx)
+		if err != nil {
+			return err
+		}
+		existing, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{"name": org.Name}})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return model.ErrOrganizationExists
+		}
+
+		result, err = repo.Create(ctx, org)
+		if err != nil {
+			if err == datastore.ErrDuplicateKey {
+				return model.ErrOrganizationExists
+			}
+			return err
+		}
+
+		members, err := s.members(ctx)
+		if err != nil {
+			return err
+		}
+		owner.OrgID = result.ID
+		if _, err := members.Create(ctx, owner); err != nil {
+			return err
+		}
+
+		// With a schema per tenant, the schema of the organization is created with it
+		if err := datastore.ProvisionTenant(ctx, uow.Store(), result.ID); err != nil {
+			return err
+		}
+
+		uow.Publish(event.NewEvent(EventTypeOrganizationCreated, OrganizationChanged{OrgID: result.ID, Name: result.Name, UserID: ownerID, Role: owner.Role}))
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to create organization: %v", err)
+		}
+		return nil, err
+	}
+
+	logger.Info("Organization created successfully: %d", result.ID)
+	return result, nil
+}
+
+// ResolveOrganizationID returns the ID of the organization with a public ID
+func (s *organizationService) ResolveOrganizationID(ctx context.Context, publicID string) (uint, error) {
+	repo, err := s.organizations(ctx)
+	if err != nil {
+		return 0, err
+	}
+	org, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrOrganizationNotFound
+		}
+		return 0, err
+	}
+	return org.ID, nil
+}
+
+// GetOrganization retrieves an organization by ID
+func (s *organizationService) GetOrganization(ctx context.Context, id uint) (*model.Organization, error) {
+	repo, err := s.organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	org, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrOrganizationNotFound
+		}
+		logger.Error("Failed to get organization: %v", err)
+		return nil, err
+	}
+	return org, nil
+}
+
+// ListOrganizations lists the organizations of a user ordered by ID, with the
+// membership of the user in each
+func (s *organizationService) ListOrganizations(ctx context.Context, userID string) ([]*model.Organization, []*model.OrganizationMember, error) {
+	members, err := s.members(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	memberships, err := members.List(ctx, datastore.ListOptions{
+		SortBy:  "org_id",
+		Filters: map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		logger.Error("Failed to list memberships: %v", err)
+		return nil, nil, err
+	}
+
+	repo, err := s.organizations(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	orgs := make([]*model.Organization, 0, len(memberships))
+	kept := make([]*model.OrganizationMember, 0, len(memberships))
+	for _, membership := range memberships {
+		org, err := repo.Get(ctx, membership.OrgID)
+		if err == datastore.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		orgs = append(orgs, org)
+		kept = append(kept, membership)
+	}
+	return orgs, kept, nil
+}
+
+// UpdateOrganization updates the name and description of an organization
+func (s *organizationService) UpdateOrganization(ctx context.Context, org *model.Organization) (*model.Organization, error) {
+	logger.Info("Updating organization: %d", org.ID)
+
+	if err := org.Validate(); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.organizations(ctx)
+	if err != nil {
+		return nil, err
+	}
+	current, err := repo.Get(ctx, org.ID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	if current.Name != org.Name {
+		existing, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{"name": org.Name}})
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			return nil, model.ErrOrganizationExists
+		}
+	}
+
+	current.Name, current.Description = org.Name, org.Description
+	result, err := repo.Update(ctx, current)
+	if err != nil {
+		switch err {
+		case datastore.ErrNotFound:
+			return nil, model.ErrOrganizationNotFound
+		case datastore.ErrDuplicateKey:
+			return nil, model.ErrOrganizationExists
+		}
+		logger.Error("Failed to update organization: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// DeleteOrganization deletes an organization without applications and its memberships in one unit of work
+func (s *organizationService) DeleteOrganization(ctx context.Context, id uint) error {
+	logger.Info("Deleting organization: %d", id)
+
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		apps, err := datastore.NewRepository[*model.Application](uow.Store())
+		if err != nil {
+			return err
+		}
+		owned, err := apps.Count(ctx, datastore.ListOptions{Filters: map[string]interface{}{"org_id": id}})
+		if err != nil {
+			return err
+		}
+		if owned > 0 {
+			return model.ErrOrganizationNotEmpty
+		}
+
+		repo, err := s.organizations(ctx)
+		if err != nil {
+			return err
+		}
+		if err := repo.Delete(ctx, id); err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrOrganizationNotFound
+			}
+			return err
+		}
+
+		members, err := s.members(ctx)
+		if err != nil {
+			return err
+		}
+		if err := deleteAll(ctx, members, map[string]interface{}{"org_id": id}); err != nil {
+			return err
+		}
+
+		uow.Publish(event.NewEvent(EventTypeOrganizationDeleted, OrganizationChanged{OrgID: id}))
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to delete organization: %v", err)
+		}
+		return err
+	}
+
+	logger.Info("Organization deleted successfully: %d", id)
+	return nil
+}
+
+// GetMembership returns the membership of a user in an organization or model.ErrOrganizationMemberNotFound
+func (s *organizationService) GetMembership(ctx context.Context, orgID uint, userID string) (*model.OrganizationMember, error) {
+	members, err := s.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+	member, err := findMember(ctx, members, orgID, userID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrOrganizationMemberNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// ListMembers lists the members of an organization ordered by user ID
+func (s *organizationService) ListMembers(ctx context.Context, orgID uint) ([]*model.OrganizationMember, error) {
+	members, err := s.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := members.List(ctx, datastore.ListOptions{
+		SortBy:  "user_id",
+		Filters: map[string]interface{}{"org_id": orgID},
+	})
+	if err != nil {
+		logger.Error("Failed to list organization members: %v", err)
+		return nil, err
+	}
+	return result, nil
+}
+
+// AddMember adds a user to an organization
+func (s *organizationService) AddMember(ctx context.Context, member *model.OrganizationMember) (*model.OrganizationMember, error) {
+	logger.Info("Adding user %s to organization %d as %s", member.UserID, member.OrgID, member.Role)
+
+	if err := member.Validate(); err != nil {
+		return nil, err
+	}
+	if _, err := s.GetOrganization(ctx, member.OrgID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.members(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := findMember(ctx, members, member.OrgID, member.UserID); err == nil {
+		return nil, model.ErrOrganizationMemberExists
+	} else if err != datastore.ErrNotFound {
+		return nil, err
+	}
+
+	result, err := members.Create(ctx, member)
+	if err != nil {
+		if err == datastore.ErrDuplicateKey {
+			return nil, model.ErrOrganizationMemberExists
+		}
+		logger.Error("Failed to add organization member: %v", err)
+		return nil, err
+	}
+
+	s.publish(ctx, EventTypeOrganizationMemberAdded, result)
+	return result, nil
+}
+
+// UpdateMemberRole changes the role of a member, keeping at least one owner
+func (s *organizationService) UpdateMemberRole(ctx context.Context, orgID uint, userID, role string) (*model.OrganizationMember, error) {
+	logger.Info("Changing the role of user %s in organization %d to %s", userID, orgID, role)
+
+	if !model.IsOrganizationRole(role) {
+		return nil, model.ErrOrganizationRoleInvalid
+	}
+
+	var result *model.OrganizationMember
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		members, err := s.members(ctx)
+		if err != nil {
+			return err
+		}
+		member, err := keepOwner(ctx, members, orgID, userID, role)
+		if err != nil {
+			return err
+		}
+
+		member.Role = role
+		result, err = members.Update(ctx, member)
+		return err
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to update organization member: %v", err)
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveMember removes a member from an organization, keeping at least one owner
+func (s *organizationService) RemoveMember(ctx context.Context, orgID uint, userID string) error {
+	logger.Info("Removing user %s from organization %d", userID, orgID)
+
+	var removed *model.OrganizationMember
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		members, err := s.members(ctx)
+		if err != nil {
+			return err
+		}
+		member, err := keepOwner(ctx, members, orgID, userID, "")
+		if err != nil {
+			return err
+		}
+
+		if err := members.Delete(ctx, member.ID); err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrOrganizationMemberNotFound
+			}
+			return err
+		}
+		removed = member
+		return nil
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to remove organization member: %v", err)
+		}
+		return err
+	}
+
+	s.publish(ctx, EventTypeOrganizationMemberRemoved, removed)
+	return nil
+}
+
+// keepOwner returns the membership of a user, or model.ErrOrganizationOwnerRequired
+// when changing it to role, empty for a removal, would leave the organization without owners
+func keepOwner(ctx context.Context, members datastore.Repository[*model.OrganizationMember], orgID uint, userID, role string) (*model.OrganizationMember, error) {
+	member, err := findMember(ctx, members, orgID, userID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrOrganizationMemberNotFound
+		}
+		return nil, err
+	}
+	if member.Role != model.OrganizationRoleOwner || role == model.OrganizationRoleOwner {
+		return member, nil
+	}
+
+	owners, err := members.Count(ctx, datastore.ListOptions{
+		Filters: map[string]interface{}{"org_id": orgID, "role": model.OrganizationRoleOwner},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if owners <= 1 {
+		return nil, model.ErrOrganizationOwnerRequired
+	}
+	return member, nil
+}
+
+// publish publishes a membership event on the event bus, when one is registered
+func (s *organizationService) publish(ctx context.Context, eventType string, member *model.OrganizationMember) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(eventType, OrganizationChanged{
+			OrgID:  member.OrgID,
+			UserID: member.UserID,
+			Role:   member.Role,
+		}))
+	}
+}
+
+// findMember returns the membership of a user in an organization or datastore.ErrNotFound
+func findMember(ctx context.Context, repo datastore.Repository[*model.OrganizationMember], orgID uint, userID string) (*model.OrganizationMember, error) {
+	members, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"org_id": orgID, "user_id": userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return members[0], nil
+}
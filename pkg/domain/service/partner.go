@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/signing"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/encryption"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Partner event types
+const (
+	EventTypePartnerCreated       = "partner.created"
+	EventTypePartnerUpdated       = "partner.updated"
+	EventTypePartnerDeleted       = "partner.deleted"
+	EventTypePartnerSecretRotated = "partner.secret_rotated"
+)
+
+// PartnerChanged is the payload of the partner events; it never carries secrets
+type PartnerChanged struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// PartnerServiceInterface defines the interface for the integration partners
+// calling the signed partner routes, and for their signing secrets
+type PartnerServiceInterface interface {
+	// CreatePartner creates a partner with a new secret and returns the
+	// partner and the secret, which cannot be retrieved afterwards
+	CreatePartner(ctx context.Context, partner *model.Partner) (*model.Partner, string, error)
+	GetPartner(ctx context.Context, name string) (*model.Partner, error)
+	ListPartners(ctx context.Context) ([]*model.Partner, error)
+	// UpdatePartner replaces the description and enabled flag of a partner
+	UpdatePartner(ctx context.Context, partner *model.Partner) (*model.Partner, error)
+	DeletePartner(ctx context.Context, name string) error
+	// RotatePartnerSecret replaces the secret of a partner and returns the new
+	// one; the previous secret stays valid for security.signing.rotation_grace
+	RotatePartnerSecret(ctx context.Context, name string) (*model.Partner, string, error)
+	// PartnerSecrets returns the secrets currently verifying the requests of
+	// an enabled partner, the current one first
+	PartnerSecrets(ctx context.Context, name string) ([]string, error)
+}
+
+// partnerService 内部实现，支持依赖注入
+type partnerService struct {
+	Store    datastore.DatastoreInterface `inject:"datastore"`
+	EventBus event.Bus                    `inject:"eventbus"`
+	Config   *config.Config               `inject:"config"`
+}
+
+// NewPartnerServiceForDI 创建支持依赖注入的合作方服务实例
+func NewPartnerServiceForDI() PartnerServiceInterface {
+	return &partnerService{}
+}
+
+// repository returns the partner repository
+func (s *partnerService) repository() (datastore.Repository[*model.Partner], error) {
+	return datastore.NewRepository[*model.Partner](s.Store)
+}
+
+// CreatePartner creates a partner with a new secret
+func (s *partnerService) CreatePartner(ctx context.Context, partner *model.Partner) (*model.Partner, string, error) {
+	logger.Info("Creating partner %s", partner.Name)
+
+	if err := partner.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, "", err
+	}
+	if _, err := findPartner(ctx, repo, partner.Name); err != datastore.ErrNotFound {
+		if err == nil {
+			return nil, "", model.ErrPartnerExists
+		}
+		return nil, "", err
+	}
+
+	secret, encrypted, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	partner.Secret = encrypted
+	partner.PreviousSecret = ""
+	partner.PreviousSecretExpiresAt = nil
+	partner.SecretRotatedAt = nil
+
+	result, err := repo.Create(ctx, partner)
+	if err != nil {
+		if err == datastore.ErrDuplicateKey {
+			return nil, "", model.ErrPartnerExists
+		}
+		logger.Error("Failed to create partner: %v", err)
+		return nil, "", err
+	}
+
+	s.publish(ctx, EventTypePartnerCreated, result)
+	return result, secret, nil
+}
+
+// GetPartner retrieves a partner by name
+func (s *partnerService) GetPartner(ctx context.Context, name string) (*model.Partner, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	partner, err := findPartner(ctx, repo, name)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrPartnerNotFound
+		}
+		return nil, err
+	}
+	return partner, nil
+}
+
+// ListPartners lists the partners ordered by name
+func (s *partnerService) ListPartners(ctx context.Context) ([]*model.Partner, error) {
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+
+	partners, err := repo.List(ctx, datastore.ListOptions{SortBy: "name"})
+	if err != nil {
+		logger.Error("Failed to list partners: %v", err)
+		return nil, err
+	}
+	return partners, nil
+}
+
+// UpdatePartner replaces the description and enabled flag of a partner
+func (s *partnerService) UpdatePartner(ctx context.Context, partner *model.Partner) (*model.Partner, error) {
+	logger.Info("Updating partner %s", partner.Name)
+
+	if err := partner.Validate(); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.GetPartner(ctx, partner.Name)
+	if err != nil {
+		return nil, err
+	}
+	existing.Description = partner.Description
+	existing.Enabled = partner.Enabled
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	result, err := repo.Update(ctx, existing)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrPartnerNotFound
+		}
+		logger.Error("Failed to update partner: %v", err)
+		return nil, err
+	}
+
+	s.publish(ctx, EventTypePartnerUpdated, result)
+	return result, nil
+}
+
+// DeletePartner deletes a partner by name
+func (s *partnerService) DeletePartner(ctx context.Context, name string) error {
+	logger.Info("Deleting partner %s", name)
+
+	partner, err := s.GetPartner(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	if err := repo.Delete(ctx, partner.ID); err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrPartnerNotFound
+		}
+		logger.Error("Failed to delete partner: %v", err)
+		return err
+	}
+
+	s.publish(ctx, EventTypePartnerDeleted, partner)
+	return nil
+}
+
+// RotatePartnerSecret replaces the secret of a partner, keeping the previous one
+// valid for the rotation grace period
+func (s *partnerService) RotatePartnerSecret(ctx context.Context, name string) (*model.Partner, string, error) {
+	logger.Info("Rotating the secret of partner %s", name)
+
+	partner, err := s.GetPartner(ctx, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	secret, encrypted, err := s.newSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	expiresAt := now.Add(s.Config.Security.Signing.RotationGrace)
+	partner.PreviousSecret = partner.Secret
+	partner.PreviousSecretExpiresAt = &expiresAt
+	partner.Secret = encrypted
+	partner.SecretRotatedAt = &now
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := repo.Update(ctx, partner)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, "", model.ErrPartnerNotFound
+		}
+		logger.Error("Failed to rotate partner secret: %v", err)
+		return nil, "", err
+	}
+
+	s.publish(ctx, EventTypePartnerSecretRotated, result)
+	return result, secret, nil
+}
+
+// PartnerSecrets returns the decrypted secrets of an enabled partner
+func (s *partnerService) PartnerSecrets(ctx context.Context, name string) ([]string, error) {
+	partner, err := s.GetPartner(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if !partner.Enabled {
+		return nil, model.ErrPartnerDisabled
+	}
+
+	key, err := s.encryptionKey()
+	if err != nil {
+		return nil, err
+	}
+	secret, err := encryption.Decrypt(partner.Secret, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret of partner %s: %w", name, err)
+	}
+	secrets := []string{secret}
+
+	if partner.PreviousSecret != "" && partner.PreviousSecretExpiresAt != nil && time.Now().Before(*partner.PreviousSecretExpiresAt) {
+		previous, err := encryption.Decrypt(partner.PreviousSecret, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt previous secret of partner %s: %w", name, err)
+		}
+		secrets = append(secrets, previous)
+	}
+	return secrets, nil
+}
+
+// newSecret generates a secret and returns it in plain text and encrypted
+func (s *partnerService) newSecret() (string, string, error) {
+	key, err := s.encryptionKey()
+	if err != nil {
+		return "", "", err
+	}
+	secret, err := signing.GenerateSecret()
+	if err != nil {
+		return "", "", err
+	}
+	encrypted, err := encryption.Encrypt(secret, key)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to encrypt partner secret: %w", err)
+	}
+	return secret, encrypted, nil
+}
+
+// encryptionKey returns the key used for partner secrets
+func (s *partnerService) encryptionKey() (string, error) {
+	if s.Config == nil || s.Config.Security.EncryptionKey == "" {
+		return "", fmt.Errorf("security.encryption_key is not configured")
+	}
+	return s.Config.Security.EncryptionKey, nil
+}
+
+// publish publishes a partner event on the event bus, when one is registered
+func (s *partnerService) publish(ctx context.Context, eventType string, partner *model.Partner) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(eventType, PartnerChanged{
+			Name:    partner.Name,
+			Enabled: partner.Enabled,
+		}))
+	}
+}
+
+// findPartner returns the partner named name, or datastore.ErrNotFound
+func findPartner(ctx context.Context, repo datastore.Repository[*model.Partner], name string) (*model.Partner, error) {
+	partners, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"name": name},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(partners) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return partners[0], nil
+}
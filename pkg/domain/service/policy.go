@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Policy event types
+const (
+	EventTypePolicyPublished = "policy.published"
+	EventTypePolicyAccepted  = "policy.accepted"
+)
+
+// PolicyPublished is the payload of a policy published event
+type PolicyPublished struct {
+	Policy   string `json:"policy"`
+	Version  int    `json:"version"`
+	Language string `json:"language"`
+}
+
+// PolicyAccepted is the payload of a policy accepted event
+type PolicyAccepted struct {
+	Policy  string `json:"policy"`
+	Version int    `json:"version"`
+	UserID  string `json:"user_id"`
+}
+
+// PolicyServiceInterface defines the interface for versioned policy documents,
+// such as the terms of service, and for the consent of users to them
+type PolicyServiceInterface interface {
+	// PublishPolicy publishes a document of the current version of a policy in
+	// another language, or of the next version, which becomes the current one
+	PublishPolicy(ctx context.Context, doc *model.PolicyDocument) (*model.PolicyDocument, error)
+	// GetCurrentPolicy returns the current version of a policy in lang, falling
+	// back to the default language and then to any published language
+	GetCurrentPolicy(ctx context.Context, name, lang string) (*model.PolicyDocument, error)
+	// ListCurrentPolicies returns the current version of every policy in lang, ordered by name
+	ListCurrentPolicies(ctx context.Context, lang string) ([]*model.PolicyDocument, error)
+	// AcceptPolicy records the consent of a user to the current version of a
+	// policy; accepting a version again returns the existing consent
+	AcceptPolicy(ctx context.Context, consent *model.PolicyConsent) (*model.PolicyConsent, error)
+	// ListConsents lists the consents of a user, newest first
+	ListConsents(ctx context.Context, userID string) ([]*model.PolicyConsent, error)
+	// PendingPolicies returns the names of the published policies whose current
+	// version the user has not accepted, among names
+	PendingPolicies(ctx context.Context, userID string, names []string) ([]string, error)
+}
+
+// policyService 内部实现，支持依赖注入
+type policyService struct {
+	Store    datastore.DatastoreInterface `inject:"datastore"`
+	EventBus event.Bus                    `inject:"eventbus"`
+}
+
+// NewPolicyServiceForDI 创建支持依赖注入的政策服务实例
+func NewPolicyServiceForDI() PolicyServiceInterface {
+	return &policyService{}
+}
+
+// documents returns the policy document repository
+func (s *policyService) documents() (datastore.Repository[*model.PolicyDocument], error) {
+	return datastore.NewRepository[*model.PolicyDocument](s.Store)
+}
+
+// consents returns the policy consent repository
+func (s *policyService) consents() (datastore.Repository[*model.PolicyConsent], error) {
+	return datastore.NewRepository[*model.PolicyConsent](s.Store)
+}
+
+// PublishPolicy publishes a policy document
+func (s *policyService) PublishPolicy(ctx context.Context, doc *model.PolicyDocument) (*model.PolicyDocument, error) {
+	logger.Info("Publishing version %d of policy %s in %s", doc.Version, doc.Name, doc.Language)
+
+	if err := doc.Validate(); err != nil {
+		return nil, err
+	}
+
+	repo, err := s.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentVersions(ctx, repo, []string{doc.Name})
+	if err != nil {
+		return nil, err
+	}
+	if doc.Version != current[doc.Name] && doc.Version != current[doc.Name]+1 {
+		return nil, model.ErrPolicyVersionInvalid
+	}
+
+	result, err := repo.Create(ctx, doc)
+	if err != nil {
+		if err == datastore.ErrDuplicateKey {
+			return nil, model.ErrPolicyDocumentExists
+		}
+		logger.Error("Failed to publish policy document: %v", err)
+		return nil, err
+	}
+
+	s.publish(ctx, event.NewEvent(EventTypePolicyPublished, PolicyPublished{
+		Policy:   result.Name,
+		Version:  result.Version,
+		Language: result.Language,
+	}))
+	return result, nil
+}
+
+// GetCurrentPolicy returns the current version of a policy in lang
+func (s *policyService) GetCurrentPolicy(ctx context.Context, name, lang string) (*model.PolicyDocument, error) {
+	repo, err := s.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.currentVersions(ctx, repo, []string{name})
+	if err != nil {
+		return nil, err
+	}
+	version, ok := current[name]
+	if !ok {
+		return nil, model.ErrPolicyNotFound
+	}
+
+	docs, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:  "language",
+		Filters: map[string]interface{}{"name": name, "version": version},
+	})
+	if err != nil {
+		logger.Error("Failed to list policy documents: %v", err)
+		return nil, err
+	}
+	if len(docs) == 0 {
+		return nil, model.ErrPolicyNotFound
+	}
+	return localizedDocument(docs, lang), nil
+}
+
+// ListCurrentPolicies returns the current version of every policy in lang
+func (s *policyService) ListCurrentPolicies(ctx context.Context, lang string) ([]*model.PolicyDocument, error) {
+	repo, err := s.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := repo.List(ctx, datastore.ListOptions{SortBy: "language"})
+	if err != nil {
+		logger.Error("Failed to list policy documents: %v", err)
+		return nil, err
+	}
+
+	versions := make(map[string][]*model.PolicyDocument)
+	current := make(map[string]int)
+	for _, doc := range docs {
+		if doc.Version > current[doc.Name] {
+			current[doc.Name] = doc.Version
+			versions[doc.Name] = nil
+		}
+		if doc.Version == current[doc.Name] {
+			versions[doc.Name] = append(versions[doc.Name], doc)
+		}
+	}
+
+	result := make([]*model.PolicyDocument, 0, len(versions))
+	for _, docs := range versions {
+		result = append(result, localizedDocument(docs, lang))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// AcceptPolicy records the consent of a user to the current version of a policy
+func (s *policyService) AcceptPolicy(ctx context.Context, consent *model.PolicyConsent) (*model.PolicyConsent, error) {
+	logger.Info("User %s accepting version %d of policy %s", consent.UserID, consent.Version, consent.Policy)
+
+	docs, err := s.documents()
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.currentVersions(ctx, docs, []string{consent.Policy})
+	if err != nil {
+		return nil, err
+	}
+	version, ok := current[consent.Policy]
+	if !ok {
+		return nil, model.ErrPolicyNotFound
+	}
+	if consent.Version != version {
+		return nil, model.ErrPolicyVersionOutdated
+	}
+
+	repo, err := s.consents()
+	if err != nil {
+		return nil, err
+	}
+	existing, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"user_id": consent.UserID, "policy": consent.Policy, "version": consent.Version},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) > 0 {
+		return existing[0], nil
+	}
+
+	result, err := repo.Create(ctx, consent)
+	if err != nil {
+		logger.Error("Failed to record policy consent: %v", err)
+		return nil, err
+	}
+
+	s.publish(ctx, event.NewEvent(EventTypePolicyAccepted, PolicyAccepted{
+		Policy:  result.Policy,
+		Version: result.Version,
+		UserID:  result.UserID,
+	}))
+	return result, nil
+}
+
+// ListConsents lists the consents of a user
+func (s *policyService) ListConsents(ctx context.Context, userID string) ([]*model.PolicyConsent, error) {
+	repo, err := s.consents()
+	if err != nil {
+		return nil, err
+	}
+
+	consents, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:   "created_at",
+		SortDesc: true,
+		Filters:  map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		logger.Error("Failed to list policy consents: %v", err)
+		return nil, err
+	}
+	return consents, nil
+}
+
+// PendingPolicies returns the policies among names the user still has to accept.
+// Policies without a published document are never pending.
+func (s *policyService) PendingPolicies(ctx context.Context, userID string, names []string) ([]string, error) {
+	docs, err := s.documents()
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.currentVersions(ctx, docs, names)
+	if err != nil || len(current) == 0 {
+		return nil, err
+	}
+
+	repo, err := s.consents()
+	if err != nil {
+		return nil, err
+	}
+	consents, err := repo.List(ctx, datastore.ListOptions{
+		Filters: map[string]interface{}{"user_id": userID, "policy": names},
+	})
+	if err != nil {
+		return nil, err
+	}
+	accepted := make(map[string]bool)
+	for _, consent := range consents {
+		if consent.Version == current[consent.Policy] {
+			accepted[consent.Policy] = true
+		}
+	}
+
+	var pending []string
+	for _, name := range names {
+		if _, published := current[name]; published && !accepted[name] {
+			pending = append(pending, name)
+		}
+	}
+	return pending, nil
+}
+
+// currentVersions returns the current version of each published policy among names
+func (s *policyService) currentVersions(ctx context.Context, repo datastore.Repository[*model.PolicyDocument], names []string) (map[string]int, error) {
+	current := make(map[string]int)
+	for _, name := range names {
+		docs, err := repo.List(ctx, datastore.ListOptions{
+			Size:     1,
+			SortBy:   "version",
+			SortDesc: true,
+			Filters:  map[string]interface{}{"name": name},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(docs) > 0 {
+			current[name] = docs[0].Version
+		}
+	}
+	return current, nil
+}
+
+// publish publishes an event on the event bus, when one is registered
+func (s *policyService) publish(ctx context.Context, e event.Event) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, e)
+	}
+}
+
+// localizedDocument picks the document in lang among the documents of a version,
+// then the one in the default language, then the first one
+func localizedDocument(docs []*model.PolicyDocument, lang string) *model.PolicyDocument {
+	for _, want := range []string{lang, i18n.DefaultLanguage} {
+		for _, doc := range docs {
+			if doc.Language == want {
+				return doc
+			}
+		}
+	}
+	return docs[0]
+}
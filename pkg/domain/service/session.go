@@ -0,0 +1,465 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/denylist"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// Session event types
+const (
+	EventTypeSessionCreated = "session.created"
+	EventTypeSessionRevoked = "session.revoked"
+)
+
+// Reasons of session revocations
+const (
+	SessionRevokedByUser = "user"
+	SessionRevokedOthers = "others"
+	// SessionRevokedReuse is the revocation of a session whose replaced
+	// refresh token was presented again, which means that it leaked
+	SessionRevokedReuse = "refresh_token_reuse"
+)
+
+// SessionChanged is the payload of the session events
+type SessionChanged struct {
+	ID        uint   `json:"id"`
+	UserID    string `json:"user_id"`
+	IP        string `json:"ip"`
+	UserAgent string `json:"user_agent"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// SessionServiceInterface defines the interface for the sessions of users:
+// one per login, holding the refresh token of the device
+type SessionServiceInterface interface {
+	// CreateSession stores a session for a login and returns it and its
+	// refresh token, which cannot be retrieved afterwards
+	CreateSession(ctx context.Context, session *model.Session) (*model.Session, string, error)
+	// RefreshSession replaces the refresh token of its session and returns
+	// the session and the new token. Presenting the token replaced by the last
+	// refresh, or losing the race against a concurrent refresh with the same
+	// token, revokes the session; any other wrong token changes nothing.
+	RefreshSession(ctx context.Context, refreshToken, userAgent, ip string) (*model.Session, string, error)
+	// ListSessions lists the unexpired sessions of a user, most recently seen first
+	ListSessions(ctx context.Context, userID string) ([]*model.Session, error)
+	// ResolveSessionID returns the ID of the session of a user with a public ID
+	ResolveSessionID(ctx context.Context, userID, publicID string) (uint, error)
+	// RevokeSession terminates a session of a user
+	RevokeSession(ctx context.Context, userID string, id uint) error
+	// RevokeOtherSessions terminates the sessions of a user except keepID and
+	// returns how many were terminated
+	RevokeOtherSessions(ctx context.Context, userID string, keepID uint) (int, error)
+	// TouchSession records a request of a session from its device, writing it
+	// at most once per security.sessions.touch_interval
+	TouchSession(ctx context.Context, id uint, userAgent, ip string) error
+}
+
+// sessionService 内部实现，支持依赖注入
+type sessionService struct {
+	Store      datastore.DatastoreInterface `inject:"datastore"`
+	UnitOfWork datastore.UnitOfWorkManager  `inject:"unit_of_work"`
+	EventBus   event.Bus                    `inject:"eventbus"`
+	Config     *config.Config               `inject:"config"`
+	Clock      clock.Clock                  `inject:"clock"`
+	Denylist   *denylist.Denylist           `inject:"token_denylist"`
+
+	// touched holds when each session was last written by TouchSession
+	touchMutex sync.Mutex
+	touched    map[uint]time.Time
+}
+
+// init 注册认证模块，禁用时不迁移会话
+func init() {
+	module.Register(module.Module{
+		Name:        module.Auth,
+		Description: "Registration, session tokens, impersonation, login throttling and the token denylist",
+		Models:      []interface{}{&model.Session{}},
+	})
+}
+
+// NewSessionServiceForDI 创建支持依赖注入的会话服务实例
+func NewSessionServiceForDI() SessionServiceInterface {
+	return &sessionService{touched: make(map[uint]time.Time)}
+}
+
+// Module implements module.Component
+func (s *sessionService) Module() string {
+	return module.Auth
+}
+
+// repository returns the session repository, within the unit of work carried by ctx
+func (s *sessionService) repository(ctx context.Context) (datastore.Repository[*model.Session], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.Session](uow.Store())
+	}
+	return datastore.NewRepository[*model.Session](s.Store)
+}
+
+// CreateSession stores a session with a new refresh token
+func (s *sessionService) CreateSession(ctx context.Context, session *model.Session) (*model.Session, string, error) {
+	if err := session.Validate(); err != nil {
+		return nil, "", err
+	}
+
+	secret, hash, err := newRefreshSecret()
+	if err != nil {
+		return nil, "", err
+	}
+	now := s.Clock.Now()
+	session.UserAgent = truncate(session.UserAgent, model.MaxSessionUserAgentLength)
+	session.RefreshTokenHash = hash
+	session.LastSeenAt = now
+	session.ExpiresAt = now.Add(s.Config.Security.Sessions.RefreshTokenTTL)
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	result, err := repo.Create(ctx, session)
+	if err != nil {
+		logger.Error("Failed to create session: %v", err)
+		return nil, "", err
+	}
+
+	s.publish(ctx, EventTypeSessionCreated, result, "")
+	return result, refreshToken(result.ID, secret), nil
+}
+
+// RefreshSession rotates the refresh token of a session in a unit of work.
+// The update only matches while the session still holds the presented token,
+// so of two concurrent refreshes with the same token the one losing the race
+// finds it replaced, and is treated as the reuse of a replaced token.
+func (s *sessionService) RefreshSession(ctx context.Context, token, userAgent, ip string) (*model.Session, string, error) {
+	id, secret, ok := parseRefreshToken(token)
+	if !ok {
+		return nil, "", model.ErrRefreshTokenInvalid
+	}
+
+	var (
+		result  *model.Session
+		reused  *model.Session
+		expired bool
+	)
+	hash := hashRefreshSecret(secret)
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.repository(ctx)
+		if err != nil {
+			return err
+		}
+		session, err := repo.Get(ctx, id)
+		if err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrRefreshTokenInvalid
+			}
+			return err
+		}
+
+		if subtle.ConstantTimeCompare([]byte(hash), []byte(session.RefreshTokenHash)) != 1 {
+			// Only the holder of a token issued for the session knows a replaced
+			// secret; a guessed one leaves the session alone
+			if session.PreviousRefreshTokenHash == "" ||
+				subtle.ConstantTimeCompare([]byte(hash), []byte(session.PreviousRefreshTokenHash)) != 1 {
+				return model.ErrRefreshTokenInvalid
+			}
+			reused = session
+			return nil
+		}
+
+		now := s.Clock.Now()
+		if session.Expired(now) {
+			expired = true
+			return nil
+		}
+
+		var next string
+		if secret, next, err = newRefreshSecret(); err != nil {
+			return err
+		}
+		rotated := *session
+		rotated.PreviousRefreshTokenHash = session.RefreshTokenHash
+		rotated.RefreshTokenHash = next
+		rotated.UserAgent = truncate(userAgent, model.MaxSessionUserAgentLength)
+		rotated.IP = ip
+		rotated.LastSeenAt = now
+		rotated.ExpiresAt = now.Add(s.Config.Security.Sessions.RefreshTokenTTL)
+
+		result, err = repo.UpdateMatching(ctx, &rotated, map[string]interface{}{
+			"refresh_token_hash": session.RefreshTokenHash,
+		})
+		if err == datastore.ErrNotFound {
+			// Another refresh replaced the token since it was read
+			reused = session
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		if err != model.ErrRefreshTokenInvalid {
+			logger.Error("Failed to refresh session: %v", err)
+		}
+		return nil, "", err
+	}
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	switch {
+	case reused != nil:
+		// A replaced token presented again has leaked: terminate the whole session
+		logger.WithContext(ctx).WithFields(map[string]interface{}{
+			logger.FieldUserID: reused.UserID,
+			logger.FieldIP:     ip,
+			"session_id":       reused.ID,
+		}).Warn("Replaced refresh token presented, revoking its session")
+		if err := s.revoke(ctx, repo, reused, SessionRevokedReuse); err != nil {
+			return nil, "", err
+		}
+		return nil, "", model.ErrRefreshTokenInvalid
+	case expired:
+		if err := repo.Delete(ctx, id); err != nil && err != datastore.ErrNotFound {
+			logger.Warn("Failed to delete expired session %d: %v", id, err)
+		}
+		return nil, "", model.ErrSessionExpired
+	}
+	return result, refreshToken(result.ID, secret), nil
+}
+
+// ListSessions lists the unexpired sessions of a user
+func (s *sessionService) ListSessions(ctx context.Context, userID string) ([]*model.Session, error) {
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := repo.List(ctx, datastore.ListOptions{
+		SortBy:   "last_seen_at",
+		SortDesc: true,
+		Filters:  map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		logger.Error("Failed to list sessions: %v", err)
+		return nil, err
+	}
+
+	now := s.Clock.Now()
+	active := make([]*model.Session, 0, len(sessions))
+	for _, session := range sessions {
+		if !session.Expired(now) {
+			active = append(active, session)
+		}
+	}
+	return active, nil
+}
+
+// ResolveSessionID returns the ID of the session of a user with a public ID;
+// the sessions of other users are not found
+func (s *sessionService) ResolveSessionID(ctx context.Context, userID, publicID string) (uint, error) {
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return 0, err
+	}
+	session, err := datastore.FindByPublicID(ctx, repo, publicID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return 0, model.ErrSessionNotFound
+		}
+		return 0, err
+	}
+	if session.UserID != userID {
+		return 0, model.ErrSessionNotFound
+	}
+	return session.ID, nil
+}
+
+// RevokeSession terminates a session of a user; the sessions of other users are not found
+func (s *sessionService) RevokeSession(ctx context.Context, userID string, id uint) error {
+	logger.Info("Revoking session %d", id)
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return err
+	}
+	session, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return model.ErrSessionNotFound
+		}
+		return err
+	}
+	if session.UserID != userID {
+		return model.ErrSessionNotFound
+	}
+	return s.revoke(ctx, repo, session, SessionRevokedByUser)
+}
+
+// RevokeOtherSessions terminates the sessions of a user except keepID
+func (s *sessionService) RevokeOtherSessions(ctx context.Context, userID string, keepID uint) (int, error) {
+	logger.Info("Revoking the sessions other than %d", keepID)
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return 0, err
+	}
+	sessions, err := repo.List(ctx, datastore.ListOptions{
+		Filters: map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		logger.Error("Failed to list sessions: %v", err)
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.ID == keepID {
+			continue
+		}
+		if err := s.revoke(ctx, repo, session, SessionRevokedOthers); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+	return revoked, nil
+}
+
+// TouchSession writes the last seen time and device of a session, unless it
+// was written less than touch_interval ago by this instance
+func (s *sessionService) TouchSession(ctx context.Context, id uint, userAgent, ip string) error {
+	now := s.Clock.Now()
+	s.touchMutex.Lock()
+	if last, ok := s.touched[id]; ok && now.Sub(last) < s.Config.Security.Sessions.TouchInterval {
+		s.touchMutex.Unlock()
+		return nil
+	}
+	s.touched[id] = now
+	s.touchMutex.Unlock()
+
+	repo, err := s.repository(ctx)
+	if err != nil {
+		return err
+	}
+	session, err := repo.Get(ctx, id)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			s.forget(id)
+			return model.ErrSessionNotFound
+		}
+		return err
+	}
+	session.LastSeenAt = now
+	session.UserAgent = truncate(userAgent, model.MaxSessionUserAgentLength)
+	session.IP = ip
+	if _, err := repo.Update(ctx, session); err != nil && err != datastore.ErrNotFound {
+		return fmt.Errorf("failed to touch session %d: %w", id, err)
+	}
+	return nil
+}
+
+// revoke denylists the access tokens of a session until the last of them
+// expires, then deletes the session and with it its refresh token
+func (s *sessionService) revoke(ctx context.Context, repo datastore.Repository[*model.Session], session *model.Session, reason string) error {
+	until := s.Clock.Now().Add(s.Config.Security.Sessions.AccessTokenTTL)
+	if err := s.Denylist.Deny(ctx, SessionTokenID(session.ID), until); err != nil {
+		logger.Error("Failed to revoke session: %v", err)
+		return err
+	}
+	if err := repo.Delete(ctx, session.ID); err != nil && err != datastore.ErrNotFound {
+		logger.Error("Failed to delete session: %v", err)
+		return err
+	}
+	s.forget(session.ID)
+
+	s.publish(ctx, EventTypeSessionRevoked, session, reason)
+	return nil
+}
+
+// forget drops the last touch of a session
+func (s *sessionService) forget(id uint) {
+	s.touchMutex.Lock()
+	delete(s.touched, id)
+	s.touchMutex.Unlock()
+}
+
+// publish publishes a session event on the event bus, when one is registered
+func (s *sessionService) publish(ctx context.Context, eventType string, session *model.Session, reason string) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(eventType, SessionChanged{
+			ID:        session.ID,
+			UserID:    session.UserID,
+			IP:        session.IP,
+			UserAgent: session.UserAgent,
+			Reason:    reason,
+		}))
+	}
+}
+
+// SessionTokenID is the ID of a session in the sid claim of its access tokens and in the denylist
+func SessionTokenID(id uint) string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// ParseSessionTokenID parses the sid claim of an access token
+func ParseSessionTokenID(sid string) (uint, bool) {
+	id, err := strconv.ParseUint(sid, 10, 0)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// newRefreshSecret generates the secret part of a refresh token and its hash
+func newRefreshSecret() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(buf)
+	return secret, hashRefreshSecret(secret), nil
+}
+
+// hashRefreshSecret returns the stored hash of a refresh token secret
+func hashRefreshSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// refreshToken formats a refresh token as "<session id>.<secret>"
+func refreshToken(id uint, secret string) string {
+	return SessionTokenID(id) + "." + secret
+}
+
+// parseRefreshToken splits a refresh token into its session ID and secret
+func parseRefreshToken(token string) (uint, string, bool) {
+	sid, secret, found := strings.Cut(token, ".")
+	if !found || secret == "" {
+		return 0, "", false
+	}
+	id, ok := ParseSessionTokenID(sid)
+	return id, secret, ok
+}
+
+// truncate shortens s to at most max bytes
+func truncate(s string, max int) string {
+	if len(s) > max {
+		return s[:max]
+	}
+	return s
+}
@@ -0,0 +1,506 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// EventTypeUserRegistered is published when a user signs up, or verifies their
+// email address after signing up openly. The audit log records it,
+// mail.notifications.user_registered are mailed about it, and other subsystems
+// may subscribe to it on the event bus.
+const EventTypeUserRegistered = "user.registered"
+
+// MailTemplateEmailVerification is the template of the email verifying the
+// address of users signing up openly
+const MailTemplateEmailVerification = "email_verification"
+
+// Registration modes
+const (
+	RegistrationModeOpen       = "open"
+	RegistrationModeInviteOnly = "invite_only"
+)
+
+// UserRegistered is the payload of the user registered event
+type UserRegistered struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// InvitationID is the invitation the user signed up with, 0 for open signup
+	InvitationID uint `json:"invitation_id,omitempty"`
+	// OrgIDs are the organizations the user joined by the invitation or the tenants
+	OrgIDs []uint `json:"org_ids,omitempty"`
+}
+
+// Registration is a signup request
+type Registration struct {
+	Email    string
+	Username string
+	Password string
+	// InvitationToken is required by invite-only signup, and sets the role of the user
+	InvitationToken string
+}
+
+// InvitationAcceptance accepts an invitation as its invited user
+type InvitationAcceptance struct {
+	Token string
+	// Username of a new user, defaults to the email of the invitation
+	Username string
+	// Password authenticates an existing user, and is the password of a new one
+	Password string
+}
+
+// UserServiceInterface defines the interface for the users who sign up
+type UserServiceInterface interface {
+	// RegisterUser creates a user as allowed by the registration policy, adds
+	// them to the organizations of their invitation and of the tenants of their
+	// email domain, and publishes EventTypeUserRegistered. A user signing up
+	// without an invitation is mailed a verification token instead, and stays
+	// unverified until VerifyEmail.
+	RegisterUser(ctx context.Context, registration *Registration) (*model.User, error)
+	// AcceptInvitation accepts an invitation as the user of its email. An
+	// existing user authenticates with their password and keeps their role; a
+	// new user is created with the password and the role and permissions of
+	// the invitation, joins their tenants, and EventTypeUserRegistered is published.
+	AcceptInvitation(ctx context.Context, acceptance *InvitationAcceptance) (*model.User, *model.Invitation, error)
+	// VerifyEmail verifies the email address of the user of token, adds them to
+	// the organizations of the tenants of their email domain and publishes
+	// EventTypeUserRegistered
+	VerifyEmail(ctx context.Context, token string) (*model.User, error)
+	// Authenticate returns the user with email and password, for login handlers;
+	// it returns model.ErrUserCredentialsInvalid for unknown users and wrong
+	// passwords, and model.ErrUserEmailUnverified for unverified users
+	Authenticate(ctx context.Context, email, password string) (*model.User, error)
+	// GetUser returns the verified user with a user ID, or model.ErrUserNotFound
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+}
+
+// userService 内部实现，支持依赖注入
+type userService struct {
+	Store         datastore.DatastoreInterface `inject:"datastore"`
+	UnitOfWork    datastore.UnitOfWorkManager  `inject:"unit_of_work"`
+	Config        *config.Config               `inject:"config"`
+	Clock         clock.Clock                  `inject:"clock"`
+	Mail          MailServiceInterface         `inject:""`
+	Invitations   InvitationServiceInterface   `inject:""`
+	Organizations OrganizationServiceInterface `inject:""`
+}
+
+// NewUserServiceForDI 创建支持依赖注入的用户服务实例
+func NewUserServiceForDI() UserServiceInterface {
+	return &userService{}
+}
+
+// emailVerificationMail is the data of the email verification email
+type emailVerificationMail struct {
+	Email    string
+	Username string
+	Token    string
+	// VerifyURL links to the verification page with the token, empty when not configured
+	VerifyURL string
+	ExpiresAt time.Time
+}
+
+// dummyPasswordHash is compared for unknown users, so that they take as long to
+// authenticate as known ones
+var dummyPasswordHash = sync.OnceValue(func() []byte {
+	hash, _ := bcrypt.GenerateFromPassword([]byte("dummy password"), bcrypt.DefaultCost)
+	return hash
+})
+
+// users returns the user repository, within the unit of work carried by ctx
+func (s *userService) users(ctx context.Context) (datastore.Repository[*model.User], error) {
+	if uow, ok := datastore.UnitOfWorkFromContext(ctx); ok {
+		return datastore.NewRepository[*model.User](uow.Store())
+	}
+	return datastore.NewRepository[*model.User](s.Store)
+}
+
+// RegisterUser checks the registration policy and creates the user, accepting
+// their invitation and joining their organizations in one unit of work. Users
+// signing up without an invitation have not proven they own their email
+// address, so they are created unverified and mailed a verification token;
+// signing up again replaces an unverified user and invalidates their tokens.
+func (s *userService) RegisterUser(ctx context.Context, registration *Registration) (*model.User, error) {
+	policy := s.Config.Registration
+	user := &model.User{
+		Email:       model.NormalizeEmail(registration.Email),
+		Username:    registration.Username,
+		Role:        policy.DefaultRole,
+		Permissions: policy.DefaultPermissions,
+	}
+	if user.Username == "" {
+		user.Username = user.Email
+	}
+	if err := user.Validate(); err != nil {
+		return nil, err
+	}
+	if len(registration.Password) < policy.MinPasswordLength {
+		return nil, model.ErrUserPasswordTooShort
+	}
+	if registration.InvitationToken == "" {
+		if policy.Mode != RegistrationModeOpen {
+			return nil, model.ErrRegistrationInvitationRequired
+		}
+		domain := model.EmailDomain(user.Email)
+		if len(policy.AllowedDomains) > 0 && !slices.ContainsFunc(policy.AllowedDomains, func(allowed string) bool { return strings.EqualFold(allowed, domain) }) {
+			return nil, model.ErrRegistrationDomainNotAllowed
+		}
+		nonce, err := newTokenNonce()
+		if err != nil {
+			return nil, err
+		}
+		user.VerificationNonce = nonce
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(registration.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash password: %w", err)
+	}
+	user.PasswordHash = string(hash)
+
+	var result *model.User
+	err = s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.users(ctx)
+		if err != nil {
+			return err
+		}
+		existing, err := findUser(ctx, repo, user.Email)
+		switch {
+		case err == nil && existing.Verified():
+			return model.ErrUserExists
+		case err == nil:
+			user.BaseModel = existing.BaseModel
+		case err != datastore.ErrNotFound:
+			return err
+		}
+
+		var orgIDs []uint
+		if registration.InvitationToken != "" {
+			invitation, err := s.Invitations.AcceptInvitation(ctx, registration.InvitationToken)
+			if err != nil {
+				return err
+			}
+			if invitation.Email != user.Email {
+				return model.ErrRegistrationInvitationMismatched
+			}
+			user.Role, user.Permissions, user.InvitationID = invitation.Role, invitation.Permissions, invitation.ID
+			if invitation.OrgID != 0 {
+				orgIDs = append(orgIDs, invitation.OrgID)
+			}
+		}
+
+		if result, err = saveUser(ctx, repo, user); err != nil {
+			return err
+		}
+		if !result.Verified() {
+			return nil
+		}
+		return s.activate(ctx, uow, result, orgIDs)
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to register user: %v", err)
+		}
+		return nil, err
+	}
+
+	if !result.Verified() {
+		if err := s.mailVerification(ctx, result); err != nil {
+			// The user may sign up again to be mailed a new token
+			logger.Error("Failed to mail the email verification of %s: %v", result.UserID(), err)
+			return nil, err
+		}
+		logger.Info("User %s signed up, email verification mailed", result.UserID())
+		return result, nil
+	}
+	logger.Info("User %s registered with role %s", result.UserID(), result.Role)
+	return result, nil
+}
+
+// AcceptInvitation accepts the invitation and authenticates or creates its user
+// in one unit of work, so that no invitation is accepted for a user who does
+// not exist. An unverified user of the email is replaced, the invitation
+// proving the email is theirs.
+func (s *userService) AcceptInvitation(ctx context.Context, acceptance *InvitationAcceptance) (*model.User, *model.Invitation, error) {
+	var user *model.User
+	var invitation *model.Invitation
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		var err error
+		if invitation, err = s.Invitations.AcceptInvitation(ctx, acceptance.Token); err != nil {
+			return err
+		}
+		repo, err := s.users(ctx)
+		if err != nil {
+			return err
+		}
+		existing, err := findUser(ctx, repo, invitation.Email)
+		switch {
+		case err == nil && existing.Verified():
+			if bcrypt.CompareHashAndPassword([]byte(existing.PasswordHash), []byte(acceptance.Password)) != nil {
+				return model.ErrUserCredentialsInvalid
+			}
+			user = existing
+			return nil
+		case err != nil && err != datastore.ErrNotFound:
+			return err
+		}
+
+		user = &model.User{
+			Email:        invitation.Email,
+			Username:     acceptance.Username,
+			Role:         invitation.Role,
+			Permissions:  invitation.Permissions,
+			InvitationID: invitation.ID,
+		}
+		if existing != nil {
+			user.BaseModel = existing.BaseModel
+		}
+		if user.Username == "" {
+			user.Username = user.Email
+		}
+		if err := user.Validate(); err != nil {
+			return err
+		}
+		if len(acceptance.Password) < s.Config.Registration.MinPasswordLength {
+			return model.ErrUserPasswordTooShort
+		}
+		hash, err := bcrypt.GenerateFromPassword([]byte(acceptance.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return fmt.Errorf("failed to hash password: %w", err)
+		}
+		user.PasswordHash = string(hash)
+
+		if user, err = saveUser(ctx, repo, user); err != nil {
+			return err
+		}
+		var orgIDs []uint
+		if invitation.OrgID != 0 {
+			orgIDs = append(orgIDs, invitation.OrgID)
+		}
+		return s.activate(ctx, uow, user, orgIDs)
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to accept invitation as a user: %v", err)
+		}
+		return nil, nil, err
+	}
+
+	if user.InvitationID == invitation.ID {
+		logger.Info("User %s registered by invitation %d with role %s", user.UserID(), invitation.ID, user.Role)
+	}
+	return user, invitation, nil
+}
+
+// VerifyEmail verifies the token, clears the verification nonce of its user and
+// joins their tenants in one unit of work
+func (s *userService) VerifyEmail(ctx context.Context, token string) (*model.User, error) {
+	id, expires, signature, ok := parseSignedToken(token)
+	if !ok {
+		return nil, model.ErrRegistrationTokenInvalid
+	}
+
+	var result *model.User
+	err := s.UnitOfWork.Do(ctx, func(ctx context.Context, uow datastore.UnitOfWork) error {
+		repo, err := s.users(ctx)
+		if err != nil {
+			return err
+		}
+		user, err := repo.Get(ctx, id)
+		if err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrRegistrationTokenInvalid
+			}
+			return err
+		}
+		if user.Verified() || !hmac.Equal([]byte(signature), []byte(s.sign(user, expires))) {
+			return model.ErrRegistrationTokenInvalid
+		}
+		if !s.Clock.Now().Before(time.Unix(expires, 0)) {
+			return model.ErrRegistrationTokenExpired
+		}
+
+		user.VerificationNonce = ""
+		if result, err = repo.Update(ctx, user); err != nil {
+			if err == datastore.ErrNotFound {
+				return model.ErrRegistrationTokenInvalid
+			}
+			return err
+		}
+		return s.activate(ctx, uow, result, nil)
+	})
+	if err != nil {
+		if _, ok := err.(*model.DomainError); !ok {
+			logger.Error("Failed to verify the email of user %d: %v", id, err)
+		}
+		return nil, err
+	}
+
+	logger.Info("User %s verified their email and registered with role %s", result.UserID(), result.Role)
+	return result, nil
+}
+
+// activate adds a user owning their email address to the organizations of the
+// tenants of its domain, and publishes EventTypeUserRegistered with orgIDs,
+// the organizations they already joined, in the unit of work
+func (s *userService) activate(ctx context.Context, uow datastore.UnitOfWork, user *model.User, orgIDs []uint) error {
+	joined, err := s.joinTenants(ctx, user)
+	if err != nil {
+		return err
+	}
+	orgIDs = append(orgIDs, joined...)
+
+	uow.Publish(event.NewEvent(EventTypeUserRegistered, UserRegistered{
+		UserID:       user.UserID(),
+		Username:     user.Username,
+		Role:         user.Role,
+		InvitationID: user.InvitationID,
+		OrgIDs:       orgIDs,
+	}))
+	return nil
+}
+
+// mailVerification mails the email verification token of an unverified user
+func (s *userService) mailVerification(ctx context.Context, user *model.User) error {
+	// Tokens carry the expiry in Unix seconds
+	expiresAt := s.Clock.Now().Add(s.Config.Registration.VerificationTTL).Truncate(time.Second)
+	token := fmt.Sprintf("%d.%d.%s", user.ID, expiresAt.Unix(), s.sign(user, expiresAt.Unix()))
+	_, err := s.Mail.Send(ctx, &Mail{
+		To:       []string{user.Email},
+		Template: MailTemplateEmailVerification,
+		Data: emailVerificationMail{
+			Email:     user.Email,
+			Username:  user.Username,
+			Token:     token,
+			VerifyURL: tokenLink(s.Config.Registration.VerifyURL, token),
+			ExpiresAt: expiresAt,
+		},
+	})
+	return err
+}
+
+// sign returns the HMAC-SHA256 of the ID and verification nonce of a user and
+// the expiry of a token
+func (s *userService) sign(user *model.User, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.Config.Security.JWTSecret))
+	fmt.Fprintf(mac, "verify.%d.%d.%s", user.ID, expires, user.VerificationNonce)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// Authenticate compares password with the password hash of the user
+func (s *userService) Authenticate(ctx context.Context, email, password string) (*model.User, error) {
+	repo, err := s.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := findUser(ctx, repo, model.NormalizeEmail(email))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			_ = bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(password))
+			return nil, model.ErrUserCredentialsInvalid
+		}
+		return nil, err
+	}
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+		return nil, model.ErrUserCredentialsInvalid
+	}
+	if !user.Verified() {
+		return nil, model.ErrUserEmailUnverified
+	}
+	return user, nil
+}
+
+// GetUser returns the user whose user ID, their email address, is userID.
+// Unverified users are not found.
+func (s *userService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	repo, err := s.users(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, err := findUser(ctx, repo, model.NormalizeEmail(userID))
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil, model.ErrUserNotFound
+		}
+		return nil, err
+	}
+	if !user.Verified() {
+		return nil, model.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// joinTenants adds the user to the organizations of the tenants of their email
+// domain and returns them. Memberships from the invitation are kept, and tenants
+// of deleted organizations are skipped.
+func (s *userService) joinTenants(ctx context.Context, user *model.User) ([]uint, error) {
+	domain := model.EmailDomain(user.Email)
+	var orgIDs []uint
+	for _, tenant := range s.Config.Registration.Tenants {
+		if !strings.EqualFold(tenant.Domain, domain) {
+			continue
+		}
+		_, err := s.Organizations.AddMember(ctx, &model.OrganizationMember{
+			OrgID:  tenant.OrgID,
+			UserID: user.UserID(),
+			Role:   tenant.OrgRole,
+		})
+		switch {
+		case err == nil:
+			orgIDs = append(orgIDs, tenant.OrgID)
+		case errors.Is(err, model.ErrOrganizationMemberExists):
+		case errors.Is(err, model.ErrOrganizationNotFound):
+			logger.Warn("Skipping the tenant of %s: organization %d not found", domain, tenant.OrgID)
+		default:
+			return nil, err
+		}
+	}
+	return orgIDs, nil
+}
+
+// saveUser creates user, or updates the unverified user whose ID it took over
+func saveUser(ctx context.Context, repo datastore.Repository[*model.User], user *model.User) (*model.User, error) {
+	var result *model.User
+	var err error
+	if user.ID == 0 {
+		result, err = repo.Create(ctx, user)
+	} else {
+		result, err = repo.Update(ctx, user)
+	}
+	if err == datastore.ErrDuplicateKey {
+		return nil, model.ErrUserExists
+	}
+	return result, err
+}
+
+// findUser returns the user with an email address or datastore.ErrNotFound
+func findUser(ctx context.Context, repo datastore.Repository[*model.User], email string) (*model.User, error) {
+	users, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"email": email},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return users[0], nil
+}
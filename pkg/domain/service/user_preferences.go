@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// EventTypeUserPreferencesUpdated is published when a user changes or resets their preferences
+const EventTypeUserPreferencesUpdated = "user_preferences.updated"
+
+// UserPreferencesChanged is the payload of the user preference events
+type UserPreferencesChanged struct {
+	UserID   string `json:"user_id"`
+	Language string `json:"language"`
+	TimeZone string `json:"time_zone"`
+}
+
+// UserPreferenceServiceInterface defines the interface for the language, time
+// zone and notification settings of users
+type UserPreferenceServiceInterface interface {
+	// GetPreferences returns the preferences a user chose; fields left empty
+	// take the defaults. Users who never chose any get empty preferences.
+	GetPreferences(ctx context.Context, userID string) (*model.UserPreferences, error)
+	// EffectivePreferences returns the preferences of a user with the
+	// defaults of the preferences settings filled in. The language stays empty
+	// when there is no default, for it to be detected from each request.
+	EffectivePreferences(ctx context.Context, userID string) (*model.UserPreferences, error)
+	// UpdatePreferences applies a partial update to the preferences of a user
+	UpdatePreferences(ctx context.Context, userID string, patch *model.UserPreferencesPatch) (*model.UserPreferences, error)
+	// ResetPreferences restores the defaults for every preference of a user
+	ResetPreferences(ctx context.Context, userID string) error
+}
+
+// cachedPreferences are the preferences of a user kept until expiresAt
+type cachedPreferences struct {
+	prefs     *model.UserPreferences
+	expiresAt time.Time
+}
+
+// userPreferenceService 内部实现，支持依赖注入
+type userPreferenceService struct {
+	Store    datastore.DatastoreInterface `inject:"datastore"`
+	EventBus event.Bus                    `inject:"eventbus"`
+	Config   *config.Config               `inject:"config"`
+	Clock    clock.Clock                  `inject:"clock"`
+
+	// cache holds the preferences read on every authenticated request
+	cacheMutex sync.Mutex
+	cache      map[string]cachedPreferences
+}
+
+// NewUserPreferenceServiceForDI 创建支持依赖注入的用户偏好服务实例
+func NewUserPreferenceServiceForDI() UserPreferenceServiceInterface {
+	return &userPreferenceService{cache: make(map[string]cachedPreferences)}
+}
+
+// repository returns the user preferences repository
+func (s *userPreferenceService) repository() (datastore.Repository[*model.UserPreferences], error) {
+	return datastore.NewRepository[*model.UserPreferences](s.Store)
+}
+
+// GetPreferences returns the stored preferences of a user, from the cache when fresh
+func (s *userPreferenceService) GetPreferences(ctx context.Context, userID string) (*model.UserPreferences, error) {
+	now := s.Clock.Now()
+	s.cacheMutex.Lock()
+	cached, ok := s.cache[userID]
+	s.cacheMutex.Unlock()
+	if ok && now.Before(cached.expiresAt) {
+		return copyPreferences(cached.prefs), nil
+	}
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := findPreferences(ctx, repo, userID)
+	switch {
+	case err == datastore.ErrNotFound:
+		prefs = &model.UserPreferences{UserID: userID}
+	case err != nil:
+		logger.Error("Failed to get user preferences: %v", err)
+		return nil, err
+	}
+
+	s.remember(prefs)
+	return copyPreferences(prefs), nil
+}
+
+// EffectivePreferences fills the empty preferences of a user with the defaults
+func (s *userPreferenceService) EffectivePreferences(ctx context.Context, userID string) (*model.UserPreferences, error) {
+	prefs, err := s.GetPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if prefs.Language == "" {
+		prefs.Language = s.Config.Preferences.Language
+	}
+	if prefs.TimeZone == "" {
+		prefs.TimeZone = s.Config.Preferences.TimeZone
+	}
+	if prefs.NotificationChannels == nil {
+		prefs.NotificationChannels = model.StringList{}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences applies a partial update, creating the preferences of the user when missing
+func (s *userPreferenceService) UpdatePreferences(ctx context.Context, userID string, patch *model.UserPreferencesPatch) (*model.UserPreferences, error) {
+	logger.Info("Updating the preferences of user %s", userID)
+
+	repo, err := s.repository()
+	if err != nil {
+		return nil, err
+	}
+	prefs, err := findPreferences(ctx, repo, userID)
+	exists := err == nil
+	switch {
+	case err == datastore.ErrNotFound:
+		prefs = &model.UserPreferences{UserID: userID}
+	case err != nil:
+		return nil, err
+	}
+
+	patch.ApplyTo(prefs)
+	if err := prefs.Validate(); err != nil {
+		return nil, err
+	}
+
+	var result *model.UserPreferences
+	if exists {
+		result, err = repo.Update(ctx, prefs)
+	} else {
+		result, err = repo.Create(ctx, prefs)
+	}
+	if err != nil {
+		logger.Error("Failed to save user preferences: %v", err)
+		return nil, err
+	}
+
+	s.remember(result)
+	s.publish(ctx, result)
+	return copyPreferences(result), nil
+}
+
+// ResetPreferences deletes the preferences of a user
+func (s *userPreferenceService) ResetPreferences(ctx context.Context, userID string) error {
+	logger.Info("Resetting the preferences of user %s", userID)
+
+	repo, err := s.repository()
+	if err != nil {
+		return err
+	}
+	prefs, err := findPreferences(ctx, repo, userID)
+	if err != nil {
+		if err == datastore.ErrNotFound {
+			return nil
+		}
+		return err
+	}
+	if err := repo.Delete(ctx, prefs.ID); err != nil && err != datastore.ErrNotFound {
+		logger.Error("Failed to reset user preferences: %v", err)
+		return err
+	}
+
+	s.remember(&model.UserPreferences{UserID: userID})
+	s.publish(ctx, &model.UserPreferences{UserID: userID})
+	return nil
+}
+
+// remember caches the preferences of a user for preferences.cache_ttl
+func (s *userPreferenceService) remember(prefs *model.UserPreferences) {
+	ttl := s.Config.Preferences.CacheTTL
+	if ttl <= 0 {
+		return
+	}
+	s.cacheMutex.Lock()
+	s.cache[prefs.UserID] = cachedPreferences{prefs: copyPreferences(prefs), expiresAt: s.Clock.Now().Add(ttl)}
+	s.cacheMutex.Unlock()
+}
+
+// publish publishes a user preference event on the event bus, when one is registered
+func (s *userPreferenceService) publish(ctx context.Context, prefs *model.UserPreferences) {
+	if s.EventBus != nil {
+		s.EventBus.Publish(ctx, event.NewEvent(EventTypeUserPreferencesUpdated, UserPreferencesChanged{
+			UserID:   prefs.UserID,
+			Language: prefs.Language,
+			TimeZone: prefs.TimeZone,
+		}))
+	}
+}
+
+// copyPreferences returns a copy of prefs that callers may change
+func copyPreferences(prefs *model.UserPreferences) *model.UserPreferences {
+	result := *prefs
+	if prefs.NotificationChannels != nil {
+		result.NotificationChannels = append(model.StringList{}, prefs.NotificationChannels...)
+	}
+	return &result
+}
+
+// findPreferences returns the preferences of a user, or datastore.ErrNotFound
+func findPreferences(ctx context.Context, repo datastore.Repository[*model.UserPreferences], userID string) (*model.UserPreferences, error) {
+	prefs, err := repo.List(ctx, datastore.ListOptions{
+		Size:    1,
+		Filters: map[string]interface{}{"user_id": userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(prefs) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return prefs[0], nil
+}
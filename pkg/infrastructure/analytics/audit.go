@@ -0,0 +1,47 @@
+package analytics
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// SubscribeAudit records every domain event published on bus as an audit entry
+// and returns a function that stops recording
+func SubscribeAudit(bus event.Bus, sink Sink) func() {
+	return bus.Subscribe(event.WildcardType, func(ctx context.Context, e event.Event) {
+		sink.Record(ctx, NewAuditEntry(ctx, e))
+	})
+}
+
+// NewAuditEntry converts a domain event to an audit entry, taking the request
+// user and impersonator IDs from ctx
+func NewAuditEntry(ctx context.Context, e event.Event) *Entry {
+	entry := &Entry{
+		Kind:           KindAudit,
+		Timestamp:      e.Timestamp,
+		Action:         e.Type,
+		RequestID:      contextString(ctx, logger.FieldRequestID),
+		UserID:         contextString(ctx, logger.FieldUserID),
+		ImpersonatorID: contextString(ctx, logger.FieldImpersonatorID),
+	}
+
+	payload, err := json.Marshal(e)
+	if err != nil {
+		logger.Warn("Failed to encode %s event for the audit log: %v", e.Type, err)
+		return entry
+	}
+	entry.Payload = string(payload)
+	return entry
+}
+
+// contextString returns the value stored in ctx under key as a string
+func contextString(ctx context.Context, key string) string {
+	if value := ctx.Value(key); value != nil {
+		return fmt.Sprint(value)
+	}
+	return ""
+}
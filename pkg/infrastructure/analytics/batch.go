@@ -0,0 +1,299 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Backpressure policies applied when the queue is full
+const (
+	BackpressureDrop  = "drop"
+	BackpressureBlock = "block"
+)
+
+// Entry statuses
+const (
+	statusInserted = "inserted"
+	statusDropped  = "dropped"
+	statusFailed   = "failed"
+)
+
+// errSinkClosed is returned by Flush once the sink is closed
+var errSinkClosed = errors.New("analytics sink is closed")
+
+var (
+	// Entry counter
+	entriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "analytics_entries_total",
+			Help: "Total number of analytics entries by outcome",
+		},
+		[]string{"provider", "kind", "status"},
+	)
+
+	// Queued entries gauge
+	queueLength = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "analytics_queue_length",
+			Help: "Number of analytics entries waiting to be inserted",
+		},
+		[]string{"provider"},
+	)
+
+	// Batch insert duration histogram
+	insertDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "analytics_insert_duration_seconds",
+			Help:    "Duration of analytics batch inserts in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"provider"},
+	)
+)
+
+// batchSink queues entries and inserts them into the backend in batches from a
+// single goroutine, so that recording never waits for the backend. A full
+// queue drops new entries or, with the block policy, waits for room up to the
+// block timeout before dropping them.
+type batchSink struct {
+	backend       Backend
+	provider      string
+	batchSize     int
+	flushInterval time.Duration
+	block         bool
+	blockTimeout  time.Duration
+
+	mu      sync.RWMutex // guards closing queue against concurrent Record calls
+	closed  bool
+	queue   chan Entry
+	flushes chan chan error
+	done    chan struct{}
+}
+
+// newBatchSink creates a batching sink in front of backend and starts its worker
+func newBatchSink(backend Backend, provider string, cfg *config.AnalyticsConfig) *batchSink {
+	batchSize := cfg.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	flushInterval := cfg.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
+
+	s := &batchSink{
+		backend:       backend,
+		provider:      provider,
+		batchSize:     batchSize,
+		flushInterval: flushInterval,
+		block:         cfg.Backpressure == BackpressureBlock,
+		blockTimeout:  cfg.BlockTimeout,
+		queue:         make(chan Entry, cfg.QueueSize),
+		flushes:       make(chan chan error),
+		done:          make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Record implements Sink
+func (s *batchSink) Record(ctx context.Context, entry *Entry) {
+	if entry == nil {
+		return
+	}
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		s.count(entry.Kind, statusDropped)
+		return
+	}
+	select {
+	case s.queue <- *entry:
+		queueLength.WithLabelValues(s.provider).Inc()
+		return
+	default:
+	}
+	if s.block && s.blockTimeout > 0 {
+		timer := time.NewTimer(s.blockTimeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- *entry:
+			queueLength.WithLabelValues(s.provider).Inc()
+			return
+		case <-timer.C:
+		case <-ctx.Done():
+		}
+	}
+	s.count(entry.Kind, statusDropped)
+}
+
+// Query implements Sink, entries still queued are not returned
+func (s *batchSink) Query(ctx context.Context, q Query) ([]Entry, error) {
+	return s.backend.Query(ctx, q)
+}
+
+// CountBefore implements Purger, entries still queued are not counted
+func (s *batchSink) CountBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	purger, ok := s.backend.(Purger)
+	if !ok {
+		return 0, ErrPurgeNotSupported
+	}
+	return purger.CountBefore(ctx, kind, cutoff)
+}
+
+// DeleteBefore implements Purger, entries still queued are not deleted
+func (s *batchSink) DeleteBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	purger, ok := s.backend.(Purger)
+	if !ok {
+		return 0, ErrPurgeNotSupported
+	}
+	return purger.DeleteBefore(ctx, kind, cutoff)
+}
+
+// Flush implements Sink, it returns once the entries queued before the call are inserted
+func (s *batchSink) Flush(ctx context.Context) error {
+	reply := make(chan error, 1)
+	select {
+	case s.flushes <- reply:
+	case <-s.done:
+		return errSinkClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close implements Sink, it inserts the queued entries and closes the backend
+func (s *batchSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+	return s.backend.Close()
+}
+
+// OnStop closes the sink when the container stops, giving up on the queued
+// entries when ctx expires first
+func (s *batchSink) OnStop(ctx context.Context) error {
+	closed := make(chan error, 1)
+	go func() { closed <- s.Close() }()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		logger.Warn("Analytics sink flush timed out, %d entries not inserted", len(s.queue))
+		return ctx.Err()
+	}
+}
+
+// run collects queued entries into batches until the queue is closed
+func (s *batchSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Entry, 0, s.batchSize)
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if !ok {
+				s.insert(batch)
+				return
+			}
+			queueLength.WithLabelValues(s.provider).Dec()
+			batch = append(batch, entry)
+			if len(batch) >= s.batchSize {
+				s.insert(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.insert(batch)
+			batch = batch[:0]
+		case reply := <-s.flushes:
+			reply <- s.drain(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// drain inserts batch together with the entries currently queued
+func (s *batchSink) drain(batch []Entry) error {
+	var firstErr error
+	for {
+		select {
+		case entry, ok := <-s.queue:
+			if ok {
+				queueLength.WithLabelValues(s.provider).Dec()
+				batch = append(batch, entry)
+				if len(batch) < s.batchSize {
+					continue
+				}
+			}
+			if err := s.insert(batch); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			batch = batch[:0]
+			if !ok {
+				return firstErr
+			}
+		default:
+			if err := s.insert(batch); err != nil && firstErr == nil {
+				firstErr = err
+			}
+			return firstErr
+		}
+	}
+}
+
+// insert stores a batch in the backend. Failed batches are counted and
+// dropped, retrying would let a slow backend grow the queue without bound.
+func (s *batchSink) insert(batch []Entry) error {
+	if len(batch) == 0 {
+		return nil
+	}
+
+	start := time.Now()
+	err := s.backend.Insert(context.Background(), batch)
+	insertDuration.WithLabelValues(s.provider).Observe(time.Since(start).Seconds())
+
+	status := statusInserted
+	if err != nil {
+		status = statusFailed
+		logger.Warn("Failed to insert %d analytics entries into %s: %v", len(batch), s.provider, err)
+	}
+	for _, entry := range batch {
+		s.count(entry.Kind, status)
+	}
+	return err
+}
+
+// count counts an entry of kind with the given status
+func (s *batchSink) count(kind Kind, status string) {
+	entriesTotal.WithLabelValues(s.provider, string(kind), status).Inc()
+}
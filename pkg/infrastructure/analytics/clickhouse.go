@@ -0,0 +1,271 @@
+package analytics
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+func init() {
+	RegisterProvider("clickhouse", newClickHouseBackend)
+}
+
+// clickHouseColumns are the columns of the analytics table in insert order.
+// Columns added later are created by migrate on existing tables, so new
+// columns must be appended and have a default.
+var clickHouseColumns = []struct {
+	name, typ string
+}{
+	{"kind", "LowCardinality(String)"},
+	{"timestamp", "DateTime64(3, 'UTC')"},
+	{"request_id", "String"},
+	{"user_id", "String"},
+	{"action", "LowCardinality(String)"},
+	{"method", "LowCardinality(String)"},
+	{"path", "String"},
+	{"route", "String"},
+	{"status", "UInt16"},
+	{"duration_ns", "Int64"},
+	{"client_ip", "String"},
+	{"user_agent", "String"},
+	{"payload", "String"},
+	{"impersonator_id", "String"},
+}
+
+var identifierPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// clickHouseBackend stores entries in a MergeTree table ordered by kind and time
+type clickHouseBackend struct {
+	conn     driver.Conn
+	database string
+	name     string
+	table    string // qualified name
+}
+
+// newClickHouseBackend connects to ClickHouse and migrates the analytics table
+func newClickHouseBackend(cfg *config.Config) (Backend, error) {
+	chCfg := cfg.Monitor.Analytics.ClickHouse
+	if len(chCfg.Addr) == 0 {
+		return nil, fmt.Errorf("clickhouse addr is required")
+	}
+	if !identifierPattern.MatchString(chCfg.Database) || !identifierPattern.MatchString(chCfg.Table) {
+		return nil, fmt.Errorf("invalid clickhouse database or table name: %s.%s", chCfg.Database, chCfg.Table)
+	}
+
+	options := &clickhouse.Options{
+		Addr: chCfg.Addr,
+		Auth: clickhouse.Auth{
+			Database: chCfg.Database,
+			Username: chCfg.Username,
+			Password: chCfg.Password,
+		},
+		DialTimeout: chCfg.DialTimeout,
+		ClientInfo: clickhouse.ClientInfo{
+			Products: []struct {
+				Name    string
+				Version string
+			}{{Name: cfg.App.Name, Version: cfg.App.Version}},
+		},
+	}
+	if chCfg.AsyncInsert {
+		// The server buffers inserts and acknowledges them once written
+		options.Settings = clickhouse.Settings{
+			"async_insert":          1,
+			"wait_for_async_insert": 1,
+		}
+	}
+	conn, err := clickhouse.Open(options)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &clickHouseBackend{
+		conn:     conn,
+		database: chCfg.Database,
+		name:     chCfg.Table,
+		table:    chCfg.Database + "." + chCfg.Table,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), chCfg.DialTimeout+10*time.Second)
+	defer cancel()
+	if err := conn.Ping(ctx); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to connect to clickhouse: %w", err)
+	}
+	if err := b.migrate(ctx, chCfg.TTL); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to migrate clickhouse table %s: %w", b.table, err)
+	}
+	return b, nil
+}
+
+// migrate creates the analytics table and adds the columns missing from an
+// existing one. The TTL only applies when the table is created.
+func (b *clickHouseBackend) migrate(ctx context.Context, ttl time.Duration) error {
+	columns := make([]string, len(clickHouseColumns))
+	for i, column := range clickHouseColumns {
+		columns[i] = column.name + " " + column.typ
+	}
+
+	ddl := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (%s)
+		ENGINE = MergeTree
+		PARTITION BY toYYYYMM(timestamp)
+		ORDER BY (kind, timestamp)`, b.table, strings.Join(columns, ", "))
+	if ttl > 0 {
+		ddl += fmt.Sprintf(" TTL toDateTime(timestamp) + INTERVAL %d SECOND", int64(ttl.Seconds()))
+	}
+	if err := b.conn.Exec(ctx, ddl); err != nil {
+		return err
+	}
+
+	rows, err := b.conn.Query(ctx, "SELECT name FROM system.columns WHERE database = ? AND table = ?", b.database, b.name)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return err
+		}
+		existing[name] = true
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i, column := range clickHouseColumns {
+		if existing[column.name] {
+			continue
+		}
+		if err := b.conn.Exec(ctx, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s", b.table, columns[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Insert implements Backend, the batch is sent as a single block
+func (b *clickHouseBackend) Insert(ctx context.Context, entries []Entry) error {
+	batch, err := b.conn.PrepareBatch(ctx, fmt.Sprintf("INSERT INTO %s (%s)", b.table, columnNames()))
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		err := batch.Append(
+			string(e.Kind), e.Timestamp.UTC(), e.RequestID, e.UserID, e.Action, e.Method,
+			e.Path, e.Route, uint16(e.Status), int64(e.Duration), e.ClientIP, e.UserAgent, e.Payload, e.ImpersonatorID,
+		)
+		if err != nil {
+			_ = batch.Abort()
+			return err
+		}
+	}
+	return batch.Send()
+}
+
+// Query implements Backend
+func (b *clickHouseBackend) Query(ctx context.Context, q Query) ([]Entry, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+	if q.Kind != "" {
+		conditions = append(conditions, "kind = ?")
+		args = append(args, string(q.Kind))
+	}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, "timestamp >= ?")
+		args = append(args, q.Since.UTC())
+	}
+	if q.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, q.UserID)
+	}
+	if q.ImpersonatorID != "" {
+		conditions = append(conditions, "impersonator_id = ?")
+		args = append(args, q.ImpersonatorID)
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM %s", columnNames(), b.table)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY timestamp DESC"
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := b.conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var (
+			e        Entry
+			kind     string
+			status   uint16
+			duration int64
+		)
+		if err := rows.Scan(
+			&kind, &e.Timestamp, &e.RequestID, &e.UserID, &e.Action, &e.Method,
+			&e.Path, &e.Route, &status, &duration, &e.ClientIP, &e.UserAgent, &e.Payload, &e.ImpersonatorID,
+		); err != nil {
+			return nil, err
+		}
+		e.Kind = Kind(kind)
+		e.Status = int(status)
+		e.Duration = time.Duration(duration)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// CountBefore implements Purger
+func (b *clickHouseBackend) CountBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	var count uint64
+	row := b.conn.QueryRow(ctx, fmt.Sprintf("SELECT count() FROM %s WHERE kind = ? AND timestamp < ?", b.table), string(kind), cutoff.UTC())
+	if err := row.Scan(&count); err != nil {
+		return 0, err
+	}
+	return int64(count), nil
+}
+
+// DeleteBefore implements Purger with a mutation that waits until the rows
+// are deleted; the returned count is taken just before
+func (b *clickHouseBackend) DeleteBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	count, err := b.CountBefore(ctx, kind, cutoff)
+	if err != nil || count == 0 {
+		return 0, err
+	}
+	ctx = clickhouse.Context(ctx, clickhouse.WithSettings(clickhouse.Settings{"mutations_sync": 1}))
+	query := fmt.Sprintf("ALTER TABLE %s DELETE WHERE kind = ? AND timestamp < ?", b.table)
+	if err := b.conn.Exec(ctx, query, string(kind), cutoff.UTC()); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Close implements Backend
+func (b *clickHouseBackend) Close() error {
+	return b.conn.Close()
+}
+
+// columnNames returns the comma separated column names in insert order
+func columnNames() string {
+	names := make([]string, len(clickHouseColumns))
+	for i, column := range clickHouseColumns {
+		names[i] = column.name
+	}
+	return strings.Join(names, ", ")
+}
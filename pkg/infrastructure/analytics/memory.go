@@ -0,0 +1,123 @@
+package analytics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// memoryBackend keeps the most recent entries in a ring buffer. It is meant for
+// development and single instances; entries are lost on restart.
+type memoryBackend struct {
+	mu      sync.RWMutex
+	entries []Entry
+	next    int
+	full    bool
+}
+
+// newMemoryBackend creates a memory backend keeping monitor.analytics.memory_size entries
+func newMemoryBackend(cfg *config.Config) (Backend, error) {
+	size := cfg.Monitor.Analytics.MemorySize
+	if size < 1 {
+		size = 1
+	}
+	return &memoryBackend{entries: make([]Entry, size)}, nil
+}
+
+// Insert implements Backend
+func (b *memoryBackend) Insert(ctx context.Context, entries []Entry) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, entry := range entries {
+		b.entries[b.next] = entry
+		b.next = (b.next + 1) % len(b.entries)
+		b.full = b.full || b.next == 0
+	}
+	return nil
+}
+
+// Query implements Backend, entries are returned in insertion order, newest first
+func (b *memoryBackend) Query(ctx context.Context, q Query) ([]Entry, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	count := b.next
+	if b.full {
+		count = len(b.entries)
+	}
+
+	var entries []Entry
+	for i := 1; i <= count && (q.Limit < 1 || len(entries) < q.Limit); i++ {
+		entry := b.entries[(b.next-i+len(b.entries))%len(b.entries)]
+		if matches(entry, q) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// CountBefore implements Purger
+func (b *memoryBackend) CountBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var count int64
+	for _, entry := range b.stored() {
+		if entry.Kind == kind && entry.Timestamp.Before(cutoff) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// DeleteBefore implements Purger, the remaining entries keep their order
+func (b *memoryBackend) DeleteBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stored := b.stored()
+	kept := make([]Entry, 0, len(stored))
+	for _, entry := range stored {
+		if entry.Kind != kind || !entry.Timestamp.Before(cutoff) {
+			kept = append(kept, entry)
+		}
+	}
+	deleted := int64(len(stored) - len(kept))
+
+	entries := make([]Entry, len(b.entries))
+	copy(entries, kept)
+	b.entries = entries
+	b.next = len(kept) % len(entries)
+	b.full = len(kept) == len(entries)
+	return deleted, nil
+}
+
+// stored returns the stored entries, oldest first; the caller holds the lock
+func (b *memoryBackend) stored() []Entry {
+	if !b.full {
+		return b.entries[:b.next]
+	}
+	return append(append([]Entry{}, b.entries[b.next:]...), b.entries[:b.next]...)
+}
+
+// Close implements Backend
+func (b *memoryBackend) Close() error {
+	return nil
+}
+
+// matches reports whether entry is selected by q
+func matches(entry Entry, q Query) bool {
+	if q.Kind != "" && entry.Kind != q.Kind {
+		return false
+	}
+	if q.UserID != "" && entry.UserID != q.UserID {
+		return false
+	}
+	if q.ImpersonatorID != "" && entry.ImpersonatorID != q.ImpersonatorID {
+		return false
+	}
+	return q.Since.IsZero() || !entry.Timestamp.Before(q.Since)
+}
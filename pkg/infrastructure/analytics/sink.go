@@ -0,0 +1,141 @@
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Kind identifies the subsystem an entry comes from
+type Kind string
+
+const (
+	// KindAccess entries describe a served HTTP request
+	KindAccess Kind = "access"
+	// KindAudit entries describe a published domain event
+	KindAudit Kind = "audit"
+)
+
+// Entry is a single analytics record. Access entries carry the request fields,
+// audit entries the event type in Action and the encoded event in Payload.
+// ImpersonatorID is the user acting as UserID with an impersonation token.
+type Entry struct {
+	Kind           Kind          `json:"kind"`
+	Timestamp      time.Time     `json:"timestamp"`
+	RequestID      string        `json:"request_id,omitempty"`
+	UserID         string        `json:"user_id,omitempty"`
+	ImpersonatorID string        `json:"impersonator_id,omitempty"`
+	Action         string        `json:"action,omitempty"`
+	Method         string        `json:"method,omitempty"`
+	Path           string        `json:"path,omitempty"`
+	Route          string        `json:"route,omitempty"`
+	Status         int           `json:"status,omitempty"`
+	Duration       time.Duration `json:"duration,omitempty"`
+	ClientIP       string        `json:"client_ip,omitempty"`
+	UserAgent      string        `json:"user_agent,omitempty"`
+	Payload        string        `json:"payload,omitempty"`
+}
+
+// Query selects recent entries, newest first
+type Query struct {
+	// Kind restricts the entries to one subsystem, empty selects all
+	Kind Kind
+	// Since excludes entries older than the given time when not zero
+	Since time.Time
+	// UserID restricts the entries to one user when not empty
+	UserID string
+	// ImpersonatorID restricts the entries to the ones of an impersonator when not empty
+	ImpersonatorID string
+	// Limit is the maximum number of entries returned
+	Limit int
+}
+
+// Sink records analytics entries and queries the recent ones
+type Sink interface {
+	// Record queues an entry; it does not wait for the entry to be stored
+	Record(ctx context.Context, entry *Entry)
+	// Query returns the stored entries matching q, newest first
+	Query(ctx context.Context, q Query) ([]Entry, error)
+	// Flush stores the queued entries
+	Flush(ctx context.Context) error
+	// Close flushes the queued entries and releases the backend
+	Close() error
+}
+
+// Backend stores batches of entries, it is implemented by each provider
+type Backend interface {
+	// Insert stores a batch of entries, the slice is reused once it returns
+	Insert(ctx context.Context, entries []Entry) error
+	// Query returns the stored entries matching q, newest first
+	Query(ctx context.Context, q Query) ([]Entry, error)
+	// Close releases resources held by the backend
+	Close() error
+}
+
+// Purger is implemented by backends that delete old entries, for the retention
+// jobs. The sink implements it when its backend does.
+type Purger interface {
+	// CountBefore returns the number of entries of kind recorded before cutoff
+	CountBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error)
+	// DeleteBefore deletes the entries of kind recorded before cutoff and returns how many were deleted
+	DeleteBefore(ctx context.Context, kind Kind, cutoff time.Time) (int64, error)
+}
+
+// ErrPurgeNotSupported is returned by the sink when its backend does not implement Purger
+var ErrPurgeNotSupported = errors.New("analytics backend does not support purging entries")
+
+// Factory creates a backend from configuration
+type Factory func(cfg *config.Config) (Backend, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		"memory": newMemoryBackend,
+	}
+)
+
+// RegisterProvider registers a backend factory under the given provider name
+func RegisterProvider(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// New creates a sink according to the monitor.analytics configuration. A no-op
+// sink is returned when analytics is disabled.
+func New(cfg *config.Config) (Sink, error) {
+	aCfg := cfg.Monitor.Analytics
+	if !aCfg.Enabled {
+		return NewNoopSink(), nil
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[aCfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported analytics provider: %s", aCfg.Provider)
+	}
+
+	backend, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s analytics backend: %w", aCfg.Provider, err)
+	}
+	return newBatchSink(backend, aCfg.Provider, &aCfg), nil
+}
+
+// noopSink discards all entries
+type noopSink struct{}
+
+// NewNoopSink creates a sink that discards all entries
+func NewNoopSink() Sink {
+	return noopSink{}
+}
+
+func (noopSink) Record(ctx context.Context, entry *Entry)            {}
+func (noopSink) Query(ctx context.Context, q Query) ([]Entry, error) { return nil, nil }
+func (noopSink) Flush(ctx context.Context) error                     { return nil }
+func (noopSink) Close() error                                        { return nil }
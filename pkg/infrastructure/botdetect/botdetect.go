@@ -0,0 +1,288 @@
+// Package botdetect scores requests for automation. The score adds up header
+// heuristics and the request rate of the client IP, counted in the same
+// counter stores as the quotas. Risky requests to challenge routes must pass
+// the challenge of a pluggable provider such as Turnstile or reCAPTCHA, and
+// requests reaching the block score are rejected.
+package botdetect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Actions taken on a request
+const (
+	ActionAllow     = "allow"
+	ActionChallenge = "challenge"
+	ActionBlock     = "block"
+)
+
+// Signals adding to the score of a request
+const (
+	SignalMissingUserAgent      = "missing_user_agent"
+	SignalBotUserAgent          = "bot_user_agent"
+	SignalMissingAccept         = "missing_accept"
+	SignalMissingAcceptLanguage = "missing_accept_language"
+	SignalHighRate              = "high_rate"
+	SignalVeryHighRate          = "very_high_rate"
+)
+
+// signalWeights are the scores of the signals; a very high rate is over twice the rate limit
+var signalWeights = map[string]int{
+	SignalMissingUserAgent:      3,
+	SignalBotUserAgent:          3,
+	SignalMissingAccept:         1,
+	SignalMissingAcceptLanguage: 1,
+	SignalHighRate:              2,
+	SignalVeryHighRate:          4,
+}
+
+var (
+	decisionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_detection_decisions_total",
+			Help: "Total number of requests challenged or blocked by bot detection, by action and mode",
+		},
+		[]string{"action", "mode"},
+	)
+
+	challengesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bot_detection_challenges_total",
+			Help: "Total number of challenge verifications, by result",
+		},
+		[]string{"result"},
+	)
+)
+
+// Assessment is the outcome of scoring a request
+type Assessment struct {
+	Action  string
+	Score   int
+	Signals []string
+}
+
+// Detector scores requests and verifies challenges
+type Detector struct {
+	cfg        config.BotDetectionConfig
+	userAgents []string
+	store      quota.Store
+	provider   ChallengeProvider // nil without a configured provider
+	clock      clock.Clock
+}
+
+// New creates the detector of security.bot_detection with the counter store and
+// challenge provider it selects. A disabled detector does not connect to its store.
+func New(cfg *config.Config, clients *httpclient.Factory, clk clock.Clock) (*Detector, error) {
+	botCfg := cfg.Security.BotDetection
+	if !botCfg.Enabled {
+		return NewDetector(botCfg, quota.NewMemoryStore(), nil, clk), nil
+	}
+
+	var store quota.Store
+	switch botCfg.Store {
+	case quota.StoreMemory, "":
+		store = quota.NewMemoryStore()
+	case quota.StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect bot detection store: %w", err)
+		}
+		store = quota.NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported bot detection store: %s", botCfg.Store)
+	}
+
+	provider, err := newChallengeProvider(botCfg.Challenge, clients)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Bot detection enabled (challenge provider: %q, monitor: %t)", botCfg.Challenge.Provider, botCfg.Monitor)
+	return NewDetector(botCfg, store, provider, clk), nil
+}
+
+// NewDetector creates a detector counting requests in store and verifying
+// challenges with provider, which may be nil
+func NewDetector(cfg config.BotDetectionConfig, store quota.Store, provider ChallengeProvider, clk clock.Clock) *Detector {
+	if clk == nil {
+		clk = clock.New()
+	}
+	userAgents := make([]string, 0, len(cfg.UserAgents))
+	for _, userAgent := range cfg.UserAgents {
+		if userAgent != "" {
+			userAgents = append(userAgents, strings.ToLower(userAgent))
+		}
+	}
+	return &Detector{cfg: cfg, userAgents: userAgents, store: store, provider: provider, clock: clk}
+}
+
+// Enabled reports whether requests are scored
+func (d *Detector) Enabled() bool {
+	return d != nil && d.cfg.Enabled
+}
+
+// Monitor reports whether challenges and blocks are only logged
+func (d *Detector) Monitor() bool {
+	return d.cfg.Monitor
+}
+
+// Provider returns the name of the challenge provider, empty when none is configured
+func (d *Detector) Provider() string {
+	if d.provider == nil {
+		return ""
+	}
+	return d.cfg.Challenge.Provider
+}
+
+// Assess scores a request from ip and counts it against the rate of ip.
+// Requests to challenge routes reaching the challenge score must pass a
+// challenge, unless ip passed one recently; without a provider they are
+// blocked. Challenged and blocked requests are counted by action.
+func (d *Detector) Assess(ctx context.Context, r *http.Request, ip string, challengeRoute bool) Assessment {
+	assessment := Assessment{Action: ActionAllow}
+	for _, signal := range d.headerSignals(r) {
+		assessment.add(signal)
+	}
+	if signal := d.rateSignal(ctx, ip); signal != "" {
+		assessment.add(signal)
+	}
+
+	switch {
+	case assessment.Score >= d.cfg.BlockScore:
+		assessment.Action = ActionBlock
+	case challengeRoute && assessment.Score >= d.cfg.ChallengeScore && !d.passed(ctx, ip):
+		assessment.Action = ActionChallenge
+		if d.provider == nil {
+			assessment.Action = ActionBlock
+		}
+	}
+	if assessment.Action != ActionAllow {
+		mode := "enforce"
+		if d.cfg.Monitor {
+			mode = "monitor"
+		}
+		decisionsTotal.WithLabelValues(assessment.Action, mode).Inc()
+	}
+	return assessment
+}
+
+// VerifyChallenge verifies a challenge token solved by ip. Once it passes, ip is
+// not challenged again for the pass TTL.
+func (d *Detector) VerifyChallenge(ctx context.Context, token, ip string) error {
+	if d.provider == nil {
+		return fmt.Errorf("%w: no challenge provider is configured", ErrChallengeFailed)
+	}
+	if err := d.provider.Verify(ctx, token, ip); err != nil {
+		result := "error"
+		if errors.Is(err, ErrChallengeFailed) {
+			result = "failed"
+		}
+		challengesTotal.WithLabelValues(result).Inc()
+		return err
+	}
+	challengesTotal.WithLabelValues("passed").Inc()
+
+	if d.cfg.Challenge.PassTTL > 0 {
+		if _, err := d.store.Increment(ctx, passKey(ip), d.clock.Now().Add(d.cfg.Challenge.PassTTL)); err != nil {
+			logger.Warn("Failed to record the passed challenge of %s: %v", ip, err)
+		}
+	}
+	return nil
+}
+
+// OnStop closes the counter store when it holds a connection
+func (d *Detector) OnStop(ctx context.Context) error {
+	if closer, ok := d.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// headerSignals returns the signals of the request headers
+func (d *Detector) headerSignals(r *http.Request) []string {
+	var signals []string
+	userAgent := strings.ToLower(r.UserAgent())
+	switch {
+	case userAgent == "":
+		signals = append(signals, SignalMissingUserAgent)
+	case d.matchesUserAgent(userAgent):
+		signals = append(signals, SignalBotUserAgent)
+	}
+	if r.Header.Get("Accept") == "" {
+		signals = append(signals, SignalMissingAccept)
+	}
+	if r.Header.Get("Accept-Language") == "" {
+		signals = append(signals, SignalMissingAcceptLanguage)
+	}
+	return signals
+}
+
+// matchesUserAgent reports whether the lower cased userAgent contains one of the configured ones
+func (d *Detector) matchesUserAgent(userAgent string) bool {
+	for _, bot := range d.userAgents {
+		if strings.Contains(userAgent, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// rateSignal counts a request of ip in the current rate window and returns the
+// rate signal it raises. Counting errors skip the signal.
+func (d *Detector) rateSignal(ctx context.Context, ip string) string {
+	window := d.clock.Now().Truncate(d.cfg.RateWindow)
+	key := "bot:requests:" + ip + ":" + strconv.FormatInt(window.Unix(), 10)
+	count, err := d.store.Increment(ctx, key, window.Add(d.cfg.RateWindow))
+	if err != nil {
+		logger.Warn("Bot detection rate check skipped: %v", err)
+		return ""
+	}
+
+	limit := int64(d.cfg.RateLimit)
+	switch {
+	case count > 2*limit:
+		return SignalVeryHighRate
+	case count > limit:
+		return SignalHighRate
+	default:
+		return ""
+	}
+}
+
+// passed reports whether ip passed a challenge within the pass TTL
+func (d *Detector) passed(ctx context.Context, ip string) bool {
+	if d.cfg.Challenge.PassTTL <= 0 {
+		return false
+	}
+	count, err := d.store.Get(ctx, passKey(ip))
+	if err != nil {
+		logger.Warn("Failed to look up the passed challenge of %s: %v", ip, err)
+		return false
+	}
+	return count > 0
+}
+
+// add adds a signal and its weight to the assessment
+func (a *Assessment) add(signal string) {
+	a.Signals = append(a.Signals, signal)
+	a.Score += signalWeights[signal]
+}
+
+// passKey is the counter key recording that ip passed a challenge
+func passKey(ip string) string {
+	return "bot:passed:" + ip
+}
@@ -0,0 +1,154 @@
+package botdetect
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+)
+
+// Built-in challenge providers
+const (
+	ProviderTurnstile = "turnstile"
+	ProviderRecaptcha = "recaptcha"
+)
+
+// Verification endpoints of the built-in providers
+const (
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	RecaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+)
+
+// ErrChallengeFailed is returned for tokens the provider rejects
+var ErrChallengeFailed = errors.New("challenge verification failed")
+
+// ChallengeProvider verifies the tokens clients obtain by solving a challenge
+type ChallengeProvider interface {
+	// Verify returns ErrChallengeFailed when token was not issued to a client
+	// at remoteIP, and other errors when the provider cannot be reached
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// ChallengeProviderFactory creates a provider from the challenge configuration.
+// Providers calling HTTP APIs should use a client of clients.
+type ChallengeProviderFactory func(cfg config.BotChallengeConfig, clients *httpclient.Factory) (ChallengeProvider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ChallengeProviderFactory{
+		ProviderTurnstile: func(cfg config.BotChallengeConfig, clients *httpclient.Factory) (ChallengeProvider, error) {
+			return NewSiteVerifyProvider(clients.New("challenge-turnstile"), verifyURL(cfg, TurnstileVerifyURL), cfg.SecretKey, 0), nil
+		},
+		ProviderRecaptcha: func(cfg config.BotChallengeConfig, clients *httpclient.Factory) (ChallengeProvider, error) {
+			return NewSiteVerifyProvider(clients.New("challenge-recaptcha"), verifyURL(cfg, RecaptchaVerifyURL), cfg.SecretKey, cfg.MinScore), nil
+		},
+	}
+)
+
+// RegisterChallengeProvider makes a challenge provider such as hcaptcha available
+// under name, replacing any provider registered under the same name
+func RegisterChallengeProvider(name string, factory ChallengeProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// ChallengeProviders returns the names of the registered providers, sorted
+func ChallengeProviders() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newChallengeProvider creates the configured provider, nil when none is configured
+func newChallengeProvider(cfg config.BotChallengeConfig, clients *httpclient.Factory) (ChallengeProvider, error) {
+	if cfg.Provider == "" {
+		return nil, nil
+	}
+	providersMu.RLock()
+	factory, ok := providers[cfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported challenge provider: %s", cfg.Provider)
+	}
+	provider, err := factory(cfg, clients)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create challenge provider %s: %w", cfg.Provider, err)
+	}
+	return provider, nil
+}
+
+// verifyURL returns the configured verification endpoint, or fallback
+func verifyURL(cfg config.BotChallengeConfig, fallback string) string {
+	if cfg.VerifyURL != "" {
+		return cfg.VerifyURL
+	}
+	return fallback
+}
+
+// SiteVerifyProvider verifies tokens with a siteverify endpoint, the API shared by
+// Cloudflare Turnstile, Google reCAPTCHA and hCaptcha
+type SiteVerifyProvider struct {
+	client   *http.Client
+	url      string
+	secret   string
+	minScore float64
+}
+
+// siteVerifyResponse is the body returned by a siteverify endpoint
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+	// Score is only returned by reCAPTCHA v3
+	Score *float64 `json:"score"`
+}
+
+// NewSiteVerifyProvider creates a provider posting tokens to endpoint. Tokens whose
+// response carries a score below minScore are rejected; 0 accepts any score.
+func NewSiteVerifyProvider(client *http.Client, endpoint, secret string, minScore float64) *SiteVerifyProvider {
+	return &SiteVerifyProvider{client: client, url: endpoint, secret: secret, minScore: minScore}
+}
+
+// Verify posts token to the siteverify endpoint
+func (p *SiteVerifyProvider) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{"secret": {p.secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("invalid challenge verification URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err := httpclient.Check(resp, err); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode challenge verification response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrChallengeFailed, strings.Join(result.ErrorCodes, ", "))
+	}
+	if result.Score != nil && *result.Score < p.minScore {
+		return fmt.Errorf("%w: score %.1f is below %.1f", ErrChallengeFailed, *result.Score, p.minScore)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package broker
+
+import (
+	"context"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Headers of bridged domain events
+const (
+	HeaderEventType = "Event-Type"
+	HeaderEventTime = "Event-Time"
+)
+
+// bridgePublishTimeout bounds publishing a bridged event, which happens on the path of the publishing request
+const bridgePublishTimeout = 5 * time.Second
+
+// Bridge forwards domain events from the event bus to the broker. The event
+// payload is encoded with the codec and the event type and time are carried in
+// the Event-Type and Event-Time headers. Events that fail to publish are logged
+// and not retried.
+type Bridge struct {
+	bus       event.Bus
+	publisher Publisher
+	codec     Codec
+	events    []string
+	prefix    string
+
+	unsubscribe []func()
+}
+
+// NewBridge creates a bridge for the configured event types
+func NewBridge(bus event.Bus, publisher Publisher, codec Codec, cfg *config.BrokerBridgeConfig) *Bridge {
+	return &Bridge{
+		bus:       bus,
+		publisher: publisher,
+		codec:     codec,
+		events:    cfg.Events,
+		prefix:    cfg.TopicPrefix,
+	}
+}
+
+// Topic returns the broker topic of a domain event type
+func (b *Bridge) Topic(eventType string) string {
+	return b.prefix + eventType
+}
+
+// OnStart subscribes to the bridged event types
+func (b *Bridge) OnStart(ctx context.Context) error {
+	for _, eventType := range b.events {
+		b.unsubscribe = append(b.unsubscribe, b.bus.Subscribe(eventType, b.forward))
+	}
+	if len(b.events) > 0 {
+		logger.Info("Bridging domain events %v to the broker", b.events)
+	}
+	return nil
+}
+
+// OnStop unsubscribes from the event bus
+func (b *Bridge) OnStop(ctx context.Context) error {
+	for _, unsubscribe := range b.unsubscribe {
+		unsubscribe()
+	}
+	b.unsubscribe = nil
+	return nil
+}
+
+// forward publishes a domain event to its topic
+func (b *Bridge) forward(ctx context.Context, e event.Event) {
+	topic := b.Topic(e.Type)
+	msg, err := Encode(b.codec, topic, e.Payload)
+	if err != nil {
+		logger.Error("Failed to bridge event %s: %v", e.Type, err)
+		return
+	}
+	msg.Headers[HeaderEventType] = e.Type
+	msg.Headers[HeaderEventTime] = e.Timestamp.UTC().Format(time.RFC3339Nano)
+
+	// The request may finish before the broker accepts the event
+	publishCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), bridgePublishTimeout)
+	defer cancel()
+	if err := b.publisher.Publish(publishCtx, msg); err != nil {
+		logger.Error("Failed to bridge event %s to %s: %v", e.Type, topic, err)
+	}
+}
@@ -0,0 +1,133 @@
+package broker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Broker drivers
+const (
+	DriverMemory = "memory"
+	DriverNATS   = "nats"
+)
+
+// HeaderContentType is the message header naming the codec of the data
+const HeaderContentType = "Content-Type"
+
+// ErrClosed is returned when using a closed broker
+var ErrClosed = errors.New("broker is closed")
+
+// Message is a message exchanged through a broker
+type Message struct {
+	Topic string
+	// ID identifies the message for deduplication and logging; generated when empty
+	ID      string
+	Headers map[string]string
+	Data    []byte
+	// Delivery counts the deliveries of a received message, starting at 1
+	Delivery int
+}
+
+// Handler processes a received message. Returning an error redelivers the message
+// after the retry backoff; messages whose handler panics are redelivered as well.
+type Handler func(ctx context.Context, msg *Message) error
+
+// Publisher publishes messages to topics
+type Publisher interface {
+	// Publish returns once the broker has accepted the message
+	Publish(ctx context.Context, msg *Message) error
+}
+
+// Subscription is an active subscription to a topic
+type Subscription interface {
+	// Unsubscribe stops the delivery of messages; the subscription of a group
+	// keeps its position so that a later subscription resumes from there
+	Unsubscribe() error
+}
+
+// Subscriber subscribes to topics as part of a consumer group
+type Subscriber interface {
+	// Subscribe delivers every message of topic to one subscriber of group, at
+	// least once, until the handler succeeds or max_deliver is reached
+	Subscribe(ctx context.Context, topic, group string, handler Handler) (Subscription, error)
+}
+
+// Broker publishes and subscribes to messages
+type Broker interface {
+	Publisher
+	Subscriber
+	// Close stops all subscriptions and releases the connection
+	Close() error
+}
+
+// Options are the delivery settings shared by the drivers
+type Options struct {
+	MaxDeliver   int
+	RetryBackoff time.Duration
+	AckWait      time.Duration
+}
+
+// New creates a broker with the configured driver
+func New(cfg *config.BrokerConfig) (Broker, error) {
+	opts := Options{MaxDeliver: cfg.MaxDeliver, RetryBackoff: cfg.RetryBackoff, AckWait: cfg.AckWait}
+	switch cfg.Driver {
+	case DriverMemory, "":
+		return NewMemoryBroker(opts), nil
+	case DriverNATS:
+		return NewNATSBroker(&cfg.NATS, opts)
+	default:
+		return nil, fmt.Errorf("unsupported broker driver: %s", cfg.Driver)
+	}
+}
+
+var (
+	// Published message counter
+	publishedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broker_messages_published_total",
+			Help: "Total number of messages published to the broker",
+		},
+		[]string{"topic", "status"},
+	)
+
+	// Consumed message counter
+	consumedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "broker_messages_consumed_total",
+			Help: "Total number of messages handled from the broker",
+		},
+		[]string{"topic", "group", "status"},
+	)
+)
+
+// Consumed message statuses
+const (
+	statusAcked   = "acked"
+	statusRetried = "retried"
+	statusDropped = "dropped"
+)
+
+// recordPublish records the outcome of a publish
+func recordPublish(topic string, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	publishedTotal.WithLabelValues(topic, status).Inc()
+}
+
+// handle runs handler, converting a panic into an error
+func handle(ctx context.Context, handler Handler, msg *Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("message handler panicked: %v", r)
+		}
+	}()
+	return handler(ctx, msg)
+}
@@ -0,0 +1,91 @@
+package broker
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// Codec names
+const (
+	CodecJSON     = "json"
+	CodecProtobuf = "protobuf"
+)
+
+// Codec encodes values into message data
+type Codec interface {
+	// ContentType is set in the Content-Type header of encoded messages
+	ContentType() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// NewCodec returns the codec with the given name; an empty name selects JSON
+func NewCodec(name string) (Codec, error) {
+	switch name {
+	case CodecJSON, "":
+		return JSONCodec{}, nil
+	case CodecProtobuf:
+		return ProtobufCodec{}, nil
+	default:
+		return nil, fmt.Errorf("unsupported broker codec: %s", name)
+	}
+}
+
+// JSONCodec encodes values as JSON
+type JSONCodec struct{}
+
+// ContentType implements Codec
+func (JSONCodec) ContentType() string {
+	return "application/json"
+}
+
+// Marshal implements Codec
+func (JSONCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal implements Codec
+func (JSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// ProtobufCodec encodes protocol buffer messages in the binary wire format
+type ProtobufCodec struct{}
+
+// ContentType implements Codec
+func (ProtobufCodec) ContentType() string {
+	return "application/x-protobuf"
+}
+
+// Marshal implements Codec; v must be a proto.Message
+func (ProtobufCodec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("protobuf codec cannot encode %T, which is not a proto.Message", v)
+	}
+	return proto.Marshal(m)
+}
+
+// Unmarshal implements Codec; v must be a proto.Message
+func (ProtobufCodec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("protobuf codec cannot decode into %T, which is not a proto.Message", v)
+	}
+	return proto.Unmarshal(data, m)
+}
+
+// Encode builds a message for topic with v encoded by codec
+func Encode(codec Codec, topic string, v interface{}) (*Message, error) {
+	data, err := codec.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode message for %s: %w", topic, err)
+	}
+	return &Message{
+		Topic:   topic,
+		Headers: map[string]string{HeaderContentType: codec.ContentType()},
+		Data:    data,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package broker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// memoryQueueSize bounds the messages waiting for the members of a group; publishing blocks when it is full
+const memoryQueueSize = 1024
+
+// MemoryBroker delivers messages in process, for development and single instance
+// deployments. Messages are kept for the groups that subscribed to their topic
+// and are lost on restart; messages of topics without groups are discarded.
+type MemoryBroker struct {
+	opts Options
+
+	mu     sync.Mutex
+	groups map[string]map[string]*memoryGroup // topic -> group
+	closed bool
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// memoryGroup holds the pending messages of a consumer group; its members compete for them
+type memoryGroup struct {
+	queue chan *Message
+}
+
+// memorySubscription is a member of a group
+type memorySubscription struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMemoryBroker creates an in-process broker
+func NewMemoryBroker(opts Options) *MemoryBroker {
+	return &MemoryBroker{
+		opts:   opts,
+		groups: make(map[string]map[string]*memoryGroup),
+		done:   make(chan struct{}),
+	}
+}
+
+// Publish queues the message for every group subscribed to its topic
+func (b *MemoryBroker) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	groups := make([]*memoryGroup, 0, len(b.groups[msg.Topic]))
+	for _, group := range b.groups[msg.Topic] {
+		groups = append(groups, group)
+	}
+	b.mu.Unlock()
+
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+	for _, group := range groups {
+		copied := *msg
+		copied.Delivery = 0
+		select {
+		case group.queue <- &copied:
+		case <-ctx.Done():
+			recordPublish(msg.Topic, ctx.Err())
+			return ctx.Err()
+		case <-b.done:
+			return ErrClosed
+		}
+	}
+	recordPublish(msg.Topic, nil)
+	return nil
+}
+
+// Subscribe adds a member to group, creating the group on first use
+func (b *MemoryBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	if b.groups[topic] == nil {
+		b.groups[topic] = make(map[string]*memoryGroup)
+	}
+	g, ok := b.groups[topic][group]
+	if !ok {
+		g = &memoryGroup{queue: make(chan *Message, memoryQueueSize)}
+		b.groups[topic][group] = g
+	}
+
+	sub := &memorySubscription{stop: make(chan struct{})}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		b.consume(topic, group, g, sub, handler)
+	}()
+	return sub, nil
+}
+
+// consume handles the messages of a group until the member stops or the broker closes
+func (b *MemoryBroker) consume(topic, group string, g *memoryGroup, sub *memorySubscription, handler Handler) {
+	for {
+		select {
+		case msg := <-g.queue:
+			msg.Delivery++
+			err := handle(context.Background(), handler, msg)
+			if err == nil {
+				consumedTotal.WithLabelValues(topic, group, statusAcked).Inc()
+				continue
+			}
+			if b.opts.MaxDeliver > 0 && msg.Delivery >= b.opts.MaxDeliver {
+				logger.Error("Dropping message %s of %s after %d deliveries to group %s: %v", msg.ID, topic, msg.Delivery, group, err)
+				consumedTotal.WithLabelValues(topic, group, statusDropped).Inc()
+				continue
+			}
+			logger.Warn("Redelivering message %s of %s to group %s in %s: %v", msg.ID, topic, group, b.opts.RetryBackoff, err)
+			consumedTotal.WithLabelValues(topic, group, statusRetried).Inc()
+			b.redeliver(g, msg)
+		case <-sub.stop:
+			return
+		case <-b.done:
+			return
+		}
+	}
+}
+
+// redeliver queues the message again after the retry backoff
+func (b *MemoryBroker) redeliver(g *memoryGroup, msg *Message) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		select {
+		case <-time.After(b.opts.RetryBackoff):
+		case <-b.done:
+			return
+		}
+		select {
+		case g.queue <- msg:
+		case <-b.done:
+		}
+	}()
+}
+
+// Close stops the subscriptions and discards the pending messages
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.done)
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
+
+// OnStop closes the broker when the container stops
+func (b *MemoryBroker) OnStop(ctx context.Context) error {
+	return b.Close()
+}
+
+// Unsubscribe stops the member; the group keeps receiving messages for later members
+func (s *memorySubscription) Unsubscribe() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	return nil
+}
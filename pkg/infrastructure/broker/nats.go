@@ -0,0 +1,250 @@
+package broker
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// defaultNATSConnectTimeout bounds connecting and creating the stream when no timeout is configured
+const defaultNATSConnectTimeout = 5 * time.Second
+
+// invalidConsumerChars matches the characters not allowed in JetStream consumer names
+var invalidConsumerChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// NATSBroker exchanges messages through a NATS JetStream stream. Topics are
+// subjects under the stream name and consumer groups are durable consumers, so
+// messages published while a group is offline are delivered once it subscribes.
+type NATSBroker struct {
+	opts   Options
+	stream string
+	conn   *nats.Conn
+	js     jetstream.JetStream
+
+	mu     sync.Mutex
+	subs   map[*natsSubscription]struct{}
+	closed bool
+}
+
+// natsSubscription consumes the durable consumer of a group
+type natsSubscription struct {
+	broker  *NATSBroker
+	consume jetstream.ConsumeContext
+	once    sync.Once
+}
+
+// NewNATSBroker connects to NATS and creates the stream when it is missing
+func NewNATSBroker(cfg *config.BrokerNATSConfig, opts Options) (*NATSBroker, error) {
+	timeout := cfg.ConnectTimeout
+	if timeout <= 0 {
+		timeout = defaultNATSConnectTimeout
+	}
+
+	natsOpts := []nats.Option{
+		nats.Name("server-tpl"),
+		nats.Timeout(timeout),
+		nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				logger.Warn("Disconnected from NATS: %v", err)
+			}
+		}),
+		nats.ReconnectHandler(func(conn *nats.Conn) {
+			logger.Info("Reconnected to NATS at %s", conn.ConnectedUrl())
+		}),
+	}
+	if cfg.Username != "" {
+		natsOpts = append(natsOpts, nats.UserInfo(cfg.Username, cfg.Password))
+	}
+	if cfg.Token != "" {
+		natsOpts = append(natsOpts, nats.Token(cfg.Token))
+	}
+
+	conn, err := nats.Connect(cfg.URL, natsOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	replicas := cfg.Replicas
+	if replicas < 1 {
+		replicas = 1
+	}
+	if _, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: []string{cfg.Stream + ".>"},
+		Replicas: replicas,
+		MaxAge:   cfg.MaxAge,
+		Storage:  jetstream.FileStorage,
+	}); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to create stream %s: %w", cfg.Stream, err)
+	}
+
+	logger.Info("Connected to NATS JetStream at %s, stream %s", conn.ConnectedUrl(), cfg.Stream)
+	return &NATSBroker{
+		opts:   opts,
+		stream: cfg.Stream,
+		conn:   conn,
+		js:     js,
+		subs:   make(map[*natsSubscription]struct{}),
+	}, nil
+}
+
+// subject returns the subject of topic in the stream
+func (b *NATSBroker) subject(topic string) string {
+	return b.stream + "." + topic
+}
+
+// Publish publishes the message and waits for the stream to store it. The
+// message ID is used by JetStream to drop duplicates within its window.
+func (b *NATSBroker) Publish(ctx context.Context, msg *Message) error {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return ErrClosed
+	}
+	if msg.ID == "" {
+		msg.ID = uuid.NewString()
+	}
+
+	natsMsg := nats.NewMsg(b.subject(msg.Topic))
+	for key, value := range msg.Headers {
+		natsMsg.Header.Set(key, value)
+	}
+	natsMsg.Data = msg.Data
+
+	_, err := b.js.PublishMsg(ctx, natsMsg, jetstream.WithMsgID(msg.ID))
+	recordPublish(msg.Topic, err)
+	if err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", msg.Topic, err)
+	}
+	return nil
+}
+
+// Subscribe consumes topic through the durable consumer of group, creating it
+// to start from the oldest message in the stream
+func (b *NATSBroker) Subscribe(ctx context.Context, topic, group string, handler Handler) (Subscription, error) {
+	b.mu.Lock()
+	closed := b.closed
+	b.mu.Unlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	maxDeliver := b.opts.MaxDeliver
+	if maxDeliver <= 0 {
+		maxDeliver = -1
+	}
+	name := invalidConsumerChars.ReplaceAllString(group+"_"+topic, "_")
+
+	consumer, err := b.js.CreateOrUpdateConsumer(ctx, b.stream, jetstream.ConsumerConfig{
+		Durable:       name,
+		FilterSubject: b.subject(topic),
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.opts.AckWait,
+		MaxDeliver:    maxDeliver,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer %s: %w", name, err)
+	}
+
+	consume, err := consumer.Consume(func(m jetstream.Msg) {
+		b.dispatch(topic, group, m, handler)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume %s: %w", name, err)
+	}
+
+	sub := &natsSubscription{broker: b, consume: consume}
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub, nil
+}
+
+// dispatch runs the handler of a delivered message and acknowledges it, or
+// requests its redelivery when the handler fails
+func (b *NATSBroker) dispatch(topic, group string, m jetstream.Msg, handler Handler) {
+	msg := &Message{
+		Topic:   topic,
+		ID:      m.Headers().Get(jetstream.MsgIDHeader),
+		Headers: make(map[string]string, len(m.Headers())),
+		Data:    m.Data(),
+	}
+	for key := range m.Headers() {
+		msg.Headers[key] = m.Headers().Get(key)
+	}
+	if meta, err := m.Metadata(); err == nil {
+		msg.Delivery = int(meta.NumDelivered)
+	}
+
+	err := handle(context.Background(), handler, msg)
+	switch {
+	case err == nil:
+		if ackErr := m.Ack(); ackErr != nil {
+			logger.Warn("Failed to acknowledge message %s of %s: %v", msg.ID, topic, ackErr)
+		}
+		consumedTotal.WithLabelValues(topic, group, statusAcked).Inc()
+	case b.opts.MaxDeliver > 0 && msg.Delivery >= b.opts.MaxDeliver:
+		logger.Error("Dropping message %s of %s after %d deliveries to group %s: %v", msg.ID, topic, msg.Delivery, group, err)
+		_ = m.Term()
+		consumedTotal.WithLabelValues(topic, group, statusDropped).Inc()
+	default:
+		logger.Warn("Redelivering message %s of %s to group %s in %s: %v", msg.ID, topic, group, b.opts.RetryBackoff, err)
+		_ = m.NakWithDelay(b.opts.RetryBackoff)
+		consumedTotal.WithLabelValues(topic, group, statusRetried).Inc()
+	}
+}
+
+// Close stops the subscriptions and drains the connection
+func (b *NATSBroker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	subs := make([]*natsSubscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		_ = sub.Unsubscribe()
+	}
+	return b.conn.Drain()
+}
+
+// OnStop closes the broker when the container stops
+func (b *NATSBroker) OnStop(ctx context.Context) error {
+	return b.Close()
+}
+
+// Unsubscribe stops consuming; the durable consumer keeps the position of the group
+func (s *natsSubscription) Unsubscribe() error {
+	s.once.Do(func() {
+		s.consume.Stop()
+		s.broker.mu.Lock()
+		delete(s.broker.subs, s)
+		s.broker.mu.Unlock()
+	})
+	return nil
+}
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
@@ -16,6 +18,10 @@ type MemoryCache struct {
 	data   map[string]*cacheItem
 	mutex  sync.RWMutex
 	config *datastore.CacheConfig
+	clock  clock.Clock
+	stop   chan struct{}
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
 type cacheItem struct {
@@ -23,17 +29,38 @@ type cacheItem struct {
 	ExpiresAt time.Time
 }
 
-// NewMemoryCache creates a new memory cache instance
-func NewMemoryCache(config *datastore.CacheConfig) datastore.Cache {
-	cache := &MemoryCache{
+// NewMemoryCache creates a new memory cache instance whose TTLs are measured
+// on clk. Expired items are removed in the background between OnStart and OnStop.
+func NewMemoryCache(config *datastore.CacheConfig, clk clock.Clock) datastore.Cache {
+	return &MemoryCache{
 		data:   make(map[string]*cacheItem),
 		config: config,
+		clock:  clk,
 	}
+}
+
+// OnStart starts the cleanup goroutine
+func (c *MemoryCache) OnStart(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.stop == nil {
+		c.stop = make(chan struct{})
+		go c.cleanup(c.stop)
+	}
+	return nil
+}
 
-	// Start cleanup goroutine
-	go cache.cleanup()
+// OnStop stops the cleanup goroutine
+func (c *MemoryCache) OnStop(ctx context.Context) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	return cache
+	if c.stop != nil {
+		close(c.stop)
+		c.stop = nil
+	}
+	return nil
 }
 
 // Get retrieves a value from cache
@@ -43,18 +70,32 @@ func (c *MemoryCache) Get(ctx context.Context, key string) (interface{}, error)
 
 	item, exists := c.data[key]
 	if !exists {
+		c.misses.Add(1)
 		return nil, datastore.ErrNotFound
 	}
 
-	// Check expiration
-	if time.Now().After(item.ExpiresAt) {
-		delete(c.data, key)
+	// Expired items are removed by the cleanup loop, not under the read lock
+	if c.clock.Now().After(item.ExpiresAt) {
+		c.misses.Add(1)
 		return nil, datastore.ErrNotFound
 	}
 
+	c.hits.Add(1)
 	return item.Value, nil
 }
 
+// Stats returns the hit and miss counts of Get and the number of stored items
+func (c *MemoryCache) Stats() datastore.CacheStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return datastore.CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Items:  len(c.data),
+	}
+}
+
 // Set stores a value in cache with TTL
 func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	c.mutex.Lock()
@@ -66,7 +107,7 @@ func (c *MemoryCache) Set(ctx context.Context, key string, value interface{}, tt
 
 	c.data[key] = &cacheItem{
 		Value:     value,
-		ExpiresAt: time.Now().Add(ttl),
+		ExpiresAt: c.clock.Now().Add(ttl),
 	}
 
 	return nil
@@ -100,9 +141,8 @@ func (c *MemoryCache) Exists(ctx context.Context, key string) (bool, error) {
 		return false, nil
 	}
 
-	// Check expiration
-	if time.Now().After(item.ExpiresAt) {
-		delete(c.data, key)
+	// Expired items are removed by the cleanup loop, not under the read lock
+	if c.clock.Now().After(item.ExpiresAt) {
 		return false, nil
 	}
 
@@ -115,22 +155,28 @@ func (c *MemoryCache) Expire(ctx context.Context, key string, ttl time.Duration)
 	defer c.mutex.Unlock()
 
 	item, exists := c.data[key]
-	if !exists {
+	if !exists || c.clock.Now().After(item.ExpiresAt) {
 		return datastore.ErrNotFound
 	}
 
-	item.ExpiresAt = time.Now().Add(ttl)
+	item.ExpiresAt = c.clock.Now().Add(ttl)
 	return nil
 }
 
-// cleanup removes expired items
-func (c *MemoryCache) cleanup() {
+// cleanup removes expired items until stop is closed
+func (c *MemoryCache) cleanup(stop <-chan struct{}) {
 	ticker := time.NewTicker(time.Minute)
 	defer ticker.Stop()
 
-	for range ticker.C {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
 		c.mutex.Lock()
-		now := time.Now()
+		now := c.clock.Now()
 		for key, item := range c.data {
 			if now.After(item.ExpiresAt) {
 				delete(c.data, key)
@@ -192,16 +238,18 @@ type CacheManager struct {
 	l1Cache datastore.Cache // Memory cache (L1)
 	l2Cache datastore.Cache // Redis cache (L2)
 	config  *datastore.CacheConfig
+	hits    atomic.Uint64
+	misses  atomic.Uint64
 }
 
 // NewCacheManager creates a new cache manager with L1 and L2 caches
-func NewCacheManager(config *datastore.CacheConfig) *CacheManager {
+func NewCacheManager(config *datastore.CacheConfig, clk clock.Clock) *CacheManager {
 	manager := &CacheManager{
 		config: config,
 	}
 
 	// Always create L1 (memory) cache
-	manager.l1Cache = NewMemoryCache(config)
+	manager.l1Cache = NewMemoryCache(config, clk)
 
 	// Create L2 (Redis) cache if configured
 	if config.Type == "redis" {
@@ -212,10 +260,27 @@ func NewCacheManager(config *datastore.CacheConfig) *CacheManager {
 	return manager
 }
 
+// OnStart starts the background work of the L1 cache
+func (m *CacheManager) OnStart(ctx context.Context) error {
+	if starter, ok := m.l1Cache.(interface{ OnStart(context.Context) error }); ok {
+		return starter.OnStart(ctx)
+	}
+	return nil
+}
+
+// OnStop stops the background work of the L1 cache
+func (m *CacheManager) OnStop(ctx context.Context) error {
+	if stopper, ok := m.l1Cache.(interface{ OnStop(context.Context) error }); ok {
+		return stopper.OnStop(ctx)
+	}
+	return nil
+}
+
 // Get retrieves value from cache (L1 first, then L2)
 func (m *CacheManager) Get(ctx context.Context, key string) (interface{}, error) {
 	// Try L1 cache first
 	if value, err := m.l1Cache.Get(ctx, key); err == nil {
+		m.hits.Add(1)
 		return value, nil
 	}
 
@@ -224,13 +289,24 @@ func (m *CacheManager) Get(ctx context.Context, key string) (interface{}, error)
 		if value, err := m.l2Cache.Get(ctx, key); err == nil {
 			// Store in L1 cache for faster access
 			m.l1Cache.Set(ctx, key, value, time.Minute*5)
+			m.hits.Add(1)
 			return value, nil
 		}
 	}
 
+	m.misses.Add(1)
 	return nil, datastore.ErrNotFound
 }
 
+// Stats returns the hit and miss counts across both layers and the number of items in L1
+func (m *CacheManager) Stats() datastore.CacheStats {
+	stats := datastore.CacheStats{Hits: m.hits.Load(), Misses: m.misses.Load()}
+	if provider, ok := m.l1Cache.(datastore.CacheStatsProvider); ok {
+		stats.Items = provider.Stats().Items
+	}
+	return stats
+}
+
 // Set stores value in both L1 and L2 caches
 func (m *CacheManager) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
 	// Store in L1 cache
@@ -0,0 +1,163 @@
+package datastoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// CacheFactory returns an empty cache for a single subtest. It registers any
+// cleanup with t and fails t when the cache cannot be created.
+type CacheFactory func(t *testing.T) datastore.Cache
+
+// RunCache runs the Cache conformance suite. Every subtest starts from an
+// empty cache returned by newCache. Values are strings, which every backend
+// returns unchanged.
+func RunCache(t *testing.T, newCache CacheFactory) {
+	t.Helper()
+
+	t.Run("SetGetDelete", func(t *testing.T) { testCacheSetGetDelete(t, newCache(t)) })
+	t.Run("NotFound", func(t *testing.T) { testCacheNotFound(t, newCache(t)) })
+	t.Run("Expiration", func(t *testing.T) { testCacheExpiration(t, newCache(t)) })
+	t.Run("Clear", func(t *testing.T) { testCacheClear(t, newCache(t)) })
+	t.Run("Concurrent", func(t *testing.T) { testCacheConcurrent(t, newCache(t)) })
+}
+
+func testCacheSetGetDelete(t *testing.T, cache datastore.Cache) {
+	ctx := context.Background()
+
+	if err := cache.Set(ctx, "key", "value", time.Minute); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	mustGet(t, cache, "key", "value")
+	if exists, err := cache.Exists(ctx, "key"); err != nil || !exists {
+		t.Fatalf("Exists = %v, %v, want true", exists, err)
+	}
+
+	if err := cache.Set(ctx, "key", "replaced", time.Minute); err != nil {
+		t.Fatalf("Set over an existing key: %v", err)
+	}
+	mustGet(t, cache, "key", "replaced")
+
+	if err := cache.Delete(ctx, "key"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := cache.Get(ctx, "key"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Get after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func testCacheNotFound(t *testing.T, cache datastore.Cache) {
+	ctx := context.Background()
+
+	if _, err := cache.Get(ctx, "missing"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("Get = %v, want ErrNotFound", err)
+	}
+	if exists, err := cache.Exists(ctx, "missing"); err != nil || exists {
+		t.Errorf("Exists = %v, %v, want false", exists, err)
+	}
+	if err := cache.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Delete of a missing key = %v, want nil", err)
+	}
+	if err := cache.Expire(ctx, "missing", time.Minute); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("Expire = %v, want ErrNotFound", err)
+	}
+}
+
+func testCacheExpiration(t *testing.T, cache datastore.Cache) {
+	ctx := context.Background()
+	// Redis expires keys with millisecond precision, so leave some margin
+	const ttl = 200 * time.Millisecond
+
+	if err := cache.Set(ctx, "short", "value", ttl); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := cache.Set(ctx, "extended", "value", ttl); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if err := cache.Expire(ctx, "extended", time.Minute); err != nil {
+		t.Fatalf("Expire: %v", err)
+	}
+
+	time.Sleep(2 * ttl)
+	if _, err := cache.Get(ctx, "short"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Get after the TTL = %v, want ErrNotFound", err)
+	}
+	if exists, err := cache.Exists(ctx, "short"); err != nil || exists {
+		t.Fatalf("Exists after the TTL = %v, %v, want false", exists, err)
+	}
+	if err := cache.Expire(ctx, "short", time.Minute); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Expire after the TTL = %v, want ErrNotFound", err)
+	}
+	mustGet(t, cache, "extended", "value")
+}
+
+func testCacheClear(t *testing.T, cache datastore.Cache) {
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if err := cache.Set(ctx, fmt.Sprintf("key-%d", i), "value", time.Minute); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if err := cache.Clear(ctx); err != nil {
+		t.Fatalf("Clear: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if exists, err := cache.Exists(ctx, fmt.Sprintf("key-%d", i)); err != nil || exists {
+			t.Fatalf("Exists after Clear = %v, %v, want false", exists, err)
+		}
+	}
+}
+
+func testCacheConcurrent(t *testing.T, cache datastore.Cache) {
+	ctx := context.Background()
+
+	// Short TTLs make readers race the expiration of the entries they read
+	errs := make(chan error, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key-%d", j%10)
+				if err := cache.Set(ctx, key, fmt.Sprint(i), time.Millisecond); err != nil {
+					errs <- fmt.Errorf("Set: %w", err)
+					return
+				}
+				if _, err := cache.Get(ctx, key); err != nil && !errors.Is(err, datastore.ErrNotFound) {
+					errs <- fmt.Errorf("Get: %w", err)
+					return
+				}
+				if _, err := cache.Exists(ctx, key); err != nil {
+					errs <- fmt.Errorf("Exists: %w", err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+}
+
+// mustGet fails t unless key holds want
+func mustGet(t *testing.T, cache datastore.Cache, key, want string) {
+	t.Helper()
+
+	got, err := cache.Get(context.Background(), key)
+	if err != nil {
+		t.Fatalf("Get(%s): %v", key, err)
+	}
+	if fmt.Sprint(got) != want {
+		t.Fatalf("Get(%s) = %v, want %s", key, got, want)
+	}
+}
@@ -0,0 +1,203 @@
+package datastoretest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/mongodb"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/postgresql"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/testcontainers/testcontainers-go"
+	tcmongodb "github.com/testcontainers/testcontainers-go/modules/mongodb"
+	tcpostgres "github.com/testcontainers/testcontainers-go/modules/postgres"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+	"github.com/testcontainers/testcontainers-go/wait"
+)
+
+// Container images started by the harnesses
+var (
+	PostgresImage = "postgres:16-alpine"
+	MongoImage    = "mongo:7"
+	RedisImage    = "redis:7-alpine"
+)
+
+// startupTimeout bounds the time a container takes to accept connections
+const startupTimeout = 2 * time.Minute
+
+// StartPostgres starts a PostgreSQL container that is removed when t
+// finishes and returns the database configuration pointing at it. It skips t
+// when Docker is not available.
+func StartPostgres(t *testing.T) config.DatabaseConfig {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	const database, user, password = "app_test", "app", "app"
+	container, err := tcpostgres.Run(ctx, PostgresImage,
+		tcpostgres.WithDatabase(database),
+		tcpostgres.WithUsername(user),
+		tcpostgres.WithPassword(password),
+		// The server restarts once after running the init scripts
+		testcontainers.WithWaitStrategy(wait.ForLog("database system is ready to accept connections").
+			WithOccurrence(2).WithStartupTimeout(startupTimeout)),
+	)
+	if container != nil {
+		terminateOnCleanup(t, container)
+	}
+	if err != nil {
+		t.Fatalf("failed to start PostgreSQL container: %v", err)
+	}
+
+	host, port := endpoint(t, container, "5432/tcp")
+	return config.DatabaseConfig{
+		Type:     "postgresql",
+		Host:     host,
+		Port:     port,
+		User:     user,
+		Password: password,
+		Database: database,
+		SSLMode:  "disable",
+	}
+}
+
+// NewPostgres opens a migrated PostgreSQL datastore on db with every table
+// emptied, closing it when t finishes. Timestamps are read from clk. Start the
+// container once with StartPostgres and call NewPostgres from the Factory
+// given to RunDatastore.
+func NewPostgres(t *testing.T, db config.DatabaseConfig, clk clock.Clock) datastore.DatastoreInterface {
+	t.Helper()
+
+	store, err := postgresql.New(&config.Config{Database: db}, clk)
+	if err != nil {
+		t.Fatalf("failed to connect to PostgreSQL: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("failed to migrate PostgreSQL: %v", err)
+	}
+	gormDB := store.(datastore.GormProvider).DB()
+	tables, err := gormDB.Migrator().GetTables()
+	if err != nil {
+		t.Fatalf("failed to list PostgreSQL tables: %v", err)
+	}
+	if len(tables) > 0 {
+		quoted := make([]string, 0, len(tables))
+		for _, table := range tables {
+			quoted = append(quoted, fmt.Sprintf("%q", table))
+		}
+		if err := gormDB.Exec("TRUNCATE " + strings.Join(quoted, ", ") + " RESTART IDENTITY CASCADE").Error; err != nil {
+			t.Fatalf("failed to empty PostgreSQL tables: %v", err)
+		}
+	}
+	return store
+}
+
+// StartMongo starts a single member MongoDB replica set, so that transactions
+// are available, that is removed when t finishes and returns the database
+// configuration pointing at it. It skips t when Docker is not available.
+func StartMongo(t *testing.T) config.DatabaseConfig {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	ctx := context.Background()
+	container, err := tcmongodb.Run(ctx, MongoImage, tcmongodb.WithReplicaSet("rs0"))
+	if container != nil {
+		terminateOnCleanup(t, container)
+	}
+	if err != nil {
+		t.Fatalf("failed to start MongoDB container: %v", err)
+	}
+
+	host, port := endpoint(t, container, "27017/tcp")
+	return config.DatabaseConfig{
+		Type: "mongodb",
+		// The replica set advertises the container address, connect to the
+		// mapped port without discovering the members
+		URI:      fmt.Sprintf("mongodb://%s/?directConnection=true", net.JoinHostPort(host, strconv.Itoa(port))),
+		Host:     host,
+		Port:     port,
+		Database: "app_test",
+	}
+}
+
+// NewMongo opens a MongoDB datastore on an empty, migrated db, closing it when
+// t finishes. Timestamps are read from clk. Start the container once with
+// StartMongo and call NewMongo from the Factory given to RunDatastore.
+func NewMongo(t *testing.T, db config.DatabaseConfig, clk clock.Clock) datastore.DatastoreInterface {
+	t.Helper()
+
+	store, err := mongodb.New(&config.Config{Database: db}, clk)
+	if err != nil {
+		t.Fatalf("failed to connect to MongoDB: %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	if err := store.(*mongodb.MongoDB).Drop(context.Background()); err != nil {
+		t.Fatalf("failed to drop MongoDB database: %v", err)
+	}
+	if err := store.Migrate(); err != nil {
+		t.Fatalf("failed to migrate MongoDB: %v", err)
+	}
+	return store
+}
+
+// StartRedis starts a Redis container that is removed when t finishes and
+// returns the Redis configuration pointing at it. It skips t when Docker is
+// not available.
+func StartRedis(t *testing.T) config.RedisConfig {
+	t.Helper()
+	testcontainers.SkipIfProviderIsNotHealthy(t)
+
+	container, err := tcredis.Run(context.Background(), RedisImage,
+		testcontainers.WithWaitStrategy(wait.ForLog("Ready to accept connections").WithStartupTimeout(startupTimeout)),
+	)
+	if container != nil {
+		terminateOnCleanup(t, container)
+	}
+	if err != nil {
+		t.Fatalf("failed to start Redis container: %v", err)
+	}
+
+	host, port := endpoint(t, container, "6379/tcp")
+	return config.RedisConfig{
+		Host:        host,
+		Port:        port,
+		PoolSize:    10,
+		DialTimeout: 5 * time.Second,
+	}
+}
+
+// terminateOnCleanup removes container when t finishes. Run may return a
+// container together with an error, so it is registered before checking it.
+func terminateOnCleanup(t *testing.T, container testcontainers.Container) {
+	t.Cleanup(func() {
+		if err := container.Terminate(context.Background()); err != nil {
+			t.Logf("failed to terminate container: %v", err)
+		}
+	})
+}
+
+// endpoint returns the host and mapped port of a container port
+func endpoint(t *testing.T, container testcontainers.Container, port nat.Port) (string, int) {
+	t.Helper()
+
+	ctx := context.Background()
+	host, err := container.Host(ctx)
+	if err != nil {
+		t.Fatalf("failed to get container host: %v", err)
+	}
+	mapped, err := container.MappedPort(ctx, port)
+	if err != nil {
+		t.Fatalf("failed to get mapped port %s: %v", port, err)
+	}
+	return host, mapped.Int()
+}
@@ -0,0 +1,55 @@
+package datastoretest_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/datastoretest"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// The container tests start their backend with Docker; they skip when Docker
+// is not available and with -short
+
+func TestContainerPostgreSQLConformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a PostgreSQL container")
+	}
+	db := datastoretest.StartPostgres(t)
+	datastoretest.RunDatastore(t, func(t *testing.T) datastore.DatastoreInterface {
+		return datastoretest.NewPostgres(t, db, clock.New())
+	})
+}
+
+func TestContainerMongoDBConformance(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a MongoDB container")
+	}
+	db := datastoretest.StartMongo(t)
+	datastoretest.RunDatastore(t, func(t *testing.T) datastore.DatastoreInterface {
+		return datastoretest.NewMongo(t, db, clock.New())
+	})
+}
+
+func TestContainerRedis(t *testing.T) {
+	if testing.Short() {
+		t.Skip("starts a Redis container")
+	}
+	redis := datastoretest.StartRedis(t)
+	client, err := middleware.NewRedisClient(&config.Config{Redis: redis})
+	if err != nil {
+		t.Fatalf("failed to connect to Redis: %v", err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	ctx := context.Background()
+	if err := client.Set(ctx, "conformance", "value", 0); err != nil {
+		t.Fatalf("Set: %v", err)
+	}
+	if got, err := client.Get(ctx, "conformance"); err != nil || got != "value" {
+		t.Fatalf("Get = %q, %v, want value", got, err)
+	}
+}
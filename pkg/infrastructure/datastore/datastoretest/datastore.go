@@ -0,0 +1,506 @@
+// Package datastoretest provides conformance suites for datastore and cache
+// implementations, plus container harnesses that start real backends for them.
+// Drivers run the suites from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		datastoretest.RunDatastore(t, func(t *testing.T) datastore.DatastoreInterface {
+//			store, _ := memory.New(clock.New())
+//			return store
+//		})
+//	}
+package datastoretest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// Factory returns an empty datastore for a single subtest. It registers any
+// cleanup with t and fails t when the datastore cannot be created.
+type Factory func(t *testing.T) datastore.DatastoreInterface
+
+// concurrency is the number of goroutines used by the concurrent subtests
+const concurrency = 16
+
+// RunDatastore runs the DatastoreInterface conformance suite. Every subtest
+// starts from an empty datastore returned by newStore.
+func RunDatastore(t *testing.T, newStore Factory) {
+	t.Helper()
+
+	t.Run("ApplicationCRUD", func(t *testing.T) { testApplicationCRUD(t, newStore(t)) })
+	t.Run("ApplicationNotFound", func(t *testing.T) { testApplicationNotFound(t, newStore(t)) })
+	t.Run("ApplicationDuplicateName", func(t *testing.T) { testApplicationDuplicateName(t, newStore(t)) })
+	t.Run("ApplicationPagination", func(t *testing.T) { testApplicationPagination(t, newStore(t)) })
+	t.Run("ConcurrentCreates", func(t *testing.T) { testConcurrentCreates(t, newStore(t)) })
+	t.Run("ConcurrentUpdates", func(t *testing.T) { testConcurrentUpdates(t, newStore(t)) })
+	t.Run("FeatureFlagCRUD", func(t *testing.T) { testFeatureFlagCRUD(t, newStore(t)) })
+	t.Run("FeatureFlagNotFound", func(t *testing.T) { testFeatureFlagNotFound(t, newStore(t)) })
+	t.Run("FeatureFlagDuplicateKey", func(t *testing.T) { testFeatureFlagDuplicateKey(t, newStore(t)) })
+	t.Run("Repository", func(t *testing.T) { testRepository(t, newStore(t)) })
+	t.Run("Transaction", func(t *testing.T) { testTransaction(t, newStore(t)) })
+	t.Run("HealthCheck", func(t *testing.T) {
+		if err := newStore(t).HealthCheck(); err != nil {
+			t.Fatalf("HealthCheck: %v", err)
+		}
+	})
+}
+
+func testApplicationCRUD(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	created := mustCreateApplication(t, store, "crud", "first")
+	if created.ID == 0 {
+		t.Fatal("CreateApplication did not assign an ID")
+	}
+	if created.CreatedAt.IsZero() || created.UpdatedAt.IsZero() {
+		t.Fatal("CreateApplication did not set the timestamps")
+	}
+
+	got, err := store.GetApplicationByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationByID: %v", err)
+	}
+	if got.Name != "crud" || got.Description != "first" {
+		t.Fatalf("GetApplicationByID = %q/%q, want crud/first", got.Name, got.Description)
+	}
+	if !sameTime(got.CreatedAt.Time, created.CreatedAt.Time) {
+		t.Fatalf("GetApplicationByID CreatedAt = %v, want %v", got.CreatedAt, created.CreatedAt)
+	}
+
+	byName, err := store.GetApplicationByName(ctx, "crud")
+	if err != nil {
+		t.Fatalf("GetApplicationByName: %v", err)
+	}
+	if byName.ID != created.ID {
+		t.Fatalf("GetApplicationByName ID = %d, want %d", byName.ID, created.ID)
+	}
+
+	got.Description = "second"
+	if _, err := store.UpdateApplication(ctx, got); err != nil {
+		t.Fatalf("UpdateApplication: %v", err)
+	}
+	updated, err := store.GetApplicationByID(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationByID after update: %v", err)
+	}
+	if updated.Description != "second" {
+		t.Fatalf("Description after update = %q, want second", updated.Description)
+	}
+	if !sameTime(updated.CreatedAt.Time, created.CreatedAt.Time) {
+		t.Fatalf("UpdateApplication changed CreatedAt from %v to %v", created.CreatedAt, updated.CreatedAt)
+	}
+	if updated.UpdatedAt.Before(created.UpdatedAt.Truncate(time.Microsecond)) {
+		t.Fatalf("UpdatedAt moved backwards from %v to %v", created.UpdatedAt, updated.UpdatedAt)
+	}
+
+	if err := store.DeleteApplication(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteApplication: %v", err)
+	}
+	if _, err := store.GetApplicationByID(ctx, created.ID); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("GetApplicationByID after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func testApplicationNotFound(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+	const missing = 987654
+
+	if _, err := store.GetApplicationByID(ctx, missing); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("GetApplicationByID = %v, want ErrNotFound", err)
+	}
+	if _, err := store.GetApplicationByName(ctx, "missing"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("GetApplicationByName = %v, want ErrNotFound", err)
+	}
+	app := &model.Application{BaseModel: model.BaseModel{ID: missing}, Name: "missing"}
+	if _, err := store.UpdateApplication(ctx, app); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("UpdateApplication = %v, want ErrNotFound", err)
+	}
+	if err := store.DeleteApplication(ctx, missing); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("DeleteApplication = %v, want ErrNotFound", err)
+	}
+	if _, err := store.GetApplicationByName(ctx, "missing"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("UpdateApplication of a missing ID created an application")
+	}
+}
+
+func testApplicationDuplicateName(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	mustCreateApplication(t, store, "taken", "")
+	if _, err := store.CreateApplication(ctx, &model.Application{Name: "taken"}); !errors.Is(err, datastore.ErrDuplicateKey) {
+		t.Fatalf("CreateApplication with a used name = %v, want ErrDuplicateKey", err)
+	}
+
+	other := mustCreateApplication(t, store, "other", "")
+	other.Name = "taken"
+	if _, err := store.UpdateApplication(ctx, other); !errors.Is(err, datastore.ErrDuplicateKey) {
+		t.Fatalf("UpdateApplication to a used name = %v, want ErrDuplicateKey", err)
+	}
+	if got, err := store.GetApplicationByID(ctx, other.ID); err != nil || got.Name != "other" {
+		t.Fatalf("GetApplicationByID after failed update = %v, %v, want name other", got, err)
+	}
+}
+
+func testApplicationPagination(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+	const total, pageSize = 25, 10
+
+	for i := 0; i < total; i++ {
+		mustCreateApplication(t, store, fmt.Sprintf("page-%02d", i), "")
+	}
+
+	seen := make(map[uint]bool, total)
+	var lastID uint
+	for page, want := range []int{10, 10, 5, 0} {
+		apps, count, err := store.ListApplications(ctx, page+1, pageSize)
+		if err != nil {
+			t.Fatalf("ListApplications page %d: %v", page+1, err)
+		}
+		if count != total {
+			t.Fatalf("ListApplications page %d total = %d, want %d", page+1, count, total)
+		}
+		if len(apps) != want {
+			t.Fatalf("ListApplications page %d returned %d applications, want %d", page+1, len(apps), want)
+		}
+		for _, app := range apps {
+			if seen[app.ID] {
+				t.Fatalf("ListApplications returned application %d on two pages", app.ID)
+			}
+			if app.ID <= lastID {
+				t.Fatalf("ListApplications is not ordered by ID: %d after %d", app.ID, lastID)
+			}
+			seen[app.ID] = true
+			lastID = app.ID
+		}
+	}
+}
+
+func testConcurrentCreates(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	errs := make([]error, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = store.CreateApplication(ctx, &model.Application{Name: "race", Description: fmt.Sprint(i)})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, datastore.ErrDuplicateKey):
+			t.Fatalf("concurrent CreateApplication = %v, want nil or ErrDuplicateKey", err)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("%d concurrent creates of the same name succeeded, want 1", created)
+	}
+}
+
+func testConcurrentUpdates(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	shared := mustCreateApplication(t, store, "shared", "initial")
+	own := make([]*model.Application, concurrency)
+	for i := range own {
+		own[i] = mustCreateApplication(t, store, fmt.Sprintf("own-%02d", i), "initial")
+	}
+
+	errs := make(chan error, 2*concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for _, id := range []uint{shared.ID, own[i].ID} {
+				app, err := store.GetApplicationByID(ctx, id)
+				if err != nil {
+					errs <- fmt.Errorf("GetApplicationByID(%d): %w", id, err)
+					return
+				}
+				app.Description = fmt.Sprintf("writer-%02d", i)
+				if _, err := store.UpdateApplication(ctx, app); err != nil {
+					errs <- fmt.Errorf("UpdateApplication(%d): %w", id, err)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	written := make(map[string]bool, concurrency)
+	for i, app := range own {
+		got, err := store.GetApplicationByID(ctx, app.ID)
+		if err != nil {
+			t.Fatalf("GetApplicationByID: %v", err)
+		}
+		want := fmt.Sprintf("writer-%02d", i)
+		if got.Description != want {
+			t.Fatalf("application %s description = %q, want %q", got.Name, got.Description, want)
+		}
+		written[want] = true
+	}
+	got, err := store.GetApplicationByID(ctx, shared.ID)
+	if err != nil {
+		t.Fatalf("GetApplicationByID: %v", err)
+	}
+	if !written[got.Description] || got.Name != "shared" {
+		t.Fatalf("shared application = %q/%q, want one of the concurrent writes", got.Name, got.Description)
+	}
+
+	_, total, err := store.ListApplications(ctx, 1, 1)
+	if err != nil {
+		t.Fatalf("ListApplications: %v", err)
+	}
+	if total != concurrency+1 {
+		t.Fatalf("concurrent updates left %d applications, want %d", total, concurrency+1)
+	}
+}
+
+func testFeatureFlagCRUD(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	for _, key := range []string{"beta", "alpha"} {
+		flag, err := store.CreateFeatureFlag(ctx, &model.FeatureFlag{Key: key, RolloutPercentage: 10})
+		if err != nil {
+			t.Fatalf("CreateFeatureFlag(%s): %v", key, err)
+		}
+		if flag.ID == 0 {
+			t.Fatalf("CreateFeatureFlag(%s) did not assign an ID", key)
+		}
+	}
+
+	flags, err := store.ListFeatureFlags(ctx)
+	if err != nil {
+		t.Fatalf("ListFeatureFlags: %v", err)
+	}
+	if len(flags) != 2 || flags[0].Key != "alpha" || flags[1].Key != "beta" {
+		t.Fatalf("ListFeatureFlags = %v, want alpha and beta ordered by key", flagKeys(flags))
+	}
+
+	created, err := store.GetFeatureFlagByKey(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("GetFeatureFlagByKey: %v", err)
+	}
+	update := &model.FeatureFlag{Key: "alpha", Enabled: true, RolloutPercentage: 50, TargetUsers: model.StringList{"u1"}}
+	if _, err := store.UpdateFeatureFlag(ctx, update); err != nil {
+		t.Fatalf("UpdateFeatureFlag: %v", err)
+	}
+	got, err := store.GetFeatureFlagByKey(ctx, "alpha")
+	if err != nil {
+		t.Fatalf("GetFeatureFlagByKey after update: %v", err)
+	}
+	if !got.Enabled || got.RolloutPercentage != 50 || len(got.TargetUsers) != 1 {
+		t.Fatalf("UpdateFeatureFlag was not applied: %+v", got)
+	}
+	if got.ID != created.ID || !sameTime(got.CreatedAt.Time, created.CreatedAt.Time) {
+		t.Fatalf("UpdateFeatureFlag changed the ID or creation time")
+	}
+
+	if err := store.DeleteFeatureFlag(ctx, "alpha"); err != nil {
+		t.Fatalf("DeleteFeatureFlag: %v", err)
+	}
+	if _, err := store.GetFeatureFlagByKey(ctx, "alpha"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("GetFeatureFlagByKey after delete = %v, want ErrNotFound", err)
+	}
+}
+
+func testFeatureFlagNotFound(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	if _, err := store.GetFeatureFlagByKey(ctx, "missing"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("GetFeatureFlagByKey = %v, want ErrNotFound", err)
+	}
+	if _, err := store.UpdateFeatureFlag(ctx, &model.FeatureFlag{Key: "missing"}); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("UpdateFeatureFlag = %v, want ErrNotFound", err)
+	}
+	if err := store.DeleteFeatureFlag(ctx, "missing"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Errorf("DeleteFeatureFlag = %v, want ErrNotFound", err)
+	}
+}
+
+func testFeatureFlagDuplicateKey(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	if _, err := store.CreateFeatureFlag(ctx, &model.FeatureFlag{Key: "taken"}); err != nil {
+		t.Fatalf("CreateFeatureFlag: %v", err)
+	}
+	if _, err := store.CreateFeatureFlag(ctx, &model.FeatureFlag{Key: "taken"}); !errors.Is(err, datastore.ErrDuplicateKey) {
+		t.Fatalf("CreateFeatureFlag with a used key = %v, want ErrDuplicateKey", err)
+	}
+}
+
+func testRepository(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	repo, err := datastore.NewRepository[*model.Application](store)
+	if err != nil {
+		t.Skipf("generic repositories are not supported: %v", err)
+	}
+	for _, name := range []string{"repo-b", "repo-c", "repo-a"} {
+		if _, err := repo.Create(ctx, &model.Application{Name: name, Description: "repo"}); err != nil {
+			t.Fatalf("Create(%s): %v", name, err)
+		}
+	}
+	if _, err := repo.Create(ctx, &model.Application{Name: "repo-a"}); !errors.Is(err, datastore.ErrDuplicateKey) {
+		t.Fatalf("Create with a used name = %v, want ErrDuplicateKey", err)
+	}
+
+	apps, err := repo.List(ctx, datastore.ListOptions{Page: 1, Size: 2, SortBy: "name", SortDesc: true})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+	if len(apps) != 2 || apps[0].Name != "repo-c" || apps[1].Name != "repo-b" {
+		t.Fatalf("List sorted by name desc = %v, want repo-c, repo-b", appNames(apps))
+	}
+
+	sorted, err := repo.List(ctx, datastore.ListOptions{Sort: []datastore.SortOrder{{Column: "description"}, {Column: "name", Desc: true}}})
+	if err != nil {
+		t.Fatalf("List with sort orders: %v", err)
+	}
+	if len(sorted) != 3 || sorted[0].Name != "repo-c" || sorted[2].Name != "repo-a" {
+		t.Fatalf("List sorted by description, name desc = %v, want repo-c, repo-b, repo-a", appNames(sorted))
+	}
+
+	ranged, err := repo.List(ctx, datastore.ListOptions{SortBy: "name", Ranges: map[string]datastore.Range{"name": {From: "repo-b"}}})
+	if err != nil {
+		t.Fatalf("List with ranges: %v", err)
+	}
+	if len(ranged) != 2 || ranged[0].Name != "repo-b" || ranged[1].Name != "repo-c" {
+		t.Fatalf("List with a name range from repo-b = %v, want repo-b, repo-c", appNames(ranged))
+	}
+	if count, err := repo.Count(ctx, datastore.ListOptions{Ranges: map[string]datastore.Range{"name": {From: "repo-a", To: "repo-b"}}}); err != nil || count != 2 {
+		t.Fatalf("Count with a name range = %d, %v, want 2", count, err)
+	}
+
+	filtered, err := repo.List(ctx, datastore.ListOptions{Filters: map[string]interface{}{"name": []string{"repo-a", "repo-c"}}})
+	if err != nil {
+		t.Fatalf("List with filters: %v", err)
+	}
+	if len(filtered) != 2 {
+		t.Fatalf("List with an IN filter = %v, want repo-a and repo-c", appNames(filtered))
+	}
+	count, err := repo.Count(ctx, datastore.ListOptions{Filters: map[string]interface{}{"description": "repo"}})
+	if err != nil {
+		t.Fatalf("Count: %v", err)
+	}
+	if count != 3 {
+		t.Fatalf("Count = %d, want 3", count)
+	}
+
+	if _, err := repo.List(ctx, datastore.ListOptions{SortBy: "name; drop table"}); err == nil {
+		t.Fatal("List accepted a sort column that is not an identifier")
+	}
+	if _, err := repo.List(ctx, datastore.ListOptions{Ranges: map[string]datastore.Range{"name or 1=1": {From: "a"}}}); err == nil {
+		t.Fatal("List accepted a range column that is not an identifier")
+	}
+	if _, err := repo.Get(ctx, 987654); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Get = %v, want ErrNotFound", err)
+	}
+	if _, err := repo.Update(ctx, &model.Application{BaseModel: model.BaseModel{ID: 987654}, Name: "missing"}); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Update = %v, want ErrNotFound", err)
+	}
+	if err := repo.Delete(ctx, 987654); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("Delete = %v, want ErrNotFound", err)
+	}
+
+	if _, err := repo.DeleteMatching(ctx, datastore.DeleteOptions{}); !errors.Is(err, datastore.ErrInvalidInput) {
+		t.Fatalf("DeleteMatching without filters = %v, want ErrInvalidInput", err)
+	}
+	ids := []uint{filtered[0].ID, filtered[1].ID, 987654}
+	if removed, err := repo.DeleteMatching(ctx, datastore.DeleteOptions{Filters: map[string]interface{}{"id": ids}}); err != nil || removed != 2 {
+		t.Fatalf("DeleteMatching = %d, %v, want 2", removed, err)
+	}
+	remaining, err := repo.List(ctx, datastore.ListOptions{})
+	if err != nil {
+		t.Fatalf("List after DeleteMatching: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].Name != "repo-b" {
+		t.Fatalf("List after DeleteMatching = %v, want repo-b", appNames(remaining))
+	}
+}
+
+func testTransaction(t *testing.T, store datastore.DatastoreInterface) {
+	ctx := context.Background()
+
+	tx, ok := store.(datastore.Transactional)
+	if !ok {
+		t.Skip("transactions are not supported")
+	}
+
+	if err := tx.Transaction(ctx, func(tx datastore.DatastoreInterface) error {
+		_, err := tx.CreateApplication(ctx, &model.Application{Name: "committed"})
+		return err
+	}); err != nil {
+		t.Fatalf("Transaction: %v", err)
+	}
+	if _, err := store.GetApplicationByName(ctx, "committed"); err != nil {
+		t.Fatalf("committed application: %v", err)
+	}
+
+	errRollback := errors.New("rollback")
+	err := tx.Transaction(ctx, func(tx datastore.DatastoreInterface) error {
+		if _, err := tx.CreateApplication(ctx, &model.Application{Name: "rolled-back"}); err != nil {
+			return err
+		}
+		return errRollback
+	})
+	if !errors.Is(err, errRollback) {
+		t.Fatalf("Transaction = %v, want the error returned by fn", err)
+	}
+	if _, err := store.GetApplicationByName(ctx, "rolled-back"); !errors.Is(err, datastore.ErrNotFound) {
+		t.Fatalf("rolled back application = %v, want ErrNotFound", err)
+	}
+}
+
+// mustCreateApplication creates an application or fails t
+func mustCreateApplication(t *testing.T, store datastore.DatastoreInterface, name, description string) *model.Application {
+	t.Helper()
+
+	app, err := store.CreateApplication(context.Background(), &model.Application{Name: name, Description: description})
+	if err != nil {
+		t.Fatalf("CreateApplication(%s): %v", name, err)
+	}
+	return app
+}
+
+// sameTime reports whether two timestamps are equal at the microsecond
+// precision kept by SQL databases
+func sameTime(a, b time.Time) bool {
+	return a.Truncate(time.Microsecond).Equal(b.Truncate(time.Microsecond))
+}
+
+// appNames returns the names of apps
+func appNames(apps []*model.Application) []string {
+	names := make([]string, 0, len(apps))
+	for _, app := range apps {
+		names = append(names, app.Name)
+	}
+	return names
+}
+
+// flagKeys returns the keys of flags
+func flagKeys(flags []*model.FeatureFlag) []string {
+	keys := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		keys = append(keys, flag.Key)
+	}
+	return keys
+}
@@ -0,0 +1,29 @@
+package datastoretest_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/cache"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/datastoretest"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/memory"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+func TestMemoryConformance(t *testing.T) {
+	datastoretest.RunDatastore(t, func(t *testing.T) datastore.DatastoreInterface {
+		store, err := memory.New(clock.New())
+		if err != nil {
+			t.Fatalf("failed to create memory datastore: %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+		return store
+	})
+}
+
+func TestMemoryCacheConformance(t *testing.T) {
+	datastoretest.RunCache(t, func(t *testing.T) datastore.Cache {
+		return cache.NewMemoryCache(&datastore.CacheConfig{TTL: time.Minute}, clock.New())
+	})
+}
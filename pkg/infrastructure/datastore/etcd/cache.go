@@ -0,0 +1,155 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// watchRetryDelay is the pause before watching again after the watch failed
+const watchRetryDelay = time.Second
+
+// cache holds recently read values, kept up to date by a watch on the prefix
+// of the store so that writes of other instances invalidate it. It is only
+// used while the watch runs; entries are dropped when it fails, as events may
+// have been missed. All methods are safe on a nil cache, which caches nothing.
+type cache struct {
+	mu       sync.Mutex
+	size     int
+	entries  map[string]cacheEntry
+	ready    bool
+	revision int64 // revision of the latest event applied
+}
+
+// cacheEntry is the value of a key at a revision, nil when the key does not exist
+type cacheEntry struct {
+	value       []byte
+	modRevision int64
+	revision    int64
+}
+
+// newCache creates a cache holding up to size entries
+func newCache(size int) *cache {
+	return &cache{size: size, entries: make(map[string]cacheEntry)}
+}
+
+// get returns the cached entry of key
+func (c *cache) get(key string) (cacheEntry, bool) {
+	if c == nil {
+		return cacheEntry{}, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	return e, ok && c.ready
+}
+
+// fill caches a key read at revision
+func (c *cache) fill(kv *mvccpb.KeyValue, revision int64) {
+	c.store(string(kv.Key), cacheEntry{value: kv.Value, modRevision: kv.ModRevision, revision: revision})
+}
+
+// put caches the value written to key at revision, nil for a deleted key
+func (c *cache) put(key string, value []byte, revision int64) {
+	modRevision := revision
+	if value == nil {
+		modRevision = 0
+	}
+	c.store(key, cacheEntry{value: value, modRevision: modRevision, revision: revision})
+}
+
+// store caches e unless events newer than its revision were applied, which
+// may have changed the key after it was read
+func (c *cache) store(key string, e cacheEntry) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.ready || e.revision < c.revision {
+		return
+	}
+	if existing, ok := c.entries[key]; ok && existing.revision >= e.revision {
+		return
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.size {
+		// Start over rather than tracking recency, the cache refills on reads
+		c.entries = make(map[string]cacheEntry, c.size)
+	}
+	c.entries[key] = e
+}
+
+// apply updates the cached keys changed by a watch event
+func (c *cache) apply(ev *clientv3.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := string(ev.Kv.Key)
+	c.revision = ev.Kv.ModRevision
+	if existing, ok := c.entries[key]; !ok || existing.revision >= ev.Kv.ModRevision {
+		return
+	}
+	if ev.Type == clientv3.EventTypeDelete {
+		c.entries[key] = cacheEntry{revision: ev.Kv.ModRevision}
+		return
+	}
+	c.entries[key] = cacheEntry{value: ev.Kv.Value, modRevision: ev.Kv.ModRevision, revision: ev.Kv.ModRevision}
+}
+
+// start enables the cache once the watch is established at revision
+func (c *cache) start(revision int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ready = true
+	c.revision = revision
+}
+
+// reset drops every entry and disables the cache until the next start
+func (c *cache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ready = false
+	c.entries = make(map[string]cacheEntry, c.size)
+}
+
+// watch applies the changes under the prefix of the store to the cache until
+// ctx is done, watching again whenever the watch fails
+func (s *Store) watch(ctx context.Context) {
+	defer s.watching.Done()
+	defer s.cache.reset()
+
+	for ctx.Err() == nil {
+		s.cache.reset()
+		watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+		events := s.client.Watch(watchCtx, s.root+"/", clientv3.WithPrefix(), clientv3.WithCreatedNotify())
+		for resp := range events {
+			if err := resp.Err(); err != nil {
+				if ctx.Err() == nil {
+					logger.Warn("etcd datastore watch failed, dropping the read cache: %v", err)
+				}
+				break
+			}
+			if resp.Created {
+				s.cache.start(resp.Header.Revision)
+				continue
+			}
+			for _, ev := range resp.Events {
+				s.cache.apply(ev)
+			}
+		}
+		cancel()
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(watchRetryDelay):
+		}
+	}
+}
@@ -0,0 +1,656 @@
+// Package etcd implements the generic DataStore on etcd, for deployments where
+// a key-value store is the only available backend.
+//
+// Entities are stored as JSON under <prefix>/<TableName>/<PrimaryKey>. Numeric
+// primary keys are zero padded so that the key order is the ID order, which
+// lets lists page over key ranges. IDs are allocated from a sequence key per
+// table with compare-and-swap, and writes are guarded by etcd revisions.
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// sequencesTable holds the last allocated ID of every table
+	sequencesTable = "_sequences"
+	// scanBatch is the number of keys read per range request
+	scanBatch = 500
+	// defaultDialTimeout is used when the configuration leaves the timeout unset
+	defaultDialTimeout = 5 * time.Second
+)
+
+// Store implements DataStore using etcd
+type Store struct {
+	client *clientv3.Client
+	root   string
+	clock  clock.Clock
+	cache  *cache // nil when the read cache is disabled
+
+	mu          sync.Mutex
+	cancelWatch context.CancelFunc
+	watching    sync.WaitGroup
+}
+
+// New creates an etcd datastore and connects it. Timestamps are read from clk.
+func New(cfg config.EtcdConfig, clk clock.Clock) (datastore.DataStore, error) {
+	dialTimeout := cfg.DialTimeout
+	if dialTimeout == 0 {
+		dialTimeout = defaultDialTimeout
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		Username:    cfg.Username,
+		Password:    cfg.Password,
+		DialTimeout: dialTimeout,
+		Logger:      zap.NewNop(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", datastore.ErrConnectionFailed, err)
+	}
+
+	s := &Store{
+		client: client,
+		root:   strings.TrimSuffix(cfg.Prefix, "/"),
+		clock:  clk,
+	}
+	if cfg.CacheSize > 0 {
+		s.cache = newCache(cfg.CacheSize)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+	defer cancel()
+	if err := s.Connect(ctx); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+
+	logger.Info("Connected to etcd datastore")
+	return s, nil
+}
+
+// Connect checks that the cluster is reachable and starts the watch keeping
+// the read cache up to date. Calling it again only repeats the check.
+func (s *Store) Connect(ctx context.Context) error {
+	if err := s.HealthCheck(ctx); err != nil {
+		return fmt.Errorf("%w: %v", datastore.ErrConnectionFailed, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cache != nil && s.cancelWatch == nil {
+		watchCtx, cancel := context.WithCancel(context.Background())
+		s.cancelWatch = cancel
+		s.watching.Add(1)
+		go s.watch(watchCtx)
+	}
+	return nil
+}
+
+// Disconnect stops the watch and closes the client
+func (s *Store) Disconnect(ctx context.Context) error {
+	s.mu.Lock()
+	if s.cancelWatch != nil {
+		s.cancelWatch()
+		s.cancelWatch = nil
+	}
+	s.mu.Unlock()
+
+	s.watching.Wait()
+	return s.client.Close()
+}
+
+// HealthCheck performs a linearizable read, which requires a quorum
+func (s *Store) HealthCheck(ctx context.Context) error {
+	_, err := s.client.Get(ctx, s.root+"/", clientv3.WithCountOnly())
+	return translateError(err)
+}
+
+// BeginTx starts an optimistic transaction, see transaction
+func (s *Store) BeginTx(ctx context.Context) (datastore.Transaction, error) {
+	return newTransaction(ctx, s), nil
+}
+
+// Add stores a new entity, allocating its ID when it is zero
+func (s *Store) Add(ctx context.Context, entity datastore.Entity) error {
+	e, allocated, err := s.prepareAdd(ctx, entity)
+	if err != nil {
+		return err
+	}
+
+	key := s.key(e)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	resp, err := s.client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+		Then(clientv3.OpPut(key, string(data))).
+		Commit()
+	if err == nil && !resp.Succeeded {
+		err = datastore.ErrDuplicateKey
+	}
+	err = translateError(err)
+	if err != nil {
+		if allocated {
+			e.SetID(0)
+		}
+		return err
+	}
+
+	s.cache.put(key, data, resp.Header.Revision)
+	return nil
+}
+
+// BatchAdd stores the entities in a single transaction, so that either all or
+// none of them are stored. etcd limits the operations of a transaction, to
+// 128 unless the server sets --max-txn-ops.
+func (s *Store) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	keys := make([]string, 0, len(entities))
+	values := make([][]byte, 0, len(entities))
+	cmps := make([]clientv3.Cmp, 0, len(entities))
+	ops := make([]clientv3.Op, 0, len(entities))
+	for _, entity := range entities {
+		e, _, err := s.prepareAdd(ctx, entity)
+		if err != nil {
+			return err
+		}
+		key := s.key(e)
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values = append(values, data)
+		cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+		ops = append(ops, clientv3.OpPut(key, string(data)))
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+
+	resp, err := s.client.Txn(ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return translateError(err)
+	}
+	if !resp.Succeeded {
+		return datastore.ErrDuplicateKey
+	}
+	for i, key := range keys {
+		s.cache.put(key, values[i], resp.Header.Revision)
+	}
+	return nil
+}
+
+// Put replaces the entity with the same ID, keeping its creation time. The
+// write only succeeds if the entity was not deleted since it was read.
+func (s *Store) Put(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	key := s.key(e)
+	for {
+		current, err := s.client.Get(ctx, key)
+		if err != nil {
+			return translateError(err)
+		}
+		if len(current.Kvs) == 0 {
+			return datastore.ErrNotFound
+		}
+		kv := current.Kvs[0]
+
+		var existing struct {
+			CreatedAt time.Time `json:"created_at"`
+			PublicID  string    `json:"public_id"`
+		}
+		if err := json.Unmarshal(kv.Value, &existing); err != nil {
+			return err
+		}
+		model.KeepPublicID(e, existing.PublicID)
+		e.SetCreateTime(existing.CreatedAt)
+		e.SetUpdateTime(s.now())
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+
+		resp, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(key), "=", kv.CreateRevision)).
+			Then(clientv3.OpPut(key, string(data))).
+			Commit()
+		if err != nil {
+			return translateError(err)
+		}
+		if resp.Succeeded {
+			s.cache.put(key, data, resp.Header.Revision)
+			return nil
+		}
+		// Deleted, and maybe created again, since it was read
+	}
+}
+
+// Delete removes the entity with the same ID
+func (s *Store) Delete(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	key := s.key(e)
+	resp, err := s.client.Delete(ctx, key)
+	if err != nil {
+		return translateError(err)
+	}
+	if resp.Deleted == 0 {
+		return datastore.ErrNotFound
+	}
+	s.cache.put(key, nil, resp.Header.Revision)
+	return nil
+}
+
+// Get loads the entity with the ID of entity into it
+func (s *Store) Get(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	value, _, err := s.read(ctx, s.key(e))
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return datastore.ErrNotFound
+	}
+	return json.Unmarshal(value, e)
+}
+
+// List returns the entities of the type of query matching options. Lists
+// ordered by ID without filters page over key ranges; other lists are read in
+// full and sorted in memory.
+func (s *Store) List(ctx context.Context, query datastore.Entity, options *datastore.ListOptions) ([]datastore.Entity, error) {
+	e, err := asEntity(query)
+	if err != nil {
+		return nil, err
+	}
+	opts := datastore.ListOptions{}
+	if options != nil {
+		opts = *options
+	}
+	if err := checkOptions(e, opts); err != nil {
+		return nil, err
+	}
+
+	typ := reflect.TypeOf(e).Elem()
+	decode := func(value []byte) (model.Entity, error) {
+		entity := reflect.New(typ).Interface().(model.Entity)
+		return entity, json.Unmarshal(value, entity)
+	}
+
+	orders := opts.Orders()
+	byID := len(orders) == 0 || (len(orders) == 1 && orders[0].Column == "id")
+	desc := byID && len(orders) == 1 && orders[0].Desc
+	if byID && len(opts.Filters) == 0 && len(opts.Tags) == 0 && len(opts.Ranges) == 0 {
+		opts.SortDesc = desc
+		return s.listPage(ctx, s.tablePrefix(e.TableName()), opts, decode)
+	}
+
+	entities := []datastore.Entity{}
+	skip := opts.Size > 0 && byID
+	skipped := 0
+	var matches []model.Entity
+	err = s.scan(ctx, s.tablePrefix(e.TableName()), desc, func(kv *mvccpb.KeyValue) (bool, error) {
+		entity, err := decode(kv.Value)
+		if err != nil {
+			return false, err
+		}
+		if !opts.Matches(entity) {
+			return true, nil
+		}
+		if !byID {
+			matches = append(matches, entity)
+			return true, nil
+		}
+		// Key order is the requested order, stop once the page is full
+		if skip && skipped < pageOffset(opts) {
+			skipped++
+			return true, nil
+		}
+		entities = append(entities, entity)
+		return opts.Size == 0 || len(entities) < opts.Size, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if byID {
+		return entities, nil
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return opts.Less(matches[i], matches[j]) })
+	start, end := 0, len(matches)
+	if opts.Size > 0 {
+		start = min(pageOffset(opts), len(matches))
+		end = min(start+opts.Size, len(matches))
+	}
+	for _, entity := range matches[start:end] {
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// listPage reads one page of entities in key order, skipping the keys of the
+// previous pages without reading their values
+func (s *Store) listPage(ctx context.Context, prefix string, opts datastore.ListOptions, decode func([]byte) (model.Entity, error)) ([]datastore.Entity, error) {
+	entities := []datastore.Entity{}
+	if opts.Size == 0 {
+		err := s.scan(ctx, prefix, opts.SortDesc, func(kv *mvccpb.KeyValue) (bool, error) {
+			entity, err := decode(kv.Value)
+			entities = append(entities, entity)
+			return true, err
+		})
+		return entities, err
+	}
+
+	// Find the key starting the page without transferring values
+	offset := pageOffset(opts)
+	var first []byte
+	skipped := 0
+	err := s.scan(ctx, prefix, opts.SortDesc, func(kv *mvccpb.KeyValue) (bool, error) {
+		if skipped == offset {
+			first = kv.Key
+			return false, nil
+		}
+		skipped++
+		return true, nil
+	}, clientv3.WithKeysOnly())
+	if err != nil || first == nil {
+		return entities, err
+	}
+
+	start, end := string(first), clientv3.GetPrefixRangeEnd(prefix)
+	sortOrder := clientv3.SortAscend
+	if opts.SortDesc {
+		start, end = prefix, string(first)+"\x00"
+		sortOrder = clientv3.SortDescend
+	}
+	resp, err := s.client.Get(ctx, start, clientv3.WithRange(end), clientv3.WithLimit(int64(opts.Size)),
+		clientv3.WithSort(clientv3.SortByKey, sortOrder))
+	if err != nil {
+		return nil, translateError(err)
+	}
+	for _, kv := range resp.Kvs {
+		entity, err := decode(kv.Value)
+		if err != nil {
+			return nil, err
+		}
+		entities = append(entities, entity)
+	}
+	return entities, nil
+}
+
+// Count returns the number of entities of the type of entity matching options
+func (s *Store) Count(ctx context.Context, entity datastore.Entity, options *datastore.FilterOptions) (int64, error) {
+	e, err := asEntity(entity)
+	if err != nil {
+		return 0, err
+	}
+
+	prefix := s.tablePrefix(e.TableName())
+	if options == nil || len(options.Filters) == 0 {
+		resp, err := s.client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+		if err != nil {
+			return 0, translateError(err)
+		}
+		return resp.Count, nil
+	}
+
+	opts := datastore.ListOptions{Filters: options.Filters}
+	typ := reflect.TypeOf(e).Elem()
+	var total int64
+	err = s.scan(ctx, prefix, false, func(kv *mvccpb.KeyValue) (bool, error) {
+		candidate := reflect.New(typ).Interface().(model.Entity)
+		if err := json.Unmarshal(kv.Value, candidate); err != nil {
+			return false, err
+		}
+		if opts.Matches(candidate) {
+			total++
+		}
+		return true, nil
+	})
+	return total, err
+}
+
+// IsExist reports whether an entity with the ID of entity exists
+func (s *Store) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
+	e, err := asEntity(entity)
+	if err != nil {
+		return false, err
+	}
+
+	value, _, err := s.read(ctx, s.key(e))
+	return value != nil, err
+}
+
+// Migrate does nothing, keys need no schema
+func (s *Store) Migrate(ctx context.Context, entities ...datastore.Entity) error {
+	return nil
+}
+
+// ExecuteSQL is not supported by etcd
+func (s *Store) ExecuteSQL(ctx context.Context, sql string, args ...interface{}) error {
+	return fmt.Errorf("%w: etcd does not execute SQL", datastore.ErrInvalidInput)
+}
+
+// prepareAdd allocates the ID of a new entity when it is zero, from the
+// sequence of its table unless IDs are generated, and sets its public ID and
+// timestamps, reporting whether the ID was allocated
+func (s *Store) prepareAdd(ctx context.Context, entity datastore.Entity) (model.Entity, bool, error) {
+	e, err := asEntity(entity)
+	if err != nil {
+		return nil, false, err
+	}
+
+	allocated := false
+	if e.GetID() == 0 {
+		id := idgen.Next()
+		if id == 0 {
+			if id, err = s.nextID(ctx, e.TableName()); err != nil {
+				return nil, false, err
+			}
+		}
+		e.SetID(id)
+		allocated = true
+	}
+	model.AssignPublicID(e)
+	now := s.now()
+	e.SetCreateTime(now)
+	e.SetUpdateTime(now)
+	return e, allocated, nil
+}
+
+// nextID allocates the next ID of table with compare-and-swap on its sequence
+// key. IDs of failed inserts are not reused, as with SQL sequences.
+func (s *Store) nextID(ctx context.Context, table string) (uint, error) {
+	key := s.tablePrefix(sequencesTable) + table
+	for {
+		resp, err := s.client.Get(ctx, key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate ID for %s: %w", table, translateError(err))
+		}
+
+		var current uint64
+		var modRevision int64
+		if len(resp.Kvs) > 0 {
+			modRevision = resp.Kvs[0].ModRevision
+			if current, err = strconv.ParseUint(string(resp.Kvs[0].Value), 10, 64); err != nil {
+				return 0, fmt.Errorf("invalid ID sequence for %s: %w", table, err)
+			}
+		}
+
+		next := strconv.FormatUint(current+1, 10)
+		txn, err := s.client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", modRevision)).
+			Then(clientv3.OpPut(key, next)).
+			Commit()
+		if err != nil {
+			return 0, fmt.Errorf("failed to allocate ID for %s: %w", table, translateError(err))
+		}
+		if txn.Succeeded {
+			return uint(current + 1), nil
+		}
+	}
+}
+
+// read returns the value and mod revision of key, from the cache when it holds
+// the key. The value is nil when the key does not exist.
+func (s *Store) read(ctx context.Context, key string) ([]byte, int64, error) {
+	if e, ok := s.cache.get(key); ok {
+		return e.value, e.modRevision, nil
+	}
+
+	resp, err := s.client.Get(ctx, key)
+	if err != nil {
+		return nil, 0, translateError(err)
+	}
+	if len(resp.Kvs) == 0 {
+		s.cache.put(key, nil, resp.Header.Revision)
+		return nil, 0, nil
+	}
+	kv := resp.Kvs[0]
+	s.cache.fill(kv, resp.Header.Revision)
+	return kv.Value, kv.ModRevision, nil
+}
+
+// scan calls fn for the keys under prefix in key order until it returns
+// false. Batches are read at the revision of the first one, so that writes
+// made during the scan do not shift the keys.
+func (s *Store) scan(ctx context.Context, prefix string, desc bool, fn func(kv *mvccpb.KeyValue) (bool, error), opts ...clientv3.OpOption) error {
+	start, end := prefix, clientv3.GetPrefixRangeEnd(prefix)
+	sortOrder := clientv3.SortAscend
+	if desc {
+		sortOrder = clientv3.SortDescend
+	}
+
+	var revision int64
+	for {
+		batchOpts := []clientv3.OpOption{
+			clientv3.WithRange(end),
+			clientv3.WithLimit(scanBatch),
+			clientv3.WithSort(clientv3.SortByKey, sortOrder),
+		}
+		if revision > 0 {
+			batchOpts = append(batchOpts, clientv3.WithRev(revision))
+		}
+		resp, err := s.client.Get(ctx, start, append(batchOpts, opts...)...)
+		if err != nil {
+			return translateError(err)
+		}
+		revision = resp.Header.Revision
+
+		for _, kv := range resp.Kvs {
+			more, err := fn(kv)
+			if err != nil || !more {
+				return err
+			}
+		}
+		if !resp.More || len(resp.Kvs) == 0 {
+			return nil
+		}
+
+		last := string(resp.Kvs[len(resp.Kvs)-1].Key)
+		if desc {
+			end = last
+		} else {
+			start = last + "\x00"
+		}
+	}
+}
+
+// key returns the key of entity
+func (s *Store) key(entity model.Entity) string {
+	primaryKey := entity.PrimaryKey()
+	if id, err := strconv.ParseUint(primaryKey, 10, 64); err == nil {
+		primaryKey = fmt.Sprintf("%020d", id)
+	}
+	return s.tablePrefix(entity.TableName()) + primaryKey
+}
+
+// tablePrefix returns the prefix of the keys of table
+func (s *Store) tablePrefix(table string) string {
+	return s.root + "/" + table + "/"
+}
+
+// now returns the current time in UTC, as JSON round trips it
+func (s *Store) now() time.Time {
+	return s.clock.Now().UTC()
+}
+
+// asEntity returns entity as a domain entity, which carries the ID
+func asEntity(entity datastore.Entity) (model.Entity, error) {
+	e, ok := entity.(model.Entity)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement model.Entity", datastore.ErrInvalidInput, entity)
+	}
+	return e, nil
+}
+
+// translateError maps etcd client errors to datastore errors: an unreachable
+// cluster or a cluster without leader is ErrConnectionFailed
+func translateError(err error) error {
+	var etcdErr rpctypes.EtcdError
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, clientv3.ErrNoAvailableEndpoints),
+		errors.As(err, &etcdErr) && etcdErr.Code() == codes.Unavailable,
+		status.Code(err) == codes.Unavailable:
+		return fmt.Errorf("%w: %w", datastore.ErrConnectionFailed, err)
+	default:
+		return err
+	}
+}
+
+// checkOptions rejects negative pages and tag selectors on untagged entities
+func checkOptions(entity model.Entity, opts datastore.ListOptions) error {
+	if opts.Page < 0 || opts.Size < 0 {
+		return fmt.Errorf("%w: page and size must not be negative", datastore.ErrInvalidInput)
+	}
+	if _, ok := entity.(model.Tagged); !ok && len(opts.Tags) > 0 {
+		return fmt.Errorf("%w: %s does not support tags", datastore.ErrInvalidInput, entity.TableName())
+	}
+	return nil
+}
+
+// pageOffset returns the number of entities before the requested page
+func pageOffset(opts datastore.ListOptions) int {
+	if opts.Page <= 1 {
+		return 0
+	}
+	return (opts.Page - 1) * opts.Size
+}
@@ -0,0 +1,257 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// errTransactionDone is returned when a finished transaction is used
+var errTransactionDone = fmt.Errorf("%w: transaction already committed or rolled back", datastore.ErrTransactionFailed)
+
+// transaction is an optimistic transaction. Reads record the mod revision of
+// every key and writes are buffered; Commit applies the writes in one etcd
+// transaction that only succeeds if none of the keys read changed since.
+// Otherwise Commit returns ErrTransactionFailed wrapping ErrSerializationFailure
+// and the caller may retry.
+type transaction struct {
+	ctx   context.Context
+	store *Store
+
+	mu      sync.Mutex
+	reads   map[string]int64  // mod revision of the keys read, 0 when absent
+	writes  map[string][]byte // buffered values, nil for deleted keys
+	order   []string          // keys in the order they were first written
+	created map[string]bool   // keys added by the transaction
+	done    bool
+}
+
+// newTransaction starts an optimistic transaction on s
+func newTransaction(ctx context.Context, s *Store) *transaction {
+	return &transaction{
+		ctx:     ctx,
+		store:   s,
+		reads:   make(map[string]int64),
+		writes:  make(map[string][]byte),
+		created: make(map[string]bool),
+	}
+}
+
+// Commit applies the buffered writes if the keys read are unchanged
+func (t *transaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errTransactionDone
+	}
+	t.done = true
+	if len(t.order) == 0 {
+		return nil
+	}
+
+	cmps := make([]clientv3.Cmp, 0, len(t.reads)+len(t.created))
+	for key, modRevision := range t.reads {
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(key), "=", modRevision))
+	}
+	for key := range t.created {
+		if _, read := t.reads[key]; !read {
+			cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+		}
+	}
+	ops := make([]clientv3.Op, 0, len(t.order))
+	for _, key := range t.order {
+		if value := t.writes[key]; value != nil {
+			ops = append(ops, clientv3.OpPut(key, string(value)))
+		} else {
+			ops = append(ops, clientv3.OpDelete(key))
+		}
+	}
+
+	resp, err := t.store.client.Txn(t.ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return fmt.Errorf("%w: %w", datastore.ErrTransactionFailed, translateError(err))
+	}
+	if !resp.Succeeded {
+		return fmt.Errorf("%w: %w: keys changed by a concurrent write", datastore.ErrTransactionFailed, datastore.ErrSerializationFailure)
+	}
+	for _, key := range t.order {
+		t.store.cache.put(key, t.writes[key], resp.Header.Revision)
+	}
+	return nil
+}
+
+// Rollback discards the buffered writes
+func (t *transaction) Rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.done = true
+	return nil
+}
+
+// Add buffers a new entity, allocating its ID when it is zero. The ID is
+// allocated immediately and not reused if the transaction rolls back.
+func (t *transaction) Add(ctx context.Context, entity datastore.Entity) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errTransactionDone
+	}
+	e, allocated, err := t.store.prepareAdd(ctx, entity)
+	if err != nil {
+		return err
+	}
+
+	key := t.store.key(e)
+	if value, err := t.get(ctx, key); err != nil || value != nil {
+		if allocated {
+			e.SetID(0)
+		}
+		if err != nil {
+			return err
+		}
+		return datastore.ErrDuplicateKey
+	}
+	if _, read := t.reads[key]; !read {
+		t.created[key] = true
+	}
+	return t.write(key, e)
+}
+
+// Put buffers the replacement of an existing entity, keeping its creation time
+func (t *transaction) Put(ctx context.Context, entity datastore.Entity) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errTransactionDone
+	}
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	key := t.store.key(e)
+	value, err := t.read(ctx, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return datastore.ErrNotFound
+	}
+
+	var existing struct {
+		CreatedAt time.Time `json:"created_at"`
+		PublicID  string    `json:"public_id"`
+	}
+	if err := json.Unmarshal(value, &existing); err != nil {
+		return err
+	}
+	model.KeepPublicID(e, existing.PublicID)
+	e.SetCreateTime(existing.CreatedAt)
+	e.SetUpdateTime(t.store.now())
+	return t.write(key, e)
+}
+
+// Delete buffers the removal of an existing entity
+func (t *transaction) Delete(ctx context.Context, entity datastore.Entity) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errTransactionDone
+	}
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	key := t.store.key(e)
+	value, err := t.read(ctx, key)
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return datastore.ErrNotFound
+	}
+	if _, buffered := t.writes[key]; !buffered {
+		t.order = append(t.order, key)
+	}
+	t.writes[key] = nil
+	return nil
+}
+
+// Get loads the entity with the ID of entity, including buffered writes
+func (t *transaction) Get(ctx context.Context, entity datastore.Entity) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.done {
+		return errTransactionDone
+	}
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+
+	value, err := t.read(ctx, t.store.key(e))
+	if err != nil {
+		return err
+	}
+	if value == nil {
+		return datastore.ErrNotFound
+	}
+	return json.Unmarshal(value, e)
+}
+
+// read returns the buffered value of key, or reads it and records its revision
+func (t *transaction) read(ctx context.Context, key string) ([]byte, error) {
+	if value, buffered := t.writes[key]; buffered {
+		return value, nil
+	}
+	value, modRevision, err := t.store.read(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, read := t.reads[key]; !read {
+		t.reads[key] = modRevision
+	}
+	return value, nil
+}
+
+// get is read for Add, where an absent key is not recorded as read so that a
+// concurrent create of the same key fails the commit on its create revision
+func (t *transaction) get(ctx context.Context, key string) ([]byte, error) {
+	if value, buffered := t.writes[key]; buffered {
+		return value, nil
+	}
+	value, modRevision, err := t.store.read(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if _, read := t.reads[key]; !read && value != nil {
+		t.reads[key] = modRevision
+	}
+	return value, nil
+}
+
+// write buffers the JSON encoding of entity under key
+func (t *transaction) write(key string, entity model.Entity) error {
+	data, err := json.Marshal(entity)
+	if err != nil {
+		return err
+	}
+	if _, buffered := t.writes[key]; !buffered {
+		t.order = append(t.order, key)
+	}
+	t.writes[key] = data
+	return nil
+}
@@ -6,8 +6,10 @@ import (
 	"github.com/make-bin/server-tpl/pkg/infrastructure/cache"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/memory"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/mongodb"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/opengauss"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/postgresql"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/config"
 )
 
@@ -17,26 +19,70 @@ type DatastoreType string
 const (
 	PostgreSQL DatastoreType = "postgresql"
 	OpenGauss  DatastoreType = "opengauss"
+	MongoDB    DatastoreType = "mongodb"
 	Memory     DatastoreType = "memory"
 )
 
 // SimpleFactory is a factory for creating datastore instances
-type SimpleFactory struct{}
+type SimpleFactory struct {
+	clock clock.Clock
+}
 
-// NewSimpleFactory creates a new SimpleFactory instance
-func NewSimpleFactory() *SimpleFactory {
-	return &SimpleFactory{}
+// NewSimpleFactory creates a new SimpleFactory instance. The datastores and
+// caches it creates read timestamps and measure TTLs on clk.
+func NewSimpleFactory(clk clock.Clock) *SimpleFactory {
+	return &SimpleFactory{clock: clk}
 }
 
-// CreateDatastore creates a datastore instance based on the configuration (backward compatibility)
+// CreateDatastore creates a datastore instance based on the configuration (backward compatibility).
+// With database.datastores configured it returns a *datastore.Router over the
+// primary database and the named datastores.
 func (f *SimpleFactory) CreateDatastore(cfg *config.Config) (datastore.DatastoreInterface, error) {
+	if len(cfg.Database.Datastores) == 0 {
+		return f.createDatastore(cfg)
+	}
+
+	stores := make(map[string]datastore.DatastoreInterface, len(cfg.Database.Datastores)+1)
+	closeAll := func() {
+		for _, store := range stores {
+			_ = store.Close()
+		}
+	}
+	primary, err := f.createDatastore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	stores[datastore.PrimaryDatastore] = primary
+	for name, database := range cfg.Database.Datastores {
+		named := *cfg
+		named.Database = database
+		store, err := f.createDatastore(&named)
+		if err != nil {
+			closeAll()
+			return nil, fmt.Errorf("datastore %s: %w", name, err)
+		}
+		stores[name] = store
+	}
+
+	router, err := datastore.NewRouter(stores, cfg.Database.Routes)
+	if err != nil {
+		closeAll()
+		return nil, err
+	}
+	return router, nil
+}
+
+// createDatastore creates the datastore of cfg.Database
+func (f *SimpleFactory) createDatastore(cfg *config.Config) (datastore.DatastoreInterface, error) {
 	switch DatastoreType(cfg.Database.Type) {
 	case PostgreSQL:
-		return postgresql.New(cfg)
+		return postgresql.New(cfg, f.clock)
 	case OpenGauss:
-		return opengauss.New(cfg)
+		return opengauss.New(cfg, f.clock)
+	case MongoDB:
+		return mongodb.New(cfg, f.clock)
 	case Memory:
-		return memory.New()
+		return memory.New(f.clock)
 	default:
 		return nil, fmt.Errorf("unsupported datastore type: %s", cfg.Database.Type)
 	}
@@ -49,11 +95,13 @@ func (f *SimpleFactory) CreateDataStore(cfg *config.Config) (datastore.Datastore
 
 	switch DatastoreType(cfg.Database.Type) {
 	case PostgreSQL:
-		store, err = postgresql.New(cfg)
+		store, err = postgresql.New(cfg, f.clock)
 	case OpenGauss:
-		store, err = opengauss.New(cfg)
+		store, err = opengauss.New(cfg, f.clock)
+	case MongoDB:
+		store, err = mongodb.New(cfg, f.clock)
 	case Memory:
-		store, err = memory.New()
+		store, err = memory.New(f.clock)
 	default:
 		return nil, fmt.Errorf("unsupported datastore type: %s", cfg.Database.Type)
 	}
@@ -92,7 +140,7 @@ func (f *SimpleFactory) CreateCache(cfg *config.Config) (datastore.Cache, error)
 
 	// For now, always create memory cache
 	// In future, this could create Redis cache based on config
-	return cache.NewMemoryCache(cacheConfig), nil
+	return cache.NewMemoryCache(cacheConfig, f.clock), nil
 }
 
 // DataStoreFactory provides factory methods for data store creation
@@ -103,8 +151,8 @@ type DataStoreFactory interface {
 }
 
 // NewDataStoreFactory creates a new data store factory
-func NewDataStoreFactory() DataStoreFactory {
-	return &SimpleFactory{}
+func NewDataStoreFactory(clk clock.Clock) DataStoreFactory {
+	return NewSimpleFactory(clk)
 }
 
 // Note: CreateMonitoredDataStore is already defined above
@@ -112,7 +160,7 @@ func NewDataStoreFactory() DataStoreFactory {
 // NewDataStore creates a new DataStore instance (convenience function)
 // TODO: Fix interface compatibility issues
 // func NewDataStore(cfg *config.Config) (datastore.DataStore, error) {
-// 	factory := NewDataStoreFactory()
+// 	factory := NewDataStoreFactory(clock.New())
 // 	return factory.CreateDataStore(cfg)
 // }
 
@@ -0,0 +1,86 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// poolingProbes is the number of statements CheckGormPooling runs outside a
+// transaction to detect server connections changing between statements
+const poolingProbes = 5
+
+// CheckGormPooling probes the connection pooling proxy in front of the
+// PostgreSQL compatible database of db. It returns an error wrapping
+// ErrPoolingIncompatible when transactions do not stay on one server
+// connection, as with statement pooling, or when the statements of one client
+// connection run on different server connections, as with transaction
+// pooling, while transactionPooling is disabled. A direct connection passes.
+// Server connections are told apart by their backend PID; a proxy may reuse
+// the same one under low load, so the probe can miss transaction pooling.
+func CheckGormPooling(ctx context.Context, db *gorm.DB, transactionPooling bool) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return TranslateGormError(err)
+	}
+	defer conn.Close()
+
+	multiplexed := false
+	var first int
+	for i := 0; i < poolingProbes; i++ {
+		pid, err := backendPID(ctx, conn)
+		switch {
+		case err != nil && i == 0:
+			return TranslateGormError(err)
+		case err != nil:
+			// A prepared statement missing on another server connection
+			multiplexed = true
+		case i == 0:
+			first = pid
+		case pid != first:
+			multiplexed = true
+		}
+		if multiplexed {
+			break
+		}
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: transactions are refused, the proxy must use transaction or session pooling: %w",
+			ErrPoolingIncompatible, err)
+	}
+	defer func() { _ = tx.Rollback() }()
+	var pids [2]int
+	for i := range pids {
+		if pids[i], err = backendPID(ctx, tx); err != nil {
+			return fmt.Errorf("%w: statements of a transaction fail, the proxy must use transaction or session pooling: %w",
+				ErrPoolingIncompatible, err)
+		}
+	}
+	if pids[0] != pids[1] {
+		return fmt.Errorf("%w: statements of a transaction run on different server connections, "+
+			"the proxy must use transaction or session pooling", ErrPoolingIncompatible)
+	}
+
+	if multiplexed && !transactionPooling {
+		return fmt.Errorf("%w: statements run on different server connections, as behind a transaction pooling proxy, "+
+			"while database.transaction_pooling is disabled", ErrPoolingIncompatible)
+	}
+	return nil
+}
+
+// backendPID returns the PID of the server process running the statements of querier
+func backendPID(ctx context.Context, querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}) (int, error) {
+	var pid int
+	err := querier.QueryRowContext(ctx, "SELECT pg_backend_pid()").Scan(&pid)
+	return pid, err
+}
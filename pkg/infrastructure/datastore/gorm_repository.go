@@ -0,0 +1,250 @@
+package datastore
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+)
+
+// gormRepository implements Repository on top of a GORM connection
+type gormRepository[T model.Entity] struct {
+	db *gorm.DB
+}
+
+// NewGormRepository creates a GORM backed repository for T
+func NewGormRepository[T model.Entity](db *gorm.DB) (Repository[T], error) {
+	if err := checkEntityType[T](); err != nil {
+		return nil, err
+	}
+	return &gormRepository[T]{db: db}, nil
+}
+
+// Get retrieves an entity by ID
+func (r *gormRepository[T]) Get(ctx context.Context, id uint) (T, error) {
+	entity := newEntity[T]()
+	if err := r.db.WithContext(ctx).First(entity, id).Error; err != nil {
+		var zero T
+		return zero, TranslateGormError(err)
+	}
+	return entity, nil
+}
+
+// List retrieves entities matching the options
+func (r *gormRepository[T]) List(ctx context.Context, opts ListOptions) ([]T, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkTagged[T](opts); err != nil {
+		return nil, err
+	}
+
+	query := r.query(ctx, opts)
+	for _, sort := range opts.Orders() {
+		order := sort.Column
+		if sort.Desc {
+			order += " DESC"
+		}
+		query = query.Order(order)
+	}
+	query = query.Order("id")
+	if opts.Size > 0 {
+		query = query.Offset(opts.offset()).Limit(opts.Size)
+	}
+
+	var entities []T
+	if err := query.Find(&entities).Error; err != nil {
+		return nil, TranslateGormError(err)
+	}
+	return entities, nil
+}
+
+// Create stores a new entity
+func (r *gormRepository[T]) Create(ctx context.Context, entity T) (T, error) {
+	if err := r.db.WithContext(ctx).Create(entity).Error; err != nil {
+		var zero T
+		return zero, TranslateGormError(err)
+	}
+	return entity, nil
+}
+
+// Update replaces every column of an existing entity except its creation time
+// and public ID
+func (r *gormRepository[T]) Update(ctx context.Context, entity T) (T, error) {
+	return r.UpdateMatching(ctx, entity, nil)
+}
+
+// UpdateMatching updates the row of the entity with the filters in the WHERE
+// clause of the UPDATE, next to its ID
+func (r *gormRepository[T]) UpdateMatching(ctx context.Context, entity T, filters map[string]interface{}) (T, error) {
+	if err := (ListOptions{Filters: filters}).validate(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	query := r.db.WithContext(ctx).Model(entity)
+	if len(filters) > 0 {
+		query = query.Where(filters)
+	}
+	result := query.Select("*").Omit("created_at", "public_id").Updates(entity)
+	if result.Error != nil {
+		var zero T
+		return zero, TranslateGormError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		var zero T
+		return zero, ErrNotFound
+	}
+	return entity, nil
+}
+
+// Delete removes an entity by ID
+func (r *gormRepository[T]) Delete(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(newEntity[T](), id)
+	if result.Error != nil {
+		return TranslateGormError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMatching soft deletes the entities matching the filters with a single
+// UPDATE, or removes them with a single DELETE when opts.Permanent is set
+func (r *gormRepository[T]) DeleteMatching(ctx context.Context, opts DeleteOptions) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	db := r.db.WithContext(ctx)
+	if opts.Permanent {
+		db = db.Unscoped()
+	}
+	result := db.Where(opts.Filters).Delete(newEntity[T]())
+	if result.Error != nil {
+		return 0, TranslateGormError(result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// Count returns the number of entities matching the filters
+func (r *gormRepository[T]) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	if err := checkTagged[T](opts); err != nil {
+		return 0, err
+	}
+
+	var total int64
+	if err := r.query(ctx, opts).Count(&total).Error; err != nil {
+		return 0, TranslateGormError(err)
+	}
+	return total, nil
+}
+
+// query returns a query on the entity table with the filters and ranges applied
+func (r *gormRepository[T]) query(ctx context.Context, opts ListOptions) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(newEntity[T]())
+	if len(opts.Filters) > 0 {
+		query = query.Where(opts.Filters)
+	}
+	for column, bounds := range opts.Ranges {
+		if bounds.From != nil {
+			query = query.Where(column+" >= ?", bounds.From)
+		}
+		if bounds.To != nil {
+			query = query.Where(column+" <= ?", bounds.To)
+		}
+	}
+	if len(opts.Tags) > 0 {
+		table := newEntity[T]().TableName()
+		joined, _ := r.db.Config.Plugins[tagTablesName].(*TagTables)
+		for _, term := range opts.Tags {
+			if joined != nil && joined.holds(table) {
+				query = whereJoinedTag(query, table, term)
+			} else {
+				query = whereTag(query, term)
+			}
+		}
+	}
+	return query
+}
+
+// whereTag restricts query to rows whose JSONB tags column matches a selector
+// term. Exact tags use containment so the GIN index applies; a bare key also
+// matches any "key:value" tag.
+func whereTag(query *gorm.DB, term string) *gorm.DB {
+	exact, _ := json.Marshal([]string{term})
+	if strings.Contains(term, ":") {
+		return query.Where("tags @> ?", string(exact))
+	}
+	prefix := term + ":"
+	return query.Where("(tags @> ? OR EXISTS (SELECT 1 FROM jsonb_array_elements_text(tags) AS tag WHERE substr(tag, 1, ?) = ?))",
+		string(exact), len(prefix), prefix)
+}
+
+// SQLSTATE codes and classes mapped to datastore errors
+const (
+	sqlStateUniqueViolation      = "23505"
+	sqlStateForeignKeyViolation  = "23503"
+	sqlStateSerializationFailure = "40001"
+	sqlStateDeadlockDetected     = "40P01"
+	sqlStateAdminShutdown        = "57P01"
+	sqlStateCrashShutdown        = "57P02"
+	sqlStateCannotConnectNow     = "57P03"
+	sqlClassConnectionException  = "08"
+	sqlClassInvalidAuthorization = "28"
+)
+
+// sqlStateError is implemented by driver errors carrying a SQLSTATE code,
+// such as *pgconn.PgError of the PostgreSQL and OpenGauss drivers
+type sqlStateError interface {
+	SQLState() string
+}
+
+// TranslateGormError maps GORM and SQL driver errors to datastore errors:
+// unique and foreign key violations, serialization failures and deadlocks, and
+// lost or refused connections. Errors already translated and errors without a
+// datastore counterpart are returned unchanged.
+func TranslateGormError(err error) error {
+	switch {
+	case err == nil || translated(err):
+		return err
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return ErrNotFound
+	case errors.Is(err, gorm.ErrDuplicatedKey):
+		return ErrDuplicateKey
+	case errors.Is(err, gorm.ErrForeignKeyViolated):
+		return fmt.Errorf("%w: %w", ErrForeignKeyViolation, err)
+	}
+
+	var stateErr sqlStateError
+	if errors.As(err, &stateErr) {
+		switch code := stateErr.SQLState(); {
+		case code == sqlStateUniqueViolation:
+			return ErrDuplicateKey
+		case code == sqlStateForeignKeyViolation:
+			return fmt.Errorf("%w: %w", ErrForeignKeyViolation, err)
+		case code == sqlStateSerializationFailure, code == sqlStateDeadlockDetected:
+			return fmt.Errorf("%w: %w", ErrSerializationFailure, err)
+		case strings.HasPrefix(code, sqlClassConnectionException), strings.HasPrefix(code, sqlClassInvalidAuthorization),
+			code == sqlStateAdminShutdown, code == sqlStateCrashShutdown, code == sqlStateCannotConnectNow:
+			return fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if errors.Is(err, driver.ErrBadConn) || errors.As(err, &netErr) {
+		return fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+	}
+	return err
+}
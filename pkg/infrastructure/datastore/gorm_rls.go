@@ -0,0 +1,221 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+)
+
+// Session variables read by row-level security policies, set per transaction
+const (
+	RLSTenantVariable = "app.current_tenant" // ID of the organization of the request
+	RLSUserVariable   = "app.current_user"   // ID of the user of the request
+)
+
+// rlsStartedKey is the statement setting of the transactions begun to set the
+// session variables of a query
+const rlsStartedKey = "rls:started_transaction"
+
+// RLSPolicy is a PostgreSQL row-level security policy of a table
+type RLSPolicy struct {
+	Table string
+	Name  string
+	Using string // condition of the rows read, updated and deleted
+	Check string // condition of the rows written, Using when empty
+}
+
+// TenantRLSPolicy returns the policy restricting the rows of table to the
+// organization in RLSTenantVariable by their org_id column. With
+// allowUnscoped, sessions without a current tenant see and write every row.
+func TenantRLSPolicy(table string, allowUnscoped bool) RLSPolicy {
+	tenant := fmt.Sprintf("NULLIF(current_setting('%s', true), '')::bigint", RLSTenantVariable)
+	condition := "org_id = " + tenant
+	if allowUnscoped {
+		condition = fmt.Sprintf("%s IS NULL OR %s", tenant, condition)
+	}
+	return RLSPolicy{Table: table, Name: "tenant_isolation", Using: condition}
+}
+
+// CreateGormRLSPolicies enables and forces row-level security on the tables
+// of policies, the table owner included, and creates the policies anew, in
+// one transaction of db
+func CreateGormRLSPolicies(db *gorm.DB, policies ...RLSPolicy) error {
+	if len(policies) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		enabled := make(map[string]bool, len(policies))
+		for _, policy := range policies {
+			table := tx.Statement.Quote(policy.Table)
+			check := policy.Check
+			if check == "" {
+				check = policy.Using
+			}
+			statements := []string{
+				fmt.Sprintf("DROP POLICY IF EXISTS %s ON %s", tx.Statement.Quote(policy.Name), table),
+				fmt.Sprintf("CREATE POLICY %s ON %s USING (%s) WITH CHECK (%s)", tx.Statement.Quote(policy.Name), table, policy.Using, check),
+			}
+			if !enabled[policy.Table] {
+				enabled[policy.Table] = true
+				statements = append([]string{
+					fmt.Sprintf("ALTER TABLE %s ENABLE ROW LEVEL SECURITY", table),
+					fmt.Sprintf("ALTER TABLE %s FORCE ROW LEVEL SECURITY", table),
+				}, statements...)
+			}
+			for _, statement := range statements {
+				if err := tx.Exec(statement).Error; err != nil {
+					return fmt.Errorf("failed to create policy %s of %s: %w", policy.Name, policy.Table, err)
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// RowLevelSecurity moves the tenancy of the tables owned by organizations into
+// PostgreSQL row-level security. Installed with db.Use, it sets
+// RLSTenantVariable and RLSUserVariable from the organization and subject of
+// the statement context, locally to the transaction: in the default
+// transaction of writes, and in one begun for each query. Raw SQL run with
+// Rows or Scan outside a transaction is left out; run it in a unit of work,
+// whose transactions set the variables with SetVariables.
+type RowLevelSecurity struct {
+	tables        []string
+	allowUnscoped bool
+}
+
+// NewRowLevelSecurity creates the row-level security of tables, see TenantRLSPolicy
+func NewRowLevelSecurity(tables []string, allowUnscoped bool) *RowLevelSecurity {
+	return &RowLevelSecurity{tables: tables, allowUnscoped: allowUnscoped}
+}
+
+// Policies returns the tenant policies of the tables among models
+func (r *RowLevelSecurity) Policies(models []interface{}) []RLSPolicy {
+	held := make(map[string]bool, len(models))
+	for _, m := range models {
+		if tabler, ok := m.(interface{ TableName() string }); ok {
+			held[tabler.TableName()] = true
+		}
+	}
+	var policies []RLSPolicy
+	for _, table := range r.tables {
+		if held[table] {
+			policies = append(policies, TenantRLSPolicy(table, r.allowUnscoped))
+		}
+	}
+	return policies
+}
+
+// Name implements gorm.Plugin
+func (r *RowLevelSecurity) Name() string {
+	return "row_level_security"
+}
+
+// Initialize implements gorm.Plugin
+func (r *RowLevelSecurity) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().After("gorm:begin_transaction").Before("gorm:before_create").Register("rls:set_variables", r.setStarted),
+		callbacks.Update().After("gorm:begin_transaction").Before("gorm:setup_reflect_value").Register("rls:set_variables", r.setStarted),
+		callbacks.Delete().After("gorm:begin_transaction").Before("gorm:before_delete").Register("rls:set_variables", r.setStarted),
+		callbacks.Query().Before("gorm:query").Register("rls:begin_transaction", r.begin),
+		callbacks.Query().After("gorm:after_query").Register("rls:commit_or_rollback_transaction", r.commitOrRollback),
+	)
+}
+
+// SetVariables sets the session variables of ctx in the transaction tx
+func (r *RowLevelSecurity) SetVariables(ctx context.Context, tx *gorm.DB) error {
+	query, args := rlsVariables(ctx, func(int) string { return "?" })
+	if query == "" {
+		return nil
+	}
+	return TranslateGormError(tx.Exec(query, args...).Error)
+}
+
+// setStarted sets the session variables in the default transaction gorm began for a write
+func (r *RowLevelSecurity) setStarted(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if _, ok := db.InstanceGet("gorm:started_transaction"); !ok {
+		return
+	}
+	r.set(db)
+}
+
+// begin begins a transaction for a query outside one whose context carries
+// session variables, and sets them in it
+func (r *RowLevelSecurity) begin(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil {
+		return
+	}
+	if query, _ := rlsVariables(db.Statement.Context, positional); query == "" {
+		return
+	}
+	tx := db.Begin()
+	if errors.Is(tx.Error, gorm.ErrInvalidTransaction) {
+		// Already in a transaction, which set the variables
+		return
+	}
+	if tx.Error != nil {
+		db.AddError(tx.Error)
+		return
+	}
+	db.Statement.ConnPool = tx.Statement.ConnPool
+	db.InstanceSet(rlsStartedKey, true)
+	r.set(db)
+}
+
+// commitOrRollback ends the transaction begun for a query
+func (r *RowLevelSecurity) commitOrRollback(db *gorm.DB) {
+	if _, ok := db.InstanceGet(rlsStartedKey); !ok {
+		return
+	}
+	if db.Error != nil {
+		db.Rollback()
+	} else {
+		db.Commit()
+	}
+	db.Statement.ConnPool = db.ConnPool
+}
+
+// set sets the session variables of the statement context on its connection
+func (r *RowLevelSecurity) set(db *gorm.DB) {
+	query, args := rlsVariables(db.Statement.Context, positional)
+	if query == "" {
+		return
+	}
+	if _, err := db.Statement.ConnPool.ExecContext(db.Statement.Context, query, args...); err != nil {
+		db.AddError(err)
+	}
+}
+
+// positional returns the PostgreSQL placeholder of the argument n, from 1
+func positional(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// rlsVariables returns the statement setting the session variables of ctx
+// locally to the transaction, with the placeholders of placeholder; empty
+// without an organization or subject
+func rlsVariables(ctx context.Context, placeholder func(n int) string) (string, []interface{}) {
+	var calls []string
+	var args []interface{}
+	if tenant, ok := model.OrganizationFromContext(ctx); ok && tenant != 0 {
+		calls = append(calls, fmt.Sprintf("set_config('%s', %s, true)", RLSTenantVariable, placeholder(len(args)+1)))
+		args = append(args, strconv.FormatUint(uint64(tenant), 10))
+	}
+	if subject, ok := model.SubjectFromContext(ctx); ok && subject.UserID != "" {
+		calls = append(calls, fmt.Sprintf("set_config('%s', %s, true)", RLSUserVariable, placeholder(len(args)+1)))
+		args = append(args, subject.UserID)
+	}
+	if len(calls) == 0 {
+		return "", nil
+	}
+	return "SELECT " + strings.Join(calls, ", "), args
+}
@@ -0,0 +1,94 @@
+package datastore
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+)
+
+// CheckGormSchema returns an error wrapping ErrSchemaOutdated that lists the
+// tables and columns of models missing from the database of db
+func CheckGormSchema(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+
+	var missing []string
+	for _, m := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(m); err != nil {
+			return fmt.Errorf("failed to parse model %T: %w", m, err)
+		}
+		table := stmt.Schema.Table
+
+		if !migrator.HasTable(m) {
+			missing = append(missing, "table "+table)
+			continue
+		}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			if !migrator.HasColumn(m, field.DBName) {
+				missing = append(missing, "column "+table+"."+field.DBName)
+			}
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: missing %s", ErrSchemaOutdated, strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// LegacyIndex is an index of an earlier schema version that a migration drops
+type LegacyIndex struct {
+	Model interface{}
+	Name  string
+}
+
+// DropGormIndexes drops the legacy indexes still present in the database of db
+func DropGormIndexes(db *gorm.DB, indexes ...LegacyIndex) error {
+	migrator := db.Migrator()
+	for _, index := range indexes {
+		if !migrator.HasIndex(index.Model, index.Name) {
+			continue
+		}
+		if err := migrator.DropIndex(index.Model, index.Name); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", index.Name, err)
+		}
+	}
+	return nil
+}
+
+// publicIDBatchSize is the number of rows BackfillGormPublicIDs reads at once
+const publicIDBatchSize = 500
+
+// BackfillGormPublicIDs assigns public IDs to the rows of models stored before
+// the public_id column existed, soft-deleted rows included
+func BackfillGormPublicIDs(db *gorm.DB, models ...interface{}) error {
+	for _, m := range models {
+		if _, ok := m.(model.PublicIdentified); !ok {
+			continue
+		}
+		for {
+			var ids []uint
+			err := db.Unscoped().Model(m).Where("public_id IS NULL OR public_id = ''").
+				Limit(publicIDBatchSize).Pluck("id", &ids).Error
+			if err != nil {
+				return fmt.Errorf("failed to find rows without public ID of %T: %w", m, err)
+			}
+			if len(ids) == 0 {
+				break
+			}
+			for _, id := range ids {
+				err := db.Unscoped().Model(m).Where("id = ?", id).UpdateColumn("public_id", uuid.NewString()).Error
+				if err != nil {
+					return fmt.Errorf("failed to assign public ID of %T %d: %w", m, id, err)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package datastore
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+)
+
+// tagTablesName is the name of the TagTables plugin
+const tagTablesName = "tag_tables"
+
+// TagTables stores the tags of tagged entities in a join table besides their
+// JSONB column. Installed with db.Use, it replaces the tags of an entity in
+// the join table of its table, named <table>_tags, whenever the entity is
+// created or updated, in the transaction of the write; rows of deleted
+// entities cascade. The JSONB column stays the copy read with the entity, and
+// repositories select entities by tag on the join table, whose index on the
+// tag serves key prefixes as well.
+type TagTables struct {
+	tables map[string]bool
+}
+
+// NewTagTables creates the join tables of the tagged models among models
+func NewTagTables(models []interface{}) *TagTables {
+	t := &TagTables{tables: make(map[string]bool)}
+	for _, m := range models {
+		if _, ok := m.(model.Tagged); !ok {
+			continue
+		}
+		if tabler, ok := m.(interface{ TableName() string }); ok {
+			t.tables[tabler.TableName()] = true
+		}
+	}
+	return t
+}
+
+// TagTable returns the join table holding the tags of the rows of table
+func TagTable(table string) string {
+	return table + "_tags"
+}
+
+// Name implements gorm.Plugin
+func (t *TagTables) Name() string {
+	return tagTablesName
+}
+
+// Initialize implements gorm.Plugin
+func (t *TagTables) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().After("gorm:create").Before("gorm:commit_or_rollback_transaction").Register("tags:create", t.sync),
+		callbacks.Update().After("gorm:update").Before("gorm:commit_or_rollback_transaction").Register("tags:update", t.sync),
+	)
+}
+
+// Migrate creates the join tables of the tagged tables among models, and fills
+// them from the JSONB column of the rows tagged before
+func (t *TagTables) Migrate(db *gorm.DB, models ...interface{}) error {
+	for _, m := range models {
+		tabler, ok := m.(interface{ TableName() string })
+		if !ok || !t.tables[tabler.TableName()] {
+			continue
+		}
+		table := db.Statement.Quote(tabler.TableName())
+		join := db.Statement.Quote(TagTable(tabler.TableName()))
+		index := db.Statement.Quote("idx_" + TagTable(tabler.TableName()) + "_tag")
+		statements := []string{
+			fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (entity_id bigint NOT NULL REFERENCES %s (id) ON DELETE CASCADE, tag varchar(100) COLLATE \"C\" NOT NULL, PRIMARY KEY (entity_id, tag))", join, table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (tag)", index, join),
+			fmt.Sprintf("INSERT INTO %s (entity_id, tag) SELECT id, jsonb_array_elements_text(tags) FROM %s ON CONFLICT DO NOTHING", join, table),
+		}
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to create tag table of %s: %w", tabler.TableName(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// sync replaces the tags of the entity written by the statement in its join
+// table. Writes of maps or of several entities at once are left out.
+func (t *TagTables) sync(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || db.RowsAffected == 0 || !t.tables[stmt.Table] {
+		return
+	}
+	entity, ok := stmt.Dest.(interface {
+		model.Tagged
+		GetID() uint
+	})
+	if !ok || entity.GetID() == 0 {
+		return
+	}
+
+	join := stmt.Quote(TagTable(stmt.Table))
+	if _, err := stmt.ConnPool.ExecContext(stmt.Context, fmt.Sprintf("DELETE FROM %s WHERE entity_id = $1", join), entity.GetID()); err != nil {
+		db.AddError(err)
+		return
+	}
+	tags := entity.GetTags()
+	if len(tags) == 0 {
+		return
+	}
+	values := make([]string, 0, len(tags))
+	args := []interface{}{entity.GetID()}
+	for _, tag := range tags {
+		args = append(args, tag)
+		values = append(values, fmt.Sprintf("($1, %s)", positional(len(args))))
+	}
+	query := fmt.Sprintf("INSERT INTO %s (entity_id, tag) VALUES %s", join, strings.Join(values, ", "))
+	if _, err := stmt.ConnPool.ExecContext(stmt.Context, query, args...); err != nil {
+		db.AddError(err)
+	}
+}
+
+// holds reports whether the tags of table are stored in a join table
+func (t *TagTables) holds(table string) bool {
+	return t.tables[table]
+}
+
+// whereJoinedTag restricts query on table to rows whose join table holds a
+// tag matching a selector term. A bare key also matches any "key:value" tag,
+// by the range of tags from "key:" up to "key;", which the index of the
+// column, compared byte by byte, serves.
+func whereJoinedTag(query *gorm.DB, table, term string) *gorm.DB {
+	exists := fmt.Sprintf("EXISTS (SELECT 1 FROM %s AS t WHERE t.entity_id = %s.id AND ",
+		query.Statement.Quote(TagTable(table)), query.Statement.Quote(table))
+	if strings.Contains(term, ":") {
+		return query.Where(exists+"t.tag = ?)", term)
+	}
+	return query.Where(exists+"(t.tag = ? OR (t.tag >= ? AND t.tag < ?)))", term, term+":", term+";")
+}
@@ -0,0 +1,142 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// tenantSchemasSkipKey is the session setting disabling the qualification of
+// tenant tables, set while migrating a tenant schema through its search path
+const tenantSchemasSkipKey = "tenancy:skip"
+
+// TenantSchemas isolates every tenant, an organization, in a PostgreSQL schema
+// of its own holding its tenant tables. Installed with db.Use, it qualifies
+// the tenant tables of statements whose context carries an organization with
+// the schema of that organization; the other tables and the statements of
+// contexts without an organization use the public schema.
+type TenantSchemas struct {
+	prefix string
+	tables map[string]bool
+}
+
+// NewTenantSchemas creates the schemas named prefix followed by the tenant ID,
+// holding tables
+func NewTenantSchemas(prefix string, tables []string) *TenantSchemas {
+	t := &TenantSchemas{prefix: prefix, tables: make(map[string]bool, len(tables))}
+	for _, table := range tables {
+		t.tables[table] = true
+	}
+	return t
+}
+
+// Schema returns the schema of tenant
+func (t *TenantSchemas) Schema(tenant uint) string {
+	return fmt.Sprintf("%s%d", t.prefix, tenant)
+}
+
+// Models returns the models of the tenant tables among models
+func (t *TenantSchemas) Models(models []interface{}) []interface{} {
+	var tenant []interface{}
+	for _, m := range models {
+		if tabler, ok := m.(interface{ TableName() string }); ok && t.tables[tabler.TableName()] {
+			tenant = append(tenant, m)
+		}
+	}
+	return tenant
+}
+
+// Name implements gorm.Plugin
+func (t *TenantSchemas) Name() string {
+	return "tenant_schemas"
+}
+
+// Initialize implements gorm.Plugin
+func (t *TenantSchemas) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("tenancy:create", t.qualify),
+		callbacks.Query().Before("gorm:query").Register("tenancy:query", t.qualify),
+		callbacks.Update().Before("gorm:update").Register("tenancy:update", t.qualify),
+		callbacks.Delete().Before("gorm:delete").Register("tenancy:delete", t.qualify),
+		callbacks.Row().Before("gorm:row").Register("tenancy:row", t.qualify),
+	)
+}
+
+// qualify points a statement on a tenant table to the schema of the
+// organization of its context. Tables given as expressions, such as aliases
+// and tables already qualified, are left as they are.
+func (t *TenantSchemas) qualify(db *gorm.DB) {
+	stmt := db.Statement
+	if db.Error != nil || stmt.Context == nil || !t.tables[stmt.Table] {
+		return
+	}
+	if skip, ok := db.Get(tenantSchemasSkipKey); ok && skip == true {
+		return
+	}
+	tenant, ok := model.OrganizationFromContext(stmt.Context)
+	if !ok || tenant == 0 {
+		return
+	}
+	if stmt.TableExpr != nil && stmt.TableExpr.SQL != stmt.Quote(stmt.Table) {
+		return
+	}
+	// Qualified as gorm does for table names with a schema
+	stmt.TableExpr = &clause.Expr{SQL: stmt.Quote(t.Schema(tenant) + "." + stmt.Table)}
+}
+
+// SetSearchPath puts the schema of the organization of ctx first in the
+// search path of the transaction tx, so that raw SQL in the transaction uses
+// the tenant tables of the organization too. The search path is local to the
+// transaction, which suits transaction pooling proxies.
+func (t *TenantSchemas) SetSearchPath(ctx context.Context, tx *gorm.DB) error {
+	tenant, ok := model.OrganizationFromContext(ctx)
+	if !ok || tenant == 0 {
+		return nil
+	}
+	err := tx.Exec("SELECT set_config('search_path', ?, true)", t.searchPath(tx, tenant)).Error
+	return TranslateGormError(err)
+}
+
+// Provision creates the schema of tenant when missing and migrates the tenant
+// tables among models in it, in a transaction of db, or a savepoint when db
+// already is one. Provisioning an existing schema brings it up to date.
+func (t *TenantSchemas) Provision(ctx context.Context, db *gorm.DB, tenant uint, models ...interface{}) error {
+	schema := t.Schema(tenant)
+	err := db.WithContext(ctx).Set(tenantSchemasSkipKey, true).Transaction(func(tx *gorm.DB) error {
+		var searchPath string
+		if err := tx.Raw("SELECT current_setting('search_path')").Scan(&searchPath).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("CREATE SCHEMA IF NOT EXISTS " + tx.Statement.Quote(schema)).Error; err != nil {
+			return err
+		}
+		// The migrator creates the tables missing from the current schema,
+		// the first of the search path
+		if err := tx.Exec("SELECT set_config('search_path', ?, true)", t.searchPath(tx, tenant)).Error; err != nil {
+			return err
+		}
+		tenantModels := t.Models(models)
+		if err := tx.AutoMigrate(tenantModels...); err != nil {
+			return err
+		}
+		if err := BackfillGormPublicIDs(tx, tenantModels...); err != nil {
+			return err
+		}
+		return tx.Exec("SELECT set_config('search_path', ?, true)", searchPath).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to provision schema %s: %w", schema, TranslateGormError(err))
+	}
+	return nil
+}
+
+// searchPath returns the search path of tenant: its schema, then the public
+// schema holding the shared tables
+func (t *TenantSchemas) searchPath(db *gorm.DB, tenant uint) string {
+	return db.Statement.Quote(t.Schema(tenant)) + ", public"
+}
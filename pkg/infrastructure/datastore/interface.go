@@ -8,15 +8,36 @@ import (
 	"github.com/make-bin/server-tpl/pkg/domain/model"
 )
 
-// Common datastore errors
+// Common datastore errors. Drivers return ErrNotFound and ErrDuplicateKey
+// unwrapped; the other errors wrap the driver error, so both errors.Is and
+// errors.As work on them.
 var (
 	ErrNotFound          = errors.New("record not found")
 	ErrDuplicateKey      = errors.New("duplicate key violation")
 	ErrInvalidInput      = errors.New("invalid input")
 	ErrConnectionFailed  = errors.New("database connection failed")
 	ErrTransactionFailed = errors.New("transaction failed")
+	ErrSchemaOutdated    = errors.New("database schema is not up to date")
+	// ErrPoolingIncompatible is returned by PoolingChecker
+	ErrPoolingIncompatible = errors.New("incompatible with the connection pooling proxy")
+	// ErrForeignKeyViolation is returned when a write references a missing record
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+	// ErrSerializationFailure is returned when a transaction conflicts with a
+	// concurrent one, including deadlocks; retrying the transaction may succeed
+	ErrSerializationFailure = errors.New("serialization failure")
 )
 
+// translated reports whether err already is one of the common datastore errors
+func translated(err error) bool {
+	for _, target := range []error{ErrNotFound, ErrDuplicateKey, ErrInvalidInput, ErrConnectionFailed,
+		ErrTransactionFailed, ErrSchemaOutdated, ErrForeignKeyViolation, ErrSerializationFailure} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
 // Entity interface defines common methods for all entities
 type Entity interface {
 	SetCreateTime(time.Time)
@@ -43,7 +64,22 @@ type ListOptions struct {
 	Size     int                    `json:"size"`
 	SortBy   string                 `json:"sort_by"`
 	SortDesc bool                   `json:"sort_desc"`
-	Filters  map[string]interface{} `json:"filters"`
+	Filters  map[string]interface{} `json:"filters"` // equality, a slice value matches any of its elements
+	Tags     model.TagSelector      `json:"tags"`    // requires an entity implementing model.Tagged
+	Sort     []SortOrder            `json:"sort"`    // further orders applied after SortBy, ties fall back to the ID
+	Ranges   map[string]Range       `json:"ranges"`  // inclusive bounds of column values
+}
+
+// SortOrder orders list results by a column
+type SortOrder struct {
+	Column string `json:"column"`
+	Desc   bool   `json:"desc"`
+}
+
+// Range bounds the values of a column inclusively, a nil From or To leaves that side open
+type Range struct {
+	From interface{} `json:"from,omitempty"`
+	To   interface{} `json:"to,omitempty"`
 }
 
 // FilterOptions defines options for filter queries
@@ -101,12 +137,41 @@ type DatastoreInterface interface {
 	UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error)
 	DeleteApplication(ctx context.Context, id uint) error
 
+	// Feature flag operations
+	CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error)
+	ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error)
+	UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	DeleteFeatureFlag(ctx context.Context, key string) error
+
 	// Database operations
 	Migrate() error
 	Close() error
 	HealthCheck() error
 }
 
+// SchemaChecker is implemented by datastores that can verify, without
+// migrating, that the database schema has every table and column of the models
+type SchemaChecker interface {
+	CheckSchema() error
+}
+
+// PoolingChecker is implemented by datastores that can probe the connection
+// pooling proxy between them and the database
+type PoolingChecker interface {
+	// CheckPooling returns an error wrapping ErrPoolingIncompatible when the
+	// proxy does not suit the configured connection handling
+	CheckPooling(ctx context.Context) error
+}
+
+// TenantProvisioner is implemented by datastores isolating every tenant, an
+// organization, in a schema of its own
+type TenantProvisioner interface {
+	// ProvisionTenant creates the schema of tenant when missing and migrates
+	// its tables; provisioning an existing tenant brings its schema up to date
+	ProvisionTenant(ctx context.Context, tenant uint) error
+}
+
 // Cache interface for caching layer
 type Cache interface {
 	Get(ctx context.Context, key string) (interface{}, error)
@@ -117,6 +182,26 @@ type Cache interface {
 	Expire(ctx context.Context, key string, ttl time.Duration) error
 }
 
+// CacheStats describes cache usage since the cache was created
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Items  int    `json:"items"`
+}
+
+// HitRate returns the fraction of lookups that were hits, 0 before the first lookup
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// CacheStatsProvider is implemented by caches that count hits and misses
+type CacheStatsProvider interface {
+	Stats() CacheStats
+}
+
 // CacheConfig defines cache configuration
 type CacheConfig struct {
 	Type     string        `json:"type"` // redis, memory
@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+)
+
+// CreateFeatureFlag creates a new feature flag
+func (m *Memory) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.featureFlags[flag.Key]; exists {
+		return nil, datastore.ErrDuplicateKey
+	}
+
+	now := timestamp.New(m.clock.Now())
+	flag.ID = idgen.Next()
+	if flag.ID == 0 {
+		flag.ID = m.nextFlagID
+		m.nextFlagID++
+	}
+	model.AssignPublicID(flag)
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+
+	m.featureFlags[flag.Key] = flag
+	return flag, nil
+}
+
+// GetFeatureFlagByKey retrieves a feature flag by key
+func (m *Memory) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	flag, exists := m.featureFlags[key]
+	if !exists {
+		return nil, datastore.ErrNotFound
+	}
+	return flag, nil
+}
+
+// ListFeatureFlags retrieves all feature flags ordered by key
+func (m *Memory) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	flags := make([]*model.FeatureFlag, 0, len(m.featureFlags))
+	for _, flag := range m.featureFlags {
+		flags = append(flags, flag)
+	}
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Key < flags[j].Key
+	})
+	return flags, nil
+}
+
+// UpdateFeatureFlag updates an existing feature flag
+func (m *Memory) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	existing, exists := m.featureFlags[flag.Key]
+	if !exists {
+		return nil, datastore.ErrNotFound
+	}
+
+	flag.ID = existing.ID
+	model.KeepPublicID(flag, existing.PublicID)
+	flag.CreatedAt = existing.CreatedAt
+	flag.UpdatedAt = timestamp.New(m.clock.Now())
+
+	m.featureFlags[flag.Key] = flag
+	return flag, nil
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (m *Memory) DeleteFeatureFlag(ctx context.Context, key string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if _, exists := m.featureFlags[key]; !exists {
+		return datastore.ErrNotFound
+	}
+	delete(m.featureFlags, key)
+	return nil
+}
@@ -3,157 +3,145 @@ package memory
 import (
 	"context"
 	"sync"
-	"time"
 
 	"github.com/make-bin/server-tpl/pkg/domain/model"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
 // Memory implements DatastoreInterface using in-memory storage
 type Memory struct {
-	applications map[uint]*model.Application
-	nameIndex    map[string]uint
-	nextID       uint
+	tables       map[string]*datastore.MemoryTable
+	featureFlags map[string]*model.FeatureFlag
+	nextFlagID   uint
+	clock        clock.Clock
 	mutex        sync.RWMutex
+	txMutex      sync.Mutex
 }
 
-// New creates a new Memory datastore instance
-func New() (datastore.DatastoreInterface, error) {
+// New creates a new Memory datastore instance whose timestamps are read from clk
+func New(clk clock.Clock) (datastore.DatastoreInterface, error) {
 	logger.Info("Initialized in-memory datastore")
 
 	return &Memory{
-		applications: make(map[uint]*model.Application),
-		nameIndex:    make(map[string]uint),
-		nextID:       1,
+		tables:       newTables(clk),
+		featureFlags: make(map[string]*model.FeatureFlag),
+		nextFlagID:   1,
+		clock:        clk,
 	}, nil
 }
 
-// CreateApplication creates a new application
-func (m *Memory) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+// newTables creates the tables that carry unique constraints
+func newTables(clk clock.Clock) map[string]*datastore.MemoryTable {
+	return map[string]*datastore.MemoryTable{
+		(&model.Application{}).TableName():            datastore.NewMemoryTable(clk, "org_id,name"),
+		(&model.ApplicationVariable{}).TableName():    datastore.NewMemoryTable(clk, "app_id,key"),
+		(&model.ApplicationRevision{}).TableName():    datastore.NewMemoryTable(clk, "app_id,revision"),
+		(&model.ApplicationBackup{}).TableName():      datastore.NewMemoryTable(clk, "backup_id"),
+		(&model.File{}).TableName():                   datastore.NewMemoryTable(clk, "storage_key"),
+		(&model.UploadSession{}).TableName():          datastore.NewMemoryTable(clk, "file_id"),
+		(&model.Operation{}).TableName():              datastore.NewMemoryTable(clk, "operation_id"),
+		(&model.NotificationPreference{}).TableName(): datastore.NewMemoryTable(clk, "user_id,channel"),
+		(&model.PolicyDocument{}).TableName():         datastore.NewMemoryTable(clk, "name,version,language"),
+		(&model.PolicyConsent{}).TableName():          datastore.NewMemoryTable(clk, "user_id,policy,version"),
+		(&model.Partner{}).TableName():                datastore.NewMemoryTable(clk, "name"),
+		(&model.OutboxMessage{}).TableName():          datastore.NewMemoryTable(clk, "message_id"),
+		(&model.ProcessedMessage{}).TableName():       datastore.NewMemoryTable(clk, "consumer_group,message_id"),
+		(&model.UserPreferences{}).TableName():        datastore.NewMemoryTable(clk, "user_id"),
+		(&model.Organization{}).TableName():           datastore.NewMemoryTable(clk, "name"),
+		(&model.OrganizationMember{}).TableName():     datastore.NewMemoryTable(clk, "org_id,user_id"),
+		(&model.User{}).TableName():                   datastore.NewMemoryTable(clk, "email"),
+	}
+}
+
+// Table returns the table backing generic repositories for the named entity table
+func (m *Memory) Table(name string) *datastore.MemoryTable {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
-	// Check if name already exists
-	if _, exists := m.nameIndex[app.Name]; exists {
-		return nil, datastore.ErrDuplicateKey
+	table, exists := m.tables[name]
+	if !exists {
+		table = datastore.NewMemoryTable(m.clock)
+		m.tables[name] = table
 	}
+	return table
+}
 
-	// Set ID and timestamps
-	app.ID = m.nextID
-	app.CreatedAt = time.Now()
-	app.UpdatedAt = time.Now()
-	m.nextID++
-
-	// Store application
-	m.applications[app.ID] = app
-	m.nameIndex[app.Name] = app.ID
+// applications returns the repository shared by the application methods
+func (m *Memory) applications() (datastore.Repository[*model.Application], error) {
+	return datastore.NewMemoryRepository[*model.Application](m.Table((&model.Application{}).TableName()))
+}
 
-	return app, nil
+// CreateApplication creates a new application
+func (m *Memory) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Create(ctx, app)
 }
 
 // GetApplicationByID retrieves an application by ID
 func (m *Memory) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	app, exists := m.applications[id]
-	if !exists {
-		return nil, datastore.ErrNotFound
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
 	}
-
-	return app, nil
+	return repo.Get(ctx, id)
 }
 
 // GetApplicationByName retrieves an application by name
 func (m *Memory) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
 
-	id, exists := m.nameIndex[name]
-	if !exists {
+	apps, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{"name": name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(apps) == 0 {
 		return nil, datastore.ErrNotFound
 	}
-
-	app := m.applications[id]
-	return app, nil
+	return apps[0], nil
 }
 
 // ListApplications retrieves a paginated list of applications
 func (m *Memory) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
-	m.mutex.RLock()
-	defer m.mutex.RUnlock()
-
-	total := int64(len(m.applications))
-
-	// Convert map to slice
-	apps := make([]*model.Application, 0, len(m.applications))
-	for _, app := range m.applications {
-		apps = append(apps, app)
+	repo, err := m.applications()
+	if err != nil {
+		return nil, 0, err
 	}
 
-	// Apply pagination
-	start := (page - 1) * pageSize
-	end := start + pageSize
-
-	if start >= len(apps) {
-		return []*model.Application{}, total, nil
+	total, err := repo.Count(ctx, datastore.ListOptions{})
+	if err != nil {
+		return nil, 0, err
 	}
-
-	if end > len(apps) {
-		end = len(apps)
+	apps, err := repo.List(ctx, datastore.ListOptions{Page: page, Size: pageSize})
+	if err != nil {
+		return nil, 0, err
 	}
-
-	paginatedApps := apps[start:end]
-	return paginatedApps, total, nil
+	return apps, total, nil
 }
 
 // UpdateApplication updates an existing application
 func (m *Memory) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	// Check if application exists
-	existing, exists := m.applications[app.ID]
-	if !exists {
-		return nil, datastore.ErrNotFound
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
 	}
-
-	// Check if name changed and new name already exists
-	if existing.Name != app.Name {
-		if _, nameExists := m.nameIndex[app.Name]; nameExists {
-			return nil, datastore.ErrDuplicateKey
-		}
-		// Update name index
-		delete(m.nameIndex, existing.Name)
-		m.nameIndex[app.Name] = app.ID
-	}
-
-	// Update timestamps
-	app.CreatedAt = existing.CreatedAt
-	app.UpdatedAt = time.Now()
-
-	// Store updated application
-	m.applications[app.ID] = app
-
-	return app, nil
+	return repo.Update(ctx, app)
 }
 
 // DeleteApplication deletes an application by ID
 func (m *Memory) DeleteApplication(ctx context.Context, id uint) error {
-	m.mutex.Lock()
-	defer m.mutex.Unlock()
-
-	app, exists := m.applications[id]
-	if !exists {
-		return datastore.ErrNotFound
+	repo, err := m.applications()
+	if err != nil {
+		return err
 	}
-
-	// Remove from both maps
-	delete(m.applications, id)
-	delete(m.nameIndex, app.Name)
-
-	return nil
+	return repo.Delete(ctx, id)
 }
 
 // Migrate runs database migrations (no-op for memory)
@@ -168,14 +156,34 @@ func (m *Memory) Close() error {
 	defer m.mutex.Unlock()
 
 	// Clear all data
-	m.applications = make(map[uint]*model.Application)
-	m.nameIndex = make(map[string]uint)
-	m.nextID = 1
+	for _, table := range m.tables {
+		table.Reset()
+	}
+	m.featureFlags = make(map[string]*model.FeatureFlag)
+	m.nextFlagID = 1
 
 	logger.Info("Memory datastore closed")
 	return nil
 }
 
+// OnStop closes the datastore when the container stops
+func (m *Memory) OnStop(ctx context.Context) error {
+	return m.Close()
+}
+
+// Stats returns the row count of every table
+func (m *Memory) Stats() *datastore.DatastoreStats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	tables := make(map[string]int, len(m.tables)+1)
+	for name, table := range m.tables {
+		tables[name] = table.Len()
+	}
+	tables[(&model.FeatureFlag{}).TableName()] = len(m.featureFlags)
+	return &datastore.DatastoreStats{Driver: "memory", Tables: tables}
+}
+
 // HealthCheck checks the datastore health (always healthy for memory)
 func (m *Memory) HealthCheck() error {
 	return nil
@@ -0,0 +1,72 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// snapshot captures the datastore state for rollback
+type snapshot struct {
+	tables       map[string]datastore.MemoryTableSnapshot
+	featureFlags map[string]*model.FeatureFlag
+	nextFlagID   uint
+}
+
+// Transaction runs fn against the datastore and restores the previous state when
+// fn fails or panics. Transactions are serialized; writes made outside a
+// transaction while one is running are discarded if it rolls back.
+func (m *Memory) Transaction(ctx context.Context, fn func(tx datastore.DatastoreInterface) error) (err error) {
+	m.txMutex.Lock()
+	defer m.txMutex.Unlock()
+
+	saved := m.snapshot()
+	defer func() {
+		if r := recover(); r != nil {
+			m.restore(saved)
+			panic(r)
+		}
+		if err != nil {
+			m.restore(saved)
+		}
+	}()
+
+	return fn(m)
+}
+
+// snapshot copies the state of every table
+func (m *Memory) snapshot() snapshot {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	saved := snapshot{
+		tables:       make(map[string]datastore.MemoryTableSnapshot, len(m.tables)),
+		featureFlags: make(map[string]*model.FeatureFlag, len(m.featureFlags)),
+		nextFlagID:   m.nextFlagID,
+	}
+	for name, table := range m.tables {
+		saved.tables[name] = table.Snapshot()
+	}
+	for key, flag := range m.featureFlags {
+		saved.featureFlags[key] = flag
+	}
+	return saved
+}
+
+// restore rolls every table back to the snapshot. Tables created after the
+// snapshot was taken are emptied.
+func (m *Memory) restore(saved snapshot) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for name, table := range m.tables {
+		if tableSnapshot, ok := saved.tables[name]; ok {
+			table.Restore(tableSnapshot)
+		} else {
+			table.Reset()
+		}
+	}
+	m.featureFlags = saved.featureFlags
+	m.nextFlagID = saved.nextFlagID
+}
@@ -0,0 +1,415 @@
+package datastore
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+)
+
+// MemoryTable stores the rows of one entity type for in-memory datastores
+type MemoryTable struct {
+	mu     sync.RWMutex
+	rows   map[uint]model.Entity
+	nextID uint
+	unique []string
+	clock  clock.Clock
+}
+
+// NewMemoryTable creates an empty table whose timestamps are read from clk.
+// Unique columns are checked against the values returned by Entity.Index on
+// create and update; a comma separated entry such as "app_id,key" makes the
+// combination of the columns unique.
+func NewMemoryTable(clk clock.Clock, uniqueColumns ...string) *MemoryTable {
+	return &MemoryTable{
+		rows:   make(map[uint]model.Entity),
+		nextID: 1,
+		unique: uniqueColumns,
+		clock:  clk,
+	}
+}
+
+// Reset removes all rows and restarts ID assignment
+func (t *MemoryTable) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rows = make(map[uint]model.Entity)
+	t.nextID = 1
+}
+
+// Len returns the number of rows in the table
+func (t *MemoryTable) Len() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return len(t.rows)
+}
+
+// MemoryTableSnapshot captures the state of a MemoryTable for rollback
+type MemoryTableSnapshot struct {
+	rows   map[uint]model.Entity
+	nextID uint
+}
+
+// Snapshot returns the current table state. Stored rows are never mutated in
+// place, so a shallow copy of the row map is sufficient.
+func (t *MemoryTable) Snapshot() MemoryTableSnapshot {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	rows := make(map[uint]model.Entity, len(t.rows))
+	for id, row := range t.rows {
+		rows[id] = row
+	}
+	return MemoryTableSnapshot{rows: rows, nextID: t.nextID}
+}
+
+// Restore replaces the table state with a snapshot
+func (t *MemoryTable) Restore(snapshot MemoryTableSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rows = snapshot.rows
+	t.nextID = snapshot.nextID
+}
+
+// conflicts reports whether entity violates a unique column, caller must hold the lock
+func (t *MemoryTable) conflicts(entity model.Entity) bool {
+	if len(t.unique) == 0 {
+		return false
+	}
+
+	index := entity.Index()
+	for id, row := range t.rows {
+		if id == entity.GetID() {
+			continue
+		}
+		rowIndex := row.Index()
+		for _, columns := range t.unique {
+			if uniqueEqual(strings.Split(columns, ","), index, rowIndex) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// uniqueEqual reports whether two rows have equal values in every column
+func uniqueEqual(columns []string, a, b map[string]interface{}) bool {
+	for _, column := range columns {
+		if !valuesEqual(a[column], b[column]) {
+			return false
+		}
+	}
+	return true
+}
+
+// memoryRepository implements Repository on top of a MemoryTable
+type memoryRepository[T model.Entity] struct {
+	table *MemoryTable
+}
+
+// NewMemoryRepository creates an in-memory repository for T
+func NewMemoryRepository[T model.Entity](table *MemoryTable) (Repository[T], error) {
+	if err := checkEntityType[T](); err != nil {
+		return nil, err
+	}
+	return &memoryRepository[T]{table: table}, nil
+}
+
+// Get retrieves an entity by ID
+func (r *memoryRepository[T]) Get(ctx context.Context, id uint) (T, error) {
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	return r.row(id)
+}
+
+// List retrieves entities matching the options
+func (r *memoryRepository[T]) List(ctx context.Context, opts ListOptions) ([]T, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if err := checkTagged[T](opts); err != nil {
+		return nil, err
+	}
+
+	r.table.mu.RLock()
+	entities, err := r.match(opts)
+	r.table.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(entities, func(i, j int) bool {
+		return opts.Less(entities[i], entities[j])
+	})
+
+	if opts.Size > 0 {
+		start := opts.offset()
+		if start >= len(entities) {
+			return []T{}, nil
+		}
+		end := start + opts.Size
+		if end > len(entities) {
+			end = len(entities)
+		}
+		entities = entities[start:end]
+	}
+	return entities, nil
+}
+
+// Create stores a new entity
+func (r *memoryRepository[T]) Create(ctx context.Context, entity T) (T, error) {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	entity.SetID(0)
+	if r.table.conflicts(entity) {
+		var zero T
+		return zero, ErrDuplicateKey
+	}
+
+	id := idgen.Next()
+	if id == 0 {
+		id = r.table.nextID
+		r.table.nextID++
+	}
+	now := r.table.clock.Now()
+	entity.SetID(id)
+	model.AssignPublicID(entity)
+	entity.SetCreateTime(now)
+	entity.SetUpdateTime(now)
+
+	r.table.rows[entity.GetID()] = cloneEntity(entity)
+	return entity, nil
+}
+
+// Update replaces an existing entity, keeping its creation time and public ID
+func (r *memoryRepository[T]) Update(ctx context.Context, entity T) (T, error) {
+	return r.UpdateMatching(ctx, entity, nil)
+}
+
+// UpdateMatching replaces an existing entity whose stored copy matches filters
+// under one lock
+func (r *memoryRepository[T]) UpdateMatching(ctx context.Context, entity T, filters map[string]interface{}) (T, error) {
+	opts := ListOptions{Filters: filters}
+	if err := opts.validate(); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	existing, err := r.row(entity.GetID())
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	if !opts.Matches(existing) {
+		var zero T
+		return zero, ErrNotFound
+	}
+	if r.table.conflicts(entity) {
+		var zero T
+		return zero, ErrDuplicateKey
+	}
+
+	if stored, ok := any(existing).(model.PublicIdentified); ok {
+		model.KeepPublicID(entity, stored.GetPublicID())
+	}
+	entity.SetCreateTime(existing.GetCreatedAt())
+	entity.SetUpdateTime(r.table.clock.Now())
+	r.table.rows[entity.GetID()] = cloneEntity(entity)
+	return entity, nil
+}
+
+// Delete removes an entity by ID
+func (r *memoryRepository[T]) Delete(ctx context.Context, id uint) error {
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	if _, exists := r.table.rows[id]; !exists {
+		return ErrNotFound
+	}
+	delete(r.table.rows, id)
+	return nil
+}
+
+// DeleteMatching removes the entities matching the filters under one lock
+func (r *memoryRepository[T]) DeleteMatching(ctx context.Context, opts DeleteOptions) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+
+	r.table.mu.Lock()
+	defer r.table.mu.Unlock()
+
+	entities, err := r.match(ListOptions{Filters: opts.Filters})
+	if err != nil {
+		return 0, err
+	}
+	for _, entity := range entities {
+		delete(r.table.rows, entity.GetID())
+	}
+	return int64(len(entities)), nil
+}
+
+// Count returns the number of entities matching the filters
+func (r *memoryRepository[T]) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	if err := checkTagged[T](opts); err != nil {
+		return 0, err
+	}
+
+	r.table.mu.RLock()
+	defer r.table.mu.RUnlock()
+
+	entities, err := r.match(opts)
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(entities)), nil
+}
+
+// row returns a copy of the typed row for id, caller must hold the lock
+func (r *memoryRepository[T]) row(id uint) (T, error) {
+	var zero T
+	row, exists := r.table.rows[id]
+	if !exists {
+		return zero, ErrNotFound
+	}
+	entity, ok := row.(T)
+	if !ok {
+		return zero, fmt.Errorf("memory table row %d has type %T, expected %T", id, row, zero)
+	}
+	return cloneEntity(entity), nil
+}
+
+// cloneEntity returns a shallow copy so callers cannot mutate stored rows
+func cloneEntity[T model.Entity](entity T) T {
+	value := reflect.ValueOf(entity)
+	clone := reflect.New(value.Type().Elem())
+	clone.Elem().Set(value.Elem())
+	return clone.Interface().(T)
+}
+
+// match returns the rows matching the filters, caller must hold the lock
+func (r *memoryRepository[T]) match(opts ListOptions) ([]T, error) {
+	entities := make([]T, 0, len(r.table.rows))
+	for id := range r.table.rows {
+		entity, err := r.row(id)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Matches(entity) {
+			entities = append(entities, entity)
+		}
+	}
+	return entities, nil
+}
+
+// valuesMatch reports whether an index value matches a filter, which is either
+// a single value or a slice of accepted values as with GORM's IN conditions
+func valuesMatch(indexValue, filter interface{}) bool {
+	v := reflect.ValueOf(filter)
+	if filter == nil || v.Kind() != reflect.Slice || v.Type().Elem().Kind() == reflect.Uint8 {
+		return valuesEqual(indexValue, filter)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if valuesEqual(indexValue, v.Index(i).Interface()) {
+			return true
+		}
+	}
+	return false
+}
+
+// valuesEqual compares index values loosely so that e.g. int and uint filters match
+func valuesEqual(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// compareValues orders index values of the same kind, returning -1, 0 or 1
+func compareValues(a, b interface{}) int {
+	switch av := a.(type) {
+	case time.Time:
+		if bv, ok := b.(time.Time); ok {
+			return av.Compare(bv)
+		}
+	case bool:
+		if bv, ok := b.(bool); ok && av != bv {
+			if !av {
+				return -1
+			}
+			return 1
+		}
+		return 0
+	case uint:
+		// IDs are compared exactly, generated ones exceed the precision of float64
+		if bv, ok := b.(uint); ok {
+			return cmp.Compare(av, bv)
+		}
+	}
+
+	af, aNum := toFloat(a)
+	bf, bNum := toFloat(b)
+	if aNum && bNum {
+		switch {
+		case af < bf:
+			return -1
+		case af > bf:
+			return 1
+		default:
+			return 0
+		}
+	}
+
+	as, bs := fmt.Sprint(a), fmt.Sprint(b)
+	switch {
+	case as < bs:
+		return -1
+	case as > bs:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// toFloat converts numeric index values for comparison
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,617 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/timestamp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/topology"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// mongoCountersField holds the last allocated ID in the documents of the counters collection
+const mongoCountersField = "seq"
+
+var (
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+	timeType      = reflect.TypeOf(time.Time{})
+	timestampType = reflect.TypeOf(timestamp.Time{})
+)
+
+// NewMongoRegistry returns the BSON registry of MongoDB datastores. Field names
+// follow the json tags, which match the SQL columns and the keys of
+// Entity.Index; embedded structs are inlined and the id field is stored as
+// _id. Fields tagged gorm:"-" and the GORM soft delete field are not stored,
+// and empty fields are kept so that filters on zero values match. A bson tag
+// overrides these rules. timestamp.Time fields are stored as BSON dates.
+func NewMongoRegistry() *bsoncodec.Registry {
+	// Custom tag parsers are deprecated for the 2.0 driver but supported by v1,
+	// and keep storage tags out of the domain models
+	codec, err := bsoncodec.NewStructCodec(bsoncodec.StructTagParserFunc(parseMongoTags))
+	if err != nil {
+		// Only returned for a nil parser
+		panic(err)
+	}
+
+	registry := bson.NewRegistry()
+	registry.RegisterKindEncoder(reflect.Struct, codec)
+	registry.RegisterKindDecoder(reflect.Struct, codec)
+	registry.RegisterTypeEncoder(timestampType, bsoncodec.ValueEncoderFunc(encodeTimestamp))
+	registry.RegisterTypeDecoder(timestampType, bsoncodec.ValueDecoderFunc(decodeTimestamp))
+	return registry
+}
+
+// encodeTimestamp writes a timestamp.Time with the time.Time encoder
+func encodeTimestamp(ec bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	t, ok := val.Interface().(timestamp.Time)
+	if !ok {
+		return bsoncodec.ValueEncoderError{Name: "TimestampEncodeValue", Types: []reflect.Type{timestampType}, Received: val}
+	}
+	encoder, err := ec.LookupEncoder(timeType)
+	if err != nil {
+		return err
+	}
+	return encoder.EncodeValue(ec, vw, reflect.ValueOf(t.Time))
+}
+
+// decodeTimestamp reads a timestamp.Time with the time.Time decoder, in UTC
+func decodeTimestamp(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	if !val.CanSet() || val.Type() != timestampType {
+		return bsoncodec.ValueDecoderError{Name: "TimestampDecodeValue", Types: []reflect.Type{timestampType}, Received: val}
+	}
+	decoder, err := dc.LookupDecoder(timeType)
+	if err != nil {
+		return err
+	}
+	var t time.Time
+	if err := decoder.DecodeValue(dc, vr, reflect.ValueOf(&t).Elem()); err != nil {
+		return err
+	}
+	val.Set(reflect.ValueOf(timestamp.New(t)))
+	return nil
+}
+
+// parseMongoTags derives the BSON field of a struct field, see NewMongoRegistry
+func parseMongoTags(sf reflect.StructField) (bsoncodec.StructTags, error) {
+	if _, ok := sf.Tag.Lookup("bson"); ok {
+		return bsoncodec.DefaultStructTagParser.ParseStructTags(sf)
+	}
+	if sf.Tag.Get("gorm") == "-" || sf.Type == deletedAtType {
+		return bsoncodec.StructTags{Skip: true}, nil
+	}
+
+	typ := sf.Type
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if sf.Anonymous && typ.Kind() == reflect.Struct {
+		return bsoncodec.StructTags{Name: strings.ToLower(sf.Name), Inline: true}, nil
+	}
+
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	switch name {
+	case "", "-":
+		// Fields hidden from the API are still stored
+		name = strings.ToLower(sf.Name)
+	case "id":
+		name = "_id"
+	}
+	return bsoncodec.StructTags{Name: name}, nil
+}
+
+// MongoCollection stores the documents of one entity type for MongoDB
+// datastores. IDs are allocated from a sequence document named after the
+// collection, outside of transactions, so that a rolled back transaction
+// leaves a gap as with SQL sequences. Timestamps are read from the clock and
+// truncated to the millisecond precision of BSON dates.
+type MongoCollection struct {
+	collection *mongo.Collection
+	counters   *mongo.Collection
+	session    mongo.Session
+	clock      clock.Clock
+}
+
+// NewMongoCollection creates a collection whose IDs are allocated in counters.
+// Operations join the transaction of session unless it is nil.
+func NewMongoCollection(collection, counters *mongo.Collection, session mongo.Session, clk clock.Clock) *MongoCollection {
+	return &MongoCollection{
+		collection: collection,
+		counters:   counters,
+		session:    session,
+		clock:      clk,
+	}
+}
+
+// MongoCollectionProvider is implemented by MongoDB datastores
+type MongoCollectionProvider interface {
+	Collection(name string) *MongoCollection
+}
+
+// Insert stores a new entity and assigns its ID, public ID and timestamps
+func (c *MongoCollection) Insert(ctx context.Context, entity model.Entity) error {
+	id := idgen.Next()
+	if id == 0 {
+		var err error
+		if id, err = c.nextID(ctx); err != nil {
+			return err
+		}
+	}
+
+	now := c.now()
+	entity.SetID(id)
+	model.AssignPublicID(entity)
+	entity.SetCreateTime(now)
+	entity.SetUpdateTime(now)
+	if _, err := c.collection.InsertOne(c.context(ctx), entity); err != nil {
+		entity.SetID(0)
+		return TranslateMongoError(err)
+	}
+	return nil
+}
+
+// Replace replaces an existing entity, keeping its creation time and public ID
+func (c *MongoCollection) Replace(ctx context.Context, entity model.Entity) error {
+	return c.ReplaceMatching(ctx, entity, ListOptions{})
+}
+
+// ReplaceMatching replaces an existing entity that also matches the filters in
+// opts, returning ErrNotFound otherwise
+func (c *MongoCollection) ReplaceMatching(ctx context.Context, entity model.Entity, opts ListOptions) error {
+	filter, err := mongoFilter(opts)
+	if err != nil {
+		return err
+	}
+	filter = append(bson.D{{Key: "_id", Value: entity.GetID()}}, filter...)
+	ctx = c.context(ctx)
+
+	var existing struct {
+		CreatedAt time.Time `bson:"created_at"`
+		PublicID  string    `bson:"public_id"`
+	}
+	err = c.collection.FindOne(ctx, filter,
+		options.FindOne().SetProjection(bson.D{{Key: "created_at", Value: 1}, {Key: "public_id", Value: 1}})).Decode(&existing)
+	if err != nil {
+		return TranslateMongoError(err)
+	}
+
+	model.KeepPublicID(entity, existing.PublicID)
+	entity.SetCreateTime(existing.CreatedAt)
+	entity.SetUpdateTime(c.now())
+	result, err := c.collection.ReplaceOne(ctx, filter, entity)
+	if err != nil {
+		return TranslateMongoError(err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// FindByID decodes the entity with id into entity
+func (c *MongoCollection) FindByID(ctx context.Context, id uint, entity model.Entity) error {
+	err := c.collection.FindOne(c.context(ctx), bson.D{{Key: "_id", Value: id}}).Decode(entity)
+	return TranslateMongoError(err)
+}
+
+// Find decodes the entities matching opts into results, a pointer to a slice
+func (c *MongoCollection) Find(ctx context.Context, opts ListOptions, results interface{}) error {
+	filter, err := mongoFilter(opts)
+	if err != nil {
+		return err
+	}
+
+	sort := bson.D{}
+	seen := make(map[string]bool)
+	for _, order := range opts.Orders() {
+		field := mongoField(order.Column)
+		if seen[field] {
+			continue
+		}
+		seen[field] = true
+		direction := 1
+		if order.Desc {
+			direction = -1
+		}
+		sort = append(sort, bson.E{Key: field, Value: direction})
+	}
+	if !seen["_id"] {
+		sort = append(sort, bson.E{Key: "_id", Value: 1})
+	}
+	findOptions := options.Find().SetSort(sort)
+	if opts.Size > 0 {
+		findOptions.SetSkip(int64(opts.offset())).SetLimit(int64(opts.Size))
+	}
+
+	ctx = c.context(ctx)
+	cursor, err := c.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return TranslateMongoError(err)
+	}
+	return TranslateMongoError(cursor.All(ctx, results))
+}
+
+// Count returns the number of entities matching the filters in opts
+func (c *MongoCollection) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	filter, err := mongoFilter(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	total, err := c.collection.CountDocuments(c.context(ctx), filter)
+	if err != nil {
+		return 0, TranslateMongoError(err)
+	}
+	return total, nil
+}
+
+// Delete removes the entity with id
+func (c *MongoCollection) Delete(ctx context.Context, id uint) error {
+	result, err := c.collection.DeleteOne(c.context(ctx), bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		return TranslateMongoError(err)
+	}
+	if result.DeletedCount == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteMany removes the entities matching the filters in opts, returning how
+// many were removed
+func (c *MongoCollection) DeleteMany(ctx context.Context, opts ListOptions) (int64, error) {
+	filter, err := mongoFilter(opts)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := c.collection.DeleteMany(c.context(ctx), filter)
+	if err != nil {
+		return 0, TranslateMongoError(err)
+	}
+	return result.DeletedCount, nil
+}
+
+// EnsureIndexes creates an index on every key of entity.Index, on the tags of
+// tagged entities and a unique index for every entry of unique. A comma
+// separated entry such as "app_id,key" makes the combination unique. Keys
+// leading a unique index are not indexed again.
+func (c *MongoCollection) EnsureIndexes(ctx context.Context, entity model.Entity, unique ...string) error {
+	var indexes []mongo.IndexModel
+	names := mongoColumns(entity)
+	field := func(column string) string {
+		if name, ok := names[column]; ok {
+			return name
+		}
+		return mongoField(column)
+	}
+	covered := map[string]bool{"_id": true}
+	for _, columns := range unique {
+		keys := bson.D{}
+		for _, column := range strings.Split(columns, ",") {
+			keys = append(keys, bson.E{Key: field(column), Value: 1})
+		}
+		covered[keys[0].Key] = true
+		indexes = append(indexes, mongo.IndexModel{Keys: keys, Options: options.Index().SetUnique(true)})
+	}
+
+	columns := make([]string, 0, len(entity.Index())+1)
+	for column := range entity.Index() {
+		columns = append(columns, field(column))
+	}
+	if _, ok := entity.(model.Tagged); ok {
+		columns = append(columns, "tags")
+	}
+	for _, column := range columns {
+		if !covered[column] {
+			covered[column] = true
+			indexes = append(indexes, mongo.IndexModel{Keys: bson.D{{Key: column, Value: 1}}})
+		}
+	}
+
+	if len(indexes) == 0 {
+		return nil
+	}
+	if _, err := c.collection.Indexes().CreateMany(c.context(ctx), indexes); err != nil {
+		return fmt.Errorf("failed to create indexes on %s: %w", c.collection.Name(), err)
+	}
+	return nil
+}
+
+// DropUniqueIndex drops the named index when the collection has it as a unique
+// index, so that EnsureIndexes replaces a unique index of an earlier schema
+func (c *MongoCollection) DropUniqueIndex(ctx context.Context, name string) error {
+	cursor, err := c.collection.Indexes().List(c.context(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to list indexes on %s: %w", c.collection.Name(), err)
+	}
+	var indexes []struct {
+		Name   string `bson:"name"`
+		Unique bool   `bson:"unique"`
+	}
+	if err := cursor.All(c.context(ctx), &indexes); err != nil {
+		return fmt.Errorf("failed to list indexes on %s: %w", c.collection.Name(), err)
+	}
+
+	for _, index := range indexes {
+		if index.Name != name || !index.Unique {
+			continue
+		}
+		if _, err := c.collection.Indexes().DropOne(c.context(ctx), name); err != nil {
+			return fmt.Errorf("failed to drop index %s on %s: %w", name, c.collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+// nextID allocates the next ID of the collection
+func (c *MongoCollection) nextID(ctx context.Context) (uint, error) {
+	var counter struct {
+		Seq int64 `bson:"seq"`
+	}
+	err := c.counters.FindOneAndUpdate(ctx,
+		bson.D{{Key: "_id", Value: c.collection.Name()}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: mongoCountersField, Value: 1}}}},
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate ID for %s: %w", c.collection.Name(), TranslateMongoError(err))
+	}
+	return uint(counter.Seq), nil
+}
+
+// now returns the current time as stored by MongoDB
+func (c *MongoCollection) now() time.Time {
+	return c.clock.Now().UTC().Truncate(time.Millisecond)
+}
+
+// context binds ctx to the transaction of the collection, if any
+func (c *MongoCollection) context(ctx context.Context) context.Context {
+	if c.session == nil {
+		return ctx
+	}
+	return mongo.NewSessionContext(ctx, c.session)
+}
+
+// mongoField returns the document field of a column
+func mongoField(column string) string {
+	if column == "id" {
+		return "_id"
+	}
+	return column
+}
+
+// mongoColumnCache holds the result of mongoColumns by entity type
+var mongoColumnCache sync.Map
+
+// mongoColumns maps the SQL columns of the fields of entity stored under
+// another BSON name, the fields hidden from the API, to that name, so that
+// filters and Entity.Index name the same columns for every datastore
+func mongoColumns(entity interface{}) map[string]string {
+	typ := reflect.TypeOf(entity)
+	if cached, ok := mongoColumnCache.Load(typ); ok {
+		return cached.(map[string]string)
+	}
+
+	columns := make(map[string]string)
+	if parsed, err := schema.Parse(entity, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+		for _, field := range parsed.Fields {
+			if field.DBName == "" {
+				continue
+			}
+			tags, err := parseMongoTags(field.StructField)
+			if err != nil || tags.Skip || tags.Inline || tags.Name == "" {
+				continue
+			}
+			if tags.Name != field.DBName && tags.Name != mongoField(field.DBName) {
+				columns[field.DBName] = tags.Name
+			}
+		}
+	}
+	mongoColumnCache.Store(typ, columns)
+	return columns
+}
+
+// renameColumns returns opts with its filters and ranges on the keys of
+// columns moved to their BSON names
+func renameColumns(columns map[string]string, opts ListOptions) ListOptions {
+	if len(columns) == 0 {
+		return opts
+	}
+	rename := func(column string) string {
+		if name, ok := columns[column]; ok {
+			return name
+		}
+		return column
+	}
+	if opts.Filters != nil {
+		filters := make(map[string]interface{}, len(opts.Filters))
+		for column, value := range opts.Filters {
+			filters[rename(column)] = value
+		}
+		opts.Filters = filters
+	}
+	if opts.Ranges != nil {
+		ranges := make(map[string]Range, len(opts.Ranges))
+		for column, bounds := range opts.Ranges {
+			ranges[rename(column)] = bounds
+		}
+		opts.Ranges = ranges
+	}
+	return opts
+}
+
+// mongoFilter converts the filters, ranges and tag selector of opts to a query
+func mongoFilter(opts ListOptions) (bson.D, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{}
+	for column, value := range opts.Filters {
+		v := reflect.ValueOf(value)
+		if value != nil && v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+			// A slice accepts any of its elements as with GORM's IN conditions
+			value = bson.D{{Key: "$in", Value: value}}
+		}
+		filter = append(filter, bson.E{Key: mongoField(column), Value: value})
+	}
+	for column, bounds := range opts.Ranges {
+		condition := bson.D{}
+		if bounds.From != nil {
+			condition = append(condition, bson.E{Key: "$gte", Value: bounds.From})
+		}
+		if bounds.To != nil {
+			condition = append(condition, bson.E{Key: "$lte", Value: bounds.To})
+		}
+		if len(condition) > 0 {
+			filter = append(filter, bson.E{Key: mongoField(column), Value: condition})
+		}
+	}
+
+	terms := make(bson.A, 0, len(opts.Tags))
+	for _, term := range opts.Tags {
+		if strings.Contains(term, ":") {
+			terms = append(terms, bson.D{{Key: "tags", Value: term}})
+			continue
+		}
+		// A bare key also matches any "key:value" tag
+		prefix := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(term) + ":"}
+		terms = append(terms, bson.D{{Key: "tags", Value: bson.D{{Key: "$in", Value: bson.A{term, prefix}}}}})
+	}
+	if len(terms) > 0 {
+		filter = append(filter, bson.E{Key: "$and", Value: terms})
+	}
+	return filter, nil
+}
+
+// mongoWriteConflict is the code of a write conflicting with a concurrent transaction
+const mongoWriteConflict = 112
+
+// TranslateMongoError maps MongoDB errors to datastore errors: duplicate keys,
+// write conflicts and transient transaction errors, and network and server
+// selection errors. Errors already translated and errors without a datastore
+// counterpart are returned unchanged.
+func TranslateMongoError(err error) error {
+	var (
+		serverErr    mongo.ServerError
+		selectionErr topology.ServerSelectionError
+	)
+	switch {
+	case err == nil || translated(err):
+		return err
+	case errors.Is(err, mongo.ErrNoDocuments):
+		return ErrNotFound
+	case mongo.IsDuplicateKeyError(err):
+		return ErrDuplicateKey
+	case errors.As(err, &serverErr) &&
+		(serverErr.HasErrorCode(mongoWriteConflict) || serverErr.HasErrorLabel(driver.TransientTransactionError)):
+		return fmt.Errorf("%w: %w", ErrSerializationFailure, err)
+	case mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected), errors.As(err, &selectionErr):
+		return fmt.Errorf("%w: %w", ErrConnectionFailed, err)
+	default:
+		return err
+	}
+}
+
+// mongoRepository implements Repository on top of a MongoCollection
+type mongoRepository[T model.Entity] struct {
+	collection *MongoCollection
+	// columns maps the SQL columns of T stored under another BSON name to that name
+	columns map[string]string
+}
+
+// NewMongoRepository creates a MongoDB backed repository for T
+func NewMongoRepository[T model.Entity](collection *MongoCollection) (Repository[T], error) {
+	if err := checkEntityType[T](); err != nil {
+		return nil, err
+	}
+	return &mongoRepository[T]{collection: collection, columns: mongoColumns(newEntity[T]())}, nil
+}
+
+// Get retrieves an entity by ID
+func (r *mongoRepository[T]) Get(ctx context.Context, id uint) (T, error) {
+	entity := newEntity[T]()
+	if err := r.collection.FindByID(ctx, id, entity); err != nil {
+		var zero T
+		return zero, err
+	}
+	return entity, nil
+}
+
+// List retrieves entities matching the options
+func (r *mongoRepository[T]) List(ctx context.Context, opts ListOptions) ([]T, error) {
+	if err := checkTagged[T](opts); err != nil {
+		return nil, err
+	}
+
+	entities := []T{}
+	if err := r.collection.Find(ctx, renameColumns(r.columns, opts), &entities); err != nil {
+		return nil, err
+	}
+	return entities, nil
+}
+
+// Create stores a new entity
+func (r *mongoRepository[T]) Create(ctx context.Context, entity T) (T, error) {
+	if err := r.collection.Insert(ctx, entity); err != nil {
+		var zero T
+		return zero, err
+	}
+	return entity, nil
+}
+
+// Update replaces an existing entity, keeping its creation time
+func (r *mongoRepository[T]) Update(ctx context.Context, entity T) (T, error) {
+	if err := r.collection.Replace(ctx, entity); err != nil {
+		var zero T
+		return zero, err
+	}
+	return entity, nil
+}
+
+// UpdateMatching replaces an existing entity whose document matches the filters
+func (r *mongoRepository[T]) UpdateMatching(ctx context.Context, entity T, filters map[string]interface{}) (T, error) {
+	if err := r.collection.ReplaceMatching(ctx, entity, renameColumns(r.columns, ListOptions{Filters: filters})); err != nil {
+		var zero T
+		return zero, err
+	}
+	return entity, nil
+}
+
+// Delete removes an entity by ID
+func (r *mongoRepository[T]) Delete(ctx context.Context, id uint) error {
+	return r.collection.Delete(ctx, id)
+}
+
+// DeleteMatching removes the entities matching the filters with a single deleteMany
+func (r *mongoRepository[T]) DeleteMatching(ctx context.Context, opts DeleteOptions) (int64, error) {
+	if err := opts.validate(); err != nil {
+		return 0, err
+	}
+	return r.collection.DeleteMany(ctx, renameColumns(r.columns, ListOptions{Filters: opts.Filters}))
+}
+
+// Count returns the number of entities matching the filters
+func (r *mongoRepository[T]) Count(ctx context.Context, opts ListOptions) (int64, error) {
+	if err := checkTagged[T](opts); err != nil {
+		return 0, err
+	}
+	return r.collection.Count(ctx, renameColumns(r.columns, opts))
+}
@@ -0,0 +1,77 @@
+package mongodb
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// featureFlags returns the repository shared by the feature flag methods
+func (m *MongoDB) featureFlags() (datastore.Repository[*model.FeatureFlag], error) {
+	return datastore.NewMongoRepository[*model.FeatureFlag](m.Collection((&model.FeatureFlag{}).TableName()))
+}
+
+// CreateFeatureFlag creates a new feature flag
+func (m *MongoDB) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	repo, err := m.featureFlags()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Create(ctx, flag)
+}
+
+// GetFeatureFlagByKey retrieves a feature flag by key
+func (m *MongoDB) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	repo, err := m.featureFlags()
+	if err != nil {
+		return nil, err
+	}
+
+	flags, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{"key": key}})
+	if err != nil {
+		return nil, err
+	}
+	if len(flags) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return flags[0], nil
+}
+
+// ListFeatureFlags retrieves all feature flags ordered by key
+func (m *MongoDB) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	repo, err := m.featureFlags()
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx, datastore.ListOptions{SortBy: "key"})
+}
+
+// UpdateFeatureFlag updates the existing feature flag with the same key
+func (m *MongoDB) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	existing, err := m.GetFeatureFlagByKey(ctx, flag.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := m.featureFlags()
+	if err != nil {
+		return nil, err
+	}
+	flag.ID = existing.ID
+	return repo.Update(ctx, flag)
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (m *MongoDB) DeleteFeatureFlag(ctx context.Context, key string) error {
+	existing, err := m.GetFeatureFlagByKey(ctx, key)
+	if err != nil {
+		return err
+	}
+
+	repo, err := m.featureFlags()
+	if err != nil {
+		return err
+	}
+	return repo.Delete(ctx, existing.ID)
+}
@@ -0,0 +1,374 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+const (
+	// defaultPort is used when the configuration leaves the port unset
+	defaultPort = 27017
+	// defaultMaxPoolSize is the pool size of the driver when max_open_conns is unset
+	defaultMaxPoolSize = 100
+	// countersCollection holds the ID sequence of every collection
+	countersCollection = "counters"
+	// connectTimeout bounds the initial ping and topology discovery
+	connectTimeout = 10 * time.Second
+)
+
+// MongoDB implements DatastoreInterface using MongoDB. Entities are stored in
+// a collection named after their table, see datastore.NewMongoRegistry for the
+// document layout.
+type MongoDB struct {
+	client       *mongo.Client
+	db           *mongo.Database
+	session      mongo.Session // set on datastores bound to a transaction
+	clock        clock.Clock
+	pool         *poolStats
+	maxPoolSize  int
+	transactions bool // whether the deployment is a replica set or sharded cluster
+	// name and routes select the collections of the datastore among the models
+	name   string
+	routes datastore.Routes
+}
+
+// poolStats counts the connections of the client pool
+type poolStats struct {
+	open       atomic.Int64
+	inUse      atomic.Int64
+	idleClosed atomic.Int64
+}
+
+// monitor returns the pool monitor updating the counters
+func (s *poolStats) monitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				s.open.Add(1)
+			case event.ConnectionClosed:
+				s.open.Add(-1)
+				if e.Reason == event.ReasonIdle {
+					s.idleClosed.Add(1)
+				}
+			case event.GetSucceeded:
+				s.inUse.Add(1)
+			case event.ConnectionReturned:
+				s.inUse.Add(-1)
+			}
+		},
+	}
+}
+
+// New creates a new MongoDB datastore instance whose timestamps are read from clk
+func New(cfg *config.Config, clk clock.Clock) (datastore.DatastoreInterface, error) {
+	pool := &poolStats{}
+	opts := options.Client().
+		ApplyURI(connectionURI(cfg.Database)).
+		SetRegistry(datastore.NewMongoRegistry()).
+		SetBSONOptions(&options.BSONOptions{NilSliceAsEmpty: true, NilMapAsEmpty: true}).
+		SetPoolMonitor(pool.monitor())
+	if cfg.Database.MaxOpenConns > 0 {
+		opts.SetMaxPoolSize(uint64(cfg.Database.MaxOpenConns))
+	}
+	if cfg.Database.MaxIdleConns > 0 {
+		opts.SetMinPoolSize(uint64(cfg.Database.MaxIdleConns))
+	}
+	if cfg.Database.ConnMaxLifetime > 0 {
+		opts.SetMaxConnIdleTime(cfg.Database.ConnMaxLifetime)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w: %w", datastore.ErrConnectionFailed, err)
+	}
+	if err := client.Ping(ctx, readpref.Primary()); err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to connect to MongoDB: %w: %w", datastore.ErrConnectionFailed, err)
+	}
+
+	db := client.Database(cfg.Database.Database)
+	transactions, err := supportsTransactions(ctx, db)
+	if err != nil {
+		_ = client.Disconnect(context.Background())
+		return nil, fmt.Errorf("failed to detect MongoDB topology: %w", err)
+	}
+
+	logger.Info("Connected to MongoDB database")
+	if !transactions {
+		logger.Warn("MongoDB is a standalone server, transactions are not available")
+	}
+
+	maxPoolSize := cfg.Database.MaxOpenConns
+	if maxPoolSize == 0 {
+		maxPoolSize = defaultMaxPoolSize
+	}
+	return &MongoDB{
+		client:       client,
+		db:           db,
+		clock:        clk,
+		pool:         pool,
+		maxPoolSize:  maxPoolSize,
+		transactions: transactions,
+		name:         cfg.Database.DatastoreName(),
+		routes:       cfg.Database.Routes,
+	}, nil
+}
+
+// connectionURI returns the configured URI or builds one from the host, port
+// and credentials. The ssl_mode values of the SQL drivers map to TLS: require
+// encrypts without verifying the server certificate, verify-ca and
+// verify-full also verify it.
+func connectionURI(cfg config.DatabaseConfig) string {
+	if cfg.URI != "" {
+		return cfg.URI
+	}
+
+	port := cfg.Port
+	if port == 0 {
+		port = defaultPort
+	}
+	uri := url.URL{Scheme: "mongodb", Host: net.JoinHostPort(cfg.Host, strconv.Itoa(port)), Path: "/"}
+	if cfg.User != "" {
+		uri.User = url.UserPassword(cfg.User, cfg.Password)
+	}
+
+	query := url.Values{}
+	switch cfg.SSLMode {
+	case "require":
+		query.Set("tls", "true")
+		query.Set("tlsInsecure", "true")
+	case "verify-ca", "verify-full":
+		query.Set("tls", "true")
+	}
+	uri.RawQuery = query.Encode()
+	return uri.String()
+}
+
+// supportsTransactions reports whether the server is a replica set member or
+// a mongos router, the deployments supporting multi-document transactions
+func supportsTransactions(ctx context.Context, db *mongo.Database) (bool, error) {
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := db.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		return false, err
+	}
+	return hello.SetName != "" || hello.Msg == "isdbgrid", nil
+}
+
+// Collection returns the collection backing generic repositories for the named entity table
+func (m *MongoDB) Collection(name string) *datastore.MongoCollection {
+	return datastore.NewMongoCollection(m.db.Collection(name), m.db.Collection(countersCollection), m.session, m.clock)
+}
+
+// applications returns the repository shared by the application methods
+func (m *MongoDB) applications() (datastore.Repository[*model.Application], error) {
+	return datastore.NewMongoRepository[*model.Application](m.Collection((&model.Application{}).TableName()))
+}
+
+// CreateApplication creates a new application
+func (m *MongoDB) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Create(ctx, app)
+}
+
+// GetApplicationByID retrieves an application by ID
+func (m *MongoDB) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Get(ctx, id)
+}
+
+// GetApplicationByName retrieves an application by name
+func (m *MongoDB) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
+
+	apps, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{"name": name}})
+	if err != nil {
+		return nil, err
+	}
+	if len(apps) == 0 {
+		return nil, datastore.ErrNotFound
+	}
+	return apps[0], nil
+}
+
+// ListApplications retrieves a paginated list of applications
+func (m *MongoDB) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	total, err := repo.Count(ctx, datastore.ListOptions{})
+	if err != nil {
+		return nil, 0, err
+	}
+	apps, err := repo.List(ctx, datastore.ListOptions{Page: page, Size: pageSize})
+	if err != nil {
+		return nil, 0, err
+	}
+	return apps, total, nil
+}
+
+// UpdateApplication updates an existing application, keeping its creation time
+func (m *MongoDB) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	repo, err := m.applications()
+	if err != nil {
+		return nil, err
+	}
+	return repo.Update(ctx, app)
+}
+
+// DeleteApplication deletes an application by ID
+func (m *MongoDB) DeleteApplication(ctx context.Context, id uint) error {
+	repo, err := m.applications()
+	if err != nil {
+		return err
+	}
+	return repo.Delete(ctx, id)
+}
+
+// uniqueColumns lists the unique constraints of every collection, mirroring
+// the unique indexes of the SQL schema
+var uniqueColumns = map[string][]string{
+	(&model.Application{}).TableName():            {"org_id,name"},
+	(&model.FeatureFlag{}).TableName():            {"key"},
+	(&model.ApplicationVariable{}).TableName():    {"app_id,key"},
+	(&model.ApplicationRevision{}).TableName():    {"app_id,revision"},
+	(&model.ApplicationBackup{}).TableName():      {"backup_id"},
+	(&model.File{}).TableName():                   {"storage_key"},
+	(&model.UploadSession{}).TableName():          {"file_id"},
+	(&model.Operation{}).TableName():              {"operation_id"},
+	(&model.NotificationPreference{}).TableName(): {"user_id,channel"},
+	(&model.PolicyDocument{}).TableName():         {"name,version,language"},
+	(&model.PolicyConsent{}).TableName():          {"user_id,policy,version"},
+	(&model.Partner{}).TableName():                {"name"},
+	(&model.OutboxMessage{}).TableName():          {"message_id"},
+	(&model.ProcessedMessage{}).TableName():       {"consumer_group,message_id"},
+	(&model.UserPreferences{}).TableName():        {"user_id"},
+	(&model.Organization{}).TableName():           {"name"},
+	(&model.OrganizationMember{}).TableName():     {"org_id,user_id"},
+	(&model.User{}).TableName():                   {"email"},
+}
+
+// legacyUniqueIndexes lists the unique indexes of earlier schema versions dropped by Migrate
+var legacyUniqueIndexes = map[string][]string{
+	// application names are unique per organization
+	(&model.Application{}).TableName(): {"name_1"},
+}
+
+// Migrate creates the indexes of every collection, leaving out the collections
+// of disabled modules and those routed to other datastores
+func (m *MongoDB) Migrate() error {
+	entities := module.Models([]model.Entity{
+		&model.Application{},
+		&model.FeatureFlag{},
+		&model.ApplicationVariable{},
+		&model.ApplicationRevision{},
+		&model.ApplicationBackup{},
+		&model.Operation{},
+		&model.NotificationPreference{},
+		&model.PolicyDocument{},
+		&model.PolicyConsent{},
+		&model.Partner{},
+		&model.OutboxMessage{},
+		&model.ProcessedMessage{},
+		&model.DatastoreMetric{},
+		&model.Session{},
+		&model.UserPreferences{},
+		&model.Organization{},
+		&model.OrganizationMember{},
+		&model.Invitation{},
+		&model.User{},
+		&model.DomainEvent{},
+		&model.File{},
+		&model.UploadSession{},
+		// gen:migrate-models
+	})
+	entities = datastore.RoutedModels(m.routes, m.name, entities)
+
+	ctx := context.Background()
+	for _, entity := range entities {
+		name := entity.TableName()
+		for _, index := range legacyUniqueIndexes[name] {
+			if err := m.Collection(name).DropUniqueIndex(ctx, index); err != nil {
+				return err
+			}
+		}
+		if err := m.Collection(name).EnsureIndexes(ctx, entity, uniqueColumns[name]...); err != nil {
+			return err
+		}
+	}
+	logger.Info("MongoDB datastore migration completed")
+	return nil
+}
+
+// Drop drops the database, removing every collection and index
+func (m *MongoDB) Drop(ctx context.Context) error {
+	return m.db.Drop(ctx)
+}
+
+// Close disconnects the client
+func (m *MongoDB) Close() error {
+	return m.client.Disconnect(context.Background())
+}
+
+// OnStop closes the datastore when the container stops
+func (m *MongoDB) OnStop(ctx context.Context) error {
+	return m.client.Disconnect(ctx)
+}
+
+// Stats returns the connection pool statistics
+func (m *MongoDB) Stats() *datastore.DatastoreStats {
+	open := int(m.pool.open.Load())
+	inUse := int(m.pool.inUse.Load())
+	return &datastore.DatastoreStats{
+		Driver: "mongodb",
+		Connections: &datastore.ConnectionStats{
+			MaxOpen:       m.maxPoolSize,
+			Open:          open,
+			InUse:         inUse,
+			Idle:          open - inUse,
+			MaxIdleClosed: m.pool.idleClosed.Load(),
+		},
+	}
+}
+
+// HealthCheck pings the primary
+func (m *MongoDB) HealthCheck() error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	return datastore.TranslateMongoError(m.client.Ping(ctx, readpref.Primary()))
+}
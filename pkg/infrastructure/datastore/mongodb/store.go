@@ -0,0 +1,216 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// store implements the generic DataStore on top of a MongoDB datastore. Entities
+// must implement model.Entity, the IDs are used as document keys.
+type store struct {
+	mongo *MongoDB
+}
+
+// DataStore returns the generic DataStore backed by m. Its indexes are
+// derived from Entity.Index, see MongoCollection.EnsureIndexes.
+func (m *MongoDB) DataStore() datastore.DataStore {
+	return &store{mongo: m}
+}
+
+// Connect checks that the primary is reachable, the client connects in New
+func (s *store) Connect(ctx context.Context) error {
+	if err := s.mongo.client.Ping(ctx, readpref.Primary()); err != nil {
+		return fmt.Errorf("%w: %w", datastore.ErrConnectionFailed, err)
+	}
+	return nil
+}
+
+// Disconnect disconnects the client
+func (s *store) Disconnect(ctx context.Context) error {
+	return s.mongo.client.Disconnect(ctx)
+}
+
+// HealthCheck pings the primary
+func (s *store) HealthCheck(ctx context.Context) error {
+	return datastore.TranslateMongoError(s.mongo.client.Ping(ctx, readpref.Primary()))
+}
+
+// BeginTx starts a multi-document transaction
+func (s *store) BeginTx(ctx context.Context) (datastore.Transaction, error) {
+	if !s.mongo.transactions {
+		return nil, fmt.Errorf("%w: MongoDB transactions require a replica set or sharded cluster", datastore.ErrTransactionFailed)
+	}
+
+	session, err := s.mongo.client.StartSession()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", datastore.ErrTransactionFailed, err)
+	}
+	if err := session.StartTransaction(); err != nil {
+		session.EndSession(ctx)
+		return nil, fmt.Errorf("%w: %v", datastore.ErrTransactionFailed, err)
+	}
+
+	bound := *s.mongo
+	bound.session = session
+	return &transaction{store: store{mongo: &bound}, session: session, ctx: ctx}, nil
+}
+
+// Add stores a new entity and assigns its ID and timestamps
+func (s *store) Add(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+	return s.mongo.Collection(e.TableName()).Insert(ctx, e)
+}
+
+// BatchAdd stores the entities one by one, stopping at the first error
+func (s *store) BatchAdd(ctx context.Context, entities []datastore.Entity) error {
+	for _, entity := range entities {
+		if err := s.Add(ctx, entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put replaces the entity with the same ID, keeping its creation time
+func (s *store) Put(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+	return s.mongo.Collection(e.TableName()).Replace(ctx, e)
+}
+
+// Delete removes the entity with the same ID
+func (s *store) Delete(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+	return s.mongo.Collection(e.TableName()).Delete(ctx, e.GetID())
+}
+
+// Get loads the entity with the ID of entity into it
+func (s *store) Get(ctx context.Context, entity datastore.Entity) error {
+	e, err := asEntity(entity)
+	if err != nil {
+		return err
+	}
+	return s.mongo.Collection(e.TableName()).FindByID(ctx, e.GetID(), e)
+}
+
+// List returns the entities of the type of query matching options
+func (s *store) List(ctx context.Context, query datastore.Entity, options *datastore.ListOptions) ([]datastore.Entity, error) {
+	e, err := asEntity(query)
+	if err != nil {
+		return nil, err
+	}
+	if options == nil {
+		options = &datastore.ListOptions{}
+	}
+	if _, ok := e.(model.Tagged); !ok && len(options.Tags) > 0 {
+		return nil, fmt.Errorf("%w: %s does not support tags", datastore.ErrInvalidInput, e.TableName())
+	}
+
+	results := reflect.New(reflect.SliceOf(reflect.TypeOf(e)))
+	if err := s.mongo.Collection(e.TableName()).Find(ctx, *options, results.Interface()); err != nil {
+		return nil, err
+	}
+
+	entities := make([]datastore.Entity, results.Elem().Len())
+	for i := range entities {
+		entities[i] = results.Elem().Index(i).Interface().(datastore.Entity)
+	}
+	return entities, nil
+}
+
+// Count returns the number of entities of the type of entity matching options
+func (s *store) Count(ctx context.Context, entity datastore.Entity, options *datastore.FilterOptions) (int64, error) {
+	e, err := asEntity(entity)
+	if err != nil {
+		return 0, err
+	}
+
+	var opts datastore.ListOptions
+	if options != nil {
+		opts.Filters = options.Filters
+	}
+	return s.mongo.Collection(e.TableName()).Count(ctx, opts)
+}
+
+// IsExist reports whether an entity with the ID of entity exists
+func (s *store) IsExist(ctx context.Context, entity datastore.Entity) (bool, error) {
+	e, err := asEntity(entity)
+	if err != nil {
+		return false, err
+	}
+
+	total, err := s.mongo.Collection(e.TableName()).Count(ctx, datastore.ListOptions{
+		Filters: map[string]interface{}{"id": e.GetID()},
+	})
+	return total > 0, err
+}
+
+// Migrate creates the indexes of the given entities
+func (s *store) Migrate(ctx context.Context, entities ...datastore.Entity) error {
+	for _, entity := range entities {
+		e, err := asEntity(entity)
+		if err != nil {
+			return err
+		}
+		name := e.TableName()
+		if err := s.mongo.Collection(name).EnsureIndexes(ctx, e, uniqueColumns[name]...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExecuteSQL is not supported by MongoDB
+func (s *store) ExecuteSQL(ctx context.Context, sql string, args ...interface{}) error {
+	return fmt.Errorf("%w: MongoDB does not execute SQL", datastore.ErrInvalidInput)
+}
+
+// asEntity returns entity as a domain entity, which carries the document ID
+func asEntity(entity datastore.Entity) (model.Entity, error) {
+	e, ok := entity.(model.Entity)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T does not implement model.Entity", datastore.ErrInvalidInput, entity)
+	}
+	return e, nil
+}
+
+// transaction implements Transaction with a MongoDB session
+type transaction struct {
+	store
+	session mongo.Session
+	ctx     context.Context
+}
+
+// Commit commits the transaction and ends its session
+func (t *transaction) Commit() error {
+	defer t.session.EndSession(t.ctx)
+
+	if err := t.session.CommitTransaction(t.ctx); err != nil {
+		return fmt.Errorf("%w: %w", datastore.ErrTransactionFailed, datastore.TranslateMongoError(err))
+	}
+	return nil
+}
+
+// Rollback aborts the transaction and ends its session
+func (t *transaction) Rollback() error {
+	defer t.session.EndSession(t.ctx)
+
+	if err := t.session.AbortTransaction(t.ctx); err != nil {
+		return fmt.Errorf("%w: %v", datastore.ErrTransactionFailed, err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package mongodb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// Transaction runs fn with a datastore bound to a single multi-document
+// transaction. Transactions require a replica set or a sharded cluster; a
+// transaction already bound to the datastore is joined. Transient errors are
+// returned to the caller as ErrSerializationFailure rather than retried, as
+// with the SQL datastores.
+func (m *MongoDB) Transaction(ctx context.Context, fn func(tx datastore.DatastoreInterface) error) error {
+	if m.session != nil {
+		return fn(m)
+	}
+	if !m.transactions {
+		return fmt.Errorf("%w: MongoDB transactions require a replica set or sharded cluster", datastore.ErrTransactionFailed)
+	}
+
+	session, err := m.client.StartSession()
+	if err != nil {
+		return fmt.Errorf("%w: %v", datastore.ErrTransactionFailed, err)
+	}
+	defer session.EndSession(context.Background())
+
+	if err := session.StartTransaction(); err != nil {
+		return fmt.Errorf("%w: %v", datastore.ErrTransactionFailed, err)
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			_ = session.AbortTransaction(context.Background())
+			panic(r)
+		}
+	}()
+
+	tx := *m
+	tx.session = session
+	if err := fn(&tx); err != nil {
+		_ = session.AbortTransaction(context.Background())
+		return err
+	}
+	if err := session.CommitTransaction(ctx); err != nil {
+		return fmt.Errorf("%w: %w", datastore.ErrTransactionFailed, datastore.TranslateMongoError(err))
+	}
+	return nil
+}
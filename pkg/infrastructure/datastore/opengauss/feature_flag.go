@@ -0,0 +1,61 @@
+package opengauss
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// CreateFeatureFlag creates a new feature flag
+func (o *OpenGauss) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	if err := o.db.WithContext(ctx).Create(flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flag, nil
+}
+
+// GetFeatureFlagByKey retrieves a feature flag by key
+func (o *OpenGauss) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	var flag model.FeatureFlag
+	if err := o.db.WithContext(ctx).Where("key = ?", key).First(&flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return &flag, nil
+}
+
+// ListFeatureFlags retrieves all feature flags ordered by key
+func (o *OpenGauss) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	var flags []*model.FeatureFlag
+	if err := o.db.WithContext(ctx).Order("key").Find(&flags).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flags, nil
+}
+
+// UpdateFeatureFlag updates the existing feature flag with the same key
+func (o *OpenGauss) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	existing, err := o.GetFeatureFlagByKey(ctx, flag.Key)
+	if err != nil {
+		return nil, err
+	}
+	flag.ID = existing.ID
+	model.KeepPublicID(flag, existing.PublicID)
+	flag.CreatedAt = existing.CreatedAt
+	if err := o.db.WithContext(ctx).Save(flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flag, nil
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (o *OpenGauss) DeleteFeatureFlag(ctx context.Context, key string) error {
+	result := o.db.WithContext(ctx).Where("key = ?", key).Delete(&model.FeatureFlag{})
+	if result.Error != nil {
+		return datastore.TranslateGormError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return datastore.ErrNotFound
+	}
+	return nil
+}
@@ -6,43 +6,101 @@ import (
 
 	"github.com/make-bin/server-tpl/pkg/domain/model"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/config"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
 // OpenGauss implements DatastoreInterface using OpenGauss
 type OpenGauss struct {
-	db *gorm.DB
+	db    *gorm.DB
+	retry datastore.TxRetryPolicy
+	// transactionPooling is set behind a transaction pooling proxy such as PgBouncer
+	transactionPooling bool
+	// name and routes select the tables of the datastore among the models
+	name   string
+	routes datastore.Routes
+	// tenancy isolates every organization in a schema of its own, nil with row tenancy
+	tenancy *datastore.TenantSchemas
+	// rls sets the session variables of row-level security policies, nil when disabled
+	rls *datastore.RowLevelSecurity
+	// tags stores the tags of tagged entities in join tables, nil with JSONB columns only
+	tags *datastore.TagTables
 }
 
-// New creates a new OpenGauss datastore instance
-func New(cfg *config.Config) (datastore.DatastoreInterface, error) {
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s TimeZone=%s",
+// New creates a new OpenGauss datastore instance whose timestamps are read from clk
+func New(cfg *config.Config, clk clock.Clock) (datastore.DatastoreInterface, error) {
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
 		cfg.Database.Host,
 		cfg.Database.User,
 		cfg.Database.Password,
 		cfg.Database.Database,
 		cfg.Database.Port,
 		cfg.Database.SSLMode,
-		"UTC", // Default timezone
 	)
+	pooling := cfg.Database.TransactionPooling
+	if !pooling {
+		// Default timezone, a session setting a transaction pooling proxy does
+		// not keep; timestamps carry their offset either way
+		dsn += " TimeZone=UTC"
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	// The simple protocol prepares no statements, which a transaction pooling
+	// proxy cannot keep on the server connections of a client
+	dialector := postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: pooling})
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true, NowFunc: clk.Now})
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to OpenGauss: %w", err)
+		return nil, fmt.Errorf("failed to connect to OpenGauss: %w: %w", datastore.ErrConnectionFailed, err)
+	}
+
+	var tenancy *datastore.TenantSchemas
+	if cfg.Database.Tenancy.Mode == "schema" {
+		tenancy = datastore.NewTenantSchemas(cfg.Database.Tenancy.SchemaPrefix, cfg.Database.Tenancy.Tables)
+		if err := db.Use(tenancy); err != nil {
+			return nil, fmt.Errorf("failed to install tenant schemas: %w", err)
+		}
+	}
+	var rls *datastore.RowLevelSecurity
+	if cfg.Database.RLS.Enabled {
+		rls = datastore.NewRowLevelSecurity(cfg.Database.RLS.Tables, cfg.Database.RLS.AllowUnscoped)
+		if err := db.Use(rls); err != nil {
+			return nil, fmt.Errorf("failed to install row-level security: %w", err)
+		}
+	}
+	var tags *datastore.TagTables
+	if cfg.Database.Tags.Storage == "join_table" {
+		tags = datastore.NewTagTables(datastore.RoutedModels(cfg.Database.Routes, cfg.Database.DatastoreName(), models()))
+		if err := db.Use(tags); err != nil {
+			return nil, fmt.Errorf("failed to install tag tables: %w", err)
+		}
 	}
 
 	logger.Info("Connected to OpenGauss database")
 
-	return &OpenGauss{db: db}, nil
+	retry := datastore.TxRetryPolicy{
+		MaxAttempts:    cfg.Database.TxRetry.MaxAttempts,
+		InitialBackoff: cfg.Database.TxRetry.InitialBackoff,
+		MaxBackoff:     cfg.Database.TxRetry.MaxBackoff,
+	}
+	return &OpenGauss{
+		db:                 db,
+		retry:              retry,
+		transactionPooling: pooling,
+		name:               cfg.Database.DatastoreName(),
+		routes:             cfg.Database.Routes,
+		tenancy:            tenancy,
+		rls:                rls,
+		tags:               tags,
+	}, nil
 }
 
 // CreateApplication creates a new application
 func (o *OpenGauss) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
 	if err := o.db.WithContext(ctx).Create(app).Error; err != nil {
-		return nil, err
+		return nil, datastore.TranslateGormError(err)
 	}
 	return app, nil
 }
@@ -51,10 +109,7 @@ func (o *OpenGauss) CreateApplication(ctx context.Context, app *model.Applicatio
 func (o *OpenGauss) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
 	var app model.Application
 	if err := o.db.WithContext(ctx).First(&app, id).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, datastore.ErrNotFound
-		}
-		return nil, err
+		return nil, datastore.TranslateGormError(err)
 	}
 	return &app, nil
 }
@@ -63,10 +118,7 @@ func (o *OpenGauss) GetApplicationByID(ctx context.Context, id uint) (*model.App
 func (o *OpenGauss) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
 	var app model.Application
 	if err := o.db.WithContext(ctx).Where("name = ?", name).First(&app).Error; err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return nil, datastore.ErrNotFound
-		}
-		return nil, err
+		return nil, datastore.TranslateGormError(err)
 	}
 	return &app, nil
 }
@@ -78,22 +130,26 @@ func (o *OpenGauss) ListApplications(ctx context.Context, page, pageSize int) ([
 
 	// Count total records
 	if err := o.db.WithContext(ctx).Model(&model.Application{}).Count(&total).Error; err != nil {
-		return nil, 0, err
+		return nil, 0, datastore.TranslateGormError(err)
 	}
 
 	// Get paginated records
 	offset := (page - 1) * pageSize
-	if err := o.db.WithContext(ctx).Offset(offset).Limit(pageSize).Find(&apps).Error; err != nil {
-		return nil, 0, err
+	if err := o.db.WithContext(ctx).Order("id").Offset(offset).Limit(pageSize).Find(&apps).Error; err != nil {
+		return nil, 0, datastore.TranslateGormError(err)
 	}
 
 	return apps, total, nil
 }
 
-// UpdateApplication updates an existing application
+// UpdateApplication updates an existing application, keeping its creation time and public ID
 func (o *OpenGauss) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
-	if err := o.db.WithContext(ctx).Save(app).Error; err != nil {
-		return nil, err
+	result := o.db.WithContext(ctx).Model(app).Select("*").Omit("created_at", "public_id").Updates(app)
+	if result.Error != nil {
+		return nil, datastore.TranslateGormError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, datastore.ErrNotFound
 	}
 	return app, nil
 }
@@ -102,7 +158,7 @@ func (o *OpenGauss) UpdateApplication(ctx context.Context, app *model.Applicatio
 func (o *OpenGauss) DeleteApplication(ctx context.Context, id uint) error {
 	result := o.db.WithContext(ctx).Delete(&model.Application{}, id)
 	if result.Error != nil {
-		return result.Error
+		return datastore.TranslateGormError(result.Error)
 	}
 	if result.RowsAffected == 0 {
 		return datastore.ErrNotFound
@@ -110,9 +166,129 @@ func (o *OpenGauss) DeleteApplication(ctx context.Context, id uint) error {
 	return nil
 }
 
-// Migrate runs database migrations
+// DB returns the GORM connection backing generic repositories
+func (o *OpenGauss) DB() *gorm.DB {
+	return o.db
+}
+
+// Transaction runs fn with a datastore bound to a single database transaction.
+// Transactions failing with a serialization failure or deadlock are retried
+// as configured under database.tx_retry, so fn may run more than once. With a
+// schema per tenant, the search path of the transaction starts with the
+// schema of the organization of ctx. With row-level security, the session
+// variables of the policies are set from ctx.
+func (o *OpenGauss) Transaction(ctx context.Context, fn func(tx datastore.DatastoreInterface) error) error {
+	return datastore.GormTransaction(ctx, o.db, o.retry, "opengauss", func(tx *gorm.DB) error {
+		if o.tenancy != nil {
+			if err := o.tenancy.SetSearchPath(ctx, tx); err != nil {
+				return err
+			}
+		}
+		if o.rls != nil {
+			if err := o.rls.SetVariables(ctx, tx); err != nil {
+				return err
+			}
+		}
+		bound := *o
+		bound.db = tx
+		return fn(&bound)
+	})
+}
+
+// Migrate runs database migrations, creating the tag tables and row-level
+// security policies when enabled
 func (o *OpenGauss) Migrate() error {
-	return o.db.AutoMigrate(&model.Application{})
+	models := o.models()
+	if err := o.db.AutoMigrate(models...); err != nil {
+		return err
+	}
+	if err := datastore.BackfillGormPublicIDs(o.db, models...); err != nil {
+		return err
+	}
+	if err := datastore.DropGormIndexes(o.db, o.legacyIndexes()...); err != nil {
+		return err
+	}
+	if o.tags != nil {
+		if err := o.tags.Migrate(o.db, models...); err != nil {
+			return err
+		}
+	}
+	if o.rls != nil {
+		return datastore.CreateGormRLSPolicies(o.db, o.rls.Policies(models)...)
+	}
+	return nil
+}
+
+// CheckSchema implements datastore.SchemaChecker
+func (o *OpenGauss) CheckSchema() error {
+	return datastore.CheckGormSchema(o.db, o.models()...)
+}
+
+// models returns the models of the tables held by the datastore
+func (o *OpenGauss) models() []interface{} {
+	return datastore.RoutedModels(o.routes, o.name, models())
+}
+
+// legacyIndexes returns the legacy indexes of the tables held by the datastore
+func (o *OpenGauss) legacyIndexes() []datastore.LegacyIndex {
+	var indexes []datastore.LegacyIndex
+	for _, index := range legacyIndexes() {
+		if len(datastore.RoutedModels(o.routes, o.name, []interface{}{index.Model})) > 0 {
+			indexes = append(indexes, index)
+		}
+	}
+	return indexes
+}
+
+// ProvisionTenant implements datastore.TenantProvisioner, provisioning the
+// schema of tenant when every organization has a schema of its own
+func (o *OpenGauss) ProvisionTenant(ctx context.Context, tenant uint) error {
+	if o.tenancy == nil {
+		return nil
+	}
+	return o.tenancy.Provision(ctx, o.db, tenant, o.models()...)
+}
+
+// CheckPooling implements datastore.PoolingChecker
+func (o *OpenGauss) CheckPooling(ctx context.Context) error {
+	return datastore.CheckGormPooling(ctx, o.db, o.transactionPooling)
+}
+
+// models returns the models migrated by Migrate, leaving out the models of disabled modules
+func models() []interface{} {
+	return module.Models([]interface{}{
+		&model.Application{},
+		&model.FeatureFlag{},
+		&model.ApplicationVariable{},
+		&model.ApplicationRevision{},
+		&model.ApplicationBackup{},
+		&model.Operation{},
+		&model.NotificationPreference{},
+		&model.PolicyDocument{},
+		&model.PolicyConsent{},
+		&model.Partner{},
+		&model.OutboxMessage{},
+		&model.ProcessedMessage{},
+		&model.DatastoreMetric{},
+		&model.Session{},
+		&model.UserPreferences{},
+		&model.Organization{},
+		&model.OrganizationMember{},
+		&model.Invitation{},
+		&model.User{},
+		&model.DomainEvent{},
+		&model.File{},
+		&model.UploadSession{},
+		// gen:migrate-models
+	})
+}
+
+// legacyIndexes returns the indexes of earlier schema versions dropped by Migrate
+func legacyIndexes() []datastore.LegacyIndex {
+	return []datastore.LegacyIndex{
+		// application names are unique per organization, see idx_applications_org_name
+		{Model: &model.Application{}, Name: "idx_applications_name"},
+	}
 }
 
 // Close closes the database connection
@@ -124,11 +300,24 @@ func (o *OpenGauss) Close() error {
 	return sqlDB.Close()
 }
 
+// OnStop closes the datastore when the container stops
+func (o *OpenGauss) OnStop(ctx context.Context) error {
+	return o.Close()
+}
+
+// Stats returns the connection pool statistics
+func (o *OpenGauss) Stats() *datastore.DatastoreStats {
+	return datastore.GormStats("opengauss", o.db)
+}
+
 // HealthCheck checks the database connection
 func (o *OpenGauss) HealthCheck() error {
 	sqlDB, err := o.db.DB()
 	if err != nil {
 		return err
 	}
-	return sqlDB.Ping()
+	if err := sqlDB.Ping(); err != nil {
+		return fmt.Errorf("%w: %w", datastore.ErrConnectionFailed, err)
+	}
+	return nil
 }
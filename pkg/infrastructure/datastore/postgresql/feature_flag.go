@@ -0,0 +1,61 @@
+package postgresql
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+// CreateFeatureFlag creates a new feature flag
+func (p *PostgreSQL) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	if err := p.db.WithContext(ctx).Create(flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flag, nil
+}
+
+// GetFeatureFlagByKey retrieves a feature flag by key
+func (p *PostgreSQL) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	var flag model.FeatureFlag
+	if err := p.db.WithContext(ctx).Where("key = ?", key).First(&flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return &flag, nil
+}
+
+// ListFeatureFlags retrieves all feature flags ordered by key
+func (p *PostgreSQL) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	var flags []*model.FeatureFlag
+	if err := p.db.WithContext(ctx).Order("key").Find(&flags).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flags, nil
+}
+
+// UpdateFeatureFlag updates the existing feature flag with the same key
+func (p *PostgreSQL) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	existing, err := p.GetFeatureFlagByKey(ctx, flag.Key)
+	if err != nil {
+		return nil, err
+	}
+	flag.ID = existing.ID
+	model.KeepPublicID(flag, existing.PublicID)
+	flag.CreatedAt = existing.CreatedAt
+	if err := p.db.WithContext(ctx).Save(flag).Error; err != nil {
+		return nil, datastore.TranslateGormError(err)
+	}
+	return flag, nil
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (p *PostgreSQL) DeleteFeatureFlag(ctx context.Context, key string) error {
+	result := p.db.WithContext(ctx).Where("key = ?", key).Delete(&model.FeatureFlag{})
+	if result.Error != nil {
+		return datastore.TranslateGormError(result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return datastore.ErrNotFound
+	}
+	return nil
+}
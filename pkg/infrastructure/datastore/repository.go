@@ -0,0 +1,214 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"gorm.io/gorm"
+)
+
+// Repository provides typed CRUD access to a single entity type.
+// T is a pointer to a model embedding BaseModel, e.g. *model.Application.
+type Repository[T model.Entity] interface {
+	// Get retrieves an entity by ID, returning ErrNotFound when it does not exist
+	Get(ctx context.Context, id uint) (T, error)
+	// List retrieves entities matching the options. A zero Size disables pagination.
+	List(ctx context.Context, opts ListOptions) ([]T, error)
+	// Create stores a new entity and assigns its ID and timestamps
+	Create(ctx context.Context, entity T) (T, error)
+	// Update replaces an existing entity, returning ErrNotFound when it does not exist
+	Update(ctx context.Context, entity T) (T, error)
+	// UpdateMatching replaces an existing entity whose stored version also
+	// matches filters, as in ListOptions, returning ErrNotFound otherwise. It
+	// is the optimistic check that the entity did not change since it was read.
+	UpdateMatching(ctx context.Context, entity T, filters map[string]interface{}) (T, error)
+	// Delete removes an entity by ID, returning ErrNotFound when it does not exist
+	Delete(ctx context.Context, id uint) error
+	// DeleteMatching removes every entity matching the filters of opts in a
+	// single statement and returns how many were removed
+	DeleteMatching(ctx context.Context, opts DeleteOptions) (int64, error)
+	// Count returns the number of entities matching the filters in opts
+	Count(ctx context.Context, opts ListOptions) (int64, error)
+}
+
+// GormProvider is implemented by datastores backed by GORM
+type GormProvider interface {
+	DB() *gorm.DB
+}
+
+// MemoryTableProvider is implemented by in-memory datastores
+type MemoryTableProvider interface {
+	Table(name string) *MemoryTable
+}
+
+// NewRepository returns the repository for T backed by the given datastore,
+// or by the datastore holding the table of T when store is a Router
+func NewRepository[T model.Entity](store DatastoreInterface) (Repository[T], error) {
+	if err := checkEntityType[T](); err != nil {
+		return nil, err
+	}
+
+	store = Route(store, newEntity[T]().TableName())
+	switch s := store.(type) {
+	case GormProvider:
+		return NewGormRepository[T](s.DB())
+	case MemoryTableProvider:
+		return NewMemoryRepository[T](s.Table(newEntity[T]().TableName()))
+	case MongoCollectionProvider:
+		return NewMongoRepository[T](s.Collection(newEntity[T]().TableName()))
+	default:
+		return nil, fmt.Errorf("datastore %T does not support generic repositories", store)
+	}
+}
+
+// FindByPublicID retrieves the entity of repo with a public ID, returning
+// ErrNotFound when none has it
+func FindByPublicID[T model.Entity](ctx context.Context, repo Repository[T], publicID string) (T, error) {
+	var zero T
+	entities, err := repo.List(ctx, ListOptions{Size: 1, Filters: map[string]interface{}{"public_id": publicID}})
+	if err != nil {
+		return zero, err
+	}
+	if len(entities) == 0 {
+		return zero, ErrNotFound
+	}
+	return entities[0], nil
+}
+
+// DeleteOptions selects the entities removed by DeleteMatching
+type DeleteOptions struct {
+	// Filters as in ListOptions, they must not be empty so that a missing
+	// filter cannot remove every entity
+	Filters map[string]interface{}
+	// Permanent removes the rows of GORM models with a DeletedAt column,
+	// which are otherwise soft deleted. Other datastores always remove entities.
+	Permanent bool
+}
+
+// validate checks that filters are present and name plain identifiers
+func (o DeleteOptions) validate() error {
+	if len(o.Filters) == 0 {
+		return fmt.Errorf("%w: deleting requires filters", ErrInvalidInput)
+	}
+	return ListOptions{Filters: o.Filters}.validate()
+}
+
+var columnPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// validate checks that sort and filter columns are plain identifiers
+func (o ListOptions) validate() error {
+	if o.SortBy != "" && !columnPattern.MatchString(o.SortBy) {
+		return fmt.Errorf("%w: invalid sort column %q", ErrInvalidInput, o.SortBy)
+	}
+	for column := range o.Filters {
+		if !columnPattern.MatchString(column) {
+			return fmt.Errorf("%w: invalid filter column %q", ErrInvalidInput, column)
+		}
+	}
+	for _, order := range o.Sort {
+		if !columnPattern.MatchString(order.Column) {
+			return fmt.Errorf("%w: invalid sort column %q", ErrInvalidInput, order.Column)
+		}
+	}
+	for column := range o.Ranges {
+		if !columnPattern.MatchString(column) {
+			return fmt.Errorf("%w: invalid range column %q", ErrInvalidInput, column)
+		}
+	}
+	if o.Page < 0 || o.Size < 0 {
+		return fmt.Errorf("%w: page and size must not be negative", ErrInvalidInput)
+	}
+	return nil
+}
+
+// checkTagged ensures T supports tag selectors when opts selects by tags
+func checkTagged[T model.Entity](opts ListOptions) error {
+	if len(opts.Tags) == 0 {
+		return nil
+	}
+	entity := newEntity[T]()
+	if _, ok := any(entity).(model.Tagged); !ok {
+		return fmt.Errorf("%w: %s does not support tags", ErrInvalidInput, entity.TableName())
+	}
+	return nil
+}
+
+// offset returns the number of rows to skip for the requested page
+func (o ListOptions) offset() int {
+	if o.Page <= 1 {
+		return 0
+	}
+	return (o.Page - 1) * o.Size
+}
+
+// Orders returns the columns results are sorted by, SortBy followed by Sort
+func (o ListOptions) Orders() []SortOrder {
+	var orders []SortOrder
+	if o.SortBy != "" {
+		orders = append(orders, SortOrder{Column: o.SortBy, Desc: o.SortDesc})
+	}
+	return append(orders, o.Sort...)
+}
+
+// Matches reports whether entity matches the filters, ranges and tag selector,
+// comparing them with the values of entity.Index(). It lets datastores without a
+// query language, such as the in-memory and key-value ones, filter loaded entities.
+func (o ListOptions) Matches(entity model.Entity) bool {
+	index := entity.Index()
+	for column, value := range o.Filters {
+		if !valuesMatch(index[column], value) {
+			return false
+		}
+	}
+	for column, bounds := range o.Ranges {
+		value, ok := index[column]
+		if !ok || value == nil {
+			return false
+		}
+		if bounds.From != nil && compareValues(value, bounds.From) < 0 {
+			return false
+		}
+		if bounds.To != nil && compareValues(value, bounds.To) > 0 {
+			return false
+		}
+	}
+	if len(o.Tags) > 0 {
+		tagged, ok := entity.(model.Tagged)
+		return ok && o.Tags.Matches(tagged.GetTags())
+	}
+	return true
+}
+
+// Less reports whether a sorts before b, by the Orders() columns of their
+// Index() and then by ID
+func (o ListOptions) Less(a, b model.Entity) bool {
+	orders := o.Orders()
+	if len(orders) == 0 {
+		return a.GetID() < b.GetID()
+	}
+	ai, bi := a.Index(), b.Index()
+	for _, order := range orders {
+		if cmp := compareValues(ai[order.Column], bi[order.Column]); cmp != 0 {
+			return (cmp < 0) != order.Desc
+		}
+	}
+	return a.GetID() < b.GetID()
+}
+
+// checkEntityType ensures T is a pointer to a struct so new values can be allocated
+func checkEntityType[T model.Entity]() error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("repository entity type %s must be a pointer to a struct", typ)
+	}
+	return nil
+}
+
+// newEntity allocates a new zero value of the struct T points to
+func newEntity[T model.Entity]() T {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	return reflect.New(typ.Elem()).Interface().(T)
+}
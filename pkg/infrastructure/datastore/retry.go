@@ -0,0 +1,103 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"gorm.io/gorm"
+)
+
+var (
+	// Transaction retry counter
+	txRetriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "datastore_transaction_retries_total",
+			Help: "Total number of transactions retried after a serialization failure or deadlock",
+		},
+		[]string{"driver"},
+	)
+
+	// Exhausted transaction retry counter
+	txRetriesExhaustedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "datastore_transaction_retries_exhausted_total",
+			Help: "Total number of transactions failing with a serialization failure or deadlock after the last attempt",
+		},
+		[]string{"driver"},
+	)
+)
+
+// TxRetryPolicy bounds the retries of transactions failing with
+// ErrSerializationFailure. The zero value runs transactions once.
+type TxRetryPolicy struct {
+	// MaxAttempts is the number of times a transaction runs, 1 or less disables retries
+	MaxAttempts int
+	// InitialBackoff is the ceiling of the delay before the first retry,
+	// doubled after every attempt up to MaxBackoff; delays are fully jittered
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// backoff returns the delay before the attempt following attempt
+func (p TxRetryPolicy) backoff(attempt int) time.Duration {
+	ceiling := p.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || (p.MaxBackoff > 0 && ceiling > p.MaxBackoff) {
+		ceiling = p.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// RetryTransaction runs transaction until it succeeds, fails with an error
+// other than ErrSerializationFailure, the attempts of policy are exhausted or
+// ctx is done. transaction must run a whole transaction, as a serialization
+// failure aborts it; its effects outside the datastore may be repeated.
+// Retries are counted per driver.
+func RetryTransaction(ctx context.Context, policy TxRetryPolicy, driver string, transaction func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := transaction()
+		if err == nil || !errors.Is(err, ErrSerializationFailure) {
+			return err
+		}
+		if attempt >= policy.MaxAttempts {
+			if policy.MaxAttempts > 1 {
+				txRetriesExhaustedTotal.WithLabelValues(driver).Inc()
+				logger.Warn("Transaction on %s failed after %d attempts: %v", driver, attempt, err)
+			}
+			return err
+		}
+
+		wait := policy.backoff(attempt)
+		logger.Debug("Retrying transaction on %s in %s (attempt %d/%d): %v", driver, wait, attempt+1, policy.MaxAttempts, err)
+		txRetriesTotal.WithLabelValues(driver).Inc()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// GormTransaction runs fn in a transaction of db, retrying it with policy on
+// serialization failures and deadlocks. Within a transaction of db, fn runs
+// once in a savepoint and the outermost transaction is retried instead.
+// Errors are translated with TranslateGormError.
+func GormTransaction(ctx context.Context, db *gorm.DB, policy TxRetryPolicy, driver string, fn func(tx *gorm.DB) error) error {
+	transaction := func() error {
+		return TranslateGormError(db.WithContext(ctx).Transaction(fn))
+	}
+	if committer, ok := db.Statement.ConnPool.(gorm.TxCommitter); ok && committer != nil {
+		return transaction()
+	}
+	return RetryTransaction(ctx, policy, driver, transaction)
+}
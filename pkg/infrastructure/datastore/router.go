@@ -0,0 +1,296 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+)
+
+// PrimaryDatastore names the datastore holding the tables without a route
+const PrimaryDatastore = "primary"
+
+// Routes map table names to the name of the datastore holding them
+type Routes map[string]string
+
+// Datastore returns the name of the datastore holding table, PrimaryDatastore
+// for tables without a route
+func (r Routes) Datastore(table string) string {
+	if name, ok := r[table]; ok {
+		return name
+	}
+	return PrimaryDatastore
+}
+
+// RoutedModels returns the models held by the datastore name, e.g. for
+// migrating the tables of one datastore. Models without a TableName method are
+// held by every datastore.
+func RoutedModels[T any](routes Routes, name string, models []T) []T {
+	routed := make([]T, 0, len(models))
+	for _, m := range models {
+		tabler, ok := any(m).(interface{ TableName() string })
+		if !ok || routes.Datastore(tabler.TableName()) == name {
+			routed = append(routed, m)
+		}
+	}
+	return routed
+}
+
+// NamedDatastore is a datastore with the name it is configured with
+type NamedDatastore struct {
+	Name  string
+	Store DatastoreInterface
+}
+
+// Router spreads the tables over several datastores: every table is held by
+// the datastore its route names, the tables without a route by the primary
+// datastore. NewRepository returns the repositories of a Router backed by the
+// datastore of their table, so services use a Router like a single datastore.
+//
+// Transactions run in the primary datastore. The repositories of tables held
+// by another datastore bypass the transaction, a unit of work spanning them
+// is not atomic.
+type Router struct {
+	stores []NamedDatastore // the primary datastore first, then by name
+	byName map[string]DatastoreInterface
+	routes Routes
+}
+
+// NewRouter creates a router over the datastores by name, which include
+// PrimaryDatastore. Every route must name one of the datastores.
+func NewRouter(stores map[string]DatastoreInterface, routes Routes) (*Router, error) {
+	if stores[PrimaryDatastore] == nil {
+		return nil, fmt.Errorf("%w: no %s datastore", ErrInvalidInput, PrimaryDatastore)
+	}
+	for table, name := range routes {
+		if stores[name] == nil {
+			return nil, fmt.Errorf("%w: table %s is routed to unknown datastore %s", ErrInvalidInput, table, name)
+		}
+	}
+
+	r := &Router{byName: make(map[string]DatastoreInterface, len(stores)), routes: routes}
+	for name, store := range stores {
+		r.byName[name] = store
+		r.stores = append(r.stores, NamedDatastore{Name: name, Store: store})
+	}
+	sort.Slice(r.stores, func(i, j int) bool {
+		if r.stores[i].Name == PrimaryDatastore || r.stores[j].Name == PrimaryDatastore {
+			return r.stores[i].Name == PrimaryDatastore
+		}
+		return r.stores[i].Name < r.stores[j].Name
+	})
+	return r, nil
+}
+
+// Route returns the datastore holding table
+func (r *Router) Route(table string) DatastoreInterface {
+	return r.byName[r.routes.Datastore(table)]
+}
+
+// Primary returns the primary datastore
+func (r *Router) Primary() DatastoreInterface {
+	return r.byName[PrimaryDatastore]
+}
+
+// Datastores returns the datastores of the router, the primary datastore first
+func (r *Router) Datastores() []NamedDatastore {
+	return append([]NamedDatastore(nil), r.stores...)
+}
+
+// Route returns the datastore of store holding table: the routed datastore of
+// a Router, store itself otherwise
+func Route(store DatastoreInterface, table string) DatastoreInterface {
+	if router, ok := store.(*Router); ok {
+		return router.Route(table)
+	}
+	return store
+}
+
+// Datastores returns the datastores of store by name: those of a Router, store
+// itself as the primary datastore otherwise
+func Datastores(store DatastoreInterface) []NamedDatastore {
+	if router, ok := store.(*Router); ok {
+		return router.Datastores()
+	}
+	return []NamedDatastore{{Name: PrimaryDatastore, Store: store}}
+}
+
+// ProvisionTenant provisions tenant in every datastore of store implementing
+// TenantProvisioner, see Datastores
+func ProvisionTenant(ctx context.Context, store DatastoreInterface, tenant uint) error {
+	for _, named := range Datastores(store) {
+		provisioner, ok := named.Store.(TenantProvisioner)
+		if !ok {
+			continue
+		}
+		if err := provisioner.ProvisionTenant(ctx, tenant); err != nil {
+			return fmt.Errorf("datastore %s: %w", named.Name, err)
+		}
+	}
+	return nil
+}
+
+// CreateApplication creates a new application
+func (r *Router) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	return r.applications().CreateApplication(ctx, app)
+}
+
+// GetApplicationByID retrieves an application by ID
+func (r *Router) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
+	return r.applications().GetApplicationByID(ctx, id)
+}
+
+// GetApplicationByName retrieves an application by name
+func (r *Router) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+	return r.applications().GetApplicationByName(ctx, name)
+}
+
+// ListApplications retrieves a paginated list of applications
+func (r *Router) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
+	return r.applications().ListApplications(ctx, page, pageSize)
+}
+
+// UpdateApplication updates an existing application
+func (r *Router) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	return r.applications().UpdateApplication(ctx, app)
+}
+
+// DeleteApplication deletes an application by ID
+func (r *Router) DeleteApplication(ctx context.Context, id uint) error {
+	return r.applications().DeleteApplication(ctx, id)
+}
+
+// CreateFeatureFlag creates a new feature flag
+func (r *Router) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	return r.featureFlags().CreateFeatureFlag(ctx, flag)
+}
+
+// GetFeatureFlagByKey retrieves a feature flag by key
+func (r *Router) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	return r.featureFlags().GetFeatureFlagByKey(ctx, key)
+}
+
+// ListFeatureFlags retrieves all feature flags
+func (r *Router) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	return r.featureFlags().ListFeatureFlags(ctx)
+}
+
+// UpdateFeatureFlag updates an existing feature flag
+func (r *Router) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	return r.featureFlags().UpdateFeatureFlag(ctx, flag)
+}
+
+// DeleteFeatureFlag deletes a feature flag by key
+func (r *Router) DeleteFeatureFlag(ctx context.Context, key string) error {
+	return r.featureFlags().DeleteFeatureFlag(ctx, key)
+}
+
+// applications returns the datastore holding the applications table
+func (r *Router) applications() DatastoreInterface {
+	return r.Route((&model.Application{}).TableName())
+}
+
+// featureFlags returns the datastore holding the feature flags table
+func (r *Router) featureFlags() DatastoreInterface {
+	return r.Route((&model.FeatureFlag{}).TableName())
+}
+
+// Transaction runs fn with a router whose primary datastore is bound to a
+// transaction of the primary datastore; the other datastores are not
+func (r *Router) Transaction(ctx context.Context, fn func(tx DatastoreInterface) error) error {
+	transactional, ok := r.Primary().(Transactional)
+	if !ok {
+		return fmt.Errorf("%w: datastore %T does not support transactions", ErrTransactionFailed, r.Primary())
+	}
+	return transactional.Transaction(ctx, func(tx DatastoreInterface) error {
+		bound := &Router{byName: make(map[string]DatastoreInterface, len(r.byName)), routes: r.routes}
+		for _, named := range r.stores {
+			if named.Name == PrimaryDatastore {
+				named.Store = tx
+			}
+			bound.byName[named.Name] = named.Store
+			bound.stores = append(bound.stores, named)
+		}
+		return fn(bound)
+	})
+}
+
+// Migrate migrates every datastore, each one its own tables
+func (r *Router) Migrate() error {
+	for _, named := range r.stores {
+		if err := named.Store.Migrate(); err != nil {
+			return fmt.Errorf("datastore %s: %w", named.Name, err)
+		}
+	}
+	return nil
+}
+
+// CheckSchema implements SchemaChecker for the datastores that implement it
+func (r *Router) CheckSchema() error {
+	for _, named := range r.stores {
+		if checker, ok := named.Store.(SchemaChecker); ok {
+			if err := checker.CheckSchema(); err != nil {
+				return fmt.Errorf("datastore %s: %w", named.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Close closes every datastore
+func (r *Router) Close() error {
+	var errs []error
+	for _, named := range r.stores {
+		if err := named.Store.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("datastore %s: %w", named.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// OnStop closes the primary datastore when the container stops. The server
+// registers the other datastores as beans of their own, which the container
+// stops after the router.
+func (r *Router) OnStop(ctx context.Context) error {
+	return r.Primary().Close()
+}
+
+// HealthCheck checks every datastore
+func (r *Router) HealthCheck() error {
+	var errs []error
+	for _, named := range r.stores {
+		if err := named.Store.HealthCheck(); err != nil {
+			errs = append(errs, fmt.Errorf("datastore %s: %w", named.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Stats returns the statistics of the primary datastore, with those of the
+// other datastores by name
+func (r *Router) Stats() *DatastoreStats {
+	var stats *DatastoreStats
+	others := make(map[string]*DatastoreStats, len(r.stores)-1)
+	for _, named := range r.stores {
+		provider, ok := named.Store.(StatsProvider)
+		if !ok {
+			continue
+		}
+		if named.Name == PrimaryDatastore {
+			stats = provider.Stats()
+		} else {
+			others[named.Name] = provider.Stats()
+		}
+	}
+	if stats == nil {
+		stats = &DatastoreStats{}
+	}
+	if len(others) > 0 {
+		copied := *stats
+		copied.Datastores = others
+		stats = &copied
+	}
+	return stats
+}
@@ -0,0 +1,54 @@
+package datastore
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatsProvider is implemented by datastores that report operational statistics
+type StatsProvider interface {
+	Stats() *DatastoreStats
+}
+
+// DatastoreStats describes the current state of a datastore
+type DatastoreStats struct {
+	Driver      string           `json:"driver"`
+	Tables      map[string]int   `json:"tables,omitempty"` // row counts, reported by in-memory datastores
+	Connections *ConnectionStats `json:"connections,omitempty"`
+	// Datastores holds the statistics of the other datastores of a Router by name
+	Datastores map[string]*DatastoreStats `json:"datastores,omitempty"`
+}
+
+// ConnectionStats describes the connection pool of an SQL datastore
+type ConnectionStats struct {
+	MaxOpen           int           `json:"max_open"`
+	Open              int           `json:"open"`
+	InUse             int           `json:"in_use"`
+	Idle              int           `json:"idle"`
+	WaitCount         int64         `json:"wait_count"`
+	WaitDuration      time.Duration `json:"wait_duration"`
+	MaxIdleClosed     int64         `json:"max_idle_closed"`
+	MaxLifetimeClosed int64         `json:"max_lifetime_closed"`
+}
+
+// GormStats returns the connection pool statistics of a GORM connection
+func GormStats(driver string, db *gorm.DB) *DatastoreStats {
+	stats := &DatastoreStats{Driver: driver}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return stats
+	}
+	s := sqlDB.Stats()
+	stats.Connections = &ConnectionStats{
+		MaxOpen:           s.MaxOpenConnections,
+		Open:              s.OpenConnections,
+		InUse:             s.InUse,
+		Idle:              s.Idle,
+		WaitCount:         s.WaitCount,
+		WaitDuration:      s.WaitDuration,
+		MaxIdleClosed:     s.MaxIdleClosed,
+		MaxLifetimeClosed: s.MaxLifetimeClosed,
+	}
+	return stats
+}
@@ -0,0 +1,121 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+)
+
+// Transactional is implemented by datastores that support transactions
+type Transactional interface {
+	// Transaction runs fn with a datastore bound to a single transaction. The
+	// transaction is committed when fn returns nil and rolled back otherwise.
+	Transaction(ctx context.Context, fn func(tx DatastoreInterface) error) error
+}
+
+// UnitOfWork groups repository operations and domain events into one transaction
+type UnitOfWork interface {
+	// Store returns the datastore bound to the transaction, use it with NewRepository
+	Store() DatastoreInterface
+	// Publish defers the event until the unit of work commits. Events of a
+	// rolled back unit of work are discarded.
+	Publish(evt event.Event)
+}
+
+// UnitOfWorkManager runs functions inside a unit of work
+type UnitOfWorkManager interface {
+	// Do runs fn in a unit of work, committing when fn returns nil and rolling
+	// back otherwise. Nested calls with a context returned by Do join the
+	// outer unit of work.
+	Do(ctx context.Context, fn func(ctx context.Context, uow UnitOfWork) error) error
+}
+
+// Outbox stores the events of a unit of work in its transaction, so that they
+// are delivered even when the process stops right after the commit
+type Outbox interface {
+	// Record stores events using tx; an error rolls the unit of work back
+	Record(ctx context.Context, tx DatastoreInterface, events []event.Event) error
+}
+
+type unitOfWorkContextKey struct{}
+
+// UnitOfWorkFromContext returns the unit of work carried by ctx, if any
+func UnitOfWorkFromContext(ctx context.Context) (UnitOfWork, bool) {
+	uow, ok := ctx.Value(unitOfWorkContextKey{}).(UnitOfWork)
+	return uow, ok
+}
+
+// unitOfWorkManager implements UnitOfWorkManager on top of a transactional datastore
+type unitOfWorkManager struct {
+	store  DatastoreInterface
+	bus    event.Bus
+	outbox Outbox
+}
+
+// NewUnitOfWorkManager creates a unit of work manager. The event bus may be nil,
+// in which case published events are dropped.
+func NewUnitOfWorkManager(store DatastoreInterface, bus event.Bus) UnitOfWorkManager {
+	return &unitOfWorkManager{store: store, bus: bus}
+}
+
+// NewUnitOfWorkManagerWithOutbox creates a unit of work manager that also records
+// the published events in outbox before committing
+func NewUnitOfWorkManagerWithOutbox(store DatastoreInterface, bus event.Bus, outbox Outbox) UnitOfWorkManager {
+	return &unitOfWorkManager{store: store, bus: bus, outbox: outbox}
+}
+
+// Do runs fn in a unit of work
+func (m *unitOfWorkManager) Do(ctx context.Context, fn func(ctx context.Context, uow UnitOfWork) error) error {
+	if uow, ok := UnitOfWorkFromContext(ctx); ok {
+		return fn(ctx, uow)
+	}
+
+	transactional, ok := m.store.(Transactional)
+	if !ok {
+		return fmt.Errorf("%w: datastore %T does not support transactions", ErrTransactionFailed, m.store)
+	}
+
+	var uow *unitOfWork
+	err := transactional.Transaction(ctx, func(tx DatastoreInterface) error {
+		uow = &unitOfWork{store: tx}
+		if err := fn(context.WithValue(ctx, unitOfWorkContextKey{}, UnitOfWork(uow)), uow); err != nil {
+			return err
+		}
+		if m.outbox != nil && len(uow.events) > 0 {
+			return m.outbox.Record(ctx, tx, uow.events)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if m.bus != nil {
+		for _, evt := range uow.events {
+			m.bus.Publish(ctx, evt)
+		}
+	}
+	return nil
+}
+
+// unitOfWork collects the events of a running transaction
+type unitOfWork struct {
+	store  DatastoreInterface
+	mu     sync.Mutex
+	events []event.Event
+}
+
+// Store returns the datastore bound to the transaction
+func (u *unitOfWork) Store() DatastoreInterface {
+	return u.store
+}
+
+// Publish defers the event until commit
+func (u *unitOfWork) Publish(evt event.Event) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.events = append(u.events, evt)
+}
@@ -0,0 +1,73 @@
+// Package denylist remembers the IDs of terminated sessions until the last
+// access token they issued expires, so that those tokens are rejected before
+// they expire on their own.
+package denylist
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Denylist keeps denied session IDs in a counter store
+type Denylist struct {
+	store quota.Store
+}
+
+// New creates the denylist of security.sessions with the store it selects.
+// A disabled denylist does not connect to its store.
+func New(cfg *config.Config) (*Denylist, error) {
+	sessions := cfg.Security.Sessions
+	switch {
+	case !sessions.Enabled, sessions.DenylistStore == quota.StoreMemory, sessions.DenylistStore == "":
+		return NewDenylist(quota.NewMemoryStore()), nil
+	case sessions.DenylistStore == quota.StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect session denylist store: %w", err)
+		}
+		return NewDenylist(quota.NewRedisStore(client)), nil
+	default:
+		return nil, fmt.Errorf("unsupported session denylist store: %s", sessions.DenylistStore)
+	}
+}
+
+// NewDenylist creates a denylist kept in store
+func NewDenylist(store quota.Store) *Denylist {
+	return &Denylist{store: store}
+}
+
+// Deny denies the tokens of sessionID until until
+func (d *Denylist) Deny(ctx context.Context, sessionID string, until time.Time) error {
+	if _, err := d.store.Increment(ctx, key(sessionID), until); err != nil {
+		return fmt.Errorf("failed to denylist session %s: %w", sessionID, err)
+	}
+	return nil
+}
+
+// Denied reports whether the tokens of sessionID are denied
+func (d *Denylist) Denied(ctx context.Context, sessionID string) (bool, error) {
+	count, err := d.store.Get(ctx, key(sessionID))
+	if err != nil {
+		return false, fmt.Errorf("failed to check session denylist: %w", err)
+	}
+	return count > 0, nil
+}
+
+// OnStop closes the store when it holds a connection
+func (d *Denylist) OnStop(ctx context.Context) error {
+	if closer, ok := d.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// key is the store key of a denied session
+func key(sessionID string) string {
+	return "denylist:session:" + sessionID
+}
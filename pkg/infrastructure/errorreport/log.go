@@ -0,0 +1,59 @@
+package errorreport
+
+import (
+	"context"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/sirupsen/logrus"
+)
+
+// logReporter writes events to the application logger
+type logReporter struct{}
+
+// newLogReporter creates a reporter backed by the application logger
+func newLogReporter(cfg *config.Config) (Reporter, error) {
+	return &logReporter{}, nil
+}
+
+// Report implements Reporter
+func (r *logReporter) Report(ctx context.Context, event *Event) {
+	fields := logrus.Fields{
+		"request_id":  event.RequestID,
+		"user_id":     event.UserID,
+		"route":       event.Route,
+		"method":      event.Method,
+		"status_code": event.StatusCode,
+		"panic":       event.Panic,
+	}
+	for k, v := range event.Tags {
+		fields["tag_"+k] = v
+	}
+	for k, v := range event.Extra {
+		fields[k] = v
+	}
+	if event.Error != nil {
+		fields["error"] = event.Error.Error()
+	}
+	if event.StackTrace != "" {
+		fields["stack_trace"] = event.StackTrace
+	}
+
+	entry := logger.WithFields(fields)
+	if event.Level == LevelFatal {
+		entry.Errorf("[FATAL] %s", event.Message)
+		return
+	}
+	entry.Error(event.Message)
+}
+
+// Flush implements Reporter
+func (r *logReporter) Flush(timeout time.Duration) bool {
+	return true
+}
+
+// Close implements Reporter
+func (r *logReporter) Close() error {
+	return nil
+}
@@ -0,0 +1,83 @@
+package errorreport
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RecentReporter keeps the most recent events in memory in addition to
+// delegating them to the wrapped reporter. Events are kept before sampling, so
+// the history is complete even when the backend drops events or is disabled.
+type RecentReporter struct {
+	Reporter
+	mu     sync.Mutex
+	events []Event
+	next   int
+	full   bool
+	total  uint64
+}
+
+// NewRecentReporter wraps reporter and keeps the last size events
+func NewRecentReporter(reporter Reporter, size int) *RecentReporter {
+	if size < 1 {
+		size = 1
+	}
+	return &RecentReporter{Reporter: reporter, events: make([]Event, size)}
+}
+
+// Report implements Reporter
+func (r *RecentReporter) Report(ctx context.Context, event *Event) {
+	if event == nil {
+		return
+	}
+	// Delegate first so the kept copy carries the tags added by the backend
+	r.Reporter.Report(ctx, event)
+
+	recorded := *event
+	if recorded.Timestamp.IsZero() {
+		recorded.Timestamp = time.Now()
+	}
+	if recorded.Level == "" {
+		recorded.Level = LevelError
+	}
+	if recorded.Message == "" && recorded.Error != nil {
+		recorded.Message = recorded.Error.Error()
+	}
+
+	r.mu.Lock()
+	r.events[r.next] = recorded
+	r.next = (r.next + 1) % len(r.events)
+	r.full = r.full || r.next == 0
+	r.total++
+	r.mu.Unlock()
+}
+
+// Recent returns up to limit kept events, newest first, and the number of
+// events reported since start. A limit below 1 returns all kept events.
+func (r *RecentReporter) Recent(limit int) ([]Event, uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.events)
+	}
+	if limit < 1 || limit > count {
+		limit = count
+	}
+
+	events := make([]Event, 0, limit)
+	for i := 1; i <= limit; i++ {
+		events = append(events, r.events[(r.next-i+len(r.events))%len(r.events)])
+	}
+	return events, r.total
+}
+
+// OnStop stops the wrapped reporter when the container stops
+func (r *RecentReporter) OnStop(ctx context.Context) error {
+	if stopper, ok := r.Reporter.(interface{ OnStop(context.Context) error }); ok {
+		return stopper.OnStop(ctx)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Level represents the severity of a reported event
+type Level string
+
+const (
+	LevelError Level = "error"
+	LevelFatal Level = "fatal"
+)
+
+// Event describes a single error occurrence together with request metadata
+type Event struct {
+	Level      Level                  `json:"level"`
+	Message    string                 `json:"message"`
+	Error      error                  `json:"-"`
+	StackTrace string                 `json:"stack_trace,omitempty"`
+	Panic      bool                   `json:"panic"`
+	RequestID  string                 `json:"request_id,omitempty"`
+	UserID     string                 `json:"user_id,omitempty"`
+	Route      string                 `json:"route,omitempty"`
+	Method     string                 `json:"method,omitempty"`
+	StatusCode int                    `json:"status_code,omitempty"`
+	Tags       map[string]string      `json:"tags,omitempty"`
+	Extra      map[string]interface{} `json:"extra,omitempty"`
+	Timestamp  time.Time              `json:"timestamp"`
+}
+
+// Reporter defines the interface for error reporting backends
+type Reporter interface {
+	// Report sends an event to the backend
+	Report(ctx context.Context, event *Event)
+	// Flush waits until buffered events are delivered or the timeout expires
+	Flush(timeout time.Duration) bool
+	// Close releases resources held by the backend
+	Close() error
+}
+
+// Factory creates a reporter from configuration
+type Factory func(cfg *config.Config) (Reporter, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Factory{
+		"log": newLogReporter,
+	}
+)
+
+// RegisterProvider registers a reporter factory under the given provider name
+func RegisterProvider(name string, factory Factory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// New creates a reporter according to the monitor.error_reporting configuration.
+// A no-op reporter is returned when error reporting is disabled.
+func New(cfg *config.Config) (Reporter, error) {
+	erCfg := cfg.Monitor.ErrorReporting
+	if !erCfg.Enabled {
+		return NewNoopReporter(), nil
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[erCfg.Provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported error reporting provider: %s", erCfg.Provider)
+	}
+
+	reporter, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s error reporter: %w", erCfg.Provider, err)
+	}
+
+	return &sampledReporter{
+		Reporter:     reporter,
+		sampleRate:   erCfg.SampleRate,
+		environment:  environment(cfg),
+		release:      release(cfg),
+		flushTimeout: erCfg.FlushTimeout,
+	}, nil
+}
+
+// sampledReporter applies sampling and common tags before delegating to the backend.
+// Panics are never sampled out.
+type sampledReporter struct {
+	Reporter
+	sampleRate   float64
+	environment  string
+	release      string
+	flushTimeout time.Duration
+}
+
+// Report implements Reporter
+func (r *sampledReporter) Report(ctx context.Context, event *Event) {
+	if event == nil {
+		return
+	}
+	if !event.Panic && r.sampleRate < 1 && rand.Float64() >= r.sampleRate {
+		return
+	}
+
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if event.Level == "" {
+		event.Level = LevelError
+	}
+	if event.Tags == nil {
+		event.Tags = make(map[string]string)
+	}
+	if r.environment != "" {
+		event.Tags["environment"] = r.environment
+	}
+	if r.release != "" {
+		event.Tags["release"] = r.release
+	}
+
+	r.Reporter.Report(ctx, event)
+}
+
+// OnStop flushes buffered events and closes the backend when the container stops
+func (r *sampledReporter) OnStop(ctx context.Context) error {
+	timeout := r.flushTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+	if !r.Flush(timeout) {
+		logger.Warn("Error reporter flush timed out after %s", timeout)
+	}
+	return r.Close()
+}
+
+// noopReporter discards all events
+type noopReporter struct{}
+
+// NewNoopReporter creates a reporter that discards all events
+func NewNoopReporter() Reporter {
+	return noopReporter{}
+}
+
+func (noopReporter) Report(ctx context.Context, event *Event) {}
+func (noopReporter) Flush(timeout time.Duration) bool         { return true }
+func (noopReporter) Close() error                             { return nil }
+
+// environment returns the configured environment tag, defaulting to app.env
+func environment(cfg *config.Config) string {
+	if cfg.Monitor.ErrorReporting.Environment != "" {
+		return cfg.Monitor.ErrorReporting.Environment
+	}
+	return cfg.App.Env
+}
+
+// release returns the configured release tag, defaulting to app.version
+func release(cfg *config.Config) string {
+	if cfg.Monitor.ErrorReporting.Release != "" {
+		return cfg.Monitor.ErrorReporting.Release
+	}
+	return cfg.App.Version
+}
@@ -0,0 +1,89 @@
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/getsentry/sentry-go"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+func init() {
+	RegisterProvider("sentry", newSentryReporter)
+}
+
+// sentryReporter sends events to Sentry
+type sentryReporter struct {
+	hub *sentry.Hub
+}
+
+// newSentryReporter creates a Sentry-backed reporter
+func newSentryReporter(cfg *config.Config) (Reporter, error) {
+	erCfg := cfg.Monitor.ErrorReporting
+	if erCfg.DSN == "" {
+		return nil, fmt.Errorf("sentry dsn is required")
+	}
+
+	client, err := sentry.NewClient(sentry.ClientOptions{
+		Dsn:         erCfg.DSN,
+		Environment: environment(cfg),
+		Release:     release(cfg),
+		ServerName:  cfg.App.Name,
+		// Sampling is applied by sampledReporter so that panics are never dropped
+		SampleRate: 1.0,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sentryReporter{
+		hub: sentry.NewHub(client, sentry.NewScope()),
+	}, nil
+}
+
+// Report implements Reporter
+func (r *sentryReporter) Report(ctx context.Context, event *Event) {
+	hub := r.hub.Clone()
+	hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetLevel(sentryLevel(event.Level))
+		scope.SetTags(event.Tags)
+		scope.SetTag("route", event.Route)
+		scope.SetTag("method", event.Method)
+		scope.SetTag("request_id", event.RequestID)
+		if event.StatusCode != 0 {
+			scope.SetTag("status_code", fmt.Sprintf("%d", event.StatusCode))
+		}
+		if event.UserID != "" {
+			scope.SetUser(sentry.User{ID: event.UserID})
+		}
+		scope.SetExtras(event.Extra)
+		if event.StackTrace != "" {
+			scope.SetExtra("stack_trace", event.StackTrace)
+		}
+
+		if event.Error != nil {
+			hub.CaptureException(event.Error)
+			return
+		}
+		hub.CaptureMessage(event.Message)
+	})
+}
+
+// Flush implements Reporter
+func (r *sentryReporter) Flush(timeout time.Duration) bool {
+	return r.hub.Flush(timeout)
+}
+
+// Close implements Reporter
+func (r *sentryReporter) Close() error {
+	return nil
+}
+
+// sentryLevel maps a reporter level to a Sentry level
+func sentryLevel(level Level) sentry.Level {
+	if level == LevelFatal {
+		return sentry.LevelFatal
+	}
+	return sentry.LevelError
+}
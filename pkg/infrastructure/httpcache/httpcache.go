@@ -0,0 +1,202 @@
+// Package httpcache stores the responses of cacheable GET routes. Responses
+// are grouped by tags; invalidating a tag bumps its generation, which is part
+// of the keys of the responses stored under it, so that stale responses are
+// no longer found and expire with their TTL. With the redis store the
+// generations, and so invalidations, are shared by all instances.
+package httpcache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Store types
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// keyPrefix prefixes the keys of the cache in its store
+const keyPrefix = "httpcache:"
+
+// Store keeps cached responses and tag generations
+type Store interface {
+	// Get returns the value under key, nil when it does not exist or has expired
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Generation returns the generation of tag, zero until it is first bumped
+	Generation(ctx context.Context, tag string) (int64, error)
+	// Bump increments the generation of tag
+	Bump(ctx context.Context, tag string) error
+}
+
+// Entry is a cached response
+type Entry struct {
+	Status   int         `json:"status"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+	StoredAt time.Time   `json:"stored_at"`
+}
+
+// Cache caches responses in a store and invalidates them on domain events
+type Cache struct {
+	enabled     bool
+	store       Store
+	clock       clock.Clock
+	maxBodySize int64
+
+	mu            sync.RWMutex
+	invalidations map[string][]string // event type to tags
+}
+
+// New creates a response cache with the store selected by the response cache
+// configuration. A disabled cache does not connect to its store.
+func New(cfg *config.Config, clk clock.Clock) (*Cache, error) {
+	cacheCfg := cfg.Server.Cache
+	var store Store
+	switch {
+	case !cacheCfg.Enabled, cacheCfg.Store == StoreMemory, cacheCfg.Store == "":
+		store = NewMemoryStore(cacheCfg.MaxEntries, clk)
+	case cacheCfg.Store == StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect response cache store: %w", err)
+		}
+		store = NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported response cache store: %s", cacheCfg.Store)
+	}
+	return NewCache(cacheCfg, store, clk), nil
+}
+
+// NewCache creates a response cache keeping responses in store
+func NewCache(cfg config.HTTPCacheConfig, store Store, clk clock.Clock) *Cache {
+	return &Cache{
+		enabled:       cfg.Enabled,
+		store:         store,
+		clock:         clk,
+		maxBodySize:   int64(cfg.MaxBodySize),
+		invalidations: make(map[string][]string),
+	}
+}
+
+// Enabled reports whether responses are cached
+func (c *Cache) Enabled() bool {
+	return c.enabled
+}
+
+// MaxBodySize returns the size above which responses are not cached, 0 when unlimited
+func (c *Cache) MaxBodySize() int64 {
+	return c.maxBodySize
+}
+
+// Now returns the current time of the cache clock
+func (c *Cache) Now() time.Time {
+	return c.clock.Now()
+}
+
+// Key returns the store key of key for the current generations of tags.
+// Resolve it before running the handler: a response computed while one of
+// its tags is invalidated is then stored under the old generation and never
+// found.
+func (c *Cache) Key(ctx context.Context, key string, tags []string) (string, error) {
+	var b strings.Builder
+	b.WriteString(keyPrefix)
+	b.WriteString(key)
+	for _, tag := range tags {
+		generation, err := c.store.Generation(ctx, tag)
+		if err != nil {
+			return "", err
+		}
+		b.WriteString("|")
+		b.WriteString(tag)
+		b.WriteString("=")
+		b.WriteString(strconv.FormatInt(generation, 10))
+	}
+	return b.String(), nil
+}
+
+// Get returns the response stored under a key returned by Key, nil when there is none
+func (c *Cache) Get(ctx context.Context, storeKey string) (*Entry, error) {
+	data, err := c.store.Get(ctx, storeKey)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid cached response: %w", err)
+	}
+	return &entry, nil
+}
+
+// Set stores a response for ttl under a key returned by Key
+func (c *Cache) Set(ctx context.Context, storeKey string, entry *Entry, ttl time.Duration) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	return c.store.Set(ctx, storeKey, data, ttl)
+}
+
+// Invalidate drops the responses stored under any of tags
+func (c *Cache) Invalidate(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		if err := c.store.Bump(ctx, tag); err != nil {
+			return fmt.Errorf("failed to invalidate %s: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// InvalidateOn invalidates tags whenever an event of eventType is handled by HandleEvent
+func (c *Cache) InvalidateOn(eventType string, tags ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, tag := range tags {
+		if !slices.Contains(c.invalidations[eventType], tag) {
+			c.invalidations[eventType] = append(c.invalidations[eventType], tag)
+		}
+	}
+}
+
+// HandleEvent invalidates the tags registered for the type of e with
+// InvalidateOn; subscribe it to the event bus for all event types
+func (c *Cache) HandleEvent(ctx context.Context, e event.Event) {
+	c.mu.RLock()
+	tags := c.invalidations[e.Type]
+	c.mu.RUnlock()
+	if len(tags) == 0 {
+		return
+	}
+
+	if err := c.Invalidate(ctx, tags...); err != nil {
+		logger.Warn("Failed to invalidate cached responses on %s: %v", e.Type, err)
+		return
+	}
+	logger.Debug("Invalidated cached responses tagged %s on %s", strings.Join(tags, ", "), e.Type)
+}
+
+// OnStop closes the connection of the store
+func (c *Cache) OnStop(ctx context.Context) error {
+	if closer, ok := c.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
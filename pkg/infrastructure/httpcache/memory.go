@@ -0,0 +1,101 @@
+package httpcache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+// sweepInterval is how often expired responses are removed from a memory store
+const sweepInterval = time.Minute
+
+// memoryEntry is a response kept in memory
+type memoryEntry struct {
+	value    []byte
+	expireAt time.Time
+}
+
+// MemoryStore implements Store in memory; responses are per process and lost on restart
+type MemoryStore struct {
+	maxEntries int
+	clock      clock.Clock
+
+	mu          sync.Mutex
+	entries     map[string]memoryEntry
+	generations map[string]int64
+	lastSweep   time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store keeping up to maxEntries
+// responses, any number when maxEntries is 0
+func NewMemoryStore(maxEntries int, clk clock.Clock) *MemoryStore {
+	return &MemoryStore{
+		maxEntries:  maxEntries,
+		clock:       clk,
+		entries:     make(map[string]memoryEntry),
+		generations: make(map[string]int64),
+		lastSweep:   clk.Now(),
+	}
+}
+
+// Get returns the response unless it has expired
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[key]
+	if !ok || !s.clock.Now().Before(entry.expireAt) {
+		return nil, nil
+	}
+	return entry.value, nil
+}
+
+// Set stores the response. When the store is full, expired responses are
+// removed first, then arbitrary ones.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	now := s.clock.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+	if _, ok := s.entries[key]; !ok && s.maxEntries > 0 && len(s.entries) >= s.maxEntries {
+		s.sweep(now)
+		for k := range s.entries {
+			if len(s.entries) < s.maxEntries {
+				break
+			}
+			delete(s.entries, k)
+		}
+	}
+	s.entries[key] = memoryEntry{value: value, expireAt: now.Add(ttl)}
+	return nil
+}
+
+// Generation returns the generation of tag
+func (s *MemoryStore) Generation(ctx context.Context, tag string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generations[tag], nil
+}
+
+// Bump increments the generation of tag
+func (s *MemoryStore) Bump(ctx context.Context, tag string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generations[tag]++
+	return nil
+}
+
+// sweep removes expired responses; the caller holds the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+	s.lastSweep = now
+}
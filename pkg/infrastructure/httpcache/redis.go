@@ -0,0 +1,48 @@
+package httpcache
+
+import (
+	"context"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+)
+
+// generationPrefix prefixes the keys of the tag generations in Redis
+const generationPrefix = keyPrefix + "generation:"
+
+// RedisStore implements Store in Redis so that responses and invalidations
+// are shared by all instances
+type RedisStore struct {
+	client *infra_middleware.RedisClient
+}
+
+// NewRedisStore creates a store caching in Redis
+func NewRedisStore(client *infra_middleware.RedisClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Get returns the response, nil when the key does not exist
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, error) {
+	return s.client.GetBytes(ctx, key)
+}
+
+// Set stores the response with its TTL as expiration
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, key, value, ttl)
+}
+
+// Generation returns the generation of tag, zero when the key does not exist
+func (s *RedisStore) Generation(ctx context.Context, tag string) (int64, error) {
+	return s.client.GetInt64(ctx, generationPrefix+tag)
+}
+
+// Bump increments the generation of tag with INCR
+func (s *RedisStore) Bump(ctx context.Context, tag string) error {
+	_, err := s.client.Incr(ctx, generationPrefix+tag)
+	return err
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
@@ -0,0 +1,339 @@
+// Package loginguard protects login routes against brute-force attacks. Failed
+// logins are counted per username and per client IP in sliding windows kept in
+// the quota counter stores. Repeated failures of a username delay its next
+// attempt progressively and then require a challenge; a username or IP
+// reaching its limit is locked out, which is published as a domain event.
+package loginguard
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Subjects failures are counted for
+const (
+	SubjectUsername = "username"
+	SubjectIP       = "ip"
+)
+
+// Reasons a login attempt is rejected
+const (
+	ReasonLocked            = "locked"
+	ReasonDelayed           = "delayed"
+	ReasonChallengeRequired = "challenge_required"
+)
+
+// EventTypeLockedOut is published when a username or client IP is locked out
+const EventTypeLockedOut = "login.locked_out"
+
+var (
+	rejectionsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_throttle_rejections_total",
+			Help: "Total number of login attempts rejected by the login throttle, by reason",
+		},
+		[]string{"reason"},
+	)
+
+	lockoutsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "login_lockouts_total",
+			Help: "Total number of lockouts after repeated failed logins, by subject",
+		},
+		[]string{"subject"},
+	)
+)
+
+// LockedOut is the payload of a locked out event. Username is the attempted
+// username, also when the client IP was locked out.
+type LockedOut struct {
+	Subject  string    `json:"subject"`
+	Username string    `json:"username,omitempty"`
+	IP       string    `json:"ip"`
+	Failures int       `json:"failures"`
+	Until    time.Time `json:"until"`
+}
+
+// Status is the outcome of checking a login attempt. RetryAfter is set for locked
+// out and delayed attempts; it is an upper bound of the remaining wait.
+type Status struct {
+	Locked            bool
+	RetryAfter        time.Duration
+	ChallengeRequired bool
+}
+
+// Reason returns the reason the attempt is rejected, empty when it may proceed
+// without a challenge
+func (s Status) Reason() string {
+	switch {
+	case s.Locked:
+		return ReasonLocked
+	case s.RetryAfter > 0:
+		return ReasonDelayed
+	case s.ChallengeRequired:
+		return ReasonChallengeRequired
+	default:
+		return ""
+	}
+}
+
+// Guard counts failed logins and decides whether attempts may proceed
+type Guard struct {
+	cfg   config.LoginThrottleConfig
+	store quota.Store
+	bus   event.Bus
+	clock clock.Clock
+}
+
+// New creates the guard of security.login_throttle with the store it selects.
+// A disabled guard does not connect to its store.
+func New(cfg *config.Config, bus event.Bus, clk clock.Clock) (*Guard, error) {
+	throttle := cfg.Security.LoginThrottle
+	var store quota.Store
+	switch {
+	case !throttle.Enabled, throttle.Store == quota.StoreMemory, throttle.Store == "":
+		store = quota.NewMemoryStore()
+	case throttle.Store == quota.StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect login throttle store: %w", err)
+		}
+		store = quota.NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported login throttle store: %s", throttle.Store)
+	}
+	return NewGuard(throttle, store, bus, clk), nil
+}
+
+// NewGuard creates a guard counting failures in store. bus, which may be nil,
+// receives the lockouts.
+func NewGuard(cfg config.LoginThrottleConfig, store quota.Store, bus event.Bus, clk clock.Clock) *Guard {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &Guard{cfg: cfg, store: store, bus: bus, clock: clk}
+}
+
+// Enabled reports whether login attempts are throttled
+func (g *Guard) Enabled() bool {
+	return g != nil && g.cfg.Enabled
+}
+
+// UsernameFields returns the JSON body fields holding the username
+func (g *Guard) UsernameFields() []string {
+	return g.cfg.UsernameFields
+}
+
+// Check checks a login attempt for username, which may be empty, from ip.
+// Rejected attempts are counted by reason.
+func (g *Guard) Check(ctx context.Context, username, ip string) (Status, error) {
+	status, err := g.check(ctx, username, ip)
+	if reason := status.Reason(); reason != "" {
+		rejectionsTotal.WithLabelValues(reason).Inc()
+	}
+	return status, err
+}
+
+// check implements Check
+func (g *Guard) check(ctx context.Context, username, ip string) (Status, error) {
+	subjects := []string{ipSubject(ip)}
+	if username != "" {
+		subjects = append(subjects, usernameSubject(username))
+	}
+	for _, subject := range subjects {
+		locked, err := g.store.Get(ctx, lockKey(subject))
+		if err != nil {
+			return Status{}, fmt.Errorf("failed to check login lockout: %w", err)
+		}
+		if locked > 0 {
+			return Status{Locked: true, RetryAfter: g.cfg.LockoutDuration}, nil
+		}
+	}
+	if username == "" {
+		return Status{}, nil
+	}
+
+	subject := usernameSubject(username)
+	failures, err := g.failures(ctx, subject, g.clock.Now())
+	if err != nil {
+		return Status{}, err
+	}
+	delayed, err := g.store.Get(ctx, delayKey(subject))
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to check login delay: %w", err)
+	}
+	if delayed > 0 {
+		return Status{RetryAfter: g.delay(failures)}, nil
+	}
+	return Status{ChallengeRequired: g.cfg.ChallengeAfter > 0 && failures >= g.cfg.ChallengeAfter}, nil
+}
+
+// RecordFailure counts a failed login for username, which may be empty, from
+// ip. It delays the next attempt of username, and locks username or ip out once
+// it reaches its limit.
+func (g *Guard) RecordFailure(ctx context.Context, username, ip string) error {
+	now := g.clock.Now()
+	ipFailures, err := g.increment(ctx, ipSubject(ip), now)
+	if err != nil {
+		return err
+	}
+	if ipFailures >= g.cfg.MaxIPFailures {
+		if err := g.lock(ctx, SubjectIP, ipSubject(ip), username, ip, ipFailures, now); err != nil {
+			return err
+		}
+	}
+	if username == "" {
+		return nil
+	}
+
+	subject := usernameSubject(username)
+	failures, err := g.increment(ctx, subject, now)
+	if err != nil {
+		return err
+	}
+	if failures >= g.cfg.MaxUsernameFailures {
+		return g.lock(ctx, SubjectUsername, subject, username, ip, failures, now)
+	}
+	if delay := g.delay(failures); delay > 0 {
+		if _, err := g.store.Increment(ctx, delayKey(subject), now.Add(delay)); err != nil {
+			return fmt.Errorf("failed to delay login: %w", err)
+		}
+	}
+	return nil
+}
+
+// RecordSuccess clears the failures of username after a successful login. The
+// failures of the client IP are kept, so that a valid account does not reset them.
+func (g *Guard) RecordSuccess(ctx context.Context, username string) error {
+	if username == "" {
+		return nil
+	}
+	subject := usernameSubject(username)
+	current := g.clock.Now().Truncate(g.cfg.Window)
+	return g.store.Delete(ctx,
+		failuresKey(subject, current), failuresKey(subject, current.Add(-g.cfg.Window)), delayKey(subject))
+}
+
+// OnStop closes the counter store when it holds a connection
+func (g *Guard) OnStop(ctx context.Context) error {
+	if closer, ok := g.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// increment counts a failure of subject and returns its failures within the window
+func (g *Guard) increment(ctx context.Context, subject string, now time.Time) (int, error) {
+	current := now.Truncate(g.cfg.Window)
+	// The counter of a window is still read as the previous one during the next window
+	if _, err := g.store.Increment(ctx, failuresKey(subject, current), current.Add(2*g.cfg.Window)); err != nil {
+		return 0, fmt.Errorf("failed to count login failure: %w", err)
+	}
+	return g.failures(ctx, subject, now)
+}
+
+// failures returns the failures of subject within the sliding window ending at
+// now, weighting the previous fixed window by its overlap with the sliding one
+func (g *Guard) failures(ctx context.Context, subject string, now time.Time) (int, error) {
+	current := now.Truncate(g.cfg.Window)
+	currentCount, err := g.store.Get(ctx, failuresKey(subject, current))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read login failures: %w", err)
+	}
+	previousCount, err := g.store.Get(ctx, failuresKey(subject, current.Add(-g.cfg.Window)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to read login failures: %w", err)
+	}
+	overlap := 1 - float64(now.Sub(current))/float64(g.cfg.Window)
+	return int(currentCount) + int(float64(previousCount)*overlap), nil
+}
+
+// delay returns the wait before the next attempt after failures, doubling from
+// the base delay with each failure beyond DelayAfter
+func (g *Guard) delay(failures int) time.Duration {
+	if failures <= g.cfg.DelayAfter || g.cfg.BaseDelay <= 0 {
+		return 0
+	}
+	delay := g.cfg.BaseDelay
+	for i := g.cfg.DelayAfter + 1; i < failures && delay < g.cfg.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.cfg.MaxDelay {
+		delay = g.cfg.MaxDelay
+	}
+	return delay
+}
+
+// lock locks subject out and publishes the lockout, once per lockout
+func (g *Guard) lock(ctx context.Context, kind, subject, username, ip string, failures int, now time.Time) error {
+	until := now.Add(g.cfg.LockoutDuration)
+	locks, err := g.store.Increment(ctx, lockKey(subject), until)
+	if err != nil {
+		return fmt.Errorf("failed to lock login out: %w", err)
+	}
+	if locks > 1 {
+		return nil
+	}
+
+	lockoutsTotal.WithLabelValues(kind).Inc()
+	logger.Warn("Login locked out for %s after %d failures (username %q, ip %s) until %s",
+		kind, failures, username, ip, until.Format(time.RFC3339))
+	if g.bus != nil {
+		g.bus.Publish(ctx, event.NewEvent(EventTypeLockedOut, LockedOut{
+			Subject:  kind,
+			Username: username,
+			IP:       ip,
+			Failures: failures,
+			Until:    until,
+		}))
+	}
+	return nil
+}
+
+// NormalizeUsername returns the form usernames are counted under, so that
+// changing their case or padding does not bypass the limits
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// usernameSubject returns the counter subject of a normalized username. Usernames
+// are hashed so that they are not stored in clear and cannot collide with key separators.
+func usernameSubject(username string) string {
+	sum := sha256.Sum256([]byte(username))
+	return "user:" + hex.EncodeToString(sum[:])
+}
+
+// ipSubject returns the counter subject of a client IP
+func ipSubject(ip string) string {
+	return "ip:" + ip
+}
+
+// failuresKey is the counter key of the failures of subject in the window starting at window
+func failuresKey(subject string, window time.Time) string {
+	return "login:failures:" + subject + ":" + strconv.FormatInt(window.Unix(), 10)
+}
+
+// delayKey is the key set while the next attempt of subject is delayed
+func delayKey(subject string) string {
+	return "login:delay:" + subject
+}
+
+// lockKey is the key set while subject is locked out
+func lockKey(subject string) string {
+	return "login:locked:" + subject
+}
@@ -0,0 +1,42 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/notification"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// SubscribeWebhook posts every lockout published on bus to url through the
+// webhook notification channel and returns a function that stops posting.
+// Lockouts are posted in the background so that logins do not wait for the webhook.
+func SubscribeWebhook(bus event.Bus, notifier *notification.Notifier, url string) func() {
+	return bus.Subscribe(EventTypeLockedOut, func(ctx context.Context, e event.Event) {
+		lockout, ok := e.Payload.(LockedOut)
+		if !ok {
+			return
+		}
+		msg := &notification.Message{
+			Channel: model.NotificationChannelWebhook,
+			To:      url,
+			Title:   "Login locked out",
+			Body:    fmt.Sprintf("%s locked out after %d failed logins", lockout.Subject, lockout.Failures),
+			Data: map[string]interface{}{
+				"event":    EventTypeLockedOut,
+				"subject":  lockout.Subject,
+				"username": lockout.Username,
+				"ip":       lockout.IP,
+				"failures": lockout.Failures,
+				"until":    lockout.Until,
+			},
+		}
+		go func() {
+			if err := notifier.Send(context.WithoutCancel(ctx), msg); err != nil {
+				logger.Warn("Failed to post login lockout to the webhook: %v", err)
+			}
+		}()
+	})
+}
@@ -0,0 +1,22 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// LogSender writes messages to the log instead of sending them; used in development and when mail is disabled
+type LogSender struct{}
+
+// NewLogSender creates a log sender
+func NewLogSender() *LogSender {
+	return &LogSender{}
+}
+
+// Send logs the message
+func (s *LogSender) Send(ctx context.Context, msg *Message) error {
+	logger.Info("Mail to %s: %s\n%s", strings.Join(msg.To, ", "), msg.Subject, msg.Text)
+	return nil
+}
@@ -0,0 +1,145 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Mail providers
+const (
+	ProviderSMTP = "smtp"
+	ProviderLog  = "log"
+)
+
+// ErrNoRecipients is returned for messages without recipients
+var ErrNoRecipients = errors.New("message has no recipients")
+
+// Message is an email ready to be sent
+type Message struct {
+	// From defaults to the configured sender
+	From    string
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+	// Headers are added to the message, e.g. Reply-To
+	Headers map[string]string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	// Send delivers msg synchronously; retries are left to the caller
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ProviderFactory creates the sender of a mail provider from configuration
+type ProviderFactory func(cfg *config.MailConfig) (Sender, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{
+		ProviderSMTP: func(cfg *config.MailConfig) (Sender, error) { return NewSMTPSender(&cfg.SMTP), nil },
+		ProviderLog:  func(cfg *config.MailConfig) (Sender, error) { return NewLogSender(), nil },
+	}
+)
+
+// RegisterProvider makes a mail provider such as ses available under name,
+// replacing any provider registered under the same name
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// New creates the sender of the configured provider with the configured sender
+// address and delivery metrics. A disabled mailer logs messages instead of sending them.
+func New(cfg *config.MailConfig) (Sender, error) {
+	provider := cfg.Provider
+	if !cfg.Enabled || provider == "" {
+		provider = ProviderLog
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[provider]
+	providersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported mail provider: %s", provider)
+	}
+
+	sender, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create mail provider %s: %w", provider, err)
+	}
+	return &instrumentedSender{provider: provider, from: cfg.From, next: sender}, nil
+}
+
+var (
+	// Mail delivery counter
+	deliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mail_deliveries_total",
+			Help: "Total number of email delivery attempts",
+		},
+		[]string{"provider", "status"},
+	)
+
+	// Mail delivery duration histogram
+	deliveryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "mail_delivery_duration_seconds",
+			Help:    "Email delivery attempt duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"provider"},
+	)
+)
+
+// instrumentedSender fills in the sender address and records delivery metrics
+type instrumentedSender struct {
+	provider string
+	from     string
+	next     Sender
+}
+
+// Send implements Sender
+func (s *instrumentedSender) Send(ctx context.Context, msg *Message) error {
+	if len(msg.To) == 0 {
+		return ErrNoRecipients
+	}
+	if msg.From == "" {
+		copied := *msg
+		copied.From = s.from
+		msg = &copied
+	}
+
+	start := time.Now()
+	err := s.next.Send(ctx, msg)
+	deliveryDuration.WithLabelValues(s.provider).Observe(time.Since(start).Seconds())
+
+	status := "sent"
+	if err != nil {
+		status = "failed"
+	}
+	deliveriesTotal.WithLabelValues(s.provider, status).Inc()
+	return err
+}
@@ -0,0 +1,166 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+)
+
+// Template file suffixes, e.g. application_deleted.subject.tmpl
+const (
+	subjectSuffix = ".subject.tmpl"
+	textSuffix    = ".txt.tmpl"
+	htmlSuffix    = ".html.tmpl"
+)
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// ErrTemplateNotFound is returned when no template with the requested name exists
+var ErrTemplateNotFound = errors.New("mail template not found")
+
+// Content is a rendered email
+type Content struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Templates renders email templates. Every template has a subject and a text and/or
+// HTML body; templates for a language live under a <language>/ directory and fall
+// back to the top-level templates of the default language. Templates in the
+// configured directory take precedence over the built-in ones.
+type Templates struct {
+	sources    []fs.FS
+	translator i18n.Translator
+
+	cache sync.Map // name+"|"+lang -> *parsedTemplate
+}
+
+// parsedTemplate holds the parsed parts of a template for one language
+type parsedTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+// NewTemplates creates a template renderer that loads templates from dir, if set,
+// and from the built-in templates. The t template function translates i18n keys.
+func NewTemplates(dir string, translator i18n.Translator) *Templates {
+	builtin, _ := fs.Sub(builtinTemplates, "templates")
+	sources := []fs.FS{builtin}
+	if dir != "" {
+		sources = append([]fs.FS{os.DirFS(dir)}, sources...)
+	}
+	return &Templates{sources: sources, translator: translator}
+}
+
+// Render renders the named template in lang with data
+func (t *Templates) Render(name, lang string, data interface{}) (*Content, error) {
+	if lang == "" {
+		lang = i18n.DefaultLanguage
+	}
+	tmpl, err := t.load(name, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &Content{}
+	var buf bytes.Buffer
+	if err := tmpl.subject.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render subject of mail template %s: %w", name, err)
+	}
+	content.Subject = strings.TrimSpace(buf.String())
+	if tmpl.text != nil {
+		buf.Reset()
+		if err := tmpl.text.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render text of mail template %s: %w", name, err)
+		}
+		content.Text = buf.String()
+	}
+	if tmpl.html != nil {
+		buf.Reset()
+		if err := tmpl.html.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render html of mail template %s: %w", name, err)
+		}
+		content.HTML = buf.String()
+	}
+	return content, nil
+}
+
+// load parses the named template for lang, caching the result
+func (t *Templates) load(name, lang string) (*parsedTemplate, error) {
+	key := name + "|" + lang
+	if cached, ok := t.cache.Load(key); ok {
+		return cached.(*parsedTemplate), nil
+	}
+
+	funcs := map[string]interface{}{
+		"t": func(key string, args ...interface{}) string {
+			if t.translator == nil {
+				return key
+			}
+			return t.translator.TranslateWithLang(lang, key, args...)
+		},
+	}
+
+	subject, ok, err := t.read(name+subjectSuffix, lang)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrTemplateNotFound, name)
+	}
+	tmpl := &parsedTemplate{}
+	if tmpl.subject, err = texttemplate.New(name + subjectSuffix).Funcs(funcs).Parse(subject); err != nil {
+		return nil, fmt.Errorf("failed to parse mail template %s: %w", name+subjectSuffix, err)
+	}
+
+	if text, ok, err := t.read(name+textSuffix, lang); err != nil {
+		return nil, err
+	} else if ok {
+		if tmpl.text, err = texttemplate.New(name + textSuffix).Funcs(funcs).Parse(text); err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", name+textSuffix, err)
+		}
+	}
+	if html, ok, err := t.read(name+htmlSuffix, lang); err != nil {
+		return nil, err
+	} else if ok {
+		if tmpl.html, err = htmltemplate.New(name + htmlSuffix).Funcs(funcs).Parse(html); err != nil {
+			return nil, fmt.Errorf("failed to parse mail template %s: %w", name+htmlSuffix, err)
+		}
+	}
+	if tmpl.text == nil && tmpl.html == nil {
+		return nil, fmt.Errorf("mail template %s has neither a text nor an html body", name)
+	}
+
+	t.cache.Store(key, tmpl)
+	return tmpl, nil
+}
+
+// read returns the first template file found, trying the language directory
+// before the top-level templates in each source
+func (t *Templates) read(file, lang string) (string, bool, error) {
+	for _, source := range t.sources {
+		for _, p := range []string{path.Join(lang, file), file} {
+			data, err := fs.ReadFile(source, p)
+			if err == nil {
+				return string(data), true, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", false, fmt.Errorf("failed to read mail template %s: %w", p, err)
+			}
+		}
+	}
+	return "", false, nil
+}
@@ -2,11 +2,15 @@ package middleware
 
 import (
 	"context"
+	"fmt"
+	"net"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
@@ -94,11 +98,20 @@ func (h *chainHandler) Handle(ctx context.Context, req *http.Request) (*http.Res
 	return h.chain.middlewares[h.index].Handle(ctx, req, h)
 }
 
+// ginContextKey is the context key under which GinMiddleware stores the gin.Context
+type ginContextKey struct{}
+
+// GinContext returns the gin.Context carried by ctx, if the request is served through GinMiddleware
+func GinContext(ctx context.Context) (*gin.Context, bool) {
+	c, ok := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c, ok
+}
+
 // GinMiddleware adapts a Middleware to gin.HandlerFunc
 func GinMiddleware(middleware Middleware) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Create context
-		ctx := c.Request.Context()
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
 
 		// Create gin handler wrapper
 		handler := &ginHandler{c: c}
@@ -126,6 +139,8 @@ type ginHandler struct {
 
 // Handle continues with the next Gin handler
 func (h *ginHandler) Handle(ctx context.Context, req *http.Request) (*http.Response, error) {
+	// Propagate values added by the middleware to the remaining handlers
+	h.c.Request = req.WithContext(ctx)
 	h.c.Next()
 
 	// Check if there were any errors
@@ -273,33 +288,139 @@ func (m *CORSMiddleware) Handle(ctx context.Context, req *http.Request, next Han
 	return next.Handle(ctx, req)
 }
 
+// DefaultRequestIDHeader is the header used to propagate request IDs
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of request IDs accepted from upstream
+const maxRequestIDLength = 128
+
+// RequestIDConfig defines request ID middleware configuration
+type RequestIDConfig struct {
+	// Header is the request/response header carrying the request ID
+	Header string `json:"header"`
+	// TrustedProxies lists IPs or CIDRs whose incoming request IDs are accepted
+	TrustedProxies []string `json:"trusted_proxies"`
+}
+
 // RequestIDMiddleware implements request ID generation
 type RequestIDMiddleware struct {
 	*BaseMiddleware
+	header  string
+	trusted []*net.IPNet
 }
 
 // NewRequestIDMiddleware creates a new request ID middleware
 func NewRequestIDMiddleware() *RequestIDMiddleware {
+	return NewRequestIDMiddlewareWithConfig(RequestIDConfig{})
+}
+
+// NewRequestIDMiddlewareWithConfig creates a request ID middleware with the given configuration.
+// Invalid trusted proxy entries are logged and ignored.
+func NewRequestIDMiddlewareWithConfig(config RequestIDConfig) *RequestIDMiddleware {
+	header := config.Header
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+
+	trusted := make([]*net.IPNet, 0, len(config.TrustedProxies))
+	for _, proxy := range config.TrustedProxies {
+		ipNet, err := parseIPNet(proxy)
+		if err != nil {
+			logger.Warn("Ignoring invalid trusted proxy %q: %v", proxy, err)
+			continue
+		}
+		trusted = append(trusted, ipNet)
+	}
+
 	return &RequestIDMiddleware{
 		BaseMiddleware: NewBaseMiddleware("request_id", 5),
+		header:         header,
+		trusted:        trusted,
 	}
 }
 
 // Handle processes the request with request ID
 func (m *RequestIDMiddleware) Handle(ctx context.Context, req *http.Request, next Handler) (*http.Response, error) {
-	// Generate request ID (simplified)
-	requestID := generateRequestID()
+	requestID := ""
+	if m.isTrusted(req.RemoteAddr) {
+		requestID = sanitizeRequestID(req.Header.Get(m.header))
+	}
+	if requestID == "" {
+		requestID = generateRequestID()
+	}
 
 	// Add to context
-	ctx = context.WithValue(ctx, "request_id", requestID)
+	ctx = context.WithValue(ctx, logger.FieldRequestID, requestID)
+	if c, ok := GinContext(ctx); ok {
+		c.Set("request_id", requestID)
+		c.Header(m.header, requestID)
+	}
 
 	return next.Handle(ctx, req)
 }
 
-// generateRequestID generates a unique request ID
+// isTrusted reports whether the remote address belongs to a trusted proxy
+func (m *RequestIDMiddleware) isTrusted(remoteAddr string) bool {
+	if len(m.trusted) == 0 {
+		return false
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+
+	for _, ipNet := range m.trusted {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIPNet parses an IP or CIDR into an IPNet
+func parseIPNet(value string) (*net.IPNet, error) {
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid IP address")
+		}
+		bits := 128
+		if ip.To4() != nil {
+			ip = ip.To4()
+			bits = 32
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(value)
+	return ipNet, err
+}
+
+// sanitizeRequestID rejects request IDs that are too long or contain unsafe characters
+func sanitizeRequestID(id string) string {
+	if id == "" || len(id) > maxRequestIDLength {
+		return ""
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return ""
+		}
+	}
+	return id
+}
+
+// generateRequestID generates a unique, time-ordered request ID (UUIDv7)
 func generateRequestID() string {
-	// Simplified implementation - in real usage, use UUID or similar
-	return "req_" + time.Now().Format("20060102150405")
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
 }
 
 // MiddlewareConfig defines middleware configuration
@@ -47,9 +47,46 @@ func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
 	return r.client.Get(ctx, key).Result()
 }
 
-// Delete removes a key
-func (r *RedisClient) Delete(ctx context.Context, key string) error {
-	return r.client.Del(ctx, key).Err()
+// GetInt64 retrieves an integer value by key, returning zero when the key does not exist
+func (r *RedisClient) GetInt64(ctx context.Context, key string) (int64, error) {
+	value, err := r.client.Get(ctx, key).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	return value, err
+}
+
+// GetBytes retrieves a value by key, returning nil when the key does not exist
+func (r *RedisClient) GetBytes(ctx context.Context, key string) ([]byte, error) {
+	value, err := r.client.Get(ctx, key).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	return value, err
+}
+
+// Incr increments a counter without expiration and returns the new value
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return r.client.Incr(ctx, key).Result()
+}
+
+// IncrExpireAt increments a counter and sets it to expire at the given time in a single transaction
+func (r *RedisClient) IncrExpireAt(ctx context.Context, key string, expireAt time.Time) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireAt(ctx, key, expireAt)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Delete removes keys
+func (r *RedisClient) Delete(ctx context.Context, keys ...string) error {
+	return r.client.Del(ctx, keys...).Err()
 }
 
 // Exists checks if a key exists
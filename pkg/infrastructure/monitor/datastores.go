@@ -0,0 +1,122 @@
+package monitor
+
+import (
+	"context"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// datastoreUp reports the result of the last health check of each datastore
+	datastoreUp = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "datastore_up",
+			Help: "Whether the last health check of the datastore succeeded",
+		},
+		[]string{"datastore"},
+	)
+
+	// datastorePoolConnections reports the connection pool of each datastore
+	datastorePoolConnections = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "datastore_pool_connections",
+			Help: "Connections of the datastore pool by state",
+		},
+		[]string{"datastore", "state"},
+	)
+)
+
+// DatastoreProbe checks the health of every datastore of a Router, or of a
+// single datastore, every interval and exports the results with the
+// statistics of their connection pools, labelled with the datastore name
+type DatastoreProbe struct {
+	stores   []datastore.NamedDatastore
+	interval time.Duration
+	failing  map[string]bool // datastores whose last health check failed
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewDatastoreProbe creates a probe of the datastores of store
+func NewDatastoreProbe(store datastore.DatastoreInterface, interval time.Duration) *DatastoreProbe {
+	return &DatastoreProbe{
+		stores:   datastore.Datastores(store),
+		interval: interval,
+		failing:  make(map[string]bool),
+	}
+}
+
+// OnStart probes the datastores once and then every interval in the background
+func (p *DatastoreProbe) OnStart(ctx context.Context) error {
+	p.probe()
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+	go p.run(runCtx)
+	return nil
+}
+
+// OnStop stops probing, waiting up to the deadline of ctx
+func (p *DatastoreProbe) OnStop(ctx context.Context) error {
+	if p.cancel == nil {
+		return nil
+	}
+	p.cancel()
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run probes the datastores every interval
+func (p *DatastoreProbe) run(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			p.probe()
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// probe checks the health of every datastore and records its pool statistics.
+// Health changes are logged.
+func (p *DatastoreProbe) probe() {
+	for _, named := range p.stores {
+		err := named.Store.HealthCheck()
+		switch {
+		case err != nil && !p.failing[named.Name]:
+			logger.Warn("Datastore %s failed its health check: %v", named.Name, err)
+		case err == nil && p.failing[named.Name]:
+			logger.Info("Datastore %s is healthy again", named.Name)
+		}
+		p.failing[named.Name] = err != nil
+		if err != nil {
+			datastoreUp.WithLabelValues(named.Name).Set(0)
+		} else {
+			datastoreUp.WithLabelValues(named.Name).Set(1)
+		}
+
+		provider, ok := named.Store.(datastore.StatsProvider)
+		if !ok {
+			continue
+		}
+		if conn := provider.Stats().Connections; conn != nil {
+			datastorePoolConnections.WithLabelValues(named.Name, "in_use").Set(float64(conn.InUse))
+			datastorePoolConnections.WithLabelValues(named.Name, "idle").Set(float64(conn.Idle))
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/push"
+	prombridge "go.opentelemetry.io/contrib/bridges/prometheus"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/resource"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// Metrics export modes
+const (
+	// MetricsModePull serves the metrics for scraping
+	MetricsModePull = "pull"
+	// MetricsModePush pushes the metrics to a Pushgateway
+	MetricsModePush = "push"
+	// MetricsModeOTLP exports the metrics to an OTLP/HTTP collector
+	MetricsModeOTLP = "otlp"
+)
+
+var metricsExportsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "metrics_exports_total",
+		Help: "Total number of metrics pushes and exports by mode and status",
+	},
+	[]string{"mode", "status"},
+)
+
+// MetricsExporter periodically sends the metrics of a Prometheus registry to
+// a backend that does not scrape them, and sends them a last time on stop
+type MetricsExporter interface {
+	OnStart(ctx context.Context) error
+	OnStop(ctx context.Context) error
+}
+
+// NewMetricsExporter creates the exporter of the default Prometheus registry
+// selected by the metrics mode. It returns nil in pull mode or when metrics
+// are disabled.
+func NewMetricsExporter(cfg *config.Config) (MetricsExporter, error) {
+	prom := cfg.Monitor.Prometheus
+	if !prom.Enabled {
+		return nil, nil
+	}
+
+	switch prom.Mode {
+	case MetricsModePull, "":
+		return nil, nil
+	case MetricsModePush:
+		return NewPushExporter(&prom.Push, cfg.App.Name, prometheus.DefaultGatherer), nil
+	case MetricsModeOTLP:
+		return NewOTLPExporter(&prom.OTLP, &cfg.App, prometheus.DefaultGatherer)
+	default:
+		return nil, fmt.Errorf("unknown metrics mode: %s", prom.Mode)
+	}
+}
+
+// PushExporter pushes metrics to a Pushgateway every interval
+type PushExporter struct {
+	pusher *push.Pusher
+	cfg    config.PushGatewayConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPushExporter creates an exporter pushing the metrics of gatherer under
+// job, unless the configuration names another job
+func NewPushExporter(cfg *config.PushGatewayConfig, job string, gatherer prometheus.Gatherer) *PushExporter {
+	if cfg.Job != "" {
+		job = cfg.Job
+	}
+
+	pusher := push.New(cfg.URL, job).
+		Gatherer(gatherer).
+		Client(&http.Client{Timeout: cfg.Timeout})
+	if _, ok := cfg.Grouping["instance"]; !ok {
+		pusher.Grouping("instance", hostname())
+	}
+	for name, value := range cfg.Grouping {
+		pusher.Grouping(name, value)
+	}
+	if cfg.Username != "" {
+		pusher.BasicAuth(cfg.Username, cfg.Password)
+	}
+
+	return &PushExporter{pusher: pusher, cfg: *cfg}
+}
+
+// OnStart starts pushing in the background. An unreachable Pushgateway does
+// not prevent the server from starting.
+func (e *PushExporter) OnStart(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.done = make(chan struct{})
+	go e.run(runCtx)
+	logger.Info("Pushing metrics to %s every %s", e.cfg.URL, e.cfg.Interval)
+	return nil
+}
+
+// OnStop stops pushing and pushes a last time, or deletes the group when
+// DeleteOnShutdown is set, waiting up to the deadline of ctx
+func (e *PushExporter) OnStop(ctx context.Context) error {
+	if e.cancel == nil {
+		return nil
+	}
+	e.cancel()
+	select {
+	case <-e.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if e.cfg.DeleteOnShutdown {
+		if err := e.pusher.Delete(); err != nil {
+			return fmt.Errorf("failed to delete metrics from pushgateway: %w", err)
+		}
+		return nil
+	}
+	if err := e.push(ctx); err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	return nil
+}
+
+// run pushes every interval until ctx is done
+func (e *PushExporter) run(ctx context.Context) {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := e.push(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to push metrics: %v", err)
+		}
+	}
+}
+
+// push replaces the metrics of the group on the Pushgateway
+func (e *PushExporter) push(ctx context.Context) error {
+	err := e.pusher.PushContext(ctx)
+	metricsExportsTotal.WithLabelValues(MetricsModePush, exportStatus(err)).Inc()
+	return err
+}
+
+// OTLPExporter exports metrics to an OTLP/HTTP collector every interval
+type OTLPExporter struct {
+	provider *sdkmetric.MeterProvider
+	exporter sdkmetric.Exporter
+	cfg      config.OTLPMetricsConfig
+	resource *resource.Resource
+	producer sdkmetric.Producer
+}
+
+// NewOTLPExporter creates an exporter converting the metrics of gatherer to
+// OTLP, describing the service with the application name, version and
+// environment
+func NewOTLPExporter(cfg *config.OTLPMetricsConfig, app *config.AppConfig, gatherer prometheus.Gatherer) (*OTLPExporter, error) {
+	opts := []otlpmetrichttp.Option{
+		otlpmetrichttp.WithEndpointURL(cfg.Endpoint),
+		otlpmetrichttp.WithTimeout(cfg.Timeout),
+	}
+	if len(cfg.Headers) > 0 {
+		opts = append(opts, otlpmetrichttp.WithHeaders(cfg.Headers))
+	}
+	exporter, err := otlpmetrichttp.New(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP metrics exporter: %w", err)
+	}
+
+	return &OTLPExporter{
+		exporter: &countingExporter{Exporter: exporter},
+		cfg:      *cfg,
+		resource: resource.NewWithAttributes(semconv.SchemaURL,
+			semconv.ServiceName(app.Name),
+			semconv.ServiceVersion(app.Version),
+			semconv.ServiceInstanceID(hostname()),
+			semconv.DeploymentEnvironment(app.Env),
+		),
+		producer: prombridge.NewMetricProducer(prombridge.WithGatherer(gatherer)),
+	}, nil
+}
+
+// OnStart starts exporting in the background
+func (e *OTLPExporter) OnStart(ctx context.Context) error {
+	reader := sdkmetric.NewPeriodicReader(e.exporter,
+		sdkmetric.WithInterval(e.cfg.Interval),
+		sdkmetric.WithTimeout(e.cfg.Timeout),
+		sdkmetric.WithProducer(e.producer),
+	)
+	e.provider = sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(e.resource),
+	)
+	logger.Info("Exporting metrics to %s every %s", e.cfg.Endpoint, e.cfg.Interval)
+	return nil
+}
+
+// OnStop exports a last time and shuts the exporter down, waiting up to the
+// deadline of ctx
+func (e *OTLPExporter) OnStop(ctx context.Context) error {
+	if e.provider == nil {
+		return nil
+	}
+	if err := e.provider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to flush OTLP metrics: %w", err)
+	}
+	return nil
+}
+
+// countingExporter counts the exports of an OTLP exporter and logs failures,
+// which the periodic reader otherwise only hands to the OpenTelemetry error
+// handler
+type countingExporter struct {
+	sdkmetric.Exporter
+}
+
+// Export implements sdkmetric.Exporter
+func (e *countingExporter) Export(ctx context.Context, rm *metricdata.ResourceMetrics) error {
+	err := e.Exporter.Export(ctx, rm)
+	metricsExportsTotal.WithLabelValues(MetricsModeOTLP, exportStatus(err)).Inc()
+	if err != nil {
+		logger.Warn("Failed to export metrics: %v", err)
+	}
+	return err
+}
+
+// exportStatus returns the status label of an export result
+func exportStatus(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "success"
+}
+
+// hostname returns the hostname identifying this instance
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil || name == "" {
+		return "unknown"
+	}
+	return name
+}
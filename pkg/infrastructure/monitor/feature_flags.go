@@ -0,0 +1,22 @@
+package monitor
+
+import (
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// featureFlagEvaluations counts feature flag evaluations by flag and result
+var featureFlagEvaluations = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "feature_flag_evaluations_total",
+		Help: "Total number of feature flag evaluations",
+	},
+	[]string{"flag", "enabled"},
+)
+
+// RecordFeatureFlagEvaluation records the result of a feature flag evaluation
+func RecordFeatureFlagEvaluation(flag string, enabled bool) {
+	featureFlagEvaluations.WithLabelValues(flag, strconv.FormatBool(enabled)).Inc()
+}
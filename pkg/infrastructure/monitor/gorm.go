@@ -0,0 +1,77 @@
+package monitor
+
+import (
+	"errors"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"gorm.io/gorm"
+)
+
+// gormStartKey is the statement setting holding the start of an operation
+const gormStartKey = "monitor:start"
+
+// GormPlugin records the duration and errors of every GORM operation in a
+// monitor, under the operations create, query, update, delete, row and raw
+type GormPlugin struct {
+	monitor datastore.Monitor
+}
+
+// NewGormPlugin creates a plugin recording GORM operations in monitor, install
+// it with db.Use
+func NewGormPlugin(monitor datastore.Monitor) *GormPlugin {
+	return &GormPlugin{monitor: monitor}
+}
+
+// Name implements gorm.Plugin
+func (p *GormPlugin) Name() string {
+	return "datastore_monitor"
+}
+
+// Initialize implements gorm.Plugin
+func (p *GormPlugin) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	return errors.Join(
+		callbacks.Create().Before("gorm:create").Register("monitor:before_create", p.start),
+		callbacks.Create().After("gorm:create").Register("monitor:after_create", p.record("create")),
+		callbacks.Query().Before("gorm:query").Register("monitor:before_query", p.start),
+		callbacks.Query().After("gorm:query").Register("monitor:after_query", p.record("query")),
+		callbacks.Update().Before("gorm:update").Register("monitor:before_update", p.start),
+		callbacks.Update().After("gorm:update").Register("monitor:after_update", p.record("update")),
+		callbacks.Delete().Before("gorm:delete").Register("monitor:before_delete", p.start),
+		callbacks.Delete().After("gorm:delete").Register("monitor:after_delete", p.record("delete")),
+		callbacks.Row().Before("gorm:row").Register("monitor:before_row", p.start),
+		callbacks.Row().After("gorm:row").Register("monitor:after_row", p.record("row")),
+		callbacks.Raw().Before("gorm:raw").Register("monitor:before_raw", p.start),
+		callbacks.Raw().After("gorm:raw").Register("monitor:after_raw", p.record("raw")),
+	)
+}
+
+// start stores the start time of the operation in the statement
+func (p *GormPlugin) start(db *gorm.DB) {
+	db.InstanceSet(gormStartKey, time.Now())
+}
+
+// record returns a callback recording the duration and error of operation.
+// A missing record is not counted as an error.
+func (p *GormPlugin) record(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(gormStartKey)
+		if !ok {
+			return
+		}
+		start, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		p.monitor.RecordQuery(operation, table, time.Since(start))
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			p.monitor.RecordError(operation, table, datastore.TranslateGormError(db.Error))
+		}
+	}
+}
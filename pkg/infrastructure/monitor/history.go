@@ -0,0 +1,382 @@
+package monitor
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// History persists the operation stats of a performance monitor as samples in
+// the datastore_metrics table, so that latency trends survive restarts. Every
+// instance writes and rolls up its own samples; queries sum all instances.
+type History struct {
+	monitor  *PerformanceMonitor
+	store    datastore.DatastoreInterface
+	cfg      config.HistoryConfig
+	clock    clock.Clock
+	instance string
+
+	since  time.Time // start of the current interval
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewHistory creates a history of the operations recorded by its monitor,
+// see Monitor, stored in store
+func NewHistory(store datastore.DatastoreInterface, cfg *config.HistoryConfig, clk clock.Clock) *History {
+	return &History{
+		monitor:  newPerformanceMonitor(clk),
+		store:    store,
+		cfg:      *cfg,
+		clock:    clk,
+		instance: hostname(),
+		since:    clk.Now(),
+	}
+}
+
+// Monitor returns the monitor whose stats are persisted, e.g. for NewGormPlugin
+func (h *History) Monitor() *PerformanceMonitor {
+	return h.monitor
+}
+
+// OnStart starts persisting samples in the background
+func (h *History) OnStart(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.done = make(chan struct{})
+	go h.run(runCtx)
+	logger.Info("Datastore history started, sampling every %s", h.cfg.Interval)
+	return nil
+}
+
+// OnStop stops persisting samples after writing the current interval, waiting
+// up to the deadline of ctx
+func (h *History) OnStop(ctx context.Context) error {
+	if h.cancel == nil {
+		return nil
+	}
+	h.cancel()
+	select {
+	case <-h.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if err := h.Record(ctx); err != nil {
+		logger.Warn("Failed to record the last datastore history samples: %v", err)
+	}
+	return nil
+}
+
+// run records samples every interval and rolls them up every rollup interval
+func (h *History) run(ctx context.Context) {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.cfg.Interval)
+	defer ticker.Stop()
+	var lastRollup time.Time
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+
+		if err := h.Record(ctx); err != nil {
+			logger.Warn("Failed to record datastore history samples: %v", err)
+		}
+		if h.clock.Now().Sub(lastRollup) >= h.cfg.RollupInterval {
+			if err := h.Rollup(ctx); err != nil {
+				logger.Warn("Failed to roll up datastore history samples: %v", err)
+			}
+			lastRollup = h.clock.Now()
+		}
+	}
+}
+
+// Record stores the activity since the previous call as raw samples
+func (h *History) Record(ctx context.Context) error {
+	repo, err := datastore.NewRepository[*model.DatastoreMetric](h.store)
+	if err != nil {
+		return err
+	}
+
+	bucket := h.since.UTC().Truncate(time.Second)
+	h.since = h.clock.Now()
+	for _, sample := range h.monitor.TakeSamples() {
+		_, err := repo.Create(ctx, &model.DatastoreMetric{
+			Instance:    h.instance,
+			Resolution:  model.MetricResolutionRaw,
+			Operation:   sample.Operation,
+			Table:       sample.Table,
+			BucketStart: bucket,
+			Count:       sample.Count,
+			TotalTime:   int64(sample.TotalTime),
+			MaxTime:     int64(sample.MaxTime),
+			Errors:      sample.Errors,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollup merges the raw samples of this instance older than the raw retention
+// into hourly samples and deletes the hourly samples older than the rollup
+// retention
+func (h *History) Rollup(ctx context.Context) error {
+	repo, err := datastore.NewRepository[*model.DatastoreMetric](h.store)
+	if err != nil {
+		return err
+	}
+
+	cutoff := h.clock.Now().Add(-h.cfg.RawRetention)
+	for ctx.Err() == nil {
+		samples, err := repo.List(ctx, datastore.ListOptions{
+			Size:    h.cfg.BatchSize,
+			SortBy:  "bucket_start",
+			Filters: map[string]interface{}{"resolution": model.MetricResolutionRaw, "instance": h.instance},
+		})
+		if err != nil {
+			return err
+		}
+
+		expired := samples
+		for i, sample := range samples {
+			if !sample.BucketStart.Before(cutoff) {
+				expired = samples[:i]
+				break
+			}
+		}
+		if err := h.merge(ctx, repo, expired); err != nil {
+			return err
+		}
+		if len(expired) < h.cfg.BatchSize {
+			break
+		}
+	}
+
+	if h.cfg.RollupRetention <= 0 {
+		return nil
+	}
+	return h.expire(ctx, repo, h.clock.Now().Add(-h.cfg.RollupRetention))
+}
+
+// merge adds raw samples to the hourly samples of their hour and deletes them
+func (h *History) merge(ctx context.Context, repo datastore.Repository[*model.DatastoreMetric], raw []*model.DatastoreMetric) error {
+	hourly := make(map[seriesPoint]*model.DatastoreMetric)
+	for _, sample := range raw {
+		key := seriesPoint{sample.Operation, sample.Table, sample.BucketStart.UTC().Truncate(time.Hour)}
+		rollup, ok := hourly[key]
+		if !ok {
+			existing, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: map[string]interface{}{
+				"resolution":   model.MetricResolutionHour,
+				"instance":     h.instance,
+				"operation":    key.operation,
+				"table_name":   key.table,
+				"bucket_start": key.bucket,
+			}})
+			if err != nil {
+				return err
+			}
+			if len(existing) > 0 {
+				rollup = existing[0]
+			} else {
+				rollup = &model.DatastoreMetric{
+					Instance:    h.instance,
+					Resolution:  model.MetricResolutionHour,
+					Operation:   key.operation,
+					Table:       key.table,
+					BucketStart: key.bucket,
+				}
+			}
+			hourly[key] = rollup
+		}
+		rollup.Count += sample.Count
+		rollup.TotalTime += sample.TotalTime
+		rollup.MaxTime = max(rollup.MaxTime, sample.MaxTime)
+		rollup.Errors += sample.Errors
+	}
+
+	for _, rollup := range hourly {
+		var err error
+		if rollup.ID == 0 {
+			_, err = repo.Create(ctx, rollup)
+		} else {
+			_, err = repo.Update(ctx, rollup)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, sample := range raw {
+		if err := repo.Delete(ctx, sample.ID); err != nil && !errors.Is(err, datastore.ErrNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// expire deletes the hourly samples of every instance older than cutoff
+func (h *History) expire(ctx context.Context, repo datastore.Repository[*model.DatastoreMetric], cutoff time.Time) error {
+	for ctx.Err() == nil {
+		samples, err := repo.List(ctx, datastore.ListOptions{
+			Size:    h.cfg.BatchSize,
+			SortBy:  "bucket_start",
+			Filters: map[string]interface{}{"resolution": model.MetricResolutionHour},
+		})
+		if err != nil {
+			return err
+		}
+
+		deleted := 0
+		for _, sample := range samples {
+			if !sample.BucketStart.Before(cutoff) {
+				return nil
+			}
+			// Another instance may have deleted it first
+			if err := repo.Delete(ctx, sample.ID); err != nil && !errors.Is(err, datastore.ErrNotFound) {
+				return err
+			}
+			deleted++
+		}
+		if deleted < h.cfg.BatchSize {
+			return nil
+		}
+	}
+	return ctx.Err()
+}
+
+// HistoryQuery selects the samples of a history query
+type HistoryQuery struct {
+	// Operation and Table restrict the series when not empty
+	Operation string
+	Table     string
+	// Since and Until bound the bucket start times when not zero
+	Since time.Time
+	Until time.Time
+	// Resolution is MetricResolutionRaw or MetricResolutionHour. Hourly
+	// points include the raw samples not rolled up yet.
+	Resolution string
+	// MaxPoints bounds the samples read, the newest ones are kept
+	MaxPoints int
+}
+
+// HistoryPoint is the activity of an operation during one bucket, summed over instances
+type HistoryPoint struct {
+	Timestamp   time.Time
+	Count       int64
+	AverageTime time.Duration
+	MaxTime     time.Duration
+	Errors      int64
+}
+
+// HistorySeries is the latency history of one operation on one table
+type HistorySeries struct {
+	Operation string
+	Table     string
+	Points    []HistoryPoint
+}
+
+// seriesPoint identifies a bucket of a series
+type seriesPoint struct {
+	operation string
+	table     string
+	bucket    time.Time
+}
+
+// QueryHistory returns the series of the samples stored in store matching q,
+// sorted by operation and table, with points in time order
+func QueryHistory(ctx context.Context, store datastore.DatastoreInterface, q HistoryQuery) ([]HistorySeries, error) {
+	repo, err := datastore.NewRepository[*model.DatastoreMetric](store)
+	if err != nil {
+		return nil, err
+	}
+
+	resolutions := []string{model.MetricResolutionRaw}
+	if q.Resolution == model.MetricResolutionHour {
+		resolutions = append(resolutions, model.MetricResolutionHour)
+	}
+
+	points := make(map[seriesPoint]*model.DatastoreMetric)
+	for _, resolution := range resolutions {
+		filters := map[string]interface{}{"resolution": resolution}
+		if q.Operation != "" {
+			filters["operation"] = q.Operation
+		}
+		if q.Table != "" {
+			filters["table_name"] = q.Table
+		}
+		samples, err := repo.List(ctx, datastore.ListOptions{
+			Size:     q.MaxPoints,
+			SortBy:   "bucket_start",
+			SortDesc: true,
+			Filters:  filters,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, sample := range samples {
+			if !q.Since.IsZero() && sample.BucketStart.Before(q.Since) {
+				break
+			}
+			if !q.Until.IsZero() && !sample.BucketStart.Before(q.Until) {
+				continue
+			}
+			bucket := sample.BucketStart.UTC()
+			if q.Resolution == model.MetricResolutionHour {
+				bucket = bucket.Truncate(time.Hour)
+			}
+			key := seriesPoint{sample.Operation, sample.Table, bucket}
+			point, ok := points[key]
+			if !ok {
+				point = &model.DatastoreMetric{}
+				points[key] = point
+			}
+			point.Count += sample.Count
+			point.TotalTime += sample.TotalTime
+			point.MaxTime = max(point.MaxTime, sample.MaxTime)
+			point.Errors += sample.Errors
+		}
+	}
+
+	index := make(map[[2]string]int)
+	var series []HistorySeries
+	for key, point := range points {
+		i, ok := index[[2]string{key.operation, key.table}]
+		if !ok {
+			i = len(series)
+			index[[2]string{key.operation, key.table}] = i
+			series = append(series, HistorySeries{Operation: key.operation, Table: key.table})
+		}
+		p := HistoryPoint{
+			Timestamp: key.bucket,
+			Count:     point.Count,
+			MaxTime:   time.Duration(point.MaxTime),
+			Errors:    point.Errors,
+		}
+		if point.Count > 0 {
+			p.AverageTime = time.Duration(point.TotalTime / point.Count)
+		}
+		series[i].Points = append(series[i].Points, p)
+	}
+
+	sort.Slice(series, func(i, j int) bool {
+		if series[i].Operation != series[j].Operation {
+			return series[i].Operation < series[j].Operation
+		}
+		return series[i].Table < series[j].Table
+	})
+	for _, s := range series {
+		sort.Slice(s.Points, func(i, j int) bool { return s.Points[i].Timestamp.Before(s.Points[j].Timestamp) })
+	}
+	return series, nil
+}
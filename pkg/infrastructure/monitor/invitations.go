@@ -0,0 +1,40 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// invitationsTotal counts invitations created, accepted and revoked, and the
+	// attempts to accept expired ones; accepted over created is the conversion rate
+	invitationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "invitations_total",
+			Help: "Total number of invitations by outcome",
+		},
+		[]string{"status"},
+	)
+
+	// invitationAcceptDelay measures how long invitations waited to be accepted
+	invitationAcceptDelay = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "invitation_accept_delay_seconds",
+			Help:    "Time from the creation of an invitation to its acceptance in seconds",
+			Buckets: []float64{60, 300, 900, 3600, 4 * 3600, 12 * 3600, 24 * 3600, 72 * 3600, 7 * 24 * 3600},
+		},
+	)
+)
+
+// RecordInvitation records an invitation reaching status
+func RecordInvitation(status string) {
+	invitationsTotal.WithLabelValues(status).Inc()
+}
+
+// RecordInvitationAccepted records the acceptance of an invitation created delay ago
+func RecordInvitationAccepted(delay time.Duration) {
+	invitationsTotal.WithLabelValues("accepted").Inc()
+	invitationAcceptDelay.Observe(delay.Seconds())
+}
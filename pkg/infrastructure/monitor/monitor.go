@@ -2,42 +2,20 @@ package monitor
 
 import (
 	"context"
+	"errors"
 	"sync"
 	"time"
 
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
-// PerformanceMonitor implements the Monitor interface
-type PerformanceMonitor struct {
-	queryDuration    *prometheus.HistogramVec
-	connectionGauge  *prometheus.GaugeVec
-	errorCounter     *prometheus.CounterVec
-	operationCounter *prometheus.CounterVec
-	mutex            sync.RWMutex
-	stats            map[string]*operationStats
-}
-
-// operationStats holds statistics for database operations
-type operationStats struct {
-	Count        int64         `json:"count"`
-	TotalTime    time.Duration `json:"total_time"`
-	AverageTime  time.Duration `json:"average_time"`
-	LastExecuted time.Time     `json:"last_executed"`
-	Errors       int64         `json:"errors"`
-}
-
-// NewPerformanceMonitor creates a new performance monitor
-func NewPerformanceMonitor() datastore.Monitor {
-	monitor := &PerformanceMonitor{
-		stats: make(map[string]*operationStats),
-	}
-
-	// Initialize Prometheus metrics
-	monitor.queryDuration = promauto.NewHistogramVec(
+var (
+	// Query duration histogram
+	queryDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "datastore_query_duration_seconds",
 			Help:    "Time spent executing datastore queries",
@@ -46,7 +24,8 @@ func NewPerformanceMonitor() datastore.Monitor {
 		[]string{"operation", "table"},
 	)
 
-	monitor.connectionGauge = promauto.NewGaugeVec(
+	// Connection gauge
+	connectionGauge = promauto.NewGaugeVec(
 		prometheus.GaugeOpts{
 			Name: "datastore_connections",
 			Help: "Number of active database connections",
@@ -54,7 +33,8 @@ func NewPerformanceMonitor() datastore.Monitor {
 		[]string{"database"},
 	)
 
-	monitor.errorCounter = promauto.NewCounterVec(
+	// Error counter
+	errorCounter = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "datastore_errors_total",
 			Help: "Total number of datastore errors",
@@ -62,15 +42,65 @@ func NewPerformanceMonitor() datastore.Monitor {
 		[]string{"operation", "table", "error_type"},
 	)
 
-	monitor.operationCounter = promauto.NewCounterVec(
+	// Operation counter
+	operationCounter = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "datastore_operations_total",
 			Help: "Total number of datastore operations",
 		},
 		[]string{"operation", "table"},
 	)
+)
+
+// PerformanceMonitor implements the Monitor interface. The Prometheus metrics
+// are shared by all monitors of the process.
+type PerformanceMonitor struct {
+	queryDuration    *prometheus.HistogramVec
+	connectionGauge  *prometheus.GaugeVec
+	errorCounter     *prometheus.CounterVec
+	operationCounter *prometheus.CounterVec
+	mutex            sync.RWMutex
+	stats            map[string]*operationStats
+	clock            clock.Clock
+}
+
+// operationStats holds statistics for database operations
+type operationStats struct {
+	Count        int64         `json:"count"`
+	TotalTime    time.Duration `json:"total_time"`
+	AverageTime  time.Duration `json:"average_time"`
+	LastExecuted time.Time     `json:"last_executed"`
+	Errors       int64         `json:"errors"`
+
+	// Activity since the last TakeSamples call
+	interval Sample
+}
+
+// Sample is the activity of one operation on one table during an interval
+type Sample struct {
+	Operation string
+	Table     string
+	Count     int64
+	TotalTime time.Duration
+	MaxTime   time.Duration
+	Errors    int64
+}
 
-	return monitor
+// NewPerformanceMonitor creates a new performance monitor stamping operations with clk
+func NewPerformanceMonitor(clk clock.Clock) datastore.Monitor {
+	return newPerformanceMonitor(clk)
+}
+
+// newPerformanceMonitor creates a performance monitor with the shared metrics
+func newPerformanceMonitor(clk clock.Clock) *PerformanceMonitor {
+	return &PerformanceMonitor{
+		queryDuration:    queryDuration,
+		connectionGauge:  connectionGauge,
+		errorCounter:     errorCounter,
+		operationCounter: operationCounter,
+		stats:            make(map[string]*operationStats),
+		clock:            clk,
+	}
 }
 
 // RecordQuery records a database query execution
@@ -86,14 +116,17 @@ func (m *PerformanceMonitor) RecordQuery(operation, table string, duration time.
 	key := operation + ":" + table
 	stats, exists := m.stats[key]
 	if !exists {
-		stats = &operationStats{}
+		stats = &operationStats{interval: Sample{Operation: operation, Table: table}}
 		m.stats[key] = stats
 	}
 
 	stats.Count++
 	stats.TotalTime += duration
 	stats.AverageTime = stats.TotalTime / time.Duration(stats.Count)
-	stats.LastExecuted = time.Now()
+	stats.LastExecuted = m.clock.Now()
+	stats.interval.Count++
+	stats.interval.TotalTime += duration
+	stats.interval.MaxTime = max(stats.interval.MaxTime, duration)
 
 	// Log slow queries
 	if duration > time.Second {
@@ -111,16 +144,20 @@ func (m *PerformanceMonitor) RecordConnection(database string, connections int)
 func (m *PerformanceMonitor) RecordError(operation, table string, err error) {
 	errorType := "unknown"
 	if err != nil {
-		errorType = err.Error()
-		// Classify common errors
-		switch err {
-		case datastore.ErrNotFound:
+		errorType = "other"
+		// Classify common errors, keeping the label cardinality bounded
+		switch {
+		case errors.Is(err, datastore.ErrNotFound):
 			errorType = "not_found"
-		case datastore.ErrDuplicateKey:
+		case errors.Is(err, datastore.ErrDuplicateKey):
 			errorType = "duplicate_key"
-		case datastore.ErrConnectionFailed:
+		case errors.Is(err, datastore.ErrForeignKeyViolation):
+			errorType = "foreign_key_violation"
+		case errors.Is(err, datastore.ErrSerializationFailure):
+			errorType = "serialization_failure"
+		case errors.Is(err, datastore.ErrConnectionFailed):
 			errorType = "connection_failed"
-		case datastore.ErrTransactionFailed:
+		case errors.Is(err, datastore.ErrTransactionFailed):
 			errorType = "transaction_failed"
 		}
 	}
@@ -135,6 +172,7 @@ func (m *PerformanceMonitor) RecordError(operation, table string, err error) {
 	stats, exists := m.stats[key]
 	if exists {
 		stats.Errors++
+		stats.interval.Errors++
 	}
 
 	logger.Error("Database error: operation=%s, table=%s, error=%v",
@@ -160,20 +198,39 @@ func (m *PerformanceMonitor) GetStats() map[string]interface{} {
 	return result
 }
 
+// TakeSamples returns the activity of every operation since the previous call
+// and starts a new interval. Operations without activity are left out.
+func (m *PerformanceMonitor) TakeSamples() []Sample {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var samples []Sample
+	for _, stats := range m.stats {
+		if stats.interval.Count == 0 && stats.interval.Errors == 0 {
+			continue
+		}
+		samples = append(samples, stats.interval)
+		stats.interval = Sample{Operation: stats.interval.Operation, Table: stats.interval.Table}
+	}
+	return samples
+}
+
 // StatsCollector implements the Stats interface
 type StatsCollector struct {
 	monitor     datastore.Monitor
 	connections map[string]int
 	queries     map[string]int64
 	mutex       sync.RWMutex
+	clock       clock.Clock
 }
 
-// NewStatsCollector creates a new stats collector
-func NewStatsCollector(monitor datastore.Monitor) datastore.Stats {
+// NewStatsCollector creates a new stats collector stamping reports with clk
+func NewStatsCollector(monitor datastore.Monitor, clk clock.Clock) datastore.Stats {
 	return &StatsCollector{
 		monitor:     monitor,
 		connections: make(map[string]int),
 		queries:     make(map[string]int64),
+		clock:       clk,
 	}
 }
 
@@ -185,7 +242,7 @@ func (s *StatsCollector) GetStats() map[string]interface{} {
 	return map[string]interface{}{
 		"connections": s.connections,
 		"queries":     s.queries,
-		"timestamp":   time.Now().Format(time.RFC3339),
+		"timestamp":   s.clock.Now().Format(time.RFC3339),
 	}
 }
 
@@ -198,7 +255,7 @@ func (s *StatsCollector) GetConnectionStats() map[string]interface{} {
 	for db, count := range s.connections {
 		stats[db] = map[string]interface{}{
 			"active_connections": count,
-			"last_updated":       time.Now().Format(time.RFC3339),
+			"last_updated":       s.clock.Now().Format(time.RFC3339),
 		}
 	}
 
@@ -214,7 +271,7 @@ func (s *StatsCollector) GetQueryStats() map[string]interface{} {
 
 	return map[string]interface{}{
 		"total_queries": s.queries,
-		"timestamp":     time.Now().Format(time.RFC3339),
+		"timestamp":     s.clock.Now().Format(time.RFC3339),
 	}
 }
 
@@ -246,10 +303,11 @@ type MonitoredDataStore struct {
 	dbName  string
 }
 
-// NewMonitoredDataStore creates a new monitored data store
-func NewMonitoredDataStore(store datastore.DataStore, dbName string) *MonitoredDataStore {
-	monitor := NewPerformanceMonitor()
-	stats := NewStatsCollector(monitor)
+// NewMonitoredDataStore creates a new monitored data store. Durations are
+// measured with the monotonic clock, timestamps are read from clk.
+func NewMonitoredDataStore(store datastore.DataStore, dbName string, clk clock.Clock) *MonitoredDataStore {
+	monitor := NewPerformanceMonitor(clk)
+	stats := NewStatsCollector(monitor, clk)
 
 	return &MonitoredDataStore{
 		store:   store,
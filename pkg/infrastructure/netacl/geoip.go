@@ -0,0 +1,57 @@
+package netacl
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// CountryLocator returns the ISO 3166-1 alpha-2 country code of an IP address,
+// or an empty string when it is unknown
+type CountryLocator interface {
+	Country(ip net.IP) (string, error)
+	Close() error
+}
+
+// countryDatabase looks countries up in a MaxMind database
+type countryDatabase struct {
+	reader *maxminddb.Reader
+}
+
+// countryRecord holds the fields of a GeoIP2 or GeoLite2 country or city record used by the ACL
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	// RegisteredCountry is used when the database has no country for the network
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// OpenCountryDatabase opens a MaxMind country or city database, e.g. GeoLite2-Country.mmdb
+func OpenCountryDatabase(path string) (CountryLocator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return &countryDatabase{reader: reader}, nil
+}
+
+// Country returns the country of ip
+func (d *countryDatabase) Country(ip net.IP) (string, error) {
+	var record countryRecord
+	if err := d.reader.Lookup(ip, &record); err != nil {
+		return "", err
+	}
+	if record.Country.ISOCode != "" {
+		return record.Country.ISOCode, nil
+	}
+	return record.RegisteredCountry.ISOCode, nil
+}
+
+// Close closes the database
+func (d *countryDatabase) Close() error {
+	return d.reader.Close()
+}
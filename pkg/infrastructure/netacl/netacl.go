@@ -0,0 +1,371 @@
+// Package netacl allows and denies requests by client IP and country. A global
+// rule applies to every request and group rules to the requests under their
+// path prefixes; a request must pass every rule that applies to it. The rules
+// come from the configuration and can be replaced at runtime.
+package netacl
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// GlobalRule names the rule applying to every request
+const GlobalRule = "global"
+
+// Reasons a request is blocked
+const (
+	ReasonIPDenied      = "ip_denied"
+	ReasonCountryDenied = "country_denied"
+	ReasonNotAllowed    = "not_allowed"
+)
+
+// EventTypeRulesUpdated is published when a rule is replaced or a group deleted
+const EventTypeRulesUpdated = "network_acl.updated"
+
+// Errors returned when replacing rules
+var (
+	ErrInvalidRule      = errors.New("invalid network ACL rule")
+	ErrGroupNotFound    = errors.New("network ACL group not found")
+	ErrGeoIPUnavailable = errors.New("country rules require security.network_acl.geoip_database")
+)
+
+var blockedRequestsTotal = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "network_acl_blocked_requests_total",
+		Help: "Total number of requests blocked by the network ACL, by rule and reason",
+	},
+	[]string{"rule", "reason"},
+)
+
+// RulesUpdated is the payload of a rules updated event; Rule is nil when the group was deleted
+type RulesUpdated struct {
+	Name string                 `json:"name"`
+	Rule *config.NetworkACLRule `json:"rule,omitempty"`
+}
+
+// Decision is the outcome of checking a request
+type Decision struct {
+	Allowed bool
+	// Rule and Reason name the rule that blocked the request and why
+	Rule   string
+	Reason string
+	// Country is the ISO code of the client, when a country rule needed it and the lookup found one
+	Country string
+}
+
+// rule is a parsed NetworkACLRule
+type rule struct {
+	source         config.NetworkACLRule
+	allow, deny    []*net.IPNet
+	allowCountries map[string]bool
+	denyCountries  map[string]bool
+}
+
+// Manager holds the rules and checks requests against them
+type Manager struct {
+	enabled   bool
+	countries CountryLocator // nil without a GeoIP database
+	bus       event.Bus
+
+	mu     sync.RWMutex
+	global *rule
+	groups map[string]*rule
+}
+
+// New creates the manager of cfg, opening the GeoIP database when set. bus,
+// which may be nil, receives an event for each runtime change.
+func New(cfg *config.NetworkACLConfig, bus event.Bus) (*Manager, error) {
+	m := &Manager{enabled: cfg.Enabled, bus: bus, groups: make(map[string]*rule)}
+	if !cfg.Enabled {
+		return m, nil
+	}
+	if cfg.GeoIPDatabase != "" {
+		locator, err := OpenCountryDatabase(cfg.GeoIPDatabase)
+		if err != nil {
+			return nil, err
+		}
+		m.countries = locator
+	}
+
+	global, err := m.parse(GlobalRule, cfg.Global)
+	if err != nil {
+		return nil, err
+	}
+	m.global = global
+	for name, source := range cfg.Groups {
+		group, err := m.parse(name, source)
+		if err != nil {
+			return nil, err
+		}
+		m.groups[name] = group
+	}
+	logger.Info("Network ACL enabled with %d group rules", len(m.groups))
+	return m, nil
+}
+
+// Enabled reports whether requests are checked
+func (m *Manager) Enabled() bool {
+	return m != nil && m.enabled
+}
+
+// Check checks a request from ip to path against the global rule and the rules
+// of the groups whose paths contain it. Blocked requests are counted by rule and reason.
+func (m *Manager) Check(ip net.IP, path string) Decision {
+	m.mu.RLock()
+	rules := []string{GlobalRule}
+	applied := []*rule{m.global}
+	for _, name := range sortedNames(m.groups) {
+		if m.groups[name].matches(path) {
+			rules = append(rules, name)
+			applied = append(applied, m.groups[name])
+		}
+	}
+	m.mu.RUnlock()
+
+	country, located := "", false
+	for i, r := range applied {
+		if r == nil {
+			continue
+		}
+		if r.hasCountries() && !located {
+			country, located = m.country(ip), true
+		}
+		if reason := r.check(ip, country); reason != "" {
+			blockedRequestsTotal.WithLabelValues(rules[i], reason).Inc()
+			return Decision{Rule: rules[i], Reason: reason, Country: country}
+		}
+	}
+	return Decision{Allowed: true, Country: country}
+}
+
+// Rules returns the global rule and the group rules in use
+func (m *Manager) Rules() (config.NetworkACLRule, map[string]config.NetworkACLRule) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var global config.NetworkACLRule
+	if m.global != nil {
+		global = m.global.source
+	}
+	groups := make(map[string]config.NetworkACLRule, len(m.groups))
+	for name, group := range m.groups {
+		groups[name] = group.source
+	}
+	return global, groups
+}
+
+// SetGlobal replaces the global rule
+func (m *Manager) SetGlobal(ctx context.Context, source config.NetworkACLRule) error {
+	source.Paths = nil
+	parsed, err := m.parse(GlobalRule, source)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.global = parsed
+	m.mu.Unlock()
+
+	logger.Info("Network ACL global rule replaced")
+	m.publish(ctx, RulesUpdated{Name: GlobalRule, Rule: &source})
+	return nil
+}
+
+// SetGroup creates or replaces the rule of a group
+func (m *Manager) SetGroup(ctx context.Context, name string, source config.NetworkACLRule) error {
+	if name == "" || name == GlobalRule {
+		return fmt.Errorf("%w: group name must not be empty nor %q", ErrInvalidRule, GlobalRule)
+	}
+	if len(source.Paths) == 0 {
+		return fmt.Errorf("%w: group %s has no paths", ErrInvalidRule, name)
+	}
+	parsed, err := m.parse(name, source)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.groups[name] = parsed
+	m.mu.Unlock()
+
+	logger.Info("Network ACL rule of group %s replaced", name)
+	m.publish(ctx, RulesUpdated{Name: name, Rule: &source})
+	return nil
+}
+
+// DeleteGroup deletes the rule of a group
+func (m *Manager) DeleteGroup(ctx context.Context, name string) error {
+	m.mu.Lock()
+	if _, ok := m.groups[name]; !ok {
+		m.mu.Unlock()
+		return ErrGroupNotFound
+	}
+	delete(m.groups, name)
+	m.mu.Unlock()
+
+	logger.Info("Network ACL rule of group %s deleted", name)
+	m.publish(ctx, RulesUpdated{Name: name})
+	return nil
+}
+
+// GeoIPEnabled reports whether country rules can be used
+func (m *Manager) GeoIPEnabled() bool {
+	return m.countries != nil
+}
+
+// OnStop closes the GeoIP database
+func (m *Manager) OnStop(ctx context.Context) error {
+	if m.countries != nil {
+		return m.countries.Close()
+	}
+	return nil
+}
+
+// parse validates and parses a rule; country codes are upper cased
+func (m *Manager) parse(name string, source config.NetworkACLRule) (*rule, error) {
+	parsed := &rule{source: source}
+	var err error
+	if parsed.allow, err = parseNetworks(source.Allow); err != nil {
+		return nil, fmt.Errorf("%w: %s allow: %v", ErrInvalidRule, name, err)
+	}
+	if parsed.deny, err = parseNetworks(source.Deny); err != nil {
+		return nil, fmt.Errorf("%w: %s deny: %v", ErrInvalidRule, name, err)
+	}
+	if parsed.allowCountries, err = parseCountries(source.AllowCountries); err != nil {
+		return nil, fmt.Errorf("%w: %s allow_countries: %v", ErrInvalidRule, name, err)
+	}
+	if parsed.denyCountries, err = parseCountries(source.DenyCountries); err != nil {
+		return nil, fmt.Errorf("%w: %s deny_countries: %v", ErrInvalidRule, name, err)
+	}
+	if parsed.hasCountries() && m.countries == nil {
+		return nil, ErrGeoIPUnavailable
+	}
+	for _, path := range source.Paths {
+		if !strings.HasPrefix(path, "/") {
+			return nil, fmt.Errorf("%w: %s path %q must start with /", ErrInvalidRule, name, path)
+		}
+	}
+	return parsed, nil
+}
+
+// country returns the country of ip, or an empty string when unknown
+func (m *Manager) country(ip net.IP) string {
+	if m.countries == nil || ip == nil {
+		return ""
+	}
+	country, err := m.countries.Country(ip)
+	if err != nil {
+		logger.Warn("GeoIP lookup of %s failed: %v", ip, err)
+		return ""
+	}
+	return country
+}
+
+// publish publishes a rules updated event, when a bus is set
+func (m *Manager) publish(ctx context.Context, payload RulesUpdated) {
+	if m.bus != nil {
+		m.bus.Publish(ctx, event.NewEvent(EventTypeRulesUpdated, payload))
+	}
+}
+
+// matches reports whether path is under one of the paths of the rule
+func (r *rule) matches(path string) bool {
+	for _, prefix := range r.source.Paths {
+		prefix = strings.TrimSuffix(prefix, "/")
+		if path == prefix || strings.HasPrefix(path, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// hasCountries reports whether the rule needs the country of the client
+func (r *rule) hasCountries() bool {
+	return len(r.allowCountries) > 0 || len(r.denyCountries) > 0
+}
+
+// check returns why the rule blocks ip located in country, or an empty string.
+// Clients of an unknown country only pass an allowlist by IP.
+func (r *rule) check(ip net.IP, country string) string {
+	if containsIP(r.deny, ip) {
+		return ReasonIPDenied
+	}
+	if country != "" && r.denyCountries[country] {
+		return ReasonCountryDenied
+	}
+	if len(r.allow) == 0 && len(r.allowCountries) == 0 {
+		return ""
+	}
+	if containsIP(r.allow, ip) || (country != "" && r.allowCountries[country]) {
+		return ""
+	}
+	return ReasonNotAllowed
+}
+
+// containsIP reports whether one of networks contains ip
+func containsIP(networks []*net.IPNet, ip net.IP) bool {
+	if ip == nil {
+		return false
+	}
+	for _, network := range networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseNetworks parses IPs and CIDRs, an IP being the network of that address only
+func parseNetworks(values []string) ([]*net.IPNet, error) {
+	networks := make([]*net.IPNet, 0, len(values))
+	for _, value := range values {
+		if ip := net.ParseIP(value); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR", value)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// parseCountries parses ISO 3166-1 alpha-2 codes
+func parseCountries(values []string) (map[string]bool, error) {
+	countries := make(map[string]bool, len(values))
+	for _, value := range values {
+		code := strings.ToUpper(value)
+		if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+			return nil, fmt.Errorf("%q is not an ISO 3166-1 alpha-2 country code", value)
+		}
+		countries[code] = true
+	}
+	return countries, nil
+}
+
+// sortedNames returns the names of groups in order, so that the blocking rule is deterministic
+func sortedNames(groups map[string]*rule) []string {
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
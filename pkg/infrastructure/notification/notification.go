@@ -0,0 +1,238 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Built-in providers
+const (
+	ProviderLog     = "log"
+	ProviderWebhook = "webhook"
+)
+
+// Delivery statuses recorded in metrics
+const (
+	StatusSent        = "sent"
+	StatusFailed      = "failed"
+	StatusRateLimited = "rate_limited"
+)
+
+var (
+	// ErrChannelUnavailable is returned for channels without a configured provider
+	ErrChannelUnavailable = errors.New("notification channel is not available")
+	// ErrRateLimited is returned when the recipient has received too many notifications
+	ErrRateLimited = errors.New("notification rate limit of recipient exceeded")
+)
+
+// Message is a notification ready to be sent on a channel
+type Message struct {
+	Channel string
+	// To is the address of the recipient in the channel: a phone number, a
+	// device token or a webhook URL
+	To    string
+	Title string
+	Body  string
+	// Data is passed to providers that deliver structured payloads, e.g. push and webhooks
+	Data map[string]interface{}
+}
+
+// Provider delivers notifications through a vendor
+type Provider interface {
+	// Send delivers msg synchronously
+	Send(ctx context.Context, msg *Message) error
+}
+
+// ProviderFactory creates a provider from the options of its channel. Providers
+// calling HTTP APIs should use a client of clients.
+type ProviderFactory func(options map[string]string, clients *httpclient.Factory) (Provider, error)
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]ProviderFactory{
+		ProviderLog: func(options map[string]string, clients *httpclient.Factory) (Provider, error) {
+			return NewLogProvider(), nil
+		},
+		ProviderWebhook: func(options map[string]string, clients *httpclient.Factory) (Provider, error) {
+			if options["allow_private_addresses"] == "true" {
+				return NewWebhookProvider(clients.New("notification-webhook"), options["secret"]), nil
+			}
+			return NewWebhookProvider(clients.NewPublic("notification-webhook"), options["secret"]), nil
+		},
+	}
+)
+
+// init registers the webhooks module, which has no models
+func init() {
+	module.Register(module.Module{
+		Name:        module.Webhooks,
+		Description: "Webhook notification channels and the login lockout webhook",
+	})
+}
+
+// RegisterProvider makes a vendor provider such as twilio or fcm available under
+// name, replacing any provider registered under the same name
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[name] = factory
+}
+
+// Providers returns the names of the registered providers, sorted
+func Providers() []string {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+var (
+	// Notification delivery counter
+	deliveriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "notification_deliveries_total",
+			Help: "Total number of notification delivery attempts",
+		},
+		[]string{"channel", "provider", "status"},
+	)
+
+	// Notification delivery duration histogram
+	deliveryDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "notification_delivery_duration_seconds",
+			Help:    "Notification delivery duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"channel", "provider"},
+	)
+)
+
+// channelProvider is the provider configured for a channel
+type channelProvider struct {
+	name     string
+	provider Provider
+}
+
+// Notifier sends notifications through the provider of their channel, limiting
+// the notifications each recipient receives
+type Notifier struct {
+	enabled  bool
+	channels map[string]channelProvider
+	limiter  *recipientLimiter
+}
+
+// New creates a notifier with the configured channel providers and rate limit
+// store. A disabled notifier has no channels and does not connect to its store.
+// Channels of the webhook provider are unavailable while the webhooks module
+// is disabled.
+func New(cfg *config.Config, clients *httpclient.Factory) (*Notifier, error) {
+	if !cfg.Notification.Enabled {
+		return &Notifier{channels: map[string]channelProvider{}}, nil
+	}
+
+	channels := make(map[string]channelProvider, len(cfg.Notification.Channels))
+	for channel, channelCfg := range cfg.Notification.Channels {
+		if channelCfg.Provider == ProviderWebhook && !module.Enabled(module.Webhooks) {
+			continue
+		}
+		providersMu.RLock()
+		factory, ok := providers[channelCfg.Provider]
+		providersMu.RUnlock()
+		if !ok {
+			return nil, fmt.Errorf("unsupported notification provider %s of channel %s", channelCfg.Provider, channel)
+		}
+		provider, err := factory(channelCfg.Options, clients)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notification provider %s of channel %s: %w", channelCfg.Provider, channel, err)
+		}
+		channels[channel] = channelProvider{name: channelCfg.Provider, provider: provider}
+	}
+
+	var store quota.Store
+	switch cfg.Notification.RateLimit.Store {
+	case quota.StoreMemory, "":
+		store = quota.NewMemoryStore()
+	case quota.StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect notification rate limit store: %w", err)
+		}
+		store = quota.NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported notification rate limit store: %s", cfg.Notification.RateLimit.Store)
+	}
+
+	return &Notifier{
+		enabled:  true,
+		channels: channels,
+		limiter:  newRecipientLimiter(store, cfg.Notification.RateLimit.PerRecipient, cfg.Notification.RateLimit.Window),
+	}, nil
+}
+
+// Enabled reports whether notifications are sent
+func (n *Notifier) Enabled() bool {
+	return n.enabled
+}
+
+// HasChannel reports whether channel has a provider
+func (n *Notifier) HasChannel(channel string) bool {
+	_, ok := n.channels[channel]
+	return ok
+}
+
+// Send delivers msg through the provider of its channel. ErrRateLimited is
+// returned without sending when the recipient is over the rate limit.
+func (n *Notifier) Send(ctx context.Context, msg *Message) error {
+	channel, ok := n.channels[msg.Channel]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrChannelUnavailable, msg.Channel)
+	}
+
+	allowed, err := n.limiter.Allow(ctx, msg.Channel, msg.To)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		deliveriesTotal.WithLabelValues(msg.Channel, channel.name, StatusRateLimited).Inc()
+		return ErrRateLimited
+	}
+
+	start := time.Now()
+	err = channel.provider.Send(ctx, msg)
+	deliveryDuration.WithLabelValues(msg.Channel, channel.name).Observe(time.Since(start).Seconds())
+
+	status := StatusSent
+	if err != nil {
+		status = StatusFailed
+	}
+	deliveriesTotal.WithLabelValues(msg.Channel, channel.name, status).Inc()
+	return err
+}
+
+// OnStop closes the rate limit store when it holds a connection
+func (n *Notifier) OnStop(ctx context.Context) error {
+	if n.limiter == nil {
+		return nil
+	}
+	if closer, ok := n.limiter.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
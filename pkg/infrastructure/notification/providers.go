@@ -0,0 +1,90 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of webhook payloads when a secret is configured
+const SignatureHeader = "X-Notification-Signature"
+
+// LogProvider writes notifications to the log instead of sending them; used in development
+type LogProvider struct{}
+
+// NewLogProvider creates a log provider
+func NewLogProvider() *LogProvider {
+	return &LogProvider{}
+}
+
+// Send logs the notification
+func (p *LogProvider) Send(ctx context.Context, msg *Message) error {
+	logger.Info("Notification via %s to %s: %s\n%s", msg.Channel, msg.To, msg.Title, msg.Body)
+	return nil
+}
+
+// webhookPayload is the JSON body posted by the webhook provider
+type webhookPayload struct {
+	Channel string                 `json:"channel"`
+	Title   string                 `json:"title,omitempty"`
+	Body    string                 `json:"body"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	SentAt  time.Time              `json:"sent_at"`
+}
+
+// WebhookProvider posts notifications as JSON to the recipient URL
+type WebhookProvider struct {
+	client *http.Client
+	secret string
+}
+
+// NewWebhookProvider creates a webhook provider. The URLs are chosen by users,
+// so client should only reach public addresses, see httpclient.NewPublic. With
+// a secret, payloads are signed in the X-Notification-Signature header as
+// sha256=<hex HMAC>.
+func NewWebhookProvider(client *http.Client, secret string) *WebhookProvider {
+	return &WebhookProvider{client: client, secret: secret}
+}
+
+// Send posts the notification to msg.To
+func (p *WebhookProvider) Send(ctx context.Context, msg *Message) error {
+	body, err := json.Marshal(webhookPayload{
+		Channel: msg.Channel,
+		Title:   msg.Title,
+		Body:    msg.Body,
+		Data:    msg.Data,
+		SentAt:  time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, msg.To, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.secret != "" {
+		mac := hmac.New(sha256.New, []byte(p.secret))
+		mac.Write(body)
+		req.Header.Set(SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := p.client.Do(req)
+	if err := httpclient.Check(resp, err); err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
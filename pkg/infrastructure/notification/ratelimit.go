@@ -0,0 +1,37 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+)
+
+// recipientLimiter counts the notifications of each recipient over fixed windows
+type recipientLimiter struct {
+	store  quota.Store
+	limit  int64
+	window time.Duration
+}
+
+// newRecipientLimiter creates a limiter allowing limit notifications per
+// recipient and window; a zero limit or window disables it
+func newRecipientLimiter(store quota.Store, limit int64, window time.Duration) *recipientLimiter {
+	return &recipientLimiter{store: store, limit: limit, window: window}
+}
+
+// Allow counts a notification to the recipient of channel and reports whether it is within the limit
+func (l *recipientLimiter) Allow(ctx context.Context, channel, to string) (bool, error) {
+	if l.limit <= 0 || l.window <= 0 {
+		return true, nil
+	}
+
+	start := time.Now().UTC().Truncate(l.window)
+	key := fmt.Sprintf("notification:%s:%s:%d", channel, to, start.Unix())
+	count, err := l.store.Increment(ctx, key, start.Add(l.window))
+	if err != nil {
+		return false, fmt.Errorf("failed to count notifications of %s recipient: %w", channel, err)
+	}
+	return count <= l.limit, nil
+}
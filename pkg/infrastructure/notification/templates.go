@@ -0,0 +1,136 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+)
+
+// titleChannel is the pseudo channel of the optional title template, e.g. welcome.title.tmpl
+const titleChannel = "title"
+
+//go:embed templates
+var builtinTemplates embed.FS
+
+// ErrTemplateNotFound is returned when a template has no body for the requested channel
+var ErrTemplateNotFound = errors.New("notification template not found")
+
+// Content is a rendered notification
+type Content struct {
+	Title string
+	Body  string
+}
+
+// Templates renders notification templates. A template has a body per channel
+// in <name>.<channel>.tmpl and an optional title shared by all channels in
+// <name>.title.tmpl. Templates for a language live under a <language>/ directory
+// and fall back to the top-level templates of the default language; templates in
+// the configured directory take precedence over the built-in ones.
+type Templates struct {
+	sources    []fs.FS
+	translator i18n.Translator
+
+	cache sync.Map // name+"|"+channel+"|"+lang -> *template.Template, nil when missing
+}
+
+// NewTemplates creates a template renderer that loads templates from dir, if set,
+// and from the built-in templates. The t template function translates i18n keys.
+func NewTemplates(dir string, translator i18n.Translator) *Templates {
+	builtin, _ := fs.Sub(builtinTemplates, "templates")
+	sources := []fs.FS{builtin}
+	if dir != "" {
+		sources = append([]fs.FS{os.DirFS(dir)}, sources...)
+	}
+	return &Templates{sources: sources, translator: translator}
+}
+
+// Render renders the named template for channel in lang with data
+func (t *Templates) Render(name, channel, lang string, data interface{}) (*Content, error) {
+	if lang == "" {
+		lang = i18n.DefaultLanguage
+	}
+
+	body, err := t.load(name, channel, lang)
+	if err != nil {
+		return nil, err
+	}
+	if body == nil {
+		return nil, fmt.Errorf("%w: %s for channel %s", ErrTemplateNotFound, name, channel)
+	}
+	title, err := t.load(name, titleChannel, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	content := &Content{}
+	var buf bytes.Buffer
+	if err := body.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("failed to render notification template %s for channel %s: %w", name, channel, err)
+	}
+	content.Body = strings.TrimSpace(buf.String())
+	if title != nil {
+		buf.Reset()
+		if err := title.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("failed to render title of notification template %s: %w", name, err)
+		}
+		content.Title = strings.TrimSpace(buf.String())
+	}
+	return content, nil
+}
+
+// load parses a template file for lang, caching the result; a missing file yields nil
+func (t *Templates) load(name, channel, lang string) (*template.Template, error) {
+	key := name + "|" + channel + "|" + lang
+	if cached, ok := t.cache.Load(key); ok {
+		return cached.(*template.Template), nil
+	}
+
+	file := name + "." + channel + ".tmpl"
+	text, ok, err := t.read(file, lang)
+	if err != nil {
+		return nil, err
+	}
+	var tmpl *template.Template
+	if ok {
+		funcs := template.FuncMap{
+			"t": func(key string, args ...interface{}) string {
+				if t.translator == nil {
+					return key
+				}
+				return t.translator.TranslateWithLang(lang, key, args...)
+			},
+		}
+		if tmpl, err = template.New(file).Funcs(funcs).Parse(text); err != nil {
+			return nil, fmt.Errorf("failed to parse notification template %s: %w", file, err)
+		}
+	}
+
+	t.cache.Store(key, tmpl)
+	return tmpl, nil
+}
+
+// read returns the first template file found, trying the language directory
+// before the top-level templates in each source
+func (t *Templates) read(file, lang string) (string, bool, error) {
+	for _, source := range t.sources {
+		for _, p := range []string{path.Join(lang, file), file} {
+			data, err := fs.ReadFile(source, p)
+			if err == nil {
+				return string(data), true, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return "", false, fmt.Errorf("failed to read notification template %s: %w", p, err)
+			}
+		}
+	}
+	return "", false, nil
+}
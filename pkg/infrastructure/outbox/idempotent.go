@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/broker"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// errProcessed aborts the unit of work of a message already handled by the group
+var errProcessed = errors.New("message already processed")
+
+// Idempotent wraps handler so that group handles each message ID once. The
+// handler runs in a unit of work together with the record of the message, so
+// services joining the unit of work through ctx commit their changes only with
+// the record. Redelivered messages are acknowledged without calling handler.
+func Idempotent(uow datastore.UnitOfWorkManager, group string, handler broker.Handler) broker.Handler {
+	return func(ctx context.Context, msg *broker.Message) error {
+		if msg.ID == "" {
+			return handler(ctx, msg)
+		}
+
+		err := uow.Do(ctx, func(ctx context.Context, u datastore.UnitOfWork) error {
+			repo, err := datastore.NewRepository[*model.ProcessedMessage](u.Store())
+			if err != nil {
+				return err
+			}
+			processed, err := repo.List(ctx, datastore.ListOptions{
+				Size:    1,
+				Filters: map[string]interface{}{"consumer_group": group, "message_id": msg.ID},
+			})
+			if err != nil {
+				return err
+			}
+			if len(processed) > 0 {
+				return errProcessed
+			}
+
+			if err := handler(ctx, msg); err != nil {
+				return err
+			}
+			_, err = repo.Create(ctx, &model.ProcessedMessage{
+				ConsumerGroup: group,
+				MessageID:     msg.ID,
+				Topic:         msg.Topic,
+			})
+			if errors.Is(err, datastore.ErrDuplicateKey) {
+				// A concurrent delivery of the same message committed first
+				return errProcessed
+			}
+			return err
+		})
+		if errors.Is(err, errProcessed) {
+			logger.Debug("Skipping message %s of %s already processed by group %s", msg.ID, msg.Topic, group)
+			return nil
+		}
+		return err
+	}
+}
@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/make-bin/server-tpl/pkg/domain/event"
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/broker"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Relayed message statuses
+const (
+	statusPublished = "published"
+	statusRetried   = "retried"
+	statusFailed    = "failed"
+)
+
+var (
+	// Recorded message counter
+	recordedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_messages_recorded_total",
+			Help: "Total number of domain events stored in the outbox",
+		},
+		[]string{"event_type"},
+	)
+
+	// Relayed message counter
+	relayedTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "outbox_messages_relayed_total",
+			Help: "Total number of outbox publish attempts by outcome",
+		},
+		[]string{"status"},
+	)
+)
+
+// Recorder stores the domain events of a unit of work in the outbox table. It
+// implements datastore.Outbox, so events are only recorded when the business
+// transaction commits.
+type Recorder struct {
+	codec  broker.Codec
+	events map[string]bool
+	prefix string
+}
+
+// NewRecorder creates a recorder for the configured event types
+func NewRecorder(cfg *config.OutboxConfig, codec broker.Codec) *Recorder {
+	events := make(map[string]bool, len(cfg.Events))
+	for _, eventType := range cfg.Events {
+		events[eventType] = true
+	}
+	return &Recorder{codec: codec, events: events, prefix: cfg.TopicPrefix}
+}
+
+// Records reports whether events of the type are stored in the outbox
+func (r *Recorder) Records(eventType string) bool {
+	return r.events[event.WildcardType] || r.events[eventType]
+}
+
+// Record implements datastore.Outbox
+func (r *Recorder) Record(ctx context.Context, tx datastore.DatastoreInterface, events []event.Event) error {
+	repo, err := datastore.NewRepository[*model.OutboxMessage](tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, e := range events {
+		if !r.Records(e.Type) {
+			continue
+		}
+		msg, err := broker.Encode(r.codec, r.prefix+e.Type, e.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to record event %s: %w", e.Type, err)
+		}
+		msg.Headers[broker.HeaderEventType] = e.Type
+		msg.Headers[broker.HeaderEventTime] = e.Timestamp.UTC().Format(time.RFC3339Nano)
+		headers, err := json.Marshal(msg.Headers)
+		if err != nil {
+			return fmt.Errorf("failed to record event %s: %w", e.Type, err)
+		}
+
+		if _, err := repo.Create(ctx, &model.OutboxMessage{
+			MessageID:     uuid.NewString(),
+			Topic:         msg.Topic,
+			EventType:     e.Type,
+			Headers:       headers,
+			Payload:       msg.Data,
+			Status:        model.OutboxStatusPending,
+			NextAttemptAt: now,
+		}); err != nil {
+			return fmt.Errorf("failed to record event %s: %w", e.Type, err)
+		}
+		recordedTotal.WithLabelValues(e.Type).Inc()
+	}
+	return nil
+}
@@ -0,0 +1,220 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/broker"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+const (
+	// publishTimeout bounds publishing a single outbox message
+	publishTimeout = 10 * time.Second
+	// cleanupInterval is the delay between two removals of expired records
+	cleanupInterval = time.Minute
+)
+
+// Relay publishes the pending outbox messages to the broker and marks them
+// published. Messages are published at least once: a message published right
+// before the process stops, or by two instances at the same time, is published
+// again, with the same message ID.
+type Relay struct {
+	store     datastore.DatastoreInterface
+	publisher broker.Publisher
+	cfg       config.OutboxConfig
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRelay creates a relay reading the outbox table of store
+func NewRelay(store datastore.DatastoreInterface, publisher broker.Publisher, cfg *config.OutboxConfig) *Relay {
+	return &Relay{store: store, publisher: publisher, cfg: *cfg}
+}
+
+// OnStart starts relaying in the background
+func (r *Relay) OnStart(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go r.run(runCtx)
+	logger.Info("Outbox relay started, polling every %s", r.cfg.PollInterval)
+	return nil
+}
+
+// OnStop stops relaying, waiting for the running batch up to the deadline of ctx
+func (r *Relay) OnStop(ctx context.Context) error {
+	if r.cancel == nil {
+		return nil
+	}
+	r.cancel()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run relays the pending messages until ctx is cancelled
+func (r *Relay) run(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	var lastCleanup time.Time
+	for {
+		// Batches are relayed back to back while the outbox is full
+		for {
+			relayed, err := r.Relay(ctx)
+			if err != nil {
+				logger.Error("Failed to relay outbox messages: %v", err)
+				break
+			}
+			if relayed < r.cfg.BatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+		if r.cfg.Retention > 0 && time.Since(lastCleanup) >= cleanupInterval {
+			r.cleanup(ctx)
+			lastCleanup = time.Now()
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Relay publishes one batch of due messages and returns how many were attempted
+func (r *Relay) Relay(ctx context.Context) (int, error) {
+	repo, err := datastore.NewRepository[*model.OutboxMessage](r.store)
+	if err != nil {
+		return 0, err
+	}
+	messages, err := repo.List(ctx, datastore.ListOptions{
+		Size:    r.cfg.BatchSize,
+		SortBy:  "next_attempt_at",
+		Filters: map[string]interface{}{"status": model.OutboxStatusPending},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	relayed := 0
+	for _, msg := range messages {
+		// Messages are sorted by attempt time, the rest are not due yet
+		if msg.NextAttemptAt.After(now) || ctx.Err() != nil {
+			break
+		}
+		r.publish(ctx, msg)
+		// Keep the outcome of the attempt when the relay is stopping
+		if _, err := repo.Update(context.WithoutCancel(ctx), msg); err != nil {
+			return relayed, err
+		}
+		relayed++
+	}
+	return relayed, nil
+}
+
+// publish publishes msg and updates its status with the outcome
+func (r *Relay) publish(ctx context.Context, msg *model.OutboxMessage) {
+	headers := make(map[string]string)
+	if len(msg.Headers) > 0 {
+		if err := json.Unmarshal(msg.Headers, &headers); err != nil {
+			logger.Warn("Ignoring invalid headers of outbox message %s: %v", msg.MessageID, err)
+		}
+	}
+
+	publishCtx, cancel := context.WithTimeout(ctx, publishTimeout)
+	defer cancel()
+	msg.Attempts++
+	err := r.publisher.Publish(publishCtx, &broker.Message{
+		Topic:   msg.Topic,
+		ID:      msg.MessageID,
+		Headers: headers,
+		Data:    msg.Payload,
+	})
+	if err == nil {
+		now := time.Now()
+		msg.Status = model.OutboxStatusPublished
+		msg.PublishedAt = &now
+		msg.LastError = ""
+		relayedTotal.WithLabelValues(statusPublished).Inc()
+		return
+	}
+
+	msg.LastError = err.Error()
+	if r.cfg.MaxAttempts > 0 && msg.Attempts >= r.cfg.MaxAttempts {
+		logger.Error("Giving up outbox message %s of %s after %d attempts: %v", msg.MessageID, msg.EventType, msg.Attempts, err)
+		msg.Status = model.OutboxStatusFailed
+		relayedTotal.WithLabelValues(statusFailed).Inc()
+		return
+	}
+	delay := r.backoff(msg.Attempts)
+	logger.Warn("Retrying outbox message %s of %s in %s: %v", msg.MessageID, msg.EventType, delay, err)
+	msg.NextAttemptAt = time.Now().Add(delay)
+	relayedTotal.WithLabelValues(statusRetried).Inc()
+}
+
+// backoff returns the delay before the next attempt, doubling after each failure
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.cfg.RetryBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if r.cfg.MaxBackoff > 0 && delay >= r.cfg.MaxBackoff {
+			return r.cfg.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// cleanup deletes the published messages and processed message records older than the retention
+func (r *Relay) cleanup(ctx context.Context) {
+	cutoff := time.Now().Add(-r.cfg.Retention)
+
+	deleted, err := deleteExpired[*model.OutboxMessage](ctx, r.store, r.cfg.BatchSize, cutoff,
+		map[string]interface{}{"status": model.OutboxStatusPublished})
+	if err != nil {
+		logger.Warn("Failed to delete published outbox messages: %v", err)
+	}
+	processed, err := deleteExpired[*model.ProcessedMessage](ctx, r.store, r.cfg.BatchSize, cutoff, nil)
+	if err != nil {
+		logger.Warn("Failed to delete processed message records: %v", err)
+	}
+	if deleted+processed > 0 {
+		logger.Debug("Deleted %d outbox messages and %d processed message records older than %s", deleted, processed, r.cfg.Retention)
+	}
+}
+
+// deleteExpired deletes up to limit entities matching filters that were last updated before cutoff
+func deleteExpired[T model.Entity](ctx context.Context, store datastore.DatastoreInterface, limit int, cutoff time.Time, filters map[string]interface{}) (int, error) {
+	repo, err := datastore.NewRepository[T](store)
+	if err != nil {
+		return 0, err
+	}
+	entities, err := repo.List(ctx, datastore.ListOptions{Size: limit, SortBy: "updated_at", Filters: filters})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, entity := range entities {
+		if !entity.GetUpdatedAt().Before(cutoff) {
+			break
+		}
+		if err := repo.Delete(ctx, entity.GetID()); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
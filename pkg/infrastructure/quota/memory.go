@@ -0,0 +1,78 @@
+package quota
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired counters are removed from a memory store
+const sweepInterval = time.Minute
+
+// counter is a request counter kept in memory
+type counter struct {
+	value    int64
+	expireAt time.Time
+}
+
+// MemoryStore implements Store in memory; counters are per process and lost on restart
+type MemoryStore struct {
+	mu        sync.Mutex
+	counters  map[string]*counter
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{counters: make(map[string]*counter), lastSweep: time.Now()}
+}
+
+// Increment adds one to the counter, starting a new one when it is missing or has expired
+func (s *MemoryStore) Increment(ctx context.Context, key string, expireAt time.Time) (int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if now.Sub(s.lastSweep) >= sweepInterval {
+		s.sweep(now)
+	}
+	c, ok := s.counters[key]
+	if !ok || !now.Before(c.expireAt) {
+		c = &counter{}
+		s.counters[key] = c
+	}
+	c.value++
+	c.expireAt = expireAt
+	return c.value, nil
+}
+
+// Get returns the counter value, zero when it is missing or has expired
+func (s *MemoryStore) Get(ctx context.Context, key string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	c, ok := s.counters[key]
+	if !ok || !time.Now().Before(c.expireAt) {
+		return 0, nil
+	}
+	return c.value, nil
+}
+
+// Delete removes the counters
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		delete(s.counters, key)
+	}
+	return nil
+}
+
+// sweep removes expired counters; the caller must hold the lock
+func (s *MemoryStore) sweep(now time.Time) {
+	for key, c := range s.counters {
+		if !now.Before(c.expireAt) {
+			delete(s.counters, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,239 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Store types
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// DefaultClass is the quota class of routes that do not select one
+const DefaultClass = "default"
+
+// Quota periods. Periods are calendar days and months in UTC.
+const (
+	PeriodDay   = "day"
+	PeriodMonth = "month"
+)
+
+// Store keeps the request counters of quota periods
+type Store interface {
+	// Increment adds one to the counter under key, which expires at expireAt, and returns the new count
+	Increment(ctx context.Context, key string, expireAt time.Time) (int64, error)
+	// Get returns the counter under key, zero when it does not exist or has expired
+	Get(ctx context.Context, key string) (int64, error)
+	// Delete removes the counters under keys
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// Window is the usage of a principal in the current period of a quota
+type Window struct {
+	Period string
+	Limit  int64
+	Used   int64
+	Reset  time.Time
+}
+
+// Remaining returns the requests left in the period
+func (w Window) Remaining() int64 {
+	if w.Used >= w.Limit {
+		return 0
+	}
+	return w.Limit - w.Used
+}
+
+// Exceeded reports whether the requests counted in the period are over the limit
+func (w Window) Exceeded() bool {
+	return w.Used > w.Limit
+}
+
+// Usage is the usage of a principal in a quota class
+type Usage struct {
+	Class   string
+	Windows []Window
+}
+
+// Manager counts requests of principals against the configured quotas
+type Manager struct {
+	enabled    bool
+	store      Store
+	classes    map[string]config.QuotaLimit
+	principals map[string]map[string]config.QuotaLimit
+}
+
+// New creates a quota manager with the store selected by the quota configuration.
+// A disabled manager does not connect to its store.
+func New(cfg *config.Config) (*Manager, error) {
+	var store Store
+	switch {
+	case !cfg.Quota.Enabled, cfg.Quota.Store == StoreMemory, cfg.Quota.Store == "":
+		store = NewMemoryStore()
+	case cfg.Quota.Store == StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect quota store: %w", err)
+		}
+		store = NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported quota store: %s", cfg.Quota.Store)
+	}
+	return NewManager(cfg.Quota, store), nil
+}
+
+// NewManager creates a quota manager counting in store
+func NewManager(cfg config.QuotaConfig, store Store) *Manager {
+	return &Manager{
+		enabled:    cfg.Enabled,
+		store:      store,
+		classes:    cfg.Classes,
+		principals: cfg.Principals,
+	}
+}
+
+// Enabled reports whether quotas are enforced
+func (m *Manager) Enabled() bool {
+	return m.enabled
+}
+
+// HasClass reports whether class is configured
+func (m *Manager) HasClass(class string) bool {
+	_, ok := m.classes[class]
+	return ok
+}
+
+// Consume counts a request of principal against the quota class and returns the
+// windows that have a limit; an empty class selects DefaultClass. Requests over
+// the limit are counted as well.
+func (m *Manager) Consume(ctx context.Context, class, principal string) ([]Window, error) {
+	if class == "" {
+		class = DefaultClass
+	}
+
+	limit := m.limit(class, principal)
+	var windows []Window
+	for _, window := range periodWindows(limit, time.Now().UTC()) {
+		used, err := m.store.Increment(ctx, counterKey(class, principal, window), window.Reset)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count quota %s of %s: %w", class, principal, err)
+		}
+		window.Used = used
+		windows = append(windows, window)
+	}
+	return windows, nil
+}
+
+// Usage returns the usage of principal in every class that limits it, ordered by class
+func (m *Manager) Usage(ctx context.Context, principal string) ([]Usage, error) {
+	now := time.Now().UTC()
+	var usages []Usage
+	for _, class := range m.classNames(principal) {
+		usage := Usage{Class: class}
+		for _, window := range periodWindows(m.limit(class, principal), now) {
+			used, err := m.store.Get(ctx, counterKey(class, principal, window))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read quota %s of %s: %w", class, principal, err)
+			}
+			window.Used = used
+			usage.Windows = append(usage.Windows, window)
+		}
+		if len(usage.Windows) > 0 {
+			usages = append(usages, usage)
+		}
+	}
+	return usages, nil
+}
+
+// Reset clears the usage of principal in the current periods of class, or of every class when class is empty
+func (m *Manager) Reset(ctx context.Context, principal, class string) error {
+	classes := []string{class}
+	if class == "" {
+		classes = m.classNames(principal)
+	}
+
+	now := time.Now().UTC()
+	var keys []string
+	for _, name := range classes {
+		// Unlimited periods are cleared too, removing counts made before a limit change
+		for _, window := range periodWindows(config.QuotaLimit{Daily: 1, Monthly: 1}, now) {
+			keys = append(keys, counterKey(name, principal, window))
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	if err := m.store.Delete(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to reset quota of %s: %w", principal, err)
+	}
+	return nil
+}
+
+// OnStop closes the store when it holds a connection
+func (m *Manager) OnStop(ctx context.Context) error {
+	if closer, ok := m.store.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// limit returns the limit of principal in class; principal overrides take precedence over the class limit.
+// Principal keys are matched case-insensitively since configuration keys are lower-cased when loaded.
+func (m *Manager) limit(class, principal string) config.QuotaLimit {
+	if override, ok := m.principals[strings.ToLower(principal)][class]; ok {
+		return override
+	}
+	return m.classes[class]
+}
+
+// classNames returns the configured classes and the classes overridden for principal, sorted
+func (m *Manager) classNames(principal string) []string {
+	seen := make(map[string]bool, len(m.classes))
+	var names []string
+	for name := range m.classes {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range m.principals[strings.ToLower(principal)] {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// periodWindows returns the current windows of the periods limited by limit
+func periodWindows(limit config.QuotaLimit, now time.Time) []Window {
+	var windows []Window
+	if limit.Daily > 0 {
+		start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		windows = append(windows, Window{Period: PeriodDay, Limit: limit.Daily, Reset: start.AddDate(0, 0, 1)})
+	}
+	if limit.Monthly > 0 {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		windows = append(windows, Window{Period: PeriodMonth, Limit: limit.Monthly, Reset: start.AddDate(0, 1, 0)})
+	}
+	return windows
+}
+
+// counterKey returns the store key of the counter of a window, e.g. quota:default:user:42:day:20240101
+func counterKey(class, principal string, window Window) string {
+	var period string
+	switch window.Period {
+	case PeriodDay:
+		period = window.Reset.AddDate(0, 0, -1).Format("20060102")
+	default:
+		period = window.Reset.AddDate(0, -1, 0).Format("200601")
+	}
+	return fmt.Sprintf("quota:%s:%s:%s:%s", class, principal, window.Period, period)
+}
@@ -0,0 +1,38 @@
+package quota
+
+import (
+	"context"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+)
+
+// RedisStore implements Store in Redis so that counters are shared by all instances
+type RedisStore struct {
+	client *infra_middleware.RedisClient
+}
+
+// NewRedisStore creates a store counting in Redis
+func NewRedisStore(client *infra_middleware.RedisClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Increment increments the counter with INCR and sets its expiry in the same transaction
+func (s *RedisStore) Increment(ctx context.Context, key string, expireAt time.Time) (int64, error) {
+	return s.client.IncrExpireAt(ctx, key, expireAt)
+}
+
+// Get returns the counter value, zero when the key does not exist
+func (s *RedisStore) Get(ctx context.Context, key string) (int64, error) {
+	return s.client.GetInt64(ctx, key)
+}
+
+// Delete removes the counters
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	return s.client.Delete(ctx, keys...)
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
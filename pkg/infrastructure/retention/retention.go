@@ -0,0 +1,264 @@
+// Package retention purges expired records on a schedule. Each policy of the
+// retention configuration names a target, a kind of record, and how long its
+// records are kept; the records older than that are deleted in batches.
+package retention
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Policy names of the built-in targets
+const (
+	PolicyAuditLogs    = "audit_logs"
+	PolicyApplications = "applications"
+	PolicyOperations   = "operations"
+	PolicyDomainEvents = "domain_events"
+)
+
+var (
+	purgedRecordsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "retention_purged_records_total",
+			Help: "Total number of expired records deleted by retention policy",
+		},
+		[]string{"policy"},
+	)
+	expiredRecords = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "retention_expired_records",
+			Help: "Expired records left to delete by the running or last purge, by retention policy",
+		},
+		[]string{"policy"},
+	)
+	lastRunTimestamp = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "retention_last_run_timestamp_seconds",
+			Help: "Unix time at which the last purge run finished",
+		},
+	)
+)
+
+// Target is a kind of record purged once older than the retention of its policy
+type Target interface {
+	// Count returns the number of records expired at cutoff
+	Count(ctx context.Context, cutoff time.Time) (int64, error)
+	// Purge deletes up to limit records expired at cutoff and returns how many were deleted
+	Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+}
+
+// Report describes the expired records of a policy, and how many of them a
+// run deleted
+type Report struct {
+	Policy    string
+	Retention time.Duration
+	Cutoff    time.Time
+	Expired   int64
+	Deleted   int64
+	Err       error
+}
+
+// Manager runs the retention policies with the targets registered for them
+type Manager struct {
+	cfg   config.RetentionConfig
+	clock clock.Clock
+
+	mu      sync.RWMutex
+	targets map[string]Target
+	running sync.Mutex // serializes purge runs
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// New creates a retention manager with the built-in targets supported by store
+// and sink: soft-deleted applications are only purged from GORM datastores,
+// audit logs only from analytics backends implementing analytics.Purger. Each
+// target purges the datastore holding its table when store is a Router.
+func New(cfg *config.Config, store datastore.DatastoreInterface, sink analytics.Sink, clk clock.Clock) *Manager {
+	m := &Manager{
+		cfg:     cfg.Retention,
+		clock:   clk,
+		targets: make(map[string]Target),
+	}
+	if store != nil {
+		m.Register(PolicyOperations, NewOperationsTarget(store))
+		m.Register(PolicyDomainEvents, NewDomainEventsTarget(store))
+		if provider, ok := datastore.Route(store, (&model.Application{}).TableName()).(datastore.GormProvider); ok {
+			m.Register(PolicyApplications, NewApplicationsTarget(provider))
+		}
+	}
+	if purger, ok := sink.(analytics.Purger); ok {
+		m.Register(PolicyAuditLogs, NewAuditTarget(purger))
+	}
+	return m
+}
+
+// Register sets the target purged by the policy name
+func (m *Manager) Register(name string, target Target) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.targets[name] = target
+}
+
+// Enabled reports whether purges run on a schedule
+func (m *Manager) Enabled() bool {
+	return m.cfg.Enabled
+}
+
+// Interval returns the delay between two scheduled purge runs
+func (m *Manager) Interval() time.Duration {
+	return m.cfg.Interval
+}
+
+// Preview reports the records each policy would delete now, without deleting them
+func (m *Manager) Preview(ctx context.Context) []Report {
+	now := m.clock.Now()
+	var reports []Report
+	for _, policy := range m.policies() {
+		report := policy.report(now)
+		report.Expired, report.Err = policy.target.Count(ctx, report.Cutoff)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// Run deletes the expired records of every policy in batches. A failing
+// policy does not stop the others; runs do not overlap.
+func (m *Manager) Run(ctx context.Context) []Report {
+	m.running.Lock()
+	defer m.running.Unlock()
+
+	now := m.clock.Now()
+	var reports []Report
+	for _, policy := range m.policies() {
+		report := policy.report(now)
+		m.purge(ctx, policy.target, &report)
+		if report.Err != nil {
+			logger.Warn("Retention policy %s failed after deleting %d records: %v", report.Policy, report.Deleted, report.Err)
+		} else if report.Deleted > 0 {
+			logger.Info("Retention policy %s deleted %d records older than %s", report.Policy, report.Deleted, report.Retention)
+		}
+		reports = append(reports, report)
+	}
+	lastRunTimestamp.Set(float64(m.clock.Now().Unix()))
+	return reports
+}
+
+// purge deletes the expired records of one policy until a batch is not full
+func (m *Manager) purge(ctx context.Context, target Target, report *Report) {
+	report.Expired, report.Err = target.Count(ctx, report.Cutoff)
+	if report.Err != nil || report.Expired == 0 {
+		expiredRecords.WithLabelValues(report.Policy).Set(0)
+		return
+	}
+
+	for ctx.Err() == nil {
+		expiredRecords.WithLabelValues(report.Policy).Set(float64(max(report.Expired-report.Deleted, 0)))
+		deleted, err := target.Purge(ctx, report.Cutoff, m.cfg.BatchSize)
+		report.Deleted += deleted
+		purgedRecordsTotal.WithLabelValues(report.Policy).Add(float64(deleted))
+		if err != nil {
+			report.Err = err
+			return
+		}
+		if deleted < int64(m.cfg.BatchSize) {
+			break
+		}
+	}
+	expiredRecords.WithLabelValues(report.Policy).Set(float64(max(report.Expired-report.Deleted, 0)))
+	report.Err = ctx.Err()
+}
+
+// OnStart starts the scheduled purges in the background when enabled
+func (m *Manager) OnStart(ctx context.Context) error {
+	if !m.cfg.Enabled {
+		return nil
+	}
+	for name := range m.cfg.Policies {
+		if m.target(name) == nil {
+			logger.Warn("Retention policy %s has no target with the configured datastore and analytics sink, it is ignored", name)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+	go m.run(runCtx)
+	logger.Info("Retention purges started, running every %s", m.cfg.Interval)
+	return nil
+}
+
+// OnStop stops the scheduled purges, waiting for the running batch up to the deadline of ctx
+func (m *Manager) OnStop(ctx context.Context) error {
+	if m.cancel == nil {
+		return nil
+	}
+	m.cancel()
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run purges on startup and then every interval until ctx is cancelled
+func (m *Manager) run(ctx context.Context) {
+	defer close(m.done)
+
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		m.Run(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// policy is a configured policy with its target
+type policy struct {
+	name      string
+	retention time.Duration
+	target    Target
+}
+
+// report returns the report of the policy for a run at now
+func (p policy) report(now time.Time) Report {
+	return Report{Policy: p.name, Retention: p.retention, Cutoff: now.Add(-p.retention)}
+}
+
+// policies returns the policies that expire records and have a target, by name
+func (m *Manager) policies() []policy {
+	var policies []policy
+	for name, retention := range m.cfg.Policies {
+		target := m.target(name)
+		if retention <= 0 || target == nil {
+			continue
+		}
+		policies = append(policies, policy{name: name, retention: retention, target: target})
+	}
+	sort.Slice(policies, func(i, j int) bool { return policies[i].name < policies[j].name })
+	return policies
+}
+
+// target returns the target of the policy name, nil when none is registered
+func (m *Manager) target(name string) Target {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.targets[name]
+}
@@ -0,0 +1,175 @@
+package retention
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"gorm.io/gorm"
+)
+
+// entityTarget purges the entities of T matching filters and last updated
+// before the cutoff. GORM datastores delete the rows, including soft-deleted
+// ones, with a single statement per batch; other datastores delete the
+// entities one by one through the generic repository.
+type entityTarget[T model.Entity] struct {
+	store   datastore.DatastoreInterface
+	filters map[string]interface{}
+}
+
+// NewOperationsTarget creates the target of the completed and failed operations
+func NewOperationsTarget(store datastore.DatastoreInterface) Target {
+	return &entityTarget[*model.Operation]{
+		store: datastore.Route(store, (&model.Operation{}).TableName()),
+		filters: map[string]interface{}{
+			"status": []string{model.OperationStatusCompleted, model.OperationStatusFailed},
+		},
+	}
+}
+
+// NewDomainEventsTarget creates the target of the entries of the event log
+func NewDomainEventsTarget(store datastore.DatastoreInterface) Target {
+	return &entityTarget[*model.DomainEvent]{store: datastore.Route(store, (&model.DomainEvent{}).TableName())}
+}
+
+// Count implements Target
+func (t *entityTarget[T]) Count(ctx context.Context, cutoff time.Time) (int64, error) {
+	if provider, ok := t.store.(datastore.GormProvider); ok {
+		var count int64
+		err := t.expired(provider.DB().WithContext(ctx), cutoff).Count(&count).Error
+		return count, err
+	}
+
+	entities, err := t.list(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	for _, entity := range entities {
+		if !entity.GetUpdatedAt().Before(cutoff) {
+			break
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Purge implements Target
+func (t *entityTarget[T]) Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	if provider, ok := t.store.(datastore.GormProvider); ok {
+		db := provider.DB().WithContext(ctx)
+		ids := t.expired(db, cutoff).Select("id").Order("id").Limit(limit)
+		result := db.Unscoped().Where("id IN (?)", ids).Delete(newEntity[T]())
+		return result.RowsAffected, result.Error
+	}
+
+	repo, err := datastore.NewRepository[T](t.store)
+	if err != nil {
+		return 0, err
+	}
+	entities, err := t.list(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	var deleted int64
+	for _, entity := range entities {
+		if !entity.GetUpdatedAt().Before(cutoff) {
+			break
+		}
+		if err := repo.Delete(ctx, entity.GetID()); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// expired returns the query of the expired rows
+func (t *entityTarget[T]) expired(db *gorm.DB, cutoff time.Time) *gorm.DB {
+	return db.Unscoped().Model(newEntity[T]()).Where(t.filters).Where("updated_at < ?", cutoff)
+}
+
+// list returns up to size entities matching the filters, least recently updated first; 0 returns all of them
+func (t *entityTarget[T]) list(ctx context.Context, size int) ([]T, error) {
+	repo, err := datastore.NewRepository[T](t.store)
+	if err != nil {
+		return nil, err
+	}
+	return repo.List(ctx, datastore.ListOptions{Size: size, SortBy: "updated_at", Filters: t.filters})
+}
+
+// newEntity allocates a zero value of the struct T points to
+func newEntity[T model.Entity]() T {
+	var entity T
+	return reflect.New(reflect.TypeOf(entity).Elem()).Interface().(T)
+}
+
+// applicationsTarget purges the applications soft-deleted before the cutoff,
+// with their soft-deleted variables and revisions. Backups outlive them.
+type applicationsTarget struct {
+	db *gorm.DB
+}
+
+// NewApplicationsTarget creates the target of the soft-deleted applications of a GORM datastore
+func NewApplicationsTarget(provider datastore.GormProvider) Target {
+	return &applicationsTarget{db: provider.DB()}
+}
+
+// Count implements Target
+func (t *applicationsTarget) Count(ctx context.Context, cutoff time.Time) (int64, error) {
+	var count int64
+	err := t.expired(t.db.WithContext(ctx), cutoff).Count(&count).Error
+	return count, err
+}
+
+// Purge implements Target, a batch is deleted in one transaction
+func (t *applicationsTarget) Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	var deleted int64
+	err := t.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		if err := t.expired(tx, cutoff).Order("id").Limit(limit).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		for _, dependent := range []model.Entity{&model.ApplicationVariable{}, &model.ApplicationRevision{}} {
+			if err := tx.Unscoped().Where("app_id IN ?", ids).Delete(dependent).Error; err != nil {
+				return err
+			}
+		}
+		result := tx.Unscoped().Where("id IN ?", ids).Delete(&model.Application{})
+		deleted = result.RowsAffected
+		return result.Error
+	})
+	return deleted, err
+}
+
+// expired returns the query of the applications soft-deleted before the cutoff
+func (t *applicationsTarget) expired(db *gorm.DB, cutoff time.Time) *gorm.DB {
+	return db.Unscoped().Model(&model.Application{}).Where("deleted_at < ?", cutoff)
+}
+
+// auditTarget purges the audit entries of the analytics sink. Backends delete
+// all of them at once, regardless of the batch size.
+type auditTarget struct {
+	purger analytics.Purger
+}
+
+// NewAuditTarget creates the target of the audit entries of an analytics sink
+func NewAuditTarget(purger analytics.Purger) Target {
+	return &auditTarget{purger: purger}
+}
+
+// Count implements Target
+func (t *auditTarget) Count(ctx context.Context, cutoff time.Time) (int64, error) {
+	return t.purger.CountBefore(ctx, analytics.KindAudit, cutoff)
+}
+
+// Purge implements Target
+func (t *auditTarget) Purge(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return t.purger.DeleteBefore(ctx, analytics.KindAudit, cutoff)
+}
@@ -0,0 +1,93 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// defaultClamAVTimeout bounds a scan when no timeout is configured
+const defaultClamAVTimeout = 5 * time.Minute
+
+// clamAVChunkSize is the size of the chunks streamed to clamd
+const clamAVChunkSize = 64 << 10
+
+// ClamAVScanner scans content with a clamd daemon through its INSTREAM command
+type ClamAVScanner struct {
+	cfg config.FilesScanConfig
+}
+
+// NewClamAVScanner creates a scanner of the clamd daemon at cfg.Address
+func NewClamAVScanner(cfg *config.FilesScanConfig) *ClamAVScanner {
+	return &ClamAVScanner{cfg: *cfg}
+}
+
+// Scan streams r to clamd in length-prefixed chunks, then reads its verdict:
+// "stream: OK", "stream: <signature> FOUND" or "<reason> ERROR". Content over
+// the StreamMaxLength of clamd is refused with an error.
+func (s *ClamAVScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	timeout := s.cfg.Timeout
+	if timeout <= 0 {
+		timeout = defaultClamAVTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.cfg.Address)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	if _, err := io.WriteString(conn, "zINSTREAM\x00"); err != nil {
+		return Result{}, fmt.Errorf("failed to send to clamd: %w", err)
+	}
+	chunk := make([]byte, 4+clamAVChunkSize)
+	for {
+		n, readErr := io.ReadFull(r, chunk[4:])
+		if n > 0 {
+			binary.BigEndian.PutUint32(chunk, uint32(n))
+			if _, err := conn.Write(chunk[:4+n]); err != nil {
+				// clamd closes the connection once the stream is over its limit
+				break
+			}
+		}
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			break
+		}
+		if readErr != nil {
+			return Result{}, readErr
+		}
+	}
+	// A zero length chunk ends the stream
+	_, _ = conn.Write([]byte{0, 0, 0, 0})
+
+	reply, err := bufio.NewReader(conn).ReadString(0)
+	if err != nil && reply == "" {
+		return Result{}, fmt.Errorf("failed to read the clamd reply: %w", err)
+	}
+	return parseClamAVReply(strings.TrimRight(reply, "\x00\n"))
+}
+
+// parseClamAVReply returns the verdict of an INSTREAM reply
+func parseClamAVReply(reply string) (Result, error) {
+	verdict := strings.TrimPrefix(reply, "stream: ")
+	switch {
+	case verdict == "OK":
+		return Result{Clean: true}, nil
+	case strings.HasSuffix(verdict, " FOUND"):
+		return Result{Signature: strings.TrimSuffix(verdict, " FOUND")}, nil
+	}
+	return Result{}, fmt.Errorf("clamd: %s", reply)
+}
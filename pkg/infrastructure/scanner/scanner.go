@@ -0,0 +1,85 @@
+// Package scanner scans uploaded files for malware. Content is streamed to a
+// ClamAV daemon; when scanning is disabled every file is reported clean.
+package scanner
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Result is the verdict of a scan
+type Result struct {
+	// Clean is false when the content is infected
+	Clean bool
+	// Signature names what was found in infected content
+	Signature string
+}
+
+// Scanner scans content for malware
+type Scanner interface {
+	// Scan reads r to its end and returns the verdict on its content
+	Scan(ctx context.Context, r io.Reader) (Result, error)
+}
+
+var (
+	// Scan counter; result is clean, infected or error
+	scansTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "file_scans_total",
+			Help: "Total number of malware scans of uploaded files",
+		},
+		[]string{"result"},
+	)
+
+	// Scan duration histogram
+	scanDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "file_scan_duration_seconds",
+			Help:    "Malware scan duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+	)
+)
+
+// New creates the configured scanner with scan metrics. A disabled scanner
+// reports all content clean.
+func New(cfg *config.FilesScanConfig) Scanner {
+	if !cfg.Enabled {
+		return nopScanner{}
+	}
+	return &instrumentedScanner{next: NewClamAVScanner(cfg)}
+}
+
+// nopScanner reports all content clean without reading it
+type nopScanner struct{}
+
+// Scan implements Scanner
+func (nopScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	return Result{Clean: true}, nil
+}
+
+// instrumentedScanner records the outcome and duration of scans
+type instrumentedScanner struct {
+	next Scanner
+}
+
+// Scan implements Scanner
+func (s *instrumentedScanner) Scan(ctx context.Context, r io.Reader) (Result, error) {
+	start := time.Now()
+	result, err := s.next.Scan(ctx, r)
+	scanDuration.Observe(time.Since(start).Seconds())
+	switch {
+	case err != nil:
+		scansTotal.WithLabelValues("error").Inc()
+	case result.Clean:
+		scansTotal.WithLabelValues("clean").Inc()
+	default:
+		scansTotal.WithLabelValues("infected").Inc()
+	}
+	return result, err
+}
@@ -0,0 +1,122 @@
+package seed
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// The sample dataset: the acme organization, an admin and a developer who
+// are its owner and member, and applications of the organization
+const (
+	SampleOrganization = "acme"
+	SampleAdminEmail   = "admin@example.com"
+	SampleUserEmail    = "developer@example.com"
+	// SamplePassword is the password of the sample users
+	SamplePassword = "demo-password"
+)
+
+func init() {
+	Register(Seeder{
+		Name:  "organizations",
+		Model: &model.Organization{},
+		Seed: func(ctx context.Context, store datastore.DatastoreInterface) error {
+			_, err := Upsert(ctx, store, &model.Organization{
+				Name:        SampleOrganization,
+				Description: "Sample organization for development and demos",
+			}, "name")
+			return err
+		},
+	})
+	Register(Seeder{
+		Name:  "users",
+		Model: &model.User{},
+		Seed:  seedUsers,
+	})
+	Register(Seeder{
+		Name:      "organization_members",
+		Model:     &model.OrganizationMember{},
+		DependsOn: []string{"organizations", "users"},
+		Seed:      seedOrganizationMembers,
+	})
+	Register(Seeder{
+		Name:      "applications",
+		Model:     &model.Application{},
+		DependsOn: []string{"organizations", "users"},
+		Seed:      seedApplications,
+	})
+}
+
+// seedUsers upserts the sample users, whose password is SamplePassword
+func seedUsers(ctx context.Context, store datastore.DatastoreInterface) error {
+	hash, err := bcrypt.GenerateFromPassword([]byte(SamplePassword), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	users := []*model.User{
+		{Email: SampleAdminEmail, Username: "Admin", Role: "admin", Permissions: model.StringList{}},
+		{Email: SampleUserEmail, Username: "Developer", Role: "user", Permissions: model.StringList{}},
+	}
+	for _, user := range users {
+		user.PasswordHash = string(hash)
+		if _, err := Upsert(ctx, store, user, "email"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedOrganizationMembers makes the sample admin the owner of the sample
+// organization and the sample developer a member
+func seedOrganizationMembers(ctx context.Context, store datastore.DatastoreInterface) error {
+	org, err := sampleOrganization(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	members := []*model.OrganizationMember{
+		{OrgID: org.ID, UserID: SampleAdminEmail, Role: model.OrganizationRoleOwner},
+		{OrgID: org.ID, UserID: SampleUserEmail, Role: model.OrganizationRoleMember},
+	}
+	for _, member := range members {
+		if _, err := Upsert(ctx, store, member, "org_id", "user_id"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// seedApplications upserts applications of the sample organization owned by the sample developer
+func seedApplications(ctx context.Context, store datastore.DatastoreInterface) error {
+	org, err := sampleOrganization(ctx, store)
+	if err != nil {
+		return err
+	}
+
+	apps := []*model.Application{
+		{Name: "storefront", Description: "Customer facing web shop", Tags: model.StringList{"team:web", "env:demo"}},
+		{Name: "billing", Description: "Invoices and payments", Tags: model.StringList{"team:payments", "env:demo"}},
+		{Name: "inventory", Description: "Stock levels of the warehouses", Tags: model.StringList{"team:logistics", "env:demo"}},
+	}
+	for _, app := range apps {
+		app.OrgID = org.ID
+		app.OwnerID = SampleUserEmail
+		if _, err := Upsert(ctx, store, app, "org_id", "name"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// sampleOrganization returns the organization seeded by the organizations seeder
+func sampleOrganization(ctx context.Context, store datastore.DatastoreInterface) (*model.Organization, error) {
+	org, err := Find[*model.Organization](ctx, store, map[string]interface{}{"name": SampleOrganization})
+	if err != nil {
+		return nil, fmt.Errorf("organization %s: %w", SampleOrganization, err)
+	}
+	return org, nil
+}
@@ -0,0 +1,197 @@
+// Package seed populates a datastore with data for development and demos.
+// Seeders register themselves per model in init functions and run after the
+// seeders they depend on, see the "seed" subcommand. Seeders upsert by a
+// natural key, so seeding again updates the seeded records instead of
+// duplicating them.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+)
+
+// Seeder seeds the records of one model
+type Seeder struct {
+	// Name selects the seeder on the command line, e.g. "users"
+	Name string
+	// Model is the model seeded; seeders of models of disabled modules are skipped
+	Model model.Entity
+	// DependsOn names the seeders whose records must exist first
+	DependsOn []string
+	// Seed upserts the records into store
+	Seed func(ctx context.Context, store datastore.DatastoreInterface) error
+}
+
+var (
+	mu      sync.RWMutex
+	seeders = map[string]Seeder{}
+)
+
+// Register makes a seeder available under its name. It panics when a seeder
+// of the same name is already registered.
+func Register(s Seeder) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := seeders[s.Name]; ok {
+		panic(fmt.Sprintf("seeder %q already registered", s.Name))
+	}
+	seeders[s.Name] = s
+}
+
+// Seeders returns the registered seeders, each after the seeders it depends
+// on and otherwise by name
+func Seeders() ([]Seeder, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return order(names())
+}
+
+// CheckEnvironment refuses to seed production, and other environments than
+// development and test unless force is set
+func CheckEnvironment(env string, force bool) error {
+	switch strings.ToLower(env) {
+	case "development", "test":
+		return nil
+	case "production":
+		return fmt.Errorf("refusing to seed the production environment")
+	}
+	if !force {
+		return fmt.Errorf("refusing to seed the %s environment without -force", env)
+	}
+	return nil
+}
+
+// Run runs the named seeders and the seeders they depend on, or every
+// registered seeder without names. Seeders of disabled modules are skipped.
+func Run(ctx context.Context, store datastore.DatastoreInterface, selected ...string) error {
+	mu.RLock()
+	if len(selected) == 0 {
+		selected = names()
+	}
+	ordered, err := order(selected)
+	mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	for _, s := range ordered {
+		if len(module.Models([]interface{}{s.Model})) == 0 {
+			logger.Info("Skipping seeder %s, its module is disabled", s.Name)
+			continue
+		}
+		logger.Info("Seeding %s...", s.Name)
+		if err := s.Seed(ctx, store); err != nil {
+			return fmt.Errorf("seeder %s: %w", s.Name, err)
+		}
+	}
+	return nil
+}
+
+// Upsert creates entity, or updates the existing entity with the same values
+// in the key columns of Entity.Index and returns it
+func Upsert[T model.Entity](ctx context.Context, store datastore.DatastoreInterface, entity T, keys ...string) (T, error) {
+	index := entity.Index()
+	filters := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		value, ok := index[key]
+		if !ok {
+			return entity, fmt.Errorf("%w: %s is not an index of %s", datastore.ErrInvalidInput, key, entity.TableName())
+		}
+		filters[key] = value
+	}
+
+	repo, err := datastore.NewRepository[T](store)
+	if err != nil {
+		return entity, err
+	}
+	existing, err := find(ctx, repo, filters)
+	switch {
+	case err == datastore.ErrNotFound:
+		return repo.Create(ctx, entity)
+	case err != nil:
+		return entity, err
+	}
+	entity.SetID(existing.GetID())
+	return repo.Update(ctx, entity)
+}
+
+// Find returns the first entity matching filters, ErrNotFound when none does
+func Find[T model.Entity](ctx context.Context, store datastore.DatastoreInterface, filters map[string]interface{}) (T, error) {
+	repo, err := datastore.NewRepository[T](store)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return find(ctx, repo, filters)
+}
+
+// find returns the first entity of repo matching filters
+func find[T model.Entity](ctx context.Context, repo datastore.Repository[T], filters map[string]interface{}) (T, error) {
+	var zero T
+	entities, err := repo.List(ctx, datastore.ListOptions{Size: 1, Filters: filters})
+	if err != nil {
+		return zero, err
+	}
+	if len(entities) == 0 {
+		return zero, datastore.ErrNotFound
+	}
+	return entities[0], nil
+}
+
+// names returns the names of the registered seeders, sorted; mu must be held
+func names() []string {
+	sorted := make([]string, 0, len(seeders))
+	for name := range seeders {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// order returns the selected seeders and their dependencies, each after its
+// dependencies; mu must be held
+func order(selected []string) ([]Seeder, error) {
+	var ordered []Seeder
+	state := map[string]int{} // 1 while visiting, 2 once ordered
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("seeders depend on each other: %s", strings.Join(append(path, name), " -> "))
+		case 2:
+			return nil
+		}
+		s, ok := seeders[name]
+		if !ok {
+			if len(path) > 0 {
+				return fmt.Errorf("seeder %q of %s is not registered", name, path[len(path)-1])
+			}
+			return fmt.Errorf("unknown seeder %q, registered seeders are %s", name, strings.Join(names(), ", "))
+		}
+
+		state[name] = 1
+		for _, dependency := range s.DependsOn {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		ordered = append(ordered, s)
+		return nil
+	}
+
+	for _, name := range selected {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,91 @@
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Verifier checks the signatures of partner requests and remembers the nonces
+// of the valid ones until their timestamp leaves the replay window
+type Verifier struct {
+	maxSkew time.Duration
+	nonces  quota.Store
+	clock   clock.Clock
+}
+
+// New creates a verifier remembering nonces in the store selected by
+// security.signing.nonce_store. The Redis store shares nonces between instances.
+func New(cfg *config.Config, clk clock.Clock) (*Verifier, error) {
+	var store quota.Store
+	switch cfg.Security.Signing.NonceStore {
+	case quota.StoreMemory, "":
+		store = quota.NewMemoryStore()
+	case quota.StoreRedis:
+		client, err := infra_middleware.NewRedisClient(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect signature nonce store: %w", err)
+		}
+		store = quota.NewRedisStore(client)
+	default:
+		return nil, fmt.Errorf("unsupported signature nonce store: %s", cfg.Security.Signing.NonceStore)
+	}
+	return NewVerifier(cfg.Security.Signing.MaxSkew, store, clk), nil
+}
+
+// NewVerifier creates a verifier accepting timestamps up to maxSkew away from
+// the current time and remembering nonces in store
+func NewVerifier(maxSkew time.Duration, store quota.Store, clk clock.Clock) *Verifier {
+	if clk == nil {
+		clk = clock.New()
+	}
+	return &Verifier{maxSkew: maxSkew, nonces: store, clock: clk}
+}
+
+// Verify checks that sig signs the request with one of secrets and that its
+// timestamp is within the replay window, then records its nonce. The nonce is
+// only recorded for valid signatures, so that forged requests cannot use it up.
+func (v *Verifier) Verify(ctx context.Context, r *http.Request, sig *Signature, body []byte, secrets []string) error {
+	now := v.clock.Now()
+	if sig.Timestamp.Before(now.Add(-v.maxSkew)) || sig.Timestamp.After(now.Add(v.maxSkew)) {
+		return ErrSignatureExpired
+	}
+
+	stringToSign := StringToSign(r.Method, r.URL.RequestURI(), sig.Timestamp, sig.Nonce, body)
+	valid := false
+	for _, secret := range secrets {
+		if hmac.Equal([]byte(Sign(secret, stringToSign)), []byte(sig.Value)) {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return ErrSignatureInvalid
+	}
+
+	// A nonce can be replayed until its timestamp leaves the window on either side
+	uses, err := v.nonces.Increment(ctx, "signature:nonce:"+sig.KeyID+":"+sig.Nonce, sig.Timestamp.Add(v.maxSkew+time.Second))
+	if err != nil {
+		return fmt.Errorf("failed to record signature nonce: %w", err)
+	}
+	if uses > 1 {
+		return ErrNonceReused
+	}
+	return nil
+}
+
+// OnStop closes the nonce store when it holds a connection
+func (v *Verifier) OnStop(ctx context.Context) error {
+	if closer, ok := v.nonces.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package snapshot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+)
+
+// Rule anonymizes the values of a column
+type Rule string
+
+// Anonymization rules. Email and name pseudonyms are derived from the value
+// with a keyed hash, so equal values map to equal pseudonyms across tables and
+// references such as user IDs stay consistent within a snapshot.
+const (
+	// RuleEmail replaces values with a pseudonymous address, user-<hash>@example.com
+	RuleEmail Rule = "email"
+	// RuleName replaces values with a pseudonymous name, user-<hash>
+	RuleName Rule = "name"
+	// RuleIP zeroes the host part of addresses, keeping the /24 of IPv4 and the /48 of IPv6
+	RuleIP Rule = "ip"
+	// RuleMask replaces values with MaskedValue
+	RuleMask Rule = "mask"
+	// RuleClear replaces values with the zero value of the column
+	RuleClear Rule = "clear"
+)
+
+// MaskedValue replaces the values of columns masked with RuleMask, as masked
+// response fields and redacted settings
+const MaskedValue = "******"
+
+// pseudonymDomain is the domain of pseudonymous email addresses, reserved by RFC 2606
+const pseudonymDomain = "example.com"
+
+// sensitiveSegments are column name segments of secrets. Columns named with
+// one, such as password_hash or refresh_token_hash, are cleared without a rule.
+var sensitiveSegments = []string{"password", "secret", "token", "nonce"}
+
+// pseudonymizer derives pseudonyms from values with a keyed hash
+type pseudonymizer struct {
+	key []byte
+}
+
+// token returns the pseudonymous token of value, ignoring case and surrounding spaces
+func (p pseudonymizer) token(value string) string {
+	mac := hmac.New(sha256.New, p.key)
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(value))))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// apply anonymizes a string value with rule, empty values stay empty
+func (p pseudonymizer) apply(rule Rule, value string) string {
+	if value == "" {
+		return value
+	}
+	switch rule {
+	case RuleEmail:
+		return fmt.Sprintf("user-%s@%s", p.token(value), pseudonymDomain)
+	case RuleName:
+		return "user-" + p.token(value)
+	case RuleIP:
+		return anonymizeIP(value)
+	case RuleMask:
+		return MaskedValue
+	default:
+		return ""
+	}
+}
+
+// anonymize applies rule to the column value v, a string or a list of strings.
+// RuleClear applies to columns of any type.
+func (p pseudonymizer) anonymize(rule Rule, v reflect.Value) error {
+	if rule == RuleClear {
+		v.Set(reflect.Zero(v.Type()))
+		return nil
+	}
+
+	switch {
+	case v.Kind() == reflect.String:
+		v.SetString(p.apply(rule, v.String()))
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		if v.IsNil() {
+			return nil
+		}
+		values := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			values.Index(i).SetString(p.apply(rule, v.Index(i).String()))
+		}
+		v.Set(values)
+	default:
+		return fmt.Errorf("rule %s does not apply to %s values", rule, v.Type())
+	}
+	return nil
+}
+
+// validate reports whether rule is one of the anonymization rules
+func (r Rule) validate() error {
+	switch r {
+	case RuleEmail, RuleName, RuleIP, RuleMask, RuleClear:
+		return nil
+	}
+	return fmt.Errorf("unknown anonymization rule %q", r)
+}
+
+// anonymizeIP zeroes the host part of an IP address, invalid addresses are cleared
+func anonymizeIP(value string) string {
+	ip := net.ParseIP(strings.TrimSpace(value))
+	if ip == nil {
+		return ""
+	}
+	if v4 := ip.To4(); v4 != nil {
+		return v4.Mask(net.CIDRMask(24, 32)).String()
+	}
+	return ip.Mask(net.CIDRMask(48, 128)).String()
+}
+
+// sensitiveColumn reports whether a column name holds a secret
+func sensitiveColumn(column string) bool {
+	for _, segment := range strings.Split(strings.ToLower(column), "_") {
+		for _, sensitive := range sensitiveSegments {
+			if segment == sensitive {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,538 @@
+// Package snapshot dumps tables of a SQL datastore into an archive with the
+// personal data anonymized, and restores such archives, e.g. to build staging
+// datasets from production. Tables are registered with the anonymization rules
+// of their columns; columns named like secrets are cleared without a rule.
+//
+// An archive is a gzip compressed tar of manifest.json followed by one
+// tables/<table>.jsonl entry per table, holding a JSON object per row keyed by
+// column name. Rows keep their IDs, so references between tables survive.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// archiveVersion is the version of the archive layout
+const archiveVersion = 1
+
+// Entries of an archive
+const (
+	manifestEntry = "manifest.json"
+	tablesDir     = "tables/"
+	tableSuffix   = ".jsonl"
+)
+
+// batchSize is the number of rows read or inserted per statement
+const batchSize = 500
+
+// ErrInvalidArchive is returned when an archive cannot be restored
+var ErrInvalidArchive = errors.New("invalid snapshot archive")
+
+// Table is a table included in snapshots
+type Table struct {
+	// Model is the model of the table; tables of models of disabled modules are skipped
+	Model model.Entity
+	// Columns maps column names to the rule anonymizing their values
+	Columns map[string]Rule
+	// Filters select the rows dumped by equality, all rows when empty
+	Filters map[string]interface{}
+}
+
+// Name returns the name of the table
+func (t Table) Name() string {
+	return t.Model.TableName()
+}
+
+// Manifest describes the content of an archive
+type Manifest struct {
+	Version     int             `json:"version"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Environment string          `json:"environment,omitempty"`
+	Tables      []TableManifest `json:"tables"`
+}
+
+// TableManifest describes a table of an archive
+type TableManifest struct {
+	Name string `json:"name"`
+	Rows int64  `json:"rows"`
+	// Anonymized lists the anonymized columns and their rules, as column:rule
+	Anonymized []string `json:"anonymized"`
+}
+
+// DumpOptions configures Dump
+type DumpOptions struct {
+	// Tables names the dumped tables, all registered tables when empty
+	Tables []string
+	// Exclude names tables left out, e.g. those held by another database
+	Exclude []string
+	// Key derives the pseudonyms of RuleEmail and RuleName. The same key yields
+	// the same pseudonyms across snapshots; a random key is used when empty.
+	Key []byte
+	// Environment is recorded in the manifest
+	Environment string
+}
+
+// RestoreOptions configures Restore
+type RestoreOptions struct {
+	// Tables names the restored tables, all tables of the archive when empty
+	Tables []string
+	// Exclude names tables of the archive left out
+	Exclude []string
+	// Replace deletes the rows of the restored tables first, including soft
+	// deleted ones; otherwise rows conflicting with existing ones fail the restore
+	Replace bool
+}
+
+var (
+	mu     sync.RWMutex
+	tables []Table
+)
+
+// Register includes a table in snapshots. Tables are dumped and restored in
+// the order they are registered. It panics when the table is already
+// registered or a rule is unknown.
+func Register(t Table) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, registered := range tables {
+		if registered.Name() == t.Name() {
+			panic(fmt.Sprintf("snapshot table %q already registered", t.Name()))
+		}
+	}
+	for column, rule := range t.Columns {
+		if err := rule.validate(); err != nil {
+			panic(fmt.Sprintf("snapshot table %q column %q: %v", t.Name(), column, err))
+		}
+	}
+	tables = append(tables, t)
+}
+
+// Tables returns the registered tables in the order they are registered
+func Tables() []Table {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]Table(nil), tables...)
+}
+
+// Dump writes an archive of the selected tables of db to w, anonymizing
+// their columns. Rows of each table are written in ID order, soft deleted
+// rows included. Tables of disabled modules are skipped.
+func Dump(ctx context.Context, db *gorm.DB, w io.Writer, opts DumpOptions) (*Manifest, error) {
+	selected, err := selectTables(opts.Tables, opts.Exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	key := opts.Key
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+	}
+	p := pseudonymizer{key: key}
+
+	// Tables are dumped to temporary files first, so that the manifest with
+	// the row counts leads the archive
+	var files []*os.File
+	defer func() {
+		for _, file := range files {
+			file.Close()
+			os.Remove(file.Name())
+		}
+	}()
+
+	manifest := &Manifest{Version: archiveVersion, CreatedAt: time.Now().UTC(), Environment: opts.Environment}
+	for _, t := range selected {
+		file, err := os.CreateTemp("", "snapshot-*"+tableSuffix)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+
+		logger.Info("Dumping table %s...", t.Name())
+		table, err := dumpTable(ctx, db, t, p, file)
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", t.Name(), err)
+		}
+		manifest.Tables = append(manifest.Tables, table)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestEntry, int64(len(data)), strings.NewReader(string(data))); err != nil {
+		return nil, err
+	}
+	for i, file := range files {
+		info, err := file.Stat()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		if err := writeEntry(tw, tablesDir+manifest.Tables[i].Name+tableSuffix, info.Size(), file); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Restore inserts the rows of the selected tables of an archive into db in one
+// transaction and returns the manifest of the archive. Rows keep their IDs and
+// timestamps and are inserted without hooks; ID sequences of PostgreSQL
+// compatible databases are moved past the restored IDs. Tables of disabled
+// modules are skipped.
+func Restore(ctx context.Context, db *gorm.DB, r io.Reader, opts RestoreOptions) (*Manifest, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	header, err := tr.Next()
+	if err != nil || header.Name != manifestEntry {
+		return nil, fmt.Errorf("%w: missing %s", ErrInvalidArchive, manifestEntry)
+	}
+	manifest := &Manifest{}
+	if err := json.NewDecoder(tr).Decode(manifest); err != nil {
+		return nil, fmt.Errorf("%w: invalid %s: %v", ErrInvalidArchive, manifestEntry, err)
+	}
+	if manifest.Version != archiveVersion {
+		return nil, fmt.Errorf("%w: unsupported archive version %d", ErrInvalidArchive, manifest.Version)
+	}
+
+	selected := make(map[string]bool, len(opts.Tables))
+	for _, name := range opts.Tables {
+		if !manifest.has(name) {
+			return nil, fmt.Errorf("table %q is not in the archive, its tables are %s", name, strings.Join(manifest.names(), ", "))
+		}
+		selected[name] = true
+	}
+
+	err = db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+			}
+
+			name := strings.TrimSuffix(strings.TrimPrefix(header.Name, tablesDir), tableSuffix)
+			if (len(selected) > 0 && !selected[name]) || excluded(opts.Exclude, name) {
+				continue
+			}
+			t, ok := lookup(name)
+			if !ok {
+				return fmt.Errorf("table %s of the archive is not registered", name)
+			}
+			if !enabled(t) {
+				logger.Info("Skipping table %s, its module is disabled", name)
+				continue
+			}
+
+			logger.Info("Restoring table %s...", name)
+			rows, err := restoreTable(ctx, tx, t, tr, opts.Replace)
+			if err != nil {
+				return fmt.Errorf("table %s: %w", name, err)
+			}
+			logger.Info("Restored %d rows of table %s", rows, name)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// dumpTable writes the anonymized rows of t as JSON lines to out
+func dumpTable(ctx context.Context, db *gorm.DB, t Table, p pseudonymizer, out io.Writer) (TableManifest, error) {
+	table := TableManifest{Name: t.Name()}
+	s, err := parseSchema(db, t.Model)
+	if err != nil {
+		return table, err
+	}
+	rules, err := t.rules(s)
+	if err != nil {
+		return table, err
+	}
+	for _, field := range s.Fields {
+		if rule, ok := rules[field.DBName]; ok && field.DBName != "" {
+			table.Anonymized = append(table.Anonymized, field.DBName+":"+string(rule))
+		}
+	}
+
+	buf := bufio.NewWriter(out)
+	encoder := json.NewEncoder(buf)
+	rows := reflect.New(reflect.SliceOf(reflect.TypeOf(t.Model)))
+	query := db.WithContext(ctx).Unscoped().Model(t.Model)
+	if len(t.Filters) > 0 {
+		query = query.Where(t.Filters)
+	}
+	result := query.FindInBatches(rows.Interface(), batchSize, func(tx *gorm.DB, batch int) error {
+		for i := 0; i < rows.Elem().Len(); i++ {
+			row := rows.Elem().Index(i).Elem()
+			record := make(map[string]interface{}, len(s.Fields))
+			for _, field := range s.Fields {
+				if field.DBName == "" {
+					continue
+				}
+				if rule, ok := rules[field.DBName]; ok {
+					if err := p.anonymize(rule, field.ReflectValueOf(ctx, row)); err != nil {
+						return fmt.Errorf("column %s: %w", field.DBName, err)
+					}
+				}
+				record[field.DBName], _ = field.ValueOf(ctx, row)
+			}
+			if err := encoder.Encode(record); err != nil {
+				return err
+			}
+			table.Rows++
+		}
+		return nil
+	})
+	if result.Error != nil {
+		return table, datastore.TranslateGormError(result.Error)
+	}
+	return table, buf.Flush()
+}
+
+// restoreTable inserts the rows of t read as JSON lines from in and returns
+// how many were inserted
+func restoreTable(ctx context.Context, tx *gorm.DB, t Table, in io.Reader, replace bool) (int64, error) {
+	s, err := parseSchema(tx, t.Model)
+	if err != nil {
+		return 0, err
+	}
+	if replace {
+		result := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(reflect.New(s.ModelType).Interface())
+		if result.Error != nil {
+			return 0, datastore.TranslateGormError(result.Error)
+		}
+	}
+
+	sliceType := reflect.SliceOf(reflect.PointerTo(s.ModelType))
+	batch := reflect.New(sliceType)
+	var rows int64
+	insert := func() error {
+		if batch.Elem().Len() == 0 {
+			return nil
+		}
+		if err := tx.Session(&gorm.Session{SkipHooks: true}).Create(batch.Interface()).Error; err != nil {
+			return datastore.TranslateGormError(err)
+		}
+		rows += int64(batch.Elem().Len())
+		batch.Elem().Set(reflect.MakeSlice(sliceType, 0, batchSize))
+		return nil
+	}
+
+	unknown := map[string]bool{}
+	decoder := json.NewDecoder(in)
+	for {
+		var record map[string]json.RawMessage
+		if err := decoder.Decode(&record); err == io.EOF {
+			break
+		} else if err != nil {
+			return rows, fmt.Errorf("%w: %v", ErrInvalidArchive, err)
+		}
+
+		entity := reflect.New(s.ModelType)
+		for column, raw := range record {
+			field := s.LookUpField(column)
+			if field == nil || field.DBName == "" {
+				unknown[column] = true
+				continue
+			}
+			value := reflect.New(field.FieldType)
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				return rows, fmt.Errorf("%w: column %s: %v", ErrInvalidArchive, column, err)
+			}
+			if err := field.Set(ctx, entity.Elem(), value.Elem().Interface()); err != nil {
+				return rows, fmt.Errorf("column %s: %w", column, err)
+			}
+		}
+		batch.Elem().Set(reflect.Append(batch.Elem(), entity))
+		if batch.Elem().Len() >= batchSize {
+			if err := insert(); err != nil {
+				return rows, err
+			}
+		}
+	}
+	if err := insert(); err != nil {
+		return rows, err
+	}
+	if len(unknown) > 0 {
+		columns := make([]string, 0, len(unknown))
+		for column := range unknown {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		logger.Warn("Ignored columns of table %s missing from the model: %s", t.Name(), strings.Join(columns, ", "))
+	}
+
+	if tx.Dialector.Name() == "postgres" && s.PrioritizedPrimaryField != nil && s.PrioritizedPrimaryField.AutoIncrement {
+		if err := resetSequence(tx, s); err != nil {
+			return rows, err
+		}
+	}
+	return rows, nil
+}
+
+// resetSequence moves the ID sequence of a table past its largest ID, so that
+// rows created after a restore do not collide with restored ones
+func resetSequence(tx *gorm.DB, s *schema.Schema) error {
+	column := s.PrioritizedPrimaryField.DBName
+	sql := fmt.Sprintf("SELECT setval(pg_get_serial_sequence(?, ?), MAX(%s)) FROM %s",
+		tx.Statement.Quote(column), tx.Statement.Quote(s.Table))
+	return datastore.TranslateGormError(tx.Exec(sql, s.Table, column).Error)
+}
+
+// rules returns the rules of the columns of t, clearing secret columns
+// without a rule
+func (t Table) rules(s *schema.Schema) (map[string]Rule, error) {
+	for column := range t.Columns {
+		if field := s.LookUpField(column); field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("no column %s to anonymize", column)
+		}
+	}
+
+	rules := make(map[string]Rule, len(t.Columns))
+	for _, field := range s.Fields {
+		if field.DBName == "" {
+			continue
+		}
+		if rule, ok := t.Columns[field.DBName]; ok {
+			rules[field.DBName] = rule
+		} else if sensitiveColumn(field.DBName) {
+			rules[field.DBName] = RuleClear
+		}
+	}
+	return rules, nil
+}
+
+// has reports whether the archive has a table
+func (m *Manifest) has(name string) bool {
+	for _, table := range m.Tables {
+		if table.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// names returns the names of the tables of the archive
+func (m *Manifest) names() []string {
+	names := make([]string, 0, len(m.Tables))
+	for _, table := range m.Tables {
+		names = append(names, table.Name)
+	}
+	return names
+}
+
+// selectTables returns the named tables in registration order, or all enabled
+// tables without names, leaving out the excluded tables
+func selectTables(names, exclude []string) ([]Table, error) {
+	registered := Tables()
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := lookup(name); !ok {
+			known := make([]string, 0, len(registered))
+			for _, t := range registered {
+				known = append(known, t.Name())
+			}
+			return nil, fmt.Errorf("unknown table %q, registered tables are %s", name, strings.Join(known, ", "))
+		}
+		wanted[name] = true
+	}
+
+	var selected []Table
+	for _, t := range registered {
+		if (len(wanted) > 0 && !wanted[t.Name()]) || excluded(exclude, t.Name()) {
+			continue
+		}
+		if !enabled(t) {
+			logger.Info("Skipping table %s, its module is disabled", t.Name())
+			continue
+		}
+		selected = append(selected, t)
+	}
+	return selected, nil
+}
+
+// excluded reports whether name is one of the excluded tables
+func excluded(exclude []string, name string) bool {
+	for _, e := range exclude {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup returns the registered table of a name
+func lookup(name string) (Table, bool) {
+	for _, t := range Tables() {
+		if t.Name() == name {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
+
+// enabled reports whether the module of the model of t is enabled
+func enabled(t Table) bool {
+	return len(module.Models([]interface{}{t.Model})) > 0
+}
+
+// parseSchema returns the GORM schema of a model
+func parseSchema(db *gorm.DB, m model.Entity) (*schema.Schema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(m); err != nil {
+		return nil, err
+	}
+	return stmt.Schema, nil
+}
+
+// writeEntry writes a file entry of size bytes read from r to an archive
+func writeEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	header := &tar.Header{Name: name, Mode: 0644, Size: size, ModTime: time.Now()}
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
@@ -0,0 +1,52 @@
+package snapshot
+
+import "github.com/make-bin/server-tpl/pkg/domain/model"
+
+// User IDs are the email addresses of users, so every column holding a user
+// ID is anonymized with RuleEmail and keeps referencing the same user.
+func init() {
+	Register(Table{Model: &model.Organization{}})
+	Register(Table{
+		Model:   &model.User{},
+		Columns: map[string]Rule{"email": RuleEmail, "username": RuleName},
+	})
+	Register(Table{
+		Model:   &model.OrganizationMember{},
+		Columns: map[string]Rule{"user_id": RuleEmail},
+	})
+	Register(Table{
+		Model:   &model.UserPreferences{},
+		Columns: map[string]Rule{"user_id": RuleEmail},
+	})
+	Register(Table{
+		Model:   &model.NotificationPreference{},
+		Columns: map[string]Rule{"user_id": RuleEmail, "address": RuleMask},
+	})
+	Register(Table{
+		Model:   &model.Invitation{},
+		Columns: map[string]Rule{"email": RuleEmail, "invited_by": RuleEmail, "accepted_by": RuleEmail},
+	})
+	Register(Table{
+		Model:   &model.Application{},
+		Columns: map[string]Rule{"owner_id": RuleEmail},
+	})
+	// Values of secret variables are encrypted with the key of the source
+	// environment, the variables are left out
+	Register(Table{
+		Model:   &model.ApplicationVariable{},
+		Filters: map[string]interface{}{"secret": false},
+	})
+	Register(Table{
+		Model:   &model.ApplicationRevision{},
+		Columns: map[string]Rule{"actor": RuleEmail},
+	})
+	Register(Table{
+		Model:   &model.FeatureFlag{},
+		Columns: map[string]Rule{"target_users": RuleEmail},
+	})
+	Register(Table{Model: &model.PolicyDocument{}})
+	Register(Table{
+		Model:   &model.PolicyConsent{},
+		Columns: map[string]Rule{"user_id": RuleEmail, "ip_address": RuleIP, "user_agent": RuleMask},
+	})
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryObject is an object held by MemoryStorage
+type memoryObject struct {
+	data    []byte
+	modTime time.Time
+}
+
+// MemoryStorage implements Storage in memory; objects are lost on restart
+type MemoryStorage struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+}
+
+// NewMemoryStorage creates an empty in-memory storage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{objects: make(map[string]memoryObject)}
+}
+
+// Put reads r fully and stores a copy of its content
+func (s *MemoryStorage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[cleaned] = memoryObject{data: data, modTime: time.Now()}
+	return int64(len(data)), nil
+}
+
+// Get returns a reader over the stored content
+func (s *MemoryStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	object, ok := s.objects[cleaned]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(object.data)), nil
+}
+
+// Delete removes the stored content
+func (s *MemoryStorage) Delete(ctx context.Context, key string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.objects[cleaned]; !ok {
+		return ErrNotFound
+	}
+	delete(s.objects, cleaned)
+	return nil
+}
+
+// List returns the stored objects under prefix
+func (s *MemoryStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	objects := []ObjectInfo{}
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, ObjectInfo{Key: key, Size: int64(len(object.data)), ModTime: object.modTime})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+// Presign is not supported, the objects live in the process
+func (s *MemoryStorage) Presign(ctx context.Context, key string, opts PresignOptions) (string, error) {
+	return "", ErrPresignNotSupported
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Storage operation counter; status is ok, not_found or error
+	operationsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "storage_operations_total",
+			Help: "Total number of object storage operations",
+		},
+		[]string{"backend", "operation", "status"},
+	)
+
+	// Storage operation duration histogram; Get is timed until the object is opened
+	operationDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "storage_operation_duration_seconds",
+			Help:    "Object storage operation duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"backend", "operation"},
+	)
+
+	// Stored bytes counter
+	uploadedBytes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "storage_uploaded_bytes_total",
+			Help: "Total number of bytes written to the object storage",
+		},
+		[]string{"backend"},
+	)
+)
+
+// instrumentedStorage records the metrics of the operations of a storage
+type instrumentedStorage struct {
+	backend string
+	next    Storage
+}
+
+// observe records the outcome of an operation started at start
+func (s *instrumentedStorage) observe(operation string, start time.Time, err error) {
+	status := "ok"
+	if errors.Is(err, ErrNotFound) {
+		status = "not_found"
+	} else if err != nil {
+		status = "error"
+	}
+	operationsTotal.WithLabelValues(s.backend, operation, status).Inc()
+	operationDuration.WithLabelValues(s.backend, operation).Observe(time.Since(start).Seconds())
+}
+
+// Put implements Storage
+func (s *instrumentedStorage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	start := time.Now()
+	size, err := s.next.Put(ctx, key, r)
+	s.observe("put", start, err)
+	if err == nil {
+		uploadedBytes.WithLabelValues(s.backend).Add(float64(size))
+	}
+	return size, err
+}
+
+// Get implements Storage
+func (s *instrumentedStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	start := time.Now()
+	object, err := s.next.Get(ctx, key)
+	s.observe("get", start, err)
+	return object, err
+}
+
+// Delete implements Storage
+func (s *instrumentedStorage) Delete(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.next.Delete(ctx, key)
+	s.observe("delete", start, err)
+	return err
+}
+
+// List implements Storage
+func (s *instrumentedStorage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	start := time.Now()
+	objects, err := s.next.List(ctx, prefix)
+	s.observe("list", start, err)
+	return objects, err
+}
+
+// Presign implements Storage
+func (s *instrumentedStorage) Presign(ctx context.Context, key string, opts PresignOptions) (string, error) {
+	start := time.Now()
+	url, err := s.next.Presign(ctx, key, opts)
+	s.observe("presign", start, err)
+	return url, err
+}
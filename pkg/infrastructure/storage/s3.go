@@ -0,0 +1,447 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+)
+
+const (
+	// s3Algorithm is the AWS Signature Version 4 algorithm
+	s3Algorithm = "AWS4-HMAC-SHA256"
+	// s3UnsignedPayload leaves request bodies out of the signature so they are streamed
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxPresignExpiry is the longest validity of a presigned URL accepted by S3
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+	// DefaultS3Region is the region signed for when none is configured
+	DefaultS3Region = "us-east-1"
+)
+
+// S3Storage implements Storage on a bucket of AWS S3 or an S3-compatible
+// service such as MinIO, signing requests with AWS Signature Version 4
+type S3Storage struct {
+	client       *http.Client
+	endpoint     *url.URL
+	bucket       string
+	region       string
+	pathStyle    bool
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	now          func() time.Time
+}
+
+// NewS3Storage creates a storage on the configured bucket. Missing credentials
+// are read from AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN.
+// Requests go through an outbound client of http_client without its overall
+// timeout, since transfers of large objects are bounded by their context.
+func NewS3Storage(cfg *config.StorageS3Config, clientCfg *config.HTTPClientConfig) (*S3Storage, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 storage requires a bucket")
+	}
+	region := cfg.Region
+	if region == "" {
+		region = DefaultS3Region
+	}
+	endpoint := cfg.Endpoint
+	if endpoint == "" {
+		endpoint = "https://s3." + region + ".amazonaws.com"
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", endpoint)
+	}
+
+	s := &S3Storage{
+		endpoint:     u,
+		bucket:       cfg.Bucket,
+		region:       region,
+		pathStyle:    cfg.PathStyle,
+		accessKey:    cfg.AccessKeyID,
+		secretKey:    cfg.SecretAccessKey,
+		sessionToken: cfg.SessionToken,
+		now:          time.Now,
+	}
+	if s.accessKey == "" && s.secretKey == "" {
+		s.accessKey = os.Getenv("AWS_ACCESS_KEY_ID")
+		s.secretKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+		s.sessionToken = os.Getenv("AWS_SESSION_TOKEN")
+	}
+	if s.accessKey == "" || s.secretKey == "" {
+		return nil, fmt.Errorf("s3 storage requires an access key ID and a secret access key")
+	}
+
+	unbounded := *clientCfg
+	unbounded.Timeout = 0
+	s.client = httpclient.New("storage", &unbounded)
+	return s, nil
+}
+
+// Put uploads the object. S3 needs the size up front, so content of unknown
+// size is spooled to a temporary file first.
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader) (int64, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return 0, err
+	}
+
+	body, size, cleanup, err := sizedBody(r)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read object: %w", err)
+	}
+	defer cleanup()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(cleaned, nil), body)
+	if err != nil {
+		return 0, err
+	}
+	req.ContentLength = size
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to store object: %w", err)
+	}
+	resp.Body.Close()
+	return size, nil
+}
+
+// Get downloads the object
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(cleaned, nil), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Delete removes the object. S3 deletes missing objects successfully, so the
+// object is looked up first to report ErrNotFound like the other storages.
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+	for _, method := range []string{http.MethodHead, http.MethodDelete} {
+		req, err := http.NewRequestWithContext(ctx, method, s.objectURL(cleaned, nil), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// s3ListResult is the response of ListObjectsV2
+type s3ListResult struct {
+	Contents []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List lists the objects under prefix with ListObjectsV2, following its pages
+func (s *S3Storage) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	objects := []ObjectInfo{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL("", query), nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode object list: %w", err)
+		}
+
+		for _, content := range result.Contents {
+			objects = append(objects, ObjectInfo{Key: content.Key, Size: content.Size, ModTime: content.LastModified})
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// Presign signs a URL in the query string, valid for opts.Expires. The
+// content type and length of an upload are signed headers, so clients must
+// send exactly those values.
+func (s *S3Storage) Presign(ctx context.Context, key string, opts PresignOptions) (string, error) {
+	cleaned, err := cleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	method := opts.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	if method != http.MethodGet && method != http.MethodPut {
+		return "", fmt.Errorf("%w: method %s", ErrPresignNotSupported, method)
+	}
+	if opts.Expires <= 0 || opts.Expires > s3MaxPresignExpiry {
+		return "", fmt.Errorf("presigned URL expiry must be between 1s and %s", s3MaxPresignExpiry)
+	}
+
+	headers := http.Header{}
+	if method == http.MethodPut {
+		if opts.ContentType != "" {
+			headers.Set("Content-Type", opts.ContentType)
+		}
+		if opts.ContentLength > 0 {
+			headers.Set("Content-Length", strconv.FormatInt(opts.ContentLength, 10))
+		}
+	}
+
+	now := s.now().UTC()
+	query := url.Values{
+		"X-Amz-Algorithm":  {s3Algorithm},
+		"X-Amz-Credential": {s.accessKey + "/" + s.scope(now)},
+		"X-Amz-Date":       {now.Format("20060102T150405Z")},
+		"X-Amz-Expires":    {strconv.Itoa(int(opts.Expires / time.Second))},
+	}
+	if s.sessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+	u, _ := url.Parse(s.objectURL(cleaned, nil))
+	signedHeaders, canonicalHeaders := canonicalHeaders(u.Host, headers)
+	query.Set("X-Amz-SignedHeaders", signedHeaders)
+
+	canonical := strings.Join([]string{method, canonicalPath(u.Path), canonicalQuery(query), canonicalHeaders, signedHeaders, s3UnsignedPayload}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+	u.RawQuery = canonicalQuery(query)
+	return u.String(), nil
+}
+
+// objectURL returns the URL of the object stored under key, or of the bucket
+// when key is empty
+func (s *S3Storage) objectURL(key string, query url.Values) string {
+	u := *s.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path += "/" + s.bucket
+		if key != "" {
+			u.Path += "/" + key
+		}
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path += "/" + key
+	}
+	// The path is sent encoded exactly as it is signed
+	u.RawPath = canonicalPath(u.Path)
+	u.RawQuery = canonicalQuery(query)
+	return u.String()
+}
+
+// do signs and sends a request, turning error responses into errors; 404 is ErrNotFound
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	s.sign(req)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	var s3Err struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if xml.Unmarshal(data, &s3Err) == nil && s3Err.Code != "" {
+		return nil, fmt.Errorf("s3 %s: %s (status %d)", s3Err.Code, s3Err.Message, resp.StatusCode)
+	}
+	return nil, fmt.Errorf("s3 request failed with status %d", resp.StatusCode)
+}
+
+// sign adds the Signature Version 4 authorization headers to req. Bodies are
+// not hashed, the connection to S3 is trusted to carry them intact.
+func (s *S3Storage) sign(req *http.Request) {
+	now := s.now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+	if s.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.sessionToken)
+	}
+
+	signedHeaders, canonicalHeaders := canonicalHeaders(req.URL.Host, req.Header)
+	canonical := strings.Join([]string{req.Method, canonicalPath(req.URL.Path), canonicalQuery(req.URL.Query()), canonicalHeaders, signedHeaders, s3UnsignedPayload}, "\n")
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm, s.accessKey, s.scope(now), signedHeaders, s.signature(now, canonical)))
+}
+
+// scope returns the credential scope of requests signed at t
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request made at t
+func (s *S3Storage) signature(t time.Time, canonicalRequest string) string {
+	hash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{s3Algorithm, t.Format("20060102T150405Z"), s.scope(t), hex.EncodeToString(hash[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalHeaders returns the signed header names and the canonical headers:
+// host, content headers and the x-amz- headers
+func canonicalHeaders(host string, header http.Header) (string, string) {
+	values := map[string]string{"host": host}
+	for name := range header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-length" || lower == "content-md5" || strings.HasPrefix(lower, "x-amz-") {
+			values[lower] = strings.TrimSpace(header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + values[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalPath encodes every segment of a path as Signature Version 4 requires
+func canonicalPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery encodes the query sorted by name as Signature Version 4 requires
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		values := append([]string(nil), query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name)+"="+uriEncode(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything but the unreserved characters of RFC 3986
+func uriEncode(s string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(s) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' || b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+		} else {
+			fmt.Fprintf(&encoded, "%%%02X", b)
+		}
+	}
+	return encoded.String()
+}
+
+// sizedBody returns r with its size, spooling readers of unknown size to a
+// temporary file that cleanup removes
+func sizedBody(r io.Reader) (io.Reader, int64, func(), error) {
+	noop := func() {}
+	switch body := r.(type) {
+	case *bytes.Reader:
+		return body, int64(body.Len()), noop, nil
+	case *bytes.Buffer:
+		return body, int64(body.Len()), noop, nil
+	case *strings.Reader:
+		return body, int64(body.Len()), noop, nil
+	case *os.File:
+		if info, err := body.Stat(); err == nil && info.Mode().IsRegular() {
+			offset, err := body.Seek(0, io.SeekCurrent)
+			if err == nil {
+				return io.NopCloser(body), info.Size() - offset, noop, nil
+			}
+		}
+	}
+
+	spool, err := os.CreateTemp("", "storage-upload-*")
+	if err != nil {
+		return nil, 0, noop, err
+	}
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+	size, err := io.Copy(spool, r)
+	if err == nil {
+		_, err = spool.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		cleanup()
+		return nil, 0, noop, err
+	}
+	return io.NopCloser(spool), size, cleanup, nil
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Common storage errors
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+	// ErrPresignNotSupported is returned by storages that clients cannot reach directly
+	ErrPresignNotSupported = errors.New("presigned URLs are not supported")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// PresignOptions describes the request a presigned URL allows
+type PresignOptions struct {
+	// Method is GET to download the object or PUT to upload it, GET by default
+	Method string
+	// Expires is how long the URL stays valid
+	Expires time.Duration
+	// ContentType is the Content-Type an upload must be sent with, empty allows any
+	ContentType string
+	// ContentLength is the exact size of an upload, 0 allows any
+	ContentLength int64
+}
+
+// Storage stores binary objects such as backup archives under slash separated keys
+type Storage interface {
+	// Put stores the content of r under key, replacing any existing object, and returns its size
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Get opens the object stored under key; the caller must close it
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object stored under key
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose keys start with prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	// Presign returns a URL through which clients download or upload the object
+	// stored under key without going through the API, or ErrPresignNotSupported
+	Presign(ctx context.Context, key string, opts PresignOptions) (string, error)
+}
+
+// Storage types
+const (
+	TypeLocal  = "local"
+	TypeMemory = "memory"
+	TypeS3     = "s3"
+)
+
+// DefaultLocalPath is the root directory of the local storage when none is configured
+const DefaultLocalPath = "data/storage"
+
+// New creates a storage according to the storage configuration, recording
+// operation metrics labelled with the storage type
+func New(cfg *config.Config) (Storage, error) {
+	var (
+		backend Storage
+		err     error
+	)
+	storageType := cfg.Storage.Type
+	switch storageType {
+	case TypeLocal, "":
+		storageType = TypeLocal
+		root := cfg.Storage.Path
+		if root == "" {
+			root = DefaultLocalPath
+		}
+		backend, err = NewLocalStorage(root)
+	case TypeMemory:
+		backend = NewMemoryStorage()
+	case TypeS3:
+		backend, err = NewS3Storage(&cfg.Storage.S3, &cfg.HTTPClient)
+	default:
+		return nil, fmt.Errorf("unsupported storage type: %s", cfg.Storage.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instrumentedStorage{backend: storageType, next: backend}, nil
+}
+
+// cleanKey validates key and returns it in canonical form. Keys are relative,
+// slash separated and may not escape the storage root.
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,253 @@
+package watchdog
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"runtime/pprof"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	utilpprof "github.com/make-bin/server-tpl/pkg/utils/pprof"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// Resources watched for sustained growth
+const (
+	ResourceGoroutines = "goroutines"
+	ResourceHeap       = "heap"
+	ResourceOpenFiles  = "open_files"
+)
+
+const (
+	// maxProfileLog bounds the goroutine profile written to the log
+	maxProfileLog = 64 * 1024
+	// memoryLimitRatio is the fraction of the memory limit that triggers a heap dump by default
+	memoryLimitRatio = 0.9
+)
+
+var (
+	resourceUsage = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "watchdog_resource_usage",
+			Help: "Last sampled usage of a watched resource",
+		},
+		[]string{"resource"},
+	)
+	resourceBaseline = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "watchdog_resource_baseline",
+			Help: "Baseline above which sustained growth of a resource is reported",
+		},
+		[]string{"resource"},
+	)
+	leakDetected = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "watchdog_leak_detected",
+			Help: "Whether a resource is currently growing above its baseline (1) or not (0)",
+		},
+		[]string{"resource"},
+	)
+	heapDumpsTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "watchdog_heap_dumps_total",
+			Help: "Total number of heap dumps written by the watchdog",
+		},
+	)
+)
+
+// Watchdog samples runtime statistics at intervals and reports resources that
+// stay above their baseline and keep growing, which usually indicates a leak.
+// It can also write a heap dump when the heap approaches the memory limit, so
+// the dump is available after the process is killed for running out of memory.
+type Watchdog struct {
+	profiler *utilpprof.PProfManager
+	cfg      config.WatchdogConfig
+	checks   []*check
+	lastDump time.Time
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// check tracks the recent samples of one resource
+type check struct {
+	resource string
+	baseline int64
+	samples  []int64
+	alerting bool
+}
+
+// New creates a watchdog reading runtime statistics from profiler
+func New(profiler *utilpprof.PProfManager, cfg *config.WatchdogConfig) *Watchdog {
+	w := &Watchdog{profiler: profiler, cfg: *cfg}
+	for _, c := range []*check{
+		{resource: ResourceGoroutines, baseline: int64(cfg.Goroutines)},
+		{resource: ResourceHeap, baseline: int64(cfg.Heap)},
+		{resource: ResourceOpenFiles, baseline: int64(cfg.OpenFiles)},
+	} {
+		if c.baseline > 0 {
+			resourceBaseline.WithLabelValues(c.resource).Set(float64(c.baseline))
+			w.checks = append(w.checks, c)
+		}
+	}
+	return w
+}
+
+// OnStart starts sampling in the background
+func (w *Watchdog) OnStart(ctx context.Context) error {
+	runCtx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+	go w.run(runCtx)
+	logger.Info("Watchdog started, sampling every %s", w.cfg.Interval)
+	return nil
+}
+
+// OnStop stops sampling
+func (w *Watchdog) OnStop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+	w.cancel()
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// run samples until ctx is cancelled
+func (w *Watchdog) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		w.Sample()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sample takes one sample of every resource, updates the gauges and reports
+// resources whose growth started or ended
+func (w *Watchdog) Sample() {
+	stats := w.profiler.GetRuntimeStats()
+	usage := map[string]int64{
+		ResourceGoroutines: int64(stats.NumGoroutine),
+		ResourceHeap:       int64(stats.HeapAlloc),
+	}
+	if files, ok := openFiles(); ok {
+		usage[ResourceOpenFiles] = files
+	}
+	for resource, value := range usage {
+		resourceUsage.WithLabelValues(resource).Set(float64(value))
+	}
+
+	for _, c := range w.checks {
+		value, ok := usage[c.resource]
+		if !ok {
+			continue
+		}
+		switch growing := c.add(value, w.cfg.Samples); {
+		case growing && !c.alerting:
+			c.alerting = true
+			leakDetected.WithLabelValues(c.resource).Set(1)
+			w.report(c)
+		case !growing && c.alerting && value <= c.baseline:
+			c.alerting = false
+			leakDetected.WithLabelValues(c.resource).Set(0)
+			logger.Info("Watchdog: %s back to %d, below baseline %d", c.resource, value, c.baseline)
+		}
+	}
+
+	if w.cfg.HeapDump.Enabled {
+		w.dumpHeap(stats.HeapAlloc)
+	}
+}
+
+// add records value and reports whether the kept samples are all above the
+// baseline and the newest is higher than the oldest
+func (c *check) add(value int64, size int) bool {
+	c.samples = append(c.samples, value)
+	if len(c.samples) > size {
+		c.samples = c.samples[len(c.samples)-size:]
+	}
+	if len(c.samples) < size {
+		return false
+	}
+	for _, sample := range c.samples {
+		if sample <= c.baseline {
+			return false
+		}
+	}
+	return c.samples[len(c.samples)-1] > c.samples[0]
+}
+
+// report logs a resource growing above its baseline; for goroutines the
+// profile grouping goroutines by stack is logged to locate the leak
+func (w *Watchdog) report(c *check) {
+	logger.Warn("Watchdog: %s grew from %d to %d over %d samples, above baseline %d",
+		c.resource, c.samples[0], c.samples[len(c.samples)-1], len(c.samples), c.baseline)
+	if c.resource != ResourceGoroutines {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		logger.Error("Watchdog: failed to write goroutine profile: %v", err)
+		return
+	}
+	profile := buf.String()
+	if len(profile) > maxProfileLog {
+		profile = profile[:maxProfileLog] + "\n... truncated"
+	}
+	logger.Warn("Watchdog: goroutine profile\n%s", profile)
+}
+
+// dumpHeap writes a heap profile when the heap exceeds the dump threshold,
+// at most once per minimum interval
+func (w *Watchdog) dumpHeap(heap uint64) {
+	threshold := uint64(w.cfg.HeapDump.Threshold)
+	if threshold == 0 {
+		limit := debug.SetMemoryLimit(-1)
+		if limit == math.MaxInt64 {
+			return
+		}
+		threshold = uint64(float64(limit) * memoryLimitRatio)
+	}
+	if heap < threshold || (!w.lastDump.IsZero() && time.Since(w.lastDump) < w.cfg.HeapDump.MinInterval) {
+		return
+	}
+
+	w.lastDump = time.Now()
+	filename := filepath.Join(w.cfg.HeapDump.Dir, fmt.Sprintf("heap_%s.prof", w.lastDump.Format("20060102_150405")))
+	if err := w.profiler.WriteHeapProfile(filename); err != nil {
+		logger.Error("Watchdog: failed to write heap dump: %v", err)
+		return
+	}
+	heapDumpsTotal.Inc()
+	logger.Warn("Watchdog: heap at %d bytes exceeds %d, wrote heap dump %s", heap, threshold, filename)
+}
+
+// openFiles returns the number of open file descriptors, where /proc is available
+func openFiles() (int64, bool) {
+	entries, err := os.ReadDir("/proc/self/fd")
+	if err != nil {
+		return 0, false
+	}
+	// The directory read itself holds one descriptor
+	return int64(len(entries) - 1), true
+}
@@ -0,0 +1,186 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/domain/service"
+)
+
+var _ service.ApplicationServiceInterface = (*ApplicationService)(nil)
+
+// ApplicationService is a fake service.ApplicationServiceInterface
+type ApplicationService struct {
+	recorder
+
+	CreateApplicationFunc        func(ctx context.Context, app *model.Application) (*model.Application, error)
+	GetApplicationByIDFunc       func(ctx context.Context, id uint) (*model.Application, error)
+	GetApplicationByNameFunc     func(ctx context.Context, name string) (*model.Application, error)
+	ResolveApplicationIDFunc     func(ctx context.Context, publicID string) (uint, error)
+	ListApplicationsFunc         func(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error)
+	ListApplicationsByTagsFunc   func(ctx context.Context, selector model.TagSelector, page, pageSize int) ([]*model.Application, int64, error)
+	QueryApplicationsFunc        func(ctx context.Context, query model.ApplicationQuery) ([]*model.Application, int64, error)
+	ExistingApplicationNamesFunc func(ctx context.Context, names []string) ([]string, error)
+	AddApplicationTagsFunc       func(ctx context.Context, id uint, tags []string) (*model.Application, error)
+	RemoveApplicationTagsFunc    func(ctx context.Context, id uint, tags []string) (*model.Application, error)
+	UpdateApplicationFunc        func(ctx context.Context, app *model.Application) (*model.Application, error)
+	PatchApplicationFunc         func(ctx context.Context, id uint, patch *model.ApplicationPatch) (*model.Application, error)
+	DeleteApplicationFunc        func(ctx context.Context, id uint) error
+	BatchDeleteApplicationsFunc  func(ctx context.Context, ids []uint, opts service.BatchDeleteOptions) ([]service.BatchDeleteFailure, error)
+	ImportApplicationsFunc       func(ctx context.Context, apps []*model.Application, dryRun bool) ([]service.ImportFailure, error)
+	ListApplicationRevisionsFunc func(ctx context.Context, id uint, page, pageSize int) ([]*model.ApplicationRevision, int64, error)
+	RollbackApplicationFunc      func(ctx context.Context, id uint, revision int) (*model.Application, error)
+}
+
+// CreateApplication calls CreateApplicationFunc
+func (m *ApplicationService) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	m.record("CreateApplication")
+	if m.CreateApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.CreateApplicationFunc(ctx, app)
+}
+
+// GetApplicationByID calls GetApplicationByIDFunc
+func (m *ApplicationService) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
+	m.record("GetApplicationByID")
+	if m.GetApplicationByIDFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetApplicationByIDFunc(ctx, id)
+}
+
+// GetApplicationByName calls GetApplicationByNameFunc
+func (m *ApplicationService) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+	m.record("GetApplicationByName")
+	if m.GetApplicationByNameFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetApplicationByNameFunc(ctx, name)
+}
+
+// ResolveApplicationID calls ResolveApplicationIDFunc
+func (m *ApplicationService) ResolveApplicationID(ctx context.Context, publicID string) (uint, error) {
+	m.record("ResolveApplicationID")
+	if m.ResolveApplicationIDFunc == nil {
+		return 0, ErrNotStubbed
+	}
+	return m.ResolveApplicationIDFunc(ctx, publicID)
+}
+
+// ListApplications calls ListApplicationsFunc
+func (m *ApplicationService) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
+	m.record("ListApplications")
+	if m.ListApplicationsFunc == nil {
+		return nil, 0, ErrNotStubbed
+	}
+	return m.ListApplicationsFunc(ctx, page, pageSize)
+}
+
+// ListApplicationsByTags calls ListApplicationsByTagsFunc
+func (m *ApplicationService) ListApplicationsByTags(ctx context.Context, selector model.TagSelector, page, pageSize int) ([]*model.Application, int64, error) {
+	m.record("ListApplicationsByTags")
+	if m.ListApplicationsByTagsFunc == nil {
+		return nil, 0, ErrNotStubbed
+	}
+	return m.ListApplicationsByTagsFunc(ctx, selector, page, pageSize)
+}
+
+// QueryApplications calls QueryApplicationsFunc
+func (m *ApplicationService) QueryApplications(ctx context.Context, query model.ApplicationQuery) ([]*model.Application, int64, error) {
+	m.record("QueryApplications")
+	if m.QueryApplicationsFunc == nil {
+		return nil, 0, ErrNotStubbed
+	}
+	return m.QueryApplicationsFunc(ctx, query)
+}
+
+// ExistingApplicationNames calls ExistingApplicationNamesFunc
+func (m *ApplicationService) ExistingApplicationNames(ctx context.Context, names []string) ([]string, error) {
+	m.record("ExistingApplicationNames")
+	if m.ExistingApplicationNamesFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.ExistingApplicationNamesFunc(ctx, names)
+}
+
+// AddApplicationTags calls AddApplicationTagsFunc
+func (m *ApplicationService) AddApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error) {
+	m.record("AddApplicationTags")
+	if m.AddApplicationTagsFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.AddApplicationTagsFunc(ctx, id, tags)
+}
+
+// RemoveApplicationTags calls RemoveApplicationTagsFunc
+func (m *ApplicationService) RemoveApplicationTags(ctx context.Context, id uint, tags []string) (*model.Application, error) {
+	m.record("RemoveApplicationTags")
+	if m.RemoveApplicationTagsFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.RemoveApplicationTagsFunc(ctx, id, tags)
+}
+
+// UpdateApplication calls UpdateApplicationFunc
+func (m *ApplicationService) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	m.record("UpdateApplication")
+	if m.UpdateApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.UpdateApplicationFunc(ctx, app)
+}
+
+// PatchApplication calls PatchApplicationFunc
+func (m *ApplicationService) PatchApplication(ctx context.Context, id uint, patch *model.ApplicationPatch) (*model.Application, error) {
+	m.record("PatchApplication")
+	if m.PatchApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.PatchApplicationFunc(ctx, id, patch)
+}
+
+// DeleteApplication calls DeleteApplicationFunc
+func (m *ApplicationService) DeleteApplication(ctx context.Context, id uint) error {
+	m.record("DeleteApplication")
+	if m.DeleteApplicationFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.DeleteApplicationFunc(ctx, id)
+}
+
+// BatchDeleteApplications calls BatchDeleteApplicationsFunc
+func (m *ApplicationService) BatchDeleteApplications(ctx context.Context, ids []uint, opts service.BatchDeleteOptions) ([]service.BatchDeleteFailure, error) {
+	m.record("BatchDeleteApplications")
+	if m.BatchDeleteApplicationsFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.BatchDeleteApplicationsFunc(ctx, ids, opts)
+}
+
+// ImportApplications calls ImportApplicationsFunc
+func (m *ApplicationService) ImportApplications(ctx context.Context, apps []*model.Application, dryRun bool) ([]service.ImportFailure, error) {
+	m.record("ImportApplications")
+	if m.ImportApplicationsFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.ImportApplicationsFunc(ctx, apps, dryRun)
+}
+
+// ListApplicationRevisions calls ListApplicationRevisionsFunc
+func (m *ApplicationService) ListApplicationRevisions(ctx context.Context, id uint, page, pageSize int) ([]*model.ApplicationRevision, int64, error) {
+	m.record("ListApplicationRevisions")
+	if m.ListApplicationRevisionsFunc == nil {
+		return nil, 0, ErrNotStubbed
+	}
+	return m.ListApplicationRevisionsFunc(ctx, id, page, pageSize)
+}
+
+// RollbackApplication calls RollbackApplicationFunc
+func (m *ApplicationService) RollbackApplication(ctx context.Context, id uint, revision int) (*model.Application, error) {
+	m.record("RollbackApplication")
+	if m.RollbackApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.RollbackApplicationFunc(ctx, id, revision)
+}
@@ -0,0 +1,77 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+var _ datastore.Cache = (*Cache)(nil)
+
+// Cache is a fake datastore.Cache. Use cache.NewMemoryCache for a working
+// in-memory cache.
+type Cache struct {
+	recorder
+
+	GetFunc    func(ctx context.Context, key string) (interface{}, error)
+	SetFunc    func(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	DeleteFunc func(ctx context.Context, key string) error
+	ClearFunc  func(ctx context.Context) error
+	ExistsFunc func(ctx context.Context, key string) (bool, error)
+	ExpireFunc func(ctx context.Context, key string, ttl time.Duration) error
+}
+
+// Get calls GetFunc
+func (m *Cache) Get(ctx context.Context, key string) (interface{}, error) {
+	m.record("Get")
+	if m.GetFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetFunc(ctx, key)
+}
+
+// Set calls SetFunc
+func (m *Cache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	m.record("Set")
+	if m.SetFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.SetFunc(ctx, key, value, ttl)
+}
+
+// Delete calls DeleteFunc
+func (m *Cache) Delete(ctx context.Context, key string) error {
+	m.record("Delete")
+	if m.DeleteFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.DeleteFunc(ctx, key)
+}
+
+// Clear calls ClearFunc
+func (m *Cache) Clear(ctx context.Context) error {
+	m.record("Clear")
+	if m.ClearFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.ClearFunc(ctx)
+}
+
+// Exists calls ExistsFunc
+func (m *Cache) Exists(ctx context.Context, key string) (bool, error) {
+	m.record("Exists")
+	if m.ExistsFunc == nil {
+		return false, ErrNotStubbed
+	}
+	return m.ExistsFunc(ctx, key)
+}
+
+// Expire calls ExpireFunc
+func (m *Cache) Expire(ctx context.Context, key string, ttl time.Duration) error {
+	m.record("Expire")
+	if m.ExpireFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.ExpireFunc(ctx, key, ttl)
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/container"
+)
+
+var _ container.Container = (*Container)(nil)
+
+// Container is a fake container.Container. Without stubs it keeps provided
+// beans in a map, rejects nothing and injects nothing.
+type Container struct {
+	recorder
+
+	ProvideFunc  func(name string, bean interface{}) error
+	GetFunc      func(name string) (interface{}, bool)
+	PopulateFunc func() error
+	CloseFunc    func() error
+
+	beans map[string]interface{}
+}
+
+// Provide calls ProvideFunc
+func (m *Container) Provide(name string, bean interface{}) error {
+	m.record("Provide")
+	if m.ProvideFunc != nil {
+		return m.ProvideFunc(name, bean)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.beans == nil {
+		m.beans = make(map[string]interface{})
+	}
+	m.beans[name] = bean
+	return nil
+}
+
+// Get calls GetFunc
+func (m *Container) Get(name string) (interface{}, bool) {
+	m.record("Get")
+	if m.GetFunc != nil {
+		return m.GetFunc(name)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	bean, ok := m.beans[name]
+	return bean, ok
+}
+
+// Populate calls PopulateFunc
+func (m *Container) Populate() error {
+	m.record("Populate")
+	if m.PopulateFunc == nil {
+		return nil
+	}
+	return m.PopulateFunc()
+}
+
+// Close calls CloseFunc
+func (m *Container) Close() error {
+	m.record("Close")
+	if m.CloseFunc == nil {
+		return nil
+	}
+	return m.CloseFunc()
+}
@@ -0,0 +1,159 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/make-bin/server-tpl/pkg/domain/model"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+var _ datastore.DatastoreInterface = (*Datastore)(nil)
+
+// Datastore is a fake datastore.DatastoreInterface. Without stubs Migrate,
+// Close and HealthCheck succeed. Use memory.New for a working in-memory store.
+type Datastore struct {
+	recorder
+
+	CreateApplicationFunc    func(ctx context.Context, app *model.Application) (*model.Application, error)
+	GetApplicationByIDFunc   func(ctx context.Context, id uint) (*model.Application, error)
+	GetApplicationByNameFunc func(ctx context.Context, name string) (*model.Application, error)
+	ListApplicationsFunc     func(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error)
+	UpdateApplicationFunc    func(ctx context.Context, app *model.Application) (*model.Application, error)
+	DeleteApplicationFunc    func(ctx context.Context, id uint) error
+
+	CreateFeatureFlagFunc   func(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	GetFeatureFlagByKeyFunc func(ctx context.Context, key string) (*model.FeatureFlag, error)
+	ListFeatureFlagsFunc    func(ctx context.Context) ([]*model.FeatureFlag, error)
+	UpdateFeatureFlagFunc   func(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error)
+	DeleteFeatureFlagFunc   func(ctx context.Context, key string) error
+
+	MigrateFunc     func() error
+	CloseFunc       func() error
+	HealthCheckFunc func() error
+}
+
+// CreateApplication calls CreateApplicationFunc
+func (m *Datastore) CreateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	m.record("CreateApplication")
+	if m.CreateApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.CreateApplicationFunc(ctx, app)
+}
+
+// GetApplicationByID calls GetApplicationByIDFunc
+func (m *Datastore) GetApplicationByID(ctx context.Context, id uint) (*model.Application, error) {
+	m.record("GetApplicationByID")
+	if m.GetApplicationByIDFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetApplicationByIDFunc(ctx, id)
+}
+
+// GetApplicationByName calls GetApplicationByNameFunc
+func (m *Datastore) GetApplicationByName(ctx context.Context, name string) (*model.Application, error) {
+	m.record("GetApplicationByName")
+	if m.GetApplicationByNameFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetApplicationByNameFunc(ctx, name)
+}
+
+// ListApplications calls ListApplicationsFunc
+func (m *Datastore) ListApplications(ctx context.Context, page, pageSize int) ([]*model.Application, int64, error) {
+	m.record("ListApplications")
+	if m.ListApplicationsFunc == nil {
+		return nil, 0, ErrNotStubbed
+	}
+	return m.ListApplicationsFunc(ctx, page, pageSize)
+}
+
+// UpdateApplication calls UpdateApplicationFunc
+func (m *Datastore) UpdateApplication(ctx context.Context, app *model.Application) (*model.Application, error) {
+	m.record("UpdateApplication")
+	if m.UpdateApplicationFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.UpdateApplicationFunc(ctx, app)
+}
+
+// DeleteApplication calls DeleteApplicationFunc
+func (m *Datastore) DeleteApplication(ctx context.Context, id uint) error {
+	m.record("DeleteApplication")
+	if m.DeleteApplicationFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.DeleteApplicationFunc(ctx, id)
+}
+
+// CreateFeatureFlag calls CreateFeatureFlagFunc
+func (m *Datastore) CreateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	m.record("CreateFeatureFlag")
+	if m.CreateFeatureFlagFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.CreateFeatureFlagFunc(ctx, flag)
+}
+
+// GetFeatureFlagByKey calls GetFeatureFlagByKeyFunc
+func (m *Datastore) GetFeatureFlagByKey(ctx context.Context, key string) (*model.FeatureFlag, error) {
+	m.record("GetFeatureFlagByKey")
+	if m.GetFeatureFlagByKeyFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.GetFeatureFlagByKeyFunc(ctx, key)
+}
+
+// ListFeatureFlags calls ListFeatureFlagsFunc
+func (m *Datastore) ListFeatureFlags(ctx context.Context) ([]*model.FeatureFlag, error) {
+	m.record("ListFeatureFlags")
+	if m.ListFeatureFlagsFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.ListFeatureFlagsFunc(ctx)
+}
+
+// UpdateFeatureFlag calls UpdateFeatureFlagFunc
+func (m *Datastore) UpdateFeatureFlag(ctx context.Context, flag *model.FeatureFlag) (*model.FeatureFlag, error) {
+	m.record("UpdateFeatureFlag")
+	if m.UpdateFeatureFlagFunc == nil {
+		return nil, ErrNotStubbed
+	}
+	return m.UpdateFeatureFlagFunc(ctx, flag)
+}
+
+// DeleteFeatureFlag calls DeleteFeatureFlagFunc
+func (m *Datastore) DeleteFeatureFlag(ctx context.Context, key string) error {
+	m.record("DeleteFeatureFlag")
+	if m.DeleteFeatureFlagFunc == nil {
+		return ErrNotStubbed
+	}
+	return m.DeleteFeatureFlagFunc(ctx, key)
+}
+
+// Migrate calls MigrateFunc
+func (m *Datastore) Migrate() error {
+	m.record("Migrate")
+	if m.MigrateFunc == nil {
+		return nil
+	}
+	return m.MigrateFunc()
+}
+
+// Close calls CloseFunc
+func (m *Datastore) Close() error {
+	m.record("Close")
+	if m.CloseFunc == nil {
+		return nil
+	}
+	return m.CloseFunc()
+}
+
+// HealthCheck calls HealthCheckFunc
+func (m *Datastore) HealthCheck() error {
+	m.record("HealthCheck")
+	if m.HealthCheckFunc == nil {
+		return nil
+	}
+	return m.HealthCheckFunc()
+}
@@ -0,0 +1,63 @@
+// Package mocks provides hand-written fakes of the core interfaces so that
+// handlers and services can be unit tested without infrastructure.
+//
+// Every fake has one XxxFunc field per interface method. A method calls its
+// field when it is set; otherwise it returns zero values and ErrNotStubbed
+// when the method returns an error, unless the fake documents a different
+// default. Fakes count their calls:
+//
+//	svc := &mocks.ApplicationService{
+//		GetApplicationByIDFunc: func(ctx context.Context, id uint) (*model.Application, error) {
+//			app := &model.Application{Name: "demo"}
+//			app.ID = id
+//			return app, nil
+//		},
+//	}
+//	h := handler.NewApplicationHandler(svc, nil, nil)
+//	// ... serve a request with h ...
+//	if svc.Calls("GetApplicationByID") != 1 { ... }
+//
+// Fakes are safe for concurrent use as long as their fields are set before
+// the first call.
+package mocks
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrNotStubbed is returned by methods whose XxxFunc field is not set
+var ErrNotStubbed = errors.New("mocks: method not stubbed")
+
+// recorder counts the calls of each method
+type recorder struct {
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+// record counts a call of method
+func (r *recorder) record(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.calls == nil {
+		r.calls = make(map[string]int)
+	}
+	r.calls[method]++
+}
+
+// Calls returns how many times method was called
+func (r *recorder) Calls(method string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.calls[method]
+}
+
+// Reset forgets the recorded calls
+func (r *recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+)
+
+var _ datastore.Monitor = (*Monitor)(nil)
+
+// Monitor is a fake datastore.Monitor. Without stubs it only counts calls.
+type Monitor struct {
+	recorder
+
+	RecordQueryFunc      func(operation, table string, duration time.Duration)
+	RecordConnectionFunc func(database string, connections int)
+	RecordErrorFunc      func(operation, table string, err error)
+}
+
+// RecordQuery calls RecordQueryFunc
+func (m *Monitor) RecordQuery(operation, table string, duration time.Duration) {
+	m.record("RecordQuery")
+	if m.RecordQueryFunc != nil {
+		m.RecordQueryFunc(operation, table, duration)
+	}
+}
+
+// RecordConnection calls RecordConnectionFunc
+func (m *Monitor) RecordConnection(database string, connections int) {
+	m.record("RecordConnection")
+	if m.RecordConnectionFunc != nil {
+		m.RecordConnectionFunc(database, connections)
+	}
+}
+
+// RecordError calls RecordErrorFunc
+func (m *Monitor) RecordError(operation, table string, err error) {
+	m.record("RecordError")
+	if m.RecordErrorFunc != nil {
+		m.RecordErrorFunc(operation, table, err)
+	}
+}
@@ -0,0 +1,96 @@
+package mocks
+
+import (
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+)
+
+var _ i18n.Translator = (*Translator)(nil)
+
+// Translator is a fake i18n.Translator. Without stubs it returns keys
+// untranslated, reports i18n.DefaultLanguage, accepts every language and has
+// no translations, so responses carry the message keys.
+type Translator struct {
+	recorder
+
+	TranslateFunc             func(key string, args ...interface{}) string
+	TranslateWithLangFunc     func(lang, key string, args ...interface{}) string
+	GetLanguageFunc           func() string
+	SetLanguageFunc           func(lang string) error
+	GetSupportedLanguagesFunc func() []string
+	HasTranslationFunc        func(key string) bool
+	ReloadFunc                func() error
+	WithLanguageFunc          func(lang string) i18n.Translator
+}
+
+// Translate calls TranslateFunc
+func (m *Translator) Translate(key string, args ...interface{}) string {
+	m.record("Translate")
+	if m.TranslateFunc == nil {
+		return key
+	}
+	return m.TranslateFunc(key, args...)
+}
+
+// TranslateWithLang calls TranslateWithLangFunc
+func (m *Translator) TranslateWithLang(lang, key string, args ...interface{}) string {
+	m.record("TranslateWithLang")
+	if m.TranslateWithLangFunc == nil {
+		return key
+	}
+	return m.TranslateWithLangFunc(lang, key, args...)
+}
+
+// GetLanguage calls GetLanguageFunc
+func (m *Translator) GetLanguage() string {
+	m.record("GetLanguage")
+	if m.GetLanguageFunc == nil {
+		return i18n.DefaultLanguage
+	}
+	return m.GetLanguageFunc()
+}
+
+// SetLanguage calls SetLanguageFunc
+func (m *Translator) SetLanguage(lang string) error {
+	m.record("SetLanguage")
+	if m.SetLanguageFunc == nil {
+		return nil
+	}
+	return m.SetLanguageFunc(lang)
+}
+
+// GetSupportedLanguages calls GetSupportedLanguagesFunc
+func (m *Translator) GetSupportedLanguages() []string {
+	m.record("GetSupportedLanguages")
+	if m.GetSupportedLanguagesFunc == nil {
+		return []string{i18n.DefaultLanguage}
+	}
+	return m.GetSupportedLanguagesFunc()
+}
+
+// HasTranslation calls HasTranslationFunc
+func (m *Translator) HasTranslation(key string) bool {
+	m.record("HasTranslation")
+	if m.HasTranslationFunc == nil {
+		return false
+	}
+	return m.HasTranslationFunc(key)
+}
+
+// Reload calls ReloadFunc
+func (m *Translator) Reload() error {
+	m.record("Reload")
+	if m.ReloadFunc == nil {
+		return nil
+	}
+	return m.ReloadFunc()
+}
+
+// WithLanguage calls WithLanguageFunc. Without a stub it returns m, so calls
+// made through the bound translator are counted on m.
+func (m *Translator) WithLanguage(lang string) i18n.Translator {
+	m.record("WithLanguage")
+	if m.WithLanguageFunc == nil {
+		return m
+	}
+	return m.WithLanguageFunc(lang)
+}
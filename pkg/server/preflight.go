@@ -0,0 +1,357 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/factory"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/httpcache"
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"github.com/sirupsen/logrus"
+)
+
+// 启动前检查结果
+const (
+	preflightOK      = "ok"
+	preflightWarn    = "warn"
+	preflightFail    = "fail"
+	preflightSkipped = "skipped"
+)
+
+// preflightProbeTimeout 限制探测连接池代理的时长
+const preflightProbeTimeout = 10 * time.Second
+
+// errPreflightSkipped 由依赖的检查失败或不适用于当前配置的检查返回
+var errPreflightSkipped = errors.New("skipped")
+
+// preflightCheck 启动前检查项。hard为true时失败将拒绝启动，否则只记录警告；
+// remediation返回失败时给出的修复建议
+type preflightCheck struct {
+	name        string
+	hard        bool
+	run         func() error
+	remediation func(err error) string
+}
+
+// PreflightFailure 一项失败的启动前检查
+type PreflightFailure struct {
+	Check       string
+	Err         error
+	Remediation string
+}
+
+// PreflightError 启动前检查失败，列出所有失败的硬性检查及其修复建议
+type PreflightError struct {
+	Failures []PreflightFailure
+}
+
+// Error 实现error接口，每项失败占两行：错误与修复建议
+func (e *PreflightError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "preflight checks failed (%d problem(s)):", len(e.Failures))
+	for _, f := range e.Failures {
+		fmt.Fprintf(&b, "\n  - %s: %s", f.Check, strings.ReplaceAll(f.Err.Error(), "\n", "\n    "))
+		if f.Remediation != "" {
+			fmt.Fprintf(&b, "\n    remediation: %s", f.Remediation)
+		}
+	}
+	return b.String()
+}
+
+// Unwrap 返回各项失败的错误
+func (e *PreflightError) Unwrap() []error {
+	errs := make([]error, len(e.Failures))
+	for i, f := range e.Failures {
+		errs[i] = f.Err
+	}
+	return errs
+}
+
+// preflight 在创建任何组件前校验配置、连接数据库和Redis并检查数据库结构，
+// 连接成功的数据存储保留供registerInfrastructure使用。所有检查都会执行，
+// 硬性检查失败时返回*PreflightError，服务拒绝启动
+func (s *Server) preflight() error {
+	logger.Info("Running preflight checks...")
+
+	failed := &PreflightError{}
+	for _, check := range s.preflightChecks() {
+		start := time.Now()
+		err := check.run()
+
+		status := preflightOK
+		switch {
+		case errors.Is(err, errPreflightSkipped):
+			status = preflightSkipped
+		case err != nil && check.hard:
+			status = preflightFail
+		case err != nil:
+			status = preflightWarn
+		}
+
+		fields := logrus.Fields{
+			"check":       check.name,
+			"status":      status,
+			"duration_ms": time.Since(start).Milliseconds(),
+		}
+		switch status {
+		case preflightOK, preflightSkipped:
+			logger.WithFields(fields).Debug("Preflight check " + check.name + ": " + status)
+		default:
+			remediation := check.remediation(err)
+			fields["error"] = err.Error()
+			fields["remediation"] = remediation
+			if status == preflightFail {
+				logger.WithFields(fields).Error("Preflight check " + check.name + " failed")
+				failed.Failures = append(failed.Failures, PreflightFailure{Check: check.name, Err: err, Remediation: remediation})
+			} else {
+				logger.WithFields(fields).Warn("Preflight check " + check.name + " reported a problem")
+			}
+		}
+	}
+
+	if len(failed.Failures) > 0 {
+		if s.dataStore != nil {
+			_ = s.dataStore.Close()
+			s.dataStore = nil
+		}
+		return failed
+	}
+	logger.Info("Preflight checks passed")
+	return nil
+}
+
+// preflightChecks 按执行顺序返回启动前检查项，后面的检查可依赖前面检查的结果
+func (s *Server) preflightChecks() []preflightCheck {
+	return []preflightCheck{
+		{
+			name: "config",
+			hard: true,
+			run:  func() error { return config.ValidateConfig(s.config) },
+			remediation: func(error) string {
+				return "fix the settings listed above in configs/app.yml or the matching environment variables, " +
+					"then check them with \"server config validate\""
+			},
+		},
+		{
+			name: "modules",
+			hard: true,
+			run:  func() error { return module.Configure(s.config.Modules) },
+			remediation: func(error) string {
+				return "remove the unknown modules from modules in configs/app.yml; the routes, beans and migrations " +
+					"of modules are registered unless their enabled flag is false"
+			},
+		},
+		{
+			name:        "database",
+			hard:        true,
+			run:         s.checkDatabase,
+			remediation: s.databaseRemediation,
+		},
+		{
+			name:        "migrations",
+			hard:        true,
+			run:         s.checkMigrations,
+			remediation: s.migrationsRemediation,
+		},
+		{
+			name: "connection_pooling",
+			run:  s.checkConnectionPooling,
+			remediation: func(err error) string {
+				if !errors.Is(err, datastore.ErrPoolingIncompatible) {
+					return "disable database.transaction_pooling (DATABASE_TRANSACTION_POOLING=false)"
+				}
+				return "connect through a proxy in transaction or session pooling mode (PgBouncer pool_mode = transaction), " +
+					"and enable database.transaction_pooling (DATABASE_TRANSACTION_POOLING=true) when it pools transactions"
+			},
+		},
+		{
+			name: "redis",
+			hard: true,
+			run:  s.checkRedis,
+			remediation: func(error) string {
+				return fmt.Sprintf("check that Redis is running and reachable at %s:%d and that redis.password and redis.database are correct "+
+					"(REDIS_HOST, REDIS_PORT, REDIS_PASSWORD), or use the memory store for quota.store, server.response_cache.store, "+
+					"notification.rate_limit.store, security.signing.nonce_store, security.bot_detection.store, security.login_throttle.store and security.sessions.denylist_store",
+					s.config.Redis.Host, s.config.Redis.Port)
+			},
+		},
+		{
+			name: "secrets",
+			run:  s.checkSecrets,
+			remediation: func(error) string {
+				return "set security.jwt_secret and security.encryption_key (SECURITY_JWT_SECRET, SECURITY_ENCRYPTION_KEY); " +
+					"the defaults are refused in production"
+			},
+		},
+	}
+}
+
+// checkDatabase 创建并连接数据存储，成功时保留供registerInfrastructure使用
+func (s *Server) checkDatabase() error {
+	if s.dataStore != nil {
+		return s.dataStore.HealthCheck()
+	}
+
+	store, err := factory.NewSimpleFactory(s.clock).CreateDatastore(s.config)
+	if err != nil {
+		return err
+	}
+	if err := store.HealthCheck(); err != nil {
+		_ = store.Close()
+		return err
+	}
+	s.dataStore = store
+	return nil
+}
+
+// databaseRemediation 数据库连接失败的修复建议，配置了命名数据存储时补充其设置的位置
+func (s *Server) databaseRemediation(error) string {
+	db := s.config.Database
+	var remediation string
+	switch db.Type {
+	case "memory":
+		remediation = "the memory datastore needs no connection, check database.type"
+	case "mongodb":
+		target := db.URI
+		if target == "" {
+			target = fmt.Sprintf("%s:%d", db.Host, db.Port)
+		}
+		remediation = fmt.Sprintf("check that MongoDB is running and reachable at %s and that database.user and database.password are correct "+
+			"(DATABASE_URI, DATABASE_HOST, DATABASE_PORT)", target)
+	default:
+		name := "PostgreSQL"
+		if db.Type == "opengauss" {
+			name = "openGauss"
+		}
+		remediation = fmt.Sprintf("check that %s is running and reachable at %s:%d, that database %q exists and that database.user, "+
+			"database.password and database.ssl_mode are correct (DATABASE_HOST, DATABASE_PORT, DATABASE_USER, DATABASE_PASSWORD)",
+			name, db.Host, db.Port, db.Database)
+	}
+	if len(db.Datastores) > 0 {
+		remediation += "; errors starting with \"datastore <name>\" concern the settings under database.datastores.<name>"
+	}
+	return remediation
+}
+
+// checkMigrations 启用自动迁移时执行迁移，否则检查数据库结构是否包含所有模型的表和列
+func (s *Server) checkMigrations() error {
+	if s.dataStore == nil {
+		return errPreflightSkipped
+	}
+
+	if s.config.Database.AutoMigrate {
+		return s.dataStore.Migrate()
+	}
+	checker, ok := s.dataStore.(datastore.SchemaChecker)
+	if !ok {
+		return errPreflightSkipped
+	}
+	return checker.CheckSchema()
+}
+
+// migrationsRemediation 迁移失败或数据库结构过期的修复建议
+func (s *Server) migrationsRemediation(err error) string {
+	if errors.Is(err, datastore.ErrSchemaOutdated) {
+		return "run the migrations with database.auto_migrate enabled (DATABASE_AUTO_MIGRATE=true) once, " +
+			"or apply them with a database user allowed to change the schema"
+	}
+	return "check that database.user may create and alter tables, or disable database.auto_migrate and apply the migrations separately"
+}
+
+// checkConnectionPooling 探测各SQL数据存储与数据库之间的连接池代理，代理不支持事务或
+// 以事务池模式运行而未启用database.transaction_pooling时发出警告
+func (s *Server) checkConnectionPooling() error {
+	if s.dataStore == nil {
+		return errPreflightSkipped
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), preflightProbeTimeout)
+	defer cancel()
+	checked := false
+	var errs []error
+	for _, named := range datastore.Datastores(s.dataStore) {
+		db, _ := s.config.Database.Datastore(named.Name)
+		var err error
+		if checker, ok := named.Store.(datastore.PoolingChecker); ok {
+			checked = true
+			err = checker.CheckPooling(ctx)
+		} else if db.TransactionPooling {
+			err = fmt.Errorf("database.transaction_pooling only applies to postgresql and opengauss, not %s", db.Type)
+		}
+		if err != nil && named.Name != datastore.PrimaryDatastore {
+			err = fmt.Errorf("datastore %s: %w", named.Name, err)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) == 0 && !checked {
+		return errPreflightSkipped
+	}
+	return errors.Join(errs...)
+}
+
+// checkRedis 配额、通知限流、签名随机数、人机识别、登录防暴力破解或会话拒绝列表使用Redis时检查Redis连接
+func (s *Server) checkRedis() error {
+	usesRedis := (s.config.Quota.Enabled && s.config.Quota.Store == quota.StoreRedis) ||
+		(s.config.Server.Cache.Enabled && s.config.Server.Cache.Store == httpcache.StoreRedis) ||
+		s.config.Notification.RateLimit.Store == quota.StoreRedis ||
+		s.config.Security.Signing.NonceStore == quota.StoreRedis ||
+		(s.config.Security.BotDetection.Enabled && s.config.Security.BotDetection.Store == quota.StoreRedis) ||
+		(s.config.Security.LoginThrottle.Enabled && s.config.Security.LoginThrottle.Store == quota.StoreRedis) ||
+		(s.config.Security.Sessions.Enabled && s.config.Security.Sessions.DenylistStore == quota.StoreRedis)
+	if !usesRedis {
+		return errPreflightSkipped
+	}
+
+	client, err := infra_middleware.NewRedisClient(s.config)
+	if err != nil {
+		return err
+	}
+	return client.Close()
+}
+
+// checkSecrets 非生产环境下使用内置密钥时发出警告，生产环境由配置校验拒绝
+func (s *Server) checkSecrets() error {
+	if s.config.IsProduction() {
+		return errPreflightSkipped
+	}
+
+	var defaults []string
+	if s.config.Security.JWTSecret == config.DefaultJWTSecret {
+		defaults = append(defaults, "security.jwt_secret")
+	}
+	if s.config.Security.EncryptionKey == config.DefaultEncryptionKey {
+		defaults = append(defaults, "security.encryption_key")
+	}
+	if len(defaults) > 0 {
+		return fmt.Errorf("%s use the built-in development defaults", strings.Join(defaults, " and "))
+	}
+	return nil
+}
+
+// logBanner 以结构化日志记录启动横幅：版本、环境、数据存储、监听地址和已启用的模块，
+// 与/info返回的信息一致
+func (s *Server) logBanner(addr string) {
+	info := s.systemInfo
+	logger.WithFields(logrus.Fields{
+		"banner":      true,
+		"service":     info.ServiceName,
+		"version":     info.Version,
+		"environment": info.Environment,
+		"go_version":  info.GoVersion,
+		"git_commit":  info.GitCommit,
+		"build_time":  info.BuildTime,
+		"datastore":   s.config.Database.Type,
+		"addr":        addr,
+		"modules":     info.EnabledModules(),
+	}).Info("Server starting")
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/api/router"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// TestRoutePoliciesMatchRoutes checks that the route policy of every API
+// matches a registered route, with the optional APIs enabled; a policy
+// matching no route leaves its route with the default policy
+func TestRoutePoliciesMatchRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	manager := config.NewManager()
+	if err := manager.Load("../../configs/app.yml"); err != nil {
+		t.Fatalf("failed to load configuration: %v", err)
+	}
+	cfg := manager.GetConfig()
+	cfg.Database.Type = "memory"
+	cfg.Organizations.Enabled = true
+	cfg.Registration.Enabled = true
+	cfg.Security.Sessions.Enabled = true
+	cfg.Security.Impersonation.Enabled = true
+	cfg.Server.GraphQL.Enabled = true
+	cfg.Server.GraphQL.Playground = true
+	cfg.Server.Gateway.Enabled = true
+	cfg.Server.Batch.Enabled = true
+
+	s := New(cfg)
+	if err := s.checkDatabase(); err != nil {
+		t.Fatalf("failed to connect the datastore: %v", err)
+	}
+	if err := s.initContainer(); err != nil {
+		t.Fatalf("failed to initialize the container: %v", err)
+	}
+
+	engine := gin.New()
+	policies := middleware.NewRoutePolicies()
+	routerConfig := router.DefaultRouterConfig()
+	routerConfig.Container = s.beanContainer
+	routerConfig.Batch = &cfg.Server.Batch
+	routerConfig.RoutePolicies = policies
+	if len(cfg.Server.API.Versions) > 0 {
+		routerConfig.APIConfig = &cfg.Server.API
+	}
+	router.InitRouterWithConfig(engine, nil, routerConfig)
+
+	declared := policies.Routes()
+	if len(declared) == 0 {
+		t.Fatal("no route policy was declared")
+	}
+	for _, route := range router.UnmatchedRoutePolicies(engine, declared) {
+		t.Errorf("route policy %s matches no route", route)
+	}
+}
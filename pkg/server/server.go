@@ -3,86 +3,327 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
 	"github.com/make-bin/server-tpl/pkg/api"
 	"github.com/make-bin/server-tpl/pkg/api/router"
 	"github.com/make-bin/server-tpl/pkg/api/validation"
+	"github.com/make-bin/server-tpl/pkg/domain/event"
 	"github.com/make-bin/server-tpl/pkg/domain/service"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/analytics"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/botdetect"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/broker"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore"
 	"github.com/make-bin/server-tpl/pkg/infrastructure/datastore/factory"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/denylist"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/errorreport"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/httpcache"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/loginguard"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/mailer"
+	infra_middleware "github.com/make-bin/server-tpl/pkg/infrastructure/middleware"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/monitor"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/netacl"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/notification"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/outbox"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/quota"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/retention"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/scanner"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/signing"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/storage"
+	"github.com/make-bin/server-tpl/pkg/infrastructure/watchdog"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
 	"github.com/make-bin/server-tpl/pkg/utils/config"
 	"github.com/make-bin/server-tpl/pkg/utils/container"
+	"github.com/make-bin/server-tpl/pkg/utils/featureflags"
+	"github.com/make-bin/server-tpl/pkg/utils/httpclient"
+	"github.com/make-bin/server-tpl/pkg/utils/i18n"
+	"github.com/make-bin/server-tpl/pkg/utils/idgen"
+	"github.com/make-bin/server-tpl/pkg/utils/listener"
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"github.com/make-bin/server-tpl/pkg/utils/module"
+	"github.com/make-bin/server-tpl/pkg/utils/pprof"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // Server HTTP服务器结构
 type Server struct {
 	config        *config.Config
+	httpMu        sync.Mutex // 保护httpServer，Start与Shutdown通常在不同的goroutine中调用
 	httpServer    *http.Server
 	beanContainer *container.SimpleContainer
 	dataStore     datastore.DatastoreInterface
+	errorReporter errorreport.Reporter
+	analytics     analytics.Sink
+	quotaManager  *quota.Manager
+	signing       *signing.Verifier
+	networkACL    *netacl.Manager
+	botDetector   *botdetect.Detector
+	loginGuard    *loginguard.Guard
+	denylist      *denylist.Denylist
+	responseCache *httpcache.Cache
+	pprofManager  *pprof.PProfManager
+	translator    i18n.Translator
+	clock         clock.Clock
+	systemInfo    *router.SystemInfo
+	sockets       *listener.Manager // Start创建，由httpMu保护
 }
 
-// New 创建新的服务器实例
+// New 创建新的服务器实例，默认使用系统时钟
 func New(cfg *config.Config) *Server {
 	return &Server{
 		config:        cfg,
 		beanContainer: container.NewContainer(),
+		clock:         clock.New(),
 	}
 }
 
-// Start 启动HTTP服务器
+// SetClock 替换服务器使用的时钟，测试中用于冻结和推进时间，需在Start之前调用。
+// 时钟用于数据存储的时间戳、缓存过期和令牌过期判断，并注册为clock bean
+func (s *Server) SetClock(clk clock.Clock) {
+	s.clock = clk
+}
+
+// Start 在配置的端口或server.listeners列出的所有TCP地址和Unix套接字上启动HTTP服务器，
+// 所有监听器共用同一个引擎。监听套接字按配置从升级前的进程或systemd继承，
+// 或者新建（可设置SO_REUSEPORT）；开始服务后通知升级前的进程和systemd
 func (s *Server) Start() error {
+	sockets, err := listener.New(&s.config.Server.Socket)
+	if err != nil {
+		return fmt.Errorf("failed to inherit sockets: %w", err)
+	}
+	s.httpMu.Lock()
+	s.sockets = sockets
+	s.httpMu.Unlock()
+
+	httpServer, err := s.setup()
+	if err != nil {
+		return err
+	}
+	var lns []net.Listener
+	var addrs []string
+	for _, l := range s.listeners() {
+		ln, err := sockets.Listen(l.Network, l.Address, l.FileMode())
+		if err != nil {
+			for _, ln := range lns {
+				_ = ln.Close()
+			}
+			return fmt.Errorf("failed to listen on %s %s: %w", l.Network, l.Address, err)
+		}
+		lns = append(lns, ln)
+		addrs = append(addrs, l.Network+"://"+ln.Addr().String())
+	}
+
+	s.logBanner(strings.Join(addrs, ","))
+	if err := sockets.Ready(); err != nil {
+		logger.Warn("Failed to report readiness: %v", err)
+	}
+
+	// 任一监听器出错时关闭服务器，其余监听器随之停止
+	errs := make(chan error, len(lns))
+	for _, ln := range lns {
+		go func(ln net.Listener) { errs <- httpServer.Serve(ln) }(ln)
+	}
+	err = <-errs
+	if err != http.ErrServerClosed {
+		_ = httpServer.Close()
+	}
+	return err
+}
+
+// listeners 返回配置的监听器，未配置server.listeners时监听server.port
+func (s *Server) listeners() []config.ListenerConfig {
+	if len(s.config.Server.Listeners) == 0 {
+		return []config.ListenerConfig{{Network: "tcp", Address: fmt.Sprintf(":%d", s.config.Server.Port)}}
+	}
+	listeners := make([]config.ListenerConfig, len(s.config.Server.Listeners))
+	for i, l := range s.config.Server.Listeners {
+		if l.Network == "" {
+			l.Network = "tcp"
+		}
+		listeners[i] = l
+	}
+	return listeners
+}
+
+// Upgrade 以相同参数启动新的二进制并移交监听套接字，新进程开始服务后返回，
+// 调用方随后应调用Shutdown排空现有连接。失败时新进程被终止，当前进程继续服务
+func (s *Server) Upgrade(ctx context.Context) error {
+	s.httpMu.Lock()
+	sockets := s.sockets
+	s.httpMu.Unlock()
+	if sockets == nil {
+		return listener.ErrUpgradeDisabled
+	}
+	return sockets.Upgrade(ctx)
+}
+
+// Serve 在调用方创建的监听器上启动HTTP服务器，例如测试中监听临时端口，返回时监听器已关闭
+func (s *Server) Serve(ln net.Listener) error {
+	httpServer, err := s.setup()
+	if err != nil {
+		_ = ln.Close()
+		return err
+	}
+
+	s.logBanner(ln.Addr().String())
+	return httpServer.Serve(ln)
+}
+
+// setup 初始化容器、启动bean并创建HTTP服务器
+func (s *Server) setup() (*http.Server, error) {
 	logger.Info("Starting server initialization...")
 
+	// 0. 启动前检查配置、数据库和Redis连接及数据库结构，失败时拒绝启动
+	if err := s.preflight(); err != nil {
+		return nil, err
+	}
+
 	// 1. 初始化依赖注入容器
 	if err := s.initContainer(); err != nil {
-		return fmt.Errorf("failed to initialize container: %w", err)
+		return nil, fmt.Errorf("failed to initialize container: %w", err)
 	}
 
-	// 2. 设置Gin模式
+	// 2. 按依赖顺序启动实现了OnStart的bean
+	if err := s.beanContainer.Start(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to start container beans: %w", err)
+	}
+
+	// 3. 设置Gin模式
 	if s.config.App.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	} else {
 		gin.SetMode(gin.DebugMode)
 	}
 
-	// 3. 创建Gin引擎
+	// 4. 创建Gin引擎
 	engine := gin.New()
+	if err := engine.SetTrustedProxies(s.config.Server.RequestID.TrustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 
-	// 4. 初始化路由
+	// 5. 初始化路由
 	// 注意：路由系统暂时不需要容器，使用nil
-	router.InitRouter(engine, nil)
+	routerConfig := router.DefaultRouterConfig()
+	routerConfig.ErrorReporter = s.errorReporter
+	if s.config.Monitor.Analytics.Enabled && s.config.Monitor.Analytics.AccessLog {
+		routerConfig.AccessLog = s.analytics
+	}
+	if s.config.Security.Impersonation.Enabled && module.Enabled(module.Auth) {
+		routerConfig.ImpersonationAudit = s.analytics
+	}
+	routerConfig.SecurityConfig = &s.config.Security
+	if s.networkACL.Enabled() {
+		routerConfig.NetworkACL = s.networkACL
+		if s.config.Monitor.Analytics.Enabled && s.config.Monitor.Analytics.Audit {
+			routerConfig.NetworkACLAudit = s.analytics
+		}
+	}
+	routerConfig.RequestID = &infra_middleware.RequestIDConfig{
+		Header:         s.config.Server.RequestID.Header,
+		TrustedProxies: s.config.Server.RequestID.TrustedProxies,
+	}
+	if evaluator, ok := s.beanContainer.GetByType(reflect.TypeOf((*featureflags.Evaluator)(nil)).Elem()); ok {
+		routerConfig.FeatureFlags = evaluator.(featureflags.Evaluator)
+	}
+	if assigner, ok := s.beanContainer.GetByType(reflect.TypeOf((*featureflags.Assigner)(nil)).Elem()); ok {
+		routerConfig.Experiments = assigner.(featureflags.Assigner)
+	}
+	if policies, ok := s.beanContainer.GetByType(reflect.TypeOf((*service.PolicyServiceInterface)(nil)).Elem()); ok {
+		routerConfig.Consent = policies.(service.PolicyServiceInterface)
+	}
+	if partners, ok := s.beanContainer.GetByType(reflect.TypeOf((*service.PartnerServiceInterface)(nil)).Elem()); ok {
+		routerConfig.Signing = s.signing
+		routerConfig.Partners = partners.(service.PartnerServiceInterface)
+	}
+	if s.botDetector.Enabled() {
+		routerConfig.BotDetection = s.botDetector
+	}
+	if s.loginGuard.Enabled() {
+		routerConfig.LoginThrottle = s.loginGuard
+	}
+	if s.config.Security.Sessions.Enabled {
+		if sessions, ok := s.beanContainer.GetByType(reflect.TypeOf((*service.SessionServiceInterface)(nil)).Elem()); ok {
+			routerConfig.Denylist = s.denylist
+			routerConfig.Sessions = sessions.(service.SessionServiceInterface)
+		}
+	}
+	if preferences, ok := s.beanContainer.GetByType(reflect.TypeOf((*service.UserPreferenceServiceInterface)(nil)).Elem()); ok {
+		routerConfig.Preferences = preferences.(service.UserPreferenceServiceInterface)
+	}
+	if s.config.Organizations.Enabled {
+		if organizations, ok := s.beanContainer.GetByType(reflect.TypeOf((*service.OrganizationServiceInterface)(nil)).Elem()); ok {
+			routerConfig.Organizations = organizations.(service.OrganizationServiceInterface)
+			routerConfig.OrganizationHeader = s.config.Organizations.Header
+		}
+	}
+	routerConfig.Container = s.beanContainer
+	routerConfig.LoadShedding = &s.config.Server.LoadShedding
+	routerConfig.Coalescing = &s.config.Server.Coalescing
+	routerConfig.Batch = &s.config.Server.Batch
+	if s.responseCache.Enabled() {
+		routerConfig.ResponseCache = s.responseCache
+	}
+	routerConfig.PProf = s.pprofManager
+	routerConfig.PProfAllowedIPs = s.config.Monitor.PProf.AllowedIPs
+	routerConfig.Clock = s.clock
+	s.systemInfo = router.NewSystemInfo(s.config)
+	routerConfig.SystemInfo = s.systemInfo
+	routerConfig.CheckRouteDocs = s.config.IsDevelopment()
+	routerConfig.StrictJSON = s.config.Server.StrictJSON
+	routerConfig.MetricsPath = ""
+	if s.config.Monitor.Prometheus.Enabled && s.config.Monitor.Prometheus.Mode == monitor.MetricsModePull {
+		routerConfig.MetricsPath = s.config.Monitor.Prometheus.Path
+	}
+	if s.quotaManager.Enabled() {
+		routerConfig.Quota = s.quotaManager
+	}
+	if len(s.config.Server.API.Versions) > 0 {
+		routerConfig.APIConfig = &s.config.Server.API
+	}
+	router.InitRouterWithConfig(engine, nil, routerConfig)
 
-	// 5. 创建HTTP服务器
-	s.httpServer = &http.Server{
+	// 6. 创建HTTP服务器
+	httpServer := &http.Server{
 		Addr:         fmt.Sprintf(":%d", s.config.Server.Port),
-		Handler:      engine,
+		Handler:      router.VersionNegotiation(engine, routerConfig.APIConfig),
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
 
-	logger.Info("Server starting on port %d", s.config.Server.Port)
-	return s.httpServer.ListenAndServe()
+	s.httpMu.Lock()
+	s.httpServer = httpServer
+	s.httpMu.Unlock()
+	return httpServer, nil
 }
 
 // Shutdown 优雅关闭服务器
 func (s *Server) Shutdown(ctx context.Context) error {
 	logger.Info("Shutting down server...")
 
-	if s.httpServer != nil {
-		if err := s.httpServer.Shutdown(ctx); err != nil {
+	s.httpMu.Lock()
+	httpServer := s.httpServer
+	s.httpMu.Unlock()
+	if httpServer != nil {
+		if err := httpServer.Shutdown(ctx); err != nil {
 			return fmt.Errorf("failed to shutdown HTTP server: %w", err)
 		}
 	}
 
-	// 清理容器
+	// 按依赖的相反顺序停止bean（错误上报刷新、数据库连接关闭等），然后清理容器
 	if s.beanContainer != nil {
+		if err := s.beanContainer.Stop(ctx); err != nil {
+			logger.Warn("Failed to stop container beans: %v", err)
+		}
 		s.beanContainer.Clear()
 	}
 
@@ -115,10 +356,9 @@ func (s *Server) initContainer() error {
 	}
 
 	// 5. 调用Populate()完成依赖注入
-	// 暂时跳过依赖注入以便测试基本启动
-	// if err := s.beanContainer.Populate(); err != nil {
-	//	return fmt.Errorf("failed to populate the bean container: %w", err)
-	// }
+	if err := s.beanContainer.Populate(); err != nil {
+		return fmt.Errorf("failed to populate the bean container: %w", err)
+	}
 
 	logger.Info("Container initialization completed successfully")
 	return nil
@@ -131,6 +371,16 @@ func (s *Server) registerUtilities() error {
 		return fmt.Errorf("failed to register config: %w", err)
 	}
 
+	// 注册时钟
+	if err := s.beanContainer.ProvideWithName("clock", s.clock); err != nil {
+		return fmt.Errorf("failed to register clock: %w", err)
+	}
+
+	// 注册容器自身，供诊断接口查看bean与注入关系
+	if err := s.beanContainer.ProvideWithName("container", s.beanContainer); err != nil {
+		return fmt.Errorf("failed to register container: %w", err)
+	}
+
 	// 注册验证器
 	v := validator.New()
 	validation.RegisterCustomValidators(v)
@@ -144,29 +394,339 @@ func (s *Server) registerUtilities() error {
 		v.RegisterValidation(name, fn)
 	}
 
+	// 请求绑定在验证前按 sanitize 标签清理字符串字段
+	binding.Validator = validation.NewSanitizingValidator(binding.Validator)
+
+	// 同时注册到Gin请求绑定使用的验证器，否则binding标签中的自定义规则无法识别
+	if engine, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		validation.RegisterCustomValidators(engine)
+		for name, fn := range validationInterfaces {
+			engine.RegisterValidation(name, fn)
+		}
+	}
+
+	// 注册翻译器：加载翻译的单例，以及按请求语言绑定的请求作用域翻译器
+	translator := i18n.NewTranslator(s.config.I18n.LocalesPath)
+	s.translator = translator
+	if err := s.beanContainer.ProvideWithName("i18n", translator); err != nil {
+		return fmt.Errorf("failed to register i18n: %w", err)
+	}
+	requestTranslator := func(ctx context.Context) (interface{}, error) {
+		lang := translator.GetLanguage()
+		if gc, ok := container.GinContext(ctx); ok {
+			lang = i18n.DetectLanguage(gc)
+		}
+		return translator.WithLanguage(lang), nil
+	}
+	if err := s.beanContainer.ProvideFactory(i18n.TranslatorBeanName, container.Request, requestTranslator); err != nil {
+		return fmt.Errorf("failed to register request translator: %w", err)
+	}
+	// 请求作用域本地化器：按请求语言和用户偏好的时区格式化日期与数字
+	requestLocalizer := func(ctx context.Context) (interface{}, error) {
+		lang, tz := translator.GetLanguage(), "UTC"
+		if gc, ok := container.GinContext(ctx); ok {
+			lang, tz = i18n.DetectLanguage(gc), i18n.DetectTimeZone(gc)
+		}
+		return i18n.NewLocalizer(lang, tz), nil
+	}
+	if err := s.beanContainer.ProvideFactory(i18n.LocalizerBeanName, container.Request, requestLocalizer); err != nil {
+		return fmt.Errorf("failed to register request localizer: %w", err)
+	}
+
 	logger.Debug("Utilities registered successfully")
 	return nil
 }
 
 // registerInfrastructure 注册基础设施组件
 func (s *Server) registerInfrastructure() error {
-	// 创建数据存储
-	datastoreFactory := factory.NewSimpleFactory()
-	datastore, err := datastoreFactory.CreateDatastore(s.config)
+	// 推送或OTLP导出模式下注册指标导出器，最先注册以便最后停止，停止时发送最后一次指标
+	metricsExporter, err := monitor.NewMetricsExporter(s.config)
 	if err != nil {
-		return fmt.Errorf("failed to create datastore: %w", err)
+		return fmt.Errorf("failed to create metrics exporter: %w", err)
 	}
-
-	// 执行数据库迁移
-	if err := datastore.Migrate(); err != nil {
-		return fmt.Errorf("failed to migrate database: %w", err)
+	if metricsExporter != nil {
+		if err := s.beanContainer.ProvideWithName("metrics_exporter", metricsExporter); err != nil {
+			return fmt.Errorf("failed to register metrics exporter: %w", err)
+		}
 	}
 
-	// 注册数据存储
-	s.dataStore = datastore
-	if err := s.beanContainer.ProvideWithName("datastore", datastore); err != nil {
+	// 注册启动前检查中已连接、迁移或检查过结构的数据存储
+	store := s.dataStore
+	if store == nil {
+		return fmt.Errorf("datastore is not connected, preflight checks did not run")
+	}
+	// 实体ID由数据存储分配或按 database.id_strategy 生成，模型钩子和各数据存储共用进程的生成器
+	ids, err := idgen.New(s.config.Database.IDStrategy, s.config.Database.NodeID, s.clock)
+	if err != nil {
+		return fmt.Errorf("invalid ID strategy: %w", err)
+	}
+	idgen.Use(ids)
+	// 隐藏内部ID时响应只返回实体的公开ID
+	idgen.Hide(s.config.Server.API.HideInternalIDs)
+	datastoreFactory := factory.NewSimpleFactory(s.clock)
+	// 配置了 database.datastores 时数据存储是按表路由的Router，各命名数据存储另以限定符注册
+	// （例如 inject:"datastore,hot"），先于Router注册以便在其后停止
+	for _, named := range datastore.Datastores(store) {
+		if named.Name == datastore.PrimaryDatastore {
+			continue
+		}
+		if err := s.beanContainer.ProvideWithName("datastore", named.Store, named.Name); err != nil {
+			return fmt.Errorf("failed to register datastore %s: %w", named.Name, err)
+		}
+	}
+	if err := s.beanContainer.ProvideWithName("datastore", store); err != nil {
 		return fmt.Errorf("failed to register datastore: %w", err)
 	}
+	// 按间隔检查各数据存储的健康状态并导出连接池指标
+	if s.config.Database.HealthInterval > 0 {
+		if err := s.beanContainer.ProvideWithName("datastore_probe", monitor.NewDatastoreProbe(store, s.config.Database.HealthInterval)); err != nil {
+			return fmt.Errorf("failed to register datastore probe: %w", err)
+		}
+	}
+
+	// 启用时注册数据存储统计历史，按间隔将各操作的耗时持久化并汇总，目前只有GORM数据存储记录操作统计
+	if s.config.Monitor.History.Enabled {
+		history := monitor.NewHistory(store, &s.config.Monitor.History, s.clock)
+		for _, named := range datastore.Datastores(store) {
+			provider, ok := named.Store.(datastore.GormProvider)
+			if !ok {
+				logger.Warn("Datastore %s does not record operation stats, its tables stay out of the datastore history", named.Name)
+				continue
+			}
+			if err := provider.DB().Use(monitor.NewGormPlugin(history.Monitor())); err != nil {
+				return fmt.Errorf("failed to install datastore monitor: %w", err)
+			}
+		}
+		if err := s.beanContainer.ProvideWithName("datastore_history", history); err != nil {
+			return fmt.Errorf("failed to register datastore history: %w", err)
+		}
+	}
+
+	// 注册缓存，由容器生命周期启动和停止过期清理
+	cache, err := datastoreFactory.CreateCache(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to create cache: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("cache", cache); err != nil {
+		return fmt.Errorf("failed to register cache: %w", err)
+	}
+
+	// 注册领域事件总线
+	bus := event.NewInMemoryBus()
+	if err := s.beanContainer.ProvideWithName("eventbus", bus); err != nil {
+		return fmt.Errorf("failed to register event bus: %w", err)
+	}
+
+	// 创建并注册响应缓存，由领域事件使缓存的响应失效；未启用时不连接缓存存储
+	responseCache, err := httpcache.New(s.config, s.clock)
+	if err != nil {
+		return fmt.Errorf("failed to create response cache: %w", err)
+	}
+	s.responseCache = responseCache
+	if err := s.beanContainer.ProvideWithName("response_cache", responseCache); err != nil {
+		return fmt.Errorf("failed to register response cache: %w", err)
+	}
+	bus.Subscribe(event.WildcardType, responseCache.HandleEvent)
+
+	// 启用时创建并注册消息代理和编解码器，按配置将领域事件桥接到消息代理
+	var messageBroker broker.Broker
+	var codec broker.Codec
+	if s.config.Broker.Enabled {
+		messageBroker, err = broker.New(&s.config.Broker)
+		if err != nil {
+			return fmt.Errorf("failed to create broker: %w", err)
+		}
+		if err := s.beanContainer.ProvideWithName("broker", messageBroker); err != nil {
+			return fmt.Errorf("failed to register broker: %w", err)
+		}
+		codec, err = broker.NewCodec(s.config.Broker.Codec)
+		if err != nil {
+			return fmt.Errorf("failed to create broker codec: %w", err)
+		}
+		if err := s.beanContainer.ProvideWithName("broker_codec", codec); err != nil {
+			return fmt.Errorf("failed to register broker codec: %w", err)
+		}
+		if len(s.config.Broker.Bridge.Events) > 0 {
+			bridge := broker.NewBridge(bus, messageBroker, codec, &s.config.Broker.Bridge)
+			if err := s.beanContainer.ProvideWithName("broker_bridge", bridge); err != nil {
+				return fmt.Errorf("failed to register broker bridge: %w", err)
+			}
+		}
+	}
+
+	// 注册工作单元，事务提交后再发布领域事件；启用发件箱时事件在同一事务中写入发件箱，由中继投递到消息代理
+	unitOfWork := datastore.NewUnitOfWorkManager(store, bus)
+	if s.config.Outbox.Enabled {
+		unitOfWork = datastore.NewUnitOfWorkManagerWithOutbox(store, bus, outbox.NewRecorder(&s.config.Outbox, codec))
+		if err := s.beanContainer.ProvideWithName("outbox_relay", outbox.NewRelay(store, messageBroker, &s.config.Outbox)); err != nil {
+			return fmt.Errorf("failed to register outbox relay: %w", err)
+		}
+	}
+	if err := s.beanContainer.ProvideWithName("unit_of_work", unitOfWork); err != nil {
+		return fmt.Errorf("failed to register unit of work: %w", err)
+	}
+
+	// 创建并注册对象存储（备份归档等文件）
+	objectStorage, err := storage.New(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to create storage: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("storage", objectStorage); err != nil {
+		return fmt.Errorf("failed to register storage: %w", err)
+	}
+
+	// 启用文件模块时注册上传文件的恶意软件扫描，未启用扫描时所有文件视为安全
+	if module.Enabled(module.Files) {
+		if err := s.beanContainer.ProvideWithName("scanner", scanner.New(&s.config.Files.Scan)); err != nil {
+			return fmt.Errorf("failed to register file scanner: %w", err)
+		}
+	}
+
+	// 创建并注册请求配额，未启用时不连接计数存储
+	quotaManager, err := quota.New(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to create quota manager: %w", err)
+	}
+	s.quotaManager = quotaManager
+	if err := s.beanContainer.ProvideWithName("quota", quotaManager); err != nil {
+		return fmt.Errorf("failed to register quota manager: %w", err)
+	}
+
+	// 创建并注册合作方请求签名校验，随机数存储按 security.signing.nonce_store 选择
+	verifier, err := signing.New(s.config, s.clock)
+	if err != nil {
+		return fmt.Errorf("failed to create request signing verifier: %w", err)
+	}
+	s.signing = verifier
+	if err := s.beanContainer.ProvideWithName("request_signing", verifier); err != nil {
+		return fmt.Errorf("failed to register request signing verifier: %w", err)
+	}
+
+	// 注册出站HTTP客户端工厂，调用第三方服务时使用；链路上下文按W3C Trace Context传播
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	httpClients := httpclient.NewFactory(&s.config.HTTPClient)
+	if err := s.beanContainer.ProvideWithName("http_client_factory", httpClients); err != nil {
+		return fmt.Errorf("failed to register http client factory: %w", err)
+	}
+
+	// 创建并注册人机识别，未启用时不连接计数存储；人机验证提供方使用出站HTTP客户端
+	botDetector, err := botdetect.New(s.config, httpClients, s.clock)
+	if err != nil {
+		return fmt.Errorf("failed to create bot detector: %w", err)
+	}
+	s.botDetector = botDetector
+	if err := s.beanContainer.ProvideWithName("bot_detection", botDetector); err != nil {
+		return fmt.Errorf("failed to register bot detector: %w", err)
+	}
+
+	// 启用认证模块时创建并注册登录防暴力破解和已终止会话的拒绝列表
+	if module.Enabled(module.Auth) {
+		// 登录防暴力破解未启用时不连接计数存储；锁定发布为领域事件，启用审计时记入审计日志
+		loginGuard, err := loginguard.New(s.config, bus, s.clock)
+		if err != nil {
+			return fmt.Errorf("failed to create login throttle: %w", err)
+		}
+		s.loginGuard = loginGuard
+		if err := s.beanContainer.ProvideWithName("login_throttle", loginGuard); err != nil {
+			return fmt.Errorf("failed to register login throttle: %w", err)
+		}
+
+		// 拒绝列表未启用会话时不连接存储
+		tokenDenylist, err := denylist.New(s.config)
+		if err != nil {
+			return fmt.Errorf("failed to create session denylist: %w", err)
+		}
+		s.denylist = tokenDenylist
+		if err := s.beanContainer.ProvideWithName("token_denylist", tokenDenylist); err != nil {
+			return fmt.Errorf("failed to register session denylist: %w", err)
+		}
+	}
+
+	// 创建并注册邮件发送和邮件模板，未启用时邮件只写入日志
+	mailSender, err := mailer.New(&s.config.Mail)
+	if err != nil {
+		return fmt.Errorf("failed to create mailer: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("mailer", mailSender); err != nil {
+		return fmt.Errorf("failed to register mailer: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("mail_templates", mailer.NewTemplates(s.config.Mail.TemplatesPath, s.translator)); err != nil {
+		return fmt.Errorf("failed to register mail templates: %w", err)
+	}
+
+	// 创建并注册短信、推送和Webhook通知及其模板，未启用时没有可用的通知渠道
+	notifier, err := notification.New(s.config, httpClients)
+	if err != nil {
+		return fmt.Errorf("failed to create notifier: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("notifier", notifier); err != nil {
+		return fmt.Errorf("failed to register notifier: %w", err)
+	}
+	if err := s.beanContainer.ProvideWithName("notification_templates", notification.NewTemplates(s.config.Notification.TemplatesPath, s.translator)); err != nil {
+		return fmt.Errorf("failed to register notification templates: %w", err)
+	}
+	if s.loginGuard.Enabled() && module.Enabled(module.Webhooks) && s.config.Security.LoginThrottle.WebhookURL != "" {
+		// 启用Webhook模块时通过Webhook通知渠道推送登录锁定
+		loginguard.SubscribeWebhook(bus, notifier, s.config.Security.LoginThrottle.WebhookURL)
+	}
+
+	// 创建并注册错误上报，启用管理模块和管理接口时在内存中保留最近的错误事件
+	errorReporter, err := errorreport.New(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to create error reporter: %w", err)
+	}
+	if s.config.Monitor.Admin.Enabled && module.Enabled(module.Admin) {
+		errorReporter = errorreport.NewRecentReporter(errorReporter, s.config.Monitor.Admin.RecentErrors)
+	}
+	s.errorReporter = errorReporter
+	if err := s.beanContainer.ProvideWithName("error_reporter", errorReporter); err != nil {
+		return fmt.Errorf("failed to register error reporter: %w", err)
+	}
+
+	// 创建并注册分析数据接收器，启用时按配置记录访问日志和审计事件（领域事件）
+	analyticsSink, err := analytics.New(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to create analytics sink: %w", err)
+	}
+	s.analytics = analyticsSink
+	if err := s.beanContainer.ProvideWithName("analytics", analyticsSink); err != nil {
+		return fmt.Errorf("failed to register analytics sink: %w", err)
+	}
+	if s.config.Monitor.Analytics.Enabled && s.config.Monitor.Analytics.Audit {
+		analytics.SubscribeAudit(bus, analyticsSink)
+	}
+
+	// 创建并注册网络访问控制，启用且配置了GeoIP数据库时打开数据库；运行时的规则变更发布为领域事件
+	networkACL, err := netacl.New(&s.config.Security.NetworkACL, bus)
+	if err != nil {
+		return fmt.Errorf("failed to create network ACL: %w", err)
+	}
+	s.networkACL = networkACL
+	if err := s.beanContainer.ProvideWithName("network_acl", networkACL); err != nil {
+		return fmt.Errorf("failed to register network ACL: %w", err)
+	}
+
+	// 注册数据保留管理器，启用时按保留策略定期分批删除过期记录，在数据存储和分析数据接收器之前停止
+	if err := s.beanContainer.ProvideWithName("retention", retention.New(s.config, store, analyticsSink, s.clock)); err != nil {
+		return fmt.Errorf("failed to register retention manager: %w", err)
+	}
+
+	// 注册PProf管理器，启用时其路由挂载在主路由上，停止时结束正在进行的采集
+	pprofManager := pprof.NewPProfManager(&pprof.PProfConfig{
+		Enabled:    s.config.Monitor.PProf.Enabled,
+		PathPrefix: s.config.Monitor.PProf.PathPrefix,
+	})
+	s.pprofManager = pprofManager
+	if err := s.beanContainer.ProvideWithName("pprof", pprofManager); err != nil {
+		return fmt.Errorf("failed to register pprof manager: %w", err)
+	}
+
+	// 启用时注册资源看门狗，按间隔采样运行时统计并报告持续增长的资源
+	if s.config.Monitor.Watchdog.Enabled {
+		if err := s.beanContainer.ProvideWithName("watchdog", watchdog.New(pprofManager, &s.config.Monitor.Watchdog)); err != nil {
+			return fmt.Errorf("failed to register watchdog: %w", err)
+		}
+	}
 
 	logger.Debug("Infrastructure components registered successfully")
 	return nil
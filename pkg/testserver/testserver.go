@@ -0,0 +1,251 @@
+// Package testserver boots the full server in-process for end-to-end tests.
+//
+// The server runs with the memory datastore on an ephemeral port of the
+// loopback interface and is shut down when the test finishes:
+//
+//	func TestCreateApplication(t *testing.T) {
+//		ts := testserver.New(t)
+//
+//		resp, err := ts.Client.Post("/api/v1/applications", "application/json",
+//			strings.NewReader(`{"name":"demo"}`))
+//		...
+//	}
+//
+// Servers share process-wide state such as the Gin mode, the binding validator
+// and the Prometheus registry, so do not start them from parallel tests.
+package testserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/make-bin/server-tpl/pkg/api/middleware"
+	"github.com/make-bin/server-tpl/pkg/server"
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Principal of the token used by TestServer.Client
+const (
+	DefaultUserID = "test-user"
+	DefaultRole   = "admin"
+)
+
+const (
+	// tokenTTL is the lifetime of minted tokens without an expiry
+	tokenTTL = time.Hour
+	// startupTimeout bounds the time until the server answers health checks
+	startupTimeout = 10 * time.Second
+	// shutdownTimeout bounds the graceful shutdown
+	shutdownTimeout = 10 * time.Second
+)
+
+// Option customizes a test server
+type Option func(*options)
+
+type options struct {
+	configure []func(*config.Config)
+	clock     clock.Clock
+}
+
+// WithConfig changes the configuration before the server starts. The server
+// listens on an ephemeral port, so the port set by fn is ignored.
+func WithConfig(fn func(cfg *config.Config)) Option {
+	return func(o *options) {
+		o.configure = append(o.configure, fn)
+	}
+}
+
+// WithClock makes the server read the time from clk, e.g. a clock.Fake
+func WithClock(clk clock.Clock) Option {
+	return func(o *options) {
+		o.clock = clk
+	}
+}
+
+// TestServer is a running server
+type TestServer struct {
+	// URL is the base URL of the server, e.g. http://127.0.0.1:34567
+	URL string
+	// Config is the configuration the server was started with
+	Config *config.Config
+	// Server is the running server, e.g. to reach the datastore
+	Server *server.Server
+	// Clock is the clock of the server
+	Clock clock.Clock
+	// Client sends requests authenticated as DefaultUserID with DefaultRole.
+	// Requests to a URL without a host, such as "/api/v1/applications", are
+	// sent to the server.
+	Client *http.Client
+
+	host      string
+	transport *http.Transport
+	done      chan struct{}
+	serveErr  error
+	closeOnce sync.Once
+}
+
+// New starts a server that is shut down when t finishes. It fails t when the
+// server does not start. The configuration is the default one with the memory
+// datastore, the memory object storage and CSRF protection disabled; use
+// WithConfig to change it.
+func New(t testing.TB, opts ...Option) *TestServer {
+	t.Helper()
+
+	o := options{clock: clock.New()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	cfg := config.New()
+	cfg.Database.Type = "memory"
+	cfg.Storage.Type = "memory"
+	cfg.Security.CSRFEnabled = false
+	for _, fn := range o.configure {
+		fn(cfg)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	cfg.Server.Port = ln.Addr().(*net.TCPAddr).Port
+
+	srv := server.New(cfg)
+	srv.SetClock(o.clock)
+
+	ts := &TestServer{
+		URL:       "http://" + ln.Addr().String(),
+		Config:    cfg,
+		Server:    srv,
+		Clock:     o.clock,
+		host:      ln.Addr().String(),
+		transport: http.DefaultTransport.(*http.Transport).Clone(),
+		done:      make(chan struct{}),
+	}
+	go func() {
+		defer close(ts.done)
+		if err := srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			ts.serveErr = err
+		}
+	}()
+	t.Cleanup(ts.Close)
+
+	if err := ts.waitReady(); err != nil {
+		t.Fatalf("test server did not start: %v", err)
+	}
+	ts.Client = ts.ClientWithToken(ts.Token(DefaultUserID, DefaultRole))
+	return ts
+}
+
+// Token returns a token for userID with role and permissions that expires an
+// hour after the current time of the server clock
+func (ts *TestServer) Token(userID, role string, permissions ...string) string {
+	return ts.TokenWithClaims(middleware.JWTClaims{
+		UserID:      userID,
+		Username:    userID,
+		Role:        role,
+		Permissions: permissions,
+	})
+}
+
+// TokenWithClaims signs claims with the server secret. Without an expiry the
+// token expires an hour after the current time of the server clock.
+func (ts *TestServer) TokenWithClaims(claims middleware.JWTClaims) string {
+	now := ts.Clock.Now()
+	if claims.IssuedAt == nil {
+		claims.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if claims.ExpiresAt == nil {
+		claims.ExpiresAt = jwt.NewNumericDate(now.Add(tokenTTL))
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(ts.Config.Security.JWTSecret))
+	if err != nil {
+		// HMAC signing only fails for keys of the wrong type
+		panic(fmt.Sprintf("testserver: failed to sign token: %v", err))
+	}
+	return token
+}
+
+// ClientWithToken returns a client sending token with every request. An empty
+// token sends anonymous requests. Like Client, it sends requests to a URL
+// without a host to the server.
+func (ts *TestServer) ClientWithToken(token string) *http.Client {
+	return &http.Client{
+		Transport: &transport{base: ts.transport, host: ts.host, token: token},
+	}
+}
+
+// Close shuts the server down and closes idle client connections. It is
+// called when the test finishes and is safe to call more than once.
+func (ts *TestServer) Close() {
+	ts.closeOnce.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		ts.transport.CloseIdleConnections()
+		_ = ts.Server.Shutdown(ctx)
+		select {
+		case <-ts.done:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// waitReady polls the health endpoint until the server answers
+func (ts *TestServer) waitReady() error {
+	client := &http.Client{Transport: ts.transport, Timeout: time.Second}
+	deadline := time.Now().Add(startupTimeout)
+	for {
+		select {
+		case <-ts.done:
+			if ts.serveErr != nil {
+				return ts.serveErr
+			}
+			return errors.New("server stopped")
+		default:
+		}
+
+		resp, err := client.Get(ts.URL + "/health")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+			err = fmt.Errorf("health check returned %s", resp.Status)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out after %v: %w", startupTimeout, err)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// transport sends requests without a host to the server and authenticates them
+type transport struct {
+	base  http.RoundTripper
+	host  string
+	token string
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	if req.URL.Host == "" {
+		req.URL.Scheme = "http"
+		req.URL.Host = t.host
+		req.Host = t.host
+	}
+	if t.token != "" && req.Header.Get("Authorization") == "" {
+		req.Header.Set("Authorization", "Bearer "+t.token)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,159 @@
+package bench
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Buckets are the upper bounds of the latency histogram; slower operations
+// are counted in the overflow bucket
+var Buckets = []time.Duration{
+	50 * time.Microsecond,
+	100 * time.Microsecond,
+	250 * time.Microsecond,
+	500 * time.Microsecond,
+	time.Millisecond,
+	2500 * time.Microsecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+}
+
+// Op is a single benchmarked operation; i is the iteration number
+type Op func(ctx context.Context, i int) error
+
+// Options controls a benchmark run
+type Options struct {
+	Concurrency int // number of workers
+	Iterations  int // total number of operations shared by the workers
+}
+
+// Bucket is a histogram bucket holding the operations at most UpperBound long;
+// the overflow bucket has a zero UpperBound
+type Bucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+// Result summarizes the latencies of a benchmark run
+type Result struct {
+	Concurrency int
+	Operations  int
+	Errors      int
+	// FirstError is the message of the first failed operation
+	FirstError string
+	Elapsed    time.Duration
+	Throughput float64 // operations per second
+	Min        time.Duration
+	Mean       time.Duration
+	Max        time.Duration
+	P50        time.Duration
+	P90        time.Duration
+	P99        time.Duration
+	Histogram  []Bucket
+}
+
+// Run executes op Iterations times across Concurrency workers and records the
+// latency of every operation. Cancelling ctx stops the run early; the result
+// then covers the completed operations.
+func Run(ctx context.Context, opts Options, op Op) *Result {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Iterations < 1 {
+		opts.Iterations = 1
+	}
+
+	var (
+		next       atomic.Int64
+		errCount   atomic.Int64
+		firstError atomic.Value
+		wg         sync.WaitGroup
+	)
+	latencies := make([][]time.Duration, opts.Concurrency)
+	start := time.Now()
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for ctx.Err() == nil {
+				i := int(next.Add(1)) - 1
+				if i >= opts.Iterations {
+					return
+				}
+				began := time.Now()
+				err := op(ctx, i)
+				latencies[w] = append(latencies[w], time.Since(began))
+				if err != nil {
+					errCount.Add(1)
+					firstError.CompareAndSwap(nil, err.Error())
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	result := summarize(latencies, time.Since(start))
+	result.Concurrency = opts.Concurrency
+	result.Errors = int(errCount.Load())
+	if msg, ok := firstError.Load().(string); ok {
+		result.FirstError = msg
+	}
+	return result
+}
+
+// summarize merges the latencies of the workers into a Result
+func summarize(perWorker [][]time.Duration, elapsed time.Duration) *Result {
+	var all []time.Duration
+	for _, l := range perWorker {
+		all = append(all, l...)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+
+	result := &Result{
+		Operations: len(all),
+		Elapsed:    elapsed,
+		Histogram:  make([]Bucket, len(Buckets)+1),
+	}
+	for i, bound := range Buckets {
+		result.Histogram[i].UpperBound = bound
+	}
+	if len(all) == 0 {
+		return result
+	}
+
+	var total time.Duration
+	for _, d := range all {
+		total += d
+		b := sort.Search(len(Buckets), func(i int) bool { return d <= Buckets[i] })
+		result.Histogram[b].Count++
+	}
+	result.Min = all[0]
+	result.Max = all[len(all)-1]
+	result.Mean = total / time.Duration(len(all))
+	result.P50 = percentile(all, 0.50)
+	result.P90 = percentile(all, 0.90)
+	result.P99 = percentile(all, 0.99)
+	if elapsed > 0 {
+		result.Throughput = float64(len(all)) / elapsed.Seconds()
+	}
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
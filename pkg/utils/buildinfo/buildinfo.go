@@ -0,0 +1,85 @@
+// Package buildinfo describes the build of the binary. The version, commit
+// and date are set at link time:
+//
+//	go build -ldflags "-X github.com/make-bin/server-tpl/pkg/utils/buildinfo.Version=v1.2.3 \
+//	  -X github.com/make-bin/server-tpl/pkg/utils/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/make-bin/server-tpl/pkg/utils/buildinfo.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Without them, the commit and date recorded by the go toolchain are used.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set with -ldflags "-X", empty when not set
+var (
+	Version string
+	Commit  string
+	Date    string
+)
+
+// Unknown is reported for build settings that are not known
+const Unknown = "unknown"
+
+// Info describes the build of the binary
+type Info struct {
+	// Version is the release version, empty for development builds
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"go_version"`
+	// Modified reports a build from a working tree with uncommitted changes
+	Modified bool `json:"modified"`
+}
+
+var (
+	once sync.Once
+	info Info
+)
+
+// Get returns the build of the binary
+func Get() Info {
+	once.Do(func() {
+		info = read()
+	})
+	return info
+}
+
+// read combines the link time settings with the build settings of the go toolchain
+func read() Info {
+	i := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+	if build, ok := debug.ReadBuildInfo(); ok {
+		if i.Version == "" && build.Main.Version != "(devel)" {
+			i.Version = build.Main.Version
+		}
+		for _, setting := range build.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				if i.Commit == "" {
+					i.Commit = setting.Value
+				}
+			case "vcs.time":
+				if i.Date == "" {
+					i.Date = setting.Value
+				}
+			case "vcs.modified":
+				i.Modified = setting.Value == "true"
+			}
+		}
+	}
+	if i.Commit == "" {
+		i.Commit = Unknown
+	}
+	if i.Date == "" {
+		i.Date = Unknown
+	}
+	return i
+}
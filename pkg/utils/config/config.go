@@ -2,7 +2,10 @@ package config
 
 import (
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -15,81 +18,334 @@ type Manager interface {
 	GetConfig() *Config
 	WatchConfig(callback func(*Config))
 	Validate() error
+	Sources() []string
+	Settings(redacted bool) map[string]interface{}
+	Close() error
 }
 
 // ConfigManager implements the Manager interface
 type ConfigManager struct {
-	viper  *viper.Viper
-	config *Config
+	viper   *viper.Viper
+	config  *Config
+	sources []string
+	mu      sync.RWMutex
+
+	// Remote backend and the last good remote document
+	remote       RemoteProvider
+	remoteConfig RemoteConfig
+	remoteData   []byte
+	remoteSource string
+	stop         chan struct{}
 }
 
 // Config holds the application configuration
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Redis    RedisConfig    `mapstructure:"redis"`
-	Log      LogConfig      `mapstructure:"log"`
-	Server   ServerConfig   `mapstructure:"server"`
-	Monitor  MonitorConfig  `mapstructure:"monitor"`
+	App           AppConfig               `mapstructure:"app"`
+	Modules       map[string]ModuleConfig `mapstructure:"modules"`
+	Database      DatabaseConfig          `mapstructure:"database"`
+	Redis         RedisConfig             `mapstructure:"redis"`
+	Log           LogConfig               `mapstructure:"log"`
+	Server        ServerConfig            `mapstructure:"server"`
+	Monitor       MonitorConfig           `mapstructure:"monitor"`
+	FeatureFlags  FeatureFlagsConfig      `mapstructure:"feature_flags"`
+	Experiments   []ExperimentConfig      `mapstructure:"experiments" validate:"dive"`
+	I18n          I18nConfig              `mapstructure:"i18n"`
+	Preferences   PreferencesConfig       `mapstructure:"preferences"`
+	Organizations OrganizationsConfig     `mapstructure:"organizations"`
+	Authorization AuthorizationConfig     `mapstructure:"authorization"`
+	Invitations   InvitationsConfig       `mapstructure:"invitations"`
+	Registration  RegistrationConfig      `mapstructure:"registration"`
+	Remote        RemoteConfig            `mapstructure:"remote"`
+	Security      SecurityConfig          `mapstructure:"security"`
+	Revisions     RevisionsConfig         `mapstructure:"revisions"`
+	Storage       StorageConfig           `mapstructure:"storage"`
+	Files         FilesConfig             `mapstructure:"files"`
+	Quota         QuotaConfig             `mapstructure:"quota"`
+	HTTPClient    HTTPClientConfig        `mapstructure:"http_client"`
+	Mail          MailConfig              `mapstructure:"mail"`
+	Notification  NotificationConfig      `mapstructure:"notification"`
+	Broker        BrokerConfig            `mapstructure:"broker"`
+	Outbox        OutboxConfig            `mapstructure:"outbox"`
+	EventLog      EventLogConfig          `mapstructure:"event_log"`
+	Retention     RetentionConfig         `mapstructure:"retention"`
 }
 
 // AppConfig holds application configuration
 type AppConfig struct {
-	Name    string `mapstructure:"name"`
+	Name    string `mapstructure:"name" validate:"required"`
 	Version string `mapstructure:"version"`
-	Env     string `mapstructure:"env"`
+	Env     string `mapstructure:"env" validate:"required"`
 	Debug   bool   `mapstructure:"debug"`
 }
 
+// ModuleConfig holds the configuration of a module of the template by name,
+// e.g. files; modules left out of the configuration are enabled
+type ModuleConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
 // DatabaseConfig holds database configuration
 type DatabaseConfig struct {
-	Type            string        `mapstructure:"type"`
-	Host            string        `mapstructure:"host"`
-	Port            int           `mapstructure:"port"`
-	User            string        `mapstructure:"user"`
-	Password        string        `mapstructure:"password"`
-	Database        string        `mapstructure:"database"`
-	SSLMode         string        `mapstructure:"ssl_mode"`
-	MaxOpenConns    int           `mapstructure:"max_open_conns"`
-	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	Type               string        `mapstructure:"type" validate:"required,oneof=memory postgresql opengauss mongodb"`
+	URI                string        `mapstructure:"uri"` // mongodb connection string, overrides host, port and credentials
+	Host               string        `mapstructure:"host" validate:"required_unless=Type memory"`
+	Port               int           `mapstructure:"port" validate:"min=0,max=65535"`
+	User               string        `mapstructure:"user"`
+	Password           string        `mapstructure:"password"`
+	Database           string        `mapstructure:"database" validate:"required_unless=Type memory"`
+	SSLMode            string        `mapstructure:"ssl_mode" validate:"omitempty,oneof=disable allow prefer require verify-ca verify-full"`
+	MaxOpenConns       int           `mapstructure:"max_open_conns" validate:"min=0"`
+	MaxIdleConns       int           `mapstructure:"max_idle_conns" validate:"min=0"`
+	ConnMaxLifetime    time.Duration `mapstructure:"conn_max_lifetime" validate:"min=0"`
+	AutoMigrate        bool          `mapstructure:"auto_migrate"` // migrate on startup, otherwise only verify the schema
+	IDStrategy         string        `mapstructure:"id_strategy" validate:"omitempty,oneof=auto_increment snowflake ulid"`
+	NodeID             int64         `mapstructure:"node_id" validate:"min=0,max=1023"` // snowflake node, unique per instance
+	TxRetry            TxRetryConfig `mapstructure:"tx_retry"`
+	TransactionPooling bool          `mapstructure:"transaction_pooling"` // behind a transaction pooling proxy such as PgBouncer
+	Etcd               EtcdConfig    `mapstructure:"etcd"`
+	Tenancy            TenancyConfig `mapstructure:"tenancy"`
+	RLS                RLSConfig     `mapstructure:"rls"`
+	Tags               TagsConfig    `mapstructure:"tags"`
+	// Datastores are further databases by name, e.g. for hot tables. Settings
+	// a datastore leaves out are those of the primary database above.
+	Datastores map[string]DatabaseConfig `mapstructure:"datastores" validate:"dive"`
+	// Routes map table names to the datastore holding them; tables left out
+	// are held by the primary database
+	Routes map[string]string `mapstructure:"routes"`
+	// HealthInterval is the interval of the health and pool metrics of each datastore, 0 disables them
+	HealthInterval time.Duration `mapstructure:"health_interval" validate:"min=0"`
+	// Name is the name of a datastore under Datastores, empty for the primary database
+	Name string `mapstructure:"-"`
+}
+
+// TagsConfig holds how the SQL datastores store the tags of tagged entities:
+// in a JSONB column with a GIN index (column), or besides it in a join table
+// per tagged table, named <table>_tags, on which tag selectors run (join_table)
+type TagsConfig struct {
+	Storage string `mapstructure:"storage" validate:"oneof=column join_table"`
+}
+
+// TxRetryConfig holds the retries of SQL transactions failing with a
+// serialization failure or deadlock, with exponential backoff
+type TxRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts" validate:"min=0"` // 1 or less disables retries
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" validate:"min=0"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" validate:"min=0"`
+}
+
+// TenancyConfig holds how the data of organizations is isolated in the SQL
+// datastores: in shared tables filtered by organization (row), or in the
+// tables of a PostgreSQL schema per organization (schema)
+type TenancyConfig struct {
+	Mode         string   `mapstructure:"mode" validate:"oneof=row schema"`
+	SchemaPrefix string   `mapstructure:"schema_prefix" validate:"required_if=Mode schema,max=40"` // schemas are named <prefix><organization ID>
+	Tables       []string `mapstructure:"tables"`                                                  // tables held in the schema of each organization
+}
+
+// RLSConfig holds the PostgreSQL row-level security of the tables owned by
+// organizations: their policies restrict the rows to the organization in the
+// app.current_tenant session variable, which the datastore sets per request
+// with app.current_user
+type RLSConfig struct {
+	Enabled bool     `mapstructure:"enabled"`
+	Tables  []string `mapstructure:"tables"` // tables restricted by their org_id column
+	// AllowUnscoped lets sessions without a current tenant, such as
+	// background tasks, see and write every row
+	AllowUnscoped bool `mapstructure:"allow_unscoped"`
+}
+
+// EtcdConfig holds the configuration of the etcd key-value datastore
+type EtcdConfig struct {
+	Endpoints   []string      `mapstructure:"endpoints"`
+	Prefix      string        `mapstructure:"prefix"` // root of every key written by the datastore
+	Username    string        `mapstructure:"username"`
+	Password    string        `mapstructure:"password"`
+	DialTimeout time.Duration `mapstructure:"dial_timeout" validate:"min=0"`
+	CacheSize   int           `mapstructure:"cache_size" validate:"min=0"` // entries of the read cache, 0 disables it
 }
 
 // RedisConfig holds Redis configuration
 type RedisConfig struct {
 	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
+	Port         int           `mapstructure:"port" validate:"min=1,max=65535"`
 	Password     string        `mapstructure:"password"`
-	Database     int           `mapstructure:"database"`
-	PoolSize     int           `mapstructure:"pool_size"`
-	MinIdleConns int           `mapstructure:"min_idle_conns"`
-	MaxRetries   int           `mapstructure:"max_retries"`
-	DialTimeout  time.Duration `mapstructure:"dial_timeout"`
+	Database     int           `mapstructure:"database" validate:"min=0,max=15"`
+	PoolSize     int           `mapstructure:"pool_size" validate:"min=1"`
+	MinIdleConns int           `mapstructure:"min_idle_conns" validate:"min=0,ltefield=PoolSize"`
+	MaxRetries   int           `mapstructure:"max_retries" validate:"min=0"`
+	DialTimeout  time.Duration `mapstructure:"dial_timeout" validate:"gt=0"`
 }
 
 // LogConfig holds logging configuration
 type LogConfig struct {
-	Level      string            `mapstructure:"level"`
-	Format     string            `mapstructure:"format"`
-	Output     string            `mapstructure:"output"`
-	FilePath   string            `mapstructure:"file_path"`
-	MaxSize    int               `mapstructure:"max_size"`
-	MaxBackups int               `mapstructure:"max_backups"`
-	MaxAge     int               `mapstructure:"max_age"`
+	Level      string            `mapstructure:"level" validate:"oneof=trace debug info warn warning error fatal panic"`
+	Format     string            `mapstructure:"format" validate:"oneof=json text"`
+	Output     string            `mapstructure:"output" validate:"oneof=stdout file both"`
+	FilePath   string            `mapstructure:"file_path" validate:"required_unless=Output stdout"`
+	MaxSize    int               `mapstructure:"max_size" validate:"min=0"`
+	MaxBackups int               `mapstructure:"max_backups" validate:"min=0"`
+	MaxAge     int               `mapstructure:"max_age" validate:"min=0"`
 	Compress   bool              `mapstructure:"compress"`
 	Fields     map[string]string `mapstructure:"fields"`
-	BufferSize int               `mapstructure:"buffer_size"`
+	BufferSize int               `mapstructure:"buffer_size" validate:"min=0"`
 	Async      bool              `mapstructure:"async"`
 }
 
 // ServerConfig holds server configuration
 type ServerConfig struct {
-	Host         string        `mapstructure:"host"`
-	Port         int           `mapstructure:"port"`
-	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
-	WriteTimeout time.Duration `mapstructure:"write_timeout"`
-	IdleTimeout  time.Duration `mapstructure:"idle_timeout"`
-	CORS         CORSConfig    `mapstructure:"cors"`
+	Host         string             `mapstructure:"host"`
+	Port         int                `mapstructure:"port" validate:"min=1,max=65535"`
+	ReadTimeout  time.Duration      `mapstructure:"read_timeout" validate:"min=0"`
+	WriteTimeout time.Duration      `mapstructure:"write_timeout" validate:"min=0"`
+	IdleTimeout  time.Duration      `mapstructure:"idle_timeout" validate:"min=0"`
+	CORS         CORSConfig         `mapstructure:"cors"`
+	RequestID    RequestIDConfig    `mapstructure:"request_id"`
+	API          APIConfig          `mapstructure:"api"`
+	LoadShedding LoadSheddingConfig `mapstructure:"load_shedding"`
+	Coalescing   CoalescingConfig   `mapstructure:"coalescing"`
+	Cache        HTTPCacheConfig    `mapstructure:"response_cache"`
+	GraphQL      GraphQLConfig      `mapstructure:"graphql"`
+	Gateway      GatewayConfig      `mapstructure:"gateway"`
+	Batch        BatchConfig        `mapstructure:"batch"`
+	Bench        BenchConfig        `mapstructure:"bench"`
+	Socket       SocketConfig       `mapstructure:"socket"`
+	// Listeners replace the TCP listener on Port when set, e.g. to serve a
+	// local reverse proxy or sidecar over a unix socket
+	Listeners []ListenerConfig `mapstructure:"listeners" validate:"dive"`
+	// StrictJSON rejects JSON request bodies with fields the request DTO does
+	// not have, e.g. typos, unless the route policy allows unknown fields
+	StrictJSON bool `mapstructure:"strict_json"`
+}
+
+// ListenerConfig holds an address the server listens on. Every listener
+// serves the same routes.
+type ListenerConfig struct {
+	Network string `mapstructure:"network" validate:"omitempty,oneof=tcp unix"` // tcp when empty
+	Address string `mapstructure:"address" validate:"required"`                 // host:port, or the socket path for unix
+	Mode    string `mapstructure:"mode"`                                        // octal permissions of the unix socket file, e.g. "0660"
+}
+
+// FileMode returns the permissions of the unix socket file, or 0 to keep the
+// permissions given by the umask
+func (l ListenerConfig) FileMode() os.FileMode {
+	mode, err := strconv.ParseUint(l.Mode, 8, 32)
+	if err != nil {
+		return 0
+	}
+	return os.FileMode(mode) & os.ModePerm
+}
+
+// SocketConfig holds how the server obtains its listening socket for zero
+// downtime deploys. With Upgrade, the upgrade signal (SIGUSR2) starts the new
+// binary with the socket and the old process drains once the new one serves.
+type SocketConfig struct {
+	Systemd        bool          `mapstructure:"systemd"`    // use systemd socket activation and notify readiness
+	ReusePort      bool          `mapstructure:"reuse_port"` // set SO_REUSEPORT so that another process can bind the port
+	Upgrade        bool          `mapstructure:"upgrade"`
+	UpgradeTimeout time.Duration `mapstructure:"upgrade_timeout" validate:"required_if=Upgrade true,min=0"` // wait for the new process to serve
+	PIDFile        string        `mapstructure:"pid_file"`                                                  // rewritten by the serving process
+}
+
+// GraphQLConfig holds the GraphQL endpoint served at /api/{version}/graphql
+type GraphQLConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Playground serves the GraphiQL page at /api/{version}/graphql/playground without authentication
+	Playground    bool `mapstructure:"playground"`
+	Introspection bool `mapstructure:"introspection"`
+	// MaxComplexity bounds the complexity of a query, each field counting 1 and lists their page size; 0 is unlimited
+	MaxComplexity int `mapstructure:"max_complexity" validate:"min=0"`
+	// MaxDepth bounds the nesting of the selections of a query; 0 is unlimited
+	MaxDepth int `mapstructure:"max_depth" validate:"min=0"`
+}
+
+// GatewayConfig holds the REST transcoding of the proto-defined services,
+// served at /api/{version}{prefix} from the HTTP bindings of the proto files
+type GatewayConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Prefix  string `mapstructure:"prefix" validate:"required_if=Enabled true,omitempty,startswith=/"`
+}
+
+// BatchConfig holds the batch endpoint at /api/{version}/batch, which runs
+// several API requests of a client in one round trip with its credentials
+type BatchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxRequests bounds the sub-requests of a batch
+	MaxRequests int `mapstructure:"max_requests" validate:"min=1"`
+	// MaxConcurrency bounds the sub-requests of a batch executed at the same time
+	MaxConcurrency int `mapstructure:"max_concurrency" validate:"min=1"`
+}
+
+// BenchConfig holds the development-only benchmark endpoints served at
+// /api/{version}/_bench, usually enabled through SERVER_BENCH_ENABLED
+type BenchConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxConcurrency bounds the workers of a benchmark run
+	MaxConcurrency int `mapstructure:"max_concurrency" validate:"min=1"`
+	// MaxIterations bounds the operations of a benchmark run
+	MaxIterations int `mapstructure:"max_iterations" validate:"min=1"`
+	// MaxPayload bounds the size of the JSON benchmark document
+	MaxPayload ByteSize `mapstructure:"max_payload" validate:"min=1"`
+}
+
+// APIConfig holds the API versions served under /api/{version} and how
+// their responses identify entities
+type APIConfig struct {
+	// DefaultVersion serves /api paths without a version when the Accept header names none
+	DefaultVersion string             `mapstructure:"default_version" validate:"required"`
+	Versions       []APIVersionConfig `mapstructure:"versions" validate:"required,dive"`
+	// HideInternalIDs omits the database IDs of entities from responses, leaving their public IDs
+	HideInternalIDs bool `mapstructure:"hide_internal_ids"`
+}
+
+// APIVersionConfig holds the lifecycle of an API version. Dates use the
+// 2006-01-02 layout and are announced in the Deprecation and Sunset headers.
+type APIVersionConfig struct {
+	Name            string `mapstructure:"name" validate:"required,startswith=v"`
+	DeprecatedSince string `mapstructure:"deprecated_since" validate:"omitempty,datetime=2006-01-02"`
+	Sunset          string `mapstructure:"sunset" validate:"omitempty,datetime=2006-01-02"`
+	// Link documents the deprecation and the migration to a newer version
+	Link string `mapstructure:"link" validate:"omitempty,url"`
+}
+
+// LoadSheddingConfig holds the overload thresholds at which API requests are
+// rejected by route priority. Critical routes are never shed.
+type LoadSheddingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxInFlight is the number of concurrent API requests above which normal priority requests are shed
+	MaxInFlight int `mapstructure:"max_in_flight" validate:"required_if=Enabled true,min=0"`
+	// LowPriorityRatio is the fraction of MaxInFlight above which low priority requests are shed
+	LowPriorityRatio float64 `mapstructure:"low_priority_ratio" validate:"min=0,max=1"`
+	// MaxLatency is the average API latency above which low priority requests are shed; zero disables it
+	MaxLatency time.Duration `mapstructure:"max_latency" validate:"min=0"`
+	// RetryAfter is announced to shed clients in the Retry-After header
+	RetryAfter time.Duration `mapstructure:"retry_after" validate:"min=0"`
+}
+
+// CoalescingConfig holds the coalescing of identical concurrent GET requests
+// on the routes whose policy enables it: one request runs the handler and the
+// others receive a copy of its response
+type CoalescingConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxBodySize bounds the shared response; waiting requests run the handler themselves above it
+	MaxBodySize ByteSize `mapstructure:"max_body_size" validate:"min=0"`
+}
+
+// HTTPCacheConfig holds the caching of GET responses on the routes whose
+// policy sets a cache TTL
+type HTTPCacheConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Store is memory (per process) or redis (shared by all instances)
+	Store string `mapstructure:"store" validate:"required_if=Enabled true,omitempty,oneof=memory redis"`
+	// MaxEntries bounds the responses kept by the memory store; 0 is unlimited
+	MaxEntries int `mapstructure:"max_entries" validate:"min=0"`
+	// MaxBodySize bounds the cached responses; larger responses are not cached
+	MaxBodySize ByteSize `mapstructure:"max_body_size" validate:"min=0"`
+}
+
+// RequestIDConfig holds request ID propagation configuration
+type RequestIDConfig struct {
+	Header         string   `mapstructure:"header" validate:"required"`
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 // CORSConfig holds CORS configuration
@@ -98,27 +354,696 @@ type CORSConfig struct {
 	AllowedMethods   []string `mapstructure:"allowed_methods"`
 	AllowedHeaders   []string `mapstructure:"allowed_headers"`
 	AllowCredentials bool     `mapstructure:"allow_credentials"`
-	MaxAge           int      `mapstructure:"max_age"`
+	MaxAge           int      `mapstructure:"max_age" validate:"min=0"`
 }
 
 // MonitorConfig holds monitoring configuration
 type MonitorConfig struct {
-	Prometheus PrometheusConfig `mapstructure:"prometheus"`
-	PProf      PProfConfig      `mapstructure:"pprof"`
+	Prometheus     PrometheusConfig     `mapstructure:"prometheus"`
+	PProf          PProfConfig          `mapstructure:"pprof"`
+	ErrorReporting ErrorReportingConfig `mapstructure:"error_reporting"`
+	Admin          AdminConfig          `mapstructure:"admin"`
+	Watchdog       WatchdogConfig       `mapstructure:"watchdog"`
+	Analytics      AnalyticsConfig      `mapstructure:"analytics"`
+	History        HistoryConfig        `mapstructure:"history"`
 }
 
-// PrometheusConfig holds Prometheus configuration
+// AdminConfig holds the admin dashboard API configuration
+type AdminConfig struct {
+	Enabled            bool          `mapstructure:"enabled"`
+	RecentErrors       int           `mapstructure:"recent_errors" validate:"required_if=Enabled true,omitempty,min=1"` // error events kept in memory
+	MaxProfileDuration time.Duration `mapstructure:"max_profile_duration" validate:"min=0"`                             // upper bound of on-demand CPU profiles and traces
+}
+
+// WatchdogConfig holds the goroutine leak and resource watchdog configuration.
+// A resource is reported when Samples consecutive samples stay above its
+// baseline and keep growing; a zero baseline disables the check.
+type WatchdogConfig struct {
+	Enabled    bool           `mapstructure:"enabled"`
+	Interval   time.Duration  `mapstructure:"interval" validate:"required_if=Enabled true,min=0"`
+	Samples    int            `mapstructure:"samples" validate:"required_if=Enabled true,omitempty,min=2"`
+	Goroutines int            `mapstructure:"goroutines" validate:"min=0"`
+	Heap       ByteSize       `mapstructure:"heap" validate:"min=0"` // e.g. "1GB"
+	OpenFiles  int            `mapstructure:"open_files" validate:"min=0"`
+	HeapDump   HeapDumpConfig `mapstructure:"heap_dump"`
+}
+
+// HeapDumpConfig holds the heap dump written when the heap approaches the memory limit
+type HeapDumpConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Threshold is the heap size that triggers a dump; 0 uses 90% of the GOMEMLIMIT memory limit
+	Threshold   ByteSize      `mapstructure:"threshold" validate:"min=0"`
+	Dir         string        `mapstructure:"dir" validate:"required_if=Enabled true"`
+	MinInterval time.Duration `mapstructure:"min_interval" validate:"min=0"` // between two dumps
+}
+
+// PrometheusConfig holds Prometheus configuration. Mode selects how metrics
+// leave the process: pull serves them on Path for scraping, push sends them to
+// a Pushgateway and otlp exports them to an OTLP/HTTP collector.
 type PrometheusConfig struct {
+	Enabled bool              `mapstructure:"enabled"`
+	Mode    string            `mapstructure:"mode" validate:"required_if=Enabled true,omitempty,oneof=pull push otlp"`
+	Path    string            `mapstructure:"path" validate:"required_if=Enabled true,omitempty,startswith=/"`
+	Port    int               `mapstructure:"port" validate:"min=0,max=65535"`
+	Push    PushGatewayConfig `mapstructure:"push"`
+	OTLP    OTLPMetricsConfig `mapstructure:"otlp"`
+}
+
+// PushGatewayConfig holds the Pushgateway metrics are pushed to in push mode.
+// The grouping labels identify the pushing instance, "instance" defaults to
+// the hostname.
+type PushGatewayConfig struct {
+	URL              string            `mapstructure:"url" validate:"omitempty,url"`
+	Job              string            `mapstructure:"job"` // defaults to app.name
+	Grouping         map[string]string `mapstructure:"grouping"`
+	Username         string            `mapstructure:"username"`
+	Password         string            `mapstructure:"password"`
+	Interval         time.Duration     `mapstructure:"interval" validate:"min=0"`
+	Timeout          time.Duration     `mapstructure:"timeout" validate:"min=0"`
+	DeleteOnShutdown bool              `mapstructure:"delete_on_shutdown"` // delete the group instead of pushing a last time
+}
+
+// OTLPMetricsConfig holds the collector metrics are exported to in otlp mode
+type OTLPMetricsConfig struct {
+	Endpoint string            `mapstructure:"endpoint" validate:"omitempty,url"` // e.g. http://otel-collector:4318/v1/metrics
+	Headers  map[string]string `mapstructure:"headers"`
+	Interval time.Duration     `mapstructure:"interval" validate:"min=0"`
+	Timeout  time.Duration     `mapstructure:"timeout" validate:"min=0"`
+}
+
+// SecurityConfig holds API security configuration
+type SecurityConfig struct {
+	JWTSecret        string   `mapstructure:"jwt_secret" validate:"required"`
+	RateLimitRPS     int      `mapstructure:"rate_limit_rps" validate:"min=1"`
+	RateLimitBurst   int      `mapstructure:"rate_limit_burst" validate:"min=1"`
+	MaxFileSize      ByteSize `mapstructure:"max_file_size" validate:"min=1"` // e.g. "10MB"
+	AllowedFileTypes []string `mapstructure:"allowed_file_types"`
+	CSRFEnabled      bool     `mapstructure:"csrf_enabled"`
+	EncryptionKey    string   `mapstructure:"encryption_key" validate:"min=32"` // AES-256 key, first 32 bytes are used
+
+	// RateLimitClasses are named limits that routes opt into instead of the default one
+	RateLimitClasses map[string]RateLimitClass `mapstructure:"rate_limit_classes" validate:"dive"`
+
+	// Impersonation lets support staff act as another user with a short-lived token
+	Impersonation ImpersonationConfig `mapstructure:"impersonation"`
+	// Signing verifies the HMAC signatures of requests to the partner routes
+	Signing RequestSigningConfig `mapstructure:"signing"`
+	// NetworkACL blocks requests by client IP and country
+	NetworkACL NetworkACLConfig `mapstructure:"network_acl"`
+	// BotDetection scores requests for automation and challenges or blocks the risky ones
+	BotDetection BotDetectionConfig `mapstructure:"bot_detection"`
+	// LoginThrottle protects the login routes against brute-force attacks
+	LoginThrottle LoginThrottleConfig `mapstructure:"login_throttle"`
+	// Sessions tracks the refresh tokens of users and revokes their access tokens
+	Sessions SessionsConfig `mapstructure:"sessions"`
+}
+
+// SessionsConfig holds the session settings. A session is created at login
+// with a refresh token, which is rotated at each refresh; the access tokens
+// it issues carry its ID. Terminating a session deletes its refresh token and
+// denylists its ID until its last access token expires.
+type SessionsConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl" validate:"gt=0"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl" validate:"gtfield=AccessTokenTTL"`
+	// DenylistStore keeps the IDs of terminated sessions; redis shares them between instances
+	DenylistStore string `mapstructure:"denylist_store" validate:"omitempty,oneof=memory redis"`
+	// TouchInterval bounds how often the last seen time of a session is written
+	TouchInterval time.Duration `mapstructure:"touch_interval" validate:"min=0"`
+}
+
+// LoginThrottleConfig holds the brute-force protection of the login routes,
+// separate from the generic rate limits. Failed logins are counted per
+// username and per client IP in sliding windows. Repeated failures delay the
+// next attempt progressively, then require a challenge, and the username or
+// IP is locked out once it reaches its limit.
+type LoginThrottleConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Store keeps the failure counters; redis shares them between instances
+	Store  string        `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	Window time.Duration `mapstructure:"window" validate:"gt=0"`
+	// UsernameFields are the JSON body fields holding the username, the first one set is used
+	UsernameFields      []string      `mapstructure:"username_fields" validate:"min=1"`
+	MaxUsernameFailures int           `mapstructure:"max_username_failures" validate:"min=1"`
+	MaxIPFailures       int           `mapstructure:"max_ip_failures" validate:"min=1"`
+	LockoutDuration     time.Duration `mapstructure:"lockout_duration" validate:"gt=0"`
+	// Failures of a username beyond DelayAfter make the next attempt wait BaseDelay,
+	// doubling with each failure up to MaxDelay
+	DelayAfter int           `mapstructure:"delay_after" validate:"min=0"`
+	BaseDelay  time.Duration `mapstructure:"base_delay" validate:"min=0"`
+	MaxDelay   time.Duration `mapstructure:"max_delay" validate:"gtefield=BaseDelay"`
+	// ChallengeAfter failures of a username require the bot detection challenge, 0 never requires it
+	ChallengeAfter int `mapstructure:"challenge_after" validate:"min=0"`
+	// WebhookURL receives the lockouts through the webhook notification channel
+	WebhookURL string `mapstructure:"webhook_url" validate:"omitempty,url"`
+}
+
+// BotDetectionConfig holds the bot mitigation settings. Each request is scored
+// from its headers and the request rate of its client IP. Requests scoring
+// BlockScore are rejected; requests to challenge routes such as login and
+// signup scoring ChallengeScore must pass the challenge of the provider.
+type BotDetectionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Monitor only logs and counts the requests that would be challenged or blocked
+	Monitor bool `mapstructure:"monitor"`
+	// Store keeps the request counters and passed challenges of client IPs
+	Store          string `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	ChallengeScore int    `mapstructure:"challenge_score" validate:"min=1"`
+	BlockScore     int    `mapstructure:"block_score" validate:"gtefield=ChallengeScore"`
+	// UserAgents are case-insensitive substrings of the user agents of automated clients, e.g. curl
+	UserAgents []string `mapstructure:"user_agents"`
+	// Clients making more than RateLimit requests within RateWindow score as automated
+	RateWindow time.Duration      `mapstructure:"rate_window" validate:"gt=0"`
+	RateLimit  int                `mapstructure:"rate_limit" validate:"min=1"`
+	Challenge  BotChallengeConfig `mapstructure:"challenge"`
+}
+
+// BotChallengeConfig holds the challenge provider verifying the tokens solved by
+// clients. Without a provider, requests to challenge routes scoring
+// ChallengeScore are blocked instead.
+type BotChallengeConfig struct {
+	Provider  string `mapstructure:"provider"` // turnstile, recaptcha or a registered provider
+	SecretKey string `mapstructure:"secret_key" validate:"required_with=Provider"`
+	// VerifyURL overrides the verification endpoint of the provider
+	VerifyURL string `mapstructure:"verify_url" validate:"omitempty,url"`
+	// MinScore is the lowest reCAPTCHA v3 score accepted, 0 accepts any score
+	MinScore float64 `mapstructure:"min_score" validate:"min=0,max=1"`
+	// PassTTL is how long a client IP that passed a challenge is not challenged again
+	PassTTL time.Duration `mapstructure:"pass_ttl" validate:"min=0"`
+}
+
+// NetworkACLConfig holds the network access control settings. The global rule
+// applies to every request and the rule of each group to the requests under
+// its paths; a request must pass all the rules that apply to it. The admin API
+// replaces the rules at runtime until the next restart.
+type NetworkACLConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// GeoIPDatabase is the path of a MaxMind country or city database (.mmdb),
+	// required by country rules
+	GeoIPDatabase string                    `mapstructure:"geoip_database"`
+	Global        NetworkACLRule            `mapstructure:"global"`
+	Groups        map[string]NetworkACLRule `mapstructure:"groups" validate:"dive"`
+}
+
+// NetworkACLRule allows or denies client IPs and countries. Denied entries win
+// over allowed ones; when any allow entry is set, other clients are denied.
+type NetworkACLRule struct {
+	// Paths are the path prefixes of a group, e.g. /api/v1/admin; unused by the global rule
+	Paths []string `mapstructure:"paths" validate:"dive,startswith=/"`
+	// Allow and Deny list IPs or CIDRs, e.g. 10.0.0.0/8
+	Allow []string `mapstructure:"allow" validate:"dive,ip|cidr"`
+	Deny  []string `mapstructure:"deny" validate:"dive,ip|cidr"`
+	// AllowCountries and DenyCountries list ISO 3166-1 alpha-2 codes, e.g. DE
+	AllowCountries []string `mapstructure:"allow_countries" validate:"dive,iso3166_1_alpha2"`
+	DenyCountries  []string `mapstructure:"deny_countries" validate:"dive,iso3166_1_alpha2"`
+}
+
+// ImpersonationConfig holds the impersonation settings. Every request made with
+// an impersonation token is recorded in the audit log, which must be enabled.
+type ImpersonationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Roles may impersonate other users
+	Roles    []string      `mapstructure:"roles" validate:"required_if=Enabled true"`
+	TokenTTL time.Duration `mapstructure:"token_ttl" validate:"required_if=Enabled true,min=0"`
+	// ProtectedRoles cannot be impersonated
+	ProtectedRoles []string `mapstructure:"protected_roles"`
+}
+
+// RequestSigningConfig holds the request signing settings of the partner routes.
+// Nonces are remembered for the replay window, MaxSkew on either side of their timestamp.
+type RequestSigningConfig struct {
+	MaxSkew    time.Duration `mapstructure:"max_skew" validate:"gt=0"`
+	NonceStore string        `mapstructure:"nonce_store" validate:"omitempty,oneof=memory redis"`
+	// MaxBodySize bounds the bodies read to verify a signature
+	MaxBodySize ByteSize `mapstructure:"max_body_size" validate:"min=1"`
+	// RotationGrace keeps the previous secret of a partner valid after a rotation
+	RotationGrace time.Duration `mapstructure:"rotation_grace" validate:"min=0"`
+}
+
+// RateLimitClass holds the rate and burst of a named rate limit
+type RateLimitClass struct {
+	RPS   int `mapstructure:"rps" validate:"min=1"`
+	Burst int `mapstructure:"burst" validate:"min=1"`
+}
+
+// Built-in security defaults. They are only suitable for development; the
+// server refuses to start in production while they are in use.
+const (
+	DefaultJWTSecret     = "your-secret-key"
+	DefaultEncryptionKey = "your-encryption-key-32-characters"
+)
+
+// I18nConfig holds internationalization configuration
+type I18nConfig struct {
+	LocalesPath string `mapstructure:"locales_path" validate:"required"`
+}
+
+// PreferencesConfig holds the defaults of the user preferences, applied to
+// users who did not choose a setting
+type PreferencesConfig struct {
+	// Language of users without one, empty to detect it from each request
+	Language string `mapstructure:"language" validate:"omitempty,oneof=zh-CN zh-TW en-US en-GB ja-JP ko-KR fr-FR de-DE es-ES pt-BR"`
+	// TimeZone of users without one, an IANA time zone name
+	TimeZone string `mapstructure:"time_zone" validate:"required,timezone"`
+	// CacheTTL bounds how long an instance keeps the preferences of a user,
+	// and so serves preferences changed on another instance
+	CacheTTL time.Duration `mapstructure:"cache_ttl" validate:"min=0"`
+}
+
+// OrganizationsConfig holds the settings of organizations. When enabled,
+// applications belong to the active organization of each request and only its
+// members may use them.
+type OrganizationsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Header carries the ID of the active organization on routes without an org_id path parameter
+	Header string `mapstructure:"header" validate:"required"`
+}
+
+// AuthorizationConfig holds the access policies services consult before acting
+// on a resource. An action on a resource type with no policy is allowed; with
+// policies it is allowed when any of them grants it.
+type AuthorizationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Audit selects the decisions recorded in the audit log: none, denied or all
+	Audit    string                `mapstructure:"audit" validate:"oneof=none denied all"`
+	Policies []AuthorizationPolicy `mapstructure:"policies" validate:"dive"`
+}
+
+// AuthorizationPolicy grants an action on a resource type to the users with one
+// of Roles, to the owner of the resource when Owner is set, and to the members
+// of the organization of the resource with at least OrgRole when it is set
+type AuthorizationPolicy struct {
+	Resource string   `mapstructure:"resource" validate:"required"`
+	Action   string   `mapstructure:"action" validate:"required,oneof=create read update delete"`
+	Roles    []string `mapstructure:"roles"`
+	Owner    bool     `mapstructure:"owner"`
+	OrgRole  string   `mapstructure:"org_role" validate:"omitempty,oneof=owner admin member"`
+}
+
+// InvitationsConfig holds the settings of invitations. An invitation is mailed
+// as a signed token that expires after TTL; accepting it provisions the invited
+// user with the role and organization membership set by the inviter.
+type InvitationsConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	TTL     time.Duration `mapstructure:"ttl" validate:"gt=0"`
+	// Secret signs the invitation tokens, defaults to security.jwt_secret
+	Secret string `mapstructure:"secret"`
+	// AcceptURL is the page accepting invitations, linked in the invitation
+	// email with the token in its token query parameter
+	AcceptURL string `mapstructure:"accept_url" validate:"omitempty,url"`
+	// DefaultRole is the role of invited users; only admins may invite with another role
+	DefaultRole string `mapstructure:"default_role" validate:"required"`
+}
+
+// RegistrationConfig holds the self-service signup policy. Open signup accepts
+// any email address in AllowedDomains once the user verifies it; invite-only
+// signup requires an invitation, which also sets the role of the user.
+type RegistrationConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Mode is open or invite_only
+	Mode string `mapstructure:"mode" validate:"oneof=open invite_only"`
+	// AllowedDomains restricts open signup to these email domains, empty allows any
+	AllowedDomains []string `mapstructure:"allowed_domains" validate:"dive,hostname"`
+	// DefaultRole and DefaultPermissions are granted to users signing up without an invitation
+	DefaultRole        string   `mapstructure:"default_role" validate:"required"`
+	DefaultPermissions []string `mapstructure:"default_permissions"`
+	MinPasswordLength  int      `mapstructure:"min_password_length" validate:"min=8,max=72"`
+	// VerificationTTL is how long the email verification token mailed on open
+	// signup is valid; tokens are signed with security.jwt_secret
+	VerificationTTL time.Duration `mapstructure:"verification_ttl" validate:"gt=0"`
+	// VerifyURL is the page verifying email addresses, linked in the verification
+	// email with the token in its token query parameter
+	VerifyURL string `mapstructure:"verify_url" validate:"omitempty,url"`
+	// Tenants adds new users to organizations by the domain of their email address
+	Tenants []RegistrationTenant `mapstructure:"tenants" validate:"dive"`
+}
+
+// RegistrationTenant adds the users signing up with an email address in Domain
+// to the organization OrgID with OrgRole
+type RegistrationTenant struct {
+	Domain  string `mapstructure:"domain" validate:"required,hostname"`
+	OrgID   uint   `mapstructure:"org_id" validate:"required"`
+	OrgRole string `mapstructure:"org_role" validate:"oneof=owner admin member"`
+}
+
+// RevisionsConfig holds the retention of application revisions. A zero value disables the limit;
+// the latest revision of an application is always kept.
+type RevisionsConfig struct {
+	MaxPerApplication int           `mapstructure:"max_per_application" validate:"min=0"`
+	MaxAge            time.Duration `mapstructure:"max_age" validate:"min=0"`
+}
+
+// StorageConfig holds the object storage used for files such as backup archives
+type StorageConfig struct {
+	Type string          `mapstructure:"type" validate:"omitempty,oneof=local memory s3"`
+	Path string          `mapstructure:"path"` // root directory of the local storage
+	S3   StorageS3Config `mapstructure:"s3"`
+}
+
+// StorageS3Config holds the bucket of the s3 storage, on AWS S3 or an
+// S3-compatible service such as MinIO. Empty credentials are read from the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN variables.
+type StorageS3Config struct {
+	// Endpoint is the service URL, e.g. http://localhost:9000 for MinIO; empty uses AWS S3 in Region
+	Endpoint        string `mapstructure:"endpoint" validate:"omitempty,url"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	SessionToken    string `mapstructure:"session_token"`
+	// PathStyle addresses the bucket in the path, endpoint/bucket/key, as MinIO
+	// requires, instead of the host name, bucket.endpoint/key
+	PathStyle bool `mapstructure:"path_style"`
+}
+
+// FilesConfig holds the files clients upload to and download from the s3
+// storage through presigned URLs
+type FilesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxSize is the largest file in bytes, 0 for no limit
+	MaxSize int64 `mapstructure:"max_size" validate:"min=0"`
+	// ContentTypes are the accepted media types, type/* accepting a whole type; empty accepts any
+	ContentTypes []string `mapstructure:"content_types"`
+	// UploadURLTTL and DownloadURLTTL are how long presigned URLs are valid, at most 7 days
+	UploadURLTTL   time.Duration `mapstructure:"upload_url_ttl" validate:"required_if=Enabled true,min=0,max=168h"`
+	DownloadURLTTL time.Duration `mapstructure:"download_url_ttl" validate:"required_if=Enabled true,min=0,max=168h"`
+	// KeyPrefix is prepended to the storage keys of files
+	KeyPrefix string             `mapstructure:"key_prefix"`
+	Images    FilesImagesConfig  `mapstructure:"images"`
+	Scan      FilesScanConfig    `mapstructure:"scan"`
+	Uploads   FilesUploadsConfig `mapstructure:"uploads"`
+}
+
+// FilesUploadsConfig holds the resumable uploads sent through the API in
+// chunks, for clients on networks too unreliable for a single presigned upload
+type FilesUploadsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MaxChunkSize is the largest chunk in bytes, buffered in memory before it is stored
+	MaxChunkSize int64 `mapstructure:"max_chunk_size" validate:"required_if=Enabled true,min=0"`
+	// SessionTTL is how long a session accepts chunks and finalization after its creation
+	SessionTTL time.Duration `mapstructure:"session_ttl" validate:"required_if=Enabled true,min=0"`
+	// CleanupInterval is how often expired sessions are deleted with their file and parts
+	CleanupInterval time.Duration `mapstructure:"cleanup_interval" validate:"required_if=Enabled true,min=0"`
+}
+
+// FilesScanConfig holds the malware scanning of uploaded files by a ClamAV
+// daemon. Files cannot be downloaded until they are scanned clean.
+type FilesScanConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Address is the TCP address of clamd, host:port
+	Address string `mapstructure:"address" validate:"required_if=Enabled true,omitempty,hostname_port"`
+	// Timeout bounds a scan, including the transfer of the file to clamd
+	Timeout time.Duration `mapstructure:"timeout" validate:"min=0"`
+}
+
+// FilesImagesConfig holds the processing of uploaded JPEG, PNG and GIF
+// images, run as an operation once their upload is completed
+type FilesImagesConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ThumbnailSizes are the thumbnails generated per image, each fitting in a square of that many pixels
+	ThumbnailSizes []int `mapstructure:"thumbnail_sizes" validate:"dive,min=1,max=4096"`
+	// StripMetadata removes EXIF, XMP, IPTC and text metadata from the uploaded images
+	StripMetadata bool `mapstructure:"strip_metadata"`
+	// Quality is the JPEG quality of the thumbnails of JPEG images
+	Quality int `mapstructure:"quality" validate:"required_if=Enabled true,min=0,max=100"`
+	// MaxPixels bounds the width times height of processed images, 0 for no limit
+	MaxPixels int64 `mapstructure:"max_pixels" validate:"min=0"`
+}
+
+// QuotaConfig holds the daily and monthly request quotas counted per principal.
+// Periods are calendar days and months in UTC.
+type QuotaConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Store   string `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	// Classes are the limits of route quota classes; routes without a class use "default"
+	Classes map[string]QuotaLimit `mapstructure:"classes" validate:"dive"`
+	// Principals override class limits for individual principals, keyed by principal then class
+	Principals map[string]map[string]QuotaLimit `mapstructure:"principals" validate:"dive,dive"`
+}
+
+// QuotaLimit holds the requests allowed per day and per month; zero means unlimited
+type QuotaLimit struct {
+	Daily   int64 `mapstructure:"daily" validate:"min=0"`
+	Monthly int64 `mapstructure:"monthly" validate:"min=0"`
+}
+
+// HTTPClientConfig holds the timeouts, retries and circuit breaking of outbound HTTP clients
+type HTTPClientConfig struct {
+	Timeout               time.Duration `mapstructure:"timeout" validate:"min=0"` // whole request including retries
+	DialTimeout           time.Duration `mapstructure:"dial_timeout" validate:"min=0"`
+	TLSHandshakeTimeout   time.Duration `mapstructure:"tls_handshake_timeout" validate:"min=0"`
+	ResponseHeaderTimeout time.Duration `mapstructure:"response_header_timeout" validate:"min=0"`
+	IdleConnTimeout       time.Duration `mapstructure:"idle_conn_timeout" validate:"min=0"`
+	MaxIdleConnsPerHost   int           `mapstructure:"max_idle_conns_per_host" validate:"min=0"`
+
+	Retry          HTTPClientRetryConfig   `mapstructure:"retry"`
+	CircuitBreaker HTTPClientBreakerConfig `mapstructure:"circuit_breaker"`
+}
+
+// HTTPClientRetryConfig holds the retries of idempotent outbound requests with exponential backoff
+type HTTPClientRetryConfig struct {
+	MaxAttempts    int           `mapstructure:"max_attempts" validate:"min=1"` // 1 disables retries
+	InitialBackoff time.Duration `mapstructure:"initial_backoff" validate:"min=0"`
+	MaxBackoff     time.Duration `mapstructure:"max_backoff" validate:"min=0"`
+}
+
+// HTTPClientBreakerConfig holds the per-host circuit breaker of outbound HTTP clients
+type HTTPClientBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit; zero disables it
+	FailureThreshold int `mapstructure:"failure_threshold" validate:"min=0"`
+	// OpenTimeout is how long the circuit stays open before a probe request is let through
+	OpenTimeout time.Duration `mapstructure:"open_timeout" validate:"min=0"`
+}
+
+// MailConfig holds outgoing email configuration
+type MailConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Provider string `mapstructure:"provider" validate:"required_if=Enabled true"` // smtp, log or a registered provider such as ses
+	From     string `mapstructure:"from" validate:"required_if=Enabled true"`
+	Language string `mapstructure:"language"` // language of notifications, defaults to the i18n default
+	// TemplatesPath holds <name>.<subject|txt|html>.tmpl files, optionally under a
+	// <language>/ directory, that take precedence over the built-in templates
+	TemplatesPath string        `mapstructure:"templates_path"`
+	MaxAttempts   int           `mapstructure:"max_attempts" validate:"min=1"`
+	RetryBackoff  time.Duration `mapstructure:"retry_backoff" validate:"min=0"` // doubled after every failed attempt
+
+	SMTP          MailSMTPConfig          `mapstructure:"smtp"`
+	Notifications MailNotificationsConfig `mapstructure:"notifications"`
+}
+
+// MailSMTPConfig holds the SMTP server used by the smtp mail provider
+type MailSMTPConfig struct {
+	Host     string        `mapstructure:"host"`
+	Port     int           `mapstructure:"port" validate:"min=0,max=65535"`
+	Username string        `mapstructure:"username"`
+	Password string        `mapstructure:"password"`
+	TLS      string        `mapstructure:"tls" validate:"omitempty,oneof=starttls tls none"`
+	Timeout  time.Duration `mapstructure:"timeout" validate:"min=0"`
+}
+
+// MailNotificationsConfig holds the recipients of notifications sent on domain events
+type MailNotificationsConfig struct {
+	ApplicationDeleted []string `mapstructure:"application_deleted" validate:"dive,email"`
+	UserRegistered     []string `mapstructure:"user_registered" validate:"dive,email"`
+}
+
+// NotificationConfig holds SMS, push and webhook notification configuration
+type NotificationConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`
+	Language string `mapstructure:"language"` // language of users without one, defaults to the i18n default
+	// TemplatesPath holds <name>.<channel>.tmpl and <name>.title.tmpl files, optionally
+	// under a <language>/ directory, that take precedence over the built-in templates
+	TemplatesPath string `mapstructure:"templates_path"`
+	// Channels selects the provider of each channel (sms, push, webhook); channels
+	// without a provider are unavailable
+	Channels  map[string]NotificationChannelConfig `mapstructure:"channels" validate:"dive"`
+	RateLimit NotificationRateLimitConfig          `mapstructure:"rate_limit"`
+}
+
+// NotificationChannelConfig holds the provider of a notification channel
+type NotificationChannelConfig struct {
+	Provider string `mapstructure:"provider" validate:"required"` // log, webhook or a registered vendor provider
+	// Options are passed to the provider, e.g. vendor API keys
+	Options map[string]string `mapstructure:"options"`
+}
+
+// NotificationRateLimitConfig limits the notifications sent to one recipient of a channel
+type NotificationRateLimitConfig struct {
+	Store        string        `mapstructure:"store" validate:"omitempty,oneof=memory redis"`
+	PerRecipient int64         `mapstructure:"per_recipient" validate:"min=0"` // 0 is unlimited
+	Window       time.Duration `mapstructure:"window" validate:"required_with=PerRecipient,min=0"`
+}
+
+// BrokerConfig holds the message broker used to exchange events with other services
+type BrokerConfig struct {
 	Enabled bool   `mapstructure:"enabled"`
-	Path    string `mapstructure:"path"`
-	Port    int    `mapstructure:"port"`
+	Driver  string `mapstructure:"driver" validate:"required_if=Enabled true,omitempty,oneof=memory nats"`
+	Codec   string `mapstructure:"codec" validate:"omitempty,oneof=json protobuf"`
+	// MaxDeliver bounds the deliveries of a message whose handler keeps failing; 0 is unlimited
+	MaxDeliver   int           `mapstructure:"max_deliver" validate:"min=0"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff" validate:"min=0"` // delay before a failed message is redelivered
+	AckWait      time.Duration `mapstructure:"ack_wait" validate:"min=0"`      // redelivery delay of messages whose handler does not return
+
+	NATS   BrokerNATSConfig   `mapstructure:"nats"`
+	Bridge BrokerBridgeConfig `mapstructure:"bridge"`
+}
+
+// BrokerNATSConfig holds the NATS JetStream connection of the nats broker driver
+type BrokerNATSConfig struct {
+	URL      string `mapstructure:"url"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	Token    string `mapstructure:"token"`
+	// Stream is created when missing and captures every subject under <stream>.
+	Stream         string        `mapstructure:"stream"`
+	Replicas       int           `mapstructure:"replicas" validate:"min=0"`
+	MaxAge         time.Duration `mapstructure:"max_age" validate:"min=0"` // retention of messages, 0 keeps them until limits are reached
+	ConnectTimeout time.Duration `mapstructure:"connect_timeout" validate:"min=0"`
+}
+
+// BrokerBridgeConfig selects the domain events forwarded to the broker
+type BrokerBridgeConfig struct {
+	// Events are the forwarded domain event types, * forwards all of them
+	Events []string `mapstructure:"events"`
+	// TopicPrefix is prepended to the event type to form the topic, e.g. events.application.deleted
+	TopicPrefix string `mapstructure:"topic_prefix"`
+}
+
+// OutboxConfig holds the transactional outbox relaying domain events to the broker
+type OutboxConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Events are the recorded domain event types, * records all of them
+	Events      []string `mapstructure:"events"`
+	TopicPrefix string   `mapstructure:"topic_prefix"`
+	// PollInterval is the delay between relay runs when no message is pending
+	PollInterval time.Duration `mapstructure:"poll_interval" validate:"required_if=Enabled true,min=0"`
+	BatchSize    int           `mapstructure:"batch_size" validate:"required_if=Enabled true,min=0"`
+	// MaxAttempts bounds the publish attempts of a message before it is marked failed; 0 is unlimited
+	MaxAttempts  int           `mapstructure:"max_attempts" validate:"min=0"`
+	RetryBackoff time.Duration `mapstructure:"retry_backoff" validate:"min=0"` // doubled after each failed attempt
+	MaxBackoff   time.Duration `mapstructure:"max_backoff" validate:"min=0"`
+	// Retention is how long published messages and processed message records are kept; 0 keeps them
+	Retention time.Duration `mapstructure:"retention" validate:"min=0"`
+}
+
+// EventLogConfig holds the log of domain events that clients follow with GET /api/v1/events
+type EventLogConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Events are the logged domain event types, * logs all of them
+	Events []string `mapstructure:"events"`
+	// Exclude are event types not logged even when Events has *
+	Exclude []string `mapstructure:"exclude"`
+	// PollInterval is how often waiting requests read the log for the events of other instances
+	PollInterval time.Duration `mapstructure:"poll_interval" validate:"required_if=Enabled true,min=0"`
+	// MaxWait bounds how long a long polling request waits for events
+	MaxWait time.Duration `mapstructure:"max_wait" validate:"min=0"`
+	// PageSize bounds the events returned by one request
+	PageSize int `mapstructure:"page_size" validate:"required_if=Enabled true,min=0"`
+}
+
+// RetentionConfig holds the scheduled purge of expired records. Policies map
+// a kind of record to how long it is kept, 0 keeping it: audit_logs (audit
+// entries of the analytics sink), applications (soft-deleted applications and
+// their variables and revisions, GORM datastores only) and operations
+// (completed and failed operations).
+type RetentionConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is the delay between two purge runs
+	Interval time.Duration `mapstructure:"interval" validate:"required_if=Enabled true,min=0"`
+	// BatchSize bounds the records deleted by a single statement
+	BatchSize int                      `mapstructure:"batch_size" validate:"min=1"`
+	Policies  map[string]time.Duration `mapstructure:"policies" validate:"dive,min=0"`
 }
 
 // PProfConfig holds PProf configuration
 type PProfConfig struct {
-	Enabled    bool   `mapstructure:"enabled"`
-	PathPrefix string `mapstructure:"path_prefix"`
-	Port       int    `mapstructure:"port"`
+	Enabled    bool     `mapstructure:"enabled"`
+	PathPrefix string   `mapstructure:"path_prefix" validate:"required_if=Enabled true,omitempty,startswith=/"`
+	AllowedIPs []string `mapstructure:"allowed_ips" validate:"dive,ip|cidr"` // allowed without an admin token
+}
+
+// FeatureFlagsConfig holds feature flag configuration
+type FeatureFlagsConfig struct {
+	Flags []FeatureFlagConfig `mapstructure:"flags" validate:"dive"`
+}
+
+// FeatureFlagConfig holds a feature flag defined in configuration
+type FeatureFlagConfig struct {
+	Key               string   `mapstructure:"key" validate:"required"`
+	Description       string   `mapstructure:"description"`
+	Enabled           bool     `mapstructure:"enabled"`
+	RolloutPercentage int      `mapstructure:"rollout_percentage" validate:"min=0,max=100"`
+	TargetUsers       []string `mapstructure:"target_users"`
+	TargetTenants     []string `mapstructure:"target_tenants"`
+}
+
+// ExperimentConfig holds an A/B experiment definition
+type ExperimentConfig struct {
+	Key         string                    `mapstructure:"key" validate:"required"`
+	Description string                    `mapstructure:"description"`
+	Enabled     bool                      `mapstructure:"enabled"`
+	Flag        string                    `mapstructure:"flag"` // optional feature flag gating enrollment
+	Variants    []ExperimentVariantConfig `mapstructure:"variants" validate:"required_if=Enabled true,dive"`
+}
+
+// ExperimentVariantConfig holds a weighted experiment variant
+type ExperimentVariantConfig struct {
+	Name   string `mapstructure:"name" validate:"required"`
+	Weight int    `mapstructure:"weight" validate:"min=0"`
+}
+
+// ErrorReportingConfig holds error reporting configuration
+type ErrorReportingConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Provider     string        `mapstructure:"provider" validate:"required_if=Enabled true"` // log, sentry
+	DSN          string        `mapstructure:"dsn" validate:"required_if=Enabled true Provider sentry"`
+	Environment  string        `mapstructure:"environment"`
+	Release      string        `mapstructure:"release"`
+	SampleRate   float64       `mapstructure:"sample_rate" validate:"min=0,max=1"`
+	FlushTimeout time.Duration `mapstructure:"flush_timeout" validate:"min=0"`
+}
+
+// AnalyticsConfig holds the analytics sink fed with access logs and audit events.
+// Entries are queued and inserted in batches; when the queue is full they are
+// dropped or, with the block policy, the caller waits up to BlockTimeout.
+type AnalyticsConfig struct {
+	Enabled       bool                      `mapstructure:"enabled"`
+	Provider      string                    `mapstructure:"provider" validate:"required_if=Enabled true"` // memory, clickhouse
+	AccessLog     bool                      `mapstructure:"access_log"`
+	Audit         bool                      `mapstructure:"audit"`
+	BatchSize     int                       `mapstructure:"batch_size" validate:"required_if=Enabled true,min=0"`
+	FlushInterval time.Duration             `mapstructure:"flush_interval" validate:"required_if=Enabled true,min=0"`
+	QueueSize     int                       `mapstructure:"queue_size" validate:"required_if=Enabled true,min=0"`
+	Backpressure  string                    `mapstructure:"backpressure" validate:"omitempty,oneof=drop block"`
+	BlockTimeout  time.Duration             `mapstructure:"block_timeout" validate:"min=0"`
+	MemorySize    int                       `mapstructure:"memory_size" validate:"min=0"` // entries kept by the memory provider
+	ClickHouse    ClickHouseAnalyticsConfig `mapstructure:"clickhouse"`
+}
+
+// ClickHouseAnalyticsConfig holds the ClickHouse connection of the analytics sink
+type ClickHouseAnalyticsConfig struct {
+	Addr        []string      `mapstructure:"addr"`
+	Database    string        `mapstructure:"database"`
+	Username    string        `mapstructure:"username"`
+	Password    string        `mapstructure:"password"`
+	Table       string        `mapstructure:"table"`
+	TTL         time.Duration `mapstructure:"ttl" validate:"min=0"` // 0 keeps entries forever
+	DialTimeout time.Duration `mapstructure:"dial_timeout" validate:"min=0"`
+	AsyncInsert bool          `mapstructure:"async_insert"`
+}
+
+// HistoryConfig holds the persisted history of the datastore operation stats.
+// Samples are written every Interval, rolled up into hourly samples once older
+// than RawRetention and deleted once older than RollupRetention.
+type HistoryConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	Interval        time.Duration `mapstructure:"interval" validate:"required_if=Enabled true,min=0"`
+	RollupInterval  time.Duration `mapstructure:"rollup_interval" validate:"required_if=Enabled true,min=0"`
+	RawRetention    time.Duration `mapstructure:"raw_retention" validate:"required_if=Enabled true,min=0"`
+	RollupRetention time.Duration `mapstructure:"rollup_retention" validate:"min=0"` // 0 keeps hourly samples
+	BatchSize       int           `mapstructure:"batch_size" validate:"required_if=Enabled true,min=0"`
+	// MaxPoints bounds the samples returned by the history endpoint
+	MaxPoints int `mapstructure:"max_points" validate:"min=0"`
 }
 
 // NewManager creates a new configuration manager
@@ -131,8 +1056,9 @@ func NewManager() Manager {
 	// Set configuration file settings
 	v.SetConfigName("app")
 	v.SetConfigType("yaml")
-	v.AddConfigPath("./configs")
-	v.AddConfigPath("./")
+	for _, path := range configPaths {
+		v.AddConfigPath(path)
+	}
 
 	// Set environment variable settings
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
@@ -143,70 +1069,122 @@ func NewManager() Manager {
 	}
 }
 
-// Load loads configuration from file and environment variables
+// Load loads configuration in increasing order of precedence: defaults, the base
+// file (configs/app.yml), the environment overlay (configs/app.{env}.yml, where
+// env is APP_ENV or app.env of the base file), the remote backend configured
+// under remote.* and environment variables.
 func (m *ConfigManager) Load(configPath string) error {
 	if configPath != "" {
 		m.viper.SetConfigFile(configPath)
 	}
 
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.readLocal(); err != nil {
+		return err
+	}
+
+	// Merge remote configuration
+	if err := m.loadRemote(); err != nil {
+		return err
+	}
+
+	// Unmarshal configuration
+	config, err := m.unmarshal()
+	if err != nil {
+		return err
+	}
+	m.config = config
+
+	return nil
+}
+
+// readLocal reads the base file and merges the environment overlay, replacing
+// any previously read file and remote values. Caller must hold the lock.
+func (m *ConfigManager) readLocal() error {
+	m.sources = []string{SourceDefaults}
+
 	// Read configuration file
 	if err := m.viper.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			return fmt.Errorf("failed to read config file: %w", err)
 		}
+	} else {
+		m.sources = append(m.sources, m.viper.ConfigFileUsed())
 	}
 
-	// Unmarshal configuration
-	m.config = &Config{}
-	if err := m.viper.Unmarshal(m.config); err != nil {
-		return fmt.Errorf("failed to unmarshal config: %w", err)
-	}
+	// Merge environment specific overlay
+	return m.mergeOverlay()
+}
 
-	return nil
+// unmarshal decodes the effective settings into a new Config
+func (m *ConfigManager) unmarshal() (*Config, error) {
+	config := &Config{}
+	if err := m.viper.Unmarshal(config, decodeHook()); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	if err := resolveDatastores(&config.Database, m.viper.Get("database.datastores")); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	return config, nil
 }
 
 // GetConfig returns the current configuration
 func (m *ConfigManager) GetConfig() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	return m.config
 }
 
-// WatchConfig watches for configuration changes
+// WatchConfig watches the configuration file and, when a remote backend with a
+// refresh interval is configured, the remote document. The callback receives
+// the rebuilt configuration after every successful change; invalid changes are
+// ignored and the last good configuration is kept.
 func (m *ConfigManager) WatchConfig(callback func(*Config)) {
 	m.viper.WatchConfig()
 	m.viper.OnConfigChange(func(e fsnotify.Event) {
-		newConfig := &Config{}
-		if err := m.viper.Unmarshal(newConfig); err != nil {
-			return
+		m.mu.Lock()
+		newConfig, err := m.rebuild(m.remoteData, m.remoteSource)
+		if err == nil {
+			m.config = newConfig
 		}
-		m.config = newConfig
-		if callback != nil {
+		m.mu.Unlock()
+
+		if err == nil && callback != nil {
 			callback(newConfig)
 		}
 	})
-}
 
-// Validate validates the configuration
-func (m *ConfigManager) Validate() error {
-	if m.config == nil {
-		return fmt.Errorf("configuration not loaded")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.remote != nil && m.remoteConfig.RefreshInterval > 0 && m.stop == nil {
+		m.stop = make(chan struct{})
+		go m.watchRemote(m.stop, callback)
 	}
+}
 
-	// Validate app configuration
-	if m.config.App.Name == "" {
-		return fmt.Errorf("app name is required")
-	}
+// Close stops watching the remote configuration backend
+func (m *ConfigManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
-	// Validate database configuration
-	if m.config.Database.Type == "" {
-		return fmt.Errorf("database type is required")
+	if m.stop != nil {
+		close(m.stop)
+		m.stop = nil
 	}
+	return nil
+}
 
-	// Validate server configuration
-	if m.config.Server.Port <= 0 || m.config.Server.Port > 65535 {
-		return fmt.Errorf("invalid server port: %d", m.config.Server.Port)
+// Validate validates the whole configuration against the validate struct tags
+// and the cross-field rules, returning a *ValidationError listing every violation
+func (m *ConfigManager) Validate() error {
+	config := m.GetConfig()
+	if config == nil {
+		return fmt.Errorf("configuration not loaded")
 	}
-
-	return nil
+	return ValidateConfig(config)
 }
 
 // setDefaults sets default configuration values
@@ -219,6 +1197,7 @@ func setDefaults(v *viper.Viper) {
 
 	// Database defaults
 	v.SetDefault("database.type", "postgresql")
+	v.SetDefault("database.uri", "")
 	v.SetDefault("database.host", "localhost")
 	v.SetDefault("database.port", 5432)
 	v.SetDefault("database.user", "postgres")
@@ -228,6 +1207,27 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("database.max_open_conns", 100)
 	v.SetDefault("database.max_idle_conns", 10)
 	v.SetDefault("database.conn_max_lifetime", "1h")
+	v.SetDefault("database.auto_migrate", true)
+	v.SetDefault("database.id_strategy", "auto_increment")
+	v.SetDefault("database.node_id", 0)
+	v.SetDefault("database.transaction_pooling", false)
+	v.SetDefault("database.tx_retry.max_attempts", 3)
+	v.SetDefault("database.tx_retry.initial_backoff", "20ms")
+	v.SetDefault("database.tx_retry.max_backoff", "500ms")
+	v.SetDefault("database.etcd.endpoints", []string{"localhost:2379"})
+	v.SetDefault("database.etcd.prefix", "/server-tpl")
+	v.SetDefault("database.etcd.username", "")
+	v.SetDefault("database.etcd.password", "")
+	v.SetDefault("database.etcd.dial_timeout", "5s")
+	v.SetDefault("database.etcd.cache_size", 10000)
+	v.SetDefault("database.health_interval", "15s")
+	v.SetDefault("database.tenancy.mode", "row")
+	v.SetDefault("database.tenancy.schema_prefix", "tenant_")
+	v.SetDefault("database.tenancy.tables", []string{"applications", "application_variables", "application_revisions", "application_backups"})
+	v.SetDefault("database.rls.enabled", false)
+	v.SetDefault("database.rls.tables", []string{"applications", "application_backups", "files"})
+	v.SetDefault("database.rls.allow_unscoped", true)
+	v.SetDefault("database.tags.storage", "column")
 
 	// Redis defaults
 	v.SetDefault("redis.host", "localhost")
@@ -257,19 +1257,382 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("server.read_timeout", "30s")
 	v.SetDefault("server.write_timeout", "30s")
 	v.SetDefault("server.idle_timeout", "60s")
+	v.SetDefault("server.strict_json", false)
 	v.SetDefault("server.cors.allowed_origins", []string{"http://localhost:3000"})
-	v.SetDefault("server.cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"})
+	v.SetDefault("server.cors.allowed_methods", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
 	v.SetDefault("server.cors.allowed_headers", []string{"Content-Type", "Authorization"})
 	v.SetDefault("server.cors.allow_credentials", true)
 	v.SetDefault("server.cors.max_age", 86400)
+	v.SetDefault("server.request_id.header", "X-Request-ID")
+	v.SetDefault("server.request_id.trusted_proxies", []string{})
+	v.SetDefault("server.api.default_version", "v1")
+	v.SetDefault("server.api.versions", []map[string]interface{}{{"name": "v1"}, {"name": "v2"}})
+	v.SetDefault("server.api.hide_internal_ids", false)
+	v.SetDefault("server.load_shedding.enabled", false)
+	v.SetDefault("server.load_shedding.max_in_flight", 200)
+	v.SetDefault("server.load_shedding.low_priority_ratio", 0.8)
+	v.SetDefault("server.load_shedding.max_latency", "1s")
+	v.SetDefault("server.load_shedding.retry_after", "5s")
+	v.SetDefault("server.coalescing.enabled", true)
+	v.SetDefault("server.coalescing.max_body_size", "1MB")
+	v.SetDefault("server.response_cache.enabled", false)
+	v.SetDefault("server.response_cache.store", "memory")
+	v.SetDefault("server.response_cache.max_entries", 10000)
+	v.SetDefault("server.response_cache.max_body_size", "1MB")
 
 	// Monitor defaults
 	v.SetDefault("monitor.prometheus.enabled", true)
 	v.SetDefault("monitor.prometheus.path", "/metrics")
 	v.SetDefault("monitor.prometheus.port", 9090)
+	v.SetDefault("monitor.prometheus.mode", "pull")
+	v.SetDefault("monitor.prometheus.push.url", "")
+	v.SetDefault("monitor.prometheus.push.job", "")
+	v.SetDefault("monitor.prometheus.push.username", "")
+	v.SetDefault("monitor.prometheus.push.password", "")
+	v.SetDefault("monitor.prometheus.push.interval", "15s")
+	v.SetDefault("monitor.prometheus.push.timeout", "10s")
+	v.SetDefault("monitor.prometheus.push.delete_on_shutdown", false)
+	v.SetDefault("monitor.prometheus.otlp.endpoint", "")
+	v.SetDefault("monitor.prometheus.otlp.interval", "15s")
+	v.SetDefault("monitor.prometheus.otlp.timeout", "10s")
 	v.SetDefault("monitor.pprof.enabled", false)
 	v.SetDefault("monitor.pprof.path_prefix", "/debug/pprof")
-	v.SetDefault("monitor.pprof.port", 6060)
+	v.SetDefault("monitor.pprof.allowed_ips", []string{})
+	v.SetDefault("monitor.error_reporting.enabled", false)
+	v.SetDefault("monitor.error_reporting.provider", "log")
+	v.SetDefault("monitor.error_reporting.dsn", "")
+	v.SetDefault("monitor.error_reporting.environment", "")
+	v.SetDefault("monitor.error_reporting.release", "")
+	v.SetDefault("monitor.error_reporting.sample_rate", 1.0)
+	v.SetDefault("monitor.error_reporting.flush_timeout", "2s")
+	v.SetDefault("monitor.admin.enabled", true)
+	v.SetDefault("monitor.admin.recent_errors", 100)
+	v.SetDefault("monitor.admin.max_profile_duration", "60s")
+	v.SetDefault("monitor.watchdog.enabled", false)
+	v.SetDefault("monitor.watchdog.interval", "30s")
+	v.SetDefault("monitor.watchdog.samples", 10)
+	v.SetDefault("monitor.watchdog.goroutines", 10000)
+	v.SetDefault("monitor.watchdog.heap", "1GB")
+	v.SetDefault("monitor.watchdog.open_files", 4096)
+	v.SetDefault("monitor.watchdog.heap_dump.enabled", false)
+	v.SetDefault("monitor.watchdog.heap_dump.threshold", 0)
+	v.SetDefault("monitor.watchdog.heap_dump.dir", "logs/heapdumps")
+	v.SetDefault("monitor.watchdog.heap_dump.min_interval", "10m")
+	v.SetDefault("monitor.history.enabled", false)
+	v.SetDefault("monitor.history.interval", "1m")
+	v.SetDefault("monitor.history.rollup_interval", "10m")
+	v.SetDefault("monitor.history.raw_retention", "24h")
+	v.SetDefault("monitor.history.rollup_retention", "720h")
+	v.SetDefault("monitor.history.batch_size", 500)
+	v.SetDefault("monitor.history.max_points", 5000)
+	v.SetDefault("monitor.analytics.enabled", false)
+	v.SetDefault("monitor.analytics.provider", "memory")
+	v.SetDefault("monitor.analytics.access_log", true)
+	v.SetDefault("monitor.analytics.audit", true)
+	v.SetDefault("monitor.analytics.batch_size", 1000)
+	v.SetDefault("monitor.analytics.flush_interval", "5s")
+	v.SetDefault("monitor.analytics.queue_size", 10000)
+	v.SetDefault("monitor.analytics.backpressure", "drop")
+	v.SetDefault("monitor.analytics.block_timeout", "100ms")
+	v.SetDefault("monitor.analytics.memory_size", 10000)
+	v.SetDefault("monitor.analytics.clickhouse.addr", []string{"localhost:9000"})
+	v.SetDefault("monitor.analytics.clickhouse.database", "default")
+	v.SetDefault("monitor.analytics.clickhouse.username", "default")
+	v.SetDefault("monitor.analytics.clickhouse.password", "")
+	v.SetDefault("monitor.analytics.clickhouse.table", "analytics_events")
+	v.SetDefault("monitor.analytics.clickhouse.ttl", "720h")
+	v.SetDefault("monitor.analytics.clickhouse.dial_timeout", "5s")
+	v.SetDefault("monitor.analytics.clickhouse.async_insert", false)
+
+	// I18n defaults
+	v.SetDefault("i18n.locales_path", "locales")
+
+	// User preferences defaults
+	v.SetDefault("preferences.language", "")
+	v.SetDefault("preferences.time_zone", "UTC")
+	v.SetDefault("preferences.cache_ttl", "1m")
+
+	// Organization defaults
+	v.SetDefault("organizations.enabled", false)
+	v.SetDefault("organizations.header", "X-Organization-ID")
+
+	// Authorization defaults: admins and the owner of an application may change
+	// or delete it, and so may the admins of its organization
+	v.SetDefault("authorization.enabled", true)
+	v.SetDefault("authorization.audit", "denied")
+	v.SetDefault("authorization.policies", []map[string]interface{}{
+		{"resource": "application", "action": "update", "roles": []string{"admin"}, "owner": true, "org_role": "admin"},
+		{"resource": "application", "action": "delete", "roles": []string{"admin"}, "owner": true, "org_role": "admin"},
+	})
+
+	// Invitation defaults
+	v.SetDefault("invitations.enabled", false)
+	v.SetDefault("invitations.ttl", "72h")
+	v.SetDefault("invitations.secret", "")
+	v.SetDefault("invitations.accept_url", "")
+	v.SetDefault("invitations.default_role", "user")
+
+	// Registration defaults
+	v.SetDefault("registration.enabled", false)
+	v.SetDefault("registration.mode", "invite_only")
+	v.SetDefault("registration.allowed_domains", []string{})
+	v.SetDefault("registration.default_role", "user")
+	v.SetDefault("registration.default_permissions", []string{})
+	v.SetDefault("registration.min_password_length", 8)
+	v.SetDefault("registration.verification_ttl", "24h")
+	v.SetDefault("registration.verify_url", "")
+	v.SetDefault("registration.tenants", []map[string]interface{}{})
+
+	// Security defaults
+	v.SetDefault("security.jwt_secret", DefaultJWTSecret)
+	v.SetDefault("security.rate_limit_rps", 100)
+	v.SetDefault("security.rate_limit_burst", 200)
+	v.SetDefault("security.rate_limit_classes", map[string]interface{}{
+		"strict": map[string]interface{}{"rps": 10, "burst": 20},
+	})
+	v.SetDefault("security.max_file_size", "10MB")
+	v.SetDefault("security.allowed_file_types", []string{"image/jpeg", "image/png", "image/gif", "application/pdf"})
+	v.SetDefault("security.csrf_enabled", true)
+	v.SetDefault("security.encryption_key", DefaultEncryptionKey)
+	v.SetDefault("security.impersonation.enabled", false)
+	v.SetDefault("security.impersonation.roles", []string{"admin", "support"})
+	v.SetDefault("security.impersonation.token_ttl", "15m")
+	v.SetDefault("security.impersonation.protected_roles", []string{"admin"})
+	v.SetDefault("security.signing.max_skew", "5m")
+	v.SetDefault("security.signing.nonce_store", "memory")
+	v.SetDefault("security.signing.max_body_size", "10MB")
+	v.SetDefault("security.signing.rotation_grace", "24h")
+	v.SetDefault("security.network_acl.enabled", false)
+	v.SetDefault("security.network_acl.geoip_database", "")
+	v.SetDefault("security.network_acl.global.allow", []string{})
+	v.SetDefault("security.network_acl.global.deny", []string{})
+	v.SetDefault("security.network_acl.global.allow_countries", []string{})
+	v.SetDefault("security.network_acl.global.deny_countries", []string{})
+	v.SetDefault("security.bot_detection.enabled", false)
+	v.SetDefault("security.bot_detection.monitor", false)
+	v.SetDefault("security.bot_detection.store", "memory")
+	v.SetDefault("security.bot_detection.challenge_score", 3)
+	v.SetDefault("security.bot_detection.block_score", 6)
+	v.SetDefault("security.bot_detection.user_agents", []string{
+		"curl", "wget", "python-requests", "python-urllib", "go-http-client", "scrapy",
+		"headlesschrome", "phantomjs", "bot", "spider", "crawler",
+	})
+	v.SetDefault("security.bot_detection.rate_window", "1m")
+	v.SetDefault("security.bot_detection.rate_limit", 120)
+	v.SetDefault("security.bot_detection.challenge.provider", "")
+	v.SetDefault("security.bot_detection.challenge.secret_key", "")
+	v.SetDefault("security.bot_detection.challenge.verify_url", "")
+	v.SetDefault("security.bot_detection.challenge.min_score", 0.5)
+	v.SetDefault("security.bot_detection.challenge.pass_ttl", "30m")
+	v.SetDefault("security.login_throttle.enabled", false)
+	v.SetDefault("security.login_throttle.store", "memory")
+	v.SetDefault("security.login_throttle.window", "15m")
+	v.SetDefault("security.login_throttle.username_fields", []string{"username", "email"})
+	v.SetDefault("security.login_throttle.max_username_failures", 10)
+	v.SetDefault("security.login_throttle.max_ip_failures", 50)
+	v.SetDefault("security.login_throttle.lockout_duration", "15m")
+	v.SetDefault("security.login_throttle.delay_after", 3)
+	v.SetDefault("security.login_throttle.base_delay", "1s")
+	v.SetDefault("security.login_throttle.max_delay", "30s")
+	v.SetDefault("security.login_throttle.challenge_after", 0)
+	v.SetDefault("security.login_throttle.webhook_url", "")
+	v.SetDefault("security.sessions.enabled", false)
+	v.SetDefault("security.sessions.access_token_ttl", "15m")
+	v.SetDefault("security.sessions.refresh_token_ttl", "720h")
+	v.SetDefault("security.sessions.denylist_store", "memory")
+	v.SetDefault("security.sessions.touch_interval", "1m")
+
+	// Remote configuration defaults (disabled unless remote.provider is set)
+	v.SetDefault("remote.provider", "")
+	v.SetDefault("remote.endpoint", "")
+	v.SetDefault("remote.key", "")
+	v.SetDefault("remote.token", "")
+	v.SetDefault("remote.username", "")
+	v.SetDefault("remote.password", "")
+	v.SetDefault("remote.timeout", "5s")
+	v.SetDefault("remote.refresh_interval", "30s")
+	v.SetDefault("remote.snapshot_path", "")
+	v.SetDefault("remote.required", false)
+
+	// Revisions defaults
+	v.SetDefault("revisions.max_per_application", 50)
+	v.SetDefault("revisions.max_age", "0s")
+
+	// Storage defaults
+	v.SetDefault("storage.type", "local")
+	v.SetDefault("storage.path", "data/storage")
+	v.SetDefault("storage.s3.region", "us-east-1")
+
+	// Files defaults
+	v.SetDefault("files.enabled", false)
+	v.SetDefault("files.max_size", 100<<20)
+	v.SetDefault("files.content_types", []string{})
+	v.SetDefault("files.upload_url_ttl", "15m")
+	v.SetDefault("files.download_url_ttl", "5m")
+	v.SetDefault("files.key_prefix", "files/")
+	v.SetDefault("files.images.enabled", false)
+	v.SetDefault("files.images.thumbnail_sizes", []int{128, 512})
+	v.SetDefault("files.images.strip_metadata", true)
+	v.SetDefault("files.images.quality", 85)
+	v.SetDefault("files.images.max_pixels", 40000000)
+	v.SetDefault("files.scan.enabled", false)
+	v.SetDefault("files.scan.address", "localhost:3310")
+	v.SetDefault("files.scan.timeout", "5m")
+	v.SetDefault("files.uploads.enabled", false)
+	v.SetDefault("files.uploads.max_chunk_size", 8<<20)
+	v.SetDefault("files.uploads.session_ttl", "24h")
+	v.SetDefault("files.uploads.cleanup_interval", "1h")
+
+	// Outbound HTTP client defaults
+	v.SetDefault("http_client.timeout", "30s")
+	v.SetDefault("http_client.dial_timeout", "5s")
+	v.SetDefault("http_client.tls_handshake_timeout", "5s")
+	v.SetDefault("http_client.response_header_timeout", "10s")
+	v.SetDefault("http_client.idle_conn_timeout", "90s")
+	v.SetDefault("http_client.max_idle_conns_per_host", 10)
+	v.SetDefault("http_client.retry.max_attempts", 3)
+	v.SetDefault("http_client.retry.initial_backoff", "100ms")
+	v.SetDefault("http_client.retry.max_backoff", "2s")
+	v.SetDefault("http_client.circuit_breaker.failure_threshold", 5)
+	v.SetDefault("http_client.circuit_breaker.open_timeout", "30s")
+
+	// Mail defaults
+	v.SetDefault("mail.enabled", false)
+	v.SetDefault("mail.provider", "log")
+	v.SetDefault("mail.from", "server-tpl <noreply@example.com>")
+	v.SetDefault("mail.language", "")
+	v.SetDefault("mail.templates_path", "")
+	v.SetDefault("mail.max_attempts", 3)
+	v.SetDefault("mail.retry_backoff", "30s")
+	v.SetDefault("mail.smtp.host", "")
+	v.SetDefault("mail.smtp.port", 587)
+	v.SetDefault("mail.smtp.tls", "starttls")
+	v.SetDefault("mail.smtp.timeout", "10s")
+	v.SetDefault("mail.notifications.application_deleted", []string{})
+	v.SetDefault("mail.notifications.user_registered", []string{})
+
+	// Notification defaults
+	v.SetDefault("notification.enabled", false)
+	v.SetDefault("notification.language", "")
+	v.SetDefault("notification.templates_path", "")
+	v.SetDefault("notification.channels.sms.provider", "log")
+	v.SetDefault("notification.channels.push.provider", "log")
+	v.SetDefault("notification.channels.webhook.provider", "webhook")
+	v.SetDefault("notification.rate_limit.store", "memory")
+	v.SetDefault("notification.rate_limit.per_recipient", 10)
+	v.SetDefault("notification.rate_limit.window", "1h")
+
+	// Broker defaults
+	v.SetDefault("broker.enabled", false)
+	v.SetDefault("broker.driver", "memory")
+	v.SetDefault("broker.codec", "json")
+	v.SetDefault("broker.max_deliver", 5)
+	v.SetDefault("broker.retry_backoff", "1s")
+	v.SetDefault("broker.ack_wait", "30s")
+	v.SetDefault("broker.nats.url", "nats://127.0.0.1:4222")
+	v.SetDefault("broker.nats.stream", "SERVER_TPL")
+	v.SetDefault("broker.nats.replicas", 1)
+	v.SetDefault("broker.nats.max_age", "168h")
+	v.SetDefault("broker.nats.connect_timeout", "5s")
+	v.SetDefault("broker.bridge.events", []string{})
+	v.SetDefault("broker.bridge.topic_prefix", "events.")
+
+	// Outbox defaults
+	v.SetDefault("outbox.enabled", false)
+	v.SetDefault("outbox.events", []string{})
+	v.SetDefault("outbox.topic_prefix", "events.")
+	v.SetDefault("outbox.poll_interval", "1s")
+	v.SetDefault("outbox.batch_size", 100)
+	v.SetDefault("outbox.max_attempts", 0)
+	v.SetDefault("outbox.retry_backoff", "1s")
+	v.SetDefault("outbox.max_backoff", "5m")
+	v.SetDefault("outbox.retention", "168h")
+
+	// Event log defaults
+	v.SetDefault("event_log.enabled", false)
+	v.SetDefault("event_log.events", []string{"*"})
+	v.SetDefault("event_log.exclude", []string{"operation.progress", "experiment.exposure"})
+	v.SetDefault("event_log.poll_interval", "1s")
+	v.SetDefault("event_log.max_wait", "30s")
+	v.SetDefault("event_log.page_size", 100)
+
+	// Retention defaults
+	v.SetDefault("retention.enabled", false)
+	v.SetDefault("retention.interval", "1h")
+	v.SetDefault("retention.batch_size", 500)
+	v.SetDefault("retention.policies.audit_logs", "2160h")
+	v.SetDefault("retention.policies.applications", "720h")
+	v.SetDefault("retention.policies.operations", "168h")
+	v.SetDefault("retention.policies.domain_events", "168h")
+
+	// GraphQL defaults
+	v.SetDefault("server.graphql.enabled", false)
+	v.SetDefault("server.graphql.playground", false)
+	v.SetDefault("server.graphql.introspection", false)
+	v.SetDefault("server.graphql.max_complexity", 500)
+	v.SetDefault("server.graphql.max_depth", 8)
+
+	// gRPC gateway defaults
+	v.SetDefault("server.gateway.enabled", false)
+	v.SetDefault("server.gateway.prefix", "/rpc")
+
+	// Batch endpoint defaults
+	v.SetDefault("server.batch.enabled", true)
+	v.SetDefault("server.batch.max_requests", 20)
+	v.SetDefault("server.batch.max_concurrency", 4)
+
+	// Benchmark defaults
+	v.SetDefault("server.socket.systemd", false)
+	v.SetDefault("server.socket.reuse_port", false)
+	v.SetDefault("server.socket.upgrade", false)
+	v.SetDefault("server.socket.upgrade_timeout", "30s")
+	v.SetDefault("server.socket.pid_file", "")
+	v.SetDefault("server.bench.enabled", false)
+	v.SetDefault("server.bench.max_concurrency", 256)
+	v.SetDefault("server.bench.max_iterations", 100000)
+	v.SetDefault("server.bench.max_payload", "1MB")
+
+	// Quota defaults
+	v.SetDefault("quota.enabled", false)
+	v.SetDefault("quota.store", "memory")
+	v.SetDefault("quota.classes.default.daily", 10000)
+	v.SetDefault("quota.classes.default.monthly", 0)
+}
+
+// DefaultSecurityConfig returns the security configuration built from the defaults only
+func DefaultSecurityConfig() *SecurityConfig {
+	v := viper.New()
+	setDefaults(v)
+
+	config := &Config{}
+	if err := v.Unmarshal(config, decodeHook()); err != nil {
+		panic(fmt.Sprintf("invalid security defaults: %v", err))
+	}
+	return &config.Security
+}
+
+// DefaultHTTPClientConfig returns the outbound HTTP client configuration built from the defaults only
+func DefaultHTTPClientConfig() *HTTPClientConfig {
+	v := viper.New()
+	setDefaults(v)
+
+	config := &Config{}
+	if err := v.Unmarshal(config, decodeHook()); err != nil {
+		panic(fmt.Sprintf("invalid http client defaults: %v", err))
+	}
+	return &config.HTTPClient
+}
+
+// DefaultAPIConfig returns the API version configuration built from the defaults only
+func DefaultAPIConfig() *APIConfig {
+	v := viper.New()
+	setDefaults(v)
+
+	config := &Config{}
+	if err := v.Unmarshal(config, decodeHook()); err != nil {
+		panic(fmt.Sprintf("invalid api defaults: %v", err))
+	}
+	return &config.Server.API
 }
 
 // Convenience methods for backward compatibility
@@ -301,6 +1664,7 @@ func New() *Config {
 				Host: "0.0.0.0",
 				Port: 8080,
 			},
+			Security: *DefaultSecurityConfig(),
 		}
 	}
 	return manager.GetConfig()
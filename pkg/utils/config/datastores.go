@@ -0,0 +1,76 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/mitchellh/mapstructure"
+)
+
+// PrimaryDatastore names the primary database in database.routes
+const PrimaryDatastore = "primary"
+
+// DatastoreName returns the name of the datastore configured by c
+func (c *DatabaseConfig) DatastoreName() string {
+	if c.Name == "" {
+		return PrimaryDatastore
+	}
+	return c.Name
+}
+
+// Datastore returns the settings of the datastore name, those of c for PrimaryDatastore
+func (c *DatabaseConfig) Datastore(name string) (DatabaseConfig, bool) {
+	if name == PrimaryDatastore {
+		return *c, true
+	}
+	ds, ok := c.Datastores[name]
+	return ds, ok
+}
+
+// TableDatastore returns the settings of the datastore holding table
+func (c *DatabaseConfig) TableDatastore(table string) (DatabaseConfig, bool) {
+	name, ok := c.Routes[table]
+	if !ok {
+		name = PrimaryDatastore
+	}
+	return c.Datastore(name)
+}
+
+// resolveDatastores completes the named datastores of db with the settings of
+// the primary database they leave out. raw holds the database.datastores
+// settings as read, which tell the settings given apart from zero values.
+func resolveDatastores(db *DatabaseConfig, raw interface{}) error {
+	settings, ok := raw.(map[string]interface{})
+	if !ok || len(db.Datastores) == 0 {
+		return nil
+	}
+
+	resolved := make(map[string]DatabaseConfig, len(db.Datastores))
+	for name := range db.Datastores {
+		ds := *db
+		ds.Datastores = nil
+		ds.Routes = nil
+		ds.Etcd.Endpoints = append([]string(nil), db.Etcd.Endpoints...)
+		ds.Tenancy.Tables = append([]string(nil), db.Tenancy.Tables...)
+		ds.RLS.Tables = append([]string(nil), db.RLS.Tables...)
+
+		decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			DecodeHook:       decodeHookFunc(),
+			WeaklyTypedInput: true,
+			ZeroFields:       true, // lists given replace those of the primary database
+			Result:           &ds,
+		})
+		if err != nil {
+			return err
+		}
+		if err := decoder.Decode(settings[name]); err != nil {
+			return fmt.Errorf("database.datastores.%s: %w", name, err)
+		}
+		// Datastores are not nested, they share the routes of the primary database
+		ds.Name = name
+		ds.Datastores = nil
+		ds.Routes = db.Routes
+		resolved[name] = ds
+	}
+	db.Datastores = resolved
+	return nil
+}
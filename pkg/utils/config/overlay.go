@@ -0,0 +1,180 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Configuration sources in increasing order of precedence
+const (
+	SourceDefaults    = "defaults"
+	SourceEnvironment = "environment variables"
+)
+
+// RedactedValue replaces sensitive values in redacted settings
+const RedactedValue = "******"
+
+// configPaths are the directories searched for app.yml and its overlays
+var configPaths = []string{"./configs", "./"}
+
+// sensitiveKeys are setting names, or suffixes after "_", whose values are
+// redacted, as are the values of every name ending in "_key" such as api_key
+// or secret_key. A bare key, as of feature flags, is not a secret.
+var sensitiveKeys = []string{"password", "secret", "token", "dsn", "uri", "credentials"}
+
+// mergeOverlay deep merges the environment overlay app.{env}.yml over the base
+// configuration. Maps are merged key by key, lists and scalars are replaced.
+func (m *ConfigManager) mergeOverlay() error {
+	env := strings.ToLower(strings.TrimSpace(m.viper.GetString("app.env")))
+	if env == "" {
+		return nil
+	}
+
+	for _, path := range m.overlayCandidates(env) {
+		file, err := os.Open(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to open config overlay %s: %w", path, err)
+		}
+
+		err = m.viper.MergeConfig(file)
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to merge config overlay %s: %w", path, err)
+		}
+		m.sources = append(m.sources, path)
+		return nil
+	}
+	return nil
+}
+
+// overlayCandidates returns the possible overlay files for env, next to the base
+// file when one was loaded and in the default config paths otherwise
+func (m *ConfigManager) overlayCandidates(env string) []string {
+	if base := m.viper.ConfigFileUsed(); base != "" {
+		dir := filepath.Dir(base)
+		ext := filepath.Ext(base)
+		name := strings.TrimSuffix(filepath.Base(base), ext)
+		return []string{filepath.Join(dir, name+"."+env+ext)}
+	}
+
+	var candidates []string
+	for _, dir := range configPaths {
+		for _, ext := range []string{".yml", ".yaml"} {
+			candidates = append(candidates, filepath.Join(dir, "app."+env+ext))
+		}
+	}
+	return candidates
+}
+
+// Sources returns the configuration sources that were applied, lowest precedence first
+func (m *ConfigManager) Sources() []string {
+	sources := append([]string{}, m.sources...)
+	return append(sources, SourceEnvironment)
+}
+
+// Settings returns the effective configuration as a nested map. Sensitive values
+// such as passwords, secrets, tokens and DSNs are masked when redacted is true.
+func (m *ConfigManager) Settings(redacted bool) map[string]interface{} {
+	settings := m.viper.AllSettings()
+	if redacted {
+		redact(settings)
+	}
+	return settings
+}
+
+// Snapshot returns cfg as a nested map keyed by setting names, in the same shape
+// as Settings. Sensitive values are masked when redacted is true.
+func Snapshot(cfg *Config, redacted bool) map[string]interface{} {
+	settings, _ := settingsOf(reflect.ValueOf(cfg)).(map[string]interface{})
+	if redacted {
+		redact(settings)
+	}
+	return settings
+}
+
+// settingsOf converts a configuration value to maps, lists and scalars
+func settingsOf(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return settingsOf(v.Elem())
+	case reflect.Struct:
+		settings := make(map[string]interface{}, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("mapstructure"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			settings[name] = settingsOf(v.Field(i))
+		}
+		return settings
+	case reflect.Map:
+		settings := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			settings[fmt.Sprint(iter.Key().Interface())] = settingsOf(iter.Value())
+		}
+		return settings
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = settingsOf(v.Index(i))
+		}
+		return items
+	default:
+		if d, ok := v.Interface().(time.Duration); ok {
+			return d.String()
+		}
+		return v.Interface()
+	}
+}
+
+// redact masks sensitive values in settings in place
+func redact(settings map[string]interface{}) {
+	for key, value := range settings {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			redact(v)
+		case []interface{}:
+			for _, item := range v {
+				if nested, ok := item.(map[string]interface{}); ok {
+					redact(nested)
+				}
+			}
+		default:
+			if isSensitiveKey(key) && fmt.Sprint(v) != "" {
+				settings[key] = RedactedValue
+			}
+		}
+	}
+}
+
+// isSensitiveKey reports whether a setting name holds a secret
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if strings.HasSuffix(key, "_key") {
+		return true
+	}
+	for _, sensitive := range sensitiveKeys {
+		if key == sensitive || strings.HasSuffix(key, "_"+sensitive) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,351 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// RemoteConfig holds the remote configuration backend settings. The remote
+// document is a YAML (or JSON) file stored under Key and is merged over the
+// local files; environment variables still take precedence.
+type RemoteConfig struct {
+	Provider        string        `mapstructure:"provider"` // etcd, consul
+	Endpoint        string        `mapstructure:"endpoint" validate:"required_with=Provider,omitempty,url"`
+	Key             string        `mapstructure:"key" validate:"required_with=Provider"`
+	Token           string        `mapstructure:"token"` // consul ACL token
+	Username        string        `mapstructure:"username"`
+	Password        string        `mapstructure:"password"`
+	Timeout         time.Duration `mapstructure:"timeout" validate:"gt=0"`
+	RefreshInterval time.Duration `mapstructure:"refresh_interval" validate:"min=0"`
+	SnapshotPath    string        `mapstructure:"snapshot_path"` // last good document, used when the backend is unreachable
+	Required        bool          `mapstructure:"required"`      // fail startup when neither backend nor snapshot are available
+}
+
+// RemoteProvider fetches the configuration document from a remote backend
+type RemoteProvider interface {
+	// Name returns a description of the backend used in configuration sources
+	Name() string
+	// Fetch returns the current configuration document
+	Fetch(ctx context.Context) ([]byte, error)
+}
+
+// RemoteProviderFactory creates a remote provider from configuration
+type RemoteProviderFactory func(cfg RemoteConfig) (RemoteProvider, error)
+
+var (
+	remoteProvidersMu sync.RWMutex
+	remoteProviders   = map[string]RemoteProviderFactory{
+		"consul": newConsulProvider,
+		"etcd":   newEtcdProvider,
+	}
+)
+
+// RegisterRemoteProvider registers a remote provider factory under the given name
+func RegisterRemoteProvider(name string, factory RemoteProviderFactory) {
+	remoteProvidersMu.Lock()
+	defer remoteProvidersMu.Unlock()
+	remoteProviders[name] = factory
+}
+
+// newRemoteProvider creates the provider configured in cfg
+func newRemoteProvider(cfg RemoteConfig) (RemoteProvider, error) {
+	remoteProvidersMu.RLock()
+	factory, ok := remoteProviders[cfg.Provider]
+	remoteProvidersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unsupported remote config provider: %s", cfg.Provider)
+	}
+	if cfg.Endpoint == "" || cfg.Key == "" {
+		return nil, fmt.Errorf("remote config provider %s requires endpoint and key", cfg.Provider)
+	}
+	return factory(cfg)
+}
+
+// fetchRemote fetches the remote document, bounded by the configured timeout
+func fetchRemote(provider RemoteProvider, timeout time.Duration) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	data, err := provider.Fetch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch remote config from %s: %w", provider.Name(), err)
+	}
+	return data, nil
+}
+
+// saveSnapshot stores the last good remote document
+func saveSnapshot(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// consulProvider reads a key from the Consul KV HTTP API
+type consulProvider struct {
+	endpoint string
+	key      string
+	token    string
+	client   *http.Client
+}
+
+// newConsulProvider creates a Consul KV provider
+func newConsulProvider(cfg RemoteConfig) (RemoteProvider, error) {
+	return &consulProvider{
+		endpoint: strings.TrimRight(cfg.Endpoint, "/"),
+		key:      strings.TrimLeft(cfg.Key, "/"),
+		token:    cfg.Token,
+		client:   &http.Client{},
+	}, nil
+}
+
+// Name implements RemoteProvider
+func (p *consulProvider) Name() string {
+	return fmt.Sprintf("consul %s/%s", p.endpoint, p.key)
+}
+
+// Fetch implements RemoteProvider
+func (p *consulProvider) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.endpoint+"/v1/kv/"+p.key+"?raw", nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.token != "" {
+		req.Header.Set("X-Consul-Token", p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return body, nil
+}
+
+// etcdProvider reads a key through the etcd v3 JSON gateway
+type etcdProvider struct {
+	endpoint string
+	key      string
+	username string
+	password string
+	client   *http.Client
+}
+
+// newEtcdProvider creates an etcd v3 provider
+func newEtcdProvider(cfg RemoteConfig) (RemoteProvider, error) {
+	return &etcdProvider{
+		endpoint: strings.TrimRight(cfg.Endpoint, "/"),
+		key:      cfg.Key,
+		username: cfg.Username,
+		password: cfg.Password,
+		client:   &http.Client{},
+	}, nil
+}
+
+// Name implements RemoteProvider
+func (p *etcdProvider) Name() string {
+	return fmt.Sprintf("etcd %s%s", p.endpoint, p.key)
+}
+
+// Fetch implements RemoteProvider
+func (p *etcdProvider) Fetch(ctx context.Context) ([]byte, error) {
+	var token string
+	if p.username != "" {
+		var auth struct {
+			Token string `json:"token"`
+		}
+		if err := p.post(ctx, "/v3/auth/authenticate", "", map[string]string{
+			"name":     p.username,
+			"password": p.password,
+		}, &auth); err != nil {
+			return nil, fmt.Errorf("authentication failed: %w", err)
+		}
+		token = auth.Token
+	}
+
+	var result struct {
+		Kvs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := p.post(ctx, "/v3/kv/range", token, map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(p.key)),
+	}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Kvs) == 0 {
+		return nil, fmt.Errorf("key %s not found", p.key)
+	}
+	return base64.StdEncoding.DecodeString(result.Kvs[0].Value)
+}
+
+// post sends a JSON request to the etcd gateway and decodes the response
+func (p *etcdProvider) post(ctx context.Context, path, token string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// loadRemote fetches the remote document configured under remote.* and merges it.
+// When the backend is unreachable or the document is invalid the snapshot is used; without a snapshot the
+// error is returned only if remote.required is set. Caller must hold the lock.
+func (m *ConfigManager) loadRemote() error {
+	local, err := m.unmarshal()
+	if err != nil {
+		return err
+	}
+	cfg := local.Remote
+	if cfg.Provider == "" {
+		return nil
+	}
+
+	provider, err := newRemoteProvider(cfg)
+	if err != nil {
+		return err
+	}
+	m.remote, m.remoteConfig = provider, cfg
+
+	data, err := fetchRemote(provider, cfg.Timeout)
+	if err == nil {
+		if err = m.mergeRemote(data, provider.Name()); err == nil {
+			if cfg.SnapshotPath != "" {
+				if err := saveSnapshot(cfg.SnapshotPath, data); err != nil {
+					logger.Warn("Failed to save remote config snapshot: %v", err)
+				}
+			}
+			return nil
+		}
+	}
+
+	// Fall back to the last good document
+	if cfg.SnapshotPath != "" {
+		if snapshot, readErr := os.ReadFile(cfg.SnapshotPath); readErr == nil {
+			logger.Warn("%v, using snapshot %s", err, cfg.SnapshotPath)
+			return m.mergeRemote(snapshot, "snapshot "+cfg.SnapshotPath)
+		}
+	}
+	if cfg.Required {
+		return err
+	}
+	logger.Warn("%v, continuing with local configuration", err)
+	return nil
+}
+
+// mergeRemote deep merges the remote document over the local files. Caller must hold the lock.
+func (m *ConfigManager) mergeRemote(data []byte, source string) error {
+	if err := m.viper.MergeConfig(bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to merge remote config from %s: %w", source, err)
+	}
+	m.remoteData, m.remoteSource = data, source
+	m.sources = append(m.sources, source)
+	return nil
+}
+
+// rebuild re-reads the local files and merges the given remote document. Caller must hold the lock.
+func (m *ConfigManager) rebuild(data []byte, source string) (*Config, error) {
+	if err := m.readLocal(); err != nil {
+		return nil, err
+	}
+	if data != nil {
+		if err := m.mergeRemote(data, source); err != nil {
+			return nil, err
+		}
+	}
+	return m.unmarshal()
+}
+
+// watchRemote periodically fetches the remote document and rebuilds the
+// configuration when it changed. Fetch failures and invalid documents keep the
+// last good configuration.
+func (m *ConfigManager) watchRemote(stop <-chan struct{}, callback func(*Config)) {
+	ticker := time.NewTicker(m.remoteConfig.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+
+		data, err := fetchRemote(m.remote, m.remoteConfig.Timeout)
+		if err != nil {
+			logger.Warn("%v, keeping last good configuration", err)
+			continue
+		}
+
+		m.mu.Lock()
+		if bytes.Equal(data, m.remoteData) {
+			m.mu.Unlock()
+			continue
+		}
+
+		lastData, lastSource := m.remoteData, m.remoteSource
+		newConfig, err := m.rebuild(data, m.remote.Name())
+		if err != nil {
+			logger.Warn("Ignoring invalid remote config: %v", err)
+			if _, restoreErr := m.rebuild(lastData, lastSource); restoreErr != nil {
+				logger.Warn("Failed to restore last good configuration: %v", restoreErr)
+			}
+			m.mu.Unlock()
+			continue
+		}
+		m.config = newConfig
+		if m.remoteConfig.SnapshotPath != "" {
+			if err := saveSnapshot(m.remoteConfig.SnapshotPath, data); err != nil {
+				logger.Warn("Failed to save remote config snapshot: %v", err)
+			}
+		}
+		m.mu.Unlock()
+
+		logger.Info("Remote configuration changed, reloaded from %s", m.remote.Name())
+		if callback != nil {
+			callback(newConfig)
+		}
+	}
+}
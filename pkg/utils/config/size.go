@@ -0,0 +1,95 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/viper"
+)
+
+// ByteSize is a size in bytes that can be configured in human readable form,
+// e.g. "512KB", "10MB" or "1.5GB". Units are binary (1KB = 1024 bytes).
+type ByteSize int64
+
+// Byte size units
+const (
+	Byte     ByteSize = 1
+	Kilobyte          = 1024 * Byte
+	Megabyte          = 1024 * Kilobyte
+	Gigabyte          = 1024 * Megabyte
+	Terabyte          = 1024 * Gigabyte
+)
+
+// byteSizeUnits maps unit suffixes to their size, longest suffixes first
+var byteSizeUnits = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"KIB", Kilobyte}, {"MIB", Megabyte}, {"GIB", Gigabyte}, {"TIB", Terabyte},
+	{"KB", Kilobyte}, {"MB", Megabyte}, {"GB", Gigabyte}, {"TB", Terabyte},
+	{"K", Kilobyte}, {"M", Megabyte}, {"G", Gigabyte}, {"T", Terabyte},
+	{"B", Byte},
+}
+
+// ParseByteSize parses a size such as "10MB", "512 KiB" or "1048576"
+func ParseByteSize(s string) (ByteSize, error) {
+	value := strings.ToUpper(strings.TrimSpace(s))
+	if value == "" {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	unit := Byte
+	for _, u := range byteSizeUnits {
+		if strings.HasSuffix(value, u.suffix) {
+			value, unit = strings.TrimSpace(strings.TrimSuffix(value, u.suffix)), u.size
+			break
+		}
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return ByteSize(number * float64(unit)), nil
+}
+
+// String returns the size using the largest unit that divides it exactly
+func (b ByteSize) String() string {
+	for _, u := range []struct {
+		suffix string
+		size   ByteSize
+	}{{"TB", Terabyte}, {"GB", Gigabyte}, {"MB", Megabyte}, {"KB", Kilobyte}} {
+		if b != 0 && b%u.size == 0 {
+			return fmt.Sprintf("%d%s", b/u.size, u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", int64(b))
+}
+
+// stringToByteSizeHook decodes strings such as "10MB" into ByteSize fields.
+// Plain numbers keep their meaning as a number of bytes.
+func stringToByteSizeHook() mapstructure.DecodeHookFuncType {
+	return func(from reflect.Type, to reflect.Type, data interface{}) (interface{}, error) {
+		if from.Kind() != reflect.String || to != reflect.TypeOf(ByteSize(0)) {
+			return data, nil
+		}
+		return ParseByteSize(data.(string))
+	}
+}
+
+// decodeHook is used when unmarshalling settings into Config
+func decodeHook() viper.DecoderConfigOption {
+	return viper.DecodeHook(decodeHookFunc())
+}
+
+// decodeHookFunc converts durations, lists and byte sizes given as strings
+func decodeHookFunc() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		stringToByteSizeHook(),
+	)
+}
@@ -0,0 +1,417 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// Tags reported by the cross-field rules in validateConfig
+const (
+	tagProductionRequired = "production_required"
+	tagProductionFalse    = "production_false"
+	tagProductionDefault  = "production_default"
+	tagConflicts          = "conflicts"
+	tagUnique             = "unique_key"
+	tagRegistered         = "registered"
+	tagListed             = "listed"
+	tagRequires           = "requires"
+	tagFileMode           = "file_mode"
+)
+
+// Violation describes a single invalid setting
+type Violation struct {
+	Field   string // setting path, e.g. server.port
+	Message string
+}
+
+// ValidationError aggregates every violation found in a configuration
+type ValidationError struct {
+	Violations []Violation
+}
+
+// Error implements error, listing one violation per line
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "invalid configuration (%d problem(s)):", len(e.Violations))
+	for _, v := range e.Violations {
+		fmt.Fprintf(&b, "\n  - %s: %s", v.Field, v.Message)
+	}
+	return b.String()
+}
+
+// configValidator validates Config using the validate struct tags, reporting
+// fields by their setting path instead of the Go field name
+var configValidator = newConfigValidator()
+
+func newConfigValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("mapstructure"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return field.Name
+		}
+		return name
+	})
+	v.RegisterStructValidation(validateConfig, Config{})
+	return v
+}
+
+// ValidateConfig checks the whole configuration tree and returns a
+// *ValidationError listing all violations, or nil when the configuration is valid
+func ValidateConfig(cfg *Config) error {
+	err := configValidator.Struct(cfg)
+	if err == nil {
+		return nil
+	}
+
+	fieldErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+
+	result := &ValidationError{}
+	for _, fe := range fieldErrors {
+		field := strings.TrimPrefix(fe.Namespace(), "Config.")
+		result.Violations = append(result.Violations, Violation{
+			Field:   field,
+			Message: violationMessage(field, fe),
+		})
+	}
+	return result
+}
+
+// validateConfig implements the rules spanning several settings
+func validateConfig(sl validator.StructLevel) {
+	cfg := sl.Current().Interface().(Config)
+
+	// Production requires secrets and forbids debug mode
+	if cfg.IsProduction() {
+		if cfg.Database.Type != "memory" && cfg.Database.Password == "" {
+			sl.ReportError(cfg.Database.Password, "database.password", "Password", tagProductionRequired, "")
+		}
+		if cfg.App.Debug {
+			sl.ReportError(cfg.App.Debug, "app.debug", "Debug", tagProductionFalse, "")
+		}
+		if cfg.Server.Bench.Enabled {
+			sl.ReportError(cfg.Server.Bench.Enabled, "server.bench.enabled", "Enabled", tagProductionFalse, "")
+		}
+		if cfg.Security.JWTSecret == DefaultJWTSecret {
+			sl.ReportError(cfg.Security.JWTSecret, "security.jwt_secret", "JWTSecret", tagProductionDefault, "")
+		}
+		if cfg.Security.EncryptionKey == DefaultEncryptionKey {
+			sl.ReportError(cfg.Security.EncryptionKey, "security.encryption_key", "EncryptionKey", tagProductionDefault, "")
+		}
+	}
+
+	// Mutually exclusive options
+	if cfg.Server.CORS.AllowCredentials {
+		for _, origin := range cfg.Server.CORS.AllowedOrigins {
+			if origin == "*" {
+				sl.ReportError(cfg.Server.CORS.AllowCredentials, "server.cors.allow_credentials", "AllowCredentials", tagConflicts, "server.cors.allowed_origins \"*\"")
+				break
+			}
+		}
+	}
+	if cfg.Database.MaxOpenConns > 0 && cfg.Database.MaxIdleConns > cfg.Database.MaxOpenConns {
+		sl.ReportError(cfg.Database.MaxIdleConns, "database.max_idle_conns", "MaxIdleConns", "ltefield", "MaxOpenConns")
+	}
+
+	// Named datastores are held to the rules of the primary database, routes
+	// lead to one of them or to the primary database
+	for name, ds := range cfg.Database.Datastores {
+		field := "database.datastores." + name
+		if name == PrimaryDatastore {
+			sl.ReportError(name, field, "Datastores", tagUnique, "")
+		}
+		if cfg.IsProduction() && ds.Type != "memory" && ds.Password == "" {
+			sl.ReportError(ds.Password, field+".password", "Password", tagProductionRequired, "")
+		}
+		if ds.MaxOpenConns > 0 && ds.MaxIdleConns > ds.MaxOpenConns {
+			sl.ReportError(ds.MaxIdleConns, field+".max_idle_conns", "MaxIdleConns", "ltefield", "MaxOpenConns")
+		}
+	}
+	for table, name := range cfg.Database.Routes {
+		if _, ok := cfg.Database.Datastores[name]; !ok && name != PrimaryDatastore {
+			sl.ReportError(name, "database.routes."+table, "Routes", tagListed, "database.datastores")
+		}
+	}
+
+	// Schemas per tenant and row-level security policies are created in the
+	// datastore of every table they cover
+	if cfg.Database.Tenancy.Mode == "schema" && !sqlDatastores(&cfg.Database, cfg.Database.Tenancy.Tables) {
+		sl.ReportError(cfg.Database.Tenancy.Mode, "database.tenancy.mode", "Mode", tagRequires,
+			"a postgresql or opengauss datastore for every table of database.tenancy.tables")
+	}
+	if cfg.Database.RLS.Enabled && !sqlDatastores(&cfg.Database, cfg.Database.RLS.Tables) {
+		sl.ReportError(cfg.Database.RLS.Enabled, "database.rls.enabled", "Enabled", tagRequires,
+			"a postgresql or opengauss datastore for every table of database.rls.tables")
+	}
+	// Tag selectors on join tables are not qualified with the schema of a tenant
+	if cfg.Database.Tags.Storage == "join_table" && cfg.Database.Tenancy.Mode == "schema" {
+		sl.ReportError(cfg.Database.Tags.Storage, "database.tags.storage", "Storage", tagRequires, "database.tenancy.mode row")
+	}
+
+	if cfg.Mail.Enabled && cfg.Mail.Provider == "smtp" && cfg.Mail.SMTP.Host == "" {
+		sl.ReportError(cfg.Mail.SMTP.Host, "mail.smtp.host", "Host", "required_if", "Provider smtp")
+	}
+
+	if cfg.Storage.Type == "s3" && cfg.Storage.S3.Bucket == "" {
+		sl.ReportError(cfg.Storage.S3.Bucket, "storage.s3.bucket", "Bucket", "required_if", "Type s3")
+	}
+	if cfg.Files.Enabled && cfg.Storage.Type != "s3" {
+		sl.ReportError(cfg.Files.Enabled, "files.enabled", "Enabled", tagRequires, "storage.type s3")
+	}
+
+	if cfg.Monitor.Prometheus.Enabled && cfg.Monitor.Prometheus.Mode == "push" && cfg.Monitor.Prometheus.Push.URL == "" {
+		sl.ReportError(cfg.Monitor.Prometheus.Push.URL, "monitor.prometheus.push.url", "URL", "required_if", "Mode push")
+	}
+	if cfg.Monitor.Prometheus.Enabled && cfg.Monitor.Prometheus.Mode == "otlp" && cfg.Monitor.Prometheus.OTLP.Endpoint == "" {
+		sl.ReportError(cfg.Monitor.Prometheus.OTLP.Endpoint, "monitor.prometheus.otlp.endpoint", "Endpoint", "required_if", "Mode otlp")
+	}
+
+	if cfg.Outbox.Enabled && !cfg.Broker.Enabled {
+		sl.ReportError(cfg.Outbox.Enabled, "outbox.enabled", "Enabled", tagRequires, "broker.enabled")
+	}
+	// Impersonated requests must be audited
+	if cfg.Security.Impersonation.Enabled && !(cfg.Monitor.Analytics.Enabled && cfg.Monitor.Analytics.Audit) {
+		sl.ReportError(cfg.Security.Impersonation.Enabled, "security.impersonation.enabled", "Enabled", tagRequires, "monitor.analytics.enabled and monitor.analytics.audit")
+	}
+
+	// Country rules need the GeoIP database, groups need paths
+	if cfg.Security.NetworkACL.GeoIPDatabase == "" {
+		global := cfg.Security.NetworkACL.Global
+		if len(global.AllowCountries) > 0 || len(global.DenyCountries) > 0 {
+			sl.ReportError(global.AllowCountries, "security.network_acl.global.allow_countries", "AllowCountries", tagRequires, "security.network_acl.geoip_database")
+		}
+	}
+	for name, group := range cfg.Security.NetworkACL.Groups {
+		if len(group.Paths) == 0 {
+			sl.ReportError(group.Paths, "security.network_acl.groups."+name+".paths", "Paths", "required", "")
+		}
+		if cfg.Security.NetworkACL.GeoIPDatabase == "" && (len(group.AllowCountries) > 0 || len(group.DenyCountries) > 0) {
+			sl.ReportError(group.AllowCountries, "security.network_acl.groups."+name+".allow_countries", "AllowCountries", tagRequires, "security.network_acl.geoip_database")
+		}
+	}
+
+	// Login challenges are verified by the bot detection provider, lockouts posted through the webhook channel
+	if cfg.Security.LoginThrottle.Enabled {
+		botDetection := cfg.Security.BotDetection
+		if cfg.Security.LoginThrottle.ChallengeAfter > 0 && !(botDetection.Enabled && botDetection.Challenge.Provider != "") {
+			sl.ReportError(cfg.Security.LoginThrottle.ChallengeAfter, "security.login_throttle.challenge_after", "ChallengeAfter", tagRequires,
+				"security.bot_detection.enabled and security.bot_detection.challenge.provider, or 0")
+		}
+		if _, ok := cfg.Notification.Channels["webhook"]; cfg.Security.LoginThrottle.WebhookURL != "" && !(cfg.Notification.Enabled && ok) {
+			sl.ReportError(cfg.Security.LoginThrottle.WebhookURL, "security.login_throttle.webhook_url", "WebhookURL", tagRequires,
+				"notification.enabled and notification.channels.webhook")
+		}
+	}
+
+	// Invite-only signup accepts invitations
+	if cfg.Registration.Enabled && cfg.Registration.Mode == "invite_only" && !cfg.Invitations.Enabled {
+		sl.ReportError(cfg.Registration.Mode, "registration.mode", "Mode", tagRequires, "invitations.enabled")
+	}
+
+	// Open signup mails the email verification token
+	if cfg.Registration.Enabled && cfg.Registration.Mode == "open" && !cfg.Mail.Enabled {
+		sl.ReportError(cfg.Registration.Mode, "registration.mode", "Mode", tagRequires, "mail.enabled")
+	}
+
+	// A refresh must not start before the previous fetch timed out
+	if cfg.Remote.Provider != "" && cfg.Remote.RefreshInterval > 0 && cfg.Remote.RefreshInterval < cfg.Remote.Timeout {
+		sl.ReportError(cfg.Remote.RefreshInterval, "remote.refresh_interval", "RefreshInterval", "gtefield", "Timeout")
+	}
+
+	// Registered providers
+	if cfg.Remote.Provider != "" {
+		remoteProvidersMu.RLock()
+		_, ok := remoteProviders[cfg.Remote.Provider]
+		remoteProvidersMu.RUnlock()
+		if !ok {
+			sl.ReportError(cfg.Remote.Provider, "remote.provider", "Provider", tagRegistered, "")
+		}
+	}
+
+	// Unique keys
+	flags := make(map[string]bool, len(cfg.FeatureFlags.Flags))
+	for i, flag := range cfg.FeatureFlags.Flags {
+		if flag.Key != "" && flags[flag.Key] {
+			sl.ReportError(flag.Key, fmt.Sprintf("feature_flags.flags[%d].key", i), "Key", tagUnique, "")
+		}
+		flags[flag.Key] = true
+	}
+	versions := make(map[string]bool, len(cfg.Server.API.Versions))
+	for i, version := range cfg.Server.API.Versions {
+		if version.Name != "" && versions[version.Name] {
+			sl.ReportError(version.Name, fmt.Sprintf("server.api.versions[%d].name", i), "Name", tagUnique, "")
+		}
+		versions[version.Name] = true
+	}
+	if cfg.Server.API.DefaultVersion != "" && !versions[cfg.Server.API.DefaultVersion] {
+		sl.ReportError(cfg.Server.API.DefaultVersion, "server.api.default_version", "DefaultVersion", tagListed, "server.api.versions")
+	}
+	listeners := make(map[string]bool, len(cfg.Server.Listeners))
+	for i, l := range cfg.Server.Listeners {
+		key := l.Network + " " + l.Address
+		if l.Network == "" {
+			key = "tcp " + l.Address
+		}
+		if l.Address != "" && listeners[key] {
+			sl.ReportError(l.Address, fmt.Sprintf("server.listeners[%d].address", i), "Address", tagUnique, "")
+		}
+		listeners[key] = true
+		if l.Mode != "" {
+			if mode, err := strconv.ParseUint(l.Mode, 8, 32); err != nil || mode > 0o777 {
+				sl.ReportError(l.Mode, fmt.Sprintf("server.listeners[%d].mode", i), "Mode", tagFileMode, "")
+			} else if l.Network != "unix" {
+				sl.ReportError(l.Mode, fmt.Sprintf("server.listeners[%d].mode", i), "Mode", tagConflicts, "a tcp listener")
+			}
+		}
+	}
+	experiments := make(map[string]bool, len(cfg.Experiments))
+	for i, experiment := range cfg.Experiments {
+		if experiment.Key != "" && experiments[experiment.Key] {
+			sl.ReportError(experiment.Key, fmt.Sprintf("experiments[%d].key", i), "Key", tagUnique, "")
+		}
+		experiments[experiment.Key] = true
+	}
+}
+
+// sqlDatastores reports whether every table is held by a PostgreSQL compatible datastore
+func sqlDatastores(db *DatabaseConfig, tables []string) bool {
+	for _, table := range tables {
+		if ds, ok := db.TableDatastore(table); ok && ds.Type != "postgresql" && ds.Type != "opengauss" {
+			return false
+		}
+	}
+	return true
+}
+
+// violationMessage renders a readable message for a failed rule
+func violationMessage(field string, fe validator.FieldError) string {
+	var message string
+	switch fe.Tag() {
+	case "required":
+		message = "is required"
+	case "required_if":
+		message = "is required when " + describeCondition(fe.Param())
+	case "required_unless":
+		message = "is required unless " + describeCondition(fe.Param())
+	case "required_with":
+		message = "is required when " + settingName(fe.Param()) + " is set"
+	case "min":
+		if fe.Kind() == reflect.String {
+			message = "must be at least " + fe.Param() + " characters long"
+			break
+		}
+		message = "must be at least " + fe.Param()
+	case "max":
+		message = "must be at most " + fe.Param()
+	case "gt":
+		message = "must be greater than " + fe.Param()
+	case "oneof":
+		message = "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	case "startswith":
+		message = fmt.Sprintf("must start with %q", fe.Param())
+	case "url":
+		message = "must be a valid URL"
+	case "ip|cidr":
+		message = "must be an IP address or a CIDR such as 10.0.0.0/8"
+	case "iso3166_1_alpha2":
+		message = "must be an upper case ISO 3166-1 alpha-2 country code such as DE"
+	case "email":
+		message = "must be a valid email address"
+	case "hostname":
+		message = "must be a domain name such as example.com"
+	case "timezone":
+		message = "must be an IANA time zone name"
+	case "datetime":
+		message = "must be a date in the " + fe.Param() + " layout"
+	case "ltefield":
+		message = "must not exceed " + settingName(fe.Param())
+	case "gtfield":
+		message = "must be greater than " + settingName(fe.Param())
+	case "gtefield":
+		message = "must not be less than " + settingName(fe.Param())
+	case tagProductionRequired:
+		return "is required in production"
+	case tagProductionFalse:
+		return "must be false in production"
+	case tagProductionDefault:
+		return "must be changed from the built-in default in production"
+	case tagConflicts:
+		return "cannot be combined with " + fe.Param()
+	case tagUnique:
+		return fmt.Sprintf("duplicate key %q", fe.Value())
+	case tagRegistered:
+		return fmt.Sprintf("unsupported provider %q", fe.Value())
+	case tagListed:
+		return fmt.Sprintf("%q is not listed in %s", fe.Value(), fe.Param())
+	case tagRequires:
+		return "requires " + fe.Param()
+	case tagFileMode:
+		return fmt.Sprintf("must be octal permissions such as \"0660\", got %q", fe.Value())
+	default:
+		message = "failed " + fe.Tag() + " validation"
+	}
+
+	if strings.HasPrefix(fe.Tag(), "required") {
+		return message
+	}
+	if value := describeValue(field, fe.Value()); value != "" {
+		message += ", got " + value
+	}
+	return message
+}
+
+// describeValue renders the offending value, hiding secrets and empty values
+func describeValue(field string, value interface{}) string {
+	if isSensitiveKey(field[strings.LastIndex(field, ".")+1:]) {
+		return ""
+	}
+	switch v := value.(type) {
+	case string:
+		if v == "" {
+			return ""
+		}
+		return fmt.Sprintf("%q", v)
+	case time.Duration:
+		return v.String()
+	case ByteSize:
+		return v.String()
+	case int, int64, float64, bool:
+		return fmt.Sprint(v)
+	}
+	return ""
+}
+
+// describeCondition renders a required_if style parameter ("Enabled true Provider sentry")
+func describeCondition(param string) string {
+	fields := strings.Fields(param)
+	conditions := make([]string, 0, len(fields)/2)
+	for i := 0; i+1 < len(fields); i += 2 {
+		conditions = append(conditions, settingName(fields[i])+"="+fields[i+1])
+	}
+	return strings.Join(conditions, " and ")
+}
+
+// settingName converts a sibling Go field name to its setting name (PathPrefix -> path_prefix)
+func settingName(field string) string {
+	var b strings.Builder
+	runes := []rune(field)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -24,6 +24,22 @@ const (
 	Request
 )
 
+// String returns the lifecycle name
+func (l Lifecycle) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Prototype:
+		return "prototype"
+	case Session:
+		return "session"
+	case Request:
+		return "request"
+	default:
+		return fmt.Sprintf("lifecycle(%d)", int(l))
+	}
+}
+
 // Bean represents a registered dependency
 type Bean struct {
 	Name      string
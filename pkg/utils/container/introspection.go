@@ -0,0 +1,115 @@
+package container
+
+import (
+	"fmt"
+)
+
+// DependencyInfo 描述bean的一个注入字段及其注入结果
+type DependencyInfo struct {
+	Field     string   `json:"field"`
+	Tag       string   `json:"tag"`
+	Type      string   `json:"type"`
+	Bean      string   `json:"bean,omitempty"`
+	Group     []string `json:"group,omitempty"`
+	Satisfied bool     `json:"satisfied"`
+	Error     string   `json:"error,omitempty"`
+}
+
+// BeanInfo 描述一个已注册bean的类型、依赖、生命周期与健康状态
+type BeanInfo struct {
+	Name         string           `json:"name"`
+	Type         string           `json:"type"`
+	Qualifiers   []string         `json:"qualifiers,omitempty"`
+	Lifecycle    string           `json:"lifecycle"`
+	Dependencies []DependencyInfo `json:"dependencies"`
+	Starter      bool             `json:"starter"`
+	Stopper      bool             `json:"stopper"`
+	Started      bool             `json:"started"`
+	Healthy      bool             `json:"healthy"`
+	HealthError  string           `json:"health_error,omitempty"`
+}
+
+// HealthChecker 由能够自检的bean实现（例如数据存储）
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// Inspector 提供容器的运行时诊断信息
+type Inspector interface {
+	// Describe 按注册顺序返回所有bean的信息，并执行bean的健康检查
+	Describe() []BeanInfo
+}
+
+// Describe 按注册顺序返回所有bean的信息，工厂bean排在单例之后。依赖未满足或健康检查失败的bean标记为不健康。
+func (c *SimpleContainer) Describe() []BeanInfo {
+	c.mu.RLock()
+	started := make(map[string]bool, len(c.started))
+	for _, name := range c.started {
+		started[name] = true
+	}
+
+	infos := make([]BeanInfo, 0, len(c.order))
+	beans := make([]interface{}, 0, len(c.order))
+	for _, name := range c.order {
+		bean, exists := c.beans[name]
+		if !exists {
+			continue
+		}
+
+		_, isStarter := bean.(Starter)
+		_, isStopper := bean.(Stopper)
+		info := BeanInfo{
+			Name:         name,
+			Type:         fmt.Sprintf("%T", bean),
+			Qualifiers:   c.qualifiers[name],
+			Lifecycle:    Singleton.String(),
+			Dependencies: append([]DependencyInfo{}, c.injections[name]...),
+			Starter:      isStarter,
+			Stopper:      isStopper,
+			Started:      started[name],
+			Healthy:      true,
+		}
+		for _, dep := range info.Dependencies {
+			if !dep.Satisfied {
+				info.Healthy = false
+				info.HealthError = fmt.Sprintf("unsatisfied dependency for field %s: %s", dep.Field, dep.Error)
+				break
+			}
+		}
+
+		infos = append(infos, info)
+		beans = append(beans, bean)
+	}
+	// 工厂bean只有在解析时才创建实例，这里只列出定义
+	for _, key := range c.factoryOrder {
+		definition, exists := c.factories[key]
+		if !exists {
+			continue
+		}
+		_, qualifiers := parseInjectTag(key)
+		infos = append(infos, BeanInfo{
+			Name:       key,
+			Type:       "factory",
+			Qualifiers: qualifiers,
+			Lifecycle:  definition.lifecycle.String(),
+			Healthy:    true,
+		})
+		beans = append(beans, nil)
+	}
+	c.mu.RUnlock()
+
+	// 健康检查可能涉及IO，不在锁内执行
+	for i, bean := range beans {
+		if !infos[i].Healthy {
+			continue
+		}
+		if checker, ok := bean.(HealthChecker); ok {
+			if err := checker.HealthCheck(); err != nil {
+				infos[i].Healthy = false
+				infos[i].HealthError = err.Error()
+			}
+		}
+	}
+
+	return infos
+}
@@ -0,0 +1,126 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// Starter 由需要在服务启动时执行初始化（启动后台任务、建立连接等）的bean实现
+type Starter interface {
+	OnStart(ctx context.Context) error
+}
+
+// Stopper 由需要在服务关闭时释放资源的bean实现
+type Stopper interface {
+	OnStop(ctx context.Context) error
+}
+
+// Start 按依赖顺序调用bean的OnStart，被依赖的bean先启动。
+// 任一bean启动失败时，已启动的bean会按相反顺序停止。
+func (c *SimpleContainer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return fmt.Errorf("container already started")
+	}
+	order := c.lifecycleOrder()
+	beans := make([]interface{}, len(order))
+	for i, name := range order {
+		beans[i] = c.beans[name]
+	}
+	c.running = true
+	c.started = nil
+	c.mu.Unlock()
+
+	for i, name := range order {
+		if starter, ok := beans[i].(Starter); ok {
+			logger.Debug("Starting bean: %s", name)
+			if err := starter.OnStart(ctx); err != nil {
+				if stopErr := c.Stop(ctx); stopErr != nil {
+					logger.Warn("Failed to stop beans after start failure: %v", stopErr)
+				}
+				return fmt.Errorf("failed to start bean '%s': %w", name, err)
+			}
+		}
+
+		c.mu.Lock()
+		c.started = append(c.started, name)
+		c.mu.Unlock()
+	}
+
+	logger.Info("Container started %d beans", len(order))
+	return nil
+}
+
+// Stop 按启动的相反顺序调用bean的OnStop，依赖方先于被依赖的bean停止。
+// 所有bean都会被尝试停止，错误会合并返回。
+func (c *SimpleContainer) Stop(ctx context.Context) error {
+	c.mu.Lock()
+	if !c.running {
+		c.mu.Unlock()
+		return nil
+	}
+	started := c.started
+	beans := make([]interface{}, len(started))
+	for i, name := range started {
+		beans[i] = c.beans[name]
+	}
+	c.running = false
+	c.started = nil
+	c.mu.Unlock()
+
+	var errs []error
+	for i := len(started) - 1; i >= 0; i-- {
+		if stopper, ok := beans[i].(Stopper); ok {
+			logger.Debug("Stopping bean: %s", started[i])
+			if err := stopper.OnStop(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("failed to stop bean '%s': %w", started[i], err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// lifecycleOrder 返回按依赖拓扑排序的bean名称，依赖在前；无依赖关系的bean保持注册顺序。
+// 调用方需持有锁。
+func (c *SimpleContainer) lifecycleOrder() []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[string]int, len(c.order))
+	order := make([]string, 0, len(c.order))
+
+	var visit func(name string)
+	visit = func(name string) {
+		switch state[name] {
+		case visited:
+			return
+		case visiting:
+			logger.Warn("Dependency cycle detected at bean '%s', lifecycle order may be incomplete", name)
+			return
+		}
+
+		state[name] = visiting
+		for _, dep := range c.deps[name] {
+			if _, exists := c.beans[dep]; exists {
+				visit(dep)
+			}
+		}
+		state[name] = visited
+		order = append(order, name)
+	}
+
+	for _, name := range c.order {
+		if _, exists := c.beans[name]; exists {
+			visit(name)
+		}
+	}
+	return order
+}
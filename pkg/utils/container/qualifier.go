@@ -0,0 +1,109 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// QualifierPrimary 标记同一名称或类型的多个实现中的首选bean，在存在歧义时优先注入
+const QualifierPrimary = "primary"
+
+// parseInjectTag 解析inject标签 "name,qualifier1,qualifier2"，name为空表示按类型查找
+func parseInjectTag(tag string) (string, []string) {
+	parts := strings.Split(tag, ",")
+	name := strings.TrimSpace(parts[0])
+
+	var qualifiers []string
+	for _, part := range parts[1:] {
+		if q := strings.TrimSpace(part); q != "" {
+			qualifiers = append(qualifiers, q)
+		}
+	}
+	return name, qualifiers
+}
+
+// beanKey 返回bean在容器中的唯一键，格式与inject标签一致："name" 或 "name,qualifier..."
+func beanKey(name string, qualifiers []string) string {
+	if len(qualifiers) == 0 {
+		return name
+	}
+	return name + "," + strings.Join(qualifiers, ",")
+}
+
+// hasQualifiers 判断bean是否带有全部指定的限定符，调用方需持有锁
+func (c *SimpleContainer) hasQualifiers(key string, qualifiers []string) bool {
+	for _, q := range qualifiers {
+		found := false
+		for _, own := range c.qualifiers[key] {
+			if own == q {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// getByName 按名称和限定符查找bean，调用方需持有锁。
+// 先精确匹配键，否则在同名bean中查找带有全部限定符的实现，多个实现时优先primary。
+func (c *SimpleContainer) getByName(name string, qualifiers []string) (string, interface{}, bool) {
+	key := beanKey(name, qualifiers)
+	if bean, exists := c.beans[key]; exists {
+		return key, bean, true
+	}
+
+	var candidates []string
+	for _, key := range c.order {
+		if c.names[key] == name && c.hasQualifiers(key, qualifiers) {
+			candidates = append(candidates, key)
+		}
+	}
+	return c.pick(candidates, fmt.Sprintf("name %s", beanKey(name, qualifiers)))
+}
+
+// getAll 返回所有可赋值给elemType且带有全部限定符的bean（按注册顺序），
+// name非空时只包含该名称下的实现。调用方需持有锁。
+func (c *SimpleContainer) getAll(elemType reflect.Type, name string, qualifiers []string) []string {
+	var keys []string
+	for _, key := range c.order {
+		if name != "" && c.names[key] != name {
+			continue
+		}
+		if !c.hasQualifiers(key, qualifiers) {
+			continue
+		}
+		if reflect.TypeOf(c.beans[key]).AssignableTo(elemType) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// pick 从候选bean中选出唯一结果：单个候选直接返回，否则使用唯一的primary实现
+func (c *SimpleContainer) pick(candidates []string, lookup string) (string, interface{}, bool) {
+	switch len(candidates) {
+	case 0:
+		return "", nil, false
+	case 1:
+		return candidates[0], c.beans[candidates[0]], true
+	}
+
+	var primary []string
+	for _, key := range candidates {
+		if c.hasQualifiers(key, []string{QualifierPrimary}) {
+			primary = append(primary, key)
+		}
+	}
+	if len(primary) == 1 {
+		return primary[0], c.beans[primary[0]], true
+	}
+
+	logger.Warn("Ambiguous bean lookup for %s: %d candidates %v", lookup, len(candidates), candidates)
+	return "", nil, false
+}
@@ -0,0 +1,197 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+// ErrNoRequestScope 在请求作用域之外解析请求作用域bean时返回
+var ErrNoRequestScope = errors.New("no request scope in context")
+
+// Factory 创建原型或请求作用域bean的工厂函数。
+// 请求作用域bean的ctx为请求上下文，可通过GinContext获取当前请求。
+type Factory func(ctx context.Context) (interface{}, error)
+
+// factoryBean 通过工厂创建实例的bean定义
+type factoryBean struct {
+	lifecycle Lifecycle
+	factory   Factory
+}
+
+// ProvideFactory 注册原型（每次解析创建新实例）或请求作用域（每个请求一个实例）的bean。
+// 工厂创建的实例会按inject标签注入单例依赖。
+func (c *SimpleContainer) ProvideFactory(name string, lifecycle Lifecycle, factory Factory, qualifiers ...string) error {
+	if lifecycle != Prototype && lifecycle != Request {
+		return fmt.Errorf("unsupported lifecycle for factory bean '%s': %s", name, lifecycle)
+	}
+	if factory == nil {
+		return fmt.Errorf("factory for bean '%s' is nil", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := beanKey(name, qualifiers)
+	if _, exists := c.beans[key]; exists {
+		return fmt.Errorf("bean with name '%s' already exists", key)
+	}
+	if _, exists := c.factories[key]; exists {
+		return fmt.Errorf("bean with name '%s' already exists", key)
+	}
+
+	c.factories[key] = &factoryBean{lifecycle: lifecycle, factory: factory}
+	c.factoryOrder = append(c.factoryOrder, key)
+	logger.Debug("Registered %s bean: %s", lifecycle, key)
+	return nil
+}
+
+// GetScoped 按名称解析bean：单例直接返回，原型bean每次创建新实例，
+// 请求作用域bean在ctx携带的请求作用域内复用同一实例。
+func (c *SimpleContainer) GetScoped(ctx context.Context, name string) (interface{}, error) {
+	key := beanKey(parseInjectTag(name))
+
+	c.mu.RLock()
+	definition, isFactory := c.factories[key]
+	if !isFactory {
+		_, bean, found := c.getByName(parseInjectTag(name))
+		c.mu.RUnlock()
+		if !found {
+			return nil, fmt.Errorf("bean '%s' not found", name)
+		}
+		return bean, nil
+	}
+	c.mu.RUnlock()
+
+	if definition.lifecycle == Prototype {
+		return c.newInstance(ctx, key, definition)
+	}
+
+	scope, ok := scopeFromContext(ctx)
+	if !ok || scope.container != c {
+		return nil, fmt.Errorf("%w: bean '%s' is request scoped", ErrNoRequestScope, key)
+	}
+	return scope.get(ctx, key, definition)
+}
+
+// newInstance 调用工厂创建实例并注入单例依赖
+func (c *SimpleContainer) newInstance(ctx context.Context, key string, definition *factoryBean) (interface{}, error) {
+	instance, err := definition.factory(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bean '%s': %w", key, err)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if err := c.injectDependencies("", instance); err != nil {
+		return nil, fmt.Errorf("failed to inject dependencies for bean '%s': %w", key, err)
+	}
+	return instance, nil
+}
+
+// Scoped 从ctx携带的请求作用域解析bean并转换为T
+func Scoped[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+	scope, ok := scopeFromContext(ctx)
+	if !ok {
+		return zero, fmt.Errorf("%w: resolving bean '%s'", ErrNoRequestScope, name)
+	}
+
+	bean, err := scope.container.GetScoped(ctx, name)
+	if err != nil {
+		return zero, err
+	}
+	typed, ok := bean.(T)
+	if !ok {
+		return zero, fmt.Errorf("bean '%s' has type %T, expected %T", name, bean, zero)
+	}
+	return typed, nil
+}
+
+// requestScope 保存单个请求内创建的请求作用域bean
+type requestScope struct {
+	container *SimpleContainer
+	gin       *gin.Context
+	mu        sync.Mutex
+	instances map[string]interface{}
+	order     []string
+}
+
+type requestScopeContextKey struct{}
+
+// scopeFromContext 返回ctx携带的请求作用域
+func scopeFromContext(ctx context.Context) (*requestScope, bool) {
+	scope, ok := ctx.Value(requestScopeContextKey{}).(*requestScope)
+	return scope, ok
+}
+
+// GinContext 返回请求作用域所属的gin上下文，供请求作用域bean的工厂读取请求信息
+func GinContext(ctx context.Context) (*gin.Context, bool) {
+	scope, ok := scopeFromContext(ctx)
+	if !ok || scope.gin == nil {
+		return nil, false
+	}
+	return scope.gin, true
+}
+
+// get 返回作用域内的实例，首次解析时创建。
+// 创建时不持有锁，工厂可以解析同一请求内的其他请求作用域bean。
+func (s *requestScope) get(ctx context.Context, key string, definition *factoryBean) (interface{}, error) {
+	s.mu.Lock()
+	instance, exists := s.instances[key]
+	s.mu.Unlock()
+	if exists {
+		return instance, nil
+	}
+
+	created, err := s.container.newInstance(ctx, key, definition)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if instance, exists := s.instances[key]; exists {
+		return instance, nil
+	}
+	s.instances[key] = created
+	s.order = append(s.order, key)
+	return created, nil
+}
+
+// close 按创建的相反顺序停止实现了Stopper的请求作用域实例
+func (s *requestScope) close(ctx context.Context) {
+	s.mu.Lock()
+	order, instances := s.order, s.instances
+	s.order, s.instances = nil, make(map[string]interface{})
+	s.mu.Unlock()
+
+	for i := len(order) - 1; i >= 0; i-- {
+		if stopper, ok := instances[order[i]].(Stopper); ok {
+			if err := stopper.OnStop(ctx); err != nil {
+				logger.Warn("Failed to stop request scoped bean '%s': %v", order[i], err)
+			}
+		}
+	}
+}
+
+// RequestScopeMiddleware 为每个请求创建请求作用域，并绑定到请求的context。
+// 处理器通过 container.Scoped[T](c.Request.Context(), name) 解析请求作用域bean，请求结束时作用域关闭。
+func RequestScopeMiddleware(c *SimpleContainer) gin.HandlerFunc {
+	return func(gc *gin.Context) {
+		scope := &requestScope{
+			container: c,
+			gin:       gc,
+			instances: make(map[string]interface{}),
+		}
+		ctx := context.WithValue(gc.Request.Context(), requestScopeContextKey{}, scope)
+		gc.Request = gc.Request.WithContext(ctx)
+
+		defer scope.close(ctx)
+		gc.Next()
+	}
+}
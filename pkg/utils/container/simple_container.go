@@ -1,6 +1,7 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"reflect"
 	"sync"
@@ -9,16 +10,39 @@ import (
 	"github.com/make-bin/server-tpl/pkg/utils/logger"
 )
 
-// SimpleContainer 简单的依赖注入容器，按照规范实现
+// SimpleContainer 简单的依赖注入容器，按照规范实现。
+// 同一名称或接口可以注册多个实现，通过限定符区分，例如 inject:"datastore,replica"。
 type SimpleContainer struct {
 	beans map[string]interface{}
 	mu    sync.RWMutex
+
+	// bean键对应的名称与限定符，键的格式为 "name" 或 "name,qualifier..."
+	names      map[string]string
+	qualifiers map[string][]string
+
+	// 生命周期管理：注册顺序、Populate时记录的依赖关系以及已启动的bean
+	order   []string
+	deps    map[string][]string
+	started []string
+	running bool
+
+	// 每个bean的注入结果，供Describe诊断使用
+	injections map[string][]DependencyInfo
+
+	// 原型和请求作用域bean的工厂定义
+	factories    map[string]*factoryBean
+	factoryOrder []string
 }
 
 // NewContainer 创建新的容器实例
 func NewContainer() *SimpleContainer {
 	return &SimpleContainer{
-		beans: make(map[string]interface{}),
+		beans:      make(map[string]interface{}),
+		names:      make(map[string]string),
+		qualifiers: make(map[string][]string),
+		deps:       make(map[string][]string),
+		injections: make(map[string][]DependencyInfo),
+		factories:  make(map[string]*factoryBean),
 	}
 }
 
@@ -32,51 +56,95 @@ func (c *SimpleContainer) Provides(beans ...interface{}) error {
 	return nil
 }
 
-// ProvideWithName 提供带名称的bean
-func (c *SimpleContainer) ProvideWithName(name string, bean interface{}) error {
+// ProvideWithName 提供带名称的bean，可附加限定符以在同一名称下注册多个实现
+func (c *SimpleContainer) ProvideWithName(name string, bean interface{}, qualifiers ...string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	if name == "" {
 		name = fmt.Sprintf("%T", bean)
 	}
+	key := beanKey(name, qualifiers)
 
 	// 检查是否已存在
-	if _, exists := c.beans[name]; exists {
-		return fmt.Errorf("bean with name '%s' already exists", name)
+	if _, exists := c.beans[key]; exists {
+		return fmt.Errorf("bean with name '%s' already exists", key)
+	}
+	if _, exists := c.factories[key]; exists {
+		return fmt.Errorf("bean with name '%s' already exists", key)
 	}
 
-	c.beans[name] = bean
-	logger.Debug("Registered bean: %s", name)
+	c.beans[key] = bean
+	c.names[key] = name
+	c.qualifiers[key] = qualifiers
+	c.order = append(c.order, key)
+	logger.Debug("Registered bean: %s", key)
 	return nil
 }
 
-// Get 获取bean
+// Get 获取bean，name可以带限定符，例如 "datastore,replica"
 func (c *SimpleContainer) Get(name string) (interface{}, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	bean, exists := c.beans[name]
-	return bean, exists
+	_, bean, found := c.getByName(parseInjectTag(name))
+	return bean, found
+}
+
+// GetByType 根据类型获取bean，只匹配带有全部指定限定符的实现
+func (c *SimpleContainer) GetByType(beanType reflect.Type, qualifiers ...string) (interface{}, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, bean, found := c.getByType(beanType, qualifiers)
+	return bean, found
 }
 
-// GetByType 根据类型获取bean
-func (c *SimpleContainer) GetByType(beanType reflect.Type) (interface{}, bool) {
+// GetAll 按注册顺序返回所有可赋值给beanType且带有全部指定限定符的bean
+func (c *SimpleContainer) GetAll(beanType reflect.Type, qualifiers ...string) []interface{} {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for _, bean := range c.beans {
+	keys := c.getAll(beanType, "", qualifiers)
+	beans := make([]interface{}, len(keys))
+	for i, key := range keys {
+		beans[i] = c.beans[key]
+	}
+	return beans
+}
+
+// getByType 根据类型查找bean，调用方需持有锁。
+// 多个bean匹配时优先使用未显式命名的bean（命名bean应按名称注入），其次是primary实现，仍有歧义则返回未找到。
+func (c *SimpleContainer) getByType(beanType reflect.Type, qualifiers []string) (string, interface{}, bool) {
+	var exact, candidates, unnamed []string
+	for _, key := range c.order {
+		if !c.hasQualifiers(key, qualifiers) {
+			continue
+		}
+
+		bean := c.beans[key]
 		if reflect.TypeOf(bean) == beanType {
-			return bean, true
+			exact = append(exact, key)
+			continue
 		}
 
 		// 检查是否实现了接口
 		if beanType.Kind() == reflect.Interface && reflect.TypeOf(bean).Implements(beanType) {
-			return bean, true
+			candidates = append(candidates, key)
+			if key == fmt.Sprintf("%T", bean) {
+				unnamed = append(unnamed, key)
+			}
 		}
 	}
 
-	return nil, false
+	lookup := fmt.Sprintf("type %s", beanType)
+	switch {
+	case len(exact) > 0:
+		return c.pick(exact, lookup)
+	case len(candidates) > 1 && len(unnamed) == 1:
+		return unnamed[0], c.beans[unnamed[0]], true
+	}
+	return c.pick(candidates, lookup)
 }
 
 // Populate 填充依赖字段
@@ -89,9 +157,9 @@ func (c *SimpleContainer) Populate() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// 遍历所有bean，进行依赖注入
-	for name, bean := range c.beans {
-		if err := c.injectDependencies(bean); err != nil {
+	// 按注册顺序遍历所有bean，进行依赖注入
+	for _, name := range c.order {
+		if err := c.injectDependencies(name, c.beans[name]); err != nil {
 			return fmt.Errorf("failed to inject dependencies for bean '%s': %w", name, err)
 		}
 	}
@@ -99,8 +167,9 @@ func (c *SimpleContainer) Populate() error {
 	return nil
 }
 
-// injectDependencies 注入依赖
-func (c *SimpleContainer) injectDependencies(target interface{}) error {
+// injectDependencies 注入依赖，并记录bean之间的依赖关系用于生命周期排序。
+// beanName为空表示工厂创建的实例，不记录依赖关系。调用方需持有锁。
+func (c *SimpleContainer) injectDependencies(beanName string, target interface{}) error {
 	targetValue := reflect.ValueOf(target)
 
 	// 如果是指针，获取元素
@@ -114,56 +183,112 @@ func (c *SimpleContainer) injectDependencies(target interface{}) error {
 	}
 
 	targetType := targetValue.Type()
+	var deps []string
+	var infos []DependencyInfo
 
 	// 遍历所有字段
 	for i := 0; i < targetValue.NumField(); i++ {
 		field := targetValue.Field(i)
 		fieldType := targetType.Field(i)
 
-		// 检查inject标签
-		injectTag := fieldType.Tag.Get("inject")
-		if injectTag == "" {
+		// 检查inject标签，空标签表示按类型注入
+		injectTag, ok := fieldType.Tag.Lookup("inject")
+		if !ok {
 			continue
 		}
 
+		info := DependencyInfo{
+			Field: fieldType.Name,
+			Tag:   injectTag,
+			Type:  field.Type().String(),
+		}
+
 		// 字段必须可设置
 		if !field.CanSet() {
 			logger.Warn("Field %s cannot be set", fieldType.Name)
+			info.Error = "field cannot be set"
+			infos = append(infos, info)
 			continue
 		}
 
-		// 根据标签值查找依赖
-		var dependency interface{}
-		var found bool
+		// 根据标签值查找依赖（Populate已持有锁，这里直接访问beans）
+		name, qualifiers := parseInjectTag(injectTag)
+
+		// 工厂bean：原型为每个注入点创建新实例，请求作用域bean只能在请求内解析
+		if definition, isFactory := c.factories[beanKey(name, qualifiers)]; isFactory {
+			info.Bean = beanKey(name, qualifiers)
+			if definition.lifecycle == Request {
+				logger.Warn("Request scoped bean %s cannot be injected into field %s, resolve it per request", info.Bean, fieldType.Name)
+				info.Error = "request scoped bean must be resolved per request"
+				infos = append(infos, info)
+				continue
+			}
 
-		if injectTag == "" {
-			// 如果标签为空，按类型查找
-			dependency, found = c.GetByType(field.Type())
-		} else {
-			// 按名称查找
-			dependency, found = c.Get(injectTag)
+			instance, err := definition.factory(context.Background())
+			if err == nil {
+				err = c.injectDependencies("", instance)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to create prototype bean '%s': %w", info.Bean, err)
+			}
+			if value := reflect.ValueOf(instance); value.Type().AssignableTo(field.Type()) {
+				field.Set(value)
+				info.Satisfied = true
+			} else {
+				info.Error = fmt.Sprintf("type mismatch: got %s", value.Type())
+			}
+			infos = append(infos, info)
+			continue
 		}
 
-		if !found {
-			// 尝试按类型名查找
-			typeName := field.Type().String()
-			dependency, found = c.Get(typeName)
+		dependencyName, dependency, found := c.lookup(name, qualifiers, field.Type())
+
+		// 切片字段在没有同类型bean时按组注入所有实现
+		if field.Kind() == reflect.Slice && (!found || !reflect.TypeOf(dependency).AssignableTo(field.Type())) {
+			keys := c.getAll(field.Type().Elem(), name, qualifiers)
+			group := reflect.MakeSlice(field.Type(), 0, len(keys))
+			members := make([]string, 0, len(keys))
+			for _, key := range keys {
+				if key == beanName {
+					continue
+				}
+				group = reflect.Append(group, reflect.ValueOf(c.beans[key]))
+				members = append(members, key)
+			}
+			field.Set(group)
+			deps = append(deps, members...)
+			info.Group = members
+			info.Satisfied = true
+			logger.Debug("Injected %d beans into group field: %s", len(members), fieldType.Name)
+			infos = append(infos, info)
+			continue
 		}
 
 		if found {
 			dependencyValue := reflect.ValueOf(dependency)
 			if dependencyValue.Type().AssignableTo(field.Type()) {
 				field.Set(dependencyValue)
+				deps = append(deps, dependencyName)
+				info.Bean = dependencyName
+				info.Satisfied = true
 				logger.Debug("Injected dependency for field: %s", fieldType.Name)
 			} else {
 				logger.Warn("Dependency type mismatch for field %s: expected %s, got %s",
 					fieldType.Name, field.Type(), dependencyValue.Type())
+				info.Bean = dependencyName
+				info.Error = fmt.Sprintf("type mismatch: got %s", dependencyValue.Type())
 			}
 		} else {
 			logger.Warn("Dependency not found for field: %s with inject tag: %s", fieldType.Name, injectTag)
+			info.Error = "dependency not found"
 		}
+		infos = append(infos, info)
 	}
 
+	if beanName != "" {
+		c.deps[beanName] = deps
+		c.injections[beanName] = infos
+	}
 	return nil
 }
 
@@ -184,16 +309,46 @@ func (c *SimpleContainer) HasBean(name string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	_, exists := c.beans[name]
+	_, _, exists := c.getByName(parseInjectTag(name))
 	return exists
 }
 
+// lookup 按inject标签查找单个依赖：name为空时按类型查找，
+// 未找到且没有限定符时再尝试按类型名查找。调用方需持有锁。
+func (c *SimpleContainer) lookup(name string, qualifiers []string, fieldType reflect.Type) (string, interface{}, bool) {
+	var (
+		key   string
+		bean  interface{}
+		found bool
+	)
+	if name == "" {
+		key, bean, found = c.getByType(fieldType, qualifiers)
+	} else {
+		key, bean, found = c.getByName(name, qualifiers)
+	}
+
+	if !found && len(qualifiers) == 0 {
+		key = fieldType.String()
+		bean, found = c.beans[key]
+	}
+	return key, bean, found
+}
+
 // Clear 清空容器
 func (c *SimpleContainer) Clear() {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.beans = make(map[string]interface{})
+	c.names = make(map[string]string)
+	c.qualifiers = make(map[string][]string)
+	c.order = nil
+	c.deps = make(map[string][]string)
+	c.injections = make(map[string][]DependencyInfo)
+	c.factories = make(map[string]*factoryBean)
+	c.factoryOrder = nil
+	c.started = nil
+	c.running = false
 	logger.Info("Container cleared")
 }
 
@@ -0,0 +1,118 @@
+package dotenv
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Entry is a single KEY=value line of a dotenv file
+type Entry struct {
+	Key   string
+	Value string
+}
+
+// Parse parses a dotenv document. Blank lines and lines starting with # are
+// ignored, an optional "export " prefix is accepted, double quoted values
+// support \n, \t, \" and \\ escapes and single quoted values are literal.
+// Unquoted values end at an inline " #" comment.
+func Parse(data []byte) ([]Entry, error) {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=value", lineNo)
+		}
+
+		value, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		entries = append(entries, Entry{Key: key, Value: value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// parseValue unquotes a value
+func parseValue(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		return parseDoubleQuoted(value[1:])
+	case strings.HasPrefix(value, "'"):
+		end := strings.IndexByte(value[1:], '\'')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		return value[1 : end+1], nil
+	default:
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		return strings.TrimSpace(value), nil
+	}
+}
+
+// parseDoubleQuoted unescapes a double quoted value up to its closing quote
+func parseDoubleQuoted(value string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if i+1 == len(value) {
+				return "", fmt.Errorf("unterminated quoted value")
+			}
+			i++
+			switch value[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			default:
+				b.WriteByte(value[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated quoted value")
+}
+
+// Marshal renders entries as a dotenv document, quoting values when needed
+func Marshal(entries []Entry) []byte {
+	var b bytes.Buffer
+	for _, entry := range entries {
+		b.WriteString(entry.Key)
+		b.WriteByte('=')
+		b.WriteString(quote(entry.Value))
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// quote returns value unchanged when it can be written bare, double quoted otherwise
+func quote(value string) string {
+	if !strings.ContainsAny(value, " \t\r\n\"'\\#=$") {
+		return value
+	}
+
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+	return `"` + replacer.Replace(value) + `"`
+}
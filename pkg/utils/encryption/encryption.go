@@ -0,0 +1,68 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// KeySize is the AES-256 key size; longer keys are truncated to it
+const KeySize = 32
+
+// Errors returned by Decrypt
+var (
+	ErrKeyTooShort       = fmt.Errorf("encryption key must be at least %d bytes", KeySize)
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// Encrypt encrypts plaintext with AES-256-GCM and returns the base64 encoded
+// nonce and ciphertext. Only the first 32 bytes of key are used.
+func Encrypt(plaintext, key string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value returned by Encrypt. It fails with
+// ErrInvalidCiphertext when the value was not encrypted with key.
+func Decrypt(ciphertext, key string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil || len(data) < aead.NonceSize() {
+		return "", ErrInvalidCiphertext
+	}
+	nonce, sealed := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", ErrInvalidCiphertext
+	}
+	return string(plaintext), nil
+}
+
+// newAEAD creates the AES-GCM cipher for key
+func newAEAD(key string) (cipher.AEAD, error) {
+	if len(key) < KeySize {
+		return nil, ErrKeyTooShort
+	}
+	block, err := aes.NewCipher([]byte(key)[:KeySize])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"net/http"
 	"runtime"
@@ -59,6 +60,14 @@ const (
 	CodeRequestTimeout       = 20010
 	CodePayloadTooLarge      = 20011
 	CodeUnsupportedMediaType = 20012
+
+	// Third party service error codes (100000-199999)
+	CodeThirdPartyError       = 100000
+	CodeThirdPartyTimeout     = 100001
+	CodeThirdPartyUnavailable = 100002
+	CodeThirdPartyCircuitOpen = 100003
+	CodeThirdPartyRateLimited = 100004
+	CodeThirdPartyBadResponse = 100005
 )
 
 // Error represents a business error
@@ -138,6 +147,14 @@ var errorCodeMap = map[int]string{
 	CodeRequestTimeout:       "请求超时",
 	CodePayloadTooLarge:      "请求体过大",
 	CodeUnsupportedMediaType: "不支持的媒体类型",
+
+	// Third party errors
+	CodeThirdPartyError:       "第三方服务错误",
+	CodeThirdPartyTimeout:     "第三方服务超时",
+	CodeThirdPartyUnavailable: "第三方服务不可用",
+	CodeThirdPartyCircuitOpen: "第三方服务已熔断",
+	CodeThirdPartyRateLimited: "第三方服务请求过于频繁",
+	CodeThirdPartyBadResponse: "第三方服务响应异常",
 }
 
 // GetErrorMessage returns the error message for a given code
@@ -223,6 +240,15 @@ func getStackTrace() string {
 	return string(buf)
 }
 
+// GetStackTrace returns the stack trace captured by the first ErrorWrapper in the error chain
+func GetStackTrace(err error) string {
+	var wrapErr *ErrorWrapper
+	if stderrors.As(err, &wrapErr) {
+		return wrapErr.stackTrace
+	}
+	return ""
+}
+
 // IsErrorType checks if error is of specific type
 func IsErrorType(err error, errorType string) bool {
 	if appErr, ok := err.(*Error); ok {
@@ -267,6 +293,9 @@ func GetHTTPStatusCode(code int) int {
 	case code >= BusinessErrorCodeMin && code <= BusinessErrorCodeMax:
 		return http.StatusBadRequest
 	case code >= ThirdPartyErrorCodeMin && code <= ThirdPartyErrorCodeMax:
+		if code == CodeThirdPartyTimeout {
+			return http.StatusGatewayTimeout
+		}
 		return http.StatusBadGateway
 	default:
 		return http.StatusInternalServerError
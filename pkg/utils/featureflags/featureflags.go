@@ -0,0 +1,130 @@
+package featureflags
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+)
+
+// EvaluationContext carries the attributes a flag is evaluated against
+type EvaluationContext struct {
+	UserID   string `json:"user_id,omitempty"`
+	TenantID string `json:"tenant_id,omitempty"`
+}
+
+// Evaluator evaluates feature flags
+type Evaluator interface {
+	// IsEnabled reports whether the flag is enabled for the given evaluation context
+	IsEnabled(ctx context.Context, key string, evalCtx EvaluationContext) bool
+}
+
+// Assigner assigns experiment variants
+type Assigner interface {
+	// Assign returns the variant for the experiment, or false when the subject is not enrolled
+	Assign(ctx context.Context, key string, evalCtx EvaluationContext) (string, bool)
+}
+
+// Hash returns a stable hash of a key and subject
+func Hash(key, subject string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	h.Write([]byte{':'})
+	h.Write([]byte(subject))
+	return h.Sum32()
+}
+
+// Bucket maps a flag key and subject to a stable bucket in [0, 100)
+func Bucket(key, subject string) int {
+	return int(Hash(key, subject) % 100)
+}
+
+type contextKey struct{}
+
+type variantsContextKey struct{}
+
+// requestFlags memoizes flag evaluations for a single request
+type requestFlags struct {
+	evaluator Evaluator
+	evalCtx   EvaluationContext
+	mu        sync.Mutex
+	results   map[string]bool
+}
+
+// WithEvaluator returns a context whose flag lookups use the evaluator and evaluation context
+func WithEvaluator(ctx context.Context, evaluator Evaluator, evalCtx EvaluationContext) context.Context {
+	return context.WithValue(ctx, contextKey{}, &requestFlags{
+		evaluator: evaluator,
+		evalCtx:   evalCtx,
+		results:   make(map[string]bool),
+	})
+}
+
+// IsEnabled reports whether the flag is enabled for the request carried by ctx.
+// It returns false when no evaluator is attached to the context.
+func IsEnabled(ctx context.Context, key string) bool {
+	flags, ok := ctx.Value(contextKey{}).(*requestFlags)
+	if !ok {
+		return false
+	}
+
+	flags.mu.Lock()
+	defer flags.mu.Unlock()
+
+	if enabled, exists := flags.results[key]; exists {
+		return enabled
+	}
+	enabled := flags.evaluator.IsEnabled(ctx, key, flags.evalCtx)
+	flags.results[key] = enabled
+	return enabled
+}
+
+// FromContext returns the evaluation context attached to ctx
+func FromContext(ctx context.Context) (EvaluationContext, bool) {
+	flags, ok := ctx.Value(contextKey{}).(*requestFlags)
+	if !ok {
+		return EvaluationContext{}, false
+	}
+	return flags.evalCtx, true
+}
+
+// requestVariants memoizes experiment assignments for a single request
+type requestVariants struct {
+	assigner Assigner
+	evalCtx  EvaluationContext
+	mu       sync.Mutex
+	results  map[string]string
+}
+
+// WithAssigner returns a context whose experiment lookups use the assigner and evaluation context
+func WithAssigner(ctx context.Context, assigner Assigner, evalCtx EvaluationContext) context.Context {
+	return context.WithValue(ctx, variantsContextKey{}, &requestVariants{
+		assigner: assigner,
+		evalCtx:  evalCtx,
+		results:  make(map[string]string),
+	})
+}
+
+// Variant returns the experiment variant for the request carried by ctx.
+// It returns an empty string when the subject is not enrolled or no assigner
+// is attached to the context. Each experiment is assigned at most once per request.
+func Variant(ctx context.Context, key string) string {
+	variants, ok := ctx.Value(variantsContextKey{}).(*requestVariants)
+	if !ok {
+		return ""
+	}
+
+	variants.mu.Lock()
+	defer variants.mu.Unlock()
+
+	if variant, exists := variants.results[key]; exists {
+		return variant
+	}
+	variant, _ := variants.assigner.Assign(ctx, key, variants.evalCtx)
+	variants.results[key] = variant
+	return variant
+}
+
+// InVariant reports whether the request carried by ctx is assigned to the given variant
+func InVariant(ctx context.Context, key, variant string) bool {
+	return Variant(ctx, key) == variant
+}
@@ -0,0 +1,101 @@
+package httpclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Circuit states
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half_open"
+)
+
+// circuitBreaker stops calls to a host after consecutive failures. Once the open
+// timeout has passed a single probe is let through: success closes the circuit,
+// failure opens it again.
+type circuitBreaker struct {
+	threshold   int
+	openTimeout time.Duration
+	onChange    func(state string)
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// newCircuitBreaker creates a closed breaker; a zero failure threshold never opens it
+func newCircuitBreaker(cfg config.HTTPClientBreakerConfig, onChange func(state string)) *circuitBreaker {
+	return &circuitBreaker{
+		threshold:   cfg.FailureThreshold,
+		openTimeout: cfg.OpenTimeout,
+		onChange:    onChange,
+		state:       StateClosed,
+	}
+}
+
+// allow reports whether a call may be made now
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return true
+	case StateHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the outcome of a call
+func (b *circuitBreaker) record(failure bool) {
+	if b.threshold <= 0 {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	if !failure {
+		b.failures = 0
+		if b.state != StateClosed {
+			b.setState(StateClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		if b.state != StateOpen {
+			b.setState(StateOpen)
+		}
+	}
+}
+
+// setState changes the state and notifies the listener; the caller must hold the lock
+func (b *circuitBreaker) setState(state string) {
+	b.state = state
+	if b.onChange != nil {
+		b.onChange(state)
+	}
+}
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/make-bin/server-tpl/pkg/utils/errors"
+)
+
+// maxErrorBodyBytes is how much of a failed response body is kept in the error details
+const maxErrorBodyBytes = 512
+
+// ErrCircuitOpen is returned without calling the host while its circuit is open
+var ErrCircuitOpen = stderrors.New("circuit breaker is open")
+
+// newTransportError maps a failure to reach the host to a ThirdPartyError code
+func newTransportError(client, host string, err error) error {
+	return errors.WrapErrorWithFields(err, transportErrorCode(err),
+		fmt.Sprintf("%s request to %s failed: %v", client, host, err),
+		map[string]interface{}{"client": client, "host": host})
+}
+
+// transportErrorCode returns the ThirdPartyError code of a transport failure
+func transportErrorCode(err error) int {
+	var netErr net.Error
+	switch {
+	case stderrors.Is(err, ErrCircuitOpen):
+		return errors.CodeThirdPartyCircuitOpen
+	case stderrors.Is(err, context.DeadlineExceeded), stderrors.As(err, &netErr) && netErr.Timeout():
+		return errors.CodeThirdPartyTimeout
+	default:
+		return errors.CodeThirdPartyUnavailable
+	}
+}
+
+// Check maps the result of a client call to a ThirdPartyError: transport failures keep
+// the code set by the transport, and responses other than 2xx are mapped by status.
+// The response body is closed when an error is returned.
+//
+//	resp, err := client.Do(req)
+//	if err := httpclient.Check(resp, err); err != nil {
+//		return err
+//	}
+//	defer resp.Body.Close()
+func Check(resp *http.Response, err error) error {
+	if err != nil {
+		var wrapped *errors.ErrorWrapper
+		if stderrors.As(err, &wrapped) {
+			return err
+		}
+		// The client timeout is enforced outside the transport
+		return errors.WrapError(err, transportErrorCode(err), err.Error())
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodyBytes))
+	host := resp.Request.URL.Host
+	return errors.WrapErrorWithFields(nil, statusErrorCode(resp.StatusCode),
+		fmt.Sprintf("request to %s failed with status %d", host, resp.StatusCode),
+		map[string]interface{}{"host": host, "status": resp.StatusCode, "body": strings.TrimSpace(string(body))})
+}
+
+// Code returns the ThirdPartyError code of an error returned by Check or the transport,
+// or zero when err carries none
+func Code(err error) int {
+	var wrapped *errors.ErrorWrapper
+	if stderrors.As(err, &wrapped) {
+		return wrapped.Code()
+	}
+	return 0
+}
+
+// statusErrorCode returns the ThirdPartyError code of a failed response status
+func statusErrorCode(status int) int {
+	switch {
+	case status == http.StatusTooManyRequests:
+		return errors.CodeThirdPartyRateLimited
+	case status == http.StatusRequestTimeout, status == http.StatusGatewayTimeout:
+		return errors.CodeThirdPartyTimeout
+	case status >= http.StatusInternalServerError:
+		return errors.CodeThirdPartyUnavailable
+	default:
+		return errors.CodeThirdPartyBadResponse
+	}
+}
@@ -0,0 +1,284 @@
+package httpclient
+
+import (
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// maxDrainBytes is how much of a discarded response body is read so the connection can be reused
+const maxDrainBytes = 4 << 10
+
+// Factory creates outbound HTTP clients sharing the configured timeouts, retries
+// and circuit breaking. Clients are cached by name, so the callers of a service
+// share its connection pool and the circuit breakers of its hosts.
+type Factory struct {
+	cfg config.HTTPClientConfig
+
+	mu      sync.Mutex
+	clients map[string]*http.Client
+	public  map[string]*http.Client
+}
+
+// NewFactory creates a client factory from the http_client configuration
+func NewFactory(cfg *config.HTTPClientConfig) *Factory {
+	return &Factory{
+		cfg:     *cfg,
+		clients: make(map[string]*http.Client),
+		public:  make(map[string]*http.Client),
+	}
+}
+
+// New returns the client of name, creating it on first use; name identifies the
+// called service in metrics and errors, e.g. "payment"
+func (f *Factory) New(name string) *http.Client {
+	return f.client(f.clients, name, New)
+}
+
+// NewPublic returns the client of name that only connects to public addresses,
+// for URLs chosen by users such as webhooks, creating it on first use; see NewPublic
+func (f *Factory) NewPublic(name string) *http.Client {
+	return f.client(f.public, name, NewPublic)
+}
+
+// client returns the client of name in clients, created with create on first use
+func (f *Factory) client(clients map[string]*http.Client, name string, create func(string, *config.HTTPClientConfig) *http.Client) *http.Client {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	client, ok := clients[name]
+	if !ok {
+		client = create(name, &f.cfg)
+		clients[name] = client
+	}
+	return client
+}
+
+// New creates an HTTP client whose transport retries idempotent requests, breaks the
+// circuit of failing hosts, propagates the trace context and records per-host metrics
+func New(name string, cfg *config.HTTPClientConfig) *http.Client {
+	base := newBaseTransport(cfg, nil)
+	base.Proxy = http.ProxyFromEnvironment
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: NewTransport(name, cfg, base),
+	}
+}
+
+// NewPublic creates a client like New that refuses to connect to loopback,
+// private, link-local and unspecified addresses, checked after DNS resolution
+// and on every redirect. It bypasses the environment proxy, whose address
+// would be checked instead of the destination.
+func NewPublic(name string, cfg *config.HTTPClientConfig) *http.Client {
+	return &http.Client{
+		Timeout:       cfg.Timeout,
+		Transport:     NewTransport(name, cfg, newBaseTransport(cfg, dialPublic)),
+		CheckRedirect: checkPublicRedirect,
+	}
+}
+
+// newBaseTransport creates the transport of the clients of cfg, whose dialer
+// runs control on each resolved address before connecting
+func newBaseTransport(cfg *config.HTTPClientConfig, control func(network, address string, c syscall.RawConn) error) *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout:   cfg.DialTimeout,
+			KeepAlive: 30 * time.Second,
+			Control:   control,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSHandshakeTimeout:   cfg.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: cfg.ResponseHeaderTimeout,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+	}
+}
+
+// Transport is an http.RoundTripper adding retries, circuit breaking, trace propagation
+// and metrics to a base transport. Transport failures are returned as ThirdPartyError codes.
+type Transport struct {
+	name    string
+	base    http.RoundTripper
+	retry   config.HTTPClientRetryConfig
+	breaker config.HTTPClientBreakerConfig
+
+	mu       sync.Mutex
+	breakers map[string]*circuitBreaker
+}
+
+// NewTransport wraps base, which defaults to http.DefaultTransport
+func NewTransport(name string, cfg *config.HTTPClientConfig, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &Transport{
+		name:     name,
+		base:     base,
+		retry:    cfg.Retry,
+		breaker:  cfg.CircuitBreaker,
+		breakers: make(map[string]*circuitBreaker),
+	}
+}
+
+// RoundTrip sends the request, retrying idempotent requests on network errors and
+// retryable statuses while the circuit of the host is closed
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	host := req.URL.Host
+	breaker := t.circuitBreaker(host)
+
+	// RoundTrip must not modify the caller's request
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	attempts := 1
+	if t.retry.MaxAttempts > 1 && replayable(req) {
+		attempts = t.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		if !breaker.allow() {
+			return nil, newTransportError(t.name, host, ErrCircuitOpen)
+		}
+		if attempt > 1 {
+			if err := rewindBody(req); err != nil {
+				return nil, newTransportError(t.name, host, err)
+			}
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		observeRequest(t.name, host, req.Method, resp, err, time.Since(start))
+		breaker.record(err != nil || resp.StatusCode >= http.StatusInternalServerError)
+
+		if attempt >= attempts || ctx.Err() != nil || !retryable(resp, err) {
+			if err != nil {
+				return nil, newTransportError(t.name, host, err)
+			}
+			return resp, nil
+		}
+
+		wait := t.backoff(attempt, resp)
+		if resp != nil {
+			drain(resp)
+		}
+		logger.Debug("Retrying %s request to %s in %s (attempt %d/%d)", t.name, host, wait, attempt+1, attempts)
+		retriesTotal.WithLabelValues(t.name, host).Inc()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, newTransportError(t.name, host, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// circuitBreaker returns the breaker of host, creating it on first use
+func (t *Transport) circuitBreaker(host string) *circuitBreaker {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	b, ok := t.breakers[host]
+	if !ok {
+		b = newCircuitBreaker(t.breaker, func(state string) {
+			logger.Warn("Circuit of %s client to %s is %s", t.name, host, state)
+			circuitState.WithLabelValues(t.name, host).Set(stateValue(state))
+		})
+		t.breakers[host] = b
+	}
+	return b
+}
+
+// backoff returns the delay before the next attempt: the Retry-After of the response when
+// present, otherwise exponential backoff with full jitter, both capped at the max backoff
+func (t *Transport) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if t.retry.MaxBackoff > 0 && wait > t.retry.MaxBackoff {
+				wait = t.retry.MaxBackoff
+			}
+			return wait
+		}
+	}
+
+	ceiling := t.retry.InitialBackoff << (attempt - 1)
+	if ceiling <= 0 || (t.retry.MaxBackoff > 0 && ceiling > t.retry.MaxBackoff) {
+		ceiling = t.retry.MaxBackoff
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(ceiling) + 1))
+}
+
+// replayable reports whether the request may be sent again: its method is idempotent
+// or it carries an idempotency key, and its body can be rewound
+func replayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != "" || req.Header.Get("X-Idempotency-Key") != ""
+}
+
+// retryable reports whether the outcome of an attempt is worth retrying
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// rewindBody replaces the consumed request body with a fresh copy
+func rewindBody(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		wait := time.Until(at)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// drain discards a response that is not returned to the caller
+func drain(resp *http.Response) {
+	_, _ = io.CopyN(io.Discard, resp.Body, maxDrainBytes)
+	resp.Body.Close()
+}
@@ -0,0 +1,71 @@
+package httpclient
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	// Outbound request counter; status is "error" when no response was received
+	requestsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_requests_total",
+			Help: "Total number of outbound HTTP request attempts",
+		},
+		[]string{"client", "host", "method", "status"},
+	)
+
+	// Outbound request duration histogram
+	requestDuration = promauto.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "http_client_request_duration_seconds",
+			Help:    "Outbound HTTP request attempt duration in seconds",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"client", "host"},
+	)
+
+	// Outbound retry counter
+	retriesTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_client_retries_total",
+			Help: "Total number of retried outbound HTTP requests",
+		},
+		[]string{"client", "host"},
+	)
+
+	// Circuit state gauge: 0 closed, 1 half open, 2 open
+	circuitState = promauto.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "http_client_circuit_state",
+			Help: "Circuit breaker state per host: 0 closed, 1 half open, 2 open",
+		},
+		[]string{"client", "host"},
+	)
+)
+
+// observeRequest records the outcome of one attempt
+func observeRequest(client, host, method string, resp *http.Response, err error, duration time.Duration) {
+	status := "error"
+	if err == nil {
+		status = strconv.Itoa(resp.StatusCode)
+	}
+	requestsTotal.WithLabelValues(client, host, method, status).Inc()
+	requestDuration.WithLabelValues(client, host).Observe(duration.Seconds())
+}
+
+// stateValue returns the gauge value of a circuit state
+func stateValue(state string) float64 {
+	switch state {
+	case StateHalfOpen:
+		return 1
+	case StateOpen:
+		return 2
+	default:
+		return 0
+	}
+}
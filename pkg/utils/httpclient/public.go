@@ -0,0 +1,53 @@
+package httpclient
+
+import (
+	stderrors "errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+)
+
+// maxRedirects is how many redirects a public client follows, as net/http does by default
+const maxRedirects = 10
+
+// ErrAddressNotPublic is returned when a public client would connect to an
+// address that is not routable on the internet
+var ErrAddressNotPublic = stderrors.New("address is not public")
+
+// PublicIP reports whether ip may be reached by a public client: it is neither
+// loopback, private, link-local, multicast nor unspecified
+func PublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast())
+}
+
+// dialPublic is the Control of the dialer of public clients. It runs on the
+// resolved address of every connection, so host names resolving to internal
+// addresses and redirects to them are rejected too.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !PublicIP(ip) {
+		return fmt.Errorf("%w: %s", ErrAddressNotPublic, host)
+	}
+	return nil
+}
+
+// checkPublicRedirect is the CheckRedirect of public clients: redirects stay
+// on http(s) and literal internal addresses are rejected before dialing
+func checkPublicRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("redirect to unsupported scheme %q", req.URL.Scheme)
+	}
+	if ip := net.ParseIP(req.URL.Hostname()); ip != nil && !PublicIP(ip) {
+		return fmt.Errorf("redirect to %s: %w", req.URL.Host, ErrAddressNotPublic)
+	}
+	return nil
+}
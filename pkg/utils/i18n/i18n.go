@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/make-bin/server-tpl/pkg/utils/container"
 )
 
 // Supported languages
@@ -52,8 +53,22 @@ type Translator interface {
 	GetSupportedLanguages() []string
 	HasTranslation(key string) bool
 	Reload() error
+	// WithLanguage returns a translator bound to lang that shares the loaded translations
+	WithLanguage(lang string) Translator
 }
 
+// TranslatorBeanName is the container name of the request scoped translator
+const TranslatorBeanName = "translator"
+
+// LocalizerBeanName is the container name of the request scoped localizer
+const LocalizerBeanName = "localizer"
+
+// Context keys of the language and time zone preferred by the authenticated user
+const (
+	preferredLanguageKey = "preferred_language"
+	preferredTimeZoneKey = "preferred_time_zone"
+)
+
 // Localizer interface for localization operations
 type Localizer interface {
 	FormatNumber(number interface{}) string
@@ -193,6 +208,24 @@ func (i *I18nManager) HasTranslation(key string) bool {
 	return i.getNestedValue(langTranslations, key) != ""
 }
 
+// WithLanguage returns a translator bound to lang. The returned translator shares
+// the loaded translations, so it is cheap enough to create per request and safe to
+// use concurrently with translators bound to other languages.
+func (i *I18nManager) WithLanguage(lang string) Translator {
+	i.mutex.RLock()
+	defer i.mutex.RUnlock()
+
+	if !isValidLanguage(lang) {
+		lang = i.currentLang
+	}
+	return &I18nManager{
+		translations: i.translations,
+		currentLang:  lang,
+		localesPath:  i.localesPath,
+		timeZone:     i.timeZone,
+	}
+}
+
 // Reload reloads all translations from files
 func (i *I18nManager) Reload() error {
 	i.mutex.Lock()
@@ -360,6 +393,12 @@ func LanguageMiddleware(translator Translator) gin.HandlerFunc {
 	}
 }
 
+// DetectLanguage detects the language of a request from the lang query parameter,
+// the preferred language of the user, the Accept-Language header or the lang cookie
+func DetectLanguage(c *gin.Context) string {
+	return detectLanguage(c)
+}
+
 // detectLanguage detects the language from request
 func detectLanguage(c *gin.Context) string {
 	// 1. Check query parameter
@@ -367,22 +406,57 @@ func detectLanguage(c *gin.Context) string {
 		return lang
 	}
 
-	// 2. Check Accept-Language header
+	// 2. Check the preferred language of the authenticated user
+	if lang := c.GetString(preferredLanguageKey); lang != "" && isValidLanguage(lang) {
+		return lang
+	}
+
+	// 3. Check Accept-Language header
 	if acceptLang := c.GetHeader("Accept-Language"); acceptLang != "" {
 		if lang := parseAcceptLanguage(acceptLang); lang != "" && isValidLanguage(lang) {
 			return lang
 		}
 	}
 
-	// 3. Check cookie
+	// 4. Check cookie
 	if lang, err := c.Cookie("lang"); err == nil && isValidLanguage(lang) {
 		return lang
 	}
 
-	// 4. Return default language
+	// 5. Return default language
 	return DefaultLanguage
 }
 
+// DetectTimeZone returns the preferred time zone of the user, UTC when none is set
+func DetectTimeZone(c *gin.Context) string {
+	if tz := c.GetString(preferredTimeZoneKey); tz != "" {
+		return tz
+	}
+	return "UTC"
+}
+
+// SetPreferences stores the language and time zone preferred by the authenticated
+// user in the request. The language is applied again when LanguageMiddleware
+// already ran; empty values are ignored.
+func SetPreferences(c *gin.Context, language, timeZone string) {
+	if language != "" {
+		c.Set(preferredLanguageKey, language)
+	}
+	if timeZone != "" {
+		c.Set(preferredTimeZoneKey, timeZone)
+	}
+
+	if _, exists := c.Get("language"); exists {
+		lang := detectLanguage(c)
+		c.Set("language", lang)
+		if translator, ok := c.Get("translator"); ok {
+			if t, ok := translator.(Translator); ok {
+				c.Set("translator", t.WithLanguage(lang))
+			}
+		}
+	}
+}
+
 // parseAcceptLanguage parses Accept-Language header
 func parseAcceptLanguage(acceptLang string) string {
 	// Simplified parsing - take the first language
@@ -431,26 +505,51 @@ func isValidLanguage(lang string) bool {
 
 // Helper functions for use in handlers
 
-// T translates a key using the translator from context
-func T(c *gin.Context, key string, args ...interface{}) string {
+// FromContext returns the translator of the request, either set by
+// LanguageMiddleware or resolved from the request scoped translator bean
+func FromContext(c *gin.Context) (Translator, bool) {
 	if translator, exists := c.Get("translator"); exists {
 		if t, ok := translator.(Translator); ok {
-			return t.Translate(key, args...)
+			return t, true
 		}
 	}
+	if c.Request == nil {
+		return nil, false
+	}
+	t, err := container.Scoped[Translator](c.Request.Context(), TranslatorBeanName)
+	if err != nil {
+		return nil, false
+	}
+	return t, true
+}
+
+// T translates a key using the translator from context
+func T(c *gin.Context, key string, args ...interface{}) string {
+	if t, ok := FromContext(c); ok {
+		return t.Translate(key, args...)
+	}
 	return key
 }
 
 // TWithLang translates a key using a specific language
 func TWithLang(c *gin.Context, lang, key string, args ...interface{}) string {
-	if translator, exists := c.Get("translator"); exists {
-		if t, ok := translator.(Translator); ok {
-			return t.TranslateWithLang(lang, key, args...)
-		}
+	if t, ok := FromContext(c); ok {
+		return t.TranslateWithLang(lang, key, args...)
 	}
 	return key
 }
 
+// LocalizerFromContext returns the localizer of the request, resolved from the
+// request scoped localizer bean or built from the language and time zone of the request
+func LocalizerFromContext(c *gin.Context) Localizer {
+	if c.Request != nil {
+		if l, err := container.Scoped[Localizer](c.Request.Context(), LocalizerBeanName); err == nil {
+			return l
+		}
+	}
+	return NewLocalizer(GetLanguage(c), DetectTimeZone(c))
+}
+
 // GetLanguage returns the current language from context
 func GetLanguage(c *gin.Context) string {
 	if lang, exists := c.Get("language"); exists {
@@ -458,5 +557,8 @@ func GetLanguage(c *gin.Context) string {
 			return langStr
 		}
 	}
+	if t, ok := FromContext(c); ok {
+		return t.GetLanguage()
+	}
 	return DefaultLanguage
 }
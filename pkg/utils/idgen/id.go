@@ -0,0 +1,86 @@
+package idgen
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+)
+
+// ID is an entity ID in API types. It is encoded in JSON as a number with
+// auto-increment IDs and as a string with generated IDs, which exceed the
+// integers JavaScript represents exactly. Both forms are decoded.
+type ID uint
+
+// hidden reports whether responses omit internal IDs
+var hidden atomic.Bool
+
+// Hide sets whether responses omit internal IDs, which leaves the public IDs
+// of entities to identify them
+func Hide(h bool) {
+	hidden.Store(h)
+}
+
+// Expose returns id as an API ID, or 0 so that omitempty fields are omitted
+// when internal IDs are hidden
+func Expose(id uint) ID {
+	if hidden.Load() {
+		return 0
+	}
+	return ID(id)
+}
+
+// MarshalJSON encodes id as a number, or a string when IDs are generated
+func (id ID) MarshalJSON() ([]byte, error) {
+	b := strconv.AppendUint(nil, uint64(id), 10)
+	if Sequential() {
+		return b, nil
+	}
+	return strconv.AppendQuote(nil, string(b)), nil
+}
+
+// UnmarshalJSON decodes a number or a string of digits
+func (id *ID) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	s := string(data)
+	if len(data) >= 2 && data[0] == '"' && data[len(data)-1] == '"' {
+		s = string(data[1 : len(data)-1])
+	}
+	v, err := strconv.ParseUint(s, 10, 0)
+	if err != nil {
+		return fmt.Errorf("idgen: invalid ID %s", data)
+	}
+	*id = ID(v)
+	return nil
+}
+
+// String returns id in decimal
+func (id ID) String() string {
+	return strconv.FormatUint(uint64(id), 10)
+}
+
+// IDs converts ids to API IDs
+func IDs(ids []uint) []ID {
+	if ids == nil {
+		return nil
+	}
+	out := make([]ID, len(ids))
+	for i, id := range ids {
+		out[i] = ID(id)
+	}
+	return out
+}
+
+// Uints converts API IDs to entity IDs
+func Uints(ids []ID) []uint {
+	if ids == nil {
+		return nil
+	}
+	out := make([]uint, len(ids))
+	for i, id := range ids {
+		out[i] = uint(id)
+	}
+	return out
+}
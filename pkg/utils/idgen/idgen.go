@@ -0,0 +1,75 @@
+// Package idgen generates the primary keys of entities. The strategy is chosen
+// with database.id_strategy:
+//
+//   - auto_increment: the datastore allocates sequential IDs (the default)
+//   - snowflake: 63-bit IDs made of a millisecond timestamp, the node ID of the
+//     instance and a sequence, unique across instances with distinct node IDs
+//   - ulid: 63-bit IDs with the layout of a ULID, a 48-bit millisecond
+//     timestamp followed by 15 random bits, monotonic within a process
+//
+// Generated IDs are ordered by creation time and do not reveal how many
+// entities exist. They fit the integer keys of every datastore.
+package idgen
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+// ID strategies
+const (
+	StrategyAutoIncrement = "auto_increment"
+	StrategySnowflake     = "snowflake"
+	StrategyULID          = "ulid"
+)
+
+// Generator generates unique IDs
+type Generator interface {
+	// NextID returns a new ID, never 0
+	NextID() uint64
+}
+
+// New returns the generator of strategy, nil for auto_increment and an empty
+// strategy. node is the snowflake node ID of the instance.
+func New(strategy string, node int64, clk clock.Clock) (Generator, error) {
+	switch strategy {
+	case "", StrategyAutoIncrement:
+		return nil, nil
+	case StrategySnowflake:
+		return NewSnowflake(node, clk)
+	case StrategyULID:
+		return NewULID(clk), nil
+	}
+	return nil, fmt.Errorf("idgen: unknown strategy %q", strategy)
+}
+
+// holder wraps the process generator, which may be nil
+type holder struct {
+	generator Generator
+}
+
+var current atomic.Pointer[holder]
+
+// Use sets the generator of the process, nil restores auto-increment IDs. It is
+// process wide because entities get their IDs in model hooks and datastores
+// that have no access to the container.
+func Use(g Generator) {
+	current.Store(&holder{generator: g})
+}
+
+// Next returns a new ID from the process generator, or 0 when the datastore
+// allocates sequential IDs
+func Next() uint {
+	if h := current.Load(); h != nil && h.generator != nil {
+		return uint(h.generator.NextID())
+	}
+	return 0
+}
+
+// Sequential reports whether IDs are allocated by the datastore
+func Sequential() bool {
+	h := current.Load()
+	return h == nil || h.generator == nil
+}
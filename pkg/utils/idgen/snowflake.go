@@ -0,0 +1,65 @@
+package idgen
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+// Snowflake ID layout: 41 bits of milliseconds since SnowflakeEpoch, 10 bits of
+// node ID and 12 bits of sequence
+const (
+	snowflakeNodeBits     = 10
+	snowflakeSequenceBits = 12
+
+	// MaxNode is the largest snowflake node ID
+	MaxNode = 1<<snowflakeNodeBits - 1
+
+	maxSequence = 1<<snowflakeSequenceBits - 1
+)
+
+// SnowflakeEpoch is the start of snowflake timestamps, which last 69 years
+var SnowflakeEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake generates snowflake IDs for one node
+type Snowflake struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	node  uint64
+	// last is the timestamp of the last ID, seq its sequence
+	last int64
+	seq  uint64
+}
+
+// NewSnowflake creates a snowflake generator for node, between 0 and MaxNode.
+// Timestamps are read from clk.
+func NewSnowflake(node int64, clk clock.Clock) (*Snowflake, error) {
+	if node < 0 || node > MaxNode {
+		return nil, fmt.Errorf("idgen: snowflake node %d is not between 0 and %d", node, MaxNode)
+	}
+	return &Snowflake{clock: clk, node: uint64(node)}, nil
+}
+
+// NextID returns a new ID. When the clock moves backwards or the sequence of a
+// millisecond is exhausted, IDs continue from the last timestamp instead of
+// waiting, so they never repeat.
+func (s *Snowflake) NextID() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ms := s.clock.Now().Sub(SnowflakeEpoch).Milliseconds()
+	if ms <= s.last {
+		ms = s.last
+		s.seq++
+		if s.seq > maxSequence {
+			ms++
+			s.seq = 0
+		}
+	} else {
+		s.seq = 0
+	}
+	s.last = ms
+	return uint64(ms)<<(snowflakeNodeBits+snowflakeSequenceBits) | s.node<<snowflakeSequenceBits | s.seq
+}
@@ -0,0 +1,49 @@
+package idgen
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/utils/clock"
+)
+
+// ulidRandomBits is the number of random bits after the 48-bit timestamp,
+// keeping IDs within 63 bits
+const ulidRandomBits = 15
+
+// ULID generates IDs with the layout of a ULID truncated to 63 bits: the
+// milliseconds since the Unix epoch followed by random bits. Unlike snowflake
+// IDs they need no node ID, and IDs of different instances created in the
+// same millisecond differ by their random bits only.
+type ULID struct {
+	mu    sync.Mutex
+	clock clock.Clock
+	last  uint64
+}
+
+// NewULID creates a ULID generator, timestamps are read from clk
+func NewULID(clk clock.Clock) *ULID {
+	return &ULID{clock: clk}
+}
+
+// NextID returns a new ID. Within a millisecond, and when the clock moves
+// backwards, IDs increase by one from the last ID as with monotonic ULIDs.
+func (u *ULID) NextID() uint64 {
+	var random [2]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		// crypto/rand does not fail on supported platforms
+		panic(err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	ms := uint64(u.clock.Now().UnixMilli())
+	id := ms<<ulidRandomBits | uint64(binary.BigEndian.Uint16(random[:]))&(1<<ulidRandomBits-1)
+	if id>>ulidRandomBits <= u.last>>ulidRandomBits {
+		id = u.last + 1
+	}
+	u.last = id
+	return id
+}
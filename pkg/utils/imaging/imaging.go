@@ -0,0 +1,311 @@
+// Package imaging strips metadata from JPEG and PNG images and renders
+// thumbnails with the standard library codecs.
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Content types of the supported image formats
+const (
+	ContentTypeJPEG = "image/jpeg"
+	ContentTypePNG  = "image/png"
+	ContentTypeGIF  = "image/gif"
+)
+
+// Common errors
+var (
+	ErrInvalidImage = errors.New("invalid image")
+	ErrTooLarge     = errors.New("image has too many pixels")
+)
+
+// Supported reports whether images of contentType, a media type without
+// parameters, can be decoded
+func Supported(contentType string) bool {
+	switch contentType {
+	case ContentTypeJPEG, ContentTypePNG, ContentTypeGIF:
+		return true
+	}
+	return false
+}
+
+// Decode decodes the first frame of an image, refusing images of more than
+// maxPixels pixels, 0 for no limit, before allocating them
+func Decode(data []byte, maxPixels int64) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, ErrTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return img, nil
+}
+
+// Encode writes img as a JPEG of the given quality when contentType is JPEG,
+// and as a PNG otherwise, returning the content type written
+func Encode(w io.Writer, img image.Image, contentType string, quality int) (string, error) {
+	if contentType == ContentTypeJPEG {
+		return ContentTypeJPEG, jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+	}
+	return ContentTypePNG, png.Encode(w, img)
+}
+
+// Thumbnail scales img down to fit in a size by size square, keeping its
+// aspect ratio. Each output pixel averages the input pixels it covers. Images
+// that already fit are returned unchanged.
+func Thumbnail(img image.Image, size int) image.Image {
+	b := img.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+	if size <= 0 || sw <= size && sh <= size {
+		return img
+	}
+	dw, dh := size, size
+	if sw > sh {
+		dh = max(1, sh*size/sw)
+	} else {
+		dw = max(1, sw*size/sh)
+	}
+
+	// Premultiplied 16 bit channel sums and pixel counts per output pixel
+	sums := make([]uint64, dw*dh*4)
+	counts := make([]uint32, dw*dh)
+	for y := 0; y < sh; y++ {
+		row := (y * dh / sh) * dw
+		for x := 0; x < sw; x++ {
+			i := row + x*dw/sw
+			r, g, bl, a := img.At(b.Min.X+x, b.Min.Y+y).RGBA()
+			sums[i*4] += uint64(r)
+			sums[i*4+1] += uint64(g)
+			sums[i*4+2] += uint64(bl)
+			sums[i*4+3] += uint64(a)
+			counts[i]++
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for i, n := range counts {
+		if n == 0 {
+			continue
+		}
+		for c := 0; c < 4; c++ {
+			dst.Pix[i*4+c] = uint8(sums[i*4+c] / uint64(n) >> 8)
+		}
+	}
+	return dst
+}
+
+// Orient turns img upright according to an EXIF orientation between 1 and 8,
+// as returned by Orientation
+func Orient(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	// Orientations 5 to 8 swap the width and the height
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2: // mirror horizontally
+				sx, sy = w-1-x, y
+			case 3: // rotate 180°
+				sx, sy = w-1-x, h-1-y
+			case 4: // mirror vertically
+				sx, sy = x, h-1-y
+			case 5: // transpose
+				sx, sy = y, x
+			case 6: // rotate 90° clockwise
+				sx, sy = y, h-1-x
+			case 7: // transverse
+				sx, sy = w-1-y, h-1-x
+			case 8: // rotate 90° counterclockwise
+				sx, sy = w-1-y, x
+			}
+			dst.Set(x, y, color.RGBAModel.Convert(img.At(b.Min.X+sx, b.Min.Y+sy)))
+		}
+	}
+	return dst
+}
+
+// Orientation returns the EXIF orientation of a JPEG image, 1 (upright) when
+// it has none or is not a JPEG
+func Orientation(data []byte) int {
+	orientation := 1
+	_ = walkJPEG(data, func(marker byte, segment []byte) bool {
+		if marker == 0xE1 && bytes.HasPrefix(segment, exifHeader) {
+			if o := exifOrientation(segment[len(exifHeader):]); o != 0 {
+				orientation = o
+			}
+			return false
+		}
+		return true
+	})
+	return orientation
+}
+
+// StripMetadata removes the metadata of a JPEG or PNG image without
+// re-encoding its pixels: EXIF, XMP and IPTC segments and comments of JPEG
+// images, and EXIF, text and time chunks of PNG images. Color profiles are
+// kept. Images of other types are returned unchanged.
+func StripMetadata(data []byte, contentType string) ([]byte, error) {
+	switch contentType {
+	case ContentTypeJPEG:
+		return stripJPEG(data)
+	case ContentTypePNG:
+		return stripPNG(data)
+	}
+	return data, nil
+}
+
+// exifHeader starts the APP1 segments holding EXIF data
+var exifHeader = []byte("Exif\x00\x00")
+
+// stripJPEG copies the segments of a JPEG image except the metadata ones,
+// then the entropy-coded data from the first start of scan on
+func stripJPEG(data []byte) ([]byte, error) {
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(data[:min(2, len(data))])
+	rest := 2
+	err := walkJPEG(data, func(marker byte, segment []byte) bool {
+		start := rest
+		rest += 4 + len(segment)
+		if keepJPEGSegment(marker, segment) {
+			out.Write(data[start:rest])
+		}
+		return marker != 0xDA
+	})
+	if err != nil {
+		return nil, err
+	}
+	out.Write(data[rest:])
+	return out.Bytes(), nil
+}
+
+// keepJPEGSegment reports whether a segment is kept by stripJPEG: all but the
+// application segments, except JFIF (APP0), ICC profiles (APP2) and Adobe
+// color transforms (APP14), and comments
+func keepJPEGSegment(marker byte, segment []byte) bool {
+	switch {
+	case marker == 0xFE:
+		return false
+	case marker == 0xE0, marker == 0xEE:
+		return true
+	case marker == 0xE2:
+		return bytes.HasPrefix(segment, []byte("ICC_PROFILE\x00"))
+	case marker > 0xE0 && marker <= 0xEF:
+		return false
+	}
+	return true
+}
+
+// walkJPEG calls fn with the marker and payload of each segment of a JPEG
+// image up to and including the first start of scan, until fn returns false.
+// Segments follow one another without fill bytes.
+func walkJPEG(data []byte, fn func(marker byte, segment []byte) bool) error {
+	if len(data) < 2 || data[0] != 0xFF || data[1] != 0xD8 {
+		return ErrInvalidImage
+	}
+	for i := 2; ; {
+		if i+4 > len(data) || data[i] != 0xFF {
+			return ErrInvalidImage
+		}
+		marker := data[i+1]
+		length := int(binary.BigEndian.Uint16(data[i+2:]))
+		if length < 2 || i+2+length > len(data) {
+			return ErrInvalidImage
+		}
+		if !fn(marker, data[i+4:i+2+length]) || marker == 0xDA {
+			return nil
+		}
+		i += 2 + length
+	}
+}
+
+// exifOrientation reads the orientation tag of the first image file
+// directory of TIFF-structured EXIF data, or returns 0
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+	var order binary.ByteOrder
+	switch string(tiff[:4]) {
+	case "II*\x00":
+		order = binary.LittleEndian
+	case "MM\x00*":
+		order = binary.BigEndian
+	default:
+		return 0
+	}
+	ifd := int(order.Uint32(tiff[4:]))
+	if ifd+2 > len(tiff) || ifd < 8 {
+		return 0
+	}
+	entries := int(order.Uint16(tiff[ifd:]))
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 0
+		}
+		// The orientation is a SHORT held in the value field
+		if order.Uint16(tiff[entry:]) == 0x0112 && order.Uint16(tiff[entry+2:]) == 3 {
+			if o := int(order.Uint16(tiff[entry+8:])); o >= 1 && o <= 8 {
+				return o
+			}
+			return 0
+		}
+	}
+	return 0
+}
+
+// pngSignature starts every PNG image
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// pngMetadataChunks are the chunks removed by stripPNG
+var pngMetadataChunks = map[string]bool{"eXIf": true, "tEXt": true, "zTXt": true, "iTXt": true, "tIME": true}
+
+// stripPNG copies the chunks of a PNG image except the metadata ones
+func stripPNG(data []byte) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, ErrInvalidImage
+	}
+	out := bytes.NewBuffer(make([]byte, 0, len(data)))
+	out.Write(pngSignature)
+	for i := len(pngSignature); i < len(data); {
+		if i+8 > len(data) {
+			return nil, ErrInvalidImage
+		}
+		length := int(binary.BigEndian.Uint32(data[i:]))
+		end := i + 12 + length
+		if length < 0 || end > len(data) {
+			return nil, ErrInvalidImage
+		}
+		if !pngMetadataChunks[string(data[i+4:i+8])] {
+			out.Write(data[i:end])
+		}
+		if string(data[i+4:i+8]) == "IEND" {
+			break
+		}
+		i = end
+	}
+	return out.Bytes(), nil
+}
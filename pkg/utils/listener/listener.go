@@ -0,0 +1,498 @@
+// Package listener provides the listening sockets of the server for zero
+// downtime deploys. A socket is, in order of preference, handed over by the
+// previous process of the binary during an upgrade, passed by systemd socket
+// activation, or created, with SO_REUSEPORT when configured. Unix sockets are
+// supported next to TCP sockets, e.g. for a local reverse proxy or sidecar.
+//
+// During an upgrade the running process starts the new binary with its
+// sockets as extra files and waits until the new process calls Ready. The old
+// process then stops accepting and drains its connections while the new one
+// accepts on the same sockets, so no connection is refused.
+package listener
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+	"github.com/make-bin/server-tpl/pkg/utils/logger"
+)
+
+const (
+	// envListeners lists the escaped network:address of the sockets handed
+	// over by the parent, one per file descriptor from firstFD
+	envListeners = "SERVER_UPGRADE_LISTENERS"
+	// envReadyFD is the file descriptor the child writes a byte to once it serves
+	envReadyFD = "SERVER_UPGRADE_READY_FD"
+	// firstFD is the first passed file descriptor, after stdin, stdout and stderr
+	firstFD = 3
+)
+
+var (
+	// ErrUpgradeDisabled is returned by Upgrade when upgrades are not configured
+	ErrUpgradeDisabled = errors.New("socket upgrades are disabled")
+	// ErrUpgradeInProgress is returned by Upgrade while another upgrade runs
+	ErrUpgradeInProgress = errors.New("an upgrade is already in progress")
+)
+
+// inheritedListener is a socket passed by the parent process or systemd
+type inheritedListener struct {
+	key string // network:address the parent listened on, empty for systemd sockets
+	ln  net.Listener
+}
+
+// activeListener is a socket in use, handed over on upgrade
+type activeListener struct {
+	key string
+	ln  net.Listener
+}
+
+// listenerKey identifies the socket listening on address in the handover
+func listenerKey(network, address string) string {
+	return network + ":" + address
+}
+
+// Manager provides the listening sockets of the process and hands them over
+// to a new process on upgrade
+type Manager struct {
+	cfg config.SocketConfig
+
+	mu        sync.Mutex
+	inherited []inheritedListener
+	active    []activeListener
+	readyFile *os.File
+
+	upgrading bool
+	upgraded  chan struct{}
+	readyOnce sync.Once
+}
+
+// New creates a manager, taking over the sockets passed by a parent process
+// or, when cfg.Systemd is set, by systemd socket activation
+func New(cfg *config.SocketConfig) (*Manager, error) {
+	m := &Manager{cfg: *cfg, upgraded: make(chan struct{})}
+
+	if err := m.inheritFromParent(); err != nil {
+		return nil, err
+	}
+	if len(m.inherited) == 0 && cfg.Systemd {
+		if err := m.inheritFromSystemd(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// inheritFromParent takes over the sockets handed over by an upgrading parent
+func (m *Manager) inheritFromParent() error {
+	addrs := os.Getenv(envListeners)
+	readyFD := os.Getenv(envReadyFD)
+	os.Unsetenv(envListeners)
+	os.Unsetenv(envReadyFD)
+	if addrs == "" {
+		return nil
+	}
+
+	for i, escaped := range strings.Split(addrs, ",") {
+		key, err := url.QueryUnescape(escaped)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", envListeners, err)
+		}
+		ln, err := fileListener(firstFD+i, "upgrade-"+key)
+		if err != nil {
+			return err
+		}
+		// The socket file now belongs to this process, see Upgrade
+		if unixLn, ok := ln.(*net.UnixListener); ok {
+			unixLn.SetUnlinkOnClose(true)
+		}
+		m.inherited = append(m.inherited, inheritedListener{key: key, ln: ln})
+	}
+	if readyFD != "" {
+		fd, err := strconv.Atoi(readyFD)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", envReadyFD, err)
+		}
+		m.readyFile = os.NewFile(uintptr(fd), "upgrade-ready")
+	}
+	logger.Info("Inherited %d socket(s) from the parent process", len(m.inherited))
+	return nil
+}
+
+// inheritFromSystemd takes over the sockets passed by systemd socket
+// activation, see sd_listen_fds(3)
+func (m *Manager) inheritFromSystemd() error {
+	pid, count := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+	if pid != strconv.Itoa(os.Getpid()) || count == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return fmt.Errorf("invalid LISTEN_FDS: %w", err)
+	}
+	for i := 0; i < n; i++ {
+		ln, err := fileListener(firstFD+i, "systemd")
+		if err != nil {
+			return err
+		}
+		m.inherited = append(m.inherited, inheritedListener{ln: ln})
+	}
+	logger.Info("Using %d socket(s) from systemd socket activation", n)
+	return nil
+}
+
+// fileListener creates a listener from a passed file descriptor
+func fileListener(fd int, name string) (net.Listener, error) {
+	f := os.NewFile(uintptr(fd), name)
+	if f == nil {
+		return nil, fmt.Errorf("file descriptor %d of %s is not open", fd, name)
+	}
+	defer f.Close()
+
+	ln, err := net.FileListener(f)
+	if err != nil {
+		return nil, fmt.Errorf("file descriptor %d of %s is not a listening socket: %w", fd, name, err)
+	}
+	return ln, nil
+}
+
+// Listen returns a listener on address, a host:port for the tcp network or a
+// socket path for the unix network. An inherited socket is used when one
+// listens on address, or on the port or path of address for systemd sockets;
+// otherwise a new socket is created and, for unix sockets, given mode unless
+// it is 0. Connections accepted on unix sockets report 127.0.0.1 as their
+// remote address, so that proxy headers are trusted like from a local proxy.
+func (m *Manager) Listen(network, address string, mode os.FileMode) (net.Listener, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ln, err := m.takeInherited(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if ln == nil {
+		switch network {
+		case "tcp":
+			ln, err = m.listenTCP(address)
+		case "unix":
+			ln, err = listenUnix(address, mode)
+		default:
+			err = fmt.Errorf("unsupported network %q", network)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	m.active = append(m.active, activeListener{key: listenerKey(network, address), ln: ln})
+	if network == "unix" {
+		return loopbackListener{ln}, nil
+	}
+	return ln, nil
+}
+
+// listenTCP creates a TCP socket, with SO_REUSEPORT when configured
+func (m *Manager) listenTCP(address string) (net.Listener, error) {
+	lc := net.ListenConfig{}
+	if m.cfg.ReusePort {
+		lc.Control = reusePort
+	}
+	return lc.Listen(context.Background(), "tcp", address)
+}
+
+// listenUnix creates a unix socket at path, replacing the socket file a
+// crashed process left behind, and sets its permissions
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	if info, err := os.Lstat(path); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s exists and is not a socket", path)
+		}
+		if conn, err := net.Dial("unix", path); err == nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("%s is in use by another process", path)
+		}
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket %s: %w", path, err)
+		}
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if mode != 0 {
+		if err := os.Chmod(path, mode); err != nil {
+			_ = ln.Close()
+			return nil, fmt.Errorf("failed to set the permissions of %s: %w", path, err)
+		}
+	}
+	return ln, nil
+}
+
+// takeInherited removes and returns the inherited socket matching address, or nil
+func (m *Manager) takeInherited(network, address string) (net.Listener, error) {
+	key := listenerKey(network, address)
+	for i, inherited := range m.inherited {
+		if inherited.key == key {
+			m.inherited = append(m.inherited[:i], m.inherited[i+1:]...)
+			return inherited.ln, nil
+		}
+	}
+
+	var want *net.TCPAddr
+	if network == "tcp" {
+		var err error
+		if want, err = net.ResolveTCPAddr("tcp", address); err != nil {
+			return nil, err
+		}
+	}
+	for i, inherited := range m.inherited {
+		if inherited.key != "" || !systemdMatches(inherited.ln.Addr(), want, address) {
+			continue
+		}
+		m.inherited = append(m.inherited[:i], m.inherited[i+1:]...)
+		return inherited.ln, nil
+	}
+	return nil, nil
+}
+
+// systemdMatches reports whether a systemd socket listening on got serves the
+// TCP address want, or the unix socket path when want is nil
+func systemdMatches(got net.Addr, want *net.TCPAddr, path string) bool {
+	switch got := got.(type) {
+	case *net.TCPAddr:
+		if want == nil || got.Port != want.Port {
+			return false
+		}
+		return want.IP == nil || want.IP.IsUnspecified() || want.IP.Equal(got.IP)
+	case *net.UnixAddr:
+		return want == nil && got.Name == path
+	default:
+		return false
+	}
+}
+
+// loopbackListener accepts connections reporting 127.0.0.1 as remote address.
+// Unix socket peers have no address, which net/http and Gin cannot parse.
+type loopbackListener struct {
+	net.Listener
+}
+
+// Accept implements net.Listener
+func (l loopbackListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return loopbackConn{conn}, nil
+}
+
+// loopbackConn is a connection accepted by loopbackListener
+type loopbackConn struct {
+	net.Conn
+}
+
+// RemoteAddr implements net.Conn
+func (c loopbackConn) RemoteAddr() net.Addr {
+	return &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1)}
+}
+
+// Ready reports that the process serves: an upgrading parent starts shutting
+// down, systemd is notified when NOTIFY_SOCKET is set and the PID file is
+// written. Sockets inherited but not listened on are closed.
+func (m *Manager) Ready() error {
+	var errs []error
+	m.readyOnce.Do(func() {
+		m.mu.Lock()
+		for _, inherited := range m.inherited {
+			_ = inherited.ln.Close()
+		}
+		m.inherited = nil
+		readyFile := m.readyFile
+		m.readyFile = nil
+		m.mu.Unlock()
+
+		if m.cfg.PIDFile != "" {
+			errs = append(errs, writePIDFile(m.cfg.PIDFile))
+		}
+		if m.cfg.Systemd {
+			errs = append(errs, notifySystemd(fmt.Sprintf("READY=1\nMAINPID=%d", os.Getpid())))
+		}
+		if readyFile != nil {
+			if _, err := readyFile.Write([]byte{1}); err != nil {
+				errs = append(errs, fmt.Errorf("failed to notify the parent process: %w", err))
+			}
+			_ = readyFile.Close()
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// Upgraded is closed once a new process took over the sockets; the process
+// should then shut down gracefully
+func (m *Manager) Upgraded() <-chan struct{} {
+	return m.upgraded
+}
+
+// Upgrade starts the current binary with the same arguments, hands it the
+// sockets in use and waits until it calls Ready. It fails when the new process
+// exits or is not ready within the upgrade timeout, in which case the new
+// process is killed and the current one keeps serving.
+func (m *Manager) Upgrade(ctx context.Context) error {
+	if !m.cfg.Upgrade {
+		return ErrUpgradeDisabled
+	}
+
+	m.mu.Lock()
+	if m.upgrading {
+		m.mu.Unlock()
+		return ErrUpgradeInProgress
+	}
+	select {
+	case <-m.upgraded:
+		m.mu.Unlock()
+		return ErrUpgradeInProgress
+	default:
+	}
+	m.upgrading = true
+	active := append([]activeListener(nil), m.active...)
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		m.upgrading = false
+		m.mu.Unlock()
+	}()
+
+	cmd, ready, err := m.startChild(active)
+	if err != nil {
+		return err
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	timeout := time.NewTimer(m.cfg.UpgradeTimeout)
+	defer timeout.Stop()
+	select {
+	case <-ready:
+		logger.Info("New process %d took over the sockets", cmd.Process.Pid)
+		// Draining must not remove the socket files the new process serves on
+		for _, a := range active {
+			if unixLn, ok := a.ln.(*net.UnixListener); ok {
+				unixLn.SetUnlinkOnClose(false)
+			}
+		}
+		close(m.upgraded)
+		return nil
+	case err := <-exited:
+		return fmt.Errorf("new process exited before it was ready: %v", err)
+	case <-timeout.C:
+		err = fmt.Errorf("new process was not ready within %s", m.cfg.UpgradeTimeout)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	_ = cmd.Process.Kill()
+	return err
+}
+
+// startChild starts the new process with the sockets of active and returns a
+// channel closed once it is ready
+func (m *Manager) startChild(active []activeListener) (*exec.Cmd, <-chan struct{}, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to locate the executable: %w", err)
+	}
+
+	var files []*os.File
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	keys := make([]string, 0, len(active))
+	for _, a := range active {
+		filer, ok := a.ln.(interface{ File() (*os.File, error) })
+		if !ok {
+			return nil, nil, fmt.Errorf("listener on %s cannot be handed over", a.key)
+		}
+		f, err := filer.File()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to hand over the listener on %s: %w", a.key, err)
+		}
+		files = append(files, f)
+		keys = append(keys, url.QueryEscape(a.key))
+	}
+
+	readyReader, readyWriter, err := os.Pipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	files = append(files, readyWriter)
+
+	cmd := exec.Command(executable, os.Args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(os.Environ(),
+		envListeners+"="+strings.Join(keys, ","),
+		envReadyFD+"="+strconv.Itoa(firstFD+len(files)-1),
+	)
+	if err := cmd.Start(); err != nil {
+		_ = readyReader.Close()
+		return nil, nil, fmt.Errorf("failed to start the new process: %w", err)
+	}
+	logger.Info("Started new process %d, handing over %d socket(s)", cmd.Process.Pid, len(keys))
+
+	// The child writes a byte once ready; the read fails if it exits first
+	ready := make(chan struct{})
+	go func() {
+		defer readyReader.Close()
+		if _, err := readyReader.Read(make([]byte, 1)); err == nil {
+			close(ready)
+		}
+	}()
+	return cmd, ready, nil
+}
+
+// writePIDFile atomically replaces path with the PID of the process
+func writePIDFile(path string) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write PID file: %w", err)
+	}
+	return nil
+}
+
+// notifySystemd sends state to the service manager when NOTIFY_SOCKET is set,
+// see sd_notify(3)
+func notifySystemd(state string) error {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+//go:build !unix || solaris
+
+package listener
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// UpgradeSignal is nil, in-place upgrades are not supported on this platform
+var UpgradeSignal os.Signal
+
+// reusePort fails, SO_REUSEPORT is not supported on this platform
+func reusePort(network, address string, conn syscall.RawConn) error {
+	return errors.New("SO_REUSEPORT is not supported on this platform")
+}
@@ -0,0 +1,25 @@
+//go:build unix && !solaris
+
+package listener
+
+import (
+	"os"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// UpgradeSignal is the signal asking the server to upgrade in place
+var UpgradeSignal os.Signal = syscall.SIGUSR2
+
+// reusePort sets SO_REUSEPORT on a socket before it is bound
+func reusePort(network, address string, conn syscall.RawConn) error {
+	var sockErr error
+	err := conn.Control(func(fd uintptr) {
+		sockErr = unix.SetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_REUSEPORT, 1)
+	})
+	if err != nil {
+		return err
+	}
+	return sockErr
+}
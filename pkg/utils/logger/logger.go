@@ -15,14 +15,15 @@ import (
 // Standard log fields
 const (
 	// Request related fields
-	FieldRequestID    = "request_id"
-	FieldUserID       = "user_id"
-	FieldIP           = "ip"
-	FieldUserAgent    = "user_agent"
-	FieldMethod       = "method"
-	FieldPath         = "path"
-	FieldStatusCode   = "status_code"
-	FieldResponseTime = "response_time"
+	FieldRequestID      = "request_id"
+	FieldUserID         = "user_id"
+	FieldImpersonatorID = "impersonator_id"
+	FieldIP             = "ip"
+	FieldUserAgent      = "user_agent"
+	FieldMethod         = "method"
+	FieldPath           = "path"
+	FieldStatusCode     = "status_code"
+	FieldResponseTime   = "response_time"
 
 	// Business related fields
 	FieldOperation  = "operation"
@@ -127,6 +128,11 @@ func (m *LogManager) WithContext(ctx context.Context) *logrus.Entry {
 		entry = entry.WithField(FieldUserID, userID)
 	}
 
+	// Add impersonator ID if the user is impersonated
+	if impersonatorID := ctx.Value(FieldImpersonatorID); impersonatorID != nil {
+		entry = entry.WithField(FieldImpersonatorID, impersonatorID)
+	}
+
 	return entry
 }
 
@@ -237,6 +243,15 @@ func InitWithConfig(config *LogConfig) {
 	defaultManager = NewManager(config).(*LogManager)
 }
 
+// SetLevel sets the level of the default logger
+func SetLevel(level string) error {
+	if defaultManager == nil {
+		Init(level)
+		return nil
+	}
+	return defaultManager.SetLevel(level)
+}
+
 // GetDefaultLogger returns the default logger
 func GetDefaultLogger() *logrus.Logger {
 	if defaultManager == nil {
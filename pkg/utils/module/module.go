@@ -0,0 +1,177 @@
+// Package module is the registry of the optional subsystems of the template.
+// Each subsystem registers itself with its models; beans owned by a module
+// implement Component. Modules are enabled unless disabled under modules in
+// the configuration, and a disabled module registers no routes, beans or
+// migrations.
+package module
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/make-bin/server-tpl/pkg/utils/config"
+)
+
+// Built-in modules
+const (
+	// Auth is registration, session tokens, impersonation, login throttling and the token denylist
+	Auth = "auth"
+	// Files is file uploads and downloads, resumable uploads and scanning
+	Files = "files"
+	// Webhooks is the webhook notification provider and the login lockout webhook
+	Webhooks = "webhooks"
+	// Admin is the /admin routes: dashboard, container, quotas, network ACL and partners
+	Admin = "admin"
+)
+
+// Module is a subsystem that can be disabled
+type Module struct {
+	Name        string
+	Description string
+	// Version of the module, empty for modules versioned with the binary
+	Version string
+	// Models are the models migrated only while the module is enabled
+	Models []interface{}
+}
+
+// Component is implemented by routes and beans owned by a module, which are
+// left out while the module is disabled
+type Component interface {
+	Module() string
+}
+
+// Info is a registered module and whether it is enabled
+type Info struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+	Enabled     bool   `json:"enabled"`
+}
+
+var (
+	mu       sync.RWMutex
+	modules  = map[string]Module{}
+	disabled = map[string]bool{}
+	// owners maps the model types of modules to their module
+	owners = map[reflect.Type]string{}
+)
+
+// Register makes a module available under its name. It panics when a module
+// of the same name or owning one of the models is already registered.
+func Register(m Module) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := modules[m.Name]; ok {
+		panic(fmt.Sprintf("module %q already registered", m.Name))
+	}
+	for _, model := range m.Models {
+		t := modelType(model)
+		if owner, ok := owners[t]; ok {
+			panic(fmt.Sprintf("model %s of module %q already owned by module %q", t, m.Name, owner))
+		}
+		owners[t] = m.Name
+	}
+	modules[m.Name] = m
+}
+
+// Configure applies the enabled flags of the modules configuration, enabling
+// every module it leaves out. It fails on modules that are not registered.
+func Configure(cfg map[string]config.ModuleConfig) error {
+	mu.Lock()
+	defer mu.Unlock()
+	var unknown []string
+	next := map[string]bool{}
+	for name, moduleCfg := range cfg {
+		if _, ok := modules[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if !moduleCfg.Enabled {
+			next[name] = true
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown modules %s, registered modules are %s",
+			strings.Join(unknown, ", "), strings.Join(names(), ", "))
+	}
+	disabled = next
+	return nil
+}
+
+// Enabled reports whether the module is enabled. Modules that are not
+// registered are enabled.
+func Enabled(name string) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	return !disabled[name]
+}
+
+// Includes reports whether bean belongs to no module or to an enabled module
+func Includes(bean interface{}) bool {
+	component, ok := bean.(Component)
+	return !ok || Enabled(component.Module())
+}
+
+// Filter returns the beans Includes, in order
+func Filter(beans []interface{}) []interface{} {
+	filtered := make([]interface{}, 0, len(beans))
+	for _, bean := range beans {
+		if Includes(bean) {
+			filtered = append(filtered, bean)
+		}
+	}
+	return filtered
+}
+
+// Models returns the models that belong to no module or to an enabled module, in order
+func Models[T any](models []T) []T {
+	mu.RLock()
+	defer mu.RUnlock()
+	filtered := make([]T, 0, len(models))
+	for _, model := range models {
+		if owner, ok := owners[modelType(model)]; ok && disabled[owner] {
+			continue
+		}
+		filtered = append(filtered, model)
+	}
+	return filtered
+}
+
+// List returns the registered modules by name
+func List() []Info {
+	mu.RLock()
+	defer mu.RUnlock()
+	infos := make([]Info, 0, len(modules))
+	for _, name := range names() {
+		infos = append(infos, Info{
+			Name:        name,
+			Description: modules[name].Description,
+			Version:     modules[name].Version,
+			Enabled:     !disabled[name],
+		})
+	}
+	return infos
+}
+
+// names returns the names of the registered modules, sorted; mu must be held
+func names() []string {
+	sorted := make([]string, 0, len(modules))
+	for name := range modules {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// modelType is the struct type of a model or a pointer to it
+func modelType(model interface{}) reflect.Type {
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
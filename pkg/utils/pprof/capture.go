@@ -0,0 +1,151 @@
+package pprof
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"time"
+)
+
+// Profiles accepted by Capture. CPU profiles and traces run for the capture
+// duration, the others are snapshots taken when the duration has elapsed.
+const (
+	ProfileCPU       = "cpu"
+	ProfileTrace     = "trace"
+	ProfileHeap      = "heap"
+	ProfileAllocs    = "allocs"
+	ProfileGoroutine = "goroutine"
+	ProfileBlock     = "block"
+	ProfileMutex     = "mutex"
+)
+
+var (
+	// ErrUnknownProfile is returned for a profile name Capture does not support
+	ErrUnknownProfile = errors.New("unknown profile")
+	// ErrProfileInProgress is returned when another CPU profile or trace is running
+	ErrProfileInProgress = errors.New("a CPU profile or trace is already running")
+)
+
+// DefaultProfiles are the profiles captured by GenerateFullProfile
+var DefaultProfiles = []string{ProfileHeap, ProfileGoroutine, ProfileCPU}
+
+// CaptureOptions selects the profiles of a capture
+type CaptureOptions struct {
+	Profiles []string
+	Duration time.Duration // duration of the CPU profile and trace
+}
+
+// ProfileFile is a captured profile
+type ProfileFile struct {
+	Profile string
+	Name    string // file name, e.g. cpu.pprof
+	Data    []byte
+}
+
+// IsProfile reports whether name is a profile accepted by Capture
+func IsProfile(name string) bool {
+	switch name {
+	case ProfileCPU, ProfileTrace, ProfileHeap, ProfileAllocs, ProfileGoroutine, ProfileBlock, ProfileMutex:
+		return true
+	}
+	return false
+}
+
+// Capture collects the selected profiles. The CPU profile and trace run
+// together for the duration; capture stops early with ctx's error when ctx is
+// done. Block and mutex profiles are empty unless their rates are enabled.
+func (p *PProfManager) Capture(ctx context.Context, opts CaptureOptions) ([]ProfileFile, error) {
+	selected := make(map[string]bool, len(opts.Profiles))
+	for _, name := range opts.Profiles {
+		if !IsProfile(name) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+		}
+		selected[name] = true
+	}
+
+	var cpu, tr bytes.Buffer
+	if selected[ProfileCPU] {
+		if err := pprof.StartCPUProfile(&cpu); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrProfileInProgress, err)
+		}
+	}
+	if selected[ProfileTrace] {
+		if err := trace.Start(&tr); err != nil {
+			if selected[ProfileCPU] {
+				pprof.StopCPUProfile()
+			}
+			return nil, fmt.Errorf("%w: %v", ErrProfileInProgress, err)
+		}
+	}
+	if selected[ProfileCPU] || selected[ProfileTrace] {
+		timer := time.NewTimer(opts.Duration)
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+		if selected[ProfileCPU] {
+			pprof.StopCPUProfile()
+		}
+		if selected[ProfileTrace] {
+			trace.Stop()
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	files := make([]ProfileFile, 0, len(selected))
+	for _, name := range opts.Profiles {
+		if !selected[name] {
+			continue
+		}
+		delete(selected, name)
+
+		file := ProfileFile{Profile: name, Name: name + ".pprof"}
+		switch name {
+		case ProfileCPU:
+			file.Data = cpu.Bytes()
+		case ProfileTrace:
+			file.Name, file.Data = name+".out", tr.Bytes()
+		default:
+			if name == ProfileHeap {
+				runtime.GC() // Get fresh heap statistics
+			}
+			var buf bytes.Buffer
+			if err := pprof.Lookup(name).WriteTo(&buf, 0); err != nil {
+				return nil, fmt.Errorf("failed to write %s profile: %w", name, err)
+			}
+			file.Data = buf.Bytes()
+		}
+		files = append(files, file)
+	}
+	return files, nil
+}
+
+// WriteBundle writes files to w as a gzip compressed tar archive
+func WriteBundle(w io.Writer, files []ProfileFile) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	now := time.Now()
+	for _, file := range files {
+		header := &tar.Header{Name: file.Name, Mode: 0644, Size: int64(len(file.Data)), ModTime: now}
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+		if _, err := tw.Write(file.Data); err != nil {
+			return fmt.Errorf("failed to write bundle: %w", err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	return gz.Close()
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	_ "net/http/pprof"
+	httppprof "net/http/pprof"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -19,15 +19,13 @@ import (
 type PProfConfig struct {
 	Enabled    bool   `mapstructure:"enabled"`
 	PathPrefix string `mapstructure:"path_prefix"`
-	Port       int    `mapstructure:"port"`
 }
 
 // PProfManager manages PProf profiling
 type PProfManager struct {
-	config     *PProfConfig
-	cpuFile    *os.File
-	traceFile  *os.File
-	httpServer *http.Server
+	config    *PProfConfig
+	cpuFile   *os.File
+	traceFile *os.File
 }
 
 // RuntimeStats holds runtime statistics
@@ -75,40 +73,10 @@ func NewPProfManager(config *PProfConfig) *PProfManager {
 	}
 }
 
-// StartHTTPServer starts the PProf HTTP server
-func (p *PProfManager) StartHTTPServer() error {
-	if !p.config.Enabled {
-		return nil
-	}
-
-	mux := http.NewServeMux()
-
-	// Register pprof handlers
-	mux.HandleFunc(p.config.PathPrefix+"/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		http.DefaultServeMux.ServeHTTP(w, r)
-	}))
-
-	p.httpServer = &http.Server{
-		Addr:    fmt.Sprintf(":%d", p.config.Port),
-		Handler: mux,
-	}
-
-	go func() {
-		if err := p.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			fmt.Printf("PProf HTTP server error: %v\n", err)
-		}
-	}()
-
-	return nil
-}
-
-// StopHTTPServer stops the PProf HTTP server
-func (p *PProfManager) StopHTTPServer() error {
-	if p.httpServer != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
-		return p.httpServer.Shutdown(ctx)
-	}
+// OnStop stops any running CPU profile or trace when the container stops
+func (p *PProfManager) OnStop(ctx context.Context) error {
+	p.StopCPUProfile()
+	p.StopTrace()
 	return nil
 }
 
@@ -298,84 +266,59 @@ func (p *PProfManager) StartPeriodicProfiling(ctx context.Context, interval time
 	}
 }
 
-// GenerateFullProfile generates a complete set of profiles
+// GenerateFullProfile writes the default profiles with a 5 second CPU profile to outputDir
 func (p *PProfManager) GenerateFullProfile(outputDir string) error {
+	return p.GenerateProfiles(context.Background(), outputDir, CaptureOptions{
+		Profiles: DefaultProfiles,
+		Duration: 5 * time.Second,
+	})
+}
+
+// GenerateProfiles captures the selected profiles and writes them to outputDir
+// as <profile>_<timestamp>.prof, traces as .out
+func (p *PProfManager) GenerateProfiles(ctx context.Context, outputDir string, opts CaptureOptions) error {
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %w", err)
 	}
 
 	timestamp := time.Now().Format("20060102_150405")
-
-	// Generate heap profile
-	heapFile := filepath.Join(outputDir, fmt.Sprintf("heap_%s.prof", timestamp))
-	if err := p.WriteHeapProfile(heapFile); err != nil {
-		return fmt.Errorf("failed to write heap profile: %w", err)
-	}
-
-	// Generate goroutine profile
-	goroutineFile := filepath.Join(outputDir, fmt.Sprintf("goroutine_%s.prof", timestamp))
-	if err := p.WriteGoroutineProfile(goroutineFile); err != nil {
-		return fmt.Errorf("failed to write goroutine profile: %w", err)
-	}
-
-	// Generate CPU profile (5 seconds)
-	cpuFile := filepath.Join(outputDir, fmt.Sprintf("cpu_%s.prof", timestamp))
-	file, err := p.StartCPUProfile(cpuFile)
+	files, err := p.Capture(ctx, opts)
 	if err != nil {
-		return fmt.Errorf("failed to start CPU profile: %w", err)
+		return err
+	}
+	for _, file := range files {
+		ext := filepath.Ext(file.Name)
+		if ext == ".pprof" {
+			ext = ".prof"
+		}
+		filename := filepath.Join(outputDir, fmt.Sprintf("%s_%s%s", file.Profile, timestamp, ext))
+		if err := os.WriteFile(filename, file.Data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s profile: %w", file.Profile, err)
+		}
 	}
-
-	time.Sleep(5 * time.Second)
-	p.StopCPUProfile()
-	file.Close()
-
 	return nil
 }
 
-// RegisterRoutes registers PProf routes with Gin router
-func (p *PProfManager) RegisterRoutes(router *gin.Engine) {
+// profiles are the runtime profiles served by name under the path prefix
+var profiles = []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"}
+
+// RegisterRoutes registers the PProf handlers and the runtime stats endpoint
+// under the configured path prefix. The guards run before every handler and
+// should restrict access, as profiles expose process internals.
+func (p *PProfManager) RegisterRoutes(router gin.IRouter, guards ...gin.HandlerFunc) {
 	if !p.config.Enabled {
 		return
 	}
 
-	pprofGroup := router.Group(p.config.PathPrefix)
-	{
-		pprofGroup.GET("/", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, p.config.PathPrefix+"/debug/pprof/", http.StatusMovedPermanently)
-		})))
-		pprofGroup.GET("/cmdline", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/profile", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.POST("/symbol", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/symbol", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/trace", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/allocs", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/block", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/goroutine", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/heap", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/mutex", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
-		pprofGroup.GET("/threadcreate", gin.WrapH(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.DefaultServeMux.ServeHTTP(w, r)
-		})))
+	pprofGroup := router.Group(p.config.PathPrefix, guards...)
+	pprofGroup.GET("/", gin.WrapF(httppprof.Index))
+	pprofGroup.GET("/cmdline", gin.WrapF(httppprof.Cmdline))
+	pprofGroup.GET("/profile", gin.WrapF(httppprof.Profile))
+	pprofGroup.GET("/symbol", gin.WrapF(httppprof.Symbol))
+	pprofGroup.POST("/symbol", gin.WrapF(httppprof.Symbol))
+	pprofGroup.GET("/trace", gin.WrapF(httppprof.Trace))
+	for _, name := range profiles {
+		pprofGroup.GET("/"+name, gin.WrapH(httppprof.Handler(name)))
 	}
 
 	// Add runtime stats endpoint
@@ -0,0 +1,272 @@
+// Package sanitize cleans user-provided strings. Struct fields declare their
+// rules with a sanitize tag, for example:
+//
+//	Name        string `json:"name" sanitize:"trim,collapse,strip_control"`
+//	Description string `json:"description" sanitize:"trim,strip_control,html"`
+//
+// Rules always run in the same order regardless of their order in the tag:
+// strip_control, then html or strip_html, then collapse, then trim.
+package sanitize
+
+import (
+	"fmt"
+	"html"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/microcosm-cc/bluemonday"
+)
+
+// Tag is the struct tag listing the comma separated rules of a field
+const Tag = "sanitize"
+
+// Rule names
+const (
+	// Trim removes leading and trailing white space
+	Trim = "trim"
+	// Collapse replaces each run of white space, including line breaks, with a single space
+	Collapse = "collapse"
+	// StripControl removes control characters other than tab and line breaks, and invalid UTF-8
+	StripControl = "strip_control"
+	// HTML keeps safe markup such as formatting and links and removes scripts,
+	// event handlers, styles and unsafe URLs
+	HTML = "html"
+	// StripHTML removes all markup, leaving plain text
+	StripHTML = "strip_html"
+)
+
+// Rules is a parsed set of sanitize rules
+type Rules struct {
+	trim, collapse, stripControl, html, stripHTML bool
+}
+
+// ParseRules parses the comma separated rules of a sanitize tag
+func ParseRules(tag string) (Rules, error) {
+	var r Rules
+	for _, name := range strings.Split(tag, ",") {
+		switch strings.TrimSpace(name) {
+		case "":
+		case Trim:
+			r.trim = true
+		case Collapse:
+			r.collapse = true
+		case StripControl:
+			r.stripControl = true
+		case HTML:
+			r.html = true
+		case StripHTML:
+			r.stripHTML = true
+		default:
+			return Rules{}, fmt.Errorf("sanitize: unknown rule %q", name)
+		}
+	}
+	if r.html && r.stripHTML {
+		return Rules{}, fmt.Errorf("sanitize: rules %s and %s are exclusive", HTML, StripHTML)
+	}
+	return r, nil
+}
+
+// IsZero reports whether no rule is set
+func (r Rules) IsZero() bool {
+	return r == Rules{}
+}
+
+// Apply returns s cleaned by the rules
+func (r Rules) Apply(s string) string {
+	if r.stripControl {
+		s = stripControl(s)
+	}
+	if r.html {
+		s = ugcPolicy.Sanitize(s)
+	}
+	if r.stripHTML {
+		// the strict policy escapes the remaining text as HTML, which plain text must not be
+		s = html.UnescapeString(strictPolicy.Sanitize(s))
+	}
+	if r.collapse {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if r.trim {
+		s = strings.TrimSpace(s)
+	}
+	return s
+}
+
+// String returns s cleaned by the comma separated rules
+func String(s, rules string) (string, error) {
+	r, err := ParseRules(rules)
+	if err != nil {
+		return "", err
+	}
+	return r.Apply(s), nil
+}
+
+var (
+	ugcPolicy    = bluemonday.UGCPolicy()
+	strictPolicy = bluemonday.StrictPolicy()
+)
+
+// stripControl removes control characters other than tab, line feed and
+// carriage return, and invalid UTF-8
+func stripControl(s string) string {
+	s = strings.ToValidUTF8(s, "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+// Struct cleans the tagged string fields of the struct, slice or map obj points
+// to, including those of nested and embedded structs. Tagged fields may be
+// strings, string slices, string maps or pointers to them; the tag of a field
+// of another type is an error.
+func Struct(obj interface{}) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil
+	}
+	return sanitizeValue(v.Elem())
+}
+
+// sanitizeValue walks v looking for structs with tagged fields
+func sanitizeValue(v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return sanitizeValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if !hasStructs(v.Type().Elem()) {
+			return nil
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := sanitizeValue(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		// map values are not addressable, only pointers to structs are cleaned
+		if !hasStructs(v.Type().Elem()) {
+			return nil
+		}
+		iter := v.MapRange()
+		for iter.Next() {
+			if err := sanitizeValue(iter.Value()); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		fields, err := structFields(v.Type())
+		if err != nil {
+			return err
+		}
+		for _, f := range fields {
+			fv := v.Field(f.index)
+			if f.rules.IsZero() {
+				if err := sanitizeValue(fv); err != nil {
+					return err
+				}
+				continue
+			}
+			applyRules(fv, f.rules)
+		}
+	}
+	return nil
+}
+
+// applyRules cleans the strings of a tagged field
+func applyRules(v reflect.Value, rules Rules) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			applyRules(v.Elem(), rules)
+		}
+	case reflect.String:
+		if v.CanSet() {
+			v.SetString(rules.Apply(v.String()))
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			applyRules(v.Index(i), rules)
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			v.SetMapIndex(iter.Key(), reflect.ValueOf(rules.Apply(iter.Value().String())).Convert(v.Type().Elem()))
+		}
+	}
+}
+
+// field is an exported struct field that is tagged or may contain tagged fields
+type field struct {
+	index int
+	rules Rules
+}
+
+// fieldCache caches the fields of each struct type
+var fieldCache sync.Map
+
+// structFields returns the fields of t to clean or walk
+func structFields(t reflect.Type) ([]field, error) {
+	if cached, ok := fieldCache.Load(t); ok {
+		return cached.([]field), nil
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag, ok := sf.Tag.Lookup(Tag)
+		if !ok {
+			if hasStructs(sf.Type) {
+				fields = append(fields, field{index: i})
+			}
+			continue
+		}
+
+		rules, err := ParseRules(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w on field %s.%s", err, t.Name(), sf.Name)
+		}
+		if !isStrings(sf.Type) {
+			return nil, fmt.Errorf("sanitize: field %s.%s of type %s has no strings to clean", t.Name(), sf.Name, sf.Type)
+		}
+		if !rules.IsZero() {
+			fields = append(fields, field{index: i, rules: rules})
+		}
+	}
+
+	fieldCache.Store(t, fields)
+	return fields, nil
+}
+
+// isStrings reports whether t is a string, or a pointer, slice, array or map of strings
+func isStrings(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return isStrings(t.Elem())
+	case reflect.Map:
+		return t.Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+// hasStructs reports whether values of t may contain structs to walk
+func hasStructs(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Interface:
+		return true
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasStructs(t.Elem())
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package timestamp
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+var timeType = reflect.TypeOf(Time{})
+
+// In returns a copy of data with the times it contains, including those of
+// nested structs, pointers, slices, maps and interfaces, converted to loc. The
+// copy has the type of data; values without times are shared with data, which
+// is never modified. Zero times are kept and unexported fields are copied as is.
+func In(data interface{}, loc *time.Location) interface{} {
+	if data == nil || loc == nil {
+		return data
+	}
+	v := reflect.ValueOf(data)
+	if !hasTimes(v.Type()) {
+		return data
+	}
+	return convert(v, loc).Interface()
+}
+
+// convert returns v with its times converted to loc
+func convert(v reflect.Value, loc *time.Location) reflect.Value {
+	t := v.Type()
+	if t == timeType {
+		ts := v.Interface().(Time)
+		if ts.IsZero() {
+			return v
+		}
+		return reflect.ValueOf(Time{ts.Time.In(loc)})
+	}
+	if !hasTimes(t) {
+		return v
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		p := reflect.New(t.Elem())
+		p.Elem().Set(convert(v.Elem(), loc))
+		return p
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t).Elem()
+		out.Set(convert(v.Elem(), loc))
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := 0; i < t.NumField(); i++ {
+			if f := out.Field(i); f.CanSet() {
+				f.Set(convert(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(convert(v.Index(i), loc))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(convert(v.Index(i), loc))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), convert(iter.Value(), loc))
+		}
+		return out
+	}
+	return v
+}
+
+// timesCache caches whether values of a type may contain times
+var timesCache sync.Map // map[reflect.Type]bool
+
+// hasTimes reports whether values of t may contain times, interfaces depend on their value
+func hasTimes(t reflect.Type) bool {
+	if cached, ok := timesCache.Load(t); ok {
+		return cached.(bool)
+	}
+	r := containsTimes(t, make(map[reflect.Type]bool))
+	timesCache.Store(t, r)
+	return r
+}
+
+// containsTimes checks t recursively, visiting holds the types being checked to end cycles
+func containsTimes(t reflect.Type, visiting map[reflect.Type]bool) bool {
+	switch {
+	case t == timeType, t.Kind() == reflect.Interface:
+		return true
+	case visiting[t]:
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return containsTimes(t.Elem(), visiting)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() && containsTimes(f.Type, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,139 @@
+// Package timestamp provides the time type of models and API responses.
+// Values are stored in UTC and encoded in JSON as RFC3339 strings with their
+// offset, so a response rendered in the time zone of the user names the same
+// instant as the stored value:
+//
+//	CreatedAt timestamp.Time `json:"created_at"`
+//
+// In converts the times found in a response to a time zone before encoding.
+package timestamp
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Layout is the RFC3339 layout of encoded times, fractional seconds are
+// written only when present
+const Layout = time.RFC3339Nano
+
+// Time is an instant stored in UTC. It embeds time.Time, whose methods apply
+// to it; the zero value encodes as null.
+type Time struct {
+	time.Time
+}
+
+// New returns t in UTC, without its monotonic clock reading
+func New(t time.Time) Time {
+	if t.IsZero() {
+		return Time{}
+	}
+	return Time{t.UTC()}
+}
+
+// Now returns the current time in UTC
+func Now() Time {
+	return New(time.Now())
+}
+
+// Ptr returns a pointer to t in UTC, nil when t is nil
+func Ptr(t *time.Time) *Time {
+	if t == nil {
+		return nil
+	}
+	v := New(*t)
+	return &v
+}
+
+// Parse parses an RFC3339 time and returns it in UTC
+func Parse(value string) (Time, error) {
+	t, err := time.Parse(Layout, value)
+	if err != nil {
+		return Time{}, err
+	}
+	return New(t), nil
+}
+
+// Std returns the time.Time of t
+func (t Time) Std() time.Time {
+	return t.Time
+}
+
+// String returns t in the RFC3339 layout
+func (t Time) String() string {
+	return t.Format(Layout)
+}
+
+// MarshalJSON encodes t as an RFC3339 string in its location, or null when zero
+func (t Time) MarshalJSON() ([]byte, error) {
+	if t.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(t.Format(Layout))
+}
+
+// UnmarshalJSON decodes an RFC3339 string or null, converting the time to UTC
+func (t *Time) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Time{}
+		return nil
+	}
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("timestamp: %s is not a string", data)
+	}
+	return t.UnmarshalText([]byte(value))
+}
+
+// MarshalText encodes t as an RFC3339 string in its location, empty when zero
+func (t Time) MarshalText() ([]byte, error) {
+	if t.IsZero() {
+		return nil, nil
+	}
+	return []byte(t.Format(Layout)), nil
+}
+
+// UnmarshalText decodes an RFC3339 string, converting the time to UTC; an
+// empty string is the zero time
+func (t *Time) UnmarshalText(data []byte) error {
+	if len(data) == 0 {
+		*t = Time{}
+		return nil
+	}
+	parsed, err := Parse(string(data))
+	if err != nil {
+		return fmt.Errorf("timestamp: %w", err)
+	}
+	*t = parsed
+	return nil
+}
+
+// Scan implements sql.Scanner, times read from the database are converted to UTC
+func (t *Time) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*t = Time{}
+	case time.Time:
+		*t = New(v)
+	case string:
+		return t.UnmarshalText([]byte(v))
+	case []byte:
+		return t.UnmarshalText(v)
+	default:
+		return fmt.Errorf("timestamp: cannot scan %T", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, times are written in UTC
+func (t Time) Value() (driver.Value, error) {
+	return t.Time.UTC(), nil
+}
+
+// GormDataType returns the GORM data type of the column, stored as a
+// timestamp with time zone where the database supports it
+func (Time) GormDataType() string {
+	return "time"
+}